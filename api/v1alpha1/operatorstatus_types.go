@@ -0,0 +1,113 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// OperatorStatusName is the name of the OperatorStatus the controller manager reports to
+const OperatorStatusName = "agents"
+
+// PreflightCheckResult is the result of a preflight check
+// +kubebuilder:validation:Enum=Pass;Warn;Fail
+type PreflightCheckResult string
+
+const (
+	// PreflightCheckPass means the prerequisite is met
+	PreflightCheckPass PreflightCheckResult = "Pass"
+	// PreflightCheckWarn means the prerequisite is not met, only some features are affected
+	PreflightCheckWarn PreflightCheckResult = "Warn"
+	// PreflightCheckFail means the prerequisite is not met, the operator does not work
+	PreflightCheckFail PreflightCheckResult = "Fail"
+)
+
+// PreflightCheck reports the result of a preflight check of the cluster prerequisites
+type PreflightCheck struct {
+	// Name of the check, e.g. CRDs or RBAC
+	Name string `json:"name"`
+
+	// Result of the check, one of Pass, Warn or Fail
+	Result PreflightCheckResult `json:"result"`
+
+	// Message describes the unmet prerequisites
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// OperatorStatusStatus defines the observed state of OperatorStatus
+type OperatorStatusStatus struct {
+	// Checks are the results of the preflight checks run by the controller manager at startup
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	Checks []PreflightCheck `json:"checks,omitempty"`
+
+	// LastCheckTime is when the preflight checks were run last
+	// +optional
+	LastCheckTime *metav1.Time `json:"lastCheckTime,omitempty"`
+
+	// Conditions of the operator, the PreflightPassed condition is True if no preflight check failed
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// OperatorStatusConditionType defines condition types
+type OperatorStatusConditionType string
+
+const (
+	// OperatorStatusConditionPreflightPassed indicates if the cluster meets the prerequisites checked at startup
+	OperatorStatusConditionPreflightPassed OperatorStatusConditionType = "PreflightPassed"
+)
+
+// +genclient
+// +genclient:nonNamespaced
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,path=operatorstatuses,singular=operatorstatus
+// +kubebuilder:printcolumn:name="Preflight",type="string",JSONPath=".status.conditions[?(@.type==\"PreflightPassed\")].status"
+// +kubebuilder:printcolumn:name="Last Check",type="date",JSONPath=".status.lastCheckTime"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+// OperatorStatus reports the health of the installation of the operator, e.g. whether the cluster meets its
+// prerequisites, written by the controller manager.
+type OperatorStatus struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// metadata is a standard object metadata
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty,omitzero"`
+
+	// status defines the observed state of OperatorStatus
+	// +optional
+	Status OperatorStatusStatus `json:"status,omitempty,omitzero"`
+}
+
+// +kubebuilder:object:root=true
+// OperatorStatusList contains a list of OperatorStatus
+type OperatorStatusList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []OperatorStatus `json:"items"`
+}
+
+var OperatorStatusControllerKind = GroupVersion.WithKind("OperatorStatus")
+
+func init() {
+	SchemeBuilder.Register(&OperatorStatus{}, &OperatorStatusList{})
+}
@@ -18,6 +18,7 @@ package v1alpha1
 
 import (
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 )
@@ -83,9 +84,106 @@ type SandboxSpec struct {
 	// +kubebuilder:validation:Format="date-time"
 	PauseTime *metav1.Time `json:"pauseTime,omitempty"`
 
+	// ReadinessProbe - Custom readiness check which must pass, in addition to the pod being ready,
+	// before the sandbox is reported as Ready.
+	// +optional
+	ReadinessProbe *SandboxReadinessProbe `json:"readinessProbe,omitempty"`
+
+	// Quarantine isolates a suspicious sandbox for security review. The pod of a quarantined sandbox is cut from the
+	// network by the quarantine NetworkPolicy of its namespace, and the sandbox is never claimed, released or
+	// garbage collected, regardless of its shutdownTime and pauseTime, until the quarantine is removed.
+	// +optional
+	Quarantine *SandboxQuarantine `json:"quarantine,omitempty"`
+
 	EmbeddedSandboxTemplate `json:",inline"`
 }
 
+// SandboxQuarantine describes why a sandbox is quarantined.
+type SandboxQuarantine struct {
+	// Reason is a human-readable description of the suspicious behavior, for the security review.
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=1024
+	Reason string `json:"reason"`
+
+	// Source is who quarantined the sandbox, like Manual or AnomalyDetector.
+	// +optional
+	Source string `json:"source,omitempty"`
+}
+
+const (
+	// SandboxQuarantineSourceManual means the sandbox is quarantined by an administrator
+	SandboxQuarantineSourceManual = "Manual"
+	// SandboxQuarantineSourceAnomalyDetector means the sandbox is quarantined by an anomaly detector, like the one
+	// of an egress proxy
+	SandboxQuarantineSourceAnomalyDetector = "AnomalyDetector"
+	// SandboxQuarantineSourceRuntimeSecurity means the sandbox is quarantined on an alert of a runtime security
+	// agent, like Falco or Tetragon
+	SandboxQuarantineSourceRuntimeSecurity = "RuntimeSecurity"
+
+	// LabelSandboxQuarantined is set to "true" on the pods of quarantined sandboxes. It is the pod selector of the
+	// quarantine NetworkPolicy, and other NetworkPolicies of sandboxes should not select the pods with it.
+	LabelSandboxQuarantined = InternalPrefix + "quarantined"
+	// QuarantineNetworkPolicyName is the name of the NetworkPolicy denying all traffic of the quarantined pods,
+	// which is created in the namespace of the first quarantined sandbox
+	QuarantineNetworkPolicyName = "sandbox-quarantine"
+)
+
+// SandboxReadinessProbe describes a custom readiness check evaluated against the sandbox pod.
+// It is meant for templates whose agent frameworks don't implement the default health endpoint.
+// Exactly one of httpGet, tcpSocket and exec must be specified.
+// +kubebuilder:validation:XValidation:rule="[has(self.httpGet), has(self.tcpSocket), has(self.exec)].filter(x, x).size() == 1",message="exactly one of httpGet, tcpSocket and exec must be specified"
+type SandboxReadinessProbe struct {
+	// HTTPGet specifies an HTTP GET request against the sandbox. A 2xx or 3xx response means ready.
+	// +optional
+	HTTPGet *SandboxHTTPGetProbe `json:"httpGet,omitempty"`
+
+	// TCPSocket specifies a TCP port of the sandbox which must accept connections.
+	// +optional
+	TCPSocket *SandboxTCPSocketProbe `json:"tcpSocket,omitempty"`
+
+	// Exec specifies a command executed inside the sandbox through the agent runtime. Exit code 0 means ready.
+	// +optional
+	Exec *SandboxExecProbe `json:"exec,omitempty"`
+
+	// TimeoutSeconds is the number of seconds after which a single probe times out. Default: 1.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	TimeoutSeconds int32 `json:"timeoutSeconds,omitempty"`
+
+	// PeriodSeconds is how often (in seconds) the probe is retried while it is failing. Default: 5.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	PeriodSeconds int32 `json:"periodSeconds,omitempty"`
+}
+
+// SandboxHTTPGetProbe describes an HTTP GET readiness check.
+type SandboxHTTPGetProbe struct {
+	// Path to access on the HTTP server, e.g. "/health".
+	// +optional
+	Path string `json:"path,omitempty"`
+
+	// Port to access on the sandbox. Defaults to the agent runtime port (49983).
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	Port int32 `json:"port,omitempty"`
+}
+
+// SandboxTCPSocketProbe describes a TCP readiness check.
+type SandboxTCPSocketProbe struct {
+	// Port to connect to on the sandbox.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	Port int32 `json:"port"`
+}
+
+// SandboxExecProbe describes a command based readiness check.
+type SandboxExecProbe struct {
+	// Command is the command line to execute inside the sandbox. The first element is the executable.
+	// +kubebuilder:validation:MinItems=1
+	Command []string `json:"command"`
+}
+
 type EmbeddedSandboxTemplate struct {
 
 	// TemplateRef references a SandboxTemplate, which will be used to create the sandbox.
@@ -147,6 +245,15 @@ type SandboxStatus struct {
 	// message
 	Message string `json:"message,omitempty"`
 
+	// State is the state of the sandbox derived from its phase, conditions and ownership, i.e. creating, available,
+	// running, paused or dead, as evaluated by the sandbox controller on its last status update.
+	// +optional
+	State string `json:"state,omitempty"`
+
+	// StateReason is the reason of the state, e.g. ResourceControlledBySbsAndReady.
+	// +optional
+	StateReason string `json:"stateReason,omitempty"`
+
 	// conditions represent the current state of the Sandbox resource.
 	// Each condition has a unique type and reflects the status of a specific aspect of the resource.
 	// The status of each condition is one of True, False, or Unknown.
@@ -171,6 +278,89 @@ type SandboxStatus struct {
 	// UpdateRevision is the template-hash calculated from `spec.template`.
 	// +optional
 	UpdateRevision string `json:"updateRevision,omitempty"`
+
+	// Heartbeat is the latest heartbeat reported by the agent inside the sandbox to the sandbox-manager.
+	// It is persisted at a damped rate, so it may lag behind the agent by up to the persist interval of the manager.
+	// +optional
+	Heartbeat *SandboxHeartbeat `json:"heartbeat,omitempty"`
+
+	// ResumeStartTime is when the sandbox controller observed spec.paused being unset for the latest resume. The
+	// resume latency is measured from it to the Ready condition turning true again.
+	// +optional
+	ResumeStartTime *metav1.Time `json:"resumeStartTime,omitempty"`
+
+	// Usage is the smoothed resource usage of the sandbox sampled from metrics-server at a low frequency.
+	// It is only maintained for running sandboxes when the SandboxUsageSampling feature is enabled.
+	// +optional
+	Usage *SandboxUsage `json:"usage,omitempty"`
+
+	// SecurityAlert summarizes the alerts raised on the sandbox by runtime security agents, like Falco or Tetragon.
+	// +optional
+	SecurityAlert *SandboxSecurityAlert `json:"securityAlert,omitempty"`
+}
+
+// SandboxSecurityAlert is the summary of the runtime security alerts of a sandbox. It keeps the details of the
+// latest alert only.
+type SandboxSecurityAlert struct {
+	// Count is the number of alerts received.
+	Count int32 `json:"count"`
+
+	// Source is the agent raising the latest alert, e.g. Falco.
+	// +optional
+	Source string `json:"source,omitempty"`
+
+	// Rule is the rule of the agent triggered by the latest alert.
+	// +optional
+	Rule string `json:"rule,omitempty"`
+
+	// Severity of the latest alert, one of Emergency, Alert, Critical, Error, Warning, Notice, Informational
+	// and Debug.
+	Severity string `json:"severity"`
+
+	// Summary is the truncated output of the latest alert.
+	// +optional
+	Summary string `json:"summary,omitempty"`
+
+	// Action is taken by the sandbox-manager on the latest alert.
+	Action SandboxSecurityAlertAction `json:"action"`
+
+	// LastAlertTime is when the latest alert was raised.
+	LastAlertTime metav1.Time `json:"lastAlertTime"`
+}
+
+// SandboxSecurityAlertAction is the action taken on a runtime security alert of a sandbox.
+// +enum
+type SandboxSecurityAlertAction string
+
+const (
+	SandboxSecurityAlertActionNone       SandboxSecurityAlertAction = "None"
+	SandboxSecurityAlertActionPause      SandboxSecurityAlertAction = "Pause"
+	SandboxSecurityAlertActionQuarantine SandboxSecurityAlertAction = "Quarantine"
+)
+
+// SandboxUsage is the resource usage of all containers of a sandbox, smoothed by an exponentially weighted
+// moving average over the samples.
+type SandboxUsage struct {
+	// CPU is the smoothed cpu usage, in millicores.
+	CPU resource.Quantity `json:"cpu"`
+
+	// Memory is the smoothed working set memory, rounded to MiB.
+	Memory resource.Quantity `json:"memory"`
+
+	// LastSampleTime is when the usage was last written. An unchanged usage is written again every few samples,
+	// so a LastSampleTime far behind the sample interval means the usage is stale.
+	LastSampleTime metav1.Time `json:"lastSampleTime"`
+}
+
+// SandboxHeartbeat is the liveness and activity reported by the agent inside the sandbox, which is the single
+// source for idle detection, lease renewal and zombie detection of the sandbox.
+type SandboxHeartbeat struct {
+	// LastHeartbeatTime is when the agent reported the heartbeat.
+	LastHeartbeatTime metav1.Time `json:"lastHeartbeatTime"`
+
+	// LastActivityTime is the last user activity seen by the agent, e.g. a command run or a connection served.
+	// +optional
+	LastActivityTime *metav1.Time `json:"lastActivityTime,omitempty"`
 }
 
 // SandboxPhase is a label for the condition of a pod at the current time.
@@ -231,6 +421,9 @@ const (
 
 	// SandboxConditionInplaceUpdate means inplace update state.
 	SandboxConditionInplaceUpdate SandboxConditionType = "InplaceUpdate"
+
+	// SandboxConditionQuarantined means the sandbox is quarantined for security review.
+	SandboxConditionQuarantined SandboxConditionType = "Quarantined"
 )
 
 const (
@@ -238,6 +431,7 @@ const (
 	SandboxReadyReasonPodReady             = "PodReady"
 	SandboxReadyReasonInplaceUpdating      = "InplaceUpdating"
 	SandboxReadyReasonStartContainerFailed = "StartContainerFailed"
+	SandboxReadyReasonProbeFailed          = "ReadinessProbeFailed"
 
 	// SandboxConditionInplaceUpdate Reason
 	SandboxInplaceUpdateReasonInplaceUpdating = "InplaceUpdating"
@@ -251,6 +445,10 @@ const (
 	// SandboxConditionResume Reason
 	SandboxResumeReasonCreatePod = "CreatePod"
 	SandboxResumeReasonResumePod = "ResumePod"
+
+	// SandboxConditionQuarantined Reason
+	SandboxQuarantinedReasonNetworkIsolated = "NetworkIsolated"
+	SandboxQuarantinedReasonPodNotFound     = "PodNotFound"
 )
 
 // +genclient
@@ -259,7 +457,9 @@ const (
 // +kubebuilder:resource:path=sandboxes,shortName={sbx},singular=sandbox
 // +kubebuilder:storageversion
 // +kubebuilder:printcolumn:name="Status",type="string",JSONPath=".status.phase"
+// +kubebuilder:printcolumn:name="State",type="string",JSONPath=".status.state"
 // +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:printcolumn:name="Reason",type="string",JSONPath=".status.stateReason",priority=1
 // +kubebuilder:printcolumn:name="Claimed",type="string",JSONPath=".metadata.labels.agents\\.kruise\\.io/sandbox-claimed"
 // +kubebuilder:printcolumn:name="shutdown_time",type="string",JSONPath=".spec.shutdownTime"
 // +kubebuilder:printcolumn:name="pause_time",type="string",JSONPath=".spec.pauseTime"
@@ -18,43 +18,192 @@ package v1alpha1
 
 import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 )
 
 // SandboxClaimSpec defines the desired state of SandboxClaim
+// requiresApproval is omitted when false, so its immutability is validated on the spec to cover adding and removing it.
+// +kubebuilder:validation:XValidation:rule="(has(self.requiresApproval) && self.requiresApproval) == (has(oldSelf.requiresApproval) && oldSelf.requiresApproval)",message="requiresApproval is immutable"
+// +kubebuilder:validation:XValidation:rule="(has(self.dryRun) && self.dryRun) == (has(oldSelf.dryRun) && oldSelf.dryRun)",message="dryRun is immutable"
+// +kubebuilder:validation:XValidation:rule="[has(self.templateName), has(self.templateSelector), has(self.templates)].filter(x, x).size() == 1",message="exactly one of templateName, templateSelector and templates must be set"
 type SandboxClaimSpec struct {
 	// TemplateName specifies which SandboxSet pool to claim from
-	// +kubebuilder:validation:Required
-	TemplateName string `json:"templateName"`
+	// Exactly one of templateName, templateSelector and templates must be set.
+	// +optional
+	// +kubebuilder:validation:XValidation:rule="self.size() > 0",message="templateName must not be empty"
+	TemplateName string `json:"templateName,omitempty"`
+
+	// TemplateSelector selects the SandboxSet pools to claim from by their labels, as an alternative to templateName.
+	// The claim claims from one of the matching SandboxSets with available sandboxes, the one it claimed from last
+	// first and then by name, and falls back to another one once it is exhausted. If none has available sandboxes,
+	// the claim waits for the one it claimed from last, or the first one by name. The SandboxSet being claimed from is
+	// recorded in status.sandboxSetName.
+	// +optional
+	TemplateSelector *metav1.LabelSelector `json:"templateSelector,omitempty"`
+
+	// Templates lists the SandboxSet pools to claim from in the order of preference, as an alternative to
+	// templateName, e.g. a gpu-pool preferred over a cpu-pool. The claim claims from the first one with available
+	// sandboxes until its replicas are met, and waits for the first one if none has. The SandboxSet being claimed
+	// from is recorded in status.sandboxSetName, and the sandboxes claimed from each one in status.templates.
+	// +optional
+	// +listType=set
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:MaxItems=10
+	Templates []string `json:"templates,omitempty"`
+
+	// TemplateRevision restricts the sandboxes to claim to a revision of the SandboxSet, as recorded in its
+	// status.updateRevision and the agents.kruise.io/template-hash label of its sandboxes.
+	// Latest floats to the current revision of the pool, so that interactive users never get an outdated sandbox.
+	// Any other value pins the claim to that revision hash, so that batch jobs are reproducible; a pinned claim
+	// never creates sandboxes on no stock once the pool has moved to another revision.
+	// Empty claims sandboxes of any revision.
+	// +optional
+	// +kubebuilder:validation:MaxLength=63
+	// +kubebuilder:validation:Pattern=`^(Latest|[a-z0-9]+)$`
+	TemplateRevision string `json:"templateRevision,omitempty"`
+
+	// Regions selects the sandboxes to claim by the regions of their SandboxSets, labeled with
+	// agents.kruise.io/region. Empty claims sandboxes of any region.
+	// +optional
+	Regions *RegionPolicy `json:"regions,omitempty"`
+
+	// Selector restricts the sandboxes to claim to the ones of the SandboxSet whose labels match it,
+	// e.g. gpu=true or zone=us-east-1a. Empty claims any sandbox of the SandboxSet.
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
 
 	// Replicas specifies how many sandboxes to claim (default: 1)
 	// For batch claiming support
-	// This field is immutable once set
+	// Increasing it makes a claim that has claimed all its replicas claim the added ones, decreasing it releases
+	// the claimed sandboxes beyond it according to releasePolicy.
 	// +optional
 	// +kubebuilder:default=1
 	// +kubebuilder:validation:Minimum=1
-	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="replicas is immutable"
 	Replicas *int32 `json:"replicas,omitempty"`
 
+	// ReleasePolicy specifies which claimed sandboxes are released, i.e. deleted so that the pool replenishes, when
+	// replicas is decreased below the claimed ones. ReleaseNewest releases the last claimed sandboxes first,
+	// ReleaseOldest the first claimed ones, and Retain keeps all of them claimed. Quarantined sandboxes are never
+	// released.
+	// +optional
+	// +kubebuilder:default=Retain
+	ReleasePolicy SandboxClaimReleasePolicy `json:"releasePolicy,omitempty"`
+
+	// Priority of the claim among the claims competing for the unclaimed sandboxes of the same SandboxSet. The
+	// available sandboxes are left to the remaining replicas of the claiming claims of a higher priority first, so
+	// that a claim only claims the ones they do not need. The Priority ordering of the claim queue of the SandboxSet
+	// also admits claims of a higher priority first. Defaults to 0.
+	// +optional
+	Priority *int32 `json:"priority,omitempty"`
+
+	// PreemptionPolicy specifies whether the claim may preempt claims of a lower priority of the same SandboxSet.
+	// Never (default) only waits for the available sandboxes.
+	// PreemptLowerPriority releases, i.e. deletes, the claimed sandboxes of the lowest priority claims of the
+	// SandboxSet when the claim makes no progress and no sandbox is being created for it, so that their capacity goes
+	// to the sandboxes the claim is waiting for. Preempted claims get a Preempted condition and claim replacements
+	// once no claim of a higher priority needs the sandboxes.
+	// +optional
+	// +kubebuilder:default=Never
+	PreemptionPolicy SandboxClaimPreemptionPolicy `json:"preemptionPolicy,omitempty"`
+
+	// FulfillmentPolicy specifies whether the replicas may be claimed incrementally.
+	// BestEffort (default) claims the available sandboxes as they come, so that the claim may hold part of its
+	// replicas while waiting for the rest.
+	// AllOrNothing waits until the SandboxSet has enough available sandboxes for all the remaining replicas, claims
+	// them in a single batch and releases the ones bound in it if any other bind fails, so that gang workloads get
+	// all their sandboxes or none.
+	// +optional
+	// +kubebuilder:default=BestEffort
+	FulfillmentPolicy SandboxClaimFulfillmentPolicy `json:"fulfillmentPolicy,omitempty"`
+
+	// ClaimGroup gangs the claims of the same group in the namespace, e.g. one per agent role of a workload, so that
+	// they are fulfilled atomically: the remaining replicas of all the claims of the group are claimed at once, once
+	// all of them are claiming and their SandboxSets have enough available sandboxes, and the sandboxes bound for any
+	// of them are released if any bind fails. The group fails, completing its claims without claiming, once one of
+	// them completes without all its replicas, e.g. on timeout. The fulfillmentPolicy of grouped claims is ignored.
+	// +optional
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="claimGroup is immutable"
+	ClaimGroup *SandboxClaimGroup `json:"claimGroup,omitempty"`
+
+	// RetryPolicy backs off the retries of the claim while the SandboxSet has no sandbox for it, instead of retrying
+	// at a fixed interval. Empty applies the default retry policy of the controller, configured by its
+	// --sandboxclaim-retry-* flags, which backs off from 2s up to 1m with a jitter of 20% unless disabled.
+	// +optional
+	RetryPolicy *SandboxClaimRetryPolicy `json:"retryPolicy,omitempty"`
+
+	// Paused suspends claiming: while paused, the claim binds no more sandboxes and its claimTimeout does not run, and
+	// it resumes from where it stopped once unpaused. The sandboxes claimed already are kept. It has no effect on
+	// completed claims.
+	// +optional
+	Paused bool `json:"paused,omitempty"`
+
 	// ShutdownTime specifies the absolute time when the sandbox should be shut down
 	// This will be set as spec.shutdownTime (absolute time) on the Sandbox
 	// +optional
 	ShutdownTime *metav1.Time `json:"shutdownTime,omitempty"`
 
+	// LeaseDuration leases the claimed sandboxes for the duration from the completion of the claim, so that the
+	// sandboxes of abandoned sessions return to the pool. The lease is renewed for another duration by setting the
+	// agents.kruise.io/lease-renew-time annotation to the current time, and the claimed sandboxes are released, i.e.
+	// deleted, once the lease expires without renewal. Unset means the sandboxes are claimed without a lease.
+	// +optional
+	// +kubebuilder:validation:XValidation:rule="duration(self) > duration('0s')",message="leaseDuration must be positive"
+	LeaseDuration *metav1.Duration `json:"leaseDuration,omitempty"`
+
 	// ClaimTimeout specifies the maximum duration to wait for claiming sandboxes
 	// If the timeout is reached, the claim will be marked as Completed regardless of
 	// whether all replicas were successfully claimed
+	// Defaults to 1m, or with the SandboxClaimAdaptiveTimeout feature gate to twice the p99 latency of the recent
+	// claims of the template, bounded to [10s, 10m], once enough claims of the template are observed.
 	// +optional
-	// +kubebuilder:default="1m"
+	// +kubebuilder:validation:XValidation:rule="duration(self) > duration('0s')",message="claimTimeout must be positive"
 	ClaimTimeout *metav1.Duration `json:"claimTimeout,omitempty"`
 
+	// PerReplicaTimeout specifies the maximum duration to bind a single sandbox, so that a claim whose binds stall,
+	// e.g. in a storm of conflicts, fails fast instead of waiting for the whole ClaimTimeout.
+	// If a bind exceeds it, the claim is marked as Completed with a ReplicaTimedOut condition, while reaching the
+	// ClaimTimeout gives a TimedOut condition. Unset means binds are only bounded by the ClaimTimeout.
+	// +optional
+	// +kubebuilder:validation:XValidation:rule="duration(self) > duration('0s')",message="perReplicaTimeout must be positive"
+	PerReplicaTimeout *metav1.Duration `json:"perReplicaTimeout,omitempty"`
+
+	// MinReadySeconds is how long a claimed sandbox must have been Ready since it was claimed before it is counted in
+	// status.claimedReplicas, so that a sandbox flapping or dying right after being claimed is not handed over to the
+	// workload. The claim completes once all its replicas are counted. Defaults to 0, counting sandboxes once bound.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	MinReadySeconds int32 `json:"minReadySeconds,omitempty"`
+
 	// TTLAfterCompleted specifies the time to live after the claim reaches Completed phase
 	// After this duration, the SandboxClaim will be automatically deleted.
-	// Note: Only the SandboxClaim resource will be deleted; the claimed sandboxes will NOT be deleted
+	// Note: Only the SandboxClaim resource will be deleted unless TTLPolicy is Delete; the claimed sandboxes will
+	// NOT be deleted by default
 	// Set to a negative value (e.g., "-1s") to disable automatic deletion (never delete).
 	// +optional
 	// +kubebuilder:default="60m"
 	TTLAfterCompleted *metav1.Duration `json:"ttlAfterCompleted,omitempty"`
 
+	// TTLPolicy specifies what happens to the claimed sandboxes when the claim is deleted after TTLAfterCompleted.
+	// Retain (default) keeps them claimed and running after the claim is deleted, spec.deletionPolicy does not apply
+	// to them.
+	// Delete releases, i.e. deletes, them before the claim is deleted, so that they do not outlive the claim.
+	// Quarantined sandboxes are never deleted.
+	// +optional
+	// +kubebuilder:default=Retain
+	TTLPolicy SandboxClaimTTLPolicy `json:"ttlPolicy,omitempty"`
+
+	// DeletionPolicy specifies what happens to the sandboxes still claimed when the claim is deleted, unless deleted
+	// after TTLAfterCompleted, which spec.ttlPolicy governs instead.
+	// Unbind (default) returns them to the pool of their SandboxSet to be claimed again: the claim labels, annotations
+	// and shutdownTime are removed from them, they are marked unclaimed and are controlled by the SandboxSet again.
+	// Sandboxes whose SandboxSet no longer exists are deleted. Use Delete if the used sandboxes must not be reused,
+	// e.g. when the claims run untrusted code in them.
+	// Delete releases, i.e. deletes, them so that the pool replenishes.
+	// Quarantined sandboxes are neither returned nor deleted, but only unbound from the claim for their review.
+	// +optional
+	// +kubebuilder:default=Unbind
+	DeletionPolicy SandboxClaimDeletionPolicy `json:"deletionPolicy,omitempty"`
+
 	// Labels contains key-value pairs to be added as labels
 	// to claimed Sandbox resources
 	// +optional
@@ -97,12 +246,324 @@ type SandboxClaimSpec struct {
 	// Format: duration string (e.g., "3h", "200s", "15m")
 	// +optional
 	// +kubebuilder:default="30s"
+	// +kubebuilder:validation:XValidation:rule="duration(self) > duration('0s')",message="waitReadyTimeout must be positive"
 	WaitReadyTimeout *metav1.Duration `json:"waitReadyTimeout,omitempty"`
 
 	// SkipInitRuntime allows to skip init runtime for sandbox while claiming
 	// +optional
 	// +kubebuilder:default=false
 	SkipInitRuntime bool `json:"skipInitRuntime,omitempty"`
+
+	// ReplaceOnFailure makes the controller replace claimed sandboxes that die after the claim completed.
+	// When a claimed sandbox is found dead (failed, deleted, etc.), ClaimedReplicas is decreased,
+	// a ReplicaLost condition is recorded and the claim re-enters the Claiming phase to bind a replacement.
+	// Sandboxes that reach spec.shutdownTime are not replaced.
+	// +optional
+	ReplaceOnFailure bool `json:"replaceOnFailure,omitempty"`
+
+	// OnTimeout specifies what happens to the sandboxes already claimed when the claim times out or is canceled
+	// before all replicas are claimed.
+	// KeepClaimed (default) keeps them bound to the claim.
+	// ReleaseClaimed deletes them, so that a failed claim does not hold pool capacity.
+	// BestEffort downgrades a timed out claim to best-effort: it keeps them and goes on claiming the rest without a
+	// deadline, until all replicas are claimed or the claim is canceled, in which case they are kept.
+	// Fail deletes them like ReleaseClaimed, and a timed out claim ends in the Failed phase with the Completed
+	// condition False instead of Completed, so that automation can tell it from a fulfilled claim by its phase or
+	// conditions. Failing is a value of onTimeout rather than a separate timeout policy, as a failed claim also
+	// releases its sandboxes, which onTimeout already decides, and a separate field could contradict it, e.g. keeping
+	// the sandboxes of a failed claim.
+	// The behavior applied on timeout is recorded as the reason of the TimeoutHandled condition.
+	// +optional
+	// +kubebuilder:default=KeepClaimed
+	OnTimeout SandboxClaimTimeoutPolicy `json:"onTimeout,omitempty"`
+
+	// ResultSecretName is the name of a Secret in the namespace of the claim, into which the controller writes the
+	// claimed sandboxes once the claim is completed, so that Pods can mount the result instead of querying the API.
+	// The Secret is owned by the claim and contains the keys `sandbox-ids` (one ID per line) and `sandboxes.json`
+	// (IDs, runtime URLs and access tokens). An existing Secret not owned by the claim is never overwritten.
+	// +optional
+	// +kubebuilder:validation:MaxLength=253
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9.]*[a-z0-9])?$`
+	ResultSecretName string `json:"resultSecretName,omitempty"`
+
+	// RequiresApproval holds the claim in the PendingApproval phase until it is approved or rejected with the
+	// agents.kruise.io/claim-approval annotation, by the approval endpoint of the sandbox-manager or by any user
+	// allowed to `approve` sandboxclaims. The claim timeout starts counting after the approval.
+	// +optional
+	RequiresApproval bool `json:"requiresApproval,omitempty"`
+
+	// OrdinalAliases gives the claimed sandboxes stable aliases, so that multi-sandbox topologies can address their
+	// peers predictably. Each claimed sandbox and its pod are labeled with a distinct ordinal from 0 to replicas-1 in
+	// agents.kruise.io/claim-ordinal and the alias <claim name>-<ordinal> in agents.kruise.io/claim-alias, which may
+	// name a Service selecting it. A replacement of a lost sandbox takes its ordinal.
+	// The name of the claim must be a DNS label of at most 56 characters.
+	// +optional
+	OrdinalAliases bool `json:"ordinalAliases,omitempty"`
+
+	// DryRun checks how many sandboxes the claim could claim right now without claiming any of them, e.g. to probe
+	// the capacity of the pools before committing a real claim. The claim neither waits for approval nor queues, and
+	// completes with the DryRun reason once status.dryRun is reported.
+	// +optional
+	DryRun bool `json:"dryRun,omitempty"`
+
+	// Notifications configures the HTTP callback invoked once the claim reaches the Completed or Failed phase,
+	// including when it timed out, so that agent orchestrators learn about the claimed sandboxes without watching the
+	// API server. The delivery is recorded in the Notified condition and retried until it succeeds, a claim reopened
+	// by a scale up or a lost replica is notified again once finished.
+	// +optional
+	Notifications *SandboxClaimNotifications `json:"notifications,omitempty"`
+}
+
+// SandboxClaimTemplateRevisionLatest floats a claim to the current revision of its SandboxSet
+const SandboxClaimTemplateRevisionLatest = "Latest"
+
+const (
+	// AnnotationClaimApproval records the approval decision of a SandboxClaim requiring approval,
+	// either SandboxClaimApprovalApproved or SandboxClaimApprovalRejected. Setting it requires the `approve` verb
+	// on the claim, and the decision cannot be changed once made.
+	AnnotationClaimApproval = InternalPrefix + "claim-approval"
+
+	SandboxClaimApprovalApproved = "Approved"
+	SandboxClaimApprovalRejected = "Rejected"
+)
+
+const (
+	// LabelSandboxClaimOrdinal is the ordinal of a sandbox claimed by a SandboxClaim with spec.ordinalAliases
+	LabelSandboxClaimOrdinal = InternalPrefix + "claim-ordinal"
+	// LabelSandboxClaimAlias is the alias <claim name>-<ordinal> of a sandbox claimed by a SandboxClaim with
+	// spec.ordinalAliases
+	LabelSandboxClaimAlias = InternalPrefix + "claim-alias"
+)
+
+// AnnotationClaimCancel cancels a SandboxClaim not completed yet when set to "true": the controller stops binding
+// sandboxes, releases the claimed ones according to spec.onTimeout and completes the claim with a Canceled condition.
+// It has no effect on completed claims.
+const AnnotationClaimCancel = InternalPrefix + "cancel"
+
+// AnnotationClaimLeaseRenewTime renews the lease of a SandboxClaim with spec.leaseDuration when set to the current
+// time in RFC3339, the lease then expires spec.leaseDuration after it. A time in the future takes effect when reached.
+const AnnotationClaimLeaseRenewTime = InternalPrefix + "lease-renew-time"
+
+// SandboxClaimFinalizer is added to SandboxClaims by the controller, so that the claimed sandboxes are released
+// according to spec.deletionPolicy before the claim is gone.
+const SandboxClaimFinalizer = "agents.kruise.io/sandboxclaim"
+
+const (
+	// SandboxClaimResultSandboxIDsKey is the key of the result Secret holding the IDs of claimed sandboxes, one per line
+	SandboxClaimResultSandboxIDsKey = "sandbox-ids"
+	// SandboxClaimResultSandboxesKey is the key of the result Secret holding the claimed sandboxes as a JSON list
+	SandboxClaimResultSandboxesKey = "sandboxes.json"
+)
+
+// SandboxClaimResultSandbox is an item of the sandboxes.json in the result Secret of a SandboxClaim
+type SandboxClaimResultSandbox struct {
+	SandboxID   string `json:"sandboxID"`
+	Name        string `json:"name"`
+	RuntimeURL  string `json:"runtimeURL,omitempty"`
+	AccessToken string `json:"accessToken,omitempty"`
+}
+
+// SandboxClaimNotifications defines the HTTP callback of a SandboxClaim
+type SandboxClaimNotifications struct {
+	// URL is the https endpoint a SandboxClaimNotification is posted to as JSON, any 2xx response means it is
+	// delivered. Redirects are not followed, and endpoints resolving to loopback, link-local or private addresses are
+	// refused. http endpoints are refused unless the controller runs with --sandboxclaim-notification-allow-http.
+	// +kubebuilder:validation:Pattern=`^https?://.+`
+	URL string `json:"url"`
+
+	// IncludeAccessTokens includes the access tokens of the claimed sandboxes in the callbacks, which are left out by
+	// default so that they are not sent to the endpoint. Only set it for endpoints you trust with the sandboxes.
+	// +optional
+	IncludeAccessTokens bool `json:"includeAccessTokens,omitempty"`
+
+	// Headers are set on the callback requests, e.g. to route them or identify the cluster
+	// +optional
+	Headers map[string]string `json:"headers,omitempty"`
+
+	// SecretRef refers to a key of a Secret in the namespace of the claim. Its value is the HMAC-SHA256 key the body
+	// of the callback requests is signed with into the X-Sandbox-Claim-Signature header as sha256=<hex digest>, so
+	// that the receiver can verify them.
+	// +optional
+	SecretRef *SandboxClaimNotificationSecretRef `json:"secretRef,omitempty"`
+}
+
+// SandboxClaimNotificationSecretRef selects the key of a Secret signing the callbacks of a SandboxClaim
+type SandboxClaimNotificationSecretRef struct {
+	// Name is the name of the Secret
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Key is the key of the signing key in the Secret, defaults to "key"
+	// +optional
+	Key string `json:"key,omitempty"`
+}
+
+const (
+	// SandboxClaimNotificationSignatureHeader is the header carrying the signature of a callback signed with
+	// spec.notifications.secretRef
+	SandboxClaimNotificationSignatureHeader = "X-Sandbox-Claim-Signature"
+	// SandboxClaimNotificationDefaultSecretKey is the key of spec.notifications.secretRef if unset
+	SandboxClaimNotificationDefaultSecretKey = "key"
+)
+
+// SandboxClaimNotification is the JSON body posted to spec.notifications.url once a SandboxClaim is finished
+type SandboxClaimNotification struct {
+	Name      string            `json:"name"`
+	Namespace string            `json:"namespace"`
+	UID       types.UID         `json:"uid"`
+	Phase     SandboxClaimPhase `json:"phase"`
+	// Reason is the reason of the Completed condition, e.g. AllReplicasClaimed or ClaimTimeout
+	Reason          string       `json:"reason,omitempty"`
+	Message         string       `json:"message,omitempty"`
+	TimedOut        bool         `json:"timedOut"`
+	ClaimedReplicas int32        `json:"claimedReplicas"`
+	CompletionTime  *metav1.Time `json:"completionTime,omitempty"`
+	// Sandboxes are the claimed sandboxes with their endpoints, the same as in the result Secret but without the
+	// access tokens unless spec.notifications.includeAccessTokens is set
+	Sandboxes []SandboxClaimResultSandbox `json:"sandboxes"`
+}
+
+// SandboxClaimTimeoutPolicy defines what happens to the claimed sandboxes when a claim times out
+// +kubebuilder:validation:Enum=KeepClaimed;ReleaseClaimed;BestEffort;Fail
+type SandboxClaimTimeoutPolicy string
+
+const (
+	// SandboxClaimTimeoutKeepClaimed keeps the partially claimed sandboxes
+	SandboxClaimTimeoutKeepClaimed SandboxClaimTimeoutPolicy = "KeepClaimed"
+	// SandboxClaimTimeoutReleaseClaimed deletes the partially claimed sandboxes
+	SandboxClaimTimeoutReleaseClaimed SandboxClaimTimeoutPolicy = "ReleaseClaimed"
+	// SandboxClaimTimeoutBestEffort keeps the partially claimed sandboxes and goes on claiming without a deadline
+	SandboxClaimTimeoutBestEffort SandboxClaimTimeoutPolicy = "BestEffort"
+	// SandboxClaimTimeoutFail deletes the partially claimed sandboxes and fails the claim
+	SandboxClaimTimeoutFail SandboxClaimTimeoutPolicy = "Fail"
+)
+
+// SandboxClaimReleasePolicy defines which claimed sandboxes are released when the replicas of a claim are decreased
+// +kubebuilder:validation:Enum=ReleaseNewest;ReleaseOldest;Retain
+type SandboxClaimReleasePolicy string
+
+const (
+	// SandboxClaimReleaseNewest releases the last claimed sandboxes first
+	SandboxClaimReleaseNewest SandboxClaimReleasePolicy = "ReleaseNewest"
+	// SandboxClaimReleaseOldest releases the first claimed sandboxes first
+	SandboxClaimReleaseOldest SandboxClaimReleasePolicy = "ReleaseOldest"
+	// SandboxClaimRetain keeps the claimed sandboxes beyond the replicas
+	SandboxClaimRetain SandboxClaimReleasePolicy = "Retain"
+)
+
+// SandboxClaimTTLPolicy defines what happens to the claimed sandboxes when a claim is deleted by its TTL
+// +kubebuilder:validation:Enum=Retain;Delete
+type SandboxClaimTTLPolicy string
+
+const (
+	// SandboxClaimTTLRetain keeps the claimed sandboxes after the claim is deleted
+	SandboxClaimTTLRetain SandboxClaimTTLPolicy = "Retain"
+	// SandboxClaimTTLDelete deletes the claimed sandboxes with the claim
+	SandboxClaimTTLDelete SandboxClaimTTLPolicy = "Delete"
+)
+
+// SandboxClaimDeletionPolicy defines what happens to the claimed sandboxes when a claim is deleted other than by its TTL
+// +kubebuilder:validation:Enum=Unbind;Delete
+type SandboxClaimDeletionPolicy string
+
+const (
+	// SandboxClaimDeletionUnbind returns the claimed sandboxes to the pool of their SandboxSet
+	SandboxClaimDeletionUnbind SandboxClaimDeletionPolicy = "Unbind"
+	// SandboxClaimDeletionDelete deletes the claimed sandboxes with the claim
+	SandboxClaimDeletionDelete SandboxClaimDeletionPolicy = "Delete"
+)
+
+// SandboxClaimFulfillmentPolicy defines whether the replicas of a claim may be claimed incrementally
+// +kubebuilder:validation:Enum=AllOrNothing;BestEffort
+type SandboxClaimFulfillmentPolicy string
+
+const (
+	// SandboxClaimFulfillmentAllOrNothing claims all the remaining replicas at once or none of them
+	SandboxClaimFulfillmentAllOrNothing SandboxClaimFulfillmentPolicy = "AllOrNothing"
+	// SandboxClaimFulfillmentBestEffort claims the replicas incrementally as sandboxes become available
+	SandboxClaimFulfillmentBestEffort SandboxClaimFulfillmentPolicy = "BestEffort"
+)
+
+// SandboxClaimGroup identifies the group of claims fulfilled atomically
+type SandboxClaimGroup struct {
+	// Name of the group, shared by the claims of the group in the namespace
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MaxLength=63
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	Name string `json:"name"`
+
+	// Size is the number of claims of the group. The group is only claimed once all of them are created.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	Size int32 `json:"size"`
+}
+
+// SandboxClaimPreemptionPolicy defines whether a claim may preempt claims of a lower priority
+// +kubebuilder:validation:Enum=PreemptLowerPriority;Never
+type SandboxClaimPreemptionPolicy string
+
+const (
+	// SandboxClaimPreemptLowerPriority releases the claimed sandboxes of claims of a lower priority for the claim
+	SandboxClaimPreemptLowerPriority SandboxClaimPreemptionPolicy = "PreemptLowerPriority"
+	// SandboxClaimPreemptNever never preempts other claims
+	SandboxClaimPreemptNever SandboxClaimPreemptionPolicy = "Never"
+)
+
+// RegionFallback decides how to claim when the preferred regions of a RegionPolicy are exhausted
+// +enum
+type RegionFallback string
+
+const (
+	// RegionFallbackAny claims from the other regions not forbidden
+	RegionFallbackAny RegionFallback = "Any"
+	// RegionFallbackNone only claims from the preferred regions
+	RegionFallbackNone RegionFallback = "None"
+)
+
+// RegionPolicy selects sandboxes by the regions of their SandboxSets, see LabelSandboxRegion.
+// Sandboxes of SandboxSets without a region are only claimed by fallback.
+type RegionPolicy struct {
+	// Preferred regions in order of preference. The sandboxes of a region are only claimed once those of the
+	// regions preferred over it are exhausted.
+	// +optional
+	// +listType=atomic
+	Preferred []string `json:"preferred,omitempty"`
+
+	// Forbidden regions are never claimed from, even if preferred
+	// +optional
+	// +listType=atomic
+	Forbidden []string `json:"forbidden,omitempty"`
+
+	// Fallback decides how to claim when the preferred regions are exhausted, either Any or None.
+	// Ignored if no region is preferred.
+	// +optional
+	// +kubebuilder:default=Any
+	// +kubebuilder:validation:Enum=Any;None
+	Fallback RegionFallback `json:"fallback,omitempty"`
+}
+
+// SandboxClaimRetryPolicy defines how a claim backs off while the SandboxSet has no sandbox for it.
+// The backoff doubles on every consecutive retry from initialBackoff up to maxBackoff, and restarts once the claim
+// stops waiting for sandboxes. It never delays a retry beyond the claim timeout.
+// +kubebuilder:validation:XValidation:rule="!has(self.initialBackoff) || !has(self.maxBackoff) || duration(self.initialBackoff) <= duration(self.maxBackoff)",message="initialBackoff must not exceed maxBackoff"
+type SandboxClaimRetryPolicy struct {
+	// InitialBackoff is the delay of the first retry (default: 2s)
+	// +optional
+	// +kubebuilder:validation:XValidation:rule="duration(self) > duration('0s')",message="initialBackoff must be positive"
+	InitialBackoff *metav1.Duration `json:"initialBackoff,omitempty"`
+
+	// MaxBackoff caps the delay of the retries (default: 1m)
+	// +optional
+	// +kubebuilder:validation:XValidation:rule="duration(self) > duration('0s')",message="maxBackoff must be positive"
+	MaxBackoff *metav1.Duration `json:"maxBackoff,omitempty"`
+
+	// JitterPercent extends every delay by a random duration of up to the percentage of it, so that the claims
+	// waiting for the same SandboxSet do not retry in lockstep (default: 0)
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	JitterPercent int32 `json:"jitterPercent,omitempty"`
 }
 
 type SandboxClaimInplaceUpdateOptions struct {
@@ -118,6 +579,8 @@ type SandboxClaimStatus struct {
 	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
 
 	// Phase represents the current phase of the claim
+	// PendingApproval: Waiting for the approval of a claim requiring approval
+	// Queued: Waiting in the claim queue of the SandboxSet to be admitted
 	// Claiming: In the process of claiming sandboxes
 	// Completed: Claim process finished (either all replicas claimed or timeout reached)
 	// +optional
@@ -127,12 +590,30 @@ type SandboxClaimStatus struct {
 	// +optional
 	Message string `json:"message,omitempty"`
 
+	// SandboxSetName is the SandboxSet picked by spec.templateSelector or spec.templates the claim claims sandboxes
+	// from. Not set for claims of a templateName.
+	// +optional
+	SandboxSetName string `json:"sandboxSetName,omitempty"`
+
+	// Templates counts the sandboxes claimed from each SandboxSet by claims of spec.templateSelector or
+	// spec.templates, which may claim from several of them. Not set for claims of a templateName.
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	Templates []SandboxClaimTemplateStatus `json:"templates,omitempty"`
+
 	// ClaimedReplicas indicates how many sandboxes are currently claimed (total)
 	// This is determined by querying sandboxes with matching ownerReference
 	// Only updated during Pending and Claiming phases
 	// +optional
 	ClaimedReplicas int32 `json:"claimedReplicas"`
 
+	// BoundReplicas is how many sandboxes are bound to the claim while claiming, including the ones not counted in
+	// claimedReplicas yet as they have not been Ready for spec.minReadySeconds. Not set without spec.minReadySeconds
+	// or once the claim is completed, when all the bound sandboxes are counted in claimedReplicas.
+	// +optional
+	BoundReplicas int32 `json:"boundReplicas,omitempty"`
+
 	// ClaimStartTime is the timestamp when claiming started
 	// Used for calculating timeout
 	// +optional
@@ -143,11 +624,133 @@ type SandboxClaimStatus struct {
 	// +optional
 	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
 
+	// LeaseExpireTime is when the lease of the claimed sandboxes expires, if the claim has spec.leaseDuration.
+	// Not set before the claim is completed, or after the lease expired and the sandboxes were released.
+	// +optional
+	LeaseExpireTime *metav1.Time `json:"leaseExpireTime,omitempty"`
+
+	// QueuedPosition is the 1-based position of the claim in the claim queue of the SandboxSet,
+	// when the SandboxSet queues claims with spec.claimQueue or limits concurrent claims with spec.maxConcurrentClaims.
+	// Not set when the claim is claiming sandboxes or completed.
+	// +optional
+	QueuedPosition *int32 `json:"queuedPosition,omitempty"`
+
+	// ETA estimates when the remaining replicas become available, when the pool is exhausted or the claim is queued
+	// but sandboxes are being created in it, so that callers can decide to wait or fall back. Not set otherwise.
+	// +optional
+	ETA *SandboxClaimETA `json:"eta,omitempty"`
+
+	// DryRun is the result of a claim with spec.dryRun, not set for the other claims
+	// +optional
+	DryRun *SandboxClaimDryRunStatus `json:"dryRun,omitempty"`
+
 	// Conditions represent the current state of the SandboxClaim
 	// +optional
 	// +listType=map
 	// +listMapKey=type
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// History records the last transitions of the claim, oldest first, so that the story of a claim can be told
+	// after its Events have expired. Repeated transitions, like retries on shortage, are recorded once.
+	// +optional
+	// +listType=atomic
+	// +kubebuilder:validation:MaxItems=20
+	History []SandboxClaimHistoryEntry `json:"history,omitempty"`
+
+	// ClaimedSandboxes lists the sandboxes currently claimed by the claim and not being deleted, sorted by name, so
+	// that consumers of the claim need not list them by labels. At most SandboxClaimClaimedSandboxesLimit sandboxes are
+	// listed.
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	// +kubebuilder:validation:MaxItems=100
+	ClaimedSandboxes []SandboxClaimClaimedSandbox `json:"claimedSandboxes,omitempty"`
+}
+
+// SandboxClaimTemplateStatus counts the sandboxes a SandboxClaim claimed from a SandboxSet
+type SandboxClaimTemplateStatus struct {
+	// Name is the name of the SandboxSet
+	Name string `json:"name"`
+
+	// ClaimedReplicas is how many sandboxes claimed by the claim are of the SandboxSet
+	ClaimedReplicas int32 `json:"claimedReplicas"`
+}
+
+// SandboxClaimClaimedSandboxesLimit is the max number of sandboxes listed in the status of a SandboxClaim
+const SandboxClaimClaimedSandboxesLimit = 100
+
+// SandboxClaimClaimedSandbox is a sandbox claimed by a SandboxClaim
+type SandboxClaimClaimedSandbox struct {
+	// Name is the name of the sandbox, in the namespace of the claim
+	Name string `json:"name"`
+
+	// UID is the UID of the sandbox
+	UID types.UID `json:"uid"`
+
+	// SandboxID is the ID the sandbox is addressed by, e.g. in the E2B API and the routes of the sandbox gateway
+	// +optional
+	SandboxID string `json:"sandboxID,omitempty"`
+
+	// PodIP is the IP of the pod of the sandbox, not set before the pod is scheduled
+	// +optional
+	PodIP string `json:"podIP,omitempty"`
+
+	// Ready tells whether the sandbox is ready
+	Ready bool `json:"ready"`
+}
+
+// SandboxClaimETA is the estimate of when a claim waiting for sandboxes being created can be fulfilled
+type SandboxClaimETA struct {
+	// EstimatedTime is when the sandboxes being created for the remaining replicas are expected to be ready,
+	// estimated with the p90 of the recent warmup latencies of the pool
+	EstimatedTime metav1.Time `json:"estimatedTime"`
+
+	// CreatingReplicas is the number of unclaimed sandboxes being created in the pool. The remaining replicas beyond it
+	// are not covered by the estimate.
+	CreatingReplicas int32 `json:"creatingReplicas"`
+
+	// WarmupLatencyP50 is the median of the recent warmup latencies of the pool
+	WarmupLatencyP50 metav1.Duration `json:"warmupLatencyP50"`
+
+	// WarmupLatencyP90 is the 90th percentile of the recent warmup latencies of the pool
+	WarmupLatencyP90 metav1.Duration `json:"warmupLatencyP90"`
+}
+
+// SandboxClaimDryRunStatus is how many sandboxes a dry-run claim could claim when it was checked
+type SandboxClaimDryRunStatus struct {
+	// AvailableReplicas is the number of available sandboxes of the SandboxSet matching the claim, e.g. its selector,
+	// regions and template revision. The sandboxes that would be created with createOnNoStock are not counted.
+	AvailableReplicas int32 `json:"availableReplicas"`
+
+	// ClaimableReplicas is the number of replicas of the claim that would be claimed, at most its replicas
+	ClaimableReplicas int32 `json:"claimableReplicas"`
+
+	// CheckTime is when the sandboxes were counted
+	CheckTime metav1.Time `json:"checkTime"`
+}
+
+// SandboxClaimHistoryLimit is the default max number of entries kept in the history of a SandboxClaim
+const SandboxClaimHistoryLimit = 20
+
+// SandboxClaimHistoryEntry is a transition of a SandboxClaim
+type SandboxClaimHistoryEntry struct {
+	// Time is when the transition happened
+	Time metav1.Time `json:"time"`
+
+	// Phase is the phase of the claim after the transition
+	// +optional
+	Phase SandboxClaimPhase `json:"phase,omitempty"`
+
+	// Reason is a CamelCase reason of the transition, e.g. SandboxClaimed, NoAvailableSandboxes, TimeoutReached
+	Reason string `json:"reason"`
+
+	// Message provides human-readable details about the transition
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// ClaimedReplicas is the number of claimed sandboxes after the transition
+	// +optional
+	ClaimedReplicas int32 `json:"claimedReplicas"`
 }
 
 // SandboxClaimPhase defines the phase of SandboxClaim
@@ -155,8 +758,13 @@ type SandboxClaimStatus struct {
 type SandboxClaimPhase string
 
 const (
-	SandboxClaimPhaseClaiming  SandboxClaimPhase = "Claiming"
-	SandboxClaimPhaseCompleted SandboxClaimPhase = "Completed"
+	SandboxClaimPhasePendingApproval SandboxClaimPhase = "PendingApproval"
+	SandboxClaimPhaseQueued          SandboxClaimPhase = "Queued"
+	SandboxClaimPhaseClaiming        SandboxClaimPhase = "Claiming"
+	SandboxClaimPhaseCompleted       SandboxClaimPhase = "Completed"
+	// SandboxClaimPhaseFailed is the terminal phase of a claim timed out with spec.onTimeout Fail, handled like
+	// Completed otherwise, e.g. its TTL and lease
+	SandboxClaimPhaseFailed SandboxClaimPhase = "Failed"
 )
 
 // SandboxClaimConditionType defines condition types
@@ -167,6 +775,57 @@ const (
 	SandboxClaimConditionCompleted SandboxClaimConditionType = "Completed"
 	// SandboxClaimConditionTimedOut indicates if the claim has timed out
 	SandboxClaimConditionTimedOut SandboxClaimConditionType = "TimedOut"
+	// SandboxClaimConditionReplicaTimedOut indicates if binding a single sandbox exceeded the per-replica timeout
+	SandboxClaimConditionReplicaTimedOut SandboxClaimConditionType = "ReplicaTimedOut"
+	// SandboxClaimConditionTimeoutHandled records the spec.onTimeout behavior applied to a timed out claim as its
+	// reason, i.e. KeepClaimed, ReleaseClaimed, BestEffort or Fail
+	SandboxClaimConditionTimeoutHandled SandboxClaimConditionType = "TimeoutHandled"
+	// SandboxClaimConditionReplicaLost indicates that claimed sandboxes died and are being replaced
+	SandboxClaimConditionReplicaLost SandboxClaimConditionType = "ReplicaLost"
+	// SandboxClaimConditionReleased indicates that the claimed sandboxes have been released by the controller
+	SandboxClaimConditionReleased SandboxClaimConditionType = "Released"
+	// SandboxClaimConditionApproved records the approval decision of a claim requiring approval
+	SandboxClaimConditionApproved SandboxClaimConditionType = "Approved"
+	// SandboxClaimConditionCanceled indicates that the claim was canceled by the agents.kruise.io/cancel annotation
+	SandboxClaimConditionCanceled SandboxClaimConditionType = "Canceled"
+	// SandboxClaimConditionFeatureDisabled indicates that the unfinished claim is parked, as the SandboxClaim feature
+	// gate of the controller is disabled. It turns False once the controller is enabled again and adopts the claim.
+	SandboxClaimConditionFeatureDisabled SandboxClaimConditionType = "FeatureDisabled"
+	// SandboxClaimConditionPreempted indicates that claimed sandboxes were released for a claim of a higher priority,
+	// its message records the preempting claim and how many sandboxes were released
+	SandboxClaimConditionPreempted SandboxClaimConditionType = "Preempted"
+	// SandboxClaimConditionLeaseExpired indicates that the lease of spec.leaseDuration expired without renewal and the
+	// claimed sandboxes were released
+	SandboxClaimConditionLeaseExpired SandboxClaimConditionType = "LeaseExpired"
+	// SandboxClaimConditionPaused indicates that claiming is suspended by spec.paused, it turns False once resumed
+	SandboxClaimConditionPaused SandboxClaimConditionType = "Paused"
+	// SandboxClaimConditionQuotaExceeded indicates that claiming is stopped as the namespace reached the
+	// maxClaimedReplicas of its SandboxQuota, it turns False once the quota allows claiming again
+	SandboxClaimConditionQuotaExceeded SandboxClaimConditionType = "QuotaExceeded"
+	// SandboxClaimConditionProgressing is True while the claim is making its way to claim all its replicas, i.e. queued
+	// or claiming, and False once it stopped, because it waits for an approval, is paused or is completed
+	SandboxClaimConditionProgressing SandboxClaimConditionType = "Progressing"
+	// SandboxClaimConditionReplicasReady is True once all the replicas of the claim are claimed. Together with
+	// Progressing it tells generic tooling whether the claim is healthy, in progress or stopped short of its replicas.
+	SandboxClaimConditionReplicasReady SandboxClaimConditionType = "ReplicasReady"
+	// SandboxClaimConditionNotified indicates that the finished claim was posted to spec.notifications.url, it is False
+	// while the delivery fails
+	SandboxClaimConditionNotified SandboxClaimConditionType = "Notified"
+)
+
+const (
+	// SandboxClaimConditionProgressing Reason
+	SandboxClaimProgressingReasonQueued          = "Queued"
+	SandboxClaimProgressingReasonClaiming        = "Claiming"
+	SandboxClaimProgressingReasonPendingApproval = "PendingApproval"
+	SandboxClaimProgressingReasonPaused          = "Paused"
+	SandboxClaimProgressingReasonCompleted       = "Completed"
+	SandboxClaimProgressingReasonFailed          = "Failed"
+
+	// SandboxClaimConditionReplicasReady Reason
+	SandboxClaimReplicasReadyReasonAllReplicasClaimed   = "AllReplicasClaimed"
+	SandboxClaimReplicasReadyReasonClaimingReplicas     = "ClaimingReplicas"
+	SandboxClaimReplicasReadyReasonInsufficientReplicas = "InsufficientReplicas"
 )
 
 // +genclient
@@ -181,6 +840,7 @@ const (
 // +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
 // SandboxClaim is the Schema for the sandboxclaims API
+// +kubebuilder:validation:XValidation:rule="!has(self.spec) || !has(self.spec.ordinalAliases) || !self.spec.ordinalAliases || (size(self.metadata.name) <= 56 && !self.metadata.name.contains('.'))",message="ordinalAliases requires a name of at most 56 characters without dots"
 type SandboxClaim struct {
 	metav1.TypeMeta `json:",inline"`
 
@@ -0,0 +1,66 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SandboxQuotaSpec defines the desired state of SandboxQuota
+type SandboxQuotaSpec struct {
+	// MaxClaimedReplicas limits the total number of sandboxes claimed by the SandboxClaims of the namespace. Claims
+	// stop claiming with the QuotaExceeded condition once the namespace reaches it, and go on as sandboxes are
+	// released. Sandboxes already claimed are never released for it.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Minimum=0
+	MaxClaimedReplicas int32 `json:"maxClaimedReplicas"`
+}
+
+// +genclient
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:path=sandboxquotas,shortName={sbq},singular=sandboxquota
+// +kubebuilder:printcolumn:name="Max Claimed",type="integer",JSONPath=".spec.maxClaimedReplicas"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// SandboxQuota limits the sandboxes claimed by the SandboxClaims of its namespace. The most restrictive one applies
+// if a namespace has several.
+type SandboxQuota struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// metadata is a standard object metadata
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty,omitzero"`
+
+	// spec defines the desired state of SandboxQuota
+	// +required
+	Spec SandboxQuotaSpec `json:"spec"`
+}
+
+// +kubebuilder:object:root=true
+
+// SandboxQuotaList contains a list of SandboxQuota
+type SandboxQuotaList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []SandboxQuota `json:"items"`
+}
+
+var SandboxQuotaControllerKind = GroupVersion.WithKind("SandboxQuota")
+
+func init() {
+	SchemeBuilder.Register(&SandboxQuota{}, &SandboxQuotaList{})
+}
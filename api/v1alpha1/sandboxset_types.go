@@ -17,6 +17,7 @@ limitations under the License.
 package v1alpha1
 
 import (
+	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 )
@@ -30,9 +31,17 @@ const (
 	LabelSandboxTemplate = InternalPrefix + "sandbox-template"
 	// LabelSandboxIsClaimed indicates whether the sandbox has been claimed by user
 	LabelSandboxIsClaimed = InternalPrefix + "sandbox-claimed"
-	// LabelSandboxClaimName indicates the name of the SandboxClaim that claimed this sandbox
+	// LabelSandboxClaimName indicates the name of the SandboxClaim that claimed this sandbox, also set on the pod template
 	LabelSandboxClaimName = InternalPrefix + "claim-name"
-	LabelTemplateHash     = InternalPrefix + "template-hash"
+	// LabelSandboxClaimUID indicates the UID of the SandboxClaim that claimed this sandbox, telling it from a claim
+	// recreated with the same name
+	LabelSandboxClaimUID = InternalPrefix + "claim-uid"
+	LabelTemplateHash    = InternalPrefix + "template-hash"
+	// LabelSandboxRegion is the region of the sandboxes of a SandboxSet. Set on the SandboxSet, it is propagated to
+	// the sandboxes created by it, so that claims can select the sandboxes by region, see RegionPolicy.
+	LabelSandboxRegion = InternalPrefix + "region"
+	// LabelSandboxNodePool is the name of the node pool in spec.nodePools of the SandboxSet the sandbox is placed in
+	LabelSandboxNodePool = InternalPrefix + "node-pool"
 
 	AnnotationLock               = InternalPrefix + "lock"
 	AnnotationOwner              = InternalPrefix + "owner"
@@ -42,6 +51,34 @@ const (
 	AnnotationSandboxID          = InternalPrefix + "sandbox-id"
 )
 
+const (
+	// AnnotationWorkloadClass classifies the claims of a SandboxSet to prioritize reconciling them when the queue of
+	// the SandboxClaim controller is deep, either WorkloadClassInteractive or WorkloadClassBatch. Claims of pools
+	// without it are of the normal priority. Set on a SandboxClaim, it overrides the class of the pool for the claim,
+	// which also decides whether the claim may take the sandboxes reserved by spec.interactiveReserve.
+	AnnotationWorkloadClass = InternalPrefix + "workload-class"
+
+	// WorkloadClassInteractive pools serve users waiting for the sandboxes, their claims are reconciled first
+	WorkloadClassInteractive = "Interactive"
+	// WorkloadClassBatch pools serve background jobs, their claims are reconciled last
+	WorkloadClassBatch = "Batch"
+)
+
+// Right-sizing recommendations written on SandboxSets by the pool analyzer of the SandboxSet controller, from the
+// claims and the available sandboxes of the pool observed in the last day. They are only advisory.
+const (
+	// AnnotationRecommendedReplicas is the replicas covering the claims of the pool in 95% of the refill windows
+	AnnotationRecommendedReplicas = InternalPrefix + "recommended-replicas"
+	// AnnotationRecommendedStandby is the number of paused sandboxes to keep beyond the recommended replicas to
+	// absorb the peak bursts of claims by resuming them instead of creating new ones
+	AnnotationRecommendedStandby = InternalPrefix + "recommended-standby"
+	// AnnotationRecommendedSandboxTTL is the p95 of how long the claimed sandboxes of the pool are kept, as a
+	// duration like "15m0s", which suits their shutdown time
+	AnnotationRecommendedSandboxTTL = InternalPrefix + "recommended-sandbox-ttl"
+	// AnnotationRightSizingReport summarizes the observations the recommendations are made from
+	AnnotationRightSizingReport = InternalPrefix + "rightsizing-report"
+)
+
 const (
 	SandboxStateCreating  = "creating"
 	SandboxStateAvailable = "available"
@@ -53,10 +90,21 @@ const (
 var SandboxSetControllerKind = GroupVersion.WithKind("SandboxSet")
 
 // SandboxSetSpec defines the desired state of SandboxSet
+// +kubebuilder:validation:XValidation:rule="!has(self.autoscaling) || self.autoscaling.maxReplicas >= self.replicas",message="autoscaling.maxReplicas must not be less than replicas"
 type SandboxSetSpec struct {
 	// Replicas is the number of unused sandboxes, including available and creating ones.
+	// +kubebuilder:validation:Minimum=0
 	Replicas int32 `json:"replicas"`
 
+	// MinAvailable is the number of available, i.e. ready and unclaimed, sandboxes the SandboxSet keeps at least,
+	// however many get claimed and whatever replicas and autoscaling scale it to. Sandboxes short of it, e.g. once
+	// sandboxes are claimed or die, are created right away regardless of scaleStrategy.maxUnavailable, so that the
+	// buffer is refilled as soon as possible. Sandboxes being created count toward it for 5 minutes, the ones still
+	// not available by then are stalled and replaced, at most minAvailable of them. Not kept if not set.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	MinAvailable int32 `json:"minAvailable,omitempty"`
+
 	// PersistentContents indicates resume pod with persistent content, Enum: ip, memory, filesystem
 	PersistentContents []string `json:"persistentContents,omitempty"`
 
@@ -64,11 +112,191 @@ type SandboxSetSpec struct {
 	// +optional
 	Runtimes []RuntimeConfig `json:"runtimes,omitempty"`
 
+	// ReadinessProbe - Custom readiness check propagated to every sandbox created by this SandboxSet.
+	// +optional
+	ReadinessProbe *SandboxReadinessProbe `json:"readinessProbe,omitempty"`
+
 	EmbeddedSandboxTemplate `json:",inline"`
 
+	// TemplateOverlay is strategic merged onto the pod template of the SandboxTemplate referenced by templateRef,
+	// e.g. containers and env are merged by name, so that pools sharing a base template only declare their
+	// differences such as extra env, resources or sidecars. Sandboxes are then created with the resolved template,
+	// which also inherits persistentContents, runtimes and volumeClaimTemplates of the SandboxTemplate unless set here.
+	// Requires templateRef.
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +kubebuilder:validation:Schemaless
+	// +optional
+	TemplateOverlay *v1.PodTemplateSpec `json:"templateOverlay,omitempty"`
+
 	// ScaleStrategy indicates the ScaleStrategy that will be employed to
 	// create and delete Sandboxes in the SandboxSet.
 	ScaleStrategy SandboxSetScaleStrategy `json:"scaleStrategy,omitempty"`
+
+	// MaxConcurrentClaims limits how many SandboxClaims may claim sandboxes from this SandboxSet at the same time.
+	// Excess claims wait in a FIFO queue ordered by creation time, and report their place in status.queuedPosition.
+	// Unlimited if not set.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	MaxConcurrentClaims *int32 `json:"maxConcurrentClaims,omitempty"`
+
+	// ClaimQueue queues the SandboxClaims of this SandboxSet beyond its available sandboxes in the Queued phase, with
+	// their position and ETA in status, and admits them to claim in order as sandboxes become available, instead of
+	// having all of them poll the pool. Claiming claims also leave the available sandboxes to the claims ahead of
+	// them in the same order, so that earlier claims are fulfilled first and none starves. The order is derived from
+	// the claims themselves, so it survives restarts of the controller. Not queued if not set.
+	// +optional
+	ClaimQueue *SandboxSetClaimQueue `json:"claimQueue,omitempty"`
+
+	// MaxReplicasPerClaim limits the replicas of each SandboxClaim of this SandboxSet, so that a single huge claim
+	// cannot camp on the pool. Enforced by the validating webhook when claims are created. Unlimited if not set.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	MaxReplicasPerClaim *int32 `json:"maxReplicasPerClaim,omitempty"`
+
+	// MaxOutstandingClaims limits how many SandboxClaims of this SandboxSet may be outstanding, i.e. not completed,
+	// in its namespace. New claims beyond the limit are rejected by the validating webhook. Unlimited if not set.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	MaxOutstandingClaims *int32 `json:"maxOutstandingClaims,omitempty"`
+
+	// InteractiveReserve reserves a share of the available sandboxes for claims of the Interactive workload class,
+	// either an absolute number or a percentage rounded up, e.g. "20%". Claims of the Batch workload class only take
+	// the available sandboxes beyond the reservation, so that latency sensitive users are never starved by large
+	// batch claims. Not reserved if not set.
+	// +optional
+	// +kubebuilder:validation:XIntOrString
+	InteractiveReserve *intstr.IntOrString `json:"interactiveReserve,omitempty"`
+
+	// Paused stops the SandboxSet from creating sandboxes, neither to keep replicas nor for claims creating sandboxes
+	// on no stock, e.g. to stop a bad template from spreading during an incident. Claimed sandboxes are not affected,
+	// and unclaimed ones are kept to be claimed unless drainOnPause is set. Reported by the Paused condition.
+	// +optional
+	Paused bool `json:"paused,omitempty"`
+
+	// DrainOnPause deletes the unclaimed sandboxes of the SandboxSet while paused, regardless of maintenance windows,
+	// so that no more sandboxes of a bad template can be claimed.
+	// +optional
+	DrainOnPause bool `json:"drainOnPause,omitempty"`
+
+	// SLO specifies the service level objectives of the SandboxSet. Their compliance is evaluated over a sliding window
+	// by the controller, reported in status.slo and by the SLOViolated condition once an error budget is exhausted.
+	// +optional
+	SLO *SandboxSetSLO `json:"slo,omitempty"`
+
+	// NodePools distributes the unclaimed sandboxes of the SandboxSet across pools of nodes by weight, e.g. 70% on spot
+	// and 30% on on-demand nodes. The weighted shares only go to the pools with ready nodes, and are rebalanced as
+	// nodes come and go, while the minReplicas of each pool are always kept. Sandboxes are placed in a pool by merging
+	// its nodeSelector and tolerations into their pod template, so a templateRef requires a templateOverlay, which
+	// may be empty. Requires the SandboxSetNodePools feature gate. Not distributed if not set.
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	// +kubebuilder:validation:MaxItems=16
+	NodePools []SandboxSetNodePool `json:"nodePools,omitempty"`
+
+	// Autoscaling grows the SandboxSet beyond replicas while its queued and claiming SandboxClaims need more sandboxes
+	// than it has unclaimed, up to maxReplicas, and shrinks it back once the claim pressure has dropped for the
+	// cooldown. Replicas is kept as the minimum. The replicas scaled to are reported in status.autoscaling. Requires
+	// the SandboxSetAutoscaling feature gate. Not autoscaled if not set.
+	// +optional
+	Autoscaling *SandboxSetAutoscaling `json:"autoscaling,omitempty"`
+}
+
+// SandboxSetAutoscaling defines how a SandboxSet grows with the pressure of its SandboxClaims
+type SandboxSetAutoscaling struct {
+	// MaxReplicas is the most unclaimed sandboxes the SandboxSet grows to, at least replicas.
+	// +kubebuilder:validation:Minimum=0
+	MaxReplicas int32 `json:"maxReplicas"`
+
+	// ScaleDownCooldown is how long the SandboxSet keeps its grown replicas after the claim pressure drops, so that
+	// bursts of claims coming in waves do not wait for the pool to warm up again.
+	// +optional
+	// +kubebuilder:default="5m"
+	ScaleDownCooldown *metav1.Duration `json:"scaleDownCooldown,omitempty"`
+}
+
+// SandboxSetNodePool defines a pool of nodes the sandboxes of a SandboxSet are distributed to
+type SandboxSetNodePool struct {
+	// Name identifies the pool, and is set on its sandboxes by the agents.kruise.io/node-pool label.
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=63
+	Name string `json:"name"`
+
+	// NodeSelector selects the nodes of the pool. It is merged into the nodeSelector of the sandboxes placed in it.
+	NodeSelector map[string]string `json:"nodeSelector"`
+
+	// Tolerations are appended to the tolerations of the sandboxes placed in the pool, e.g. to run on tainted spot
+	// nodes.
+	// +optional
+	Tolerations []v1.Toleration `json:"tolerations,omitempty"`
+
+	// Weight is the relative share of the pool in the replicas beyond the minReplicas of all pools.
+	// +optional
+	// +kubebuilder:default=1
+	// +kubebuilder:validation:Minimum=0
+	Weight int32 `json:"weight,omitempty"`
+
+	// MinReplicas is the number of sandboxes always placed in the pool, even if it has no ready nodes, e.g. so that
+	// a cluster autoscaler scales it up from zero. Limited by spec.replicas in the order of the pools.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	MinReplicas int32 `json:"minReplicas,omitempty"`
+}
+
+// ClaimQueueOrdering is the order in which queued SandboxClaims are admitted
+// +enum
+type ClaimQueueOrdering string
+
+const (
+	// ClaimQueueOrderingFIFO admits claims by creation time
+	ClaimQueueOrderingFIFO ClaimQueueOrdering = "FIFO"
+	// ClaimQueueOrderingPriority admits claims of a higher spec.priority first, then of the Interactive workload class
+	// first and of the Batch workload class last, each by creation time
+	ClaimQueueOrderingPriority ClaimQueueOrdering = "Priority"
+)
+
+// SandboxSetClaimQueue defines the claim queue of a SandboxSet
+type SandboxSetClaimQueue struct {
+	// Ordering is the order in which queued claims are admitted, FIFO or Priority.
+	// +optional
+	// +kubebuilder:default=FIFO
+	// +kubebuilder:validation:Enum=FIFO;Priority
+	Ordering ClaimQueueOrdering `json:"ordering,omitempty"`
+}
+
+// SandboxSetSLO defines the service level objectives of a SandboxSet. Objectives are percentages like "99.5".
+type SandboxSetSLO struct {
+	// ClaimLatency is the objective of the latency of the SandboxClaims of the SandboxSet, from the start of claiming
+	// to all replicas being claimed. Timed out claims never meet it.
+	// +optional
+	ClaimLatency *SandboxSetLatencyObjective `json:"claimLatency,omitempty"`
+
+	// WarmupSuccess is the percentage of the sandboxes of the SandboxSet expected to become available, instead of
+	// dying while being created.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^(100|[0-9]{1,2}(\.[0-9]+)?)$`
+	WarmupSuccess string `json:"warmupSuccess,omitempty"`
+
+	// ResumeLatency is the objective of the latency of resuming the paused sandboxes claimed from the SandboxSet, from
+	// spec.paused being unset to the sandbox being ready again.
+	// +optional
+	ResumeLatency *SandboxSetLatencyObjective `json:"resumeLatency,omitempty"`
+
+	// Window is the sliding window over which the compliance is evaluated, at most 24h.
+	// +optional
+	// +kubebuilder:default="1h"
+	// +kubebuilder:validation:XValidation:rule="duration(self) > duration('0s') && duration(self) <= duration('24h')",message="window must be positive and at most 24h"
+	Window *metav1.Duration `json:"window,omitempty"`
+}
+
+// SandboxSetLatencyObjective defines the objective of a latency
+type SandboxSetLatencyObjective struct {
+	// Target is the latency expected to be met
+	Target metav1.Duration `json:"target"`
+
+	// Objective is the percentage of the latencies expected to meet the target
+	// +kubebuilder:validation:Pattern=`^(100|[0-9]{1,2}(\.[0-9]+)?)$`
+	Objective string `json:"objective"`
 }
 
 // SandboxSetScaleStrategy defines strategies for sandboxes scale.
@@ -92,7 +320,18 @@ type SandboxSetStatus struct {
 	// AvailableReplicas is the number of available sandboxes, which are ready to be claimed.
 	AvailableReplicas int32 `json:"availableReplicas"`
 
-	// UpdateRevision is the template-hash calculated from `spec.template`.
+	// StalledReplicas is the number of sandboxes still not available 5 minutes after they were created, which do
+	// not count toward spec.minAvailable. Only counted if spec.minAvailable is set.
+	// +optional
+	StalledReplicas int32 `json:"stalledReplicas,omitempty"`
+
+	// ClaimedReplicas is the number of alive sandboxes that have been claimed from this SandboxSet.
+	// Claimed sandboxes are no longer controlled by the SandboxSet and are not counted in Replicas.
+	// +optional
+	ClaimedReplicas int32 `json:"claimedReplicas"`
+
+	// UpdateRevision is the template-hash calculated from `spec.template`, or from the template resolved from
+	// `spec.templateRef` and `spec.templateOverlay`.
 	UpdateRevision string `json:"updateRevision,omitempty"`
 
 	// conditions represent the current state of the SandboxSet resource.
@@ -108,16 +347,124 @@ type SandboxSetStatus struct {
 	// duplication for CRDs that do not support structural schemas.
 	// +optional
 	Selector string `json:"selector,omitempty"`
+
+	// SLO reports the compliance of the service level objectives in spec.slo over their window
+	// +optional
+	SLO *SandboxSetSLOStatus `json:"slo,omitempty"`
+
+	// NodePools reports the distribution of the sandboxes across spec.nodePools
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	NodePools []SandboxSetNodePoolStatus `json:"nodePools,omitempty"`
+
+	// Autoscaling reports the replicas the SandboxSet is scaled to by spec.autoscaling
+	// +optional
+	Autoscaling *SandboxSetAutoscalingStatus `json:"autoscaling,omitempty"`
+}
+
+// SandboxSetAutoscalingStatus reports the autoscaling of a SandboxSet
+type SandboxSetAutoscalingStatus struct {
+	// DesiredReplicas is the number of unclaimed sandboxes the SandboxSet is scaled to, between spec.replicas and
+	// spec.autoscaling.maxReplicas
+	DesiredReplicas int32 `json:"desiredReplicas"`
+
+	// PendingReplicas is the number of sandboxes still needed by the queued and claiming SandboxClaims of the
+	// SandboxSet
+	PendingReplicas int32 `json:"pendingReplicas"`
+
+	// LastScaleUpTime is the last time the claim pressure required the desired replicas, the cooldown of scaling
+	// down starts from it
+	// +optional
+	LastScaleUpTime *metav1.Time `json:"lastScaleUpTime,omitempty"`
 }
 
+// SandboxSetNodePoolStatus reports the sandboxes of a node pool of a SandboxSet
+type SandboxSetNodePoolStatus struct {
+	// Name of the pool in spec.nodePools
+	Name string `json:"name"`
+
+	// DesiredReplicas is the number of unclaimed sandboxes the pool is expected to have
+	DesiredReplicas int32 `json:"desiredReplicas"`
+
+	// Replicas is the number of creating and available sandboxes in the pool
+	Replicas int32 `json:"replicas"`
+
+	// AvailableReplicas is the number of available sandboxes in the pool
+	AvailableReplicas int32 `json:"availableReplicas"`
+
+	// ReadyNodes is the number of ready and schedulable nodes selected by the pool
+	ReadyNodes int32 `json:"readyNodes"`
+}
+
+// SandboxSetSLOStatus reports the compliance of the service level objectives of a SandboxSet
+type SandboxSetSLOStatus struct {
+	// ClaimLatency reports the compliance of spec.slo.claimLatency
+	// +optional
+	ClaimLatency *SandboxSetObjectiveStatus `json:"claimLatency,omitempty"`
+
+	// WarmupSuccess reports the compliance of spec.slo.warmupSuccess
+	// +optional
+	WarmupSuccess *SandboxSetObjectiveStatus `json:"warmupSuccess,omitempty"`
+
+	// ResumeLatency reports the compliance of spec.slo.resumeLatency
+	// +optional
+	ResumeLatency *SandboxSetObjectiveStatus `json:"resumeLatency,omitempty"`
+}
+
+// SandboxSetObjectiveStatus reports the compliance of a service level objective over its window
+type SandboxSetObjectiveStatus struct {
+	// Total is the number of events observed in the window
+	Total int32 `json:"total"`
+
+	// Bad is the number of events in the window not meeting the objective
+	Bad int32 `json:"bad"`
+
+	// BurnRate is the rate at which the error budget is consumed, i.e. the ratio of bad events to the ratio allowed
+	// by the objective, formatted with two decimals. The error budget of the window is exhausted above 1.
+	BurnRate string `json:"burnRate"`
+}
+
+// SandboxSetConditionType defines condition types of SandboxSet
+type SandboxSetConditionType string
+
+const (
+	// SandboxSetConditionReady indicates whether all desired sandboxes of the pool are available to be claimed
+	SandboxSetConditionReady SandboxSetConditionType = "Ready"
+	// SandboxSetConditionPaused indicates that the SandboxSet is paused by spec.paused
+	SandboxSetConditionPaused SandboxSetConditionType = "Paused"
+	// SandboxSetConditionSLOViolated indicates that the error budget of a service level objective in spec.slo is
+	// exhausted in its window
+	SandboxSetConditionSLOViolated SandboxSetConditionType = "SLOViolated"
+)
+
+const (
+	SandboxSetReadyReasonAllAvailable     = "AllReplicasAvailable"
+	SandboxSetReadyReasonReplicasNotReady = "ReplicasNotAvailable"
+)
+
+const (
+	SandboxSetPausedReasonPaused   = "Paused"
+	SandboxSetPausedReasonDraining = "Draining"
+	SandboxSetPausedReasonDrained  = "Drained"
+)
+
+const (
+	SandboxSetSLOReasonBudgetExhausted = "ErrorBudgetExhausted"
+	SandboxSetSLOReasonWithinBudget    = "WithinErrorBudget"
+)
+
 // +genclient
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
 // +kubebuilder:subresource:scale:specpath=.spec.replicas,statuspath=.status.replicas,selectorpath=.status.selector
 // +kubebuilder:resource:path=sandboxsets,shortName={sbs},singular=sandboxset
 // +kubebuilder:storageversion
+// +kubebuilder:printcolumn:name="Desired",type="integer",JSONPath=".spec.replicas"
 // +kubebuilder:printcolumn:name="Replicas",type="integer",JSONPath=".status.replicas"
 // +kubebuilder:printcolumn:name="Available",type="integer",JSONPath=".status.availableReplicas"
+// +kubebuilder:printcolumn:name="Claimed",type="integer",JSONPath=".status.claimedReplicas"
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
 // +kubebuilder:printcolumn:name="UpdateRevision",type="string",JSONPath=".status.updateRevision"
 // +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
@@ -21,6 +21,16 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+const (
+	// LabelShareableSecret marks a Secret of the system namespace that SandboxTemplates of other namespaces may
+	// reference by name. It is copied into the namespaces of the templates referencing it that are used by SandboxSets.
+	LabelShareableSecret = InternalPrefix + "shareable-secret"
+	// LabelPropagatedSecret marks a copy of a shareable Secret, which is kept in sync with its source
+	LabelPropagatedSecret = InternalPrefix + "propagated-secret"
+	// AnnotationSecretHash is the hash of the type and data of the shareable Secret last copied into a propagated one
+	AnnotationSecretHash = InternalPrefix + "secret-hash"
+)
+
 // SandboxTemplateSpec defines the desired state of SandboxTemplate
 type SandboxTemplateSpec struct {
 	// Template describes the pods that will be created.
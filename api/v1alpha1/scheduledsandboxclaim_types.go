@@ -0,0 +1,147 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// LabelScheduledSandboxClaimName indicates the name of the ScheduledSandboxClaim that created the SandboxClaim
+	LabelScheduledSandboxClaimName = InternalPrefix + "scheduled-claim-name"
+	// AnnotationScheduledTime records the time a SandboxClaim created by a ScheduledSandboxClaim was scheduled at
+	AnnotationScheduledTime = InternalPrefix + "scheduled-time"
+)
+
+// ScheduledSandboxClaimSpec defines the desired state of ScheduledSandboxClaim
+type ScheduledSandboxClaimSpec struct {
+	// Schedule is the cron schedule the SandboxClaims are created on, e.g. "0 8 * * 1-5" to warm up sandboxes before
+	// business hours on weekdays
+	// +kubebuilder:validation:MinLength=1
+	Schedule string `json:"schedule"`
+
+	// TimeZone is the name of the time zone of the schedule, e.g. "Asia/Shanghai". Defaults to the time zone of the
+	// controller.
+	// +optional
+	TimeZone *string `json:"timeZone,omitempty"`
+
+	// StartingDeadlineSeconds is how late a SandboxClaim may still be created after its scheduled time, e.g. after
+	// the controller was down. Missed schedules older than it are skipped. No deadline by default.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	StartingDeadlineSeconds *int64 `json:"startingDeadlineSeconds,omitempty"`
+
+	// Suspend stops creating SandboxClaims on schedule, the ones already created are left as they are
+	// +optional
+	Suspend bool `json:"suspend,omitempty"`
+
+	// ClaimTemplate is the template of the SandboxClaims created on schedule. The fields immutable on a SandboxClaim
+	// are immutable in the template as well.
+	ClaimTemplate SandboxClaimTemplate `json:"claimTemplate"`
+
+	// HistoryLimit is how many of the completed SandboxClaims created on schedule are kept, the older ones are
+	// deleted, releasing their sandboxes with their deletionPolicy. Defaults to 3.
+	// +optional
+	// +kubebuilder:default=3
+	// +kubebuilder:validation:Minimum=0
+	HistoryLimit *int32 `json:"historyLimit,omitempty"`
+}
+
+// SandboxClaimTemplate describes the SandboxClaims created by a ScheduledSandboxClaim
+type SandboxClaimTemplate struct {
+	// Labels are set on the SandboxClaims created from the template
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Annotations are set on the SandboxClaims created from the template
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// Spec is the spec of the SandboxClaims created from the template
+	Spec SandboxClaimSpec `json:"spec"`
+}
+
+// ScheduledSandboxClaimStatus defines the observed state of ScheduledSandboxClaim
+type ScheduledSandboxClaimStatus struct {
+	// LastScheduleTime is the last time a SandboxClaim was scheduled at
+	// +optional
+	LastScheduleTime *metav1.Time `json:"lastScheduleTime,omitempty"`
+
+	// LastClaimName is the name of the SandboxClaim created for the LastScheduleTime
+	// +optional
+	LastClaimName string `json:"lastClaimName,omitempty"`
+
+	// Conditions represent the current state of the ScheduledSandboxClaim, i.e. InvalidSchedule
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// ScheduledSandboxClaimConditionType defines condition types
+type ScheduledSandboxClaimConditionType string
+
+const (
+	// ScheduledSandboxClaimConditionInvalidSchedule indicates that the schedule or the time zone cannot be parsed,
+	// so that no SandboxClaim is created until they are fixed
+	ScheduledSandboxClaimConditionInvalidSchedule ScheduledSandboxClaimConditionType = "InvalidSchedule"
+)
+
+// +genclient
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=scheduledsandboxclaims,shortName={ssbc},singular=scheduledsandboxclaim
+// +kubebuilder:printcolumn:name="Schedule",type="string",JSONPath=".spec.schedule"
+// +kubebuilder:printcolumn:name="Suspend",type="boolean",JSONPath=".spec.suspend"
+// +kubebuilder:printcolumn:name="Last Schedule",type="date",JSONPath=".status.lastScheduleTime"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// ScheduledSandboxClaim creates SandboxClaims from its template on a cron schedule, e.g. to warm up sandboxes before
+// business hours, and deletes the old ones beyond its history limit. Its name labels the SandboxClaims, which are
+// named after it with the minute of the schedule appended, so it is limited to 52 characters like CronJobs.
+// +kubebuilder:validation:XValidation:rule="size(self.metadata.name) <= 52",message="name must be no more than 52 characters"
+// +kubebuilder:validation:XValidation:rule="!has(self.spec) || !has(self.spec.claimTemplate.spec.ordinalAliases) || !self.spec.claimTemplate.spec.ordinalAliases || (size(self.metadata.name) <= 47 && !self.metadata.name.contains('.'))",message="claimTemplate with ordinalAliases requires a name of at most 47 characters without dots"
+type ScheduledSandboxClaim struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// metadata is a standard object metadata
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty,omitzero"`
+
+	// spec defines the desired state of ScheduledSandboxClaim
+	// +required
+	Spec ScheduledSandboxClaimSpec `json:"spec"`
+
+	// status defines the observed state of ScheduledSandboxClaim
+	// +optional
+	Status ScheduledSandboxClaimStatus `json:"status,omitempty,omitzero"`
+}
+
+// +kubebuilder:object:root=true
+
+// ScheduledSandboxClaimList contains a list of ScheduledSandboxClaim
+type ScheduledSandboxClaimList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ScheduledSandboxClaim `json:"items"`
+}
+
+var ScheduledSandboxClaimControllerKind = GroupVersion.WithKind("ScheduledSandboxClaim")
+
+func init() {
+	SchemeBuilder.Register(&ScheduledSandboxClaim{}, &ScheduledSandboxClaimList{})
+}
@@ -0,0 +1,221 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apiextensions-apiserver/pkg/apiserver/schema"
+	"k8s.io/apiextensions-apiserver/pkg/apiserver/schema/cel"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	celconfig "k8s.io/apiserver/pkg/apis/cel"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/yaml"
+)
+
+// newCRDValidator returns the validator of the CEL rules of the generated CRD, as run by the API server.
+func newCRDValidator(t *testing.T, file string) (*cel.Validator, *schema.Structural) {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("..", "..", "config", "crd", "bases", file))
+	require.NoError(t, err)
+	crd := &apiextensionsv1.CustomResourceDefinition{}
+	require.NoError(t, yaml.Unmarshal(data, crd))
+	props := &apiextensions.JSONSchemaProps{}
+	require.NoError(t, apiextensionsv1.Convert_v1_JSONSchemaProps_To_apiextensions_JSONSchemaProps(
+		crd.Spec.Versions[0].Schema.OpenAPIV3Schema, props, nil))
+	structural, err := schema.NewStructural(props)
+	require.NoError(t, err)
+	return cel.NewValidator(structural, true, celconfig.PerCallLimit), structural
+}
+
+func validateCEL(t *testing.T, validator *cel.Validator, structural *schema.Structural, obj, oldObj runtime.Object) field.ErrorList {
+	t.Helper()
+	toUnstructured := func(o runtime.Object) any {
+		if o == nil {
+			return nil
+		}
+		u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(o)
+		require.NoError(t, err)
+		return u
+	}
+	errs, _ := validator.Validate(context.Background(), field.NewPath("root"), structural,
+		toUnstructured(obj), toUnstructured(oldObj), celconfig.RuntimeCELCostBudget)
+	return errs
+}
+
+func TestSandboxClaimValidationRules(t *testing.T) {
+	validator, structural := newCRDValidator(t, "agents.kruise.io_sandboxclaims.yaml")
+	newClaim := func(modify func(claim *SandboxClaim)) *SandboxClaim {
+		claim := &SandboxClaim{
+			Spec: SandboxClaimSpec{
+				TemplateName:     "python",
+				Replicas:         ptr.To[int32](1),
+				ClaimTimeout:     &metav1.Duration{Duration: time.Minute},
+				WaitReadyTimeout: &metav1.Duration{Duration: 30 * time.Second},
+			},
+		}
+		if modify != nil {
+			modify(claim)
+		}
+		return claim
+	}
+	tests := []struct {
+		name        string
+		claim       *SandboxClaim
+		oldClaim    *SandboxClaim
+		expectError string
+	}{
+		{
+			name:  "valid",
+			claim: newClaim(nil),
+		},
+		{
+			name:        "empty templateName",
+			claim:       newClaim(func(claim *SandboxClaim) { claim.Spec.TemplateName = "" }),
+			expectError: "exactly one of templateName, templateSelector and templates must be set",
+		},
+		{
+			name: "templateSelector",
+			claim: newClaim(func(claim *SandboxClaim) {
+				claim.Spec.TemplateName = ""
+				claim.Spec.TemplateSelector = &metav1.LabelSelector{MatchLabels: map[string]string{"runtime": "python"}}
+			}),
+		},
+		{
+			name: "templates",
+			claim: newClaim(func(claim *SandboxClaim) {
+				claim.Spec.TemplateName = ""
+				claim.Spec.Templates = []string{"gpu-pool", "cpu-pool"}
+			}),
+		},
+		{
+			name: "both templateName and templateSelector",
+			claim: newClaim(func(claim *SandboxClaim) {
+				claim.Spec.TemplateSelector = &metav1.LabelSelector{MatchLabels: map[string]string{"runtime": "python"}}
+			}),
+			expectError: "exactly one of templateName, templateSelector and templates must be set",
+		},
+		{
+			name:        "zero claimTimeout",
+			claim:       newClaim(func(claim *SandboxClaim) { claim.Spec.ClaimTimeout = &metav1.Duration{} }),
+			expectError: "claimTimeout must be positive",
+		},
+		{
+			name:        "negative waitReadyTimeout",
+			claim:       newClaim(func(claim *SandboxClaim) { claim.Spec.WaitReadyTimeout = &metav1.Duration{Duration: -1} }),
+			expectError: "waitReadyTimeout must be positive",
+		},
+		{
+			name:     "replicas changed",
+			claim:    newClaim(func(claim *SandboxClaim) { claim.Spec.Replicas = ptr.To[int32](2) }),
+			oldClaim: newClaim(nil),
+		},
+		{
+			name:        "requiresApproval changed",
+			claim:       newClaim(func(claim *SandboxClaim) { claim.Spec.RequiresApproval = true }),
+			oldClaim:    newClaim(nil),
+			expectError: "requiresApproval is immutable",
+		},
+		{
+			name:        "requiresApproval removed",
+			claim:       newClaim(nil),
+			oldClaim:    newClaim(func(claim *SandboxClaim) { claim.Spec.RequiresApproval = true }),
+			expectError: "requiresApproval is immutable",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var oldObj runtime.Object
+			if tt.oldClaim != nil {
+				oldObj = tt.oldClaim
+			}
+			errs := validateCEL(t, validator, structural, tt.claim, oldObj)
+			if tt.expectError == "" {
+				assert.Empty(t, errs)
+				return
+			}
+			require.Len(t, errs, 1)
+			assert.Contains(t, errs[0].Error(), tt.expectError)
+		})
+	}
+}
+
+func TestScheduledSandboxClaimValidationRules(t *testing.T) {
+	validator, structural := newCRDValidator(t, "agents.kruise.io_scheduledsandboxclaims.yaml")
+	newScheduled := func(name string, ordinalAliases bool) *ScheduledSandboxClaim {
+		return &ScheduledSandboxClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: ScheduledSandboxClaimSpec{
+				Schedule: "0 8 * * 1-5",
+				ClaimTemplate: SandboxClaimTemplate{
+					Spec: SandboxClaimSpec{TemplateName: "python", OrdinalAliases: ordinalAliases},
+				},
+			},
+		}
+	}
+	tests := []struct {
+		name        string
+		scheduled   *ScheduledSandboxClaim
+		expectError string
+	}{
+		{
+			name:      "valid",
+			scheduled: newScheduled(strings.Repeat("a", 52), false),
+		},
+		{
+			name:        "name too long",
+			scheduled:   newScheduled(strings.Repeat("a", 53), false),
+			expectError: "name must be no more than 52 characters",
+		},
+		{
+			name:      "ordinalAliases",
+			scheduled: newScheduled(strings.Repeat("a", 47), true),
+		},
+		{
+			name:        "ordinalAliases with a name too long",
+			scheduled:   newScheduled(strings.Repeat("a", 48), true),
+			expectError: "claimTemplate with ordinalAliases requires a name of at most 47 characters without dots",
+		},
+		{
+			name:        "ordinalAliases with dots",
+			scheduled:   newScheduled("warm.up", true),
+			expectError: "claimTemplate with ordinalAliases requires a name of at most 47 characters without dots",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := validateCEL(t, validator, structural, tt.scheduled, nil)
+			if tt.expectError == "" {
+				assert.Empty(t, errs)
+				return
+			}
+			require.Len(t, errs, 1)
+			assert.Contains(t, errs[0].Error(), tt.expectError)
+		})
+	}
+}
@@ -21,8 +21,8 @@ limitations under the License.
 package v1alpha1
 
 import (
-	"k8s.io/api/core/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/intstr"
 )
@@ -170,12 +170,12 @@ func (in *EmbeddedSandboxTemplate) DeepCopyInto(out *EmbeddedSandboxTemplate) {
 	}
 	if in.Template != nil {
 		in, out := &in.Template, &out.Template
-		*out = new(v1.PodTemplateSpec)
+		*out = new(corev1.PodTemplateSpec)
 		(*in).DeepCopyInto(*out)
 	}
 	if in.VolumeClaimTemplates != nil {
 		in, out := &in.VolumeClaimTemplates, &out.VolumeClaimTemplates
-		*out = make([]v1.PersistentVolumeClaim, len(*in))
+		*out = make([]corev1.PersistentVolumeClaim, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
@@ -192,6 +192,95 @@ func (in *EmbeddedSandboxTemplate) DeepCopy() *EmbeddedSandboxTemplate {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OperatorStatus) DeepCopyInto(out *OperatorStatus) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OperatorStatus.
+func (in *OperatorStatus) DeepCopy() *OperatorStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(OperatorStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *OperatorStatus) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OperatorStatusList) DeepCopyInto(out *OperatorStatusList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]OperatorStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OperatorStatusList.
+func (in *OperatorStatusList) DeepCopy() *OperatorStatusList {
+	if in == nil {
+		return nil
+	}
+	out := new(OperatorStatusList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *OperatorStatusList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OperatorStatusStatus) DeepCopyInto(out *OperatorStatusStatus) {
+	*out = *in
+	if in.Checks != nil {
+		in, out := &in.Checks, &out.Checks
+		*out = make([]PreflightCheck, len(*in))
+		copy(*out, *in)
+	}
+	if in.LastCheckTime != nil {
+		in, out := &in.LastCheckTime, &out.LastCheckTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OperatorStatusStatus.
+func (in *OperatorStatusStatus) DeepCopy() *OperatorStatusStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(OperatorStatusStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PodInfo) DeepCopyInto(out *PodInfo) {
 	*out = *in
@@ -221,6 +310,46 @@ func (in *PodInfo) DeepCopy() *PodInfo {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PreflightCheck) DeepCopyInto(out *PreflightCheck) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PreflightCheck.
+func (in *PreflightCheck) DeepCopy() *PreflightCheck {
+	if in == nil {
+		return nil
+	}
+	out := new(PreflightCheck)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RegionPolicy) DeepCopyInto(out *RegionPolicy) {
+	*out = *in
+	if in.Preferred != nil {
+		in, out := &in.Preferred, &out.Preferred
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Forbidden != nil {
+		in, out := &in.Forbidden, &out.Forbidden
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RegionPolicy.
+func (in *RegionPolicy) DeepCopy() *RegionPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(RegionPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RuntimeConfig) DeepCopyInto(out *RuntimeConfig) {
 	*out = *in
@@ -290,6 +419,86 @@ func (in *SandboxClaim) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SandboxClaimClaimedSandbox) DeepCopyInto(out *SandboxClaimClaimedSandbox) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SandboxClaimClaimedSandbox.
+func (in *SandboxClaimClaimedSandbox) DeepCopy() *SandboxClaimClaimedSandbox {
+	if in == nil {
+		return nil
+	}
+	out := new(SandboxClaimClaimedSandbox)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SandboxClaimDryRunStatus) DeepCopyInto(out *SandboxClaimDryRunStatus) {
+	*out = *in
+	in.CheckTime.DeepCopyInto(&out.CheckTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SandboxClaimDryRunStatus.
+func (in *SandboxClaimDryRunStatus) DeepCopy() *SandboxClaimDryRunStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SandboxClaimDryRunStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SandboxClaimETA) DeepCopyInto(out *SandboxClaimETA) {
+	*out = *in
+	in.EstimatedTime.DeepCopyInto(&out.EstimatedTime)
+	out.WarmupLatencyP50 = in.WarmupLatencyP50
+	out.WarmupLatencyP90 = in.WarmupLatencyP90
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SandboxClaimETA.
+func (in *SandboxClaimETA) DeepCopy() *SandboxClaimETA {
+	if in == nil {
+		return nil
+	}
+	out := new(SandboxClaimETA)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SandboxClaimGroup) DeepCopyInto(out *SandboxClaimGroup) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SandboxClaimGroup.
+func (in *SandboxClaimGroup) DeepCopy() *SandboxClaimGroup {
+	if in == nil {
+		return nil
+	}
+	out := new(SandboxClaimGroup)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SandboxClaimHistoryEntry) DeepCopyInto(out *SandboxClaimHistoryEntry) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SandboxClaimHistoryEntry.
+func (in *SandboxClaimHistoryEntry) DeepCopy() *SandboxClaimHistoryEntry {
+	if in == nil {
+		return nil
+	}
+	out := new(SandboxClaimHistoryEntry)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SandboxClaimInplaceUpdateOptions) DeepCopyInto(out *SandboxClaimInplaceUpdateOptions) {
 	*out = *in
@@ -337,26 +546,177 @@ func (in *SandboxClaimList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SandboxClaimNotification) DeepCopyInto(out *SandboxClaimNotification) {
+	*out = *in
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Sandboxes != nil {
+		in, out := &in.Sandboxes, &out.Sandboxes
+		*out = make([]SandboxClaimResultSandbox, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SandboxClaimNotification.
+func (in *SandboxClaimNotification) DeepCopy() *SandboxClaimNotification {
+	if in == nil {
+		return nil
+	}
+	out := new(SandboxClaimNotification)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SandboxClaimNotificationSecretRef) DeepCopyInto(out *SandboxClaimNotificationSecretRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SandboxClaimNotificationSecretRef.
+func (in *SandboxClaimNotificationSecretRef) DeepCopy() *SandboxClaimNotificationSecretRef {
+	if in == nil {
+		return nil
+	}
+	out := new(SandboxClaimNotificationSecretRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SandboxClaimNotifications) DeepCopyInto(out *SandboxClaimNotifications) {
+	*out = *in
+	if in.Headers != nil {
+		in, out := &in.Headers, &out.Headers
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.SecretRef != nil {
+		in, out := &in.SecretRef, &out.SecretRef
+		*out = new(SandboxClaimNotificationSecretRef)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SandboxClaimNotifications.
+func (in *SandboxClaimNotifications) DeepCopy() *SandboxClaimNotifications {
+	if in == nil {
+		return nil
+	}
+	out := new(SandboxClaimNotifications)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SandboxClaimResultSandbox) DeepCopyInto(out *SandboxClaimResultSandbox) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SandboxClaimResultSandbox.
+func (in *SandboxClaimResultSandbox) DeepCopy() *SandboxClaimResultSandbox {
+	if in == nil {
+		return nil
+	}
+	out := new(SandboxClaimResultSandbox)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SandboxClaimRetryPolicy) DeepCopyInto(out *SandboxClaimRetryPolicy) {
+	*out = *in
+	if in.InitialBackoff != nil {
+		in, out := &in.InitialBackoff, &out.InitialBackoff
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.MaxBackoff != nil {
+		in, out := &in.MaxBackoff, &out.MaxBackoff
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SandboxClaimRetryPolicy.
+func (in *SandboxClaimRetryPolicy) DeepCopy() *SandboxClaimRetryPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(SandboxClaimRetryPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SandboxClaimSpec) DeepCopyInto(out *SandboxClaimSpec) {
 	*out = *in
+	if in.TemplateSelector != nil {
+		in, out := &in.TemplateSelector, &out.TemplateSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Templates != nil {
+		in, out := &in.Templates, &out.Templates
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Regions != nil {
+		in, out := &in.Regions, &out.Regions
+		*out = new(RegionPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Replicas != nil {
 		in, out := &in.Replicas, &out.Replicas
 		*out = new(int32)
 		**out = **in
 	}
+	if in.Priority != nil {
+		in, out := &in.Priority, &out.Priority
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ClaimGroup != nil {
+		in, out := &in.ClaimGroup, &out.ClaimGroup
+		*out = new(SandboxClaimGroup)
+		**out = **in
+	}
+	if in.RetryPolicy != nil {
+		in, out := &in.RetryPolicy, &out.RetryPolicy
+		*out = new(SandboxClaimRetryPolicy)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.ShutdownTime != nil {
 		in, out := &in.ShutdownTime, &out.ShutdownTime
 		*out = (*in).DeepCopy()
 	}
+	if in.LeaseDuration != nil {
+		in, out := &in.LeaseDuration, &out.LeaseDuration
+		*out = new(v1.Duration)
+		**out = **in
+	}
 	if in.ClaimTimeout != nil {
 		in, out := &in.ClaimTimeout, &out.ClaimTimeout
-		*out = new(metav1.Duration)
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.PerReplicaTimeout != nil {
+		in, out := &in.PerReplicaTimeout, &out.PerReplicaTimeout
+		*out = new(v1.Duration)
 		**out = **in
 	}
 	if in.TTLAfterCompleted != nil {
 		in, out := &in.TTLAfterCompleted, &out.TTLAfterCompleted
-		*out = new(metav1.Duration)
+		*out = new(v1.Duration)
 		**out = **in
 	}
 	if in.Labels != nil {
@@ -397,9 +757,14 @@ func (in *SandboxClaimSpec) DeepCopyInto(out *SandboxClaimSpec) {
 	}
 	if in.WaitReadyTimeout != nil {
 		in, out := &in.WaitReadyTimeout, &out.WaitReadyTimeout
-		*out = new(metav1.Duration)
+		*out = new(v1.Duration)
 		**out = **in
 	}
+	if in.Notifications != nil {
+		in, out := &in.Notifications, &out.Notifications
+		*out = new(SandboxClaimNotifications)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SandboxClaimSpec.
@@ -415,6 +780,11 @@ func (in *SandboxClaimSpec) DeepCopy() *SandboxClaimSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SandboxClaimStatus) DeepCopyInto(out *SandboxClaimStatus) {
 	*out = *in
+	if in.Templates != nil {
+		in, out := &in.Templates, &out.Templates
+		*out = make([]SandboxClaimTemplateStatus, len(*in))
+		copy(*out, *in)
+	}
 	if in.ClaimStartTime != nil {
 		in, out := &in.ClaimStartTime, &out.ClaimStartTime
 		*out = (*in).DeepCopy()
@@ -423,51 +793,445 @@ func (in *SandboxClaimStatus) DeepCopyInto(out *SandboxClaimStatus) {
 		in, out := &in.CompletionTime, &out.CompletionTime
 		*out = (*in).DeepCopy()
 	}
+	if in.LeaseExpireTime != nil {
+		in, out := &in.LeaseExpireTime, &out.LeaseExpireTime
+		*out = (*in).DeepCopy()
+	}
+	if in.QueuedPosition != nil {
+		in, out := &in.QueuedPosition, &out.QueuedPosition
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ETA != nil {
+		in, out := &in.ETA, &out.ETA
+		*out = new(SandboxClaimETA)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DryRun != nil {
+		in, out := &in.DryRun, &out.DryRun
+		*out = new(SandboxClaimDryRunStatus)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
-		*out = make([]metav1.Condition, len(*in))
+		*out = make([]v1.Condition, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.History != nil {
+		in, out := &in.History, &out.History
+		*out = make([]SandboxClaimHistoryEntry, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ClaimedSandboxes != nil {
+		in, out := &in.ClaimedSandboxes, &out.ClaimedSandboxes
+		*out = make([]SandboxClaimClaimedSandbox, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SandboxClaimStatus.
+func (in *SandboxClaimStatus) DeepCopy() *SandboxClaimStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SandboxClaimStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SandboxClaimTemplate) DeepCopyInto(out *SandboxClaimTemplate) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SandboxClaimTemplate.
+func (in *SandboxClaimTemplate) DeepCopy() *SandboxClaimTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(SandboxClaimTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SandboxClaimTemplateStatus) DeepCopyInto(out *SandboxClaimTemplateStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SandboxClaimTemplateStatus.
+func (in *SandboxClaimTemplateStatus) DeepCopy() *SandboxClaimTemplateStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SandboxClaimTemplateStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SandboxExecProbe) DeepCopyInto(out *SandboxExecProbe) {
+	*out = *in
+	if in.Command != nil {
+		in, out := &in.Command, &out.Command
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SandboxExecProbe.
+func (in *SandboxExecProbe) DeepCopy() *SandboxExecProbe {
+	if in == nil {
+		return nil
+	}
+	out := new(SandboxExecProbe)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SandboxHTTPGetProbe) DeepCopyInto(out *SandboxHTTPGetProbe) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SandboxHTTPGetProbe.
+func (in *SandboxHTTPGetProbe) DeepCopy() *SandboxHTTPGetProbe {
+	if in == nil {
+		return nil
+	}
+	out := new(SandboxHTTPGetProbe)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SandboxHeartbeat) DeepCopyInto(out *SandboxHeartbeat) {
+	*out = *in
+	in.LastHeartbeatTime.DeepCopyInto(&out.LastHeartbeatTime)
+	if in.LastActivityTime != nil {
+		in, out := &in.LastActivityTime, &out.LastActivityTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SandboxHeartbeat.
+func (in *SandboxHeartbeat) DeepCopy() *SandboxHeartbeat {
+	if in == nil {
+		return nil
+	}
+	out := new(SandboxHeartbeat)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SandboxList) DeepCopyInto(out *SandboxList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]Sandbox, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SandboxList.
+func (in *SandboxList) DeepCopy() *SandboxList {
+	if in == nil {
+		return nil
+	}
+	out := new(SandboxList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SandboxList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SandboxQuarantine) DeepCopyInto(out *SandboxQuarantine) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SandboxQuarantine.
+func (in *SandboxQuarantine) DeepCopy() *SandboxQuarantine {
+	if in == nil {
+		return nil
+	}
+	out := new(SandboxQuarantine)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SandboxQuota) DeepCopyInto(out *SandboxQuota) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SandboxQuota.
+func (in *SandboxQuota) DeepCopy() *SandboxQuota {
+	if in == nil {
+		return nil
+	}
+	out := new(SandboxQuota)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SandboxQuota) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SandboxQuotaList) DeepCopyInto(out *SandboxQuotaList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]SandboxQuota, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SandboxQuotaList.
+func (in *SandboxQuotaList) DeepCopy() *SandboxQuotaList {
+	if in == nil {
+		return nil
+	}
+	out := new(SandboxQuotaList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SandboxQuotaList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SandboxQuotaSpec) DeepCopyInto(out *SandboxQuotaSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SandboxQuotaSpec.
+func (in *SandboxQuotaSpec) DeepCopy() *SandboxQuotaSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SandboxQuotaSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SandboxReadinessProbe) DeepCopyInto(out *SandboxReadinessProbe) {
+	*out = *in
+	if in.HTTPGet != nil {
+		in, out := &in.HTTPGet, &out.HTTPGet
+		*out = new(SandboxHTTPGetProbe)
+		**out = **in
+	}
+	if in.TCPSocket != nil {
+		in, out := &in.TCPSocket, &out.TCPSocket
+		*out = new(SandboxTCPSocketProbe)
+		**out = **in
+	}
+	if in.Exec != nil {
+		in, out := &in.Exec, &out.Exec
+		*out = new(SandboxExecProbe)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SandboxReadinessProbe.
+func (in *SandboxReadinessProbe) DeepCopy() *SandboxReadinessProbe {
+	if in == nil {
+		return nil
+	}
+	out := new(SandboxReadinessProbe)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SandboxSecurityAlert) DeepCopyInto(out *SandboxSecurityAlert) {
+	*out = *in
+	in.LastAlertTime.DeepCopyInto(&out.LastAlertTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SandboxSecurityAlert.
+func (in *SandboxSecurityAlert) DeepCopy() *SandboxSecurityAlert {
+	if in == nil {
+		return nil
+	}
+	out := new(SandboxSecurityAlert)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SandboxSet) DeepCopyInto(out *SandboxSet) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SandboxSet.
+func (in *SandboxSet) DeepCopy() *SandboxSet {
+	if in == nil {
+		return nil
+	}
+	out := new(SandboxSet)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SandboxSet) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SandboxSetAutoscaling) DeepCopyInto(out *SandboxSetAutoscaling) {
+	*out = *in
+	if in.ScaleDownCooldown != nil {
+		in, out := &in.ScaleDownCooldown, &out.ScaleDownCooldown
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SandboxSetAutoscaling.
+func (in *SandboxSetAutoscaling) DeepCopy() *SandboxSetAutoscaling {
+	if in == nil {
+		return nil
+	}
+	out := new(SandboxSetAutoscaling)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SandboxSetAutoscalingStatus) DeepCopyInto(out *SandboxSetAutoscalingStatus) {
+	*out = *in
+	if in.LastScaleUpTime != nil {
+		in, out := &in.LastScaleUpTime, &out.LastScaleUpTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SandboxSetAutoscalingStatus.
+func (in *SandboxSetAutoscalingStatus) DeepCopy() *SandboxSetAutoscalingStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SandboxSetAutoscalingStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SandboxSetClaimQueue) DeepCopyInto(out *SandboxSetClaimQueue) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SandboxSetClaimQueue.
+func (in *SandboxSetClaimQueue) DeepCopy() *SandboxSetClaimQueue {
+	if in == nil {
+		return nil
+	}
+	out := new(SandboxSetClaimQueue)
+	in.DeepCopyInto(out)
+	return out
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SandboxClaimStatus.
-func (in *SandboxClaimStatus) DeepCopy() *SandboxClaimStatus {
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SandboxSetLatencyObjective) DeepCopyInto(out *SandboxSetLatencyObjective) {
+	*out = *in
+	out.Target = in.Target
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SandboxSetLatencyObjective.
+func (in *SandboxSetLatencyObjective) DeepCopy() *SandboxSetLatencyObjective {
 	if in == nil {
 		return nil
 	}
-	out := new(SandboxClaimStatus)
+	out := new(SandboxSetLatencyObjective)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SandboxList) DeepCopyInto(out *SandboxList) {
+func (in *SandboxSetList) DeepCopyInto(out *SandboxSetList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]Sandbox, len(*in))
+		*out = make([]SandboxSet, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SandboxList.
-func (in *SandboxList) DeepCopy() *SandboxList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SandboxSetList.
+func (in *SandboxSetList) DeepCopy() *SandboxSetList {
 	if in == nil {
 		return nil
 	}
-	out := new(SandboxList)
+	out := new(SandboxSetList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *SandboxList) DeepCopyObject() runtime.Object {
+func (in *SandboxSetList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -475,62 +1239,122 @@ func (in *SandboxList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SandboxSet) DeepCopyInto(out *SandboxSet) {
+func (in *SandboxSetNodePool) DeepCopyInto(out *SandboxSetNodePool) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
-	in.Status.DeepCopyInto(&out.Status)
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]corev1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SandboxSet.
-func (in *SandboxSet) DeepCopy() *SandboxSet {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SandboxSetNodePool.
+func (in *SandboxSetNodePool) DeepCopy() *SandboxSetNodePool {
 	if in == nil {
 		return nil
 	}
-	out := new(SandboxSet)
+	out := new(SandboxSetNodePool)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *SandboxSet) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SandboxSetNodePoolStatus) DeepCopyInto(out *SandboxSetNodePoolStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SandboxSetNodePoolStatus.
+func (in *SandboxSetNodePoolStatus) DeepCopy() *SandboxSetNodePoolStatus {
+	if in == nil {
+		return nil
 	}
-	return nil
+	out := new(SandboxSetNodePoolStatus)
+	in.DeepCopyInto(out)
+	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SandboxSetList) DeepCopyInto(out *SandboxSetList) {
+func (in *SandboxSetObjectiveStatus) DeepCopyInto(out *SandboxSetObjectiveStatus) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ListMeta.DeepCopyInto(&out.ListMeta)
-	if in.Items != nil {
-		in, out := &in.Items, &out.Items
-		*out = make([]SandboxSet, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SandboxSetObjectiveStatus.
+func (in *SandboxSetObjectiveStatus) DeepCopy() *SandboxSetObjectiveStatus {
+	if in == nil {
+		return nil
 	}
+	out := new(SandboxSetObjectiveStatus)
+	in.DeepCopyInto(out)
+	return out
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SandboxSetList.
-func (in *SandboxSetList) DeepCopy() *SandboxSetList {
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SandboxSetSLO) DeepCopyInto(out *SandboxSetSLO) {
+	*out = *in
+	if in.ClaimLatency != nil {
+		in, out := &in.ClaimLatency, &out.ClaimLatency
+		*out = new(SandboxSetLatencyObjective)
+		**out = **in
+	}
+	if in.ResumeLatency != nil {
+		in, out := &in.ResumeLatency, &out.ResumeLatency
+		*out = new(SandboxSetLatencyObjective)
+		**out = **in
+	}
+	if in.Window != nil {
+		in, out := &in.Window, &out.Window
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SandboxSetSLO.
+func (in *SandboxSetSLO) DeepCopy() *SandboxSetSLO {
 	if in == nil {
 		return nil
 	}
-	out := new(SandboxSetList)
+	out := new(SandboxSetSLO)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *SandboxSetList) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SandboxSetSLOStatus) DeepCopyInto(out *SandboxSetSLOStatus) {
+	*out = *in
+	if in.ClaimLatency != nil {
+		in, out := &in.ClaimLatency, &out.ClaimLatency
+		*out = new(SandboxSetObjectiveStatus)
+		**out = **in
 	}
-	return nil
+	if in.WarmupSuccess != nil {
+		in, out := &in.WarmupSuccess, &out.WarmupSuccess
+		*out = new(SandboxSetObjectiveStatus)
+		**out = **in
+	}
+	if in.ResumeLatency != nil {
+		in, out := &in.ResumeLatency, &out.ResumeLatency
+		*out = new(SandboxSetObjectiveStatus)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SandboxSetSLOStatus.
+func (in *SandboxSetSLOStatus) DeepCopy() *SandboxSetSLOStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SandboxSetSLOStatus)
+	in.DeepCopyInto(out)
+	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
@@ -566,8 +1390,60 @@ func (in *SandboxSetSpec) DeepCopyInto(out *SandboxSetSpec) {
 		*out = make([]RuntimeConfig, len(*in))
 		copy(*out, *in)
 	}
+	if in.ReadinessProbe != nil {
+		in, out := &in.ReadinessProbe, &out.ReadinessProbe
+		*out = new(SandboxReadinessProbe)
+		(*in).DeepCopyInto(*out)
+	}
 	in.EmbeddedSandboxTemplate.DeepCopyInto(&out.EmbeddedSandboxTemplate)
+	if in.TemplateOverlay != nil {
+		in, out := &in.TemplateOverlay, &out.TemplateOverlay
+		*out = new(corev1.PodTemplateSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	in.ScaleStrategy.DeepCopyInto(&out.ScaleStrategy)
+	if in.MaxConcurrentClaims != nil {
+		in, out := &in.MaxConcurrentClaims, &out.MaxConcurrentClaims
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ClaimQueue != nil {
+		in, out := &in.ClaimQueue, &out.ClaimQueue
+		*out = new(SandboxSetClaimQueue)
+		**out = **in
+	}
+	if in.MaxReplicasPerClaim != nil {
+		in, out := &in.MaxReplicasPerClaim, &out.MaxReplicasPerClaim
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxOutstandingClaims != nil {
+		in, out := &in.MaxOutstandingClaims, &out.MaxOutstandingClaims
+		*out = new(int32)
+		**out = **in
+	}
+	if in.InteractiveReserve != nil {
+		in, out := &in.InteractiveReserve, &out.InteractiveReserve
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+	if in.SLO != nil {
+		in, out := &in.SLO, &out.SLO
+		*out = new(SandboxSetSLO)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NodePools != nil {
+		in, out := &in.NodePools, &out.NodePools
+		*out = make([]SandboxSetNodePool, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Autoscaling != nil {
+		in, out := &in.Autoscaling, &out.Autoscaling
+		*out = new(SandboxSetAutoscaling)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SandboxSetSpec.
@@ -585,11 +1461,26 @@ func (in *SandboxSetStatus) DeepCopyInto(out *SandboxSetStatus) {
 	*out = *in
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
-		*out = make([]metav1.Condition, len(*in))
+		*out = make([]v1.Condition, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.SLO != nil {
+		in, out := &in.SLO, &out.SLO
+		*out = new(SandboxSetSLOStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NodePools != nil {
+		in, out := &in.NodePools, &out.NodePools
+		*out = make([]SandboxSetNodePoolStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.Autoscaling != nil {
+		in, out := &in.Autoscaling, &out.Autoscaling
+		*out = new(SandboxSetAutoscalingStatus)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SandboxSetStatus.
@@ -623,6 +1514,16 @@ func (in *SandboxSpec) DeepCopyInto(out *SandboxSpec) {
 		in, out := &in.PauseTime, &out.PauseTime
 		*out = (*in).DeepCopy()
 	}
+	if in.ReadinessProbe != nil {
+		in, out := &in.ReadinessProbe, &out.ReadinessProbe
+		*out = new(SandboxReadinessProbe)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Quarantine != nil {
+		in, out := &in.Quarantine, &out.Quarantine
+		*out = new(SandboxQuarantine)
+		**out = **in
+	}
 	in.EmbeddedSandboxTemplate.DeepCopyInto(&out.EmbeddedSandboxTemplate)
 }
 
@@ -641,12 +1542,31 @@ func (in *SandboxStatus) DeepCopyInto(out *SandboxStatus) {
 	*out = *in
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
-		*out = make([]metav1.Condition, len(*in))
+		*out = make([]v1.Condition, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 	in.PodInfo.DeepCopyInto(&out.PodInfo)
+	if in.Heartbeat != nil {
+		in, out := &in.Heartbeat, &out.Heartbeat
+		*out = new(SandboxHeartbeat)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ResumeStartTime != nil {
+		in, out := &in.ResumeStartTime, &out.ResumeStartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Usage != nil {
+		in, out := &in.Usage, &out.Usage
+		*out = new(SandboxUsage)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SecurityAlert != nil {
+		in, out := &in.SecurityAlert, &out.SecurityAlert
+		*out = new(SandboxSecurityAlert)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SandboxStatus.
@@ -659,6 +1579,21 @@ func (in *SandboxStatus) DeepCopy() *SandboxStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SandboxTCPSocketProbe) DeepCopyInto(out *SandboxTCPSocketProbe) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SandboxTCPSocketProbe.
+func (in *SandboxTCPSocketProbe) DeepCopy() *SandboxTCPSocketProbe {
+	if in == nil {
+		return nil
+	}
+	out := new(SandboxTCPSocketProbe)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SandboxTemplate) DeepCopyInto(out *SandboxTemplate) {
 	*out = *in
@@ -747,12 +1682,12 @@ func (in *SandboxTemplateSpec) DeepCopyInto(out *SandboxTemplateSpec) {
 	*out = *in
 	if in.Template != nil {
 		in, out := &in.Template, &out.Template
-		*out = new(v1.PodTemplateSpec)
+		*out = new(corev1.PodTemplateSpec)
 		(*in).DeepCopyInto(*out)
 	}
 	if in.VolumeClaimTemplates != nil {
 		in, out := &in.VolumeClaimTemplates, &out.VolumeClaimTemplates
-		*out = make([]v1.PersistentVolumeClaim, len(*in))
+		*out = make([]corev1.PersistentVolumeClaim, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
@@ -778,3 +1713,137 @@ func (in *SandboxTemplateSpec) DeepCopy() *SandboxTemplateSpec {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SandboxUsage) DeepCopyInto(out *SandboxUsage) {
+	*out = *in
+	out.CPU = in.CPU.DeepCopy()
+	out.Memory = in.Memory.DeepCopy()
+	in.LastSampleTime.DeepCopyInto(&out.LastSampleTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SandboxUsage.
+func (in *SandboxUsage) DeepCopy() *SandboxUsage {
+	if in == nil {
+		return nil
+	}
+	out := new(SandboxUsage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScheduledSandboxClaim) DeepCopyInto(out *ScheduledSandboxClaim) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScheduledSandboxClaim.
+func (in *ScheduledSandboxClaim) DeepCopy() *ScheduledSandboxClaim {
+	if in == nil {
+		return nil
+	}
+	out := new(ScheduledSandboxClaim)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ScheduledSandboxClaim) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScheduledSandboxClaimList) DeepCopyInto(out *ScheduledSandboxClaimList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ScheduledSandboxClaim, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScheduledSandboxClaimList.
+func (in *ScheduledSandboxClaimList) DeepCopy() *ScheduledSandboxClaimList {
+	if in == nil {
+		return nil
+	}
+	out := new(ScheduledSandboxClaimList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ScheduledSandboxClaimList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScheduledSandboxClaimSpec) DeepCopyInto(out *ScheduledSandboxClaimSpec) {
+	*out = *in
+	if in.TimeZone != nil {
+		in, out := &in.TimeZone, &out.TimeZone
+		*out = new(string)
+		**out = **in
+	}
+	if in.StartingDeadlineSeconds != nil {
+		in, out := &in.StartingDeadlineSeconds, &out.StartingDeadlineSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	in.ClaimTemplate.DeepCopyInto(&out.ClaimTemplate)
+	if in.HistoryLimit != nil {
+		in, out := &in.HistoryLimit, &out.HistoryLimit
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScheduledSandboxClaimSpec.
+func (in *ScheduledSandboxClaimSpec) DeepCopy() *ScheduledSandboxClaimSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ScheduledSandboxClaimSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScheduledSandboxClaimStatus) DeepCopyInto(out *ScheduledSandboxClaimStatus) {
+	*out = *in
+	if in.LastScheduleTime != nil {
+		in, out := &in.LastScheduleTime, &out.LastScheduleTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScheduledSandboxClaimStatus.
+func (in *ScheduledSandboxClaimStatus) DeepCopy() *ScheduledSandboxClaimStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ScheduledSandboxClaimStatus)
+	in.DeepCopyInto(out)
+	return out
+}
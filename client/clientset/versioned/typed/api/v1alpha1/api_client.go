@@ -28,10 +28,13 @@ import (
 type ApiV1alpha1Interface interface {
 	RESTClient() rest.Interface
 	CheckpointsGetter
+	OperatorStatusesGetter
 	SandboxesGetter
 	SandboxClaimsGetter
+	SandboxQuotasGetter
 	SandboxSetsGetter
 	SandboxTemplatesGetter
+	ScheduledSandboxClaimsGetter
 }
 
 // ApiV1alpha1Client is used to interact with features provided by the api group.
@@ -43,6 +46,10 @@ func (c *ApiV1alpha1Client) Checkpoints(namespace string) CheckpointInterface {
 	return newCheckpoints(c, namespace)
 }
 
+func (c *ApiV1alpha1Client) OperatorStatuses() OperatorStatusInterface {
+	return newOperatorStatuses(c)
+}
+
 func (c *ApiV1alpha1Client) Sandboxes(namespace string) SandboxInterface {
 	return newSandboxes(c, namespace)
 }
@@ -51,6 +58,10 @@ func (c *ApiV1alpha1Client) SandboxClaims(namespace string) SandboxClaimInterfac
 	return newSandboxClaims(c, namespace)
 }
 
+func (c *ApiV1alpha1Client) SandboxQuotas(namespace string) SandboxQuotaInterface {
+	return newSandboxQuotas(c, namespace)
+}
+
 func (c *ApiV1alpha1Client) SandboxSets(namespace string) SandboxSetInterface {
 	return newSandboxSets(c, namespace)
 }
@@ -59,6 +70,10 @@ func (c *ApiV1alpha1Client) SandboxTemplates(namespace string) SandboxTemplateIn
 	return newSandboxTemplates(c, namespace)
 }
 
+func (c *ApiV1alpha1Client) ScheduledSandboxClaims(namespace string) ScheduledSandboxClaimInterface {
+	return newScheduledSandboxClaims(c, namespace)
+}
+
 // NewForConfig creates a new ApiV1alpha1Client for the given config.
 // NewForConfig is equivalent to NewForConfigAndClient(c, httpClient),
 // where httpClient was generated with rest.HTTPClientFor(c).
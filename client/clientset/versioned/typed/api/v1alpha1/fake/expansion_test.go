@@ -0,0 +1,132 @@
+package fake_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/openkruise/agents/api/v1alpha1"
+	"github.com/openkruise/agents/client/clientset/versioned/fake"
+)
+
+func TestMarkClaimed(t *testing.T) {
+	ctx := context.Background()
+	sbx := &v1alpha1.Sandbox{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "sbx",
+			Namespace:       "default",
+			ResourceVersion: "1",
+			Labels:          map[string]string{v1alpha1.LabelSandboxPool: "pool"},
+			OwnerReferences: []metav1.OwnerReference{{APIVersion: "agents.kruise.io/v1alpha1", Kind: "SandboxSet", Name: "pool", UID: "pool-uid"}},
+		},
+	}
+	// created rather than tracked, as the tracker guesses the resource of Sandbox as sandboxs
+	sandboxes := fake.NewSimpleClientset().ApiV1alpha1().Sandboxes("default")
+	_, err := sandboxes.Create(ctx, sbx, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		sandbox func() *v1alpha1.Sandbox
+		wantErr func(error) bool
+	}{
+		{
+			name: "no resourceVersion",
+			sandbox: func() *v1alpha1.Sandbox {
+				unversioned := sbx.DeepCopy()
+				unversioned.ResourceVersion = ""
+				return unversioned
+			},
+			wantErr: func(err error) bool { return err != nil && !errors.IsConflict(err) },
+		},
+		{
+			name:    "claim unclaimed",
+			sandbox: sbx.DeepCopy,
+			wantErr: func(err error) bool { return err == nil },
+		},
+		{
+			name: "already claimed",
+			sandbox: func() *v1alpha1.Sandbox {
+				claimed, err := sandboxes.Get(ctx, sbx.Name, metav1.GetOptions{})
+				require.NoError(t, err)
+				return claimed
+			},
+			wantErr: errors.IsConflict,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := sandboxes.MarkClaimed(ctx, tt.sandbox(), "user", "lock", metav1.UpdateOptions{})
+			assert.True(t, tt.wantErr(err), "unexpected error %v", err)
+		})
+	}
+
+	claimed, err := sandboxes.Get(ctx, sbx.Name, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, claimed.OwnerReferences)
+	assert.Equal(t, v1alpha1.True, claimed.Labels[v1alpha1.LabelSandboxIsClaimed])
+	assert.Equal(t, "pool", claimed.Labels[v1alpha1.LabelSandboxPool])
+	assert.Equal(t, "lock", claimed.Annotations[v1alpha1.AnnotationLock])
+	assert.Equal(t, "user", claimed.Annotations[v1alpha1.AnnotationOwner])
+	assert.NotEmpty(t, claimed.Annotations[v1alpha1.AnnotationClaimTime])
+}
+
+func TestWaitForPhase(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	claim := &v1alpha1.SandboxClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "claim", Namespace: "default"},
+		Status:     v1alpha1.SandboxClaimStatus{Phase: v1alpha1.SandboxClaimPhaseClaiming},
+	}
+	other := &v1alpha1.SandboxClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "default"},
+		Status:     v1alpha1.SandboxClaimStatus{Phase: v1alpha1.SandboxClaimPhaseCompleted},
+	}
+	claims := fake.NewSimpleClientset(claim, other).ApiV1alpha1().SandboxClaims("default")
+
+	got, err := claims.WaitForPhase(ctx, "claim", v1alpha1.SandboxClaimPhaseClaiming)
+	require.NoError(t, err)
+	assert.Equal(t, "claim", got.Name)
+
+	_, err = claims.WaitForPhase(ctx, "missing", v1alpha1.SandboxClaimPhaseCompleted)
+	assert.True(t, errors.IsNotFound(err), "unexpected error %v", err)
+
+	go func() {
+		time.Sleep(200 * time.Millisecond) // Wait for the watch to start
+		completed := claim.DeepCopy()
+		completed.Status.Phase = v1alpha1.SandboxClaimPhaseCompleted
+		_, _ = claims.UpdateStatus(context.Background(), completed, metav1.UpdateOptions{})
+	}()
+	got, err = claims.WaitForPhase(ctx, "claim", v1alpha1.SandboxClaimPhaseCompleted)
+	require.NoError(t, err)
+	assert.Equal(t, v1alpha1.SandboxClaimPhaseCompleted, got.Status.Phase)
+
+	timeoutCtx, timeoutCancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer timeoutCancel()
+	_, err = claims.WaitForPhase(timeoutCtx, "other", v1alpha1.SandboxClaimPhaseClaiming)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestRenewLease(t *testing.T) {
+	ctx := context.Background()
+	claim := &v1alpha1.SandboxClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "claim", Namespace: "default", Annotations: map[string]string{"keep": "me"}},
+	}
+	claims := fake.NewSimpleClientset(claim).ApiV1alpha1().SandboxClaims("default")
+
+	before := time.Now().Truncate(time.Second)
+	got, err := claims.RenewLease(ctx, "claim")
+	require.NoError(t, err)
+	renewed, err := time.Parse(time.RFC3339, got.Annotations[v1alpha1.AnnotationClaimLeaseRenewTime])
+	require.NoError(t, err)
+	assert.False(t, renewed.Before(before), "renewed at %v before %v", renewed, before)
+	assert.Equal(t, "me", got.Annotations["keep"])
+
+	_, err = claims.RenewLease(ctx, "missing")
+	assert.True(t, errors.IsNotFound(err), "unexpected error %v", err)
+}
@@ -31,6 +31,10 @@ func (c *FakeApiV1alpha1) Checkpoints(namespace string) v1alpha1.CheckpointInter
 	return newFakeCheckpoints(c, namespace)
 }
 
+func (c *FakeApiV1alpha1) OperatorStatuses() v1alpha1.OperatorStatusInterface {
+	return newFakeOperatorStatuses(c)
+}
+
 func (c *FakeApiV1alpha1) Sandboxes(namespace string) v1alpha1.SandboxInterface {
 	return newFakeSandboxes(c, namespace)
 }
@@ -39,6 +43,10 @@ func (c *FakeApiV1alpha1) SandboxClaims(namespace string) v1alpha1.SandboxClaimI
 	return newFakeSandboxClaims(c, namespace)
 }
 
+func (c *FakeApiV1alpha1) SandboxQuotas(namespace string) v1alpha1.SandboxQuotaInterface {
+	return newFakeSandboxQuotas(c, namespace)
+}
+
 func (c *FakeApiV1alpha1) SandboxSets(namespace string) v1alpha1.SandboxSetInterface {
 	return newFakeSandboxSets(c, namespace)
 }
@@ -47,6 +55,10 @@ func (c *FakeApiV1alpha1) SandboxTemplates(namespace string) v1alpha1.SandboxTem
 	return newFakeSandboxTemplates(c, namespace)
 }
 
+func (c *FakeApiV1alpha1) ScheduledSandboxClaims(namespace string) v1alpha1.ScheduledSandboxClaimInterface {
+	return newFakeScheduledSandboxClaims(c, namespace)
+}
+
 // RESTClient returns a RESTClient that is used to communicate
 // with API server by this client implementation.
 func (c *FakeApiV1alpha1) RESTClient() rest.Interface {
@@ -0,0 +1,51 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	v1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+	apiv1alpha1 "github.com/openkruise/agents/client/clientset/versioned/typed/api/v1alpha1"
+	gentype "k8s.io/client-go/gentype"
+)
+
+// fakeOperatorStatuses implements OperatorStatusInterface
+type fakeOperatorStatuses struct {
+	*gentype.FakeClientWithList[*v1alpha1.OperatorStatus, *v1alpha1.OperatorStatusList]
+	Fake *FakeApiV1alpha1
+}
+
+func newFakeOperatorStatuses(fake *FakeApiV1alpha1) apiv1alpha1.OperatorStatusInterface {
+	return &fakeOperatorStatuses{
+		gentype.NewFakeClientWithList[*v1alpha1.OperatorStatus, *v1alpha1.OperatorStatusList](
+			fake.Fake,
+			"",
+			v1alpha1.SchemeGroupVersion.WithResource("operatorstatuses"),
+			v1alpha1.SchemeGroupVersion.WithKind("OperatorStatus"),
+			func() *v1alpha1.OperatorStatus { return &v1alpha1.OperatorStatus{} },
+			func() *v1alpha1.OperatorStatusList { return &v1alpha1.OperatorStatusList{} },
+			func(dst, src *v1alpha1.OperatorStatusList) { dst.ListMeta = src.ListMeta },
+			func(list *v1alpha1.OperatorStatusList) []*v1alpha1.OperatorStatus {
+				return gentype.ToPointerSlice(list.Items)
+			},
+			func(list *v1alpha1.OperatorStatusList, items []*v1alpha1.OperatorStatus) {
+				list.Items = gentype.FromPointerSlice(items)
+			},
+		),
+		fake,
+	}
+}
@@ -0,0 +1,29 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake
+
+import (
+	context "context"
+
+	v1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+	apiv1alpha1 "github.com/openkruise/agents/client/clientset/versioned/typed/api/v1alpha1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func (c *fakeSandboxes) MarkClaimed(ctx context.Context, sandbox *v1alpha1.Sandbox, owner, lock string, opts v1.UpdateOptions) (*v1alpha1.Sandbox, error) {
+	return apiv1alpha1.MarkSandboxClaimed(ctx, c, sandbox, owner, lock, opts)
+}
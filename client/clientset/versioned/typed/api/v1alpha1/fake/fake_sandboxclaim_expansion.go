@@ -0,0 +1,43 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake
+
+import (
+	context "context"
+	time "time"
+
+	v1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+	apiv1alpha1 "github.com/openkruise/agents/client/clientset/versioned/typed/api/v1alpha1"
+)
+
+func (c *fakeSandboxClaims) WaitForPhase(ctx context.Context, name string, phase v1alpha1.SandboxClaimPhase) (*v1alpha1.SandboxClaim, error) {
+	return apiv1alpha1.WaitForSandboxClaimPhase(ctx, watchListUnsupportedSandboxClaims{c}, name, phase)
+}
+
+func (c *fakeSandboxClaims) RenewLease(ctx context.Context, name string) (*v1alpha1.SandboxClaim, error) {
+	return apiv1alpha1.RenewSandboxClaimLease(ctx, c, name, time.Now())
+}
+
+// watchListUnsupportedSandboxClaims tells informers to list and then watch, as the tracker does not support the
+// watch-list semantics
+type watchListUnsupportedSandboxClaims struct {
+	*fakeSandboxClaims
+}
+
+func (watchListUnsupportedSandboxClaims) IsWatchListSemanticsUnSupported() bool {
+	return true
+}
@@ -0,0 +1,51 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	v1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+	apiv1alpha1 "github.com/openkruise/agents/client/clientset/versioned/typed/api/v1alpha1"
+	gentype "k8s.io/client-go/gentype"
+)
+
+// fakeScheduledSandboxClaims implements ScheduledSandboxClaimInterface
+type fakeScheduledSandboxClaims struct {
+	*gentype.FakeClientWithList[*v1alpha1.ScheduledSandboxClaim, *v1alpha1.ScheduledSandboxClaimList]
+	Fake *FakeApiV1alpha1
+}
+
+func newFakeScheduledSandboxClaims(fake *FakeApiV1alpha1, namespace string) apiv1alpha1.ScheduledSandboxClaimInterface {
+	return &fakeScheduledSandboxClaims{
+		gentype.NewFakeClientWithList[*v1alpha1.ScheduledSandboxClaim, *v1alpha1.ScheduledSandboxClaimList](
+			fake.Fake,
+			namespace,
+			v1alpha1.SchemeGroupVersion.WithResource("scheduledsandboxclaims"),
+			v1alpha1.SchemeGroupVersion.WithKind("ScheduledSandboxClaim"),
+			func() *v1alpha1.ScheduledSandboxClaim { return &v1alpha1.ScheduledSandboxClaim{} },
+			func() *v1alpha1.ScheduledSandboxClaimList { return &v1alpha1.ScheduledSandboxClaimList{} },
+			func(dst, src *v1alpha1.ScheduledSandboxClaimList) { dst.ListMeta = src.ListMeta },
+			func(list *v1alpha1.ScheduledSandboxClaimList) []*v1alpha1.ScheduledSandboxClaim {
+				return gentype.ToPointerSlice(list.Items)
+			},
+			func(list *v1alpha1.ScheduledSandboxClaimList, items []*v1alpha1.ScheduledSandboxClaim) {
+				list.Items = gentype.FromPointerSlice(items)
+			},
+		),
+		fake,
+	}
+}
@@ -19,10 +19,12 @@ package v1alpha1
 
 type CheckpointExpansion interface{}
 
-type SandboxExpansion interface{}
+type OperatorStatusExpansion interface{}
 
-type SandboxClaimExpansion interface{}
+type SandboxQuotaExpansion interface{}
 
 type SandboxSetExpansion interface{}
 
 type SandboxTemplateExpansion interface{}
+
+type ScheduledSandboxClaimExpansion interface{}
@@ -0,0 +1,65 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	context "context"
+	fmt "fmt"
+	time "time"
+
+	apiv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+	errors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SandboxExpansion has the methods of SandboxInterface beyond the generated ones.
+type SandboxExpansion interface {
+	// MarkClaimed claims the unclaimed sandbox for the owner the way the sandbox manager does. See MarkSandboxClaimed.
+	MarkClaimed(ctx context.Context, sandbox *apiv1alpha1.Sandbox, owner, lock string, opts v1.UpdateOptions) (*apiv1alpha1.Sandbox, error)
+}
+
+// MarkClaimed claims the unclaimed sandbox for the owner the way the sandbox manager does. See MarkSandboxClaimed.
+func (c *sandboxes) MarkClaimed(ctx context.Context, sandbox *apiv1alpha1.Sandbox, owner, lock string, opts v1.UpdateOptions) (*apiv1alpha1.Sandbox, error) {
+	return MarkSandboxClaimed(ctx, c, sandbox, owner, lock, opts)
+}
+
+// MarkSandboxClaimed claims the unclaimed sandbox for the owner with the client: the sandbox is locked with the lock,
+// labeled as claimed and detached from its SandboxSet, so that the pool scales up.
+// The update is conditioned on the resourceVersion of the given sandbox, so that only one of the concurrent callers
+// claiming the same sandbox succeeds, and the others get a Conflict error to pick another sandbox. A sandbox already
+// claimed or locked gives a Conflict error as well.
+func MarkSandboxClaimed(ctx context.Context, c SandboxInterface, sandbox *apiv1alpha1.Sandbox, owner, lock string, opts v1.UpdateOptions) (*apiv1alpha1.Sandbox, error) {
+	if sandbox.ResourceVersion == "" {
+		return nil, fmt.Errorf("sandbox %s has no resourceVersion to claim it at", sandbox.Name)
+	}
+	if sandbox.Labels[apiv1alpha1.LabelSandboxIsClaimed] == apiv1alpha1.True || sandbox.Annotations[apiv1alpha1.AnnotationLock] != "" {
+		return nil, errors.NewConflict(apiv1alpha1.Resource("sandboxes"), sandbox.Name, fmt.Errorf("sandbox is already claimed"))
+	}
+	claimed := sandbox.DeepCopy()
+	claimed.OwnerReferences = nil
+	if claimed.Labels == nil {
+		claimed.Labels = make(map[string]string, 1)
+	}
+	claimed.Labels[apiv1alpha1.LabelSandboxIsClaimed] = apiv1alpha1.True
+	if claimed.Annotations == nil {
+		claimed.Annotations = make(map[string]string, 3)
+	}
+	claimed.Annotations[apiv1alpha1.AnnotationLock] = lock
+	claimed.Annotations[apiv1alpha1.AnnotationOwner] = owner
+	claimed.Annotations[apiv1alpha1.AnnotationClaimTime] = time.Now().Format(time.RFC3339)
+	return c.Update(ctx, claimed, opts)
+}
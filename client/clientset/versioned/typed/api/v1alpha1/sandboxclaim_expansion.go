@@ -0,0 +1,112 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	context "context"
+	json "encoding/json"
+	time "time"
+
+	apiv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+	errors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fields "k8s.io/apimachinery/pkg/fields"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	cache "k8s.io/client-go/tools/cache"
+	watchtools "k8s.io/client-go/tools/watch"
+)
+
+// SandboxClaimExpansion has the methods of SandboxClaimInterface beyond the generated ones.
+type SandboxClaimExpansion interface {
+	// WaitForPhase waits for the claim to reach the phase. See WaitForSandboxClaimPhase.
+	WaitForPhase(ctx context.Context, name string, phase apiv1alpha1.SandboxClaimPhase) (*apiv1alpha1.SandboxClaim, error)
+	// RenewLease renews the lease of the claim from now. See RenewSandboxClaimLease.
+	RenewLease(ctx context.Context, name string) (*apiv1alpha1.SandboxClaim, error)
+}
+
+// WaitForPhase waits for the claim to reach the phase. See WaitForSandboxClaimPhase.
+func (c *sandboxClaims) WaitForPhase(ctx context.Context, name string, phase apiv1alpha1.SandboxClaimPhase) (*apiv1alpha1.SandboxClaim, error) {
+	return WaitForSandboxClaimPhase(ctx, c, name, phase)
+}
+
+// RenewLease renews the lease of the claim from now. See RenewSandboxClaimLease.
+func (c *sandboxClaims) RenewLease(ctx context.Context, name string) (*apiv1alpha1.SandboxClaim, error) {
+	return RenewSandboxClaimLease(ctx, c, name, time.Now())
+}
+
+// RenewSandboxClaimLease renews the lease of the claim with spec.leaseDuration, so that its sandboxes are kept for
+// another spec.leaseDuration from now, by setting the lease-renew-time annotation to now. Renewing a claim without a
+// lease has no effect.
+func RenewSandboxClaimLease(ctx context.Context, c SandboxClaimInterface, name string, now time.Time) (*apiv1alpha1.SandboxClaim, error) {
+	patch, err := json.Marshal(map[string]any{
+		"metadata": map[string]any{
+			"annotations": map[string]string{
+				apiv1alpha1.AnnotationClaimLeaseRenewTime: now.UTC().Format(time.RFC3339),
+			},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return c.Patch(ctx, name, types.MergePatchType, patch, v1.PatchOptions{})
+}
+
+// WaitForSandboxClaimPhase watches the claim with the client until it reaches the phase and returns it. The claim is
+// listed before it is watched from the same resourceVersion, so that no change in between is missed, and the watch is
+// resumed if closed. It fails with a NotFound error if the claim is not found or deleted before reaching the phase,
+// and with the error of the context once done.
+func WaitForSandboxClaimPhase(ctx context.Context, c SandboxClaimInterface, name string, phase apiv1alpha1.SandboxClaimPhase) (*apiv1alpha1.SandboxClaim, error) {
+	fieldSelector := fields.OneTermEqualSelector("metadata.name", name).String()
+	lw := cache.ToListWatcherWithWatchListSemantics(&cache.ListWatch{
+		ListWithContextFunc: func(ctx context.Context, options v1.ListOptions) (runtime.Object, error) {
+			options.FieldSelector = fieldSelector
+			return c.List(ctx, options)
+		},
+		WatchFuncWithContext: func(ctx context.Context, options v1.ListOptions) (watch.Interface, error) {
+			options.FieldSelector = fieldSelector
+			return c.Watch(ctx, options)
+		},
+	}, c)
+	notFound := errors.NewNotFound(apiv1alpha1.Resource("sandboxclaims"), name)
+	precondition := func(store cache.Store) (bool, error) {
+		for _, obj := range store.List() {
+			if claim, ok := obj.(*apiv1alpha1.SandboxClaim); ok && claim.Name == name {
+				return false, nil
+			}
+		}
+		return false, notFound
+	}
+	event, err := watchtools.UntilWithSync(ctx, lw, &apiv1alpha1.SandboxClaim{}, precondition, func(event watch.Event) (bool, error) {
+		claim, ok := event.Object.(*apiv1alpha1.SandboxClaim)
+		if !ok || claim.Name != name {
+			return false, nil
+		}
+		if event.Type == watch.Deleted {
+			return false, notFound
+		}
+		return claim.Status.Phase == phase, nil
+	})
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, err
+	}
+	return event.Object.(*apiv1alpha1.SandboxClaim), nil
+}
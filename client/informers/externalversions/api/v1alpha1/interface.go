@@ -25,14 +25,20 @@ import (
 type Interface interface {
 	// Checkpoints returns a CheckpointInformer.
 	Checkpoints() CheckpointInformer
+	// OperatorStatuses returns a OperatorStatusInformer.
+	OperatorStatuses() OperatorStatusInformer
 	// Sandboxes returns a SandboxInformer.
 	Sandboxes() SandboxInformer
 	// SandboxClaims returns a SandboxClaimInformer.
 	SandboxClaims() SandboxClaimInformer
+	// SandboxQuotas returns a SandboxQuotaInformer.
+	SandboxQuotas() SandboxQuotaInformer
 	// SandboxSets returns a SandboxSetInformer.
 	SandboxSets() SandboxSetInformer
 	// SandboxTemplates returns a SandboxTemplateInformer.
 	SandboxTemplates() SandboxTemplateInformer
+	// ScheduledSandboxClaims returns a ScheduledSandboxClaimInformer.
+	ScheduledSandboxClaims() ScheduledSandboxClaimInformer
 }
 
 type version struct {
@@ -51,6 +57,11 @@ func (v *version) Checkpoints() CheckpointInformer {
 	return &checkpointInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
 
+// OperatorStatuses returns a OperatorStatusInformer.
+func (v *version) OperatorStatuses() OperatorStatusInformer {
+	return &operatorStatusInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
+}
+
 // Sandboxes returns a SandboxInformer.
 func (v *version) Sandboxes() SandboxInformer {
 	return &sandboxInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
@@ -61,6 +72,11 @@ func (v *version) SandboxClaims() SandboxClaimInformer {
 	return &sandboxClaimInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
 
+// SandboxQuotas returns a SandboxQuotaInformer.
+func (v *version) SandboxQuotas() SandboxQuotaInformer {
+	return &sandboxQuotaInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
 // SandboxSets returns a SandboxSetInformer.
 func (v *version) SandboxSets() SandboxSetInformer {
 	return &sandboxSetInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
@@ -70,3 +86,8 @@ func (v *version) SandboxSets() SandboxSetInformer {
 func (v *version) SandboxTemplates() SandboxTemplateInformer {
 	return &sandboxTemplateInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
+
+// ScheduledSandboxClaims returns a ScheduledSandboxClaimInformer.
+func (v *version) ScheduledSandboxClaims() ScheduledSandboxClaimInformer {
+	return &scheduledSandboxClaimInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
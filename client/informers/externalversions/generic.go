@@ -54,14 +54,20 @@ func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource
 	// Group=api, Version=v1alpha1
 	case v1alpha1.SchemeGroupVersion.WithResource("checkpoints"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Api().V1alpha1().Checkpoints().Informer()}, nil
+	case v1alpha1.SchemeGroupVersion.WithResource("operatorstatuses"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Api().V1alpha1().OperatorStatuses().Informer()}, nil
 	case v1alpha1.SchemeGroupVersion.WithResource("sandboxes"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Api().V1alpha1().Sandboxes().Informer()}, nil
 	case v1alpha1.SchemeGroupVersion.WithResource("sandboxclaims"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Api().V1alpha1().SandboxClaims().Informer()}, nil
+	case v1alpha1.SchemeGroupVersion.WithResource("sandboxquotas"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Api().V1alpha1().SandboxQuotas().Informer()}, nil
 	case v1alpha1.SchemeGroupVersion.WithResource("sandboxsets"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Api().V1alpha1().SandboxSets().Informer()}, nil
 	case v1alpha1.SchemeGroupVersion.WithResource("sandboxtemplates"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Api().V1alpha1().SandboxTemplates().Informer()}, nil
+	case v1alpha1.SchemeGroupVersion.WithResource("scheduledsandboxclaims"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Api().V1alpha1().ScheduledSandboxClaims().Informer()}, nil
 
 	}
 
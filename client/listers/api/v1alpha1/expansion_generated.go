@@ -25,6 +25,10 @@ type CheckpointListerExpansion interface{}
 // CheckpointNamespaceLister.
 type CheckpointNamespaceListerExpansion interface{}
 
+// OperatorStatusListerExpansion allows custom methods to be added to
+// OperatorStatusLister.
+type OperatorStatusListerExpansion interface{}
+
 // SandboxListerExpansion allows custom methods to be added to
 // SandboxLister.
 type SandboxListerExpansion interface{}
@@ -41,6 +45,14 @@ type SandboxClaimListerExpansion interface{}
 // SandboxClaimNamespaceLister.
 type SandboxClaimNamespaceListerExpansion interface{}
 
+// SandboxQuotaListerExpansion allows custom methods to be added to
+// SandboxQuotaLister.
+type SandboxQuotaListerExpansion interface{}
+
+// SandboxQuotaNamespaceListerExpansion allows custom methods to be added to
+// SandboxQuotaNamespaceLister.
+type SandboxQuotaNamespaceListerExpansion interface{}
+
 // SandboxSetListerExpansion allows custom methods to be added to
 // SandboxSetLister.
 type SandboxSetListerExpansion interface{}
@@ -56,3 +68,11 @@ type SandboxTemplateListerExpansion interface{}
 // SandboxTemplateNamespaceListerExpansion allows custom methods to be added to
 // SandboxTemplateNamespaceLister.
 type SandboxTemplateNamespaceListerExpansion interface{}
+
+// ScheduledSandboxClaimListerExpansion allows custom methods to be added to
+// ScheduledSandboxClaimLister.
+type ScheduledSandboxClaimListerExpansion interface{}
+
+// ScheduledSandboxClaimNamespaceListerExpansion allows custom methods to be added to
+// ScheduledSandboxClaimNamespaceLister.
+type ScheduledSandboxClaimNamespaceListerExpansion interface{}
@@ -0,0 +1,45 @@
+# Integration tests of the generated Python client, run against the sandbox-manager at $SANDBOX_MANAGER_URL
+# with `make test-sdk-clients`. The template in $SANDBOX_TEMPLATE must have available sandboxes.
+import os
+
+import pytest
+
+from kruise_agents_client import ApiClient, Configuration, NewSandbox, SandboxesApi, TemplatesApi
+from kruise_agents_client.exceptions import NotFoundException
+
+SERVER = os.environ.get("SANDBOX_MANAGER_URL", "")
+API_KEY = os.environ.get("SANDBOX_MANAGER_API_KEY", "")
+TEMPLATE = os.environ.get("SANDBOX_TEMPLATE", "")
+
+pytestmark = pytest.mark.skipif(not SERVER or not TEMPLATE,
+                                reason="SANDBOX_MANAGER_URL and SANDBOX_TEMPLATE are required")
+
+
+@pytest.fixture
+def client():
+    configuration = Configuration(host=SERVER.rstrip("/") + "/kruise/api", api_key={"ApiKeyAuth": API_KEY})
+    with ApiClient(configuration) as api_client:
+        yield api_client
+
+
+def test_template_exists(client):
+    template = TemplatesApi(client).get_template(TEMPLATE)
+    assert template.template_id == TEMPLATE
+
+
+def test_claim_describe_release(client):
+    sandboxes = SandboxesApi(client)
+    sandbox = sandboxes.create_sandbox(NewSandbox(template_id=TEMPLATE, timeout=60))
+    try:
+        assert sandbox.sandbox_id
+        assert sandbox.template_id == TEMPLATE
+
+        described = sandboxes.describe_sandbox(sandbox.sandbox_id)
+        assert described.sandbox_id == sandbox.sandbox_id
+        assert described.envd_access_token
+        assert any(s.sandbox_id == sandbox.sandbox_id for s in sandboxes.list_sandboxes())
+    finally:
+        sandboxes.delete_sandbox(sandbox.sandbox_id)
+
+    with pytest.raises(NotFoundException):
+        sandboxes.describe_sandbox(sandbox.sandbox_id)
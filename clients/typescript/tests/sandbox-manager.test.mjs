@@ -0,0 +1,42 @@
+// Integration tests of the generated TypeScript client, run against the sandbox-manager at $SANDBOX_MANAGER_URL
+// with `make test-sdk-clients`. The template in $SANDBOX_TEMPLATE must have available sandboxes.
+import assert from 'node:assert/strict';
+import { test } from 'node:test';
+
+import { Configuration, ResponseError, SandboxesApi, TemplatesApi } from '@openkruise/agents-client';
+
+const server = process.env.SANDBOX_MANAGER_URL ?? '';
+const apiKey = process.env.SANDBOX_MANAGER_API_KEY ?? '';
+const template = process.env.SANDBOX_TEMPLATE ?? '';
+const skip = !server || !template ? 'SANDBOX_MANAGER_URL and SANDBOX_TEMPLATE are required' : false;
+
+const configuration = new Configuration({
+  basePath: server.replace(/\/$/, '') + '/kruise/api',
+  apiKey: () => apiKey,
+});
+
+test('template exists', { skip }, async () => {
+  const got = await new TemplatesApi(configuration).getTemplate({ templateID: template });
+  assert.equal(got.templateID, template);
+});
+
+test('claim, describe and release a sandbox', { skip }, async () => {
+  const sandboxes = new SandboxesApi(configuration);
+  const sandbox = await sandboxes.createSandbox({ newSandbox: { templateID: template, timeout: 60 } });
+  try {
+    assert.ok(sandbox.sandboxID);
+    assert.equal(sandbox.templateID, template);
+
+    const described = await sandboxes.describeSandbox({ sandboxID: sandbox.sandboxID });
+    assert.equal(described.sandboxID, sandbox.sandboxID);
+    assert.ok(described.envdAccessToken);
+    const listed = await sandboxes.listSandboxes({});
+    assert.ok(listed.some((s) => s.sandboxID === sandbox.sandboxID));
+  } finally {
+    await sandboxes.deleteSandbox({ sandboxID: sandbox.sandboxID });
+  }
+
+  await assert.rejects(sandboxes.describeSandbox({ sandboxID: sandbox.sandboxID }), (err) => {
+    return err instanceof ResponseError && err.response.status === 404;
+  });
+});
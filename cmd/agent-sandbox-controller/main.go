@@ -45,12 +45,21 @@ import (
 	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
 	"github.com/openkruise/agents/client"
 	"github.com/openkruise/agents/pkg/controller"
+	claimarchive "github.com/openkruise/agents/pkg/controller/sandboxclaim/archive"
 	"github.com/openkruise/agents/pkg/features"
+	"github.com/openkruise/agents/pkg/preflight"
 	"github.com/openkruise/agents/pkg/utils"
+	"github.com/openkruise/agents/pkg/utils/cachetransform"
+	"github.com/openkruise/agents/pkg/utils/controllermetrics"
 	utilfeature "github.com/openkruise/agents/pkg/utils/feature"
 	"github.com/openkruise/agents/pkg/utils/fieldindex"
+	"github.com/openkruise/agents/pkg/utils/objectsize"
+	"github.com/openkruise/agents/pkg/utils/sandboxid"
 	customwebhook "github.com/openkruise/agents/pkg/webhook"
+	"github.com/openkruise/agents/pkg/webhook/faultinjection"
+	claimpolicy "github.com/openkruise/agents/pkg/webhook/sandboxclaim/policy"
 	"github.com/openkruise/agents/pkg/webhook/sandboxset/mutating"
+	"github.com/openkruise/agents/pkg/webhook/standalone"
 )
 
 var (
@@ -120,6 +129,13 @@ func main() {
 	}
 	opts.BindFlags(flag.CommandLine)
 	utilfeature.DefaultMutableFeatureGate.AddFlag(pflag.CommandLine)
+	cachetransform.DefaultOptions.AddFlags(pflag.CommandLine)
+	claimpolicy.DefaultOptions.AddFlags(pflag.CommandLine)
+	claimarchive.DefaultOptions.AddFlags(pflag.CommandLine)
+	faultinjection.DefaultOptions.AddFlags(pflag.CommandLine)
+	objectsize.DefaultOptions.AddFlags(pflag.CommandLine)
+	sandboxid.DefaultOptions.AddFlags(pflag.CommandLine)
+	standalone.DefaultOptions.AddFlags(pflag.CommandLine)
 	klog.InitFlags(nil)
 	pflag.CommandLine.AddGoFlagSet(flag.CommandLine)
 	pflag.Parse()
@@ -131,6 +147,10 @@ func main() {
 		setupLog.Error(err, "unable to start")
 		os.Exit(1)
 	}
+	if err := sandboxid.DefaultOptions.Apply(); err != nil {
+		setupLog.Error(err, "invalid sandbox ID scheme")
+		os.Exit(1)
+	}
 
 	// Start pprof server if enabled
 	if enablePprof {
@@ -188,6 +208,10 @@ func main() {
 		BindAddress:   metricsAddr,
 		SecureServing: secureMetrics,
 		TLSOpts:       tlsOpts,
+		// Serve the OpenMetrics format, so that exemplars of the controller metrics can be scraped
+		ExtraHandlers: map[string]http.Handler{
+			controllermetrics.OpenMetricsPath: controllermetrics.OpenMetricsHandler(),
+		},
 	}
 
 	if secureMetrics {
@@ -224,7 +248,9 @@ func main() {
 		setupLog.Error(err, "unable to set up client")
 		os.Exit(1)
 	}
-	cacheOptions := ctrlcache.Options{}
+	cacheOptions := ctrlcache.Options{
+		DefaultTransform: cachetransform.DefaultOptions.TransformFunc(),
+	}
 	if utilfeature.DefaultFeatureGate.Enabled(features.CachePodLabelSelectorGate) {
 		podLabelReq, err := labels.NewRequirement(utils.PodLabelCreatedBy, selection.Exists, nil)
 		if err != nil {
@@ -280,6 +306,12 @@ func main() {
 		os.Exit(1)
 	}
 
+	setupLog.Info("setup preflight checks")
+	if err := preflight.SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to setup preflight checks")
+		os.Exit(1)
+	}
+
 	// nolint:goconst
 	if os.Getenv("ENABLE_WEBHOOKS") != "false" {
 		if err := customwebhook.SetupWithManager(setupLog, mgr); err != nil {
@@ -287,6 +319,9 @@ func main() {
 			os.Exit(1)
 		}
 	}
+	if standalone.DefaultOptions.Enabled {
+		setupLog.Info("controllers default and validate objects in the standalone validation mode")
+	}
 	// +kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
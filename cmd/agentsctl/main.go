@@ -0,0 +1,43 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/openkruise/agents/pkg/agentsctl"
+)
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	err := agentsctl.NewCommand().ExecuteContext(ctx)
+	stop()
+	if err == nil {
+		return
+	}
+	var exitErr *agentsctl.ExitError
+	if errors.As(err, &exitErr) {
+		os.Exit(int(exitErr.Code))
+	}
+	_, _ = fmt.Fprintln(os.Stderr, "Error:", err)
+	os.Exit(1)
+}
@@ -0,0 +1,146 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"text/tabwriter"
+
+	"github.com/spf13/pflag"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/kubernetes"
+	_ "k8s.io/client-go/plugin/pkg/client/auth" // Import all Kubernetes client auth plugins (Azure, GCP, OIDC, etc.)
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+	"github.com/openkruise/agents/pkg/preflight"
+	utilfeature "github.com/openkruise/agents/pkg/utils/feature"
+	"github.com/openkruise/agents/pkg/utils/webhookutils"
+)
+
+var scheme = runtime.NewScheme()
+
+func init() {
+	utilruntime.Must(agentsv1alpha1.AddToScheme(scheme))
+}
+
+// preflight verifies the prerequisites of the cluster for the operator, and prints a report of the checks. It exits
+// with 1 if any check failed, and with 2 if the checks could not be run.
+func main() {
+	var output string
+	var checks []string
+	var startupOnly bool
+	env := &preflight.Env{FeatureGate: utilfeature.DefaultFeatureGate}
+	var serviceAccount string
+
+	pflag.StringVarP(&output, "output", "o", "text", "Output format of the report, one of text or json.")
+	pflag.StringSliceVar(&checks, "checks", nil, "Checks to run, all of them if empty.")
+	pflag.BoolVar(&startupOnly, "startup-only", false, "Only run the checks the controller manager runs at startup.")
+	pflag.StringVar(&env.Namespace, "namespace", webhookutils.GetNamespace(), "Namespace the operator is deployed in.")
+	pflag.StringVar(&env.WebhookService, "webhook-service", webhookutils.GetServiceName(), "Service of the webhook server.")
+	pflag.StringVar(&serviceAccount, "service-account", "sandbox-controller-manager",
+		"Service account of the controller manager in the namespace the RBAC is checked for, the current user if empty.")
+	utilfeature.DefaultMutableFeatureGate.AddFlag(pflag.CommandLine)
+	klog.InitFlags(nil)
+	pflag.CommandLine.AddGoFlagSet(flag.CommandLine)
+	pflag.Parse()
+
+	if output != "text" && output != "json" {
+		exitOnError(fmt.Errorf("unknown output %q, must be one of text or json", output))
+	}
+	if serviceAccount != "" {
+		env.User = fmt.Sprintf("system:serviceaccount:%s:%s", env.Namespace, serviceAccount)
+	}
+	selected, err := selectChecks(checks, startupOnly)
+	exitOnError(err)
+
+	config, err := ctrl.GetConfig()
+	exitOnError(err)
+	if env.Kube, err = kubernetes.NewForConfig(config); err != nil {
+		exitOnError(err)
+	}
+	if env.Discovery, err = discovery.NewDiscoveryClientForConfig(config); err != nil {
+		exitOnError(err)
+	}
+	if env.Reader, err = ctrlclient.New(config, ctrlclient.Options{Scheme: scheme}); err != nil {
+		exitOnError(err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	report := preflight.Run(ctx, env, selected)
+	stop()
+	exitOnError(printReport(report, output))
+	if !report.Passed {
+		os.Exit(1)
+	}
+}
+
+// selectChecks returns the checks to run by their names
+func selectChecks(names []string, startupOnly bool) ([]preflight.Check, error) {
+	candidates := preflight.Checks
+	if startupOnly {
+		candidates = preflight.StartupChecks()
+	}
+	if len(names) == 0 {
+		return candidates, nil
+	}
+	wanted := sets.New(names...)
+	var selected []preflight.Check
+	for _, check := range candidates {
+		if wanted.Has(check.Name) {
+			selected = append(selected, check)
+			wanted.Delete(check.Name)
+		}
+	}
+	if wanted.Len() > 0 {
+		return nil, fmt.Errorf("unknown checks: %v", sets.List(wanted))
+	}
+	return selected, nil
+}
+
+// printReport prints the report in the output format
+func printReport(report *preflight.Report, output string) error {
+	if output == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "CHECK\tRESULT\tMESSAGE")
+	for _, check := range report.Checks {
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\n", check.Name, check.Result, check.Message)
+	}
+	return w.Flush()
+}
+
+func exitOnError(err error) {
+	if err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(2)
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"flag"
 	"net/http"         // Added for pprof server
 	_ "net/http/pprof" // Added to register pprof handlers
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/spf13/pflag"
@@ -28,12 +29,17 @@ import (
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 
+	"github.com/openkruise/agents/pkg/proxy"
+	sandbox_manager "github.com/openkruise/agents/pkg/sandbox-manager"
 	"github.com/openkruise/agents/pkg/sandbox-manager/clients"
 	"github.com/openkruise/agents/pkg/sandbox-manager/consts"
+	"github.com/openkruise/agents/pkg/sandbox-manager/infra/sandboxcr"
 	"github.com/openkruise/agents/pkg/servers/e2b"
 	"github.com/openkruise/agents/pkg/servers/e2b/models"
 	"github.com/openkruise/agents/pkg/utils"
+	"github.com/openkruise/agents/pkg/utils/cachetransform"
 	utilfeature "github.com/openkruise/agents/pkg/utils/feature"
+	"github.com/openkruise/agents/pkg/utils/sandboxid"
 )
 
 func main() {
@@ -57,8 +63,16 @@ func main() {
 	var kubeClientQPS float64
 	var kubeClientBurst int
 	var memberlistBindPort int
+	var heartbeatPersistInterval time.Duration
 
 	utilfeature.DefaultMutableFeatureGate.AddFlag(pflag.CommandLine)
+	cachetransform.DefaultOptions.AddFlags(pflag.CommandLine)
+	sandboxcr.DefaultSnapshotOptions.AddFlags(pflag.CommandLine)
+	sandbox_manager.DefaultSecurityAlertOptions.AddFlags(pflag.CommandLine)
+	sandbox_manager.DefaultShutdownOptions.AddFlags(pflag.CommandLine)
+	sandbox_manager.DefaultIntrospectionOptions.AddFlags(pflag.CommandLine)
+	proxy.DefaultTrafficMetricsOptions.AddFlags(pflag.CommandLine)
+	sandboxid.DefaultOptions.AddFlags(pflag.CommandLine)
 
 	// Register the new pprof flags
 	pflag.BoolVar(&enablePprof, "enable-pprof", false, "Enable pprof profiling")
@@ -80,6 +94,7 @@ func main() {
 	pflag.Float64Var(&kubeClientQPS, "kube-client-qps", 500, "QPS for Kubernetes client")
 	pflag.IntVar(&kubeClientBurst, "kube-client-burst", 1000, "Burst for Kubernetes client")
 	pflag.IntVar(&memberlistBindPort, "memberlist-bind-port", 7946, "Port for memberlist gossip (default 7946)")
+	pflag.DurationVar(&heartbeatPersistInterval, "heartbeat-persist-interval", consts.DefaultHeartbeatPersistInterval, "Minimum interval to persist sandbox heartbeats into the Sandbox status")
 
 	opts := zap.Options{
 		Development: false,
@@ -114,6 +129,10 @@ func main() {
 		klog.Fatalf("--peer-selector is required")
 	}
 
+	if err := sandboxid.DefaultOptions.Apply(); err != nil {
+		klog.Fatalf("invalid sandbox ID scheme: %v", err)
+	}
+
 	// Generate admin key if not provided
 	if e2bAdminKey == "" {
 		e2bAdminKey = uuid.NewString()
@@ -144,6 +163,18 @@ func main() {
 		klog.Fatalf("--kube-client-burst must be greater than 0")
 	}
 
+	if heartbeatPersistInterval < 0 {
+		klog.Fatalf("--heartbeat-persist-interval must be non-negative")
+	}
+
+	if err := sandbox_manager.DefaultSecurityAlertOptions.Validate(); err != nil {
+		klog.Fatalf("Invalid security alert options: %v", err)
+	}
+
+	if err := sandbox_manager.DefaultShutdownOptions.Validate(); err != nil {
+		klog.Fatalf("Invalid shutdown options: %v", err)
+	}
+
 	// Initialize Kubernetes client and config
 	clientSet, err := clients.NewClientSetWithOptions(float32(kubeClientQPS), kubeClientBurst)
 	if err != nil {
@@ -151,7 +182,7 @@ func main() {
 	}
 
 	sandboxController := e2b.NewController(domain, e2bAdminKey, sysNs, sandboxNamespace, sandboxLabelSelector, e2bMaxTimeout, maxClaimWorkers, maxCreateQPS, uint32(extProcMaxConcurrency),
-		port, e2bEnableAuth, memberlistBindPort, clientSet)
+		port, e2bEnableAuth, memberlistBindPort, heartbeatPersistInterval, clientSet)
 	if err := sandboxController.Init(); err != nil {
 		klog.Fatalf("Failed to initialize sandbox controller: %v", err)
 	}
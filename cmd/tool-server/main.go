@@ -0,0 +1,70 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/pflag"
+	"k8s.io/klog/v2"
+
+	"github.com/openkruise/agents/pkg/servers/tools"
+)
+
+func main() {
+	var port int
+	var sandboxManager string
+
+	pflag.IntVar(&port, "port", 8090, "The port the server listens on")
+	pflag.StringVar(&sandboxManager, "sandbox-manager", "", "Address of the sandbox-manager, e.g. http://sandbox-manager.sandbox-system:8080 (required)")
+	klog.InitFlags(nil)
+	pflag.CommandLine.AddGoFlagSet(flag.CommandLine)
+	pflag.Parse()
+
+	if sandboxManager == "" {
+		klog.Fatalf("--sandbox-manager is required")
+	}
+
+	server := &http.Server{
+		Addr:              fmt.Sprintf(":%d", port),
+		Handler:           tools.NewServer(tools.NewSandboxManagerConnector(sandboxManager)).Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			klog.ErrorS(err, "Failed to shutdown tool server")
+		}
+	}()
+	klog.InfoS("Starting tool server", "port", port, "sandboxManager", sandboxManager)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		klog.Fatalf("Tool server failed: %v", err)
+	}
+	klog.Info("Tool server stopped")
+}
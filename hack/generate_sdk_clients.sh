@@ -0,0 +1,58 @@
+#!/usr/bin/env bash
+# Copyright (c) 2025 Alibaba Group Holding Ltd.
+
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+
+#      http://www.apache.org/licenses/LICENSE-2.0
+
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+# Generates the Python and TypeScript clients into ./clients:
+#   - the sandbox-manager management API from api/openapi/sandbox-manager.yaml, with openapi-generator;
+#   - the envd process API (exec inside sandboxes) from the upstream envd protobuf files, with buf.
+set -o errexit
+set -o nounset
+set -o pipefail
+
+SCRIPT_ROOT=$(cd "$(dirname "${BASH_SOURCE[0]}")/.." && pwd)
+CLIENTS_DIR="${SCRIPT_ROOT}/clients"
+
+OPENAPI_GENERATOR_IMAGE=${OPENAPI_GENERATOR_IMAGE:-"openapitools/openapi-generator-cli:v7.10.0"}
+BUF_IMAGE=${BUF_IMAGE:-"bufbuild/buf:1.47.2"}
+CLIENT_VERSION=${CLIENT_VERSION:-"0.1.0"}
+# ENVD_PROTO_REF is the ref of https://github.com/e2b-dev/infra that the envd protobuf files are fetched from,
+# keep it consistent with the envd version in proto/envd.
+ENVD_PROTO_REF=${ENVD_PROTO_REF:-"main"}
+
+openapi_generate() {
+  local generator=$1 output=$2 properties=$3
+  rm -rf "${CLIENTS_DIR:?}/${output}/generated"
+  docker run --rm -u "$(id -u):$(id -g)" -v "${SCRIPT_ROOT}:/local" "${OPENAPI_GENERATOR_IMAGE}" generate \
+    -i /local/api/openapi/sandbox-manager.yaml \
+    -g "${generator}" \
+    -o "/local/clients/${output}/generated" \
+    --additional-properties="${properties}"
+}
+
+echo "generate openapi clients"
+openapi_generate python python \
+  "packageName=kruise_agents_client,projectName=kruise-agents-client,packageVersion=${CLIENT_VERSION}"
+openapi_generate typescript-fetch typescript \
+  "npmName=@openkruise/agents-client,npmVersion=${CLIENT_VERSION},supportsES6=true"
+
+echo "generate envd clients"
+PROTO_DIR=$(mktemp -d)
+trap 'rm -rf "${PROTO_DIR}"' EXIT
+mkdir -p "${PROTO_DIR}/process"
+curl -fsSL -o "${PROTO_DIR}/process/process.proto" \
+  "https://raw.githubusercontent.com/e2b-dev/infra/${ENVD_PROTO_REF}/packages/envd/spec/process/process.proto"
+cp "${CLIENTS_DIR}/buf.gen.yaml" "${PROTO_DIR}/"
+rm -rf "${CLIENTS_DIR}/python/envd" "${CLIENTS_DIR}/typescript/envd"
+docker run --rm -u "$(id -u):$(id -g)" -v "${PROTO_DIR}:/workspace" -v "${CLIENTS_DIR}:/clients" -w /workspace \
+  "${BUF_IMAGE}" generate --template buf.gen.yaml .
@@ -0,0 +1,365 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package agentsctl implements a command line client of the sandbox-manager API. It only needs the
+// address of the sandbox-manager and an API key, so it can be used where no kubeconfig is available.
+package agentsctl
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"connectrpc.com/connect"
+
+	"github.com/openkruise/agents/pkg/sandbox-manager/consts"
+	"github.com/openkruise/agents/pkg/servers/e2b/adapters"
+	"github.com/openkruise/agents/pkg/servers/e2b/models"
+	"github.com/openkruise/agents/pkg/servers/web"
+	"github.com/openkruise/agents/proto/envd/process"
+	"github.com/openkruise/agents/proto/envd/process/processconnect"
+)
+
+const (
+	// HeaderAPIKey is the header carrying the API key of the sandbox-manager.
+	HeaderAPIKey = "X-API-KEY"
+	// HeaderAccessToken is the header carrying the access token of the agent runtime in a sandbox.
+	HeaderAccessToken = "X-Access-Token"
+	// DefaultUser is the user to run commands and access files as inside the sandbox.
+	DefaultUser = "root"
+)
+
+// Client talks to the sandbox-manager with the customized E2B API, i.e. all requests are sent to one
+// address and sandboxes are addressed by path: <server>/kruise/api/... for the management API and
+// <server>/kruise/<sandboxID>/<port>/... for the agent runtime inside a sandbox.
+type Client struct {
+	Server     string
+	APIKey     string
+	HTTPClient *http.Client
+}
+
+// NewClient creates a Client for the sandbox-manager at server.
+func NewClient(server, apiKey string) *Client {
+	return &Client{
+		Server:     strings.TrimSuffix(server, "/"),
+		APIKey:     apiKey,
+		HTTPClient: http.DefaultClient,
+	}
+}
+
+// Claim claims a sandbox from the template in the request.
+func (c *Client) Claim(ctx context.Context, request *models.NewSandboxRequest) (*models.Sandbox, error) {
+	sbx := &models.Sandbox{}
+	if err := c.doAPI(ctx, http.MethodPost, "/sandboxes", request, sbx); err != nil {
+		return nil, err
+	}
+	return sbx, nil
+}
+
+// Describe returns the sandbox with its runtime access token.
+func (c *Client) Describe(ctx context.Context, sandboxID string) (*models.Sandbox, error) {
+	sbx := &models.Sandbox{}
+	if err := c.doAPI(ctx, http.MethodGet, "/sandboxes/"+url.PathEscape(sandboxID), nil, sbx); err != nil {
+		return nil, err
+	}
+	return sbx, nil
+}
+
+// Release deletes the sandbox.
+func (c *Client) Release(ctx context.Context, sandboxID string) error {
+	return c.doAPI(ctx, http.MethodDelete, "/sandboxes/"+url.PathEscape(sandboxID), nil, nil)
+}
+
+// BulkRelease releases one page of sandboxes, or deletes one page of SandboxClaims, matching the selector in the request.
+func (c *Client) BulkRelease(ctx context.Context, request *models.BulkReleaseRequest) (*models.BulkReleaseResponse, error) {
+	resp := &models.BulkReleaseResponse{}
+	if err := c.doAPI(ctx, http.MethodPost, "/bulk-release", request, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// WaitForClaim waits until the SandboxClaim is completed and returns it with the sandboxes bound to it.
+// It long-polls the sandbox-manager, so ctx is the only timeout.
+func (c *Client) WaitForClaim(ctx context.Context, namespace, name string) (*models.SandboxClaim, error) {
+	path := fmt.Sprintf("/sandboxclaims/%s/%s/wait?timeout=%d", url.PathEscape(namespace), url.PathEscape(name),
+		models.DefaultWaitForClaimTimeoutSeconds)
+	for {
+		claim := &models.SandboxClaim{}
+		if err := c.doAPI(ctx, http.MethodGet, path, nil, claim); err != nil {
+			return nil, err
+		}
+		if claim.Completed {
+			return claim, nil
+		}
+	}
+}
+
+func (c *Client) doAPI(ctx context.Context, method, path string, body, result any) error {
+	var reader io.Reader
+	if body != nil {
+		by, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(by)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.Server+adapters.CustomPrefix+"/api"+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set(HeaderAPIKey, c.APIKey)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if err = checkResponse(resp); err != nil {
+		return fmt.Errorf("%s %s failed: %w", method, path, err)
+	}
+	if result == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(result)
+}
+
+// ResponseError is returned when the sandbox-manager or the agent runtime responds with a non-2xx status code.
+type ResponseError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *ResponseError) Error() string {
+	return fmt.Sprintf("status code %d: %s", e.StatusCode, e.Message)
+}
+
+func checkResponse(resp *http.Response) error {
+	if resp.StatusCode >= http.StatusOK && resp.StatusCode < http.StatusMultipleChoices {
+		return nil
+	}
+	by, _ := io.ReadAll(resp.Body)
+	apiErr := web.ApiError{}
+	if json.Unmarshal(by, &apiErr) == nil && apiErr.Message != "" {
+		return &ResponseError{StatusCode: resp.StatusCode, Message: apiErr.Message}
+	}
+	return &ResponseError{StatusCode: resp.StatusCode, Message: strings.TrimSpace(string(by))}
+}
+
+// Runtime is a connection to the agent runtime of one sandbox.
+type Runtime struct {
+	client      *Client
+	baseURL     string
+	accessToken string
+}
+
+// Runtime returns a connection to the agent runtime of the sandbox, the access token is fetched from the sandbox-manager.
+func (c *Client) Runtime(ctx context.Context, sandboxID string) (*Runtime, error) {
+	sbx, err := c.Describe(ctx, sandboxID)
+	if err != nil {
+		return nil, err
+	}
+	return &Runtime{
+		client:      c,
+		baseURL:     fmt.Sprintf("%s%s/%s/%d", c.Server, adapters.CustomPrefix, url.PathEscape(sandboxID), consts.RuntimePort),
+		accessToken: sbx.EnvdAccessToken,
+	}, nil
+}
+
+func (r *Runtime) processClient() processconnect.ProcessClient {
+	return processconnect.NewProcessClient(r.client.HTTPClient, r.baseURL, connect.WithGRPC())
+}
+
+func (r *Runtime) setHeaders(header http.Header) {
+	header.Set(HeaderAPIKey, r.client.APIKey)
+	header.Set(HeaderAccessToken, r.accessToken)
+	header.Set("Authorization", "Basic cm9vdDo=") // Basic root:
+}
+
+// Exec runs the command in the sandbox and streams its output. The exit code of the process is returned.
+func (r *Runtime) Exec(ctx context.Context, cmd []string, envs map[string]string, cwd string, stdout, stderr io.Writer) (int32, error) {
+	if len(cmd) == 0 {
+		return 0, fmt.Errorf("command is required")
+	}
+	clientContext, callInfo := connect.NewClientContext(ctx)
+	r.setHeaders(callInfo.RequestHeader())
+	config := &process.ProcessConfig{Cmd: cmd[0], Args: cmd[1:], Envs: envs}
+	if cwd != "" {
+		config.Cwd = &cwd
+	}
+	stream, err := r.processClient().Start(clientContext, connect.NewRequest(&process.StartRequest{Process: config}))
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		_ = stream.Close()
+	}()
+	for stream.Receive() {
+		if exitCode, done, err := handleProcessEvent(stream.Msg().GetEvent(), stdout, stderr); done {
+			return exitCode, err
+		}
+	}
+	if err = stream.Err(); err != nil {
+		return 0, err
+	}
+	return 0, fmt.Errorf("process stream closed before the process exited")
+}
+
+// Attach streams the output of a running process and forwards stdin to it until the process exits.
+func (r *Runtime) Attach(ctx context.Context, pid uint32, stdin io.Reader, stdout, stderr io.Writer) (int32, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	client := r.processClient()
+	selector := &process.ProcessSelector{Selector: &process.ProcessSelector_Pid{Pid: pid}}
+
+	clientContext, callInfo := connect.NewClientContext(ctx)
+	r.setHeaders(callInfo.RequestHeader())
+	stream, err := client.Connect(clientContext, connect.NewRequest(&process.ConnectRequest{Process: selector}))
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		_ = stream.Close()
+	}()
+
+	if stdin != nil {
+		go func() {
+			buf := make([]byte, 4096)
+			for {
+				n, readErr := stdin.Read(buf)
+				if n > 0 {
+					req := connect.NewRequest(&process.SendInputRequest{
+						Process: selector,
+						Input:   &process.ProcessInput{Input: &process.ProcessInput_Stdin{Stdin: bytes.Clone(buf[:n])}},
+					})
+					r.setHeaders(req.Header())
+					if _, sendErr := client.SendInput(ctx, req); sendErr != nil {
+						return
+					}
+				}
+				if readErr != nil {
+					return
+				}
+			}
+		}()
+	}
+
+	for stream.Receive() {
+		if exitCode, done, err := handleProcessEvent(stream.Msg().GetEvent(), stdout, stderr); done {
+			return exitCode, err
+		}
+	}
+	if err = stream.Err(); err != nil {
+		return 0, err
+	}
+	return 0, fmt.Errorf("process stream closed before the process exited")
+}
+
+// handleProcessEvent writes the output of a process event, done is true once the process ends.
+func handleProcessEvent(event *process.ProcessEvent, stdout, stderr io.Writer) (exitCode int32, done bool, err error) {
+	switch evt := event.GetEvent().(type) {
+	case *process.ProcessEvent_Data:
+		switch data := evt.Data.GetOutput().(type) {
+		case *process.ProcessEvent_DataEvent_Stdout:
+			_, err = stdout.Write(data.Stdout)
+		case *process.ProcessEvent_DataEvent_Stderr:
+			_, err = stderr.Write(data.Stderr)
+		case *process.ProcessEvent_DataEvent_Pty:
+			_, err = stdout.Write(data.Pty)
+		}
+		return 0, err != nil, err
+	case *process.ProcessEvent_End:
+		if evt.End.Error != nil && !evt.End.Exited {
+			return evt.End.ExitCode, true, fmt.Errorf("process error: %s", *evt.End.Error)
+		}
+		return evt.End.ExitCode, true, nil
+	default: // start and keepalive events
+		return 0, false, nil
+	}
+}
+
+func (r *Runtime) filesURL(path string) string {
+	query := url.Values{}
+	query.Set("path", path)
+	query.Set("username", DefaultUser)
+	return r.baseURL + "/files?" + query.Encode()
+}
+
+// Download writes the content of the file at path inside the sandbox into w.
+func (r *Runtime) Download(ctx context.Context, path string, w io.Writer) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.filesURL(path), nil)
+	if err != nil {
+		return err
+	}
+	r.setHeaders(req.Header)
+	resp, err := r.client.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if err = checkResponse(resp); err != nil {
+		return fmt.Errorf("download %s failed: %w", path, err)
+	}
+	_, err = io.Copy(w, resp.Body)
+	return err
+}
+
+// Upload writes the content of reader into the file at path inside the sandbox.
+func (r *Runtime) Upload(ctx context.Context, path string, reader io.Reader) error {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, err := writer.CreateFormFile("file", path)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(part, reader); err != nil {
+		return err
+	}
+	if err = writer.Close(); err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.filesURL(path), body)
+	if err != nil {
+		return err
+	}
+	r.setHeaders(req.Header)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.Header.Set("Content-Length", strconv.Itoa(body.Len()))
+	resp, err := r.client.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if err = checkResponse(resp); err != nil {
+		return fmt.Errorf("upload %s failed: %w", path, err)
+	}
+	return nil
+}
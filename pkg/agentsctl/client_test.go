@@ -0,0 +1,265 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package agentsctl
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openkruise/agents/pkg/servers/e2b/models"
+	"github.com/openkruise/agents/pkg/servers/web"
+	testutils "github.com/openkruise/agents/test/utils"
+)
+
+const (
+	testAPIKey    = "test-api-key"
+	testSandboxID = "sbx-1"
+)
+
+// newTestServer fakes a sandbox-manager serving the customized E2B API and proxying the runtime of testSandboxID.
+func newTestServer(t *testing.T, runtimeOpts testutils.TestRuntimeServerOptions) (*httptest.Server, map[string][]byte) {
+	files := map[string][]byte{}
+	runtime := testutils.NewTestRuntimeServer(runtimeOpts)
+	t.Cleanup(runtime.Close)
+	runtimeURL, err := url.Parse(runtime.URL)
+	require.NoError(t, err)
+	runtimePrefix := "/kruise/" + testSandboxID + "/49983"
+
+	waits := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("/kruise/api/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(HeaderAPIKey) != testAPIKey {
+			w.WriteHeader(http.StatusUnauthorized)
+			_ = json.NewEncoder(w).Encode(web.ApiError{Code: http.StatusUnauthorized, Message: "Invalid API Key"})
+			return
+		}
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/kruise/api/sandboxes":
+			request := models.NewSandboxRequest{}
+			_ = json.NewDecoder(r.Body).Decode(&request)
+			w.WriteHeader(http.StatusCreated)
+			_ = json.NewEncoder(w).Encode(models.Sandbox{SandboxID: testSandboxID, TemplateID: request.TemplateID})
+		case r.Method == http.MethodGet && r.URL.Path == "/kruise/api/sandboxes/"+testSandboxID:
+			_ = json.NewEncoder(w).Encode(models.Sandbox{SandboxID: testSandboxID, EnvdAccessToken: testutils.AccessToken})
+		case r.Method == http.MethodDelete && r.URL.Path == "/kruise/api/sandboxes/"+testSandboxID:
+			w.WriteHeader(http.StatusNoContent)
+		case r.Method == http.MethodPost && r.URL.Path == "/kruise/api/bulk-release":
+			// two pages of one sandbox each
+			request := models.BulkReleaseRequest{}
+			_ = json.NewDecoder(r.Body).Decode(&request)
+			resp := models.BulkReleaseResponse{Matched: 1, Released: []string{testSandboxID}, Continue: "page-2"}
+			if request.Continue != "" {
+				resp = models.BulkReleaseResponse{Matched: 1, Failed: []models.BulkReleaseFailure{{Name: "sbx-2", Message: "quarantined"}}}
+			}
+			if request.DryRun {
+				resp.Released, resp.Failed = nil, nil
+			}
+			_ = json.NewEncoder(w).Encode(resp)
+		case r.Method == http.MethodGet && r.URL.Path == "/kruise/api/sandboxclaims/default/claim/wait":
+			// completed on the second poll
+			waits++
+			claim := models.SandboxClaim{Namespace: "default", Name: "claim", Phase: "Claiming"}
+			if waits > 1 {
+				claim.Phase, claim.Completed = "Completed", true
+				claim.Sandboxes = []*models.Sandbox{{SandboxID: testSandboxID}}
+			}
+			_ = json.NewEncoder(w).Encode(claim)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_ = json.NewEncoder(w).Encode(web.ApiError{Code: http.StatusNotFound, Message: "sandbox not found"})
+		}
+	})
+	mux.HandleFunc(runtimePrefix+"/files", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(HeaderAccessToken) != testutils.AccessToken {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		path := r.URL.Query().Get("path")
+		switch r.Method {
+		case http.MethodGet:
+			content, ok := files[path]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_, _ = w.Write(content)
+		case http.MethodPost:
+			file, _, err := r.FormFile("file")
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			files[path], _ = io.ReadAll(file)
+		}
+	})
+	proxy := httputil.NewSingleHostReverseProxy(runtimeURL)
+	mux.Handle(runtimePrefix+"/", http.StripPrefix(runtimePrefix, proxy))
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server, files
+}
+
+func TestClient_ClaimAndRelease(t *testing.T) {
+	server, _ := newTestServer(t, testutils.TestRuntimeServerOptions{})
+	ctx := context.Background()
+
+	client := NewClient(server.URL+"/", testAPIKey)
+	sbx, err := client.Claim(ctx, &models.NewSandboxRequest{TemplateID: "python"})
+	require.NoError(t, err)
+	assert.Equal(t, testSandboxID, sbx.SandboxID)
+	assert.Equal(t, "python", sbx.TemplateID)
+
+	sbx, err = client.Describe(ctx, testSandboxID)
+	require.NoError(t, err)
+	assert.Equal(t, testutils.AccessToken, sbx.EnvdAccessToken)
+
+	assert.NoError(t, client.Release(ctx, testSandboxID))
+	err = client.Release(ctx, "not-exist")
+	assert.ErrorContains(t, err, "sandbox not found")
+	var respErr *ResponseError
+	require.ErrorAs(t, err, &respErr)
+	assert.Equal(t, http.StatusNotFound, respErr.StatusCode)
+
+	_, err = NewClient(server.URL, "wrong-key").Claim(ctx, &models.NewSandboxRequest{TemplateID: "python"})
+	assert.ErrorContains(t, err, "Invalid API Key")
+}
+
+func TestClient_WaitForClaim(t *testing.T) {
+	server, _ := newTestServer(t, testutils.TestRuntimeServerOptions{})
+	ctx := context.Background()
+
+	client := NewClient(server.URL, testAPIKey)
+	claim, err := client.WaitForClaim(ctx, "default", "claim")
+	require.NoError(t, err)
+	assert.True(t, claim.Completed)
+	require.Len(t, claim.Sandboxes, 1)
+	assert.Equal(t, testSandboxID, claim.Sandboxes[0].SandboxID)
+
+	_, err = client.WaitForClaim(ctx, "default", "not-exist")
+	assert.ErrorContains(t, err, "status code 404")
+}
+
+func TestBulkRelease(t *testing.T) {
+	server, _ := newTestServer(t, testutils.TestRuntimeServerOptions{})
+	client := NewClient(server.URL, testAPIKey)
+
+	out := &bytes.Buffer{}
+	require.NoError(t, bulkRelease(context.Background(), client, out, &models.BulkReleaseRequest{Resource: "sandboxes", Selector: "team=a", DryRun: true}))
+	assert.Equal(t, "2 sandboxes matched (dry run)\n", out.String())
+
+	out.Reset()
+	err := bulkRelease(context.Background(), client, out, &models.BulkReleaseRequest{Resource: "sandboxes", Selector: "team=a"})
+	assert.EqualError(t, err, "failed to release 1 sandboxes")
+	assert.Equal(t, "sandbox sbx-1 released\nsandbox sbx-2 failed: quarantined\n2 sandboxes matched, 1 released, 1 failed\n", out.String())
+}
+
+func TestRuntime_Exec(t *testing.T) {
+	tests := []struct {
+		name         string
+		result       testutils.RunCommandResult
+		expectCode   int32
+		expectStdout string
+		expectStderr string
+	}{
+		{
+			name: "succeeded",
+			result: testutils.RunCommandResult{
+				Stdout: []string{"hello ", "world"},
+				Exited: true,
+			},
+			expectStdout: "hello world",
+		},
+		{
+			name: "failed with exit code",
+			result: testutils.RunCommandResult{
+				Stderr:   []string{"no such file"},
+				ExitCode: 2,
+				Exited:   true,
+			},
+			expectCode:   2,
+			expectStderr: "no such file",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, _ := newTestServer(t, testutils.TestRuntimeServerOptions{
+				RunCommandResult:      tt.result,
+				RunCommandImmediately: true,
+			})
+			ctx := context.Background()
+			runtime, err := NewClient(server.URL, testAPIKey).Runtime(ctx, testSandboxID)
+			require.NoError(t, err)
+
+			stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+			code, err := runtime.Exec(ctx, []string{"cat", "/tmp/a"}, nil, "", stdout, stderr)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectCode, code)
+			assert.Equal(t, tt.expectStdout, stdout.String())
+			assert.Equal(t, tt.expectStderr, stderr.String())
+		})
+	}
+}
+
+func TestRuntime_UploadAndDownload(t *testing.T) {
+	server, files := newTestServer(t, testutils.TestRuntimeServerOptions{})
+	ctx := context.Background()
+	runtime, err := NewClient(server.URL, testAPIKey).Runtime(ctx, testSandboxID)
+	require.NoError(t, err)
+
+	require.NoError(t, runtime.Upload(ctx, "/workspace/main.py", strings.NewReader("print('hi')")))
+	assert.Equal(t, "print('hi')", string(files["/workspace/main.py"]))
+
+	out := &bytes.Buffer{}
+	require.NoError(t, runtime.Download(ctx, "/workspace/main.py", out))
+	assert.Equal(t, "print('hi')", out.String())
+
+	err = runtime.Download(ctx, "/not-exist", out)
+	assert.ErrorContains(t, err, "status code 404")
+}
+
+func TestParseCopyPath(t *testing.T) {
+	tests := []struct {
+		arg           string
+		expectSandbox string
+		expectPath    string
+	}{
+		{arg: "sbx-1:/workspace/a.txt", expectSandbox: "sbx-1", expectPath: "/workspace/a.txt"},
+		{arg: "./a.txt", expectPath: "./a.txt"},
+		{arg: "/tmp/a:b.txt", expectPath: "/tmp/a:b.txt"},
+		{arg: "a.txt", expectPath: "a.txt"},
+		{arg: "-", expectPath: "-"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.arg, func(t *testing.T) {
+			sandboxID, path := ParseCopyPath(tt.arg)
+			assert.Equal(t, tt.expectSandbox, sandboxID)
+			assert.Equal(t, tt.expectPath, path)
+		})
+	}
+}
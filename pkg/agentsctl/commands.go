@@ -0,0 +1,372 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package agentsctl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/openkruise/agents/pkg/servers/e2b/models"
+)
+
+const (
+	// EnvServer is the environment variable of the default --server.
+	EnvServer = "AGENTSCTL_SERVER"
+	// EnvAPIKey is the environment variable of the default --api-key.
+	EnvAPIKey = "AGENTSCTL_API_KEY"
+)
+
+// ExitError is returned when a remote process exits with a non-zero code, so that
+// agentsctl can exit with the same code.
+type ExitError struct {
+	Code int32
+}
+
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("command exited with code %d", e.Code)
+}
+
+type globalOptions struct {
+	server  string
+	apiKey  string
+	timeout time.Duration
+}
+
+func (o *globalOptions) client() (*Client, error) {
+	if o.server == "" {
+		return nil, fmt.Errorf("--server or $%s is required", EnvServer)
+	}
+	return NewClient(o.server, o.apiKey), nil
+}
+
+func (o *globalOptions) context(cmd *cobra.Command) (context.Context, context.CancelFunc) {
+	if o.timeout > 0 {
+		return context.WithTimeout(cmd.Context(), o.timeout)
+	}
+	return context.WithCancel(cmd.Context())
+}
+
+// NewCommand returns the root command of agentsctl.
+func NewCommand() *cobra.Command {
+	opts := &globalOptions{}
+	cmd := &cobra.Command{
+		Use:           "agentsctl",
+		Short:         "agentsctl operates sandboxes through the sandbox-manager API",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	cmd.PersistentFlags().StringVar(&opts.server, "server", os.Getenv(EnvServer), "Address of the sandbox-manager, e.g. https://sandbox-manager.example.com")
+	cmd.PersistentFlags().StringVar(&opts.apiKey, "api-key", os.Getenv(EnvAPIKey), "API key of the sandbox-manager")
+	cmd.PersistentFlags().DurationVar(&opts.timeout, "request-timeout", 0, "Timeout of the whole command, 0 means no timeout")
+
+	cmd.AddCommand(
+		newClaimCommand(opts),
+		newExecCommand(opts),
+		newCopyCommand(opts),
+		newAttachCommand(opts),
+		newReleaseCommand(opts),
+		newInitCommand(),
+	)
+	return cmd
+}
+
+func newClaimCommand(opts *globalOptions) *cobra.Command {
+	var timeoutSeconds int
+	var envVars, metadata map[string]string
+	var output string
+	cmd := &cobra.Command{
+		Use:   "claim TEMPLATE",
+		Short: "Claim a sandbox from a template and print its ID",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := opts.client()
+			if err != nil {
+				return err
+			}
+			ctx, cancel := opts.context(cmd)
+			defer cancel()
+			sbx, err := client.Claim(ctx, &models.NewSandboxRequest{
+				TemplateID: args[0],
+				Timeout:    timeoutSeconds,
+				EnvVars:    envVars,
+				Metadata:   metadata,
+			})
+			if err != nil {
+				return err
+			}
+			return printSandbox(cmd.OutOrStdout(), sbx, output)
+		},
+	}
+	cmd.Flags().IntVar(&timeoutSeconds, "timeout", 0, "Seconds before the sandbox is released automatically, 0 uses the server default")
+	cmd.Flags().StringToStringVarP(&envVars, "env", "e", nil, "Environment variables of the sandbox, e.g. --env KEY=VALUE")
+	cmd.Flags().StringToStringVar(&metadata, "metadata", nil, "Metadata of the sandbox, e.g. --metadata KEY=VALUE")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Output format, one of: '', json")
+	return cmd
+}
+
+func printSandbox(w io.Writer, sbx *models.Sandbox, output string) error {
+	switch output {
+	case "":
+		_, err := fmt.Fprintln(w, sbx.SandboxID)
+		return err
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(sbx)
+	default:
+		return fmt.Errorf("unknown output format %q", output)
+	}
+}
+
+func newExecCommand(opts *globalOptions) *cobra.Command {
+	var envVars map[string]string
+	var cwd string
+	cmd := &cobra.Command{
+		Use:   "exec SANDBOX -- COMMAND [ARGS...]",
+		Short: "Run a command in a sandbox",
+		Args:  cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := opts.client()
+			if err != nil {
+				return err
+			}
+			ctx, cancel := opts.context(cmd)
+			defer cancel()
+			runtime, err := client.Runtime(ctx, args[0])
+			if err != nil {
+				return err
+			}
+			exitCode, err := runtime.Exec(ctx, args[1:], envVars, cwd, cmd.OutOrStdout(), cmd.ErrOrStderr())
+			if err != nil {
+				return err
+			}
+			if exitCode != 0 {
+				return &ExitError{Code: exitCode}
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringToStringVarP(&envVars, "env", "e", nil, "Environment variables of the command, e.g. --env KEY=VALUE")
+	cmd.Flags().StringVar(&cwd, "cwd", "", "Working directory of the command")
+	return cmd
+}
+
+func newAttachCommand(opts *globalOptions) *cobra.Command {
+	var pid uint32
+	var stdin bool
+	cmd := &cobra.Command{
+		Use:   "attach SANDBOX --pid PID",
+		Short: "Attach to a running process in a sandbox",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := opts.client()
+			if err != nil {
+				return err
+			}
+			ctx, cancel := opts.context(cmd)
+			defer cancel()
+			runtime, err := client.Runtime(ctx, args[0])
+			if err != nil {
+				return err
+			}
+			var in io.Reader
+			if stdin {
+				in = cmd.InOrStdin()
+			}
+			exitCode, err := runtime.Attach(ctx, pid, in, cmd.OutOrStdout(), cmd.ErrOrStderr())
+			if err != nil {
+				return err
+			}
+			if exitCode != 0 {
+				return &ExitError{Code: exitCode}
+			}
+			return nil
+		},
+	}
+	cmd.Flags().Uint32Var(&pid, "pid", 0, "PID of the process to attach to")
+	cmd.Flags().BoolVarP(&stdin, "stdin", "i", false, "Forward stdin to the process")
+	_ = cmd.MarkFlagRequired("pid")
+	return cmd
+}
+
+func newCopyCommand(opts *globalOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cp SRC DST",
+		Short: "Copy a file between the local machine and a sandbox",
+		Long: "Copy a file between the local machine and a sandbox. Remote files are written as SANDBOX:PATH, " +
+			"and '-' means stdin or stdout, e.g.\n" +
+			"  agentsctl cp ./main.py sbx-1:/workspace/main.py\n" +
+			"  agentsctl cp sbx-1:/workspace/result.json -",
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := opts.client()
+			if err != nil {
+				return err
+			}
+			ctx, cancel := opts.context(cmd)
+			defer cancel()
+			srcSandbox, srcPath := ParseCopyPath(args[0])
+			dstSandbox, dstPath := ParseCopyPath(args[1])
+			switch {
+			case srcSandbox == "" && dstSandbox != "":
+				return upload(ctx, client, cmd.InOrStdin(), srcPath, dstSandbox, dstPath)
+			case srcSandbox != "" && dstSandbox == "":
+				return download(ctx, client, cmd.OutOrStdout(), srcSandbox, srcPath, dstPath)
+			default:
+				return fmt.Errorf("exactly one of SRC and DST must be a sandbox path SANDBOX:PATH")
+			}
+		},
+	}
+	return cmd
+}
+
+// ParseCopyPath splits a cp argument into the sandbox ID and the path. The sandbox ID is empty for local paths.
+func ParseCopyPath(arg string) (sandboxID, path string) {
+	i := strings.Index(arg, ":")
+	// absolute and relative local paths may contain colons after the first separator
+	if i <= 0 || strings.ContainsAny(arg[:i], `/\.`) {
+		return "", arg
+	}
+	return arg[:i], arg[i+1:]
+}
+
+func upload(ctx context.Context, client *Client, stdin io.Reader, src, sandboxID, dst string) error {
+	runtime, err := client.Runtime(ctx, sandboxID)
+	if err != nil {
+		return err
+	}
+	if src == "-" {
+		return runtime.Upload(ctx, dst, stdin)
+	}
+	f, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	return runtime.Upload(ctx, dst, f)
+}
+
+func download(ctx context.Context, client *Client, stdout io.Writer, sandboxID, src, dst string) error {
+	runtime, err := client.Runtime(ctx, sandboxID)
+	if err != nil {
+		return err
+	}
+	if dst == "-" {
+		return runtime.Download(ctx, src, stdout)
+	}
+	f, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if err = runtime.Download(ctx, src, f); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func newReleaseCommand(opts *globalOptions) *cobra.Command {
+	bulk := &models.BulkReleaseRequest{}
+	var claims bool
+	cmd := &cobra.Command{
+		Use:   "release {SANDBOX [SANDBOX...] | --selector SELECTOR}",
+		Short: "Release (delete) sandboxes",
+		Long: "Release (delete) sandboxes by ID, or all claimed sandboxes matching a label selector, e.g.\n" +
+			"  agentsctl release sbx-1 sbx-2\n" +
+			"  agentsctl release --selector team=a --namespace default --dry-run\n" +
+			"  agentsctl release --selector team=a --claims --qps 5\n" +
+			"Selectors require the admin API key.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if (len(args) == 0) == (bulk.Selector == "") {
+				return fmt.Errorf("exactly one of SANDBOX arguments and --selector is required")
+			}
+			client, err := opts.client()
+			if err != nil {
+				return err
+			}
+			ctx, cancel := opts.context(cmd)
+			defer cancel()
+			if bulk.Selector != "" {
+				bulk.Resource = "sandboxes"
+				if claims {
+					bulk.Resource = "sandboxclaims"
+				}
+				return bulkRelease(ctx, client, cmd.OutOrStdout(), bulk)
+			}
+			for _, id := range args {
+				if err = client.Release(ctx, id); err != nil {
+					return err
+				}
+				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "sandbox %s released\n", id)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&bulk.Selector, "selector", "l", "", "Release all claimed sandboxes matching the label selector, e.g. team=a")
+	cmd.Flags().StringVarP(&bulk.Namespace, "namespace", "n", "", "Namespace of the selected objects, empty for all namespaces")
+	cmd.Flags().BoolVar(&claims, "claims", false, "Delete the SandboxClaims matching --selector instead of sandboxes")
+	cmd.Flags().BoolVar(&bulk.DryRun, "dry-run", false, "Only count the objects matching --selector")
+	cmd.Flags().Float64Var(&bulk.QPS, "qps", 0, "Max deletions per second of --selector, 0 uses the server default")
+	cmd.Flags().Int64Var(&bulk.Limit, "page-size", 0, "Objects released per request of --selector, 0 uses the server default")
+	return cmd
+}
+
+// bulkRelease releases the objects matching the selector of the request page by page until the last page.
+func bulkRelease(ctx context.Context, client *Client, w io.Writer, request *models.BulkReleaseRequest) error {
+	kind := "sandbox"
+	if request.Resource == "sandboxclaims" {
+		kind = "sandboxclaim"
+	}
+	matched, released, failed := 0, 0, 0
+	for {
+		resp, err := client.BulkRelease(ctx, request)
+		if err != nil {
+			return err
+		}
+		matched += resp.Matched
+		released += len(resp.Released)
+		failed += len(resp.Failed)
+		for _, name := range resp.Released {
+			_, _ = fmt.Fprintf(w, "%s %s released\n", kind, name)
+		}
+		for _, failure := range resp.Failed {
+			_, _ = fmt.Fprintf(w, "%s %s failed: %s\n", kind, failure.Name, failure.Message)
+		}
+		if resp.Continue == "" {
+			break
+		}
+		request.Continue = resp.Continue
+	}
+	if request.DryRun {
+		_, _ = fmt.Fprintf(w, "%d %s matched (dry run)\n", matched, request.Resource)
+		return nil
+	}
+	_, _ = fmt.Fprintf(w, "%d %s matched, %d released, %d failed\n", matched, request.Resource, released, failed)
+	if failed > 0 {
+		return fmt.Errorf("failed to release %d %s", failed, request.Resource)
+	}
+	return nil
+}
@@ -0,0 +1,431 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package agentsctl
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/yaml"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+	"github.com/openkruise/agents/pkg/utils"
+)
+
+// SandboxSize is the resources of each sandbox.
+type SandboxSize struct {
+	CPU    string
+	Memory string
+}
+
+// SandboxSizes are the sizes supported by agentsctl init.
+var SandboxSizes = map[string]SandboxSize{
+	"small":  {CPU: "1", Memory: "2Gi"},
+	"medium": {CPU: "2", Memory: "4Gi"},
+	"large":  {CPU: "4", Memory: "8Gi"},
+}
+
+// IsolationRuntimeClasses maps the isolation levels supported by agentsctl init to RuntimeClasses,
+// an empty RuntimeClass means the default runtime of the cluster.
+var IsolationRuntimeClasses = map[string]string{
+	"container": "",
+	"gvisor":    "gvisor",
+	"kata":      "kata",
+}
+
+// LabelInitName is added to the pods of the generated SandboxSet, it is selected by the generated NetworkPolicy.
+const LabelInitName = "agents.kruise.io/init-name"
+
+// InitOptions is the questionnaire of agentsctl init.
+type InitOptions struct {
+	Name      string
+	Namespace string
+	Image     string
+	Size      string
+	Isolation string
+	// PoolSize is the number of available sandboxes kept warm.
+	PoolSize int32
+	// MaxSandboxes is the quota of sandboxes in the namespace, including the pool and the claimed ones.
+	MaxSandboxes int32
+	// ClaimTimeout is how long a claim waits for sandboxes.
+	ClaimTimeout time.Duration
+	// TTLAfterCompleted is how long a completed claim is kept before it is deleted.
+	TTLAfterCompleted time.Duration
+	// ManagerNamespace is the namespace of sandbox-manager, which is the only namespace allowed to access the sandboxes.
+	ManagerNamespace string
+}
+
+// Validate checks the answers of the questionnaire.
+func (o *InitOptions) Validate() error {
+	if o.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if o.Image == "" {
+		return fmt.Errorf("image is required")
+	}
+	if _, ok := SandboxSizes[o.Size]; !ok {
+		return fmt.Errorf("unknown size %q, must be one of %s", o.Size, strings.Join(sortedKeys(SandboxSizes), ", "))
+	}
+	if _, ok := IsolationRuntimeClasses[o.Isolation]; !ok {
+		return fmt.Errorf("unknown isolation %q, must be one of %s", o.Isolation, strings.Join(sortedKeys(IsolationRuntimeClasses), ", "))
+	}
+	if o.PoolSize < 0 {
+		return fmt.Errorf("pool size must be non-negative")
+	}
+	if o.MaxSandboxes < o.PoolSize {
+		return fmt.Errorf("max sandboxes %d must not be less than pool size %d", o.MaxSandboxes, o.PoolSize)
+	}
+	return nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Ask fills the options interactively, the current values are used as the defaults.
+func (o *InitOptions) Ask(in io.Reader, out io.Writer) error {
+	scanner := bufio.NewScanner(in)
+	ask := func(question, current string) (string, error) {
+		if _, err := fmt.Fprintf(out, "%s [%s]: ", question, current); err != nil {
+			return "", err
+		}
+		if !scanner.Scan() {
+			if err := scanner.Err(); err != nil {
+				return "", err
+			}
+			return current, nil
+		}
+		if answer := strings.TrimSpace(scanner.Text()); answer != "" {
+			return answer, nil
+		}
+		return current, nil
+	}
+	askInt := func(question string, current int32) (int32, error) {
+		answer, err := ask(question, strconv.Itoa(int(current)))
+		if err != nil {
+			return 0, err
+		}
+		value, err := strconv.ParseInt(answer, 10, 32)
+		if err != nil {
+			return 0, fmt.Errorf("invalid answer of %q: %w", question, err)
+		}
+		return int32(value), nil
+	}
+	askDuration := func(question string, current time.Duration) (time.Duration, error) {
+		answer, err := ask(question, current.String())
+		if err != nil {
+			return 0, err
+		}
+		value, err := time.ParseDuration(answer)
+		if err != nil {
+			return 0, fmt.Errorf("invalid answer of %q: %w", question, err)
+		}
+		return value, nil
+	}
+
+	var err error
+	if o.Name, err = ask("Name of the sandbox pool", o.Name); err != nil {
+		return err
+	}
+	if o.Namespace, err = ask("Namespace", o.Namespace); err != nil {
+		return err
+	}
+	if o.Image, err = ask("Sandbox image", o.Image); err != nil {
+		return err
+	}
+	if o.Size, err = ask(fmt.Sprintf("Size (%s)", strings.Join(sortedKeys(SandboxSizes), ", ")), o.Size); err != nil {
+		return err
+	}
+	if o.Isolation, err = ask(fmt.Sprintf("Isolation (%s)", strings.Join(sortedKeys(IsolationRuntimeClasses), ", ")), o.Isolation); err != nil {
+		return err
+	}
+	if o.PoolSize, err = askInt("Number of warm sandboxes", o.PoolSize); err != nil {
+		return err
+	}
+	if o.MaxSandboxes, err = askInt("Maximum number of sandboxes in the namespace", o.MaxSandboxes); err != nil {
+		return err
+	}
+	if o.ClaimTimeout, err = askDuration("Claim timeout", o.ClaimTimeout); err != nil {
+		return err
+	}
+	if o.TTLAfterCompleted, err = askDuration("Time to keep completed claims", o.TTLAfterCompleted); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Manifests generates the SandboxSet, its NetworkPolicy and ResourceQuota, and a SandboxClaim to claim from it.
+func (o *InitOptions) Manifests() ([]client.Object, error) {
+	if err := o.Validate(); err != nil {
+		return nil, err
+	}
+	size := SandboxSizes[o.Size]
+	resources := corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse(size.CPU),
+		corev1.ResourceMemory: resource.MustParse(size.Memory),
+	}
+	podLabels := map[string]string{LabelInitName: o.Name}
+	meta := func(name string) metav1.ObjectMeta {
+		return metav1.ObjectMeta{Name: name, Namespace: o.Namespace}
+	}
+
+	sbs := &agentsv1alpha1.SandboxSet{
+		ObjectMeta: meta(o.Name),
+		Spec: agentsv1alpha1.SandboxSetSpec{
+			Replicas: o.PoolSize,
+			EmbeddedSandboxTemplate: agentsv1alpha1.EmbeddedSandboxTemplate{
+				Template: &corev1.PodTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{Labels: podLabels},
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{{
+							Name:      "sandbox",
+							Image:     o.Image,
+							Resources: corev1.ResourceRequirements{Requests: resources, Limits: resources},
+						}},
+						TerminationGracePeriodSeconds: ptr.To[int64](1),
+					},
+				},
+			},
+		},
+	}
+	if runtimeClass := IsolationRuntimeClasses[o.Isolation]; runtimeClass != "" {
+		sbs.Spec.Template.Spec.RuntimeClassName = ptr.To(runtimeClass)
+	}
+
+	// only sandbox-manager may connect to the sandboxes, the egress is not restricted for agents to access the internet.
+	// Quarantined sandboxes are left to the quarantine policy, which denies all traffic.
+	policy := &networkingv1.NetworkPolicy{
+		ObjectMeta: meta(o.Name),
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{
+				MatchLabels: podLabels,
+				MatchExpressions: []metav1.LabelSelectorRequirement{{
+					Key:      agentsv1alpha1.LabelSandboxQuarantined,
+					Operator: metav1.LabelSelectorOpDoesNotExist,
+				}},
+			},
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+			Ingress: []networkingv1.NetworkPolicyIngressRule{{
+				From: []networkingv1.NetworkPolicyPeer{{
+					NamespaceSelector: &metav1.LabelSelector{
+						MatchLabels: map[string]string{corev1.LabelMetadataName: o.ManagerNamespace},
+					},
+				}},
+			}},
+		},
+	}
+
+	quotaResources := corev1.ResourceList{corev1.ResourcePods: *resource.NewQuantity(int64(o.MaxSandboxes), resource.DecimalSI)}
+	for name, quantity := range resources {
+		total := quantity.DeepCopy()
+		total.Mul(int64(o.MaxSandboxes))
+		quotaResources["requests."+name] = total
+		quotaResources["limits."+name] = total
+	}
+	quota := &corev1.ResourceQuota{
+		ObjectMeta: meta(o.Name),
+		Spec:       corev1.ResourceQuotaSpec{Hard: quotaResources},
+	}
+
+	claim := &agentsv1alpha1.SandboxClaim{
+		ObjectMeta: meta(o.Name),
+		Spec: agentsv1alpha1.SandboxClaimSpec{
+			TemplateName: o.Name,
+			Replicas:     ptr.To[int32](1),
+		},
+	}
+	if o.ClaimTimeout > 0 {
+		claim.Spec.ClaimTimeout = &metav1.Duration{Duration: o.ClaimTimeout}
+	}
+	if o.TTLAfterCompleted > 0 {
+		claim.Spec.TTLAfterCompleted = &metav1.Duration{Duration: o.TTLAfterCompleted}
+	}
+
+	objs := []client.Object{sbs, policy, quota, claim}
+	for _, obj := range objs {
+		if err := setTypeMeta(obj); err != nil {
+			return nil, err
+		}
+	}
+	return objs, nil
+}
+
+// InitScheme contains all kinds generated by agentsctl init.
+var InitScheme = runtime.NewScheme()
+
+func init() {
+	utilruntime.Must(clientgoscheme.AddToScheme(InitScheme))
+	utilruntime.Must(agentsv1alpha1.AddToScheme(InitScheme))
+}
+
+func setTypeMeta(obj client.Object) error {
+	gvk, err := apiutil.GVKForObject(obj, InitScheme)
+	if err != nil {
+		return err
+	}
+	obj.GetObjectKind().SetGroupVersionKind(gvk)
+	return nil
+}
+
+// WriteManifests writes the objects as a multi-document YAML.
+func WriteManifests(w io.Writer, objs []client.Object) error {
+	for i, obj := range objs {
+		data, err := yaml.Marshal(obj)
+		if err != nil {
+			return err
+		}
+		if i > 0 {
+			if _, err = io.WriteString(w, "---\n"); err != nil {
+				return err
+			}
+		}
+		if _, err = w.Write(data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ValidateManifests sends the objects to the API server with server-side dry-run, so that they are checked by
+// the CRD schemas and the admission webhooks without being persisted. Existing objects are validated as updates.
+func ValidateManifests(ctx context.Context, c client.Client, objs []client.Object) error {
+	for _, obj := range objs {
+		gvk := obj.GetObjectKind().GroupVersionKind()
+		err := c.Create(ctx, obj.DeepCopyObject().(client.Object), client.DryRunAll)
+		if apierrors.IsAlreadyExists(err) {
+			err = dryRunUpdate(ctx, c, obj, gvk)
+		}
+		if err != nil {
+			return fmt.Errorf("%s %s is invalid: %w", gvk.Kind, client.ObjectKeyFromObject(obj), err)
+		}
+	}
+	return nil
+}
+
+func dryRunUpdate(ctx context.Context, c client.Client, obj client.Object, gvk schema.GroupVersionKind) error {
+	existing, err := InitScheme.New(gvk)
+	if err != nil {
+		return err
+	}
+	if err = c.Get(ctx, client.ObjectKeyFromObject(obj), existing.(client.Object)); err != nil {
+		return err
+	}
+	updated := obj.DeepCopyObject().(client.Object)
+	updated.SetResourceVersion(existing.(client.Object).GetResourceVersion())
+	return c.Update(ctx, updated, client.DryRunAll)
+}
+
+func newInitCommand() *cobra.Command {
+	opts := &InitOptions{}
+	var interactive, skipValidation bool
+	var kubeconfig, outputFile string
+	cmd := &cobra.Command{
+		Use:   "init [NAME]",
+		Short: "Generate the manifests of a sandbox pool and a claim for it",
+		Long: "Generate a SandboxSet with its NetworkPolicy and ResourceQuota, and a SandboxClaim template, from flags " +
+			"or an interactive questionnaire. The manifests are validated with server-side dry-run before being written.",
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) > 0 {
+				opts.Name = args[0]
+			}
+			if interactive {
+				if err := opts.Ask(cmd.InOrStdin(), cmd.ErrOrStderr()); err != nil {
+					return err
+				}
+			}
+			if opts.MaxSandboxes == 0 {
+				opts.MaxSandboxes = 2 * opts.PoolSize
+			}
+			objs, err := opts.Manifests()
+			if err != nil {
+				return err
+			}
+			if !skipValidation {
+				c, err := newKubeClient(kubeconfig)
+				if err != nil {
+					return fmt.Errorf("failed to connect to the cluster for validation, use --skip-validation to skip: %w", err)
+				}
+				if err = ValidateManifests(cmd.Context(), c, objs); err != nil {
+					return err
+				}
+			}
+			if outputFile == "" || outputFile == "-" {
+				return WriteManifests(cmd.OutOrStdout(), objs)
+			}
+			f, err := os.Create(outputFile)
+			if err != nil {
+				return err
+			}
+			if err = WriteManifests(f, objs); err != nil {
+				_ = f.Close()
+				return err
+			}
+			return f.Close()
+		},
+	}
+	cmd.Flags().StringVarP(&opts.Namespace, "namespace", "n", "default", "Namespace of the sandbox pool")
+	cmd.Flags().StringVar(&opts.Image, "image", "", "Image of the sandboxes")
+	cmd.Flags().StringVar(&opts.Size, "size", "small", fmt.Sprintf("Size of each sandbox, one of %s", strings.Join(sortedKeys(SandboxSizes), ", ")))
+	cmd.Flags().StringVar(&opts.Isolation, "isolation", "container", fmt.Sprintf("Isolation of the sandboxes, one of %s", strings.Join(sortedKeys(IsolationRuntimeClasses), ", ")))
+	cmd.Flags().Int32Var(&opts.PoolSize, "pool-size", 2, "Number of warm sandboxes")
+	cmd.Flags().Int32Var(&opts.MaxSandboxes, "max-sandboxes", 0, "Quota of sandboxes in the namespace, 0 means twice the pool size")
+	cmd.Flags().DurationVar(&opts.ClaimTimeout, "claim-timeout", time.Minute, "How long a claim waits for sandboxes")
+	cmd.Flags().DurationVar(&opts.TTLAfterCompleted, "ttl-after-completed", time.Hour, "How long a completed claim is kept")
+	cmd.Flags().StringVar(&opts.ManagerNamespace, "manager-namespace", utils.DefaultSandboxDeployNamespace, "Namespace of sandbox-manager, which is allowed to access the sandboxes")
+	cmd.Flags().BoolVarP(&interactive, "interactive", "i", false, "Ask for the options interactively")
+	cmd.Flags().BoolVar(&skipValidation, "skip-validation", false, "Do not validate the manifests with server-side dry-run")
+	cmd.Flags().StringVar(&kubeconfig, "kubeconfig", "", "Path of the kubeconfig used for validation, defaults to $KUBECONFIG or ~/.kube/config")
+	cmd.Flags().StringVarP(&outputFile, "output-file", "f", "", "File to write the manifests to, defaults to stdout")
+	return cmd
+}
+
+func newKubeClient(kubeconfig string) (client.Client, error) {
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	loadingRules.ExplicitPath = kubeconfig
+	config, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, &clientcmd.ConfigOverrides{}).ClientConfig()
+	if err != nil {
+		return nil, err
+	}
+	return client.New(config, client.Options{Scheme: InitScheme})
+}
@@ -0,0 +1,227 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package agentsctl
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+)
+
+func newInitOptions() *InitOptions {
+	return &InitOptions{
+		Name:              "python",
+		Namespace:         "default",
+		Image:             "python:3.12",
+		Size:              "medium",
+		Isolation:         "container",
+		PoolSize:          2,
+		MaxSandboxes:      5,
+		ClaimTimeout:      time.Minute,
+		TTLAfterCompleted: time.Hour,
+		ManagerNamespace:  "sandbox-system",
+	}
+}
+
+func TestInitOptions_Manifests(t *testing.T) {
+	tests := []struct {
+		name         string
+		modify       func(o *InitOptions)
+		expectErr    string
+		runtimeClass *string
+	}{
+		{
+			name: "container isolation",
+		},
+		{
+			name:         "gvisor isolation",
+			modify:       func(o *InitOptions) { o.Isolation = "gvisor" },
+			runtimeClass: ptr.To("gvisor"),
+		},
+		{
+			name:      "unknown size",
+			modify:    func(o *InitOptions) { o.Size = "huge" },
+			expectErr: `unknown size "huge", must be one of large, medium, small`,
+		},
+		{
+			name:      "quota less than pool size",
+			modify:    func(o *InitOptions) { o.MaxSandboxes = 1 },
+			expectErr: "max sandboxes 1 must not be less than pool size 2",
+		},
+		{
+			name:      "no image",
+			modify:    func(o *InitOptions) { o.Image = "" },
+			expectErr: "image is required",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := newInitOptions()
+			if tt.modify != nil {
+				tt.modify(opts)
+			}
+			objs, err := opts.Manifests()
+			if tt.expectErr != "" {
+				assert.EqualError(t, err, tt.expectErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, objs, 4)
+
+			sbs := objs[0].(*agentsv1alpha1.SandboxSet)
+			assert.Equal(t, "SandboxSet", sbs.Kind)
+			assert.Equal(t, int32(2), sbs.Spec.Replicas)
+			assert.Equal(t, tt.runtimeClass, sbs.Spec.Template.Spec.RuntimeClassName)
+			container := sbs.Spec.Template.Spec.Containers[0]
+			assert.Equal(t, "python:3.12", container.Image)
+			assert.Equal(t, "4Gi", container.Resources.Limits.Memory().String())
+
+			policy := objs[1].(*networkingv1.NetworkPolicy)
+			assert.Equal(t, sbs.Spec.Template.Labels, policy.Spec.PodSelector.MatchLabels)
+			assert.Equal(t, []metav1.LabelSelectorRequirement{{
+				Key:      agentsv1alpha1.LabelSandboxQuarantined,
+				Operator: metav1.LabelSelectorOpDoesNotExist,
+			}}, policy.Spec.PodSelector.MatchExpressions)
+
+			quota := objs[2].(*corev1.ResourceQuota)
+			assert.Equal(t, "5", ptr.To(quota.Spec.Hard[corev1.ResourcePods]).String())
+			assert.Equal(t, "10", ptr.To(quota.Spec.Hard[corev1.ResourceLimitsCPU]).String())
+			assert.Equal(t, "20Gi", ptr.To(quota.Spec.Hard[corev1.ResourceRequestsMemory]).String())
+
+			claim := objs[3].(*agentsv1alpha1.SandboxClaim)
+			assert.Equal(t, "python", claim.Spec.TemplateName)
+			assert.Equal(t, time.Minute, claim.Spec.ClaimTimeout.Duration)
+			assert.Equal(t, time.Hour, claim.Spec.TTLAfterCompleted.Duration)
+
+			out := &bytes.Buffer{}
+			require.NoError(t, WriteManifests(out, objs))
+			assert.Equal(t, 3, strings.Count(out.String(), "---\n"))
+			assert.Contains(t, out.String(), "kind: SandboxClaim")
+		})
+	}
+}
+
+func TestInitOptions_Ask(t *testing.T) {
+	opts := newInitOptions()
+	// empty answers keep the defaults
+	in := strings.NewReader("code\n\nmy-image\nlarge\nkata\n3\n\n30s\n")
+	out := &bytes.Buffer{}
+	require.NoError(t, opts.Ask(in, out))
+	assert.Equal(t, "code", opts.Name)
+	assert.Equal(t, "default", opts.Namespace)
+	assert.Equal(t, "my-image", opts.Image)
+	assert.Equal(t, "large", opts.Size)
+	assert.Equal(t, "kata", opts.Isolation)
+	assert.Equal(t, int32(3), opts.PoolSize)
+	assert.Equal(t, int32(5), opts.MaxSandboxes)
+	assert.Equal(t, 30*time.Second, opts.ClaimTimeout)
+	assert.Equal(t, time.Hour, opts.TTLAfterCompleted)
+	assert.Contains(t, out.String(), "Sandbox image [python:3.12]: ")
+
+	err := newInitOptions().Ask(strings.NewReader("\n\n\n\n\nmany\n"), out)
+	assert.ErrorContains(t, err, `invalid answer of "Number of warm sandboxes"`)
+}
+
+func TestValidateManifests(t *testing.T) {
+	existing := &corev1.ResourceQuota{}
+	existing.Name, existing.Namespace = "python", "default"
+
+	tests := []struct {
+		name      string
+		rejected  string
+		onUpdate  bool
+		expectErr string
+	}{
+		{
+			name: "valid",
+		},
+		{
+			name:      "rejected by webhook",
+			rejected:  "SandboxSet",
+			expectErr: "SandboxSet default/python is invalid: admission webhook denied the request",
+		},
+		{
+			name:      "existing object is validated as update",
+			rejected:  "ResourceQuota",
+			onUpdate:  true,
+			expectErr: "ResourceQuota default/python is invalid: admission webhook denied the request",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			objs, err := newInitOptions().Manifests()
+			require.NoError(t, err)
+			reject := func(obj client.Object, opts []string, update bool) error {
+				assert.Equal(t, []string{"All"}, opts)
+				if obj.GetObjectKind().GroupVersionKind().Kind == tt.rejected && update == tt.onUpdate {
+					return fmt.Errorf("admission webhook denied the request")
+				}
+				return nil
+			}
+			c := fake.NewClientBuilder().WithScheme(InitScheme).WithObjects(existing.DeepCopy()).WithInterceptorFuncs(interceptor.Funcs{
+				Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+					createOpts := &client.CreateOptions{}
+					createOpts.ApplyOptions(opts)
+					if err := reject(obj, createOpts.DryRun, false); err != nil {
+						return err
+					}
+					// the fake client does not check the existence of objects created with dry-run
+					if c.Get(ctx, client.ObjectKeyFromObject(obj), obj.DeepCopyObject().(client.Object)) == nil {
+						return apierrors.NewAlreadyExists(schema.GroupResource{}, obj.GetName())
+					}
+					return c.Create(ctx, obj, opts...)
+				},
+				Update: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.UpdateOption) error {
+					updateOpts := &client.UpdateOptions{}
+					updateOpts.ApplyOptions(opts)
+					if err := reject(obj, updateOpts.DryRun, true); err != nil {
+						return err
+					}
+					return c.Update(ctx, obj, opts...)
+				},
+			}).Build()
+
+			err = ValidateManifests(context.Background(), c, objs)
+			if tt.expectErr != "" {
+				assert.EqualError(t, err, tt.expectErr)
+				return
+			}
+			require.NoError(t, err)
+			// dry-run must not persist anything
+			list := &agentsv1alpha1.SandboxSetList{}
+			require.NoError(t, c.List(context.Background(), list))
+			assert.Empty(t, list.Items)
+		})
+	}
+}
@@ -22,6 +22,8 @@ import (
 	"github.com/openkruise/agents/pkg/controller/sandbox"
 	"github.com/openkruise/agents/pkg/controller/sandboxclaim"
 	"github.com/openkruise/agents/pkg/controller/sandboxset"
+	"github.com/openkruise/agents/pkg/controller/sandboxtemplate"
+	"github.com/openkruise/agents/pkg/controller/scheduledsandboxclaim"
 )
 
 var controllerAddFuncs []func(manager.Manager) error
@@ -30,6 +32,8 @@ func init() {
 	controllerAddFuncs = append(controllerAddFuncs, sandbox.Add)
 	controllerAddFuncs = append(controllerAddFuncs, sandboxset.Add)
 	controllerAddFuncs = append(controllerAddFuncs, sandboxclaim.Add)
+	controllerAddFuncs = append(controllerAddFuncs, sandboxtemplate.Add)
+	controllerAddFuncs = append(controllerAddFuncs, scheduledsandboxclaim.Add)
 }
 
 func SetupWithManager(m manager.Manager) error {
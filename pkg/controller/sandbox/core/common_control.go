@@ -19,7 +19,6 @@ package core
 import (
 	"context"
 	"fmt"
-	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -34,6 +33,7 @@ import (
 	"github.com/openkruise/agents/pkg/utils"
 	"github.com/openkruise/agents/pkg/utils/expectations"
 	"github.com/openkruise/agents/pkg/utils/inplaceupdate"
+	"github.com/openkruise/agents/pkg/utils/requeue"
 	"github.com/openkruise/agents/pkg/utils/sidecarutils"
 )
 
@@ -44,6 +44,7 @@ type commonControl struct {
 	recorder             record.EventRecorder
 	inplaceUpdateControl *inplaceupdate.InPlaceUpdateControl
 	rateLimiter          *RateLimiter
+	readinessProber      ReadinessProber
 }
 
 func NewCommonControl(c client.Client, recorder record.EventRecorder, rl *RateLimiter) SandboxControl {
@@ -52,19 +53,20 @@ func NewCommonControl(c client.Client, recorder record.EventRecorder, rl *RateLi
 		recorder:             recorder,
 		inplaceUpdateControl: inplaceupdate.NewInPlaceUpdateControl(c, inplaceupdate.DefaultGeneratePatchBodyFunc),
 		rateLimiter:          rl,
+		readinessProber:      NewReadinessProber(),
 	}
 	return control
 }
 
-func (r *commonControl) EnsureSandboxRunning(ctx context.Context, args EnsureFuncArgs) (time.Duration, error) {
+func (r *commonControl) EnsureSandboxRunning(ctx context.Context, args EnsureFuncArgs) (RequeueStrategy, error) {
 	pod, box, newStatus := args.Pod, args.Box, args.NewStatus
 	// If the Pod does not exist, it must first be created.
 	if pod == nil {
 		if requeueAfter, shouldReturn := r.rateLimiter.getRateLimitDuration(ctx, pod, box); shouldReturn {
-			return requeueAfter, nil
+			return requeue.After(requeueAfter).WithReason("CreatePodRateLimited"), nil
 		}
 		_, err := r.createPod(ctx, box, newStatus)
-		return 0, err
+		return requeue.NoRequeue().WithReason("PodCreated"), err
 	}
 
 	// pod status running
@@ -100,20 +102,21 @@ func (r *commonControl) EnsureSandboxRunning(ctx context.Context, args EnsureFun
 			NodeName: pod.Spec.NodeName,
 			PodUID:   pod.UID,
 		}
-		return 0, nil
+		r.ensureReadinessProbe(ctx, args)
+		return requeue.NoRequeue().WithReason("PodRunning"), nil
 	}
 
-	return 0, nil
+	return requeue.NoRequeue().WithReason("WaitingPodRunning"), nil
 }
 
-func (r *commonControl) EnsureSandboxUpdated(ctx context.Context, args EnsureFuncArgs) error {
+func (r *commonControl) EnsureSandboxUpdated(ctx context.Context, args EnsureFuncArgs) (RequeueStrategy, error) {
 	pod, _, newStatus := args.Pod, args.Box, args.NewStatus
 	logger := logf.FromContext(ctx).WithValues("pod", klog.KObj(pod))
 	// If a Pod is no longer present in the Running state, it should be considered an abnormal situation.
 	if pod == nil {
 		newStatus.Phase = agentsv1alpha1.SandboxFailed
 		newStatus.Message = "Sandbox Pod Not Found"
-		return nil
+		return requeue.NoRequeue().WithReason("PodNotFound"), nil
 	}
 
 	// update sandbox status
@@ -128,9 +131,9 @@ func (r *commonControl) EnsureSandboxUpdated(ctx context.Context, args EnsureFun
 	// inplace update
 	done, err := r.handleInplaceUpdateSandbox(ctx, args)
 	if err != nil {
-		return err
+		return requeue.NoRequeue(), err
 	} else if !done {
-		return nil
+		return requeue.NoRequeue().WithReason("InplaceUpdating"), nil
 	}
 
 	pCond := utils.GetPodCondition(&pod.Status, corev1.PodReady)
@@ -147,10 +150,11 @@ func (r *commonControl) EnsureSandboxUpdated(ctx context.Context, args EnsureFun
 		}
 	}
 	utils.SetSandboxCondition(newStatus, *cond)
-	return nil
+	r.ensureReadinessProbe(ctx, args)
+	return requeue.NoRequeue().WithReason("PodRunning"), nil
 }
 
-func (r *commonControl) EnsureSandboxPaused(ctx context.Context, args EnsureFuncArgs) error {
+func (r *commonControl) EnsureSandboxPaused(ctx context.Context, args EnsureFuncArgs) (RequeueStrategy, error) {
 	pod, box, newStatus := args.Pod, args.Box, args.NewStatus
 	logger := logf.FromContext(ctx).WithValues("sandbox", klog.KObj(box), "pod", klog.KObj(pod), "phase", "EnsureSandboxPaused")
 	cond := utils.GetSandboxCondition(newStatus, string(agentsv1alpha1.SandboxConditionPaused))
@@ -165,7 +169,7 @@ func (r *commonControl) EnsureSandboxPaused(ctx context.Context, args EnsureFunc
 		logger.Info("Paused condition initialized")
 	} else if cond.Status == metav1.ConditionTrue {
 		logger.Info("Paused condition is already true")
-		return nil
+		return requeue.NoRequeue().WithReason("Paused"), nil
 	}
 
 	// The paused phase sets condition ready to false.
@@ -183,36 +187,35 @@ func (r *commonControl) EnsureSandboxPaused(ctx context.Context, args EnsureFunc
 		cond.LastTransitionTime = metav1.Now()
 		utils.SetSandboxCondition(newStatus, *cond)
 		logger.Info("Pod deletion completed, pause phase completed")
-		return nil
+		return requeue.NoRequeue().WithReason("Paused"), nil
 	}
 	// Pod deletion incomplete, waiting
 	if pod != nil && !pod.DeletionTimestamp.IsZero() {
 		logger.Info("Sandbox wait pod paused")
-		return nil
+		return requeue.NoRequeue().WithReason("WaitingPodDeleted"), nil
 	}
 	err := client.IgnoreNotFound(r.Delete(ctx, pod, &client.DeleteOptions{GracePeriodSeconds: ptr.To(int64(5))}))
 	if err != nil {
 		logger.Error(err, "Delete pod failed")
-		return err
+		return requeue.NoRequeue(), err
 	}
 	logger.Info("Delete pod success")
-	return nil
+	return requeue.NoRequeue().WithReason("PodDeleted"), nil
 }
 
-func (r *commonControl) EnsureSandboxResumed(ctx context.Context, args EnsureFuncArgs) error {
+func (r *commonControl) EnsureSandboxResumed(ctx context.Context, args EnsureFuncArgs) (RequeueStrategy, error) {
 	pod, box, newStatus := args.Pod, args.Box, args.NewStatus
 
 	// Consider the scenario where a pod is paused and immediately resumed,
 	// pod phase may be Running, but the actual state could be Terminating.
 	if pod != nil && !pod.DeletionTimestamp.IsZero() {
-		return fmt.Errorf("the pods created in the previous stage are still in the terminating state")
+		return requeue.NoRequeue(), fmt.Errorf("the pods created in the previous stage are still in the terminating state")
 	}
 
 	// first create pod
-	var err error
 	if pod == nil {
-		_, err = r.createPod(ctx, box, newStatus)
-		return err
+		_, err := r.createPod(ctx, box, newStatus)
+		return requeue.NoRequeue().WithReason("PodCreated"), err
 	}
 
 	// create pod success, set resumed condition to true
@@ -230,11 +233,13 @@ func (r *commonControl) EnsureSandboxResumed(ctx context.Context, args EnsureFun
 		rCond.Status = metav1.ConditionStatus(pCond.Status)
 		rCond.LastTransitionTime = pCond.LastTransitionTime
 		utils.SetSandboxCondition(newStatus, *rCond)
+		r.ensureReadinessProbe(ctx, args)
+		return requeue.NoRequeue().WithReason("Resumed"), nil
 	}
-	return nil
+	return requeue.NoRequeue().WithReason("WaitingPodReady"), nil
 }
 
-func (r *commonControl) EnsureSandboxTerminated(ctx context.Context, args EnsureFuncArgs) error {
+func (r *commonControl) EnsureSandboxTerminated(ctx context.Context, args EnsureFuncArgs) (RequeueStrategy, error) {
 	pod, box, _ := args.Pod, args.Box, args.NewStatus
 	logger := logf.FromContext(ctx).WithValues("sandbox", klog.KObj(box))
 	var err error
@@ -242,22 +247,22 @@ func (r *commonControl) EnsureSandboxTerminated(ctx context.Context, args Ensure
 		_, err = utils.PatchFinalizer(ctx, r.Client, box, utils.RemoveFinalizerOpType, utils.SandboxFinalizer)
 		if err != nil {
 			logger.Error(err, "update sandbox finalizer failed")
-			return err
+			return requeue.NoRequeue(), err
 		}
 		logger.Info("remove sandbox finalizer success")
-		return nil
+		return requeue.NoRequeue().WithReason("FinalizerRemoved"), nil
 	} else if !pod.DeletionTimestamp.IsZero() {
 		logger.Info("Pod is deleting, and wait a moment")
-		return nil
+		return requeue.NoRequeue().WithReason("WaitingPodDeleted"), nil
 	}
 
 	err = client.IgnoreNotFound(r.Delete(ctx, pod))
 	if err != nil {
 		logger.Error(err, "delete pod failed")
-		return err
+		return requeue.NoRequeue(), err
 	}
 	logger.Info("delete pod success")
-	return nil
+	return requeue.NoRequeue().WithReason("PodDeleted"), nil
 }
 
 func (r *commonControl) createPod(ctx context.Context, box *agentsv1alpha1.Sandbox, newStatus *agentsv1alpha1.SandboxStatus) (*corev1.Pod, error) {
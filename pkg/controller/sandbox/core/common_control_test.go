@@ -47,6 +47,7 @@ func TestCommonControl_EnsureSandboxRunning(t *testing.T) {
 		podExist    bool
 		wantErr     bool
 		wantRequeue time.Duration
+		wantReason  string
 		setupRL     func(rl *RateLimiter) // optional: pre-populate rate limiter
 		featureGate bool
 	}{
@@ -168,6 +169,7 @@ func TestCommonControl_EnsureSandboxRunning(t *testing.T) {
 			podExist:    false,
 			wantErr:     false,
 			wantRequeue: 3 * time.Second,
+			wantReason:  "CreatePodRateLimited",
 		},
 		{
 			name:        "feature gate enabled, high-priority sandbox bypasses rate limit",
@@ -203,6 +205,7 @@ func TestCommonControl_EnsureSandboxRunning(t *testing.T) {
 			podExist:    false,
 			wantErr:     false,
 			wantRequeue: 0,
+			wantReason:  "PodCreated",
 		},
 		{
 			name:        "feature gate disabled, no rate limiting even when threshold exceeded",
@@ -235,6 +238,7 @@ func TestCommonControl_EnsureSandboxRunning(t *testing.T) {
 			podExist:    false,
 			wantErr:     false,
 			wantRequeue: 0,
+			wantReason:  "PodCreated",
 		},
 	}
 
@@ -260,13 +264,16 @@ func TestCommonControl_EnsureSandboxRunning(t *testing.T) {
 				rateLimiter:          rl,
 			}
 
-			requeue, err := control.EnsureSandboxRunning(context.TODO(), tt.args)
+			strategy, err := control.EnsureSandboxRunning(context.TODO(), tt.args)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("EnsureSandboxRunning() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
-			if requeue != tt.wantRequeue {
-				t.Errorf("EnsureSandboxRunning() requeue = %v, want %v", requeue, tt.wantRequeue)
+			if strategy.After != tt.wantRequeue {
+				t.Errorf("EnsureSandboxRunning() requeue = %v, want %v", strategy.After, tt.wantRequeue)
+			}
+			if tt.wantReason != "" && strategy.Reason != tt.wantReason {
+				t.Errorf("EnsureSandboxRunning() reason = %v, want %v", strategy.Reason, tt.wantReason)
 			}
 
 			// Verify that pod was created if it didn't exist and not rate-limited
@@ -446,7 +453,7 @@ func TestCommonControl_EnsureSandboxUpdated(t *testing.T) {
 				inplaceUpdateControl: inplaceupdate.NewInPlaceUpdateControl(client, inplaceupdate.DefaultGeneratePatchBodyFunc),
 			}
 
-			err := control.EnsureSandboxUpdated(context.TODO(), tt.args)
+			_, err := control.EnsureSandboxUpdated(context.TODO(), tt.args)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("EnsureSandboxUpdated() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -588,7 +595,7 @@ func TestCommonControl_EnsureSandboxPaused(t *testing.T) {
 				inplaceUpdateControl: inplaceupdate.NewInPlaceUpdateControl(client, inplaceupdate.DefaultGeneratePatchBodyFunc),
 			}
 
-			err := control.EnsureSandboxPaused(context.TODO(), tt.args)
+			_, err := control.EnsureSandboxPaused(context.TODO(), tt.args)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("EnsureSandboxPaused() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -826,7 +833,7 @@ func TestCommonControl_EnsureSandboxResumed(t *testing.T) {
 				inplaceUpdateControl: inplaceupdate.NewInPlaceUpdateControl(client, inplaceupdate.DefaultGeneratePatchBodyFunc),
 			}
 
-			err := control.EnsureSandboxResumed(context.TODO(), tt.args)
+			_, err := control.EnsureSandboxResumed(context.TODO(), tt.args)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("EnsureSandboxResumed() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -936,7 +943,7 @@ func TestCommonControl_EnsureSandboxTerminated(t *testing.T) {
 				inplaceUpdateControl: inplaceupdate.NewInPlaceUpdateControl(client, inplaceupdate.DefaultGeneratePatchBodyFunc),
 			}
 
-			err := control.EnsureSandboxTerminated(context.TODO(), tt.args)
+			_, err := control.EnsureSandboxTerminated(context.TODO(), tt.args)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("EnsureSandboxTerminated() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -18,7 +18,6 @@ package core
 
 import (
 	"context"
-	"time"
 
 	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
@@ -28,6 +27,7 @@ import (
 	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
 	"github.com/openkruise/agents/pkg/utils/expectations"
 	"github.com/openkruise/agents/pkg/utils/inplaceupdate"
+	"github.com/openkruise/agents/pkg/utils/requeue"
 )
 
 var (
@@ -36,6 +36,9 @@ var (
 	ScaleExpectation            = expectations.NewScaleExpectations()
 )
 
+// RequeueStrategy defines the requeue behavior for controller reconciliation
+type RequeueStrategy = requeue.Strategy
+
 type EnsureFuncArgs struct {
 	Pod       *corev1.Pod
 	Box       *agentsv1alpha1.Sandbox
@@ -44,19 +47,19 @@ type EnsureFuncArgs struct {
 
 type SandboxControl interface {
 	// EnsureSandboxRunning handle sandbox with status phase = Pending
-	EnsureSandboxRunning(ctx context.Context, args EnsureFuncArgs) (time.Duration, error)
+	EnsureSandboxRunning(ctx context.Context, args EnsureFuncArgs) (RequeueStrategy, error)
 
 	// EnsureSandboxUpdated handle sandbox with status phase = Running
-	EnsureSandboxUpdated(ctx context.Context, args EnsureFuncArgs) error
+	EnsureSandboxUpdated(ctx context.Context, args EnsureFuncArgs) (RequeueStrategy, error)
 
 	// EnsureSandboxPaused handle sandbox with status phase = Paused
-	EnsureSandboxPaused(ctx context.Context, args EnsureFuncArgs) error
+	EnsureSandboxPaused(ctx context.Context, args EnsureFuncArgs) (RequeueStrategy, error)
 
 	// EnsureSandboxResumed handle sandbox with status phase = Resuming
-	EnsureSandboxResumed(ctx context.Context, args EnsureFuncArgs) error
+	EnsureSandboxResumed(ctx context.Context, args EnsureFuncArgs) (RequeueStrategy, error)
 
 	// EnsureSandboxTerminated handle sandbox with status phase = Terminating
-	EnsureSandboxTerminated(ctx context.Context, args EnsureFuncArgs) error
+	EnsureSandboxTerminated(ctx context.Context, args EnsureFuncArgs) (RequeueStrategy, error)
 }
 
 func NewSandboxControl(c client.Client, recorder record.EventRecorder, rl *RateLimiter) map[string]SandboxControl {
@@ -0,0 +1,228 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"connectrpc.com/connect"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+	"github.com/openkruise/agents/pkg/sandbox-manager/consts"
+	"github.com/openkruise/agents/pkg/utils"
+	"github.com/openkruise/agents/proto/envd/process"
+	"github.com/openkruise/agents/proto/envd/process/processconnect"
+)
+
+const (
+	// DefaultReadinessProbeTimeout is used when SandboxReadinessProbe.TimeoutSeconds is not set.
+	DefaultReadinessProbeTimeout = time.Second
+	// DefaultReadinessProbePeriod is used when SandboxReadinessProbe.PeriodSeconds is not set.
+	DefaultReadinessProbePeriod = 5 * time.Second
+)
+
+// ReadinessProber evaluates the custom readiness probe of a sandbox against its pod.
+type ReadinessProber interface {
+	Probe(ctx context.Context, box *agentsv1alpha1.Sandbox, podIP string, probe *agentsv1alpha1.SandboxReadinessProbe) error
+}
+
+// NewReadinessProber returns the default ReadinessProber, which supports httpGet, tcpSocket and exec probes.
+func NewReadinessProber() ReadinessProber {
+	return &readinessProber{client: &http.Client{
+		// Do not follow redirects, a 3xx response is already a success.
+		CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+	}}
+}
+
+type readinessProber struct {
+	client *http.Client
+}
+
+func (p *readinessProber) Probe(ctx context.Context, box *agentsv1alpha1.Sandbox, podIP string, probe *agentsv1alpha1.SandboxReadinessProbe) error {
+	if podIP == "" {
+		return fmt.Errorf("sandbox has no pod ip")
+	}
+	ctx, cancel := context.WithTimeout(ctx, GetReadinessProbeTimeout(probe))
+	defer cancel()
+	switch {
+	case probe.HTTPGet != nil:
+		return p.probeHTTP(ctx, podIP, probe.HTTPGet)
+	case probe.TCPSocket != nil:
+		return probeTCP(ctx, podIP, probe.TCPSocket)
+	case probe.Exec != nil:
+		return p.probeExec(ctx, box, podIP, probe.Exec)
+	default:
+		return fmt.Errorf("readiness probe has no handler")
+	}
+}
+
+func (p *readinessProber) probeHTTP(ctx context.Context, podIP string, probe *agentsv1alpha1.SandboxHTTPGetProbe) error {
+	port := probe.Port
+	if port == 0 {
+		port = consts.RuntimePort
+	}
+	path := probe.Path
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	url := fmt.Sprintf("http://%s%s", net.JoinHostPort(podIP, strconv.Itoa(int(port))), path)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("http probe %s failed: %w", url, err)
+	}
+	defer func() {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("http probe %s failed with status code %d", url, resp.StatusCode)
+	}
+	return nil
+}
+
+func probeTCP(ctx context.Context, podIP string, probe *agentsv1alpha1.SandboxTCPSocketProbe) error {
+	addr := net.JoinHostPort(podIP, strconv.Itoa(int(probe.Port)))
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return fmt.Errorf("tcp probe %s failed: %w", addr, err)
+	}
+	return conn.Close()
+}
+
+// probeExec runs the command inside the sandbox through the agent runtime process API.
+func (p *readinessProber) probeExec(ctx context.Context, box *agentsv1alpha1.Sandbox, podIP string, probe *agentsv1alpha1.SandboxExecProbe) error {
+	if len(probe.Command) == 0 {
+		return fmt.Errorf("exec probe has empty command")
+	}
+	url := box.Annotations[agentsv1alpha1.AnnotationRuntimeURL]
+	if url == "" {
+		url = fmt.Sprintf("http://%s", net.JoinHostPort(podIP, strconv.Itoa(consts.RuntimePort)))
+	}
+	client := processconnect.NewProcessClient(p.client, url, connect.WithGRPC())
+	clientContext, callInfo := connect.NewClientContext(ctx)
+	callInfo.RequestHeader().Set("X-Access-Token", box.Annotations[agentsv1alpha1.AnnotationRuntimeAccessToken])
+	callInfo.RequestHeader().Set("Authorization", "Basic cm9vdDo=") // Basic root:
+
+	stream, err := client.Start(clientContext, connect.NewRequest(&process.StartRequest{
+		Process: &process.ProcessConfig{Cmd: probe.Command[0], Args: probe.Command[1:]},
+	}))
+	if err != nil {
+		return fmt.Errorf("exec probe failed to start: %w", err)
+	}
+	defer func() {
+		_ = stream.Close()
+	}()
+	for stream.Receive() {
+		if end, ok := stream.Msg().Event.Event.(*process.ProcessEvent_End); ok {
+			if end.End.Error != nil {
+				return fmt.Errorf("exec probe failed: %s", *end.End.Error)
+			}
+			if end.End.ExitCode != 0 {
+				return fmt.Errorf("exec probe exited with code %d", end.End.ExitCode)
+			}
+			return nil
+		}
+	}
+	if err := stream.Err(); err != nil {
+		return fmt.Errorf("exec probe failed: %w", err)
+	}
+	return fmt.Errorf("exec probe stream closed before the process exited")
+}
+
+// GetReadinessProbeTimeout returns the timeout of a single probe attempt.
+func GetReadinessProbeTimeout(probe *agentsv1alpha1.SandboxReadinessProbe) time.Duration {
+	if probe.TimeoutSeconds > 0 {
+		return time.Duration(probe.TimeoutSeconds) * time.Second
+	}
+	return DefaultReadinessProbeTimeout
+}
+
+// GetReadinessProbePeriod returns the retry interval of a failing probe.
+func GetReadinessProbePeriod(probe *agentsv1alpha1.SandboxReadinessProbe) time.Duration {
+	if probe.PeriodSeconds > 0 {
+		return time.Duration(probe.PeriodSeconds) * time.Second
+	}
+	return DefaultReadinessProbePeriod
+}
+
+// ReadinessProbeRequeueAfter returns how long to wait before retrying a failing readiness probe,
+// or 0 if no retry is needed.
+func ReadinessProbeRequeueAfter(box *agentsv1alpha1.Sandbox, newStatus *agentsv1alpha1.SandboxStatus) time.Duration {
+	if box.Spec.ReadinessProbe == nil || newStatus.Phase != agentsv1alpha1.SandboxRunning {
+		return 0
+	}
+	cond := utils.GetSandboxCondition(newStatus, string(agentsv1alpha1.SandboxConditionReady))
+	if cond == nil || cond.Status != metav1.ConditionFalse || cond.Reason != agentsv1alpha1.SandboxReadyReasonProbeFailed {
+		return 0
+	}
+	return GetReadinessProbePeriod(box.Spec.ReadinessProbe)
+}
+
+// ensureReadinessProbe gates the Ready condition of a running sandbox on its custom readiness probe.
+// The probe is only evaluated while the sandbox is transitioning to Ready, i.e. once it has passed,
+// it is not re-evaluated as long as the pod stays ready.
+func (r *commonControl) ensureReadinessProbe(ctx context.Context, args EnsureFuncArgs) {
+	box, newStatus := args.Box, args.NewStatus
+	probe := box.Spec.ReadinessProbe
+	if probe == nil || r.readinessProber == nil || newStatus.Phase != agentsv1alpha1.SandboxRunning {
+		return
+	}
+	cond := utils.GetSandboxCondition(newStatus, string(agentsv1alpha1.SandboxConditionReady))
+	if cond == nil || cond.Status != metav1.ConditionTrue {
+		return
+	}
+	oldCond := utils.GetSandboxCondition(&box.Status, string(agentsv1alpha1.SandboxConditionReady))
+	if oldCond != nil && oldCond.Status == metav1.ConditionTrue {
+		return
+	}
+	logger := logf.FromContext(ctx).WithValues("sandbox", klog.KObj(box))
+	if err := r.readinessProber.Probe(ctx, box, newStatus.PodInfo.PodIP, probe); err != nil {
+		logger.Info("sandbox readiness probe failed", "err", err.Error())
+		failed := metav1.Condition{
+			Type:               string(agentsv1alpha1.SandboxConditionReady),
+			Status:             metav1.ConditionFalse,
+			Reason:             agentsv1alpha1.SandboxReadyReasonProbeFailed,
+			Message:            err.Error(),
+			LastTransitionTime: metav1.Now(),
+		}
+		if oldCond != nil && oldCond.Status == metav1.ConditionFalse {
+			failed.LastTransitionTime = oldCond.LastTransitionTime
+		}
+		*cond = failed
+		return
+	}
+	logger.Info("sandbox readiness probe succeeded")
+	if cond.Reason == agentsv1alpha1.SandboxReadyReasonProbeFailed {
+		cond.Reason = agentsv1alpha1.SandboxReadyReasonPodReady
+		cond.Message = ""
+	}
+}
@@ -0,0 +1,236 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+	"github.com/openkruise/agents/pkg/utils"
+)
+
+type fakeReadinessProber struct {
+	err   error
+	calls int
+}
+
+func (f *fakeReadinessProber) Probe(context.Context, *agentsv1alpha1.Sandbox, string, *agentsv1alpha1.SandboxReadinessProbe) error {
+	f.calls++
+	return f.err
+}
+
+func splitHostPort(t *testing.T, addr string) (string, int32) {
+	host, portStr, err := net.SplitHostPort(addr)
+	assert.NoError(t, err)
+	port, err := strconv.Atoi(portStr)
+	assert.NoError(t, err)
+	return host, int32(port)
+}
+
+func TestReadinessProber_Probe(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/healthz" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+	host, port := splitHostPort(t, server.Listener.Addr().String())
+
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	_, closedPort := splitHostPort(t, closed.Addr().String())
+	_ = closed.Close()
+
+	tests := []struct {
+		name    string
+		podIP   string
+		probe   *agentsv1alpha1.SandboxReadinessProbe
+		wantErr bool
+	}{
+		{
+			name:  "http probe succeeds",
+			podIP: host,
+			probe: &agentsv1alpha1.SandboxReadinessProbe{
+				HTTPGet: &agentsv1alpha1.SandboxHTTPGetProbe{Path: "healthz", Port: port},
+			},
+		},
+		{
+			name:  "http probe fails on bad status code",
+			podIP: host,
+			probe: &agentsv1alpha1.SandboxReadinessProbe{
+				HTTPGet: &agentsv1alpha1.SandboxHTTPGetProbe{Path: "/not-ready", Port: port},
+			},
+			wantErr: true,
+		},
+		{
+			name:  "tcp probe succeeds",
+			podIP: host,
+			probe: &agentsv1alpha1.SandboxReadinessProbe{
+				TCPSocket: &agentsv1alpha1.SandboxTCPSocketProbe{Port: port},
+			},
+		},
+		{
+			name:  "tcp probe fails on closed port",
+			podIP: "127.0.0.1",
+			probe: &agentsv1alpha1.SandboxReadinessProbe{
+				TCPSocket: &agentsv1alpha1.SandboxTCPSocketProbe{Port: closedPort},
+			},
+			wantErr: true,
+		},
+		{
+			name: "no pod ip",
+			probe: &agentsv1alpha1.SandboxReadinessProbe{
+				TCPSocket: &agentsv1alpha1.SandboxTCPSocketProbe{Port: port},
+			},
+			wantErr: true,
+		},
+		{
+			name:    "no handler",
+			podIP:   host,
+			probe:   &agentsv1alpha1.SandboxReadinessProbe{},
+			wantErr: true,
+		},
+	}
+
+	prober := NewReadinessProber()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := prober.Probe(context.TODO(), &agentsv1alpha1.Sandbox{}, tt.podIP, tt.probe)
+			assert.Equal(t, tt.wantErr, err != nil, "err: %v", err)
+		})
+	}
+}
+
+func TestEnsureReadinessProbe(t *testing.T) {
+	past := metav1.NewTime(time.Now().Add(-time.Minute))
+	probe := &agentsv1alpha1.SandboxReadinessProbe{
+		TCPSocket: &agentsv1alpha1.SandboxTCPSocketProbe{Port: 8080},
+	}
+	readyCond := func(status metav1.ConditionStatus, reason string) metav1.Condition {
+		return metav1.Condition{
+			Type:               string(agentsv1alpha1.SandboxConditionReady),
+			Status:             status,
+			Reason:             reason,
+			LastTransitionTime: past,
+		}
+	}
+
+	tests := []struct {
+		name         string
+		probe        *agentsv1alpha1.SandboxReadinessProbe
+		oldConds     []metav1.Condition
+		newCond      metav1.Condition
+		probeErr     error
+		expectCalls  int
+		expectStatus metav1.ConditionStatus
+		expectReason string
+		expectRetry  bool
+	}{
+		{
+			name:         "no probe configured",
+			newCond:      readyCond(metav1.ConditionTrue, agentsv1alpha1.SandboxReadyReasonPodReady),
+			expectStatus: metav1.ConditionTrue,
+			expectReason: agentsv1alpha1.SandboxReadyReasonPodReady,
+		},
+		{
+			name:         "pod not ready, probe skipped",
+			probe:        probe,
+			newCond:      readyCond(metav1.ConditionFalse, agentsv1alpha1.SandboxReadyReasonPodReady),
+			expectStatus: metav1.ConditionFalse,
+			expectReason: agentsv1alpha1.SandboxReadyReasonPodReady,
+		},
+		{
+			name:         "already ready, probe skipped",
+			probe:        probe,
+			oldConds:     []metav1.Condition{readyCond(metav1.ConditionTrue, agentsv1alpha1.SandboxReadyReasonPodReady)},
+			newCond:      readyCond(metav1.ConditionTrue, agentsv1alpha1.SandboxReadyReasonPodReady),
+			probeErr:     errors.New("should not be called"),
+			expectStatus: metav1.ConditionTrue,
+			expectReason: agentsv1alpha1.SandboxReadyReasonPodReady,
+		},
+		{
+			name:         "probe succeeds",
+			probe:        probe,
+			newCond:      readyCond(metav1.ConditionTrue, agentsv1alpha1.SandboxReadyReasonPodReady),
+			expectCalls:  1,
+			expectStatus: metav1.ConditionTrue,
+			expectReason: agentsv1alpha1.SandboxReadyReasonPodReady,
+		},
+		{
+			name:         "probe fails",
+			probe:        probe,
+			newCond:      readyCond(metav1.ConditionTrue, agentsv1alpha1.SandboxReadyReasonPodReady),
+			probeErr:     errors.New("connection refused"),
+			expectCalls:  1,
+			expectStatus: metav1.ConditionFalse,
+			expectReason: agentsv1alpha1.SandboxReadyReasonProbeFailed,
+			expectRetry:  true,
+		},
+		{
+			name:         "probe recovers after failure",
+			probe:        probe,
+			oldConds:     []metav1.Condition{readyCond(metav1.ConditionFalse, agentsv1alpha1.SandboxReadyReasonProbeFailed)},
+			newCond:      readyCond(metav1.ConditionTrue, agentsv1alpha1.SandboxReadyReasonProbeFailed),
+			expectCalls:  1,
+			expectStatus: metav1.ConditionTrue,
+			expectReason: agentsv1alpha1.SandboxReadyReasonPodReady,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prober := &fakeReadinessProber{err: tt.probeErr}
+			control := &commonControl{readinessProber: prober}
+			box := &agentsv1alpha1.Sandbox{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-sandbox", Namespace: "default"},
+				Spec:       agentsv1alpha1.SandboxSpec{ReadinessProbe: tt.probe},
+				Status: agentsv1alpha1.SandboxStatus{
+					Phase:      agentsv1alpha1.SandboxRunning,
+					Conditions: tt.oldConds,
+				},
+			}
+			newStatus := box.Status.DeepCopy()
+			newStatus.PodInfo.PodIP = "10.0.0.1"
+			utils.SetSandboxCondition(newStatus, tt.newCond)
+
+			control.ensureReadinessProbe(context.TODO(), EnsureFuncArgs{Box: box, NewStatus: newStatus})
+
+			assert.Equal(t, tt.expectCalls, prober.calls)
+			cond := utils.GetSandboxCondition(newStatus, string(agentsv1alpha1.SandboxConditionReady))
+			assert.Equal(t, tt.expectStatus, cond.Status)
+			assert.Equal(t, tt.expectReason, cond.Reason)
+			requeueAfter := ReadinessProbeRequeueAfter(box, newStatus)
+			if tt.expectRetry {
+				assert.Equal(t, DefaultReadinessProbePeriod, requeueAfter)
+			} else {
+				assert.Zero(t, requeueAfter)
+			}
+		})
+	}
+}
@@ -22,6 +22,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/metrics"
 
 	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+	"github.com/openkruise/agents/pkg/utils/sandboxutils"
 )
 
 var (
@@ -140,6 +141,26 @@ var (
 		[]string{"namespace", "name"},
 	)
 
+	// sandboxResumeLatency observes how long resuming the paused sandboxes takes, from spec.paused being unset to
+	// being ready again, by template.
+	sandboxResumeLatency = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "sandbox_resume_latency_seconds",
+			Help:    "Latency of resuming paused sandboxes from spec.paused being unset to Ready, by template",
+			Buckets: []float64{0.5, 1, 2, 5, 10, 20, 30, 60, 120, 300},
+		},
+		[]string{"namespace", "template"},
+	)
+
+	// sandboxRequeueTotal counts the requeue decisions of the Sandbox reconciler.
+	sandboxRequeueTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "sandbox_requeue_total",
+			Help: "Total number of requeue decisions made by the Sandbox reconciler, by strategy and reason",
+		},
+		[]string{"strategy", "reason"},
+	)
+
 	// allPhases enumerates all possible sandbox phases for metric cleanup.
 	allPhases = []agentsv1alpha1.SandboxPhase{
 		agentsv1alpha1.SandboxPending,
@@ -166,6 +187,8 @@ func init() {
 		sandboxStatusUnresumed,
 		sandboxStatusUnresumedTime,
 		sandboxInfo,
+		sandboxResumeLatency,
+		sandboxRequeueTotal,
 	)
 }
 
@@ -242,6 +265,14 @@ func recordSandboxMetrics(sandbox *agentsv1alpha1.Sandbox) {
 	}
 }
 
+// recordResumeLatency observes the latency of the resume the sandbox has just finished.
+func recordResumeLatency(sandbox *agentsv1alpha1.Sandbox) {
+	if latency, ok := sandboxutils.ResumeLatency(sandbox); ok {
+		sandboxResumeLatency.WithLabelValues(sandbox.Namespace, sandbox.Labels[agentsv1alpha1.LabelSandboxTemplate]).
+			Observe(latency.Seconds())
+	}
+}
+
 // deleteSandboxMetrics removes all metrics for a sandbox that has been deleted.
 func deleteSandboxMetrics(namespace, name string) {
 	sandboxInfo.DeletePartialMatch(prometheus.Labels{"namespace": namespace, "name": name})
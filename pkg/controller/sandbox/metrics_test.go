@@ -22,6 +22,7 @@ import (
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
@@ -609,3 +610,39 @@ func TestRecordSandboxMetrics_InfoNoOwner(t *testing.T) {
 		t.Errorf("sandbox_info with no owner = %v, want 1", val)
 	}
 }
+
+func TestRecordResumeLatency(t *testing.T) {
+	start := metav1.NewTime(time.Now().Add(-time.Minute))
+	sandbox := &agentsv1alpha1.Sandbox{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-sandbox",
+			Namespace: "default",
+			Labels:    map[string]string{agentsv1alpha1.LabelSandboxTemplate: "resume-template"},
+		},
+		Status: agentsv1alpha1.SandboxStatus{
+			Phase:           agentsv1alpha1.SandboxRunning,
+			ResumeStartTime: &start,
+			Conditions: []metav1.Condition{
+				{
+					Type:               string(agentsv1alpha1.SandboxConditionReady),
+					Status:             metav1.ConditionTrue,
+					LastTransitionTime: metav1.NewTime(start.Add(3 * time.Second)),
+				},
+			},
+		},
+	}
+	defer sandboxResumeLatency.DeleteLabelValues("default", "resume-template")
+
+	recordResumeLatency(sandbox)
+
+	m := &dto.Metric{}
+	if err := sandboxResumeLatency.WithLabelValues("default", "resume-template").(prometheus.Metric).Write(m); err != nil {
+		t.Fatal(err)
+	}
+	if got := m.GetHistogram().GetSampleCount(); got != 1 {
+		t.Errorf("sandbox_resume_latency_seconds sample count = %v, want 1", got)
+	}
+	if got := m.GetHistogram().GetSampleSum(); got != 3 {
+		t.Errorf("sandbox_resume_latency_seconds sample sum = %v, want 3", got)
+	}
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sandbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+	"github.com/openkruise/agents/pkg/utils"
+)
+
+// +kubebuilder:rbac:groups=networking.k8s.io,resources=networkpolicies,verbs=get;list;watch;create
+
+// ensureQuarantine cuts the pod of a quarantined sandbox from the network by labeling it into the selector of the
+// quarantine NetworkPolicy, and reflects the quarantine in the Quarantined condition. Lifting the quarantine removes
+// the label and the condition.
+func (r *SandboxReconciler) ensureQuarantine(ctx context.Context, box *agentsv1alpha1.Sandbox, pod *corev1.Pod, newStatus *agentsv1alpha1.SandboxStatus) error {
+	logger := logf.FromContext(ctx)
+	quarantine := box.Spec.Quarantine
+	if quarantine == nil {
+		if pod != nil && pod.Labels[agentsv1alpha1.LabelSandboxQuarantined] != "" {
+			if err := r.patchPodQuarantineLabel(ctx, pod, nil); err != nil {
+				return err
+			}
+			logger.Info("quarantine lifted, pod label removed")
+		}
+		utils.RemoveSandboxCondition(newStatus, string(agentsv1alpha1.SandboxConditionQuarantined))
+		return nil
+	}
+
+	if err := r.ensureQuarantineNetworkPolicy(ctx, box.Namespace); err != nil {
+		return err
+	}
+	reason := agentsv1alpha1.SandboxQuarantinedReasonPodNotFound
+	if pod != nil {
+		if pod.Labels[agentsv1alpha1.LabelSandboxQuarantined] != "true" {
+			value := "true"
+			if err := r.patchPodQuarantineLabel(ctx, pod, &value); err != nil {
+				return err
+			}
+			logger.Info("sandbox quarantined, pod isolated from network", "reason", quarantine.Reason, "source", quarantine.Source)
+		}
+		reason = agentsv1alpha1.SandboxQuarantinedReasonNetworkIsolated
+	}
+	message := quarantine.Reason
+	if quarantine.Source != "" {
+		message = fmt.Sprintf("%s: %s", quarantine.Source, quarantine.Reason)
+	}
+	utils.SetSandboxCondition(newStatus, metav1.Condition{
+		Type:    string(agentsv1alpha1.SandboxConditionQuarantined),
+		Status:  metav1.ConditionTrue,
+		Reason:  reason,
+		Message: message,
+	})
+	return nil
+}
+
+// patchPodQuarantineLabel sets the quarantine label of the pod to value, or removes it if value is nil
+func (r *SandboxReconciler) patchPodQuarantineLabel(ctx context.Context, pod *corev1.Pod, value *string) error {
+	patch := map[string]any{"metadata": map[string]any{"labels": map[string]any{agentsv1alpha1.LabelSandboxQuarantined: value}}}
+	by, err := json.Marshal(patch)
+	if err != nil {
+		return err
+	}
+	return client.IgnoreNotFound(r.Patch(ctx, pod, client.RawPatch(types.MergePatchType, by)))
+}
+
+// ensureQuarantineNetworkPolicy creates the NetworkPolicy denying all ingress and egress traffic of the quarantined
+// pods in the namespace. It is shared by all quarantined sandboxes of the namespace and is never deleted.
+func (r *SandboxReconciler) ensureQuarantineNetworkPolicy(ctx context.Context, namespace string) error {
+	policy := &networkingv1.NetworkPolicy{}
+	err := r.Get(ctx, types.NamespacedName{Namespace: namespace, Name: agentsv1alpha1.QuarantineNetworkPolicyName}, policy)
+	if err == nil || !errors.IsNotFound(err) {
+		return err
+	}
+	policy = &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      agentsv1alpha1.QuarantineNetworkPolicyName,
+		},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{
+				MatchLabels: map[string]string{agentsv1alpha1.LabelSandboxQuarantined: "true"},
+			},
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress, networkingv1.PolicyTypeEgress},
+		},
+	}
+	if err = r.Create(ctx, policy); err != nil && !errors.IsAlreadyExists(err) {
+		return err
+	}
+	logf.FromContext(ctx).Info("quarantine network policy created", "namespace", namespace)
+	return nil
+}
@@ -0,0 +1,138 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sandbox
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+	"github.com/openkruise/agents/pkg/utils"
+)
+
+func TestEnsureQuarantine(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = agentsv1alpha1.AddToScheme(scheme)
+
+	quarantine := &agentsv1alpha1.SandboxQuarantine{Reason: "unexpected egress", Source: agentsv1alpha1.SandboxQuarantineSourceManual}
+	quarantinedCondition := metav1.Condition{
+		Type:   string(agentsv1alpha1.SandboxConditionQuarantined),
+		Status: metav1.ConditionTrue,
+		Reason: agentsv1alpha1.SandboxQuarantinedReasonNetworkIsolated,
+	}
+	tests := []struct {
+		name            string
+		quarantine      *agentsv1alpha1.SandboxQuarantine
+		withPod         bool
+		podLabel        string
+		conditions      []metav1.Condition
+		expectPodLabel  string
+		expectPolicy    bool
+		expectCondition *metav1.Condition
+	}{
+		{
+			name:           "quarantined",
+			quarantine:     quarantine,
+			withPod:        true,
+			expectPodLabel: "true",
+			expectPolicy:   true,
+			expectCondition: &metav1.Condition{
+				Status:  metav1.ConditionTrue,
+				Reason:  agentsv1alpha1.SandboxQuarantinedReasonNetworkIsolated,
+				Message: "Manual: unexpected egress",
+			},
+		},
+		{
+			name:         "quarantined without pod",
+			quarantine:   quarantine,
+			expectPolicy: true,
+			expectCondition: &metav1.Condition{
+				Status:  metav1.ConditionTrue,
+				Reason:  agentsv1alpha1.SandboxQuarantinedReasonPodNotFound,
+				Message: "Manual: unexpected egress",
+			},
+		},
+		{
+			name:       "quarantine lifted",
+			withPod:    true,
+			podLabel:   "true",
+			conditions: []metav1.Condition{quarantinedCondition},
+		},
+		{
+			name:    "not quarantined",
+			withPod: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			box := &agentsv1alpha1.Sandbox{
+				ObjectMeta: metav1.ObjectMeta{Name: "sbx", Namespace: "default"},
+				Spec:       agentsv1alpha1.SandboxSpec{Quarantine: tt.quarantine},
+			}
+			builder := fake.NewClientBuilder().WithScheme(scheme).WithObjects(box)
+			var pod *corev1.Pod
+			if tt.withPod {
+				pod = &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "sbx", Namespace: "default", Labels: map[string]string{}}}
+				if tt.podLabel != "" {
+					pod.Labels[agentsv1alpha1.LabelSandboxQuarantined] = tt.podLabel
+				}
+				builder = builder.WithObjects(pod)
+			}
+			r := &SandboxReconciler{Client: builder.Build(), Scheme: scheme}
+			newStatus := &agentsv1alpha1.SandboxStatus{Conditions: tt.conditions}
+			require.NoError(t, r.ensureQuarantine(context.Background(), box, pod, newStatus))
+
+			if tt.withPod {
+				got := &corev1.Pod{}
+				require.NoError(t, r.Get(context.Background(), client.ObjectKeyFromObject(pod), got))
+				assert.Equal(t, tt.expectPodLabel, got.Labels[agentsv1alpha1.LabelSandboxQuarantined])
+			}
+			policy := &networkingv1.NetworkPolicy{}
+			err := r.Get(context.Background(), client.ObjectKey{Namespace: "default", Name: agentsv1alpha1.QuarantineNetworkPolicyName}, policy)
+			if tt.expectPolicy {
+				require.NoError(t, err)
+				assert.Equal(t, "true", policy.Spec.PodSelector.MatchLabels[agentsv1alpha1.LabelSandboxQuarantined])
+				assert.Empty(t, policy.Spec.Ingress)
+				assert.Empty(t, policy.Spec.Egress)
+				assert.ElementsMatch(t, []networkingv1.PolicyType{networkingv1.PolicyTypeIngress, networkingv1.PolicyTypeEgress}, policy.Spec.PolicyTypes)
+			} else {
+				assert.True(t, errors.IsNotFound(err))
+			}
+			cond := utils.GetSandboxCondition(newStatus, string(agentsv1alpha1.SandboxConditionQuarantined))
+			if tt.expectCondition == nil {
+				assert.Nil(t, cond)
+				return
+			}
+			require.NotNil(t, cond)
+			assert.Equal(t, tt.expectCondition.Status, cond.Status)
+			assert.Equal(t, tt.expectCondition.Reason, cond.Reason)
+			assert.Equal(t, tt.expectCondition.Message, cond.Message)
+		})
+	}
+}
@@ -18,10 +18,8 @@ package sandbox
 
 import (
 	"context"
-	"encoding/json"
 	"flag"
 	"fmt"
-	"reflect"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
@@ -29,23 +27,32 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/klog/v2"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
 	"github.com/openkruise/agents/pkg/controller/sandbox/core"
 	"github.com/openkruise/agents/pkg/discovery"
 	"github.com/openkruise/agents/pkg/features"
 	"github.com/openkruise/agents/pkg/utils"
+	"github.com/openkruise/agents/pkg/utils/controllermetrics"
 	"github.com/openkruise/agents/pkg/utils/expectations"
 	utilfeature "github.com/openkruise/agents/pkg/utils/feature"
+	"github.com/openkruise/agents/pkg/utils/objectsize"
+	"github.com/openkruise/agents/pkg/utils/requeue"
+	"github.com/openkruise/agents/pkg/utils/sandboxstate"
+	"github.com/openkruise/agents/pkg/utils/statusupdater"
+	"github.com/openkruise/agents/pkg/utils/timewheel"
 )
 
 func init() {
@@ -55,6 +62,17 @@ func init() {
 var (
 	concurrentReconciles  = 500
 	sandboxControllerKind = agentsv1alpha1.GroupVersion.WithKind("Sandbox")
+	// sandboxStatusUpdater skips the status fields written by the sandbox-manager and the usage sampler
+	sandboxStatusUpdater = statusupdater.New("heartbeat", "usage", "securityAlert")
+)
+
+const (
+	// timerTick is the precision of requeueing sandboxes at their timers, and a round of the timing wheel spans
+	// timerWheelSize ticks, i.e. a minute
+	timerTick      = 100 * time.Millisecond
+	timerWheelSize = 600
+	// timerEventBufferSize buffers the sandboxes whose timers fired, so that a burst of them does not hold the wheel
+	timerEventBufferSize = 1024
 )
 
 func Add(mgr manager.Manager) error {
@@ -62,15 +80,38 @@ func Add(mgr manager.Manager) error {
 		return nil
 	}
 	rateLimiter := core.NewRateLimiter()
+	timerEvents := make(chan event.GenericEvent, timerEventBufferSize)
+	timers := timewheel.New(timerTick, timerWheelSize, func(key types.NamespacedName) {
+		timerEvents <- event.GenericEvent{Object: &agentsv1alpha1.Sandbox{ObjectMeta: metav1.ObjectMeta{Namespace: key.Namespace, Name: key.Name}}}
+	})
 	err := (&SandboxReconciler{
 		Client:      mgr.GetClient(),
 		Scheme:      mgr.GetScheme(),
 		controls:    core.NewSandboxControl(mgr.GetClient(), mgr.GetEventRecorderFor("sandbox"), rateLimiter),
 		rateLimiter: rateLimiter,
+		timers:      timers,
+		timerEvents: timerEvents,
 	}).SetupWithManager(mgr)
 	if err != nil {
 		return err
 	}
+	if err = mgr.Add(timers); err != nil {
+		return fmt.Errorf("failed to add sandbox timers: %w", err)
+	}
+	if utilfeature.DefaultFeatureGate.Enabled(features.SandboxUsageSamplingGate) {
+		clientSet, err := kubernetes.NewForConfig(mgr.GetConfig())
+		if err != nil {
+			return fmt.Errorf("failed to create kubernetes client: %w", err)
+		}
+		err = mgr.Add(&usageSampler{
+			Client:   mgr.GetClient(),
+			source:   &metricsServerSource{client: clientSet.Discovery().RESTClient()},
+			interval: usageSampleInterval,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to add usage sampler: %w", err)
+		}
+	}
 	klog.Infof("Started SandboxReconciler successfully")
 	return nil
 }
@@ -81,6 +122,10 @@ type SandboxReconciler struct {
 	Scheme      *runtime.Scheme
 	controls    map[string]core.SandboxControl
 	rateLimiter *core.RateLimiter
+	// timers requeues sandboxes at their shutdown and pause times through timerEvents, nil to requeue them after
+	// the times with the workqueue instead
+	timers      *timewheel.Wheel[types.NamespacedName]
+	timerEvents chan event.GenericEvent
 }
 
 // +kubebuilder:rbac:groups=agents.kruise.io,resources=sandboxes,verbs=get;list;watch;create;update;patch;delete
@@ -114,6 +159,9 @@ func (r *SandboxReconciler) Reconcile(ctx context.Context, req ctrl.Request) (cr
 			core.ResourceVersionExpectations.Delete(box)
 			core.ScaleExpectation.DeleteExpectations(utils.GetControllerKey(box))
 			deleteSandboxMetrics(req.NamespacedName.Namespace, req.NamespacedName.Name)
+			if r.timers != nil {
+				r.timers.Cancel(req.NamespacedName)
+			}
 		}
 		return reconcile.Result{}, client.IgnoreNotFound(err)
 	}
@@ -196,17 +244,18 @@ func (r *SandboxReconciler) Reconcile(ctx context.Context, req ctrl.Request) (cr
 		return reconcile.Result{}, err
 	}
 
-	// Check ShutdownTime and PauseTime
+	// Check ShutdownTime and PauseTime, a quarantined sandbox is kept as it is for security review
 	now := metav1.Now()
-	var requeueAfter time.Duration
-	if box.Spec.ShutdownTime != nil && box.DeletionTimestamp == nil {
+	timers := requeue.NoRequeue()
+	quarantined := box.Spec.Quarantine != nil
+	if box.Spec.ShutdownTime != nil && box.DeletionTimestamp == nil && !quarantined {
 		if box.Spec.ShutdownTime.Before(&now) {
 			logger.Info("sandbox shutdown time reached, will be deleted", "shutdownTime", box.Spec.ShutdownTime)
 			return ctrl.Result{}, r.Delete(ctx, box)
 		}
-		requeueAfter = box.Spec.ShutdownTime.Sub(now.Time)
+		timers = requeue.After(box.Spec.ShutdownTime.Sub(now.Time)).WithReason("ShutdownTime")
 	}
-	if box.Spec.PauseTime != nil && !box.Spec.Paused {
+	if box.Spec.PauseTime != nil && !box.Spec.Paused && !quarantined {
 		if box.Spec.PauseTime.Before(&now) {
 			logger.Info("sandbox pause time reached, will be paused")
 			modified := box.DeepCopy()
@@ -214,38 +263,85 @@ func (r *SandboxReconciler) Reconcile(ctx context.Context, req ctrl.Request) (cr
 			modified.Spec.Paused = true
 			return ctrl.Result{}, r.Patch(ctx, modified, patch)
 		}
-		requeueAfter = min(requeueAfter, box.Spec.PauseTime.Sub(now.Time))
+		timers = timers.Sooner(requeue.After(box.Spec.PauseTime.Sub(now.Time)).WithReason("PauseTime"))
 	}
+	timers = r.scheduleTimers(box, timers, now.Time)
 
 	// calculate sandbox status
 	var shouldRequeue bool
 	newStatus, shouldRequeue = calculateStatus(args)
 	if shouldRequeue {
-		return reconcile.Result{RequeueAfter: requeueAfter}, r.updateSandboxStatus(ctx, *newStatus, box)
+		if err = r.updateSandboxStatus(ctx, *newStatus, box); err != nil {
+			return reconcile.Result{}, err
+		}
+		return r.requeueResult(ctx, box, timers), nil
 	}
 
+	var strategy core.RequeueStrategy
 	switch newStatus.Phase {
 	case agentsv1alpha1.SandboxPending:
-		requeueAfter, err = r.getControl(args.Pod).EnsureSandboxRunning(ctx, args)
+		strategy, err = r.getControl(args.Pod).EnsureSandboxRunning(ctx, args)
 	case agentsv1alpha1.SandboxRunning:
-		err = r.getControl(args.Pod).EnsureSandboxUpdated(ctx, args)
+		strategy, err = r.getControl(args.Pod).EnsureSandboxUpdated(ctx, args)
 	case agentsv1alpha1.SandboxPaused:
-		err = r.getControl(args.Pod).EnsureSandboxPaused(ctx, args)
+		strategy, err = r.getControl(args.Pod).EnsureSandboxPaused(ctx, args)
 	case agentsv1alpha1.SandboxResuming:
-		err = r.getControl(args.Pod).EnsureSandboxResumed(ctx, args)
+		strategy, err = r.getControl(args.Pod).EnsureSandboxResumed(ctx, args)
 	default:
 		logger.Info("sandbox status phase is invalid", "phase", box.Status.Phase)
-		return ctrl.Result{RequeueAfter: requeueAfter}, nil
+		return r.requeueResult(ctx, box, timers), nil
 	}
 	if err != nil {
 		return reconcile.Result{}, err
 	}
-	return ctrl.Result{RequeueAfter: requeueAfter}, r.updateSandboxStatus(ctx, *newStatus, box)
+	if err = r.ensureQuarantine(ctx, box, args.Pod, newStatus); err != nil {
+		logger.Error(err, "failed to ensure quarantine")
+		return reconcile.Result{}, err
+	}
+	strategy = strategy.Sooner(timers)
+	// Retry a failing custom readiness probe, since no pod event will trigger it
+	if probeRequeue := core.ReadinessProbeRequeueAfter(box, newStatus); probeRequeue > 0 {
+		strategy = strategy.Sooner(requeue.After(probeRequeue).WithReason("ReadinessProbe"))
+	}
+	if err = r.updateSandboxStatus(ctx, *newStatus, box); err != nil {
+		return reconcile.Result{}, err
+	}
+	return r.requeueResult(ctx, box, strategy), nil
 }
 
 func (r *SandboxReconciler) handleTerminating(ctx context.Context, args core.EnsureFuncArgs) (ctrl.Result, error) {
-	pod, _, _ := args.Pod, args.Box, args.NewStatus
-	return ctrl.Result{}, r.getControl(pod).EnsureSandboxTerminated(ctx, args)
+	strategy, err := r.getControl(args.Pod).EnsureSandboxTerminated(ctx, args)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	return r.requeueResult(ctx, args.Box, strategy), nil
+}
+
+// scheduleTimers schedules the requeue of the sandbox at its next timer on the timing wheel, which also cancels the
+// requeue for timers removed or postponed since, and returns the strategy left to the workqueue.
+func (r *SandboxReconciler) scheduleTimers(box *agentsv1alpha1.Sandbox, timers core.RequeueStrategy, now time.Time) core.RequeueStrategy {
+	if r.timers == nil {
+		return timers
+	}
+	key := client.ObjectKeyFromObject(box)
+	if timers.Kind() == requeue.KindNone {
+		r.timers.Cancel(key)
+	} else {
+		r.timers.Schedule(key, now.Add(timers.After))
+	}
+	return requeue.NoRequeue()
+}
+
+// requeueResult logs and records the requeue strategy chosen for a reconcile, and converts it into the result.
+func (r *SandboxReconciler) requeueResult(ctx context.Context, box *agentsv1alpha1.Sandbox, strategy core.RequeueStrategy) ctrl.Result {
+	logger := logf.FromContext(ctx).WithValues("sandbox", klog.KObj(box))
+	strategy.Record(sandboxRequeueTotal)
+	if strategy.Kind() == requeue.KindNone {
+		logger.V(2).Info("No requeue requested", "reason", strategy.Reason)
+	} else {
+		logger.V(2).Info("Requeue requested", "strategy", strategy.Kind(), "after", strategy.After, "reason", strategy.Reason)
+	}
+	return strategy.Result()
 }
 
 func isSandboxCompletedPhase(phase agentsv1alpha1.SandboxPhase) bool {
@@ -276,26 +372,40 @@ func (r *SandboxReconciler) addSandboxFinalizerAndHash(ctx context.Context, box
 
 func (r *SandboxReconciler) updateSandboxStatus(ctx context.Context, newStatus agentsv1alpha1.SandboxStatus, box *agentsv1alpha1.Sandbox) error {
 	logger := logf.FromContext(ctx).WithValues("sandbox", klog.KObj(box))
-	if reflect.DeepEqual(box.Status, newStatus) || newStatus.Phase == agentsv1alpha1.SandboxPending {
+	if newStatus.Phase == agentsv1alpha1.SandboxPending {
 		return nil
 	}
 
-	by, _ := json.Marshal(newStatus)
-	patchStatus := fmt.Sprintf(`{"status":%s}`, string(by))
+	setSandboxState(box, &newStatus, time.Now())
+	objectsize.DefaultOptions.TruncateSandboxStatus(&newStatus)
 	rcvObject := &agentsv1alpha1.Sandbox{ObjectMeta: metav1.ObjectMeta{Namespace: box.Namespace, Name: box.Name}}
-	err := client.IgnoreNotFound(r.Status().Patch(ctx, rcvObject, client.RawPatch(types.MergePatchType, []byte(patchStatus))))
-	if err != nil {
-		logger.Error(err, "update sandbox status failed", "patchStatus", patchStatus)
+	patched, err := sandboxStatusUpdater.Patch(ctx, r.Client, rcvObject, box.Status, newStatus)
+	if err = client.IgnoreNotFound(err); err != nil {
+		logger.Error(err, "update sandbox status failed", "status", utils.DumpJson(newStatus))
 		return err
 	}
+	if !patched {
+		return nil
+	}
 	core.ResourceVersionExpectations.Expect(rcvObject)
 	logger.Info("update sandbox status success", "status", utils.DumpJson(newStatus))
+	resumed := box.Status.Phase == agentsv1alpha1.SandboxResuming && newStatus.Phase == agentsv1alpha1.SandboxRunning
 	box.Status = newStatus
 	// Update metrics after status change
 	recordSandboxMetrics(box)
+	if resumed {
+		recordResumeLatency(box)
+	}
 	return nil
 }
 
+// setSandboxState records the state of the sandbox with the new status in it, so that users see the state the
+// sandbox-manager and the controllers work with instead of deriving it from the phase and conditions.
+func setSandboxState(box *agentsv1alpha1.Sandbox, newStatus *agentsv1alpha1.SandboxStatus, now time.Time) {
+	evaluated := &agentsv1alpha1.Sandbox{ObjectMeta: box.ObjectMeta, Spec: box.Spec, Status: *newStatus}
+	newStatus.State, newStatus.StateReason = sandboxstate.Evaluate(evaluated, now, sandboxstate.Policy{})
+}
+
 func calculateStatus(args core.EnsureFuncArgs) (*agentsv1alpha1.SandboxStatus, bool) {
 	pod, box, newStatus := args.Pod, args.Box, args.NewStatus
 	logger := logf.FromContext(context.TODO()).WithValues("sandbox", klog.KObj(box))
@@ -340,11 +450,13 @@ func calculateStatus(args core.EnsureFuncArgs) (*agentsv1alpha1.SandboxStatus, b
 			// delete paused condition
 			utils.RemoveSandboxCondition(newStatus, string(agentsv1alpha1.SandboxConditionPaused))
 			newStatus.Phase = agentsv1alpha1.SandboxResuming
+			now := metav1.Now()
+			newStatus.ResumeStartTime = &now
 			rCond := metav1.Condition{
 				Type:               string(agentsv1alpha1.SandboxConditionResumed),
 				Status:             metav1.ConditionFalse,
 				Reason:             agentsv1alpha1.SandboxResumeReasonCreatePod,
-				LastTransitionTime: metav1.Now(),
+				LastTransitionTime: now,
 			}
 			utils.SetSandboxCondition(newStatus, rCond)
 		} else if !box.Spec.Paused && cond.Status == metav1.ConditionFalse {
@@ -369,12 +481,16 @@ func updateStatusIfPodCompleted(pod *corev1.Pod, newStatus *agentsv1alpha1.Sandb
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *SandboxReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
-		WithOptions(controller.Options{MaxConcurrentReconciles: concurrentReconciles}).
+	controllerName := "sandbox-controller"
+	b := ctrl.NewControllerManagedBy(mgr).
+		WithOptions(controller.Options{MaxConcurrentReconciles: concurrentReconciles, NewQueue: controllermetrics.NewQueue}).
 		For(&agentsv1alpha1.Sandbox{}).
-		Named("sandbox-controller").
-		Watches(&agentsv1alpha1.Sandbox{}, &handler.EnqueueRequestForObject{}).Watches(&corev1.Pod{}, &SandboxPodEventHandler{}).
-		Complete(r)
+		Named(controllerName).
+		Watches(&agentsv1alpha1.Sandbox{}, &handler.EnqueueRequestForObject{}).Watches(&corev1.Pod{}, &SandboxPodEventHandler{})
+	if r.timerEvents != nil {
+		b = b.WatchesRawSource(source.Channel(r.timerEvents, &handler.EnqueueRequestForObject{}))
+	}
+	return b.Complete(controllermetrics.Wrap(controllerName, r))
 }
 
 // ensureVolumeClaimTemplates creates and ensures PVCs exist for persistent data recovery during sleep/wake operations
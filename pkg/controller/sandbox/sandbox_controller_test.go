@@ -38,6 +38,8 @@ import (
 	"github.com/openkruise/agents/pkg/controller/sandbox/core"
 	"github.com/openkruise/agents/pkg/utils"
 	utilfeature "github.com/openkruise/agents/pkg/utils/feature"
+	"github.com/openkruise/agents/pkg/utils/requeue"
+	"github.com/openkruise/agents/pkg/utils/timewheel"
 )
 
 func TestSandboxReconciler_Reconcile(t *testing.T) {
@@ -795,6 +797,73 @@ func TestSandboxReconciler_updateSandboxStatus(t *testing.T) {
 		t.Errorf("Failed to get updated sandbox: %v", err)
 	} else if updatedSandbox.Status.Phase != agentsv1alpha1.SandboxRunning {
 		t.Errorf("Expected sandbox phase %v, got %v", agentsv1alpha1.SandboxRunning, updatedSandbox.Status.Phase)
+	} else if updatedSandbox.Status.State != agentsv1alpha1.SandboxStateDead ||
+		updatedSandbox.Status.StateReason != "RunningResourceClaimedButNotReady" {
+		t.Errorf("Expected sandbox state %v/RunningResourceClaimedButNotReady, got %v/%v", agentsv1alpha1.SandboxStateDead,
+			updatedSandbox.Status.State, updatedSandbox.Status.StateReason)
+	}
+}
+
+func TestSetSandboxState(t *testing.T) {
+	now := time.Now()
+	ready := []metav1.Condition{{Type: string(agentsv1alpha1.SandboxConditionReady), Status: metav1.ConditionTrue}}
+	pooled := metav1.ObjectMeta{
+		Name:      "test-sandbox",
+		Namespace: "default",
+		OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(
+			&agentsv1alpha1.SandboxSet{ObjectMeta: metav1.ObjectMeta{Name: "pool"}}, agentsv1alpha1.SandboxSetControllerKind)},
+	}
+
+	tests := []struct {
+		name           string
+		meta           metav1.ObjectMeta
+		spec           agentsv1alpha1.SandboxSpec
+		newStatus      agentsv1alpha1.SandboxStatus
+		expectedState  string
+		expectedReason string
+	}{
+		{
+			name:           "pooled sandbox becoming ready is available",
+			meta:           pooled,
+			newStatus:      agentsv1alpha1.SandboxStatus{Phase: agentsv1alpha1.SandboxRunning, Conditions: ready},
+			expectedState:  agentsv1alpha1.SandboxStateAvailable,
+			expectedReason: "ResourceControlledBySbsAndReady",
+		},
+		{
+			name:           "claimed ready sandbox is running",
+			meta:           metav1.ObjectMeta{Name: "test-sandbox", Namespace: "default"},
+			newStatus:      agentsv1alpha1.SandboxStatus{Phase: agentsv1alpha1.SandboxRunning, Conditions: ready},
+			expectedState:  agentsv1alpha1.SandboxStateRunning,
+			expectedReason: "RunningResourceClaimedAndReady",
+		},
+		{
+			name:           "claimed sandbox being paused is paused",
+			meta:           metav1.ObjectMeta{Name: "test-sandbox", Namespace: "default"},
+			spec:           agentsv1alpha1.SandboxSpec{Paused: true},
+			newStatus:      agentsv1alpha1.SandboxStatus{Phase: agentsv1alpha1.SandboxRunning, Conditions: ready},
+			expectedState:  agentsv1alpha1.SandboxStatePaused,
+			expectedReason: "RunningResourceClaimedAndPaused",
+		},
+		{
+			name:           "failed sandbox is dead",
+			meta:           pooled,
+			newStatus:      agentsv1alpha1.SandboxStatus{Phase: agentsv1alpha1.SandboxFailed},
+			expectedState:  agentsv1alpha1.SandboxStateDead,
+			expectedReason: "ResourceFailed",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			box := &agentsv1alpha1.Sandbox{ObjectMeta: tt.meta, Spec: tt.spec}
+			newStatus := tt.newStatus
+			setSandboxState(box, &newStatus, now)
+			if newStatus.State != tt.expectedState || newStatus.StateReason != tt.expectedReason {
+				t.Errorf("setSandboxState() = %v/%v, want %v/%v", newStatus.State, newStatus.StateReason, tt.expectedState, tt.expectedReason)
+			}
+			if box.Status.State != "" {
+				t.Errorf("setSandboxState() modified the status of the sandbox")
+			}
+		})
 	}
 }
 
@@ -957,6 +1026,71 @@ func TestSandboxReconciler_ShutdownTime(t *testing.T) {
 	}
 }
 
+func TestSandboxReconciler_scheduleTimers(t *testing.T) {
+	now := time.Now()
+	box := &agentsv1alpha1.Sandbox{ObjectMeta: metav1.ObjectMeta{Name: "timer-sandbox", Namespace: "default"}}
+	key := types.NamespacedName{Namespace: "default", Name: "timer-sandbox"}
+
+	tests := []struct {
+		name             string
+		withWheel        bool
+		scheduled        bool
+		timers           core.RequeueStrategy
+		expectedStrategy core.RequeueStrategy
+		expectedDeadline time.Time
+	}{
+		{
+			name:             "requeued by the workqueue without a wheel",
+			timers:           requeue.After(time.Minute).WithReason("ShutdownTime"),
+			expectedStrategy: requeue.After(time.Minute).WithReason("ShutdownTime"),
+		},
+		{
+			name:             "scheduled on the wheel",
+			withWheel:        true,
+			timers:           requeue.After(time.Minute).WithReason("ShutdownTime"),
+			expectedStrategy: requeue.NoRequeue(),
+			expectedDeadline: now.Add(time.Minute),
+		},
+		{
+			name:             "rescheduled on the wheel",
+			withWheel:        true,
+			scheduled:        true,
+			timers:           requeue.After(time.Hour).WithReason("PauseTime"),
+			expectedStrategy: requeue.NoRequeue(),
+			expectedDeadline: now.Add(time.Hour),
+		},
+		{
+			name:             "removed timers canceled on the wheel",
+			withWheel:        true,
+			scheduled:        true,
+			timers:           requeue.NoRequeue(),
+			expectedStrategy: requeue.NoRequeue(),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reconciler := &SandboxReconciler{}
+			if tt.withWheel {
+				reconciler.timers = timewheel.New(timerTick, timerWheelSize, func(types.NamespacedName) {})
+				if tt.scheduled {
+					reconciler.timers.Schedule(key, now.Add(time.Second))
+				}
+			}
+			strategy := reconciler.scheduleTimers(box, tt.timers, now)
+			if strategy != tt.expectedStrategy {
+				t.Errorf("scheduleTimers() = %+v, want %+v", strategy, tt.expectedStrategy)
+			}
+			if !tt.withWheel {
+				return
+			}
+			deadline, ok := reconciler.timers.Deadline(key)
+			if ok != !tt.expectedDeadline.IsZero() || !deadline.Equal(tt.expectedDeadline) {
+				t.Errorf("scheduleTimers() scheduled %v (%v), want %v", deadline, ok, tt.expectedDeadline)
+			}
+		})
+	}
+}
+
 func TestSandboxReconcile_WithVolumeClaimTemplates(t *testing.T) {
 	scheme := runtime.NewScheme()
 	_ = clientgoscheme.AddToScheme(scheme)
@@ -1551,6 +1685,10 @@ func TestCalculateStatus(t *testing.T) {
 				if !found {
 					t.Errorf("Resumed condition should be added")
 				}
+				// Should record when the resume started
+				if status.ResumeStartTime == nil {
+					t.Errorf("ResumeStartTime should be set")
+				}
 			},
 		},
 		{
@@ -0,0 +1,177 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sandbox
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/rest"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+)
+
+func init() {
+	flag.DurationVar(&usageSampleInterval, "sandbox-usage-sample-interval", usageSampleInterval, "Interval of sampling the resource usage of running sandboxes into their status.")
+}
+
+var usageSampleInterval = time.Minute
+
+const (
+	// usageSmoothingFactor is the weight of a new sample in the exponentially weighted moving average of usage
+	usageSmoothingFactor = 0.3
+	// usageRefreshSamples is the number of samples after which an unchanged usage is written again, so that
+	// consumers can tell a stale usage from a stable one by its sample time
+	usageRefreshSamples = 5
+	mebibyte            = 1 << 20
+)
+
+// PodUsageSource lists the resource usage of pods, summed over their containers, keyed by pod name.
+type PodUsageSource interface {
+	ListPodUsage(ctx context.Context, namespace string) (map[string]corev1.ResourceList, error)
+}
+
+// metricsServerSource lists pod usage from the resource metrics API served by metrics-server.
+type metricsServerSource struct {
+	client rest.Interface
+}
+
+type podMetricsList struct {
+	Items []struct {
+		metav1.ObjectMeta `json:"metadata"`
+		Containers        []struct {
+			Usage corev1.ResourceList `json:"usage"`
+		} `json:"containers"`
+	} `json:"items"`
+}
+
+// +kubebuilder:rbac:groups=metrics.k8s.io,resources=pods,verbs=list
+
+func (s *metricsServerSource) ListPodUsage(ctx context.Context, namespace string) (map[string]corev1.ResourceList, error) {
+	data, err := s.client.Get().AbsPath("/apis/metrics.k8s.io/v1beta1/namespaces", namespace, "pods").DoRaw(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pod metrics: %w", err)
+	}
+	list := podMetricsList{}
+	if err = json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("failed to parse pod metrics: %w", err)
+	}
+	usages := make(map[string]corev1.ResourceList, len(list.Items))
+	for _, item := range list.Items {
+		cpu, memory := resource.Quantity{}, resource.Quantity{}
+		for _, container := range item.Containers {
+			cpu.Add(container.Usage[corev1.ResourceCPU])
+			memory.Add(container.Usage[corev1.ResourceMemory])
+		}
+		usages[item.Name] = corev1.ResourceList{corev1.ResourceCPU: cpu, corev1.ResourceMemory: memory}
+	}
+	return usages, nil
+}
+
+// usageSampler periodically writes the smoothed resource usage of running sandboxes into their status, so that
+// consumers like claim strategies and idle detection do not scrape metrics by themselves.
+type usageSampler struct {
+	client.Client
+	source   PodUsageSource
+	interval time.Duration
+}
+
+func (s *usageSampler) Start(ctx context.Context) error {
+	klog.InfoS("Starting sandbox usage sampler", "interval", s.interval)
+	wait.UntilWithContext(ctx, s.sample, s.interval)
+	return nil
+}
+
+func (s *usageSampler) sample(ctx context.Context) {
+	sandboxList := &agentsv1alpha1.SandboxList{}
+	if err := s.List(ctx, sandboxList); err != nil {
+		klog.ErrorS(err, "Failed to list sandboxes for usage sampling")
+		return
+	}
+	usages := map[string]map[string]corev1.ResourceList{}
+	now := metav1.Now()
+	for i := range sandboxList.Items {
+		box := &sandboxList.Items[i]
+		var usage *agentsv1alpha1.SandboxUsage
+		if box.Status.Phase == agentsv1alpha1.SandboxRunning && box.DeletionTimestamp.IsZero() {
+			podUsages, ok := usages[box.Namespace]
+			if !ok {
+				var err error
+				if podUsages, err = s.source.ListPodUsage(ctx, box.Namespace); err != nil {
+					klog.ErrorS(err, "Failed to sample usage of sandboxes", "namespace", box.Namespace)
+				}
+				// do not retry a failed namespace in this round
+				usages[box.Namespace] = podUsages
+			}
+			podUsage, ok := podUsages[box.Name]
+			if !ok {
+				// not sampled yet, keep the last usage
+				continue
+			}
+			usage = smoothUsage(box.Status.Usage, podUsage, now)
+		}
+		if usageEqual(box.Status.Usage, usage) &&
+			(usage == nil || now.Sub(box.Status.Usage.LastSampleTime.Time) < usageRefreshSamples*s.interval) {
+			continue
+		}
+		if err := s.patchUsage(ctx, box, usage); err != nil {
+			klog.ErrorS(err, "Failed to update usage of sandbox", "sandbox", klog.KObj(box))
+		}
+	}
+}
+
+func (s *usageSampler) patchUsage(ctx context.Context, box *agentsv1alpha1.Sandbox, usage *agentsv1alpha1.SandboxUsage) error {
+	by, err := json.Marshal(map[string]any{"status": map[string]any{"usage": usage}})
+	if err != nil {
+		return err
+	}
+	return client.IgnoreNotFound(s.Status().Patch(ctx, box, client.RawPatch(types.MergePatchType, by)))
+}
+
+// smoothUsage folds a sample into the usage of a sandbox. The cpu is kept in millicores and the memory in MiB,
+// so that jitters below the precision do not update the status.
+func smoothUsage(last *agentsv1alpha1.SandboxUsage, sample corev1.ResourceList, now metav1.Time) *agentsv1alpha1.SandboxUsage {
+	cpu := float64(sample.Cpu().MilliValue())
+	memory := float64(sample.Memory().Value()) / mebibyte
+	if last != nil {
+		cpu = usageSmoothingFactor*cpu + (1-usageSmoothingFactor)*float64(last.CPU.MilliValue())
+		memory = usageSmoothingFactor*memory + (1-usageSmoothingFactor)*float64(last.Memory.Value())/mebibyte
+	}
+	return &agentsv1alpha1.SandboxUsage{
+		CPU:            *resource.NewMilliQuantity(int64(cpu+0.5), resource.DecimalSI),
+		Memory:         *resource.NewQuantity(int64(memory+0.5)*mebibyte, resource.BinarySI),
+		LastSampleTime: now,
+	}
+}
+
+// usageEqual compares the usages regardless of the sample time
+func usageEqual(a, b *agentsv1alpha1.SandboxUsage) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.CPU.Cmp(b.CPU) == 0 && a.Memory.Cmp(b.Memory) == 0
+}
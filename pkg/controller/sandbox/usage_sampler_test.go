@@ -0,0 +1,176 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sandbox
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+)
+
+type fakeUsageSource map[string]map[string]corev1.ResourceList
+
+func (s fakeUsageSource) ListPodUsage(_ context.Context, namespace string) (map[string]corev1.ResourceList, error) {
+	return s[namespace], nil
+}
+
+func newUsage(cpu, memory string, sampleTime time.Time) *agentsv1alpha1.SandboxUsage {
+	return &agentsv1alpha1.SandboxUsage{
+		CPU:            resource.MustParse(cpu),
+		Memory:         resource.MustParse(memory),
+		LastSampleTime: metav1.NewTime(sampleTime),
+	}
+}
+
+func TestUsageSampler_Sample(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = agentsv1alpha1.AddToScheme(scheme)
+	now := time.Now()
+
+	tests := []struct {
+		name        string
+		phase       agentsv1alpha1.SandboxPhase
+		usage       *agentsv1alpha1.SandboxUsage
+		sample      corev1.ResourceList
+		expectUsage *agentsv1alpha1.SandboxUsage
+		expectFresh bool
+	}{
+		{
+			name:        "first sample",
+			phase:       agentsv1alpha1.SandboxRunning,
+			sample:      corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m"), corev1.ResourceMemory: resource.MustParse("256Mi")},
+			expectUsage: newUsage("100m", "256Mi", now),
+			expectFresh: true,
+		},
+		{
+			name:        "smoothed",
+			phase:       agentsv1alpha1.SandboxRunning,
+			usage:       newUsage("100m", "256Mi", now.Add(-time.Minute)),
+			sample:      corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("200m"), corev1.ResourceMemory: resource.MustParse("512Mi")},
+			expectUsage: newUsage("130m", "333Mi", now),
+			expectFresh: true,
+		},
+		{
+			name:        "unchanged usage is not written",
+			phase:       agentsv1alpha1.SandboxRunning,
+			usage:       newUsage("100m", "256Mi", now.Add(-time.Minute)),
+			sample:      corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m"), corev1.ResourceMemory: resource.MustParse("256Mi")},
+			expectUsage: newUsage("100m", "256Mi", now.Add(-time.Minute)),
+		},
+		{
+			name:        "unchanged usage is refreshed",
+			phase:       agentsv1alpha1.SandboxRunning,
+			usage:       newUsage("100m", "256Mi", now.Add(-time.Hour)),
+			sample:      corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m"), corev1.ResourceMemory: resource.MustParse("256Mi")},
+			expectUsage: newUsage("100m", "256Mi", now),
+			expectFresh: true,
+		},
+		{
+			name:        "not sampled yet",
+			phase:       agentsv1alpha1.SandboxRunning,
+			usage:       newUsage("100m", "256Mi", now.Add(-time.Minute)),
+			expectUsage: newUsage("100m", "256Mi", now.Add(-time.Minute)),
+		},
+		{
+			name:  "paused sandbox usage is removed",
+			phase: agentsv1alpha1.SandboxPaused,
+			usage: newUsage("100m", "256Mi", now.Add(-time.Minute)),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			box := &agentsv1alpha1.Sandbox{
+				ObjectMeta: metav1.ObjectMeta{Name: "sbx", Namespace: "default"},
+				Status:     agentsv1alpha1.SandboxStatus{Phase: tt.phase, Usage: tt.usage},
+			}
+			source := fakeUsageSource{}
+			if tt.sample != nil {
+				source["default"] = map[string]corev1.ResourceList{"sbx": tt.sample}
+			}
+			sampler := &usageSampler{
+				Client:   fake.NewClientBuilder().WithScheme(scheme).WithStatusSubresource(&agentsv1alpha1.Sandbox{}).WithObjects(box).Build(),
+				source:   source,
+				interval: time.Minute,
+			}
+			sampler.sample(context.Background())
+
+			got := &agentsv1alpha1.Sandbox{}
+			require.NoError(t, sampler.Get(context.Background(), client.ObjectKeyFromObject(box), got))
+			if tt.expectUsage == nil {
+				assert.Nil(t, got.Status.Usage)
+				return
+			}
+			require.NotNil(t, got.Status.Usage)
+			assert.True(t, usageEqual(tt.expectUsage, got.Status.Usage), "expect %v/%v, got %v/%v",
+				tt.expectUsage.CPU.String(), tt.expectUsage.Memory.String(), got.Status.Usage.CPU.String(), got.Status.Usage.Memory.String())
+			if tt.expectFresh {
+				assert.WithinDuration(t, now, got.Status.Usage.LastSampleTime.Time, 5*time.Second)
+			} else {
+				assert.WithinDuration(t, tt.usage.LastSampleTime.Time, got.Status.Usage.LastSampleTime.Time, time.Second)
+			}
+		})
+	}
+}
+
+func TestMetricsServerSource_ListPodUsage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/apis/metrics.k8s.io/v1beta1/namespaces/default/pods", r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{
+  "kind": "PodMetricsList",
+  "apiVersion": "metrics.k8s.io/v1beta1",
+  "items": [
+    {
+      "metadata": {"name": "sbx", "namespace": "default"},
+      "window": "15s",
+      "containers": [
+        {"name": "main", "usage": {"cpu": "150m", "memory": "100Mi"}},
+        {"name": "runtime", "usage": {"cpu": "12345n", "memory": "28Mi"}}
+      ]
+    }
+  ]
+}`))
+	}))
+	defer server.Close()
+	clientSet, err := kubernetes.NewForConfig(&rest.Config{Host: server.URL})
+	require.NoError(t, err)
+
+	source := &metricsServerSource{client: clientSet.Discovery().RESTClient()}
+	usages, err := source.ListPodUsage(context.Background(), "default")
+	require.NoError(t, err)
+	require.Len(t, usages, 1)
+	usage := usages["sbx"]
+	assert.Equal(t, int64(150012345), usage.Cpu().ScaledValue(resource.Nano))
+	assert.Equal(t, int64(128<<20), usage.Memory().Value())
+}
@@ -0,0 +1,171 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package archive serializes completed SandboxClaims to an external sink before they are deleted by TTL, so that
+// the latency and failure reasons of claims can be analyzed long after the claims are garbage collected.
+package archive
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/pflag"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+)
+
+const (
+	// SinkNone disables archiving
+	SinkNone = ""
+	// SinkFile writes each claim into a JSON file of a directory, e.g. a volume backed by an object storage bucket
+	SinkFile = "file"
+	// SinkHTTP posts each claim as JSON to an endpoint, e.g. a log collector forwarding to S3 or BigQuery
+	SinkHTTP = "http"
+)
+
+// Options configures the archive sink of SandboxClaims.
+type Options struct {
+	Sink    string
+	Dir     string
+	URL     string
+	Timeout time.Duration
+}
+
+// DefaultOptions is set by the command line flags and used by the SandboxClaim controller.
+var DefaultOptions = Options{
+	Timeout: 10 * time.Second,
+}
+
+// AddFlags registers the flags of the options.
+func (o *Options) AddFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&o.Sink, "sandboxclaim-archive-sink", o.Sink,
+		"Archive completed SandboxClaims before they are deleted by TTL, one of: '' (disabled), file, http")
+	fs.StringVar(&o.Dir, "sandboxclaim-archive-dir", o.Dir, "Directory of the file archive sink")
+	fs.StringVar(&o.URL, "sandboxclaim-archive-url", o.URL, "Endpoint of the http archive sink, claims are posted as JSON")
+	fs.DurationVar(&o.Timeout, "sandboxclaim-archive-timeout", o.Timeout, "Timeout of archiving one SandboxClaim")
+}
+
+// Record is the archived document of a SandboxClaim. The claim keeps its spec and status, including the history.
+type Record struct {
+	ArchivedAt metav1.Time                  `json:"archivedAt"`
+	Claim      *agentsv1alpha1.SandboxClaim `json:"claim"`
+}
+
+// NewRecord returns the record of a claim without the managed fields, which are of no use for analytics.
+func NewRecord(claim *agentsv1alpha1.SandboxClaim, now time.Time) *Record {
+	claim = claim.DeepCopy()
+	claim.APIVersion = agentsv1alpha1.GroupVersion.String()
+	claim.Kind = "SandboxClaim"
+	claim.ManagedFields = nil
+	return &Record{ArchivedAt: metav1.NewTime(now), Claim: claim}
+}
+
+// Sink stores the records of claims. A claim may be archived more than once if it fails to be deleted after being
+// archived, so sinks should deduplicate records by the UID of the claim.
+type Sink interface {
+	Archive(ctx context.Context, record *Record) error
+}
+
+// NewSink returns the sink configured by the options, or nil if archiving is disabled.
+func NewSink(opts Options) (Sink, error) {
+	switch opts.Sink {
+	case SinkNone:
+		return nil, nil
+	case SinkFile:
+		if opts.Dir == "" {
+			return nil, fmt.Errorf("--sandboxclaim-archive-dir is required by the file archive sink")
+		}
+		return &FileSink{Dir: opts.Dir}, nil
+	case SinkHTTP:
+		if opts.URL == "" {
+			return nil, fmt.Errorf("--sandboxclaim-archive-url is required by the http archive sink")
+		}
+		return &HTTPSink{URL: opts.URL, Client: &http.Client{Timeout: opts.Timeout}}, nil
+	default:
+		return nil, fmt.Errorf("unknown archive sink %q", opts.Sink)
+	}
+}
+
+// FileSink writes each record into <Dir>/<namespace>/<name>-<uid>.json, so archiving a claim again overwrites
+// its record.
+type FileSink struct {
+	Dir string
+}
+
+func (s *FileSink) Archive(_ context.Context, record *Record) error {
+	claim := record.Claim
+	dir := filepath.Join(s.Dir, claim.Namespace)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	by, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	// write into a temporary file first, so that readers never see a partial record
+	tmp, err := os.CreateTemp(dir, ".archive-*")
+	if err != nil {
+		return err
+	}
+	if _, err = tmp.Write(by); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), filepath.Join(dir, fmt.Sprintf("%s-%s.json", claim.Name, claim.UID)))
+}
+
+// HTTPSink posts each record as JSON to URL, any 2xx response means the record is stored.
+type HTTPSink struct {
+	URL    string
+	Client *http.Client
+}
+
+func (s *HTTPSink) Archive(ctx context.Context, record *Record) error {
+	by, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, bytes.NewReader(by))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post archive: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("archive endpoint responded %d: %s", resp.StatusCode, msg)
+	}
+	return nil
+}
@@ -0,0 +1,159 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package archive
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+)
+
+func newTestRecord() *Record {
+	claim := &agentsv1alpha1.SandboxClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:          "claim",
+			Namespace:     "default",
+			UID:           "uid-1",
+			ManagedFields: []metav1.ManagedFieldsEntry{{Manager: "controller"}},
+		},
+		Spec:   agentsv1alpha1.SandboxClaimSpec{TemplateName: "python"},
+		Status: agentsv1alpha1.SandboxClaimStatus{Phase: agentsv1alpha1.SandboxClaimPhaseCompleted, Message: "Completed: 1/1 claimed"},
+	}
+	return NewRecord(claim, time.Now())
+}
+
+func TestNewSink(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        Options
+		expectSink  Sink
+		expectError string
+	}{
+		{
+			name: "disabled",
+		},
+		{
+			name:       "file",
+			opts:       Options{Sink: SinkFile, Dir: "/archive"},
+			expectSink: &FileSink{Dir: "/archive"},
+		},
+		{
+			name:        "file without dir",
+			opts:        Options{Sink: SinkFile},
+			expectError: "--sandboxclaim-archive-dir is required",
+		},
+		{
+			name:        "http without url",
+			opts:        Options{Sink: SinkHTTP},
+			expectError: "--sandboxclaim-archive-url is required",
+		},
+		{
+			name:        "unknown",
+			opts:        Options{Sink: "s3"},
+			expectError: `unknown archive sink "s3"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sink, err := NewSink(tt.opts)
+			if tt.expectError != "" {
+				assert.ErrorContains(t, err, tt.expectError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectSink, sink)
+		})
+	}
+}
+
+func TestFileSink(t *testing.T) {
+	dir := t.TempDir()
+	sink := &FileSink{Dir: dir}
+	record := newTestRecord()
+	require.NoError(t, sink.Archive(context.Background(), record))
+	// archiving again overwrites the record
+	require.NoError(t, sink.Archive(context.Background(), record))
+
+	entries, err := os.ReadDir(filepath.Join(dir, "default"))
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "claim-uid-1.json", entries[0].Name())
+	by, err := os.ReadFile(filepath.Join(dir, "default", entries[0].Name()))
+	require.NoError(t, err)
+	got := &Record{}
+	require.NoError(t, json.Unmarshal(by, got))
+	assert.Equal(t, "SandboxClaim", got.Claim.Kind)
+	assert.Equal(t, agentsv1alpha1.GroupVersion.String(), got.Claim.APIVersion)
+	assert.Empty(t, got.Claim.ManagedFields)
+	assert.Equal(t, "python", got.Claim.Spec.TemplateName)
+	assert.Equal(t, "Completed: 1/1 claimed", got.Claim.Status.Message)
+}
+
+func TestHTTPSink(t *testing.T) {
+	tests := []struct {
+		name        string
+		statusCode  int
+		expectError string
+	}{
+		{
+			name:       "stored",
+			statusCode: http.StatusCreated,
+		},
+		{
+			name:        "rejected",
+			statusCode:  http.StatusServiceUnavailable,
+			expectError: "archive endpoint responded 503: busy",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var received *Record
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+				received = &Record{}
+				assert.NoError(t, json.NewDecoder(r.Body).Decode(received))
+				w.WriteHeader(tt.statusCode)
+				if tt.statusCode != http.StatusCreated {
+					_, _ = w.Write([]byte("busy"))
+				}
+			}))
+			defer server.Close()
+
+			sink, err := NewSink(Options{Sink: SinkHTTP, URL: server.URL, Timeout: time.Second})
+			require.NoError(t, err)
+			err = sink.Archive(context.Background(), newTestRecord())
+			if tt.expectError != "" {
+				assert.EqualError(t, err, tt.expectError)
+				return
+			}
+			require.NoError(t, err)
+			require.NotNil(t, received)
+			assert.Equal(t, "uid-1", string(received.Claim.UID))
+		})
+	}
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+	"github.com/openkruise/agents/pkg/utils/sandboxstate"
+)
+
+// adoptClaimedSandboxes repairs the alive sandboxes owned by the claim whose claim was only half applied, e.g. by a
+// controller that crashed or was upgraded mid-claim, so that they carry the claim labels like the ones claimed in one
+// go. The sandboxes are repaired in the order of their names, and the number of repaired ones is returned.
+func (c *commonControl) adoptClaimedSandboxes(ctx context.Context, claim *agentsv1alpha1.SandboxClaim) (int32, error) {
+	log := logf.FromContext(ctx)
+	sandboxes, err := c.cache.ListSandboxWithUser(string(claim.UID))
+	if err != nil {
+		return 0, err
+	}
+	slices.SortFunc(sandboxes, func(a, b *agentsv1alpha1.Sandbox) int {
+		return cmp.Compare(a.Name, b.Name)
+	})
+	var adopted int32
+	now := time.Now()
+	for _, sbx := range sandboxes {
+		if state, _ := sandboxstate.Evaluate(sbx, now, sandboxstate.Policy{}); state == agentsv1alpha1.SandboxStateDead {
+			continue
+		}
+		if !isHalfClaimed(claim, sbx) {
+			continue
+		}
+		sbx = sbx.DeepCopy()
+		if sbx.Labels == nil {
+			sbx.Labels = map[string]string{}
+		}
+		sbx.Labels[agentsv1alpha1.LabelSandboxIsClaimed] = agentsv1alpha1.True
+		sbx.Labels[agentsv1alpha1.LabelSandboxClaimName] = claim.Name
+		sbx.Labels[agentsv1alpha1.LabelSandboxClaimUID] = string(claim.UID)
+		if sbx.Spec.Template != nil {
+			if sbx.Spec.Template.Labels == nil {
+				sbx.Spec.Template.Labels = map[string]string{}
+			}
+			sbx.Spec.Template.Labels[agentsv1alpha1.LabelSandboxClaimName] = claim.Name
+		}
+		// a claimed sandbox is not owned by its SandboxSet anymore, which would otherwise scale it down
+		sbx.OwnerReferences = nil
+		_, err = c.sandboxClient.SandboxClient.ApiV1alpha1().Sandboxes(sbx.Namespace).Update(ctx, sbx, metav1.UpdateOptions{})
+		if errors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return adopted, fmt.Errorf("failed to adopt sandbox %s: %w", sbx.Name, err)
+		}
+		log.Info("Adopted half claimed sandbox", "sandbox", klog.KObj(sbx))
+		adopted++
+	}
+	return adopted, nil
+}
+
+// isHalfClaimed tells if the sandbox owned by the claim misses any of the labels or still has the owner references
+// set when claiming it.
+func isHalfClaimed(claim *agentsv1alpha1.SandboxClaim, sbx *agentsv1alpha1.Sandbox) bool {
+	return sbx.Labels[agentsv1alpha1.LabelSandboxIsClaimed] != agentsv1alpha1.True ||
+		sbx.Labels[agentsv1alpha1.LabelSandboxClaimName] != claim.Name ||
+		sbx.Labels[agentsv1alpha1.LabelSandboxClaimUID] != string(claim.UID) ||
+		len(sbx.OwnerReferences) > 0
+}
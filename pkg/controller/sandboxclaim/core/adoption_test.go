@@ -0,0 +1,170 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+	"github.com/openkruise/agents/pkg/sandbox-manager/infra/sandboxcr"
+)
+
+func TestCommonControl_EnsureClaimClaiming_AdoptsHalfClaimedSandboxes(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = agentsv1alpha1.AddToScheme(scheme)
+
+	cache, clientSet, err := sandboxcr.NewTestCache(t)
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		_ = cache.Run(ctx)
+	}()
+	time.Sleep(200 * time.Millisecond) // Wait for cache to start
+
+	claim := &agentsv1alpha1.SandboxClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "adopting-claim", Namespace: "default", UID: types.UID("adopting-uid")},
+		Spec: agentsv1alpha1.SandboxClaimSpec{
+			TemplateName: "pool",
+			Replicas:     int32Ptr(3),
+		},
+	}
+	newSandbox := func(name string, labels map[string]string) *agentsv1alpha1.Sandbox {
+		labels[agentsv1alpha1.LabelSandboxTemplate] = "pool"
+		return &agentsv1alpha1.Sandbox{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Namespace:   "default",
+				Annotations: map[string]string{agentsv1alpha1.AnnotationOwner: string(claim.UID)},
+				Labels:      labels,
+			},
+			Status: agentsv1alpha1.SandboxStatus{
+				Phase: agentsv1alpha1.SandboxRunning,
+				Conditions: []metav1.Condition{{
+					Type:   string(agentsv1alpha1.SandboxConditionReady),
+					Status: metav1.ConditionTrue,
+				}},
+			},
+		}
+	}
+	claimed := newSandbox("adopting-sbx-1", map[string]string{
+		agentsv1alpha1.LabelSandboxIsClaimed: agentsv1alpha1.True,
+		agentsv1alpha1.LabelSandboxClaimName: claim.Name,
+		agentsv1alpha1.LabelSandboxClaimUID:  string(claim.UID),
+	})
+	unlabeled := newSandbox("adopting-sbx-2", map[string]string{
+		agentsv1alpha1.LabelSandboxIsClaimed: agentsv1alpha1.True,
+	})
+	owned := newSandbox("adopting-sbx-3", map[string]string{
+		agentsv1alpha1.LabelSandboxIsClaimed: agentsv1alpha1.True,
+		agentsv1alpha1.LabelSandboxClaimName: claim.Name,
+		agentsv1alpha1.LabelSandboxClaimUID:  string(claim.UID),
+	})
+	owned.OwnerReferences = []metav1.OwnerReference{{APIVersion: "agents.kruise.io/v1alpha1", Kind: "SandboxSet", Name: "pool", UID: "pool-uid"}}
+	for _, sbx := range []*agentsv1alpha1.Sandbox{claimed, unlabeled, owned} {
+		_, err := clientSet.SandboxClient.ApiV1alpha1().Sandboxes(sbx.Namespace).Create(ctx, sbx, metav1.CreateOptions{})
+		require.NoError(t, err)
+	}
+	time.Sleep(100 * time.Millisecond) // Wait for cache sync
+
+	sandboxSet := &agentsv1alpha1.SandboxSet{ObjectMeta: metav1.ObjectMeta{Name: "pool", Namespace: "default", UID: "pool-uid"}}
+	// the controller crashed after claiming the sandboxes, before recording them in the status
+	newStatus := &agentsv1alpha1.SandboxClaimStatus{
+		Phase:           agentsv1alpha1.SandboxClaimPhaseClaiming,
+		ClaimStartTime:  &metav1.Time{Time: time.Now()},
+		ClaimedReplicas: 1,
+	}
+	claim.Status = *newStatus.DeepCopy()
+	recorder := record.NewFakeRecorder(10)
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(claim, sandboxSet).Build()
+	control := NewCommonControl(fakeClient, recorder, clientSet, cache)
+
+	strategy, err := control.EnsureClaimClaiming(ctx, ClaimArgs{Claim: claim, SandboxSet: sandboxSet, NewStatus: newStatus})
+	require.NoError(t, err)
+	assert.Equal(t, "AllReplicasClaimed", strategy.Reason)
+	assert.Equal(t, int32(3), newStatus.ClaimedReplicas)
+	require.NotEmpty(t, recorder.Events)
+	assert.Equal(t, "Normal SandboxesAdopted Adopted 2 half claimed sandbox(es), 3 claimed in total", <-recorder.Events)
+
+	for _, name := range []string{"adopting-sbx-2", "adopting-sbx-3"} {
+		sbx, err := clientSet.SandboxClient.ApiV1alpha1().Sandboxes("default").Get(ctx, name, metav1.GetOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, claim.Name, sbx.Labels[agentsv1alpha1.LabelSandboxClaimName], name)
+		assert.Equal(t, string(claim.UID), sbx.Labels[agentsv1alpha1.LabelSandboxClaimUID], name)
+		assert.Empty(t, sbx.OwnerReferences, name)
+	}
+}
+
+func TestIsHalfClaimed(t *testing.T) {
+	claim := &agentsv1alpha1.SandboxClaim{ObjectMeta: metav1.ObjectMeta{Name: "claim", UID: "claim-uid"}}
+	claimedLabels := func() map[string]string {
+		return map[string]string{
+			agentsv1alpha1.LabelSandboxIsClaimed: agentsv1alpha1.True,
+			agentsv1alpha1.LabelSandboxClaimName: "claim",
+			agentsv1alpha1.LabelSandboxClaimUID:  "claim-uid",
+		}
+	}
+	tests := []struct {
+		name     string
+		modify   func(sbx *agentsv1alpha1.Sandbox)
+		expected bool
+	}{
+		{
+			name:   "fully claimed",
+			modify: func(sbx *agentsv1alpha1.Sandbox) {},
+		},
+		{
+			name:     "not labeled claimed",
+			modify:   func(sbx *agentsv1alpha1.Sandbox) { delete(sbx.Labels, agentsv1alpha1.LabelSandboxIsClaimed) },
+			expected: true,
+		},
+		{
+			name:     "claim name label of a claim recreated with the same name",
+			modify:   func(sbx *agentsv1alpha1.Sandbox) { sbx.Labels[agentsv1alpha1.LabelSandboxClaimUID] = "previous-uid" },
+			expected: true,
+		},
+		{
+			name:     "claimed before the claim uid label was introduced",
+			modify:   func(sbx *agentsv1alpha1.Sandbox) { delete(sbx.Labels, agentsv1alpha1.LabelSandboxClaimUID) },
+			expected: true,
+		},
+		{
+			name: "still owned by the sandboxset",
+			modify: func(sbx *agentsv1alpha1.Sandbox) {
+				sbx.OwnerReferences = []metav1.OwnerReference{{Kind: "SandboxSet", Name: "pool"}}
+			},
+			expected: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sbx := &agentsv1alpha1.Sandbox{ObjectMeta: metav1.ObjectMeta{Name: "sbx", Labels: claimedLabels()}}
+			tt.modify(sbx)
+			assert.Equal(t, tt.expected, isHalfClaimed(claim, sbx))
+		})
+	}
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+	sandboxfake "github.com/openkruise/agents/client/clientset/versioned/fake"
+	"github.com/openkruise/agents/pkg/sandbox-manager/infra/sandboxcr"
+	"github.com/openkruise/agents/pkg/utils/fieldindex"
+)
+
+func TestCommonControl_EnsureClaimClaiming_RetriesLockConflicts(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = agentsv1alpha1.AddToScheme(scheme)
+
+	cache, clientSet, err := sandboxcr.NewTestCache(t)
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		_ = cache.Run(ctx)
+	}()
+	time.Sleep(200 * time.Millisecond) // Wait for cache to start
+
+	sandboxSet := &agentsv1alpha1.SandboxSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "conflict-template", Namespace: "default", UID: "conflict-template-uid"},
+	}
+	for i := range 3 {
+		CreateSandboxWithStatus(t, clientSet.SandboxClient, &agentsv1alpha1.Sandbox{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              fmt.Sprintf("conflict-sbx-%d", i),
+				Namespace:         "default",
+				CreationTimestamp: metav1.Now(),
+				Labels:            map[string]string{agentsv1alpha1.LabelSandboxTemplate: sandboxSet.Name},
+				OwnerReferences:   []metav1.OwnerReference{*metav1.NewControllerRef(sandboxSet, agentsv1alpha1.SandboxSetControllerKind)},
+			},
+			Status: agentsv1alpha1.SandboxStatus{
+				Phase: agentsv1alpha1.SandboxRunning,
+				Conditions: []metav1.Condition{{
+					Type:   string(agentsv1alpha1.SandboxConditionReady),
+					Status: metav1.ConditionTrue,
+				}},
+				PodInfo: agentsv1alpha1.PodInfo{PodIP: fmt.Sprintf("1.2.3.%d", i)},
+			},
+		})
+	}
+	time.Sleep(100 * time.Millisecond) // Wait for cache sync
+
+	// the first sandbox picked was claimed concurrently by another claim
+	var conflicts atomic.Int32
+	clientSet.SandboxClient.(*sandboxfake.Clientset).PrependReactor("update", "sandboxes",
+		func(action k8stesting.Action) (bool, runtime.Object, error) {
+			if conflicts.Add(1) > 1 {
+				return false, nil, nil
+			}
+			sbx := action.(k8stesting.UpdateAction).GetObject().(*agentsv1alpha1.Sandbox)
+			return true, nil, apierrors.NewConflict(schema.GroupResource{Resource: "sandboxes"}, sbx.Name, fmt.Errorf("claimed concurrently"))
+		})
+
+	claim := &agentsv1alpha1.SandboxClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "conflict-claim", Namespace: "default", UID: "conflict-uid"},
+		Spec: agentsv1alpha1.SandboxClaimSpec{
+			TemplateName:    sandboxSet.Name,
+			Replicas:        int32Ptr(2),
+			SkipInitRuntime: true,
+		},
+	}
+	newStatus := &agentsv1alpha1.SandboxClaimStatus{Phase: agentsv1alpha1.SandboxClaimPhaseClaiming}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(claim, sandboxSet).
+		WithIndex(&agentsv1alpha1.SandboxClaim{}, fieldindex.IndexNameForSandboxSetName, SandboxSetNameIndexFunc).Build()
+	control := NewCommonControl(fakeClient, record.NewFakeRecorder(10), clientSet, cache)
+
+	strategy, err := control.EnsureClaimClaiming(ctx, ClaimArgs{Claim: claim, SandboxSet: sandboxSet, NewStatus: newStatus})
+	require.NoError(t, err)
+	assert.Equal(t, "ClaimProgress", strategy.Reason)
+	assert.Equal(t, int32(2), newStatus.ClaimedReplicas, "the conflicting bind picks another sandbox in the same reconcile")
+	assert.Greater(t, conflicts.Load(), int32(2))
+}
@@ -0,0 +1,341 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+	"github.com/openkruise/agents/pkg/sandbox-manager/infra"
+	"github.com/openkruise/agents/pkg/utils/requeue"
+	"github.com/openkruise/agents/pkg/utils/sandboxutils"
+)
+
+// groupBoundTimeout is how long the replicas bound for a member of a claim group are held for the cache to observe them
+const groupBoundTimeout = time.Minute
+
+// claimGroupLocks is the number of locks the claim groups are spread over
+const claimGroupLocks = 64
+
+// ClaimGroupCoordinator serializes the claiming of claim groups, and holds the replicas bound for the members of a
+// group until they are observed on the claimed sandboxes, so that they are never claimed twice while the cache is
+// stale, as the members do not bind their sandboxes themselves.
+type ClaimGroupCoordinator interface {
+	// Lock locks the group of the claim and returns the function unlocking it.
+	Lock(claim *agentsv1alpha1.SandboxClaim) func()
+	// Bound returns the replicas of the member claimed by its group, at least the observed ones.
+	Bound(claim metav1.Object, observed int32) int32
+	// SetBound holds the replicas of the member claimed by its group.
+	SetBound(claim metav1.Object, replicas int32)
+	// Forget drops the replicas held for the member.
+	Forget(claim metav1.Object)
+}
+
+func NewClaimGroupCoordinator() ClaimGroupCoordinator {
+	return &realClaimGroupCoordinator{
+		bound: make(map[types.UID]heldReplicas),
+		now:   time.Now,
+	}
+}
+
+type heldReplicas struct {
+	replicas int32
+	since    time.Time
+}
+
+type realClaimGroupCoordinator struct {
+	locks [claimGroupLocks]sync.Mutex
+	mu    sync.Mutex
+	bound map[types.UID]heldReplicas
+	now   func() time.Time
+}
+
+func (g *realClaimGroupCoordinator) Lock(claim *agentsv1alpha1.SandboxClaim) func() {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(claimGroupKey(claim)))
+	lock := &g.locks[h.Sum32()%claimGroupLocks]
+	lock.Lock()
+	return lock.Unlock
+}
+
+func (g *realClaimGroupCoordinator) Bound(claim metav1.Object, observed int32) int32 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	held, ok := g.bound[claim.GetUID()]
+	if !ok {
+		return observed
+	}
+	if observed >= held.replicas || g.now().Sub(held.since) >= groupBoundTimeout {
+		delete(g.bound, claim.GetUID())
+		return observed
+	}
+	return held.replicas
+}
+
+func (g *realClaimGroupCoordinator) SetBound(claim metav1.Object, replicas int32) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.bound[claim.GetUID()] = heldReplicas{replicas: replicas, since: g.now()}
+}
+
+func (g *realClaimGroupCoordinator) Forget(claim metav1.Object) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	delete(g.bound, claim.GetUID())
+}
+
+// claimGroupKey returns the key of the group of the claim, unique across namespaces
+func claimGroupKey(claim *agentsv1alpha1.SandboxClaim) string {
+	return claim.Namespace + "/" + claim.Spec.ClaimGroup.Name
+}
+
+// claimGroupState is the outcome of evaluating the members of a claim group for one of them
+type claimGroupState struct {
+	// failed is the member completed without all its replicas, failing the group
+	failed *agentsv1alpha1.SandboxClaim
+	// ready is the number of members claiming or fully claimed, out of the size of the group
+	ready int32
+	// leader is the member claiming the sandboxes of the whole group, i.e. the first claiming member by name
+	leader *agentsv1alpha1.SandboxClaim
+}
+
+// evaluateClaimGroup evaluates the members of the group of the claim, sorted by name. Paused members are not ready, so
+// that the group is not claimed while any of them is paused.
+func evaluateClaimGroup(members []*agentsv1alpha1.SandboxClaim) claimGroupState {
+	var state claimGroupState
+	for _, member := range members {
+		switch member.Status.Phase {
+		case agentsv1alpha1.SandboxClaimPhaseFailed:
+			state.failed = member
+		case agentsv1alpha1.SandboxClaimPhaseCompleted:
+			if member.Status.ClaimedReplicas < getDesiredReplicas(member) {
+				state.failed = member
+			} else {
+				state.ready++
+			}
+		case agentsv1alpha1.SandboxClaimPhaseClaiming:
+			if member.Spec.Paused {
+				continue
+			}
+			state.ready++
+			if state.leader == nil {
+				state.leader = member
+			}
+		}
+	}
+	return state
+}
+
+// listClaimGroup lists the members of the group of the claim sorted by name, the claim itself included as it is
+// being reconciled.
+func (c *commonControl) listClaimGroup(ctx context.Context, claim *agentsv1alpha1.SandboxClaim) ([]*agentsv1alpha1.SandboxClaim, error) {
+	claims := &agentsv1alpha1.SandboxClaimList{}
+	if err := c.List(ctx, claims, client.InNamespace(claim.Namespace)); err != nil {
+		return nil, fmt.Errorf("failed to list sandboxclaims: %w", err)
+	}
+	members := []*agentsv1alpha1.SandboxClaim{claim}
+	for i := range claims.Items {
+		member := &claims.Items[i]
+		if member.UID == claim.UID || member.DeletionTimestamp != nil || member.Spec.ClaimGroup == nil ||
+			member.Spec.ClaimGroup.Name != claim.Spec.ClaimGroup.Name {
+			continue
+		}
+		members = append(members, member)
+	}
+	slices.SortFunc(members, func(a, b *agentsv1alpha1.SandboxClaim) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return members, nil
+}
+
+// claimGroupPlan is the remaining replicas of a member of a claim group to claim from its SandboxSet
+type claimGroupPlan struct {
+	member     *agentsv1alpha1.SandboxClaim
+	sandboxSet *agentsv1alpha1.SandboxSet
+	claimed    int32
+	remaining  int32
+}
+
+// ensureClaimGroupClaiming claims the remaining replicas of the claim as a member of its claim group. The group waits
+// until all its members are claiming, then the first claiming member by name claims the remaining replicas of all of
+// them at once, once their SandboxSets have enough claimable sandboxes, and releases the sandboxes bound for all of
+// them if any bind fails. The other members wait for their sandboxes to be claimed by it.
+func (c *commonControl) ensureClaimGroupClaiming(ctx context.Context, args ClaimArgs, currentCount, desiredReplicas int32,
+	quota *namespaceQuota) (RequeueStrategy, error) {
+	log := logf.FromContext(ctx)
+	claim, group := args.Claim, args.Claim.Spec.ClaimGroup
+
+	unlock := ClaimGroups.Lock(claim)
+	defer unlock()
+	members, err := c.listClaimGroup(ctx, claim)
+	if err != nil {
+		return requeue.NoRequeue(), err
+	}
+	state := evaluateClaimGroup(members)
+	if state.failed != nil {
+		message := fmt.Sprintf("Claim %s of group %s completed with %d/%d claimed", state.failed.Name, group.Name,
+			state.failed.Status.ClaimedReplicas, getDesiredReplicas(state.failed))
+		log.Info("Claim group failed, transitioning to Completed", "group", group.Name, "failed", state.failed.Name)
+		c.recorder.Event(claim, "Warning", "ClaimGroupFailed", message)
+		TransitionToCompleted(args.NewStatus, "ClaimGroupFailed", message)
+		return requeue.Immediately().WithReason("ClaimGroupFailed"), nil
+	}
+	if int32(len(members)) < group.Size || state.ready < group.Size {
+		log.Info("Waiting for the claims of the group to be claiming", "group", group.Name,
+			"members", len(members), "ready", state.ready, "size", group.Size)
+		args.NewStatus.Message = fmt.Sprintf("Waiting for %d/%d claims of group %s to be claiming: %d/%d claimed",
+			state.ready, group.Size, group.Name, currentCount, desiredReplicas)
+		c.warnClaimTimeoutApproaching(claim, args.NewStatus, time.Now())
+		return requeue.After(ClaimRetryInterval).WithReason("WaitingForClaimGroup"), nil
+	}
+	if state.leader.UID != claim.UID {
+		args.NewStatus.Message = fmt.Sprintf("Waiting for claim %s to claim the sandboxes of group %s: %d/%d claimed",
+			state.leader.Name, group.Name, currentCount, desiredReplicas)
+		c.warnClaimTimeoutApproaching(claim, args.NewStatus, time.Now())
+		return requeue.After(ClaimRetryInterval).WithReason("WaitingForClaimGroup"), nil
+	}
+
+	// Plan the remaining replicas of all the members, which must be claimable at once
+	plans, shortage, err := c.planClaimGroup(ctx, claim, members, currentCount, quota)
+	if err != nil {
+		return requeue.NoRequeue(), err
+	}
+	if shortage != "" {
+		log.Info("Not enough sandboxes to claim the whole group at once, will retry", "group", group.Name, "shortage", shortage)
+		message := fmt.Sprintf("Group %s waits for sandboxes to claim all its claims at once: %s", group.Name, shortage)
+		c.recorder.Event(claim, "Normal", "WaitingForClaimGroupSandboxes", message)
+		recordHistory(args.NewStatus, "WaitingForClaimGroupSandboxes", message)
+		args.NewStatus.Message = fmt.Sprintf("Waiting for sandboxes to claim group %s at once: %d/%d claimed",
+			group.Name, currentCount, desiredReplicas)
+		c.warnClaimTimeoutApproaching(claim, args.NewStatus, time.Now())
+		return requeue.After(ClaimRetryInterval).WithReason("WaitingForClaimGroupSandboxes"), nil
+	}
+
+	// Claim the whole group, releasing the sandboxes bound for all the members if any bind fails
+	var bound []infra.Sandbox
+	claimed := int32(0)
+	for _, plan := range plans {
+		planBound, _, err := c.claimSandboxes(ctx, plan.member, plan.sandboxSet, int(plan.remaining), plan.claimed,
+			getDesiredReplicas(plan.member))
+		bound = append(bound, planBound...)
+		if int32(len(planBound)) < plan.remaining {
+			log.Error(err, "Failed to claim the sandboxes of a claim of the group", "group", group.Name,
+				"member", plan.member.Name, "claimed", len(planBound), "attempted", plan.remaining)
+			released, rollbackErr := c.rollbackBoundSandboxes(ctx, bound)
+			message := fmt.Sprintf("Released %d sandbox(es) bound before claiming group %s failed at claim %s",
+				released, group.Name, plan.member.Name)
+			c.recorder.Event(claim, "Warning", "ClaimGroupRolledBack", message)
+			recordHistory(args.NewStatus, "ClaimGroupRolledBack", message)
+			if rollbackErr != nil {
+				return requeue.NoRequeue(), fmt.Errorf("failed to release sandboxes of incomplete claim group: %w", rollbackErr)
+			}
+			args.NewStatus.Message = fmt.Sprintf("Failed to claim group %s at once, will retry: %d/%d claimed",
+				group.Name, currentCount, desiredReplicas)
+			c.warnClaimTimeoutApproaching(claim, args.NewStatus, time.Now())
+			return requeue.After(ClaimRetryInterval).WithReason("ClaimGroupRolledBack"), nil
+		}
+		if plan.member.UID == claim.UID {
+			claimed = plan.remaining
+		}
+	}
+	for _, plan := range plans {
+		if plan.member.UID != claim.UID {
+			ClaimGroups.SetBound(plan.member, plan.claimed+plan.remaining)
+			c.recorder.Event(plan.member, "Normal", "ClaimGroupClaimed",
+				fmt.Sprintf("Claimed %d sandbox(es) with group %s by claim %s", plan.remaining, group.Name, claim.Name))
+		}
+	}
+
+	finalCount := currentCount + claimed
+	log.Info("Claimed the sandboxes of the group", "group", group.Name, "claims", len(plans), "sandboxes", len(bound))
+	message := fmt.Sprintf("Claimed %d sandbox(es) for %d claim(s) of group %s", len(bound), len(plans), group.Name)
+	c.recorder.Event(claim, "Normal", "ClaimGroupClaimed", message)
+	recordHistory(args.NewStatus, "ClaimGroupClaimed", message)
+	recordClaimedReplicas(claim, args.NewStatus, finalCount, args.NewStatus.ClaimedReplicas)
+	args.NewStatus.Message = fmt.Sprintf("Claiming sandboxes: %d/%d claimed", finalCount, desiredReplicas)
+	return requeue.Immediately().WithReason("ClaimProgress"), nil
+}
+
+// planClaimGroup plans the remaining replicas of the members of the group, and describes the shortage of sandboxes if
+// their SandboxSets or the SandboxQuota of the namespace, if any, cannot provide all of them at once.
+func (c *commonControl) planClaimGroup(ctx context.Context, claim *agentsv1alpha1.SandboxClaim,
+	members []*agentsv1alpha1.SandboxClaim, currentCount int32, quota *namespaceQuota) ([]claimGroupPlan, string, error) {
+	var plans []claimGroupPlan
+	demand := map[types.NamespacedName]int32{}
+	sandboxSets := map[types.NamespacedName]*agentsv1alpha1.SandboxSet{}
+	for _, member := range members {
+		claimed := currentCount
+		if member.UID != claim.UID {
+			observed, err := c.countClaimedSandboxes(ctx, member)
+			if err != nil {
+				return nil, "", fmt.Errorf("failed to count claimed sandboxes of claim %s: %w", member.Name, err)
+			}
+			claimed = ClaimGroups.Bound(member, max(boundReplicas(&member.Status), observed))
+		}
+		remaining := getDesiredReplicas(member) - claimed
+		if remaining <= 0 {
+			continue
+		}
+		key := types.NamespacedName{Namespace: member.Namespace, Name: sandboxutils.ClaimSandboxSetName(member)}
+		sandboxSet, ok := sandboxSets[key]
+		if !ok {
+			sandboxSet = &agentsv1alpha1.SandboxSet{}
+			if err := c.Get(ctx, key, sandboxSet); err != nil {
+				return nil, "", fmt.Errorf("failed to get SandboxSet of claim %s: %w", member.Name, err)
+			}
+			sandboxSets[key] = sandboxSet
+		}
+		claimable, err := c.countClaimableSandboxes(member, sandboxSet)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to count claimable sandboxes of claim %s: %w", member.Name, err)
+		}
+		if claimable < int(remaining) {
+			return nil, fmt.Sprintf("pool %s has %d claimable sandbox(es) for the %d replicas of claim %s",
+				sandboxSet.Name, claimable, remaining, member.Name), nil
+		}
+		demand[key] += remaining
+		plans = append(plans, claimGroupPlan{member: member, sandboxSet: sandboxSet, claimed: claimed, remaining: remaining})
+	}
+	if quota != nil {
+		var total int32
+		for _, needed := range demand {
+			total += needed
+		}
+		if headroom := quota.headroom(); headroom < total {
+			return nil, fmt.Sprintf("SandboxQuota %s allows %d more sandbox(es) for the %d replicas of the group",
+				quota.name, headroom, total), nil
+		}
+	}
+	for key, needed := range demand {
+		if available := len(c.listPoolSandboxes(sandboxSets[key], agentsv1alpha1.SandboxStateAvailable)); available < int(needed) {
+			return nil, fmt.Sprintf("pool %s has %d available sandbox(es) for the %d replicas of the group",
+				key.Name, available, needed), nil
+		}
+	}
+	return plans, "", nil
+}
@@ -0,0 +1,262 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+	"github.com/openkruise/agents/pkg/sandbox-manager/infra/sandboxcr"
+	"github.com/openkruise/agents/pkg/utils/conditions"
+)
+
+func newGroupMember(name string, phase agentsv1alpha1.SandboxClaimPhase, claimed int32) *agentsv1alpha1.SandboxClaim {
+	return &agentsv1alpha1.SandboxClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", UID: types.UID(name + "-uid")},
+		Spec: agentsv1alpha1.SandboxClaimSpec{
+			TemplateName: "pool",
+			Replicas:     ptr.To[int32](2),
+			ClaimGroup:   &agentsv1alpha1.SandboxClaimGroup{Name: "team", Size: 2},
+		},
+		Status: agentsv1alpha1.SandboxClaimStatus{Phase: phase, ClaimedReplicas: claimed},
+	}
+}
+
+func TestClaimGroupCoordinator(t *testing.T) {
+	claim := newGroupMember("planner", agentsv1alpha1.SandboxClaimPhaseClaiming, 0)
+
+	t.Run("bound replicas are held until observed", func(t *testing.T) {
+		coordinator := NewClaimGroupCoordinator()
+		assert.Equal(t, int32(1), coordinator.Bound(claim, 1))
+		coordinator.SetBound(claim, 2)
+		assert.Equal(t, int32(2), coordinator.Bound(claim, 0), "the stale cache does not show the bound sandboxes yet")
+		assert.Equal(t, int32(2), coordinator.Bound(claim, 2))
+		assert.Equal(t, int32(1), coordinator.Bound(claim, 1), "observed replicas are not held anymore")
+	})
+	t.Run("bound replicas are held until timeout", func(t *testing.T) {
+		coordinator := NewClaimGroupCoordinator().(*realClaimGroupCoordinator)
+		now := time.Now()
+		coordinator.now = func() time.Time { return now }
+		coordinator.SetBound(claim, 2)
+		now = now.Add(groupBoundTimeout)
+		assert.Equal(t, int32(0), coordinator.Bound(claim, 0))
+	})
+	t.Run("forget drops the bound replicas", func(t *testing.T) {
+		coordinator := NewClaimGroupCoordinator()
+		coordinator.SetBound(claim, 2)
+		coordinator.Forget(claim)
+		assert.Equal(t, int32(0), coordinator.Bound(claim, 0))
+	})
+	t.Run("lock serializes the group", func(t *testing.T) {
+		coordinator := NewClaimGroupCoordinator()
+		unlock := coordinator.Lock(claim)
+		locked := make(chan struct{})
+		go func() {
+			defer close(locked)
+			coordinator.Lock(newGroupMember("coder", agentsv1alpha1.SandboxClaimPhaseClaiming, 0))()
+		}()
+		select {
+		case <-locked:
+			t.Fatal("the group is locked twice")
+		case <-time.After(50 * time.Millisecond):
+		}
+		unlock()
+		<-locked
+	})
+}
+
+func TestEvaluateClaimGroup(t *testing.T) {
+	paused := newGroupMember("coder", agentsv1alpha1.SandboxClaimPhaseClaiming, 0)
+	paused.Spec.Paused = true
+
+	tests := []struct {
+		name         string
+		members      []*agentsv1alpha1.SandboxClaim
+		expectFailed string
+		expectReady  int32
+		expectLeader string
+	}{
+		{
+			name: "first claiming member leads",
+			members: []*agentsv1alpha1.SandboxClaim{
+				newGroupMember("coder", agentsv1alpha1.SandboxClaimPhaseClaiming, 0),
+				newGroupMember("planner", agentsv1alpha1.SandboxClaimPhaseClaiming, 0),
+			},
+			expectReady:  2,
+			expectLeader: "coder",
+		},
+		{
+			name: "fully claimed member is ready",
+			members: []*agentsv1alpha1.SandboxClaim{
+				newGroupMember("coder", agentsv1alpha1.SandboxClaimPhaseCompleted, 2),
+				newGroupMember("planner", agentsv1alpha1.SandboxClaimPhaseClaiming, 0),
+			},
+			expectReady:  2,
+			expectLeader: "planner",
+		},
+		{
+			name: "member completed without all replicas fails the group",
+			members: []*agentsv1alpha1.SandboxClaim{
+				newGroupMember("coder", agentsv1alpha1.SandboxClaimPhaseCompleted, 0),
+				newGroupMember("planner", agentsv1alpha1.SandboxClaimPhaseClaiming, 0),
+			},
+			expectFailed: "coder",
+			expectReady:  1,
+			expectLeader: "planner",
+		},
+		{
+			name: "queued and paused members are not ready",
+			members: []*agentsv1alpha1.SandboxClaim{
+				paused,
+				newGroupMember("planner", agentsv1alpha1.SandboxClaimPhaseQueued, 0),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := evaluateClaimGroup(tt.members)
+			if tt.expectFailed == "" {
+				assert.Nil(t, state.failed)
+			} else {
+				require.NotNil(t, state.failed)
+				assert.Equal(t, tt.expectFailed, state.failed.Name)
+			}
+			assert.Equal(t, tt.expectReady, state.ready)
+			if tt.expectLeader == "" {
+				assert.Nil(t, state.leader)
+			} else {
+				require.NotNil(t, state.leader)
+				assert.Equal(t, tt.expectLeader, state.leader.Name)
+			}
+		})
+	}
+}
+
+func TestCommonControl_ensureClaimGroupClaiming(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = agentsv1alpha1.AddToScheme(scheme)
+
+	cache, clientSet, err := sandboxcr.NewTestCache(t)
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		_ = cache.Run(ctx)
+	}()
+	sandboxSet := &agentsv1alpha1.SandboxSet{ObjectMeta: metav1.ObjectMeta{Name: "pool", Namespace: "default", UID: "pool-uid"}}
+
+	tests := []struct {
+		name          string
+		claim         string
+		members       []*agentsv1alpha1.SandboxClaim
+		expectReason  string
+		expectMessage string
+		expectPhase   agentsv1alpha1.SandboxClaimPhase
+	}{
+		{
+			name:  "waits for the missing members",
+			claim: "planner",
+			members: []*agentsv1alpha1.SandboxClaim{
+				newGroupMember("planner", agentsv1alpha1.SandboxClaimPhaseClaiming, 0),
+			},
+			expectReason:  "WaitingForClaimGroup",
+			expectMessage: "Waiting for 1/2 claims of group team to be claiming: 0/2 claimed",
+			expectPhase:   agentsv1alpha1.SandboxClaimPhaseClaiming,
+		},
+		{
+			name:  "waits for the queued members",
+			claim: "planner",
+			members: []*agentsv1alpha1.SandboxClaim{
+				newGroupMember("coder", agentsv1alpha1.SandboxClaimPhaseQueued, 0),
+				newGroupMember("planner", agentsv1alpha1.SandboxClaimPhaseClaiming, 0),
+			},
+			expectReason:  "WaitingForClaimGroup",
+			expectMessage: "Waiting for 1/2 claims of group team to be claiming: 0/2 claimed",
+			expectPhase:   agentsv1alpha1.SandboxClaimPhaseClaiming,
+		},
+		{
+			name:  "waits for the leader",
+			claim: "planner",
+			members: []*agentsv1alpha1.SandboxClaim{
+				newGroupMember("coder", agentsv1alpha1.SandboxClaimPhaseClaiming, 0),
+				newGroupMember("planner", agentsv1alpha1.SandboxClaimPhaseClaiming, 0),
+			},
+			expectReason:  "WaitingForClaimGroup",
+			expectMessage: "Waiting for claim coder to claim the sandboxes of group team: 0/2 claimed",
+			expectPhase:   agentsv1alpha1.SandboxClaimPhaseClaiming,
+		},
+		{
+			name:  "leader waits for sandboxes of the whole group",
+			claim: "coder",
+			members: []*agentsv1alpha1.SandboxClaim{
+				newGroupMember("coder", agentsv1alpha1.SandboxClaimPhaseClaiming, 0),
+				newGroupMember("planner", agentsv1alpha1.SandboxClaimPhaseClaiming, 0),
+			},
+			expectReason:  "WaitingForClaimGroupSandboxes",
+			expectMessage: "Waiting for sandboxes to claim group team at once: 0/2 claimed",
+			expectPhase:   agentsv1alpha1.SandboxClaimPhaseClaiming,
+		},
+		{
+			name:  "failed member completes the group",
+			claim: "planner",
+			members: []*agentsv1alpha1.SandboxClaim{
+				newGroupMember("coder", agentsv1alpha1.SandboxClaimPhaseCompleted, 1),
+				newGroupMember("planner", agentsv1alpha1.SandboxClaimPhaseClaiming, 0),
+			},
+			expectReason:  "ClaimGroupFailed",
+			expectMessage: "Claim coder of group team completed with 1/2 claimed",
+			expectPhase:   agentsv1alpha1.SandboxClaimPhaseCompleted,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var claim *agentsv1alpha1.SandboxClaim
+			objects := []client.Object{sandboxSet}
+			for _, member := range tt.members {
+				objects = append(objects, member)
+				if member.Name == tt.claim {
+					claim = member
+				}
+			}
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()
+			control := NewCommonControl(fakeClient, record.NewFakeRecorder(10), clientSet, cache).(*commonControl)
+			newStatus := claim.Status.DeepCopy()
+			newStatus.ClaimStartTime = &metav1.Time{Time: time.Now()}
+
+			strategy, err := control.ensureClaimGroupClaiming(ctx, ClaimArgs{Claim: claim, SandboxSet: sandboxSet, NewStatus: newStatus}, 0, 2, nil)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectReason, strategy.Reason)
+			assert.Equal(t, tt.expectMessage, newStatus.Message)
+			assert.Equal(t, tt.expectPhase, newStatus.Phase)
+			if tt.expectPhase == agentsv1alpha1.SandboxClaimPhaseCompleted {
+				assert.True(t, conditions.IsTrue(newStatus.Conditions, string(agentsv1alpha1.SandboxClaimConditionCompleted)))
+			}
+		})
+	}
+}
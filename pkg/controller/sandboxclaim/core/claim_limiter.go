@@ -0,0 +1,144 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// ClaimLimiter limits the number of claims claiming sandboxes from a pool at the same time.
+// Claims over the limit wait in a FIFO queue of the pool, ordered by creation time.
+type ClaimLimiter interface {
+	// Admit tries to admit the claim to claim sandboxes from the pool.
+	// It returns true if the claim is admitted, otherwise the 1-based position of the claim in the queue.
+	// A claim stays admitted until it is released, even if the limit is lowered.
+	Admit(pool string, claim metav1.Object, limit int32) (bool, int32)
+	// Release removes the claim from both the admitted claims and the queue of its pool.
+	Release(claim metav1.Object)
+}
+
+func NewClaimLimiter() ClaimLimiter {
+	return &realClaimLimiter{
+		pools:  make(map[string]*claimQueue),
+		claims: make(map[types.UID]string),
+	}
+}
+
+type realClaimLimiter struct {
+	sync.Mutex
+	pools map[string]*claimQueue
+	// claims records the pool of every admitted or queued claim
+	claims map[types.UID]string
+}
+
+type claimQueue struct {
+	admitted sets.Set[types.UID]
+	waiting  []queuedClaim
+}
+
+type queuedClaim struct {
+	uid     types.UID
+	key     string
+	created time.Time
+}
+
+func (q *queuedClaim) before(other *queuedClaim) bool {
+	if !q.created.Equal(other.created) {
+		return q.created.Before(other.created)
+	}
+	return q.key < other.key
+}
+
+func (l *realClaimLimiter) Admit(pool string, claim metav1.Object, limit int32) (bool, int32) {
+	l.Lock()
+	defer l.Unlock()
+
+	uid := claim.GetUID()
+	if current, ok := l.claims[uid]; ok && current != pool {
+		// the claim moved to another pool
+		l.releaseLocked(uid)
+	}
+	q := l.pools[pool]
+	if q == nil {
+		q = &claimQueue{admitted: sets.New[types.UID]()}
+		l.pools[pool] = q
+	}
+	if q.admitted.Has(uid) {
+		return true, 0
+	}
+
+	idx := q.indexOf(uid)
+	if idx < 0 {
+		entry := queuedClaim{
+			uid:     uid,
+			key:     claim.GetNamespace() + "/" + claim.GetName(),
+			created: claim.GetCreationTimestamp().Time,
+		}
+		idx = sort.Search(len(q.waiting), func(i int) bool {
+			return entry.before(&q.waiting[i])
+		})
+		q.waiting = append(q.waiting, queuedClaim{})
+		copy(q.waiting[idx+1:], q.waiting[idx:])
+		q.waiting[idx] = entry
+		l.claims[uid] = pool
+	}
+
+	// only claims at the head of the queue take the free slots, so that later claims cannot overtake earlier ones
+	if idx < int(limit)-q.admitted.Len() {
+		q.waiting = append(q.waiting[:idx], q.waiting[idx+1:]...)
+		q.admitted.Insert(uid)
+		return true, 0
+	}
+	return false, int32(idx + 1)
+}
+
+func (l *realClaimLimiter) Release(claim metav1.Object) {
+	l.Lock()
+	defer l.Unlock()
+	l.releaseLocked(claim.GetUID())
+}
+
+func (l *realClaimLimiter) releaseLocked(uid types.UID) {
+	pool, ok := l.claims[uid]
+	if !ok {
+		return
+	}
+	delete(l.claims, uid)
+	q := l.pools[pool]
+	q.admitted.Delete(uid)
+	if idx := q.indexOf(uid); idx >= 0 {
+		q.waiting = append(q.waiting[:idx], q.waiting[idx+1:]...)
+	}
+	if q.admitted.Len() == 0 && len(q.waiting) == 0 {
+		delete(l.pools, pool)
+	}
+}
+
+func (q *claimQueue) indexOf(uid types.UID) int {
+	for i := range q.waiting {
+		if q.waiting[i].uid == uid {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,133 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestClaimLimiter(t *testing.T) {
+	base := time.Now()
+	newClaim := func(name string, age time.Duration) *metav1.ObjectMeta {
+		return &metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         "default",
+			UID:               types.UID(name),
+			CreationTimestamp: metav1.NewTime(base.Add(-age)),
+		}
+	}
+	type step struct {
+		pool     string
+		claim    string
+		limit    int32
+		release  bool
+		admitted bool
+		position int32
+	}
+	claims := map[string]*metav1.ObjectMeta{
+		"a": newClaim("a", 5*time.Minute),
+		"b": newClaim("b", 4*time.Minute),
+		"c": newClaim("c", 3*time.Minute),
+		"d": newClaim("d", 3*time.Minute),
+	}
+
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{
+			name: "admit up to the limit",
+			steps: []step{
+				{pool: "p", claim: "a", limit: 2, admitted: true},
+				{pool: "p", claim: "b", limit: 2, admitted: true},
+				{pool: "p", claim: "c", limit: 2, position: 1},
+				{pool: "p", claim: "a", limit: 2, admitted: true},
+			},
+		},
+		{
+			name: "released slot goes to the oldest queued claim",
+			steps: []step{
+				{pool: "p", claim: "a", limit: 1, admitted: true},
+				{pool: "p", claim: "d", limit: 1, position: 1},
+				{pool: "p", claim: "b", limit: 1, position: 1},
+				{pool: "p", claim: "d", limit: 1, position: 2},
+				{claim: "a", release: true},
+				// d is not admitted before b, which was created earlier
+				{pool: "p", claim: "d", limit: 1, position: 2},
+				{pool: "p", claim: "b", limit: 1, admitted: true},
+				{pool: "p", claim: "d", limit: 1, position: 1},
+			},
+		},
+		{
+			name: "claims created at the same time are ordered by name",
+			steps: []step{
+				{pool: "p", claim: "a", limit: 1, admitted: true},
+				{pool: "p", claim: "d", limit: 1, position: 1},
+				{pool: "p", claim: "c", limit: 1, position: 1},
+				{pool: "p", claim: "d", limit: 1, position: 2},
+			},
+		},
+		{
+			name: "admitted claims are not preempted by a lower limit",
+			steps: []step{
+				{pool: "p", claim: "a", limit: 2, admitted: true},
+				{pool: "p", claim: "b", limit: 2, admitted: true},
+				{pool: "p", claim: "a", limit: 1, admitted: true},
+				{pool: "p", claim: "c", limit: 1, position: 1},
+				{claim: "a", release: true},
+				{pool: "p", claim: "c", limit: 1, position: 1},
+				{claim: "b", release: true},
+				{pool: "p", claim: "c", limit: 1, admitted: true},
+			},
+		},
+		{
+			name: "pools are limited separately",
+			steps: []step{
+				{pool: "p", claim: "a", limit: 1, admitted: true},
+				{pool: "q", claim: "b", limit: 1, admitted: true},
+				{pool: "q", claim: "c", limit: 1, position: 1},
+				// a claim moving to another pool frees its slot
+				{pool: "q", claim: "a", limit: 1, position: 1},
+				{pool: "p", claim: "d", limit: 1, admitted: true},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter := NewClaimLimiter()
+			for i, s := range tt.steps {
+				if s.release {
+					limiter.Release(claims[s.claim])
+					continue
+				}
+				admitted, position := limiter.Admit(s.pool, claims[s.claim], s.limit)
+				assert.Equal(t, s.admitted, admitted, "step %d", i)
+				assert.Equal(t, s.position, position, "step %d", i)
+			}
+			for _, claim := range claims {
+				limiter.Release(claim)
+			}
+			assert.Empty(t, limiter.(*realClaimLimiter).pools)
+			assert.Empty(t, limiter.(*realClaimLimiter).claims)
+		})
+	}
+}
@@ -0,0 +1,196 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+	"github.com/openkruise/agents/pkg/utils/requeue"
+	"github.com/openkruise/agents/pkg/utils/sandboxutils"
+)
+
+// claimQueueAdmission is the outcome of evaluating the claim queue of a pool for one of its queued claims
+type claimQueueAdmission struct {
+	admitted bool
+	// position is the 1-based position of the claim in the queue
+	position int32
+	// demand is the number of sandboxes still needed by the claiming claims, the queued claims ahead and the claim
+	// itself, i.e. to be taken from the pool before the claim is fulfilled
+	demand int32
+}
+
+// EnsureClaimQueued admits a claim in the Queued phase to Claiming once the available sandboxes of the pool cover the
+// claims ahead of it, otherwise reports its position and ETA. The queue is rebuilt from the claims of the pool on every
+// evaluation, so that admissions are neither lost nor reordered by controller restarts.
+func (c *commonControl) EnsureClaimQueued(ctx context.Context, args ClaimArgs) (RequeueStrategy, error) {
+	log := logf.FromContext(ctx)
+	claim, sandboxSet := args.Claim, args.SandboxSet
+	desiredReplicas := getDesiredReplicas(claim)
+
+	if sandboxSet.Spec.ClaimQueue == nil {
+		log.Info("Pool no longer queues claims, admitting claim")
+		admitQueuedClaim(args.NewStatus, "ClaimQueueDisabled", fmt.Sprintf("Pool %s no longer queues claims", sandboxSet.Name))
+		return requeue.Immediately().WithReason("ClaimAdmitted"), nil
+	}
+
+	claims := &agentsv1alpha1.SandboxClaimList{}
+	if err := c.List(ctx, claims, client.InNamespace(claim.Namespace)); err != nil {
+		return requeue.NoRequeue(), fmt.Errorf("failed to list sandboxclaims: %w", err)
+	}
+	available := len(c.listPoolSandboxes(sandboxSet, agentsv1alpha1.SandboxStateAvailable))
+	admission := evaluateClaimQueue(claim, sandboxSet, claims.Items, available)
+	if admission.admitted {
+		message := fmt.Sprintf("Admitted from position %d of the claim queue of pool %s with %d available sandbox(es)",
+			admission.position, sandboxSet.Name, available)
+		log.Info("Claim admitted from claim queue of pool", "position", admission.position, "available", available)
+		c.recorder.Event(claim, "Normal", "ClaimAdmitted", message)
+		admitQueuedClaim(args.NewStatus, "ClaimAdmitted", message)
+		return requeue.Immediately().WithReason("ClaimAdmitted"), nil
+	}
+
+	log.Info("Claim queued by claim queue of pool", "position", admission.position, "available", available, "demand", admission.demand)
+	if args.NewStatus.QueuedPosition == nil {
+		message := fmt.Sprintf("Pool %s has %d available sandbox(es) for %d needed by the claims up to this one, queued at position %d",
+			sandboxSet.Name, available, admission.demand, admission.position)
+		c.recorder.Event(claim, "Normal", "ClaimQueued", message)
+		recordHistory(args.NewStatus, "ClaimQueued", message)
+	}
+	args.NewStatus.QueuedPosition = &admission.position
+	args.NewStatus.ETA = c.estimateClaimETA(sandboxSet, admission.demand-int32(available), time.Now())
+	args.NewStatus.Message = fmt.Sprintf("Queued at position %d: %d/%d claimed", admission.position, args.NewStatus.ClaimedReplicas, desiredReplicas)
+	c.warnClaimTimeoutApproaching(claim, args.NewStatus, time.Now())
+	return requeue.After(ClaimQueueRecheckInterval).WithReason("ClaimQueued"), nil
+}
+
+// admitQueuedClaim moves a queued claim on to Claiming
+func admitQueuedClaim(status *agentsv1alpha1.SandboxClaimStatus, reason, message string) {
+	status.Phase = agentsv1alpha1.SandboxClaimPhaseClaiming
+	status.QueuedPosition = nil
+	status.ETA = nil
+	status.Message = message
+	recordHistory(status, reason, message)
+}
+
+// evaluateClaimQueue evaluates the claim queue of the pool for the queued claim, given the claims in the namespace of
+// the pool and the number of its available sandboxes. The available sandboxes are first left to the remaining replicas
+// of the claiming claims, then handed to the queued claims in order, and a claim is admitted if any is left for it.
+// The head of the queue is always admitted when no claim is claiming, so that claims creating sandboxes on no stock
+// are never stuck on an empty pool.
+func evaluateClaimQueue(claim *agentsv1alpha1.SandboxClaim, sandboxSet *agentsv1alpha1.SandboxSet,
+	claims []agentsv1alpha1.SandboxClaim, available int) claimQueueAdmission {
+	var demand int32
+	claiming := 0
+	queue := []*agentsv1alpha1.SandboxClaim{claim}
+	for i := range claims {
+		other := &claims[i]
+		if other.UID == claim.UID || sandboxutils.ClaimSandboxSetName(other) != sandboxSet.Name {
+			continue
+		}
+		switch other.Status.Phase {
+		case agentsv1alpha1.SandboxClaimPhaseClaiming:
+			claiming++
+			demand += remainingReplicas(other)
+		case agentsv1alpha1.SandboxClaimPhaseQueued:
+			queue = append(queue, other)
+		}
+	}
+	slices.SortFunc(queue, claimQueueOrder(sandboxSet))
+
+	free := int32(available) - demand
+	for i, queued := range queue {
+		demand += remainingReplicas(queued)
+		if queued.UID == claim.UID {
+			return claimQueueAdmission{
+				admitted: free > 0 || (i == 0 && claiming == 0),
+				position: int32(i + 1),
+				demand:   demand,
+			}
+		}
+		free -= remainingReplicas(queued)
+	}
+	return claimQueueAdmission{}
+}
+
+// claimQueueOrder returns the order in which the claim queue of the pool admits claims, falling back to their names
+// for claims created in the same second.
+func claimQueueOrder(sandboxSet *agentsv1alpha1.SandboxSet) func(a, b *agentsv1alpha1.SandboxClaim) int {
+	byPriority := sandboxSet.Spec.ClaimQueue != nil && sandboxSet.Spec.ClaimQueue.Ordering == agentsv1alpha1.ClaimQueueOrderingPriority
+	return func(a, b *agentsv1alpha1.SandboxClaim) int {
+		if byPriority {
+			if diff := cmp.Compare(claimPriority(b), claimPriority(a)); diff != 0 {
+				return diff
+			}
+			if diff := workloadClassRank(ClaimWorkloadClass(a, sandboxSet)) - workloadClassRank(ClaimWorkloadClass(b, sandboxSet)); diff != 0 {
+				return diff
+			}
+		}
+		if !a.CreationTimestamp.Equal(&b.CreationTimestamp) {
+			return a.CreationTimestamp.Compare(b.CreationTimestamp.Time)
+		}
+		return cmp.Compare(a.Name, b.Name)
+	}
+}
+
+// earlierClaimDemand returns the number of sandboxes still needed by the claiming claims of the pool with a claim
+// queue which are ahead of the claim in its order, given the claims in the namespace of the pool, and how many claims
+// need them. Claims of a higher priority are left to higherPriorityDemand and paused claims wait for nothing, so that
+// none of them starves the claim.
+func earlierClaimDemand(claim *agentsv1alpha1.SandboxClaim, sandboxSet *agentsv1alpha1.SandboxSet,
+	claims []agentsv1alpha1.SandboxClaim) (demand int32, competing int) {
+	if sandboxSet.Spec.ClaimQueue == nil {
+		return 0, 0
+	}
+	order := claimQueueOrder(sandboxSet)
+	for i := range claims {
+		other := &claims[i]
+		if other.UID == claim.UID || sandboxutils.ClaimSandboxSetName(other) != sandboxSet.Name || other.Spec.Paused ||
+			other.Status.Phase != agentsv1alpha1.SandboxClaimPhaseClaiming || claimPriority(other) != claimPriority(claim) ||
+			order(other, claim) >= 0 {
+			continue
+		}
+		if remaining := remainingReplicas(other); remaining > 0 {
+			demand += remaining
+			competing++
+		}
+	}
+	return demand, competing
+}
+
+// workloadClassRank ranks Interactive claims first and Batch claims last
+func workloadClassRank(class string) int {
+	switch class {
+	case agentsv1alpha1.WorkloadClassInteractive:
+		return 0
+	case agentsv1alpha1.WorkloadClassBatch:
+		return 2
+	default:
+		return 1
+	}
+}
+
+// remainingReplicas returns the number of replicas the claim has yet to claim
+func remainingReplicas(claim *agentsv1alpha1.SandboxClaim) int32 {
+	return max(getDesiredReplicas(claim)-boundReplicas(&claim.Status), 0)
+}
@@ -0,0 +1,328 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+	"github.com/openkruise/agents/pkg/utils/requeue"
+)
+
+func newQueuedClaim(name, template string, age time.Duration, phase agentsv1alpha1.SandboxClaimPhase, replicas, claimed int32) *agentsv1alpha1.SandboxClaim {
+	return &agentsv1alpha1.SandboxClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         "default",
+			UID:               types.UID(name),
+			CreationTimestamp: metav1.NewTime(time.Now().Add(-age).Truncate(time.Second)),
+		},
+		Spec:   agentsv1alpha1.SandboxClaimSpec{TemplateName: template, Replicas: ptr.To(replicas)},
+		Status: agentsv1alpha1.SandboxClaimStatus{Phase: phase, ClaimedReplicas: claimed},
+	}
+}
+
+func TestEvaluateClaimQueue(t *testing.T) {
+	newSandboxSet := func(ordering agentsv1alpha1.ClaimQueueOrdering) *agentsv1alpha1.SandboxSet {
+		return &agentsv1alpha1.SandboxSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "pool", Namespace: "default"},
+			Spec:       agentsv1alpha1.SandboxSetSpec{ClaimQueue: &agentsv1alpha1.SandboxSetClaimQueue{Ordering: ordering}},
+		}
+	}
+	withClass := func(claim *agentsv1alpha1.SandboxClaim, class string) *agentsv1alpha1.SandboxClaim {
+		claim.Annotations = map[string]string{agentsv1alpha1.AnnotationWorkloadClass: class}
+		return claim
+	}
+	withPriority := func(claim *agentsv1alpha1.SandboxClaim, priority int32) *agentsv1alpha1.SandboxClaim {
+		claim.Spec.Priority = ptr.To(priority)
+		return claim
+	}
+
+	tests := []struct {
+		name       string
+		sandboxSet *agentsv1alpha1.SandboxSet
+		claim      *agentsv1alpha1.SandboxClaim
+		others     []*agentsv1alpha1.SandboxClaim
+		available  int
+		expected   claimQueueAdmission
+	}{
+		{
+			name:       "head of queue admitted on an empty pool without claiming claims",
+			sandboxSet: newSandboxSet(agentsv1alpha1.ClaimQueueOrderingFIFO),
+			claim:      newQueuedClaim("a", "pool", time.Minute, agentsv1alpha1.SandboxClaimPhaseQueued, 2, 0),
+			expected:   claimQueueAdmission{admitted: true, position: 1, demand: 2},
+		},
+		{
+			name:       "head of queue waits for the claiming claims",
+			sandboxSet: newSandboxSet(agentsv1alpha1.ClaimQueueOrderingFIFO),
+			claim:      newQueuedClaim("a", "pool", time.Minute, agentsv1alpha1.SandboxClaimPhaseQueued, 2, 0),
+			others: []*agentsv1alpha1.SandboxClaim{
+				newQueuedClaim("claiming", "pool", 2*time.Minute, agentsv1alpha1.SandboxClaimPhaseClaiming, 5, 2),
+			},
+			available: 3,
+			expected:  claimQueueAdmission{position: 1, demand: 5},
+		},
+		{
+			name:       "sandboxes left by the claiming claims are handed out in order",
+			sandboxSet: newSandboxSet(agentsv1alpha1.ClaimQueueOrderingFIFO),
+			claim:      newQueuedClaim("b", "pool", time.Minute, agentsv1alpha1.SandboxClaimPhaseQueued, 1, 0),
+			others: []*agentsv1alpha1.SandboxClaim{
+				newQueuedClaim("claiming", "pool", 3*time.Minute, agentsv1alpha1.SandboxClaimPhaseClaiming, 2, 0),
+				newQueuedClaim("a", "pool", 2*time.Minute, agentsv1alpha1.SandboxClaimPhaseQueued, 2, 0),
+				newQueuedClaim("c", "pool", 0, agentsv1alpha1.SandboxClaimPhaseQueued, 1, 0),
+			},
+			available: 5,
+			expected:  claimQueueAdmission{admitted: true, position: 2, demand: 5},
+		},
+		{
+			name:       "claims behind a claim not admitted wait",
+			sandboxSet: newSandboxSet(agentsv1alpha1.ClaimQueueOrderingFIFO),
+			claim:      newQueuedClaim("c", "pool", 0, agentsv1alpha1.SandboxClaimPhaseQueued, 1, 0),
+			others: []*agentsv1alpha1.SandboxClaim{
+				newQueuedClaim("a", "pool", 2*time.Minute, agentsv1alpha1.SandboxClaimPhaseQueued, 2, 0),
+				newQueuedClaim("b", "pool", time.Minute, agentsv1alpha1.SandboxClaimPhaseQueued, 3, 0),
+			},
+			available: 4,
+			expected:  claimQueueAdmission{position: 3, demand: 6},
+		},
+		{
+			name:       "claims of other pools and completed claims are ignored",
+			sandboxSet: newSandboxSet(agentsv1alpha1.ClaimQueueOrderingFIFO),
+			claim:      newQueuedClaim("b", "pool", time.Minute, agentsv1alpha1.SandboxClaimPhaseQueued, 1, 0),
+			others: []*agentsv1alpha1.SandboxClaim{
+				newQueuedClaim("other-pool", "other", 2*time.Minute, agentsv1alpha1.SandboxClaimPhaseQueued, 5, 0),
+				newQueuedClaim("other-claiming", "other", 2*time.Minute, agentsv1alpha1.SandboxClaimPhaseClaiming, 5, 0),
+				newQueuedClaim("completed", "pool", 2*time.Minute, agentsv1alpha1.SandboxClaimPhaseCompleted, 5, 0),
+			},
+			available: 1,
+			expected:  claimQueueAdmission{admitted: true, position: 1, demand: 1},
+		},
+		{
+			name:       "claims created in the same second are ordered by name",
+			sandboxSet: newSandboxSet(agentsv1alpha1.ClaimQueueOrderingFIFO),
+			claim:      newQueuedClaim("b", "pool", time.Minute, agentsv1alpha1.SandboxClaimPhaseQueued, 1, 0),
+			others: []*agentsv1alpha1.SandboxClaim{
+				newQueuedClaim("a", "pool", time.Minute, agentsv1alpha1.SandboxClaimPhaseQueued, 1, 0),
+			},
+			available: 1,
+			expected:  claimQueueAdmission{position: 2, demand: 2},
+		},
+		{
+			name:       "priority ordering admits interactive claims first",
+			sandboxSet: newSandboxSet(agentsv1alpha1.ClaimQueueOrderingPriority),
+			claim:      withClass(newQueuedClaim("interactive", "pool", 0, agentsv1alpha1.SandboxClaimPhaseQueued, 1, 0), agentsv1alpha1.WorkloadClassInteractive),
+			others: []*agentsv1alpha1.SandboxClaim{
+				withClass(newQueuedClaim("batch", "pool", 2*time.Minute, agentsv1alpha1.SandboxClaimPhaseQueued, 1, 0), agentsv1alpha1.WorkloadClassBatch),
+				newQueuedClaim("normal", "pool", time.Minute, agentsv1alpha1.SandboxClaimPhaseQueued, 1, 0),
+			},
+			available: 1,
+			expected:  claimQueueAdmission{admitted: true, position: 1, demand: 1},
+		},
+		{
+			name:       "priority ordering admits batch claims last",
+			sandboxSet: newSandboxSet(agentsv1alpha1.ClaimQueueOrderingPriority),
+			claim:      withClass(newQueuedClaim("batch", "pool", 2*time.Minute, agentsv1alpha1.SandboxClaimPhaseQueued, 1, 0), agentsv1alpha1.WorkloadClassBatch),
+			others: []*agentsv1alpha1.SandboxClaim{
+				newQueuedClaim("normal", "pool", time.Minute, agentsv1alpha1.SandboxClaimPhaseQueued, 1, 0),
+			},
+			available: 1,
+			expected:  claimQueueAdmission{position: 2, demand: 2},
+		},
+		{
+			name:       "priority ordering admits claims of higher priority before interactive claims",
+			sandboxSet: newSandboxSet(agentsv1alpha1.ClaimQueueOrderingPriority),
+			claim:      withPriority(withClass(newQueuedClaim("batch", "pool", 0, agentsv1alpha1.SandboxClaimPhaseQueued, 1, 0), agentsv1alpha1.WorkloadClassBatch), 10),
+			others: []*agentsv1alpha1.SandboxClaim{
+				withClass(newQueuedClaim("interactive", "pool", 2*time.Minute, agentsv1alpha1.SandboxClaimPhaseQueued, 1, 0), agentsv1alpha1.WorkloadClassInteractive),
+			},
+			available: 1,
+			expected:  claimQueueAdmission{admitted: true, position: 1, demand: 1},
+		},
+		{
+			name:       "fifo ordering ignores priorities",
+			sandboxSet: newSandboxSet(agentsv1alpha1.ClaimQueueOrderingFIFO),
+			claim:      withPriority(newQueuedClaim("b", "pool", 0, agentsv1alpha1.SandboxClaimPhaseQueued, 1, 0), 10),
+			others: []*agentsv1alpha1.SandboxClaim{
+				newQueuedClaim("a", "pool", time.Minute, agentsv1alpha1.SandboxClaimPhaseQueued, 1, 0),
+			},
+			available: 1,
+			expected:  claimQueueAdmission{position: 2, demand: 2},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := make([]agentsv1alpha1.SandboxClaim, 0, len(tt.others))
+			for _, other := range tt.others {
+				claims = append(claims, *other)
+			}
+			assert.Equal(t, tt.expected, evaluateClaimQueue(tt.claim, tt.sandboxSet, claims, tt.available))
+		})
+	}
+}
+
+func TestEarlierClaimDemand(t *testing.T) {
+	queued := &agentsv1alpha1.SandboxSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "pool", Namespace: "default"},
+		Spec:       agentsv1alpha1.SandboxSetSpec{ClaimQueue: &agentsv1alpha1.SandboxSetClaimQueue{Ordering: agentsv1alpha1.ClaimQueueOrderingFIFO}},
+	}
+	paused := newQueuedClaim("paused", "pool", 3*time.Minute, agentsv1alpha1.SandboxClaimPhaseClaiming, 2, 0)
+	paused.Spec.Paused = true
+	higher := newQueuedClaim("higher", "pool", 3*time.Minute, agentsv1alpha1.SandboxClaimPhaseClaiming, 2, 0)
+	higher.Spec.Priority = ptr.To[int32](10)
+
+	tests := []struct {
+		name            string
+		sandboxSet      *agentsv1alpha1.SandboxSet
+		others          []*agentsv1alpha1.SandboxClaim
+		expected        int32
+		expectCompeting int
+	}{
+		{
+			name:       "remaining replicas of earlier claiming claims",
+			sandboxSet: queued,
+			others: []*agentsv1alpha1.SandboxClaim{
+				newQueuedClaim("earlier", "pool", 3*time.Minute, agentsv1alpha1.SandboxClaimPhaseClaiming, 3, 1),
+				newQueuedClaim("fulfilled", "pool", 3*time.Minute, agentsv1alpha1.SandboxClaimPhaseClaiming, 2, 2),
+				newQueuedClaim("later", "pool", time.Second, agentsv1alpha1.SandboxClaimPhaseClaiming, 2, 0),
+				newQueuedClaim("queued", "pool", 3*time.Minute, agentsv1alpha1.SandboxClaimPhaseQueued, 2, 0),
+				newQueuedClaim("other-pool", "other", 3*time.Minute, agentsv1alpha1.SandboxClaimPhaseClaiming, 2, 0),
+			},
+			expected:        2,
+			expectCompeting: 1,
+		},
+		{
+			name:       "paused and higher priority claims are ignored",
+			sandboxSet: queued,
+			others:     []*agentsv1alpha1.SandboxClaim{paused, higher},
+		},
+		{
+			name:       "pool without claim queue",
+			sandboxSet: &agentsv1alpha1.SandboxSet{ObjectMeta: metav1.ObjectMeta{Name: "pool", Namespace: "default"}},
+			others: []*agentsv1alpha1.SandboxClaim{
+				newQueuedClaim("earlier", "pool", 3*time.Minute, agentsv1alpha1.SandboxClaimPhaseClaiming, 3, 1),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claim := newQueuedClaim("claim", "pool", time.Minute, agentsv1alpha1.SandboxClaimPhaseClaiming, 2, 0)
+			claims := []agentsv1alpha1.SandboxClaim{*claim}
+			for _, other := range tt.others {
+				claims = append(claims, *other)
+			}
+			demand, competing := earlierClaimDemand(claim, tt.sandboxSet, claims)
+			assert.Equal(t, tt.expected, demand)
+			assert.Equal(t, tt.expectCompeting, competing)
+		})
+	}
+}
+
+func TestCommonControl_EnsureClaimQueued(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, agentsv1alpha1.AddToScheme(scheme))
+	newSandboxSet := func(queue bool) *agentsv1alpha1.SandboxSet {
+		sbs := &agentsv1alpha1.SandboxSet{ObjectMeta: metav1.ObjectMeta{Name: "pool", Namespace: "default"}}
+		if queue {
+			sbs.Spec.ClaimQueue = &agentsv1alpha1.SandboxSetClaimQueue{Ordering: agentsv1alpha1.ClaimQueueOrderingFIFO}
+		}
+		return sbs
+	}
+
+	tests := []struct {
+		name             string
+		sandboxSet       *agentsv1alpha1.SandboxSet
+		others           []client.Object
+		queuedPosition   *int32
+		expectedPhase    agentsv1alpha1.SandboxClaimPhase
+		expectedPosition *int32
+		expectedStrategy RequeueStrategy
+		expectedHistory  string
+	}{
+		{
+			name:             "admitted once the pool no longer queues claims",
+			sandboxSet:       newSandboxSet(false),
+			others:           []client.Object{newQueuedClaim("claiming", "pool", time.Hour, agentsv1alpha1.SandboxClaimPhaseClaiming, 1, 0)},
+			queuedPosition:   ptr.To[int32](1),
+			expectedPhase:    agentsv1alpha1.SandboxClaimPhaseClaiming,
+			expectedStrategy: requeue.Immediately(),
+			expectedHistory:  "ClaimQueueDisabled",
+		},
+		{
+			name:             "head of queue admitted",
+			sandboxSet:       newSandboxSet(true),
+			queuedPosition:   ptr.To[int32](1),
+			expectedPhase:    agentsv1alpha1.SandboxClaimPhaseClaiming,
+			expectedStrategy: requeue.Immediately(),
+			expectedHistory:  "ClaimAdmitted",
+		},
+		{
+			name:             "queued behind a claiming claim",
+			sandboxSet:       newSandboxSet(true),
+			others:           []client.Object{newQueuedClaim("claiming", "pool", time.Hour, agentsv1alpha1.SandboxClaimPhaseClaiming, 1, 0)},
+			expectedPhase:    agentsv1alpha1.SandboxClaimPhaseQueued,
+			expectedPosition: ptr.To[int32](1),
+			expectedStrategy: requeue.After(ClaimQueueRecheckInterval),
+			expectedHistory:  "ClaimQueued",
+		},
+		{
+			name:       "position updated without recording history again",
+			sandboxSet: newSandboxSet(true),
+			others: []client.Object{
+				newQueuedClaim("claiming", "pool", time.Hour, agentsv1alpha1.SandboxClaimPhaseClaiming, 1, 0),
+				newQueuedClaim("ahead", "pool", 2*time.Hour, agentsv1alpha1.SandboxClaimPhaseQueued, 1, 0),
+			},
+			queuedPosition:   ptr.To[int32](1),
+			expectedPhase:    agentsv1alpha1.SandboxClaimPhaseQueued,
+			expectedPosition: ptr.To[int32](2),
+			expectedStrategy: requeue.After(ClaimQueueRecheckInterval),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claim := newQueuedClaim("claim", "pool", time.Minute, agentsv1alpha1.SandboxClaimPhaseQueued, 1, 0)
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(append(tt.others, claim, tt.sandboxSet)...).Build()
+			control := NewCommonControl(fakeClient, record.NewFakeRecorder(10), nil, nil)
+			newStatus := claim.Status.DeepCopy()
+			newStatus.QueuedPosition = tt.queuedPosition
+
+			strategy, err := control.EnsureClaimQueued(context.Background(), ClaimArgs{Claim: claim, SandboxSet: tt.sandboxSet, NewStatus: newStatus})
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedStrategy.Kind(), strategy.Kind())
+			assert.Equal(t, tt.expectedStrategy.After, strategy.After)
+			assert.Equal(t, tt.expectedPhase, newStatus.Phase)
+			assert.Equal(t, tt.expectedPosition, newStatus.QueuedPosition)
+			if tt.expectedHistory == "" {
+				assert.Empty(t, newStatus.History)
+			} else {
+				require.Len(t, newStatus.History, 1)
+				assert.Equal(t, tt.expectedHistory, newStatus.History[0].Reason)
+			}
+		})
+	}
+}
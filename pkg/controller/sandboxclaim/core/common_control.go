@@ -20,25 +20,38 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/http"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
 	"golang.org/x/time/rate"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/retry"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 
 	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
 	"github.com/openkruise/agents/pkg/agent-runtime/storages"
+	"github.com/openkruise/agents/pkg/controller/sandboxclaim/archive"
 	"github.com/openkruise/agents/pkg/sandbox-manager/clients"
 	"github.com/openkruise/agents/pkg/sandbox-manager/config"
 	"github.com/openkruise/agents/pkg/sandbox-manager/infra"
 	"github.com/openkruise/agents/pkg/sandbox-manager/infra/sandboxcr"
 	"github.com/openkruise/agents/pkg/utils"
+	"github.com/openkruise/agents/pkg/utils/conditions"
 	"github.com/openkruise/agents/pkg/utils/csiutils"
-	stateutils "github.com/openkruise/agents/pkg/utils/sandboxutils"
+	"github.com/openkruise/agents/pkg/utils/fieldindex"
+	"github.com/openkruise/agents/pkg/utils/requeue"
+	"github.com/openkruise/agents/pkg/utils/sandboxstate"
 )
 
 type commonControl struct {
@@ -48,6 +61,8 @@ type commonControl struct {
 	cache           *sandboxcr.Cache
 	storageRegistry storages.VolumeMountProviderRegistry
 	pickCache       sync.Map
+	archiver        archive.Sink
+	notifier        *http.Client
 }
 
 func NewCommonControl(c client.Client, recorder record.EventRecorder, sandboxClient *clients.ClientSet, cache *sandboxcr.Cache) ClaimControl {
@@ -61,6 +76,8 @@ func NewCommonControl(c client.Client, recorder record.EventRecorder, sandboxCli
 		cache:           cache,
 		storageRegistry: storages.NewStorageProvider(),
 		pickCache:       sync.Map{},
+		archiver:        ClaimArchiver,
+		notifier:        ClaimNotifier,
 	}
 
 	return control
@@ -70,12 +87,13 @@ func NewCommonControl(c client.Client, recorder record.EventRecorder, sandboxCli
 func (c *commonControl) EnsureClaimClaiming(ctx context.Context, args ClaimArgs) (RequeueStrategy, error) {
 	log := logf.FromContext(ctx)
 	claim, sandboxSet := args.Claim, args.SandboxSet
+	defer c.syncClaimedSandboxes(ctx, claim, args.NewStatus)
 
 	// Step 1: Get desired replicas
 	desiredReplicas := getDesiredReplicas(claim)
 
-	// Step 2: Get current count from status
-	statusCount := claim.Status.ClaimedReplicas
+	// Step 2: Get current count from status, including the sandboxes not Ready for minReadySeconds yet
+	statusCount := boundReplicas(&claim.Status)
 
 	// Step 3: Recovery logic - query actual count to prevent loss
 	// This handles edge cases:
@@ -88,9 +106,14 @@ func (c *commonControl) EnsureClaimClaiming(ctx context.Context, args ClaimArgs)
 	//   4. Controller restarts
 	//   Then the controller will create new sandboxes to reach the desired replicas,
 	//   even though the user intentionally deleted them, it's an extremely rare case.
+	// The sandboxes whose claim was only half applied are repaired before they are counted.
+	adopted, err := c.adoptClaimedSandboxes(ctx, claim)
+	if err != nil {
+		return requeue.NoRequeue(), fmt.Errorf("failed to adopt claimed sandboxes: %w", err)
+	}
 	actualCount, err := c.countClaimedSandboxes(ctx, claim)
 	if err != nil {
-		return NoRequeue(), fmt.Errorf("failed to count claimed sandboxes: %w", err)
+		return requeue.NoRequeue(), fmt.Errorf("failed to count claimed sandboxes: %w", err)
 	}
 
 	// Step 4: Use max(statusCount, actualCount) to get current count
@@ -101,12 +124,41 @@ func (c *commonControl) EnsureClaimClaiming(ctx context.Context, args ClaimArgs)
 			"actualCount", actualCount)
 		currentCount = actualCount
 	}
+	if adopted > 0 {
+		message := fmt.Sprintf("Adopted %d half claimed sandbox(es), %d claimed in total", adopted, currentCount)
+		c.recorder.Event(claim, "Normal", "SandboxesAdopted", message)
+		recordHistory(args.NewStatus, "SandboxesAdopted", message)
+	}
+	if claim.Spec.ClaimGroup != nil {
+		// the replicas claimed by the group count until the cache observes them
+		currentCount = max(currentCount, ClaimGroups.Bound(claim, actualCount))
+	}
 
-	// Step 5: Update status with current count
-	args.NewStatus.ClaimedReplicas = currentCount
+	// Step 5: Update status with current count, the ETA is only kept while waiting for sandboxes being created.
+	// With minReadySeconds, the bound sandboxes are only counted as claimed once they have been Ready for it.
+	minReady, minReadyWait := currentCount, time.Duration(0)
+	if claim.Spec.MinReadySeconds > 0 {
+		minReady, minReadyWait, err = c.countMinReadySandboxes(claim, time.Now())
+		if err != nil {
+			return requeue.NoRequeue(), fmt.Errorf("failed to count ready claimed sandboxes: %w", err)
+		}
+	}
+	recordClaimedReplicas(claim, args.NewStatus, currentCount, minReady)
+	args.NewStatus.ETA = nil
 
 	// Step 6: Check if already completed
 	if currentCount >= desiredReplicas {
+		if claimed := args.NewStatus.ClaimedReplicas; claimed < currentCount {
+			log.Info("All replicas bound, waiting for the sandboxes to be ready for minReadySeconds",
+				"bound", currentCount, "claimed", claimed, "minReadySeconds", claim.Spec.MinReadySeconds)
+			args.NewStatus.Message = fmt.Sprintf("Waiting for %d sandbox(es) to be ready for %ds: %d/%d claimed",
+				currentCount-claimed, claim.Spec.MinReadySeconds, claimed, desiredReplicas)
+			if minReadyWait <= 0 {
+				// the sandboxes not ready yet are reconciled on becoming ready
+				minReadyWait = ClaimRetryInterval
+			}
+			return requeue.After(minReadyWait).WithReason("WaitingForMinReady"), nil
+		}
 		log.Info("All replicas claimed",
 			"claimed", currentCount,
 			"desired", desiredReplicas)
@@ -114,60 +166,353 @@ func (c *commonControl) EnsureClaimClaiming(ctx context.Context, args ClaimArgs)
 			fmt.Sprintf("Successfully claimed %d/%d sandboxes", currentCount, desiredReplicas))
 		args.NewStatus.Message = fmt.Sprintf("Completed: %d/%d claimed", currentCount, desiredReplicas)
 		// Requeue immediately to transition to Completed phase
-		return RequeueImmediately(), nil
+		return requeue.Immediately().WithReason("AllReplicasClaimed"), nil
 	}
 
-	// Step 7: Calculate batch size
+	// Step 7: Wait in the claim queue of the pool if it limits concurrent claims
+	if sandboxSet.Spec.MaxConcurrentClaims != nil {
+		admitted, position := ClaimConcurrencyLimiter.Admit(client.ObjectKeyFromObject(sandboxSet).String(), claim, *sandboxSet.Spec.MaxConcurrentClaims)
+		if !admitted {
+			log.Info("Claim queued by concurrent claim limit of pool",
+				"position", position,
+				"maxConcurrentClaims", *sandboxSet.Spec.MaxConcurrentClaims)
+			if args.NewStatus.QueuedPosition == nil {
+				message := fmt.Sprintf("Pool %s reached its limit of %d concurrent claims, queued at position %d",
+					sandboxSet.Name, *sandboxSet.Spec.MaxConcurrentClaims, position)
+				c.recorder.Event(claim, "Normal", "ClaimQueued", message)
+				recordHistory(args.NewStatus, "ClaimQueued", message)
+			}
+			args.NewStatus.QueuedPosition = &position
+			args.NewStatus.Message = fmt.Sprintf("Queued at position %d: %d/%d claimed", position, currentCount, desiredReplicas)
+			return requeue.After(ClaimRetryInterval).WithReason("ClaimQueued"), nil
+		}
+	} else {
+		ClaimConcurrencyLimiter.Release(claim)
+	}
+	args.NewStatus.QueuedPosition = nil
+
+	// Stop claiming while the namespace reached its SandboxQuota, all-or-nothing claims until it allows all the
+	// remaining replicas
+	quota, err := c.getNamespaceQuota(ctx, claim, currentCount)
+	if err != nil {
+		return requeue.NoRequeue(), err
+	}
+	needed := int32(1)
+	if fulfillmentPolicy(claim) == agentsv1alpha1.SandboxClaimFulfillmentAllOrNothing {
+		needed = desiredReplicas - currentCount
+	}
+	if quota != nil && quota.headroom() < needed {
+		log.Info("Namespace reached its SandboxQuota, will retry", "quota", quota.name,
+			"claimed", quota.claimed, "maxClaimedReplicas", quota.limit, "retryInterval", ClaimRetryInterval)
+		c.exceedQuota(claim, args.NewStatus, quota, needed, currentCount, desiredReplicas, time.Now())
+		c.warnClaimTimeoutApproaching(claim, args.NewStatus, time.Now())
+		return requeue.After(ClaimRetryInterval).WithReason("QuotaExceeded"), nil
+	}
+	withinQuota(args.NewStatus, quota, time.Now())
+
+	// Claims of a group are claimed all at once by the group
+	if claim.Spec.ClaimGroup != nil {
+		return c.ensureClaimGroupClaiming(ctx, args, currentCount, desiredReplicas, quota)
+	}
+
+	// Step 8: Calculate batch size, all-or-nothing claims claim all the remaining replicas in a single batch
 	remaining := desiredReplicas - currentCount
 	batchSize := min(int(remaining), MaxClaimBatchSize)
+	allOrNothing := fulfillmentPolicy(claim) == agentsv1alpha1.SandboxClaimFulfillmentAllOrNothing
+	if allOrNothing {
+		batchSize = int(remaining)
+	}
+	if quota != nil {
+		batchSize = min(batchSize, int(quota.headroom()))
+	}
 
-	// Step 8: Perform claim
-	claimed, err := c.claimSandboxes(ctx, claim, sandboxSet, batchSize)
+	// Step 9: Leave the sandboxes reserved for interactive claims to them
+	class := ClaimWorkloadClass(claim, sandboxSet)
+	if sandboxSet.Spec.InteractiveReserve != nil {
+		available := len(c.listPoolSandboxes(sandboxSet, agentsv1alpha1.SandboxStateAvailable))
+		allowed, reserved, limited := fairShareAllowance(sandboxSet, class, available)
+		if limited && allowed <= 0 && available > 0 {
+			log.Info("Available sandboxes are reserved for interactive claims, will retry",
+				"available", available, "reserved", reserved, "retryInterval", ClaimRetryInterval)
+			classThrottledTotal.WithLabelValues(sandboxSet.Namespace, sandboxSet.Name, workloadClassLabel(class)).Inc()
+			message := fmt.Sprintf("All %d available sandbox(es) of pool %s are reserved for interactive claims", available, sandboxSet.Name)
+			c.recorder.Event(claim, "Normal", "ReservedForInteractive", message)
+			recordHistory(args.NewStatus, "ReservedForInteractive", message)
+			args.NewStatus.Message = fmt.Sprintf("Waiting for sandboxes beyond the %d reserved for interactive claims: %d/%d claimed",
+				reserved, currentCount, desiredReplicas)
+			return requeue.After(ClaimRetryInterval).WithReason("ReservedForInteractive"), nil
+		}
+		if limited && allowed > 0 {
+			batchSize = min(batchSize, allowed)
+		}
+	}
+
+	// Step 10: Leave the available sandboxes to the claiming claims of a higher priority, then to the ones ahead of
+	// the claim in the claim queue of the pool, so that earlier claims are fulfilled first. The claims of the pool are
+	// only read, so they are listed from the cache without copying
+	claims := &agentsv1alpha1.SandboxClaimList{}
+	if err := c.List(ctx, claims, client.InNamespace(claim.Namespace),
+		client.MatchingFields{fieldindex.IndexNameForSandboxSetName: sandboxSet.Name}, client.UnsafeDisableDeepCopy); err != nil {
+		return requeue.NoRequeue(), fmt.Errorf("failed to list sandboxclaims: %w", err)
+	}
+	demand, competing := higherPriorityDemand(claim, sandboxSet, claims.Items)
+	earlier, earlierCompeting := earlierClaimDemand(claim, sandboxSet, claims.Items)
+	if demand+earlier > 0 {
+		available := len(c.listPoolSandboxes(sandboxSet, agentsv1alpha1.SandboxStateAvailable))
+		if allowed := available - int(demand+earlier); allowed > 0 {
+			batchSize = min(batchSize, allowed)
+		} else if available <= int(demand) {
+			log.Info("Available sandboxes are left to claims of higher priority, will retry",
+				"available", available, "demand", demand, "claims", competing, "retryInterval", ClaimRetryInterval)
+			message := fmt.Sprintf("%d claim(s) of higher priority need %d sandbox(es) of pool %s with %d available",
+				competing, demand, sandboxSet.Name, available)
+			c.recorder.Event(claim, "Normal", "WaitingForHigherPriority", message)
+			recordHistory(args.NewStatus, "WaitingForHigherPriority", message)
+			args.NewStatus.Message = fmt.Sprintf("Waiting for claims of higher priority: %d/%d claimed", currentCount, desiredReplicas)
+			c.warnClaimTimeoutApproaching(claim, args.NewStatus, time.Now())
+			return requeue.After(ClaimRetryInterval).WithReason("WaitingForHigherPriority"), nil
+		} else {
+			log.Info("Available sandboxes are left to earlier claims, will retry",
+				"available", available, "demand", demand+earlier, "claims", earlierCompeting, "retryInterval", ClaimRetryInterval)
+			message := fmt.Sprintf("%d claim(s) ahead in the claim queue of pool %s need %d sandbox(es) with %d available",
+				earlierCompeting, sandboxSet.Name, earlier, available-int(demand))
+			c.recorder.Event(claim, "Normal", "WaitingForEarlierClaims", message)
+			recordHistory(args.NewStatus, "WaitingForEarlierClaims", message)
+			args.NewStatus.Message = fmt.Sprintf("Waiting for earlier claims: %d/%d claimed", currentCount, desiredReplicas)
+			c.warnClaimTimeoutApproaching(claim, args.NewStatus, time.Now())
+			return requeue.After(ClaimRetryInterval).WithReason("WaitingForEarlierClaims"), nil
+		}
+	}
+
+	// Step 11: Wait until all the remaining replicas of an all-or-nothing claim can be claimed at once
+	if allOrNothing {
+		claimable, err := c.countClaimableSandboxes(claim, sandboxSet)
+		if err != nil {
+			return requeue.NoRequeue(), fmt.Errorf("failed to count claimable sandboxes: %w", err)
+		}
+		if claimable = min(claimable, batchSize); claimable < int(remaining) {
+			log.Info("Not enough sandboxes to claim all remaining replicas at once, will retry",
+				"claimable", claimable, "remaining", remaining, "retryInterval", ClaimRetryInterval)
+			message := fmt.Sprintf("Pool %s has %d claimable sandbox(es) for %d replicas to claim all at once",
+				sandboxSet.Name, claimable, remaining)
+			c.recorder.Event(claim, "Normal", "WaitingForAllReplicas", message)
+			recordHistory(args.NewStatus, "WaitingForAllReplicas", message)
+			args.NewStatus.Message = fmt.Sprintf("Waiting for %d sandbox(es) to claim all at once: %d/%d claimed",
+				remaining, currentCount, desiredReplicas)
+			args.NewStatus.ETA = c.estimateClaimETA(sandboxSet, remaining-int32(claimable), time.Now())
+			c.warnClaimTimeoutApproaching(claim, args.NewStatus, time.Now())
+			return requeue.After(ClaimRetryInterval).WithReason("WaitingForAllReplicas"), nil
+		}
+	}
+
+	// Step 12: Perform claim, releasing the sandboxes bound by an incomplete all-or-nothing batch
+	bound, replicaTimedOut, err := c.claimSandboxes(ctx, claim, sandboxSet, batchSize, currentCount, desiredReplicas)
 	if err != nil {
 		log.Error(err, "Claim attempts completed with errors",
-			"claimed", claimed, "attempted", batchSize)
+			"claimed", len(bound), "attempted", batchSize)
+	}
+	if allOrNothing && len(bound) < batchSize {
+		released, rollbackErr := c.rollbackBoundSandboxes(ctx, bound)
+		message := fmt.Sprintf("Released %d sandbox(es) bound before claiming all %d replicas at once failed", released, batchSize)
+		c.recorder.Event(claim, "Warning", "AllOrNothingRolledBack", message)
+		recordHistory(args.NewStatus, "AllOrNothingRolledBack", message)
+		if rollbackErr != nil {
+			return requeue.NoRequeue(), fmt.Errorf("failed to release sandboxes of incomplete all-or-nothing batch: %w", rollbackErr)
+		}
+		bound = nil
+		if !replicaTimedOut {
+			args.NewStatus.Message = fmt.Sprintf("Failed to claim all %d sandbox(es) at once, will retry: %d/%d claimed",
+				remaining, currentCount, desiredReplicas)
+			c.warnClaimTimeoutApproaching(claim, args.NewStatus, time.Now())
+			return requeue.After(ClaimRetryInterval).WithReason("AllOrNothingRolledBack"), nil
+		}
+	}
+	claimed := len(bound)
+	if claimed > 0 {
+		classClaimedSandboxesTotal.WithLabelValues(sandboxSet.Namespace, sandboxSet.Name, workloadClassLabel(class)).Add(float64(claimed))
 	}
 
-	// Step 9: Update final count and status
+	// Step 13: Update final count and status
 	finalCount := currentCount + int32(claimed)
-	args.NewStatus.ClaimedReplicas = finalCount
+	recordClaimedReplicas(claim, args.NewStatus, finalCount, args.NewStatus.ClaimedReplicas)
 	args.NewStatus.Message = fmt.Sprintf("Claiming sandboxes: %d/%d claimed", finalCount, desiredReplicas)
+	if replicaTimedOut && timeoutPolicy(claim) == agentsv1alpha1.SandboxClaimTimeoutBestEffort {
+		// Best-effort claims go on claiming, only backing off from the stalled binds
+		timeout := claim.Spec.PerReplicaTimeout.Duration
+		if !IsDowngradedToBestEffort(args.NewStatus) {
+			log.Info("Binding a sandbox exceeded the per-replica timeout, downgrading to best-effort",
+				"perReplicaTimeout", timeout, "claimed", finalCount, "desired", desiredReplicas)
+			c.recorder.Event(claim, "Warning", "DowngradedToBestEffort",
+				fmt.Sprintf("Binding a sandbox exceeded the per-replica timeout of %v, claimed %d/%d", timeout, finalCount, desiredReplicas))
+			observeClaimTimedOut(claim, args.SandboxSet, args.NewStatus)
+			downgradeToBestEffortWithReplicaTimeout(args.NewStatus, timeout, claim)
+		}
+		return requeue.After(ClaimRetryInterval).WithReason("ReplicaTimeoutReached"), nil
+	}
+	if replicaTimedOut {
+		// Fail fast instead of retrying binds that stall until the claim timeout
+		timeout := claim.Spec.PerReplicaTimeout.Duration
+		log.Info("Binding a sandbox exceeded the per-replica timeout, transitioning to "+string(timedOutPhase(claim)),
+			"perReplicaTimeout", timeout, "claimed", finalCount, "desired", desiredReplicas)
+		c.recorder.Event(claim, "Warning", "ReplicaTimeoutReached",
+			fmt.Sprintf("Binding a sandbox exceeded the per-replica timeout of %v, claimed %d/%d", timeout, finalCount, desiredReplicas))
+		observeClaimTimedOut(claim, args.SandboxSet, args.NewStatus)
+		transitionToCompletedWithReplicaTimeout(args.NewStatus, timeout, claim)
+		return requeue.Immediately().WithReason("ReplicaTimeoutReached"), nil
+	}
 
-	// Step 10: Record results and determine requeue strategy
+	// Step 14: Record results and determine requeue strategy
 	if claimed > 0 {
 		log.Info("Claimed sandboxes in this cycle",
 			"claimed", claimed,
 			"total", finalCount,
 			"desired", desiredReplicas)
-		c.recorder.Event(claim, "Normal", "SandboxClaimed",
-			fmt.Sprintf("Claimed %d sandbox(es), total: %d/%d", claimed, finalCount, desiredReplicas))
+		// Every bound sandbox has been reported by an event already
+		message := fmt.Sprintf("Claimed %d sandbox(es), total: %d/%d", claimed, finalCount, desiredReplicas)
+		recordHistory(args.NewStatus, "SandboxClaimed", message)
 		// Made progress, requeue immediately to continue claiming
-		return RequeueImmediately(), nil
+		return requeue.Immediately().WithReason("ClaimProgress"), nil
+	}
+
+	// No progress - preempt claims of a lower priority for the sandboxes that are not being created
+	if preemptionPolicy(claim) == agentsv1alpha1.SandboxClaimPreemptLowerPriority {
+		creating := len(c.listPoolSandboxes(sandboxSet, agentsv1alpha1.SandboxStateCreating))
+		if needed := int(desiredReplicas-finalCount) - creating; needed > 0 {
+			preempted, err := c.preemptLowerPriority(ctx, claim, sandboxSet, claims.Items, needed)
+			if err != nil {
+				return requeue.NoRequeue(), fmt.Errorf("failed to preempt claims of lower priority: %w", err)
+			}
+			if preempted > 0 {
+				log.Info("Preempted claims of lower priority, will retry",
+					"preempted", preempted, "needed", needed, "retryInterval", ClaimRetryInterval)
+				message := fmt.Sprintf("Preempted %d sandbox(es) of claims of lower priority for %d needed", preempted, needed)
+				recordHistory(args.NewStatus, "PreemptedLowerPriority", message)
+				args.NewStatus.Message = fmt.Sprintf("Waiting for the capacity of %d preempted sandbox(es): %d/%d claimed",
+					preempted, finalCount, desiredReplicas)
+				c.warnClaimTimeoutApproaching(claim, args.NewStatus, time.Now())
+				return requeue.After(ClaimRetryInterval).WithReason("PreemptedLowerPriority"), nil
+			}
+		}
 	}
 
 	// No progress - no available sandboxes
 	log.Info("No available sandboxes, will retry",
 		"retryInterval", ClaimRetryInterval)
-	c.recorder.Event(claim, "Warning", "NoAvailableSandboxes",
-		fmt.Sprintf("No available sandboxes in pool %s", sandboxSet.Name))
+	message := fmt.Sprintf("Pool %s exhausted, %d more needed", sandboxSet.Name, desiredReplicas-finalCount)
+	if eta := c.estimateClaimETA(sandboxSet, desiredReplicas-finalCount, time.Now()); eta != nil {
+		log.Info("Waiting for sandboxes being created", "eta", eta.EstimatedTime, "creating", eta.CreatingReplicas)
+		args.NewStatus.ETA = eta
+		args.NewStatus.Message = fmt.Sprintf("Waiting for %d sandbox(es) being created, estimated ready at %s: %d/%d claimed",
+			eta.CreatingReplicas, eta.EstimatedTime.UTC().Format(time.RFC3339), finalCount, desiredReplicas)
+	}
+	c.recorder.Event(claim, "Warning", "NoAvailableSandboxes", message)
+	recordHistory(args.NewStatus, "NoAvailableSandboxes", message)
+	c.warnClaimTimeoutApproaching(claim, args.NewStatus, time.Now())
 	// Retry after interval to avoid busy loop
-	return RequeueAfter(ClaimRetryInterval), nil
+	return requeue.After(ClaimRetryInterval).WithReason("NoAvailableSandboxes"), nil
 }
 
 // EnsureClaimCompleted handles claim in Completed phase
 func (c *commonControl) EnsureClaimCompleted(ctx context.Context, args ClaimArgs) (RequeueStrategy, error) {
 	log := logf.FromContext(ctx)
 	claim := args.Claim
+	defer c.syncClaimedSandboxes(ctx, claim, args.NewStatus)
 
 	log.V(1).Info("EnsureClaimCompleted called", "phase", args.NewStatus.Phase)
 
+	// Free the slot of the claim in the claim queue of the pool
+	ClaimConcurrencyLimiter.Release(claim)
+	args.NewStatus.QueuedPosition = nil
+	args.NewStatus.ETA = nil
+
+	// Release the claimed sandboxes of a claim whose lease expired, or partially claimed ones of a timed out or
+	// canceled claim
+	args.NewStatus.LeaseExpireTime = leaseExpireTime(claim, args.NewStatus, time.Now())
+	if reason := claimReleaseReason(claim, args.NewStatus); reason != "" {
+		released, err := c.releaseClaimedSandboxes(ctx, claim)
+		if err != nil {
+			return requeue.NoRequeue(), fmt.Errorf("failed to release claimed sandboxes: %w", err)
+		}
+		eventReason, cause := releaseEvent(reason)
+		log.Info("Released claimed sandboxes", "reason", reason, "released", released)
+		c.recorder.Event(claim, "Normal", eventReason, fmt.Sprintf("Released %d claimed sandbox(es) %s", released, cause))
+		markClaimReleased(args.NewStatus, released, reason)
+	}
+
+	// Follow the replicas changed after completion, unless the claim is canceled
+	if !isClaimShutdown(claim) && !isClaimCanceled(claim) &&
+		!conditions.IsTrue(args.NewStatus.Conditions, string(agentsv1alpha1.SandboxClaimConditionReleased)) {
+		desiredReplicas := getDesiredReplicas(claim)
+		if args.NewStatus.ClaimedReplicas > desiredReplicas && releasePolicy(claim) != agentsv1alpha1.SandboxClaimRetain {
+			released, err := c.releaseExcessReplicas(ctx, claim)
+			if err != nil {
+				return requeue.NoRequeue(), fmt.Errorf("failed to release claimed sandboxes beyond replicas: %w", err)
+			}
+			if released > 0 {
+				log.Info("Released claimed sandboxes beyond decreased replicas", "released", released, "desired", desiredReplicas)
+				c.recorder.Event(claim, "Normal", "ReleasedOnScaleDown",
+					fmt.Sprintf("Released %d claimed sandbox(es) beyond %d replicas with policy %s", released, desiredReplicas, releasePolicy(claim)))
+				markReleasedOnScaleDown(args.NewStatus, claim, released)
+			}
+		}
+		if isReplicasIncreased(claim, args.NewStatus) {
+			log.Info("Replicas increased, re-entering Claiming phase",
+				"claimed", args.NewStatus.ClaimedReplicas, "desired", desiredReplicas)
+			c.recorder.Event(claim, "Normal", "ReplicasIncreased",
+				fmt.Sprintf("Replicas increased to %d, claiming %d more sandbox(es)", desiredReplicas, desiredReplicas-args.NewStatus.ClaimedReplicas))
+			transitionToClaimingOnScaleUp(args.NewStatus, claim)
+			// Requeue immediately to claim the added replicas
+			return requeue.Immediately().WithReason("ReplicasIncreased"), nil
+		}
+	}
+
+	// Replace claimed sandboxes that died after completion, unless the claim is canceled
+	if claim.Spec.ReplaceOnFailure && !isClaimShutdown(claim) && !isClaimCanceled(claim) &&
+		!conditions.IsTrue(args.NewStatus.Conditions, string(agentsv1alpha1.SandboxClaimConditionReleased)) {
+		alive, err := c.countClaimedSandboxes(ctx, claim)
+		if err != nil {
+			return requeue.NoRequeue(), fmt.Errorf("failed to count claimed sandboxes: %w", err)
+		}
+		if alive < args.NewStatus.ClaimedReplicas {
+			lost := args.NewStatus.ClaimedReplicas - alive
+			log.Info("Claimed sandboxes lost, re-entering Claiming phase",
+				"lost", lost, "alive", alive)
+			c.recorder.Event(claim, "Warning", "ReplicaLost",
+				fmt.Sprintf("Lost %d claimed sandbox(es), claiming replacements", lost))
+			transitionToClaimingOnReplicaLost(args.NewStatus, claim, alive)
+			// Requeue immediately to claim replacements
+			return requeue.Immediately().WithReason("ReplicaLost"), nil
+		}
+	}
+
+	// Publish the claimed sandboxes into the result Secret
+	if claim.Spec.ResultSecretName != "" {
+		if err := c.ensureResultSecret(ctx, claim); err != nil {
+			return requeue.NoRequeue(), fmt.Errorf("failed to ensure result secret: %w", err)
+		}
+	}
+
+	// Call back spec.notifications, coming back later if the delivery failed
+	notifyStrategy := c.ensureClaimNotified(ctx, claim, args.NewStatus)
+
+	// Come back when the lease expires, unless renewed before
+	leaseStrategy := requeue.NoRequeue()
+	if args.NewStatus.LeaseExpireTime != nil {
+		if remaining := time.Until(args.NewStatus.LeaseExpireTime.Time); remaining > 0 {
+			leaseStrategy = requeue.After(remaining).WithReason("WaitingForLeaseExpiry")
+		} else {
+			leaseStrategy = requeue.Immediately().WithReason("LeaseExpired")
+		}
+	}
+	leaseStrategy = leaseStrategy.Sooner(notifyStrategy)
+
 	// Check if TTL cleanup is needed
 	if claim.Spec.TTLAfterCompleted != nil && args.NewStatus.CompletionTime != nil {
 		ttl := claim.Spec.TTLAfterCompleted.Duration
 		// Negative TTL means never delete - skip TTL cleanup
 		if ttl < 0 {
 			log.V(1).Info("TTL is negative, skipping automatic deletion (never delete)", "ttl", ttl)
-			return NoRequeue(), nil
+			return requeue.NoRequeue().WithReason("TTLDisabled").Sooner(leaseStrategy), nil
 		}
 		elapsed := time.Since(args.NewStatus.CompletionTime.Time)
 
@@ -175,46 +520,137 @@ func (c *commonControl) EnsureClaimCompleted(ctx context.Context, args ClaimArgs
 
 		// Check if TTL expired
 		if elapsed >= ttl {
+			// Delete the claimed sandboxes first, so that the claim is kept to retry until none of them leaks
+			if ttlPolicy(claim) == agentsv1alpha1.SandboxClaimTTLDelete {
+				released, err := c.releaseClaimedSandboxes(ctx, claim)
+				if err != nil {
+					return requeue.NoRequeue(), fmt.Errorf("failed to delete claimed sandboxes after TTL: %w", err)
+				}
+				if released > 0 {
+					log.Info("TTL expired, deleted claimed sandboxes", "ttl", ttl, "released", released)
+					c.recorder.Event(claim, "Normal", "ClaimedSandboxesTTLDelete",
+						fmt.Sprintf("Deleted %d claimed sandbox(es) after TTL of %v", released, ttl))
+				}
+			}
+			if c.archiver != nil {
+				if err := c.archiver.Archive(ctx, archive.NewRecord(claim, time.Now())); err != nil {
+					log.Error(err, "failed to archive SandboxClaim, deletion postponed")
+					c.recorder.Event(claim, "Warning", "SandboxClaimArchiveFailed", fmt.Sprintf("Failed to archive SandboxClaim: %v", err))
+					// Return error to retry with exponential backoff, the claim is kept until archived
+					return requeue.NoRequeue(), fmt.Errorf("failed to archive SandboxClaim: %w", err)
+				}
+				log.Info("SandboxClaim archived before TTL deletion")
+			}
+			// The claimed sandboxes are kept claimed and running under the Retain TTL policy, so the finalizer must
+			// not release them with the deletion policy
+			if ttlPolicy(claim) == agentsv1alpha1.SandboxClaimTTLRetain {
+				if _, err := utils.PatchFinalizer(ctx, c.Client, claim, utils.RemoveFinalizerOpType, agentsv1alpha1.SandboxClaimFinalizer); err != nil {
+					return requeue.NoRequeue(), err
+				}
+			}
 			log.Info("TTL expired, deleting SandboxClaim", "ttl", ttl, "elapsed", elapsed)
 			c.recorder.Event(claim, "Normal", "SandboxClaimTTLDelete", fmt.Sprintf("Deleting SandboxClaim after TTL of %v", ttl))
 			if err := c.Delete(ctx, claim); err != nil {
 				log.Error(err, "failed to delete SandboxClaim")
 				// Return error to trigger exponential backoff retry
-				return NoRequeue(), err
+				return requeue.NoRequeue(), err
 			}
 
 			log.Info("SandboxClaim deleted successfully due to TTL expiration")
-			return NoRequeue(), nil
+			return requeue.NoRequeue().WithReason("TTLExpired"), nil
 		}
 
 		// TTL not yet expired, calculate remaining time
 		remaining := ttl - elapsed
 		log.V(1).Info("TTL not yet expired, will requeue", "remaining", remaining)
-		return RequeueAfter(remaining), nil
+		return requeue.After(remaining).WithReason("WaitingForTTL").Sooner(leaseStrategy), nil
 	}
 
 	// No TTL configured, no need to requeue
 	log.V(1).Info("No TTL cleanup configured", "hasTTL", claim.Spec.TTLAfterCompleted != nil, "hasCompletionTime", args.NewStatus.CompletionTime != nil)
-	return NoRequeue(), nil
+	return leaseStrategy, nil
 }
 
-// claimSandboxes attempts to claim up to batchSize sandboxes from the pool
-func (c *commonControl) claimSandboxes(ctx context.Context, claim *agentsv1alpha1.SandboxClaim, sandboxSet *agentsv1alpha1.SandboxSet, batchSize int) (int, error) {
+// warnClaimTimeoutApproaching records an event once per claiming round when the claim enters the last
+// 1/claimTimeoutWarningFraction of its claim timeout, so that describe output tells how long is left to get sandboxes.
+func (c *commonControl) warnClaimTimeoutApproaching(claim *agentsv1alpha1.SandboxClaim, status *agentsv1alpha1.SandboxClaimStatus, now time.Time) {
+	remaining, approaching := claimTimeoutApproaching(claim, status, now)
+	if !approaching || hasHistorySince(status, "ClaimTimeoutApproaching", status.ClaimStartTime.Time) {
+		return
+	}
+	message := fmt.Sprintf("Timeout in %v, %d/%d claimed", remaining.Round(time.Second), status.ClaimedReplicas, getDesiredReplicas(claim))
+	c.recorder.Event(claim, "Warning", "ClaimTimeoutApproaching", message)
+	recordHistory(status, "ClaimTimeoutApproaching", message)
+}
+
+// claimConflictBackoff is how a bind retries when the sandbox it picked was claimed concurrently by another claim,
+// picking another sandbox instead of failing the batch and leaving the remaining replicas to the next reconcile
+var claimConflictBackoff = wait.Backoff{
+	Steps:    5,
+	Duration: 20 * time.Millisecond,
+	Factor:   2,
+	Jitter:   0.5,
+}
+
+// claimSandboxes attempts to claim up to batchSize sandboxes from the pool, returns the bound ones and reports whether
+// any bind exceeded the per-replica timeout of the claim. Every bound sandbox is reported by an event with the progress
+// of the claim.
+func (c *commonControl) claimSandboxes(ctx context.Context, claim *agentsv1alpha1.SandboxClaim, sandboxSet *agentsv1alpha1.SandboxSet,
+	batchSize int, claimedBefore, desired int32) ([]infra.Sandbox, bool, error) {
 	log := logf.FromContext(ctx)
 
 	// Validate and build claim options
 	opts, err := c.buildClaimOptions(ctx, claim, sandboxSet)
 	if err != nil {
-		return 0, fmt.Errorf("failed to build claim options: %w", err)
+		return nil, false, fmt.Errorf("failed to build claim options: %w", err)
+	}
+	var ordinals sets.Set[int]
+	if claim.Spec.OrdinalAliases {
+		if ordinals, err = c.claimedOrdinals(claim); err != nil {
+			return nil, false, fmt.Errorf("failed to list claimed ordinals: %w", err)
+		}
 	}
 
 	claimLockChannel := make(chan struct{}, batchSize) // set to max batch size, not controlled
 	limiter := rate.NewLimiter(rate.Inf, batchSize)
+	var replicaTimedOut atomic.Bool
+	bound := atomic.Int32{}
+	bound.Store(claimedBefore)
+	var boundMu sync.Mutex
+	boundSandboxes := make([]infra.Sandbox, 0, batchSize)
 	// Attempt to claim sandboxes concurrently using DoItSlowly
 	claimedCount, err := utils.DoItSlowly(batchSize, InitialClaimBatchSize, func() error {
+		bindCtx := ctx
+		if claim.Spec.PerReplicaTimeout != nil {
+			var cancel context.CancelFunc
+			bindCtx, cancel = context.WithTimeout(ctx, claim.Spec.PerReplicaTimeout.Duration)
+			defer cancel()
+		}
+		bindOpts := opts
+		ordinal := -1
+		if claim.Spec.OrdinalAliases {
+			ordinal = ClaimOrdinals.Take(claim, ordinals)
+			bindOpts.Modifier = withOrdinalAlias(opts.Modifier, claim, ordinal)
+		}
 		// Pass nil for rand so sandboxcr uses global rand (concurrent-safe).
-		sbx, metrics, claimErr := sandboxcr.TryClaimSandbox(ctx, opts, &c.pickCache, c.cache, c.sandboxClient, claimLockChannel, limiter)
+		var sbx infra.Sandbox
+		var metrics infra.ClaimMetrics
+		conflicts := 0
+		claimErr := retry.OnError(claimConflictBackoff, sandboxcr.IsLockConflict, func() (err error) {
+			sbx, metrics, err = sandboxcr.TryClaimSandbox(bindCtx, bindOpts, &c.pickCache, c.cache, c.sandboxClient, claimLockChannel, limiter)
+			if sandboxcr.IsLockConflict(err) {
+				conflicts++
+				log.V(1).Info("Picked sandbox was claimed concurrently, picking another one", "conflicts", conflicts)
+			}
+			return err
+		})
 		if claimErr != nil {
+			if ordinal >= 0 {
+				ClaimOrdinals.Return(claim, ordinal)
+			}
+			if ctx.Err() == nil && bindCtx.Err() == context.DeadlineExceeded {
+				replicaTimedOut.Store(true)
+			}
 			log.Error(claimErr, "Failed to claim sandbox")
 			return claimErr
 		}
@@ -224,6 +660,11 @@ func (c *commonControl) claimSandboxes(ctx context.Context, claim *agentsv1alpha
 			"totalCost", metrics.Total,
 			"pickAndLock", metrics.PickAndLock,
 			"initRuntime", metrics.InitRuntime)
+		boundMu.Lock()
+		boundSandboxes = append(boundSandboxes, sbx)
+		boundMu.Unlock()
+		c.recorder.Event(claim, "Normal", "SandboxBound",
+			fmt.Sprintf("Bound sandbox %s (%d/%d)", sbx.GetName(), bound.Add(1), desired))
 		return nil
 	})
 
@@ -231,7 +672,7 @@ func (c *commonControl) claimSandboxes(ctx context.Context, claim *agentsv1alpha
 		log.Info("Claimed sandboxes successfully", "count", claimedCount, "attempted", batchSize)
 	}
 
-	return claimedCount, err
+	return boundSandboxes, replicaTimedOut.Load(), err
 }
 
 // buildClaimOptions constructs ClaimSandboxOptions for TryClaimSandbox
@@ -240,6 +681,7 @@ func (c *commonControl) buildClaimOptions(ctx context.Context, claim *agentsv1al
 	opts := infra.ClaimSandboxOptions{
 		User:     string(claim.UID), // Use UID to ensure uniqueness across claim recreations
 		Template: sandboxSet.Name,
+		Regions:  claim.Spec.Regions,
 		Modifier: func(sbx infra.Sandbox) {
 			// propagate annotations to sandbox
 			if len(claim.Spec.Annotations) > 0 {
@@ -259,17 +701,19 @@ func (c *commonControl) buildClaimOptions(ctx context.Context, claim *agentsv1al
 				labels = make(map[string]string)
 			}
 			labels[agentsv1alpha1.LabelSandboxClaimName] = claim.Name
+			labels[agentsv1alpha1.LabelSandboxClaimUID] = string(claim.UID)
 
 			for k, v := range claim.Spec.Labels {
 				labels[k] = v
 			}
 			sbx.SetLabels(labels)
 
-			// propagate annotations to podtemplate
+			// propagate labels to podtemplate, the claim name tells the stale label janitor whose labels they are
 			labels = sbx.GetPodLabels()
 			if labels == nil {
 				labels = make(map[string]string)
 			}
+			labels[agentsv1alpha1.LabelSandboxClaimName] = claim.Name
 
 			for k, v := range claim.Spec.Labels {
 				labels[k] = v
@@ -287,6 +731,22 @@ func (c *commonControl) buildClaimOptions(ctx context.Context, claim *agentsv1al
 		CreateOnNoStock:      claim.Spec.CreateOnNoStock,
 	}
 
+	switch claim.Spec.TemplateRevision {
+	case "":
+	case agentsv1alpha1.SandboxClaimTemplateRevisionLatest:
+		opts.Revision = sandboxSet.Status.UpdateRevision
+	default:
+		opts.Revision = claim.Spec.TemplateRevision
+	}
+
+	if claim.Spec.Selector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(claim.Spec.Selector)
+		if err != nil {
+			return opts, fmt.Errorf("invalid selector, err: %v", err)
+		}
+		opts.Selector = selector
+	}
+
 	if claim.Spec.InplaceUpdate != nil {
 		opts.InplaceUpdate = &config.InplaceUpdateOptions{
 			Image: claim.Spec.InplaceUpdate.Image,
@@ -340,6 +800,185 @@ func (c *commonControl) buildClaimOptions(ctx context.Context, claim *agentsv1al
 	return sandboxcr.ValidateAndInitClaimOptions(opts)
 }
 
+// releaseClaimedSandboxes deletes all sandboxes claimed by this claim, and returns how many were deleted
+func (c *commonControl) releaseClaimedSandboxes(ctx context.Context, claim *agentsv1alpha1.SandboxClaim) (int32, error) {
+	log := logf.FromContext(ctx)
+	sandboxes, err := c.cache.ListSandboxWithUser(string(claim.UID))
+	if err != nil {
+		return 0, err
+	}
+	var released int32
+	for _, sbx := range sandboxes {
+		if sbx.DeletionTimestamp != nil {
+			continue
+		}
+		if sbx.Spec.Quarantine != nil {
+			log.Info("Skip releasing quarantined sandbox", "sandbox", klog.KObj(sbx))
+			continue
+		}
+		err = c.sandboxClient.SandboxClient.ApiV1alpha1().Sandboxes(sbx.Namespace).Delete(ctx, sbx.Name, metav1.DeleteOptions{})
+		if err != nil && !errors.IsNotFound(err) {
+			return released, err
+		}
+		log.Info("Released claimed sandbox", "sandbox", klog.KObj(sbx))
+		released++
+	}
+	return released, nil
+}
+
+// ensureResultSecret creates or updates the result Secret of the claim with the sandboxes currently claimed by it.
+// The Secret is read and written with the uncached client, so that the controller does not cache all Secrets.
+func (c *commonControl) ensureResultSecret(ctx context.Context, claim *agentsv1alpha1.SandboxClaim) error {
+	log := logf.FromContext(ctx)
+	sandboxes, err := c.cache.ListSandboxWithUser(string(claim.UID))
+	if err != nil {
+		return err
+	}
+	desired, err := buildResultSecret(claim, sandboxes, c.cache, c.sandboxClient)
+	if err != nil {
+		return err
+	}
+
+	secrets := c.sandboxClient.K8sClient.CoreV1().Secrets(claim.Namespace)
+	existing, err := secrets.Get(ctx, desired.Name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		if _, err = secrets.Create(ctx, desired, metav1.CreateOptions{}); err != nil {
+			return err
+		}
+		log.Info("Result secret created", "secret", desired.Name, "sandboxes", len(sandboxes))
+		c.recorder.Event(claim, "Normal", "ResultSecretCreated",
+			fmt.Sprintf("Wrote %d claimed sandbox(es) into secret %s", len(sandboxes), desired.Name))
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !metav1.IsControlledBy(existing, claim) {
+		// never overwrite a secret of others
+		log.Info("Result secret is not owned by the claim, skip writing", "secret", desired.Name)
+		c.recorder.Event(claim, "Warning", "ResultSecretConflict",
+			fmt.Sprintf("Secret %s already exists and is not owned by the claim", desired.Name))
+		return nil
+	}
+	if equality.Semantic.DeepEqual(existing.Data, desired.Data) {
+		return nil
+	}
+	existing.Data = desired.Data
+	if _, err = secrets.Update(ctx, existing, metav1.UpdateOptions{}); err != nil {
+		return err
+	}
+	log.Info("Result secret updated", "secret", desired.Name, "sandboxes", len(sandboxes))
+	return nil
+}
+
+// estimateClaimETA estimates when the remaining replicas become available from the unclaimed sandboxes being created in
+// the pool of the SandboxSet.
+func (c *commonControl) estimateClaimETA(sandboxSet *agentsv1alpha1.SandboxSet, remaining int32, now time.Time) *agentsv1alpha1.SandboxClaimETA {
+	creating := c.listPoolSandboxes(sandboxSet, agentsv1alpha1.SandboxStateCreating)
+	return estimateETA(WarmupLatencies, WarmupPoolKey(sandboxSet.Namespace, sandboxSet.Name), creating, remaining, now)
+}
+
+// listPoolSandboxes lists the sandboxes of the pool in the state from the cache
+func (c *commonControl) listPoolSandboxes(sandboxSet *agentsv1alpha1.SandboxSet, state string) []*agentsv1alpha1.Sandbox {
+	if c.cache == nil {
+		return nil
+	}
+	sandboxes, err := c.cache.ListSandboxesInPool(sandboxSet.Name)
+	if err != nil {
+		return nil
+	}
+	matched := make([]*agentsv1alpha1.Sandbox, 0, len(sandboxes))
+	now := time.Now()
+	for _, sbx := range sandboxes {
+		if sbx.Namespace != sandboxSet.Namespace {
+			continue
+		}
+		if sbxState, _ := sandboxstate.Evaluate(sbx, now, sandboxstate.Policy{}); sbxState == state {
+			matched = append(matched, sbx)
+		}
+	}
+	return matched
+}
+
+// claimedOrdinals returns the ordinals of the alive sandboxes claimed by this claim
+func (c *commonControl) claimedOrdinals(claim *agentsv1alpha1.SandboxClaim) (sets.Set[int], error) {
+	sandboxes, err := c.cache.ListSandboxWithUser(string(claim.UID))
+	if err != nil {
+		return nil, err
+	}
+	alive := make([]*agentsv1alpha1.Sandbox, 0, len(sandboxes))
+	now := time.Now()
+	for _, sbx := range sandboxes {
+		if state, _ := sandboxstate.Evaluate(sbx, now, sandboxstate.Policy{}); state != agentsv1alpha1.SandboxStateDead {
+			alive = append(alive, sbx)
+		}
+	}
+	return observedOrdinals(alive), nil
+}
+
+// syncClaimedSandboxes lists the sandboxes claimed by this claim into its status. The list is left as is if the
+// sandboxes cannot be listed, to be synced by the next reconcile.
+func (c *commonControl) syncClaimedSandboxes(ctx context.Context, claim *agentsv1alpha1.SandboxClaim, status *agentsv1alpha1.SandboxClaimStatus) {
+	if c.cache == nil {
+		return
+	}
+	sandboxes, err := c.cache.ListSandboxWithUser(string(claim.UID))
+	if err != nil {
+		logf.FromContext(ctx).Error(err, "Failed to list claimed sandboxes")
+		return
+	}
+	status.ClaimedSandboxes = buildClaimedSandboxes(sandboxes)
+	if claim.Spec.TemplateName == "" {
+		status.Templates = buildTemplateStatuses(sandboxes)
+	}
+}
+
+// recordClaimedReplicas records the sandboxes bound to the claim in its status. Without minReadySeconds all of them are
+// claimed, otherwise only the minReady ones that have been Ready for it, and all of them are recorded in boundReplicas.
+func recordClaimedReplicas(claim *agentsv1alpha1.SandboxClaim, status *agentsv1alpha1.SandboxClaimStatus, bound, minReady int32) {
+	if claim.Spec.MinReadySeconds <= 0 {
+		status.ClaimedReplicas = bound
+		status.BoundReplicas = 0
+		return
+	}
+	status.ClaimedReplicas = min(minReady, bound)
+	status.BoundReplicas = bound
+}
+
+// countMinReadySandboxes counts the sandboxes claimed by this claim that have been Ready for its minReadySeconds since
+// they were claimed, and returns how long until the next one of the other Ready ones is counted.
+func (c *commonControl) countMinReadySandboxes(claim *agentsv1alpha1.SandboxClaim, now time.Time) (int32, time.Duration, error) {
+	sandboxes, err := c.cache.ListSandboxWithUser(string(claim.UID))
+	if err != nil {
+		return 0, 0, err
+	}
+	minReadySeconds := time.Duration(claim.Spec.MinReadySeconds) * time.Second
+	var cnt int32
+	var next time.Duration
+	for _, sbx := range sandboxes {
+		if state, _ := sandboxstate.Evaluate(sbx, now, sandboxstate.Policy{}); state == agentsv1alpha1.SandboxStateDead {
+			continue
+		}
+		cond := meta.FindStatusCondition(sbx.Status.Conditions, string(agentsv1alpha1.SandboxConditionReady))
+		if cond == nil || cond.Status != metav1.ConditionTrue {
+			continue
+		}
+		// a sandbox Ready in the pool for long is only Ready for the claim since it was claimed
+		readySince := cond.LastTransitionTime.Time
+		if claimed := claimTime(sbx); claimed.After(readySince) {
+			readySince = claimed
+		}
+		if wait := readySince.Add(minReadySeconds).Sub(now); wait > 0 {
+			if next == 0 || wait < next {
+				next = wait
+			}
+			continue
+		}
+		cnt++
+	}
+	return cnt, next, nil
+}
+
 // countClaimedSandboxes counts sandboxes that are claimed by this claim
 func (c *commonControl) countClaimedSandboxes(ctx context.Context, claim *agentsv1alpha1.SandboxClaim) (int32, error) {
 	log := logf.FromContext(ctx)
@@ -348,8 +987,9 @@ func (c *commonControl) countClaimedSandboxes(ctx context.Context, claim *agents
 		return 0, err
 	}
 	var cnt int32
+	now := time.Now()
 	for _, sbx := range sandboxes {
-		state, reason := stateutils.GetSandboxState(sbx)
+		state, reason := sandboxstate.Evaluate(sbx, now, sandboxstate.Policy{})
 		if state == agentsv1alpha1.SandboxStateDead {
 			log.Info("skip counting dead sandbox", "reason", reason)
 			continue
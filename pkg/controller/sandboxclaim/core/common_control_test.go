@@ -19,16 +19,22 @@ package core
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	k8sfake "k8s.io/client-go/kubernetes/fake"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
@@ -36,10 +42,14 @@ import (
 	"github.com/openkruise/agents/client/clientset/versioned"
 	sandboxfake "github.com/openkruise/agents/client/clientset/versioned/fake"
 	"github.com/openkruise/agents/pkg/agent-runtime/storages"
+	"github.com/openkruise/agents/pkg/controller/sandboxclaim/archive"
 	"github.com/openkruise/agents/pkg/sandbox-manager/clients"
 	"github.com/openkruise/agents/pkg/sandbox-manager/config"
 	"github.com/openkruise/agents/pkg/sandbox-manager/infra"
 	"github.com/openkruise/agents/pkg/sandbox-manager/infra/sandboxcr"
+	"github.com/openkruise/agents/pkg/utils/conditions"
+	"github.com/openkruise/agents/pkg/utils/fieldindex"
+	"github.com/openkruise/agents/pkg/utils/requeue"
 )
 
 func TestNewCommonControl(t *testing.T) {
@@ -61,55 +71,6 @@ func TestNewCommonControl(t *testing.T) {
 	var _ ClaimControl = control
 }
 
-func TestRequeueStrategy_Factories(t *testing.T) {
-	tests := []struct {
-		name             string
-		factory          func() RequeueStrategy
-		expectedStrategy RequeueStrategy
-	}{
-		{
-			name:    "RequeueImmediately",
-			factory: RequeueImmediately,
-			expectedStrategy: RequeueStrategy{
-				Immediate: true,
-				After:     0,
-			},
-		},
-		{
-			name:    "NoRequeue",
-			factory: NoRequeue,
-			expectedStrategy: RequeueStrategy{
-				Immediate: false,
-				After:     0,
-			},
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			got := tt.factory()
-			assert.Equal(t, tt.expectedStrategy.Immediate, got.Immediate, "Immediate mismatch")
-			assert.Equal(t, tt.expectedStrategy.After, got.After, "After mismatch")
-		})
-	}
-
-	// Test RequeueAfter separately with different durations
-	t.Run("RequeueAfter", func(t *testing.T) {
-		durations := []time.Duration{
-			1 * time.Second,
-			5 * time.Minute,
-			1 * time.Hour,
-		}
-		for _, d := range durations {
-			t.Run(d.String(), func(t *testing.T) {
-				got := RequeueAfter(d)
-				assert.False(t, got.Immediate, "RequeueAfter(%v).Immediate should be false", d)
-				assert.Equal(t, d, got.After, "RequeueAfter(%v).After mismatch", d)
-			})
-		}
-	})
-}
-
 func TestNewClaimControl(t *testing.T) {
 	scheme := runtime.NewScheme()
 	_ = agentsv1alpha1.AddToScheme(scheme)
@@ -153,11 +114,13 @@ func TestCommonControl_EnsureClaimClaiming(t *testing.T) {
 		name             string
 		claim            *agentsv1alpha1.SandboxClaim
 		sandboxSet       *agentsv1alpha1.SandboxSet
+		otherClaims      []client.Object
 		newStatus        *agentsv1alpha1.SandboxClaimStatus
 		setupSandboxes   func(*testing.T) []*agentsv1alpha1.Sandbox
-		expectedStrategy RequeueStrategy
+		expectedStrategy requeue.Strategy
 		expectError      bool
 		checkStatus      func(*testing.T, *agentsv1alpha1.SandboxClaimStatus)
+		expectedEvents   []string
 	}{
 		{
 			name: "no available sandboxes - should retry after interval",
@@ -186,10 +149,513 @@ func TestCommonControl_EnsureClaimClaiming(t *testing.T) {
 				// No available sandboxes
 				return nil
 			},
-			expectedStrategy: RequeueAfter(ClaimRetryInterval),
+			expectedStrategy: requeue.After(ClaimRetryInterval),
+			expectError:      false,
+			checkStatus: func(t *testing.T, status *agentsv1alpha1.SandboxClaimStatus) {
+				assert.Equal(t, int32(0), status.ClaimedReplicas, "ClaimedReplicas mismatch")
+			},
+			expectedEvents: []string{"Warning NoAvailableSandboxes Pool test-template exhausted, 2 more needed"},
+		},
+		{
+			name: "claim timeout approaching - should warn with the time left",
+			claim: &agentsv1alpha1.SandboxClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-claim-approaching",
+					Namespace: "default",
+					UID:       "test-uid-approaching",
+				},
+				Spec: agentsv1alpha1.SandboxClaimSpec{
+					TemplateName: "test-template-approaching",
+					ClaimTimeout: &metav1.Duration{Duration: time.Minute},
+				},
+			},
+			sandboxSet: &agentsv1alpha1.SandboxSet{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-template-approaching",
+					Namespace: "default",
+				},
+			},
+			newStatus: &agentsv1alpha1.SandboxClaimStatus{
+				Phase:          agentsv1alpha1.SandboxClaimPhaseClaiming,
+				ClaimStartTime: ptr.To(metav1.NewTime(time.Now().Add(-50 * time.Second))),
+			},
+			expectedStrategy: requeue.After(ClaimRetryInterval),
+			checkStatus: func(t *testing.T, status *agentsv1alpha1.SandboxClaimStatus) {
+				require.NotEmpty(t, status.History)
+				assert.Equal(t, "ClaimTimeoutApproaching", status.History[len(status.History)-1].Reason)
+			},
+			expectedEvents: []string{
+				"Warning NoAvailableSandboxes Pool test-template-approaching exhausted, 1 more needed",
+				"Warning ClaimTimeoutApproaching Timeout in 10s, 0/1 claimed",
+			},
+		},
+		{
+			name: "available sandbox bound - should report the progress",
+			claim: &agentsv1alpha1.SandboxClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-claim-bound",
+					Namespace: "default",
+					UID:       "test-uid-bound",
+				},
+				Spec: agentsv1alpha1.SandboxClaimSpec{
+					TemplateName:    "test-template-bound",
+					Replicas:        int32Ptr(3),
+					SkipInitRuntime: true,
+				},
+				Status: agentsv1alpha1.SandboxClaimStatus{
+					ClaimedReplicas: 1,
+				},
+			},
+			sandboxSet: &agentsv1alpha1.SandboxSet{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-template-bound",
+					Namespace: "default",
+					UID:       "test-template-bound-uid",
+				},
+			},
+			newStatus: &agentsv1alpha1.SandboxClaimStatus{
+				Phase:           agentsv1alpha1.SandboxClaimPhaseClaiming,
+				ClaimedReplicas: 1,
+			},
+			setupSandboxes: func(t *testing.T) []*agentsv1alpha1.Sandbox {
+				sbs := &agentsv1alpha1.SandboxSet{ObjectMeta: metav1.ObjectMeta{Name: "test-template-bound", UID: "test-template-bound-uid"}}
+				sbx := &agentsv1alpha1.Sandbox{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:              "sandbox-bound",
+						Namespace:         "default",
+						CreationTimestamp: metav1.Now(),
+						Labels:            map[string]string{agentsv1alpha1.LabelSandboxTemplate: "test-template-bound"},
+						OwnerReferences:   []metav1.OwnerReference{*metav1.NewControllerRef(sbs, agentsv1alpha1.SandboxSetControllerKind)},
+					},
+					Status: agentsv1alpha1.SandboxStatus{
+						Phase: agentsv1alpha1.SandboxRunning,
+						Conditions: []metav1.Condition{{
+							Type:   string(agentsv1alpha1.SandboxConditionReady),
+							Status: metav1.ConditionTrue,
+						}},
+						PodInfo: agentsv1alpha1.PodInfo{PodIP: "1.2.3.4"},
+					},
+				}
+				CreateSandboxWithStatus(t, sandboxClient, sbx)
+				time.Sleep(100 * time.Millisecond) // Wait for cache sync
+				return []*agentsv1alpha1.Sandbox{sbx}
+			},
+			expectedStrategy: requeue.Immediately(),
+			checkStatus: func(t *testing.T, status *agentsv1alpha1.SandboxClaimStatus) {
+				assert.Equal(t, int32(2), status.ClaimedReplicas)
+			},
+			expectedEvents: []string{"Normal SandboxBound Bound sandbox sandbox-bound (2/3)"},
+		},
+		{
+			name: "ordinal aliases - bound sandbox should take the free ordinal",
+			claim: &agentsv1alpha1.SandboxClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-claim-peers",
+					Namespace: "default",
+					UID:       "test-uid-peers",
+				},
+				Spec: agentsv1alpha1.SandboxClaimSpec{
+					TemplateName:    "test-template-peers",
+					Replicas:        int32Ptr(2),
+					SkipInitRuntime: true,
+					OrdinalAliases:  true,
+				},
+				Status: agentsv1alpha1.SandboxClaimStatus{
+					ClaimedReplicas: 1,
+				},
+			},
+			sandboxSet: &agentsv1alpha1.SandboxSet{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-template-peers",
+					Namespace: "default",
+					UID:       "test-template-peers-uid",
+				},
+			},
+			newStatus: &agentsv1alpha1.SandboxClaimStatus{
+				Phase:           agentsv1alpha1.SandboxClaimPhaseClaiming,
+				ClaimedReplicas: 1,
+			},
+			setupSandboxes: func(t *testing.T) []*agentsv1alpha1.Sandbox {
+				sbs := &agentsv1alpha1.SandboxSet{ObjectMeta: metav1.ObjectMeta{Name: "test-template-peers", UID: "test-template-peers-uid"}}
+				ready := agentsv1alpha1.SandboxStatus{
+					Phase: agentsv1alpha1.SandboxRunning,
+					Conditions: []metav1.Condition{{
+						Type:   string(agentsv1alpha1.SandboxConditionReady),
+						Status: metav1.ConditionTrue,
+					}},
+					PodInfo: agentsv1alpha1.PodInfo{PodIP: "1.2.3.4"},
+				}
+				claimed := &agentsv1alpha1.Sandbox{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:              "sandbox-peer-0",
+						Namespace:         "default",
+						CreationTimestamp: metav1.Now(),
+						Annotations:       map[string]string{agentsv1alpha1.AnnotationOwner: "test-uid-peers"},
+						Labels: map[string]string{
+							agentsv1alpha1.LabelSandboxTemplate:     "test-template-peers",
+							agentsv1alpha1.LabelSandboxIsClaimed:    "true",
+							agentsv1alpha1.LabelSandboxClaimOrdinal: "0",
+						},
+					},
+					Status: ready,
+				}
+				available := &agentsv1alpha1.Sandbox{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:              "sandbox-peer",
+						Namespace:         "default",
+						CreationTimestamp: metav1.Now(),
+						Labels:            map[string]string{agentsv1alpha1.LabelSandboxTemplate: "test-template-peers"},
+						OwnerReferences:   []metav1.OwnerReference{*metav1.NewControllerRef(sbs, agentsv1alpha1.SandboxSetControllerKind)},
+					},
+					Spec: agentsv1alpha1.SandboxSpec{
+						EmbeddedSandboxTemplate: agentsv1alpha1.EmbeddedSandboxTemplate{Template: &corev1.PodTemplateSpec{}},
+					},
+					Status: ready,
+				}
+				CreateSandboxWithStatus(t, sandboxClient, claimed)
+				CreateSandboxWithStatus(t, sandboxClient, available)
+				time.Sleep(100 * time.Millisecond) // Wait for cache sync
+				return []*agentsv1alpha1.Sandbox{claimed, available}
+			},
+			expectedStrategy: requeue.Immediately(),
+			checkStatus: func(t *testing.T, status *agentsv1alpha1.SandboxClaimStatus) {
+				assert.Equal(t, int32(2), status.ClaimedReplicas)
+				sbx, err := sandboxClient.ApiV1alpha1().Sandboxes("default").Get(ctx, "sandbox-peer", metav1.GetOptions{})
+				require.NoError(t, err)
+				assert.Equal(t, "1", sbx.Labels[agentsv1alpha1.LabelSandboxClaimOrdinal])
+				assert.Equal(t, "test-claim-peers-1", sbx.Labels[agentsv1alpha1.LabelSandboxClaimAlias])
+				assert.Equal(t, "test-claim-peers-1", sbx.Spec.Template.Labels[agentsv1alpha1.LabelSandboxClaimAlias])
+			},
+		},
+		{
+			name: "pool reached concurrent claim limit - should wait in queue",
+			claim: &agentsv1alpha1.SandboxClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-claim-queued",
+					Namespace: "default",
+					UID:       "test-uid-queued",
+				},
+				Spec: agentsv1alpha1.SandboxClaimSpec{
+					TemplateName: "test-template",
+					Replicas:     int32Ptr(2),
+				},
+			},
+			sandboxSet: &agentsv1alpha1.SandboxSet{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-template",
+					Namespace: "default",
+				},
+				Spec: agentsv1alpha1.SandboxSetSpec{
+					MaxConcurrentClaims: int32Ptr(1),
+				},
+			},
+			newStatus: &agentsv1alpha1.SandboxClaimStatus{
+				Phase:           agentsv1alpha1.SandboxClaimPhaseClaiming,
+				ClaimedReplicas: 0,
+			},
+			setupSandboxes: func(t *testing.T) []*agentsv1alpha1.Sandbox {
+				// Another claim holds the only slot of the pool
+				holder := &agentsv1alpha1.SandboxClaim{ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-claim-holder",
+					Namespace: "default",
+					UID:       "test-uid-holder",
+				}}
+				admitted, _ := ClaimConcurrencyLimiter.Admit("default/test-template", holder, 1)
+				require.True(t, admitted)
+				t.Cleanup(func() {
+					ClaimConcurrencyLimiter.Release(holder)
+				})
+				return nil
+			},
+			expectedStrategy: requeue.After(ClaimRetryInterval),
 			expectError:      false,
 			checkStatus: func(t *testing.T, status *agentsv1alpha1.SandboxClaimStatus) {
 				assert.Equal(t, int32(0), status.ClaimedReplicas, "ClaimedReplicas mismatch")
+				assert.Equal(t, int32Ptr(1), status.QueuedPosition, "QueuedPosition mismatch")
+				assert.Equal(t, "Queued at position 1: 0/2 claimed", status.Message)
+			},
+		},
+		{
+			name: "pool exhausted with sandboxes being created - should publish ETA",
+			claim: &agentsv1alpha1.SandboxClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-claim-eta",
+					Namespace: "default",
+					UID:       "test-uid-eta",
+				},
+				Spec: agentsv1alpha1.SandboxClaimSpec{
+					TemplateName: "test-template-eta",
+				},
+			},
+			sandboxSet: &agentsv1alpha1.SandboxSet{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-template-eta",
+					Namespace: "default",
+					UID:       "test-template-eta-uid",
+				},
+			},
+			newStatus: &agentsv1alpha1.SandboxClaimStatus{
+				Phase: agentsv1alpha1.SandboxClaimPhaseClaiming,
+			},
+			setupSandboxes: func(t *testing.T) []*agentsv1alpha1.Sandbox {
+				WarmupLatencies.Observe(WarmupPoolKey("default", "test-template-eta"), 30*time.Second)
+				sbs := &agentsv1alpha1.SandboxSet{ObjectMeta: metav1.ObjectMeta{Name: "test-template-eta", UID: "test-template-eta-uid"}}
+				sbx := &agentsv1alpha1.Sandbox{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:              "sandbox-creating",
+						Namespace:         "default",
+						CreationTimestamp: metav1.NewTime(time.Now().Add(-10 * time.Second)),
+						Labels:            map[string]string{agentsv1alpha1.LabelSandboxTemplate: "test-template-eta"},
+						OwnerReferences:   []metav1.OwnerReference{*metav1.NewControllerRef(sbs, agentsv1alpha1.SandboxSetControllerKind)},
+					},
+					Status: agentsv1alpha1.SandboxStatus{Phase: agentsv1alpha1.SandboxPending},
+				}
+				CreateSandboxWithStatus(t, sandboxClient, sbx)
+				time.Sleep(100 * time.Millisecond) // Wait for cache sync
+				return []*agentsv1alpha1.Sandbox{sbx}
+			},
+			expectedStrategy: requeue.After(ClaimRetryInterval),
+			checkStatus: func(t *testing.T, status *agentsv1alpha1.SandboxClaimStatus) {
+				require.NotNil(t, status.ETA)
+				assert.Equal(t, int32(1), status.ETA.CreatingReplicas)
+				assert.Equal(t, 30*time.Second, status.ETA.WarmupLatencyP90.Duration)
+				assert.WithinDuration(t, time.Now().Add(20*time.Second), status.ETA.EstimatedTime.Time, 5*time.Second)
+				assert.Contains(t, status.Message, "Waiting for 1 sandbox(es) being created, estimated ready at")
+			},
+		},
+		{
+			name: "available sandboxes reserved for interactive claims - batch claim should wait",
+			claim: &agentsv1alpha1.SandboxClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "test-claim-batch",
+					Namespace:   "default",
+					UID:         "test-uid-batch",
+					Annotations: map[string]string{agentsv1alpha1.AnnotationWorkloadClass: agentsv1alpha1.WorkloadClassBatch},
+				},
+				Spec: agentsv1alpha1.SandboxClaimSpec{
+					TemplateName: "test-template-reserve",
+				},
+			},
+			sandboxSet: &agentsv1alpha1.SandboxSet{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-template-reserve",
+					Namespace: "default",
+					UID:       "test-template-reserve-uid",
+				},
+				Spec: agentsv1alpha1.SandboxSetSpec{
+					InteractiveReserve: ptr.To(intstr.FromString("20%")),
+				},
+			},
+			newStatus: &agentsv1alpha1.SandboxClaimStatus{
+				Phase: agentsv1alpha1.SandboxClaimPhaseClaiming,
+			},
+			setupSandboxes: func(t *testing.T) []*agentsv1alpha1.Sandbox {
+				sbs := &agentsv1alpha1.SandboxSet{ObjectMeta: metav1.ObjectMeta{Name: "test-template-reserve", UID: "test-template-reserve-uid"}}
+				sbx := &agentsv1alpha1.Sandbox{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:            "sandbox-reserved",
+						Namespace:       "default",
+						Labels:          map[string]string{agentsv1alpha1.LabelSandboxTemplate: "test-template-reserve"},
+						OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(sbs, agentsv1alpha1.SandboxSetControllerKind)},
+					},
+					Status: agentsv1alpha1.SandboxStatus{
+						Phase: agentsv1alpha1.SandboxRunning,
+						Conditions: []metav1.Condition{{
+							Type:   string(agentsv1alpha1.SandboxConditionReady),
+							Status: metav1.ConditionTrue,
+						}},
+					},
+				}
+				CreateSandboxWithStatus(t, sandboxClient, sbx)
+				time.Sleep(100 * time.Millisecond) // Wait for cache sync
+				return []*agentsv1alpha1.Sandbox{sbx}
+			},
+			expectedStrategy: requeue.After(ClaimRetryInterval),
+			checkStatus: func(t *testing.T, status *agentsv1alpha1.SandboxClaimStatus) {
+				assert.Equal(t, int32(0), status.ClaimedReplicas)
+				assert.Equal(t, "Waiting for sandboxes beyond the 1 reserved for interactive claims: 0/1 claimed", status.Message)
+				require.NotEmpty(t, status.History)
+				assert.Equal(t, "ReservedForInteractive", status.History[len(status.History)-1].Reason)
+			},
+		},
+		{
+			name: "available sandboxes needed by claims of higher priority - should wait",
+			claim: &agentsv1alpha1.SandboxClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-claim-low-priority",
+					Namespace: "default",
+					UID:       "test-uid-low-priority",
+				},
+				Spec: agentsv1alpha1.SandboxClaimSpec{
+					TemplateName: "test-template-priority",
+				},
+			},
+			sandboxSet: &agentsv1alpha1.SandboxSet{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-template-priority",
+					Namespace: "default",
+					UID:       "test-template-priority-uid",
+				},
+			},
+			otherClaims: []client.Object{
+				&agentsv1alpha1.SandboxClaim{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-claim-high-priority",
+						Namespace: "default",
+						UID:       "test-uid-high-priority",
+					},
+					Spec: agentsv1alpha1.SandboxClaimSpec{
+						TemplateName: "test-template-priority",
+						Priority:     ptr.To[int32](10),
+					},
+					Status: agentsv1alpha1.SandboxClaimStatus{Phase: agentsv1alpha1.SandboxClaimPhaseClaiming},
+				},
+			},
+			newStatus: &agentsv1alpha1.SandboxClaimStatus{
+				Phase: agentsv1alpha1.SandboxClaimPhaseClaiming,
+			},
+			setupSandboxes: func(t *testing.T) []*agentsv1alpha1.Sandbox {
+				sbs := &agentsv1alpha1.SandboxSet{ObjectMeta: metav1.ObjectMeta{Name: "test-template-priority", UID: "test-template-priority-uid"}}
+				sbx := &agentsv1alpha1.Sandbox{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:            "sandbox-priority",
+						Namespace:       "default",
+						Labels:          map[string]string{agentsv1alpha1.LabelSandboxTemplate: "test-template-priority"},
+						OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(sbs, agentsv1alpha1.SandboxSetControllerKind)},
+					},
+					Status: agentsv1alpha1.SandboxStatus{
+						Phase: agentsv1alpha1.SandboxRunning,
+						Conditions: []metav1.Condition{{
+							Type:   string(agentsv1alpha1.SandboxConditionReady),
+							Status: metav1.ConditionTrue,
+						}},
+					},
+				}
+				CreateSandboxWithStatus(t, sandboxClient, sbx)
+				time.Sleep(100 * time.Millisecond) // Wait for cache sync
+				return []*agentsv1alpha1.Sandbox{sbx}
+			},
+			expectedStrategy: requeue.After(ClaimRetryInterval),
+			checkStatus: func(t *testing.T, status *agentsv1alpha1.SandboxClaimStatus) {
+				assert.Equal(t, int32(0), status.ClaimedReplicas)
+				assert.Equal(t, "Waiting for claims of higher priority: 0/1 claimed", status.Message)
+				require.NotEmpty(t, status.History)
+				assert.Equal(t, "WaitingForHigherPriority", status.History[len(status.History)-1].Reason)
+			},
+			expectedEvents: []string{"Normal WaitingForHigherPriority 1 claim(s) of higher priority need 1 sandbox(es) of pool test-template-priority with 1 available"},
+		},
+		{
+			name: "all-or-nothing claim with fewer available sandboxes than replicas - should wait without claiming",
+			claim: &agentsv1alpha1.SandboxClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-claim-gang",
+					Namespace: "default",
+					UID:       "test-uid-gang",
+				},
+				Spec: agentsv1alpha1.SandboxClaimSpec{
+					TemplateName:      "test-template-gang",
+					Replicas:          int32Ptr(2),
+					FulfillmentPolicy: agentsv1alpha1.SandboxClaimFulfillmentAllOrNothing,
+				},
+			},
+			sandboxSet: &agentsv1alpha1.SandboxSet{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-template-gang",
+					Namespace: "default",
+					UID:       "test-template-gang-uid",
+				},
+			},
+			newStatus: &agentsv1alpha1.SandboxClaimStatus{
+				Phase: agentsv1alpha1.SandboxClaimPhaseClaiming,
+			},
+			setupSandboxes: func(t *testing.T) []*agentsv1alpha1.Sandbox {
+				sbs := &agentsv1alpha1.SandboxSet{ObjectMeta: metav1.ObjectMeta{Name: "test-template-gang", UID: "test-template-gang-uid"}}
+				sbx := &agentsv1alpha1.Sandbox{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:            "sandbox-gang",
+						Namespace:       "default",
+						Labels:          map[string]string{agentsv1alpha1.LabelSandboxTemplate: "test-template-gang"},
+						OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(sbs, agentsv1alpha1.SandboxSetControllerKind)},
+					},
+					Status: agentsv1alpha1.SandboxStatus{
+						Phase: agentsv1alpha1.SandboxRunning,
+						Conditions: []metav1.Condition{{
+							Type:   string(agentsv1alpha1.SandboxConditionReady),
+							Status: metav1.ConditionTrue,
+						}},
+						PodInfo: agentsv1alpha1.PodInfo{PodIP: "1.2.3.4"},
+					},
+				}
+				CreateSandboxWithStatus(t, sandboxClient, sbx)
+				time.Sleep(100 * time.Millisecond) // Wait for cache sync
+				return []*agentsv1alpha1.Sandbox{sbx}
+			},
+			expectedStrategy: requeue.After(ClaimRetryInterval),
+			checkStatus: func(t *testing.T, status *agentsv1alpha1.SandboxClaimStatus) {
+				assert.Equal(t, int32(0), status.ClaimedReplicas)
+				assert.Equal(t, "Waiting for 2 sandbox(es) to claim all at once: 0/2 claimed", status.Message)
+				require.NotEmpty(t, status.History)
+				assert.Equal(t, "WaitingForAllReplicas", status.History[len(status.History)-1].Reason)
+				sbx, err := sandboxClient.ApiV1alpha1().Sandboxes("default").Get(t.Context(), "sandbox-gang", metav1.GetOptions{})
+				require.NoError(t, err)
+				assert.Empty(t, sbx.Annotations[agentsv1alpha1.AnnotationOwner], "no sandbox should be claimed")
+			},
+			expectedEvents: []string{"Normal WaitingForAllReplicas Pool test-template-gang has 1 claimable sandbox(es) for 2 replicas to claim all at once"},
+		},
+		{
+			name: "bind exceeds the per-replica timeout - should fail fast",
+			claim: &agentsv1alpha1.SandboxClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-claim-stalled",
+					Namespace: "default",
+					UID:       "test-uid-stalled",
+				},
+				Spec: agentsv1alpha1.SandboxClaimSpec{
+					TemplateName:      "test-template-stalled",
+					Replicas:          int32Ptr(2),
+					ClaimTimeout:      &metav1.Duration{Duration: time.Minute},
+					PerReplicaTimeout: &metav1.Duration{Duration: time.Nanosecond},
+				},
+			},
+			sandboxSet: &agentsv1alpha1.SandboxSet{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-template-stalled",
+					Namespace: "default",
+					UID:       "test-template-stalled-uid",
+				},
+			},
+			newStatus: &agentsv1alpha1.SandboxClaimStatus{
+				Phase: agentsv1alpha1.SandboxClaimPhaseClaiming,
+			},
+			setupSandboxes: func(t *testing.T) []*agentsv1alpha1.Sandbox {
+				sbs := &agentsv1alpha1.SandboxSet{ObjectMeta: metav1.ObjectMeta{Name: "test-template-stalled", UID: "test-template-stalled-uid"}}
+				sbx := &agentsv1alpha1.Sandbox{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:            "sandbox-stalled",
+						Namespace:       "default",
+						Labels:          map[string]string{agentsv1alpha1.LabelSandboxTemplate: "test-template-stalled"},
+						OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(sbs, agentsv1alpha1.SandboxSetControllerKind)},
+					},
+					Status: agentsv1alpha1.SandboxStatus{
+						Phase: agentsv1alpha1.SandboxRunning,
+						Conditions: []metav1.Condition{{
+							Type:   string(agentsv1alpha1.SandboxConditionReady),
+							Status: metav1.ConditionTrue,
+						}},
+					},
+				}
+				CreateSandboxWithStatus(t, sandboxClient, sbx)
+				time.Sleep(100 * time.Millisecond) // Wait for cache sync
+				return []*agentsv1alpha1.Sandbox{sbx}
+			},
+			expectedStrategy: requeue.Immediately(),
+			checkStatus: func(t *testing.T, status *agentsv1alpha1.SandboxClaimStatus) {
+				assert.Equal(t, agentsv1alpha1.SandboxClaimPhaseCompleted, status.Phase)
+				assert.Equal(t, int32(0), status.ClaimedReplicas)
+				assert.True(t, conditions.IsTrue(status.Conditions, string(agentsv1alpha1.SandboxClaimConditionReplicaTimedOut)))
+				assert.False(t, conditions.IsTrue(status.Conditions, string(agentsv1alpha1.SandboxClaimConditionTimedOut)))
+				require.NotEmpty(t, status.History)
+				assert.Equal(t, "ReplicaTimeoutReached", status.History[len(status.History)-1].Reason)
 			},
 		},
 		{
@@ -258,7 +724,7 @@ func TestCommonControl_EnsureClaimClaiming(t *testing.T) {
 				time.Sleep(100 * time.Millisecond) // Wait for cache sync
 				return sandboxes
 			},
-			expectedStrategy: RequeueImmediately(), // Should requeue to transition to Completed
+			expectedStrategy: requeue.Immediately(), // Should requeue to transition to Completed
 			expectError:      false,
 			checkStatus: func(t *testing.T, status *agentsv1alpha1.SandboxClaimStatus) {
 				assert.Equal(t, int32(2), status.ClaimedReplicas, "ClaimedReplicas mismatch")
@@ -331,7 +797,7 @@ func TestCommonControl_EnsureClaimClaiming(t *testing.T) {
 				time.Sleep(100 * time.Millisecond) // Wait for cache sync
 				return sandboxes
 			},
-			expectedStrategy: RequeueAfter(ClaimRetryInterval), // Should retry to claim remaining 1
+			expectedStrategy: requeue.After(ClaimRetryInterval), // Should retry to claim remaining 1
 			expectError:      false,
 			checkStatus: func(t *testing.T, status *agentsv1alpha1.SandboxClaimStatus) {
 				assert.Equal(t, int32(2), status.ClaimedReplicas, "Expected ClaimedReplicas to be recovered to 2 (actualCount)")
@@ -421,7 +887,7 @@ func TestCommonControl_EnsureClaimClaiming(t *testing.T) {
 				time.Sleep(100 * time.Millisecond) // Wait for cache sync
 				return sandboxes
 			},
-			expectedStrategy: RequeueAfter(ClaimRetryInterval), // Should retry to claim remaining 1
+			expectedStrategy: requeue.After(ClaimRetryInterval), // Should retry to claim remaining 1
 			expectError:      false,
 			checkStatus: func(t *testing.T, status *agentsv1alpha1.SandboxClaimStatus) {
 				assert.Equal(t, int32(1), status.ClaimedReplicas, "Expected ClaimedReplicas to be still 1 (dead sandbox skipped)")
@@ -438,7 +904,9 @@ func TestCommonControl_EnsureClaimClaiming(t *testing.T) {
 
 			fakeClient := fake.NewClientBuilder().
 				WithScheme(scheme).
+				WithIndex(&agentsv1alpha1.SandboxClaim{}, fieldindex.IndexNameForSandboxSetName, SandboxSetNameIndexFunc).
 				WithObjects(tt.claim, tt.sandboxSet).
+				WithObjects(tt.otherClaims...).
 				Build()
 
 			fakeRecorder := record.NewFakeRecorder(100)
@@ -472,6 +940,16 @@ func TestCommonControl_EnsureClaimClaiming(t *testing.T) {
 			if tt.checkStatus != nil && !tt.expectError {
 				tt.checkStatus(t, tt.newStatus)
 			}
+
+			// Check progress events
+			close(fakeRecorder.Events)
+			var events []string
+			for event := range fakeRecorder.Events {
+				events = append(events, event)
+			}
+			for _, expected := range tt.expectedEvents {
+				assert.Contains(t, events, expected)
+			}
 		})
 	}
 }
@@ -488,7 +966,7 @@ func TestCommonControl_EnsureClaimCompleted(t *testing.T) {
 		name               string
 		claim              *agentsv1alpha1.SandboxClaim
 		newStatus          *agentsv1alpha1.SandboxClaimStatus
-		expectedStrategy   RequeueStrategy
+		expectedStrategy   requeue.Strategy
 		expectError        bool
 		expectDeleted      bool
 		expectedRequeueMin time.Duration // minimum expected requeue time (for TTL not yet expired)
@@ -509,7 +987,7 @@ func TestCommonControl_EnsureClaimCompleted(t *testing.T) {
 				Phase:          agentsv1alpha1.SandboxClaimPhaseCompleted,
 				CompletionTime: &now,
 			},
-			expectedStrategy: NoRequeue(),
+			expectedStrategy: requeue.NoRequeue(),
 			expectError:      false,
 			expectDeleted:    false,
 		},
@@ -529,7 +1007,7 @@ func TestCommonControl_EnsureClaimCompleted(t *testing.T) {
 				Phase: agentsv1alpha1.SandboxClaimPhaseCompleted,
 				// No CompletionTime
 			},
-			expectedStrategy: NoRequeue(),
+			expectedStrategy: requeue.NoRequeue(),
 			expectError:      false,
 			expectDeleted:    false,
 		},
@@ -549,7 +1027,7 @@ func TestCommonControl_EnsureClaimCompleted(t *testing.T) {
 				Phase:          agentsv1alpha1.SandboxClaimPhaseCompleted,
 				CompletionTime: &pastTime, // 10 seconds ago, TTL is 5 seconds
 			},
-			expectedStrategy: NoRequeue(),
+			expectedStrategy: requeue.NoRequeue(),
 			expectError:      false,
 			expectDeleted:    true,
 		},
@@ -569,7 +1047,7 @@ func TestCommonControl_EnsureClaimCompleted(t *testing.T) {
 				Phase:          agentsv1alpha1.SandboxClaimPhaseCompleted,
 				CompletionTime: &futureTime, // 1 second ago, TTL is 10 seconds, should requeue after ~9s
 			},
-			expectedStrategy:   RequeueAfter(9 * time.Second), // placeholder, will check range
+			expectedStrategy:   requeue.After(9 * time.Second), // placeholder, will check range
 			expectError:        false,
 			expectDeleted:      false,
 			expectedRequeueMin: 8 * time.Second, // allow some tolerance
@@ -590,7 +1068,7 @@ func TestCommonControl_EnsureClaimCompleted(t *testing.T) {
 				Phase:          agentsv1alpha1.SandboxClaimPhaseCompleted,
 				CompletionTime: &metav1.Time{Time: time.Now().Add(-10 * time.Minute)}, // completed 10 min ago
 			},
-			expectedStrategy: NoRequeue(),
+			expectedStrategy: requeue.NoRequeue(),
 			expectError:      false,
 			expectDeleted:    false,
 		},
@@ -649,9 +1127,724 @@ func TestCommonControl_EnsureClaimCompleted(t *testing.T) {
 	}
 }
 
-func TestCommonControl_buildClaimOptions(t *testing.T) {
-	scheme := runtime.NewScheme()
-	_ = agentsv1alpha1.AddToScheme(scheme)
+type fakeArchiver struct {
+	records []*archive.Record
+	err     error
+}
+
+func (f *fakeArchiver) Archive(_ context.Context, record *archive.Record) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.records = append(f.records, record)
+	return nil
+}
+
+func TestCommonControl_EnsureClaimCompleted_Archive(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = agentsv1alpha1.AddToScheme(scheme)
+	completionTime := metav1.NewTime(time.Now().Add(-10 * time.Second))
+
+	tests := []struct {
+		name          string
+		archiveErr    error
+		expectDeleted bool
+	}{
+		{
+			name:          "archived and deleted",
+			expectDeleted: true,
+		},
+		{
+			name:       "archive failed, kept",
+			archiveErr: fmt.Errorf("sink unavailable"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claim := &agentsv1alpha1.SandboxClaim{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-claim", Namespace: "default", UID: "claim-uid"},
+				Spec: agentsv1alpha1.SandboxClaimSpec{
+					TemplateName:      "test-template",
+					TTLAfterCompleted: &metav1.Duration{Duration: 5 * time.Second},
+				},
+			}
+			newStatus := &agentsv1alpha1.SandboxClaimStatus{
+				Phase:          agentsv1alpha1.SandboxClaimPhaseCompleted,
+				CompletionTime: &completionTime,
+				History:        []agentsv1alpha1.SandboxClaimHistoryEntry{{Phase: agentsv1alpha1.SandboxClaimPhaseCompleted}},
+			}
+			claim.Status = *newStatus
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(claim).
+				WithStatusSubresource(&agentsv1alpha1.SandboxClaim{}).Build()
+			archiver := &fakeArchiver{err: tt.archiveErr}
+			control := NewCommonControl(fakeClient, record.NewFakeRecorder(10), nil, nil).(*commonControl)
+			control.archiver = archiver
+
+			_, err := control.EnsureClaimCompleted(context.Background(), ClaimArgs{Claim: claim, NewStatus: newStatus})
+			got := &agentsv1alpha1.SandboxClaim{}
+			getErr := fakeClient.Get(context.Background(), client.ObjectKeyFromObject(claim), got)
+			if !tt.expectDeleted {
+				assert.ErrorContains(t, err, "sink unavailable")
+				assert.NoError(t, getErr, "claim must be kept until archived")
+				return
+			}
+			require.NoError(t, err)
+			assert.Error(t, getErr)
+			require.Len(t, archiver.records, 1)
+			archived := archiver.records[0].Claim
+			assert.Equal(t, "SandboxClaim", archived.Kind)
+			assert.Equal(t, types.UID("claim-uid"), archived.UID)
+			assert.Equal(t, "test-template", archived.Spec.TemplateName)
+			assert.Len(t, archived.Status.History, 1)
+		})
+	}
+}
+
+func TestCommonControl_EnsureClaimCompleted_TTLPolicy(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = agentsv1alpha1.AddToScheme(scheme)
+
+	cache, clientSet, err := sandboxcr.NewTestCache(t)
+	require.NoError(t, err)
+	go func() {
+		_ = cache.Run(t.Context())
+	}()
+	completionTime := metav1.NewTime(time.Now().Add(-10 * time.Second))
+
+	tests := []struct {
+		name           string
+		uid            string
+		policy         agentsv1alpha1.SandboxClaimTTLPolicy
+		expectReleased bool
+	}{
+		{
+			name: "default retains claimed sandboxes bound to the claim",
+			uid:  "ttl-uid-1",
+		},
+		{
+			name:           "delete claimed sandboxes with the claim",
+			uid:            "ttl-uid-2",
+			policy:         agentsv1alpha1.SandboxClaimTTLDelete,
+			expectReleased: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			names := []string{tt.uid + "-sbx-1", tt.uid + "-sbx-2"}
+			for _, name := range names {
+				CreateSandboxWithStatus(t, clientSet.SandboxClient, &agentsv1alpha1.Sandbox{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:        name,
+						Namespace:   "default",
+						Annotations: map[string]string{agentsv1alpha1.AnnotationOwner: tt.uid},
+						Labels: map[string]string{
+							agentsv1alpha1.LabelSandboxIsClaimed: agentsv1alpha1.True,
+							agentsv1alpha1.LabelSandboxClaimName: "test-claim",
+						},
+					},
+					Status: agentsv1alpha1.SandboxStatus{Phase: agentsv1alpha1.SandboxRunning},
+				})
+			}
+			require.Eventually(t, func() bool {
+				sandboxes, err := cache.ListSandboxWithUser(tt.uid)
+				return err == nil && len(sandboxes) == len(names)
+			}, time.Second, 10*time.Millisecond)
+
+			claim := &agentsv1alpha1.SandboxClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test-claim", Namespace: "default", UID: types.UID(tt.uid),
+					Finalizers: []string{agentsv1alpha1.SandboxClaimFinalizer},
+				},
+				Spec: agentsv1alpha1.SandboxClaimSpec{
+					TemplateName:      "test-template",
+					TTLAfterCompleted: &metav1.Duration{Duration: 5 * time.Second},
+					TTLPolicy:         tt.policy,
+				},
+			}
+			newStatus := &agentsv1alpha1.SandboxClaimStatus{
+				Phase:          agentsv1alpha1.SandboxClaimPhaseCompleted,
+				CompletionTime: &completionTime,
+			}
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(claim).Build()
+			control := NewCommonControl(fakeClient, record.NewFakeRecorder(10), clientSet, cache)
+
+			strategy, err := control.EnsureClaimCompleted(t.Context(), ClaimArgs{Claim: claim, NewStatus: newStatus})
+			require.NoError(t, err)
+			assert.Equal(t, "TTLExpired", strategy.Reason)
+			if tt.expectReleased {
+				// the finalizer is kept to release what the TTL policy left, i.e. the quarantined sandboxes
+				deleting := &agentsv1alpha1.SandboxClaim{}
+				require.NoError(t, fakeClient.Get(t.Context(), client.ObjectKeyFromObject(claim), deleting))
+				assert.NotNil(t, deleting.DeletionTimestamp)
+			} else {
+				assert.True(t, apierrors.IsNotFound(fakeClient.Get(t.Context(), client.ObjectKeyFromObject(claim), &agentsv1alpha1.SandboxClaim{})))
+			}
+			for _, name := range names {
+				sbx, err := clientSet.SandboxClient.ApiV1alpha1().Sandboxes("default").Get(t.Context(), name, metav1.GetOptions{})
+				if tt.expectReleased {
+					assert.True(t, apierrors.IsNotFound(err), "sandbox %s", name)
+					continue
+				}
+				require.NoError(t, err)
+				assert.Equal(t, agentsv1alpha1.True, sbx.Labels[agentsv1alpha1.LabelSandboxIsClaimed])
+				assert.Equal(t, "test-claim", sbx.Labels[agentsv1alpha1.LabelSandboxClaimName])
+				assert.Equal(t, tt.uid, sbx.Annotations[agentsv1alpha1.AnnotationOwner])
+			}
+		})
+	}
+}
+
+func TestCommonControl_EnsureClaimCompleted_ReplaceOnFailure(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = agentsv1alpha1.AddToScheme(scheme)
+
+	cache, clientSet, err := sandboxcr.NewTestCache(t)
+	require.NoError(t, err, "Failed to create cache")
+	sandboxClient := clientSet.SandboxClient
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		_ = cache.Run(ctx)
+	}()
+	time.Sleep(200 * time.Millisecond) // Wait for cache to start
+
+	newSandbox := func(name, owner string, phase agentsv1alpha1.SandboxPhase) *agentsv1alpha1.Sandbox {
+		return &agentsv1alpha1.Sandbox{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+				Annotations: map[string]string{
+					agentsv1alpha1.AnnotationOwner: owner,
+				},
+				Labels: map[string]string{
+					agentsv1alpha1.LabelSandboxTemplate:  "test-template",
+					agentsv1alpha1.LabelSandboxIsClaimed: "true",
+				},
+			},
+			Status: agentsv1alpha1.SandboxStatus{Phase: phase},
+		}
+	}
+	pastShutdown := metav1.NewTime(time.Now().Add(-time.Minute))
+
+	tests := []struct {
+		name              string
+		uid               string
+		replaceOnFailure  bool
+		shutdownTime      *metav1.Time
+		sandboxes         []*agentsv1alpha1.Sandbox
+		expectImmediate   bool
+		expectPhase       agentsv1alpha1.SandboxClaimPhase
+		expectClaimed     int32
+		expectReplicaLost bool
+	}{
+		{
+			name:             "one replica dead - should re-enter claiming",
+			uid:              "replace-uid-1",
+			replaceOnFailure: true,
+			sandboxes: []*agentsv1alpha1.Sandbox{
+				newSandbox("replace-sbx-1", "replace-uid-1", agentsv1alpha1.SandboxPaused),
+				newSandbox("replace-sbx-2", "replace-uid-1", agentsv1alpha1.SandboxFailed),
+			},
+			expectImmediate:   true,
+			expectPhase:       agentsv1alpha1.SandboxClaimPhaseClaiming,
+			expectClaimed:     1,
+			expectReplicaLost: true,
+		},
+		{
+			name:             "all replicas alive - should stay completed",
+			uid:              "replace-uid-2",
+			replaceOnFailure: true,
+			sandboxes: []*agentsv1alpha1.Sandbox{
+				newSandbox("replace-sbx-3", "replace-uid-2", agentsv1alpha1.SandboxPaused),
+				newSandbox("replace-sbx-4", "replace-uid-2", agentsv1alpha1.SandboxPaused),
+			},
+			expectPhase:   agentsv1alpha1.SandboxClaimPhaseCompleted,
+			expectClaimed: 2,
+		},
+		{
+			name: "replaceOnFailure disabled - should stay completed",
+			uid:  "replace-uid-3",
+			sandboxes: []*agentsv1alpha1.Sandbox{
+				newSandbox("replace-sbx-5", "replace-uid-3", agentsv1alpha1.SandboxFailed),
+			},
+			expectPhase:   agentsv1alpha1.SandboxClaimPhaseCompleted,
+			expectClaimed: 2,
+		},
+		{
+			name:             "shutdown time reached - should stay completed",
+			uid:              "replace-uid-4",
+			replaceOnFailure: true,
+			shutdownTime:     &pastShutdown,
+			sandboxes: []*agentsv1alpha1.Sandbox{
+				newSandbox("replace-sbx-6", "replace-uid-4", agentsv1alpha1.SandboxFailed),
+			},
+			expectPhase:   agentsv1alpha1.SandboxClaimPhaseCompleted,
+			expectClaimed: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, sbx := range tt.sandboxes {
+				_, err := sandboxClient.ApiV1alpha1().Sandboxes(sbx.Namespace).Create(ctx, sbx, metav1.CreateOptions{})
+				require.NoError(t, err, "Failed to create sandbox in sandboxClient")
+			}
+			time.Sleep(100 * time.Millisecond) // Wait for cache sync
+
+			claim := &agentsv1alpha1.SandboxClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-claim",
+					Namespace: "default",
+					UID:       types.UID(tt.uid),
+				},
+				Spec: agentsv1alpha1.SandboxClaimSpec{
+					TemplateName:     "test-template",
+					Replicas:         int32Ptr(2),
+					ReplaceOnFailure: tt.replaceOnFailure,
+					ShutdownTime:     tt.shutdownTime,
+				},
+			}
+			completionTime := metav1.Now()
+			newStatus := &agentsv1alpha1.SandboxClaimStatus{
+				Phase:           agentsv1alpha1.SandboxClaimPhaseCompleted,
+				ClaimedReplicas: 2,
+				CompletionTime:  &completionTime,
+			}
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(claim).Build()
+			control := NewCommonControl(fakeClient, record.NewFakeRecorder(10), clientSet, cache)
+
+			strategy, err := control.EnsureClaimCompleted(ctx, ClaimArgs{Claim: claim, NewStatus: newStatus})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectImmediate, strategy.Immediate, "Immediate mismatch")
+			assert.Equal(t, tt.expectPhase, newStatus.Phase)
+			assert.Equal(t, tt.expectClaimed, newStatus.ClaimedReplicas)
+			lost := conditions.Get(newStatus.Conditions, string(agentsv1alpha1.SandboxClaimConditionReplicaLost))
+			assert.Equal(t, tt.expectReplicaLost, lost != nil)
+			if tt.expectReplicaLost {
+				assert.Nil(t, newStatus.CompletionTime)
+				assert.NotNil(t, newStatus.ClaimStartTime)
+				assert.True(t, conditions.IsFalse(newStatus.Conditions, string(agentsv1alpha1.SandboxClaimConditionCompleted)))
+			}
+		})
+	}
+}
+
+func TestCommonControl_EnsureClaimCompleted_ReleaseOnTimeout(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = agentsv1alpha1.AddToScheme(scheme)
+
+	cache, clientSet, err := sandboxcr.NewTestCache(t)
+	require.NoError(t, err, "Failed to create cache")
+	sandboxClient := clientSet.SandboxClient
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		_ = cache.Run(ctx)
+	}()
+	time.Sleep(200 * time.Millisecond) // Wait for cache to start
+
+	newSandbox := func(name, owner string) *agentsv1alpha1.Sandbox {
+		return &agentsv1alpha1.Sandbox{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Namespace:   "default",
+				Annotations: map[string]string{agentsv1alpha1.AnnotationOwner: owner},
+				Labels: map[string]string{
+					agentsv1alpha1.LabelSandboxTemplate:  "test-template",
+					agentsv1alpha1.LabelSandboxIsClaimed: "true",
+				},
+			},
+			Status: agentsv1alpha1.SandboxStatus{Phase: agentsv1alpha1.SandboxRunning},
+		}
+	}
+	timedOut := metav1.Condition{
+		Type:   string(agentsv1alpha1.SandboxClaimConditionTimedOut),
+		Status: metav1.ConditionTrue,
+		Reason: "ClaimTimeoutReached",
+	}
+	released := metav1.Condition{
+		Type:   string(agentsv1alpha1.SandboxClaimConditionReleased),
+		Status: metav1.ConditionTrue,
+		Reason: "ClaimTimeoutReached",
+	}
+	canceled := metav1.Condition{
+		Type:   string(agentsv1alpha1.SandboxClaimConditionCanceled),
+		Status: metav1.ConditionTrue,
+		Reason: "ClaimCanceled",
+	}
+
+	tests := []struct {
+		name            string
+		uid             string
+		onTimeout       agentsv1alpha1.SandboxClaimTimeoutPolicy
+		conditions      []metav1.Condition
+		expectClaimed   int32
+		expectReleased  bool
+		expectRemaining int
+	}{
+		{
+			name:            "release claimed on timeout",
+			uid:             "release-uid-1",
+			onTimeout:       agentsv1alpha1.SandboxClaimTimeoutReleaseClaimed,
+			conditions:      []metav1.Condition{timedOut},
+			expectClaimed:   0,
+			expectReleased:  true,
+			expectRemaining: 0,
+		},
+		{
+			name:            "release claimed on timeout with Fail",
+			uid:             "release-uid-7",
+			onTimeout:       agentsv1alpha1.SandboxClaimTimeoutFail,
+			conditions:      []metav1.Condition{timedOut},
+			expectClaimed:   0,
+			expectReleased:  true,
+			expectRemaining: 0,
+		},
+		{
+			name:            "keep claimed on timeout",
+			uid:             "release-uid-2",
+			onTimeout:       agentsv1alpha1.SandboxClaimTimeoutKeepClaimed,
+			conditions:      []metav1.Condition{timedOut},
+			expectClaimed:   2,
+			expectRemaining: 2,
+		},
+		{
+			name:            "not timed out - should keep claimed",
+			uid:             "release-uid-3",
+			onTimeout:       agentsv1alpha1.SandboxClaimTimeoutReleaseClaimed,
+			expectClaimed:   2,
+			expectRemaining: 2,
+		},
+		{
+			name:            "already released - should not release again",
+			uid:             "release-uid-4",
+			onTimeout:       agentsv1alpha1.SandboxClaimTimeoutReleaseClaimed,
+			conditions:      []metav1.Condition{timedOut, released},
+			expectClaimed:   2,
+			expectReleased:  true,
+			expectRemaining: 2,
+		},
+		{
+			name:            "release claimed on cancel",
+			uid:             "release-uid-5",
+			onTimeout:       agentsv1alpha1.SandboxClaimTimeoutReleaseClaimed,
+			conditions:      []metav1.Condition{canceled},
+			expectClaimed:   0,
+			expectReleased:  true,
+			expectRemaining: 0,
+		},
+		{
+			name:            "keep claimed on cancel",
+			uid:             "release-uid-6",
+			onTimeout:       agentsv1alpha1.SandboxClaimTimeoutKeepClaimed,
+			conditions:      []metav1.Condition{canceled},
+			expectClaimed:   2,
+			expectRemaining: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 0; i < 2; i++ {
+				sbx := newSandbox(fmt.Sprintf("%s-sbx-%d", tt.uid, i), tt.uid)
+				_, err := sandboxClient.ApiV1alpha1().Sandboxes(sbx.Namespace).Create(ctx, sbx, metav1.CreateOptions{})
+				require.NoError(t, err, "Failed to create sandbox in sandboxClient")
+			}
+			time.Sleep(100 * time.Millisecond) // Wait for cache sync
+
+			claim := &agentsv1alpha1.SandboxClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-claim",
+					Namespace: "default",
+					UID:       types.UID(tt.uid),
+				},
+				Spec: agentsv1alpha1.SandboxClaimSpec{
+					TemplateName: "test-template",
+					Replicas:     int32Ptr(3),
+					OnTimeout:    tt.onTimeout,
+				},
+			}
+			newStatus := &agentsv1alpha1.SandboxClaimStatus{
+				Phase:           agentsv1alpha1.SandboxClaimPhaseCompleted,
+				ClaimedReplicas: 2,
+				Conditions:      tt.conditions,
+			}
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(claim).Build()
+			control := NewCommonControl(fakeClient, record.NewFakeRecorder(10), clientSet, cache)
+
+			_, err := control.EnsureClaimCompleted(ctx, ClaimArgs{Claim: claim, NewStatus: newStatus})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectClaimed, newStatus.ClaimedReplicas)
+			assert.Equal(t, tt.expectReleased, conditions.IsTrue(newStatus.Conditions, string(agentsv1alpha1.SandboxClaimConditionReleased)))
+
+			list, err := sandboxClient.ApiV1alpha1().Sandboxes("default").List(ctx, metav1.ListOptions{})
+			require.NoError(t, err)
+			remaining := 0
+			for _, sbx := range list.Items {
+				if sbx.Annotations[agentsv1alpha1.AnnotationOwner] == tt.uid {
+					remaining++
+				}
+			}
+			assert.Equal(t, tt.expectRemaining, remaining)
+		})
+	}
+}
+
+func TestCommonControl_EnsureClaimCompleted_Lease(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = agentsv1alpha1.AddToScheme(scheme)
+
+	cache, clientSet, err := sandboxcr.NewTestCache(t)
+	require.NoError(t, err, "Failed to create cache")
+	sandboxClient := clientSet.SandboxClient
+	ctx := context.Background()
+
+	tests := []struct {
+		name             string
+		uid              string
+		completedAgo     time.Duration
+		renewedAgo       time.Duration
+		expectReleased   bool
+		expectExpireIn   time.Duration
+		expectStrategyIn time.Duration
+	}{
+		{
+			name:             "lease not expired",
+			uid:              "lease-uid-1",
+			completedAgo:     10 * time.Minute,
+			expectExpireIn:   20 * time.Minute,
+			expectStrategyIn: 20 * time.Minute,
+		},
+		{
+			name:           "lease expired",
+			uid:            "lease-uid-2",
+			completedAgo:   time.Hour,
+			expectReleased: true,
+		},
+		{
+			name:             "lease renewed before expiry",
+			uid:              "lease-uid-3",
+			completedAgo:     time.Hour,
+			renewedAgo:       5 * time.Minute,
+			expectExpireIn:   25 * time.Minute,
+			expectStrategyIn: 25 * time.Minute,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 0; i < 2; i++ {
+				sbx := &agentsv1alpha1.Sandbox{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:        fmt.Sprintf("%s-sbx-%d", tt.uid, i),
+						Namespace:   "default",
+						Annotations: map[string]string{agentsv1alpha1.AnnotationOwner: tt.uid},
+						Labels: map[string]string{
+							agentsv1alpha1.LabelSandboxTemplate:  "test-template",
+							agentsv1alpha1.LabelSandboxIsClaimed: "true",
+						},
+					},
+					Status: agentsv1alpha1.SandboxStatus{Phase: agentsv1alpha1.SandboxRunning},
+				}
+				_, err := sandboxClient.ApiV1alpha1().Sandboxes(sbx.Namespace).Create(ctx, sbx, metav1.CreateOptions{})
+				require.NoError(t, err)
+			}
+			require.Eventually(t, func() bool {
+				sandboxes, err := cache.ListSandboxWithUser(tt.uid)
+				return err == nil && len(sandboxes) == 2
+			}, time.Second, 10*time.Millisecond)
+
+			now := time.Now()
+			claim := &agentsv1alpha1.SandboxClaim{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-claim", Namespace: "default", UID: types.UID(tt.uid)},
+				Spec: agentsv1alpha1.SandboxClaimSpec{
+					TemplateName:  "test-template",
+					Replicas:      int32Ptr(2),
+					LeaseDuration: &metav1.Duration{Duration: 30 * time.Minute},
+				},
+			}
+			if tt.renewedAgo > 0 {
+				claim.Annotations = map[string]string{
+					agentsv1alpha1.AnnotationClaimLeaseRenewTime: now.Add(-tt.renewedAgo).Format(time.RFC3339),
+				}
+			}
+			completion := metav1.NewTime(now.Add(-tt.completedAgo))
+			newStatus := &agentsv1alpha1.SandboxClaimStatus{
+				Phase:           agentsv1alpha1.SandboxClaimPhaseCompleted,
+				ClaimedReplicas: 2,
+				CompletionTime:  &completion,
+			}
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(claim).Build()
+			recorder := record.NewFakeRecorder(10)
+			control := NewCommonControl(fakeClient, recorder, clientSet, cache)
+
+			strategy, err := control.EnsureClaimCompleted(ctx, ClaimArgs{Claim: claim, NewStatus: newStatus})
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectReleased, conditions.IsTrue(newStatus.Conditions, string(agentsv1alpha1.SandboxClaimConditionLeaseExpired)))
+			assert.Equal(t, tt.expectReleased, conditions.IsTrue(newStatus.Conditions, string(agentsv1alpha1.SandboxClaimConditionReleased)))
+
+			list, err := sandboxClient.ApiV1alpha1().Sandboxes("default").List(ctx, metav1.ListOptions{})
+			require.NoError(t, err)
+			remaining := int32(0)
+			for _, sbx := range list.Items {
+				if sbx.Annotations[agentsv1alpha1.AnnotationOwner] == tt.uid {
+					remaining++
+				}
+			}
+			if tt.expectReleased {
+				assert.Equal(t, int32(0), remaining)
+				assert.Equal(t, int32(0), newStatus.ClaimedReplicas)
+				assert.Nil(t, newStatus.LeaseExpireTime)
+				assert.Equal(t, "Released 2 claimed sandbox(es) as the lease expired without renewal", newStatus.Message)
+				assert.Contains(t, <-recorder.Events, "ReleasedOnLeaseExpired")
+				return
+			}
+			assert.Equal(t, int32(2), remaining)
+			assert.Len(t, newStatus.ClaimedSandboxes, 2)
+			require.NotNil(t, newStatus.LeaseExpireTime)
+			assert.WithinDuration(t, now.Add(tt.expectExpireIn), newStatus.LeaseExpireTime.Time, time.Second)
+			assert.Equal(t, "WaitingForLeaseExpiry", strategy.Reason)
+			assert.InDelta(t, tt.expectStrategyIn.Seconds(), strategy.After.Seconds(), 1)
+		})
+	}
+}
+
+func TestCommonControl_EnsureClaimCompleted_ResultSecret(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = agentsv1alpha1.AddToScheme(scheme)
+
+	cache, clientSet, err := sandboxcr.NewTestCache(t)
+	require.NoError(t, err, "Failed to create cache")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		_ = cache.Run(ctx)
+	}()
+	time.Sleep(200 * time.Millisecond) // Wait for cache to start
+
+	newClaim := func(name string) *agentsv1alpha1.SandboxClaim {
+		return &agentsv1alpha1.SandboxClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+				UID:       types.UID(name + "-uid"),
+			},
+			Spec: agentsv1alpha1.SandboxClaimSpec{
+				TemplateName:     "test-template",
+				Replicas:         int32Ptr(2),
+				ResultSecretName: name + "-result",
+			},
+		}
+	}
+
+	tests := []struct {
+		name          string
+		claim         *agentsv1alpha1.SandboxClaim
+		existing      func(claim *agentsv1alpha1.SandboxClaim) *corev1.Secret
+		expectWritten bool
+	}{
+		{
+			name:          "create result secret",
+			claim:         newClaim("result-create"),
+			expectWritten: true,
+		},
+		{
+			name:  "update stale result secret owned by claim",
+			claim: newClaim("result-update"),
+			existing: func(claim *agentsv1alpha1.SandboxClaim) *corev1.Secret {
+				return &corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:            claim.Spec.ResultSecretName,
+						Namespace:       claim.Namespace,
+						OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(claim, agentsv1alpha1.GroupVersion.WithKind("SandboxClaim"))},
+					},
+					Data: map[string][]byte{agentsv1alpha1.SandboxClaimResultSandboxIDsKey: []byte("stale")},
+				}
+			},
+			expectWritten: true,
+		},
+		{
+			name:  "secret not owned by claim - should not overwrite",
+			claim: newClaim("result-conflict"),
+			existing: func(claim *agentsv1alpha1.SandboxClaim) *corev1.Secret {
+				return &corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      claim.Spec.ResultSecretName,
+						Namespace: claim.Namespace,
+					},
+					Data: map[string][]byte{"password": []byte("secret")},
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 0; i < 2; i++ {
+				sbx := &agentsv1alpha1.Sandbox{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      fmt.Sprintf("%s-sbx-%d", tt.claim.Name, i),
+						Namespace: "default",
+						Annotations: map[string]string{
+							agentsv1alpha1.AnnotationOwner:              string(tt.claim.UID),
+							agentsv1alpha1.AnnotationRuntimeURL:         fmt.Sprintf("http://10.0.0.%d:49983", i),
+							agentsv1alpha1.AnnotationRuntimeAccessToken: fmt.Sprintf("token-%d", i),
+						},
+						Labels: map[string]string{
+							agentsv1alpha1.LabelSandboxTemplate:  "test-template",
+							agentsv1alpha1.LabelSandboxIsClaimed: "true",
+						},
+					},
+					Status: agentsv1alpha1.SandboxStatus{Phase: agentsv1alpha1.SandboxRunning},
+				}
+				_, err := clientSet.SandboxClient.ApiV1alpha1().Sandboxes(sbx.Namespace).Create(ctx, sbx, metav1.CreateOptions{})
+				require.NoError(t, err)
+			}
+			time.Sleep(100 * time.Millisecond) // Wait for cache sync
+
+			var existing *corev1.Secret
+			if tt.existing != nil {
+				existing = tt.existing(tt.claim)
+				_, err := clientSet.K8sClient.CoreV1().Secrets(existing.Namespace).Create(ctx, existing, metav1.CreateOptions{})
+				require.NoError(t, err)
+			}
+
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(tt.claim).Build()
+			control := NewCommonControl(fakeClient, record.NewFakeRecorder(10), clientSet, cache)
+			newStatus := &agentsv1alpha1.SandboxClaimStatus{
+				Phase:           agentsv1alpha1.SandboxClaimPhaseCompleted,
+				ClaimedReplicas: 2,
+			}
+			_, err := control.EnsureClaimCompleted(ctx, ClaimArgs{Claim: tt.claim, NewStatus: newStatus})
+			require.NoError(t, err)
+
+			secret, err := clientSet.K8sClient.CoreV1().Secrets("default").Get(ctx, tt.claim.Spec.ResultSecretName, metav1.GetOptions{})
+			require.NoError(t, err)
+			if !tt.expectWritten {
+				assert.Equal(t, existing.Data, secret.Data)
+				return
+			}
+			assert.True(t, metav1.IsControlledBy(secret, tt.claim))
+			assert.Equal(t, fmt.Sprintf("default--%[1]s-sbx-0\ndefault--%[1]s-sbx-1", tt.claim.Name),
+				string(secret.Data[agentsv1alpha1.SandboxClaimResultSandboxIDsKey]))
+			var results []agentsv1alpha1.SandboxClaimResultSandbox
+			require.NoError(t, json.Unmarshal(secret.Data[agentsv1alpha1.SandboxClaimResultSandboxesKey], &results))
+			require.Len(t, results, 2)
+			assert.Equal(t, agentsv1alpha1.SandboxClaimResultSandbox{
+				SandboxID:   fmt.Sprintf("default--%s-sbx-1", tt.claim.Name),
+				Name:        fmt.Sprintf("%s-sbx-1", tt.claim.Name),
+				RuntimeURL:  "http://10.0.0.1:49983",
+				AccessToken: "token-1",
+			}, results[1])
+		})
+	}
+}
+
+func TestCommonControl_buildClaimOptions(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = agentsv1alpha1.AddToScheme(scheme)
 
 	fakeClient := fake.NewClientBuilder().
 		WithScheme(scheme).
@@ -693,6 +1886,7 @@ func TestCommonControl_buildClaimOptions(t *testing.T) {
 			validate: func(t *testing.T, opts infra.ClaimSandboxOptions) {
 				assert.Equal(t, "test-uid-123", opts.User, "User mismatch")
 				assert.Equal(t, "test-template", opts.Template, "Template mismatch")
+				assert.Empty(t, opts.Revision, "Revision should be empty when not specified")
 				require.NotNil(t, opts.Modifier, "Modifier should not be nil")
 				assert.Nil(t, opts.InplaceUpdate, "InplaceUpdate should be nil when not specified")
 
@@ -712,9 +1906,41 @@ func TestCommonControl_buildClaimOptions(t *testing.T) {
 
 				// Verify modifier set the claim name label correctly
 				assert.Equal(t, "test-claim", mockSandbox.Labels[agentsv1alpha1.LabelSandboxClaimName], "LabelSandboxClaimName mismatch")
+				assert.Equal(t, "test-uid-123", mockSandbox.Labels[agentsv1alpha1.LabelSandboxClaimUID], "LabelSandboxClaimUID mismatch")
 				assert.Equal(t, "existing-value", mockSandbox.Labels["existing-label"], "existing-label should be preserved")
 			},
 		},
+		{
+			name: "claim with regions",
+			claim: &agentsv1alpha1.SandboxClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-claim",
+					Namespace: "default",
+					UID:       "test-uid-regions",
+				},
+				Spec: agentsv1alpha1.SandboxClaimSpec{
+					TemplateName: "test-template",
+					Regions: &agentsv1alpha1.RegionPolicy{
+						Preferred: []string{"us-east"},
+						Forbidden: []string{"eu-west"},
+						Fallback:  agentsv1alpha1.RegionFallbackNone,
+					},
+				},
+			},
+			sandboxSet: &agentsv1alpha1.SandboxSet{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-template",
+					Namespace: "default",
+				},
+			},
+			validate: func(t *testing.T, opts infra.ClaimSandboxOptions) {
+				assert.Equal(t, &agentsv1alpha1.RegionPolicy{
+					Preferred: []string{"us-east"},
+					Forbidden: []string{"eu-west"},
+					Fallback:  agentsv1alpha1.RegionFallbackNone,
+				}, opts.Regions)
+			},
+		},
 		{
 			name: "claim with labels and annotations",
 			claim: &agentsv1alpha1.SandboxClaim{
@@ -1025,6 +2251,96 @@ func TestCommonControl_buildClaimOptions(t *testing.T) {
 				assert.Nil(t, opts.InitRuntime, "InitRuntime should be nil when SkipInitRuntime is true, even with EnvVars")
 			},
 		},
+		{
+			name: "float to the latest template revision",
+			claim: &agentsv1alpha1.SandboxClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-claim-latest",
+					Namespace: "default",
+					UID:       "test-uid-latest",
+				},
+				Spec: agentsv1alpha1.SandboxClaimSpec{
+					TemplateName:     "test-template",
+					TemplateRevision: agentsv1alpha1.SandboxClaimTemplateRevisionLatest,
+				},
+			},
+			sandboxSet: &agentsv1alpha1.SandboxSet{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-template",
+					Namespace: "default",
+				},
+				Status: agentsv1alpha1.SandboxSetStatus{UpdateRevision: "rev2"},
+			},
+			validate: func(t *testing.T, opts infra.ClaimSandboxOptions) {
+				assert.Equal(t, "rev2", opts.Revision)
+			},
+		},
+		{
+			name: "pin to a template revision",
+			claim: &agentsv1alpha1.SandboxClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-claim-pinned",
+					Namespace: "default",
+					UID:       "test-uid-pinned",
+				},
+				Spec: agentsv1alpha1.SandboxClaimSpec{
+					TemplateName:     "test-template",
+					TemplateRevision: "rev1",
+				},
+			},
+			sandboxSet: &agentsv1alpha1.SandboxSet{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-template",
+					Namespace: "default",
+				},
+				Status: agentsv1alpha1.SandboxSetStatus{UpdateRevision: "rev2"},
+			},
+			validate: func(t *testing.T, opts infra.ClaimSandboxOptions) {
+				assert.Equal(t, "rev1", opts.Revision)
+			},
+		},
+		{
+			name: "claim with selector",
+			claim: &agentsv1alpha1.SandboxClaim{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-claim", Namespace: "default", UID: "test-uid"},
+				Spec: agentsv1alpha1.SandboxClaimSpec{
+					TemplateName: "test-template",
+					Selector: &metav1.LabelSelector{
+						MatchLabels: map[string]string{"gpu": "true"},
+						MatchExpressions: []metav1.LabelSelectorRequirement{
+							{Key: "zone", Operator: metav1.LabelSelectorOpIn, Values: []string{"us-east-1a"}},
+						},
+					},
+				},
+			},
+			sandboxSet: &agentsv1alpha1.SandboxSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-template", Namespace: "default"},
+			},
+			validate: func(t *testing.T, opts infra.ClaimSandboxOptions) {
+				require.NotNil(t, opts.Selector)
+				assert.True(t, opts.Selector.Matches(labels.Set{"gpu": "true", "zone": "us-east-1a"}))
+				assert.False(t, opts.Selector.Matches(labels.Set{"gpu": "true", "zone": "us-east-1b"}))
+				assert.False(t, opts.Selector.Matches(labels.Set{"zone": "us-east-1a"}))
+			},
+		},
+		{
+			name: "claim with invalid selector",
+			claim: &agentsv1alpha1.SandboxClaim{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-claim", Namespace: "default", UID: "test-uid"},
+				Spec: agentsv1alpha1.SandboxClaimSpec{
+					TemplateName: "test-template",
+					Selector: &metav1.LabelSelector{
+						MatchExpressions: []metav1.LabelSelectorRequirement{
+							{Key: "zone", Operator: "Unknown"},
+						},
+					},
+				},
+			},
+			sandboxSet: &agentsv1alpha1.SandboxSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-template", Namespace: "default"},
+			},
+			expectError: true,
+		},
 	}
 
 	for _, tt := range tests {
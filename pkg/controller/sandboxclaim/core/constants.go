@@ -32,6 +32,23 @@ const (
 	// ClaimRetryInterval is the interval between claim retries during the Claiming phase.
 	// This balances responsiveness with API server load.
 	ClaimRetryInterval = 2 * time.Second
+
+	// DefaultMaxRetryBackoff is the maximum delay between the retries of claims with a retry policy without maxBackoff.
+	DefaultMaxRetryBackoff = time.Minute
+
+	// ClaimQueueRecheckInterval is the interval between evaluations of the claim queue for claims in the Queued phase,
+	// which only read the informer cache.
+	ClaimQueueRecheckInterval = 2 * time.Second
+
+	// NotificationRetryInterval is the interval between the retries of a failed callback of spec.notifications.
+	NotificationRetryInterval = 30 * time.Second
+
+	// DefaultNotificationTimeout is the timeout of a callback of spec.notifications.
+	DefaultNotificationTimeout = 10 * time.Second
+
+	// claimTimeoutWarningFraction is the last fraction of the claim timeout, e.g. the last 1/4, in which a claim
+	// still waiting for sandboxes is warned of the approaching timeout.
+	claimTimeoutWarningFraction = 4
 )
 
 const (
@@ -0,0 +1,58 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+	"github.com/openkruise/agents/pkg/sandbox-manager/infra/sandboxcr"
+	"github.com/openkruise/agents/pkg/utils/requeue"
+)
+
+// EnsureClaimDryRun reports how many sandboxes the dry-run claim could claim from its SandboxSet right now into
+// status.dryRun and completes the claim, without locking or labeling any sandbox.
+func (c *commonControl) EnsureClaimDryRun(ctx context.Context, args ClaimArgs) (RequeueStrategy, error) {
+	log := logf.FromContext(ctx)
+	claim, sandboxSet, status := args.Claim, args.SandboxSet, args.NewStatus
+
+	// the options of a real claim select the same sandboxes, and fail the dry run on the same invalid specs
+	opts, err := c.buildClaimOptions(ctx, claim, sandboxSet)
+	if err != nil {
+		return requeue.NoRequeue(), fmt.Errorf("failed to build claim options: %w", err)
+	}
+	available, err := sandboxcr.CountAvailableSandboxes(c.cache, opts)
+	if err != nil {
+		return requeue.NoRequeue(), fmt.Errorf("failed to count available sandboxes: %w", err)
+	}
+	desired := getDesiredReplicas(claim)
+	status.DryRun = &agentsv1alpha1.SandboxClaimDryRunStatus{
+		AvailableReplicas: available,
+		ClaimableReplicas: min(available, desired),
+		CheckTime:         metav1.Now(),
+	}
+	message := fmt.Sprintf("%d/%d replica(s) claimable from %d available sandbox(es) of SandboxSet %s",
+		status.DryRun.ClaimableReplicas, desired, available, sandboxSet.Name)
+	log.Info("Checked sandboxes claimable by dry run", "available", available, "desired", desired)
+	c.recorder.Event(claim, "Normal", "DryRun", message)
+	TransitionToCompleted(status, "DryRun", message)
+	return requeue.NoRequeue().WithReason("DryRunCompleted"), nil
+}
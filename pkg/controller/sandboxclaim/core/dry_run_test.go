@@ -0,0 +1,148 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+	"github.com/openkruise/agents/pkg/sandbox-manager/infra/sandboxcr"
+	"github.com/openkruise/agents/pkg/utils/conditions"
+)
+
+func TestCalculateClaimStatus_DryRun(t *testing.T) {
+	claim := &agentsv1alpha1.SandboxClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "probe", Namespace: "default"},
+		Spec: agentsv1alpha1.SandboxClaimSpec{
+			TemplateName:     "pool",
+			Replicas:         int32Ptr(2),
+			DryRun:           true,
+			RequiresApproval: true,
+		},
+	}
+	newStatus, shouldRequeue := CalculateClaimStatus(ClaimArgs{
+		Claim:      claim,
+		SandboxSet: &agentsv1alpha1.SandboxSet{ObjectMeta: metav1.ObjectMeta{Name: "pool", Namespace: "default"}},
+		NewStatus:  &agentsv1alpha1.SandboxClaimStatus{},
+	})
+	assert.False(t, shouldRequeue)
+	assert.Equal(t, agentsv1alpha1.SandboxClaimPhaseClaiming, newStatus.Phase, "a dry-run claim does not wait for approval")
+	assert.NotNil(t, newStatus.ClaimStartTime)
+}
+
+func TestCommonControl_EnsureClaimDryRun(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = agentsv1alpha1.AddToScheme(scheme)
+
+	cache, clientSet, err := sandboxcr.NewTestCache(t)
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		_ = cache.Run(ctx)
+	}()
+	time.Sleep(200 * time.Millisecond) // Wait for cache to start
+
+	sandboxSet := &agentsv1alpha1.SandboxSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "dry-run-template", Namespace: "default", UID: "dry-run-template-uid"},
+	}
+	for i := range 3 {
+		CreateSandboxWithStatus(t, clientSet.SandboxClient, &agentsv1alpha1.Sandbox{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              fmt.Sprintf("dry-run-sbx-%d", i),
+				Namespace:         "default",
+				CreationTimestamp: metav1.Now(),
+				Labels:            map[string]string{agentsv1alpha1.LabelSandboxTemplate: sandboxSet.Name},
+				OwnerReferences:   []metav1.OwnerReference{*metav1.NewControllerRef(sandboxSet, agentsv1alpha1.SandboxSetControllerKind)},
+			},
+			Status: agentsv1alpha1.SandboxStatus{
+				Phase: agentsv1alpha1.SandboxRunning,
+				Conditions: []metav1.Condition{{
+					Type:   string(agentsv1alpha1.SandboxConditionReady),
+					Status: metav1.ConditionTrue,
+				}},
+				PodInfo: agentsv1alpha1.PodInfo{PodIP: fmt.Sprintf("1.2.3.%d", i)},
+			},
+		})
+	}
+	time.Sleep(100 * time.Millisecond) // Wait for cache sync
+
+	tests := []struct {
+		name            string
+		replicas        int32
+		expectClaimable int32
+		expectMessage   string
+	}{
+		{
+			name:            "enough available sandboxes",
+			replicas:        2,
+			expectClaimable: 2,
+			expectMessage:   "2/2 replica(s) claimable from 3 available sandbox(es) of SandboxSet dry-run-template",
+		},
+		{
+			name:            "short of available sandboxes",
+			replicas:        5,
+			expectClaimable: 3,
+			expectMessage:   "3/5 replica(s) claimable from 3 available sandbox(es) of SandboxSet dry-run-template",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claim := &agentsv1alpha1.SandboxClaim{
+				ObjectMeta: metav1.ObjectMeta{Name: "dry-run-claim", Namespace: "default", UID: "dry-run-uid"},
+				Spec: agentsv1alpha1.SandboxClaimSpec{
+					TemplateName: sandboxSet.Name,
+					Replicas:     int32Ptr(tt.replicas),
+					DryRun:       true,
+				},
+			}
+			newStatus := &agentsv1alpha1.SandboxClaimStatus{Phase: agentsv1alpha1.SandboxClaimPhaseClaiming}
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(claim, sandboxSet).Build()
+			control := NewCommonControl(fakeClient, record.NewFakeRecorder(10), clientSet, cache)
+
+			strategy, err := control.EnsureClaimDryRun(ctx, ClaimArgs{Claim: claim, SandboxSet: sandboxSet, NewStatus: newStatus})
+			require.NoError(t, err)
+			assert.Equal(t, "DryRunCompleted", strategy.Reason)
+			require.NotNil(t, newStatus.DryRun)
+			assert.Equal(t, int32(3), newStatus.DryRun.AvailableReplicas)
+			assert.Equal(t, tt.expectClaimable, newStatus.DryRun.ClaimableReplicas)
+			assert.Equal(t, agentsv1alpha1.SandboxClaimPhaseCompleted, newStatus.Phase)
+			assert.Equal(t, int32(0), newStatus.ClaimedReplicas)
+			completed := conditions.Get(newStatus.Conditions, string(agentsv1alpha1.SandboxClaimConditionCompleted))
+			require.NotNil(t, completed)
+			assert.Equal(t, "DryRun", completed.Reason)
+			assert.Equal(t, tt.expectMessage, completed.Message)
+
+			SetProgressConditions(claim, newStatus)
+			assert.Nil(t, conditions.Get(newStatus.Conditions, string(agentsv1alpha1.SandboxClaimConditionReplicasReady)))
+
+			claimed, err := cache.ListSandboxWithUser(string(claim.UID))
+			require.NoError(t, err)
+			assert.Empty(t, claimed, "a dry run claims nothing")
+		})
+	}
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	intstrutil "k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+)
+
+// workloadClassNormal is the metrics label of claims without a workload class
+const workloadClassNormal = "Normal"
+
+var (
+	// classClaimedSandboxesTotal counts the sandboxes claimed from each pool by the claims of each workload class
+	classClaimedSandboxesTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "sandboxclaim_class_claimed_sandboxes_total",
+			Help: "Total number of sandboxes claimed from the pool by SandboxClaims, by workload class",
+		},
+		[]string{"namespace", "pool", "class"},
+	)
+	// classReservedSandboxes is the number of available sandboxes of each pool reserved for interactive claims
+	classReservedSandboxes = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "sandboxclaim_interactive_reserved_sandboxes",
+			Help: "Number of available sandboxes of the pool reserved for SandboxClaims of the Interactive workload class",
+		},
+		[]string{"namespace", "pool"},
+	)
+	// classThrottledTotal counts the claiming attempts of batch claims held back by the interactive reservation
+	classThrottledTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "sandboxclaim_class_throttled_total",
+			Help: "Total number of claiming attempts held back by the interactive reservation of the pool, by workload class",
+		},
+		[]string{"namespace", "pool", "class"},
+	)
+)
+
+func init() {
+	metrics.Registry.MustRegister(classClaimedSandboxesTotal, classReservedSandboxes, classThrottledTotal)
+}
+
+// ClaimWorkloadClass returns the workload class of the claim, set on the claim itself or inherited from its pool.
+func ClaimWorkloadClass(claim *agentsv1alpha1.SandboxClaim, sandboxSet *agentsv1alpha1.SandboxSet) string {
+	if class, ok := claim.Annotations[agentsv1alpha1.AnnotationWorkloadClass]; ok {
+		return class
+	}
+	if sandboxSet == nil {
+		return ""
+	}
+	return sandboxSet.Annotations[agentsv1alpha1.AnnotationWorkloadClass]
+}
+
+func workloadClassLabel(class string) string {
+	if class == "" {
+		return workloadClassNormal
+	}
+	return class
+}
+
+// fairShareAllowance returns how many of the available sandboxes of the pool the claim of the class may take, and how
+// many of them are reserved for interactive claims. Only batch claims are limited, the others may take all.
+func fairShareAllowance(sandboxSet *agentsv1alpha1.SandboxSet, class string, available int) (allowed, reserved int, limited bool) {
+	if sandboxSet.Spec.InteractiveReserve == nil {
+		return available, 0, false
+	}
+	reserved, err := intstrutil.GetScaledValueFromIntOrPercent(sandboxSet.Spec.InteractiveReserve, available, true)
+	if err != nil || reserved < 0 {
+		// rejected by the webhook, just in case
+		reserved = 0
+	}
+	reserved = min(reserved, available)
+	classReservedSandboxes.WithLabelValues(sandboxSet.Namespace, sandboxSet.Name).Set(float64(reserved))
+	if class != agentsv1alpha1.WorkloadClassBatch {
+		return available, reserved, false
+	}
+	return available - reserved, reserved, true
+}
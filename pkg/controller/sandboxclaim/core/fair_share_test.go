@@ -0,0 +1,150 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+)
+
+func TestClaimWorkloadClass(t *testing.T) {
+	newSandboxSet := func(class string) *agentsv1alpha1.SandboxSet {
+		return &agentsv1alpha1.SandboxSet{ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{agentsv1alpha1.AnnotationWorkloadClass: class},
+		}}
+	}
+	newClaim := func(class string) *agentsv1alpha1.SandboxClaim {
+		claim := &agentsv1alpha1.SandboxClaim{}
+		if class != "" {
+			claim.Annotations = map[string]string{agentsv1alpha1.AnnotationWorkloadClass: class}
+		}
+		return claim
+	}
+	tests := []struct {
+		name       string
+		claim      *agentsv1alpha1.SandboxClaim
+		sandboxSet *agentsv1alpha1.SandboxSet
+		expected   string
+	}{
+		{
+			name:       "inherited from pool",
+			claim:      newClaim(""),
+			sandboxSet: newSandboxSet(agentsv1alpha1.WorkloadClassBatch),
+			expected:   agentsv1alpha1.WorkloadClassBatch,
+		},
+		{
+			name:       "claim overrides pool",
+			claim:      newClaim(agentsv1alpha1.WorkloadClassInteractive),
+			sandboxSet: newSandboxSet(agentsv1alpha1.WorkloadClassBatch),
+			expected:   agentsv1alpha1.WorkloadClassInteractive,
+		},
+		{
+			name:     "claim without pool",
+			claim:    newClaim(agentsv1alpha1.WorkloadClassBatch),
+			expected: agentsv1alpha1.WorkloadClassBatch,
+		},
+		{
+			name:     "unclassified",
+			claim:    newClaim(""),
+			expected: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ClaimWorkloadClass(tt.claim, tt.sandboxSet))
+		})
+	}
+}
+
+func TestFairShareAllowance(t *testing.T) {
+	tests := []struct {
+		name            string
+		reserve         *intstr.IntOrString
+		class           string
+		available       int
+		expectAllowed   int
+		expectReserved  int
+		expectedLimited bool
+	}{
+		{
+			name:          "no reservation",
+			class:         agentsv1alpha1.WorkloadClassBatch,
+			available:     10,
+			expectAllowed: 10,
+		},
+		{
+			name:            "batch takes beyond percentage reservation",
+			reserve:         ptr.To(intstr.FromString("25%")),
+			class:           agentsv1alpha1.WorkloadClassBatch,
+			available:       10,
+			expectAllowed:   7,
+			expectReserved:  3,
+			expectedLimited: true,
+		},
+		{
+			name:            "percentage reservation keeps the last sandbox",
+			reserve:         ptr.To(intstr.FromString("10%")),
+			class:           agentsv1alpha1.WorkloadClassBatch,
+			available:       1,
+			expectAllowed:   0,
+			expectReserved:  1,
+			expectedLimited: true,
+		},
+		{
+			name:            "absolute reservation over available",
+			reserve:         ptr.To(intstr.FromInt32(5)),
+			class:           agentsv1alpha1.WorkloadClassBatch,
+			available:       2,
+			expectAllowed:   0,
+			expectReserved:  2,
+			expectedLimited: true,
+		},
+		{
+			name:           "interactive takes all",
+			reserve:        ptr.To(intstr.FromString("25%")),
+			class:          agentsv1alpha1.WorkloadClassInteractive,
+			available:      10,
+			expectAllowed:  10,
+			expectReserved: 3,
+		},
+		{
+			name:           "unclassified takes all",
+			reserve:        ptr.To(intstr.FromInt32(2)),
+			available:      10,
+			expectAllowed:  10,
+			expectReserved: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sandboxSet := &agentsv1alpha1.SandboxSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "pool", Namespace: "default"},
+				Spec:       agentsv1alpha1.SandboxSetSpec{InteractiveReserve: tt.reserve},
+			}
+			allowed, reserved, limited := fairShareAllowance(sandboxSet, tt.class, tt.available)
+			assert.Equal(t, tt.expectAllowed, allowed)
+			assert.Equal(t, tt.expectReserved, reserved)
+			assert.Equal(t, tt.expectedLimited, limited)
+		})
+	}
+}
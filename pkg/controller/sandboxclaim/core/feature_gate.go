@@ -0,0 +1,68 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+	"github.com/openkruise/agents/pkg/utils/conditions"
+)
+
+// IsParked reports whether the claim is parked by a controller whose SandboxClaim feature gate is disabled
+func IsParked(status *agentsv1alpha1.SandboxClaimStatus) bool {
+	return conditions.IsTrue(status.Conditions, string(agentsv1alpha1.SandboxClaimConditionFeatureDisabled))
+}
+
+// ParkClaim parks an unfinished claim while the SandboxClaim feature gate is disabled, keeping its phase so that it
+// is resumed once the gate is enabled again. Completed claims are left alone. It returns false if nothing changed.
+func ParkClaim(status *agentsv1alpha1.SandboxClaimStatus, now time.Time) bool {
+	if IsClaimFinished(status) || IsParked(status) {
+		return false
+	}
+	message := fmt.Sprintf("The SandboxClaim feature gate of the controller is disabled, parked in phase %q until it is enabled: %d claimed",
+		status.Phase, status.ClaimedReplicas)
+	conditions.NewBuilder(&status.Conditions, status.ObservedGeneration).WithTime(metav1.NewTime(now)).
+		True(string(agentsv1alpha1.SandboxClaimConditionFeatureDisabled), "SandboxClaimGateDisabled", message)
+	status.Message = message
+	recordHistory(status, "FeatureDisabled", message)
+	return true
+}
+
+// ReadoptClaim resumes a claim parked by ParkClaim once the SandboxClaim feature gate is enabled again. The claim
+// start time is shifted by the time parked, so that the claim is not timed out for the time it was not served.
+// It returns the time parked, and false if the claim was not parked.
+func ReadoptClaim(status *agentsv1alpha1.SandboxClaimStatus, now time.Time) (time.Duration, bool) {
+	cond := conditions.Get(status.Conditions, string(agentsv1alpha1.SandboxClaimConditionFeatureDisabled))
+	if cond == nil || cond.Status != metav1.ConditionTrue {
+		return 0, false
+	}
+	parked := max(now.Sub(cond.LastTransitionTime.Time), 0)
+	if status.ClaimStartTime != nil {
+		status.ClaimStartTime = &metav1.Time{Time: status.ClaimStartTime.Add(parked)}
+	}
+	message := fmt.Sprintf("The SandboxClaim feature gate of the controller is enabled, resumed after being parked for %v",
+		parked.Round(time.Second))
+	conditions.NewBuilder(&status.Conditions, status.ObservedGeneration).WithTime(metav1.NewTime(now)).
+		False(string(agentsv1alpha1.SandboxClaimConditionFeatureDisabled), "SandboxClaimGateEnabled", message)
+	status.Message = message
+	recordHistory(status, "Readopted", message)
+	return parked, true
+}
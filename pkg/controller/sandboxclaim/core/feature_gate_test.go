@@ -0,0 +1,96 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+	"github.com/openkruise/agents/pkg/utils/conditions"
+)
+
+func TestParkAndReadoptClaim(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	start := metav1.NewTime(now.Add(-time.Minute))
+
+	tests := []struct {
+		name            string
+		status          *agentsv1alpha1.SandboxClaimStatus
+		expectParked    bool
+		expectStartTime *metav1.Time
+	}{
+		{
+			name: "claiming claim parked and readopted",
+			status: &agentsv1alpha1.SandboxClaimStatus{
+				Phase:           agentsv1alpha1.SandboxClaimPhaseClaiming,
+				ClaimStartTime:  &start,
+				ClaimedReplicas: 1,
+			},
+			expectParked:    true,
+			expectStartTime: ptr.To(metav1.NewTime(start.Add(time.Hour))),
+		},
+		{
+			name:         "pending approval claim parked and readopted",
+			status:       &agentsv1alpha1.SandboxClaimStatus{Phase: agentsv1alpha1.SandboxClaimPhasePendingApproval},
+			expectParked: true,
+		},
+		{
+			name:   "completed claim left alone",
+			status: &agentsv1alpha1.SandboxClaimStatus{Phase: agentsv1alpha1.SandboxClaimPhaseCompleted, ClaimStartTime: &start},
+			// the start time of claims never parked is kept
+			expectStartTime: &start,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			phase := tt.status.Phase
+			assert.Equal(t, tt.expectParked, ParkClaim(tt.status, now))
+			assert.Equal(t, tt.expectParked, IsParked(tt.status))
+			assert.Equal(t, phase, tt.status.Phase, "parking must keep the phase")
+			// parking twice changes nothing
+			assert.False(t, ParkClaim(tt.status, now.Add(time.Minute)))
+
+			parked, ok := ReadoptClaim(tt.status, now.Add(time.Hour))
+			assert.Equal(t, tt.expectParked, ok)
+			assert.Equal(t, phase, tt.status.Phase)
+			assert.Equal(t, tt.expectStartTime, tt.status.ClaimStartTime)
+			assert.False(t, IsParked(tt.status))
+			if !tt.expectParked {
+				assert.Zero(t, parked)
+				return
+			}
+			assert.Equal(t, time.Hour, parked)
+			cond := conditions.Get(tt.status.Conditions, string(agentsv1alpha1.SandboxClaimConditionFeatureDisabled))
+			require.NotNil(t, cond)
+			assert.Equal(t, metav1.ConditionFalse, cond.Status)
+			assert.Equal(t, "SandboxClaimGateEnabled", cond.Reason)
+			require.Len(t, tt.status.History, 2)
+			assert.Equal(t, "FeatureDisabled", tt.status.History[0].Reason)
+			assert.Equal(t, "Readopted", tt.status.History[1].Reason)
+
+			// readopting twice changes nothing
+			_, ok = ReadoptClaim(tt.status, now.Add(2*time.Hour))
+			assert.False(t, ok)
+		})
+	}
+}
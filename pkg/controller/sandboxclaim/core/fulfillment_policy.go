@@ -0,0 +1,77 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"context"
+	"errors"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+	"github.com/openkruise/agents/pkg/sandbox-manager/infra"
+)
+
+// fulfillmentPolicy returns the spec.fulfillmentPolicy of the claim, defaulting to BestEffort
+func fulfillmentPolicy(claim *agentsv1alpha1.SandboxClaim) agentsv1alpha1.SandboxClaimFulfillmentPolicy {
+	if claim.Spec.FulfillmentPolicy == "" {
+		return agentsv1alpha1.SandboxClaimFulfillmentBestEffort
+	}
+	return claim.Spec.FulfillmentPolicy
+}
+
+// countClaimableSandboxes counts the available sandboxes of the pool that the claim may claim, i.e. the ones in its
+// allowed regions and matching its selector.
+func (c *commonControl) countClaimableSandboxes(claim *agentsv1alpha1.SandboxClaim, sandboxSet *agentsv1alpha1.SandboxSet) (int, error) {
+	selector := labels.Everything()
+	if claim.Spec.Selector != nil {
+		var err error
+		if selector, err = metav1.LabelSelectorAsSelector(claim.Spec.Selector); err != nil {
+			return 0, err
+		}
+	}
+	claimable := 0
+	for _, sbx := range c.listPoolSandboxes(sandboxSet, agentsv1alpha1.SandboxStateAvailable) {
+		if _, allowed := infra.RegionRank(claim.Spec.Regions, sbx.Labels[agentsv1alpha1.LabelSandboxRegion]); !allowed {
+			continue
+		}
+		if selector.Matches(labels.Set(sbx.Labels)) {
+			claimable++
+		}
+	}
+	return claimable, nil
+}
+
+// rollbackBoundSandboxes releases the sandboxes bound in a batch of an all-or-nothing claim that could not bind all of
+// them, and returns how many were released.
+func (c *commonControl) rollbackBoundSandboxes(ctx context.Context, bound []infra.Sandbox) (int, error) {
+	log := logf.FromContext(ctx)
+	var errs []error
+	released := 0
+	for _, sbx := range bound {
+		if err := sbx.Kill(ctx); err != nil && !apierrors.IsNotFound(err) {
+			errs = append(errs, err)
+			continue
+		}
+		log.Info("Released sandbox bound by an incomplete all-or-nothing batch", "sandbox", sbx.GetName())
+		released++
+	}
+	return released, errors.Join(errs...)
+}
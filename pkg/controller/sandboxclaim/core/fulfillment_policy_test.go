@@ -0,0 +1,156 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+	"github.com/openkruise/agents/pkg/sandbox-manager/infra"
+	"github.com/openkruise/agents/pkg/sandbox-manager/infra/sandboxcr"
+)
+
+func TestFulfillmentPolicy(t *testing.T) {
+	claim := &agentsv1alpha1.SandboxClaim{}
+	assert.Equal(t, agentsv1alpha1.SandboxClaimFulfillmentBestEffort, fulfillmentPolicy(claim))
+	claim.Spec.FulfillmentPolicy = agentsv1alpha1.SandboxClaimFulfillmentAllOrNothing
+	assert.Equal(t, agentsv1alpha1.SandboxClaimFulfillmentAllOrNothing, fulfillmentPolicy(claim))
+}
+
+func TestCommonControl_countClaimableSandboxes(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = agentsv1alpha1.AddToScheme(scheme)
+
+	cache, clientSet, err := sandboxcr.NewTestCache(t)
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		_ = cache.Run(ctx)
+	}()
+
+	sandboxSet := &agentsv1alpha1.SandboxSet{ObjectMeta: metav1.ObjectMeta{Name: "pool", Namespace: "default", UID: "pool-uid"}}
+	for name, labels := range map[string]map[string]string{
+		"sbx-gpu-east": {"gpu": "true", agentsv1alpha1.LabelSandboxRegion: "us-east"},
+		"sbx-gpu-west": {"gpu": "true", agentsv1alpha1.LabelSandboxRegion: "us-west"},
+		"sbx-cpu-east": {agentsv1alpha1.LabelSandboxRegion: "us-east"},
+	} {
+		labels[agentsv1alpha1.LabelSandboxTemplate] = sandboxSet.Name
+		CreateSandboxWithStatus(t, clientSet.SandboxClient, &agentsv1alpha1.Sandbox{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            name,
+				Namespace:       "default",
+				Labels:          labels,
+				OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(sandboxSet, agentsv1alpha1.SandboxSetControllerKind)},
+			},
+			Status: agentsv1alpha1.SandboxStatus{
+				Phase:      agentsv1alpha1.SandboxRunning,
+				Conditions: []metav1.Condition{{Type: string(agentsv1alpha1.SandboxConditionReady), Status: metav1.ConditionTrue}},
+			},
+		})
+	}
+	require.Eventually(t, func() bool {
+		sandboxes, err := cache.ListSandboxesInPool(sandboxSet.Name)
+		return err == nil && len(sandboxes) == 3
+	}, time.Second, 10*time.Millisecond)
+
+	tests := []struct {
+		name        string
+		spec        agentsv1alpha1.SandboxClaimSpec
+		expected    int
+		expectError bool
+	}{
+		{
+			name:     "all available sandboxes",
+			expected: 3,
+		},
+		{
+			name: "matching the selector",
+			spec: agentsv1alpha1.SandboxClaimSpec{
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"gpu": "true"}},
+			},
+			expected: 2,
+		},
+		{
+			name: "matching the selector in the allowed regions",
+			spec: agentsv1alpha1.SandboxClaimSpec{
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"gpu": "true"}},
+				Regions:  &agentsv1alpha1.RegionPolicy{Forbidden: []string{"us-west"}},
+			},
+			expected: 1,
+		},
+		{
+			name: "invalid selector",
+			spec: agentsv1alpha1.SandboxClaimSpec{
+				Selector: &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "gpu", Operator: "Unknown"}}},
+			},
+			expectError: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme).Build()
+			control := NewCommonControl(fakeClient, record.NewFakeRecorder(10), clientSet, cache).(*commonControl)
+			claimable, err := control.countClaimableSandboxes(&agentsv1alpha1.SandboxClaim{Spec: tt.spec}, sandboxSet)
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, claimable)
+		})
+	}
+}
+
+func TestCommonControl_rollbackBoundSandboxes(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = agentsv1alpha1.AddToScheme(scheme)
+
+	cache, clientSet, err := sandboxcr.NewTestCache(t)
+	require.NoError(t, err)
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).Build()
+	control := NewCommonControl(fakeClient, record.NewFakeRecorder(10), clientSet, cache).(*commonControl)
+
+	var bound []infra.Sandbox
+	for _, name := range []string{"sbx-bound-1", "sbx-bound-2"} {
+		sbx := &agentsv1alpha1.Sandbox{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"}}
+		CreateSandboxWithStatus(t, clientSet.SandboxClient, sbx)
+		bound = append(bound, sandboxcr.AsSandbox(sbx, cache, clientSet))
+	}
+	// a sandbox already deleted by others is released as well
+	bound = append(bound, sandboxcr.AsSandbox(&agentsv1alpha1.Sandbox{
+		ObjectMeta: metav1.ObjectMeta{Name: "sbx-gone", Namespace: "default"},
+	}, cache, clientSet))
+
+	released, err := control.rollbackBoundSandboxes(t.Context(), bound)
+	require.NoError(t, err)
+	assert.Equal(t, 3, released)
+	for _, name := range []string{"sbx-bound-1", "sbx-bound-2"} {
+		_, err := clientSet.SandboxClient.ApiV1alpha1().Sandboxes("default").Get(t.Context(), name, metav1.GetOptions{})
+		assert.True(t, apierrors.IsNotFound(err), "sandbox %s should be deleted", name)
+	}
+}
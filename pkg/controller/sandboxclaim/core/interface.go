@@ -18,47 +18,46 @@ package core
 
 import (
 	"context"
-	"time"
 
 	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
 	"github.com/openkruise/agents/pkg/controller/sandbox/core"
+	"github.com/openkruise/agents/pkg/controller/sandboxclaim/archive"
 	"github.com/openkruise/agents/pkg/sandbox-manager/clients"
 	"github.com/openkruise/agents/pkg/sandbox-manager/infra/sandboxcr"
 	"github.com/openkruise/agents/pkg/utils/expectations"
+	"github.com/openkruise/agents/pkg/utils/requeue"
 )
 
 var (
 	ResourceVersionExpectations = expectations.NewResourceVersionExpectation()
+	// ClaimConcurrencyLimiter limits concurrent claims per SandboxSet with spec.maxConcurrentClaims
+	ClaimConcurrencyLimiter = NewClaimLimiter()
+	// ClaimOrdinals hands out the ordinals of the sandboxes claimed by claims with spec.ordinalAliases
+	ClaimOrdinals = NewOrdinalAllocator()
+	// ClaimRetries counts the consecutive retries of claims waiting for sandboxes to back off with spec.retryPolicy
+	ClaimRetries = NewRetryCounter()
+	// DefaultRetryPolicy backs off the retries of claims waiting for sandboxes without spec.retryPolicy, nil retries
+	// them at the fixed ClaimRetryInterval
+	DefaultRetryPolicy *agentsv1alpha1.SandboxClaimRetryPolicy
+	// SandboxQuotaEnabled enforces the SandboxQuotas of the namespaces, set if the SandboxQuota CRD is installed
+	SandboxQuotaEnabled bool
+	// ClaimGroups serializes the claiming of the groups of claims with spec.claimGroup
+	ClaimGroups = NewClaimGroupCoordinator()
+	// ClaimArchiver archives completed claims before they are deleted by TTL, nil disables archiving
+	ClaimArchiver archive.Sink
+	// ClaimNotifier posts the callbacks of spec.notifications of finished claims
+	ClaimNotifier = NewNotifier(DefaultNotificationTimeout)
+	// NotificationAllowHTTP allows the callbacks of spec.notifications to plain http endpoints, only https by default
+	NotificationAllowHTTP bool
+	// WarmupLatencies tracks the recent warmup latencies of pools to estimate the ETA of claims
+	WarmupLatencies = NewWarmupTracker()
 )
 
 // RequeueStrategy defines the requeue behavior for controller reconciliation
-type RequeueStrategy struct {
-	// Immediate indicates whether to requeue immediately (ctrl.Result{Requeue: true})
-	// If false, uses After duration
-	Immediate bool
-
-	// After specifies the duration to wait before requeue
-	// Only used when Immediate is false
-	After time.Duration
-}
-
-// RequeueImmediately returns a strategy for immediate requeue
-func RequeueImmediately() RequeueStrategy {
-	return RequeueStrategy{Immediate: true}
-}
-
-// RequeueAfter returns a strategy for delayed requeue
-func RequeueAfter(duration time.Duration) RequeueStrategy {
-	return RequeueStrategy{After: duration}
-}
-
-// NoRequeue returns a strategy that waits for Watch events
-func NoRequeue() RequeueStrategy {
-	return RequeueStrategy{}
-}
+type RequeueStrategy = requeue.Strategy
 
 // ClaimArgs encapsulates all arguments needed for claim operations
 type ClaimArgs struct {
@@ -69,11 +68,20 @@ type ClaimArgs struct {
 
 // ClaimControl defines the interface for claiming operations
 type ClaimControl interface {
+	// EnsureClaimQueued handles claim in Queued phase
+	EnsureClaimQueued(ctx context.Context, args ClaimArgs) (RequeueStrategy, error)
+
 	// EnsureClaimClaiming handles claim in Claiming phase
 	EnsureClaimClaiming(ctx context.Context, args ClaimArgs) (RequeueStrategy, error)
 
+	// EnsureClaimDryRun handles dry-run claim in Claiming phase, reporting the sandboxes it could claim
+	EnsureClaimDryRun(ctx context.Context, args ClaimArgs) (RequeueStrategy, error)
+
 	// EnsureClaimCompleted handles claim in Completed phase (TTL cleanup)
 	EnsureClaimCompleted(ctx context.Context, args ClaimArgs) (RequeueStrategy, error)
+
+	// EnsureClaimDeleted releases the sandboxes claimed by a deleting claim with its deletion policy
+	EnsureClaimDeleted(ctx context.Context, claim *agentsv1alpha1.SandboxClaim) error
 }
 
 // NewClaimControl creates a map of claim controls
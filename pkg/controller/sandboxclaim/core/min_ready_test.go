@@ -0,0 +1,169 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+	"github.com/openkruise/agents/pkg/sandbox-manager/infra/sandboxcr"
+)
+
+func TestCommonControl_EnsureClaimClaiming_MinReadySeconds(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = agentsv1alpha1.AddToScheme(scheme)
+
+	cache, clientSet, err := sandboxcr.NewTestCache(t)
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		_ = cache.Run(ctx)
+	}()
+	time.Sleep(200 * time.Millisecond) // Wait for cache to start
+
+	now := time.Now()
+	newSandbox := func(name, owner string, readySince, claimed time.Time) *agentsv1alpha1.Sandbox {
+		return &agentsv1alpha1.Sandbox{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+				Annotations: map[string]string{
+					agentsv1alpha1.AnnotationOwner:     owner,
+					agentsv1alpha1.AnnotationClaimTime: claimed.Format(time.RFC3339),
+				},
+				Labels: map[string]string{
+					agentsv1alpha1.LabelSandboxTemplate:  "pool",
+					agentsv1alpha1.LabelSandboxIsClaimed: "true",
+				},
+			},
+			Status: agentsv1alpha1.SandboxStatus{
+				Phase: agentsv1alpha1.SandboxRunning,
+				Conditions: []metav1.Condition{{
+					Type:               string(agentsv1alpha1.SandboxConditionReady),
+					Status:             metav1.ConditionTrue,
+					LastTransitionTime: metav1.NewTime(readySince),
+				}},
+			},
+		}
+	}
+	sandboxSet := &agentsv1alpha1.SandboxSet{ObjectMeta: metav1.ObjectMeta{Name: "pool", Namespace: "default", UID: "pool-uid"}}
+
+	tests := []struct {
+		name            string
+		uid             string
+		minReadySeconds int32
+		sandboxes       []*agentsv1alpha1.Sandbox
+		expectReason    string
+		expectClaimed   int32
+		expectBound     int32
+		expectMessage   string
+	}{
+		{
+			name: "bound sandboxes are claimed without minReadySeconds",
+			uid:  "min-ready-uid-1",
+			sandboxes: []*agentsv1alpha1.Sandbox{
+				newSandbox("min-ready-sbx-1", "min-ready-uid-1", now, now),
+				newSandbox("min-ready-sbx-2", "min-ready-uid-1", now, now),
+			},
+			expectReason:  "AllReplicasClaimed",
+			expectClaimed: 2,
+			expectMessage: "Completed: 2/2 claimed",
+		},
+		{
+			name:            "sandboxes are claimed once ready for minReadySeconds since claimed",
+			uid:             "min-ready-uid-2",
+			minReadySeconds: 60,
+			sandboxes: []*agentsv1alpha1.Sandbox{
+				newSandbox("min-ready-sbx-3", "min-ready-uid-2", now.Add(-time.Hour), now.Add(-2*time.Minute)),
+				newSandbox("min-ready-sbx-4", "min-ready-uid-2", now.Add(-time.Hour), now.Add(-10*time.Second)),
+			},
+			expectReason:  "WaitingForMinReady",
+			expectClaimed: 1,
+			expectBound:   2,
+			expectMessage: "Waiting for 1 sandbox(es) to be ready for 60s: 1/2 claimed",
+		},
+		{
+			name:            "all sandboxes ready for minReadySeconds",
+			uid:             "min-ready-uid-3",
+			minReadySeconds: 60,
+			sandboxes: []*agentsv1alpha1.Sandbox{
+				newSandbox("min-ready-sbx-5", "min-ready-uid-3", now.Add(-2*time.Minute), now.Add(-time.Hour)),
+				newSandbox("min-ready-sbx-6", "min-ready-uid-3", now.Add(-2*time.Minute), now.Add(-time.Hour)),
+			},
+			expectReason:  "AllReplicasClaimed",
+			expectClaimed: 2,
+			expectBound:   2,
+			expectMessage: "Completed: 2/2 claimed",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, sbx := range tt.sandboxes {
+				_, err := clientSet.SandboxClient.ApiV1alpha1().Sandboxes(sbx.Namespace).Create(ctx, sbx, metav1.CreateOptions{})
+				require.NoError(t, err)
+			}
+			time.Sleep(100 * time.Millisecond) // Wait for cache sync
+
+			claim := &agentsv1alpha1.SandboxClaim{
+				ObjectMeta: metav1.ObjectMeta{Name: "min-ready-claim", Namespace: "default", UID: types.UID(tt.uid)},
+				Spec: agentsv1alpha1.SandboxClaimSpec{
+					TemplateName:    "pool",
+					Replicas:        int32Ptr(2),
+					MinReadySeconds: tt.minReadySeconds,
+				},
+			}
+			newStatus := &agentsv1alpha1.SandboxClaimStatus{
+				Phase:          agentsv1alpha1.SandboxClaimPhaseClaiming,
+				ClaimStartTime: &metav1.Time{Time: now},
+			}
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(claim, sandboxSet).Build()
+			control := NewCommonControl(fakeClient, record.NewFakeRecorder(10), clientSet, cache)
+
+			strategy, err := control.EnsureClaimClaiming(ctx, ClaimArgs{Claim: claim, SandboxSet: sandboxSet, NewStatus: newStatus})
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectReason, strategy.Reason)
+			assert.Equal(t, tt.expectClaimed, newStatus.ClaimedReplicas)
+			assert.Equal(t, tt.expectBound, newStatus.BoundReplicas)
+			assert.Equal(t, tt.expectMessage, newStatus.Message)
+			if tt.expectReason == "WaitingForMinReady" {
+				assert.InDelta(t, 50*time.Second, strategy.After, float64(2*time.Second), "requeued when the next sandbox is counted")
+			}
+		})
+	}
+}
+
+func TestCompleteBoundReplicas(t *testing.T) {
+	status := &agentsv1alpha1.SandboxClaimStatus{
+		Phase:           agentsv1alpha1.SandboxClaimPhaseClaiming,
+		ClaimedReplicas: 1,
+		BoundReplicas:   3,
+	}
+	TransitionToCompleted(status, "Canceled", "Claim is canceled")
+	assert.Equal(t, int32(3), status.ClaimedReplicas, "the sandboxes not ready for minReadySeconds are claimed on completion")
+	assert.Zero(t, status.BoundReplicas)
+}
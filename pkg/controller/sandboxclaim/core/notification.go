@@ -0,0 +1,202 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"syscall"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+	"github.com/openkruise/agents/pkg/utils/conditions"
+	"github.com/openkruise/agents/pkg/utils/requeue"
+)
+
+// ensureClaimNotified posts the finished claim to spec.notifications.url once per completion, and records the delivery
+// in the Notified condition. A failed delivery is retried after NotificationRetryInterval.
+func (c *commonControl) ensureClaimNotified(ctx context.Context, claim *agentsv1alpha1.SandboxClaim, status *agentsv1alpha1.SandboxClaimStatus) RequeueStrategy {
+	log := logf.FromContext(ctx)
+	notifications := claim.Spec.Notifications
+	if notifications == nil || conditions.IsTrue(status.Conditions, string(agentsv1alpha1.SandboxClaimConditionNotified)) {
+		return requeue.NoRequeue()
+	}
+	builder := conditions.NewBuilder(&status.Conditions, status.ObservedGeneration)
+	if err := c.postNotification(ctx, claim, status); err != nil {
+		log.Error(err, "failed to notify the finished claim", "url", notifications.URL)
+		c.recorder.Event(claim, "Warning", "NotificationFailed", fmt.Sprintf("Failed to notify %s: %v", notifications.URL, err))
+		builder.False(string(agentsv1alpha1.SandboxClaimConditionNotified), "DeliveryFailed", err.Error())
+		return requeue.After(NotificationRetryInterval).WithReason("NotificationFailed")
+	}
+	message := fmt.Sprintf("Notified %s of the %s claim", notifications.URL, status.Phase)
+	log.Info("Notified the finished claim", "url", notifications.URL, "phase", status.Phase)
+	c.recorder.Event(claim, "Normal", "Notified", message)
+	builder.True(string(agentsv1alpha1.SandboxClaimConditionNotified), "Delivered", message)
+	return requeue.NoRequeue()
+}
+
+// postNotification posts the notification of the claim as JSON, signed with the key of spec.notifications.secretRef
+func (c *commonControl) postNotification(ctx context.Context, claim *agentsv1alpha1.SandboxClaim, status *agentsv1alpha1.SandboxClaimStatus) error {
+	notifications := claim.Spec.Notifications
+	endpoint, err := url.Parse(notifications.URL)
+	if err != nil {
+		return err
+	}
+	if endpoint.Scheme != "https" && !NotificationAllowHTTP {
+		return fmt.Errorf("notification url must be https")
+	}
+	notification, err := c.buildNotification(claim, status)
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(notification)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, notifications.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for name, value := range notifications.Headers {
+		req.Header.Set(name, value)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if notifications.SecretRef != nil {
+		key, err := c.notificationSigningKey(ctx, claim.Namespace, notifications.SecretRef)
+		if err != nil {
+			return err
+		}
+		req.Header.Set(agentsv1alpha1.SandboxClaimNotificationSignatureHeader, signNotification(key, body))
+	}
+	resp, err := c.notifier.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post notification: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	// the response body is never recorded, so that the claim cannot be used to read what an endpoint returns
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("notification endpoint responded %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// sharedAddressSpace is the carrier-grade NAT range of RFC 6598, which is internal like the private ranges
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// NewNotifier returns the client posting the callbacks of spec.notifications. Since anyone creating claims chooses
+// the endpoints, it neither follows redirects nor uses proxies, and refuses to connect to loopback, link-local,
+// private and unspecified addresses when dialing, i.e. after resolving the host, so that the callbacks cannot reach
+// the services inside the cluster or the metadata endpoint of the cloud.
+func NewNotifier(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(_, address string, _ syscall.RawConn) error {
+			return checkNotificationAddress(address)
+		},
+	}
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: timeout,
+			MaxIdleConnsPerHost: 2,
+			IdleConnTimeout:     90 * time.Second,
+		},
+		CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+	}
+}
+
+// checkNotificationAddress refuses the dialed address of a callback if it is not a public one
+func checkNotificationAddress(address string) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return err
+	}
+	addr := addrPort.Addr().Unmap()
+	if addr.IsLoopback() || addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() || addr.IsPrivate() ||
+		addr.IsUnspecified() || addr.IsMulticast() || sharedAddressSpace.Contains(addr) {
+		return fmt.Errorf("notification endpoint address %s is not public", addr)
+	}
+	return nil
+}
+
+// buildNotification builds the notification of the finished claim with the endpoints of its claimed sandboxes
+func (c *commonControl) buildNotification(claim *agentsv1alpha1.SandboxClaim, status *agentsv1alpha1.SandboxClaimStatus) (*agentsv1alpha1.SandboxClaimNotification, error) {
+	sandboxes, err := c.cache.ListSandboxWithUser(string(claim.UID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list claimed sandboxes: %w", err)
+	}
+	notification := &agentsv1alpha1.SandboxClaimNotification{
+		Name:            claim.Name,
+		Namespace:       claim.Namespace,
+		UID:             claim.UID,
+		Phase:           status.Phase,
+		Message:         status.Message,
+		TimedOut:        conditions.IsTrue(status.Conditions, string(agentsv1alpha1.SandboxClaimConditionTimedOut)),
+		ClaimedReplicas: status.ClaimedReplicas,
+		CompletionTime:  status.CompletionTime,
+		Sandboxes:       buildResultSandboxes(sandboxes, c.cache, c.sandboxClient),
+	}
+	if !claim.Spec.Notifications.IncludeAccessTokens {
+		for i := range notification.Sandboxes {
+			notification.Sandboxes[i].AccessToken = ""
+		}
+	}
+	if completed := conditions.Get(status.Conditions, string(agentsv1alpha1.SandboxClaimConditionCompleted)); completed != nil {
+		notification.Reason = completed.Reason
+	}
+	return notification, nil
+}
+
+// notificationSigningKey reads the signing key of the notifications with the uncached client, so that the controller
+// does not cache all Secrets
+func (c *commonControl) notificationSigningKey(ctx context.Context, namespace string, ref *agentsv1alpha1.SandboxClaimNotificationSecretRef) ([]byte, error) {
+	secret, err := c.sandboxClient.K8sClient.CoreV1().Secrets(namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notification secret %s: %w", ref.Name, err)
+	}
+	key := ref.Key
+	if key == "" {
+		key = agentsv1alpha1.SandboxClaimNotificationDefaultSecretKey
+	}
+	value, ok := secret.Data[key]
+	if !ok || len(value) == 0 {
+		return nil, fmt.Errorf("notification secret %s has no key %s", ref.Name, key)
+	}
+	return value, nil
+}
+
+// signNotification returns the signature header value of the body, i.e. sha256=<hex HMAC-SHA256 digest>
+func signNotification(key, body []byte) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
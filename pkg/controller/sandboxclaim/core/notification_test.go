@@ -0,0 +1,287 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+	"github.com/openkruise/agents/pkg/sandbox-manager/infra/sandboxcr"
+	"github.com/openkruise/agents/pkg/utils/conditions"
+	"github.com/openkruise/agents/pkg/utils/requeue"
+)
+
+func TestCommonControl_EnsureClaimCompleted_Notifications(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = agentsv1alpha1.AddToScheme(scheme)
+
+	cache, clientSet, err := sandboxcr.NewTestCache(t)
+	require.NoError(t, err, "Failed to create cache")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		_ = cache.Run(ctx)
+	}()
+	time.Sleep(200 * time.Millisecond) // Wait for cache to start
+
+	_, err = clientSet.K8sClient.CoreV1().Secrets("default").Create(ctx, &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "callback-key", Namespace: "default"},
+		Data:       map[string][]byte{agentsv1alpha1.SandboxClaimNotificationDefaultSecretKey: []byte("s3cr3t")},
+	}, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	type request struct {
+		header       http.Header
+		notification agentsv1alpha1.SandboxClaimNotification
+		body         []byte
+	}
+	var received []request
+	statusCode := http.StatusOK
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		req := request{header: r.Header, body: body}
+		_ = json.Unmarshal(body, &req.notification)
+		received = append(received, req)
+		w.WriteHeader(statusCode)
+		_, _ = w.Write([]byte("internal response"))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name                string
+		phase               agentsv1alpha1.SandboxClaimPhase
+		timedOut            bool
+		secretRef           *agentsv1alpha1.SandboxClaimNotificationSecretRef
+		includeAccessTokens bool
+		notified            bool
+		statusCode          int
+		expectRequests      int
+		expectNotified      metav1.ConditionStatus
+		expectMessage       string
+		expectRequeue       bool
+		expectSignature     bool
+	}{
+		{
+			name:           "notify completed claim",
+			phase:          agentsv1alpha1.SandboxClaimPhaseCompleted,
+			statusCode:     http.StatusOK,
+			expectRequests: 1,
+			expectNotified: metav1.ConditionTrue,
+		},
+		{
+			name:                "notify completed claim with access tokens",
+			phase:               agentsv1alpha1.SandboxClaimPhaseCompleted,
+			includeAccessTokens: true,
+			statusCode:          http.StatusOK,
+			expectRequests:      1,
+			expectNotified:      metav1.ConditionTrue,
+		},
+		{
+			name:            "notify timed out claim signed with the secret",
+			phase:           agentsv1alpha1.SandboxClaimPhaseFailed,
+			timedOut:        true,
+			secretRef:       &agentsv1alpha1.SandboxClaimNotificationSecretRef{Name: "callback-key"},
+			statusCode:      http.StatusNoContent,
+			expectRequests:  1,
+			expectNotified:  metav1.ConditionTrue,
+			expectSignature: true,
+		},
+		{
+			name:           "already notified claim is not notified again",
+			phase:          agentsv1alpha1.SandboxClaimPhaseCompleted,
+			notified:       true,
+			statusCode:     http.StatusOK,
+			expectRequests: 0,
+			expectNotified: metav1.ConditionTrue,
+		},
+		{
+			name:           "failed delivery is retried",
+			phase:          agentsv1alpha1.SandboxClaimPhaseCompleted,
+			statusCode:     http.StatusInternalServerError,
+			expectRequests: 1,
+			expectNotified: metav1.ConditionFalse,
+			expectMessage:  "notification endpoint responded 500",
+			expectRequeue:  true,
+		},
+		{
+			name:           "missing signing secret fails the delivery",
+			phase:          agentsv1alpha1.SandboxClaimPhaseCompleted,
+			secretRef:      &agentsv1alpha1.SandboxClaimNotificationSecretRef{Name: "missing"},
+			statusCode:     http.StatusOK,
+			expectRequests: 0,
+			expectNotified: metav1.ConditionFalse,
+			expectRequeue:  true,
+		},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			received, statusCode = nil, tt.statusCode
+			claim := &agentsv1alpha1.SandboxClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      fmt.Sprintf("notify-claim-%d", i),
+					Namespace: "default",
+					UID:       types.UID(fmt.Sprintf("notify-uid-%d", i)),
+				},
+				Spec: agentsv1alpha1.SandboxClaimSpec{
+					TemplateName: "test-template",
+					Replicas:     int32Ptr(1),
+					Notifications: &agentsv1alpha1.SandboxClaimNotifications{
+						URL:                 server.URL,
+						Headers:             map[string]string{"X-Cluster": "test"},
+						SecretRef:           tt.secretRef,
+						IncludeAccessTokens: tt.includeAccessTokens,
+					},
+				},
+			}
+			_, err := clientSet.SandboxClient.ApiV1alpha1().Sandboxes("default").Create(ctx, &agentsv1alpha1.Sandbox{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      claim.Name + "-sbx",
+					Namespace: "default",
+					Annotations: map[string]string{
+						agentsv1alpha1.AnnotationOwner:              string(claim.UID),
+						agentsv1alpha1.AnnotationRuntimeURL:         "http://10.0.0.1:49983",
+						agentsv1alpha1.AnnotationRuntimeAccessToken: "token",
+					},
+					Labels: map[string]string{
+						agentsv1alpha1.LabelSandboxTemplate:  "test-template",
+						agentsv1alpha1.LabelSandboxIsClaimed: "true",
+					},
+				},
+				Status: agentsv1alpha1.SandboxStatus{Phase: agentsv1alpha1.SandboxRunning},
+			}, metav1.CreateOptions{})
+			require.NoError(t, err)
+			time.Sleep(100 * time.Millisecond) // Wait for cache sync
+
+			newStatus := &agentsv1alpha1.SandboxClaimStatus{Phase: tt.phase, ClaimedReplicas: 1}
+			builder := conditions.NewBuilder(&newStatus.Conditions, 0).
+				True(string(agentsv1alpha1.SandboxClaimConditionCompleted), "AllReplicasClaimed", "done")
+			if tt.timedOut {
+				builder.True(string(agentsv1alpha1.SandboxClaimConditionTimedOut), "ClaimTimeout", "timed out")
+			}
+			if tt.notified {
+				builder.True(string(agentsv1alpha1.SandboxClaimConditionNotified), "Delivered", "notified")
+			}
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(claim).Build()
+			control := NewCommonControl(fakeClient, record.NewFakeRecorder(10), clientSet, cache).(*commonControl)
+			control.notifier = server.Client() // trusts the test server on the loopback address
+
+			strategy, err := control.EnsureClaimCompleted(ctx, ClaimArgs{Claim: claim, NewStatus: newStatus})
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectRequeue, strategy.Kind() == requeue.KindAfter)
+			notified := conditions.Get(newStatus.Conditions, string(agentsv1alpha1.SandboxClaimConditionNotified))
+			require.NotNil(t, notified)
+			assert.Equal(t, tt.expectNotified, notified.Status)
+			if tt.expectMessage != "" {
+				assert.Equal(t, tt.expectMessage, notified.Message, "the response body is not recorded")
+			}
+
+			require.Len(t, received, tt.expectRequests)
+			if tt.expectRequests == 0 {
+				return
+			}
+			req := received[0]
+			assert.Equal(t, "test", req.header.Get("X-Cluster"))
+			assert.Equal(t, claim.Name, req.notification.Name)
+			assert.Equal(t, tt.phase, req.notification.Phase)
+			assert.Equal(t, "AllReplicasClaimed", req.notification.Reason)
+			assert.Equal(t, tt.timedOut, req.notification.TimedOut)
+			require.Len(t, req.notification.Sandboxes, 1)
+			assert.Equal(t, "http://10.0.0.1:49983", req.notification.Sandboxes[0].RuntimeURL)
+			if tt.includeAccessTokens {
+				assert.Equal(t, "token", req.notification.Sandboxes[0].AccessToken)
+			} else {
+				assert.Empty(t, req.notification.Sandboxes[0].AccessToken)
+			}
+			if tt.expectSignature {
+				assert.Equal(t, signNotification([]byte("s3cr3t"), req.body),
+					req.header.Get(agentsv1alpha1.SandboxClaimNotificationSignatureHeader))
+			} else {
+				assert.Empty(t, req.header.Get(agentsv1alpha1.SandboxClaimNotificationSignatureHeader))
+			}
+		})
+	}
+}
+
+func TestSignNotification(t *testing.T) {
+	// echo -n '{}' | openssl dgst -sha256 -hmac key
+	assert.Equal(t, "sha256=a777724d943eb48dc69bca8a4a6d57a04db3f9ec7e1de4e581e860265bdf3032",
+		signNotification([]byte("key"), []byte("{}")))
+}
+
+func TestPostNotification_RefusesHTTP(t *testing.T) {
+	control := NewCommonControl(nil, record.NewFakeRecorder(10), nil, nil).(*commonControl)
+	claim := &agentsv1alpha1.SandboxClaim{
+		Spec: agentsv1alpha1.SandboxClaimSpec{
+			Notifications: &agentsv1alpha1.SandboxClaimNotifications{URL: "http://example.com/callback"},
+		},
+	}
+	err := control.postNotification(context.Background(), claim, &agentsv1alpha1.SandboxClaimStatus{})
+	assert.EqualError(t, err, "notification url must be https")
+}
+
+func TestNewNotifier(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	notifier := NewNotifier(time.Second)
+	_, err := notifier.Post(server.URL, "application/json", nil)
+	assert.ErrorContains(t, err, "is not public", "the loopback address is refused when dialing")
+	assert.Equal(t, http.ErrUseLastResponse, notifier.CheckRedirect(nil, nil), "redirects are not followed")
+}
+
+func TestCheckNotificationAddress(t *testing.T) {
+	tests := []struct {
+		address   string
+		expectErr bool
+	}{
+		{address: "127.0.0.1:443", expectErr: true},
+		{address: "[::1]:443", expectErr: true},
+		{address: "169.254.169.254:80", expectErr: true},
+		{address: "10.0.0.1:443", expectErr: true},
+		{address: "172.16.0.1:443", expectErr: true},
+		{address: "192.168.0.1:443", expectErr: true},
+		{address: "100.64.0.1:443", expectErr: true},
+		{address: "[fd00::1]:443", expectErr: true},
+		{address: "[::ffff:10.0.0.1]:443", expectErr: true},
+		{address: "0.0.0.0:443", expectErr: true},
+		{address: "8.8.8.8:443"},
+		{address: "[2001:4860:4860::8888]:443"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.address, func(t *testing.T) {
+			assert.Equal(t, tt.expectErr, checkNotificationAddress(tt.address) != nil)
+		})
+	}
+}
@@ -0,0 +1,144 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"maps"
+	"strconv"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+	"github.com/openkruise/agents/pkg/sandbox-manager/infra"
+)
+
+// ordinalPendingTimeout is how long an ordinal handed out is held for the cache to observe it on the claimed sandbox
+const ordinalPendingTimeout = time.Minute
+
+// OrdinalAllocator hands out the ordinals of the sandboxes claimed by claims with spec.ordinalAliases.
+// Ordinals handed out are held until they are observed on the claimed sandboxes, so that an ordinal is never handed
+// out twice while the cache is stale.
+type OrdinalAllocator interface {
+	// Take hands out the lowest ordinal of the claim neither observed on its sandboxes nor held.
+	Take(claim metav1.Object, observed sets.Set[int]) int
+	// Return gives back an ordinal handed out to a sandbox failed to be claimed.
+	Return(claim metav1.Object, ordinal int)
+	// Forget drops the ordinals held for the claim.
+	Forget(claim metav1.Object)
+}
+
+func NewOrdinalAllocator() OrdinalAllocator {
+	return &realOrdinalAllocator{
+		pending: make(map[types.UID]map[int]time.Time),
+		now:     time.Now,
+	}
+}
+
+type realOrdinalAllocator struct {
+	sync.Mutex
+	// pending records when the ordinals not observed yet were handed out, by claim
+	pending map[types.UID]map[int]time.Time
+	now     func() time.Time
+}
+
+func (a *realOrdinalAllocator) Take(claim metav1.Object, observed sets.Set[int]) int {
+	a.Lock()
+	defer a.Unlock()
+
+	now := a.now()
+	pending := a.pending[claim.GetUID()]
+	if pending == nil {
+		pending = make(map[int]time.Time)
+		a.pending[claim.GetUID()] = pending
+	}
+	for ordinal, since := range pending {
+		if observed.Has(ordinal) || now.Sub(since) >= ordinalPendingTimeout {
+			delete(pending, ordinal)
+		}
+	}
+	ordinal := 0
+	for {
+		if _, held := pending[ordinal]; !held && !observed.Has(ordinal) {
+			break
+		}
+		ordinal++
+	}
+	pending[ordinal] = now
+	return ordinal
+}
+
+func (a *realOrdinalAllocator) Return(claim metav1.Object, ordinal int) {
+	a.Lock()
+	defer a.Unlock()
+	delete(a.pending[claim.GetUID()], ordinal)
+}
+
+func (a *realOrdinalAllocator) Forget(claim metav1.Object) {
+	a.Lock()
+	defer a.Unlock()
+	delete(a.pending, claim.GetUID())
+}
+
+// claimAlias returns the alias of the sandbox of the ordinal claimed by the claim
+func claimAlias(claim *agentsv1alpha1.SandboxClaim, ordinal int) string {
+	return claim.Name + "-" + strconv.Itoa(ordinal)
+}
+
+// observedOrdinals returns the ordinals labeled on the sandboxes, ignoring invalid ones
+func observedOrdinals(sandboxes []*agentsv1alpha1.Sandbox) sets.Set[int] {
+	observed := sets.New[int]()
+	for _, sbx := range sandboxes {
+		value, ok := sbx.Labels[agentsv1alpha1.LabelSandboxClaimOrdinal]
+		if !ok {
+			continue
+		}
+		if ordinal, err := strconv.Atoi(value); err == nil && ordinal >= 0 {
+			observed.Insert(ordinal)
+		}
+	}
+	return observed
+}
+
+// withOrdinalAlias wraps the modifier of claimed sandboxes to label the sandbox and its pod with the ordinal and alias
+func withOrdinalAlias(modifier func(infra.Sandbox), claim *agentsv1alpha1.SandboxClaim, ordinal int) func(infra.Sandbox) {
+	aliasLabels := map[string]string{
+		agentsv1alpha1.LabelSandboxClaimOrdinal: strconv.Itoa(ordinal),
+		agentsv1alpha1.LabelSandboxClaimAlias:   claimAlias(claim, ordinal),
+	}
+	return func(sbx infra.Sandbox) {
+		if modifier != nil {
+			modifier(sbx)
+		}
+		labels := sbx.GetLabels()
+		if labels == nil {
+			labels = make(map[string]string)
+		}
+		maps.Copy(labels, aliasLabels)
+		sbx.SetLabels(labels)
+
+		podLabels := sbx.GetPodLabels()
+		if podLabels == nil {
+			podLabels = make(map[string]string)
+		}
+		maps.Copy(podLabels, aliasLabels)
+		sbx.SetPodLabels(podLabels)
+	}
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+	"github.com/openkruise/agents/pkg/sandbox-manager/infra"
+	"github.com/openkruise/agents/pkg/sandbox-manager/infra/sandboxcr"
+)
+
+func TestOrdinalAllocator(t *testing.T) {
+	claim := &agentsv1alpha1.SandboxClaim{ObjectMeta: metav1.ObjectMeta{Name: "claim", UID: "claim-uid"}}
+	other := &agentsv1alpha1.SandboxClaim{ObjectMeta: metav1.ObjectMeta{Name: "other", UID: "other-uid"}}
+
+	t.Run("hands out the lowest free ordinals", func(t *testing.T) {
+		allocator := NewOrdinalAllocator()
+		observed := sets.New(0, 2)
+		assert.Equal(t, 1, allocator.Take(claim, observed))
+		assert.Equal(t, 3, allocator.Take(claim, observed))
+		assert.Equal(t, 0, allocator.Take(other, sets.New[int]()), "ordinals of claims are independent")
+	})
+	t.Run("returned ordinal is handed out again", func(t *testing.T) {
+		allocator := NewOrdinalAllocator()
+		assert.Equal(t, 0, allocator.Take(claim, nil))
+		assert.Equal(t, 1, allocator.Take(claim, nil))
+		allocator.Return(claim, 0)
+		assert.Equal(t, 0, allocator.Take(claim, nil))
+	})
+	t.Run("ordinal of a lost sandbox is handed out again", func(t *testing.T) {
+		allocator := NewOrdinalAllocator()
+		assert.Equal(t, 0, allocator.Take(claim, nil))
+		assert.Equal(t, 1, allocator.Take(claim, nil))
+		// both are observed, then the sandbox of 0 is lost
+		assert.Equal(t, 2, allocator.Take(claim, sets.New(0, 1)))
+		allocator.Return(claim, 2)
+		assert.Equal(t, 0, allocator.Take(claim, sets.New(1)))
+	})
+	t.Run("ordinal not observed is held until timeout", func(t *testing.T) {
+		allocator := NewOrdinalAllocator().(*realOrdinalAllocator)
+		now := time.Now()
+		allocator.now = func() time.Time { return now }
+		assert.Equal(t, 0, allocator.Take(claim, nil))
+		assert.Equal(t, 1, allocator.Take(claim, nil), "the stale cache does not show ordinal 0 yet")
+		now = now.Add(ordinalPendingTimeout)
+		assert.Equal(t, 0, allocator.Take(claim, nil))
+	})
+	t.Run("forget drops the held ordinals", func(t *testing.T) {
+		allocator := NewOrdinalAllocator()
+		assert.Equal(t, 0, allocator.Take(claim, nil))
+		allocator.Forget(claim)
+		assert.Equal(t, 0, allocator.Take(claim, nil))
+	})
+}
+
+func TestObservedOrdinals(t *testing.T) {
+	newSandbox := func(labels map[string]string) *agentsv1alpha1.Sandbox {
+		return &agentsv1alpha1.Sandbox{ObjectMeta: metav1.ObjectMeta{Labels: labels}}
+	}
+	observed := observedOrdinals([]*agentsv1alpha1.Sandbox{
+		newSandbox(map[string]string{agentsv1alpha1.LabelSandboxClaimOrdinal: "0"}),
+		newSandbox(map[string]string{agentsv1alpha1.LabelSandboxClaimOrdinal: "3"}),
+		newSandbox(map[string]string{agentsv1alpha1.LabelSandboxClaimOrdinal: "invalid"}),
+		newSandbox(map[string]string{agentsv1alpha1.LabelSandboxClaimOrdinal: "-1"}),
+		newSandbox(nil),
+	})
+	assert.Equal(t, sets.New(0, 3), observed)
+}
+
+func TestWithOrdinalAlias(t *testing.T) {
+	claim := &agentsv1alpha1.SandboxClaim{ObjectMeta: metav1.ObjectMeta{Name: "peers", UID: "peers-uid"}}
+	sbx := &agentsv1alpha1.Sandbox{
+		ObjectMeta: metav1.ObjectMeta{Name: "sbx", Labels: map[string]string{"app": "agent"}},
+		Spec: agentsv1alpha1.SandboxSpec{
+			EmbeddedSandboxTemplate: agentsv1alpha1.EmbeddedSandboxTemplate{Template: &corev1.PodTemplateSpec{}},
+		},
+	}
+	modified := false
+	modifier := withOrdinalAlias(func(infra.Sandbox) { modified = true }, claim, 2)
+	modifier(sandboxcr.AsSandbox(sbx, nil, nil))
+
+	assert.True(t, modified, "the wrapped modifier should be called")
+	assert.Equal(t, map[string]string{
+		"app":                                   "agent",
+		agentsv1alpha1.LabelSandboxClaimOrdinal: "2",
+		agentsv1alpha1.LabelSandboxClaimAlias:   "peers-2",
+	}, sbx.Labels)
+	assert.Equal(t, "peers-2", sbx.Spec.Template.Labels[agentsv1alpha1.LabelSandboxClaimAlias])
+}
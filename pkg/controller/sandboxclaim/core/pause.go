@@ -0,0 +1,66 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+	"github.com/openkruise/agents/pkg/utils/conditions"
+)
+
+// isPaused reports whether claiming is suspended by spec.paused
+func isPaused(status *agentsv1alpha1.SandboxClaimStatus) bool {
+	return conditions.IsTrue(status.Conditions, string(agentsv1alpha1.SandboxClaimConditionPaused))
+}
+
+// pauseClaim suspends claiming of a paused claim, keeping its phase and claimed sandboxes. The Paused condition
+// records since when the claim is paused, so that the claim timeout is resumed from where it stopped.
+func pauseClaim(status *agentsv1alpha1.SandboxClaimStatus, claim *agentsv1alpha1.SandboxClaim, now time.Time) *agentsv1alpha1.SandboxClaimStatus {
+	message := fmt.Sprintf("Claiming paused by spec.paused: %d/%d claimed", status.ClaimedReplicas, getDesiredReplicas(claim))
+	status.Message = message
+	if isPaused(status) {
+		return status
+	}
+	conditions.NewBuilder(&status.Conditions, status.ObservedGeneration).WithTime(metav1.NewTime(now)).
+		True(string(agentsv1alpha1.SandboxClaimConditionPaused), "ClaimPaused", message)
+	recordHistory(status, "ClaimPaused", message)
+	return status
+}
+
+// resumeClaim resumes claiming of a claim paused by pauseClaim once unpaused. The claim start time is shifted by the
+// time paused, so that the claim is not timed out for the time it did not claim. It returns the time paused, and
+// false if the claim was not paused.
+func resumeClaim(status *agentsv1alpha1.SandboxClaimStatus, now time.Time) (time.Duration, bool) {
+	cond := conditions.Get(status.Conditions, string(agentsv1alpha1.SandboxClaimConditionPaused))
+	if cond == nil || cond.Status != metav1.ConditionTrue {
+		return 0, false
+	}
+	paused := max(now.Sub(cond.LastTransitionTime.Time), 0)
+	if status.ClaimStartTime != nil {
+		status.ClaimStartTime = &metav1.Time{Time: status.ClaimStartTime.Add(paused)}
+	}
+	message := fmt.Sprintf("Claiming resumed after being paused for %v", paused.Round(time.Second))
+	conditions.NewBuilder(&status.Conditions, status.ObservedGeneration).WithTime(metav1.NewTime(now)).
+		False(string(agentsv1alpha1.SandboxClaimConditionPaused), "ClaimResumed", message)
+	status.Message = message
+	recordHistory(status, "ClaimResumed", message)
+	return paused, true
+}
@@ -0,0 +1,170 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+	"github.com/openkruise/agents/pkg/utils/conditions"
+)
+
+func TestCalculateClaimStatus_Paused(t *testing.T) {
+	now := time.Now()
+	pausedCondition := func(since time.Time) metav1.Condition {
+		return metav1.Condition{
+			Type:               string(agentsv1alpha1.SandboxClaimConditionPaused),
+			Status:             metav1.ConditionTrue,
+			Reason:             "ClaimPaused",
+			LastTransitionTime: metav1.NewTime(since),
+		}
+	}
+
+	tests := []struct {
+		name            string
+		paused          bool
+		annotations     map[string]string
+		status          agentsv1alpha1.SandboxClaimStatus
+		expectPhase     agentsv1alpha1.SandboxClaimPhase
+		expectPaused    bool
+		expectSkip      bool
+		expectStartTime time.Time
+	}{
+		{
+			name:         "new claim starts paused",
+			paused:       true,
+			expectPhase:  agentsv1alpha1.SandboxClaimPhaseClaiming,
+			expectPaused: true,
+			expectSkip:   true,
+		},
+		{
+			name:   "paused claim is not timed out",
+			paused: true,
+			status: agentsv1alpha1.SandboxClaimStatus{
+				Phase:           agentsv1alpha1.SandboxClaimPhaseClaiming,
+				ClaimedReplicas: 1,
+				ClaimStartTime:  &metav1.Time{Time: now.Add(-time.Hour)},
+			},
+			expectPhase:     agentsv1alpha1.SandboxClaimPhaseClaiming,
+			expectPaused:    true,
+			expectSkip:      true,
+			expectStartTime: now.Add(-time.Hour),
+		},
+		{
+			name: "resumed claim times out from where it stopped",
+			status: agentsv1alpha1.SandboxClaimStatus{
+				Phase:           agentsv1alpha1.SandboxClaimPhaseClaiming,
+				ClaimedReplicas: 1,
+				ClaimStartTime:  &metav1.Time{Time: now.Add(-10*time.Minute - 30*time.Second)},
+				Conditions:      []metav1.Condition{pausedCondition(now.Add(-10 * time.Minute))},
+			},
+			expectPhase:     agentsv1alpha1.SandboxClaimPhaseClaiming,
+			expectStartTime: now.Add(-30 * time.Second),
+		},
+		{
+			name:   "paused claim with all replicas claimed completes",
+			paused: true,
+			status: agentsv1alpha1.SandboxClaimStatus{
+				Phase:           agentsv1alpha1.SandboxClaimPhaseClaiming,
+				ClaimedReplicas: 2,
+				ClaimStartTime:  &metav1.Time{Time: now.Add(-time.Minute)},
+				Conditions:      []metav1.Condition{pausedCondition(now.Add(-time.Minute))},
+			},
+			expectPhase:  agentsv1alpha1.SandboxClaimPhaseCompleted,
+			expectPaused: true,
+			expectSkip:   true,
+		},
+		{
+			name:        "paused claim is canceled",
+			paused:      true,
+			annotations: map[string]string{agentsv1alpha1.AnnotationClaimCancel: "true"},
+			status: agentsv1alpha1.SandboxClaimStatus{
+				Phase:          agentsv1alpha1.SandboxClaimPhaseClaiming,
+				ClaimStartTime: &metav1.Time{Time: now.Add(-time.Minute)},
+				Conditions:     []metav1.Condition{pausedCondition(now.Add(-time.Minute))},
+			},
+			expectPhase:  agentsv1alpha1.SandboxClaimPhaseCompleted,
+			expectPaused: true,
+			expectSkip:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claim := &agentsv1alpha1.SandboxClaim{
+				ObjectMeta: metav1.ObjectMeta{Name: "claim", Namespace: "default", Annotations: tt.annotations},
+				Spec: agentsv1alpha1.SandboxClaimSpec{
+					TemplateName: "pool",
+					Replicas:     int32Ptr(2),
+					ClaimTimeout: &metav1.Duration{Duration: time.Minute},
+					Paused:       tt.paused,
+				},
+			}
+			newStatus, skip := CalculateClaimStatus(ClaimArgs{
+				Claim:      claim,
+				SandboxSet: &agentsv1alpha1.SandboxSet{ObjectMeta: metav1.ObjectMeta{Name: "pool", Namespace: "default"}},
+				NewStatus:  tt.status.DeepCopy(),
+			})
+			assert.Equal(t, tt.expectSkip, skip)
+			assert.Equal(t, tt.expectPhase, newStatus.Phase)
+			assert.Equal(t, tt.expectPaused, conditions.IsTrue(newStatus.Conditions, string(agentsv1alpha1.SandboxClaimConditionPaused)))
+			assert.False(t, conditions.IsTrue(newStatus.Conditions, string(agentsv1alpha1.SandboxClaimConditionTimedOut)))
+			if !tt.expectStartTime.IsZero() {
+				require.NotNil(t, newStatus.ClaimStartTime)
+				assert.WithinDuration(t, tt.expectStartTime, newStatus.ClaimStartTime.Time, time.Second)
+			}
+		})
+	}
+}
+
+func TestPauseAndResumeClaim(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	claim := &agentsv1alpha1.SandboxClaim{Spec: agentsv1alpha1.SandboxClaimSpec{Replicas: int32Ptr(3), Paused: true}}
+	status := &agentsv1alpha1.SandboxClaimStatus{
+		Phase:           agentsv1alpha1.SandboxClaimPhaseClaiming,
+		ClaimedReplicas: 1,
+		ClaimStartTime:  &metav1.Time{Time: now.Add(-time.Minute)},
+	}
+
+	pauseClaim(status, claim, now)
+	// pausing again keeps the time it is paused since
+	pauseClaim(status, claim, now.Add(time.Minute))
+	cond := conditions.Get(status.Conditions, string(agentsv1alpha1.SandboxClaimConditionPaused))
+	require.NotNil(t, cond)
+	assert.Equal(t, metav1.ConditionTrue, cond.Status)
+	assert.True(t, cond.LastTransitionTime.Equal(&metav1.Time{Time: now}))
+	assert.Equal(t, "Claiming paused by spec.paused: 1/3 claimed", status.Message)
+
+	paused, ok := resumeClaim(status, now.Add(5*time.Minute))
+	assert.True(t, ok)
+	assert.Equal(t, 5*time.Minute, paused)
+	assert.True(t, status.ClaimStartTime.Time.Equal(now.Add(4*time.Minute)))
+	assert.True(t, conditions.IsFalse(status.Conditions, string(agentsv1alpha1.SandboxClaimConditionPaused)))
+	assert.Equal(t, "Claiming resumed after being paused for 5m0s", status.Message)
+
+	_, ok = resumeClaim(status, now.Add(10*time.Minute))
+	assert.False(t, ok)
+	var reasons []string
+	for _, entry := range status.History {
+		reasons = append(reasons, entry.Reason)
+	}
+	assert.Equal(t, []string{"ClaimPaused", "ClaimResumed"}, reasons)
+}
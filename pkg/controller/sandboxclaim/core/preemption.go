@@ -0,0 +1,151 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+	"github.com/openkruise/agents/pkg/utils/conditions"
+	"github.com/openkruise/agents/pkg/utils/sandboxstate"
+	"github.com/openkruise/agents/pkg/utils/sandboxutils"
+)
+
+// preemptionPolicy returns the spec.preemptionPolicy of the claim, defaulting to Never
+func preemptionPolicy(claim *agentsv1alpha1.SandboxClaim) agentsv1alpha1.SandboxClaimPreemptionPolicy {
+	if claim.Spec.PreemptionPolicy == "" {
+		return agentsv1alpha1.SandboxClaimPreemptNever
+	}
+	return claim.Spec.PreemptionPolicy
+}
+
+// lowerPriorityClaims returns the claims of the pool with a lower priority than the claim, given the claims in the
+// namespace of the pool, in the order they are preempted: the lowest priority first, and the latest started first
+// among the same priority.
+func lowerPriorityClaims(claim *agentsv1alpha1.SandboxClaim, sandboxSet *agentsv1alpha1.SandboxSet,
+	claims []agentsv1alpha1.SandboxClaim) []*agentsv1alpha1.SandboxClaim {
+	priority := claimPriority(claim)
+	var lower []*agentsv1alpha1.SandboxClaim
+	for i := range claims {
+		other := &claims[i]
+		if other.UID == claim.UID || sandboxutils.ClaimSandboxSetName(other) != sandboxSet.Name || claimPriority(other) >= priority {
+			continue
+		}
+		lower = append(lower, other)
+	}
+	slices.SortStableFunc(lower, func(a, b *agentsv1alpha1.SandboxClaim) int {
+		if diff := cmp.Compare(claimPriority(a), claimPriority(b)); diff != 0 {
+			return diff
+		}
+		if diff := claimStartTime(b).Compare(claimStartTime(a)); diff != 0 {
+			return diff
+		}
+		return cmp.Compare(a.Name, b.Name)
+	})
+	return lower
+}
+
+// claimStartTime returns when the claim started claiming, falling back to its creation
+func claimStartTime(claim *agentsv1alpha1.SandboxClaim) time.Time {
+	if claim.Status.ClaimStartTime != nil {
+		return claim.Status.ClaimStartTime.Time
+	}
+	return claim.CreationTimestamp.Time
+}
+
+// selectPreemptedSandboxes picks up to needed sandboxes claimed by the victim to release, the latest claimed first.
+// Quarantined sandboxes are never picked, and the ones being deleted are still being preempted, so they count as
+// picked already. It returns the picked sandboxes and how many more are needed.
+func selectPreemptedSandboxes(claimed []*agentsv1alpha1.Sandbox, needed int, now time.Time) ([]*agentsv1alpha1.Sandbox, int) {
+	alive := make([]*agentsv1alpha1.Sandbox, 0, len(claimed))
+	for _, sbx := range claimed {
+		if sbx.DeletionTimestamp != nil {
+			needed--
+			continue
+		}
+		if state, _ := sandboxstate.Evaluate(sbx, now, sandboxstate.Policy{}); state == agentsv1alpha1.SandboxStateDead || sbx.Spec.Quarantine != nil {
+			continue
+		}
+		alive = append(alive, sbx)
+	}
+	if needed <= 0 {
+		return nil, needed
+	}
+	sortForRelease(alive, agentsv1alpha1.SandboxClaimReleaseNewest)
+	picked := alive[:min(needed, len(alive))]
+	return picked, needed - len(picked)
+}
+
+// preemptLowerPriority releases up to needed sandboxes claimed by the claims of the pool with a lower priority than
+// the claim, given the claims in the namespace of the pool, and returns how many are released. Every preempted claim
+// gets a Preempted condition and an event recording the claim it was preempted by.
+func (c *commonControl) preemptLowerPriority(ctx context.Context, claim *agentsv1alpha1.SandboxClaim,
+	sandboxSet *agentsv1alpha1.SandboxSet, claims []agentsv1alpha1.SandboxClaim, needed int) (int, error) {
+	log := logf.FromContext(ctx)
+	now := time.Now()
+	preempted := 0
+	for _, victim := range lowerPriorityClaims(claim, sandboxSet, claims) {
+		if needed <= 0 {
+			break
+		}
+		claimed, err := c.cache.ListSandboxWithUser(string(victim.UID))
+		if err != nil {
+			return preempted, err
+		}
+		var picked []*agentsv1alpha1.Sandbox
+		picked, needed = selectPreemptedSandboxes(claimed, needed, now)
+		released := 0
+		for _, sbx := range picked {
+			err = c.sandboxClient.SandboxClient.ApiV1alpha1().Sandboxes(sbx.Namespace).Delete(ctx, sbx.Name, metav1.DeleteOptions{})
+			if err != nil && !errors.IsNotFound(err) {
+				return preempted + released, err
+			}
+			log.Info("Preempted sandbox of claim with lower priority", "sandbox", klog.KObj(sbx), "victim", klog.KObj(victim))
+			released++
+		}
+		if released == 0 {
+			continue
+		}
+		preempted += released
+		c.recorder.Event(claim, "Normal", "PreemptedLowerPriority",
+			fmt.Sprintf("Released %d sandbox(es) claimed by claim %s of priority %d", released, victim.Name, claimPriority(victim)))
+		message := fmt.Sprintf("Released %d claimed sandbox(es) for claim %s of priority %d", released, claim.Name, claimPriority(claim))
+		c.recorder.Event(victim, "Warning", "Preempted", message)
+		if err = c.markPreempted(ctx, victim, message); err != nil {
+			return preempted, fmt.Errorf("failed to mark claim %s preempted: %w", victim.Name, err)
+		}
+	}
+	return preempted, nil
+}
+
+// markPreempted sets the Preempted condition of the preempted claim, which keeps it when claiming the replacements
+func (c *commonControl) markPreempted(ctx context.Context, victim *agentsv1alpha1.SandboxClaim, message string) error {
+	victim = victim.DeepCopy()
+	conditions.NewBuilder(&victim.Status.Conditions, victim.Status.ObservedGeneration).
+		True(string(agentsv1alpha1.SandboxClaimConditionPreempted), "PreemptedByHigherPriority", message)
+	recordHistory(&victim.Status, "Preempted", message)
+	return c.Status().Update(ctx, victim)
+}
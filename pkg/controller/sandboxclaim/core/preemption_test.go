@@ -0,0 +1,208 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+	"github.com/openkruise/agents/pkg/sandbox-manager/infra/sandboxcr"
+	"github.com/openkruise/agents/pkg/utils/conditions"
+)
+
+func TestPreemptionPolicy(t *testing.T) {
+	claim := &agentsv1alpha1.SandboxClaim{}
+	assert.Equal(t, agentsv1alpha1.SandboxClaimPreemptNever, preemptionPolicy(claim))
+	claim.Spec.PreemptionPolicy = agentsv1alpha1.SandboxClaimPreemptLowerPriority
+	assert.Equal(t, agentsv1alpha1.SandboxClaimPreemptLowerPriority, preemptionPolicy(claim))
+}
+
+func TestLowerPriorityClaims(t *testing.T) {
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	newClaim := func(name, template string, priority *int32, started time.Time) agentsv1alpha1.SandboxClaim {
+		return agentsv1alpha1.SandboxClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: name, UID: types.UID(name + "-uid")},
+			Spec:       agentsv1alpha1.SandboxClaimSpec{TemplateName: template, Priority: priority},
+			Status:     agentsv1alpha1.SandboxClaimStatus{ClaimStartTime: &metav1.Time{Time: started}},
+		}
+	}
+	sandboxSet := &agentsv1alpha1.SandboxSet{ObjectMeta: metav1.ObjectMeta{Name: "pool"}}
+	claims := []agentsv1alpha1.SandboxClaim{
+		newClaim("high", "pool", ptr.To[int32](10), base),
+		newClaim("equal", "pool", ptr.To[int32](5), base),
+		newClaim("low-old", "pool", ptr.To[int32](1), base),
+		newClaim("low-new", "pool", ptr.To[int32](1), base.Add(time.Minute)),
+		newClaim("default", "pool", nil, base),
+		newClaim("other-pool", "other", nil, base),
+	}
+	claim := newClaim("claim", "pool", ptr.To[int32](5), base)
+	claims = append(claims, claim)
+
+	var names []string
+	for _, victim := range lowerPriorityClaims(&claim, sandboxSet, claims) {
+		names = append(names, victim.Name)
+	}
+	assert.Equal(t, []string{"default", "low-new", "low-old"}, names)
+}
+
+func TestSelectPreemptedSandboxes(t *testing.T) {
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := base.Add(time.Hour)
+	newSandbox := func(name string, claimed time.Time) *agentsv1alpha1.Sandbox {
+		return &agentsv1alpha1.Sandbox{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Annotations: map[string]string{agentsv1alpha1.AnnotationClaimTime: claimed.Format(time.RFC3339)},
+			},
+			Status: agentsv1alpha1.SandboxStatus{
+				Phase:      agentsv1alpha1.SandboxRunning,
+				Conditions: []metav1.Condition{{Type: string(agentsv1alpha1.SandboxConditionReady), Status: metav1.ConditionTrue}},
+			},
+		}
+	}
+	deleting := newSandbox("deleting", base)
+	deleting.DeletionTimestamp = &metav1.Time{Time: base}
+	quarantined := newSandbox("quarantined", base.Add(3*time.Minute))
+	quarantined.Spec.Quarantine = &agentsv1alpha1.SandboxQuarantine{}
+	claimed := []*agentsv1alpha1.Sandbox{
+		newSandbox("old", base),
+		newSandbox("new", base.Add(2*time.Minute)),
+		quarantined,
+		deleting,
+	}
+
+	tests := []struct {
+		name           string
+		needed         int
+		expectPicked   []string
+		expectRemained int
+	}{
+		{
+			name:           "deleting sandbox counts as picked",
+			needed:         1,
+			expectRemained: 0,
+		},
+		{
+			name:           "latest claimed first",
+			needed:         2,
+			expectPicked:   []string{"new"},
+			expectRemained: 0,
+		},
+		{
+			name:           "not enough sandboxes",
+			needed:         5,
+			expectPicked:   []string{"new", "old"},
+			expectRemained: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			picked, remained := selectPreemptedSandboxes(claimed, tt.needed, now)
+			var names []string
+			for _, sbx := range picked {
+				names = append(names, sbx.Name)
+			}
+			assert.Equal(t, tt.expectPicked, names)
+			assert.Equal(t, tt.expectRemained, remained)
+		})
+	}
+}
+
+func TestCommonControl_preemptLowerPriority(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = agentsv1alpha1.AddToScheme(scheme)
+
+	cache, clientSet, err := sandboxcr.NewTestCache(t)
+	require.NoError(t, err)
+	go func() {
+		_ = cache.Run(t.Context())
+	}()
+
+	sandboxSet := &agentsv1alpha1.SandboxSet{ObjectMeta: metav1.ObjectMeta{Name: "pool", Namespace: "default"}}
+	newClaim := func(name string, priority int32) *agentsv1alpha1.SandboxClaim {
+		return &agentsv1alpha1.SandboxClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", UID: types.UID(name + "-uid")},
+			Spec:       agentsv1alpha1.SandboxClaimSpec{TemplateName: sandboxSet.Name, Priority: ptr.To(priority)},
+		}
+	}
+	claim := newClaim("high", 10)
+	low, lowest := newClaim("low", 1), newClaim("lowest", 0)
+	for _, victim := range []*agentsv1alpha1.SandboxClaim{low, lowest} {
+		for i := 0; i < 2; i++ {
+			CreateSandboxWithStatus(t, clientSet.SandboxClient, &agentsv1alpha1.Sandbox{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        fmt.Sprintf("%s-sbx-%d", victim.Name, i),
+					Namespace:   "default",
+					Annotations: map[string]string{agentsv1alpha1.AnnotationOwner: string(victim.UID)},
+				},
+				Status: agentsv1alpha1.SandboxStatus{
+					Phase:      agentsv1alpha1.SandboxRunning,
+					Conditions: []metav1.Condition{{Type: string(agentsv1alpha1.SandboxConditionReady), Status: metav1.ConditionTrue}},
+				},
+			})
+		}
+	}
+	require.Eventually(t, func() bool {
+		sandboxes, err := cache.ListSandboxWithUser(string(low.UID))
+		return err == nil && len(sandboxes) == 2
+	}, time.Second, 10*time.Millisecond)
+
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).
+		WithObjects(claim, low, lowest).
+		WithStatusSubresource(&agentsv1alpha1.SandboxClaim{}).
+		Build()
+	recorder := record.NewFakeRecorder(10)
+	control := NewCommonControl(fakeClient, recorder, clientSet, cache).(*commonControl)
+
+	claims := &agentsv1alpha1.SandboxClaimList{}
+	require.NoError(t, fakeClient.List(t.Context(), claims))
+	preempted, err := control.preemptLowerPriority(t.Context(), claim, sandboxSet, claims.Items, 3)
+	require.NoError(t, err)
+	assert.Equal(t, 3, preempted)
+
+	// the lowest priority claim is preempted first
+	for _, name := range []string{"lowest-sbx-0", "lowest-sbx-1"} {
+		_, err := clientSet.SandboxClient.ApiV1alpha1().Sandboxes("default").Get(t.Context(), name, metav1.GetOptions{})
+		assert.True(t, apierrors.IsNotFound(err), "sandbox %s should be preempted", name)
+	}
+	remained, err := clientSet.SandboxClient.ApiV1alpha1().Sandboxes("default").List(t.Context(), metav1.ListOptions{})
+	require.NoError(t, err)
+	assert.Len(t, remained.Items, 1)
+
+	for _, victim := range []*agentsv1alpha1.SandboxClaim{lowest, low} {
+		got := &agentsv1alpha1.SandboxClaim{}
+		require.NoError(t, fakeClient.Get(t.Context(), client.ObjectKeyFromObject(victim), got))
+		cond := conditions.Get(got.Status.Conditions, string(agentsv1alpha1.SandboxClaimConditionPreempted))
+		require.NotNil(t, cond, "claim %s should be marked preempted", victim.Name)
+		assert.Equal(t, "PreemptedByHigherPriority", cond.Reason)
+		assert.Contains(t, cond.Message, claim.Name)
+	}
+	assert.Len(t, recorder.Events, 4)
+}
@@ -0,0 +1,50 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+	"github.com/openkruise/agents/pkg/utils/sandboxutils"
+)
+
+// claimPriority returns the priority of the claim, 0 if unset
+func claimPriority(claim *agentsv1alpha1.SandboxClaim) int32 {
+	if claim.Spec.Priority == nil {
+		return 0
+	}
+	return *claim.Spec.Priority
+}
+
+// higherPriorityDemand returns the number of sandboxes still needed by the claiming claims of the pool with a higher
+// priority than the claim, given the claims in the namespace of the pool, and how many claims need them. Paused and
+// dry-run claims claim nothing, so that they do not starve the claim however high their priority is.
+func higherPriorityDemand(claim *agentsv1alpha1.SandboxClaim, sandboxSet *agentsv1alpha1.SandboxSet,
+	claims []agentsv1alpha1.SandboxClaim) (demand int32, competing int) {
+	priority := claimPriority(claim)
+	for i := range claims {
+		other := &claims[i]
+		if other.UID == claim.UID || sandboxutils.ClaimSandboxSetName(other) != sandboxSet.Name || other.Spec.Paused || other.Spec.DryRun ||
+			other.Status.Phase != agentsv1alpha1.SandboxClaimPhaseClaiming || claimPriority(other) <= priority {
+			continue
+		}
+		if remaining := remainingReplicas(other); remaining > 0 {
+			demand += remaining
+			competing++
+		}
+	}
+	return demand, competing
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+)
+
+func TestHigherPriorityDemand(t *testing.T) {
+	sandboxSet := &agentsv1alpha1.SandboxSet{ObjectMeta: metav1.ObjectMeta{Name: "pool", Namespace: "default"}}
+	withPriority := func(claim *agentsv1alpha1.SandboxClaim, priority int32) *agentsv1alpha1.SandboxClaim {
+		claim.Spec.Priority = ptr.To(priority)
+		return claim
+	}
+	paused := func(claim *agentsv1alpha1.SandboxClaim) *agentsv1alpha1.SandboxClaim {
+		claim.Spec.Paused = true
+		return claim
+	}
+	dryRun := func(claim *agentsv1alpha1.SandboxClaim) *agentsv1alpha1.SandboxClaim {
+		claim.Spec.DryRun = true
+		return claim
+	}
+
+	tests := []struct {
+		name              string
+		claim             *agentsv1alpha1.SandboxClaim
+		others            []*agentsv1alpha1.SandboxClaim
+		expectedDemand    int32
+		expectedCompeting int
+	}{
+		{
+			name:  "no competing claims",
+			claim: newQueuedClaim("a", "pool", 0, agentsv1alpha1.SandboxClaimPhaseClaiming, 2, 0),
+		},
+		{
+			name:  "remaining replicas of claiming claims of higher priority",
+			claim: newQueuedClaim("a", "pool", 0, agentsv1alpha1.SandboxClaimPhaseClaiming, 2, 0),
+			others: []*agentsv1alpha1.SandboxClaim{
+				withPriority(newQueuedClaim("high", "pool", time.Minute, agentsv1alpha1.SandboxClaimPhaseClaiming, 5, 2), 10),
+				withPriority(newQueuedClaim("higher", "pool", time.Minute, agentsv1alpha1.SandboxClaimPhaseClaiming, 1, 0), 20),
+			},
+			expectedDemand:    4,
+			expectedCompeting: 2,
+		},
+		{
+			name:  "claims of equal or lower priority are ignored",
+			claim: withPriority(newQueuedClaim("a", "pool", 0, agentsv1alpha1.SandboxClaimPhaseClaiming, 2, 0), 10),
+			others: []*agentsv1alpha1.SandboxClaim{
+				withPriority(newQueuedClaim("equal", "pool", time.Minute, agentsv1alpha1.SandboxClaimPhaseClaiming, 5, 0), 10),
+				newQueuedClaim("default", "pool", time.Minute, agentsv1alpha1.SandboxClaimPhaseClaiming, 5, 0),
+				withPriority(newQueuedClaim("negative", "pool", time.Minute, agentsv1alpha1.SandboxClaimPhaseClaiming, 5, 0), -1),
+			},
+		},
+		{
+			name:  "claims not claiming from the pool are ignored",
+			claim: newQueuedClaim("a", "pool", 0, agentsv1alpha1.SandboxClaimPhaseClaiming, 2, 0),
+			others: []*agentsv1alpha1.SandboxClaim{
+				withPriority(newQueuedClaim("other-pool", "other", time.Minute, agentsv1alpha1.SandboxClaimPhaseClaiming, 5, 0), 10),
+				withPriority(newQueuedClaim("queued", "pool", time.Minute, agentsv1alpha1.SandboxClaimPhaseQueued, 5, 0), 10),
+				withPriority(newQueuedClaim("completed", "pool", time.Minute, agentsv1alpha1.SandboxClaimPhaseCompleted, 5, 1), 10),
+				withPriority(newQueuedClaim("fulfilled", "pool", time.Minute, agentsv1alpha1.SandboxClaimPhaseClaiming, 5, 5), 10),
+			},
+		},
+		{
+			name:  "paused and dry-run claims of higher priority do not block the claim",
+			claim: newQueuedClaim("a", "pool", 0, agentsv1alpha1.SandboxClaimPhaseClaiming, 2, 0),
+			others: []*agentsv1alpha1.SandboxClaim{
+				paused(withPriority(newQueuedClaim("paused", "pool", time.Minute, agentsv1alpha1.SandboxClaimPhaseClaiming, 5, 0), 10)),
+				dryRun(withPriority(newQueuedClaim("dry-run", "pool", time.Minute, agentsv1alpha1.SandboxClaimPhaseClaiming, 5, 0), 10)),
+				withPriority(newQueuedClaim("high", "pool", time.Minute, agentsv1alpha1.SandboxClaimPhaseClaiming, 1, 0), 10),
+			},
+			expectedDemand:    1,
+			expectedCompeting: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := make([]agentsv1alpha1.SandboxClaim, 0, len(tt.others))
+			for _, other := range tt.others {
+				claims = append(claims, *other)
+			}
+			demand, competing := higherPriorityDemand(tt.claim, sandboxSet, claims)
+			assert.Equal(t, tt.expectedDemand, demand)
+			assert.Equal(t, tt.expectedCompeting, competing)
+		})
+	}
+}
@@ -0,0 +1,69 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"fmt"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+	"github.com/openkruise/agents/pkg/utils/conditions"
+)
+
+// SetProgressConditions derives the Progressing and ReplicasReady conditions from the phase and the claimed replicas
+// of the claim, so that generic tooling can tell a claim in progress from a healthy or a stopped one without knowing
+// its phases.
+func SetProgressConditions(claim *agentsv1alpha1.SandboxClaim, status *agentsv1alpha1.SandboxClaimStatus) {
+	if status.Phase == "" {
+		return
+	}
+	desired := getDesiredReplicas(claim)
+	claimed := fmt.Sprintf("%d/%d claimed", status.ClaimedReplicas, desired)
+	builder := conditions.NewBuilder(&status.Conditions, status.ObservedGeneration)
+
+	progressing := string(agentsv1alpha1.SandboxClaimConditionProgressing)
+	switch {
+	case status.Phase == agentsv1alpha1.SandboxClaimPhaseFailed:
+		builder.False(progressing, agentsv1alpha1.SandboxClaimProgressingReasonFailed, "Claim failed: "+status.Message)
+	case status.Phase == agentsv1alpha1.SandboxClaimPhaseCompleted:
+		message := "Claim completed"
+		if cond := conditions.Get(status.Conditions, string(agentsv1alpha1.SandboxClaimConditionCompleted)); cond != nil {
+			message = fmt.Sprintf("Claim completed with reason %s", cond.Reason)
+		}
+		builder.False(progressing, agentsv1alpha1.SandboxClaimProgressingReasonCompleted, message)
+	case status.Phase == agentsv1alpha1.SandboxClaimPhasePendingApproval:
+		builder.False(progressing, agentsv1alpha1.SandboxClaimProgressingReasonPendingApproval, "Waiting for approval")
+	case isPaused(status):
+		builder.False(progressing, agentsv1alpha1.SandboxClaimProgressingReasonPaused, "Claiming paused by spec.paused")
+	case status.Phase == agentsv1alpha1.SandboxClaimPhaseQueued:
+		builder.True(progressing, agentsv1alpha1.SandboxClaimProgressingReasonQueued, "Waiting in the claim queue")
+	default:
+		builder.True(progressing, agentsv1alpha1.SandboxClaimProgressingReasonClaiming, "Claiming sandboxes: "+claimed)
+	}
+
+	replicasReady := string(agentsv1alpha1.SandboxClaimConditionReplicasReady)
+	switch {
+	case claim.Spec.DryRun:
+		// a dry-run claim never claims its replicas
+		builder.Remove(replicasReady)
+	case isReplicasMet(claim, status):
+		builder.True(replicasReady, agentsv1alpha1.SandboxClaimReplicasReadyReasonAllReplicasClaimed, claimed)
+	case IsClaimFinished(status):
+		builder.False(replicasReady, agentsv1alpha1.SandboxClaimReplicasReadyReasonInsufficientReplicas, claimed)
+	default:
+		builder.False(replicasReady, agentsv1alpha1.SandboxClaimReplicasReadyReasonClaimingReplicas, claimed)
+	}
+}
@@ -0,0 +1,173 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+	"github.com/openkruise/agents/pkg/utils/conditions"
+)
+
+func TestCalculateClaimStatus_ProgressConditions(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name                string
+		spec                agentsv1alpha1.SandboxClaimSpec
+		status              agentsv1alpha1.SandboxClaimStatus
+		expectProgressing   metav1.ConditionStatus
+		expectProgressingBy string
+		expectReady         metav1.ConditionStatus
+		expectReadyBy       string
+		expectReadyMessage  string
+	}{
+		{
+			name:                "new claim is claiming",
+			expectProgressing:   metav1.ConditionTrue,
+			expectProgressingBy: agentsv1alpha1.SandboxClaimProgressingReasonClaiming,
+			expectReady:         metav1.ConditionFalse,
+			expectReadyBy:       agentsv1alpha1.SandboxClaimReplicasReadyReasonClaimingReplicas,
+			expectReadyMessage:  "0/2 claimed",
+		},
+		{
+			name: "queued claim",
+			status: agentsv1alpha1.SandboxClaimStatus{
+				Phase:          agentsv1alpha1.SandboxClaimPhaseQueued,
+				ClaimStartTime: &metav1.Time{Time: now},
+			},
+			expectProgressing:   metav1.ConditionTrue,
+			expectProgressingBy: agentsv1alpha1.SandboxClaimProgressingReasonQueued,
+			expectReady:         metav1.ConditionFalse,
+			expectReadyBy:       agentsv1alpha1.SandboxClaimReplicasReadyReasonClaimingReplicas,
+			expectReadyMessage:  "0/2 claimed",
+		},
+		{
+			name:                "claim waiting for approval",
+			spec:                agentsv1alpha1.SandboxClaimSpec{RequiresApproval: true},
+			expectProgressing:   metav1.ConditionFalse,
+			expectProgressingBy: agentsv1alpha1.SandboxClaimProgressingReasonPendingApproval,
+			expectReady:         metav1.ConditionFalse,
+			expectReadyBy:       agentsv1alpha1.SandboxClaimReplicasReadyReasonClaimingReplicas,
+			expectReadyMessage:  "0/2 claimed",
+		},
+		{
+			name: "paused claim",
+			spec: agentsv1alpha1.SandboxClaimSpec{Paused: true},
+			status: agentsv1alpha1.SandboxClaimStatus{
+				Phase:           agentsv1alpha1.SandboxClaimPhaseClaiming,
+				ClaimedReplicas: 1,
+				ClaimStartTime:  &metav1.Time{Time: now},
+			},
+			expectProgressing:   metav1.ConditionFalse,
+			expectProgressingBy: agentsv1alpha1.SandboxClaimProgressingReasonPaused,
+			expectReady:         metav1.ConditionFalse,
+			expectReadyBy:       agentsv1alpha1.SandboxClaimReplicasReadyReasonClaimingReplicas,
+			expectReadyMessage:  "1/2 claimed",
+		},
+		{
+			name: "claim of all replicas",
+			status: agentsv1alpha1.SandboxClaimStatus{
+				Phase:           agentsv1alpha1.SandboxClaimPhaseClaiming,
+				ClaimedReplicas: 2,
+				ClaimStartTime:  &metav1.Time{Time: now},
+			},
+			expectProgressing:   metav1.ConditionFalse,
+			expectProgressingBy: agentsv1alpha1.SandboxClaimProgressingReasonCompleted,
+			expectReady:         metav1.ConditionTrue,
+			expectReadyBy:       agentsv1alpha1.SandboxClaimReplicasReadyReasonAllReplicasClaimed,
+			expectReadyMessage:  "2/2 claimed",
+		},
+		{
+			name: "claim timed out short of its replicas",
+			spec: agentsv1alpha1.SandboxClaimSpec{ClaimTimeout: &metav1.Duration{Duration: time.Minute}},
+			status: agentsv1alpha1.SandboxClaimStatus{
+				Phase:           agentsv1alpha1.SandboxClaimPhaseClaiming,
+				ClaimedReplicas: 1,
+				ClaimStartTime:  &metav1.Time{Time: now.Add(-time.Hour)},
+			},
+			expectProgressing:   metav1.ConditionFalse,
+			expectProgressingBy: agentsv1alpha1.SandboxClaimProgressingReasonCompleted,
+			expectReady:         metav1.ConditionFalse,
+			expectReadyBy:       agentsv1alpha1.SandboxClaimReplicasReadyReasonInsufficientReplicas,
+			expectReadyMessage:  "1/2 claimed",
+		},
+		{
+			name: "claim failed on timeout",
+			spec: agentsv1alpha1.SandboxClaimSpec{
+				ClaimTimeout: &metav1.Duration{Duration: time.Minute},
+				OnTimeout:    agentsv1alpha1.SandboxClaimTimeoutFail,
+			},
+			status: agentsv1alpha1.SandboxClaimStatus{
+				Phase:           agentsv1alpha1.SandboxClaimPhaseClaiming,
+				ClaimedReplicas: 1,
+				ClaimStartTime:  &metav1.Time{Time: now.Add(-time.Hour)},
+			},
+			expectProgressing:   metav1.ConditionFalse,
+			expectProgressingBy: agentsv1alpha1.SandboxClaimProgressingReasonFailed,
+			expectReady:         metav1.ConditionFalse,
+			expectReadyBy:       agentsv1alpha1.SandboxClaimReplicasReadyReasonInsufficientReplicas,
+			expectReadyMessage:  "1/2 claimed",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := tt.spec
+			spec.TemplateName = "pool"
+			spec.Replicas = int32Ptr(2)
+			claim := &agentsv1alpha1.SandboxClaim{
+				ObjectMeta: metav1.ObjectMeta{Name: "claim", Namespace: "default"},
+				Spec:       spec,
+			}
+			newStatus, _ := CalculateClaimStatus(ClaimArgs{
+				Claim:      claim,
+				SandboxSet: &agentsv1alpha1.SandboxSet{ObjectMeta: metav1.ObjectMeta{Name: "pool", Namespace: "default"}},
+				NewStatus:  tt.status.DeepCopy(),
+			})
+
+			progressing := conditions.Get(newStatus.Conditions, string(agentsv1alpha1.SandboxClaimConditionProgressing))
+			require.NotNil(t, progressing)
+			assert.Equal(t, tt.expectProgressing, progressing.Status)
+			assert.Equal(t, tt.expectProgressingBy, progressing.Reason)
+			ready := conditions.Get(newStatus.Conditions, string(agentsv1alpha1.SandboxClaimConditionReplicasReady))
+			require.NotNil(t, ready)
+			assert.Equal(t, tt.expectReady, ready.Status)
+			assert.Equal(t, tt.expectReadyBy, ready.Reason)
+			assert.Equal(t, tt.expectReadyMessage, ready.Message)
+		})
+	}
+}
+
+func TestSetProgressConditions_CompletedReason(t *testing.T) {
+	claim := &agentsv1alpha1.SandboxClaim{Spec: agentsv1alpha1.SandboxClaimSpec{Replicas: int32Ptr(2)}}
+	status := TransitionToCompleted(&agentsv1alpha1.SandboxClaimStatus{Phase: agentsv1alpha1.SandboxClaimPhaseClaiming},
+		"SandboxSetNotFound", "SandboxSet pool not found")
+	SetProgressConditions(claim, status)
+
+	progressing := conditions.Get(status.Conditions, string(agentsv1alpha1.SandboxClaimConditionProgressing))
+	require.NotNil(t, progressing)
+	assert.Equal(t, "Claim completed with reason SandboxSetNotFound", progressing.Message)
+
+	// a new claim that has not been initialized yet has no conditions
+	status = &agentsv1alpha1.SandboxClaimStatus{}
+	SetProgressConditions(claim, status)
+	assert.Empty(t, status.Conditions)
+}
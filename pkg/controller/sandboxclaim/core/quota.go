@@ -0,0 +1,119 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+	"github.com/openkruise/agents/pkg/utils/conditions"
+)
+
+// namespaceQuota is the most restrictive SandboxQuota of a namespace and the sandboxes claimed in the namespace
+type namespaceQuota struct {
+	name    string
+	limit   int32
+	claimed int32
+}
+
+// headroom returns how many more sandboxes the claims of the namespace may claim
+func (q *namespaceQuota) headroom() int32 {
+	return max(q.limit-q.claimed, 0)
+}
+
+// getNamespaceQuota returns the most restrictive SandboxQuota of the namespace of the claim with the sandboxes claimed
+// by the claims of the namespace, of which the claim claimed currentCount. Nil if the namespace has no quota.
+func (c *commonControl) getNamespaceQuota(ctx context.Context, claim *agentsv1alpha1.SandboxClaim, currentCount int32) (*namespaceQuota, error) {
+	if !SandboxQuotaEnabled {
+		return nil, nil
+	}
+	quotas := &agentsv1alpha1.SandboxQuotaList{}
+	if err := c.List(ctx, quotas, client.InNamespace(claim.Namespace)); err != nil {
+		return nil, fmt.Errorf("failed to list sandboxquotas: %w", err)
+	}
+	var quota *namespaceQuota
+	for i := range quotas.Items {
+		item := &quotas.Items[i]
+		if item.DeletionTimestamp != nil {
+			continue
+		}
+		if quota == nil || item.Spec.MaxClaimedReplicas < quota.limit ||
+			(item.Spec.MaxClaimedReplicas == quota.limit && item.Name < quota.name) {
+			quota = &namespaceQuota{name: item.Name, limit: item.Spec.MaxClaimedReplicas}
+		}
+	}
+	if quota == nil {
+		return nil, nil
+	}
+
+	claims := &agentsv1alpha1.SandboxClaimList{}
+	if err := c.List(ctx, claims, client.InNamespace(claim.Namespace)); err != nil {
+		return nil, fmt.Errorf("failed to list sandboxclaims: %w", err)
+	}
+	quota.claimed = currentCount
+	for i := range claims.Items {
+		other := &claims.Items[i]
+		if other.UID == claim.UID {
+			continue
+		}
+		claimed, err := c.countClaimedSandboxes(ctx, other)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count claimed sandboxes of claim %s: %w", other.Name, err)
+		}
+		if other.Spec.ClaimGroup != nil {
+			// the sandboxes claimed by the group count until the cache observes them
+			claimed = ClaimGroups.Bound(other, claimed)
+		}
+		quota.claimed += claimed
+	}
+	return quota, nil
+}
+
+// exceedQuota stops claiming of a claim as its namespace reached the quota. The QuotaExceeded condition records since
+// when, and the event is only recorded once.
+func (c *commonControl) exceedQuota(claim *agentsv1alpha1.SandboxClaim, status *agentsv1alpha1.SandboxClaimStatus,
+	quota *namespaceQuota, needed, currentCount, desiredReplicas int32, now time.Time) {
+	status.Message = fmt.Sprintf("Waiting for SandboxQuota %s: %d/%d claimed", quota.name, currentCount, desiredReplicas)
+	if conditions.IsTrue(status.Conditions, string(agentsv1alpha1.SandboxClaimConditionQuotaExceeded)) {
+		return
+	}
+	message := fmt.Sprintf("Namespace claimed %d sandbox(es) of the %d allowed by SandboxQuota %s, %d more needed",
+		quota.claimed, quota.limit, quota.name, needed)
+	c.recorder.Event(claim, "Warning", "QuotaExceeded", message)
+	conditions.NewBuilder(&status.Conditions, status.ObservedGeneration).WithTime(metav1.NewTime(now)).
+		True(string(agentsv1alpha1.SandboxClaimConditionQuotaExceeded), "QuotaExceeded", message)
+	recordHistory(status, "QuotaExceeded", message)
+}
+
+// withinQuota turns the QuotaExceeded condition of a claim stopped by exceedQuota False once it may claim again.
+func withinQuota(status *agentsv1alpha1.SandboxClaimStatus, quota *namespaceQuota, now time.Time) {
+	if !conditions.IsTrue(status.Conditions, string(agentsv1alpha1.SandboxClaimConditionQuotaExceeded)) {
+		return
+	}
+	message := "Namespace has no SandboxQuota anymore"
+	if quota != nil {
+		message = fmt.Sprintf("SandboxQuota %s allows %d more sandbox(es)", quota.name, quota.headroom())
+	}
+	conditions.NewBuilder(&status.Conditions, status.ObservedGeneration).WithTime(metav1.NewTime(now)).
+		False(string(agentsv1alpha1.SandboxClaimConditionQuotaExceeded), "WithinQuota", message)
+	recordHistory(status, "WithinQuota", message)
+}
@@ -0,0 +1,153 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+	"github.com/openkruise/agents/pkg/sandbox-manager/infra/sandboxcr"
+	"github.com/openkruise/agents/pkg/utils/conditions"
+	"github.com/openkruise/agents/pkg/utils/fieldindex"
+)
+
+func newSandboxQuota(name string, limit int32) *agentsv1alpha1.SandboxQuota {
+	return &agentsv1alpha1.SandboxQuota{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec:       agentsv1alpha1.SandboxQuotaSpec{MaxClaimedReplicas: limit},
+	}
+}
+
+func TestCommonControl_getNamespaceQuota(t *testing.T) {
+	enabled := SandboxQuotaEnabled
+	t.Cleanup(func() { SandboxQuotaEnabled = enabled })
+	scheme := runtime.NewScheme()
+	_ = agentsv1alpha1.AddToScheme(scheme)
+
+	cache, clientSet, err := sandboxcr.NewTestCache(t)
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		_ = cache.Run(ctx)
+	}()
+
+	claim := newGroupMember("claim", agentsv1alpha1.SandboxClaimPhaseClaiming, 0)
+	claim.Spec.ClaimGroup = nil
+	member := newGroupMember("quota-member", agentsv1alpha1.SandboxClaimPhaseClaiming, 0)
+	ClaimGroups.SetBound(member, 2)
+	t.Cleanup(func() { ClaimGroups.Forget(member) })
+
+	tests := []struct {
+		name     string
+		enabled  bool
+		objects  []client.Object
+		expected *namespaceQuota
+	}{
+		{
+			name:    "quotas not enforced",
+			objects: []client.Object{newSandboxQuota("quota", 1)},
+		},
+		{
+			name:    "namespace without quota",
+			enabled: true,
+			objects: []client.Object{member},
+		},
+		{
+			name:     "most restrictive quota with the sandboxes bound for groups",
+			enabled:  true,
+			objects:  []client.Object{newSandboxQuota("large", 10), newSandboxQuota("small", 5), member},
+			expected: &namespaceQuota{name: "small", limit: 5, claimed: 3},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SandboxQuotaEnabled = tt.enabled
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(append(tt.objects, claim)...).Build()
+			control := NewCommonControl(fakeClient, record.NewFakeRecorder(10), clientSet, cache).(*commonControl)
+			quota, err := control.getNamespaceQuota(ctx, claim, 1)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, quota)
+		})
+	}
+}
+
+func TestCommonControl_EnsureClaimClaiming_Quota(t *testing.T) {
+	enabled := SandboxQuotaEnabled
+	t.Cleanup(func() { SandboxQuotaEnabled = enabled })
+	SandboxQuotaEnabled = true
+	scheme := runtime.NewScheme()
+	_ = agentsv1alpha1.AddToScheme(scheme)
+
+	cache, clientSet, err := sandboxcr.NewTestCache(t)
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		_ = cache.Run(ctx)
+	}()
+
+	claim := &agentsv1alpha1.SandboxClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "quota-claim", Namespace: "default", UID: "quota-claim-uid"},
+		Spec:       agentsv1alpha1.SandboxClaimSpec{TemplateName: "pool", Replicas: int32Ptr(2)},
+	}
+	sandboxSet := &agentsv1alpha1.SandboxSet{ObjectMeta: metav1.ObjectMeta{Name: "pool", Namespace: "default", UID: "pool-uid"}}
+	quota := newSandboxQuota("quota", 0)
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(claim, sandboxSet, quota).
+		WithIndex(&agentsv1alpha1.SandboxClaim{}, fieldindex.IndexNameForSandboxSetName, SandboxSetNameIndexFunc).Build()
+	recorder := record.NewFakeRecorder(10)
+	control := NewCommonControl(fakeClient, recorder, clientSet, cache)
+	newStatus := &agentsv1alpha1.SandboxClaimStatus{
+		Phase:          agentsv1alpha1.SandboxClaimPhaseClaiming,
+		ClaimStartTime: &metav1.Time{Time: time.Now()},
+	}
+
+	// the namespace reached its quota
+	strategy, err := control.EnsureClaimClaiming(ctx, ClaimArgs{Claim: claim, SandboxSet: sandboxSet, NewStatus: newStatus})
+	require.NoError(t, err)
+	assert.Equal(t, "QuotaExceeded", strategy.Reason)
+	assert.True(t, conditions.IsTrue(newStatus.Conditions, string(agentsv1alpha1.SandboxClaimConditionQuotaExceeded)))
+	assert.Equal(t, "Waiting for SandboxQuota quota: 0/2 claimed", newStatus.Message)
+	require.Len(t, recorder.Events, 1)
+	assert.Equal(t, "Warning QuotaExceeded Namespace claimed 0 sandbox(es) of the 0 allowed by SandboxQuota quota, 1 more needed",
+		<-recorder.Events)
+
+	_, err = control.EnsureClaimClaiming(ctx, ClaimArgs{Claim: claim, SandboxSet: sandboxSet, NewStatus: newStatus})
+	require.NoError(t, err)
+	assert.Empty(t, recorder.Events, "the exceeded quota is only recorded once")
+
+	// the quota is raised
+	quota.Spec.MaxClaimedReplicas = 5
+	require.NoError(t, fakeClient.Update(ctx, quota))
+	strategy, err = control.EnsureClaimClaiming(ctx, ClaimArgs{Claim: claim, SandboxSet: sandboxSet, NewStatus: newStatus})
+	require.NoError(t, err)
+	assert.NotEqual(t, "QuotaExceeded", strategy.Reason)
+	cond := conditions.Get(newStatus.Conditions, string(agentsv1alpha1.SandboxClaimConditionQuotaExceeded))
+	require.NotNil(t, cond)
+	assert.Equal(t, metav1.ConditionFalse, cond.Status)
+	assert.Equal(t, "SandboxQuota quota allows 5 more sandbox(es)", cond.Message)
+}
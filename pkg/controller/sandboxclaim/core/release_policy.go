@@ -0,0 +1,261 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+	"github.com/openkruise/agents/pkg/utils/conditions"
+	"github.com/openkruise/agents/pkg/utils/sandboxstate"
+)
+
+// releasePolicy returns the spec.releasePolicy of the claim, defaulting to Retain
+func releasePolicy(claim *agentsv1alpha1.SandboxClaim) agentsv1alpha1.SandboxClaimReleasePolicy {
+	if claim.Spec.ReleasePolicy == "" {
+		return agentsv1alpha1.SandboxClaimRetain
+	}
+	return claim.Spec.ReleasePolicy
+}
+
+// ttlPolicy returns the spec.ttlPolicy of the claim, defaulting to Retain
+func ttlPolicy(claim *agentsv1alpha1.SandboxClaim) agentsv1alpha1.SandboxClaimTTLPolicy {
+	if claim.Spec.TTLPolicy == "" {
+		return agentsv1alpha1.SandboxClaimTTLRetain
+	}
+	return claim.Spec.TTLPolicy
+}
+
+// deletionPolicy returns the spec.deletionPolicy of the claim, defaulting to Unbind
+func deletionPolicy(claim *agentsv1alpha1.SandboxClaim) agentsv1alpha1.SandboxClaimDeletionPolicy {
+	if claim.Spec.DeletionPolicy == "" {
+		return agentsv1alpha1.SandboxClaimDeletionUnbind
+	}
+	return claim.Spec.DeletionPolicy
+}
+
+// claimSandboxLabels are the labels set on the sandboxes by every claim, which are removed when unbinding them
+var claimSandboxLabels = []string{
+	agentsv1alpha1.LabelSandboxClaimName,
+	agentsv1alpha1.LabelSandboxClaimUID,
+	agentsv1alpha1.LabelSandboxClaimOrdinal,
+	agentsv1alpha1.LabelSandboxClaimAlias,
+}
+
+// claimSandboxAnnotations are the annotations set on the sandboxes by claiming them, which are removed when returning
+// them to the pool. The runtime access token is kept, as the runtime expects it until the next claim initializes it
+// again.
+var claimSandboxAnnotations = []string{
+	agentsv1alpha1.AnnotationOwner,
+	agentsv1alpha1.AnnotationClaimTime,
+	agentsv1alpha1.AnnotationInitRuntimeRequest,
+}
+
+// EnsureClaimDeleted releases the sandboxes still claimed by the deleting claim with its spec.deletionPolicy, so that
+// none of them is left claimed by a claim that no longer exists. Quarantined sandboxes are always unbound, but kept
+// out of the pool for their review.
+func (c *commonControl) EnsureClaimDeleted(ctx context.Context, claim *agentsv1alpha1.SandboxClaim) error {
+	log := logf.FromContext(ctx)
+	sandboxes, err := c.cache.ListSandboxWithUser(string(claim.UID))
+	if err != nil {
+		return err
+	}
+	policy := deletionPolicy(claim)
+	var deleted, unbound, returned int32
+	for _, sbx := range sandboxes {
+		if sbx.DeletionTimestamp != nil {
+			continue
+		}
+		if sbx.Spec.Quarantine != nil {
+			if err = c.unbindSandbox(ctx, claim, sbx, nil); err != nil {
+				return err
+			}
+			log.Info("Unbound quarantined sandbox of deleting claim", "sandbox", klog.KObj(sbx))
+			unbound++
+			continue
+		}
+		if policy == agentsv1alpha1.SandboxClaimDeletionUnbind {
+			pool, err := c.sandboxPool(ctx, sbx)
+			if err != nil {
+				return err
+			}
+			// sandboxes whose SandboxSet is gone have no pool to return to, they are deleted instead
+			if pool != nil {
+				if err = c.unbindSandbox(ctx, claim, sbx, pool); err != nil {
+					return err
+				}
+				log.Info("Returned sandbox of deleting claim to its pool", "sandbox", klog.KObj(sbx), "pool", pool.Name)
+				returned++
+				continue
+			}
+		}
+		err = c.sandboxClient.SandboxClient.ApiV1alpha1().Sandboxes(sbx.Namespace).Delete(ctx, sbx.Name, metav1.DeleteOptions{})
+		if err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+		log.Info("Deleted sandbox of deleting claim", "sandbox", klog.KObj(sbx))
+		deleted++
+	}
+	if deleted > 0 {
+		c.recorder.Event(claim, "Normal", "ClaimedSandboxesDeleted",
+			fmt.Sprintf("Deleted %d claimed sandbox(es) with the claim", deleted))
+	}
+	if returned > 0 {
+		c.recorder.Event(claim, "Normal", "ClaimedSandboxesReturned",
+			fmt.Sprintf("Returned %d claimed sandbox(es) to their pool", returned))
+	}
+	if unbound > 0 {
+		c.recorder.Event(claim, "Normal", "ClaimedSandboxesUnbound",
+			fmt.Sprintf("Unbound %d quarantined sandbox(es) from the claim", unbound))
+	}
+	return nil
+}
+
+// sandboxPool returns the SandboxSet the claimed sandbox was created by, nil if it no longer exists
+func (c *commonControl) sandboxPool(ctx context.Context, sbx *agentsv1alpha1.Sandbox) (*agentsv1alpha1.SandboxSet, error) {
+	name := sbx.Labels[agentsv1alpha1.LabelSandboxPool]
+	if name == "" {
+		return nil, nil
+	}
+	pool := &agentsv1alpha1.SandboxSet{}
+	if err := c.Get(ctx, client.ObjectKey{Namespace: sbx.Namespace, Name: name}, pool); err != nil {
+		return nil, client.IgnoreNotFound(err)
+	}
+	if !pool.DeletionTimestamp.IsZero() {
+		return nil, nil
+	}
+	return pool, nil
+}
+
+// unbindSandbox removes the labels and the owner of the claim from the sandbox and its pod template, so that the stale
+// label janitor removes the claim labels from its pod as well. Given the pool, the sandbox is returned to it, i.e.
+// the labels and annotations of spec.labels and spec.annotations of the claim, what claiming it recorded and the
+// shutdownTime of the claim are removed as well, and the sandbox is controlled by the SandboxSet again, which counts
+// it as available once ready.
+func (c *commonControl) unbindSandbox(ctx context.Context, claim *agentsv1alpha1.SandboxClaim, sbx *agentsv1alpha1.Sandbox,
+	pool *agentsv1alpha1.SandboxSet) error {
+	sbx = sbx.DeepCopy()
+	labels := claimSandboxLabels
+	annotations := []string{agentsv1alpha1.AnnotationOwner}
+	if pool != nil {
+		labels = slices.Concat(labels, slices.Collect(maps.Keys(claim.Spec.Labels)))
+		annotations = slices.Concat(claimSandboxAnnotations, slices.Collect(maps.Keys(claim.Spec.Annotations)))
+	}
+	for _, key := range labels {
+		delete(sbx.Labels, key)
+		if sbx.Spec.Template != nil {
+			delete(sbx.Spec.Template.Labels, key)
+		}
+	}
+	for _, key := range annotations {
+		delete(sbx.Annotations, key)
+	}
+	if pool != nil {
+		if claim.Spec.ShutdownTime != nil {
+			sbx.Spec.ShutdownTime = nil
+		}
+		sbx.Labels[agentsv1alpha1.LabelSandboxIsClaimed] = agentsv1alpha1.False
+		sbx.OwnerReferences = []metav1.OwnerReference{*metav1.NewControllerRef(pool, agentsv1alpha1.SandboxSetControllerKind)}
+	}
+	_, err := c.sandboxClient.SandboxClient.ApiV1alpha1().Sandboxes(sbx.Namespace).Update(ctx, sbx, metav1.UpdateOptions{})
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// isReplicasIncreased checks if the replicas of a claim that has claimed all of them were increased since
+func isReplicasIncreased(claim *agentsv1alpha1.SandboxClaim, status *agentsv1alpha1.SandboxClaimStatus) bool {
+	cond := conditions.Get(status.Conditions, string(agentsv1alpha1.SandboxClaimConditionCompleted))
+	return cond != nil && cond.Status == metav1.ConditionTrue && cond.Reason == "AllReplicasClaimed" &&
+		!isReplicasMet(claim, status)
+}
+
+// releaseExcessReplicas releases the alive claimed sandboxes beyond the decreased replicas of the claim, in the order
+// of its spec.releasePolicy, and returns how many are released.
+func (c *commonControl) releaseExcessReplicas(ctx context.Context, claim *agentsv1alpha1.SandboxClaim) (int32, error) {
+	log := logf.FromContext(ctx)
+	sandboxes, err := c.cache.ListSandboxWithUser(string(claim.UID))
+	if err != nil {
+		return 0, err
+	}
+	alive := make([]*agentsv1alpha1.Sandbox, 0, len(sandboxes))
+	now := time.Now()
+	for _, sbx := range sandboxes {
+		if state, _ := sandboxstate.Evaluate(sbx, now, sandboxstate.Policy{}); state != agentsv1alpha1.SandboxStateDead {
+			alive = append(alive, sbx)
+		}
+	}
+	excess := len(alive) - int(getDesiredReplicas(claim))
+	if excess <= 0 {
+		return 0, nil
+	}
+	sortForRelease(alive, releasePolicy(claim))
+
+	var released int32
+	for _, sbx := range alive {
+		if int(released) >= excess {
+			break
+		}
+		if sbx.Spec.Quarantine != nil {
+			log.Info("Skip releasing quarantined sandbox", "sandbox", klog.KObj(sbx))
+			continue
+		}
+		err = c.sandboxClient.SandboxClient.ApiV1alpha1().Sandboxes(sbx.Namespace).Delete(ctx, sbx.Name, metav1.DeleteOptions{})
+		if err != nil && !errors.IsNotFound(err) {
+			return released, err
+		}
+		log.Info("Released claimed sandbox beyond replicas", "sandbox", klog.KObj(sbx))
+		released++
+	}
+	return released, nil
+}
+
+// sortForRelease sorts the claimed sandboxes in the order they are released with the policy, by the time they were
+// claimed and then by name.
+func sortForRelease(sandboxes []*agentsv1alpha1.Sandbox, policy agentsv1alpha1.SandboxClaimReleasePolicy) {
+	slices.SortStableFunc(sandboxes, func(a, b *agentsv1alpha1.Sandbox) int {
+		order := claimTime(a).Compare(claimTime(b))
+		if order == 0 {
+			order = cmp.Compare(a.Name, b.Name)
+		}
+		if policy == agentsv1alpha1.SandboxClaimReleaseNewest {
+			return -order
+		}
+		return order
+	})
+}
+
+// claimTime returns when the sandbox was claimed, falling back to its creation for sandboxes claimed by versions
+// not recording it.
+func claimTime(sbx *agentsv1alpha1.Sandbox) time.Time {
+	if claimed, err := time.Parse(time.RFC3339, sbx.Annotations[agentsv1alpha1.AnnotationClaimTime]); err == nil {
+		return claimed
+	}
+	return sbx.CreationTimestamp.Time
+}
@@ -0,0 +1,434 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+	"github.com/openkruise/agents/pkg/sandbox-manager/infra/sandboxcr"
+	"github.com/openkruise/agents/pkg/utils/requeue"
+	"github.com/openkruise/agents/pkg/utils/sandboxstate"
+)
+
+func TestTTLPolicy(t *testing.T) {
+	claim := &agentsv1alpha1.SandboxClaim{}
+	assert.Equal(t, agentsv1alpha1.SandboxClaimTTLRetain, ttlPolicy(claim))
+	claim.Spec.TTLPolicy = agentsv1alpha1.SandboxClaimTTLDelete
+	assert.Equal(t, agentsv1alpha1.SandboxClaimTTLDelete, ttlPolicy(claim))
+}
+
+func TestSortForRelease(t *testing.T) {
+	base := time.Now().Truncate(time.Second)
+	newSandbox := func(name string, claimed time.Duration) *agentsv1alpha1.Sandbox {
+		return &agentsv1alpha1.Sandbox{ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Annotations: map[string]string{agentsv1alpha1.AnnotationClaimTime: base.Add(claimed).Format(time.RFC3339)},
+		}}
+	}
+	legacy := &agentsv1alpha1.Sandbox{ObjectMeta: metav1.ObjectMeta{Name: "legacy", CreationTimestamp: metav1.NewTime(base.Add(-time.Hour))}}
+
+	tests := []struct {
+		name     string
+		policy   agentsv1alpha1.SandboxClaimReleasePolicy
+		expected []string
+	}{
+		{
+			name:     "newest first",
+			policy:   agentsv1alpha1.SandboxClaimReleaseNewest,
+			expected: []string{"c", "b", "a", "legacy"},
+		},
+		{
+			name:     "oldest first",
+			policy:   agentsv1alpha1.SandboxClaimReleaseOldest,
+			expected: []string{"legacy", "a", "b", "c"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sandboxes := []*agentsv1alpha1.Sandbox{newSandbox("b", time.Minute), legacy, newSandbox("c", 2*time.Minute), newSandbox("a", time.Minute)}
+			sortForRelease(sandboxes, tt.policy)
+			names := make([]string, 0, len(sandboxes))
+			for _, sbx := range sandboxes {
+				names = append(names, sbx.Name)
+			}
+			assert.Equal(t, tt.expected, names)
+		})
+	}
+}
+
+func TestCommonControl_EnsureClaimCompleted_ReplicasChanged(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = agentsv1alpha1.AddToScheme(scheme)
+
+	cache, clientSet, err := sandboxcr.NewTestCache(t)
+	require.NoError(t, err, "Failed to create cache")
+	sandboxClient := clientSet.SandboxClient
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		_ = cache.Run(ctx)
+	}()
+	time.Sleep(200 * time.Millisecond) // Wait for cache to start
+
+	base := time.Now().Truncate(time.Second)
+	newSandbox := func(owner string, i int, quarantined bool) *agentsv1alpha1.Sandbox {
+		sbx := &agentsv1alpha1.Sandbox{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("%s-sbx-%d", owner, i),
+				Namespace: "default",
+				Annotations: map[string]string{
+					agentsv1alpha1.AnnotationOwner:     owner,
+					agentsv1alpha1.AnnotationClaimTime: base.Add(time.Duration(i) * time.Minute).Format(time.RFC3339),
+				},
+				Labels: map[string]string{
+					agentsv1alpha1.LabelSandboxTemplate:  "test-template",
+					agentsv1alpha1.LabelSandboxIsClaimed: "true",
+				},
+			},
+			Status: agentsv1alpha1.SandboxStatus{
+				Phase:      agentsv1alpha1.SandboxRunning,
+				Conditions: []metav1.Condition{{Type: string(agentsv1alpha1.SandboxConditionReady), Status: metav1.ConditionTrue}},
+			},
+		}
+		if quarantined {
+			sbx.Spec.Quarantine = &agentsv1alpha1.SandboxQuarantine{}
+		}
+		return sbx
+	}
+	allClaimed := metav1.Condition{
+		Type:   string(agentsv1alpha1.SandboxClaimConditionCompleted),
+		Status: metav1.ConditionTrue,
+		Reason: "AllReplicasClaimed",
+	}
+	timedOut := metav1.Condition{
+		Type:   string(agentsv1alpha1.SandboxClaimConditionCompleted),
+		Status: metav1.ConditionTrue,
+		Reason: "ClaimTimeoutReached",
+	}
+
+	tests := []struct {
+		name             string
+		uid              string
+		replicas         int32
+		policy           agentsv1alpha1.SandboxClaimReleasePolicy
+		quarantined      int
+		condition        metav1.Condition
+		expectedPhase    agentsv1alpha1.SandboxClaimPhase
+		expectedClaimed  int32
+		expectedStrategy RequeueStrategy
+		expectedKept     []int
+	}{
+		{
+			name:            "release newest on scale down",
+			uid:             "scale-uid-1",
+			replicas:        1,
+			policy:          agentsv1alpha1.SandboxClaimReleaseNewest,
+			condition:       allClaimed,
+			expectedPhase:   agentsv1alpha1.SandboxClaimPhaseCompleted,
+			expectedClaimed: 1,
+			expectedKept:    []int{0},
+		},
+		{
+			name:            "release oldest on scale down",
+			uid:             "scale-uid-2",
+			replicas:        2,
+			policy:          agentsv1alpha1.SandboxClaimReleaseOldest,
+			condition:       allClaimed,
+			expectedPhase:   agentsv1alpha1.SandboxClaimPhaseCompleted,
+			expectedClaimed: 2,
+			expectedKept:    []int{1, 2},
+		},
+		{
+			name:            "retain on scale down",
+			uid:             "scale-uid-3",
+			replicas:        1,
+			condition:       allClaimed,
+			expectedPhase:   agentsv1alpha1.SandboxClaimPhaseCompleted,
+			expectedClaimed: 3,
+			expectedKept:    []int{0, 1, 2},
+		},
+		{
+			name:            "quarantined sandboxes are not released",
+			uid:             "scale-uid-4",
+			replicas:        1,
+			policy:          agentsv1alpha1.SandboxClaimReleaseOldest,
+			quarantined:     1,
+			condition:       allClaimed,
+			expectedPhase:   agentsv1alpha1.SandboxClaimPhaseCompleted,
+			expectedClaimed: 1,
+			expectedKept:    []int{0},
+		},
+		{
+			name:             "claim added replicas on scale up",
+			uid:              "scale-uid-5",
+			replicas:         5,
+			policy:           agentsv1alpha1.SandboxClaimReleaseNewest,
+			condition:        allClaimed,
+			expectedPhase:    agentsv1alpha1.SandboxClaimPhaseClaiming,
+			expectedClaimed:  3,
+			expectedStrategy: requeue.Immediately(),
+			expectedKept:     []int{0, 1, 2},
+		},
+		{
+			name:            "timed out claims do not claim added replicas",
+			uid:             "scale-uid-6",
+			replicas:        5,
+			condition:       timedOut,
+			expectedPhase:   agentsv1alpha1.SandboxClaimPhaseCompleted,
+			expectedClaimed: 3,
+			expectedKept:    []int{0, 1, 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 0; i < 3; i++ {
+				sbx := newSandbox(tt.uid, i, i < tt.quarantined)
+				_, err := sandboxClient.ApiV1alpha1().Sandboxes(sbx.Namespace).Create(ctx, sbx, metav1.CreateOptions{})
+				require.NoError(t, err, "Failed to create sandbox in sandboxClient")
+			}
+			time.Sleep(100 * time.Millisecond) // Wait for cache sync
+
+			claim := &agentsv1alpha1.SandboxClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-claim",
+					Namespace: "default",
+					UID:       types.UID(tt.uid),
+				},
+				Spec: agentsv1alpha1.SandboxClaimSpec{
+					TemplateName:  "test-template",
+					Replicas:      int32Ptr(tt.replicas),
+					ReleasePolicy: tt.policy,
+				},
+			}
+			newStatus := &agentsv1alpha1.SandboxClaimStatus{
+				Phase:           agentsv1alpha1.SandboxClaimPhaseCompleted,
+				ClaimedReplicas: 3,
+				Conditions:      []metav1.Condition{tt.condition},
+			}
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(claim).Build()
+			control := NewCommonControl(fakeClient, record.NewFakeRecorder(10), clientSet, cache)
+
+			strategy, err := control.EnsureClaimCompleted(ctx, ClaimArgs{Claim: claim, NewStatus: newStatus})
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedStrategy.Kind(), strategy.Kind())
+			assert.Equal(t, tt.expectedPhase, newStatus.Phase)
+			assert.Equal(t, tt.expectedClaimed, newStatus.ClaimedReplicas)
+
+			list, err := sandboxClient.ApiV1alpha1().Sandboxes("default").List(ctx, metav1.ListOptions{})
+			require.NoError(t, err)
+			var kept []int
+			for i := 0; i < 3; i++ {
+				for _, sbx := range list.Items {
+					if sbx.Name == fmt.Sprintf("%s-sbx-%d", tt.uid, i) {
+						kept = append(kept, i)
+					}
+				}
+			}
+			assert.Equal(t, tt.expectedKept, kept)
+		})
+	}
+}
+
+func TestCommonControl_EnsureClaimDeleted(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = agentsv1alpha1.AddToScheme(scheme)
+
+	cache, clientSet, err := sandboxcr.NewTestCache(t)
+	require.NoError(t, err, "Failed to create cache")
+	sandboxClient := clientSet.SandboxClient
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		_ = cache.Run(ctx)
+	}()
+	time.Sleep(200 * time.Millisecond) // Wait for cache to start
+
+	pool := &agentsv1alpha1.SandboxSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-pool", Namespace: "default", UID: "test-pool-uid"},
+	}
+	// sandbox 0 is of the pool, sandbox 1 is quarantined and sandbox 2 is of a deleted pool
+	const sandboxes = 3
+	newSandbox := func(owner string, i int) *agentsv1alpha1.Sandbox {
+		claimLabels := map[string]string{
+			agentsv1alpha1.LabelSandboxClaimName:    "test-claim",
+			agentsv1alpha1.LabelSandboxClaimUID:     owner,
+			agentsv1alpha1.LabelSandboxClaimOrdinal: fmt.Sprint(i),
+			agentsv1alpha1.LabelSandboxClaimAlias:   fmt.Sprintf("test-claim-%d", i),
+			"claim-label":                           "removed",
+		}
+		poolName := pool.Name
+		if i == 2 {
+			poolName = "deleted-pool"
+		}
+		sbx := &agentsv1alpha1.Sandbox{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("%s-sbx-%d", owner, i),
+				Namespace: "default",
+				Annotations: map[string]string{
+					agentsv1alpha1.AnnotationOwner:              owner,
+					agentsv1alpha1.AnnotationClaimTime:          time.Now().Format(time.RFC3339),
+					agentsv1alpha1.AnnotationInitRuntimeRequest: `{"envVars":{"SECRET":"value"}}`,
+					"claim-annotation":                          "removed",
+				},
+				Labels: map[string]string{
+					agentsv1alpha1.LabelSandboxPool:      poolName,
+					agentsv1alpha1.LabelSandboxTemplate:  poolName,
+					agentsv1alpha1.LabelSandboxIsClaimed: "true",
+					"user-label":                         "kept",
+				},
+			},
+			Spec: agentsv1alpha1.SandboxSpec{
+				EmbeddedSandboxTemplate: agentsv1alpha1.EmbeddedSandboxTemplate{
+					Template: &corev1.PodTemplateSpec{ObjectMeta: metav1.ObjectMeta{Labels: maps.Clone(claimLabels)}},
+				},
+				ShutdownTime: &metav1.Time{Time: time.Now().Add(time.Hour)},
+			},
+			Status: agentsv1alpha1.SandboxStatus{
+				Phase:      agentsv1alpha1.SandboxRunning,
+				Conditions: []metav1.Condition{{Type: string(agentsv1alpha1.SandboxConditionReady), Status: metav1.ConditionTrue}},
+			},
+		}
+		maps.Copy(sbx.Labels, claimLabels)
+		if i == 1 {
+			sbx.Spec.Quarantine = &agentsv1alpha1.SandboxQuarantine{}
+		}
+		return sbx
+	}
+
+	tests := []struct {
+		name             string
+		uid              string
+		policy           agentsv1alpha1.SandboxClaimDeletionPolicy
+		expectedReturned bool
+		expectedEvents   []string
+	}{
+		{
+			name:             "return to the pool by default",
+			uid:              "delete-uid-1",
+			expectedReturned: true,
+			expectedEvents: []string{
+				"Normal ClaimedSandboxesDeleted Deleted 1 claimed sandbox(es) with the claim",
+				"Normal ClaimedSandboxesReturned Returned 1 claimed sandbox(es) to their pool",
+				"Normal ClaimedSandboxesUnbound Unbound 1 quarantined sandbox(es) from the claim",
+			},
+		},
+		{
+			name:   "delete and unbind quarantined",
+			uid:    "delete-uid-2",
+			policy: agentsv1alpha1.SandboxClaimDeletionDelete,
+			expectedEvents: []string{
+				"Normal ClaimedSandboxesDeleted Deleted 2 claimed sandbox(es) with the claim",
+				"Normal ClaimedSandboxesUnbound Unbound 1 quarantined sandbox(es) from the claim",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 0; i < sandboxes; i++ {
+				CreateSandboxWithStatus(t, sandboxClient, newSandbox(tt.uid, i))
+			}
+			time.Sleep(100 * time.Millisecond) // Wait for cache sync
+
+			claim := &agentsv1alpha1.SandboxClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-claim",
+					Namespace: "default",
+					UID:       types.UID(tt.uid),
+				},
+				Spec: agentsv1alpha1.SandboxClaimSpec{
+					TemplateName:   pool.Name,
+					DeletionPolicy: tt.policy,
+					Labels:         map[string]string{"claim-label": "removed"},
+					Annotations:    map[string]string{"claim-annotation": "removed"},
+					ShutdownTime:   &metav1.Time{Time: time.Now().Add(time.Hour)},
+				},
+			}
+			recorder := record.NewFakeRecorder(10)
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(claim, pool).Build()
+			control := NewCommonControl(fakeClient, recorder, clientSet, cache)
+
+			require.NoError(t, control.EnsureClaimDeleted(ctx, claim))
+			var events []string
+			for len(recorder.Events) > 0 {
+				events = append(events, <-recorder.Events)
+			}
+			assert.Equal(t, tt.expectedEvents, events)
+
+			get := func(i int) (*agentsv1alpha1.Sandbox, error) {
+				return sandboxClient.ApiV1alpha1().Sandboxes("default").Get(ctx, fmt.Sprintf("%s-sbx-%d", tt.uid, i), metav1.GetOptions{})
+			}
+			_, err := get(2)
+			assert.True(t, errors.IsNotFound(err), "sandbox of a deleted pool is deleted")
+
+			quarantined, err := get(1)
+			require.NoError(t, err)
+			assert.NotContains(t, quarantined.Annotations, agentsv1alpha1.AnnotationOwner)
+			assert.Equal(t, map[string]string{
+				agentsv1alpha1.LabelSandboxPool:      pool.Name,
+				agentsv1alpha1.LabelSandboxTemplate:  pool.Name,
+				agentsv1alpha1.LabelSandboxIsClaimed: "true",
+				"user-label":                         "kept",
+				"claim-label":                        "removed",
+			}, quarantined.Labels)
+			assert.Empty(t, quarantined.OwnerReferences, "a quarantined sandbox is kept out of the pool")
+
+			sbx, err := get(0)
+			if !tt.expectedReturned {
+				assert.True(t, errors.IsNotFound(err))
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, map[string]string{
+				agentsv1alpha1.LabelSandboxPool:      pool.Name,
+				agentsv1alpha1.LabelSandboxTemplate:  pool.Name,
+				agentsv1alpha1.LabelSandboxIsClaimed: "false",
+				"user-label":                         "kept",
+			}, sbx.Labels)
+			assert.Empty(t, sbx.Annotations)
+			assert.Empty(t, sbx.Spec.Template.Labels)
+			assert.Nil(t, sbx.Spec.ShutdownTime)
+			state, _ := sandboxstate.Evaluate(sbx, time.Now(), sandboxstate.Policy{})
+			assert.Equal(t, agentsv1alpha1.SandboxStateAvailable, state, "the returned sandbox is available in the pool again")
+		})
+	}
+}
+
+func TestDeletionPolicy(t *testing.T) {
+	claim := &agentsv1alpha1.SandboxClaim{}
+	assert.Equal(t, agentsv1alpha1.SandboxClaimDeletionUnbind, deletionPolicy(claim))
+	claim.Spec.DeletionPolicy = agentsv1alpha1.SandboxClaimDeletionDelete
+	assert.Equal(t, agentsv1alpha1.SandboxClaimDeletionDelete, deletionPolicy(claim))
+}
@@ -0,0 +1,116 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+	"github.com/openkruise/agents/pkg/utils/requeue"
+)
+
+// waitingForSandboxesReasons are the reasons of the requeue strategies of claims waiting for sandboxes of the pool
+var waitingForSandboxesReasons = sets.New(
+	"NoAvailableSandboxes",
+	"ReservedForInteractive",
+	"WaitingForHigherPriority",
+	"WaitingForEarlierClaims",
+	"WaitingForAllReplicas",
+	"AllOrNothingRolledBack",
+	"PreemptedLowerPriority",
+)
+
+// RetryCounter counts the consecutive retries of claims waiting for sandboxes. The counts are kept in memory, as
+// recording them in the status would trigger a reconcile on every retry and defeat the backoff.
+type RetryCounter interface {
+	// Next returns the number of retries of the claim counted so far and counts another one.
+	Next(claim metav1.Object) int
+	// Reset drops the retries counted for the claim.
+	Reset(claim metav1.Object)
+}
+
+func NewRetryCounter() RetryCounter {
+	return &realRetryCounter{retries: make(map[types.UID]int)}
+}
+
+type realRetryCounter struct {
+	sync.Mutex
+	retries map[types.UID]int
+}
+
+func (c *realRetryCounter) Next(claim metav1.Object) int {
+	c.Lock()
+	defer c.Unlock()
+	retries := c.retries[claim.GetUID()]
+	c.retries[claim.GetUID()] = retries + 1
+	return retries
+}
+
+func (c *realRetryCounter) Reset(claim metav1.Object) {
+	c.Lock()
+	defer c.Unlock()
+	delete(c.retries, claim.GetUID())
+}
+
+// ApplyRetryPolicy replaces the fixed retry interval of a claim waiting for sandboxes with the backoff of its
+// spec.retryPolicy, or the DefaultRetryPolicy of the controller, and restarts the backoff once the claim stops waiting.
+func ApplyRetryPolicy(claim *agentsv1alpha1.SandboxClaim, status *agentsv1alpha1.SandboxClaimStatus,
+	strategy requeue.Strategy, now time.Time) requeue.Strategy {
+	policy := claim.Spec.RetryPolicy
+	if policy == nil {
+		policy = DefaultRetryPolicy
+	}
+	if policy == nil {
+		return strategy
+	}
+	if strategy.Kind() != requeue.KindAfter || !waitingForSandboxesReasons.Has(strategy.Reason) {
+		ClaimRetries.Reset(claim)
+		return strategy
+	}
+	delay := retryBackoff(policy, ClaimRetries.Next(claim), rand.Float64())
+	if remaining, _ := claimTimeoutApproaching(claim, status, now); remaining > 0 && remaining < delay {
+		// retry in time to time out the claim
+		delay = remaining
+	}
+	return requeue.After(delay).WithReason(strategy.Reason)
+}
+
+// retryBackoff returns the delay of the retry after the given number of retries, which doubles from the initial
+// backoff up to the max backoff, and is extended by the jitter percentage of it scaled by r in [0, 1).
+func retryBackoff(policy *agentsv1alpha1.SandboxClaimRetryPolicy, retries int, r float64) time.Duration {
+	initial, maxBackoff := ClaimRetryInterval, DefaultMaxRetryBackoff
+	if policy.InitialBackoff != nil {
+		initial = policy.InitialBackoff.Duration
+	}
+	if policy.MaxBackoff != nil {
+		maxBackoff = policy.MaxBackoff.Duration
+	}
+	delay := min(initial, maxBackoff)
+	for i := 0; i < retries && delay < maxBackoff; i++ {
+		delay = min(delay*2, maxBackoff)
+	}
+	if policy.JitterPercent > 0 {
+		delay += time.Duration(float64(delay) * float64(policy.JitterPercent) / 100 * r)
+	}
+	return delay
+}
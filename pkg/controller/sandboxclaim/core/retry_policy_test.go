@@ -0,0 +1,149 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+	"github.com/openkruise/agents/pkg/utils/requeue"
+)
+
+func TestRetryBackoff(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   agentsv1alpha1.SandboxClaimRetryPolicy
+		retries  int
+		r        float64
+		expected time.Duration
+	}{
+		{
+			name:     "first retry defaults to the retry interval",
+			expected: ClaimRetryInterval,
+		},
+		{
+			name:     "doubles on every retry",
+			policy:   agentsv1alpha1.SandboxClaimRetryPolicy{InitialBackoff: &metav1.Duration{Duration: time.Second}},
+			retries:  3,
+			expected: 8 * time.Second,
+		},
+		{
+			name:     "capped by the default max backoff",
+			retries:  100,
+			expected: DefaultMaxRetryBackoff,
+		},
+		{
+			name: "capped by the max backoff",
+			policy: agentsv1alpha1.SandboxClaimRetryPolicy{
+				InitialBackoff: &metav1.Duration{Duration: time.Second},
+				MaxBackoff:     &metav1.Duration{Duration: 5 * time.Second},
+			},
+			retries:  3,
+			expected: 5 * time.Second,
+		},
+		{
+			name:     "initial backoff beyond the max backoff",
+			policy:   agentsv1alpha1.SandboxClaimRetryPolicy{InitialBackoff: &metav1.Duration{Duration: 2 * time.Minute}},
+			expected: DefaultMaxRetryBackoff,
+		},
+		{
+			name: "extended by jitter",
+			policy: agentsv1alpha1.SandboxClaimRetryPolicy{
+				InitialBackoff: &metav1.Duration{Duration: 10 * time.Second},
+				JitterPercent:  20,
+			},
+			r:        0.5,
+			expected: 11 * time.Second,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, retryBackoff(&tt.policy, tt.retries, tt.r))
+		})
+	}
+}
+
+func TestApplyRetryPolicy(t *testing.T) {
+	now := time.Now()
+	newClaim := func(uid string, policy *agentsv1alpha1.SandboxClaimRetryPolicy) *agentsv1alpha1.SandboxClaim {
+		return &agentsv1alpha1.SandboxClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: uid, Namespace: "default", UID: types.UID("retry-" + uid)},
+			Spec:       agentsv1alpha1.SandboxClaimSpec{RetryPolicy: policy},
+		}
+	}
+	waiting := requeue.After(ClaimRetryInterval).WithReason("NoAvailableSandboxes")
+	policy := &agentsv1alpha1.SandboxClaimRetryPolicy{InitialBackoff: &metav1.Duration{Duration: time.Second}}
+
+	t.Run("without retry policy", func(t *testing.T) {
+		claim := newClaim("no-policy", nil)
+		status := &agentsv1alpha1.SandboxClaimStatus{}
+		for range 3 {
+			assert.Equal(t, waiting, ApplyRetryPolicy(claim, status, waiting, now))
+		}
+	})
+
+	t.Run("backs off while waiting for sandboxes and restarts on progress", func(t *testing.T) {
+		claim := newClaim("backoff", policy)
+		defer ClaimRetries.Reset(claim)
+		status := &agentsv1alpha1.SandboxClaimStatus{}
+		for _, expected := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second} {
+			strategy := ApplyRetryPolicy(claim, status, waiting, now)
+			assert.Equal(t, requeue.After(expected).WithReason("NoAvailableSandboxes"), strategy)
+		}
+		progress := requeue.Immediately().WithReason("ClaimProgress")
+		assert.Equal(t, progress, ApplyRetryPolicy(claim, status, progress, now))
+		assert.Equal(t, time.Second, ApplyRetryPolicy(claim, status, waiting, now).After)
+	})
+
+	t.Run("default retry policy of the controller", func(t *testing.T) {
+		defaultPolicy := DefaultRetryPolicy
+		t.Cleanup(func() { DefaultRetryPolicy = defaultPolicy })
+		DefaultRetryPolicy = &agentsv1alpha1.SandboxClaimRetryPolicy{InitialBackoff: &metav1.Duration{Duration: 3 * time.Second}}
+		claim := newClaim("default-policy", nil)
+		defer ClaimRetries.Reset(claim)
+		status := &agentsv1alpha1.SandboxClaimStatus{}
+		assert.Equal(t, 3*time.Second, ApplyRetryPolicy(claim, status, waiting, now).After)
+		assert.Equal(t, 6*time.Second, ApplyRetryPolicy(claim, status, waiting, now).After)
+
+		withPolicy := newClaim("own-policy", policy)
+		defer ClaimRetries.Reset(withPolicy)
+		assert.Equal(t, time.Second, ApplyRetryPolicy(withPolicy, status, waiting, now).After, "the policy of the claim applies")
+	})
+
+	t.Run("other retries are not backed off", func(t *testing.T) {
+		claim := newClaim("queued", policy)
+		defer ClaimRetries.Reset(claim)
+		status := &agentsv1alpha1.SandboxClaimStatus{}
+		queued := requeue.After(ClaimRetryInterval).WithReason("ClaimQueued")
+		for range 3 {
+			assert.Equal(t, queued, ApplyRetryPolicy(claim, status, queued, now))
+		}
+	})
+
+	t.Run("never delays beyond the claim timeout", func(t *testing.T) {
+		claim := newClaim("timeout", &agentsv1alpha1.SandboxClaimRetryPolicy{InitialBackoff: &metav1.Duration{Duration: time.Minute}})
+		claim.Spec.ClaimTimeout = &metav1.Duration{Duration: time.Minute}
+		defer ClaimRetries.Reset(claim)
+		status := &agentsv1alpha1.SandboxClaimStatus{ClaimStartTime: &metav1.Time{Time: now.Add(-50 * time.Second)}}
+		assert.Equal(t, 10*time.Second, ApplyRetryPolicy(claim, status, waiting, now).After)
+	})
+}
@@ -17,13 +17,25 @@ limitations under the License.
 package core
 
 import (
+	"encoding/json"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+	"github.com/openkruise/agents/pkg/sandbox-manager/clients"
+	"github.com/openkruise/agents/pkg/sandbox-manager/infra/sandboxcr"
+	"github.com/openkruise/agents/pkg/utils/claimlatency"
+	"github.com/openkruise/agents/pkg/utils/conditions"
+	"github.com/openkruise/agents/pkg/utils/objectsize"
+	"github.com/openkruise/agents/pkg/utils/sandboxutils"
+	"github.com/openkruise/agents/pkg/utils/slo"
 )
 
 // CalculateClaimStatus determines the next phase of a SandboxClaim and whether to skip business logic.
@@ -38,14 +50,23 @@ import (
 //
 // Handled scenarios (in order):
 //  1. Already Completed                     → Completed, continue (for TTL cleanup)
-//  2. SandboxSet not found                  → Completed, SKIP (terminal, fail-fast)
-//  3. New claim (Phase == "")               → Claiming, continue
-//  4. All replicas claimed                  → Completed, SKIP (terminal)
-//  5. Timeout exceeded                      → Completed, SKIP (terminal)
-//  6. Otherwise                             → Current phase, continue
+//  2. Canceled by annotation                → Completed, SKIP (terminal)
+//  3. SandboxSet not found                  → Completed, SKIP (terminal, fail-fast)
+//  4. New claim (Phase == "")               → Claiming, continue
+//  5. All replicas claimed                  → Completed, SKIP (terminal)
+//  6. Paused by spec.paused                 → Current phase, SKIP (until unpaused)
+//  7. Timeout exceeded                      → Completed, SKIP (terminal), or Claiming for BestEffort, continue
+//  8. Otherwise                             → Current phase, continue
 //
-// Note: ObservedGeneration is always updated to track spec changes
+// Note: ObservedGeneration is always updated to track spec changes, and the Progressing and ReplicasReady conditions
+// are always derived from the next status
 func CalculateClaimStatus(args ClaimArgs) (*agentsv1alpha1.SandboxClaimStatus, bool) {
+	newStatus, skipBusinessLogic := calculateClaimStatus(args)
+	SetProgressConditions(args.Claim, newStatus)
+	return newStatus, skipBusinessLogic
+}
+
+func calculateClaimStatus(args ClaimArgs) (*agentsv1alpha1.SandboxClaimStatus, bool) {
 	claim := args.Claim
 	newStatus := args.NewStatus
 
@@ -53,7 +74,7 @@ func CalculateClaimStatus(args ClaimArgs) (*agentsv1alpha1.SandboxClaimStatus, b
 	newStatus.ObservedGeneration = claim.Generation
 
 	// 1. Handle terminal state
-	if newStatus.Phase == agentsv1alpha1.SandboxClaimPhaseCompleted {
+	if IsClaimFinished(newStatus) {
 		klog.V(2).InfoS("SandboxClaim already completed, skipping state calculation",
 			"claim", klog.KObj(claim),
 			"completionTime", newStatus.CompletionTime)
@@ -62,45 +83,116 @@ func CalculateClaimStatus(args ClaimArgs) (*agentsv1alpha1.SandboxClaimStatus, b
 		return newStatus, false
 	}
 
-	// 2. Check if SandboxSet exists
+	// 2. Check if the claim is canceled
+	// Transition: * → Completed (Canceled)
+	if isClaimCanceled(claim) {
+		klog.InfoS("SandboxClaim canceled, transitioning to Completed",
+			"claim", klog.KObj(claim),
+			"phase", newStatus.Phase,
+			"claimedReplicas", newStatus.ClaimedReplicas,
+			"desiredReplicas", getDesiredReplicas(claim))
+		return transitionToCompletedWithCancel(newStatus, claim), true
+	}
+
+	// 3. Check if SandboxSet exists
 	// Transition: * → Completed (SandboxSet deleted)
 	if args.SandboxSet == nil {
 		klog.InfoS("SandboxSet not found, transitioning to Completed",
 			"claim", klog.KObj(claim),
-			"sandboxSet", claim.Spec.TemplateName)
+			"sandboxSet", sandboxutils.ClaimSandboxSetName(claim))
 		return TransitionToCompleted(newStatus,
 			"SandboxSetNotFound",
 			"SandboxSet not found or deleted"), true
 	}
 
-	// 3. Handle initial state
-	// Transition: "" → Claiming
+	// 4. Handle initial state
+	// Transition: "" → Claiming (dry run), a dry-run claim claims nothing, so it neither waits for approval nor queues
+	if newStatus.Phase == "" && claim.Spec.DryRun {
+		klog.InfoS("Initializing dry-run SandboxClaim", "claim", klog.KObj(claim))
+		newStatus.Phase = agentsv1alpha1.SandboxClaimPhaseClaiming
+		now := metav1.Now()
+		newStatus.ClaimStartTime = &now
+		recordHistory(newStatus, "DryRunStarted", fmt.Sprintf("Checking the sandboxes claimable for %d replica(s)", getDesiredReplicas(claim)))
+		return newStatus, false
+	}
+	// Transition: "" → PendingApproval (approval required)
+	if newStatus.Phase == "" && claim.Spec.RequiresApproval {
+		klog.InfoS("SandboxClaim requires approval, waiting for approval", "claim", klog.KObj(claim))
+		newStatus.Phase = agentsv1alpha1.SandboxClaimPhasePendingApproval
+		newStatus.Message = "Waiting for approval"
+		recordHistory(newStatus, "PendingApproval", newStatus.Message)
+		return newStatus, true
+	}
+	// Transition: PendingApproval → Claiming / Queued / Completed (approval decided)
+	if newStatus.Phase == agentsv1alpha1.SandboxClaimPhasePendingApproval {
+		return calculateApproval(claim, args.SandboxSet, newStatus)
+	}
+	// Transition: "" → Claiming / Queued
 	if newStatus.Phase == "" {
 		klog.InfoS("Initializing new SandboxClaim, starting claim process",
 			"claim", klog.KObj(claim),
 			"generation", claim.Generation,
 			"desiredReplicas", getDesiredReplicas(claim))
-		newStatus.Phase = agentsv1alpha1.SandboxClaimPhaseClaiming
+		// The claim timeout also covers the time spent in the claim queue
+		newStatus.Phase = startPhase(args.SandboxSet)
 		now := metav1.Now()
 		newStatus.ClaimStartTime = &now
+		recordHistory(newStatus, "ClaimStarted", fmt.Sprintf("Started claiming %d sandbox(es)", getDesiredReplicas(claim)))
+		if claim.Spec.Paused {
+			return pauseClaim(newStatus, claim, now.Time), true
+		}
 		return newStatus, false
 	}
 
-	// 4. Check if desired replicas already met
+	// 5. Check if desired replicas already met
 	// Transition: Claiming → Completed (All replicas claimed)
 	if isReplicasMet(claim, newStatus) {
 		klog.InfoS("All replicas claimed, transitioning to Completed",
 			"claim", klog.KObj(claim),
 			"claimedReplicas", newStatus.ClaimedReplicas,
 			"desiredReplicas", getDesiredReplicas(claim))
-		return transitionToCompletedWithSuccess(newStatus, claim), true
+		newStatus = transitionToCompletedWithSuccess(newStatus, claim)
+		if newStatus.ClaimStartTime != nil {
+			latency := newStatus.CompletionTime.Sub(newStatus.ClaimStartTime.Time)
+			claimlatency.Default.Observe(claim.Namespace, args.SandboxSet.Name, latency)
+			// best-effort claims are recorded for the SLO once they time out
+			if !IsDowngradedToBestEffort(newStatus) {
+				slo.Default.ObserveClaim(claim.Namespace, args.SandboxSet.Name, latency, true, newStatus.CompletionTime.Time)
+			}
+		}
+		return newStatus, true
+	}
+
+	// 6. Suspend claiming while paused, and resume the claim timeout from where it stopped once unpaused
+	// Transition: Claiming → Claiming (paused)
+	if claim.Spec.Paused {
+		if !isPaused(newStatus) {
+			klog.InfoS("SandboxClaim paused, suspending claiming",
+				"claim", klog.KObj(claim),
+				"claimedReplicas", newStatus.ClaimedReplicas,
+				"desiredReplicas", getDesiredReplicas(claim))
+		}
+		return pauseClaim(newStatus, claim, time.Now()), true
+	}
+	if paused, ok := resumeClaim(newStatus, time.Now()); ok {
+		klog.InfoS("SandboxClaim resumed, claiming again", "claim", klog.KObj(claim), "paused", paused)
 	}
 
-	// 5. Early timeout detection
-	// Transition: Claiming → Completed (Timeout)
-	if isClaimTimeout(claim, newStatus) {
+	// 7. Early timeout detection
+	// Transition: Claiming → Completed (Timeout), or Claiming → Claiming (downgraded to best-effort)
+	if isClaimTimeout(claim, newStatus) && !IsDowngradedToBestEffort(newStatus) {
 		elapsed := time.Since(newStatus.ClaimStartTime.Time)
-		klog.InfoS("Claim timeout reached, transitioning to Completed",
+		observeClaimTimedOut(claim, args.SandboxSet, newStatus)
+		if timeoutPolicy(claim) == agentsv1alpha1.SandboxClaimTimeoutBestEffort {
+			klog.InfoS("Claim timeout reached, downgrading to best-effort",
+				"claim", klog.KObj(claim),
+				"timeout", claim.Spec.ClaimTimeout.Duration,
+				"elapsed", elapsed,
+				"claimedReplicas", newStatus.ClaimedReplicas,
+				"desiredReplicas", getDesiredReplicas(claim))
+			return downgradeToBestEffortWithTimeout(newStatus, elapsed, claim), false
+		}
+		klog.InfoS("Claim timeout reached, transitioning to "+string(timedOutPhase(claim)),
 			"claim", klog.KObj(claim),
 			"timeout", claim.Spec.ClaimTimeout.Duration,
 			"elapsed", elapsed,
@@ -119,6 +211,37 @@ func CalculateClaimStatus(args ClaimArgs) (*agentsv1alpha1.SandboxClaimStatus, b
 	return newStatus, false
 }
 
+// calculateApproval moves a claim pending approval on according to the approval annotation
+func calculateApproval(claim *agentsv1alpha1.SandboxClaim, sandboxSet *agentsv1alpha1.SandboxSet, newStatus *agentsv1alpha1.SandboxClaimStatus) (*agentsv1alpha1.SandboxClaimStatus, bool) {
+	builder := conditions.NewBuilder(&newStatus.Conditions, newStatus.ObservedGeneration)
+	switch decision := claim.Annotations[agentsv1alpha1.AnnotationClaimApproval]; decision {
+	case agentsv1alpha1.SandboxClaimApprovalApproved:
+		klog.InfoS("SandboxClaim approved, starting claim process", "claim", klog.KObj(claim))
+		builder.True(string(agentsv1alpha1.SandboxClaimConditionApproved), decision, "Claim is approved")
+		newStatus.Phase = startPhase(sandboxSet)
+		newStatus.Message = ""
+		now := builder.Now()
+		newStatus.ClaimStartTime = &now
+		recordHistory(newStatus, decision, "Claim is approved")
+		return newStatus, true
+	case agentsv1alpha1.SandboxClaimApprovalRejected:
+		klog.InfoS("SandboxClaim rejected, transitioning to Completed", "claim", klog.KObj(claim))
+		builder.False(string(agentsv1alpha1.SandboxClaimConditionApproved), decision, "Claim is rejected")
+		return TransitionToCompleted(newStatus, decision, "Claim is rejected"), true
+	default:
+		klog.V(2).InfoS("SandboxClaim is still waiting for approval", "claim", klog.KObj(claim))
+		return newStatus, false
+	}
+}
+
+// startPhase returns the phase a claim starts claiming in, Queued if its SandboxSet queues claims
+func startPhase(sandboxSet *agentsv1alpha1.SandboxSet) agentsv1alpha1.SandboxClaimPhase {
+	if sandboxSet != nil && sandboxSet.Spec.ClaimQueue != nil {
+		return agentsv1alpha1.SandboxClaimPhaseQueued
+	}
+	return agentsv1alpha1.SandboxClaimPhaseClaiming
+}
+
 // getDesiredReplicas returns the desired number of replicas for a claim.
 // Returns DefaultReplicasCount if not specified.
 func getDesiredReplicas(claim *agentsv1alpha1.SandboxClaim) int32 {
@@ -128,6 +251,24 @@ func getDesiredReplicas(claim *agentsv1alpha1.SandboxClaim) int32 {
 	return DefaultReplicasCount
 }
 
+// SandboxSetNameIndexFunc indexes SandboxClaims by sandboxutils.ClaimSandboxSetName, leaving out the ones that have not picked one yet
+func SandboxSetNameIndexFunc(obj client.Object) []string {
+	claim, ok := obj.(*agentsv1alpha1.SandboxClaim)
+	if !ok {
+		return nil
+	}
+	if name := sandboxutils.ClaimSandboxSetName(claim); name != "" {
+		return []string{name}
+	}
+	return nil
+}
+
+// boundReplicas returns how many sandboxes are bound to a claim, including the ones not counted in claimedReplicas
+// as they have not been Ready for its minReadySeconds yet
+func boundReplicas(status *agentsv1alpha1.SandboxClaimStatus) int32 {
+	return max(status.ClaimedReplicas, status.BoundReplicas)
+}
+
 // isClaimTimeout checks if the claim has exceeded its timeout
 func isClaimTimeout(claim *agentsv1alpha1.SandboxClaim, status *agentsv1alpha1.SandboxClaimStatus) bool {
 	if claim.Spec.ClaimTimeout == nil || status.ClaimStartTime == nil {
@@ -139,6 +280,130 @@ func isClaimTimeout(claim *agentsv1alpha1.SandboxClaim, status *agentsv1alpha1.S
 	return elapsed >= timeout
 }
 
+// claimTimeoutApproaching returns the time left before the claim times out, and whether the claim is in the last
+// 1/claimTimeoutWarningFraction of its claim timeout
+func claimTimeoutApproaching(claim *agentsv1alpha1.SandboxClaim, status *agentsv1alpha1.SandboxClaimStatus, now time.Time) (time.Duration, bool) {
+	if claim.Spec.ClaimTimeout == nil || status.ClaimStartTime == nil {
+		return 0, false
+	}
+	timeout := claim.Spec.ClaimTimeout.Duration
+	remaining := status.ClaimStartTime.Add(timeout).Sub(now)
+	return remaining, remaining > 0 && remaining <= timeout/claimTimeoutWarningFraction
+}
+
+// observeClaimTimedOut records a claim timing out for the claim latency SLO of its pool
+func observeClaimTimedOut(claim *agentsv1alpha1.SandboxClaim, sandboxSet *agentsv1alpha1.SandboxSet, status *agentsv1alpha1.SandboxClaimStatus) {
+	if status.ClaimStartTime == nil {
+		return
+	}
+	now := time.Now()
+	slo.Default.ObserveClaim(claim.Namespace, sandboxSet.Name, now.Sub(status.ClaimStartTime.Time), false, now)
+}
+
+// isClaimCanceled checks if the claim is canceled by the cancel annotation
+func isClaimCanceled(claim *agentsv1alpha1.SandboxClaim) bool {
+	return claim.Annotations[agentsv1alpha1.AnnotationClaimCancel] == "true"
+}
+
+// isClaimShutdown checks if the claimed sandboxes have reached the shutdown time of the claim
+func isClaimShutdown(claim *agentsv1alpha1.SandboxClaim) bool {
+	return claim.Spec.ShutdownTime != nil && !time.Now().Before(claim.Spec.ShutdownTime.Time)
+}
+
+// claimReleaseReason returns the reason to release the claimed sandboxes of a claim whose lease expired, or timed out
+// or canceled before all replicas are claimed, or "" if they are to be kept, according to spec.onTimeout, or are
+// released already.
+func claimReleaseReason(claim *agentsv1alpha1.SandboxClaim, status *agentsv1alpha1.SandboxClaimStatus) string {
+	if conditions.IsTrue(status.Conditions, string(agentsv1alpha1.SandboxClaimConditionReleased)) {
+		return ""
+	}
+	if status.LeaseExpireTime != nil && !time.Now().Before(status.LeaseExpireTime.Time) {
+		return "LeaseExpired"
+	}
+	if !releasesOnTimeout(claim) {
+		return ""
+	}
+	if conditions.IsTrue(status.Conditions, string(agentsv1alpha1.SandboxClaimConditionCanceled)) {
+		return "ClaimCanceled"
+	}
+	if shouldReleaseOnTimeout(claim, status) {
+		return "ClaimTimeoutReached"
+	}
+	return ""
+}
+
+// leaseExpireTime returns when the lease of the completed claim expires, spec.leaseDuration after its completion or
+// its last renewal by the lease-renew-time annotation. Returns nil if the claim has no lease, is not completed, or its
+// sandboxes are released already. Renewals in the future are ignored until reached, and invalid ones altogether.
+func leaseExpireTime(claim *agentsv1alpha1.SandboxClaim, status *agentsv1alpha1.SandboxClaimStatus, now time.Time) *metav1.Time {
+	if claim.Spec.LeaseDuration == nil || status.CompletionTime == nil ||
+		conditions.IsTrue(status.Conditions, string(agentsv1alpha1.SandboxClaimConditionReleased)) {
+		return nil
+	}
+	start := status.CompletionTime.Time
+	if value, ok := claim.Annotations[agentsv1alpha1.AnnotationClaimLeaseRenewTime]; ok {
+		renewed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			klog.InfoS("Ignored invalid lease renewal of SandboxClaim", "claim", klog.KObj(claim), "renewTime", value)
+		} else if renewed.After(start) && !renewed.After(now) {
+			start = renewed
+		}
+	}
+	expire := metav1.NewTime(start.Add(claim.Spec.LeaseDuration.Duration))
+	return &expire
+}
+
+// shouldReleaseOnTimeout checks if the claimed sandboxes of a timed out claim are to be released, the claim times out
+// on either the claim timeout or the per-replica timeout
+func shouldReleaseOnTimeout(claim *agentsv1alpha1.SandboxClaim, status *agentsv1alpha1.SandboxClaimStatus) bool {
+	timedOut := conditions.IsTrue(status.Conditions, string(agentsv1alpha1.SandboxClaimConditionTimedOut)) ||
+		conditions.IsTrue(status.Conditions, string(agentsv1alpha1.SandboxClaimConditionReplicaTimedOut))
+	return releasesOnTimeout(claim) && timedOut &&
+		!conditions.IsTrue(status.Conditions, string(agentsv1alpha1.SandboxClaimConditionReleased))
+}
+
+// timeoutPolicy returns the spec.onTimeout of the claim, defaulting to KeepClaimed
+func timeoutPolicy(claim *agentsv1alpha1.SandboxClaim) agentsv1alpha1.SandboxClaimTimeoutPolicy {
+	if claim.Spec.OnTimeout == "" {
+		return agentsv1alpha1.SandboxClaimTimeoutKeepClaimed
+	}
+	return claim.Spec.OnTimeout
+}
+
+// releasesOnTimeout checks if the spec.onTimeout of the claim releases the claimed sandboxes on timeout or cancel
+func releasesOnTimeout(claim *agentsv1alpha1.SandboxClaim) bool {
+	return claim.Spec.OnTimeout == agentsv1alpha1.SandboxClaimTimeoutReleaseClaimed ||
+		claim.Spec.OnTimeout == agentsv1alpha1.SandboxClaimTimeoutFail
+}
+
+// timedOutPhase returns the phase a timed out claim ends in, Failed if its spec.onTimeout is Fail
+func timedOutPhase(claim *agentsv1alpha1.SandboxClaim) agentsv1alpha1.SandboxClaimPhase {
+	if claim.Spec.OnTimeout == agentsv1alpha1.SandboxClaimTimeoutFail {
+		return agentsv1alpha1.SandboxClaimPhaseFailed
+	}
+	return agentsv1alpha1.SandboxClaimPhaseCompleted
+}
+
+// completedOnTimeout returns the status of the Completed condition of a timed out claim, False if it failed, so that
+// a failed claim is not taken for a completed one by its conditions either
+func completedOnTimeout(claim *agentsv1alpha1.SandboxClaim) metav1.ConditionStatus {
+	if timedOutPhase(claim) == agentsv1alpha1.SandboxClaimPhaseFailed {
+		return metav1.ConditionFalse
+	}
+	return metav1.ConditionTrue
+}
+
+// IsClaimFinished checks if the claim is in a terminal phase, i.e. Completed or Failed
+func IsClaimFinished(status *agentsv1alpha1.SandboxClaimStatus) bool {
+	return status.Phase == agentsv1alpha1.SandboxClaimPhaseCompleted || status.Phase == agentsv1alpha1.SandboxClaimPhaseFailed
+}
+
+// IsDowngradedToBestEffort checks if the claim timed out and goes on claiming without a deadline
+func IsDowngradedToBestEffort(status *agentsv1alpha1.SandboxClaimStatus) bool {
+	cond := conditions.Get(status.Conditions, string(agentsv1alpha1.SandboxClaimConditionTimeoutHandled))
+	return cond != nil && cond.Status == metav1.ConditionTrue && cond.Reason == string(agentsv1alpha1.SandboxClaimTimeoutBestEffort)
+}
+
 // isReplicasMet checks if the desired number of replicas has been claimed
 func isReplicasMet(claim *agentsv1alpha1.SandboxClaim, status *agentsv1alpha1.SandboxClaimStatus) bool {
 	return status.ClaimedReplicas >= getDesiredReplicas(claim)
@@ -146,111 +411,363 @@ func isReplicasMet(claim *agentsv1alpha1.SandboxClaim, status *agentsv1alpha1.Sa
 
 // TransitionToCompleted transitions the claim to Completed state with a generic reason
 func TransitionToCompleted(status *agentsv1alpha1.SandboxClaimStatus, reason, message string) *agentsv1alpha1.SandboxClaimStatus {
+	completeBoundReplicas(status)
 	status.Phase = agentsv1alpha1.SandboxClaimPhaseCompleted
 	status.Message = message
-	now := metav1.Now()
+	builder := conditions.NewBuilder(&status.Conditions, status.ObservedGeneration)
+	now := builder.Now()
 	status.CompletionTime = &now
 
-	condition := metav1.Condition{
-		Type:               string(agentsv1alpha1.SandboxClaimConditionCompleted),
-		Status:             metav1.ConditionTrue,
-		Reason:             reason,
-		Message:            message,
-		LastTransitionTime: now,
-	}
-	SetClaimCondition(status, condition)
+	builder.True(string(agentsv1alpha1.SandboxClaimConditionCompleted), reason, message)
+	recordHistory(status, reason, message)
 
 	return status
 }
 
+// completeBoundReplicas counts all the sandboxes bound to a completing claim in claimedReplicas, including the ones
+// not Ready for its minReadySeconds yet, as the claim stops waiting for them.
+func completeBoundReplicas(status *agentsv1alpha1.SandboxClaimStatus) {
+	status.ClaimedReplicas = boundReplicas(status)
+	status.BoundReplicas = 0
+}
+
+// transitionToCompletedWithCancel transitions to Completed as the claim is canceled
+func transitionToCompletedWithCancel(status *agentsv1alpha1.SandboxClaimStatus, claim *agentsv1alpha1.SandboxClaim) *agentsv1alpha1.SandboxClaimStatus {
+	message := fmt.Sprintf("Canceled, claimed %d/%d sandboxes", status.ClaimedReplicas, getDesiredReplicas(claim))
+	conditions.NewBuilder(&status.Conditions, status.ObservedGeneration).
+		True(string(agentsv1alpha1.SandboxClaimConditionCanceled), "ClaimCanceled",
+			fmt.Sprintf("Canceled by annotation %s", agentsv1alpha1.AnnotationClaimCancel))
+	status.QueuedPosition = nil
+	status.ETA = nil
+	return TransitionToCompleted(status, "Canceled", message)
+}
+
 // transitionToCompletedWithTimeout transitions to Completed due to timeout
 func transitionToCompletedWithTimeout(status *agentsv1alpha1.SandboxClaimStatus, elapsed time.Duration, claim *agentsv1alpha1.SandboxClaim) *agentsv1alpha1.SandboxClaimStatus {
 	desiredReplicas := getDesiredReplicas(claim)
+	completeBoundReplicas(status)
 
-	status.Phase = agentsv1alpha1.SandboxClaimPhaseCompleted
+	status.Phase = timedOutPhase(claim)
 	status.Message = fmt.Sprintf("Timeout reached after %v, claimed %d/%d sandboxes",
 		elapsed, status.ClaimedReplicas, desiredReplicas)
-	now := metav1.Now()
+	builder := conditions.NewBuilder(&status.Conditions, status.ObservedGeneration)
+	now := builder.Now()
 	status.CompletionTime = &now
 
-	// Set TimedOut condition
-	condition := metav1.Condition{
-		Type:               string(agentsv1alpha1.SandboxClaimConditionTimedOut),
-		Status:             metav1.ConditionTrue,
-		Reason:             "ClaimTimeoutReached",
-		Message:            fmt.Sprintf("Timeout after %v, claimed %d/%d", elapsed, status.ClaimedReplicas, desiredReplicas),
-		LastTransitionTime: now,
-	}
-	SetClaimCondition(status, condition)
+	// Set TimedOut condition, and also the Completed condition, False if the claim failed
+	builder.
+		True(string(agentsv1alpha1.SandboxClaimConditionTimedOut), "ClaimTimeoutReached",
+			fmt.Sprintf("Timeout after %v, claimed %d/%d", elapsed, status.ClaimedReplicas, desiredReplicas)).
+		True(string(agentsv1alpha1.SandboxClaimConditionTimeoutHandled), string(timeoutPolicy(claim)), timeoutHandledMessage(claim)).
+		Set(string(agentsv1alpha1.SandboxClaimConditionCompleted), completedOnTimeout(claim), "TimeoutReached", status.Message)
+	recordHistory(status, "TimeoutReached", status.Message)
 
-	// Also set Completed condition
-	completedCondition := metav1.Condition{
-		Type:               string(agentsv1alpha1.SandboxClaimConditionCompleted),
-		Status:             metav1.ConditionTrue,
-		Reason:             "TimeoutReached",
-		Message:            status.Message,
-		LastTransitionTime: now,
-	}
-	SetClaimCondition(status, completedCondition)
+	return status
+}
+
+// transitionToCompletedWithReplicaTimeout transitions to Completed after binding a single sandbox exceeded the
+// per-replica timeout
+func transitionToCompletedWithReplicaTimeout(status *agentsv1alpha1.SandboxClaimStatus, timeout time.Duration, claim *agentsv1alpha1.SandboxClaim) *agentsv1alpha1.SandboxClaimStatus {
+	desiredReplicas := getDesiredReplicas(claim)
+	completeBoundReplicas(status)
+
+	status.Phase = timedOutPhase(claim)
+	status.Message = fmt.Sprintf("Binding a sandbox exceeded the per-replica timeout of %v, claimed %d/%d sandboxes",
+		timeout, status.ClaimedReplicas, desiredReplicas)
+	builder := conditions.NewBuilder(&status.Conditions, status.ObservedGeneration)
+	now := builder.Now()
+	status.CompletionTime = &now
+
+	builder.
+		True(string(agentsv1alpha1.SandboxClaimConditionReplicaTimedOut), "PerReplicaTimeoutReached",
+			fmt.Sprintf("Bind exceeded %v, claimed %d/%d", timeout, status.ClaimedReplicas, desiredReplicas)).
+		True(string(agentsv1alpha1.SandboxClaimConditionTimeoutHandled), string(timeoutPolicy(claim)), timeoutHandledMessage(claim)).
+		Set(string(agentsv1alpha1.SandboxClaimConditionCompleted), completedOnTimeout(claim), "ReplicaTimeoutReached", status.Message)
+	recordHistory(status, "ReplicaTimeoutReached", status.Message)
+
+	return status
+}
+
+// downgradeToBestEffortWithTimeout keeps a claim reaching its claim timeout in Claiming without a deadline, as its
+// spec.onTimeout is BestEffort
+func downgradeToBestEffortWithTimeout(status *agentsv1alpha1.SandboxClaimStatus, elapsed time.Duration, claim *agentsv1alpha1.SandboxClaim) *agentsv1alpha1.SandboxClaimStatus {
+	desiredReplicas := getDesiredReplicas(claim)
+	conditions.NewBuilder(&status.Conditions, status.ObservedGeneration).
+		True(string(agentsv1alpha1.SandboxClaimConditionTimedOut), "ClaimTimeoutReached",
+			fmt.Sprintf("Timeout after %v, claimed %d/%d", elapsed, status.ClaimedReplicas, desiredReplicas))
+	return downgradeToBestEffort(status, claim,
+		fmt.Sprintf("Timeout reached after %v, claiming the rest on a best-effort basis: %d/%d claimed",
+			elapsed, status.ClaimedReplicas, desiredReplicas))
+}
 
+// downgradeToBestEffortWithReplicaTimeout keeps a claim whose bind exceeded the per-replica timeout in Claiming
+// without a deadline, as its spec.onTimeout is BestEffort
+func downgradeToBestEffortWithReplicaTimeout(status *agentsv1alpha1.SandboxClaimStatus, timeout time.Duration, claim *agentsv1alpha1.SandboxClaim) *agentsv1alpha1.SandboxClaimStatus {
+	desiredReplicas := getDesiredReplicas(claim)
+	conditions.NewBuilder(&status.Conditions, status.ObservedGeneration).
+		True(string(agentsv1alpha1.SandboxClaimConditionReplicaTimedOut), "PerReplicaTimeoutReached",
+			fmt.Sprintf("Bind exceeded %v, claimed %d/%d", timeout, status.ClaimedReplicas, desiredReplicas))
+	return downgradeToBestEffort(status, claim,
+		fmt.Sprintf("Binding a sandbox exceeded the per-replica timeout of %v, claiming the rest on a best-effort basis: %d/%d claimed",
+			timeout, status.ClaimedReplicas, desiredReplicas))
+}
+
+func downgradeToBestEffort(status *agentsv1alpha1.SandboxClaimStatus, claim *agentsv1alpha1.SandboxClaim, message string) *agentsv1alpha1.SandboxClaimStatus {
+	status.Message = message
+	conditions.NewBuilder(&status.Conditions, status.ObservedGeneration).
+		True(string(agentsv1alpha1.SandboxClaimConditionTimeoutHandled), string(agentsv1alpha1.SandboxClaimTimeoutBestEffort),
+			timeoutHandledMessage(claim))
+	recordHistory(status, "DowngradedToBestEffort", message)
 	return status
 }
 
+// timeoutHandledMessage describes the spec.onTimeout behavior applied to a timed out claim
+func timeoutHandledMessage(claim *agentsv1alpha1.SandboxClaim) string {
+	switch timeoutPolicy(claim) {
+	case agentsv1alpha1.SandboxClaimTimeoutReleaseClaimed:
+		return "Claimed sandboxes are released"
+	case agentsv1alpha1.SandboxClaimTimeoutBestEffort:
+		return "Downgraded to best-effort, claiming the rest without a deadline"
+	case agentsv1alpha1.SandboxClaimTimeoutFail:
+		return "Claimed sandboxes are released and the claim failed"
+	default:
+		return "Claimed sandboxes are kept"
+	}
+}
+
 // transitionToCompletedWithSuccess transitions to Completed after successfully claiming all replicas
 func transitionToCompletedWithSuccess(status *agentsv1alpha1.SandboxClaimStatus, claim *agentsv1alpha1.SandboxClaim) *agentsv1alpha1.SandboxClaimStatus {
 	desiredReplicas := getDesiredReplicas(claim)
+	completeBoundReplicas(status)
 
 	status.Phase = agentsv1alpha1.SandboxClaimPhaseCompleted
 	status.Message = fmt.Sprintf("Successfully claimed %d/%d sandboxes", status.ClaimedReplicas, desiredReplicas)
-	now := metav1.Now()
+	builder := conditions.NewBuilder(&status.Conditions, status.ObservedGeneration)
+	now := builder.Now()
 	status.CompletionTime = &now
 
-	condition := metav1.Condition{
-		Type:               string(agentsv1alpha1.SandboxClaimConditionCompleted),
-		Status:             metav1.ConditionTrue,
-		Reason:             "AllReplicasClaimed",
-		Message:            fmt.Sprintf("Successfully claimed all %d sandboxes", status.ClaimedReplicas),
-		LastTransitionTime: now,
-	}
-	SetClaimCondition(status, condition)
+	builder.True(string(agentsv1alpha1.SandboxClaimConditionCompleted), "AllReplicasClaimed",
+		fmt.Sprintf("Successfully claimed all %d sandboxes", status.ClaimedReplicas))
+	recordHistory(status, "AllReplicasClaimed", status.Message)
 
 	return status
 }
 
-// SetClaimCondition sets or updates a condition in the SandboxClaim status.
-func SetClaimCondition(status *agentsv1alpha1.SandboxClaimStatus, condition metav1.Condition) {
-	currentCond := GetClaimCondition(status, condition.Type)
+// transitionToClaimingOnReplicaLost moves a Completed claim back to Claiming after some of its claimed
+// sandboxes died, so that replacements can be bound. The claim timeout starts over from now.
+func transitionToClaimingOnReplicaLost(status *agentsv1alpha1.SandboxClaimStatus, claim *agentsv1alpha1.SandboxClaim, alive int32) *agentsv1alpha1.SandboxClaimStatus {
+	lost := status.ClaimedReplicas - alive
+	message := fmt.Sprintf("Lost %d claimed sandbox(es), claiming replacements: %d/%d claimed",
+		lost, alive, getDesiredReplicas(claim))
 
-	// Check if there's any substantive change
-	if currentCond != nil && currentCond.Status == condition.Status &&
-		currentCond.Reason == condition.Reason &&
-		currentCond.Message == condition.Message {
-		// No change needed, avoid unnecessary update
-		return
-	} else if currentCond == nil {
-		// Add new condition
-		status.Conditions = append(status.Conditions, condition)
-		return
+	status.Phase = agentsv1alpha1.SandboxClaimPhaseClaiming
+	status.ClaimedReplicas = alive
+	status.Message = message
+	builder := conditions.NewBuilder(&status.Conditions, status.ObservedGeneration)
+	now := builder.Now()
+	status.ClaimStartTime = &now
+	status.CompletionTime = nil
+	status.LeaseExpireTime = nil
+
+	builder.
+		True(string(agentsv1alpha1.SandboxClaimConditionReplicaLost), "ClaimedSandboxDead", message).
+		False(string(agentsv1alpha1.SandboxClaimConditionCompleted), "ReplacingLostReplicas", message).
+		Remove(string(agentsv1alpha1.SandboxClaimConditionTimedOut)).
+		Remove(string(agentsv1alpha1.SandboxClaimConditionReplicaTimedOut)).
+		Remove(string(agentsv1alpha1.SandboxClaimConditionTimeoutHandled)).
+		Remove(string(agentsv1alpha1.SandboxClaimConditionNotified))
+	recordHistory(status, "ReplicaLost", message)
+
+	return status
+}
+
+// transitionToClaimingOnScaleUp moves a claim that has claimed all its replicas back to Claiming after its replicas
+// were increased. The claim timeout starts over from now.
+func transitionToClaimingOnScaleUp(status *agentsv1alpha1.SandboxClaimStatus, claim *agentsv1alpha1.SandboxClaim) *agentsv1alpha1.SandboxClaimStatus {
+	message := fmt.Sprintf("Replicas increased, claiming the added replicas: %d/%d claimed",
+		status.ClaimedReplicas, getDesiredReplicas(claim))
+
+	status.Phase = agentsv1alpha1.SandboxClaimPhaseClaiming
+	status.Message = message
+	builder := conditions.NewBuilder(&status.Conditions, status.ObservedGeneration)
+	now := builder.Now()
+	status.ClaimStartTime = &now
+	status.CompletionTime = nil
+	status.LeaseExpireTime = nil
+
+	builder.False(string(agentsv1alpha1.SandboxClaimConditionCompleted), "ReplicasIncreased", message).
+		Remove(string(agentsv1alpha1.SandboxClaimConditionNotified))
+	recordHistory(status, "ReplicasIncreased", message)
+
+	return status
+}
+
+// markReleasedOnScaleDown records the release of the claimed sandboxes beyond the decreased replicas of the claim
+func markReleasedOnScaleDown(status *agentsv1alpha1.SandboxClaimStatus, claim *agentsv1alpha1.SandboxClaim, released int32) *agentsv1alpha1.SandboxClaimStatus {
+	status.ClaimedReplicas = max(status.ClaimedReplicas-released, 0)
+	status.Message = fmt.Sprintf("Released %d claimed sandbox(es) beyond replicas: %d/%d claimed",
+		released, status.ClaimedReplicas, getDesiredReplicas(claim))
+	recordHistory(status, "ReleasedOnScaleDown", status.Message)
+	return status
+}
+
+// markClaimReleased records that the claimed sandboxes of a claim whose lease expired, or timed out or canceled claim
+// have been released, the reason is returned by claimReleaseReason
+func markClaimReleased(status *agentsv1alpha1.SandboxClaimStatus, released int32, reason string) *agentsv1alpha1.SandboxClaimStatus {
+	status.ClaimedReplicas = 0
+	historyReason, cause := releaseEvent(reason)
+	message := fmt.Sprintf("Released %d claimed sandbox(es) %s", released, cause)
+	builder := conditions.NewBuilder(&status.Conditions, status.ObservedGeneration).
+		True(string(agentsv1alpha1.SandboxClaimConditionReleased), reason, message)
+	if reason == "LeaseExpired" {
+		status.LeaseExpireTime = nil
+		status.Message = message
+		builder.True(string(agentsv1alpha1.SandboxClaimConditionLeaseExpired), "LeaseNotRenewed", message)
+	} else {
+		status.Message = fmt.Sprintf("%s, released %d claimed sandbox(es)", status.Message, released)
 	}
+	recordHistory(status, historyReason, message)
+	return status
+}
 
-	// Only update LastTransitionTime when Status changes
-	if currentCond.Status != condition.Status {
-		currentCond.LastTransitionTime = condition.LastTransitionTime
+// releaseEvent returns the event reason and the cause reporting the release of claimed sandboxes for the reason
+func releaseEvent(reason string) (string, string) {
+	switch reason {
+	case "ClaimCanceled":
+		return "ReleasedOnCancel", "as the claim is canceled"
+	case "LeaseExpired":
+		return "ReleasedOnLeaseExpired", "as the lease expired without renewal"
 	}
+	return "ReleasedOnTimeout", "after claim timeout"
+}
 
-	// Update other fields
-	currentCond.Status = condition.Status
-	currentCond.Reason = condition.Reason
-	currentCond.Message = condition.Message
+// hasHistorySince checks if an entry of the reason was recorded in the history since the time
+func hasHistorySince(status *agentsv1alpha1.SandboxClaimStatus, reason string, since time.Time) bool {
+	for i := range status.History {
+		if status.History[i].Reason == reason && !status.History[i].Time.Time.Before(since) {
+			return true
+		}
+	}
+	return false
 }
 
-// GetClaimCondition returns the condition with the provided type from SandboxClaim status
-func GetClaimCondition(status *agentsv1alpha1.SandboxClaimStatus, condType string) *metav1.Condition {
-	for i := range status.Conditions {
-		c := &status.Conditions[i]
-		if c.Type == condType {
-			return c
+// recordHistory appends a transition to the history of the claim, dropping the oldest entries beyond the limit
+// of --max-sandboxclaim-history.
+// A transition repeating the last one with the same phase and claimed replicas is skipped, so that retries
+// like claiming on shortage do not flush the history.
+func recordHistory(status *agentsv1alpha1.SandboxClaimStatus, reason, message string) {
+	if n := len(status.History); n > 0 {
+		last := status.History[n-1]
+		if last.Reason == reason && last.Phase == status.Phase && last.ClaimedReplicas == status.ClaimedReplicas {
+			return
 		}
 	}
-	return nil
+	status.History = append(status.History, agentsv1alpha1.SandboxClaimHistoryEntry{
+		Time:            metav1.Now(),
+		Phase:           status.Phase,
+		Reason:          reason,
+		Message:         message,
+		ClaimedReplicas: status.ClaimedReplicas,
+	})
+	if limit := objectsize.DefaultOptions.MaxClaimHistory; limit > 0 && len(status.History) > limit {
+		overflow := len(status.History) - limit
+		status.History = append([]agentsv1alpha1.SandboxClaimHistoryEntry(nil), status.History[overflow:]...)
+	}
+}
+
+// buildClaimedSandboxes builds the status list of the claimed sandboxes, ignoring deleting ones
+func buildClaimedSandboxes(sandboxes []*agentsv1alpha1.Sandbox) []agentsv1alpha1.SandboxClaimClaimedSandbox {
+	var claimed []agentsv1alpha1.SandboxClaimClaimedSandbox
+	for _, sbx := range sandboxes {
+		if sbx.DeletionTimestamp != nil {
+			continue
+		}
+		claimed = append(claimed, agentsv1alpha1.SandboxClaimClaimedSandbox{
+			Name:      sbx.Name,
+			UID:       sbx.UID,
+			SandboxID: sandboxutils.GetSandboxID(sbx),
+			PodIP:     sbx.Status.PodInfo.PodIP,
+			Ready:     sandboxutils.IsSandboxReady(sbx),
+		})
+	}
+	sort.Slice(claimed, func(i, j int) bool {
+		return claimed[i].Name < claimed[j].Name
+	})
+	if len(claimed) > agentsv1alpha1.SandboxClaimClaimedSandboxesLimit {
+		claimed = claimed[:agentsv1alpha1.SandboxClaimClaimedSandboxesLimit]
+	}
+	return claimed
+}
+
+// buildTemplateStatuses counts the sandboxes claimed from each SandboxSet, ignoring deleting ones, sorted by name
+func buildTemplateStatuses(sandboxes []*agentsv1alpha1.Sandbox) []agentsv1alpha1.SandboxClaimTemplateStatus {
+	counts := map[string]int32{}
+	for _, sbx := range sandboxes {
+		if sbx.DeletionTimestamp != nil {
+			continue
+		}
+		if pool := sbx.Labels[agentsv1alpha1.LabelSandboxPool]; pool != "" {
+			counts[pool]++
+		}
+	}
+	var templates []agentsv1alpha1.SandboxClaimTemplateStatus
+	for name, claimed := range counts {
+		templates = append(templates, agentsv1alpha1.SandboxClaimTemplateStatus{Name: name, ClaimedReplicas: claimed})
+	}
+	sort.Slice(templates, func(i, j int) bool {
+		return templates[i].Name < templates[j].Name
+	})
+	return templates
+}
+
+// buildResultSecret builds the result Secret of a claim from the sandboxes claimed by it, ignoring deleting ones
+func buildResultSecret(claim *agentsv1alpha1.SandboxClaim, sandboxes []*agentsv1alpha1.Sandbox,
+	cache *sandboxcr.Cache, client *clients.ClientSet) (*corev1.Secret, error) {
+	results := buildResultSandboxes(sandboxes, cache, client)
+	ids := make([]string, 0, len(results))
+	for _, result := range results {
+		ids = append(ids, result.SandboxID)
+	}
+	data, err := json.Marshal(results)
+	if err != nil {
+		return nil, err
+	}
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      claim.Spec.ResultSecretName,
+			Namespace: claim.Namespace,
+			Labels: map[string]string{
+				agentsv1alpha1.LabelSandboxClaimName: claim.Name,
+			},
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(claim, agentsv1alpha1.GroupVersion.WithKind("SandboxClaim")),
+			},
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: map[string][]byte{
+			agentsv1alpha1.SandboxClaimResultSandboxIDsKey: []byte(strings.Join(ids, "\n")),
+			agentsv1alpha1.SandboxClaimResultSandboxesKey:  data,
+		},
+	}, nil
+}
+
+// buildResultSandboxes lists the endpoints of the claimed sandboxes, ignoring deleting ones, sorted by name
+func buildResultSandboxes(sandboxes []*agentsv1alpha1.Sandbox, cache *sandboxcr.Cache, client *clients.ClientSet) []agentsv1alpha1.SandboxClaimResultSandbox {
+	results := make([]agentsv1alpha1.SandboxClaimResultSandbox, 0, len(sandboxes))
+	for _, sbx := range sandboxes {
+		if sbx.DeletionTimestamp != nil {
+			continue
+		}
+		sandbox := sandboxcr.AsSandbox(sbx, cache, client)
+		results = append(results, agentsv1alpha1.SandboxClaimResultSandbox{
+			SandboxID:   sandbox.GetSandboxID(),
+			Name:        sbx.Name,
+			RuntimeURL:  sandbox.GetRuntimeURL(),
+			AccessToken: sandbox.GetAccessToken(),
+		})
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Name < results[j].Name
+	})
+	return results
 }
@@ -17,12 +17,16 @@ limitations under the License.
 package core
 
 import (
+	"fmt"
+	"reflect"
 	"testing"
 	"time"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 
 	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+	"github.com/openkruise/agents/pkg/utils/conditions"
 )
 
 func TestGetDesiredReplicas(t *testing.T) {
@@ -258,8 +262,11 @@ func TestCalculateClaimStatus(t *testing.T) {
 		args              ClaimArgs
 		expectedPhase     agentsv1alpha1.SandboxClaimPhase
 		shouldRequeue     bool
-		checkCompletedSet bool // Whether CompletionTime should be set
-		checkStartTimeSet bool // Whether ClaimStartTime should be set
+		checkCompletedSet bool                   // Whether CompletionTime should be set
+		checkStartTimeSet bool                   // Whether ClaimStartTime should be set
+		checkCanceled     bool                   // Whether the Canceled condition should be set
+		timeoutHandled    string                 // Expected reason of the TimeoutHandled condition, if any
+		completed         metav1.ConditionStatus // Expected status of the Completed condition, if any
 	}{
 		{
 			name: "initialize new claim",
@@ -279,6 +286,28 @@ func TestCalculateClaimStatus(t *testing.T) {
 			shouldRequeue:     false,
 			checkStartTimeSet: true, // ClaimStartTime should be set when initializing
 		},
+		{
+			name: "initialize new claim of a pool queueing claims",
+			args: ClaimArgs{
+				Claim: &agentsv1alpha1.SandboxClaim{
+					ObjectMeta: metav1.ObjectMeta{
+						Generation: 1,
+					},
+					Spec: agentsv1alpha1.SandboxClaimSpec{
+						TemplateName: "test",
+					},
+				},
+				SandboxSet: &agentsv1alpha1.SandboxSet{
+					Spec: agentsv1alpha1.SandboxSetSpec{
+						ClaimQueue: &agentsv1alpha1.SandboxSetClaimQueue{},
+					},
+				},
+				NewStatus: &agentsv1alpha1.SandboxClaimStatus{},
+			},
+			expectedPhase:     agentsv1alpha1.SandboxClaimPhaseQueued,
+			shouldRequeue:     false,
+			checkStartTimeSet: true, // the claim timeout covers the time in the queue
+		},
 		{
 			name: "already completed",
 			args: ClaimArgs{
@@ -339,6 +368,113 @@ func TestCalculateClaimStatus(t *testing.T) {
 			expectedPhase:     agentsv1alpha1.SandboxClaimPhaseCompleted,
 			shouldRequeue:     true,
 			checkCompletedSet: true,
+			timeoutHandled:    string(agentsv1alpha1.SandboxClaimTimeoutKeepClaimed),
+			completed:         metav1.ConditionTrue,
+		},
+		{
+			name: "claim timeout with ReleaseClaimed",
+			args: ClaimArgs{
+				Claim: &agentsv1alpha1.SandboxClaim{
+					ObjectMeta: metav1.ObjectMeta{
+						Generation: 1,
+					},
+					Spec: agentsv1alpha1.SandboxClaimSpec{
+						TemplateName: "test",
+						ClaimTimeout: &metav1.Duration{Duration: 5 * time.Second},
+						OnTimeout:    agentsv1alpha1.SandboxClaimTimeoutReleaseClaimed,
+					},
+				},
+				SandboxSet: &agentsv1alpha1.SandboxSet{},
+				NewStatus: &agentsv1alpha1.SandboxClaimStatus{
+					Phase:          agentsv1alpha1.SandboxClaimPhaseClaiming,
+					ClaimStartTime: &pastTime,
+				},
+			},
+			expectedPhase:     agentsv1alpha1.SandboxClaimPhaseCompleted,
+			shouldRequeue:     true,
+			checkCompletedSet: true,
+			timeoutHandled:    string(agentsv1alpha1.SandboxClaimTimeoutReleaseClaimed),
+		},
+		{
+			name: "claim timeout with Fail",
+			args: ClaimArgs{
+				Claim: &agentsv1alpha1.SandboxClaim{
+					ObjectMeta: metav1.ObjectMeta{
+						Generation: 1,
+					},
+					Spec: agentsv1alpha1.SandboxClaimSpec{
+						TemplateName: "test",
+						ClaimTimeout: &metav1.Duration{Duration: 5 * time.Second},
+						OnTimeout:    agentsv1alpha1.SandboxClaimTimeoutFail,
+					},
+				},
+				SandboxSet: &agentsv1alpha1.SandboxSet{},
+				NewStatus: &agentsv1alpha1.SandboxClaimStatus{
+					Phase:          agentsv1alpha1.SandboxClaimPhaseClaiming,
+					ClaimStartTime: &pastTime,
+				},
+			},
+			expectedPhase:     agentsv1alpha1.SandboxClaimPhaseFailed,
+			shouldRequeue:     true,
+			checkCompletedSet: true,
+			timeoutHandled:    string(agentsv1alpha1.SandboxClaimTimeoutFail),
+			completed:         metav1.ConditionFalse,
+		},
+		{
+			name: "claim timeout with BestEffort - downgraded and keep claiming",
+			args: ClaimArgs{
+				Claim: &agentsv1alpha1.SandboxClaim{
+					ObjectMeta: metav1.ObjectMeta{
+						Generation: 1,
+					},
+					Spec: agentsv1alpha1.SandboxClaimSpec{
+						TemplateName: "test",
+						Replicas:     int32Ptr(5),
+						ClaimTimeout: &metav1.Duration{Duration: 5 * time.Second},
+						OnTimeout:    agentsv1alpha1.SandboxClaimTimeoutBestEffort,
+					},
+				},
+				SandboxSet: &agentsv1alpha1.SandboxSet{},
+				NewStatus: &agentsv1alpha1.SandboxClaimStatus{
+					Phase:           agentsv1alpha1.SandboxClaimPhaseClaiming,
+					ClaimedReplicas: 2,
+					ClaimStartTime:  &pastTime,
+				},
+			},
+			expectedPhase:  agentsv1alpha1.SandboxClaimPhaseClaiming,
+			shouldRequeue:  false,
+			timeoutHandled: string(agentsv1alpha1.SandboxClaimTimeoutBestEffort),
+		},
+		{
+			name: "best-effort claim completed once all replicas claimed",
+			args: ClaimArgs{
+				Claim: &agentsv1alpha1.SandboxClaim{
+					ObjectMeta: metav1.ObjectMeta{
+						Generation: 1,
+					},
+					Spec: agentsv1alpha1.SandboxClaimSpec{
+						TemplateName: "test",
+						Replicas:     int32Ptr(5),
+						ClaimTimeout: &metav1.Duration{Duration: 5 * time.Second},
+						OnTimeout:    agentsv1alpha1.SandboxClaimTimeoutBestEffort,
+					},
+				},
+				SandboxSet: &agentsv1alpha1.SandboxSet{},
+				NewStatus: &agentsv1alpha1.SandboxClaimStatus{
+					Phase:           agentsv1alpha1.SandboxClaimPhaseClaiming,
+					ClaimedReplicas: 5,
+					ClaimStartTime:  &pastTime,
+					Conditions: []metav1.Condition{{
+						Type:   string(agentsv1alpha1.SandboxClaimConditionTimeoutHandled),
+						Status: metav1.ConditionTrue,
+						Reason: string(agentsv1alpha1.SandboxClaimTimeoutBestEffort),
+					}},
+				},
+			},
+			expectedPhase:     agentsv1alpha1.SandboxClaimPhaseCompleted,
+			shouldRequeue:     true,
+			checkCompletedSet: true,
+			timeoutHandled:    string(agentsv1alpha1.SandboxClaimTimeoutBestEffort),
 		},
 		{
 			name: "replicas met",
@@ -383,6 +519,136 @@ func TestCalculateClaimStatus(t *testing.T) {
 			expectedPhase: agentsv1alpha1.SandboxClaimPhaseClaiming,
 			shouldRequeue: false,
 		},
+		{
+			name: "new claim requires approval",
+			args: ClaimArgs{
+				Claim: &agentsv1alpha1.SandboxClaim{
+					ObjectMeta: metav1.ObjectMeta{
+						Generation: 1,
+					},
+					Spec: agentsv1alpha1.SandboxClaimSpec{
+						TemplateName:     "test",
+						RequiresApproval: true,
+					},
+				},
+				SandboxSet: &agentsv1alpha1.SandboxSet{},
+				NewStatus:  &agentsv1alpha1.SandboxClaimStatus{},
+			},
+			expectedPhase: agentsv1alpha1.SandboxClaimPhasePendingApproval,
+			shouldRequeue: true,
+		},
+		{
+			name: "still pending approval",
+			args: ClaimArgs{
+				Claim: &agentsv1alpha1.SandboxClaim{
+					ObjectMeta: metav1.ObjectMeta{
+						Generation: 1,
+					},
+					Spec: agentsv1alpha1.SandboxClaimSpec{
+						TemplateName:     "test",
+						RequiresApproval: true,
+					},
+				},
+				SandboxSet: &agentsv1alpha1.SandboxSet{},
+				NewStatus: &agentsv1alpha1.SandboxClaimStatus{
+					Phase: agentsv1alpha1.SandboxClaimPhasePendingApproval,
+				},
+			},
+			expectedPhase: agentsv1alpha1.SandboxClaimPhasePendingApproval,
+			shouldRequeue: false,
+		},
+		{
+			name: "claim approved",
+			args: ClaimArgs{
+				Claim: &agentsv1alpha1.SandboxClaim{
+					ObjectMeta: metav1.ObjectMeta{
+						Generation:  1,
+						Annotations: map[string]string{agentsv1alpha1.AnnotationClaimApproval: agentsv1alpha1.SandboxClaimApprovalApproved},
+					},
+					Spec: agentsv1alpha1.SandboxClaimSpec{
+						TemplateName:     "test",
+						RequiresApproval: true,
+					},
+				},
+				SandboxSet: &agentsv1alpha1.SandboxSet{},
+				NewStatus: &agentsv1alpha1.SandboxClaimStatus{
+					Phase: agentsv1alpha1.SandboxClaimPhasePendingApproval,
+				},
+			},
+			expectedPhase:     agentsv1alpha1.SandboxClaimPhaseClaiming,
+			shouldRequeue:     true,
+			checkStartTimeSet: true,
+		},
+		{
+			name: "claim rejected",
+			args: ClaimArgs{
+				Claim: &agentsv1alpha1.SandboxClaim{
+					ObjectMeta: metav1.ObjectMeta{
+						Generation:  1,
+						Annotations: map[string]string{agentsv1alpha1.AnnotationClaimApproval: agentsv1alpha1.SandboxClaimApprovalRejected},
+					},
+					Spec: agentsv1alpha1.SandboxClaimSpec{
+						TemplateName:     "test",
+						RequiresApproval: true,
+					},
+				},
+				SandboxSet: &agentsv1alpha1.SandboxSet{},
+				NewStatus: &agentsv1alpha1.SandboxClaimStatus{
+					Phase: agentsv1alpha1.SandboxClaimPhasePendingApproval,
+				},
+			},
+			expectedPhase:     agentsv1alpha1.SandboxClaimPhaseCompleted,
+			shouldRequeue:     true,
+			checkCompletedSet: true,
+		},
+		{
+			name: "claiming claim canceled",
+			args: ClaimArgs{
+				Claim: &agentsv1alpha1.SandboxClaim{
+					ObjectMeta: metav1.ObjectMeta{
+						Generation:  1,
+						Annotations: map[string]string{agentsv1alpha1.AnnotationClaimCancel: "true"},
+					},
+					Spec: agentsv1alpha1.SandboxClaimSpec{
+						TemplateName: "test",
+						Replicas:     int32Ptr(5),
+					},
+				},
+				SandboxSet: &agentsv1alpha1.SandboxSet{},
+				NewStatus: &agentsv1alpha1.SandboxClaimStatus{
+					Phase:           agentsv1alpha1.SandboxClaimPhaseClaiming,
+					ClaimedReplicas: 2,
+					ClaimStartTime:  &now,
+				},
+			},
+			expectedPhase:     agentsv1alpha1.SandboxClaimPhaseCompleted,
+			shouldRequeue:     true,
+			checkCompletedSet: true,
+			checkCanceled:     true,
+		},
+		{
+			name: "cancel annotation not true - should keep claiming",
+			args: ClaimArgs{
+				Claim: &agentsv1alpha1.SandboxClaim{
+					ObjectMeta: metav1.ObjectMeta{
+						Generation:  1,
+						Annotations: map[string]string{agentsv1alpha1.AnnotationClaimCancel: "false"},
+					},
+					Spec: agentsv1alpha1.SandboxClaimSpec{
+						TemplateName: "test",
+						Replicas:     int32Ptr(5),
+					},
+				},
+				SandboxSet: &agentsv1alpha1.SandboxSet{},
+				NewStatus: &agentsv1alpha1.SandboxClaimStatus{
+					Phase:           agentsv1alpha1.SandboxClaimPhaseClaiming,
+					ClaimedReplicas: 2,
+					ClaimStartTime:  &now,
+				},
+			},
+			expectedPhase: agentsv1alpha1.SandboxClaimPhaseClaiming,
+			shouldRequeue: false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -405,6 +671,24 @@ func TestCalculateClaimStatus(t *testing.T) {
 				t.Errorf("CalculateClaimStatus() ClaimStartTime should be set but is nil")
 			}
 
+			if tt.checkCanceled && !conditions.IsTrue(gotStatus.Conditions, string(agentsv1alpha1.SandboxClaimConditionCanceled)) {
+				t.Errorf("CalculateClaimStatus() Canceled condition should be True")
+			}
+
+			if tt.timeoutHandled != "" {
+				cond := conditions.Get(gotStatus.Conditions, string(agentsv1alpha1.SandboxClaimConditionTimeoutHandled))
+				if cond == nil || cond.Status != metav1.ConditionTrue || cond.Reason != tt.timeoutHandled {
+					t.Errorf("CalculateClaimStatus() TimeoutHandled condition = %v, want True with reason %s", cond, tt.timeoutHandled)
+				}
+			}
+
+			if tt.completed != "" {
+				cond := conditions.Get(gotStatus.Conditions, string(agentsv1alpha1.SandboxClaimConditionCompleted))
+				if cond == nil || cond.Status != tt.completed {
+					t.Errorf("CalculateClaimStatus() Completed condition = %v, want %s", cond, tt.completed)
+				}
+			}
+
 			// Check ObservedGeneration is updated
 			if gotStatus.ObservedGeneration != tt.args.Claim.Generation {
 				t.Errorf("CalculateClaimStatus() ObservedGeneration = %v, want %v",
@@ -414,273 +698,6 @@ func TestCalculateClaimStatus(t *testing.T) {
 	}
 }
 
-func TestSetClaimCondition(t *testing.T) {
-	now := metav1.Now()
-	pastTime := metav1.NewTime(now.Add(-10 * time.Second))
-
-	t.Run("add first condition", func(t *testing.T) {
-		status := &agentsv1alpha1.SandboxClaimStatus{}
-
-		condition := metav1.Condition{
-			Type:               "TestCondition",
-			Status:             metav1.ConditionTrue,
-			Reason:             "TestReason",
-			Message:            "Test message",
-			LastTransitionTime: now,
-		}
-
-		SetClaimCondition(status, condition)
-
-		if len(status.Conditions) != 1 {
-			t.Errorf("Expected 1 condition, got %d", len(status.Conditions))
-		}
-
-		if status.Conditions[0].Type != "TestCondition" {
-			t.Errorf("Expected Type TestCondition, got %v", status.Conditions[0].Type)
-		}
-	})
-
-	t.Run("add second condition", func(t *testing.T) {
-		status := &agentsv1alpha1.SandboxClaimStatus{
-			Conditions: []metav1.Condition{
-				{
-					Type:               "FirstCondition",
-					Status:             metav1.ConditionTrue,
-					Reason:             "FirstReason",
-					Message:            "First message",
-					LastTransitionTime: now,
-				},
-			},
-		}
-
-		condition := metav1.Condition{
-			Type:               "SecondCondition",
-			Status:             metav1.ConditionFalse,
-			Reason:             "SecondReason",
-			Message:            "Second message",
-			LastTransitionTime: now,
-		}
-
-		SetClaimCondition(status, condition)
-
-		if len(status.Conditions) != 2 {
-			t.Errorf("Expected 2 conditions, got %d", len(status.Conditions))
-		}
-	})
-
-	t.Run("update existing condition", func(t *testing.T) {
-		status := &agentsv1alpha1.SandboxClaimStatus{
-			Conditions: []metav1.Condition{
-				{
-					Type:               "TestCondition",
-					Status:             metav1.ConditionTrue,
-					Reason:             "OldReason",
-					Message:            "Old message",
-					LastTransitionTime: pastTime,
-				},
-			},
-		}
-
-		condition := metav1.Condition{
-			Type:               "TestCondition",
-			Status:             metav1.ConditionFalse,
-			Reason:             "NewReason",
-			Message:            "New message",
-			LastTransitionTime: now,
-		}
-
-		SetClaimCondition(status, condition)
-
-		if len(status.Conditions) != 1 {
-			t.Errorf("Expected 1 condition, got %d", len(status.Conditions))
-		}
-
-		if status.Conditions[0].Status != metav1.ConditionFalse {
-			t.Errorf("Expected Status False, got %v", status.Conditions[0].Status)
-		}
-
-		if status.Conditions[0].Reason != "NewReason" {
-			t.Errorf("Expected Reason NewReason, got %v", status.Conditions[0].Reason)
-		}
-	})
-
-	t.Run("no update when nothing changes", func(t *testing.T) {
-		originalTime := metav1.NewTime(now.Add(-5 * time.Minute))
-		status := &agentsv1alpha1.SandboxClaimStatus{
-			Conditions: []metav1.Condition{
-				{
-					Type:               "Ready",
-					Status:             metav1.ConditionTrue,
-					LastTransitionTime: originalTime,
-					Reason:             "AllReady",
-					Message:            "Everything is ready",
-				},
-			},
-		}
-
-		// Try to update with same values but different LastTransitionTime
-		condition := metav1.Condition{
-			Type:               "Ready",
-			Status:             metav1.ConditionTrue,
-			LastTransitionTime: now, // Different time!
-			Reason:             "AllReady",
-			Message:            "Everything is ready",
-		}
-
-		SetClaimCondition(status, condition)
-
-		// LastTransitionTime should NOT change because Status/Reason/Message are the same
-		if !status.Conditions[0].LastTransitionTime.Equal(&originalTime) {
-			t.Errorf("Expected LastTransitionTime to remain %v, got %v (should not update when nothing changes)",
-				originalTime, status.Conditions[0].LastTransitionTime)
-		}
-	})
-
-	t.Run("preserve LastTransitionTime when only Reason changes", func(t *testing.T) {
-		originalTime := metav1.NewTime(now.Add(-10 * time.Minute))
-		status := &agentsv1alpha1.SandboxClaimStatus{
-			Conditions: []metav1.Condition{
-				{
-					Type:               "Ready",
-					Status:             metav1.ConditionTrue,
-					LastTransitionTime: originalTime,
-					Reason:             "OldReason",
-					Message:            "Old message",
-				},
-			},
-		}
-
-		// Update only Reason and Message, Status stays the same
-		condition := metav1.Condition{
-			Type:               "Ready",
-			Status:             metav1.ConditionTrue, // Same Status
-			LastTransitionTime: now,
-			Reason:             "NewReason",   // Different Reason
-			Message:            "New message", // Different Message
-		}
-
-		SetClaimCondition(status, condition)
-
-		// LastTransitionTime should NOT change because Status didn't change
-		if !status.Conditions[0].LastTransitionTime.Equal(&originalTime) {
-			t.Errorf("Expected LastTransitionTime to remain %v when Status doesn't change, got %v",
-				originalTime, status.Conditions[0].LastTransitionTime)
-		}
-
-		// But Reason and Message should be updated
-		if status.Conditions[0].Reason != "NewReason" {
-			t.Errorf("Expected Reason to be updated to NewReason, got %v", status.Conditions[0].Reason)
-		}
-		if status.Conditions[0].Message != "New message" {
-			t.Errorf("Expected Message to be updated, got %v", status.Conditions[0].Message)
-		}
-	})
-
-	t.Run("update LastTransitionTime when Status changes", func(t *testing.T) {
-		originalTime := metav1.NewTime(now.Add(-10 * time.Minute))
-		status := &agentsv1alpha1.SandboxClaimStatus{
-			Conditions: []metav1.Condition{
-				{
-					Type:               "Ready",
-					Status:             metav1.ConditionTrue,
-					LastTransitionTime: originalTime,
-					Reason:             "AllReady",
-					Message:            "Everything is ready",
-				},
-			},
-		}
-
-		// Change Status
-		condition := metav1.Condition{
-			Type:               "Ready",
-			Status:             metav1.ConditionFalse, // Status changed!
-			LastTransitionTime: now,
-			Reason:             "NotReady",
-			Message:            "Something went wrong",
-		}
-
-		SetClaimCondition(status, condition)
-
-		// LastTransitionTime SHOULD change because Status changed
-		if !status.Conditions[0].LastTransitionTime.Equal(&now) {
-			t.Errorf("Expected LastTransitionTime to be updated to %v when Status changes, got %v",
-				now, status.Conditions[0].LastTransitionTime)
-		}
-
-		// All fields should be updated
-		if status.Conditions[0].Status != metav1.ConditionFalse {
-			t.Errorf("Expected Status to be False, got %v", status.Conditions[0].Status)
-		}
-		if status.Conditions[0].Reason != "NotReady" {
-			t.Errorf("Expected Reason to be NotReady, got %v", status.Conditions[0].Reason)
-		}
-		if status.Conditions[0].Message != "Something went wrong" {
-			t.Errorf("Expected Message to be updated, got %v", status.Conditions[0].Message)
-		}
-	})
-}
-
-func TestGetClaimCondition(t *testing.T) {
-	now := metav1.Now()
-
-	t.Run("find existing condition", func(t *testing.T) {
-		status := &agentsv1alpha1.SandboxClaimStatus{
-			Conditions: []metav1.Condition{
-				{
-					Type:               "Ready",
-					Status:             metav1.ConditionTrue,
-					Reason:             "AllReady",
-					Message:            "Everything is ready",
-					LastTransitionTime: now,
-				},
-				{
-					Type:               "Completed",
-					Status:             metav1.ConditionTrue,
-					Reason:             "AllDone",
-					Message:            "All done",
-					LastTransitionTime: now,
-				},
-			},
-		}
-
-		cond := GetClaimCondition(status, "Ready")
-		if cond == nil {
-			t.Error("Expected to find Ready condition, got nil")
-		}
-		if cond != nil && cond.Reason != "AllReady" {
-			t.Errorf("Expected Reason AllReady, got %v", cond.Reason)
-		}
-	})
-
-	t.Run("condition not found", func(t *testing.T) {
-		status := &agentsv1alpha1.SandboxClaimStatus{
-			Conditions: []metav1.Condition{
-				{
-					Type:               "Ready",
-					Status:             metav1.ConditionTrue,
-					Reason:             "AllReady",
-					Message:            "Everything is ready",
-					LastTransitionTime: now,
-				},
-			},
-		}
-
-		cond := GetClaimCondition(status, "NotExist")
-		if cond != nil {
-			t.Errorf("Expected nil for non-existent condition, got %v", cond)
-		}
-	})
-
-	t.Run("empty conditions", func(t *testing.T) {
-		status := &agentsv1alpha1.SandboxClaimStatus{}
-
-		cond := GetClaimCondition(status, "Ready")
-		if cond != nil {
-			t.Errorf("Expected nil for empty conditions, got %v", cond)
-		}
-	})
-}
-
 func TestTransitionFunctions(t *testing.T) {
 	now := metav1.Now()
 	pastTime := metav1.NewTime(now.Add(-10 * time.Second))
@@ -737,6 +754,64 @@ func TestTransitionFunctions(t *testing.T) {
 		}
 	})
 
+	t.Run("transitionToCompletedWithReplicaTimeout", func(t *testing.T) {
+		claim := &agentsv1alpha1.SandboxClaim{
+			Spec: agentsv1alpha1.SandboxClaimSpec{
+				Replicas:  int32Ptr(50),
+				OnTimeout: agentsv1alpha1.SandboxClaimTimeoutReleaseClaimed,
+			},
+		}
+		status := &agentsv1alpha1.SandboxClaimStatus{
+			ClaimedReplicas: 20,
+		}
+
+		result := transitionToCompletedWithReplicaTimeout(status, 5*time.Second, claim)
+
+		if result.Phase != agentsv1alpha1.SandboxClaimPhaseCompleted {
+			t.Errorf("transitionToCompletedWithReplicaTimeout() phase = %v, want Completed", result.Phase)
+		}
+		if !conditions.IsTrue(result.Conditions, string(agentsv1alpha1.SandboxClaimConditionReplicaTimedOut)) {
+			t.Error("ReplicaTimedOut condition should be True")
+		}
+		if conditions.IsTrue(result.Conditions, string(agentsv1alpha1.SandboxClaimConditionTimedOut)) {
+			t.Error("TimedOut condition should not be set by the per-replica timeout")
+		}
+		if !shouldReleaseOnTimeout(claim, result) {
+			t.Error("claimed sandboxes should be released after the per-replica timeout")
+		}
+	})
+
+	t.Run("downgradeToBestEffortWithReplicaTimeout", func(t *testing.T) {
+		claim := &agentsv1alpha1.SandboxClaim{
+			Spec: agentsv1alpha1.SandboxClaimSpec{
+				Replicas:  int32Ptr(50),
+				OnTimeout: agentsv1alpha1.SandboxClaimTimeoutBestEffort,
+			},
+		}
+		status := &agentsv1alpha1.SandboxClaimStatus{
+			Phase:           agentsv1alpha1.SandboxClaimPhaseClaiming,
+			ClaimedReplicas: 20,
+		}
+
+		result := downgradeToBestEffortWithReplicaTimeout(status, 5*time.Second, claim)
+
+		if result.Phase != agentsv1alpha1.SandboxClaimPhaseClaiming {
+			t.Errorf("downgradeToBestEffortWithReplicaTimeout() phase = %v, want Claiming", result.Phase)
+		}
+		if result.CompletionTime != nil {
+			t.Error("CompletionTime should not be set for a best-effort claim")
+		}
+		if !conditions.IsTrue(result.Conditions, string(agentsv1alpha1.SandboxClaimConditionReplicaTimedOut)) {
+			t.Error("ReplicaTimedOut condition should be True")
+		}
+		if !IsDowngradedToBestEffort(result) {
+			t.Error("claim should be downgraded to best-effort")
+		}
+		if shouldReleaseOnTimeout(claim, result) {
+			t.Error("claimed sandboxes of a best-effort claim should not be released")
+		}
+	})
+
 	t.Run("transitionToCompletedWithSuccess", func(t *testing.T) {
 		claim := &agentsv1alpha1.SandboxClaim{
 			Spec: agentsv1alpha1.SandboxClaimSpec{
@@ -772,3 +847,256 @@ func TestTransitionFunctions(t *testing.T) {
 		}
 	})
 }
+
+func TestRecordHistory(t *testing.T) {
+	t.Run("claim story", func(t *testing.T) {
+		claim := &agentsv1alpha1.SandboxClaim{
+			Spec: agentsv1alpha1.SandboxClaimSpec{
+				Replicas:     int32Ptr(3),
+				ClaimTimeout: &metav1.Duration{Duration: time.Minute},
+			},
+		}
+		status := &agentsv1alpha1.SandboxClaimStatus{Phase: agentsv1alpha1.SandboxClaimPhaseClaiming}
+		recordHistory(status, "NoAvailableSandboxes", "No available sandboxes in pool test")
+		// retries on shortage are recorded once
+		recordHistory(status, "NoAvailableSandboxes", "No available sandboxes in pool test")
+		status.ClaimedReplicas = 2
+		recordHistory(status, "SandboxClaimed", "Claimed 2 sandbox(es), total: 2/3")
+		recordHistory(status, "NoAvailableSandboxes", "No available sandboxes in pool test")
+		transitionToCompletedWithTimeout(status, time.Minute, claim)
+
+		var reasons []string
+		for _, entry := range status.History {
+			reasons = append(reasons, entry.Reason)
+		}
+		expected := []string{"NoAvailableSandboxes", "SandboxClaimed", "NoAvailableSandboxes", "TimeoutReached"}
+		if len(reasons) != len(expected) {
+			t.Fatalf("recordHistory() reasons = %v, want %v", reasons, expected)
+		}
+		for i := range expected {
+			if reasons[i] != expected[i] {
+				t.Errorf("recordHistory() reasons = %v, want %v", reasons, expected)
+				break
+			}
+		}
+		last := status.History[len(status.History)-1]
+		if last.Phase != agentsv1alpha1.SandboxClaimPhaseCompleted || last.ClaimedReplicas != 2 {
+			t.Errorf("recordHistory() last entry = %+v, want Completed with 2 claimed replicas", last)
+		}
+		if last.Time.IsZero() {
+			t.Error("recordHistory() entry time should be set")
+		}
+	})
+
+	t.Run("bounded", func(t *testing.T) {
+		status := &agentsv1alpha1.SandboxClaimStatus{Phase: agentsv1alpha1.SandboxClaimPhaseClaiming}
+		for i := int32(1); i <= agentsv1alpha1.SandboxClaimHistoryLimit+5; i++ {
+			status.ClaimedReplicas = i
+			recordHistory(status, "SandboxClaimed", "")
+		}
+		if len(status.History) != agentsv1alpha1.SandboxClaimHistoryLimit {
+			t.Fatalf("recordHistory() kept %d entries, want %d", len(status.History), agentsv1alpha1.SandboxClaimHistoryLimit)
+		}
+		if first := status.History[0].ClaimedReplicas; first != 6 {
+			t.Errorf("recordHistory() oldest entry has %d claimed replicas, want 6", first)
+		}
+	})
+}
+
+func TestLeaseExpireTime(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	completed := metav1.NewTime(now.Add(-10 * time.Minute))
+	lease := &metav1.Duration{Duration: 30 * time.Minute}
+	released := metav1.Condition{
+		Type:   string(agentsv1alpha1.SandboxClaimConditionReleased),
+		Status: metav1.ConditionTrue,
+		Reason: "LeaseExpired",
+	}
+
+	tests := []struct {
+		name       string
+		lease      *metav1.Duration
+		renewTime  string
+		completion *metav1.Time
+		conditions []metav1.Condition
+		expect     *time.Time
+	}{
+		{
+			name:       "no lease",
+			completion: &completed,
+		},
+		{
+			name:  "not completed",
+			lease: lease,
+		},
+		{
+			name:       "from completion",
+			lease:      lease,
+			completion: &completed,
+			expect:     ptrTime(completed.Add(30 * time.Minute)),
+		},
+		{
+			name:       "renewed",
+			lease:      lease,
+			renewTime:  now.Add(-time.Minute).Format(time.RFC3339),
+			completion: &completed,
+			expect:     ptrTime(now.Add(29 * time.Minute)),
+		},
+		{
+			name:       "renewed before completion",
+			lease:      lease,
+			renewTime:  now.Add(-time.Hour).Format(time.RFC3339),
+			completion: &completed,
+			expect:     ptrTime(completed.Add(30 * time.Minute)),
+		},
+		{
+			name:       "renewal in the future ignored until reached",
+			lease:      lease,
+			renewTime:  now.Add(time.Hour).Format(time.RFC3339),
+			completion: &completed,
+			expect:     ptrTime(completed.Add(30 * time.Minute)),
+		},
+		{
+			name:       "invalid renewal ignored",
+			lease:      lease,
+			renewTime:  "yesterday",
+			completion: &completed,
+			expect:     ptrTime(completed.Add(30 * time.Minute)),
+		},
+		{
+			name:       "released",
+			lease:      lease,
+			completion: &completed,
+			conditions: []metav1.Condition{released},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claim := &agentsv1alpha1.SandboxClaim{Spec: agentsv1alpha1.SandboxClaimSpec{LeaseDuration: tt.lease}}
+			if tt.renewTime != "" {
+				claim.Annotations = map[string]string{agentsv1alpha1.AnnotationClaimLeaseRenewTime: tt.renewTime}
+			}
+			status := &agentsv1alpha1.SandboxClaimStatus{CompletionTime: tt.completion, Conditions: tt.conditions}
+			got := leaseExpireTime(claim, status, now)
+			switch {
+			case tt.expect == nil && got != nil:
+				t.Errorf("leaseExpireTime() = %v, want nil", got)
+			case tt.expect != nil && (got == nil || !got.Time.Equal(*tt.expect)):
+				t.Errorf("leaseExpireTime() = %v, want %v", got, *tt.expect)
+			}
+		})
+	}
+}
+
+func ptrTime(t time.Time) *time.Time {
+	return &t
+}
+
+func TestBuildClaimedSandboxes(t *testing.T) {
+	now := metav1.Now()
+	newSandbox := func(name, podIP string, ready bool) *agentsv1alpha1.Sandbox {
+		sbx := &agentsv1alpha1.Sandbox{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", UID: types.UID(name + "-uid")},
+			Status:     agentsv1alpha1.SandboxStatus{Phase: agentsv1alpha1.SandboxRunning, PodInfo: agentsv1alpha1.PodInfo{PodIP: podIP}},
+		}
+		if ready {
+			sbx.Status.Conditions = []metav1.Condition{{Type: string(agentsv1alpha1.SandboxConditionReady), Status: metav1.ConditionTrue}}
+		}
+		return sbx
+	}
+	deleting := newSandbox("sbx-0", "10.0.0.3", true)
+	deleting.DeletionTimestamp = &now
+
+	got := buildClaimedSandboxes([]*agentsv1alpha1.Sandbox{newSandbox("sbx-2", "", false), deleting, newSandbox("sbx-1", "10.0.0.1", true)})
+	want := []agentsv1alpha1.SandboxClaimClaimedSandbox{
+		{Name: "sbx-1", UID: "sbx-1-uid", SandboxID: "default--sbx-1", PodIP: "10.0.0.1", Ready: true},
+		{Name: "sbx-2", UID: "sbx-2-uid", SandboxID: "default--sbx-2"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("buildClaimedSandboxes() = %+v, want %+v", got, want)
+	}
+
+	var many []*agentsv1alpha1.Sandbox
+	for i := range agentsv1alpha1.SandboxClaimClaimedSandboxesLimit + 1 {
+		many = append(many, newSandbox(fmt.Sprintf("sbx-%03d", i), "", false))
+	}
+	got = buildClaimedSandboxes(many)
+	if len(got) != agentsv1alpha1.SandboxClaimClaimedSandboxesLimit {
+		t.Errorf("buildClaimedSandboxes() listed %d sandboxes, want %d", len(got), agentsv1alpha1.SandboxClaimClaimedSandboxesLimit)
+	}
+	if got := buildClaimedSandboxes(nil); got != nil {
+		t.Errorf("buildClaimedSandboxes(nil) = %+v, want nil", got)
+	}
+}
+
+func TestBuildTemplateStatuses(t *testing.T) {
+	now := metav1.Now()
+	newSandbox := func(name, pool string) *agentsv1alpha1.Sandbox {
+		return &agentsv1alpha1.Sandbox{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: map[string]string{agentsv1alpha1.LabelSandboxPool: pool}},
+		}
+	}
+	deleting := newSandbox("sbx-0", "pool-a")
+	deleting.DeletionTimestamp = &now
+
+	got := buildTemplateStatuses([]*agentsv1alpha1.Sandbox{
+		newSandbox("sbx-1", "pool-b"), deleting, newSandbox("sbx-2", "pool-a"), newSandbox("sbx-3", "pool-b"),
+	})
+	want := []agentsv1alpha1.SandboxClaimTemplateStatus{
+		{Name: "pool-a", ClaimedReplicas: 1},
+		{Name: "pool-b", ClaimedReplicas: 2},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("buildTemplateStatuses() = %+v, want %+v", got, want)
+	}
+	if got := buildTemplateStatuses(nil); got != nil {
+		t.Errorf("buildTemplateStatuses(nil) = %+v, want nil", got)
+	}
+}
+
+func TestTransitionToCompletedWithReplicaTimeout_Fail(t *testing.T) {
+	claim := &agentsv1alpha1.SandboxClaim{
+		Spec: agentsv1alpha1.SandboxClaimSpec{Replicas: int32Ptr(2), OnTimeout: agentsv1alpha1.SandboxClaimTimeoutFail},
+	}
+	status := transitionToCompletedWithReplicaTimeout(&agentsv1alpha1.SandboxClaimStatus{ClaimedReplicas: 1}, time.Minute, claim)
+	if status.Phase != agentsv1alpha1.SandboxClaimPhaseFailed {
+		t.Errorf("Phase = %v, want %v", status.Phase, agentsv1alpha1.SandboxClaimPhaseFailed)
+	}
+	cond := conditions.Get(status.Conditions, string(agentsv1alpha1.SandboxClaimConditionCompleted))
+	if cond == nil || cond.Status != metav1.ConditionFalse || cond.Reason != "ReplicaTimeoutReached" {
+		t.Errorf("Completed condition = %v, want False with reason ReplicaTimeoutReached", cond)
+	}
+	if !conditions.IsTrue(status.Conditions, string(agentsv1alpha1.SandboxClaimConditionReplicaTimedOut)) {
+		t.Errorf("ReplicaTimedOut condition should be True")
+	}
+}
+
+func TestSandboxSetNameIndexFunc(t *testing.T) {
+	tests := []struct {
+		name     string
+		claim    *agentsv1alpha1.SandboxClaim
+		expected []string
+	}{
+		{
+			name:     "template name",
+			claim:    &agentsv1alpha1.SandboxClaim{Spec: agentsv1alpha1.SandboxClaimSpec{TemplateName: "pool"}},
+			expected: []string{"pool"},
+		},
+		{
+			name:     "SandboxSet picked by the template selector",
+			claim:    &agentsv1alpha1.SandboxClaim{Status: agentsv1alpha1.SandboxClaimStatus{SandboxSetName: "picked"}},
+			expected: []string{"picked"},
+		},
+		{
+			name:  "no SandboxSet picked yet",
+			claim: &agentsv1alpha1.SandboxClaim{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SandboxSetNameIndexFunc(tt.claim); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("SandboxSetNameIndexFunc() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
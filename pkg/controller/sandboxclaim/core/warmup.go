@@ -0,0 +1,110 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+)
+
+// warmupWindowSize is the number of recent warmup latencies kept per pool
+const warmupWindowSize = 50
+
+// WarmupTracker keeps the recent warmup latencies, from creation to ready, of the sandboxes of each pool.
+type WarmupTracker interface {
+	// Observe records the warmup latency of a sandbox of the pool
+	Observe(pool string, latency time.Duration)
+	// Percentiles returns the p50 and p90 of the recent warmup latencies of the pool, false if none is observed
+	Percentiles(pool string) (p50, p90 time.Duration, ok bool)
+}
+
+func NewWarmupTracker() WarmupTracker {
+	return &realWarmupTracker{pools: make(map[string][]time.Duration)}
+}
+
+type realWarmupTracker struct {
+	sync.Mutex
+	// pools is a sliding window of latencies per pool, oldest first
+	pools map[string][]time.Duration
+}
+
+func (t *realWarmupTracker) Observe(pool string, latency time.Duration) {
+	t.Lock()
+	defer t.Unlock()
+	window := append(t.pools[pool], latency)
+	if len(window) > warmupWindowSize {
+		window = window[len(window)-warmupWindowSize:]
+	}
+	t.pools[pool] = window
+}
+
+func (t *realWarmupTracker) Percentiles(pool string) (time.Duration, time.Duration, bool) {
+	t.Lock()
+	sorted := slices.Clone(t.pools[pool])
+	t.Unlock()
+	if len(sorted) == 0 {
+		return 0, 0, false
+	}
+	slices.Sort(sorted)
+	return percentile(sorted, 50), percentile(sorted, 90), true
+}
+
+// percentile returns the nearest-rank percentile of sorted latencies
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (len(sorted)*p + 99) / 100
+	return sorted[max(rank, 1)-1]
+}
+
+// WarmupPoolKey is the key of the pool of a SandboxSet in the WarmupTracker
+func WarmupPoolKey(namespace, sandboxSet string) string {
+	return namespace + "/" + sandboxSet
+}
+
+// estimateETA estimates when the remaining replicas of a claim become available from the sandboxes being created in
+// the pool. The remaining-th earliest created sandbox, or the latest if fewer are being created, is expected to be
+// ready after the p90 warmup latency. Nil is returned if no sandbox is being created or no latency is observed.
+func estimateETA(tracker WarmupTracker, pool string, creating []*agentsv1alpha1.Sandbox, remaining int32, now time.Time) *agentsv1alpha1.SandboxClaimETA {
+	if len(creating) == 0 || remaining <= 0 {
+		return nil
+	}
+	p50, p90, ok := tracker.Percentiles(pool)
+	if !ok {
+		return nil
+	}
+	created := make([]time.Time, 0, len(creating))
+	for _, sbx := range creating {
+		created = append(created, sbx.CreationTimestamp.Time)
+	}
+	sort.Slice(created, func(i, j int) bool { return created[i].Before(created[j]) })
+	estimated := created[min(int(remaining), len(created))-1].Add(p90)
+	if estimated.Before(now) {
+		// overdue sandboxes are expected to be ready at any moment
+		estimated = now
+	}
+	return &agentsv1alpha1.SandboxClaimETA{
+		EstimatedTime:    metav1.NewTime(estimated.Truncate(time.Second)),
+		CreatingReplicas: int32(len(creating)),
+		WarmupLatencyP50: metav1.Duration{Duration: p50.Round(time.Second)},
+		WarmupLatencyP90: metav1.Duration{Duration: p90.Round(time.Second)},
+	}
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+)
+
+func TestWarmupTracker(t *testing.T) {
+	tracker := NewWarmupTracker()
+	_, _, ok := tracker.Percentiles("default/pool")
+	assert.False(t, ok)
+
+	for i := 1; i <= 10; i++ {
+		tracker.Observe("default/pool", time.Duration(i)*time.Second)
+	}
+	p50, p90, ok := tracker.Percentiles("default/pool")
+	require.True(t, ok)
+	assert.Equal(t, 5*time.Second, p50)
+	assert.Equal(t, 9*time.Second, p90)
+
+	// only the recent latencies are kept
+	for range warmupWindowSize {
+		tracker.Observe("default/pool", time.Minute)
+	}
+	p50, p90, _ = tracker.Percentiles("default/pool")
+	assert.Equal(t, time.Minute, p50)
+	assert.Equal(t, time.Minute, p90)
+	_, _, ok = tracker.Percentiles("other/pool")
+	assert.False(t, ok)
+}
+
+func TestEstimateETA(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	tracker := NewWarmupTracker()
+	for _, latency := range []time.Duration{10 * time.Second, 20 * time.Second, 30 * time.Second} {
+		tracker.Observe("default/pool", latency)
+	}
+	creating := func(ages ...time.Duration) []*agentsv1alpha1.Sandbox {
+		sandboxes := make([]*agentsv1alpha1.Sandbox, 0, len(ages))
+		for _, age := range ages {
+			sandboxes = append(sandboxes, &agentsv1alpha1.Sandbox{
+				ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.NewTime(now.Add(-age))},
+			})
+		}
+		return sandboxes
+	}
+
+	tests := []struct {
+		name      string
+		pool      string
+		creating  []*agentsv1alpha1.Sandbox
+		remaining int32
+		expectETA *time.Time
+	}{
+		{
+			name:      "earliest created sandbox covers one replica",
+			pool:      "default/pool",
+			creating:  creating(5*time.Second, 25*time.Second),
+			remaining: 1,
+			expectETA: ptr.To(now.Add(5 * time.Second)),
+		},
+		{
+			name:      "latest created sandbox covers more replicas than being created",
+			pool:      "default/pool",
+			creating:  creating(25*time.Second, 5*time.Second),
+			remaining: 3,
+			expectETA: ptr.To(now.Add(25 * time.Second)),
+		},
+		{
+			name:      "overdue sandbox is expected now",
+			pool:      "default/pool",
+			creating:  creating(time.Minute),
+			remaining: 1,
+			expectETA: ptr.To(now),
+		},
+		{
+			name:      "no sandbox being created",
+			pool:      "default/pool",
+			remaining: 1,
+		},
+		{
+			name:      "no latency observed",
+			pool:      "default/other",
+			creating:  creating(5 * time.Second),
+			remaining: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			eta := estimateETA(tracker, tt.pool, tt.creating, tt.remaining, now)
+			if tt.expectETA == nil {
+				assert.Nil(t, eta)
+				return
+			}
+			require.NotNil(t, eta)
+			assert.Equal(t, *tt.expectETA, eta.EstimatedTime.Time.UTC())
+			assert.Equal(t, int32(len(tt.creating)), eta.CreatingReplicas)
+			assert.Equal(t, 20*time.Second, eta.WarmupLatencyP50.Duration)
+			assert.Equal(t, 30*time.Second, eta.WarmupLatencyP90.Duration)
+		})
+	}
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sandboxclaim
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+	"github.com/openkruise/agents/pkg/controller/sandboxclaim/core"
+	"github.com/openkruise/agents/pkg/utils/fieldindex"
+	stateutils "github.com/openkruise/agents/pkg/utils/sandboxutils"
+)
+
+// SandboxEventHandler enqueues the owning SandboxClaim when one of its claimed sandboxes dies or is deleted,
+// so that the claimed replicas of the claim reflect the loss right away and claims with replaceOnFailure can bind a
+// replacement. It also observes the warmup latencies of the sandboxes of pools to estimate the ETA of claims.
+type SandboxEventHandler struct {
+	// Reader gets the claims by the claim UID labels of sandboxes with the fieldindex.IndexNameForUID index. The claim
+	// name labels are used if nil.
+	Reader client.Reader
+}
+
+func (e *SandboxEventHandler) Create(context.Context, event.TypedCreateEvent[client.Object], workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+}
+
+func (e *SandboxEventHandler) Update(ctx context.Context, evt event.TypedUpdateEvent[client.Object], w workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+	oldSbx, ok := evt.ObjectOld.(*agentsv1alpha1.Sandbox)
+	if !ok {
+		return
+	}
+	newSbx, ok := evt.ObjectNew.(*agentsv1alpha1.Sandbox)
+	if !ok {
+		return
+	}
+	oldState, _ := stateutils.GetSandboxState(oldSbx)
+	newState, _ := stateutils.GetSandboxState(newSbx)
+	if oldState == agentsv1alpha1.SandboxStateCreating && newState == agentsv1alpha1.SandboxStateAvailable {
+		observeWarmupLatency(newSbx, time.Now())
+	}
+	died := oldState != agentsv1alpha1.SandboxStateDead && newState == agentsv1alpha1.SandboxStateDead
+	// the pod IPs and readiness of the claimed sandboxes are listed in the status of the claim
+	changed := oldSbx.Status.PodInfo.PodIP != newSbx.Status.PodInfo.PodIP ||
+		stateutils.IsSandboxReady(oldSbx) != stateutils.IsSandboxReady(newSbx)
+	if !died && !changed {
+		return
+	}
+	if req, ok := e.getSandboxClaim(ctx, newSbx); ok {
+		w.Add(req)
+	}
+}
+
+// observeWarmupLatency records the latency of a sandbox of a pool from creation to ready.
+func observeWarmupLatency(sbx *agentsv1alpha1.Sandbox, now time.Time) {
+	owner := metav1.GetControllerOfNoCopy(sbx)
+	if owner == nil {
+		return
+	}
+	readyTime := now
+	if cond := meta.FindStatusCondition(sbx.Status.Conditions, string(agentsv1alpha1.SandboxConditionReady)); cond != nil &&
+		!cond.LastTransitionTime.IsZero() {
+		readyTime = cond.LastTransitionTime.Time
+	}
+	if latency := readyTime.Sub(sbx.CreationTimestamp.Time); latency >= 0 {
+		core.WarmupLatencies.Observe(core.WarmupPoolKey(sbx.Namespace, owner.Name), latency)
+	}
+}
+
+func (e *SandboxEventHandler) Delete(ctx context.Context, evt event.TypedDeleteEvent[client.Object], w workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+	if req, ok := e.getSandboxClaim(ctx, evt.Object); ok {
+		w.Add(req)
+	}
+}
+
+func (e *SandboxEventHandler) Generic(context.Context, event.TypedGenericEvent[client.Object], workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+}
+
+// getSandboxClaim returns the request of the SandboxClaim recorded on a claimed sandbox. The claim is looked up by
+// the claim UID label, so that a claim recreated with the same name is not enqueued for the sandboxes of the previous
+// one, and by the claim name label for the sandboxes claimed before the UID label was introduced.
+func (e *SandboxEventHandler) getSandboxClaim(ctx context.Context, obj client.Object) (reconcile.Request, bool) {
+	if obj == nil {
+		return reconcile.Request{}, false
+	}
+	if uid := obj.GetLabels()[agentsv1alpha1.LabelSandboxClaimUID]; uid != "" && e.Reader != nil {
+		claims := &agentsv1alpha1.SandboxClaimList{}
+		err := e.Reader.List(ctx, claims, client.InNamespace(obj.GetNamespace()), client.MatchingFields{fieldindex.IndexNameForUID: uid})
+		if err == nil {
+			if len(claims.Items) == 0 {
+				// the claim is gone
+				return reconcile.Request{}, false
+			}
+			return reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&claims.Items[0])}, true
+		}
+		klog.FromContext(ctx).Error(err, "Failed to get SandboxClaim by uid, fall back to its name", "sandbox", klog.KObj(obj), "uid", uid)
+	}
+	name := obj.GetLabels()[agentsv1alpha1.LabelSandboxClaimName]
+	if name == "" {
+		return reconcile.Request{}, false
+	}
+	return reconcile.Request{
+		NamespacedName: types.NamespacedName{
+			Namespace: obj.GetNamespace(),
+			Name:      name,
+		},
+	}, true
+}
@@ -0,0 +1,221 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sandboxclaim
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+	"github.com/openkruise/agents/pkg/controller/sandboxclaim/core"
+	"github.com/openkruise/agents/pkg/utils/fieldindex"
+)
+
+type fakeQueue struct {
+	workqueue.TypedRateLimitingInterface[reconcile.Request]
+	requests []reconcile.Request
+}
+
+func (f *fakeQueue) Add(item reconcile.Request) {
+	f.requests = append(f.requests, item)
+}
+
+func newClaimedSandbox(claimName string, phase agentsv1alpha1.SandboxPhase) *agentsv1alpha1.Sandbox {
+	sbx := &agentsv1alpha1.Sandbox{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-sandbox",
+			Namespace: "default",
+			Labels:    map[string]string{},
+		},
+		Status: agentsv1alpha1.SandboxStatus{Phase: phase},
+	}
+	if claimName != "" {
+		sbx.Labels[agentsv1alpha1.LabelSandboxClaimName] = claimName
+	}
+	return sbx
+}
+
+func TestSandboxEventHandler_Update(t *testing.T) {
+	tests := []struct {
+		name          string
+		oldSandbox    *agentsv1alpha1.Sandbox
+		newSandbox    *agentsv1alpha1.Sandbox
+		expectEnqueue bool
+	}{
+		{
+			name:          "claimed sandbox becomes dead",
+			oldSandbox:    newClaimedSandbox("test-claim", agentsv1alpha1.SandboxPaused),
+			newSandbox:    newClaimedSandbox("test-claim", agentsv1alpha1.SandboxFailed),
+			expectEnqueue: true,
+		},
+		{
+			name:       "claimed sandbox stays alive",
+			oldSandbox: newClaimedSandbox("test-claim", agentsv1alpha1.SandboxPaused),
+			newSandbox: newClaimedSandbox("test-claim", agentsv1alpha1.SandboxResuming),
+		},
+		{
+			name:       "claimed sandbox already dead",
+			oldSandbox: newClaimedSandbox("test-claim", agentsv1alpha1.SandboxFailed),
+			newSandbox: newClaimedSandbox("test-claim", agentsv1alpha1.SandboxFailed),
+		},
+		{
+			name:       "unclaimed sandbox becomes dead",
+			oldSandbox: newClaimedSandbox("", agentsv1alpha1.SandboxPaused),
+			newSandbox: newClaimedSandbox("", agentsv1alpha1.SandboxFailed),
+		},
+		{
+			name:       "claimed sandbox gets its pod IP",
+			oldSandbox: newClaimedSandbox("test-claim", agentsv1alpha1.SandboxRunning),
+			newSandbox: func() *agentsv1alpha1.Sandbox {
+				sbx := newClaimedSandbox("test-claim", agentsv1alpha1.SandboxRunning)
+				sbx.Status.PodInfo.PodIP = "10.0.0.1"
+				return sbx
+			}(),
+			expectEnqueue: true,
+		},
+		{
+			name:       "claimed sandbox becomes ready",
+			oldSandbox: newClaimedSandbox("test-claim", agentsv1alpha1.SandboxRunning),
+			newSandbox: func() *agentsv1alpha1.Sandbox {
+				sbx := newClaimedSandbox("test-claim", agentsv1alpha1.SandboxRunning)
+				sbx.Status.Conditions = []metav1.Condition{{Type: string(agentsv1alpha1.SandboxConditionReady), Status: metav1.ConditionTrue}}
+				return sbx
+			}(),
+			expectEnqueue: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			queue := &fakeQueue{}
+			handler := &SandboxEventHandler{}
+			handler.Update(context.TODO(), event.UpdateEvent{ObjectOld: tt.oldSandbox, ObjectNew: tt.newSandbox}, queue)
+			if tt.expectEnqueue {
+				assert.Len(t, queue.requests, 1)
+				assert.Equal(t, "test-claim", queue.requests[0].Name)
+				assert.Equal(t, "default", queue.requests[0].Namespace)
+			} else {
+				assert.Empty(t, queue.requests)
+			}
+		})
+	}
+}
+
+func TestSandboxEventHandler_Update_WarmupLatency(t *testing.T) {
+	created := time.Now().Add(-time.Minute)
+	newPoolSandbox := func(ready bool) *agentsv1alpha1.Sandbox {
+		sbs := &agentsv1alpha1.SandboxSet{ObjectMeta: metav1.ObjectMeta{Name: "warmup-pool", UID: "warmup-pool-uid"}}
+		sbx := &agentsv1alpha1.Sandbox{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "pool-sandbox",
+				Namespace:         "default",
+				CreationTimestamp: metav1.NewTime(created),
+				OwnerReferences:   []metav1.OwnerReference{*metav1.NewControllerRef(sbs, agentsv1alpha1.SandboxSetControllerKind)},
+			},
+			Status: agentsv1alpha1.SandboxStatus{Phase: agentsv1alpha1.SandboxRunning},
+		}
+		if ready {
+			sbx.Status.Conditions = []metav1.Condition{{
+				Type:               string(agentsv1alpha1.SandboxConditionReady),
+				Status:             metav1.ConditionTrue,
+				LastTransitionTime: metav1.NewTime(created.Add(15 * time.Second)),
+			}}
+		}
+		return sbx
+	}
+
+	handler := &SandboxEventHandler{}
+	// sandboxes staying creating or available are not observed
+	handler.Update(context.TODO(), event.UpdateEvent{ObjectOld: newPoolSandbox(false), ObjectNew: newPoolSandbox(false)}, &fakeQueue{})
+	handler.Update(context.TODO(), event.UpdateEvent{ObjectOld: newPoolSandbox(true), ObjectNew: newPoolSandbox(true)}, &fakeQueue{})
+	_, _, ok := core.WarmupLatencies.Percentiles(core.WarmupPoolKey("default", "warmup-pool"))
+	assert.False(t, ok)
+
+	handler.Update(context.TODO(), event.UpdateEvent{ObjectOld: newPoolSandbox(false), ObjectNew: newPoolSandbox(true)}, &fakeQueue{})
+	p50, p90, ok := core.WarmupLatencies.Percentiles(core.WarmupPoolKey("default", "warmup-pool"))
+	assert.True(t, ok)
+	assert.Equal(t, 15*time.Second, p50)
+	assert.Equal(t, 15*time.Second, p90)
+}
+
+func TestSandboxEventHandler_Delete(t *testing.T) {
+	queue := &fakeQueue{}
+	handler := &SandboxEventHandler{}
+	handler.Delete(context.TODO(), event.DeleteEvent{Object: newClaimedSandbox("", agentsv1alpha1.SandboxRunning)}, queue)
+	assert.Empty(t, queue.requests)
+	handler.Delete(context.TODO(), event.DeleteEvent{Object: newClaimedSandbox("test-claim", agentsv1alpha1.SandboxRunning)}, queue)
+	assert.Len(t, queue.requests, 1)
+	assert.Equal(t, "test-claim", queue.requests[0].Name)
+}
+
+func TestSandboxEventHandler_Delete_ClaimUID(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = agentsv1alpha1.AddToScheme(scheme)
+	claim := &agentsv1alpha1.SandboxClaim{ObjectMeta: metav1.ObjectMeta{Name: "renamed-claim", Namespace: "default", UID: "claim-uid"}}
+	reader := fake.NewClientBuilder().WithScheme(scheme).WithObjects(claim).
+		WithIndex(&agentsv1alpha1.SandboxClaim{}, fieldindex.IndexNameForUID, fieldindex.UIDIndexFunc).Build()
+	handler := &SandboxEventHandler{Reader: reader}
+
+	tests := []struct {
+		name         string
+		claimName    string
+		claimUID     string
+		expectClaims []string
+	}{
+		{
+			name:         "claim found by uid",
+			claimName:    "test-claim",
+			claimUID:     "claim-uid",
+			expectClaims: []string{"renamed-claim"},
+		},
+		{
+			name:      "claim of uid is gone",
+			claimName: "test-claim",
+			claimUID:  "deleted-claim-uid",
+		},
+		{
+			name:         "sandbox claimed before the uid label",
+			claimName:    "test-claim",
+			expectClaims: []string{"test-claim"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sbx := newClaimedSandbox(tt.claimName, agentsv1alpha1.SandboxRunning)
+			if tt.claimUID != "" {
+				sbx.Labels[agentsv1alpha1.LabelSandboxClaimUID] = tt.claimUID
+			}
+			queue := &fakeQueue{}
+			handler.Delete(context.TODO(), event.DeleteEvent{Object: sbx}, queue)
+			var claims []string
+			for _, req := range queue.requests {
+				assert.Equal(t, "default", req.Namespace)
+				claims = append(claims, req.Name)
+			}
+			assert.Equal(t, tt.expectClaims, claims)
+		})
+	}
+}
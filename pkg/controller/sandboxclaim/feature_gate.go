@@ -0,0 +1,77 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sandboxclaim
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+	"github.com/openkruise/agents/pkg/controller/sandboxclaim/core"
+	"github.com/openkruise/agents/pkg/utils"
+)
+
+// addParkingController parks the unfinished claims while the SandboxClaim feature gate is disabled, so that claims in
+// flight when the gate is turned off report why they stop progressing instead of being abandoned silently. The
+// Reconciler resumes them once the gate is enabled again.
+func addParkingController(mgr manager.Manager) error {
+	r := &parkingReconciler{
+		Reconciler: &Reconciler{
+			Client:   mgr.GetClient(),
+			Scheme:   mgr.GetScheme(),
+			recorder: mgr.GetEventRecorderFor("sandboxclaim"),
+		},
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("sandboxclaim-parking-controller").
+		WithOptions(controller.Options{MaxConcurrentReconciles: 1}).
+		For(&agentsv1alpha1.SandboxClaim{}).
+		Complete(r)
+}
+
+// parkingReconciler sets the FeatureDisabled condition on unfinished claims
+type parkingReconciler struct {
+	*Reconciler
+}
+
+func (r *parkingReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	claim := &agentsv1alpha1.SandboxClaim{}
+	if err := r.Get(ctx, req.NamespacedName, claim); err != nil {
+		return reconcile.Result{}, client.IgnoreNotFound(err)
+	}
+	if !claim.DeletionTimestamp.IsZero() {
+		// the claimed sandboxes are not released without the sandbox cache, so they are kept as before the finalizer
+		_, err := utils.PatchFinalizer(ctx, r.Client, claim, utils.RemoveFinalizerOpType, agentsv1alpha1.SandboxClaimFinalizer)
+		return reconcile.Result{}, err
+	}
+	newStatus := claim.Status.DeepCopy()
+	if !core.ParkClaim(newStatus, time.Now()) {
+		return reconcile.Result{}, nil
+	}
+	logf.FromContext(ctx).Info("Parked SandboxClaim as the SandboxClaim feature gate is disabled",
+		"sandboxclaim", klog.KObj(claim), "phase", newStatus.Phase)
+	r.recorder.Event(claim, "Warning", "FeatureDisabled", newStatus.Message)
+	return reconcile.Result{}, r.updateClaimStatus(ctx, *newStatus, claim)
+}
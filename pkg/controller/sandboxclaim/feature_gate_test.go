@@ -0,0 +1,139 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sandboxclaim
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+	"github.com/openkruise/agents/pkg/controller/sandboxclaim/core"
+	"github.com/openkruise/agents/pkg/utils/conditions"
+)
+
+func TestParkingReconciler_Reconcile(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = agentsv1alpha1.AddToScheme(scheme)
+
+	tests := []struct {
+		name        string
+		phase       agentsv1alpha1.SandboxClaimPhase
+		expectEvent bool
+	}{
+		{name: "claiming claim parked", phase: agentsv1alpha1.SandboxClaimPhaseClaiming, expectEvent: true},
+		{name: "new claim parked", phase: "", expectEvent: true},
+		{name: "completed claim left alone", phase: agentsv1alpha1.SandboxClaimPhaseCompleted},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claim := &agentsv1alpha1.SandboxClaim{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-claim", Namespace: "default"},
+				Spec:       agentsv1alpha1.SandboxClaimSpec{TemplateName: "test-template"},
+				Status:     agentsv1alpha1.SandboxClaimStatus{Phase: tt.phase},
+			}
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(claim).
+				WithStatusSubresource(&agentsv1alpha1.SandboxClaim{}).
+				Build()
+			fakeRecorder := record.NewFakeRecorder(10)
+			r := &parkingReconciler{Reconciler: &Reconciler{Client: fakeClient, Scheme: scheme, recorder: fakeRecorder}}
+
+			_, err := r.Reconcile(context.Background(), reconcile.Request{NamespacedName: client.ObjectKeyFromObject(claim)})
+			require.NoError(t, err)
+
+			updated := &agentsv1alpha1.SandboxClaim{}
+			require.NoError(t, fakeClient.Get(context.Background(), client.ObjectKeyFromObject(claim), updated))
+			assert.Equal(t, tt.phase, updated.Status.Phase)
+			assert.Equal(t, tt.expectEvent, core.IsParked(&updated.Status))
+			assert.Equal(t, tt.expectEvent, len(fakeRecorder.Events) == 1)
+		})
+	}
+}
+
+func TestParkingReconciler_Reconcile_Deleting(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = agentsv1alpha1.AddToScheme(scheme)
+
+	claim := &agentsv1alpha1.SandboxClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "test-claim",
+			Namespace:  "default",
+			Finalizers: []string{agentsv1alpha1.SandboxClaimFinalizer},
+		},
+		Spec: agentsv1alpha1.SandboxClaimSpec{TemplateName: "test-template"},
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(claim).Build()
+	require.NoError(t, fakeClient.Delete(context.Background(), claim))
+	r := &parkingReconciler{Reconciler: &Reconciler{Client: fakeClient, Scheme: scheme, recorder: record.NewFakeRecorder(10)}}
+
+	_, err := r.Reconcile(context.Background(), reconcile.Request{NamespacedName: client.ObjectKeyFromObject(claim)})
+	require.NoError(t, err)
+	err = fakeClient.Get(context.Background(), client.ObjectKeyFromObject(claim), &agentsv1alpha1.SandboxClaim{})
+	assert.True(t, errors.IsNotFound(err), "the finalizer of the deleting claim should be removed")
+}
+
+func TestReconciler_Reconcile_Readopt(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = agentsv1alpha1.AddToScheme(scheme)
+
+	status := agentsv1alpha1.SandboxClaimStatus{Phase: agentsv1alpha1.SandboxClaimPhasePendingApproval}
+	core.ParkClaim(&status, time.Now().Add(-time.Minute))
+	claim := &agentsv1alpha1.SandboxClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-claim", Namespace: "default"},
+		Spec: agentsv1alpha1.SandboxClaimSpec{
+			TemplateName:     "test-template",
+			RequiresApproval: true,
+		},
+		Status: status,
+	}
+	sandboxSet := &agentsv1alpha1.SandboxSet{ObjectMeta: metav1.ObjectMeta{Name: "test-template", Namespace: "default"}}
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(claim, sandboxSet).
+		WithStatusSubresource(&agentsv1alpha1.SandboxClaim{}).
+		Build()
+	fakeRecorder := record.NewFakeRecorder(10)
+	r := &Reconciler{
+		Client:   fakeClient,
+		Scheme:   scheme,
+		controls: core.NewClaimControl(fakeClient, fakeRecorder, nil, nil),
+		recorder: fakeRecorder,
+	}
+
+	_, err := r.Reconcile(context.Background(), reconcile.Request{NamespacedName: client.ObjectKeyFromObject(claim)})
+	require.NoError(t, err)
+
+	updated := &agentsv1alpha1.SandboxClaim{}
+	require.NoError(t, fakeClient.Get(context.Background(), client.ObjectKeyFromObject(claim), updated))
+	assert.Equal(t, agentsv1alpha1.SandboxClaimPhasePendingApproval, updated.Status.Phase)
+	assert.False(t, core.IsParked(&updated.Status))
+	assert.True(t, conditions.IsFalse(updated.Status.Conditions, string(agentsv1alpha1.SandboxClaimConditionFeatureDisabled)))
+	require.NotEmpty(t, fakeRecorder.Events)
+	assert.Contains(t, <-fakeRecorder.Events, "Normal Readopted")
+}
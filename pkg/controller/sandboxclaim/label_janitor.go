@@ -0,0 +1,164 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sandboxclaim
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+)
+
+func init() {
+	flag.DurationVar(&staleLabelGCInterval, "sandboxclaim-stale-label-gc-interval", staleLabelGCInterval,
+		"Interval of removing the claim labels left on pods whose sandboxes are no longer claimed by the claims.")
+}
+
+var staleLabelGCInterval = 5 * time.Minute
+
+// EventStaleClaimLabelsRemoved is recorded on pods whose stale claim labels are removed
+const EventStaleClaimLabelsRemoved = "StaleClaimLabelsRemoved"
+
+// claimPodLabels are the labels set on the pods of claimed sandboxes by every claim
+var claimPodLabels = []string{
+	agentsv1alpha1.LabelSandboxClaimName,
+	agentsv1alpha1.LabelSandboxClaimOrdinal,
+	agentsv1alpha1.LabelSandboxClaimAlias,
+}
+
+// +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch;patch
+
+// staleLabelJanitor periodically removes the claim labels left on pods whose sandboxes are no longer claimed by the
+// claims, e.g. after a sandbox is deleted and recreated with the same name, so that the pods are not counted for the
+// claims anymore. The claim labels of a pod are the ones set by claims on every pod, and those in spec.labels of the
+// claim named by the pod, which are kept if the template of the live sandbox still has them.
+type staleLabelJanitor struct {
+	client.Client
+	recorder record.EventRecorder
+	interval time.Duration
+}
+
+func (j *staleLabelJanitor) Start(ctx context.Context) error {
+	klog.InfoS("Starting stale claim label janitor", "interval", j.interval)
+	wait.UntilWithContext(ctx, j.collect, j.interval)
+	return nil
+}
+
+func (j *staleLabelJanitor) collect(ctx context.Context) {
+	pods := &corev1.PodList{}
+	if err := j.List(ctx, pods, client.HasLabels{agentsv1alpha1.LabelSandboxClaimName}); err != nil {
+		klog.ErrorS(err, "Failed to list pods with claim labels")
+		return
+	}
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if !pod.DeletionTimestamp.IsZero() {
+			continue
+		}
+		stale, reason, err := j.staleClaimLabels(ctx, pod)
+		if err != nil {
+			klog.ErrorS(err, "Failed to check claim labels of pod", "pod", klog.KObj(pod))
+			continue
+		}
+		if len(stale) == 0 {
+			continue
+		}
+		claimName := pod.Labels[agentsv1alpha1.LabelSandboxClaimName]
+		patch := client.MergeFrom(pod.DeepCopy())
+		for _, key := range stale {
+			delete(pod.Labels, key)
+		}
+		if err = j.Patch(ctx, pod, patch); err != nil {
+			klog.ErrorS(err, "Failed to remove stale claim labels of pod", "pod", klog.KObj(pod), "labels", stale)
+			continue
+		}
+		klog.InfoS("Removed stale claim labels of pod", "pod", klog.KObj(pod), "claim", claimName, "labels", stale, "reason", reason)
+		staleClaimLabelsRemovedTotal.WithLabelValues(pod.Namespace).Inc()
+		j.recorder.Eventf(pod, corev1.EventTypeNormal, EventStaleClaimLabelsRemoved,
+			"Removed labels %v of SandboxClaim %s: %s", stale, claimName, reason)
+	}
+}
+
+// staleClaimLabels returns the claim labels of the pod to remove and why, none if its sandbox is still claimed by the
+// claim named by the pod.
+func (j *staleLabelJanitor) staleClaimLabels(ctx context.Context, pod *corev1.Pod) ([]string, string, error) {
+	claimName := pod.Labels[agentsv1alpha1.LabelSandboxClaimName]
+	var reason string
+	var sbx *agentsv1alpha1.Sandbox
+	owner := metav1.GetControllerOfNoCopy(pod)
+	if owner == nil || owner.Kind != "Sandbox" {
+		reason = "the pod is not controlled by a sandbox"
+	} else {
+		sbx = &agentsv1alpha1.Sandbox{}
+		err := j.Get(ctx, client.ObjectKey{Namespace: pod.Namespace, Name: owner.Name}, sbx)
+		switch {
+		case errors.IsNotFound(err):
+			sbx = nil
+			reason = fmt.Sprintf("sandbox %s is not found", owner.Name)
+		case err != nil:
+			return nil, "", err
+		case sbx.UID != owner.UID:
+			reason = fmt.Sprintf("sandbox %s is recreated", owner.Name)
+		case sbx.Labels[agentsv1alpha1.LabelSandboxClaimName] != claimName:
+			reason = fmt.Sprintf("sandbox %s is no longer claimed by the claim", owner.Name)
+		default:
+			return nil, "", nil
+		}
+	}
+
+	candidates := map[string]string{}
+	for _, key := range claimPodLabels {
+		if value, ok := pod.Labels[key]; ok {
+			candidates[key] = value
+		}
+	}
+	claim := &agentsv1alpha1.SandboxClaim{}
+	if err := j.Get(ctx, client.ObjectKey{Namespace: pod.Namespace, Name: claimName}, claim); err == nil {
+		for key, value := range claim.Spec.Labels {
+			if pod.Labels[key] == value {
+				candidates[key] = value
+			}
+		}
+	} else if !errors.IsNotFound(err) {
+		return nil, "", err
+	}
+	var templateLabels map[string]string
+	if sbx != nil && sbx.Spec.Template != nil {
+		templateLabels = sbx.Spec.Template.Labels
+	}
+	stale := make([]string, 0, len(candidates))
+	for key, value := range candidates {
+		// the labels of the live sandbox are kept, except the claim name telling it is stale
+		if key != agentsv1alpha1.LabelSandboxClaimName && templateLabels[key] == value {
+			continue
+		}
+		stale = append(stale, key)
+	}
+	sort.Strings(stale)
+	return stale, reason, nil
+}
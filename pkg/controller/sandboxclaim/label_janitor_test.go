@@ -0,0 +1,154 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sandboxclaim
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+)
+
+func TestStaleLabelJanitor(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+	require.NoError(t, agentsv1alpha1.AddToScheme(scheme))
+
+	newSandbox := func(name, uid, claim string, templateLabels map[string]string) *agentsv1alpha1.Sandbox {
+		sbx := &agentsv1alpha1.Sandbox{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", UID: types.UID(uid)},
+			Spec: agentsv1alpha1.SandboxSpec{EmbeddedSandboxTemplate: agentsv1alpha1.EmbeddedSandboxTemplate{
+				Template: &corev1.PodTemplateSpec{ObjectMeta: metav1.ObjectMeta{Labels: templateLabels}},
+			}},
+		}
+		if claim != "" {
+			sbx.Labels = map[string]string{agentsv1alpha1.LabelSandboxClaimName: claim}
+		}
+		return sbx
+	}
+	claimLabels := map[string]string{
+		agentsv1alpha1.LabelSandboxClaimName: "claim",
+		"team":                               "a",
+		"app":                                "demo",
+	}
+	claim := &agentsv1alpha1.SandboxClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "claim", Namespace: "default"},
+		Spec:       agentsv1alpha1.SandboxClaimSpec{Labels: map[string]string{"team": "a"}},
+	}
+
+	tests := []struct {
+		name         string
+		sandbox      *agentsv1alpha1.Sandbox
+		claim        *agentsv1alpha1.SandboxClaim
+		expectLabels map[string]string
+		expectEvent  bool
+	}{
+		{
+			name:         "sandbox still claimed by the claim",
+			sandbox:      newSandbox("sbx", "uid-1", "claim", claimLabels),
+			claim:        claim,
+			expectLabels: claimLabels,
+		},
+		{
+			name:         "claim deleted but sandbox still claimed by it",
+			sandbox:      newSandbox("sbx", "uid-1", "claim", claimLabels),
+			expectLabels: claimLabels,
+		},
+		{
+			name:         "sandbox not found",
+			claim:        claim,
+			expectLabels: map[string]string{"app": "demo"},
+			expectEvent:  true,
+		},
+		{
+			name:         "sandbox recreated without the claim labels",
+			sandbox:      newSandbox("sbx", "uid-2", "", map[string]string{"app": "demo"}),
+			claim:        claim,
+			expectLabels: map[string]string{"app": "demo"},
+			expectEvent:  true,
+		},
+		{
+			name:         "sandbox claimed by another claim keeps its template labels",
+			sandbox:      newSandbox("sbx", "uid-1", "other", map[string]string{"team": "a", "app": "demo"}),
+			claim:        claim,
+			expectLabels: map[string]string{"team": "a", "app": "demo"},
+			expectEvent:  true,
+		},
+		{
+			name:         "claim deleted and sandbox released",
+			sandbox:      newSandbox("sbx", "uid-1", "", nil),
+			expectLabels: map[string]string{"team": "a", "app": "demo"},
+			expectEvent:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "sbx",
+					Namespace: "default",
+					Labels:    map[string]string{},
+					OwnerReferences: []metav1.OwnerReference{{
+						APIVersion: agentsv1alpha1.GroupVersion.String(),
+						Kind:       "Sandbox",
+						Name:       "sbx",
+						UID:        "uid-1",
+						Controller: ptr.To(true),
+					}},
+				},
+			}
+			for k, v := range claimLabels {
+				pod.Labels[k] = v
+			}
+			objects := []client.Object{pod}
+			if tt.sandbox != nil {
+				objects = append(objects, tt.sandbox)
+			}
+			if tt.claim != nil {
+				objects = append(objects, tt.claim)
+			}
+			recorder := record.NewFakeRecorder(10)
+			janitor := &staleLabelJanitor{
+				Client:   fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build(),
+				recorder: recorder,
+			}
+			janitor.collect(context.Background())
+
+			got := &corev1.Pod{}
+			require.NoError(t, janitor.Get(context.Background(), client.ObjectKeyFromObject(pod), got))
+			assert.Equal(t, tt.expectLabels, got.Labels)
+			if tt.expectEvent {
+				require.Len(t, recorder.Events, 1)
+				assert.Contains(t, <-recorder.Events, EventStaleClaimLabelsRemoved)
+			} else {
+				assert.Empty(t, recorder.Events)
+			}
+		})
+	}
+}
@@ -0,0 +1,196 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sandboxclaim
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+	"github.com/openkruise/agents/pkg/controller/sandboxclaim/core"
+	"github.com/openkruise/agents/pkg/utils/conditions"
+)
+
+var (
+	// sandboxClaimRequeueTotal counts the requeue strategies chosen by the SandboxClaim reconciler.
+	sandboxClaimRequeueTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "sandboxclaim_requeue_total",
+			Help: "Total number of requeue decisions made by the SandboxClaim reconciler, by strategy and reason",
+		},
+		[]string{"strategy", "reason"},
+	)
+	// sandboxSetLookupTotal counts the lookups of the SandboxSets of claims, by result.
+	sandboxSetLookupTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "sandboxclaim_sandboxset_lookup_total",
+			Help: "Total number of SandboxSet lookups made by the SandboxClaim reconciler, by result",
+		},
+		[]string{"result"},
+	)
+	// staleClaimLabelsRemovedTotal counts the pods whose stale claim labels are removed by the janitor.
+	staleClaimLabelsRemovedTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "sandboxclaim_stale_pod_labels_removed_total",
+			Help: "Total number of pods whose claim labels are removed as their sandboxes are no longer claimed by the claims",
+		},
+		[]string{"namespace"},
+	)
+	// claimTimeToFirstSandboxSeconds observes how long claims wait for their first sandbox since claiming started.
+	claimTimeToFirstSandboxSeconds = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "sandboxclaim_time_to_first_sandbox_seconds",
+			Help:    "Time from the start of claiming to the first sandbox claimed by SandboxClaims, by template",
+			Buckets: prometheus.ExponentialBuckets(0.1, 2, 14),
+		},
+		[]string{"namespace", "template"},
+	)
+	// claimTimeToCompletionSeconds observes how long claims take to finish since claiming started, timed out ones
+	// included, by the phase they finished in.
+	claimTimeToCompletionSeconds = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "sandboxclaim_time_to_completion_seconds",
+			Help:    "Time from the start of claiming to the completion of SandboxClaims, by template and phase",
+			Buckets: prometheus.ExponentialBuckets(0.1, 2, 14),
+		},
+		[]string{"namespace", "template", "phase"},
+	)
+	// claimTimeoutsTotal counts the claims timed out as a whole or on a single replica.
+	claimTimeoutsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "sandboxclaim_timeouts_total",
+			Help: "Total number of SandboxClaims timed out, by template and type (claim or replica)",
+		},
+		[]string{"namespace", "template", "type"},
+	)
+)
+
+// Types of the claim timeouts
+const (
+	claimTimeoutTypeClaim   = "claim"
+	claimTimeoutTypeReplica = "replica"
+)
+
+// claimReplicasListTimeout bounds listing the claims from the informer on scraping
+const claimReplicasListTimeout = 5 * time.Second
+
+// Results of the SandboxSet lookups
+const (
+	sandboxSetLookupFound          = "found"
+	sandboxSetLookupNotFound       = "not_found"
+	sandboxSetLookupNotFoundCached = "not_found_cached"
+	sandboxSetLookupError          = "error"
+)
+
+func init() {
+	metrics.Registry.MustRegister(sandboxClaimRequeueTotal, sandboxSetLookupTotal, staleClaimLabelsRemovedTotal,
+		claimTimeToFirstSandboxSeconds, claimTimeToCompletionSeconds, claimTimeoutsTotal)
+}
+
+// recordClaimTransitions records the fulfillment metrics of the transitions of a claim from its last status to the
+// new one patched, so that each transition is observed once.
+func recordClaimTransitions(claim *agentsv1alpha1.SandboxClaim, lastStatus, newStatus *agentsv1alpha1.SandboxClaimStatus) {
+	template := claimTemplate(claim, newStatus)
+	if newStatus.ClaimStartTime != nil {
+		if lastStatus.ClaimedReplicas == 0 && newStatus.ClaimedReplicas > 0 {
+			claimTimeToFirstSandboxSeconds.WithLabelValues(claim.Namespace, template).
+				Observe(time.Since(newStatus.ClaimStartTime.Time).Seconds())
+		}
+		if !core.IsClaimFinished(lastStatus) && core.IsClaimFinished(newStatus) && newStatus.CompletionTime != nil {
+			claimTimeToCompletionSeconds.WithLabelValues(claim.Namespace, template, string(newStatus.Phase)).
+				Observe(newStatus.CompletionTime.Sub(newStatus.ClaimStartTime.Time).Seconds())
+		}
+	}
+	for conditionType, timeoutType := range map[agentsv1alpha1.SandboxClaimConditionType]string{
+		agentsv1alpha1.SandboxClaimConditionTimedOut:        claimTimeoutTypeClaim,
+		agentsv1alpha1.SandboxClaimConditionReplicaTimedOut: claimTimeoutTypeReplica,
+	} {
+		if !conditions.IsTrue(lastStatus.Conditions, string(conditionType)) && conditions.IsTrue(newStatus.Conditions, string(conditionType)) {
+			claimTimeoutsTotal.WithLabelValues(claim.Namespace, template, timeoutType).Inc()
+		}
+	}
+}
+
+// claimTemplate returns the SandboxSet a claim claims from as the template label of its metrics
+func claimTemplate(claim *agentsv1alpha1.SandboxClaim, status *agentsv1alpha1.SandboxClaimStatus) string {
+	if claim.Spec.TemplateName != "" {
+		return claim.Spec.TemplateName
+	}
+	return status.SandboxSetName
+}
+
+// claimReplicasCollector sums the claimed and desired replicas of the claims of each template from the informer on
+// scraping, so that the gauges never keep the claims already deleted.
+type claimReplicasCollector struct {
+	reader  client.Reader
+	claimed *prometheus.Desc
+	desired *prometheus.Desc
+}
+
+func newClaimReplicasCollector(reader client.Reader) *claimReplicasCollector {
+	return &claimReplicasCollector{
+		reader: reader,
+		claimed: prometheus.NewDesc("sandboxclaim_claimed_replicas",
+			"Current number of replicas claimed by the SandboxClaims of the template", []string{"namespace", "template"}, nil),
+		desired: prometheus.NewDesc("sandboxclaim_desired_replicas",
+			"Desired number of replicas of the SandboxClaims of the template", []string{"namespace", "template"}, nil),
+	}
+}
+
+func (c *claimReplicasCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.claimed
+	ch <- c.desired
+}
+
+func (c *claimReplicasCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), claimReplicasListTimeout)
+	defer cancel()
+	claims := &agentsv1alpha1.SandboxClaimList{}
+	if err := c.reader.List(ctx, claims); err != nil {
+		klog.ErrorS(err, "failed to list SandboxClaims for metrics")
+		return
+	}
+	type key struct{ namespace, template string }
+	claimed, desired := map[key]int32{}, map[key]int32{}
+	for i := range claims.Items {
+		claim := &claims.Items[i]
+		// dry-run claims never claim, and deleting ones are releasing their sandboxes
+		if claim.Spec.DryRun || !claim.DeletionTimestamp.IsZero() {
+			continue
+		}
+		k := key{claim.Namespace, claimTemplate(claim, &claim.Status)}
+		claimed[k] += claim.Status.ClaimedReplicas
+		desired[k] += claimDesiredReplicas(claim)
+	}
+	for k, replicas := range desired {
+		ch <- prometheus.MustNewConstMetric(c.claimed, prometheus.GaugeValue, float64(claimed[k]), k.namespace, k.template)
+		ch <- prometheus.MustNewConstMetric(c.desired, prometheus.GaugeValue, float64(replicas), k.namespace, k.template)
+	}
+}
+
+// claimDesiredReplicas returns spec.replicas of the claim, DefaultReplicasCount if unset
+func claimDesiredReplicas(claim *agentsv1alpha1.SandboxClaim) int32 {
+	if claim.Spec.Replicas != nil {
+		return *claim.Spec.Replicas
+	}
+	return core.DefaultReplicasCount
+}
@@ -0,0 +1,186 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sandboxclaim
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+	"github.com/openkruise/agents/pkg/utils/conditions"
+)
+
+// histogramCount returns how many observations the histogram of the labels has
+func histogramCount(t *testing.T, vec *prometheus.HistogramVec, labels ...string) uint64 {
+	metric := &dto.Metric{}
+	require.NoError(t, vec.WithLabelValues(labels...).(prometheus.Metric).Write(metric))
+	return metric.GetHistogram().GetSampleCount()
+}
+
+func TestRecordClaimTransitions(t *testing.T) {
+	start := metav1.NewTime(time.Now().Add(-time.Minute))
+	completion := metav1.NewTime(start.Add(30 * time.Second))
+	timedOut := func(conditionType agentsv1alpha1.SandboxClaimConditionType) []metav1.Condition {
+		var conds []metav1.Condition
+		conditions.NewBuilder(&conds, 0).True(string(conditionType), "Timeout", "timed out")
+		return conds
+	}
+
+	tests := []struct {
+		name               string
+		template           string
+		lastStatus         agentsv1alpha1.SandboxClaimStatus
+		newStatus          agentsv1alpha1.SandboxClaimStatus
+		expectFirstSandbox uint64
+		expectCompletion   uint64
+		expectPhase        string
+		expectTimeouts     map[string]float64
+	}{
+		{
+			name:               "first sandbox claimed",
+			template:           "first",
+			lastStatus:         agentsv1alpha1.SandboxClaimStatus{Phase: agentsv1alpha1.SandboxClaimPhaseClaiming, ClaimStartTime: &start},
+			newStatus:          agentsv1alpha1.SandboxClaimStatus{Phase: agentsv1alpha1.SandboxClaimPhaseClaiming, ClaimStartTime: &start, ClaimedReplicas: 1},
+			expectFirstSandbox: 1,
+		},
+		{
+			name:     "more sandboxes claimed",
+			template: "more",
+			lastStatus: agentsv1alpha1.SandboxClaimStatus{
+				Phase: agentsv1alpha1.SandboxClaimPhaseClaiming, ClaimStartTime: &start, ClaimedReplicas: 1,
+			},
+			newStatus: agentsv1alpha1.SandboxClaimStatus{
+				Phase: agentsv1alpha1.SandboxClaimPhaseClaiming, ClaimStartTime: &start, ClaimedReplicas: 2,
+			},
+		},
+		{
+			name:     "claim completed",
+			template: "completed",
+			lastStatus: agentsv1alpha1.SandboxClaimStatus{
+				Phase: agentsv1alpha1.SandboxClaimPhaseClaiming, ClaimStartTime: &start, ClaimedReplicas: 1,
+			},
+			newStatus: agentsv1alpha1.SandboxClaimStatus{
+				Phase: agentsv1alpha1.SandboxClaimPhaseCompleted, ClaimStartTime: &start, ClaimedReplicas: 2, CompletionTime: &completion,
+			},
+			expectCompletion: 1,
+			expectPhase:      string(agentsv1alpha1.SandboxClaimPhaseCompleted),
+		},
+		{
+			name:     "claim timed out and failed",
+			template: "failed",
+			lastStatus: agentsv1alpha1.SandboxClaimStatus{
+				Phase: agentsv1alpha1.SandboxClaimPhaseClaiming, ClaimStartTime: &start,
+			},
+			newStatus: agentsv1alpha1.SandboxClaimStatus{
+				Phase: agentsv1alpha1.SandboxClaimPhaseFailed, ClaimStartTime: &start, CompletionTime: &completion,
+				Conditions: timedOut(agentsv1alpha1.SandboxClaimConditionTimedOut),
+			},
+			expectCompletion: 1,
+			expectPhase:      string(agentsv1alpha1.SandboxClaimPhaseFailed),
+			expectTimeouts:   map[string]float64{claimTimeoutTypeClaim: 1},
+		},
+		{
+			name:     "replica timed out",
+			template: "replica",
+			lastStatus: agentsv1alpha1.SandboxClaimStatus{
+				Phase: agentsv1alpha1.SandboxClaimPhaseClaiming, ClaimStartTime: &start,
+			},
+			newStatus: agentsv1alpha1.SandboxClaimStatus{
+				Phase: agentsv1alpha1.SandboxClaimPhaseCompleted, ClaimStartTime: &start, CompletionTime: &completion,
+				Conditions: timedOut(agentsv1alpha1.SandboxClaimConditionReplicaTimedOut),
+			},
+			expectCompletion: 1,
+			expectPhase:      string(agentsv1alpha1.SandboxClaimPhaseCompleted),
+			expectTimeouts:   map[string]float64{claimTimeoutTypeReplica: 1},
+		},
+		{
+			name:     "completed claim is observed once",
+			template: "once",
+			lastStatus: agentsv1alpha1.SandboxClaimStatus{
+				Phase: agentsv1alpha1.SandboxClaimPhaseCompleted, ClaimStartTime: &start, CompletionTime: &completion,
+				Conditions: timedOut(agentsv1alpha1.SandboxClaimConditionTimedOut),
+			},
+			newStatus: agentsv1alpha1.SandboxClaimStatus{
+				Phase: agentsv1alpha1.SandboxClaimPhaseCompleted, ClaimStartTime: &start, CompletionTime: &completion,
+				Conditions: timedOut(agentsv1alpha1.SandboxClaimConditionTimedOut),
+			},
+			expectPhase: string(agentsv1alpha1.SandboxClaimPhaseCompleted),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claim := &agentsv1alpha1.SandboxClaim{
+				ObjectMeta: metav1.ObjectMeta{Name: "claim", Namespace: "metrics"},
+				Spec:       agentsv1alpha1.SandboxClaimSpec{TemplateName: tt.template},
+			}
+			recordClaimTransitions(claim, &tt.lastStatus, &tt.newStatus)
+
+			assert.Equal(t, tt.expectFirstSandbox, histogramCount(t, claimTimeToFirstSandboxSeconds, "metrics", tt.template))
+			if tt.expectPhase != "" {
+				assert.Equal(t, tt.expectCompletion, histogramCount(t, claimTimeToCompletionSeconds, "metrics", tt.template, tt.expectPhase))
+			}
+			for _, timeoutType := range []string{claimTimeoutTypeClaim, claimTimeoutTypeReplica} {
+				assert.Equal(t, tt.expectTimeouts[timeoutType],
+					testutil.ToFloat64(claimTimeoutsTotal.WithLabelValues("metrics", tt.template, timeoutType)), timeoutType)
+			}
+		})
+	}
+}
+
+func TestClaimReplicasCollector(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = agentsv1alpha1.AddToScheme(scheme)
+	newClaim := func(name, template string, replicas *int32, claimed int32) *agentsv1alpha1.SandboxClaim {
+		return &agentsv1alpha1.SandboxClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec:       agentsv1alpha1.SandboxClaimSpec{TemplateName: template, Replicas: replicas},
+			Status:     agentsv1alpha1.SandboxClaimStatus{ClaimedReplicas: claimed},
+		}
+	}
+	dryRun := newClaim("dry-run", "pool-a", int32Ptr(5), 0)
+	dryRun.Spec.DryRun = true
+	selected := newClaim("selected", "", int32Ptr(2), 2)
+	selected.Status.SandboxSetName = "pool-b"
+	reader := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		newClaim("a-1", "pool-a", int32Ptr(3), 1),
+		newClaim("a-2", "pool-a", nil, 1),
+		dryRun,
+		selected,
+	).Build()
+
+	expected := `
+# HELP sandboxclaim_claimed_replicas Current number of replicas claimed by the SandboxClaims of the template
+# TYPE sandboxclaim_claimed_replicas gauge
+sandboxclaim_claimed_replicas{namespace="default",template="pool-a"} 2
+sandboxclaim_claimed_replicas{namespace="default",template="pool-b"} 2
+# HELP sandboxclaim_desired_replicas Desired number of replicas of the SandboxClaims of the template
+# TYPE sandboxclaim_desired_replicas gauge
+sandboxclaim_desired_replicas{namespace="default",template="pool-a"} 4
+sandboxclaim_desired_replicas{namespace="default",template="pool-b"} 2
+`
+	assert.NoError(t, testutil.CollectAndCompare(newClaimReplicasCollector(reader), strings.NewReader(expected)))
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sandboxclaim
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+	"github.com/openkruise/agents/pkg/controller/sandboxclaim/core"
+	"github.com/openkruise/agents/pkg/utils/controllermetrics"
+	"github.com/openkruise/agents/pkg/utils/priorityqueue"
+	"github.com/openkruise/agents/pkg/utils/sandboxutils"
+)
+
+// urgentClaimTimeoutFraction is the fraction of the claimTimeout left under which a claiming claim is urgent
+const urgentClaimTimeoutFraction = 4
+
+// newPriorityQueue returns the NewQueue option of the controller prioritizing claims with claimPriority.
+func newPriorityQueue(reader client.Reader) func(string, workqueue.TypedRateLimiter[reconcile.Request]) workqueue.TypedRateLimitingInterface[reconcile.Request] {
+	return func(controllerName string, rateLimiter workqueue.TypedRateLimiter[reconcile.Request]) workqueue.TypedRateLimitingInterface[reconcile.Request] {
+		queue := priorityqueue.New(controllerName, rateLimiter, func(req reconcile.Request) int {
+			return lookupClaimPriority(reader, req, time.Now())
+		})
+		return controllermetrics.InstrumentQueue(controllerName, queue)
+	}
+}
+
+// lookupClaimPriority reads the claim and its SandboxSet from the informer cache, claims not found are of the normal
+// priority and are handled as soon as possible anyway.
+func lookupClaimPriority(reader client.Reader, req reconcile.Request, now time.Time) int {
+	ctx := context.Background()
+	claim := &agentsv1alpha1.SandboxClaim{}
+	if err := reader.Get(ctx, req.NamespacedName, claim); err != nil {
+		return priorityqueue.PriorityNormal
+	}
+	sandboxSet := &agentsv1alpha1.SandboxSet{}
+	if err := reader.Get(ctx, client.ObjectKey{Namespace: claim.Namespace, Name: sandboxutils.ClaimSandboxSetName(claim)}, sandboxSet); err != nil {
+		sandboxSet = nil
+	}
+	return claimPriority(claim, sandboxSet, now)
+}
+
+// claimPriority returns the priority of reconciling a claim:
+//   - urgent for claiming claims with less than a quarter of their claimTimeout left, so that they are not timed out
+//     by the queue wait, unless they are downgraded to best-effort already
+//   - high for claims of the Interactive workload class, set on the claim or its pool
+//   - low for claims of the Batch workload class
+//   - normal for the others
+func claimPriority(claim *agentsv1alpha1.SandboxClaim, sandboxSet *agentsv1alpha1.SandboxSet, now time.Time) int {
+	status := claim.Status
+	if status.Phase == agentsv1alpha1.SandboxClaimPhaseClaiming && claim.Spec.ClaimTimeout != nil && status.ClaimStartTime != nil &&
+		!core.IsDowngradedToBestEffort(&status) {
+		timeout := claim.Spec.ClaimTimeout.Duration
+		remaining := status.ClaimStartTime.Add(timeout).Sub(now)
+		if remaining <= timeout/urgentClaimTimeoutFraction {
+			return priorityqueue.PriorityUrgent
+		}
+	}
+	switch core.ClaimWorkloadClass(claim, sandboxSet) {
+	case agentsv1alpha1.WorkloadClassInteractive:
+		return priorityqueue.PriorityHigh
+	case agentsv1alpha1.WorkloadClassBatch:
+		return priorityqueue.PriorityLow
+	default:
+		return priorityqueue.PriorityNormal
+	}
+}
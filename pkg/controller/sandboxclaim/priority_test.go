@@ -0,0 +1,105 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sandboxclaim
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+	"github.com/openkruise/agents/pkg/utils/priorityqueue"
+)
+
+func TestLookupClaimPriority(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = agentsv1alpha1.AddToScheme(scheme)
+	now := time.Now()
+
+	newSandboxSet := func(name, class string) *agentsv1alpha1.SandboxSet {
+		sbs := &agentsv1alpha1.SandboxSet{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"}}
+		if class != "" {
+			sbs.Annotations = map[string]string{agentsv1alpha1.AnnotationWorkloadClass: class}
+		}
+		return sbs
+	}
+	newClaim := func(name, template string, timeout time.Duration, elapsed time.Duration) *agentsv1alpha1.SandboxClaim {
+		claim := &agentsv1alpha1.SandboxClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec:       agentsv1alpha1.SandboxClaimSpec{TemplateName: template},
+			Status:     agentsv1alpha1.SandboxClaimStatus{Phase: agentsv1alpha1.SandboxClaimPhaseClaiming},
+		}
+		if timeout > 0 {
+			claim.Spec.ClaimTimeout = &metav1.Duration{Duration: timeout}
+			claim.Status.ClaimStartTime = &metav1.Time{Time: now.Add(-elapsed)}
+		}
+		return claim
+	}
+	escalated := newClaim("batch-escalated", "batch", 0, 0)
+	escalated.Annotations = map[string]string{agentsv1alpha1.AnnotationWorkloadClass: agentsv1alpha1.WorkloadClassInteractive}
+	completed := newClaim("completed", "batch", time.Minute, 2*time.Minute)
+	completed.Status.Phase = agentsv1alpha1.SandboxClaimPhaseCompleted
+	bestEffort := newClaim("batch-best-effort", "batch", time.Minute, 2*time.Minute)
+	bestEffort.Status.Conditions = []metav1.Condition{{
+		Type:   string(agentsv1alpha1.SandboxClaimConditionTimeoutHandled),
+		Status: metav1.ConditionTrue,
+		Reason: string(agentsv1alpha1.SandboxClaimTimeoutBestEffort),
+	}}
+
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		newSandboxSet("interactive", agentsv1alpha1.WorkloadClassInteractive),
+		newSandboxSet("batch", agentsv1alpha1.WorkloadClassBatch),
+		newSandboxSet("default", ""),
+		newClaim("interactive", "interactive", 0, 0),
+		newClaim("batch", "batch", time.Minute, 10*time.Second),
+		newClaim("batch-nearing-timeout", "batch", time.Minute, 50*time.Second),
+		newClaim("batch-timed-out", "batch", time.Minute, 2*time.Minute),
+		newClaim("default", "default", 0, 0),
+		newClaim("no-pool", "not-found", 0, 0),
+		completed,
+		escalated,
+		bestEffort,
+	).Build()
+
+	tests := []struct {
+		claim    string
+		expected int
+	}{
+		{claim: "interactive", expected: priorityqueue.PriorityHigh},
+		{claim: "batch", expected: priorityqueue.PriorityLow},
+		{claim: "batch-nearing-timeout", expected: priorityqueue.PriorityUrgent},
+		{claim: "batch-timed-out", expected: priorityqueue.PriorityUrgent},
+		{claim: "batch-best-effort", expected: priorityqueue.PriorityLow},
+		{claim: "completed", expected: priorityqueue.PriorityLow},
+		{claim: "batch-escalated", expected: priorityqueue.PriorityHigh},
+		{claim: "default", expected: priorityqueue.PriorityNormal},
+		{claim: "no-pool", expected: priorityqueue.PriorityNormal},
+		{claim: "not-found", expected: priorityqueue.PriorityNormal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.claim, func(t *testing.T) {
+			req := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: tt.claim}}
+			assert.Equal(t, tt.expected, lookupClaimPriority(fakeClient, req, now))
+		})
+	}
+}
@@ -18,29 +18,31 @@ package sandboxclaim
 
 import (
 	"context"
-	"encoding/json"
 	"flag"
 	"fmt"
-	"reflect"
+	"time"
 
-	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
-	"k8s.io/apimachinery/pkg/types"
+	toolscache "k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/klog/v2"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+	"github.com/openkruise/agents/pkg/controller/sandboxclaim/archive"
 	"github.com/openkruise/agents/pkg/controller/sandboxclaim/core"
 	"github.com/openkruise/agents/pkg/discovery"
 	"github.com/openkruise/agents/pkg/features"
@@ -48,27 +50,62 @@ import (
 	managerconfig "github.com/openkruise/agents/pkg/sandbox-manager/config"
 	"github.com/openkruise/agents/pkg/sandbox-manager/infra/sandboxcr"
 	"github.com/openkruise/agents/pkg/utils"
+	"github.com/openkruise/agents/pkg/utils/controllermetrics"
+	"github.com/openkruise/agents/pkg/utils/eventrecorder"
 	"github.com/openkruise/agents/pkg/utils/expectations"
 	utilfeature "github.com/openkruise/agents/pkg/utils/feature"
+	"github.com/openkruise/agents/pkg/utils/fieldindex"
+	"github.com/openkruise/agents/pkg/utils/objectsize"
+	"github.com/openkruise/agents/pkg/utils/requeue"
+	"github.com/openkruise/agents/pkg/utils/statusupdater"
 	"github.com/openkruise/agents/pkg/utils/webhookutils"
+	"github.com/openkruise/agents/pkg/webhook/standalone"
 )
 
 func init() {
 	flag.IntVar(&concurrentReconciles, "sandboxclaim-workers", concurrentReconciles, "Max concurrent workers for SandboxClaim controller.")
 	flag.IntVar(&maxClaimBatchSize, "sandboxclaim-max-batch-size", maxClaimBatchSize, "Maximum batch size for claiming sandboxes in a single reconcile cycle")
+	flag.DurationVar(&retryInitialBackoff, "sandboxclaim-retry-initial-backoff", retryInitialBackoff,
+		"Delay of the first retry of SandboxClaims waiting for sandboxes without spec.retryPolicy, doubling on every consecutive retry.")
+	flag.DurationVar(&retryMaxBackoff, "sandboxclaim-retry-max-backoff", retryMaxBackoff,
+		"Max delay of the retries of SandboxClaims waiting for sandboxes without spec.retryPolicy, 0 retries them at a fixed interval.")
+	flag.IntVar(&retryJitterPercent, "sandboxclaim-retry-jitter-percent", retryJitterPercent,
+		"Percentage of the delay of the retries of SandboxClaims without spec.retryPolicy by which they are randomly extended.")
+	flag.DurationVar(&notificationTimeout, "sandboxclaim-notification-timeout", notificationTimeout,
+		"Timeout of the callbacks of spec.notifications of finished SandboxClaims.")
+	flag.BoolVar(&core.NotificationAllowHTTP, "sandboxclaim-notification-allow-http", core.NotificationAllowHTTP,
+		"Allow the callbacks of spec.notifications of SandboxClaims to plain http endpoints, which expose them in transit.")
 }
 
 var (
 	concurrentReconciles = 500
 	maxClaimBatchSize    = 10
+	retryInitialBackoff  = core.ClaimRetryInterval
+	retryMaxBackoff      = core.DefaultMaxRetryBackoff
+	retryJitterPercent   = 20
+	notificationTimeout  = core.DefaultNotificationTimeout
 	controllerKind       = agentsv1alpha1.GroupVersion.WithKind("SandboxClaim")
+	// claimStatusUpdater patches the status with the fields cleared by transitions removed, e.g. the completionTime
+	// of a reopened claim
+	claimStatusUpdater = statusupdater.New()
 )
 
 func Add(mgr manager.Manager) error {
-	if !utilfeature.DefaultFeatureGate.Enabled(features.SandboxClaimGate) || !discovery.DiscoverGVK(controllerKind) {
+	if !discovery.DiscoverGVK(controllerKind) {
 		return nil
 	}
+	if !utilfeature.DefaultFeatureGate.Enabled(features.SandboxClaimGate) {
+		return addParkingController(mgr)
+	}
 
+	archiver, err := archive.NewSink(archive.DefaultOptions)
+	if err != nil {
+		return fmt.Errorf("failed to create archive sink: %w", err)
+	}
+	core.ClaimArchiver = archiver
+	core.ClaimNotifier = core.NewNotifier(notificationTimeout)
+	core.SandboxQuotaEnabled = discovery.DiscoverGVK(agentsv1alpha1.SandboxQuotaControllerKind)
+	core.DefaultRetryPolicy = defaultRetryPolicy()
 	clientSet, err := clients.NewClientSetWithConfig(mgr.GetConfig())
 	if err != nil {
 		return fmt.Errorf("failed to create manager client set: %w", err)
@@ -95,34 +132,95 @@ func Add(mgr manager.Manager) error {
 		return fmt.Errorf("failed to add cache runnable: %w", err)
 	}
 
-	recorder := mgr.GetEventRecorderFor("sandboxclaim")
+	sandboxSets := newSandboxSetGetter(cache.GetSandboxSetByKey, sandboxSetNotFoundTTL)
+	cache.AddSandboxSetEventHandler(toolscache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			if sbs, ok := obj.(*agentsv1alpha1.SandboxSet); ok {
+				sandboxSets.Forget(client.ObjectKeyFromObject(sbs))
+			}
+		},
+	})
+
+	// the claims of sandboxes are got by their claim uid labels on sandbox events
+	if err = mgr.GetFieldIndexer().IndexField(context.TODO(), &agentsv1alpha1.SandboxClaim{}, fieldindex.IndexNameForUID,
+		fieldindex.UIDIndexFunc); err != nil {
+		return fmt.Errorf("failed to index SandboxClaims by uid: %w", err)
+	}
+
+	// the claims competing for the sandboxes of a SandboxSet are listed by its name while claiming
+	if err = mgr.GetFieldIndexer().IndexField(context.TODO(), &agentsv1alpha1.SandboxClaim{}, fieldindex.IndexNameForSandboxSetName,
+		core.SandboxSetNameIndexFunc); err != nil {
+		return fmt.Errorf("failed to index SandboxClaims by SandboxSet name: %w", err)
+	}
+
+	// the claimed and desired replicas of the templates are summed up from the informer on scraping
+	if err = metrics.Registry.Register(newClaimReplicasCollector(mgr.GetCache())); err != nil {
+		return fmt.Errorf("failed to register SandboxClaim replicas metrics: %w", err)
+	}
+
+	// Progress events repeated on every reconcile while claiming are aggregated to keep describe output readable
+	recorder := eventrecorder.NewAggregating(mgr.GetEventRecorderFor("sandboxclaim"), eventrecorder.DefaultAggregationWindow)
 	err = (&Reconciler{
-		Client:   mgr.GetClient(),
-		Scheme:   mgr.GetScheme(),
-		recorder: recorder,
-		controls: core.NewClaimControl(mgr.GetClient(), recorder, clientSet, cache),
+		Client:      mgr.GetClient(),
+		Scheme:      mgr.GetScheme(),
+		recorder:    recorder,
+		controls:    core.NewClaimControl(mgr.GetClient(), recorder, clientSet, cache),
+		sandboxSets: sandboxSets,
+		sandboxes:   cache.SandboxInformer(),
 	}).SetupWithManager(mgr)
 	if err != nil {
 		return err
 	}
+	err = mgr.Add(&staleLabelJanitor{
+		Client:   mgr.GetClient(),
+		recorder: mgr.GetEventRecorderFor("sandboxclaim"),
+		interval: staleLabelGCInterval,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to add stale claim label janitor: %w", err)
+	}
 	klog.Infof("start SandboxClaimReconciler success")
 	return nil
 }
 
+// defaultRetryPolicy returns the retry policy of the claims without spec.retryPolicy configured by the flags, so that
+// the claims of exhausted pools do not retry every ClaimRetryInterval on large clusters
+func defaultRetryPolicy() *agentsv1alpha1.SandboxClaimRetryPolicy {
+	if retryMaxBackoff <= 0 {
+		return nil
+	}
+	return &agentsv1alpha1.SandboxClaimRetryPolicy{
+		InitialBackoff: &metav1.Duration{Duration: min(max(retryInitialBackoff, time.Millisecond), retryMaxBackoff)},
+		MaxBackoff:     &metav1.Duration{Duration: retryMaxBackoff},
+		JitterPercent:  int32(min(max(retryJitterPercent, 0), 100)),
+	}
+}
+
 // Reconciler reconciles a SandboxClaim object
 type Reconciler struct {
 	client.Client
 	Scheme   *runtime.Scheme
 	controls map[string]core.ClaimControl
 	recorder record.EventRecorder
+	// sandboxSets gets the SandboxSets of claims from the informer, read from the client if nil
+	sandboxSets *sandboxSetGetter
+	// sandboxes is the informer of the cache the claimed sandboxes are counted from. Its events are watched instead of
+	// the ones of the manager cache, so that a claim reconciled on the deletion of its sandbox never counts it.
+	sandboxes toolscache.SharedIndexInformer
 }
 
 // +kubebuilder:rbac:groups=agents.kruise.io,resources=sandboxclaims,verbs=get;list;watch;patch;delete
 // +kubebuilder:rbac:groups=agents.kruise.io,resources=sandboxclaims/status,verbs=get;update;patch
-// +kubebuilder:rbac:groups=agents.kruise.io,resources=sandboxes,verbs=get;list;update;patch
-// +kubebuilder:rbac:groups=agents.kruise.io,resources=sandboxsets,verbs=get
+// +kubebuilder:rbac:groups=agents.kruise.io,resources=sandboxclaims/finalizers,verbs=update
+// +kubebuilder:rbac:groups=agents.kruise.io,resources=sandboxes,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=agents.kruise.io,resources=sandboxsets,verbs=get;list;watch
+// +kubebuilder:rbac:groups=agents.kruise.io,resources=sandboxquotas,verbs=get;list;watch
+// +kubebuilder:rbac:groups=agents.kruise.io,resources=sandboxtemplates,verbs=get;list;watch
+// +kubebuilder:rbac:groups=agents.kruise.io,resources=checkpoints,verbs=get;list;watch
 // +kubebuilder:rbac:groups=core,resources=events,verbs=create;update;patch
+// +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;create;update
 // +kubebuilder:rbac:groups=core,resources=persistentvolumes,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=secrets;configmaps,verbs=get;list;watch,namespace=sandbox-system
 
 func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	// Fetch the SandboxClaim instance
@@ -134,6 +232,16 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 	logger := logf.FromContext(ctx).WithValues("sandboxclaim", klog.KObj(claim))
 	logger.Info("Began to process SandboxClaim for reconcile")
 
+	// Release the claimed sandboxes before the deleting claim is gone
+	if !claim.DeletionTimestamp.IsZero() {
+		return reconcile.Result{}, r.finalizeClaim(ctx, claim)
+	}
+	finalized, err := utils.PatchFinalizer(ctx, r.Client, claim, utils.AddFinalizerOpType, agentsv1alpha1.SandboxClaimFinalizer)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	claim = finalized.(*agentsv1alpha1.SandboxClaim)
+
 	// Check resourceVersion expectations
 	core.ResourceVersionExpectations.Observe(claim)
 	if isSatisfied, unsatisfiedDuration := core.ResourceVersionExpectations.IsSatisfied(claim); !isSatisfied {
@@ -148,18 +256,32 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 	// Initialize new status
 	newStatus := claim.Status.DeepCopy()
 
-	// Fetch SandboxSet
-	sandboxSet := &agentsv1alpha1.SandboxSet{}
-	sandboxSetKey := client.ObjectKey{Namespace: claim.Namespace, Name: claim.Spec.TemplateName}
-	if err := r.Get(ctx, sandboxSetKey, sandboxSet); err != nil {
-		if errors.IsNotFound(err) {
-			logger.Info("SandboxSet not found, marking claim as completed")
-			core.TransitionToCompleted(newStatus, "SandboxSetNotFound",
-				fmt.Sprintf("SandboxSet %s not found", claim.Spec.TemplateName))
-			return ctrl.Result{}, r.updateClaimStatus(ctx, *newStatus, claim)
-		}
+	// Resume the claim parked while the SandboxClaim feature gate was disabled
+	if parked, ok := core.ReadoptClaim(newStatus, time.Now()); ok {
+		logger.Info("Readopted SandboxClaim parked while the SandboxClaim feature gate was disabled", "parked", parked)
+		r.recorder.Event(claim, "Normal", "Readopted", newStatus.Message)
+	}
+
+	// Fetch SandboxSet, which is shared with the informer and must not be modified
+	sandboxSet, notFound, err := r.resolveSandboxSet(ctx, claim, newStatus)
+	if err != nil {
 		return reconcile.Result{}, err
 	}
+	if sandboxSet == nil {
+		logger.Info("SandboxSet not found, marking claim as completed", "reason", notFound)
+		newStatus.ObservedGeneration = claim.Generation
+		if !core.IsClaimFinished(newStatus) {
+			core.TransitionToCompleted(newStatus, "SandboxSetNotFound", notFound)
+			core.SetProgressConditions(claim, newStatus)
+		}
+		return ctrl.Result{}, r.updateClaimStatus(ctx, *newStatus, claim)
+	}
+
+	if standalone.DefaultOptions.Enabled {
+		if done, err := r.defaultAndValidate(ctx, claim, sandboxSet, newStatus); done || err != nil {
+			return reconcile.Result{}, err
+		}
+	}
 
 	// Construct args
 	args := core.ClaimArgs{
@@ -176,14 +298,24 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 
 	// Execute business logic and get requeue strategy
 	var strategy core.RequeueStrategy
-	var err error
 
 	// State-driven execution - each Ensure method returns its own requeue strategy
 	switch newStatus.Phase {
+	case agentsv1alpha1.SandboxClaimPhasePendingApproval:
+		// Wait for the approval annotation to be set
+		strategy = requeue.NoRequeue().WithReason("PendingApproval")
+
+	case agentsv1alpha1.SandboxClaimPhaseQueued:
+		strategy, err = r.getControl().EnsureClaimQueued(ctx, args)
+
 	case agentsv1alpha1.SandboxClaimPhaseClaiming:
-		strategy, err = r.getControl().EnsureClaimClaiming(ctx, args)
+		if claim.Spec.DryRun {
+			strategy, err = r.getControl().EnsureClaimDryRun(ctx, args)
+		} else {
+			strategy, err = r.getControl().EnsureClaimClaiming(ctx, args)
+		}
 
-	case agentsv1alpha1.SandboxClaimPhaseCompleted:
+	case agentsv1alpha1.SandboxClaimPhaseCompleted, agentsv1alpha1.SandboxClaimPhaseFailed:
 		strategy, err = r.getControl().EnsureClaimCompleted(ctx, args)
 
 	default:
@@ -199,6 +331,11 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		return reconcile.Result{}, err
 	}
 
+	// Back off the retries of a claim waiting for sandboxes with its retry policy
+	strategy = core.ApplyRetryPolicy(claim, newStatus, strategy, time.Now())
+	// The business logic may have claimed sandboxes or completed the claim since the status was calculated
+	core.SetProgressConditions(claim, newStatus)
+
 	// Update status after successful execution
 	// If update fails, return error to trigger retry (but lose calculated strategy)
 	if err := r.updateClaimStatus(ctx, *newStatus, claim); err != nil {
@@ -207,16 +344,38 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 	}
 
 	// Convert RequeueStrategy to ctrl.Result
-	if strategy.Immediate {
-		logger.V(1).Info("Immediate requeue requested")
-		return ctrl.Result{Requeue: true}, nil
+	strategy.Record(sandboxClaimRequeueTotal)
+	if strategy.Kind() == requeue.KindNone {
+		// No requeue, wait for Watch events
+		logger.V(1).Info("No requeue requested", "reason", strategy.Reason)
+	} else {
+		logger.V(1).Info("Requeue requested", "strategy", strategy.Kind(), "after", strategy.After, "reason", strategy.Reason)
 	}
-	if strategy.After > 0 {
-		logger.V(1).Info("Delayed requeue requested", "after", strategy.After)
-		return ctrl.Result{RequeueAfter: strategy.After}, nil
+	return strategy.Result(), nil
+}
+
+func (r *Reconciler) getSandboxSet(ctx context.Context, key client.ObjectKey) (*agentsv1alpha1.SandboxSet, error) {
+	if r.sandboxSets != nil {
+		return r.sandboxSets.Get(key)
+	}
+	sandboxSet := &agentsv1alpha1.SandboxSet{}
+	if err := r.Get(ctx, key, sandboxSet); err != nil {
+		return nil, err
 	}
-	// No requeue, wait for Watch events
-	return ctrl.Result{}, nil
+	return sandboxSet, nil
+}
+
+// finalizeClaim releases the sandboxes claimed by the deleting claim with its deletion policy and then removes the
+// finalizer, so that no sandbox is left claimed by a claim that no longer exists.
+func (r *Reconciler) finalizeClaim(ctx context.Context, claim *agentsv1alpha1.SandboxClaim) error {
+	if !controllerutil.ContainsFinalizer(claim, agentsv1alpha1.SandboxClaimFinalizer) {
+		return nil
+	}
+	if err := r.getControl().EnsureClaimDeleted(ctx, claim); err != nil {
+		return fmt.Errorf("failed to release claimed sandboxes: %w", err)
+	}
+	_, err := utils.PatchFinalizer(ctx, r.Client, claim, utils.RemoveFinalizerOpType, agentsv1alpha1.SandboxClaimFinalizer)
+	return err
 }
 
 func (r *Reconciler) getControl() core.ClaimControl {
@@ -226,28 +385,25 @@ func (r *Reconciler) getControl() core.ClaimControl {
 func (r *Reconciler) updateClaimStatus(ctx context.Context, newStatus agentsv1alpha1.SandboxClaimStatus, claim *agentsv1alpha1.SandboxClaim) error {
 	logger := logf.FromContext(ctx).WithValues("sandboxclaim", klog.KObj(claim))
 
-	if reflect.DeepEqual(claim.Status, newStatus) {
-		return nil
-	}
-
-	// Use strategic patch for status update
-	by, _ := json.Marshal(newStatus)
-	patchStatus := fmt.Sprintf(`{"status":%s}`, string(by))
 	rcvObject := &agentsv1alpha1.SandboxClaim{
 		ObjectMeta: metav1.ObjectMeta{
 			Namespace: claim.Namespace,
 			Name:      claim.Name,
 		},
 	}
-
-	err := client.IgnoreNotFound(r.Status().Patch(ctx, rcvObject, client.RawPatch(types.MergePatchType, []byte(patchStatus))))
-	if err != nil {
-		logger.Error(err, "update sandboxclaim status failed", "patchStatus", patchStatus)
+	objectsize.DefaultOptions.TruncateClaimStatus(&newStatus)
+	patched, err := claimStatusUpdater.Patch(ctx, r.Client, rcvObject, claim.Status, newStatus)
+	if err = client.IgnoreNotFound(err); err != nil {
+		logger.Error(err, "update sandboxclaim status failed", "status", utils.DumpJson(newStatus))
 		return err
 	}
+	if !patched {
+		return nil
+	}
 
 	// Set expectation for resource version
 	core.ResourceVersionExpectations.Expect(rcvObject)
+	recordClaimTransitions(claim, &claim.Status, &newStatus)
 
 	logger.Info("update sandboxclaim status success", "status", utils.DumpJson(newStatus))
 	return nil
@@ -255,13 +411,20 @@ func (r *Reconciler) updateClaimStatus(ctx context.Context, newStatus agentsv1al
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
-	// Note: We don't watch Sandbox resources because:
+	// Note: Sandbox resources are only watched for claimed sandboxes dying or being deleted, because:
 	// 1. SandboxClaim is a one-time claim operation, not continuous management
-	// 2. After Completed phase, the controller no longer manages claimed sandboxes (by design)
+	// 2. After Completed phase, the controller no longer manages claimed sandboxes, except for
+	//    replacing dead ones when replaceOnFailure is set
 	// 3. This reduces unnecessary reconcile triggers and improves performance
-	return ctrl.NewControllerManagedBy(mgr).
-		Named("sandboxclaim-controller").
-		WithOptions(controller.Options{MaxConcurrentReconciles: concurrentReconciles}).
+	controllerName := "sandboxclaim-controller"
+	options := controller.Options{MaxConcurrentReconciles: concurrentReconciles, NewQueue: controllermetrics.NewQueue}
+	if utilfeature.DefaultFeatureGate.Enabled(features.SandboxClaimPriorityQueueGate) {
+		options.NewQueue = newPriorityQueue(mgr.GetClient())
+	}
+	sandboxHandler := &SandboxEventHandler{Reader: mgr.GetCache()}
+	b := ctrl.NewControllerManagedBy(mgr).
+		Named(controllerName).
+		WithOptions(options).
 		For(&agentsv1alpha1.SandboxClaim{}).
 		Watches(&agentsv1alpha1.SandboxClaim{}, &handler.EnqueueRequestForObject{}, builder.WithPredicates(predicate.Funcs{
 			UpdateFunc: func(e event.UpdateEvent) bool {
@@ -270,8 +433,17 @@ func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
 			},
 			DeleteFunc: func(e event.DeleteEvent) bool {
 				core.ResourceVersionExpectations.Delete(e.Object)
+				core.ClaimConcurrencyLimiter.Release(e.Object)
+				core.ClaimOrdinals.Forget(e.Object)
+				core.ClaimRetries.Reset(e.Object)
+				core.ClaimGroups.Forget(e.Object)
 				return false
 			},
-		})).
-		Complete(r)
+		}))
+	if r.sandboxes != nil {
+		b = b.WatchesRawSource(&source.Informer{Informer: r.sandboxes, Handler: sandboxHandler})
+	} else {
+		b = b.Watches(&agentsv1alpha1.Sandbox{}, sandboxHandler)
+	}
+	return b.Complete(controllermetrics.Wrap(controllerName, r))
 }
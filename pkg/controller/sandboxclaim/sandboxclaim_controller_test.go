@@ -18,20 +18,25 @@ package sandboxclaim
 
 import (
 	"context"
+	"errors"
+	"reflect"
 	"testing"
 	"time"
 
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
 	"github.com/openkruise/agents/pkg/controller/sandboxclaim/core"
 	"github.com/openkruise/agents/pkg/sandbox-manager/infra/sandboxcr"
+	"github.com/openkruise/agents/pkg/utils/fieldindex"
 	utils "github.com/openkruise/agents/pkg/utils/sandbox-manager"
 )
 
@@ -269,6 +274,7 @@ func TestReconciler_Reconcile_Claiming(t *testing.T) {
 		WithScheme(scheme).
 		WithObjects(claim, sandboxSet, sandbox1, sandbox2).
 		WithStatusSubresource(&agentsv1alpha1.SandboxClaim{}).
+		WithIndex(&agentsv1alpha1.SandboxClaim{}, fieldindex.IndexNameForSandboxSetName, core.SandboxSetNameIndexFunc).
 		Build()
 
 	fakeRecorder := record.NewFakeRecorder(100)
@@ -479,6 +485,123 @@ func TestReconciler_GetControl(t *testing.T) {
 	}
 }
 
+// deletingControl records the claims released on deletion
+type deletingControl struct {
+	core.ClaimControl
+	deleted []string
+	err     error
+}
+
+func (c *deletingControl) EnsureClaimDeleted(_ context.Context, claim *agentsv1alpha1.SandboxClaim) error {
+	c.deleted = append(c.deleted, claim.Name)
+	return c.err
+}
+
+func TestReconciler_Reconcile_Finalizer(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = agentsv1alpha1.AddToScheme(scheme)
+
+	claim := &agentsv1alpha1.SandboxClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-claim", Namespace: "default"},
+		Spec:       agentsv1alpha1.SandboxClaimSpec{TemplateName: "nonexistent-sandboxset"},
+	}
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(claim).
+		WithStatusSubresource(&agentsv1alpha1.SandboxClaim{}).
+		Build()
+	control := &deletingControl{err: errors.New("conflict")}
+	reconciler := &Reconciler{
+		Client:   fakeClient,
+		Scheme:   scheme,
+		controls: map[string]core.ClaimControl{core.CommonControlName: control},
+		recorder: record.NewFakeRecorder(10),
+	}
+	req := reconcile.Request{NamespacedName: client.ObjectKeyFromObject(claim)}
+	ctx := context.Background()
+
+	if _, err := reconciler.Reconcile(ctx, req); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	if err := fakeClient.Get(ctx, req.NamespacedName, claim); err != nil {
+		t.Fatalf("Failed to get claim: %v", err)
+	}
+	if !controllerutil.ContainsFinalizer(claim, agentsv1alpha1.SandboxClaimFinalizer) {
+		t.Fatalf("Reconcile() finalizers = %v, want %s", claim.Finalizers, agentsv1alpha1.SandboxClaimFinalizer)
+	}
+
+	// the finalizer is kept until the claimed sandboxes are released
+	if err := fakeClient.Delete(ctx, claim); err != nil {
+		t.Fatalf("Failed to delete claim: %v", err)
+	}
+	if _, err := reconciler.Reconcile(ctx, req); err == nil {
+		t.Fatal("Reconcile() should fail while the claimed sandboxes are not released")
+	}
+	if err := fakeClient.Get(ctx, req.NamespacedName, claim); err != nil {
+		t.Fatalf("Claim should be kept while the claimed sandboxes are not released: %v", err)
+	}
+
+	control.err = nil
+	if _, err := reconciler.Reconcile(ctx, req); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	if err := fakeClient.Get(ctx, req.NamespacedName, claim); !apierrors.IsNotFound(err) {
+		t.Errorf("Claim should be gone after the claimed sandboxes are released, got %v", err)
+	}
+	if len(control.deleted) != 2 {
+		t.Errorf("EnsureClaimDeleted() called %d times, want 2", len(control.deleted))
+	}
+}
+
+func TestDefaultRetryPolicy(t *testing.T) {
+	initial, maxBackoff, jitter := retryInitialBackoff, retryMaxBackoff, retryJitterPercent
+	t.Cleanup(func() { retryInitialBackoff, retryMaxBackoff, retryJitterPercent = initial, maxBackoff, jitter })
+
+	tests := []struct {
+		name       string
+		initial    time.Duration
+		maxBackoff time.Duration
+		jitter     int
+		expected   *agentsv1alpha1.SandboxClaimRetryPolicy
+	}{
+		{
+			name:       "disabled",
+			initial:    time.Second,
+			maxBackoff: 0,
+		},
+		{
+			name:       "backoff with jitter",
+			initial:    time.Second,
+			maxBackoff: time.Minute,
+			jitter:     20,
+			expected: &agentsv1alpha1.SandboxClaimRetryPolicy{
+				InitialBackoff: &metav1.Duration{Duration: time.Second},
+				MaxBackoff:     &metav1.Duration{Duration: time.Minute},
+				JitterPercent:  20,
+			},
+		},
+		{
+			name:       "out of range flags are clamped",
+			initial:    2 * time.Minute,
+			maxBackoff: time.Minute,
+			jitter:     200,
+			expected: &agentsv1alpha1.SandboxClaimRetryPolicy{
+				InitialBackoff: &metav1.Duration{Duration: time.Minute},
+				MaxBackoff:     &metav1.Duration{Duration: time.Minute},
+				JitterPercent:  100,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			retryInitialBackoff, retryMaxBackoff, retryJitterPercent = tt.initial, tt.maxBackoff, tt.jitter
+			if got := defaultRetryPolicy(); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("defaultRetryPolicy() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
 // TestReconciler_SetupWithManager tests the setup function
 // Note: This is a basic test. Full integration testing would require a real Manager.
 func TestReconciler_SetupWithManager(t *testing.T) {
@@ -0,0 +1,92 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sandboxclaim
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+)
+
+// sandboxSetNotFoundTTL is how long a SandboxSet not found is remembered, unless it is created in the meantime
+const sandboxSetNotFoundTTL = 5 * time.Second
+
+// sandboxSetLookup gets a SandboxSet from an informer store, returning a NotFound error if it does not exist
+type sandboxSetLookup func(namespace, name string) (*agentsv1alpha1.SandboxSet, error)
+
+// sandboxSetGetter gets the SandboxSets of claims from the informer without deep copying them, since thousands of
+// claims of a pool look it up on every reconcile. SandboxSets not found are remembered for a short while, so that the
+// claims of a missing pool do not hit the store again, until the SandboxSet is created and Forget is called.
+// The returned SandboxSets are shared with the informer and must not be modified.
+type sandboxSetGetter struct {
+	lookup      sandboxSetLookup
+	notFoundTTL time.Duration
+	now         func() time.Time
+
+	mu       sync.Mutex
+	notFound map[types.NamespacedName]time.Time
+}
+
+func newSandboxSetGetter(lookup sandboxSetLookup, notFoundTTL time.Duration) *sandboxSetGetter {
+	return &sandboxSetGetter{
+		lookup:      lookup,
+		notFoundTTL: notFoundTTL,
+		now:         time.Now,
+		notFound:    map[types.NamespacedName]time.Time{},
+	}
+}
+
+// Get returns the SandboxSet of the key, or a NotFound error if it does not exist.
+func (g *sandboxSetGetter) Get(key types.NamespacedName) (*agentsv1alpha1.SandboxSet, error) {
+	now := g.now()
+	g.mu.Lock()
+	expiry, cached := g.notFound[key]
+	if cached && now.After(expiry) {
+		delete(g.notFound, key)
+		cached = false
+	}
+	g.mu.Unlock()
+	if cached {
+		sandboxSetLookupTotal.WithLabelValues(sandboxSetLookupNotFoundCached).Inc()
+		return nil, errors.NewNotFound(agentsv1alpha1.Resource("sandboxsets"), key.Name)
+	}
+
+	sandboxSet, err := g.lookup(key.Namespace, key.Name)
+	switch {
+	case errors.IsNotFound(err):
+		g.mu.Lock()
+		g.notFound[key] = now.Add(g.notFoundTTL)
+		g.mu.Unlock()
+		sandboxSetLookupTotal.WithLabelValues(sandboxSetLookupNotFound).Inc()
+	case err != nil:
+		sandboxSetLookupTotal.WithLabelValues(sandboxSetLookupError).Inc()
+	default:
+		sandboxSetLookupTotal.WithLabelValues(sandboxSetLookupFound).Inc()
+	}
+	return sandboxSet, err
+}
+
+// Forget drops the SandboxSet of the key from the not found ones, called once it is created.
+func (g *sandboxSetGetter) Forget(key types.NamespacedName) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	delete(g.notFound, key)
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sandboxclaim
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+)
+
+func TestSandboxSetGetter(t *testing.T) {
+	key := types.NamespacedName{Namespace: "default", Name: "pool"}
+	var (
+		stored  *agentsv1alpha1.SandboxSet
+		lookups int
+		failure error
+	)
+	lookup := func(namespace, name string) (*agentsv1alpha1.SandboxSet, error) {
+		lookups++
+		if failure != nil {
+			return nil, failure
+		}
+		if stored == nil || stored.Namespace != namespace || stored.Name != name {
+			return nil, errors.NewNotFound(agentsv1alpha1.Resource("sandboxsets"), name)
+		}
+		return stored, nil
+	}
+	now := time.Now()
+	getter := newSandboxSetGetter(lookup, 5*time.Second)
+	getter.now = func() time.Time { return now }
+	cachedBefore := testutil.ToFloat64(sandboxSetLookupTotal.WithLabelValues(sandboxSetLookupNotFoundCached))
+
+	// not found is remembered until expired
+	_, err := getter.Get(key)
+	assert.True(t, errors.IsNotFound(err))
+	_, err = getter.Get(key)
+	assert.True(t, errors.IsNotFound(err))
+	assert.Equal(t, 1, lookups, "the second lookup should hit the not found cache")
+	assert.Equal(t, cachedBefore+1, testutil.ToFloat64(sandboxSetLookupTotal.WithLabelValues(sandboxSetLookupNotFoundCached)))
+
+	stored = &agentsv1alpha1.SandboxSet{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "pool"}}
+	_, err = getter.Get(key)
+	assert.True(t, errors.IsNotFound(err), "created SandboxSet is not seen before forgotten or expired")
+	now = now.Add(6 * time.Second)
+	got, err := getter.Get(key)
+	require.NoError(t, err)
+	assert.Same(t, stored, got, "the stored SandboxSet should be returned without deep copying")
+	assert.Equal(t, 2, lookups)
+
+	// created SandboxSets are seen at once once forgotten
+	other := types.NamespacedName{Namespace: "other", Name: "pool"}
+	_, err = getter.Get(other)
+	assert.True(t, errors.IsNotFound(err))
+	stored = &agentsv1alpha1.SandboxSet{ObjectMeta: metav1.ObjectMeta{Namespace: "other", Name: "pool"}}
+	getter.Forget(other)
+	got, err = getter.Get(other)
+	require.NoError(t, err)
+	assert.Same(t, stored, got)
+
+	// other errors are not remembered
+	failure = fmt.Errorf("store failure")
+	_, err = getter.Get(key)
+	assert.EqualError(t, err, "store failure")
+	failure = nil
+	_, err = getter.Get(key)
+	assert.True(t, errors.IsNotFound(err), "SandboxSet of the other namespace is not the one of the key")
+	lookups = 0
+	_, _ = getter.Get(key)
+	assert.Equal(t, 0, lookups)
+}
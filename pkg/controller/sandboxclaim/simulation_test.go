@@ -0,0 +1,380 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sandboxclaim
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+	"github.com/openkruise/agents/pkg/controller/sandboxclaim/core"
+	"github.com/openkruise/agents/pkg/sandbox-manager/clients"
+	"github.com/openkruise/agents/pkg/sandbox-manager/infra/sandboxcr"
+	"github.com/openkruise/agents/pkg/utils/conditions"
+	"github.com/openkruise/agents/pkg/utils/fieldindex"
+)
+
+// simulationSeeds are the seeds of the simulations, each of which drives a claim through its own random events.
+// A failing seed replays the same events, so that it can be debugged alone.
+var simulationSeeds = []int64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12}
+
+const (
+	simulationSteps      = 12
+	simulationReconciles = 3
+	simulationPool       = "sim-pool"
+	simulationTimeout    = time.Minute
+)
+
+// simulationEvent changes the world around the claim, and reports whether anything changed
+type simulationEvent struct {
+	name   string
+	weight int
+	apply  func(s *simulation) (string, bool)
+}
+
+var simulationEvents = []simulationEvent{
+	{name: "SandboxAppears", weight: 4, apply: (*simulation).sandboxAppears},
+	{name: "SandboxDies", weight: 2, apply: (*simulation).sandboxDies},
+	{name: "TimeoutFires", weight: 1, apply: (*simulation).timeoutFires},
+	{name: "SandboxSetDeleted", weight: 1, apply: (*simulation).sandboxSetDeleted},
+	{name: "Nothing", weight: 2, apply: func(*simulation) (string, bool) { return "", true }},
+}
+
+// simulation drives a claim through the Reconciler with random events of its world, in an order determined by the
+// seed, and checks the invariants of the claim state machine after every reconcile.
+type simulation struct {
+	t          *testing.T
+	ctx        context.Context
+	rng        *rand.Rand
+	client     client.Client
+	clientSet  *clients.ClientSet
+	cache      *sandboxcr.Cache
+	reconciler *Reconciler
+	claim      *agentsv1alpha1.SandboxClaim
+	created    int
+	setDeleted bool
+	trace      []string
+}
+
+func newSimulation(t *testing.T, seed int64) *simulation {
+	cache, clientSet, err := sandboxcr.NewTestCache(t)
+	require.NoError(t, err)
+	scheme := runtime.NewScheme()
+	_ = agentsv1alpha1.AddToScheme(scheme)
+
+	rng := rand.New(rand.NewSource(seed))
+	policies := []agentsv1alpha1.SandboxClaimTimeoutPolicy{
+		agentsv1alpha1.SandboxClaimTimeoutKeepClaimed,
+		agentsv1alpha1.SandboxClaimTimeoutReleaseClaimed,
+		agentsv1alpha1.SandboxClaimTimeoutBestEffort,
+	}
+	replicas := int32(1 + rng.Intn(3))
+	claim := &agentsv1alpha1.SandboxClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "sim-claim",
+			Namespace:  "default",
+			UID:        types.UID(fmt.Sprintf("sim-claim-uid-%d", seed)),
+			Generation: 1,
+		},
+		Spec: agentsv1alpha1.SandboxClaimSpec{
+			TemplateName:     simulationPool,
+			Replicas:         &replicas,
+			SkipInitRuntime:  true,
+			ClaimTimeout:     &metav1.Duration{Duration: simulationTimeout},
+			ReplaceOnFailure: rng.Intn(2) == 0,
+			OnTimeout:        policies[rng.Intn(len(policies))],
+		},
+	}
+	sandboxSet := &agentsv1alpha1.SandboxSet{
+		ObjectMeta: metav1.ObjectMeta{Name: simulationPool, Namespace: "default", UID: "sim-pool-uid"},
+	}
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(claim, sandboxSet).
+		WithStatusSubresource(&agentsv1alpha1.SandboxClaim{}).
+		WithIndex(&agentsv1alpha1.SandboxClaim{}, fieldindex.IndexNameForSandboxSetName, core.SandboxSetNameIndexFunc).
+		Build()
+	recorder := record.NewFakeRecorder(1000)
+	s := &simulation{
+		t:         t,
+		ctx:       context.Background(),
+		rng:       rng,
+		client:    fakeClient,
+		clientSet: clientSet,
+		cache:     cache,
+		reconciler: &Reconciler{
+			Client:   fakeClient,
+			Scheme:   scheme,
+			controls: core.NewClaimControl(fakeClient, recorder, clientSet, cache),
+			recorder: recorder,
+		},
+		claim: claim,
+	}
+	s.logf("claim with %d replicas, replaceOnFailure %v, onTimeout %s",
+		replicas, claim.Spec.ReplaceOnFailure, claim.Spec.OnTimeout)
+	return s
+}
+
+func (s *simulation) logf(format string, args ...any) {
+	s.trace = append(s.trace, fmt.Sprintf(format, args...))
+}
+
+// failf fails the simulation with the trace of its events, so that the failure can be followed
+func (s *simulation) failf(format string, args ...any) {
+	s.t.Helper()
+	s.t.Fatalf("%s\ntrace:\n  %s", fmt.Sprintf(format, args...), strings.Join(s.trace, "\n  "))
+}
+
+func (s *simulation) sandboxes() []agentsv1alpha1.Sandbox {
+	list, err := s.clientSet.SandboxClient.ApiV1alpha1().Sandboxes("default").List(s.ctx, metav1.ListOptions{})
+	require.NoError(s.t, err)
+	return list.Items
+}
+
+func (s *simulation) sandboxAppears() (string, bool) {
+	s.created++
+	controller := true
+	sbx := &agentsv1alpha1.Sandbox{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              fmt.Sprintf("sim-sandbox-%d", s.created),
+			Namespace:         "default",
+			CreationTimestamp: metav1.Now(),
+			Labels: map[string]string{
+				agentsv1alpha1.LabelSandboxTemplate:  simulationPool,
+				agentsv1alpha1.LabelSandboxIsClaimed: "false",
+			},
+			Annotations: map[string]string{},
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: agentsv1alpha1.GroupVersion.String(),
+				Kind:       "SandboxSet",
+				Name:       simulationPool,
+				UID:        "sim-pool-uid",
+				Controller: &controller,
+			}},
+		},
+		Status: agentsv1alpha1.SandboxStatus{
+			Phase:      agentsv1alpha1.SandboxRunning,
+			Conditions: []metav1.Condition{{Type: string(agentsv1alpha1.SandboxConditionReady), Status: metav1.ConditionTrue, Reason: "PodReady"}},
+			PodInfo:    agentsv1alpha1.PodInfo{PodIP: fmt.Sprintf("10.0.0.%d", s.created)},
+		},
+	}
+	_, err := s.clientSet.SandboxClient.ApiV1alpha1().Sandboxes(sbx.Namespace).Create(s.ctx, sbx, metav1.CreateOptions{})
+	require.NoError(s.t, err)
+	return sbx.Name, true
+}
+
+func (s *simulation) sandboxDies() (string, bool) {
+	var alive []agentsv1alpha1.Sandbox
+	for _, sbx := range s.sandboxes() {
+		if sbx.Annotations[agentsv1alpha1.AnnotationOwner] == string(s.claim.UID) && sbx.Status.Phase == agentsv1alpha1.SandboxRunning {
+			alive = append(alive, sbx)
+		}
+	}
+	if len(alive) == 0 {
+		return "", false
+	}
+	sbx := alive[s.rng.Intn(len(alive))]
+	sbx.Status.Phase = agentsv1alpha1.SandboxFailed
+	_, err := s.clientSet.SandboxClient.ApiV1alpha1().Sandboxes(sbx.Namespace).UpdateStatus(s.ctx, &sbx, metav1.UpdateOptions{})
+	require.NoError(s.t, err)
+	return sbx.Name, true
+}
+
+func (s *simulation) timeoutFires() (string, bool) {
+	claim := s.getClaim()
+	if claim.Status.ClaimStartTime == nil || claim.Status.Phase == agentsv1alpha1.SandboxClaimPhaseCompleted {
+		return "", false
+	}
+	patch := client.MergeFrom(claim.DeepCopy())
+	claim.Status.ClaimStartTime = &metav1.Time{Time: claim.Status.ClaimStartTime.Add(-simulationTimeout - time.Second)}
+	require.NoError(s.t, s.client.Status().Patch(s.ctx, claim, patch))
+	return "", true
+}
+
+func (s *simulation) sandboxSetDeleted() (string, bool) {
+	if s.setDeleted {
+		return "", false
+	}
+	sandboxSet := &agentsv1alpha1.SandboxSet{ObjectMeta: metav1.ObjectMeta{Name: simulationPool, Namespace: "default"}}
+	require.NoError(s.t, s.client.Delete(s.ctx, sandboxSet))
+	s.setDeleted = true
+	return "", true
+}
+
+func (s *simulation) getClaim() *agentsv1alpha1.SandboxClaim {
+	claim := &agentsv1alpha1.SandboxClaim{}
+	require.NoError(s.t, s.client.Get(s.ctx, client.ObjectKeyFromObject(s.claim), claim))
+	return claim
+}
+
+// syncCache waits for the cache to observe the sandboxes as they are, so that every reconcile sees the same world
+// whatever the timing of the informers.
+func (s *simulation) syncCache() {
+	type view struct {
+		owner string
+		phase agentsv1alpha1.SandboxPhase
+	}
+	expected := map[string]view{}
+	for _, sbx := range s.sandboxes() {
+		expected[sbx.Name] = view{owner: sbx.Annotations[agentsv1alpha1.AnnotationOwner], phase: sbx.Status.Phase}
+	}
+	err := wait.PollUntilContextTimeout(s.ctx, 5*time.Millisecond, 5*time.Second, true, func(context.Context) (bool, error) {
+		claimed, err := s.cache.ListSandboxWithUser(string(s.claim.UID))
+		if err != nil {
+			return false, err
+		}
+		pool, err := s.cache.ListSandboxesInPool(simulationPool)
+		if err != nil {
+			return false, err
+		}
+		observed := map[string]view{}
+		for _, sbx := range append(claimed, pool...) {
+			observed[sbx.Name] = view{owner: sbx.Annotations[agentsv1alpha1.AnnotationOwner], phase: sbx.Status.Phase}
+		}
+		if len(observed) != len(expected) {
+			return false, nil
+		}
+		for name, v := range expected {
+			if observed[name] != v {
+				return false, nil
+			}
+		}
+		return true, nil
+	})
+	if err != nil {
+		s.failf("cache did not observe the sandboxes %v: %v", expected, err)
+	}
+}
+
+var phaseRank = map[agentsv1alpha1.SandboxClaimPhase]int{
+	"": 0,
+	agentsv1alpha1.SandboxClaimPhasePendingApproval: 1,
+	agentsv1alpha1.SandboxClaimPhaseQueued:          2,
+	agentsv1alpha1.SandboxClaimPhaseClaiming:        3,
+	agentsv1alpha1.SandboxClaimPhaseCompleted:       4,
+}
+
+// checkInvariants checks the status of the claim after a reconcile against the one before
+func (s *simulation) checkInvariants(prev, cur *agentsv1alpha1.SandboxClaimStatus) {
+	s.t.Helper()
+	desired := *s.claim.Spec.Replicas
+
+	// phases only progress, except a completed claim claiming again the replicas it lost
+	if phaseRank[cur.Phase] < phaseRank[prev.Phase] {
+		reopened := prev.Phase == agentsv1alpha1.SandboxClaimPhaseCompleted && cur.Phase == agentsv1alpha1.SandboxClaimPhaseClaiming &&
+			len(cur.History) > 0 && cur.History[len(cur.History)-1].Reason == "ReplicaLost"
+		if !reopened {
+			s.failf("phase went back from %q to %q", prev.Phase, cur.Phase)
+		}
+	}
+	// the completion time is set exactly once per completion
+	completed := cur.Phase == agentsv1alpha1.SandboxClaimPhaseCompleted
+	if completed != (cur.CompletionTime != nil) {
+		s.failf("phase %q with completionTime %v", cur.Phase, cur.CompletionTime)
+	}
+	if completed && prev.Phase == agentsv1alpha1.SandboxClaimPhaseCompleted && !cur.CompletionTime.Equal(prev.CompletionTime) {
+		s.failf("completionTime of the completed claim changed from %v to %v", prev.CompletionTime, cur.CompletionTime)
+	}
+	if completed != conditions.IsTrue(cur.Conditions, string(agentsv1alpha1.SandboxClaimConditionCompleted)) {
+		s.failf("phase %q with Completed condition %v", cur.Phase,
+			conditions.Get(cur.Conditions, string(agentsv1alpha1.SandboxClaimConditionCompleted)))
+	}
+	// claims started claiming keep the time they started
+	if (cur.Phase == agentsv1alpha1.SandboxClaimPhaseQueued || cur.Phase == agentsv1alpha1.SandboxClaimPhaseClaiming) && cur.ClaimStartTime == nil {
+		s.failf("phase %q without claimStartTime", cur.Phase)
+	}
+	// never more sandboxes are claimed than desired
+	if cur.ClaimedReplicas < 0 || cur.ClaimedReplicas > desired {
+		s.failf("claimedReplicas %d out of [0, %d]", cur.ClaimedReplicas, desired)
+	}
+	alive := int32(0)
+	for _, sbx := range s.sandboxes() {
+		if sbx.Annotations[agentsv1alpha1.AnnotationOwner] == string(s.claim.UID) && sbx.Status.Phase == agentsv1alpha1.SandboxRunning {
+			alive++
+		}
+	}
+	if alive > desired {
+		s.failf("%d alive sandboxes claimed for %d replicas", alive, desired)
+	}
+	// a claim whose SandboxSet is gone is done
+	if s.setDeleted && !completed {
+		s.failf("phase %q after the SandboxSet is deleted", cur.Phase)
+	}
+}
+
+func (s *simulation) pickEvent() simulationEvent {
+	total := 0
+	for _, event := range simulationEvents {
+		total += event.weight
+	}
+	n := s.rng.Intn(total)
+	for _, event := range simulationEvents {
+		if n < event.weight {
+			return event
+		}
+		n -= event.weight
+	}
+	return simulationEvents[len(simulationEvents)-1]
+}
+
+func (s *simulation) run() {
+	req := reconcile.Request{NamespacedName: client.ObjectKeyFromObject(s.claim)}
+	prev := s.getClaim().Status.DeepCopy()
+	for step := 0; step < simulationSteps; step++ {
+		event := s.pickEvent()
+		target, applied := event.apply(s)
+		if !applied {
+			continue
+		}
+		s.logf("step %d: %s %s", step, event.name, target)
+		s.syncCache()
+		for i := 0; i < simulationReconciles; i++ {
+			if _, err := s.reconciler.Reconcile(s.ctx, req); err != nil {
+				s.failf("reconcile failed: %v", err)
+			}
+			s.syncCache()
+			cur := s.getClaim().Status.DeepCopy()
+			s.logf("  reconciled: phase %q, claimed %d, message %q", cur.Phase, cur.ClaimedReplicas, cur.Message)
+			s.checkInvariants(prev, cur)
+			prev = cur
+		}
+	}
+}
+
+// TestClaimStateMachineSimulation drives claims through random orders of sandboxes appearing and dying, timeouts
+// and the SandboxSet being deleted, and checks the invariants of the claim state machine along the way.
+func TestClaimStateMachineSimulation(t *testing.T) {
+	for _, seed := range simulationSeeds {
+		t.Run(fmt.Sprintf("seed-%d", seed), func(t *testing.T) {
+			newSimulation(t, seed).run()
+		})
+	}
+}
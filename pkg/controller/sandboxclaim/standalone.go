@@ -0,0 +1,59 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sandboxclaim
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+	"github.com/openkruise/agents/pkg/controller/sandboxclaim/core"
+	"github.com/openkruise/agents/pkg/webhook/standalone"
+)
+
+// defaultAndValidate defaults and validates the claim as the webhooks would have, in the standalone validation mode.
+// New claims are patched with the defaults and reconciled again, and the ones a webhook would have rejected are
+// completed without claiming. As the webhooks only reject updates, claims in progress beyond the limits of their
+// SandboxSet are only warned about. It returns true if the claim is not to be reconciled further.
+func (r *Reconciler) defaultAndValidate(ctx context.Context, claim *agentsv1alpha1.SandboxClaim,
+	sandboxSet *agentsv1alpha1.SandboxSet, newStatus *agentsv1alpha1.SandboxClaimStatus) (bool, error) {
+	logger := logf.FromContext(ctx)
+	create := claim.Status.Phase == ""
+	if create {
+		defaulted := claim.DeepCopy()
+		if standalone.DefaultSandboxClaim(defaulted) {
+			logger.Info("Patching SandboxClaim with the defaults of the standalone validation mode")
+			return true, r.Patch(ctx, defaulted, client.MergeFrom(claim))
+		}
+	}
+	message, err := standalone.ValidateSandboxClaim(ctx, r.Client, claim, sandboxSet, create)
+	if err != nil || message == "" {
+		return false, err
+	}
+	logger.Info("SandboxClaim rejected by the standalone validation", "reason", message, "new", create)
+	r.recorder.Eventf(claim, corev1.EventTypeWarning, standalone.EventValidationFailed,
+		"The webhooks would reject the claim: %s", message)
+	if !create {
+		return false, nil
+	}
+	newStatus.ObservedGeneration = claim.Generation
+	core.TransitionToCompleted(newStatus, standalone.EventValidationFailed, message)
+	return true, r.updateClaimStatus(ctx, *newStatus, claim)
+}
@@ -0,0 +1,140 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sandboxclaim
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+	"github.com/openkruise/agents/pkg/controller/sandboxclaim/core"
+	"github.com/openkruise/agents/pkg/sandbox-manager/infra/sandboxcr"
+	"github.com/openkruise/agents/pkg/utils/conditions"
+	"github.com/openkruise/agents/pkg/utils/fieldindex"
+	"github.com/openkruise/agents/pkg/webhook/sandboxclaim/mutating"
+	"github.com/openkruise/agents/pkg/webhook/standalone"
+)
+
+func TestReconciler_Reconcile_StandaloneValidation(t *testing.T) {
+	opts := standalone.DefaultOptions
+	t.Cleanup(func() { standalone.DefaultOptions = opts })
+	standalone.DefaultOptions.Enabled = true
+
+	scheme := runtime.NewScheme()
+	require.NoError(t, agentsv1alpha1.AddToScheme(scheme))
+	sandboxSet := &agentsv1alpha1.SandboxSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "pool", Namespace: "default"},
+		Spec:       agentsv1alpha1.SandboxSetSpec{MaxReplicasPerClaim: int32Ptr(2)},
+	}
+
+	tests := []struct {
+		name          string
+		replicas      int32
+		claimTimeout  *metav1.Duration
+		status        agentsv1alpha1.SandboxClaimStatus
+		expectPhase   agentsv1alpha1.SandboxClaimPhase
+		expectTimeout time.Duration
+		expectEvent   bool
+	}{
+		{
+			name:          "new claim is defaulted",
+			replicas:      1,
+			expectTimeout: mutating.DefaultClaimTimeout,
+		},
+		{
+			name:          "new claim beyond the limits is completed",
+			replicas:      3,
+			claimTimeout:  &metav1.Duration{Duration: time.Minute},
+			expectPhase:   agentsv1alpha1.SandboxClaimPhaseCompleted,
+			expectTimeout: time.Minute,
+			expectEvent:   true,
+		},
+		{
+			name:         "claim in progress beyond the limits is warned about",
+			replicas:     3,
+			claimTimeout: &metav1.Duration{Duration: time.Minute},
+			status: agentsv1alpha1.SandboxClaimStatus{
+				Phase:          agentsv1alpha1.SandboxClaimPhaseClaiming,
+				ClaimStartTime: &metav1.Time{Time: time.Now()},
+			},
+			expectPhase:   agentsv1alpha1.SandboxClaimPhaseClaiming,
+			expectTimeout: time.Minute,
+			expectEvent:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claim := &agentsv1alpha1.SandboxClaim{
+				ObjectMeta: metav1.ObjectMeta{Name: "claim", Namespace: "default", Generation: 1},
+				Spec: agentsv1alpha1.SandboxClaimSpec{
+					TemplateName: "pool",
+					Replicas:     int32Ptr(tt.replicas),
+					ClaimTimeout: tt.claimTimeout,
+				},
+				Status: tt.status,
+			}
+			cache, clientSet, err := sandboxcr.NewTestCache(t)
+			require.NoError(t, err)
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			go func() {
+				_ = cache.Run(ctx)
+			}()
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(claim, sandboxSet).
+				WithStatusSubresource(&agentsv1alpha1.SandboxClaim{}).
+				WithIndex(&agentsv1alpha1.SandboxClaim{}, fieldindex.IndexNameForSandboxSetName, core.SandboxSetNameIndexFunc).Build()
+			recorder := record.NewFakeRecorder(10)
+			reconciler := &Reconciler{
+				Client:   fakeClient,
+				Scheme:   scheme,
+				controls: core.NewClaimControl(fakeClient, recorder, clientSet, cache),
+				recorder: recorder,
+			}
+
+			_, err = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(claim)})
+			require.NoError(t, err)
+			require.NoError(t, fakeClient.Get(ctx, client.ObjectKeyFromObject(claim), claim))
+			assert.Equal(t, tt.expectPhase, claim.Status.Phase)
+			require.NotNil(t, claim.Spec.ClaimTimeout)
+			assert.Equal(t, tt.expectTimeout, claim.Spec.ClaimTimeout.Duration)
+			var events []string
+			for len(recorder.Events) > 0 {
+				events = append(events, <-recorder.Events)
+			}
+			if tt.expectEvent {
+				assert.Contains(t, events, "Warning ValidationFailed The webhooks would reject the claim: "+
+					"replicas 3 exceeds maxReplicasPerClaim 2 of SandboxSet pool")
+			} else {
+				assert.Empty(t, events)
+			}
+			if tt.expectPhase == agentsv1alpha1.SandboxClaimPhaseCompleted {
+				assert.True(t, conditions.IsTrue(claim.Status.Conditions, string(agentsv1alpha1.SandboxClaimConditionCompleted)))
+				assert.Equal(t, "replicas 3 exceeds maxReplicasPerClaim 2 of SandboxSet pool", claim.Status.Message)
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sandboxclaim
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+)
+
+// resolveSandboxSet returns the SandboxSet the claim claims from, its templateName or the one picked by its
+// templateSelector or templates. If there is none, it returns why instead.
+func (r *Reconciler) resolveSandboxSet(ctx context.Context, claim *agentsv1alpha1.SandboxClaim,
+	newStatus *agentsv1alpha1.SandboxClaimStatus) (*agentsv1alpha1.SandboxSet, string, error) {
+	if len(claim.Spec.Templates) > 0 {
+		return r.preferSandboxSet(ctx, claim, newStatus)
+	}
+	if claim.Spec.TemplateSelector == nil {
+		sandboxSet, err := r.getSandboxSet(ctx, client.ObjectKey{Namespace: claim.Namespace, Name: claim.Spec.TemplateName})
+		if errors.IsNotFound(err) {
+			return nil, fmt.Sprintf("SandboxSet %s not found", claim.Spec.TemplateName), nil
+		}
+		return sandboxSet, "", err
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(claim.Spec.TemplateSelector)
+	if err != nil {
+		return nil, fmt.Sprintf("Invalid templateSelector: %v", err), nil
+	}
+	sandboxSets := &agentsv1alpha1.SandboxSetList{}
+	if err := r.List(ctx, sandboxSets, client.InNamespace(claim.Namespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return nil, "", fmt.Errorf("failed to list sandboxsets: %w", err)
+	}
+	sandboxSet := pickSandboxSet(sandboxSets.Items, newStatus.SandboxSetName)
+	if sandboxSet == nil {
+		return nil, "No SandboxSet matches templateSelector", nil
+	}
+	r.switchSandboxSet(ctx, claim, newStatus, sandboxSet)
+	return sandboxSet, "", nil
+}
+
+// preferSandboxSet picks the SandboxSet to claim from among the templates of the claim: the first one in the order of
+// preference with available sandboxes, or the first one found if none has.
+func (r *Reconciler) preferSandboxSet(ctx context.Context, claim *agentsv1alpha1.SandboxClaim,
+	newStatus *agentsv1alpha1.SandboxClaimStatus) (*agentsv1alpha1.SandboxSet, string, error) {
+	var picked *agentsv1alpha1.SandboxSet
+	for _, name := range claim.Spec.Templates {
+		sandboxSet, err := r.getSandboxSet(ctx, client.ObjectKey{Namespace: claim.Namespace, Name: name})
+		if errors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return nil, "", err
+		}
+		if !sandboxSet.DeletionTimestamp.IsZero() {
+			continue
+		}
+		if picked == nil {
+			picked = sandboxSet
+		}
+		if sandboxSet.Status.AvailableReplicas > 0 {
+			picked = sandboxSet
+			break
+		}
+	}
+	if picked == nil {
+		return nil, fmt.Sprintf("None of SandboxSets %s found", strings.Join(claim.Spec.Templates, ", ")), nil
+	}
+	r.switchSandboxSet(ctx, claim, newStatus, picked)
+	return picked, "", nil
+}
+
+// switchSandboxSet records the SandboxSet picked for the claim in its status, with an event if it claimed from
+// another one before.
+func (r *Reconciler) switchSandboxSet(ctx context.Context, claim *agentsv1alpha1.SandboxClaim,
+	newStatus *agentsv1alpha1.SandboxClaimStatus, sandboxSet *agentsv1alpha1.SandboxSet) {
+	previous := newStatus.SandboxSetName
+	if previous == sandboxSet.Name {
+		return
+	}
+	if previous != "" {
+		logf.FromContext(ctx).Info("Falling back to another SandboxSet", "previous", previous, "sandboxSet", sandboxSet.Name)
+		r.recorder.Eventf(claim, corev1.EventTypeNormal, "SandboxSetFallback",
+			"Claiming from SandboxSet %s instead of %s", sandboxSet.Name, previous)
+	}
+	newStatus.SandboxSetName = sandboxSet.Name
+}
+
+// pickSandboxSet picks the SandboxSet to claim from among the ones matching a templateSelector: the current one as
+// long as it has available sandboxes, otherwise the first one by name which has. If none has, the claim waits for the
+// current one, or the first one by name if the current one does not match anymore.
+func pickSandboxSet(sandboxSets []agentsv1alpha1.SandboxSet, current string) *agentsv1alpha1.SandboxSet {
+	var candidates []*agentsv1alpha1.SandboxSet
+	for i := range sandboxSets {
+		if sandboxSets[i].DeletionTimestamp.IsZero() {
+			candidates = append(candidates, &sandboxSets[i])
+		}
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if (candidates[i].Name == current) != (candidates[j].Name == current) {
+			return candidates[i].Name == current
+		}
+		return candidates[i].Name < candidates[j].Name
+	})
+	for _, sandboxSet := range candidates {
+		if sandboxSet.Status.AvailableReplicas > 0 {
+			return sandboxSet
+		}
+	}
+	return candidates[0]
+}
@@ -0,0 +1,220 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sandboxclaim
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+)
+
+func newSelectedSandboxSet(name string, available int32) *agentsv1alpha1.SandboxSet {
+	return &agentsv1alpha1.SandboxSet{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: map[string]string{"runtime": "python"}},
+		Status:     agentsv1alpha1.SandboxSetStatus{AvailableReplicas: available},
+	}
+}
+
+func TestPickSandboxSet(t *testing.T) {
+	deleting := newSelectedSandboxSet("a", 3)
+	deleting.DeletionTimestamp = ptr.To(metav1.Now())
+
+	tests := []struct {
+		name        string
+		sandboxSets []*agentsv1alpha1.SandboxSet
+		current     string
+		expected    string
+	}{
+		{
+			name: "no sandboxset",
+		},
+		{
+			name:        "first available by name",
+			sandboxSets: []*agentsv1alpha1.SandboxSet{newSelectedSandboxSet("c", 1), newSelectedSandboxSet("a", 0), newSelectedSandboxSet("b", 1)},
+			expected:    "b",
+		},
+		{
+			name:        "current available sandboxset is kept",
+			sandboxSets: []*agentsv1alpha1.SandboxSet{newSelectedSandboxSet("a", 1), newSelectedSandboxSet("b", 1)},
+			current:     "b",
+			expected:    "b",
+		},
+		{
+			name:        "falls back from the exhausted current sandboxset",
+			sandboxSets: []*agentsv1alpha1.SandboxSet{newSelectedSandboxSet("a", 0), newSelectedSandboxSet("b", 0), newSelectedSandboxSet("c", 2)},
+			current:     "b",
+			expected:    "c",
+		},
+		{
+			name:        "waits for the current sandboxset when all are exhausted",
+			sandboxSets: []*agentsv1alpha1.SandboxSet{newSelectedSandboxSet("a", 0), newSelectedSandboxSet("b", 0)},
+			current:     "b",
+			expected:    "b",
+		},
+		{
+			name:        "deleting sandboxsets are skipped",
+			sandboxSets: []*agentsv1alpha1.SandboxSet{deleting, newSelectedSandboxSet("b", 0)},
+			expected:    "b",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sandboxSets []agentsv1alpha1.SandboxSet
+			for _, sandboxSet := range tt.sandboxSets {
+				sandboxSets = append(sandboxSets, *sandboxSet)
+			}
+			picked := pickSandboxSet(sandboxSets, tt.current)
+			if tt.expected == "" {
+				assert.Nil(t, picked)
+				return
+			}
+			require.NotNil(t, picked)
+			assert.Equal(t, tt.expected, picked.Name)
+		})
+	}
+}
+
+func TestReconciler_resolveSandboxSet(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = agentsv1alpha1.AddToScheme(scheme)
+	selector := &metav1.LabelSelector{MatchLabels: map[string]string{"runtime": "python"}}
+	other := newSelectedSandboxSet("node", 5)
+	other.Labels = map[string]string{"runtime": "node"}
+
+	tests := []struct {
+		name            string
+		spec            agentsv1alpha1.SandboxClaimSpec
+		current         string
+		objects         []client.Object
+		expected        string
+		expectNotFound  string
+		expectFallback  bool
+		expectSelection string
+	}{
+		{
+			name:           "templateName not found",
+			spec:           agentsv1alpha1.SandboxClaimSpec{TemplateName: "pool"},
+			expectNotFound: "SandboxSet pool not found",
+		},
+		{
+			name:     "templateName",
+			spec:     agentsv1alpha1.SandboxClaimSpec{TemplateName: "pool"},
+			objects:  []client.Object{newSelectedSandboxSet("pool", 0)},
+			expected: "pool",
+		},
+		{
+			name:           "no sandboxset matches templateSelector",
+			spec:           agentsv1alpha1.SandboxClaimSpec{TemplateSelector: selector},
+			objects:        []client.Object{other},
+			expectNotFound: "No SandboxSet matches templateSelector",
+		},
+		{
+			name: "invalid templateSelector",
+			spec: agentsv1alpha1.SandboxClaimSpec{TemplateSelector: &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+				{Key: "runtime", Operator: "Unknown"}}}},
+			expectNotFound: `Invalid templateSelector: "Unknown" is not a valid label selector operator`,
+		},
+		{
+			name:            "templateSelector picks a sandboxset",
+			spec:            agentsv1alpha1.SandboxClaimSpec{TemplateSelector: selector},
+			objects:         []client.Object{other, newSelectedSandboxSet("python-a", 0), newSelectedSandboxSet("python-b", 2)},
+			expected:        "python-b",
+			expectSelection: "python-b",
+		},
+		{
+			name:            "templateSelector falls back from the exhausted sandboxset",
+			spec:            agentsv1alpha1.SandboxClaimSpec{TemplateSelector: selector},
+			current:         "python-a",
+			objects:         []client.Object{newSelectedSandboxSet("python-a", 0), newSelectedSandboxSet("python-b", 2)},
+			expected:        "python-b",
+			expectSelection: "python-b",
+			expectFallback:  true,
+		},
+		{
+			name:           "none of templates found",
+			spec:           agentsv1alpha1.SandboxClaimSpec{Templates: []string{"python-a", "python-b"}},
+			expectNotFound: "None of SandboxSets python-a, python-b found",
+		},
+		{
+			name:            "templates prefer the first available sandboxset",
+			spec:            agentsv1alpha1.SandboxClaimSpec{Templates: []string{"python-b", "python-a"}},
+			objects:         []client.Object{newSelectedSandboxSet("python-a", 2), newSelectedSandboxSet("python-b", 1)},
+			expected:        "python-b",
+			expectSelection: "python-b",
+		},
+		{
+			name:            "templates fall back to the next sandboxset in order",
+			spec:            agentsv1alpha1.SandboxClaimSpec{Templates: []string{"missing", "python-a", "python-b"}},
+			current:         "python-a",
+			objects:         []client.Object{newSelectedSandboxSet("python-a", 0), newSelectedSandboxSet("python-b", 2)},
+			expected:        "python-b",
+			expectSelection: "python-b",
+			expectFallback:  true,
+		},
+		{
+			name:            "templates wait for the first sandboxset found when all are exhausted",
+			spec:            agentsv1alpha1.SandboxClaimSpec{Templates: []string{"missing", "python-a", "python-b"}},
+			objects:         []client.Object{newSelectedSandboxSet("python-a", 0), newSelectedSandboxSet("python-b", 0)},
+			expected:        "python-a",
+			expectSelection: "python-a",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claim := &agentsv1alpha1.SandboxClaim{
+				ObjectMeta: metav1.ObjectMeta{Name: "claim", Namespace: "default"},
+				Spec:       tt.spec,
+				Status:     agentsv1alpha1.SandboxClaimStatus{SandboxSetName: tt.current},
+			}
+			recorder := record.NewFakeRecorder(10)
+			reconciler := &Reconciler{
+				Client:   fake.NewClientBuilder().WithScheme(scheme).WithObjects(tt.objects...).Build(),
+				recorder: recorder,
+			}
+			newStatus := claim.Status.DeepCopy()
+
+			sandboxSet, notFound, err := reconciler.resolveSandboxSet(context.Background(), claim, newStatus)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectNotFound, notFound)
+			if tt.expected == "" {
+				assert.Nil(t, sandboxSet)
+			} else {
+				require.NotNil(t, sandboxSet)
+				assert.Equal(t, tt.expected, sandboxSet.Name)
+			}
+			if tt.expectSelection != "" {
+				assert.Equal(t, tt.expectSelection, newStatus.SandboxSetName)
+			}
+			if tt.expectFallback {
+				require.Len(t, recorder.Events, 1)
+				assert.Equal(t, "Normal SandboxSetFallback Claiming from SandboxSet python-b instead of python-a", <-recorder.Events)
+			} else {
+				assert.Empty(t, recorder.Events)
+			}
+		})
+	}
+}
@@ -0,0 +1,179 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sandboxset
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+	"github.com/openkruise/agents/pkg/features"
+	utilfeature "github.com/openkruise/agents/pkg/utils/feature"
+	"github.com/openkruise/agents/pkg/utils/sandboxutils"
+)
+
+// defaultScaleDownCooldown is the cooldown of spec.autoscaling without scaleDownCooldown
+const defaultScaleDownCooldown = 5 * time.Minute
+
+var sandboxClaimKind = agentsv1alpha1.GroupVersion.WithKind("SandboxClaim")
+
+// autoscalingEnabled reports whether the SandboxSet grows with the pressure of its claims
+func autoscalingEnabled(sbs *agentsv1alpha1.SandboxSet) bool {
+	return sbs.Spec.Autoscaling != nil && utilfeature.DefaultFeatureGate.Enabled(features.SandboxSetAutoscalingGate)
+}
+
+// targetReplicas returns the number of unclaimed sandboxes the SandboxSet is scaled to, i.e. the desired replicas and
+// no less than the ones keeping spec.minAvailable
+func targetReplicas(sbs *agentsv1alpha1.SandboxSet, newStatus *agentsv1alpha1.SandboxSetStatus) int32 {
+	return max(desiredReplicas(sbs, newStatus), minAvailableReplicas(sbs, newStatus))
+}
+
+// desiredReplicas returns the replicas desired by autoscaling if enabled, otherwise spec.replicas
+func desiredReplicas(sbs *agentsv1alpha1.SandboxSet, newStatus *agentsv1alpha1.SandboxSetStatus) int32 {
+	if autoscalingEnabled(sbs) && newStatus.Autoscaling != nil {
+		return newStatus.Autoscaling.DesiredReplicas
+	}
+	return sbs.Spec.Replicas
+}
+
+// calculateAutoscaling scales the desired replicas of the SandboxSet in status.autoscaling up to the pending replicas
+// of its claims right away, and down only once they have stayed lower for the cooldown. It returns how long until
+// the cooldown ends if scaling down waits for it, and whether the desired replicas changed.
+func calculateAutoscaling(newStatus *agentsv1alpha1.SandboxSetStatus, sbs *agentsv1alpha1.SandboxSet, pending int32,
+	now time.Time) (time.Duration, bool) {
+	if !autoscalingEnabled(sbs) {
+		newStatus.Autoscaling = nil
+		return 0, false
+	}
+	minReplicas := sbs.Spec.Replicas
+	maxReplicas := max(sbs.Spec.Autoscaling.MaxReplicas, minReplicas)
+	wanted := min(max(pending, minReplicas), maxReplicas)
+
+	last := newStatus.Autoscaling
+	if last == nil {
+		last = &agentsv1alpha1.SandboxSetAutoscalingStatus{DesiredReplicas: minReplicas}
+	}
+	current := min(max(last.DesiredReplicas, minReplicas), maxReplicas)
+	status := &agentsv1alpha1.SandboxSetAutoscalingStatus{
+		DesiredReplicas: current,
+		PendingReplicas: pending,
+		LastScaleUpTime: last.LastScaleUpTime,
+	}
+	var cooldownLeft time.Duration
+	switch {
+	case wanted >= current:
+		status.DesiredReplicas = wanted
+		if wanted > minReplicas {
+			// the cooldown starts over as long as the claims keep the pool at its size
+			status.LastScaleUpTime = &metav1.Time{Time: now}
+		}
+	case status.LastScaleUpTime == nil:
+		status.DesiredReplicas = wanted
+	default:
+		cooldown := defaultScaleDownCooldown
+		if sbs.Spec.Autoscaling.ScaleDownCooldown != nil {
+			cooldown = sbs.Spec.Autoscaling.ScaleDownCooldown.Duration
+		}
+		if cooldownLeft = status.LastScaleUpTime.Add(cooldown).Sub(now); cooldownLeft <= 0 {
+			status.DesiredReplicas, cooldownLeft = wanted, 0
+		}
+	}
+	newStatus.Autoscaling = status
+	return cooldownLeft, status.DesiredReplicas != last.DesiredReplicas
+}
+
+// countPendingReplicas sums the sandboxes still needed by the queued and claiming SandboxClaims of the SandboxSet,
+// dry-run and paused claims need none. No claims are pending if the SandboxClaim CRD is not installed.
+func (r *Reconciler) countPendingReplicas(ctx context.Context, sbs *agentsv1alpha1.SandboxSet) (int32, error) {
+	claims := &agentsv1alpha1.SandboxClaimList{}
+	if err := r.List(ctx, claims, client.InNamespace(sbs.Namespace), client.UnsafeDisableDeepCopy); err != nil {
+		if meta.IsNoMatchError(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	var pending int32
+	for i := range claims.Items {
+		claim := &claims.Items[i]
+		if sandboxutils.ClaimSandboxSetName(claim) != sbs.Name || claim.Spec.DryRun || claim.Spec.Paused || !claim.DeletionTimestamp.IsZero() {
+			continue
+		}
+		switch claim.Status.Phase {
+		case agentsv1alpha1.SandboxClaimPhaseQueued, agentsv1alpha1.SandboxClaimPhaseClaiming:
+			replicas := int32(1)
+			if claim.Spec.Replicas != nil {
+				replicas = *claim.Spec.Replicas
+			}
+			pending += max(replicas-claim.Status.ClaimedReplicas, 0)
+		}
+	}
+	return pending, nil
+}
+
+// mapSandboxClaimToSandboxSet enqueues the autoscaled SandboxSet the SandboxClaim claims from
+func (r *Reconciler) mapSandboxClaimToSandboxSet(ctx context.Context, obj client.Object) []reconcile.Request {
+	claim, ok := obj.(*agentsv1alpha1.SandboxClaim)
+	if !ok {
+		return nil
+	}
+	name := sandboxutils.ClaimSandboxSetName(claim)
+	if name == "" {
+		return nil
+	}
+	key := types.NamespacedName{Namespace: claim.Namespace, Name: name}
+	sbs := &agentsv1alpha1.SandboxSet{}
+	if err := r.Get(ctx, key, sbs); err != nil {
+		if client.IgnoreNotFound(err) != nil {
+			logf.FromContext(ctx).Error(err, "failed to get sandboxset of claim", "claim", klog.KObj(claim))
+		}
+		return nil
+	}
+	if sbs.Spec.Autoscaling == nil {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: key}}
+}
+
+// claimPressureChangedPredicate passes the events of SandboxClaims changing the replicas they still need
+var claimPressureChangedPredicate = predicate.Funcs{
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		oldClaim, ok := e.ObjectOld.(*agentsv1alpha1.SandboxClaim)
+		if !ok {
+			return false
+		}
+		newClaim, ok := e.ObjectNew.(*agentsv1alpha1.SandboxClaim)
+		if !ok {
+			return false
+		}
+		return oldClaim.Status.Phase != newClaim.Status.Phase ||
+			oldClaim.Status.ClaimedReplicas != newClaim.Status.ClaimedReplicas ||
+			oldClaim.Status.SandboxSetName != newClaim.Status.SandboxSetName ||
+			oldClaim.Spec.Paused != newClaim.Spec.Paused ||
+			!ptr.Equal(oldClaim.Spec.Replicas, newClaim.Spec.Replicas)
+	},
+}
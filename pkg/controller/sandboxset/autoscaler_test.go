@@ -0,0 +1,219 @@
+package sandboxset
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+	"github.com/openkruise/agents/pkg/features"
+	utilfeature "github.com/openkruise/agents/pkg/utils/feature"
+)
+
+func TestCalculateAutoscaling(t *testing.T) {
+	require.NoError(t, utilfeature.DefaultMutableFeatureGate.Set(fmt.Sprintf("%s=true", features.SandboxSetAutoscalingGate)))
+	defer func() {
+		_ = utilfeature.DefaultMutableFeatureGate.Set(fmt.Sprintf("%s=false", features.SandboxSetAutoscalingGate))
+	}()
+	now := time.Now()
+	scaledUpAt := func(ago time.Duration) *metav1.Time {
+		return &metav1.Time{Time: now.Add(-ago)}
+	}
+
+	tests := []struct {
+		name          string
+		autoscaling   *agentsv1alpha1.SandboxSetAutoscaling
+		lastStatus    *agentsv1alpha1.SandboxSetAutoscalingStatus
+		pending       int32
+		expectDesired int32
+		expectChanged bool
+		expectRequeue time.Duration
+		expectNil     bool
+	}{
+		{
+			name:          "no pressure keeps spec.replicas",
+			autoscaling:   &agentsv1alpha1.SandboxSetAutoscaling{MaxReplicas: 10},
+			pending:       1,
+			expectDesired: 2,
+		},
+		{
+			name:          "scale up to the pending replicas",
+			autoscaling:   &agentsv1alpha1.SandboxSetAutoscaling{MaxReplicas: 10},
+			pending:       6,
+			expectDesired: 6,
+			expectChanged: true,
+		},
+		{
+			name:          "scale up is capped by maxReplicas",
+			autoscaling:   &agentsv1alpha1.SandboxSetAutoscaling{MaxReplicas: 10},
+			pending:       20,
+			expectDesired: 10,
+			expectChanged: true,
+		},
+		{
+			name:          "scale down waits for the cooldown",
+			autoscaling:   &agentsv1alpha1.SandboxSetAutoscaling{MaxReplicas: 10},
+			lastStatus:    &agentsv1alpha1.SandboxSetAutoscalingStatus{DesiredReplicas: 6, LastScaleUpTime: scaledUpAt(time.Minute)},
+			pending:       0,
+			expectDesired: 6,
+			expectRequeue: 4 * time.Minute,
+		},
+		{
+			name: "scale down after the custom cooldown",
+			autoscaling: &agentsv1alpha1.SandboxSetAutoscaling{
+				MaxReplicas: 10, ScaleDownCooldown: &metav1.Duration{Duration: 30 * time.Second},
+			},
+			lastStatus:    &agentsv1alpha1.SandboxSetAutoscalingStatus{DesiredReplicas: 6, LastScaleUpTime: scaledUpAt(time.Minute)},
+			pending:       3,
+			expectDesired: 3,
+			expectChanged: true,
+		},
+		{
+			name:          "lowered maxReplicas applies right away",
+			autoscaling:   &agentsv1alpha1.SandboxSetAutoscaling{MaxReplicas: 4},
+			lastStatus:    &agentsv1alpha1.SandboxSetAutoscalingStatus{DesiredReplicas: 6, LastScaleUpTime: scaledUpAt(time.Minute)},
+			pending:       0,
+			expectDesired: 4,
+			expectChanged: true,
+			expectRequeue: 4 * time.Minute,
+		},
+		{
+			name:       "autoscaling removed",
+			lastStatus: &agentsv1alpha1.SandboxSetAutoscalingStatus{DesiredReplicas: 6},
+			expectNil:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sbs := getSandboxSet(2)
+			sbs.Spec.Autoscaling = tt.autoscaling
+			newStatus := &agentsv1alpha1.SandboxSetStatus{Autoscaling: tt.lastStatus}
+			requeueAfter, changed := calculateAutoscaling(newStatus, sbs, tt.pending, now)
+			if tt.expectNil {
+				assert.Nil(t, newStatus.Autoscaling)
+				assert.Equal(t, sbs.Spec.Replicas, targetReplicas(sbs, newStatus))
+				return
+			}
+			require.NotNil(t, newStatus.Autoscaling)
+			assert.Equal(t, tt.expectDesired, newStatus.Autoscaling.DesiredReplicas)
+			assert.Equal(t, tt.expectDesired, targetReplicas(sbs, newStatus))
+			assert.Equal(t, tt.pending, newStatus.Autoscaling.PendingReplicas)
+			assert.Equal(t, tt.expectChanged, changed)
+			assert.Equal(t, tt.expectRequeue, requeueAfter)
+		})
+	}
+}
+
+func TestSetSandboxSetReadyConditionWithAutoscaling(t *testing.T) {
+	require.NoError(t, utilfeature.DefaultMutableFeatureGate.Set(fmt.Sprintf("%s=true", features.SandboxSetAutoscalingGate)))
+	defer func() {
+		_ = utilfeature.DefaultMutableFeatureGate.Set(fmt.Sprintf("%s=false", features.SandboxSetAutoscalingGate))
+	}()
+
+	tests := []struct {
+		name          string
+		minAvailable  int32
+		desired       int32
+		available     int32
+		expectStatus  metav1.ConditionStatus
+		expectMessage string
+	}{
+		{
+			name:          "autoscaled above minAvailable is not ready",
+			minAvailable:  5,
+			desired:       12,
+			available:     5,
+			expectStatus:  metav1.ConditionFalse,
+			expectMessage: "5/12 replicas available",
+		},
+		{
+			name:          "autoscaled above minAvailable is ready",
+			minAvailable:  5,
+			desired:       12,
+			available:     12,
+			expectStatus:  metav1.ConditionTrue,
+			expectMessage: "12/12 replicas available",
+		},
+		{
+			name:          "minAvailable above autoscaled",
+			minAvailable:  8,
+			desired:       3,
+			available:     3,
+			expectStatus:  metav1.ConditionFalse,
+			expectMessage: "3/8 replicas available",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sbs := getSandboxSet(2)
+			sbs.Spec.MinAvailable = tt.minAvailable
+			sbs.Spec.Autoscaling = &agentsv1alpha1.SandboxSetAutoscaling{MaxReplicas: 20}
+			newStatus := &agentsv1alpha1.SandboxSetStatus{
+				AvailableReplicas: tt.available,
+				Autoscaling:       &agentsv1alpha1.SandboxSetAutoscalingStatus{DesiredReplicas: tt.desired},
+			}
+			setSandboxSetReadyCondition(newStatus, sbs)
+			cond := meta.FindStatusCondition(newStatus.Conditions, string(agentsv1alpha1.SandboxSetConditionReady))
+			require.NotNil(t, cond)
+			assert.Equal(t, tt.expectStatus, cond.Status)
+			assert.Equal(t, tt.expectMessage, cond.Message)
+		})
+	}
+}
+
+func TestCountPendingReplicas(t *testing.T) {
+	newClaim := func(name, template string, phase agentsv1alpha1.SandboxClaimPhase, replicas *int32, claimed int32) *agentsv1alpha1.SandboxClaim {
+		return &agentsv1alpha1.SandboxClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec:       agentsv1alpha1.SandboxClaimSpec{TemplateName: template, Replicas: replicas},
+			Status:     agentsv1alpha1.SandboxClaimStatus{Phase: phase, ClaimedReplicas: claimed},
+		}
+	}
+	dryRun := newClaim("dry-run", "test", agentsv1alpha1.SandboxClaimPhaseClaiming, ptr.To[int32](5), 0)
+	dryRun.Spec.DryRun = true
+	paused := newClaim("paused", "test", agentsv1alpha1.SandboxClaimPhaseClaiming, ptr.To[int32](5), 0)
+	paused.Spec.Paused = true
+	selected := newClaim("selected", "", agentsv1alpha1.SandboxClaimPhaseQueued, ptr.To[int32](2), 0)
+	selected.Status.SandboxSetName = "test"
+	k8sClient := fake.NewClientBuilder().WithScheme(testScheme).WithObjects(
+		newClaim("claiming", "test", agentsv1alpha1.SandboxClaimPhaseClaiming, ptr.To[int32](3), 1),
+		newClaim("single", "test", agentsv1alpha1.SandboxClaimPhaseQueued, nil, 0),
+		newClaim("completed", "test", agentsv1alpha1.SandboxClaimPhaseCompleted, ptr.To[int32](3), 1),
+		newClaim("other", "other", agentsv1alpha1.SandboxClaimPhaseClaiming, ptr.To[int32](3), 0),
+		dryRun,
+		paused,
+		selected,
+	).Build()
+	reconciler := &Reconciler{Client: k8sClient, Scheme: testScheme}
+
+	pending, err := reconciler.countPendingReplicas(context.Background(), getSandboxSet(1))
+	require.NoError(t, err)
+	assert.Equal(t, int32(5), pending)
+}
+
+func TestClaimPressureChangedPredicate(t *testing.T) {
+	claim := &agentsv1alpha1.SandboxClaim{
+		Spec:   agentsv1alpha1.SandboxClaimSpec{TemplateName: "test", Replicas: ptr.To[int32](3)},
+		Status: agentsv1alpha1.SandboxClaimStatus{Phase: agentsv1alpha1.SandboxClaimPhaseClaiming, ClaimedReplicas: 1},
+	}
+	relabeled := claim.DeepCopy()
+	relabeled.Labels = map[string]string{"foo": "bar"}
+	claimed := claim.DeepCopy()
+	claimed.Status.ClaimedReplicas = 2
+	resized := claim.DeepCopy()
+	resized.Spec.Replicas = ptr.To[int32](5)
+
+	assert.False(t, claimPressureChangedPredicate.Update(event.UpdateEvent{ObjectOld: claim, ObjectNew: relabeled}))
+	assert.True(t, claimPressureChangedPredicate.Update(event.UpdateEvent{ObjectOld: claim, ObjectNew: claimed}))
+	assert.True(t, claimPressureChangedPredicate.Update(event.UpdateEvent{ObjectOld: claim, ObjectNew: resized}))
+	assert.True(t, claimPressureChangedPredicate.Create(event.CreateEvent{Object: claim}))
+}
@@ -17,7 +17,9 @@ import (
 	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
 	"github.com/openkruise/agents/pkg/utils"
 	"github.com/openkruise/agents/pkg/utils/expectations"
+	"github.com/openkruise/agents/pkg/utils/rightsizing"
 	stateutils "github.com/openkruise/agents/pkg/utils/sandboxutils"
+	"github.com/openkruise/agents/pkg/utils/slo"
 )
 
 type SandboxEventHandler struct{}
@@ -33,9 +35,13 @@ func (e *SandboxEventHandler) Update(ctx context.Context, evt event.TypedUpdateE
 	if evt.ObjectOld == nil || evt.ObjectNew == nil {
 		return
 	}
-	req, ok := getSandboxSetController(evt.ObjectOld)
-	if !ok {
-		return
+	req, owned := getSandboxSetController(evt.ObjectOld)
+	if !owned {
+		// claimed sandboxes are counted in status.claimedReplicas of the pool they came from
+		var ok bool
+		if req, ok = getClaimedSandboxPool(evt.ObjectNew); !ok {
+			return
+		}
 	}
 	oldSbx, ok := evt.ObjectOld.(*agentsv1alpha1.Sandbox)
 	if !ok {
@@ -50,6 +56,20 @@ func (e *SandboxEventHandler) Update(ctx context.Context, evt event.TypedUpdateE
 	if oldState != newState {
 		w.Add(req)
 	}
+	if pool, claimed := getClaimedSandboxPool(evt.ObjectNew); claimed {
+		if _, wasClaimed := getClaimedSandboxPool(evt.ObjectOld); !wasClaimed {
+			rightsizing.Default.ObserveClaim(pool.Namespace, pool.Name, time.Now())
+		}
+	}
+	if owned && oldState == agentsv1alpha1.SandboxStateCreating &&
+		(newState == agentsv1alpha1.SandboxStateAvailable || newState == agentsv1alpha1.SandboxStateDead) {
+		slo.Default.ObserveWarmup(req.Namespace, req.Name, newState == agentsv1alpha1.SandboxStateAvailable, time.Now())
+	}
+	if oldSbx.Status.Phase == agentsv1alpha1.SandboxResuming && newSbx.Status.Phase == agentsv1alpha1.SandboxRunning {
+		if latency, ok := stateutils.ResumeLatency(newSbx); ok {
+			slo.Default.ObserveResume(req.Namespace, req.Name, latency, time.Now())
+		}
+	}
 	if oldState == agentsv1alpha1.SandboxStateCreating && newState == agentsv1alpha1.SandboxStateAvailable {
 		cond := utils.GetSandboxCondition(&newSbx.Status, string(agentsv1alpha1.SandboxConditionReady))
 		var afterReady, readyCost, totalCost time.Duration
@@ -68,6 +88,11 @@ func (e *SandboxEventHandler) Delete(_ context.Context, evt event.TypedDeleteEve
 	if req, ok := getSandboxSetController(evt.Object); ok {
 		scaleDownExpectation.ObserveScale(req.String(), expectations.Delete, evt.Object.GetName())
 		w.Add(req)
+	} else if req, ok = getClaimedSandboxPool(evt.Object); ok {
+		w.Add(req)
+		if claimed, err := time.Parse(time.RFC3339, evt.Object.GetAnnotations()[agentsv1alpha1.AnnotationClaimTime]); err == nil {
+			rightsizing.Default.ObserveRelease(req.Namespace, req.Name, time.Since(claimed), time.Now())
+		}
 	}
 }
 
@@ -99,3 +124,20 @@ func getSandboxSetController(obj metav1.Object) (reconcile.Request, bool) {
 	}
 	return req, true
 }
+
+func getClaimedSandboxPool(obj metav1.Object) (reconcile.Request, bool) {
+	if obj == nil {
+		return reconcile.Request{}, false
+	}
+	labels := obj.GetLabels()
+	pool := labels[agentsv1alpha1.LabelSandboxPool]
+	if pool == "" || labels[agentsv1alpha1.LabelSandboxIsClaimed] != agentsv1alpha1.True {
+		return reconcile.Request{}, false
+	}
+	return reconcile.Request{
+		NamespacedName: types.NamespacedName{
+			Namespace: obj.GetNamespace(),
+			Name:      pool,
+		},
+	}, true
+}
@@ -32,9 +32,18 @@ var (
 		},
 		[]string{"namespace", "name"},
 	)
+
+	// SandboxSetClaimedReplicas tracks the number of alive sandboxes claimed from each SandboxSet
+	SandboxSetClaimedReplicas = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "sandboxset_claimed_replicas",
+			Help: "Current number of alive sandboxes claimed from the SandboxSet",
+		},
+		[]string{"namespace", "name"},
+	)
 )
 
 func init() {
 	// Register custom metrics with the global prometheus registry
-	metrics.Registry.MustRegister(SandboxSetReplicas, SandboxSetAvailableReplicas, SandboxSetDesiredReplicas)
+	metrics.Registry.MustRegister(SandboxSetReplicas, SandboxSetAvailableReplicas, SandboxSetDesiredReplicas, SandboxSetClaimedReplicas)
 }
@@ -0,0 +1,307 @@
+package sandboxset
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+	"github.com/openkruise/agents/pkg/features"
+	utilfeature "github.com/openkruise/agents/pkg/utils/feature"
+)
+
+// nodePoolsEnabled reports whether the sandboxes of the SandboxSet are distributed across its node pools
+func nodePoolsEnabled(sbs *agentsv1alpha1.SandboxSet) bool {
+	return len(sbs.Spec.NodePools) > 0 && utilfeature.DefaultFeatureGate.Enabled(features.SandboxSetNodePoolsGate)
+}
+
+// isNodeReady reports whether new pods can be scheduled to the node
+func isNodeReady(node *corev1.Node) bool {
+	if node.Spec.Unschedulable {
+		return false
+	}
+	for _, cond := range node.Status.Conditions {
+		if cond.Type == corev1.NodeReady {
+			return cond.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// countReadyNodes counts the ready nodes selected by each node pool
+func (r *Reconciler) countReadyNodes(ctx context.Context, pools []agentsv1alpha1.SandboxSetNodePool) (map[string]int32, error) {
+	nodeList := &corev1.NodeList{}
+	if err := r.List(ctx, nodeList); err != nil {
+		return nil, err
+	}
+	counts := make(map[string]int32, len(pools))
+	for i := range nodeList.Items {
+		node := &nodeList.Items[i]
+		if !isNodeReady(node) {
+			continue
+		}
+		for _, pool := range pools {
+			if labels.SelectorFromSet(pool.NodeSelector).Matches(labels.Set(node.Labels)) {
+				counts[pool.Name]++
+			}
+		}
+	}
+	return counts, nil
+}
+
+// distributeNodePools calculates the desired replicas of each node pool. The minReplicas of the pools are kept in
+// their order, and the rest is split by weight across the pools with ready nodes, or across all pools if none has.
+// Pools whose weighted share falls below their minReplicas keep the minimum, and the others share the rest.
+func distributeNodePools(pools []agentsv1alpha1.SandboxSetNodePool, replicas int32, readyNodes map[string]int32) []int32 {
+	mins := make([]int32, len(pools))
+	left := replicas
+	anyReady := false
+	for i, pool := range pools {
+		mins[i] = max(min(pool.MinReplicas, left), 0)
+		left -= mins[i]
+		if pool.Weight > 0 && readyNodes[pool.Name] > 0 {
+			anyReady = true
+		}
+	}
+	weights := make([]int64, len(pools))
+	for i, pool := range pools {
+		if !anyReady || readyNodes[pool.Name] > 0 {
+			weights[i] = int64(pool.Weight)
+		}
+	}
+	fixed := make([]bool, len(pools))
+	for {
+		total := replicas
+		free := make([]int64, len(pools))
+		for i := range pools {
+			if fixed[i] {
+				total -= mins[i]
+			} else {
+				free[i] = weights[i]
+			}
+		}
+		shares := splitByWeight(total, free)
+		changed := false
+		for i := range pools {
+			if !fixed[i] && shares[i] < mins[i] {
+				fixed[i], changed = true, true
+			}
+		}
+		if changed {
+			continue
+		}
+		for i := range pools {
+			if fixed[i] {
+				shares[i] = mins[i]
+			}
+		}
+		return shares
+	}
+}
+
+// splitByWeight splits the total by weight with the largest remainder method, ties broken by order
+func splitByWeight(total int32, weights []int64) []int32 {
+	shares := make([]int32, len(weights))
+	var sum int64
+	for _, w := range weights {
+		sum += w
+	}
+	if sum == 0 || total <= 0 {
+		return shares
+	}
+	remainders := make([]int64, len(weights))
+	assigned := int32(0)
+	for i, w := range weights {
+		shares[i] = int32(int64(total) * w / sum)
+		remainders[i] = int64(total) * w % sum
+		assigned += shares[i]
+	}
+	order := make([]int, len(weights))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return remainders[order[a]] > remainders[order[b]]
+	})
+	for _, i := range order[:total-assigned] {
+		shares[i]++
+	}
+	return shares
+}
+
+// calculateNodePoolStatus reports the distribution of the unclaimed sandboxes across the node pools
+func calculateNodePoolStatus(newStatus *agentsv1alpha1.SandboxSetStatus, sbs *agentsv1alpha1.SandboxSet,
+	groups GroupedSandboxes, readyNodes map[string]int32) {
+	if !nodePoolsEnabled(sbs) {
+		newStatus.NodePools = nil
+		return
+	}
+	desired := distributeNodePools(sbs.Spec.NodePools, targetReplicas(sbs, newStatus), readyNodes)
+	statuses := make([]agentsv1alpha1.SandboxSetNodePoolStatus, len(sbs.Spec.NodePools))
+	index := make(map[string]int, len(statuses))
+	for i, pool := range sbs.Spec.NodePools {
+		statuses[i] = agentsv1alpha1.SandboxSetNodePoolStatus{
+			Name:            pool.Name,
+			DesiredReplicas: desired[i],
+			ReadyNodes:      readyNodes[pool.Name],
+		}
+		index[pool.Name] = i
+	}
+	for _, sbx := range groups.Creating {
+		if i, ok := index[sbx.Labels[agentsv1alpha1.LabelSandboxNodePool]]; ok {
+			statuses[i].Replicas++
+		}
+	}
+	for _, sbx := range groups.Available {
+		if i, ok := index[sbx.Labels[agentsv1alpha1.LabelSandboxNodePool]]; ok {
+			statuses[i].Replicas++
+			statuses[i].AvailableReplicas++
+		}
+	}
+	newStatus.NodePools = statuses
+}
+
+// calculateRebalance calculates how many sandboxes to create in the node pools below their desired replicas, so that
+// as many sandboxes of the pools above it, or of no pool, are scaled down afterward.
+func calculateRebalance(newStatus *agentsv1alpha1.SandboxSetStatus) int {
+	var deficit, surplus, placed int32
+	for _, pool := range newStatus.NodePools {
+		if pool.Replicas < pool.DesiredReplicas {
+			deficit += pool.DesiredReplicas - pool.Replicas
+		} else {
+			surplus += pool.Replicas - pool.DesiredReplicas
+		}
+		placed += pool.Replicas
+	}
+	// sandboxes created before the node pools, or of removed ones
+	surplus += max(newStatus.Replicas-placed, 0)
+	return int(min(deficit, surplus))
+}
+
+// planNodePools picks the node pools of the sandboxes to create, each in the pool furthest below its desired
+// replicas. No pool is picked if the sandboxes are not distributed.
+func planNodePools(newStatus *agentsv1alpha1.SandboxSetStatus, count int) []string {
+	plan := make([]string, count)
+	pools := newStatus.NodePools
+	if len(pools) == 0 {
+		return plan
+	}
+	deficits := make([]int32, len(pools))
+	for i, pool := range pools {
+		deficits[i] = pool.DesiredReplicas - pool.Replicas
+	}
+	for n := range plan {
+		best := 0
+		for i := range deficits {
+			if deficits[i] > deficits[best] {
+				best = i
+			}
+		}
+		plan[n] = pools[best].Name
+		deficits[best]--
+	}
+	return plan
+}
+
+// orderForScaleDown orders the unclaimed sandboxes to scale down, taking them from the node pools furthest above
+// their desired replicas first. Sandboxes of no pool are not desired at all. The order within a pool is kept.
+func orderForScaleDown(candidates []*agentsv1alpha1.Sandbox, newStatus *agentsv1alpha1.SandboxSetStatus) []*agentsv1alpha1.Sandbox {
+	if len(newStatus.NodePools) == 0 {
+		return candidates
+	}
+	byPool := map[string][]*agentsv1alpha1.Sandbox{}
+	for _, sbx := range candidates {
+		pool := sbx.Labels[agentsv1alpha1.LabelSandboxNodePool]
+		byPool[pool] = append(byPool[pool], sbx)
+	}
+	surplus := map[string]int32{}
+	for pool, sandboxes := range byPool {
+		surplus[pool] = int32(len(sandboxes))
+	}
+	for _, pool := range newStatus.NodePools {
+		if _, ok := byPool[pool.Name]; ok {
+			surplus[pool.Name] = pool.Replicas - pool.DesiredReplicas
+		}
+	}
+	poolNames := slices.Sorted(maps.Keys(byPool))
+	ordered := make([]*agentsv1alpha1.Sandbox, 0, len(candidates))
+	for len(ordered) < len(candidates) {
+		best := ""
+		found := false
+		for _, pool := range poolNames {
+			if len(byPool[pool]) > 0 && (!found || surplus[pool] > surplus[best]) {
+				best, found = pool, true
+			}
+		}
+		ordered = append(ordered, byPool[best][0])
+		byPool[best] = byPool[best][1:]
+		surplus[best]--
+	}
+	return ordered
+}
+
+// placeInNodePool places the sandbox in the node pool by merging its nodeSelector and tolerations into the pod
+// template of the sandbox.
+func placeInNodePool(sbx *agentsv1alpha1.Sandbox, pools []agentsv1alpha1.SandboxSetNodePool, name string) error {
+	idx := slices.IndexFunc(pools, func(pool agentsv1alpha1.SandboxSetNodePool) bool {
+		return pool.Name == name
+	})
+	if idx < 0 {
+		return fmt.Errorf("node pool %s not found", name)
+	}
+	if sbx.Spec.Template == nil {
+		return fmt.Errorf("node pools require template or templateOverlay")
+	}
+	pool := pools[idx]
+	spec := &sbx.Spec.Template.Spec
+	if spec.NodeSelector == nil {
+		spec.NodeSelector = make(map[string]string, len(pool.NodeSelector))
+	}
+	maps.Copy(spec.NodeSelector, pool.NodeSelector)
+	spec.Tolerations = append(spec.Tolerations, pool.Tolerations...)
+	sbx.Labels[agentsv1alpha1.LabelSandboxNodePool] = name
+	return nil
+}
+
+// nodeChangedPredicate passes the changes of nodes that may change the ready nodes of node pools
+var nodeChangedPredicate = predicate.Funcs{
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		oldNode, ok := e.ObjectOld.(*corev1.Node)
+		if !ok {
+			return false
+		}
+		newNode, ok := e.ObjectNew.(*corev1.Node)
+		if !ok {
+			return false
+		}
+		return isNodeReady(oldNode) != isNodeReady(newNode) || !maps.Equal(oldNode.Labels, newNode.Labels)
+	},
+}
+
+// mapNodeToSandboxSets enqueues the SandboxSets with node pools, so that they are rebalanced as nodes come and go.
+func (r *Reconciler) mapNodeToSandboxSets(ctx context.Context, obj client.Object) []reconcile.Request {
+	sbsList := &agentsv1alpha1.SandboxSetList{}
+	if err := r.List(ctx, sbsList); err != nil {
+		logf.FromContext(ctx).Error(err, "failed to list sandboxsets", "node", klog.KObj(obj))
+		return nil
+	}
+	var requests []reconcile.Request
+	for i := range sbsList.Items {
+		sbs := &sbsList.Items[i]
+		if len(sbs.Spec.NodePools) > 0 {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(sbs)})
+		}
+	}
+	return requests
+}
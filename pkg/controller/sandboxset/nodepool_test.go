@@ -0,0 +1,245 @@
+package sandboxset
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+	"github.com/openkruise/agents/pkg/features"
+	utilfeature "github.com/openkruise/agents/pkg/utils/feature"
+)
+
+func newNodePool(name string, weight, minReplicas int32) agentsv1alpha1.SandboxSetNodePool {
+	return agentsv1alpha1.SandboxSetNodePool{
+		Name:         name,
+		NodeSelector: map[string]string{"capacity-type": name},
+		Weight:       weight,
+		MinReplicas:  minReplicas,
+	}
+}
+
+func newPoolNode(name, pool string, ready bool) *corev1.Node {
+	status := corev1.ConditionTrue
+	if !ready {
+		status = corev1.ConditionFalse
+	}
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"capacity-type": pool}},
+		Status: corev1.NodeStatus{
+			Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: status}},
+		},
+	}
+}
+
+func TestDistributeNodePools(t *testing.T) {
+	tests := []struct {
+		name       string
+		pools      []agentsv1alpha1.SandboxSetNodePool
+		replicas   int32
+		readyNodes map[string]int32
+		expect     []int32
+	}{
+		{
+			name:       "split by weight",
+			pools:      []agentsv1alpha1.SandboxSetNodePool{newNodePool("spot", 7, 0), newNodePool("on-demand", 3, 0)},
+			replicas:   10,
+			readyNodes: map[string]int32{"spot": 2, "on-demand": 1},
+			expect:     []int32{7, 3},
+		},
+		{
+			name:       "largest remainder",
+			pools:      []agentsv1alpha1.SandboxSetNodePool{newNodePool("a", 1, 0), newNodePool("b", 1, 0), newNodePool("c", 1, 0)},
+			replicas:   5,
+			readyNodes: map[string]int32{"a": 1, "b": 1, "c": 1},
+			expect:     []int32{2, 2, 1},
+		},
+		{
+			name:       "minimum above the weighted share",
+			pools:      []agentsv1alpha1.SandboxSetNodePool{newNodePool("spot", 7, 0), newNodePool("on-demand", 3, 5)},
+			replicas:   10,
+			readyNodes: map[string]int32{"spot": 2, "on-demand": 1},
+			expect:     []int32{5, 5},
+		},
+		{
+			name:       "pool without ready nodes keeps its minimum only",
+			pools:      []agentsv1alpha1.SandboxSetNodePool{newNodePool("spot", 7, 2), newNodePool("on-demand", 3, 0)},
+			replicas:   10,
+			readyNodes: map[string]int32{"on-demand": 1},
+			expect:     []int32{2, 8},
+		},
+		{
+			name:       "no pool has ready nodes",
+			pools:      []agentsv1alpha1.SandboxSetNodePool{newNodePool("spot", 7, 0), newNodePool("on-demand", 3, 0)},
+			replicas:   10,
+			readyNodes: map[string]int32{},
+			expect:     []int32{7, 3},
+		},
+		{
+			name:       "minimums limited by replicas in order",
+			pools:      []agentsv1alpha1.SandboxSetNodePool{newNodePool("spot", 1, 3), newNodePool("on-demand", 1, 3)},
+			replicas:   4,
+			readyNodes: map[string]int32{"spot": 1, "on-demand": 1},
+			expect:     []int32{3, 1},
+		},
+		{
+			name:       "no replicas",
+			pools:      []agentsv1alpha1.SandboxSetNodePool{newNodePool("spot", 7, 1), newNodePool("on-demand", 3, 0)},
+			replicas:   0,
+			readyNodes: map[string]int32{"spot": 1, "on-demand": 1},
+			expect:     []int32{0, 0},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expect, distributeNodePools(tt.pools, tt.replicas, tt.readyNodes))
+		})
+	}
+}
+
+func TestPlanNodePools(t *testing.T) {
+	status := &agentsv1alpha1.SandboxSetStatus{
+		Replicas: 6,
+		NodePools: []agentsv1alpha1.SandboxSetNodePoolStatus{
+			{Name: "spot", DesiredReplicas: 7, Replicas: 4},
+			{Name: "on-demand", DesiredReplicas: 3, Replicas: 2},
+		},
+	}
+	assert.Equal(t, []string{"spot", "spot", "spot", "on-demand", "spot"}, planNodePools(status, 5))
+	assert.Equal(t, []string{"", ""}, planNodePools(&agentsv1alpha1.SandboxSetStatus{}, 2))
+	// 6 placed, 2 more of no pool, and 3 missing in spot
+	status.Replicas = 8
+	assert.Equal(t, 2, calculateRebalance(status))
+}
+
+func TestOrderForScaleDown(t *testing.T) {
+	newPoolSandbox := func(name, pool string) *agentsv1alpha1.Sandbox {
+		sbx := &agentsv1alpha1.Sandbox{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{}}}
+		if pool != "" {
+			sbx.Labels[agentsv1alpha1.LabelSandboxNodePool] = pool
+		}
+		return sbx
+	}
+	candidates := []*agentsv1alpha1.Sandbox{
+		newPoolSandbox("spot-1", "spot"),
+		newPoolSandbox("legacy-1", ""),
+		newPoolSandbox("on-demand-1", "on-demand"),
+		newPoolSandbox("spot-2", "spot"),
+		newPoolSandbox("spot-3", "spot"),
+		newPoolSandbox("on-demand-2", "on-demand"),
+	}
+	status := &agentsv1alpha1.SandboxSetStatus{
+		NodePools: []agentsv1alpha1.SandboxSetNodePoolStatus{
+			{Name: "spot", DesiredReplicas: 1, Replicas: 3},
+			{Name: "on-demand", DesiredReplicas: 2, Replicas: 2},
+		},
+	}
+	var names []string
+	for _, sbx := range orderForScaleDown(candidates, status) {
+		names = append(names, sbx.Name)
+	}
+	assert.Equal(t, []string{"spot-1", "legacy-1", "spot-2", "on-demand-1", "spot-3", "on-demand-2"}, names)
+	assert.Equal(t, candidates, orderForScaleDown(candidates, &agentsv1alpha1.SandboxSetStatus{}))
+}
+
+func TestNodeChangedPredicate(t *testing.T) {
+	ready := newPoolNode("node", "spot", true)
+	heartbeat := ready.DeepCopy()
+	heartbeat.Status.Conditions[0].LastHeartbeatTime = metav1.Now()
+	cordoned := ready.DeepCopy()
+	cordoned.Spec.Unschedulable = true
+	relabeled := ready.DeepCopy()
+	relabeled.Labels["capacity-type"] = "on-demand"
+
+	assert.False(t, nodeChangedPredicate.Update(event.UpdateEvent{ObjectOld: ready, ObjectNew: heartbeat}))
+	assert.True(t, nodeChangedPredicate.Update(event.UpdateEvent{ObjectOld: ready, ObjectNew: cordoned}))
+	assert.True(t, nodeChangedPredicate.Update(event.UpdateEvent{ObjectOld: ready, ObjectNew: newPoolNode("node", "spot", false)}))
+	assert.True(t, nodeChangedPredicate.Update(event.UpdateEvent{ObjectOld: ready, ObjectNew: relabeled}))
+	assert.True(t, nodeChangedPredicate.Create(event.CreateEvent{Object: ready}))
+}
+
+func TestReconcile_NodePools(t *testing.T) {
+	require.NoError(t, utilfeature.DefaultMutableFeatureGate.Set(fmt.Sprintf("%s=true", features.SandboxSetNodePoolsGate)))
+	defer func() {
+		_ = utilfeature.DefaultMutableFeatureGate.Set(fmt.Sprintf("%s=false", features.SandboxSetNodePoolsGate))
+	}()
+	ctx := context.Background()
+	k8sClient := NewClient()
+	spotNode := newPoolNode("spot-node", "spot", true)
+	require.NoError(t, k8sClient.Create(ctx, spotNode))
+	require.NoError(t, k8sClient.Create(ctx, newPoolNode("on-demand-node", "on-demand", true)))
+	sbs := getSandboxSet(10)
+	sbs.Name, sbs.UID = "pools", "pools-uid"
+	spot := newNodePool("spot", 7, 0)
+	spot.Tolerations = []corev1.Toleration{{Key: "spot", Operator: corev1.TolerationOpExists}}
+	sbs.Spec.NodePools = []agentsv1alpha1.SandboxSetNodePool{spot, newNodePool("on-demand", 3, 1)}
+	require.NoError(t, k8sClient.Create(ctx, sbs))
+	eventRecorder := record.NewFakeRecorder(100)
+	reconciler := &Reconciler{
+		Client:   k8sClient,
+		Scheme:   testScheme,
+		Recorder: eventRecorder,
+		Codec:    codec,
+	}
+	req := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(sbs)}
+	assert.Equal(t, []ctrl.Request{req}, reconciler.mapNodeToSandboxSets(ctx, spotNode))
+
+	countByPool := func() map[string]int {
+		sandboxList := &agentsv1alpha1.SandboxList{}
+		require.NoError(t, k8sClient.List(ctx, sandboxList, client.InNamespace(sbs.Namespace)))
+		counts := map[string]int{}
+		for _, sbx := range sandboxList.Items {
+			pool := sbx.Labels[agentsv1alpha1.LabelSandboxNodePool]
+			counts[pool]++
+			if pool == "spot" {
+				assert.Equal(t, "spot", sbx.Spec.Template.Spec.NodeSelector["capacity-type"])
+				assert.Equal(t, spot.Tolerations, sbx.Spec.Template.Spec.Tolerations)
+			}
+		}
+		return counts
+	}
+	reconcileTimes := func(n int) {
+		for range n {
+			_, err := reconciler.Reconcile(ctx, req)
+			require.NoError(t, err)
+		}
+	}
+
+	reconcileTimes(2)
+	assert.Equal(t, map[string]int{"spot": 7, "on-demand": 3}, countByPool())
+	require.NoError(t, k8sClient.Get(ctx, req.NamespacedName, sbs))
+	assert.Equal(t, []agentsv1alpha1.SandboxSetNodePoolStatus{
+		{Name: "spot", DesiredReplicas: 7, Replicas: 7, ReadyNodes: 1},
+		{Name: "on-demand", DesiredReplicas: 3, Replicas: 3, ReadyNodes: 1},
+	}, sbs.Status.NodePools)
+
+	// spot nodes are gone, the sandboxes are surged into on-demand nodes first and scaled down from spot afterward
+	require.NoError(t, k8sClient.Delete(ctx, spotNode))
+	reconcileTimes(1)
+	assert.Equal(t, map[string]int{"spot": 7, "on-demand": 10}, countByPool())
+	reconcileTimes(2)
+	assert.Equal(t, map[string]int{"on-demand": 10}, countByPool())
+	require.NoError(t, k8sClient.Get(ctx, req.NamespacedName, sbs))
+	assert.Equal(t, []agentsv1alpha1.SandboxSetNodePoolStatus{
+		{Name: "spot", DesiredReplicas: 0, Replicas: 0, ReadyNodes: 0},
+		{Name: "on-demand", DesiredReplicas: 10, Replicas: 10, ReadyNodes: 1},
+	}, sbs.Status.NodePools)
+	rebalanced := 0
+	for len(eventRecorder.Events) > 0 {
+		if evt := <-eventRecorder.Events; strings.HasPrefix(evt, corev1.EventTypeNormal+" "+EventNodePoolsRebalancing) {
+			assert.Contains(t, evt, "Rebalancing 7 sandbox(es)")
+			rebalanced++
+		}
+	}
+	assert.Equal(t, 1, rebalanced)
+}
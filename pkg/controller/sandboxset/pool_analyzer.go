@@ -0,0 +1,97 @@
+package sandboxset
+
+import (
+	"context"
+	"flag"
+	"strconv"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+	"github.com/openkruise/agents/pkg/utils/rightsizing"
+)
+
+func init() {
+	flag.DurationVar(&poolAnalysisInterval, "sandboxset-analysis-interval", poolAnalysisInterval,
+		"Interval of analyzing the claims and occupancy of SandboxSets into right-sizing recommendations annotated on them, 0 to disable.")
+	flag.DurationVar(&poolRefillWindow, "sandboxset-analysis-refill-window", poolRefillWindow,
+		"Time a SandboxSet takes to refill a claimed sandbox, over which its claims are counted to recommend its replicas.")
+}
+
+var (
+	poolAnalysisInterval = 15 * time.Minute
+	poolRefillWindow     = time.Minute
+)
+
+// EventRightSizingRecommended is recorded on SandboxSets whose recommended sizes change
+const EventRightSizingRecommended = "RightSizingRecommended"
+
+// poolAnalyzer periodically recommends the sizes of the SandboxSets from their claims and occupancy observed by the
+// controller, and annotates the recommendations and a report on them, so that teams can right-size over-provisioned
+// warm pools. The recommendations are never applied.
+type poolAnalyzer struct {
+	client.Client
+	recorder record.EventRecorder
+	tracker  *rightsizing.Tracker
+	interval time.Duration
+	refill   time.Duration
+}
+
+func (a *poolAnalyzer) Start(ctx context.Context) error {
+	klog.InfoS("Starting SandboxSet pool analyzer", "interval", a.interval, "refillWindow", a.refill)
+	wait.UntilWithContext(ctx, a.analyze, a.interval)
+	return nil
+}
+
+func (a *poolAnalyzer) analyze(ctx context.Context) {
+	sbsList := &agentsv1alpha1.SandboxSetList{}
+	if err := a.List(ctx, sbsList); err != nil {
+		klog.ErrorS(err, "Failed to list sandboxsets")
+		return
+	}
+	now := time.Now()
+	for i := range sbsList.Items {
+		sbs := &sbsList.Items[i]
+		if !sbs.DeletionTimestamp.IsZero() {
+			continue
+		}
+		rec, ok := a.tracker.Recommend(sbs.Namespace, sbs.Name, a.refill, now)
+		if !ok {
+			continue
+		}
+		annotations := map[string]string{
+			agentsv1alpha1.AnnotationRecommendedReplicas: strconv.Itoa(int(rec.Replicas)),
+			agentsv1alpha1.AnnotationRecommendedStandby:  strconv.Itoa(int(rec.Standby)),
+			agentsv1alpha1.AnnotationRightSizingReport:   rec.Report(a.refill),
+		}
+		if rec.SandboxTTL > 0 {
+			annotations[agentsv1alpha1.AnnotationRecommendedSandboxTTL] = rec.SandboxTTL.String()
+		}
+		changed := sbs.Annotations[agentsv1alpha1.AnnotationRecommendedReplicas] != annotations[agentsv1alpha1.AnnotationRecommendedReplicas] ||
+			sbs.Annotations[agentsv1alpha1.AnnotationRecommendedStandby] != annotations[agentsv1alpha1.AnnotationRecommendedStandby] ||
+			sbs.Annotations[agentsv1alpha1.AnnotationRecommendedSandboxTTL] != annotations[agentsv1alpha1.AnnotationRecommendedSandboxTTL]
+		patch := client.MergeFrom(sbs.DeepCopy())
+		if sbs.Annotations == nil {
+			sbs.Annotations = map[string]string{}
+		}
+		for key, value := range annotations {
+			sbs.Annotations[key] = value
+		}
+		if err := a.Patch(ctx, sbs, patch); err != nil {
+			klog.ErrorS(err, "Failed to annotate right-sizing recommendation of sandboxset", "sandboxset", klog.KObj(sbs))
+			continue
+		}
+		klog.InfoS("Recommended sizes of sandboxset", "sandboxset", klog.KObj(sbs), "replicas", rec.Replicas,
+			"currentReplicas", sbs.Spec.Replicas, "standby", rec.Standby, "sandboxTTL", rec.SandboxTTL, "report", rec.Report(a.refill))
+		if changed {
+			a.recorder.Eventf(sbs, corev1.EventTypeNormal, EventRightSizingRecommended,
+				"Recommended %d replicas (currently %d) and %d standby: %s",
+				rec.Replicas, sbs.Spec.Replicas, rec.Standby, rec.Report(a.refill))
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package sandboxset
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openkruise/agents/api/v1alpha1"
+	"github.com/openkruise/agents/pkg/utils/rightsizing"
+)
+
+func TestPoolAnalyzer_Analyze(t *testing.T) {
+	ctx := context.Background()
+	k8sClient := NewClient()
+	sbs := getSandboxSet(10)
+	sbs.Name, sbs.UID = "analyzed", "analyzed-uid"
+	idle := getSandboxSet(10)
+	idle.Name, idle.UID = "idle", "idle-uid"
+	require.NoError(t, k8sClient.Create(ctx, sbs))
+	require.NoError(t, k8sClient.Create(ctx, idle))
+
+	tracker := rightsizing.NewTracker()
+	now := time.Now()
+	for i := range 30 {
+		tracker.ObserveClaim(sbs.Namespace, sbs.Name, now.Add(-time.Duration(i)*time.Minute))
+	}
+	tracker.ObserveOccupancy(sbs.Namespace, sbs.Name, 8, now.Add(-30*time.Minute))
+	recorder := record.NewFakeRecorder(10)
+	analyzer := &poolAnalyzer{
+		Client:   k8sClient,
+		recorder: recorder,
+		tracker:  tracker,
+		interval: time.Minute,
+		refill:   time.Minute,
+	}
+
+	analyzer.analyze(ctx)
+	require.NoError(t, k8sClient.Get(ctx, client.ObjectKeyFromObject(sbs), sbs))
+	assert.Equal(t, "1", sbs.Annotations[v1alpha1.AnnotationRecommendedReplicas])
+	assert.Equal(t, "0", sbs.Annotations[v1alpha1.AnnotationRecommendedStandby])
+	assert.NotContains(t, sbs.Annotations, v1alpha1.AnnotationRecommendedSandboxTTL, "too few sandboxes are released")
+	assert.Contains(t, sbs.Annotations[v1alpha1.AnnotationRightSizingReport], "at least 8 available at all times")
+	require.Len(t, recorder.Events, 1)
+	assert.Contains(t, <-recorder.Events, "Recommended 1 replicas (currently 10) and 0 standby")
+	require.NoError(t, k8sClient.Get(ctx, client.ObjectKeyFromObject(idle), idle))
+	assert.NotContains(t, idle.Annotations, v1alpha1.AnnotationRecommendedReplicas, "pools without claims are not analyzed")
+
+	// unchanged recommendations are not recorded again
+	analyzer.analyze(ctx)
+	assert.Empty(t, recorder.Events)
+}
@@ -22,10 +22,10 @@ import (
 	"flag"
 	"fmt"
 	"math"
-	"reflect"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -35,22 +35,32 @@ import (
 	"k8s.io/client-go/tools/record"
 	"k8s.io/klog/v2"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
 	"github.com/openkruise/agents/pkg/discovery"
 	"github.com/openkruise/agents/pkg/features"
 	"github.com/openkruise/agents/pkg/sandbox-manager/consts"
 	"github.com/openkruise/agents/pkg/utils"
+	"github.com/openkruise/agents/pkg/utils/controllermetrics"
 	"github.com/openkruise/agents/pkg/utils/expectations"
 	utilfeature "github.com/openkruise/agents/pkg/utils/feature"
 	"github.com/openkruise/agents/pkg/utils/fieldindex"
+	"github.com/openkruise/agents/pkg/utils/maintenance"
+	"github.com/openkruise/agents/pkg/utils/rightsizing"
 	managerutils "github.com/openkruise/agents/pkg/utils/sandbox-manager"
-	stateutils "github.com/openkruise/agents/pkg/utils/sandboxutils"
+	"github.com/openkruise/agents/pkg/utils/sandboxstate"
+	"github.com/openkruise/agents/pkg/utils/slo"
+	"github.com/openkruise/agents/pkg/utils/statusupdater"
+	"github.com/openkruise/agents/pkg/utils/templateutils"
+	"github.com/openkruise/agents/pkg/utils/webhookutils"
+	"github.com/openkruise/agents/pkg/webhook/standalone"
 )
 
 func init() {
@@ -62,6 +72,8 @@ var (
 	concurrentReconciles = 3
 	initialBatchSize     = 16
 	controllerKind       = agentsv1alpha1.GroupVersion.WithKind("SandboxSet")
+	// sandboxSetStatusUpdater patches the status only when it semantically changes
+	sandboxSetStatusUpdater = statusupdater.New()
 )
 
 func Add(mgr manager.Manager) error {
@@ -71,10 +83,26 @@ func Add(mgr manager.Manager) error {
 	err := (&Reconciler{
 		Client: mgr.GetClient(),
 		Scheme: mgr.GetScheme(),
+		Maintenance: &maintenance.Checker{
+			Reader:    mgr.GetAPIReader(),
+			Namespace: webhookutils.GetNamespace(),
+		},
 	}).SetupWithManager(mgr)
 	if err != nil {
 		return err
 	}
+	if poolAnalysisInterval > 0 {
+		err = mgr.Add(&poolAnalyzer{
+			Client:   mgr.GetClient(),
+			recorder: mgr.GetEventRecorderFor("sandboxset-pool-analyzer"),
+			tracker:  rightsizing.Default,
+			interval: poolAnalysisInterval,
+			refill:   poolRefillWindow,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to add pool analyzer: %w", err)
+		}
+	}
 	klog.Infof("Started SandboxSetReconciler successfully")
 	return nil
 }
@@ -85,19 +113,29 @@ type Reconciler struct {
 	Scheme   *runtime.Scheme
 	Recorder record.EventRecorder
 	Codec    runtime.Codec
+	// Maintenance defers scaling down to the maintenance windows of the namespace, nil means no restriction
+	Maintenance *maintenance.Checker
 }
 
 const (
-	EventSandboxCreated       = "SandboxCreated"
-	EventCreateSandboxFailed  = "CreateSandboxFailed"
-	EventSandboxScaledDown    = "SandboxScaledDown"
-	EventFailedSandboxDeleted = "FailedSandboxDeleted"
+	EventSandboxCreated        = "SandboxCreated"
+	EventCreateSandboxFailed   = "CreateSandboxFailed"
+	EventSandboxScaledDown     = "SandboxScaledDown"
+	EventFailedSandboxDeleted  = "FailedSandboxDeleted"
+	EventScaleDownDeferred     = "ScaleDownDeferred"
+	EventResolveTemplateFailed = "ResolveTemplateFailed"
+	EventNodePoolsRebalancing  = "NodePoolsRebalancing"
+	EventAutoscaled            = "Autoscaled"
 )
 
 // +kubebuilder:rbac:groups=agents.kruise.io,resources=sandboxsets,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=agents.kruise.io,resources=sandboxsets/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=agents.kruise.io,resources=sandboxsets/finalizers,verbs=update
+// +kubebuilder:rbac:groups=agents.kruise.io,resources=sandboxtemplates,verbs=get;list;watch
+// +kubebuilder:rbac:groups=agents.kruise.io,resources=sandboxclaims,verbs=get;list;watch
 // +kubebuilder:rbac:groups=core,resources=persistentvolumeclaims,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=nodes,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get,namespace=sandbox-system
 
 func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	totalStart := time.Now()
@@ -112,13 +150,35 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 			SandboxSetReplicas.DeleteLabelValues(req.Namespace, req.Name)
 			SandboxSetAvailableReplicas.DeleteLabelValues(req.Namespace, req.Name)
 			SandboxSetDesiredReplicas.DeleteLabelValues(req.Namespace, req.Name)
+			SandboxSetClaimedReplicas.DeleteLabelValues(req.Namespace, req.Name)
+			SandboxSetSLOBurnRate.DeletePartialMatch(prometheus.Labels{"namespace": req.Namespace, "name": req.Name})
+			slo.Default.Forget(req.Namespace, req.Name)
+			rightsizing.Default.Forget(req.Namespace, req.Name)
 			return ctrl.Result{}, nil
 		}
 		return ctrl.Result{}, err
 	}
 
 	// Preparation
-	newStatus, err := r.initNewStatus(sbs)
+	if standalone.DefaultOptions.Enabled {
+		if done, err := r.defaultAndValidate(ctx, sbs); done || err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	// sandboxes are created and hashed with the template resolved from the base SandboxTemplate and the overlay
+	resolved, err := templateutils.ResolveSandboxSet(ctx, r.Client, sbs)
+	if err != nil {
+		log.Error(err, "failed to resolve template")
+		r.Recorder.Eventf(sbs, corev1.EventTypeWarning, EventResolveTemplateFailed, "Failed to resolve template: %s", err)
+		return ctrl.Result{}, err
+	}
+	if standalone.DefaultOptions.Enabled {
+		if errList := standalone.ValidateResolvedSandboxSet(sbs, resolved); len(errList) > 0 {
+			r.reportInvalid(ctx, sbs, errList)
+			return ctrl.Result{}, nil
+		}
+	}
+	newStatus, err := r.initNewStatus(resolved)
 	if err != nil {
 		log.Error(err, "failed to init new status")
 		return ctrl.Result{}, err
@@ -136,6 +196,49 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 	requeueAfter = min(scaleUpTimeoutAfter, scaleDownTimeoutAfter)
 
 	calculateSandboxSetStatusFromGroup(ctx, newStatus, groups, dirtyScaleUp)
+	if stallsIn := calculateStalledReplicas(newStatus, sbs, groups, time.Now()); stallsIn > 0 &&
+		(requeueAfter == 0 || stallsIn < requeueAfter) {
+		requeueAfter = stallsIn
+	}
+	var pending int32
+	if autoscalingEnabled(sbs) {
+		if pending, err = r.countPendingReplicas(ctx, sbs); err != nil {
+			log.Error(err, "failed to count pending replicas of claims")
+			return ctrl.Result{}, err
+		}
+	}
+	lastDesired := targetReplicas(sbs, newStatus)
+	cooldownLeft, autoscaled := calculateAutoscaling(newStatus, sbs, pending, time.Now())
+	// the replicas autoscaled within spec.minAvailable do not scale the SandboxSet
+	if desired := targetReplicas(sbs, newStatus); autoscaled && desired != lastDesired {
+		log.Info("autoscaled by claim pressure", "from", lastDesired, "to", desired, "pending", pending)
+		r.Recorder.Eventf(sbs, corev1.EventTypeNormal, EventAutoscaled,
+			"Scaled from %d to %d replicas for %d replica(s) pending in claims", lastDesired, desired, pending)
+	}
+	if cooldownLeft > 0 && (requeueAfter == 0 || cooldownLeft < requeueAfter) {
+		requeueAfter = cooldownLeft
+	}
+	var readyNodes map[string]int32
+	if nodePoolsEnabled(sbs) {
+		if readyNodes, err = r.countReadyNodes(ctx, sbs.Spec.NodePools); err != nil {
+			log.Error(err, "failed to count ready nodes of node pools")
+			return ctrl.Result{}, err
+		}
+	}
+	calculateNodePoolStatus(newStatus, sbs, groups, readyNodes)
+	claimed, err := r.countClaimedSandboxes(ctx, sbs)
+	if err != nil {
+		log.Error(err, "failed to count claimed sandboxes")
+		return ctrl.Result{}, err
+	}
+	newStatus.ClaimedReplicas = claimed
+	rightsizing.Default.ObserveOccupancy(sbs.Namespace, sbs.Name, newStatus.AvailableReplicas, time.Now())
+	setSandboxSetReadyCondition(newStatus, sbs)
+	setSandboxSetPausedCondition(newStatus, sbs)
+	evaluateSLO(newStatus, sbs, slo.Default, time.Now())
+	if sbs.Spec.SLO != nil && (requeueAfter == 0 || sloEvaluationInterval < requeueAfter) {
+		requeueAfter = sloEvaluationInterval
+	}
 	// Set selector in status for scale subresource
 	if newStatus.Selector == "" {
 		selector, err := metav1.LabelSelectorAsSelector(&metav1.LabelSelector{
@@ -155,15 +258,34 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 	// Step 1: perform scale
 	start := time.Now()
 	delta := calculateScaleDelta(sbs, newStatus)
-	log.Info("performing scale", "expect", sbs.Spec.Replicas, "actual", newStatus.Replicas,
+	if delta == 0 && nodePoolsEnabled(sbs) && !sbs.Spec.Paused && scaleUpSatisfied && scaleDownSatisfied {
+		// surge the pools below their share first, the pools above it are scaled down once the surge is observed
+		if delta = min(calculateRebalance(newStatus), scaleUpLimit(sbs, newStatus)); delta > 0 {
+			r.Recorder.Eventf(sbs, corev1.EventTypeNormal, EventNodePoolsRebalancing,
+				"Rebalancing %d sandbox(es) across node pools", delta)
+		}
+	}
+	log.Info("performing scale", "expect", targetReplicas(sbs, newStatus), "actual", newStatus.Replicas,
 		"available", newStatus.AvailableReplicas, "delta", delta)
 	if delta > 0 {
-		err = r.scaleUp(ctx, delta, sbs, newStatus.UpdateRevision)
+		err = r.scaleUp(ctx, planNodePools(newStatus, delta), resolved, newStatus.UpdateRevision)
 	} else if delta < 0 {
 		if !scaleUpSatisfied || !scaleDownSatisfied {
 			log.Info("skip scale down for scaleUpExpectation or scaleDownExpectation is not satisfied")
+		} else if sbs.Spec.Paused && sbs.Spec.DrainOnPause {
+			// draining a paused pool is incident response, never deferred to maintenance windows
+			err = r.scaleDown(ctx, -delta, sbs, groups, newStatus)
+		} else if allowed, wait, checkErr := r.Maintenance.Allowed(ctx, sbs.Namespace, time.Now()); checkErr != nil {
+			err = checkErr
+		} else if !allowed {
+			log.Info("defer scale down to the next maintenance window", "wait", wait)
+			r.Recorder.Eventf(sbs, corev1.EventTypeNormal, EventScaleDownDeferred,
+				"Scaling down %d sandbox(es) is deferred to the next maintenance window in %v", -delta, wait)
+			if requeueAfter == 0 || wait < requeueAfter {
+				requeueAfter = wait
+			}
 		} else {
-			err = r.scaleDown(ctx, -delta, sbs, groups)
+			err = r.scaleDown(ctx, -delta, sbs, groups, newStatus)
 		}
 	}
 	if err != nil {
@@ -189,12 +311,13 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 	return ctrl.Result{RequeueAfter: requeueAfter}, allErrors
 }
 
-// scaleUp is allowed when scaleUpExpectation is satisfied
-func (r *Reconciler) scaleUp(ctx context.Context, count int, sbs *agentsv1alpha1.SandboxSet, revision string) error {
+// scaleUp creates a sandbox in each of the node pools planned, or in no pool if empty
+func (r *Reconciler) scaleUp(ctx context.Context, plan []string, sbs *agentsv1alpha1.SandboxSet, revision string) error {
 	log := logf.FromContext(ctx)
+	count := len(plan)
 	log.Info("scale up", "count", count)
-	successes, err := utils.DoItSlowly(count, initialBatchSize, func() error {
-		created, err := r.createSandbox(ctx, sbs, revision)
+	successes, err := utils.DoItSlowlyWithInputs(plan, initialBatchSize, func(pool string) error {
+		created, err := r.createSandbox(ctx, sbs, revision, pool)
 		if err != nil {
 			log.Error(err, "failed to create sandbox")
 			return err
@@ -207,13 +330,14 @@ func (r *Reconciler) scaleUp(ctx context.Context, count int, sbs *agentsv1alpha1
 }
 
 // scaleDown is allowed when both scaleUpExpectation and scaleDownExpectation are satisfied
-func (r *Reconciler) scaleDown(ctx context.Context, count int, sbs *agentsv1alpha1.SandboxSet, groups GroupedSandboxes) error {
+func (r *Reconciler) scaleDown(ctx context.Context, count int, sbs *agentsv1alpha1.SandboxSet, groups GroupedSandboxes,
+	newStatus *agentsv1alpha1.SandboxSetStatus) error {
 	log := logf.FromContext(ctx)
 	controllerKey := GetControllerKey(sbs)
 	lock := uuid.New().String()
 	log.Info("scale down", "count", count)
 	var toDelete []client.ObjectKey
-	for _, snapshot := range append(groups.Creating, groups.Available...) {
+	for _, snapshot := range orderForScaleDown(append(groups.Creating, groups.Available...), newStatus) {
 		if count <= 0 {
 			break
 		}
@@ -237,37 +361,47 @@ func (r *Reconciler) scaleDown(ctx context.Context, count int, sbs *agentsv1alph
 // calculateScaleDelta calculates the delta for scaling, considering MaxUnavailable limit.
 // Returns positive value for scale up, negative for scale down, 0 for no scaling needed.
 func calculateScaleDelta(sbs *agentsv1alpha1.SandboxSet, newStatus *agentsv1alpha1.SandboxSetStatus) int {
-	delta := int(sbs.Spec.Replicas - newStatus.Replicas)
+	delta := int(targetReplicas(sbs, newStatus) - newStatus.Replicas)
+	if sbs.Spec.Paused {
+		// a paused pool never scales up, and scales down to nothing when drained
+		if sbs.Spec.DrainOnPause {
+			return -int(newStatus.Replicas)
+		}
+		return min(delta, 0)
+	}
 	// scale down
 	if delta <= 0 {
 		return delta
 	}
 
-	// apply maxUnavailable limit only for scale up
+	// delta cannot exceed the maxUnavailable limit, except for the sandboxes short of minAvailable
+	return min(delta, max(scaleUpLimit(sbs, newStatus), int(minAvailableReplicas(sbs, newStatus)-newStatus.Replicas)))
+}
+
+// scaleUpLimit calculates how many sandboxes may be created under the maxUnavailable limit
+func scaleUpLimit(sbs *agentsv1alpha1.SandboxSet, newStatus *agentsv1alpha1.SandboxSetStatus) int {
 	scaleMaxUnavailable := math.MaxInt
 	if sbs.Spec.ScaleStrategy.MaxUnavailable != nil {
 		scaleMaxUnavailable, _ = intstrutil.GetScaledValueFromIntOrPercent(
 			intstrutil.ValueOrDefault(sbs.Spec.ScaleStrategy.MaxUnavailable, intstrutil.FromInt32(math.MaxInt32)),
-			int(sbs.Spec.Replicas),
+			int(targetReplicas(sbs, newStatus)),
 			true)
 		// subtract sandboxes that are currently being creating
 		scaleMaxUnavailable -= int(newStatus.Replicas - newStatus.AvailableReplicas)
 	}
 	// ignore negative values
-	if scaleMaxUnavailable < 0 {
-		scaleMaxUnavailable = 0
-	}
-	// delta cannot exceed scaleMaxUnavailable
-	if delta > scaleMaxUnavailable {
-		delta = scaleMaxUnavailable
-	}
-
-	return delta
+	return max(scaleMaxUnavailable, 0)
 }
 
-func (r *Reconciler) createSandbox(ctx context.Context, sbs *agentsv1alpha1.SandboxSet, revision string) (*agentsv1alpha1.Sandbox, error) {
+func (r *Reconciler) createSandbox(ctx context.Context, sbs *agentsv1alpha1.SandboxSet, revision, pool string) (*agentsv1alpha1.Sandbox, error) {
 	sbx := NewSandboxFromSandboxSet(sbs)
 	sbx.Labels[agentsv1alpha1.LabelTemplateHash] = revision
+	if pool != "" {
+		if err := placeInNodePool(sbx, sbs.Spec.NodePools, pool); err != nil {
+			r.Recorder.Eventf(sbs, corev1.EventTypeWarning, EventCreateSandboxFailed, "Failed to create sandbox: %s", err)
+			return nil, err
+		}
+	}
 	if err := ctrl.SetControllerReference(sbs, sbx, r.Scheme); err != nil {
 		return nil, err
 	}
@@ -334,18 +468,15 @@ func (r *Reconciler) updateSandboxSetStatus(ctx context.Context, newStatus agent
 		log.Error(err, "failed to get updated sandboxset from client")
 		return client.IgnoreNotFound(err)
 	}
-	if reflect.DeepEqual(clone.Status, newStatus) {
-		return nil
-	}
-	clone.Status = newStatus
-	err := r.Status().Update(ctx, clone)
-	if err == nil {
+	patched, err := sandboxSetStatusUpdater.Patch(ctx, r.Client, clone, clone.Status, newStatus)
+	if err == nil && patched {
 		log.Info("update sandboxset status success", "status", utils.DumpJson(newStatus))
 		// Update metrics for availableReplicas and replicas
 		SandboxSetReplicas.WithLabelValues(sbs.Namespace, sbs.Name).Set(float64(newStatus.Replicas))
 		SandboxSetAvailableReplicas.WithLabelValues(sbs.Namespace, sbs.Name).Set(float64(newStatus.AvailableReplicas))
-		SandboxSetDesiredReplicas.WithLabelValues(sbs.Namespace, sbs.Name).Set(float64(sbs.Spec.Replicas))
-	} else {
+		SandboxSetDesiredReplicas.WithLabelValues(sbs.Namespace, sbs.Name).Set(float64(targetReplicas(sbs, &newStatus)))
+		SandboxSetClaimedReplicas.WithLabelValues(sbs.Namespace, sbs.Name).Set(float64(newStatus.ClaimedReplicas))
+	} else if err != nil {
 		log.Error(err, "update sandboxset status failed")
 	}
 	return err
@@ -362,11 +493,19 @@ func (r *Reconciler) groupAllSandboxes(ctx context.Context, sbs *agentsv1alpha1.
 		return GroupedSandboxes{}, err
 	}
 	groups := GroupedSandboxes{}
+	// all sandboxes are evaluated at the same time, so that they are grouped consistently
+	now := time.Now()
 	for i := range sandboxList.Items {
 		sbx := &sandboxList.Items[i]
 		scaleUpExpectation.ObserveScale(GetControllerKey(sbs), expectations.Create, sbx.Name)
 		debugLog := log.V(consts.DebugLogLevel).WithValues("sandbox", sbx.Name)
-		state, reason := stateutils.GetSandboxState(sbx)
+		if sbx.Spec.Quarantine != nil {
+			// a quarantined sandbox is neither claimed nor deleted, and the pool replaces it
+			groups.Used = append(groups.Used, sbx)
+			debugLog.Info("sandbox is grouped", "state", "quarantined")
+			continue
+		}
+		state, reason := sandboxstate.Evaluate(sbx, now, sandboxstate.Policy{})
 		switch state {
 		case agentsv1alpha1.SandboxStateCreating:
 			groups.Creating = append(groups.Creating, sbx)
@@ -393,10 +532,64 @@ func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
 	controllerName := "sandboxset-controller"
 	r.Recorder = mgr.GetEventRecorderFor(controllerName)
 	r.Codec = serializer.NewCodecFactory(mgr.GetScheme()).LegacyCodec(agentsv1alpha1.SchemeGroupVersion)
-	return ctrl.NewControllerManagedBy(mgr).
+	b := ctrl.NewControllerManagedBy(mgr).
 		Named(controllerName).
-		WithOptions(controller.Options{MaxConcurrentReconciles: concurrentReconciles}).
+		WithOptions(controller.Options{MaxConcurrentReconciles: concurrentReconciles, NewQueue: controllermetrics.NewQueue}).
 		Watches(&agentsv1alpha1.SandboxSet{}, &handler.EnqueueRequestForObject{}).
 		Watches(&agentsv1alpha1.Sandbox{}, &SandboxEventHandler{}).
-		Complete(r)
+		Watches(&agentsv1alpha1.SandboxTemplate{}, handler.EnqueueRequestsFromMapFunc(r.mapSandboxTemplateToSandboxSets))
+	if utilfeature.DefaultFeatureGate.Enabled(features.SandboxSetNodePoolsGate) {
+		// nodes are only cached if sandboxes are distributed across node pools
+		b = b.Watches(&corev1.Node{}, handler.EnqueueRequestsFromMapFunc(r.mapNodeToSandboxSets),
+			builder.WithPredicates(nodeChangedPredicate))
+	}
+	if utilfeature.DefaultFeatureGate.Enabled(features.SandboxSetAutoscalingGate) && discovery.DiscoverGVK(sandboxClaimKind) {
+		// claims are only cached if SandboxSets are autoscaled by their pressure
+		b = b.Watches(&agentsv1alpha1.SandboxClaim{}, handler.EnqueueRequestsFromMapFunc(r.mapSandboxClaimToSandboxSet),
+			builder.WithPredicates(claimPressureChangedPredicate))
+	}
+	return b.Complete(controllermetrics.Wrap(controllerName, r))
+}
+
+// mapSandboxTemplateToSandboxSets enqueues the SandboxSets overlaying the SandboxTemplate, so that their update revision
+// follows the changes of the base template.
+func (r *Reconciler) mapSandboxTemplateToSandboxSets(ctx context.Context, obj client.Object) []reconcile.Request {
+	sbsList := &agentsv1alpha1.SandboxSetList{}
+	if err := r.List(ctx, sbsList, client.InNamespace(obj.GetNamespace())); err != nil {
+		logf.FromContext(ctx).Error(err, "failed to list sandboxsets", "template", klog.KObj(obj))
+		return nil
+	}
+	var requests []reconcile.Request
+	for i := range sbsList.Items {
+		sbs := &sbsList.Items[i]
+		if sbs.Spec.TemplateOverlay != nil && sbs.Spec.TemplateRef != nil && sbs.Spec.TemplateRef.Name == obj.GetName() {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(sbs)})
+		}
+	}
+	return requests
+}
+
+// countClaimedSandboxes counts the alive sandboxes claimed from the SandboxSet. Claimed sandboxes are released
+// from the owner reference of the SandboxSet, so they are listed by the pool label.
+func (r *Reconciler) countClaimedSandboxes(ctx context.Context, sbs *agentsv1alpha1.SandboxSet) (int32, error) {
+	sandboxList := &agentsv1alpha1.SandboxList{}
+	if err := r.List(ctx, sandboxList,
+		client.InNamespace(sbs.Namespace),
+		client.MatchingLabels{
+			agentsv1alpha1.LabelSandboxPool:      sbs.Name,
+			agentsv1alpha1.LabelSandboxIsClaimed: agentsv1alpha1.True,
+		},
+		client.UnsafeDisableDeepCopy,
+	); err != nil {
+		return 0, err
+	}
+	var claimed int32
+	now := time.Now()
+	for i := range sandboxList.Items {
+		if state, _ := sandboxstate.Evaluate(&sandboxList.Items[i], now, sandboxstate.Policy{}); state != agentsv1alpha1.SandboxStateDead {
+			claimed++
+		}
+	}
+	logf.FromContext(ctx).V(consts.DebugLogLevel).Info("claimed sandboxes counted", "claimed", claimed)
+	return claimed, nil
 }
@@ -6,6 +6,7 @@ import (
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
@@ -16,13 +17,16 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	intstrutil "k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	"github.com/openkruise/agents/api/v1alpha1"
 	"github.com/openkruise/agents/pkg/sandbox-manager/consts"
+	"github.com/openkruise/agents/pkg/utils/conditions"
 	"github.com/openkruise/agents/pkg/utils/fieldindex"
+	"github.com/openkruise/agents/pkg/utils/maintenance"
 	utils "github.com/openkruise/agents/pkg/utils/sandbox-manager"
 	"github.com/openkruise/agents/pkg/utils/sandboxutils"
 )
@@ -156,6 +160,7 @@ func CreateSandboxes(t *testing.T, tt createSandboxRequest, sbs *v1alpha1.Sandbo
 		state, reason := sandboxutils.GetSandboxState(sbx)
 		assert.Equal(t, v1alpha1.SandboxStateRunning, state, reason)
 		sbx.Labels["type"] = "running"
+		sbx.Labels[v1alpha1.LabelSandboxIsClaimed] = v1alpha1.True
 		toCreate = append(toCreate, sbx)
 		idx++
 	}
@@ -163,6 +168,7 @@ func CreateSandboxes(t *testing.T, tt createSandboxRequest, sbs *v1alpha1.Sandbo
 		sbx := getBaseSandbox(idx, "paused-", sbs.Status.UpdateRevision)
 		sbx.Status.Phase = v1alpha1.SandboxPaused
 		sbx.Labels["type"] = "paused"
+		sbx.Labels[v1alpha1.LabelSandboxIsClaimed] = v1alpha1.True
 		state, reason := sandboxutils.GetSandboxState(sbx)
 		assert.Equal(t, v1alpha1.SandboxStatePaused, state, reason)
 		toCreate = append(toCreate, sbx)
@@ -583,6 +589,9 @@ func TestReconcile_BasicScale(t *testing.T) {
 			status := gotSbs.Status
 			assert.Equal(t, tt.replicas, status.Replicas)
 			assert.Equal(t, tt.expectStatusAvailable, status.AvailableReplicas)
+			assert.Equal(t, tt.request.createRunningSandboxes+tt.request.createPausedSandboxes, status.ClaimedReplicas)
+			assert.Equal(t, tt.expectStatusAvailable >= tt.replicas,
+				conditions.IsTrue(status.Conditions, string(v1alpha1.SandboxSetConditionReady)))
 
 			CheckAllEvents(t, eventRecorder, tt.expectEvents)
 		})
@@ -597,6 +606,9 @@ func TestReconcile_ScaleDown(t *testing.T) {
 		request      createSandboxRequest
 		expectEvents []string
 		expectError  bool
+		maintenance  map[string]string
+		paused       bool
+		drainOnPause bool
 		checkFunc    func(t *testing.T, sandboxes []v1alpha1.Sandbox)
 	}{
 		{
@@ -670,6 +682,79 @@ func TestReconcile_ScaleDown(t *testing.T) {
 			},
 			expectEvents: []string{EventSandboxScaledDown},
 		},
+		{
+			name:     "scale down in maintenance window",
+			replicas: 0,
+			request: createSandboxRequest{
+				createAvailableSandboxes: 1,
+			},
+			maintenance: map[string]string{maintenance.ClusterKey: `[{"start": "00:00", "end": "24:00"}]`},
+			checkFunc: func(t *testing.T, sandboxes []v1alpha1.Sandbox) {
+				assert.Equal(t, 0, len(sandboxes))
+			},
+			expectEvents: []string{EventSandboxScaledDown},
+		},
+		{
+			name:     "scale down deferred out of maintenance window",
+			replicas: 0,
+			request: createSandboxRequest{
+				createAvailableSandboxes: 1,
+			},
+			maintenance: map[string]string{
+				maintenance.ClusterKey: `[{"start": "00:00", "end": "24:00"}]`,
+				"default": fmt.Sprintf(`[{"start": %q, "end": %q}]`,
+					time.Now().UTC().Add(time.Hour).Format("15:04"), time.Now().UTC().Add(2*time.Hour).Format("15:04")),
+			},
+			checkFunc: func(t *testing.T, sandboxes []v1alpha1.Sandbox) {
+				assert.Equal(t, 1, len(sandboxes))
+			},
+			expectEvents: []string{EventScaleDownDeferred},
+		},
+		{
+			name:     "invalid maintenance windows",
+			replicas: 0,
+			request: createSandboxRequest{
+				createAvailableSandboxes: 1,
+			},
+			maintenance: map[string]string{maintenance.ClusterKey: `[{"start": "25:00", "end": "26:00"}]`},
+			checkFunc: func(t *testing.T, sandboxes []v1alpha1.Sandbox) {
+				assert.Equal(t, 1, len(sandboxes))
+			},
+			expectError: true,
+		},
+		{
+			name:     "paused pool neither scales up nor drains without drainOnPause",
+			replicas: 3,
+			request: createSandboxRequest{
+				createAvailableSandboxes: 1,
+			},
+			paused: true,
+			checkFunc: func(t *testing.T, sandboxes []v1alpha1.Sandbox) {
+				assert.Equal(t, 1, len(sandboxes))
+			},
+			expectEvents: []string{},
+		},
+		{
+			name:     "paused pool drains unclaimed sandboxes out of maintenance window",
+			replicas: 3,
+			request: createSandboxRequest{
+				createAvailableSandboxes: 1,
+				createCreatingSandboxes:  1,
+				createRunningSandboxes:   1,
+			},
+			maintenance: map[string]string{
+				maintenance.ClusterKey: fmt.Sprintf(`[{"start": %q, "end": %q}]`,
+					time.Now().UTC().Add(time.Hour).Format("15:04"), time.Now().UTC().Add(2*time.Hour).Format("15:04")),
+			},
+			paused:       true,
+			drainOnPause: true,
+			checkFunc: func(t *testing.T, sandboxes []v1alpha1.Sandbox) {
+				if assert.Equal(t, 1, len(sandboxes), "claimed sandboxes should be kept") {
+					assert.Equal(t, v1alpha1.True, sandboxes[0].Labels[v1alpha1.LabelSandboxIsClaimed])
+				}
+			},
+			expectEvents: []string{EventSandboxScaledDown, EventSandboxScaledDown},
+		},
 	}
 
 	for _, tt := range tests {
@@ -682,8 +767,20 @@ func TestReconcile_ScaleDown(t *testing.T) {
 				Scheme:   testScheme,
 				Recorder: eventRecorder,
 				Codec:    codec,
+				Maintenance: &maintenance.Checker{
+					Reader:    k8sClient,
+					Namespace: "sandbox-system",
+				},
+			}
+			if tt.maintenance != nil {
+				assert.NoError(t, k8sClient.Create(ctx, &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Name: maintenance.ConfigMapName, Namespace: "sandbox-system"},
+					Data:       tt.maintenance,
+				}))
 			}
 			sbs := getSandboxSet(tt.replicas)
+			sbs.Spec.Paused = tt.paused
+			sbs.Spec.DrainOnPause = tt.drainOnPause
 			assert.NoError(t, k8sClient.Create(ctx, sbs))
 			CreateSandboxes(t, tt.request, sbs, k8sClient)
 
@@ -1020,3 +1117,231 @@ func TestCalculateScaleDelta(t *testing.T) {
 		})
 	}
 }
+
+func TestReconcile_TemplateOverlay(t *testing.T) {
+	ctx := context.Background()
+	k8sClient := NewClient()
+	base := &v1alpha1.SandboxTemplate{
+		ObjectMeta: metav1.ObjectMeta{Name: "base", Namespace: "default"},
+		Spec: v1alpha1.SandboxTemplateSpec{
+			Template: getSandboxSet(0).Spec.Template,
+		},
+	}
+	sbs := getSandboxSet(2)
+	sbs.Name, sbs.UID = "overlay", "overlay-uid"
+	sbs.Spec.Template = nil
+	sbs.Spec.TemplateRef = &v1alpha1.SandboxTemplateRef{Name: "base"}
+	sbs.Spec.TemplateOverlay = &corev1.PodTemplateSpec{
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "sidecar", Image: "sidecar"}},
+		},
+	}
+	other := getSandboxSet(1)
+	other.Name, other.UID = "overlay-other", "overlay-other-uid"
+	assert.NoError(t, k8sClient.Create(ctx, sbs))
+	assert.NoError(t, k8sClient.Create(ctx, other))
+	eventRecorder := record.NewFakeRecorder(10)
+	reconciler := &Reconciler{
+		Client:   k8sClient,
+		Scheme:   testScheme,
+		Recorder: eventRecorder,
+		Codec:    codec,
+	}
+	req := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(sbs)}
+
+	// the base template is not created yet
+	_, err := reconciler.Reconcile(ctx, req)
+	assert.ErrorContains(t, err, "failed to get sandbox template base")
+	assert.Contains(t, <-eventRecorder.Events, EventResolveTemplateFailed)
+
+	assert.NoError(t, k8sClient.Create(ctx, base))
+	_, err = reconciler.Reconcile(ctx, req)
+	assert.NoError(t, err)
+	assert.NoError(t, k8sClient.Get(ctx, req.NamespacedName, sbs))
+	revision := sbs.Status.UpdateRevision
+	assert.NotEmpty(t, revision)
+
+	sandboxList := &v1alpha1.SandboxList{}
+	assert.NoError(t, k8sClient.List(ctx, sandboxList, client.InNamespace(sbs.Namespace)))
+	assert.Len(t, sandboxList.Items, 2)
+	for _, sbx := range sandboxList.Items {
+		assert.Nil(t, sbx.Spec.TemplateRef)
+		if assert.NotNil(t, sbx.Spec.Template) {
+			assert.Equal(t, []string{"test", "sidecar"}, []string{sbx.Spec.Template.Spec.Containers[0].Name, sbx.Spec.Template.Spec.Containers[1].Name})
+			assert.Equal(t, "true", sbx.Labels[newPodKey])
+		}
+		assert.Equal(t, "overlay", sbx.Labels[v1alpha1.LabelSandboxTemplate])
+		assert.Equal(t, revision, sbx.Labels[v1alpha1.LabelTemplateHash])
+	}
+
+	// changes of the base template are followed
+	assert.Equal(t, []ctrl.Request{req}, reconciler.mapSandboxTemplateToSandboxSets(ctx, base))
+	base.Spec.Template.Spec.Containers[0].Image = "test:v2"
+	assert.NoError(t, k8sClient.Update(ctx, base))
+	_, err = reconciler.Reconcile(ctx, req)
+	assert.NoError(t, err)
+	assert.NoError(t, k8sClient.Get(ctx, req.NamespacedName, sbs))
+	assert.NotEqual(t, revision, sbs.Status.UpdateRevision)
+}
+
+func TestCalculateScaleDelta_Paused(t *testing.T) {
+	tests := []struct {
+		name           string
+		replicas       int32
+		statusReplicas int32
+		drainOnPause   bool
+		expectedDelta  int
+	}{
+		{
+			name:           "paused pool does not scale up",
+			replicas:       10,
+			statusReplicas: 4,
+			expectedDelta:  0,
+		},
+		{
+			name:           "paused pool still scales down",
+			replicas:       2,
+			statusReplicas: 4,
+			expectedDelta:  -2,
+		},
+		{
+			name:           "drained pool scales down all unclaimed sandboxes",
+			replicas:       10,
+			statusReplicas: 4,
+			drainOnPause:   true,
+			expectedDelta:  -4,
+		},
+		{
+			name:           "drained pool without unclaimed sandboxes",
+			replicas:       10,
+			statusReplicas: 0,
+			drainOnPause:   true,
+			expectedDelta:  0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sbs := getSandboxSet(tt.replicas)
+			sbs.Spec.Paused = true
+			sbs.Spec.DrainOnPause = tt.drainOnPause
+			status := &v1alpha1.SandboxSetStatus{Replicas: tt.statusReplicas, AvailableReplicas: tt.statusReplicas}
+			assert.Equal(t, tt.expectedDelta, calculateScaleDelta(sbs, status))
+		})
+	}
+}
+
+func TestCalculateScaleDelta_MinAvailable(t *testing.T) {
+	tests := []struct {
+		name              string
+		replicas          int32
+		minAvailable      int32
+		statusReplicas    int32
+		availableReplicas int32
+		stalledReplicas   int32
+		maxUnavailable    *intstrutil.IntOrString
+		expectedDelta     int
+	}{
+		{
+			name:          "minAvailable above replicas",
+			replicas:      2,
+			minAvailable:  5,
+			expectedDelta: 5,
+		},
+		{
+			name:              "minAvailable below replicas",
+			replicas:          5,
+			minAvailable:      2,
+			statusReplicas:    3,
+			availableReplicas: 3,
+			expectedDelta:     2,
+		},
+		{
+			name:           "sandboxes short of minAvailable are not limited by maxUnavailable",
+			replicas:       10,
+			minAvailable:   4,
+			statusReplicas: 1,
+			maxUnavailable: ptr.To(intstrutil.FromInt32(2)),
+			expectedDelta:  3,
+		},
+		{
+			name:              "sandboxes beyond minAvailable are limited by maxUnavailable",
+			replicas:          10,
+			minAvailable:      4,
+			statusReplicas:    4,
+			availableReplicas: 4,
+			maxUnavailable:    ptr.To(intstrutil.FromInt32(2)),
+			expectedDelta:     2,
+		},
+		{
+			name:              "never scale down below minAvailable",
+			replicas:          0,
+			minAvailable:      3,
+			statusReplicas:    5,
+			availableReplicas: 5,
+			expectedDelta:     -2,
+		},
+		{
+			name:           "creating sandboxes count toward minAvailable until stalled",
+			replicas:       0,
+			minAvailable:   3,
+			statusReplicas: 3,
+			expectedDelta:  0,
+		},
+		{
+			name:            "stalled sandboxes do not satisfy minAvailable",
+			replicas:        2,
+			minAvailable:    3,
+			statusReplicas:  3,
+			stalledReplicas: 3,
+			maxUnavailable:  ptr.To(intstrutil.FromInt32(1)),
+			expectedDelta:   3,
+		},
+		{
+			name:            "at most minAvailable stalled sandboxes are replaced",
+			replicas:        0,
+			minAvailable:    2,
+			statusReplicas:  6,
+			stalledReplicas: 6,
+			expectedDelta:   -2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sbs := getSandboxSet(tt.replicas)
+			sbs.Spec.MinAvailable = tt.minAvailable
+			sbs.Spec.ScaleStrategy.MaxUnavailable = tt.maxUnavailable
+			status := &v1alpha1.SandboxSetStatus{Replicas: tt.statusReplicas, AvailableReplicas: tt.availableReplicas,
+				StalledReplicas: tt.stalledReplicas}
+			assert.Equal(t, tt.expectedDelta, calculateScaleDelta(sbs, status))
+		})
+	}
+}
+
+func TestCalculateStalledReplicas(t *testing.T) {
+	now := time.Now()
+	createdAgo := func(ago time.Duration) *v1alpha1.Sandbox {
+		return &v1alpha1.Sandbox{ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.NewTime(now.Add(-ago))}}
+	}
+	groups := GroupedSandboxes{
+		Creating:  []*v1alpha1.Sandbox{createdAgo(10 * time.Minute), createdAgo(6 * time.Minute), createdAgo(time.Minute)},
+		Available: []*v1alpha1.Sandbox{createdAgo(time.Hour)},
+	}
+
+	sbs := getSandboxSet(0)
+	newStatus := &v1alpha1.SandboxSetStatus{Replicas: 4, AvailableReplicas: 1, StalledReplicas: 5}
+	assert.Equal(t, time.Duration(0), calculateStalledReplicas(newStatus, sbs, groups, now))
+	assert.Equal(t, int32(0), newStatus.StalledReplicas, "not counted without minAvailable")
+
+	sbs.Spec.MinAvailable = 3
+	assert.Equal(t, 4*time.Minute, calculateStalledReplicas(newStatus, sbs, groups, now))
+	assert.Equal(t, int32(2), newStatus.StalledReplicas)
+	// the stalled sandboxes are replaced, as only the available one counts toward minAvailable
+	assert.Equal(t, 1, calculateScaleDelta(sbs, newStatus))
+
+	setSandboxSetReadyCondition(newStatus, sbs)
+	cond := conditions.Get(newStatus.Conditions, string(v1alpha1.SandboxSetConditionReady))
+	if assert.NotNil(t, cond) {
+		assert.Equal(t, metav1.ConditionFalse, cond.Status)
+		assert.Equal(t, "1/3 replicas available", cond.Message)
+	}
+}
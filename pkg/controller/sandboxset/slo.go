@@ -0,0 +1,119 @@
+package sandboxset
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+	"github.com/openkruise/agents/pkg/utils/conditions"
+	"github.com/openkruise/agents/pkg/utils/slo"
+)
+
+const (
+	// sloEvaluationInterval is how often the SLOs are evaluated again for the events leaving their window
+	sloEvaluationInterval = 30 * time.Second
+
+	sloClaimLatency  = "claim_latency"
+	sloWarmupSuccess = "warmup_success"
+	sloResumeLatency = "resume_latency"
+)
+
+// SandboxSetSLOBurnRate tracks the burn rate of the error budget of each SLO of each SandboxSet
+var SandboxSetSLOBurnRate = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "sandboxset_slo_burn_rate",
+		Help: "Rate at which the error budget of the SLO of the SandboxSet is consumed in its window, exhausted above 1",
+	},
+	[]string{"namespace", "name", "slo"},
+)
+
+func init() {
+	metrics.Registry.MustRegister(SandboxSetSLOBurnRate)
+}
+
+// evaluateSLO evaluates the SLOs of the SandboxSet on the events of the tracker in their window, and reports them in
+// status.slo and the SLOViolated condition.
+func evaluateSLO(newStatus *agentsv1alpha1.SandboxSetStatus, sbs *agentsv1alpha1.SandboxSet, tracker *slo.Tracker, now time.Time) {
+	builder := conditions.NewBuilder(&newStatus.Conditions, newStatus.ObservedGeneration)
+	spec := sbs.Spec.SLO
+	if spec == nil {
+		newStatus.SLO = nil
+		builder.Remove(string(agentsv1alpha1.SandboxSetConditionSLOViolated))
+		SandboxSetSLOBurnRate.DeletePartialMatch(prometheus.Labels{"namespace": sbs.Namespace, "name": sbs.Name})
+		return
+	}
+	window := time.Hour
+	if spec.Window != nil {
+		window = spec.Window.Duration
+	}
+	since := now.Add(-window)
+
+	newStatus.SLO = &agentsv1alpha1.SandboxSetSLOStatus{}
+	var violations []string
+	if objective := spec.ClaimLatency; objective != nil {
+		total, bad := tracker.ClaimLatency(sbs.Namespace, sbs.Name, objective.Target.Duration, since)
+		status, violated := evaluateObjective(sbs, sloClaimLatency, objective.Objective, total, bad)
+		newStatus.SLO.ClaimLatency = status
+		if violated {
+			violations = append(violations, fmt.Sprintf("claim latency: %d/%d claims slower than %v, burn rate %s of %s%% objective",
+				bad, total, objective.Target.Duration, status.BurnRate, objective.Objective))
+		}
+	} else {
+		SandboxSetSLOBurnRate.DeleteLabelValues(sbs.Namespace, sbs.Name, sloClaimLatency)
+	}
+	if spec.WarmupSuccess != "" {
+		total, bad := tracker.WarmupSuccess(sbs.Namespace, sbs.Name, since)
+		status, violated := evaluateObjective(sbs, sloWarmupSuccess, spec.WarmupSuccess, total, bad)
+		newStatus.SLO.WarmupSuccess = status
+		if violated {
+			violations = append(violations, fmt.Sprintf("warmup success: %d/%d sandboxes died while being created, burn rate %s of %s%% objective",
+				bad, total, status.BurnRate, spec.WarmupSuccess))
+		}
+	} else {
+		SandboxSetSLOBurnRate.DeleteLabelValues(sbs.Namespace, sbs.Name, sloWarmupSuccess)
+	}
+	if objective := spec.ResumeLatency; objective != nil {
+		total, bad := tracker.ResumeLatency(sbs.Namespace, sbs.Name, objective.Target.Duration, since)
+		status, violated := evaluateObjective(sbs, sloResumeLatency, objective.Objective, total, bad)
+		newStatus.SLO.ResumeLatency = status
+		if violated {
+			violations = append(violations, fmt.Sprintf("resume latency: %d/%d resumes slower than %v, burn rate %s of %s%% objective",
+				bad, total, objective.Target.Duration, status.BurnRate, objective.Objective))
+		}
+	} else {
+		SandboxSetSLOBurnRate.DeleteLabelValues(sbs.Namespace, sbs.Name, sloResumeLatency)
+	}
+
+	if len(violations) > 0 {
+		builder.True(string(agentsv1alpha1.SandboxSetConditionSLOViolated), agentsv1alpha1.SandboxSetSLOReasonBudgetExhausted,
+			fmt.Sprintf("Error budget exhausted in the last %v: %s", window, strings.Join(violations, "; ")))
+	} else {
+		builder.False(string(agentsv1alpha1.SandboxSetConditionSLOViolated), agentsv1alpha1.SandboxSetSLOReasonWithinBudget,
+			fmt.Sprintf("All SLOs are within their error budget in the last %v", window))
+	}
+}
+
+// evaluateObjective returns the status of an objective with the bad events out of the total, and whether its error
+// budget is exhausted
+func evaluateObjective(sbs *agentsv1alpha1.SandboxSet, name, objective string, total, bad int) (*agentsv1alpha1.SandboxSetObjectiveStatus, bool) {
+	burnRate, err := slo.BurnRate(objective, total, bad)
+	if err != nil {
+		// rejected by the CRD validation, just in case
+		burnRate = 0
+	}
+	SandboxSetSLOBurnRate.WithLabelValues(sbs.Namespace, sbs.Name, name).Set(burnRate)
+	formatted := "Inf"
+	if !math.IsInf(burnRate, 1) {
+		formatted = fmt.Sprintf("%.2f", burnRate)
+	}
+	return &agentsv1alpha1.SandboxSetObjectiveStatus{
+		Total:    int32(total),
+		Bad:      int32(bad),
+		BurnRate: formatted,
+	}, burnRate > 1
+}
@@ -0,0 +1,135 @@
+package sandboxset
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+	"github.com/openkruise/agents/pkg/utils/conditions"
+	"github.com/openkruise/agents/pkg/utils/slo"
+)
+
+func TestEvaluateSLO(t *testing.T) {
+	now := time.Now()
+	tracker := slo.NewTracker()
+	for range 99 {
+		tracker.ObserveClaim("default", "pool", time.Second, true, now.Add(-time.Minute))
+		tracker.ObserveWarmup("default", "pool", true, now.Add(-time.Minute))
+	}
+	tracker.ObserveClaim("default", "pool", time.Second, false, now.Add(-time.Minute))
+	tracker.ObserveClaim("default", "pool", time.Minute, true, now.Add(-2*time.Hour))
+	tracker.ObserveWarmup("default", "pool", false, now.Add(-time.Minute))
+	tracker.ObserveResume("default", "pool", time.Second, now.Add(-time.Minute))
+	tracker.ObserveResume("default", "pool", 30*time.Second, now.Add(-time.Minute))
+
+	tests := []struct {
+		name            string
+		slo             *agentsv1alpha1.SandboxSetSLO
+		expectViolated  *bool
+		expectReason    string
+		expectClaim     *agentsv1alpha1.SandboxSetObjectiveStatus
+		expectWarmup    *agentsv1alpha1.SandboxSetObjectiveStatus
+		expectResume    *agentsv1alpha1.SandboxSetObjectiveStatus
+		expectBurnRates map[string]float64
+	}{
+		{
+			name: "no slo",
+		},
+		{
+			name: "within budget",
+			slo: &agentsv1alpha1.SandboxSetSLO{
+				ClaimLatency: &agentsv1alpha1.SandboxSetLatencyObjective{
+					Target:    metav1.Duration{Duration: 5 * time.Second},
+					Objective: "98",
+				},
+				WarmupSuccess: "99",
+			},
+			expectViolated:  ptr.To(false),
+			expectReason:    agentsv1alpha1.SandboxSetSLOReasonWithinBudget,
+			expectClaim:     &agentsv1alpha1.SandboxSetObjectiveStatus{Total: 100, Bad: 1, BurnRate: "0.50"},
+			expectWarmup:    &agentsv1alpha1.SandboxSetObjectiveStatus{Total: 100, Bad: 1, BurnRate: "1.00"},
+			expectBurnRates: map[string]float64{sloClaimLatency: 0.5, sloWarmupSuccess: 1},
+		},
+		{
+			name: "claim latency budget exhausted",
+			slo: &agentsv1alpha1.SandboxSetSLO{
+				ClaimLatency: &agentsv1alpha1.SandboxSetLatencyObjective{
+					Target:    metav1.Duration{Duration: 5 * time.Second},
+					Objective: "99.9",
+				},
+			},
+			expectViolated:  ptr.To(true),
+			expectReason:    agentsv1alpha1.SandboxSetSLOReasonBudgetExhausted,
+			expectClaim:     &agentsv1alpha1.SandboxSetObjectiveStatus{Total: 100, Bad: 1, BurnRate: "10.00"},
+			expectBurnRates: map[string]float64{sloClaimLatency: 10},
+		},
+		{
+			name: "longer window",
+			slo: &agentsv1alpha1.SandboxSetSLO{
+				ClaimLatency: &agentsv1alpha1.SandboxSetLatencyObjective{
+					Target:    metav1.Duration{Duration: 5 * time.Second},
+					Objective: "99",
+				},
+				Window: &metav1.Duration{Duration: 3 * time.Hour},
+			},
+			expectViolated:  ptr.To(true),
+			expectReason:    agentsv1alpha1.SandboxSetSLOReasonBudgetExhausted,
+			expectClaim:     &agentsv1alpha1.SandboxSetObjectiveStatus{Total: 101, Bad: 2, BurnRate: "1.98"},
+			expectBurnRates: map[string]float64{sloClaimLatency: 2.0 / 101 / 0.01},
+		},
+		{
+			name: "resume latency budget exhausted",
+			slo: &agentsv1alpha1.SandboxSetSLO{
+				ResumeLatency: &agentsv1alpha1.SandboxSetLatencyObjective{
+					Target:    metav1.Duration{Duration: 10 * time.Second},
+					Objective: "90",
+				},
+			},
+			expectViolated:  ptr.To(true),
+			expectReason:    agentsv1alpha1.SandboxSetSLOReasonBudgetExhausted,
+			expectResume:    &agentsv1alpha1.SandboxSetObjectiveStatus{Total: 2, Bad: 1, BurnRate: "5.00"},
+			expectBurnRates: map[string]float64{sloResumeLatency: 5},
+		},
+		{
+			name:            "warmup success of 100 percent",
+			slo:             &agentsv1alpha1.SandboxSetSLO{WarmupSuccess: "100"},
+			expectViolated:  ptr.To(true),
+			expectReason:    agentsv1alpha1.SandboxSetSLOReasonBudgetExhausted,
+			expectWarmup:    &agentsv1alpha1.SandboxSetObjectiveStatus{Total: 100, Bad: 1, BurnRate: "Inf"},
+			expectBurnRates: map[string]float64{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sbs := &agentsv1alpha1.SandboxSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "pool", Namespace: "default"},
+				Spec:       agentsv1alpha1.SandboxSetSpec{SLO: tt.slo},
+			}
+			newStatus := &agentsv1alpha1.SandboxSetStatus{}
+			evaluateSLO(newStatus, sbs, tracker, now)
+
+			cond := conditions.Get(newStatus.Conditions, string(agentsv1alpha1.SandboxSetConditionSLOViolated))
+			if tt.expectViolated == nil {
+				assert.Nil(t, cond)
+				assert.Nil(t, newStatus.SLO)
+				return
+			}
+			require.NotNil(t, cond)
+			assert.Equal(t, *tt.expectViolated, cond.Status == metav1.ConditionTrue, cond.Message)
+			assert.Equal(t, tt.expectReason, cond.Reason)
+			require.NotNil(t, newStatus.SLO)
+			assert.Equal(t, tt.expectClaim, newStatus.SLO.ClaimLatency)
+			assert.Equal(t, tt.expectWarmup, newStatus.SLO.WarmupSuccess)
+			assert.Equal(t, tt.expectResume, newStatus.SLO.ResumeLatency)
+			for name, expect := range tt.expectBurnRates {
+				assert.InDelta(t, expect, testutil.ToFloat64(SandboxSetSLOBurnRate.WithLabelValues("default", "pool", name)), 1e-9)
+			}
+		})
+	}
+}
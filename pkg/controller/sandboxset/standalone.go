@@ -0,0 +1,51 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sandboxset
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+	"github.com/openkruise/agents/pkg/webhook/standalone"
+)
+
+// defaultAndValidate defaults and validates the SandboxSet as the webhooks would have, in the standalone validation
+// mode. It returns true if the SandboxSet is not to be reconciled further, as it is updated with the defaults and
+// reconciled again, or invalid until fixed.
+func (r *Reconciler) defaultAndValidate(ctx context.Context, sbs *agentsv1alpha1.SandboxSet) (bool, error) {
+	if standalone.DefaultSandboxSet(sbs) {
+		logf.FromContext(ctx).Info("Updating SandboxSet with the defaults of the standalone validation mode")
+		return true, r.Update(ctx, sbs)
+	}
+	if errList := standalone.ValidateSandboxSet(sbs); len(errList) > 0 {
+		r.reportInvalid(ctx, sbs, errList)
+		return true, nil
+	}
+	return false, nil
+}
+
+// reportInvalid reports the SandboxSet a webhook would have rejected, which is left as it is, including its sandboxes.
+func (r *Reconciler) reportInvalid(ctx context.Context, sbs *agentsv1alpha1.SandboxSet, errList field.ErrorList) {
+	err := errList.ToAggregate()
+	logf.FromContext(ctx).Info("Skipped SandboxSet rejected by the standalone validation", "reason", err.Error())
+	r.Recorder.Eventf(sbs, corev1.EventTypeWarning, standalone.EventValidationFailed,
+		"Not reconciled as the webhook would reject it: %s", err)
+}
@@ -0,0 +1,65 @@
+package sandboxset
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openkruise/agents/api/v1alpha1"
+	"github.com/openkruise/agents/pkg/webhook/standalone"
+)
+
+func TestReconcile_StandaloneValidation(t *testing.T) {
+	opts := standalone.DefaultOptions
+	t.Cleanup(func() { standalone.DefaultOptions = opts })
+	standalone.DefaultOptions.Enabled = true
+
+	ctx := context.Background()
+	k8sClient := NewClient()
+	sbs := getSandboxSet(2)
+	sbs.Name, sbs.UID = "standalone", "standalone-uid"
+	require.NoError(t, k8sClient.Create(ctx, sbs))
+	eventRecorder := record.NewFakeRecorder(10)
+	reconciler := &Reconciler{
+		Client:   k8sClient,
+		Scheme:   testScheme,
+		Recorder: eventRecorder,
+		Codec:    codec,
+	}
+	req := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(sbs)}
+	countSandboxes := func() int {
+		sandboxList := &v1alpha1.SandboxList{}
+		require.NoError(t, k8sClient.List(ctx, sandboxList, client.InNamespace(sbs.Namespace)))
+		return len(sandboxList.Items)
+	}
+
+	// the SandboxSet is updated with the defaults before creating sandboxes
+	_, err := reconciler.Reconcile(ctx, req)
+	require.NoError(t, err)
+	require.NoError(t, k8sClient.Get(ctx, req.NamespacedName, sbs))
+	assert.Equal(t, ptr.To(false), sbs.Spec.Template.Spec.AutomountServiceAccountToken)
+	assert.Equal(t, 0, countSandboxes())
+
+	_, err = reconciler.Reconcile(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, 2, countSandboxes())
+	for len(eventRecorder.Events) > 0 {
+		<-eventRecorder.Events
+	}
+
+	// the invalid SandboxSet is left as it is
+	require.NoError(t, k8sClient.Get(ctx, req.NamespacedName, sbs))
+	sbs.Spec.Replicas = 3
+	sbs.Spec.Template.Labels[v1alpha1.E2BPrefix+"reserved"] = "true"
+	require.NoError(t, k8sClient.Update(ctx, sbs))
+	_, err = reconciler.Reconcile(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, 2, countSandboxes())
+	assert.Contains(t, <-eventRecorder.Events, standalone.EventValidationFailed)
+}
@@ -13,6 +13,7 @@ import (
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 
 	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+	"github.com/openkruise/agents/pkg/utils/conditions"
 	"github.com/openkruise/agents/pkg/utils/expectations"
 )
 
@@ -53,6 +54,70 @@ func calculateSandboxSetStatusFromGroup(ctx context.Context, newStatus *agentsv1
 		"creating", len(groups.Creating), "dirtyCreating", len(dirtyScaleUp[expectations.Create]))
 }
 
+// stalledSandboxAge is how long a sandbox being created counts toward spec.minAvailable before it is stalled
+const stalledSandboxAge = 5 * time.Minute
+
+// calculateStalledReplicas counts the sandboxes still creating stalledSandboxAge after they were created into
+// status.stalledReplicas, if spec.minAvailable is set, and returns how long until the next one stalls.
+func calculateStalledReplicas(newStatus *agentsv1alpha1.SandboxSetStatus, sbs *agentsv1alpha1.SandboxSet, groups GroupedSandboxes,
+	now time.Time) time.Duration {
+	newStatus.StalledReplicas = 0
+	if sbs.Spec.MinAvailable == 0 {
+		return 0
+	}
+	var next time.Duration
+	for _, sbx := range groups.Creating {
+		if left := sbx.CreationTimestamp.Add(stalledSandboxAge).Sub(now); left <= 0 {
+			newStatus.StalledReplicas++
+		} else if next == 0 || left < next {
+			next = left
+		}
+	}
+	return next
+}
+
+// minAvailableReplicas returns the number of unclaimed sandboxes keeping spec.minAvailable, i.e. replacing the
+// stalled ones, which do not count toward it. At most spec.minAvailable of them are replaced, so that a template
+// whose sandboxes never become available does not grow the SandboxSet without bound.
+func minAvailableReplicas(sbs *agentsv1alpha1.SandboxSet, newStatus *agentsv1alpha1.SandboxSetStatus) int32 {
+	if sbs.Spec.MinAvailable == 0 {
+		return 0
+	}
+	return sbs.Spec.MinAvailable + min(newStatus.StalledReplicas, sbs.Spec.MinAvailable)
+}
+
+// setSandboxSetReadyCondition marks the SandboxSet Ready once the desired replicas, autoscaled if enabled, and
+// spec.minAvailable are all available to be claimed.
+func setSandboxSetReadyCondition(newStatus *agentsv1alpha1.SandboxSetStatus, sbs *agentsv1alpha1.SandboxSet) {
+	builder := conditions.NewBuilder(&newStatus.Conditions, newStatus.ObservedGeneration)
+	desired := max(desiredReplicas(sbs, newStatus), sbs.Spec.MinAvailable)
+	message := fmt.Sprintf("%d/%d replicas available", newStatus.AvailableReplicas, desired)
+	if newStatus.AvailableReplicas >= desired {
+		builder.True(string(agentsv1alpha1.SandboxSetConditionReady), agentsv1alpha1.SandboxSetReadyReasonAllAvailable, message)
+	} else {
+		builder.False(string(agentsv1alpha1.SandboxSetConditionReady), agentsv1alpha1.SandboxSetReadyReasonReplicasNotReady, message)
+	}
+}
+
+// setSandboxSetPausedCondition reports whether the SandboxSet is paused, and how draining its unclaimed sandboxes
+// goes if drainOnPause is set.
+func setSandboxSetPausedCondition(newStatus *agentsv1alpha1.SandboxSetStatus, sbs *agentsv1alpha1.SandboxSet) {
+	builder := conditions.NewBuilder(&newStatus.Conditions, newStatus.ObservedGeneration)
+	switch {
+	case !sbs.Spec.Paused:
+		builder.Remove(string(agentsv1alpha1.SandboxSetConditionPaused))
+	case !sbs.Spec.DrainOnPause:
+		builder.True(string(agentsv1alpha1.SandboxSetConditionPaused), agentsv1alpha1.SandboxSetPausedReasonPaused,
+			"Creating sandboxes is paused")
+	case newStatus.Replicas > 0:
+		builder.True(string(agentsv1alpha1.SandboxSetConditionPaused), agentsv1alpha1.SandboxSetPausedReasonDraining,
+			fmt.Sprintf("Creating sandboxes is paused, draining %d unclaimed sandbox(es)", newStatus.Replicas))
+	default:
+		builder.True(string(agentsv1alpha1.SandboxSetConditionPaused), agentsv1alpha1.SandboxSetPausedReasonDrained,
+			"Creating sandboxes is paused, all unclaimed sandboxes are drained")
+	}
+}
+
 /* Just Reserved for SandboxAutoScaler
 func calculateExpectPoolSize(ctx context.Context, total, unused int32, sbs *agentsv1alpha1.SandboxSet) (int32, error) {
 	log := klog.FromContext(ctx).V(consts.DebugLogLevel)
@@ -169,6 +234,7 @@ func NewSandboxFromSandboxSet(sbs *agentsv1alpha1.SandboxSet) *agentsv1alpha1.Sa
 		Spec: agentsv1alpha1.SandboxSpec{
 			PersistentContents: sbs.Spec.PersistentContents,
 			Runtimes:           sbs.Spec.Runtimes,
+			ReadinessProbe:     sbs.Spec.ReadinessProbe.DeepCopy(),
 			EmbeddedSandboxTemplate: agentsv1alpha1.EmbeddedSandboxTemplate{
 				TemplateRef:          sbs.Spec.TemplateRef,
 				Template:             template,
@@ -181,6 +247,9 @@ func NewSandboxFromSandboxSet(sbs *agentsv1alpha1.SandboxSet) *agentsv1alpha1.Sa
 	sbx.Labels[agentsv1alpha1.LabelSandboxPool] = sbs.Name
 	sbx.Labels[agentsv1alpha1.LabelSandboxTemplate] = sbs.Name
 	sbx.Labels[agentsv1alpha1.LabelSandboxIsClaimed] = "false"
+	if region := sbs.Labels[agentsv1alpha1.LabelSandboxRegion]; region != "" {
+		sbx.Labels[agentsv1alpha1.LabelSandboxRegion] = region
+	}
 	if sbs.Spec.TemplateRef != nil {
 		sbx.Labels[agentsv1alpha1.LabelSandboxTemplate] = sbs.Spec.TemplateRef.Name
 	} else {
@@ -22,6 +22,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
@@ -338,6 +339,30 @@ func TestNewSandboxFromSandboxSet(t *testing.T) {
 			expectedTemplateRef:        nil,
 			expectedPersistentContents: nil,
 		},
+		{
+			name: "sandboxset with region",
+			sandboxSet: &agentsv1alpha1.SandboxSet{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-sbs",
+					Namespace: "default",
+					Labels:    map[string]string{agentsv1alpha1.LabelSandboxRegion: "us-east"},
+				},
+				Spec: agentsv1alpha1.SandboxSetSpec{
+					EmbeddedSandboxTemplate: agentsv1alpha1.EmbeddedSandboxTemplate{
+						Template: &corev1.PodTemplateSpec{},
+					},
+				},
+			},
+			expectedGenerateName: "test-sbs-",
+			expectedNamespace:    "default",
+			expectedLabels: map[string]string{
+				agentsv1alpha1.LabelSandboxPool:      "test-sbs",
+				agentsv1alpha1.LabelSandboxTemplate:  "test-sbs",
+				agentsv1alpha1.LabelSandboxIsClaimed: "false",
+				agentsv1alpha1.LabelSandboxRegion:    "us-east",
+			},
+			expectedAnnotations: map[string]string{},
+		},
 		{
 			name: "sandboxset with templateRef",
 			sandboxSet: &agentsv1alpha1.SandboxSet{
@@ -516,3 +541,63 @@ func TestNewSandboxFromSandboxSet(t *testing.T) {
 		})
 	}
 }
+
+func TestSetSandboxSetPausedCondition(t *testing.T) {
+	tests := []struct {
+		name         string
+		paused       bool
+		drainOnPause bool
+		replicas     int32
+		expectReason string
+	}{
+		{
+			name:     "not paused",
+			replicas: 2,
+		},
+		{
+			name:         "paused",
+			paused:       true,
+			replicas:     2,
+			expectReason: agentsv1alpha1.SandboxSetPausedReasonPaused,
+		},
+		{
+			name:         "draining",
+			paused:       true,
+			drainOnPause: true,
+			replicas:     2,
+			expectReason: agentsv1alpha1.SandboxSetPausedReasonDraining,
+		},
+		{
+			name:         "drained",
+			paused:       true,
+			drainOnPause: true,
+			expectReason: agentsv1alpha1.SandboxSetPausedReasonDrained,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sbs := &agentsv1alpha1.SandboxSet{
+				Spec: agentsv1alpha1.SandboxSetSpec{Paused: tt.paused, DrainOnPause: tt.drainOnPause},
+			}
+			newStatus := &agentsv1alpha1.SandboxSetStatus{
+				Replicas: tt.replicas,
+				// left by a former pause
+				Conditions: []metav1.Condition{{
+					Type:   string(agentsv1alpha1.SandboxSetConditionPaused),
+					Status: metav1.ConditionTrue,
+					Reason: agentsv1alpha1.SandboxSetPausedReasonPaused,
+				}},
+			}
+			setSandboxSetPausedCondition(newStatus, sbs)
+			cond := meta.FindStatusCondition(newStatus.Conditions, string(agentsv1alpha1.SandboxSetConditionPaused))
+			if tt.expectReason == "" {
+				assert.Nil(t, cond)
+				return
+			}
+			if assert.NotNil(t, cond) {
+				assert.Equal(t, metav1.ConditionTrue, cond.Status)
+				assert.Equal(t, tt.expectReason, cond.Reason)
+			}
+		})
+	}
+}
@@ -0,0 +1,242 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sandboxtemplate
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+	"github.com/openkruise/agents/pkg/discovery"
+	"github.com/openkruise/agents/pkg/features"
+	"github.com/openkruise/agents/pkg/utils/controllermetrics"
+	utilfeature "github.com/openkruise/agents/pkg/utils/feature"
+	"github.com/openkruise/agents/pkg/utils/webhookutils"
+)
+
+var (
+	controllerKind = agentsv1alpha1.SandboxTemplateControllerKind
+	// secretResyncInterval is the interval between syncs of the propagated Secrets of a template, which corrects the
+	// drift of the copies and follows the changes of the sources without caching all Secrets
+	secretResyncInterval = 5 * time.Minute
+)
+
+const (
+	EventSecretPropagated = "SecretPropagated"
+	EventSecretSynced     = "SecretSynced"
+	EventSecretConflict   = "SecretConflict"
+)
+
+func Add(mgr manager.Manager) error {
+	if !utilfeature.DefaultFeatureGate.Enabled(features.SandboxTemplateSecretPropagationGate) || !discovery.DiscoverGVK(controllerKind) {
+		return nil
+	}
+	err := (&Reconciler{
+		Client:          mgr.GetClient(),
+		SecretReader:    mgr.GetAPIReader(),
+		SystemNamespace: webhookutils.GetNamespace(),
+	}).SetupWithManager(mgr)
+	if err != nil {
+		return err
+	}
+	klog.Infof("Started SandboxTemplateReconciler successfully")
+	return nil
+}
+
+// Reconciler propagates the shareable Secrets of the system namespace referenced by a SandboxTemplate into the
+// namespace of the template once a SandboxSet uses it, so that templates shared by copying them into other namespaces
+// do not fail to create pods for missing Secrets.
+type Reconciler struct {
+	client.Client
+	// SecretReader reads Secrets from the API server, so that the controller does not cache all Secrets
+	SecretReader    client.Reader
+	SystemNamespace string
+	Recorder        record.EventRecorder
+}
+
+// +kubebuilder:rbac:groups=agents.kruise.io,resources=sandboxtemplates,verbs=get;list;watch
+// +kubebuilder:rbac:groups=agents.kruise.io,resources=sandboxsets,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;create;update;delete
+// +kubebuilder:rbac:groups=core,resources=events,verbs=create;update;patch
+
+func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := logf.FromContext(ctx).WithValues("sandboxtemplate", req.NamespacedName)
+	template := &agentsv1alpha1.SandboxTemplate{}
+	if err := r.Get(ctx, req.NamespacedName, template); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if template.DeletionTimestamp != nil || template.Namespace == r.SystemNamespace {
+		return ctrl.Result{}, nil
+	}
+	names := referencedSecrets(template.Spec.Template)
+	if len(names) == 0 {
+		return ctrl.Result{}, nil
+	}
+	used, err := r.isUsedBySandboxSets(ctx, template)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if !used {
+		log.V(1).Info("SandboxTemplate is not used by any SandboxSet, skip propagating secrets")
+		return ctrl.Result{}, nil
+	}
+	for _, name := range names {
+		if err := r.propagateSecret(ctx, template, name); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to propagate secret %s: %w", name, err)
+		}
+	}
+	return ctrl.Result{RequeueAfter: secretResyncInterval}, nil
+}
+
+// isUsedBySandboxSets checks if any SandboxSet of the namespace of the template references it
+func (r *Reconciler) isUsedBySandboxSets(ctx context.Context, template *agentsv1alpha1.SandboxTemplate) (bool, error) {
+	sbsList := &agentsv1alpha1.SandboxSetList{}
+	if err := r.List(ctx, sbsList, client.InNamespace(template.Namespace)); err != nil {
+		return false, err
+	}
+	for i := range sbsList.Items {
+		if ref := sbsList.Items[i].Spec.TemplateRef; ref != nil && ref.Name == template.Name {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// propagateSecret copies the shareable Secret of the system namespace into the namespace of the template, or syncs
+// the copy if it drifted from the source. Secrets of the namespace that are not copies are never overwritten.
+func (r *Reconciler) propagateSecret(ctx context.Context, template *agentsv1alpha1.SandboxTemplate, name string) error {
+	log := logf.FromContext(ctx).WithValues("sandboxtemplate", klog.KObj(template), "secret", name)
+	source := &corev1.Secret{}
+	if err := r.SecretReader.Get(ctx, client.ObjectKey{Namespace: r.SystemNamespace, Name: name}, source); err != nil {
+		// the Secret is expected in the namespace of the template
+		return client.IgnoreNotFound(err)
+	}
+	if source.Labels[agentsv1alpha1.LabelShareableSecret] != agentsv1alpha1.True {
+		return nil
+	}
+	hash := hashSecret(source)
+
+	existing := &corev1.Secret{}
+	err := r.SecretReader.Get(ctx, client.ObjectKey{Namespace: template.Namespace, Name: name}, existing)
+	if apierrors.IsNotFound(err) {
+		if err := r.Create(ctx, r.newPropagatedSecret(template, source, hash)); err != nil {
+			return err
+		}
+		log.Info("Propagated secret from system namespace")
+		r.Recorder.Eventf(template, corev1.EventTypeNormal, EventSecretPropagated,
+			"Copied secret %s from namespace %s", name, r.SystemNamespace)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if existing.Labels[agentsv1alpha1.LabelPropagatedSecret] != agentsv1alpha1.True {
+		log.Info("Secret exists and is not propagated, skip propagating")
+		r.Recorder.Eventf(template, corev1.EventTypeWarning, EventSecretConflict,
+			"Secret %s already exists and is not a copy of namespace %s", name, r.SystemNamespace)
+		return nil
+	}
+	if hashSecret(existing) == hash && existing.Annotations[agentsv1alpha1.AnnotationSecretHash] == hash {
+		if owned, err := controllerutil.HasOwnerReference(existing.OwnerReferences, template, r.Scheme()); err != nil || owned {
+			return err
+		}
+		// the copy is shared by another template of the namespace
+		if err := controllerutil.SetOwnerReference(template, existing, r.Scheme()); err != nil {
+			return err
+		}
+		return r.Update(ctx, existing)
+	}
+	if existing.Type != source.Type {
+		// the type of a Secret is immutable
+		if err := r.Delete(ctx, existing); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+		if err := r.Create(ctx, r.newPropagatedSecret(template, source, hash)); err != nil {
+			return err
+		}
+	} else {
+		existing.Data = source.Data
+		if existing.Annotations == nil {
+			existing.Annotations = map[string]string{}
+		}
+		existing.Annotations[agentsv1alpha1.AnnotationSecretHash] = hash
+		if err := controllerutil.SetOwnerReference(template, existing, r.Scheme()); err != nil {
+			return err
+		}
+		if err := r.Update(ctx, existing); err != nil {
+			return err
+		}
+	}
+	log.Info("Synced drifted secret with system namespace")
+	r.Recorder.Eventf(template, corev1.EventTypeNormal, EventSecretSynced,
+		"Synced secret %s with namespace %s", name, r.SystemNamespace)
+	return nil
+}
+
+// newPropagatedSecret builds the copy of the source Secret in the namespace of the template, owned by the template
+func (r *Reconciler) newPropagatedSecret(template *agentsv1alpha1.SandboxTemplate, source *corev1.Secret, hash string) *corev1.Secret {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   template.Namespace,
+			Name:        source.Name,
+			Labels:      map[string]string{agentsv1alpha1.LabelPropagatedSecret: agentsv1alpha1.True},
+			Annotations: map[string]string{agentsv1alpha1.AnnotationSecretHash: hash},
+		},
+		Type: source.Type,
+		Data: source.Data,
+	}
+	// never fails, the template is namespaced as the secret
+	_ = controllerutil.SetOwnerReference(template, secret, r.Scheme())
+	return secret
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
+	controllerName := "sandboxtemplate-controller"
+	r.Recorder = mgr.GetEventRecorderFor(controllerName)
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(controllerName).
+		WithOptions(controller.Options{NewQueue: controllermetrics.NewQueue}).
+		For(&agentsv1alpha1.SandboxTemplate{}).
+		Watches(&agentsv1alpha1.SandboxSet{}, handler.EnqueueRequestsFromMapFunc(mapSandboxSetToSandboxTemplate)).
+		Complete(controllermetrics.Wrap(controllerName, r))
+}
+
+// mapSandboxSetToSandboxTemplate enqueues the SandboxTemplate referenced by the SandboxSet, so that its secrets are
+// propagated once it is used.
+func mapSandboxSetToSandboxTemplate(_ context.Context, obj client.Object) []reconcile.Request {
+	sbs, ok := obj.(*agentsv1alpha1.SandboxSet)
+	if !ok || sbs.Spec.TemplateRef == nil {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: client.ObjectKey{Namespace: sbs.Namespace, Name: sbs.Spec.TemplateRef.Name}}}
+}
@@ -0,0 +1,202 @@
+package sandboxtemplate
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/openkruise/agents/api/v1alpha1"
+)
+
+var testScheme *runtime.Scheme
+
+func init() {
+	testScheme = runtime.NewScheme()
+	_ = v1alpha1.AddToScheme(testScheme)
+	_ = corev1.AddToScheme(testScheme)
+}
+
+const systemNamespace = "sandbox-system"
+
+func newTemplate() *v1alpha1.SandboxTemplate {
+	return &v1alpha1.SandboxTemplate{
+		ObjectMeta: metav1.ObjectMeta{Name: "tpl", Namespace: "default", UID: "tpl-uid"},
+		Spec: v1alpha1.SandboxTemplateSpec{
+			Template: &corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					ImagePullSecrets: []corev1.LocalObjectReference{{Name: "registry"}},
+					Containers:       []corev1.Container{{Name: "main", Image: "nginx"}},
+				},
+			},
+		},
+	}
+}
+
+func newSandboxSet(templateName string) *v1alpha1.SandboxSet {
+	return &v1alpha1.SandboxSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "pool", Namespace: "default"},
+		Spec: v1alpha1.SandboxSetSpec{
+			EmbeddedSandboxTemplate: v1alpha1.EmbeddedSandboxTemplate{
+				TemplateRef: &v1alpha1.SandboxTemplateRef{Name: templateName},
+			},
+		},
+	}
+}
+
+func newSecret(namespace string, labels map[string]string, data string) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "registry", Namespace: namespace, Labels: labels},
+		Type:       corev1.SecretTypeDockerConfigJson,
+		Data:       map[string][]byte{corev1.DockerConfigJsonKey: []byte(data)},
+	}
+}
+
+func shareable() map[string]string {
+	return map[string]string{v1alpha1.LabelShareableSecret: v1alpha1.True}
+}
+
+func propagated() map[string]string {
+	return map[string]string{v1alpha1.LabelPropagatedSecret: v1alpha1.True}
+}
+
+func TestReconcile(t *testing.T) {
+	tests := []struct {
+		name         string
+		objects      []client.Object
+		expectSecret *corev1.Secret
+		expectEvent  string
+		expectResult ctrl.Result
+	}{
+		{
+			name: "propagate shareable secret",
+			objects: []client.Object{
+				newTemplate(), newSandboxSet("tpl"), newSecret(systemNamespace, shareable(), "v1"),
+			},
+			expectSecret: newSecret("default", propagated(), "v1"),
+			expectEvent:  EventSecretPropagated,
+			expectResult: ctrl.Result{RequeueAfter: secretResyncInterval},
+		},
+		{
+			name: "sync drifted secret",
+			objects: []client.Object{
+				newTemplate(), newSandboxSet("tpl"), newSecret(systemNamespace, shareable(), "v2"),
+				newSecret("default", propagated(), "v1"),
+			},
+			expectSecret: newSecret("default", propagated(), "v2"),
+			expectEvent:  EventSecretSynced,
+			expectResult: ctrl.Result{RequeueAfter: secretResyncInterval},
+		},
+		{
+			name: "never overwrite secret not propagated",
+			objects: []client.Object{
+				newTemplate(), newSandboxSet("tpl"), newSecret(systemNamespace, shareable(), "v2"),
+				newSecret("default", nil, "v1"),
+			},
+			expectSecret: newSecret("default", nil, "v1"),
+			expectEvent:  EventSecretConflict,
+			expectResult: ctrl.Result{RequeueAfter: secretResyncInterval},
+		},
+		{
+			name: "skip secret not shareable",
+			objects: []client.Object{
+				newTemplate(), newSandboxSet("tpl"), newSecret(systemNamespace, nil, "v1"),
+			},
+			expectResult: ctrl.Result{RequeueAfter: secretResyncInterval},
+		},
+		{
+			name: "skip template not used by sandboxsets",
+			objects: []client.Object{
+				newTemplate(), newSandboxSet("other"), newSecret(systemNamespace, shareable(), "v1"),
+			},
+		},
+		{
+			name:    "template not found",
+			objects: []client.Object{newSecret(systemNamespace, shareable(), "v1")},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := fake.NewClientBuilder().WithScheme(testScheme).WithObjects(tt.objects...).Build()
+			eventRecorder := record.NewFakeRecorder(10)
+			r := &Reconciler{
+				Client:          c,
+				SecretReader:    c,
+				SystemNamespace: systemNamespace,
+				Recorder:        eventRecorder,
+			}
+			result, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "tpl"}})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectResult, result)
+
+			secret := &corev1.Secret{}
+			err = c.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: "registry"}, secret)
+			if tt.expectSecret == nil {
+				assert.True(t, client.IgnoreNotFound(err) == nil && err != nil, "secret should not be propagated")
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectSecret.Labels, secret.Labels)
+				assert.Equal(t, tt.expectSecret.Data, secret.Data)
+				if tt.expectSecret.Labels[v1alpha1.LabelPropagatedSecret] == v1alpha1.True {
+					assert.Equal(t, hashSecret(tt.expectSecret), secret.Annotations[v1alpha1.AnnotationSecretHash])
+					assert.Len(t, secret.OwnerReferences, 1)
+					assert.Equal(t, types.UID("tpl-uid"), secret.OwnerReferences[0].UID)
+				}
+			}
+			select {
+			case event := <-eventRecorder.Events:
+				assert.Contains(t, event, tt.expectEvent)
+			default:
+				assert.Empty(t, tt.expectEvent, "expected event not recorded")
+			}
+		})
+	}
+}
+
+func TestReferencedSecrets(t *testing.T) {
+	template := &corev1.PodTemplateSpec{
+		Spec: corev1.PodSpec{
+			ImagePullSecrets: []corev1.LocalObjectReference{{Name: "registry"}},
+			Volumes: []corev1.Volume{
+				{Name: "certs", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "certs"}}},
+				{Name: "projected", VolumeSource: corev1.VolumeSource{Projected: &corev1.ProjectedVolumeSource{
+					Sources: []corev1.VolumeProjection{{Secret: &corev1.SecretProjection{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "projected"}}}},
+				}}},
+				{Name: "empty", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}},
+			},
+			InitContainers: []corev1.Container{{
+				Name: "init",
+				EnvFrom: []corev1.EnvFromSource{{SecretRef: &corev1.SecretEnvSource{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "init-env"}}}},
+			}},
+			Containers: []corev1.Container{{
+				Name: "main",
+				Env: []corev1.EnvVar{
+					{Name: "TOKEN", ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "registry"}, Key: "token"}}},
+					{Name: "PLAIN", Value: "value"},
+				},
+			}},
+		},
+	}
+	assert.Equal(t, []string{"certs", "init-env", "projected", "registry"}, referencedSecrets(template))
+	assert.Nil(t, referencedSecrets(nil))
+}
+
+func TestHashSecret(t *testing.T) {
+	a := newSecret("a", nil, "v1")
+	b := newSecret("b", shareable(), "v1")
+	assert.Equal(t, hashSecret(a), hashSecret(b), "hash ignores metadata")
+	b.Type = corev1.SecretTypeOpaque
+	assert.NotEqual(t, hashSecret(a), hashSecret(b))
+	assert.NotEqual(t, hashSecret(a), hashSecret(newSecret("a", nil, "v2")))
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sandboxtemplate
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"maps"
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// referencedSecrets returns the sorted names of the Secrets referenced by the pod template, i.e. its imagePullSecrets
+// and the Secrets of its volumes, env and envFrom.
+func referencedSecrets(template *corev1.PodTemplateSpec) []string {
+	if template == nil {
+		return nil
+	}
+	names := sets.New[string]()
+	for _, ref := range template.Spec.ImagePullSecrets {
+		names.Insert(ref.Name)
+	}
+	for _, volume := range template.Spec.Volumes {
+		if volume.Secret != nil {
+			names.Insert(volume.Secret.SecretName)
+		}
+		if volume.Projected != nil {
+			for _, source := range volume.Projected.Sources {
+				if source.Secret != nil {
+					names.Insert(source.Secret.Name)
+				}
+			}
+		}
+	}
+	for _, container := range slices.Concat(template.Spec.InitContainers, template.Spec.Containers) {
+		for _, env := range container.Env {
+			if env.ValueFrom != nil && env.ValueFrom.SecretKeyRef != nil {
+				names.Insert(env.ValueFrom.SecretKeyRef.Name)
+			}
+		}
+		for _, envFrom := range container.EnvFrom {
+			if envFrom.SecretRef != nil {
+				names.Insert(envFrom.SecretRef.Name)
+			}
+		}
+	}
+	names.Delete("")
+	return sets.List(names)
+}
+
+// hashSecret hashes the type and data of the Secret
+func hashSecret(secret *corev1.Secret) string {
+	h := sha256.New()
+	h.Write([]byte(secret.Type))
+	for _, key := range slices.Sorted(maps.Keys(secret.Data)) {
+		h.Write([]byte{0})
+		h.Write([]byte(key))
+		h.Write([]byte{0})
+		h.Write(secret.Data[key])
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,289 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduledsandboxclaim
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+	"github.com/openkruise/agents/pkg/discovery"
+	"github.com/openkruise/agents/pkg/features"
+	"github.com/openkruise/agents/pkg/utils/conditions"
+	"github.com/openkruise/agents/pkg/utils/controllermetrics"
+	utilfeature "github.com/openkruise/agents/pkg/utils/feature"
+	"github.com/openkruise/agents/pkg/utils/statusupdater"
+)
+
+var (
+	controllerKind = agentsv1alpha1.ScheduledSandboxClaimControllerKind
+	statusUpdater  = statusupdater.New()
+)
+
+const (
+	// maxWalkedSchedules is the number of missed schedules walked through one by one to find the most recent one,
+	// beyond which the schedules are skipped by the interval between them
+	maxWalkedSchedules = 100
+
+	EventClaimCreated    = "ClaimCreated"
+	EventClaimDeleted    = "ClaimDeleted"
+	EventInvalidSchedule = "InvalidSchedule"
+)
+
+func Add(mgr manager.Manager) error {
+	if !utilfeature.DefaultFeatureGate.Enabled(features.ScheduledSandboxClaimGate) || !discovery.DiscoverGVK(controllerKind) {
+		return nil
+	}
+	err := (&Reconciler{
+		Client: mgr.GetClient(),
+		now:    time.Now,
+	}).SetupWithManager(mgr)
+	if err != nil {
+		return err
+	}
+	klog.Infof("Started ScheduledSandboxClaimReconciler successfully")
+	return nil
+}
+
+// Reconciler creates the SandboxClaims of a ScheduledSandboxClaim on its cron schedule, e.g. to warm up sandboxes
+// before business hours, and deletes the completed ones beyond its history limit.
+type Reconciler struct {
+	client.Client
+	Recorder record.EventRecorder
+
+	// now is replaced by tests
+	now func() time.Time
+}
+
+// +kubebuilder:rbac:groups=agents.kruise.io,resources=scheduledsandboxclaims,verbs=get;list;watch
+// +kubebuilder:rbac:groups=agents.kruise.io,resources=scheduledsandboxclaims/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=agents.kruise.io,resources=sandboxclaims,verbs=get;list;watch;create;delete
+// +kubebuilder:rbac:groups=core,resources=events,verbs=create;update;patch
+
+func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := logf.FromContext(ctx).WithValues("scheduledsandboxclaim", req.NamespacedName)
+	ctx = logf.IntoContext(ctx, log)
+	scheduled := &agentsv1alpha1.ScheduledSandboxClaim{}
+	if err := r.Get(ctx, req.NamespacedName, scheduled); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if scheduled.DeletionTimestamp != nil {
+		return ctrl.Result{}, nil
+	}
+	if err := r.cleanupClaims(ctx, scheduled); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	newStatus := scheduled.Status.DeepCopy()
+	builder := conditions.NewBuilder(&newStatus.Conditions, scheduled.Generation)
+	invalidSchedule := string(agentsv1alpha1.ScheduledSandboxClaimConditionInvalidSchedule)
+	schedule, err := parseSchedule(&scheduled.Spec)
+	if err != nil {
+		log.Info("Failed to parse schedule", "schedule", scheduled.Spec.Schedule, "error", err.Error())
+		r.Recorder.Eventf(scheduled, corev1.EventTypeWarning, EventInvalidSchedule, "Failed to parse schedule: %v", err)
+		builder.True(invalidSchedule, EventInvalidSchedule, err.Error())
+		// the spec has to be changed to fix the schedule, which triggers another reconcile
+		return ctrl.Result{}, r.updateStatus(ctx, scheduled, newStatus)
+	}
+	builder.Remove(invalidSchedule)
+
+	now := r.now()
+	if !scheduled.Spec.Suspend {
+		if scheduleTime, ok := mostRecentScheduleTime(schedule, earliestScheduleTime(scheduled, now), now); ok {
+			claimName, err := r.createClaim(ctx, scheduled, scheduleTime)
+			if err != nil {
+				return ctrl.Result{}, err
+			}
+			newStatus.LastScheduleTime = &metav1.Time{Time: scheduleTime}
+			newStatus.LastClaimName = claimName
+		}
+	}
+	if err = r.updateStatus(ctx, scheduled, newStatus); err != nil {
+		return ctrl.Result{}, err
+	}
+	if scheduled.Spec.Suspend {
+		return ctrl.Result{}, nil
+	}
+	next := schedule.Next(now)
+	log.V(1).Info("Wait for the next schedule", "next", next)
+	// requeue slightly after the schedule, so that the schedule is not missed for a clock running early
+	return ctrl.Result{RequeueAfter: next.Sub(now) + 100*time.Millisecond}, nil
+}
+
+// parseSchedule parses the cron schedule of the spec in its time zone
+func parseSchedule(spec *agentsv1alpha1.ScheduledSandboxClaimSpec) (cron.Schedule, error) {
+	schedule := spec.Schedule
+	if spec.TimeZone != nil {
+		if _, err := time.LoadLocation(*spec.TimeZone); err != nil {
+			return nil, fmt.Errorf("unknown time zone %s: %w", *spec.TimeZone, err)
+		}
+		schedule = fmt.Sprintf("CRON_TZ=%s %s", *spec.TimeZone, schedule)
+	}
+	return cron.ParseStandard(schedule)
+}
+
+// earliestScheduleTime returns the time after which the schedules are due, i.e. the last schedule time or the
+// creation of the ScheduledSandboxClaim, limited by the starting deadline
+func earliestScheduleTime(scheduled *agentsv1alpha1.ScheduledSandboxClaim, now time.Time) time.Time {
+	earliest := scheduled.CreationTimestamp.Time
+	if scheduled.Status.LastScheduleTime != nil {
+		earliest = scheduled.Status.LastScheduleTime.Time
+	}
+	if deadline := scheduled.Spec.StartingDeadlineSeconds; deadline != nil {
+		if startingDeadline := now.Add(-time.Duration(*deadline) * time.Second); startingDeadline.After(earliest) {
+			earliest = startingDeadline
+		}
+	}
+	return earliest
+}
+
+// mostRecentScheduleTime returns the most recent schedule time after earliest and not after now. Only the most recent
+// schedule is due, the ones missed before it, e.g. while the controller was down, are skipped.
+func mostRecentScheduleTime(schedule cron.Schedule, earliest, now time.Time) (time.Time, bool) {
+	var mostRecent time.Time
+	walked := 0
+	for t := schedule.Next(earliest); !t.After(now); t = schedule.Next(t) {
+		mostRecent = t
+		if walked++; walked < maxWalkedSchedules {
+			continue
+		}
+		// skip ahead by the interval of the schedules instead of walking through all of them, which is exact for the
+		// schedules of a fixed interval and falls back to the last schedule walked through for the others
+		if interval := schedule.Next(t).Sub(t); interval > 0 {
+			if skipped := now.Sub(t)/interval - 1; skipped > 0 {
+				t = t.Add(skipped * interval)
+			}
+		}
+		walked = 0
+	}
+	return mostRecent, !mostRecent.IsZero()
+}
+
+// claimName returns the name of the SandboxClaim of the schedule, which is deterministic so that the claim of a
+// schedule is never created twice
+func claimName(scheduled *agentsv1alpha1.ScheduledSandboxClaim, scheduleTime time.Time) string {
+	return fmt.Sprintf("%s-%d", scheduled.Name, scheduleTime.Unix()/60)
+}
+
+// createClaim creates the SandboxClaim of the schedule from the template, and returns its name
+func (r *Reconciler) createClaim(ctx context.Context, scheduled *agentsv1alpha1.ScheduledSandboxClaim, scheduleTime time.Time) (string, error) {
+	log := logf.FromContext(ctx)
+	template := &scheduled.Spec.ClaimTemplate
+	claim := &agentsv1alpha1.SandboxClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       scheduled.Namespace,
+			Name:            claimName(scheduled, scheduleTime),
+			Labels:          maps.Clone(template.Labels),
+			Annotations:     maps.Clone(template.Annotations),
+			OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(scheduled, controllerKind)},
+		},
+		Spec: *template.Spec.DeepCopy(),
+	}
+	if claim.Labels == nil {
+		claim.Labels = map[string]string{}
+	}
+	claim.Labels[agentsv1alpha1.LabelScheduledSandboxClaimName] = scheduled.Name
+	if claim.Annotations == nil {
+		claim.Annotations = map[string]string{}
+	}
+	claim.Annotations[agentsv1alpha1.AnnotationScheduledTime] = scheduleTime.UTC().Format(time.RFC3339)
+
+	err := r.Create(ctx, claim)
+	if apierrors.IsAlreadyExists(err) {
+		// created by a previous reconcile that failed to update the status
+		log.V(1).Info("SandboxClaim of the schedule already exists", "sandboxclaim", claim.Name)
+		return claim.Name, nil
+	} else if err != nil {
+		return "", fmt.Errorf("failed to create sandboxclaim %s: %w", claim.Name, err)
+	}
+	log.Info("Created SandboxClaim on schedule", "sandboxclaim", claim.Name, "scheduleTime", scheduleTime)
+	r.Recorder.Eventf(scheduled, corev1.EventTypeNormal, EventClaimCreated, "Created SandboxClaim %s scheduled at %s",
+		claim.Name, scheduleTime.UTC().Format(time.RFC3339))
+	return claim.Name, nil
+}
+
+// cleanupClaims deletes the oldest completed or failed SandboxClaims created by the ScheduledSandboxClaim beyond its history
+// limit, the ones still claiming are never deleted
+func (r *Reconciler) cleanupClaims(ctx context.Context, scheduled *agentsv1alpha1.ScheduledSandboxClaim) error {
+	limit := int32(3)
+	if scheduled.Spec.HistoryLimit != nil {
+		limit = *scheduled.Spec.HistoryLimit
+	}
+	claimList := &agentsv1alpha1.SandboxClaimList{}
+	if err := r.List(ctx, claimList, client.InNamespace(scheduled.Namespace),
+		client.MatchingLabels{agentsv1alpha1.LabelScheduledSandboxClaimName: scheduled.Name}); err != nil {
+		return err
+	}
+	var completed []*agentsv1alpha1.SandboxClaim
+	for i := range claimList.Items {
+		claim := &claimList.Items[i]
+		finished := claim.Status.Phase == agentsv1alpha1.SandboxClaimPhaseCompleted || claim.Status.Phase == agentsv1alpha1.SandboxClaimPhaseFailed
+		if claim.DeletionTimestamp == nil && metav1.IsControlledBy(claim, scheduled) && finished {
+			completed = append(completed, claim)
+		}
+	}
+	if len(completed) <= int(limit) {
+		return nil
+	}
+	slices.SortFunc(completed, func(a, b *agentsv1alpha1.SandboxClaim) int {
+		return cmp.Or(a.CreationTimestamp.Compare(b.CreationTimestamp.Time), cmp.Compare(a.Name, b.Name))
+	})
+	for _, claim := range completed[:len(completed)-int(limit)] {
+		if err := r.Delete(ctx, claim); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("failed to delete sandboxclaim %s: %w", claim.Name, err)
+		}
+		logf.FromContext(ctx).Info("Deleted SandboxClaim beyond history limit", "sandboxclaim", claim.Name)
+		r.Recorder.Eventf(scheduled, corev1.EventTypeNormal, EventClaimDeleted, "Deleted completed SandboxClaim %s", claim.Name)
+	}
+	return nil
+}
+
+func (r *Reconciler) updateStatus(ctx context.Context, scheduled *agentsv1alpha1.ScheduledSandboxClaim, newStatus *agentsv1alpha1.ScheduledSandboxClaimStatus) error {
+	obj := &agentsv1alpha1.ScheduledSandboxClaim{
+		ObjectMeta: metav1.ObjectMeta{Namespace: scheduled.Namespace, Name: scheduled.Name},
+	}
+	_, err := statusUpdater.Patch(ctx, r.Client, obj, scheduled.Status, newStatus)
+	return client.IgnoreNotFound(err)
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
+	controllerName := "scheduledsandboxclaim-controller"
+	r.Recorder = mgr.GetEventRecorderFor(controllerName)
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(controllerName).
+		WithOptions(controller.Options{NewQueue: controllermetrics.NewQueue}).
+		For(&agentsv1alpha1.ScheduledSandboxClaim{}).
+		Owns(&agentsv1alpha1.SandboxClaim{}).
+		Complete(controllermetrics.Wrap(controllerName, r))
+}
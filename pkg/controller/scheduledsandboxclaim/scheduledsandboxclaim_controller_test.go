@@ -0,0 +1,285 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduledsandboxclaim
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/openkruise/agents/api/v1alpha1"
+	"github.com/openkruise/agents/pkg/utils/conditions"
+)
+
+var testScheme *runtime.Scheme
+
+func init() {
+	testScheme = runtime.NewScheme()
+	_ = v1alpha1.AddToScheme(testScheme)
+}
+
+// 2025-06-02 is a Monday
+var created = time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+
+func newScheduledClaim(mutate func(*v1alpha1.ScheduledSandboxClaim)) *v1alpha1.ScheduledSandboxClaim {
+	scheduled := &v1alpha1.ScheduledSandboxClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "warmup",
+			Namespace:         "default",
+			UID:               "warmup-uid",
+			CreationTimestamp: metav1.Time{Time: created},
+		},
+		Spec: v1alpha1.ScheduledSandboxClaimSpec{
+			Schedule: "0 8 * * 1-5",
+			ClaimTemplate: v1alpha1.SandboxClaimTemplate{
+				Labels: map[string]string{"team": "a"},
+				Spec:   v1alpha1.SandboxClaimSpec{TemplateName: "pool", Replicas: ptr.To[int32](10)},
+			},
+		},
+	}
+	if mutate != nil {
+		mutate(scheduled)
+	}
+	return scheduled
+}
+
+func newOwnedClaim(scheduled *v1alpha1.ScheduledSandboxClaim, name string, age time.Duration, phase v1alpha1.SandboxClaimPhase) *v1alpha1.SandboxClaim {
+	return &v1alpha1.SandboxClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         scheduled.Namespace,
+			CreationTimestamp: metav1.Time{Time: created.Add(age)},
+			Labels:            map[string]string{v1alpha1.LabelScheduledSandboxClaimName: scheduled.Name},
+			OwnerReferences:   []metav1.OwnerReference{*metav1.NewControllerRef(scheduled, controllerKind)},
+		},
+		Status: v1alpha1.SandboxClaimStatus{Phase: phase},
+	}
+}
+
+func TestReconcile(t *testing.T) {
+	tests := []struct {
+		name             string
+		scheduled        *v1alpha1.ScheduledSandboxClaim
+		claims           []client.Object
+		now              time.Time
+		expectClaim      string
+		expectLastClaim  string
+		expectClaims     []string
+		expectInvalid    bool
+		expectRequeueMin time.Duration
+	}{
+		{
+			name:             "nothing scheduled yet",
+			scheduled:        newScheduledClaim(nil),
+			now:              time.Date(2025, 6, 2, 7, 0, 0, 0, time.UTC),
+			expectRequeueMin: time.Hour,
+		},
+		{
+			name:             "create claim on schedule",
+			scheduled:        newScheduledClaim(nil),
+			now:              time.Date(2025, 6, 2, 8, 0, 5, 0, time.UTC),
+			expectClaim:      "warmup-29147520",
+			expectLastClaim:  "warmup-29147520",
+			expectClaims:     []string{"warmup-29147520"},
+			expectRequeueMin: 23 * time.Hour,
+		},
+		{
+			name: "create claim of the most recent missed schedule only",
+			scheduled: newScheduledClaim(func(s *v1alpha1.ScheduledSandboxClaim) {
+				s.Status.LastScheduleTime = &metav1.Time{Time: time.Date(2025, 6, 2, 8, 0, 0, 0, time.UTC)}
+				s.Status.LastClaimName = "warmup-29147520"
+			}),
+			now:              time.Date(2025, 6, 5, 9, 0, 0, 0, time.UTC),
+			expectClaim:      "warmup-29151840",
+			expectLastClaim:  "warmup-29151840",
+			expectClaims:     []string{"warmup-29151840"},
+			expectRequeueMin: 22 * time.Hour,
+		},
+		{
+			name: "skip schedule missed beyond the starting deadline",
+			scheduled: newScheduledClaim(func(s *v1alpha1.ScheduledSandboxClaim) {
+				s.Spec.StartingDeadlineSeconds = ptr.To[int64](600)
+			}),
+			now: time.Date(2025, 6, 2, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "schedule in time zone",
+			scheduled: newScheduledClaim(func(s *v1alpha1.ScheduledSandboxClaim) {
+				s.Spec.TimeZone = ptr.To("Asia/Shanghai")
+			}),
+			now:             time.Date(2025, 6, 2, 0, 0, 1, 0, time.UTC),
+			expectClaim:     "warmup-29147040",
+			expectLastClaim: "warmup-29147040",
+			expectClaims:    []string{"warmup-29147040"},
+		},
+		{
+			name: "suspended",
+			scheduled: newScheduledClaim(func(s *v1alpha1.ScheduledSandboxClaim) {
+				s.Spec.Suspend = true
+			}),
+			now: time.Date(2025, 6, 2, 8, 0, 5, 0, time.UTC),
+		},
+		{
+			name: "invalid schedule",
+			scheduled: newScheduledClaim(func(s *v1alpha1.ScheduledSandboxClaim) {
+				s.Spec.Schedule = "every morning"
+			}),
+			now:           time.Date(2025, 6, 2, 8, 0, 5, 0, time.UTC),
+			expectInvalid: true,
+		},
+		{
+			name: "invalid time zone",
+			scheduled: newScheduledClaim(func(s *v1alpha1.ScheduledSandboxClaim) {
+				s.Spec.TimeZone = ptr.To("Mars/Olympus")
+			}),
+			now:           time.Date(2025, 6, 2, 8, 0, 5, 0, time.UTC),
+			expectInvalid: true,
+		},
+		{
+			name: "delete completed claims beyond history limit",
+			scheduled: newScheduledClaim(func(s *v1alpha1.ScheduledSandboxClaim) {
+				s.Spec.HistoryLimit = ptr.To[int32](1)
+				s.Status.LastScheduleTime = &metav1.Time{Time: time.Date(2025, 6, 2, 8, 0, 0, 0, time.UTC)}
+			}),
+			claims: []client.Object{
+				newOwnedClaim(newScheduledClaim(nil), "old", time.Hour, v1alpha1.SandboxClaimPhaseCompleted),
+				newOwnedClaim(newScheduledClaim(nil), "older", time.Minute, v1alpha1.SandboxClaimPhaseCompleted),
+				newOwnedClaim(newScheduledClaim(nil), "recent", 2*time.Hour, v1alpha1.SandboxClaimPhaseCompleted),
+				newOwnedClaim(newScheduledClaim(nil), "claiming", 0, v1alpha1.SandboxClaimPhaseClaiming),
+			},
+			now:          time.Date(2025, 6, 2, 9, 0, 0, 0, time.UTC),
+			expectClaims: []string{"claiming", "recent"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeClient := fake.NewClientBuilder().WithScheme(testScheme).
+				WithObjects(append(tt.claims, tt.scheduled)...).
+				WithStatusSubresource(&v1alpha1.ScheduledSandboxClaim{}).Build()
+			r := &Reconciler{
+				Client:   fakeClient,
+				Recorder: record.NewFakeRecorder(10),
+				now:      func() time.Time { return tt.now },
+			}
+			key := types.NamespacedName{Namespace: tt.scheduled.Namespace, Name: tt.scheduled.Name}
+			result, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+			require.NoError(t, err)
+			assert.GreaterOrEqual(t, result.RequeueAfter, tt.expectRequeueMin)
+
+			claims := &v1alpha1.SandboxClaimList{}
+			require.NoError(t, fakeClient.List(context.Background(), claims))
+			var names []string
+			for _, claim := range claims.Items {
+				names = append(names, claim.Name)
+			}
+			assert.ElementsMatch(t, tt.expectClaims, names)
+			if tt.expectClaim != "" {
+				claim := &v1alpha1.SandboxClaim{}
+				require.NoError(t, fakeClient.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: tt.expectClaim}, claim))
+				assert.Equal(t, "a", claim.Labels["team"])
+				assert.Equal(t, "warmup", claim.Labels[v1alpha1.LabelScheduledSandboxClaimName])
+				assert.NotEmpty(t, claim.Annotations[v1alpha1.AnnotationScheduledTime])
+				assert.True(t, metav1.IsControlledBy(claim, tt.scheduled))
+				assert.Equal(t, "pool", claim.Spec.TemplateName)
+			}
+
+			scheduled := &v1alpha1.ScheduledSandboxClaim{}
+			require.NoError(t, fakeClient.Get(context.Background(), key, scheduled))
+			assert.Equal(t, tt.expectLastClaim, scheduled.Status.LastClaimName)
+			assert.Equal(t, tt.expectInvalid, conditions.IsTrue(scheduled.Status.Conditions,
+				string(v1alpha1.ScheduledSandboxClaimConditionInvalidSchedule)))
+		})
+	}
+}
+
+func TestReconcile_ClaimAlreadyCreated(t *testing.T) {
+	scheduled := newScheduledClaim(nil)
+	existing := newOwnedClaim(scheduled, "warmup-29147520", 0, v1alpha1.SandboxClaimPhaseClaiming)
+	fakeClient := fake.NewClientBuilder().WithScheme(testScheme).WithObjects(scheduled, existing).
+		WithStatusSubresource(&v1alpha1.ScheduledSandboxClaim{}).Build()
+	recorder := record.NewFakeRecorder(10)
+	r := &Reconciler{
+		Client:   fakeClient,
+		Recorder: recorder,
+		now:      func() time.Time { return time.Date(2025, 6, 2, 8, 1, 0, 0, time.UTC) },
+	}
+	key := types.NamespacedName{Namespace: scheduled.Namespace, Name: scheduled.Name}
+	_, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+	require.NoError(t, err)
+
+	require.NoError(t, fakeClient.Get(context.Background(), key, scheduled))
+	assert.Equal(t, "warmup-29147520", scheduled.Status.LastClaimName)
+	assert.Empty(t, len(recorder.Events), "the claim created by a previous reconcile is not reported again")
+}
+
+func TestMostRecentScheduleTime(t *testing.T) {
+	everyMinute, err := cron.ParseStandard("* * * * *")
+	require.NoError(t, err)
+	morningMinutes, err := cron.ParseStandard("* 8 * * *")
+	require.NoError(t, err)
+	earliest := time.Date(2025, 6, 1, 0, 0, 30, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		schedule cron.Schedule
+		now      time.Time
+		expect   time.Time
+	}{
+		{
+			name:     "none due",
+			schedule: everyMinute,
+			now:      earliest.Add(10 * time.Second),
+		},
+		{
+			name:     "one due",
+			schedule: everyMinute,
+			now:      earliest.Add(time.Minute),
+			expect:   time.Date(2025, 6, 1, 0, 1, 0, 0, time.UTC),
+		},
+		{
+			name:     "many missed of a fixed interval",
+			schedule: everyMinute,
+			now:      time.Date(2026, 6, 1, 12, 0, 30, 0, time.UTC),
+			expect:   time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "many missed of irregular intervals",
+			schedule: morningMinutes,
+			now:      time.Date(2025, 6, 1, 9, 30, 0, 0, time.UTC),
+			expect:   time.Date(2025, 6, 1, 8, 59, 0, 0, time.UTC),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := mostRecentScheduleTime(tt.schedule, earliest, tt.now)
+			assert.Equal(t, !tt.expect.IsZero(), ok)
+			assert.Equal(t, tt.expect, got)
+		})
+	}
+}
@@ -42,15 +42,53 @@ const (
 	// CachePodLabelSelectorGate enables label selector filtering on the Pod informer cache
 	// to reduce memory consumption.
 	CachePodLabelSelectorGate featuregate.Feature = "CachePodLabelSelector"
+
+	// SandboxClaimPolicyGate enable webhook to evaluate CEL and OPA admission policies of SandboxClaims.
+	SandboxClaimPolicyGate featuregate.Feature = "SandboxClaimPolicy"
+
+	// SandboxUsageSamplingGate enable Sandbox-controller to sample the resource usage of sandboxes from metrics-server.
+	SandboxUsageSamplingGate featuregate.Feature = "SandboxUsageSampling"
+
+	// SandboxClaimPriorityQueueGate enable SandboxClaim-controller to reconcile urgent and interactive claims before
+	// batch claims when its workqueue is deep.
+	SandboxClaimPriorityQueueGate featuregate.Feature = "SandboxClaimPriorityQueue"
+
+	// SandboxClaimAdaptiveTimeoutGate defaults the claimTimeout of SandboxClaims from the claim latencies observed
+	// for their templates instead of a static constant.
+	SandboxClaimAdaptiveTimeoutGate featuregate.Feature = "SandboxClaimAdaptiveTimeout"
+
+	// SandboxTemplateSecretPropagationGate enable SandboxTemplate-controller to copy the shareable Secrets of the system
+	// namespace referenced by SandboxTemplates into the namespaces of the templates used by SandboxSets.
+	SandboxTemplateSecretPropagationGate featuregate.Feature = "SandboxTemplateSecretPropagation"
+
+	// SandboxSetNodePoolsGate enable SandboxSet-controller to distribute sandboxes across the node pools of
+	// SandboxSets by weight, watching the nodes of the cluster.
+	SandboxSetNodePoolsGate featuregate.Feature = "SandboxSetNodePools"
+
+	// ScheduledSandboxClaimGate enable ScheduledSandboxClaim-controller to create SandboxClaims on the cron schedules
+	// of ScheduledSandboxClaims.
+	ScheduledSandboxClaimGate featuregate.Feature = "ScheduledSandboxClaim"
+
+	// SandboxSetAutoscalingGate enable SandboxSet-controller to grow SandboxSets with spec.autoscaling by the pressure
+	// of their SandboxClaims, watching the SandboxClaims of the cluster.
+	SandboxSetAutoscalingGate featuregate.Feature = "SandboxSetAutoscaling"
 )
 
 var defaultFeatureGates = map[featuregate.Feature]featuregate.FeatureSpec{
-	SandboxGate:                      {Default: true, PreRelease: featuregate.Alpha},
-	SandboxSetGate:                   {Default: true, PreRelease: featuregate.Alpha},
-	SandboxClaimGate:                 {Default: true, PreRelease: featuregate.Alpha},
-	SandboxCreatePodRateLimitGate:    {Default: false, PreRelease: featuregate.Alpha},
-	SandboxCreatePodInjectConfigGate: {Default: false, PreRelease: featuregate.Alpha},
-	CachePodLabelSelectorGate:        {Default: true, PreRelease: featuregate.Alpha},
+	SandboxGate:                          {Default: true, PreRelease: featuregate.Alpha},
+	SandboxSetGate:                       {Default: true, PreRelease: featuregate.Alpha},
+	SandboxClaimGate:                     {Default: true, PreRelease: featuregate.Alpha},
+	SandboxCreatePodRateLimitGate:        {Default: false, PreRelease: featuregate.Alpha},
+	SandboxCreatePodInjectConfigGate:     {Default: false, PreRelease: featuregate.Alpha},
+	CachePodLabelSelectorGate:            {Default: true, PreRelease: featuregate.Alpha},
+	SandboxClaimPolicyGate:               {Default: false, PreRelease: featuregate.Alpha},
+	SandboxUsageSamplingGate:             {Default: false, PreRelease: featuregate.Alpha},
+	SandboxClaimPriorityQueueGate:        {Default: false, PreRelease: featuregate.Alpha},
+	SandboxClaimAdaptiveTimeoutGate:      {Default: false, PreRelease: featuregate.Alpha},
+	SandboxTemplateSecretPropagationGate: {Default: false, PreRelease: featuregate.Alpha},
+	SandboxSetNodePoolsGate:              {Default: false, PreRelease: featuregate.Alpha},
+	ScheduledSandboxClaimGate:            {Default: false, PreRelease: featuregate.Alpha},
+	SandboxSetAutoscalingGate:            {Default: false, PreRelease: featuregate.Alpha},
 }
 
 func init() {
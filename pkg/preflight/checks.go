@@ -0,0 +1,276 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package preflight
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/component-base/featuregate"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+	"github.com/openkruise/agents/pkg/features"
+)
+
+// operatorKinds are the kinds served by the CRDs of the operator, with the feature gates of the controllers requiring
+// them. The CRDs of enabled controllers are required, the others are only warned about.
+var operatorKinds = []struct {
+	kind  string
+	gates []featuregate.Feature
+}{
+	{kind: "Sandbox", gates: []featuregate.Feature{features.SandboxGate}},
+	{kind: "SandboxSet", gates: []featuregate.Feature{features.SandboxSetGate}},
+	{kind: "SandboxClaim", gates: []featuregate.Feature{features.SandboxClaimGate}},
+	{kind: "SandboxTemplate", gates: []featuregate.Feature{features.SandboxSetGate, features.SandboxTemplateSecretPropagationGate}},
+	{kind: "Checkpoint"},
+	{kind: "OperatorStatus"},
+}
+
+// featureDependencies maps the feature gates to the ones they do not work without
+var featureDependencies = map[featuregate.Feature][]featuregate.Feature{
+	features.SandboxSetGate:                       {features.SandboxGate},
+	features.SandboxClaimGate:                     {features.SandboxSetGate},
+	features.SandboxCreatePodRateLimitGate:        {features.SandboxGate},
+	features.SandboxCreatePodInjectConfigGate:     {features.SandboxGate},
+	features.SandboxUsageSamplingGate:             {features.SandboxGate},
+	features.SandboxClaimPolicyGate:               {features.SandboxClaimGate},
+	features.SandboxClaimPriorityQueueGate:        {features.SandboxClaimGate},
+	features.SandboxClaimAdaptiveTimeoutGate:      {features.SandboxClaimGate},
+	features.SandboxTemplateSecretPropagationGate: {features.SandboxSetGate},
+	features.SandboxSetNodePoolsGate:              {features.SandboxSetGate},
+}
+
+// requiredPermissions are the permissions the controller manager does not work without. Permissions of the system
+// namespace have an empty namespace here.
+var requiredPermissions = []authorizationv1.ResourceAttributes{
+	{Group: agentsv1alpha1.GroupVersion.Group, Resource: "sandboxes", Verb: "create"},
+	{Group: agentsv1alpha1.GroupVersion.Group, Resource: "sandboxes", Verb: "update"},
+	{Group: agentsv1alpha1.GroupVersion.Group, Resource: "sandboxes", Verb: "delete"},
+	{Group: agentsv1alpha1.GroupVersion.Group, Resource: "sandboxsets", Verb: "watch"},
+	{Group: agentsv1alpha1.GroupVersion.Group, Resource: "sandboxclaims", Verb: "watch"},
+	{Group: agentsv1alpha1.GroupVersion.Group, Resource: "sandboxclaims", Subresource: "status", Verb: "update"},
+	{Resource: "pods", Verb: "create"},
+	{Resource: "pods", Verb: "delete"},
+	{Resource: "events", Verb: "create"},
+	{Group: "coordination.k8s.io", Resource: "leases", Verb: "update"},
+}
+
+// metricsGroupVersion is the group version of the resource metrics API served by metrics-server
+const metricsGroupVersion = "metrics.k8s.io/v1beta1"
+
+// checkCRDs checks that the kinds of the operator are served
+func checkCRDs(_ context.Context, env *Env) (agentsv1alpha1.PreflightCheckResult, string) {
+	served := sets.New[string]()
+	resources, err := env.Discovery.ServerResourcesForGroupVersion(agentsv1alpha1.GroupVersion.String())
+	if err != nil && !apierrors.IsNotFound(err) {
+		return agentsv1alpha1.PreflightCheckFail, fmt.Sprintf("failed to discover %s: %v", agentsv1alpha1.GroupVersion, err)
+	}
+	if resources != nil {
+		for _, resource := range resources.APIResources {
+			served.Insert(resource.Kind)
+		}
+	}
+	var missing, optional []string
+	for _, kind := range operatorKinds {
+		if served.Has(kind.kind) {
+			continue
+		}
+		if anyEnabled(env.FeatureGate, kind.gates) {
+			missing = append(missing, kind.kind)
+		} else {
+			optional = append(optional, kind.kind)
+		}
+	}
+	if len(missing) > 0 {
+		return agentsv1alpha1.PreflightCheckFail, fmt.Sprintf("CRDs of enabled controllers are not installed: %s", strings.Join(missing, ", "))
+	}
+	if len(optional) > 0 {
+		return agentsv1alpha1.PreflightCheckWarn, fmt.Sprintf("CRDs are not installed: %s", strings.Join(optional, ", "))
+	}
+	return agentsv1alpha1.PreflightCheckPass, ""
+}
+
+// checkWebhook checks that the Service of the webhook server has ready endpoints, so that the API server can reach it
+func checkWebhook(ctx context.Context, env *Env) (agentsv1alpha1.PreflightCheckResult, string) {
+	endpointSlices, err := env.Kube.DiscoveryV1().EndpointSlices(env.Namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=%s", discoveryv1.LabelServiceName, env.WebhookService),
+	})
+	if err != nil {
+		return agentsv1alpha1.PreflightCheckFail, fmt.Sprintf("failed to list endpoints of service %s/%s: %v", env.Namespace, env.WebhookService, err)
+	}
+	for _, slice := range endpointSlices.Items {
+		for _, endpoint := range slice.Endpoints {
+			if endpoint.Conditions.Ready == nil || *endpoint.Conditions.Ready {
+				return agentsv1alpha1.PreflightCheckPass, ""
+			}
+		}
+	}
+	return agentsv1alpha1.PreflightCheckFail, fmt.Sprintf("service %s/%s of the webhook server has no ready endpoint", env.Namespace, env.WebhookService)
+}
+
+// checkRBAC checks that the user of the operator is granted the required permissions
+func checkRBAC(ctx context.Context, env *Env) (agentsv1alpha1.PreflightCheckResult, string) {
+	var denied []string
+	for _, permission := range requiredPermissions {
+		if permission.Resource == "leases" {
+			permission.Namespace = env.Namespace
+		}
+		allowed, err := reviewAccess(ctx, env, &permission)
+		if err != nil {
+			return agentsv1alpha1.PreflightCheckFail, fmt.Sprintf("failed to review access: %v", err)
+		}
+		if !allowed {
+			denied = append(denied, describePermission(&permission))
+		}
+	}
+	if len(denied) > 0 {
+		return agentsv1alpha1.PreflightCheckFail, fmt.Sprintf("permissions not granted: %s", strings.Join(denied, ", "))
+	}
+	return agentsv1alpha1.PreflightCheckPass, ""
+}
+
+// reviewAccess reviews the access of the user of the operator, or of the user running the checks if unset
+func reviewAccess(ctx context.Context, env *Env, attributes *authorizationv1.ResourceAttributes) (bool, error) {
+	if env.User == "" {
+		review, err := env.Kube.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, &authorizationv1.SelfSubjectAccessReview{
+			Spec: authorizationv1.SelfSubjectAccessReviewSpec{ResourceAttributes: attributes},
+		}, metav1.CreateOptions{})
+		if err != nil {
+			return false, err
+		}
+		return review.Status.Allowed, nil
+	}
+	review, err := env.Kube.AuthorizationV1().SubjectAccessReviews().Create(ctx, &authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{User: env.User, ResourceAttributes: attributes},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return false, err
+	}
+	return review.Status.Allowed, nil
+}
+
+// describePermission formats the permission like `verb resource[/subresource][.group]`
+func describePermission(attributes *authorizationv1.ResourceAttributes) string {
+	resource := attributes.Resource
+	if attributes.Subresource != "" {
+		resource += "/" + attributes.Subresource
+	}
+	if attributes.Group != "" {
+		resource += "." + attributes.Group
+	}
+	return attributes.Verb + " " + resource
+}
+
+// checkRuntimeClasses checks that the RuntimeClasses of the pod templates of the SandboxSets and SandboxTemplates
+// exist. Sandboxes of pools with a missing RuntimeClass cannot be created, while the others are not affected.
+func checkRuntimeClasses(ctx context.Context, env *Env) (agentsv1alpha1.PreflightCheckResult, string) {
+	referenced := map[string][]string{}
+	reference := func(template *corev1.PodTemplateSpec, kind, namespace, name string) {
+		if template != nil && template.Spec.RuntimeClassName != nil && *template.Spec.RuntimeClassName != "" {
+			referenced[*template.Spec.RuntimeClassName] = append(referenced[*template.Spec.RuntimeClassName],
+				fmt.Sprintf("%s %s/%s", kind, namespace, name))
+		}
+	}
+	sandboxSets := &agentsv1alpha1.SandboxSetList{}
+	if err := env.Reader.List(ctx, sandboxSets); err != nil && !isNotServed(err) {
+		return agentsv1alpha1.PreflightCheckFail, fmt.Sprintf("failed to list sandboxsets: %v", err)
+	}
+	for i := range sandboxSets.Items {
+		sbs := &sandboxSets.Items[i]
+		reference(sbs.Spec.Template, "SandboxSet", sbs.Namespace, sbs.Name)
+	}
+	templates := &agentsv1alpha1.SandboxTemplateList{}
+	if err := env.Reader.List(ctx, templates); err != nil && !isNotServed(err) {
+		return agentsv1alpha1.PreflightCheckFail, fmt.Sprintf("failed to list sandboxtemplates: %v", err)
+	}
+	for i := range templates.Items {
+		template := &templates.Items[i]
+		reference(template.Spec.Template, "SandboxTemplate", template.Namespace, template.Name)
+	}
+
+	var missing []string
+	for _, name := range sets.List(sets.KeySet(referenced)) {
+		_, err := env.Kube.NodeV1().RuntimeClasses().Get(ctx, name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			missing = append(missing, fmt.Sprintf("%s (used by %s)", name, strings.Join(referenced[name], ", ")))
+			continue
+		}
+		if err != nil {
+			return agentsv1alpha1.PreflightCheckFail, fmt.Sprintf("failed to get runtimeclass %s: %v", name, err)
+		}
+	}
+	if len(missing) > 0 {
+		return agentsv1alpha1.PreflightCheckWarn, fmt.Sprintf("RuntimeClasses not found: %s", strings.Join(missing, "; "))
+	}
+	return agentsv1alpha1.PreflightCheckPass, ""
+}
+
+// checkMetricsAPI checks that the resource metrics API is served if the usage of sandboxes is sampled from it
+func checkMetricsAPI(_ context.Context, env *Env) (agentsv1alpha1.PreflightCheckResult, string) {
+	_, err := env.Discovery.ServerResourcesForGroupVersion(metricsGroupVersion)
+	if err == nil {
+		return agentsv1alpha1.PreflightCheckPass, ""
+	}
+	if !env.FeatureGate.Enabled(features.SandboxUsageSamplingGate) {
+		return agentsv1alpha1.PreflightCheckPass, fmt.Sprintf("%s is not served, only required by feature gate %s",
+			metricsGroupVersion, features.SandboxUsageSamplingGate)
+	}
+	return agentsv1alpha1.PreflightCheckWarn, fmt.Sprintf("%s is not served, feature gate %s samples no usage: %v",
+		metricsGroupVersion, features.SandboxUsageSamplingGate, err)
+}
+
+// checkFeatureGates checks that the enabled feature gates do not depend on disabled ones
+func checkFeatureGates(_ context.Context, env *Env) (agentsv1alpha1.PreflightCheckResult, string) {
+	var inconsistent []string
+	for _, gate := range sets.List(sets.KeySet(featureDependencies)) {
+		if !env.FeatureGate.Enabled(gate) {
+			continue
+		}
+		for _, dependency := range featureDependencies[gate] {
+			if !env.FeatureGate.Enabled(dependency) {
+				inconsistent = append(inconsistent, fmt.Sprintf("%s requires %s", gate, dependency))
+			}
+		}
+	}
+	if len(inconsistent) > 0 {
+		return agentsv1alpha1.PreflightCheckFail, fmt.Sprintf("enabled feature gates depend on disabled ones: %s", strings.Join(inconsistent, ", "))
+	}
+	return agentsv1alpha1.PreflightCheckPass, ""
+}
+
+// anyEnabled checks if any of the feature gates is enabled
+func anyEnabled(gate featuregate.FeatureGate, features []featuregate.Feature) bool {
+	for _, feature := range features {
+		if gate.Enabled(feature) {
+			return true
+		}
+	}
+	return false
+}
+
+// isNotServed checks if the error is returned for a kind whose CRD is not installed, which the CRDs check reports
+func isNotServed(err error) bool {
+	return apierrors.IsNotFound(err) || meta.IsNoMatchError(err)
+}
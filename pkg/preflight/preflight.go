@@ -0,0 +1,106 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package preflight
+
+import (
+	"context"
+	"slices"
+
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/component-base/featuregate"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+)
+
+// Env holds the clients and the configuration of the operator the checks run with
+type Env struct {
+	Kube      kubernetes.Interface
+	Discovery discovery.DiscoveryInterface
+	// Reader reads the objects of the operator from the API server
+	Reader      client.Reader
+	FeatureGate featuregate.FeatureGate
+	// Namespace is the namespace the operator is deployed in
+	Namespace string
+	// WebhookService is the name of the Service of the webhook server in Namespace
+	WebhookService string
+	// User is the user the RBAC of the operator is checked for, e.g. its service account, the user running the
+	// checks if empty
+	User string
+}
+
+// Check verifies a prerequisite of the cluster, and returns its result with a message describing the unmet
+// prerequisites.
+type Check struct {
+	Name string
+	// Startup checks are run by the controller manager at startup as well, the others depend on the manager running
+	Startup bool
+	Run     func(ctx context.Context, env *Env) (agentsv1alpha1.PreflightCheckResult, string)
+}
+
+// Checks are all the preflight checks in the order they are run
+var Checks = []Check{
+	{Name: "CRDs", Startup: true, Run: checkCRDs},
+	{Name: "Webhook", Run: checkWebhook},
+	{Name: "RBAC", Startup: true, Run: checkRBAC},
+	{Name: "RuntimeClasses", Startup: true, Run: checkRuntimeClasses},
+	{Name: "MetricsAPI", Startup: true, Run: checkMetricsAPI},
+	{Name: "FeatureGates", Startup: true, Run: checkFeatureGates},
+}
+
+// StartupChecks returns the checks run by the controller manager at startup
+func StartupChecks() []Check {
+	return slices.DeleteFunc(slices.Clone(Checks), func(check Check) bool {
+		return !check.Startup
+	})
+}
+
+// Report is the machine-readable report of the preflight checks
+type Report struct {
+	// Passed is true if no check failed
+	Passed bool                            `json:"passed"`
+	Checks []agentsv1alpha1.PreflightCheck `json:"checks"`
+}
+
+// Failed returns the names of the failed checks
+func (r *Report) Failed() []string {
+	var failed []string
+	for _, check := range r.Checks {
+		if check.Result == agentsv1alpha1.PreflightCheckFail {
+			failed = append(failed, check.Name)
+		}
+	}
+	return failed
+}
+
+// Run runs the checks one by one and reports their results
+func Run(ctx context.Context, env *Env, checks []Check) *Report {
+	report := &Report{Passed: true, Checks: make([]agentsv1alpha1.PreflightCheck, 0, len(checks))}
+	for _, check := range checks {
+		result, message := check.Run(ctx, env)
+		if result == agentsv1alpha1.PreflightCheckFail {
+			report.Passed = false
+		}
+		report.Checks = append(report.Checks, agentsv1alpha1.PreflightCheck{
+			Name:    check.Name,
+			Result:  result,
+			Message: message,
+		})
+	}
+	return report
+}
@@ -0,0 +1,320 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package preflight
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	nodev1 "k8s.io/api/node/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/component-base/featuregate"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlfake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+	"github.com/openkruise/agents/pkg/features"
+	"github.com/openkruise/agents/pkg/utils/conditions"
+	utilfeature "github.com/openkruise/agents/pkg/utils/feature"
+)
+
+var testScheme = runtime.NewScheme()
+
+func init() {
+	_ = agentsv1alpha1.AddToScheme(testScheme)
+}
+
+func newFeatureGate(t *testing.T, gates map[string]bool) featuregate.FeatureGate {
+	gate := utilfeature.DefaultMutableFeatureGate.DeepCopy()
+	require.NoError(t, gate.SetFromMap(gates))
+	return gate
+}
+
+func newEnv(t *testing.T, gates map[string]bool, kinds []string, objects ...runtime.Object) (*Env, *fake.Clientset) {
+	kube := fake.NewClientset(objects...)
+	resources := &metav1.APIResourceList{GroupVersion: agentsv1alpha1.GroupVersion.String()}
+	for _, kind := range kinds {
+		resources.APIResources = append(resources.APIResources, metav1.APIResource{Kind: kind})
+	}
+	kube.Discovery().(*fakediscovery.FakeDiscovery).Resources = []*metav1.APIResourceList{resources}
+	return &Env{
+		Kube:           kube,
+		Discovery:      kube.Discovery(),
+		Reader:         ctrlfake.NewClientBuilder().WithScheme(testScheme).Build(),
+		FeatureGate:    newFeatureGate(t, gates),
+		Namespace:      "sandbox-system",
+		WebhookService: "webhook-service",
+	}, kube
+}
+
+var allKinds = []string{"Sandbox", "SandboxSet", "SandboxClaim", "SandboxTemplate", "Checkpoint", "OperatorStatus"}
+
+func TestCheckCRDs(t *testing.T) {
+	tests := []struct {
+		name          string
+		gates         map[string]bool
+		kinds         []string
+		expectResult  agentsv1alpha1.PreflightCheckResult
+		expectMessage string
+	}{
+		{
+			name:         "all served",
+			kinds:        allKinds,
+			expectResult: agentsv1alpha1.PreflightCheckPass,
+		},
+		{
+			name:          "crd of enabled controller missing",
+			kinds:         []string{"Sandbox", "SandboxSet", "SandboxTemplate", "Checkpoint", "OperatorStatus"},
+			expectResult:  agentsv1alpha1.PreflightCheckFail,
+			expectMessage: "SandboxClaim",
+		},
+		{
+			name:          "crd of disabled controller missing",
+			gates:         map[string]bool{string(features.SandboxClaimGate): false},
+			kinds:         []string{"Sandbox", "SandboxSet", "SandboxTemplate", "Checkpoint", "OperatorStatus"},
+			expectResult:  agentsv1alpha1.PreflightCheckWarn,
+			expectMessage: "SandboxClaim",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env, _ := newEnv(t, tt.gates, tt.kinds)
+			result, message := checkCRDs(context.Background(), env)
+			assert.Equal(t, tt.expectResult, result)
+			assert.Contains(t, message, tt.expectMessage)
+		})
+	}
+}
+
+func TestCheckWebhook(t *testing.T) {
+	newSlice := func(ready bool) *discoveryv1.EndpointSlice {
+		return &discoveryv1.EndpointSlice{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "webhook-service-abc",
+				Namespace: "sandbox-system",
+				Labels:    map[string]string{discoveryv1.LabelServiceName: "webhook-service"},
+			},
+			Endpoints: []discoveryv1.Endpoint{{Conditions: discoveryv1.EndpointConditions{Ready: ptr.To(ready)}}},
+		}
+	}
+	tests := []struct {
+		name         string
+		objects      []runtime.Object
+		expectResult agentsv1alpha1.PreflightCheckResult
+	}{
+		{
+			name:         "ready endpoint",
+			objects:      []runtime.Object{newSlice(true)},
+			expectResult: agentsv1alpha1.PreflightCheckPass,
+		},
+		{
+			name:         "no ready endpoint",
+			objects:      []runtime.Object{newSlice(false)},
+			expectResult: agentsv1alpha1.PreflightCheckFail,
+		},
+		{
+			name:         "no endpoint",
+			expectResult: agentsv1alpha1.PreflightCheckFail,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env, _ := newEnv(t, nil, allKinds, tt.objects...)
+			result, _ := checkWebhook(context.Background(), env)
+			assert.Equal(t, tt.expectResult, result)
+		})
+	}
+}
+
+func TestCheckRBAC(t *testing.T) {
+	tests := []struct {
+		name          string
+		user          string
+		denied        string
+		expectResult  agentsv1alpha1.PreflightCheckResult
+		expectMessage string
+	}{
+		{
+			name:         "all granted to current user",
+			expectResult: agentsv1alpha1.PreflightCheckPass,
+		},
+		{
+			name:          "denied to service account",
+			user:          "system:serviceaccount:sandbox-system:sandbox-controller-manager",
+			denied:        "pods",
+			expectResult:  agentsv1alpha1.PreflightCheckFail,
+			expectMessage: "permissions not granted: create pods, delete pods",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env, kube := newEnv(t, nil, allKinds)
+			env.User = tt.user
+			kube.PrependReactor("create", "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+				switch review := action.(k8stesting.CreateAction).GetObject().(type) {
+				case *authorizationv1.SelfSubjectAccessReview:
+					assert.Empty(t, tt.user)
+					review.Status.Allowed = review.Spec.ResourceAttributes.Resource != tt.denied
+					return true, review, nil
+				case *authorizationv1.SubjectAccessReview:
+					assert.Equal(t, tt.user, review.Spec.User)
+					review.Status.Allowed = review.Spec.ResourceAttributes.Resource != tt.denied
+					return true, review, nil
+				}
+				return false, nil, nil
+			})
+			result, message := checkRBAC(context.Background(), env)
+			assert.Equal(t, tt.expectResult, result)
+			assert.Equal(t, tt.expectMessage, message)
+		})
+	}
+}
+
+func TestCheckRuntimeClasses(t *testing.T) {
+	env, _ := newEnv(t, nil, allKinds, &nodev1.RuntimeClass{ObjectMeta: metav1.ObjectMeta{Name: "kata"}})
+	newTemplate := func(runtimeClass string) *corev1.PodTemplateSpec {
+		return &corev1.PodTemplateSpec{Spec: corev1.PodSpec{RuntimeClassName: ptr.To(runtimeClass)}}
+	}
+	env.Reader = ctrlfake.NewClientBuilder().WithScheme(testScheme).WithObjects(
+		&agentsv1alpha1.SandboxSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "kata-pool", Namespace: "default"},
+			Spec: agentsv1alpha1.SandboxSetSpec{EmbeddedSandboxTemplate: agentsv1alpha1.EmbeddedSandboxTemplate{
+				Template: newTemplate("kata"),
+			}},
+		},
+		&agentsv1alpha1.SandboxTemplate{
+			ObjectMeta: metav1.ObjectMeta{Name: "gvisor", Namespace: "default"},
+			Spec:       agentsv1alpha1.SandboxTemplateSpec{Template: newTemplate("gvisor")},
+		},
+	).Build()
+
+	result, message := checkRuntimeClasses(context.Background(), env)
+	assert.Equal(t, agentsv1alpha1.PreflightCheckWarn, result)
+	assert.Equal(t, "RuntimeClasses not found: gvisor (used by SandboxTemplate default/gvisor)", message)
+}
+
+func TestCheckMetricsAPI(t *testing.T) {
+	tests := []struct {
+		name         string
+		gates        map[string]bool
+		served       bool
+		expectResult agentsv1alpha1.PreflightCheckResult
+	}{
+		{
+			name:         "served",
+			gates:        map[string]bool{string(features.SandboxUsageSamplingGate): true},
+			served:       true,
+			expectResult: agentsv1alpha1.PreflightCheckPass,
+		},
+		{
+			name:         "not served without usage sampling",
+			expectResult: agentsv1alpha1.PreflightCheckPass,
+		},
+		{
+			name:         "not served with usage sampling",
+			gates:        map[string]bool{string(features.SandboxUsageSamplingGate): true},
+			expectResult: agentsv1alpha1.PreflightCheckWarn,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env, kube := newEnv(t, tt.gates, allKinds)
+			if tt.served {
+				fake := kube.Discovery().(*fakediscovery.FakeDiscovery)
+				fake.Resources = append(fake.Resources, &metav1.APIResourceList{GroupVersion: metricsGroupVersion})
+			}
+			result, _ := checkMetricsAPI(context.Background(), env)
+			assert.Equal(t, tt.expectResult, result)
+		})
+	}
+}
+
+func TestCheckFeatureGates(t *testing.T) {
+	env, _ := newEnv(t, nil, allKinds)
+	result, _ := checkFeatureGates(context.Background(), env)
+	assert.Equal(t, agentsv1alpha1.PreflightCheckPass, result)
+
+	env, _ = newEnv(t, map[string]bool{
+		string(features.SandboxSetGate):                false,
+		string(features.SandboxClaimPriorityQueueGate): true,
+	}, allKinds)
+	result, message := checkFeatureGates(context.Background(), env)
+	assert.Equal(t, agentsv1alpha1.PreflightCheckFail, result)
+	assert.Equal(t, "enabled feature gates depend on disabled ones: SandboxClaim requires SandboxSet", message)
+}
+
+func TestRun(t *testing.T) {
+	pass := func(context.Context, *Env) (agentsv1alpha1.PreflightCheckResult, string) {
+		return agentsv1alpha1.PreflightCheckPass, ""
+	}
+	warn := func(context.Context, *Env) (agentsv1alpha1.PreflightCheckResult, string) {
+		return agentsv1alpha1.PreflightCheckWarn, "warned"
+	}
+	fail := func(context.Context, *Env) (agentsv1alpha1.PreflightCheckResult, string) {
+		return agentsv1alpha1.PreflightCheckFail, "failed"
+	}
+
+	report := Run(context.Background(), &Env{}, []Check{{Name: "a", Run: pass}, {Name: "b", Run: warn}})
+	assert.True(t, report.Passed)
+	assert.Empty(t, report.Failed())
+
+	report = Run(context.Background(), &Env{}, []Check{{Name: "a", Run: pass}, {Name: "b", Run: fail}})
+	assert.False(t, report.Passed)
+	assert.Equal(t, []string{"b"}, report.Failed())
+	assert.Equal(t, agentsv1alpha1.PreflightCheck{Name: "b", Result: agentsv1alpha1.PreflightCheckFail, Message: "failed"}, report.Checks[1])
+}
+
+func TestStartupChecks(t *testing.T) {
+	var names []string
+	for _, check := range StartupChecks() {
+		names = append(names, check.Name)
+	}
+	assert.Equal(t, []string{"CRDs", "RBAC", "RuntimeClasses", "MetricsAPI", "FeatureGates"}, names)
+	assert.Len(t, Checks, 6, "StartupChecks must not modify Checks")
+}
+
+func TestUpdateOperatorStatus(t *testing.T) {
+	c := ctrlfake.NewClientBuilder().WithScheme(testScheme).WithStatusSubresource(&agentsv1alpha1.OperatorStatus{}).Build()
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	report := &Report{Checks: []agentsv1alpha1.PreflightCheck{{Name: "RBAC", Result: agentsv1alpha1.PreflightCheckFail, Message: "denied"}}}
+	require.NoError(t, UpdateOperatorStatus(context.Background(), c, report, now))
+	status := &agentsv1alpha1.OperatorStatus{}
+	require.NoError(t, c.Get(context.Background(), client.ObjectKey{Name: agentsv1alpha1.OperatorStatusName}, status))
+	assert.Equal(t, report.Checks, status.Status.Checks)
+	assert.True(t, status.Status.LastCheckTime.Time.Equal(now))
+	cond := conditions.Get(status.Status.Conditions, string(agentsv1alpha1.OperatorStatusConditionPreflightPassed))
+	require.NotNil(t, cond)
+	assert.Equal(t, metav1.ConditionFalse, cond.Status)
+	assert.Equal(t, "Failed preflight checks: RBAC", cond.Message)
+
+	report = &Report{Passed: true, Checks: []agentsv1alpha1.PreflightCheck{{Name: "RBAC", Result: agentsv1alpha1.PreflightCheckPass}}}
+	require.NoError(t, UpdateOperatorStatus(context.Background(), c, report, now.Add(time.Minute)))
+	require.NoError(t, c.Get(context.Background(), client.ObjectKey{Name: agentsv1alpha1.OperatorStatusName}, status))
+	assert.True(t, conditions.IsTrue(status.Status.Conditions, string(agentsv1alpha1.OperatorStatusConditionPreflightPassed)))
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package preflight
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+	agentsclient "github.com/openkruise/agents/client"
+	"github.com/openkruise/agents/pkg/discovery"
+	"github.com/openkruise/agents/pkg/utils/conditions"
+	utilfeature "github.com/openkruise/agents/pkg/utils/feature"
+	"github.com/openkruise/agents/pkg/utils/webhookutils"
+)
+
+// +kubebuilder:rbac:groups=agents.kruise.io,resources=operatorstatuses,verbs=get;create
+// +kubebuilder:rbac:groups=agents.kruise.io,resources=operatorstatuses/status,verbs=get;update
+// +kubebuilder:rbac:groups=agents.kruise.io,resources=sandboxsets;sandboxtemplates,verbs=list
+// +kubebuilder:rbac:groups=node.k8s.io,resources=runtimeclasses,verbs=get
+// +kubebuilder:rbac:groups=authorization.k8s.io,resources=selfsubjectaccessreviews,verbs=create
+
+// SetupWithManager runs the startup checks once the manager is elected, and reports their results to the
+// OperatorStatus. Failed checks are logged and reported only, they never stop the manager.
+func SetupWithManager(mgr manager.Manager) error {
+	if !discovery.DiscoverGVK(agentsv1alpha1.OperatorStatusControllerKind) {
+		return nil
+	}
+	genericClient := agentsclient.GetGenericClient()
+	env := &Env{
+		Kube:           genericClient.KubeClient,
+		Discovery:      genericClient.DiscoveryClient,
+		Reader:         mgr.GetAPIReader(),
+		FeatureGate:    utilfeature.DefaultFeatureGate,
+		Namespace:      webhookutils.GetNamespace(),
+		WebhookService: webhookutils.GetServiceName(),
+	}
+	return mgr.Add(manager.RunnableFunc(func(ctx context.Context) error {
+		report := Run(ctx, env, StartupChecks())
+		if report.Passed {
+			klog.InfoS("Preflight checks passed")
+		} else {
+			klog.InfoS("Preflight checks failed, the operator may not work", "failed", report.Failed())
+		}
+		if err := UpdateOperatorStatus(ctx, mgr.GetClient(), report, time.Now()); err != nil {
+			klog.ErrorS(err, "Failed to report preflight checks to OperatorStatus", "name", agentsv1alpha1.OperatorStatusName)
+		}
+		return nil
+	}))
+}
+
+// UpdateOperatorStatus reports the results of the checks to the OperatorStatus, creating it if not found
+func UpdateOperatorStatus(ctx context.Context, c client.Client, report *Report, now time.Time) error {
+	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		status := &agentsv1alpha1.OperatorStatus{}
+		err := c.Get(ctx, client.ObjectKey{Name: agentsv1alpha1.OperatorStatusName}, status)
+		if apierrors.IsNotFound(err) {
+			status.Name = agentsv1alpha1.OperatorStatusName
+			err = c.Create(ctx, status)
+		}
+		if err != nil {
+			return err
+		}
+		status.Status.Checks = report.Checks
+		status.Status.LastCheckTime = &metav1.Time{Time: now}
+		builder := conditions.NewBuilder(&status.Status.Conditions, status.Generation).WithTime(metav1.Time{Time: now})
+		if report.Passed {
+			builder.True(string(agentsv1alpha1.OperatorStatusConditionPreflightPassed), "AllChecksPassed", "No preflight check failed")
+		} else {
+			builder.False(string(agentsv1alpha1.OperatorStatusConditionPreflightPassed), "ChecksFailed",
+				fmt.Sprintf("Failed preflight checks: %s", strings.Join(report.Failed(), ", ")))
+		}
+		return c.Status().Update(ctx, status)
+	})
+}
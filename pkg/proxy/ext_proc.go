@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	configPb "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
 	extProcPb "github.com/envoyproxy/go-control-plane/envoy/service/ext_proc/v3"
@@ -28,6 +29,10 @@ var LogLevel = consts.DebugLogLevel + 1
 func (s *Server) Process(srv extProcPb.ExternalProcessor_ProcessServer) error {
 	log := klog.LoggerWithValues(klog.Background(), "contextID", uuid.NewString()).V(LogLevel)
 	ctx := srv.Context()
+	s.inflight.Add(1)
+	defer s.inflight.Add(-1)
+	// each stream serves a single request, whose response is recorded for the traffic metrics
+	var traffic trafficRecord
 	for {
 		select {
 		case <-ctx.Done():
@@ -62,7 +67,10 @@ func (s *Server) Process(srv extProcPb.ExternalProcessor_ProcessServer) error {
 		switch v := req.Request.(type) {
 		case *extProcPb.ProcessingRequest_RequestHeaders:
 			h := req.Request.(*extProcPb.ProcessingRequest_RequestHeaders)
-			resp = s.handleRequestHeaders(h, log)
+			resp = s.handleRequestHeaders(h, &traffic, log)
+
+		case *extProcPb.ProcessingRequest_ResponseHeaders:
+			traffic.observe(s.trafficMetrics, responseStatus(v.ResponseHeaders), time.Now())
 
 		default:
 			log.Info("Unknown Request type", "type", v)
@@ -80,7 +88,8 @@ func (s *Server) Process(srv extProcPb.ExternalProcessor_ProcessServer) error {
 
 var OrigDstHeader = "x-envoy-original-dst-host"
 
-func (s *Server) handleRequestHeaders(requestHeaders *extProcPb.ProcessingRequest_RequestHeaders, log logr.Logger) *extProcPb.ProcessingResponse {
+func (s *Server) handleRequestHeaders(requestHeaders *extProcPb.ProcessingRequest_RequestHeaders, traffic *trafficRecord,
+	log logr.Logger) *extProcPb.ProcessingResponse {
 	scheme, authority, path, port, headers := parseRequest(requestHeaders.RequestHeaders)
 	log = log.WithValues("requestID", headers["x-request-id"])
 	log.Info("envoy ext processor parsed request", "scheme", scheme, "authority", authority, "path", path, "port", port, "headers", headers)
@@ -96,20 +105,24 @@ func (s *Server) handleRequestHeaders(requestHeaders *extProcPb.ProcessingReques
 		errorMsg := fmt.Sprintf("failed to map request to sandbox, URL=%s://%s%s", scheme, authority, path)
 		return s.logAndCreateErrorResponse(http.StatusInternalServerError, errorMsg, log)
 	}
+	route, ok := s.LoadRoute(sandboxID)
+	traffic.begin(sandboxID, route, ok, time.Now())
 	if sandboxPort < 0 || sandboxPort > 65535 {
 		errorMsg := fmt.Sprintf("invalid sandbox port: %d", sandboxPort)
+		traffic.observe(s.trafficMetrics, http.StatusBadRequest, time.Now())
 		return s.logAndCreateErrorResponse(http.StatusBadRequest, errorMsg, log)
 	}
 	log.Info("request mapped", "sandboxID", sandboxID, "sandboxPort", sandboxPort, "extraHeaders", extraHeaders)
 
 	errorMsg := fmt.Sprintf("healthy sandbox %s not found", sandboxID)
-	route, ok := s.LoadRoute(sandboxID)
 	if !ok {
 		log.Info("route not found", "sandboxID", sandboxID)
+		traffic.observe(s.trafficMetrics, http.StatusBadGateway, time.Now())
 		return s.logAndCreateErrorResponse(http.StatusBadGateway, errorMsg, log)
 	}
 	if route.State != agentsv1alpha1.SandboxStateRunning {
 		log.Info("sandbox is not running", "sandboxID", sandboxID, "route", route)
+		traffic.observe(s.trafficMetrics, http.StatusBadGateway, time.Now())
 		return s.logAndCreateErrorResponse(http.StatusBadGateway, errorMsg, log)
 	}
 	if extraHeaders == nil {
@@ -213,6 +226,20 @@ func parseRequest(httpHeaders *extProcPb.HttpHeaders) (scheme, authority, path s
 	return scheme, authority, path, port, headers
 }
 
+// responseStatus returns the status code of the response headers, 0 if missing
+func responseStatus(responseHeaders *extProcPb.HttpHeaders) int {
+	if responseHeaders == nil || responseHeaders.Headers == nil {
+		return 0
+	}
+	for _, header := range responseHeaders.Headers.Headers {
+		if header.Key == ":status" {
+			code, _ := strconv.Atoi(string(header.RawValue))
+			return code
+		}
+	}
+	return 0
+}
+
 func headerModifiers(key string, in *extProcPb.HttpHeaders, log logr.Logger) []*configPb.HeaderValueOption {
 	var modifiers []*configPb.HeaderValueOption
 	value := ""
@@ -0,0 +1,149 @@
+/*
+Copyright 2026.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// unknownTemplate labels the requests to sandboxes without a route or a template
+const unknownTemplate = "unknown"
+
+// TrafficMetricsOptions configures the metrics of the requests routed to sandboxes, which feed idle detection and
+// capacity planning.
+type TrafficMetricsOptions struct {
+	// PerSandbox exports the metrics per sandbox in addition to per template. The series of a sandbox are removed with
+	// its route, so the cardinality is bounded by the running sandboxes.
+	PerSandbox bool
+}
+
+// DefaultTrafficMetricsOptions is set by the command line flags and used by the proxy servers of the process.
+var DefaultTrafficMetricsOptions = TrafficMetricsOptions{}
+
+// AddFlags registers the flags of the options.
+func (o *TrafficMetricsOptions) AddFlags(fs *pflag.FlagSet) {
+	fs.BoolVar(&o.PerSandbox, "proxy-metrics-per-sandbox", o.PerSandbox,
+		"Export the rate, errors and latency of the requests routed to sandboxes per sandbox in addition to per template.")
+}
+
+var (
+	// ProxyRequests counts the requests routed to sandboxes per template and status class, e.g. 2xx or 5xx
+	ProxyRequests = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "sandbox_proxy_requests_total",
+			Help: "Total number of requests routed to sandboxes by template and status class",
+		},
+		[]string{"template", "code"},
+	)
+
+	// ProxyRequestDuration tracks the time from routing a request to receiving the response headers of the sandbox
+	ProxyRequestDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "sandbox_proxy_request_duration_seconds",
+			Help:    "Latency of the requests routed to sandboxes until their response headers by template",
+			Buckets: prometheus.ExponentialBuckets(0.005, 2, 12), // 5ms to ~10s
+		},
+		[]string{"template"},
+	)
+
+	// SandboxProxyRequests is ProxyRequests per sandbox, only recorded with TrafficMetricsOptions.PerSandbox
+	SandboxProxyRequests = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "sandbox_proxy_sandbox_requests_total",
+			Help: "Total number of requests routed to sandboxes by sandbox and status class",
+		},
+		[]string{"template", "sandbox", "code"},
+	)
+
+	// SandboxProxyRequestDuration is ProxyRequestDuration per sandbox, only recorded with
+	// TrafficMetricsOptions.PerSandbox
+	SandboxProxyRequestDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "sandbox_proxy_sandbox_request_duration_seconds",
+			Help:    "Latency of the requests routed to sandboxes until their response headers by sandbox",
+			Buckets: prometheus.ExponentialBuckets(0.005, 2, 12),
+		},
+		[]string{"template", "sandbox"},
+	)
+)
+
+func init() {
+	metrics.Registry.MustRegister(ProxyRequests, ProxyRequestDuration, SandboxProxyRequests, SandboxProxyRequestDuration)
+}
+
+// trafficRecord tracks a sandbox request through the ext-proc stream serving it, from its request headers to its
+// response headers or the immediate response of the proxy.
+type trafficRecord struct {
+	start    time.Time
+	sandbox  string
+	template string
+	// observed is set once the response is recorded, later messages of the stream are ignored
+	observed bool
+}
+
+// begin starts tracking a sandbox request
+func (r *trafficRecord) begin(sandbox string, route Route, found bool, now time.Time) {
+	*r = trafficRecord{start: now, sandbox: sandbox}
+	if found {
+		r.template = route.Template
+	}
+}
+
+// tracking reports whether a sandbox request is waiting for its response
+func (r *trafficRecord) tracking() bool {
+	return !r.start.IsZero() && !r.observed
+}
+
+// observe records the response of the request with the status code
+func (r *trafficRecord) observe(opts TrafficMetricsOptions, code int, now time.Time) {
+	if !r.tracking() {
+		return
+	}
+	r.observed = true
+	template := r.template
+	if template == "" {
+		template = unknownTemplate
+	}
+	class := statusClass(code)
+	latency := now.Sub(r.start).Seconds()
+	ProxyRequests.WithLabelValues(template, class).Inc()
+	ProxyRequestDuration.WithLabelValues(template).Observe(latency)
+	// requests to sandboxes without routes would create series never removed
+	if opts.PerSandbox && r.template != "" {
+		SandboxProxyRequests.WithLabelValues(template, r.sandbox, class).Inc()
+		SandboxProxyRequestDuration.WithLabelValues(template, r.sandbox).Observe(latency)
+	}
+}
+
+// statusClass returns the class of an HTTP status code like 2xx, so that the codes label has a few values only
+func statusClass(code int) string {
+	if code < 100 || code > 599 {
+		return "unknown"
+	}
+	return strconv.Itoa(code/100) + "xx"
+}
+
+// deleteSandboxTrafficMetrics removes the series of a sandbox whose route is deleted
+func deleteSandboxTrafficMetrics(sandbox string) {
+	SandboxProxyRequests.DeletePartialMatch(prometheus.Labels{"sandbox": sandbox})
+	SandboxProxyRequestDuration.DeletePartialMatch(prometheus.Labels{"sandbox": sandbox})
+}
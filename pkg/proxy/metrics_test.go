@@ -0,0 +1,143 @@
+package proxy
+
+import (
+	"testing"
+
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	extProcPb "github.com/envoyproxy/go-control-plane/envoy/service/ext_proc/v3"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+	"github.com/openkruise/agents/pkg/sandbox-manager/config"
+)
+
+func TestStatusClass(t *testing.T) {
+	tests := []struct {
+		code     int
+		expected string
+	}{
+		{code: 200, expected: "2xx"},
+		{code: 302, expected: "3xx"},
+		{code: 404, expected: "4xx"},
+		{code: 502, expected: "5xx"},
+		{code: 0, expected: "unknown"},
+		{code: 600, expected: "unknown"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, statusClass(tt.code), "code %d", tt.code)
+	}
+}
+
+func TestServer_Process_TrafficMetrics(t *testing.T) {
+	requestHeaders := &extProcPb.ProcessingRequest{
+		Request: &extProcPb.ProcessingRequest_RequestHeaders{
+			RequestHeaders: &extProcPb.HttpHeaders{
+				Headers: &corev3.HeaderMap{
+					Headers: []*corev3.HeaderValue{
+						{Key: ":scheme", RawValue: []byte("http")},
+						{Key: ":authority", RawValue: []byte("localhost:9002")},
+						{Key: ":path", RawValue: []byte("/sandbox")},
+					},
+				},
+			},
+		},
+	}
+	responseHeaders := func(code string) *extProcPb.ProcessingRequest {
+		return &extProcPb.ProcessingRequest{
+			Request: &extProcPb.ProcessingRequest_ResponseHeaders{
+				ResponseHeaders: &extProcPb.HttpHeaders{
+					Headers: &corev3.HeaderMap{
+						Headers: []*corev3.HeaderValue{{Key: ":status", RawValue: []byte(code)}},
+					},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name              string
+		sandboxID         string
+		route             *Route
+		perSandbox        bool
+		requests          []*extProcPb.ProcessingRequest
+		expectedTemplate  string
+		expectedCode      string
+		expectSandboxSeen bool
+	}{
+		{
+			name:             "template level by default",
+			sandboxID:        "metrics-sandbox-1",
+			route:            &Route{ID: "metrics-sandbox-1", IP: "192.168.1.10", Template: "metrics-pool-1"},
+			requests:         []*extProcPb.ProcessingRequest{requestHeaders, responseHeaders("200")},
+			expectedTemplate: "metrics-pool-1",
+			expectedCode:     "2xx",
+		},
+		{
+			name:              "sandbox level with the flag",
+			sandboxID:         "metrics-sandbox-2",
+			route:             &Route{ID: "metrics-sandbox-2", IP: "192.168.1.10", Template: "metrics-pool-2"},
+			perSandbox:        true,
+			requests:          []*extProcPb.ProcessingRequest{requestHeaders, responseHeaders("503")},
+			expectedTemplate:  "metrics-pool-2",
+			expectedCode:      "5xx",
+			expectSandboxSeen: true,
+		},
+		{
+			name:             "route not found",
+			sandboxID:        "metrics-sandbox-3",
+			perSandbox:       true,
+			requests:         []*extProcPb.ProcessingRequest{requestHeaders, responseHeaders("200")},
+			expectedTemplate: unknownTemplate,
+			expectedCode:     "5xx",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			adapter := &testRequestAdapter{
+				isSandboxRequest: true,
+				mapResult:        mapResult{sandboxID: tt.sandboxID, sandboxPort: 8080},
+				entry:            "127.0.0.1:8080",
+			}
+			server := NewServer(adapter, nil, config.SandboxManagerOptions{ExtProcMaxConcurrency: 1000})
+			server.trafficMetrics.PerSandbox = tt.perSandbox
+			if tt.route != nil {
+				tt.route.State = agentsv1alpha1.SandboxStateRunning
+				server.SetRoute(t.Context(), *tt.route)
+			}
+			before := testutil.ToFloat64(ProxyRequests.WithLabelValues(tt.expectedTemplate, tt.expectedCode))
+
+			require.NoError(t, server.Process(&mockProcessServer{reqs: tt.requests}))
+
+			// the response headers after an immediate response are not counted twice
+			assert.Equal(t, before+1, testutil.ToFloat64(ProxyRequests.WithLabelValues(tt.expectedTemplate, tt.expectedCode)))
+			if tt.expectSandboxSeen {
+				assert.Equal(t, 2, sandboxSeries(t, tt.sandboxID))
+				server.DeleteRoute(tt.sandboxID)
+			}
+			assert.Equal(t, 0, sandboxSeries(t, tt.sandboxID))
+		})
+	}
+}
+
+// sandboxSeries returns the number of series of the sandbox in the per-sandbox metrics
+func sandboxSeries(t *testing.T, sandbox string) int {
+	ch := make(chan prometheus.Metric, 100)
+	SandboxProxyRequests.Collect(ch)
+	SandboxProxyRequestDuration.Collect(ch)
+	close(ch)
+	count := 0
+	for metric := range ch {
+		m := &dto.Metric{}
+		require.NoError(t, metric.Write(m))
+		for _, label := range m.GetLabel() {
+			if label.GetName() == "sandbox" && label.GetValue() == sandbox {
+				count++
+			}
+		}
+	}
+	return count
+}
@@ -40,6 +40,7 @@ type Route struct {
 	ID              string    `json:"id"`
 	UID             types.UID `json:"uid"`
 	Owner           string    `json:"owner"`
+	Template        string    `json:"template,omitempty"`
 	State           string    `json:"state"`
 	ResourceVersion string    `json:"resourceVersion"`
 }
@@ -148,6 +149,7 @@ func (s *Server) ListPeers() []peers.Peer {
 
 func (s *Server) DeleteRoute(id string) {
 	s.routes.Delete(id)
+	deleteSandboxTrafficMetrics(id)
 }
 
 // RequestAdapter is used to register the mapping from business-side sandbox requests to internal logic
@@ -24,6 +24,7 @@ import (
 	"net"
 	"net/http"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	extProcPb "github.com/envoyproxy/go-control-plane/envoy/service/ext_proc/v3"
@@ -45,19 +46,29 @@ const (
 	SystemPort = 7789
 )
 
-type healthServer struct{}
+type healthServer struct {
+	// draining reports not serving once set, so that envoy sends new requests to other instances
+	draining *atomic.Bool
+}
+
+func (s *healthServer) status() grpc_health_v1.HealthCheckResponse_ServingStatus {
+	if s.draining != nil && s.draining.Load() {
+		return grpc_health_v1.HealthCheckResponse_NOT_SERVING
+	}
+	return grpc_health_v1.HealthCheckResponse_SERVING
+}
 
 func (s *healthServer) List(context.Context, *grpc_health_v1.HealthListRequest) (*grpc_health_v1.HealthListResponse, error) {
 	return &grpc_health_v1.HealthListResponse{
 		Statuses: map[string]*grpc_health_v1.HealthCheckResponse{
-			"envoy-ext-proc": {Status: grpc_health_v1.HealthCheckResponse_SERVING},
+			"envoy-ext-proc": {Status: s.status()},
 		},
 	}, nil
 }
 
 func (s *healthServer) Check(context.Context, *grpc_health_v1.HealthCheckRequest) (
 	*grpc_health_v1.HealthCheckResponse, error) {
-	return &grpc_health_v1.HealthCheckResponse{Status: grpc_health_v1.HealthCheckResponse_SERVING}, nil
+	return &grpc_health_v1.HealthCheckResponse{Status: s.status()}, nil
 }
 
 func (s *healthServer) Watch(*grpc_health_v1.HealthCheckRequest, grpc_health_v1.Health_WatchServer) error {
@@ -82,7 +93,11 @@ type Server struct {
 	// peers - now managed by Peers
 	peersManager peers.Peers
 	// lifecycle
-	mu sync.Mutex
+	mu       sync.Mutex
+	draining atomic.Bool
+	inflight atomic.Int64
+	// metrics
+	trafficMetrics TrafficMetricsOptions
 }
 
 func NewServer(adapter RequestAdapter, peersManager peers.Peers, opts config.SandboxManagerOptions) *Server {
@@ -90,6 +105,7 @@ func NewServer(adapter RequestAdapter, peersManager peers.Peers, opts config.San
 		adapter:                     adapter,
 		peersManager:                peersManager,
 		extProcMaxConcurrentStreams: opts.ExtProcMaxConcurrency,
+		trafficMetrics:              DefaultTrafficMetricsOptions,
 	}
 	if adapter != nil {
 		s.LBEntry = adapter.Entry()
@@ -117,7 +133,7 @@ func (s *Server) Run() error {
 	}
 	s.grpcSrv = grpc.NewServer(grpc.MaxConcurrentStreams(s.extProcMaxConcurrentStreams))
 	extProcPb.RegisterExternalProcessorServer(s.grpcSrv, s)
-	grpc_health_v1.RegisterHealthServer(s.grpcSrv, &healthServer{})
+	grpc_health_v1.RegisterHealthServer(s.grpcSrv, &healthServer{draining: &s.draining})
 	klog.InfoS("Starting envoy ext-proc gRPC server", "address", lis.Addr())
 
 	// Start servers
@@ -138,11 +154,31 @@ func (s *Server) Run() error {
 	return nil
 }
 
+// InflightStreams returns the number of ext-proc streams being processed, each of which lasts as long as the
+// proxied request, e.g. an exec or attach session.
+func (s *Server) InflightStreams() int64 {
+	return s.inflight.Load()
+}
+
+// Stop drains the servers. The health checks report not serving at once, and in-flight streams are waited for until
+// ctx is done, after which they are closed.
 func (s *Server) Stop(ctx context.Context) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	s.draining.Store(true)
 	if s.grpcSrv != nil {
-		s.grpcSrv.Stop()
+		stopped := make(chan struct{})
+		go func() {
+			s.grpcSrv.GracefulStop()
+			close(stopped)
+		}()
+		select {
+		case <-stopped:
+		case <-ctx.Done():
+			klog.InfoS("Closing in-flight ext-proc streams after the grace period", "streams", s.InflightStreams())
+			s.grpcSrv.Stop()
+			<-stopped
+		}
 	}
 	if s.httpSrv != nil {
 		_ = s.httpSrv.Shutdown(ctx)
@@ -20,14 +20,20 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	extProcPb "github.com/envoyproxy/go-control-plane/envoy/service/ext_proc/v3"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
 	grpc_health_v1 "google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/status"
 
@@ -44,6 +50,22 @@ func TestHealthServer_Check(t *testing.T) {
 	assert.Equal(t, grpc_health_v1.HealthCheckResponse_SERVING, resp.Status)
 }
 
+func TestHealthServer_Draining(t *testing.T) {
+	draining := &atomic.Bool{}
+	hs := &healthServer{draining: draining}
+	resp, err := hs.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_SERVING, resp.Status)
+
+	draining.Store(true)
+	resp, err = hs.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_NOT_SERVING, resp.Status)
+	list, err := hs.List(context.Background(), &grpc_health_v1.HealthListRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_NOT_SERVING, list.Statuses["envoy-ext-proc"].Status)
+}
+
 func TestHealthServer_List(t *testing.T) {
 	hs := &healthServer{}
 	resp, err := hs.List(context.Background(), &grpc_health_v1.HealthListRequest{})
@@ -61,6 +83,59 @@ func TestHealthServer_Watch(t *testing.T) {
 	assert.Equal(t, codes.Unimplemented, st.Code())
 }
 
+// ---- Stop tests ----
+
+func TestServer_Stop_DrainsStreams(t *testing.T) {
+	tests := []struct {
+		name string
+		// closeStream closes the in-flight stream from the client while draining
+		closeStream bool
+	}{
+		{name: "stream finishes within grace period", closeStream: true},
+		{name: "stream closed after grace period"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(nil)
+			lis, err := net.Listen("tcp", "127.0.0.1:0")
+			require.NoError(t, err)
+			s.grpcSrv = grpc.NewServer()
+			extProcPb.RegisterExternalProcessorServer(s.grpcSrv, s)
+			go func() {
+				_ = s.grpcSrv.Serve(lis)
+			}()
+
+			conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+			require.NoError(t, err)
+			defer func() {
+				_ = conn.Close()
+			}()
+			stream, err := extProcPb.NewExternalProcessorClient(conn).Process(context.Background())
+			require.NoError(t, err)
+			require.Eventually(t, func() bool { return s.InflightStreams() == 1 }, 5*time.Second, 10*time.Millisecond)
+
+			gracePeriod := 200 * time.Millisecond
+			stopped := make(chan time.Duration)
+			go func() {
+				ctx, cancel := context.WithTimeout(context.Background(), gracePeriod)
+				defer cancel()
+				start := time.Now()
+				s.Stop(ctx)
+				stopped <- time.Since(start)
+			}()
+			if tt.closeStream {
+				require.NoError(t, stream.CloseSend())
+				assert.Less(t, <-stopped, gracePeriod)
+			} else {
+				assert.GreaterOrEqual(t, <-stopped, gracePeriod)
+				_, err = stream.Recv()
+				assert.Error(t, err)
+			}
+			assert.Equal(t, int64(0), s.InflightStreams())
+		})
+	}
+}
+
 // ---- handleRefresh tests ----
 
 func TestHandleRefresh_Success(t *testing.T) {
@@ -17,9 +17,12 @@ package controller
 import (
 	"context"
 	"fmt"
+	"sync"
 
 	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -30,43 +33,68 @@ import (
 	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
 	"github.com/openkruise/agents/pkg/sandbox-gateway/registry"
 	proxyutils "github.com/openkruise/agents/pkg/utils/sandbox-manager/proxyutils"
+	"github.com/openkruise/agents/pkg/utils/sandboxid"
+	"github.com/openkruise/agents/pkg/utils/sandboxutils"
 )
 
 // SandboxReconciler reconciles Sandbox objects and updates the local registry
 type SandboxReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
+
+	// ids remembers the sandbox IDs the routes are registered with, as the IDs of some sandbox ID schemes cannot be
+	// derived from the name of a deleted sandbox, and change when the sandbox is claimed
+	ids sync.Map
 }
 
 func (r *SandboxReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	logger := log.FromContext(ctx)
-	key := req.Namespace + "--" + req.Name
 
 	var sandbox agentsv1alpha1.Sandbox
 	if err := r.Get(ctx, req.NamespacedName, &sandbox); err != nil {
 		if errors.IsNotFound(err) {
-			logger.Info("sandbox deleted, removing from registry", "key", key)
-			registry.GetRegistry().Delete(key)
+			logger.Info("sandbox deleted, removing from registry", "key", r.deleteRoute(req.NamespacedName))
 			return ctrl.Result{}, nil
 		}
 		return ctrl.Result{}, err
 	}
 
 	if sandbox.DeletionTimestamp != nil {
-		logger.Info("sandbox being deleted, removing from registry", "key", key)
-		registry.GetRegistry().Delete(key)
+		logger.Info("sandbox being deleted, removing from registry", "key", r.deleteRoute(req.NamespacedName))
 		return ctrl.Result{}, nil
 	}
 
 	route := proxyutils.DefaultGetRouteFunc(&sandbox)
-	logger.Info("updating registry", "key", key, "podIP", route.IP, "state", route.State, "resourceVersion", route.ResourceVersion)
-	registry.GetRegistry().Update(key, route)
+	if old, loaded := r.ids.Swap(req.NamespacedName, route.ID); loaded && old.(string) != route.ID {
+		registry.GetRegistry().Delete(old.(string))
+	}
+	logger.Info("updating registry", "key", route.ID, "podIP", route.IP, "state", route.State, "resourceVersion", route.ResourceVersion)
+	registry.GetRegistry().Update(route.ID, route)
 	return ctrl.Result{}, nil
 }
 
+// deleteRoute removes the route of the sandbox from the registry, and returns the sandbox ID it was registered with.
+func (r *SandboxReconciler) deleteRoute(key types.NamespacedName) string {
+	id := sandboxutils.GetSandboxID(&agentsv1alpha1.Sandbox{ObjectMeta: metav1.ObjectMeta{Namespace: key.Namespace, Name: key.Name}})
+	if old, loaded := r.ids.LoadAndDelete(key); loaded {
+		id = old.(string)
+	}
+	registry.GetRegistry().Delete(id)
+	return id
+}
+
 func StartManager(ctx context.Context) error {
 	ctrl.SetLogger(zap.New(zap.UseDevMode(true)))
 
+	// the gateway is configured by environment variables, and must agree with the sandbox-manager on the sandbox IDs
+	idOptions := sandboxid.DefaultOptions
+	if err := idOptions.LoadEnv(); err != nil {
+		return err
+	}
+	if err := idOptions.Apply(); err != nil {
+		return err
+	}
+
 	scheme := runtime.NewScheme()
 	utilruntime.Must(agentsv1alpha1.AddToScheme(scheme))
 
@@ -32,6 +32,7 @@ import (
 	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
 	"github.com/openkruise/agents/pkg/proxy"
 	"github.com/openkruise/agents/pkg/sandbox-gateway/registry"
+	"github.com/openkruise/agents/pkg/utils/sandboxid"
 )
 
 func TestSandboxReconciler_Reconcile_SandboxNotFound(t *testing.T) {
@@ -815,3 +816,71 @@ func TestSandboxReconciler_Reconcile_UnexpectedGetError(t *testing.T) {
 		t.Error("Expected no requeue on error")
 	}
 }
+
+func TestSandboxReconciler_Reconcile_SandboxIDChanged(t *testing.T) {
+	t.Cleanup(func() { _ = sandboxid.Options{}.Apply() })
+	if err := (sandboxid.Options{Scheme: sandboxid.SchemeULID}).Apply(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = agentsv1alpha1.AddToScheme(scheme)
+
+	sandbox := &agentsv1alpha1.Sandbox{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "claimed-sandbox",
+			Namespace: "default",
+		},
+		Status: agentsv1alpha1.SandboxStatus{
+			PodInfo: agentsv1alpha1.PodInfo{
+				PodIP: "10.0.0.6",
+			},
+		},
+	}
+	client := fake.NewClientBuilder().WithScheme(scheme).WithObjects(sandbox).Build()
+	reconciler := &SandboxReconciler{
+		Client: client,
+		Scheme: scheme,
+	}
+	req := ctrl.Request{
+		NamespacedName: types.NamespacedName{
+			Name:      "claimed-sandbox",
+			Namespace: "default",
+		},
+	}
+	if _, err := reconciler.Reconcile(context.Background(), req); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, found := registry.GetRegistry().Get("default--claimed-sandbox"); !found {
+		t.Fatal("Expected registry entry of the unclaimed sandbox")
+	}
+
+	// the sandbox is assigned an ID when claimed
+	if err := client.Get(context.Background(), req.NamespacedName, sandbox); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	sandbox.Annotations = map[string]string{agentsv1alpha1.AnnotationSandboxID: "sbx-claimed"}
+	if err := client.Update(context.Background(), sandbox); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := reconciler.Reconcile(context.Background(), req); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, found := registry.GetRegistry().Get("default--claimed-sandbox"); found {
+		t.Error("Expected registry entry of the previous ID to be deleted")
+	}
+	if route, found := registry.GetRegistry().Get("sbx-claimed"); !found || route.ID != "sbx-claimed" {
+		t.Errorf("Expected registry entry of the assigned ID, got %+v", route)
+	}
+
+	// the deleted sandbox is removed by the ID it was registered with
+	if err := client.Delete(context.Background(), sandbox); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := reconciler.Reconcile(context.Background(), req); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, found := registry.GetRegistry().Get("sbx-claimed"); found {
+		t.Error("Expected registry entry to be deleted")
+	}
+}
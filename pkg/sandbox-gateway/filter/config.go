@@ -11,7 +11,7 @@ import (
 )
 
 // hostPattern matches the host format: {port}-{namespace}--{name}.{domain}
-// Group 1: port (digits), Group 2: namespace--name (alphanumeric and hyphens)
+// Group 1: port (digits), Group 2: sandbox ID, e.g. namespace--name (alphanumeric and hyphens)
 var hostPattern = regexp.MustCompile(`^(\d+)-([a-zA-Z0-9\-]+)\.`)
 
 const (
@@ -2,12 +2,16 @@ package sandbox_manager
 
 import (
 	"context"
+	"crypto/subtle"
 	"fmt"
 	"time"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/klog/v2"
+	"k8s.io/utils/ptr"
 
 	"github.com/openkruise/agents/api/v1alpha1"
+	"github.com/openkruise/agents/pkg/sandbox-manager/consts"
 	"github.com/openkruise/agents/pkg/sandbox-manager/errors"
 	"github.com/openkruise/agents/pkg/sandbox-manager/infra"
 	utils "github.com/openkruise/agents/pkg/utils/sandbox-manager"
@@ -16,6 +20,9 @@ import (
 // ClaimSandbox attempts to lock a Pod and assign it to the current caller
 func (m *SandboxManager) ClaimSandbox(ctx context.Context, opts infra.ClaimSandboxOptions) (infra.Sandbox, error) {
 	log := klog.FromContext(ctx)
+	if m.Draining() {
+		return nil, errShuttingDown
+	}
 	if !m.infra.HasTemplate(opts.Template) {
 		// Requirement: Track failure in API layer
 		SandboxCreationResponses.WithLabelValues("failure").Inc()
@@ -33,6 +40,7 @@ func (m *SandboxManager) ClaimSandbox(ctx context.Context, opts infra.ClaimSandb
 	SandboxCreationResponses.WithLabelValues("success").Inc()
 	// Requirement: Only measure the latency when no error exists
 	SandboxCreationLatency.Observe(float64(metrics.Total.Milliseconds()))
+	m.claimLatencies.observe(opts.Template, metrics.Total)
 
 	state, reason := sandbox.GetState()
 	log.Info("sandbox claimed", "sandbox", klog.KObj(sandbox), "metrics", metrics.String(), "state", state, "reason", reason)
@@ -46,6 +54,9 @@ func (m *SandboxManager) ClaimSandbox(ctx context.Context, opts infra.ClaimSandb
 
 func (m *SandboxManager) CloneSandbox(ctx context.Context, opts infra.CloneSandboxOptions) (infra.Sandbox, error) {
 	log := klog.FromContext(ctx)
+	if m.Draining() {
+		return nil, errShuttingDown
+	}
 	sandbox, metrics, err := m.infra.CloneSandbox(ctx, opts)
 	if err != nil {
 		log.Error(err, "failed to clone sandbox", "metrics", metrics)
@@ -131,6 +142,126 @@ func (m *SandboxManager) DeleteCheckpoint(ctx context.Context, user string, chec
 	return nil
 }
 
+// WaitForClaim long-polls a SandboxClaim until it is completed or timeout
+func (m *SandboxManager) WaitForClaim(ctx context.Context, namespace, name string, timeout time.Duration) (*v1alpha1.SandboxClaim, []infra.Sandbox, error) {
+	log := klog.FromContext(ctx).WithValues("claim", klog.KRef(namespace, name))
+	log.Info("waiting for sandboxclaim completed", "timeout", timeout)
+	claim, sandboxes, err := m.infra.WaitForClaim(ctx, namespace, name, timeout)
+	if err != nil {
+		log.Error(err, "failed to wait for sandboxclaim")
+		return nil, nil, errors.NewError(errors.ErrorNotFound, fmt.Sprintf("sandboxclaim %s/%s not found", namespace, name))
+	}
+	log.Info("sandboxclaim waited", "phase", claim.Status.Phase, "sandboxes", len(sandboxes))
+	return claim, sandboxes, nil
+}
+
+// DecideClaimApproval approves or rejects a SandboxClaim requiring approval
+func (m *SandboxManager) DecideClaimApproval(ctx context.Context, namespace, name string, approved bool) (*v1alpha1.SandboxClaim, error) {
+	log := klog.FromContext(ctx).WithValues("claim", klog.KRef(namespace, name))
+	decision := v1alpha1.SandboxClaimApprovalRejected
+	if approved {
+		decision = v1alpha1.SandboxClaimApprovalApproved
+	}
+	claim, err := m.infra.DecideClaimApproval(ctx, namespace, name, decision)
+	if err != nil {
+		log.Error(err, "failed to decide sandboxclaim approval", "decision", decision)
+		return nil, err
+	}
+	return claim, nil
+}
+
+// RecordHeartbeat records a heartbeat reported by the agent in the sandbox, which is authenticated by the access
+// token of the sandbox. The heartbeat is persisted into the sandbox status at most once per persist interval.
+func (m *SandboxManager) RecordHeartbeat(ctx context.Context, sandboxID, accessToken string, lastActivity *time.Time) error {
+	log := klog.FromContext(ctx).WithValues("sandboxID", sandboxID)
+	sbx, err := m.infra.GetClaimedSandbox(ctx, sandboxID)
+	if err != nil {
+		log.Error(err, "failed to get sandbox from cache")
+		return errors.NewError(errors.ErrorNotFound, fmt.Sprintf("sandbox %s not found", sandboxID))
+	}
+	expected := sbx.GetAccessToken()
+	if expected == "" || subtle.ConstantTimeCompare([]byte(expected), []byte(accessToken)) != 1 {
+		return errors.NewError(errors.ErrorNotAllowed, fmt.Sprintf("invalid access token for sandbox %s", sandboxID))
+	}
+	if state, reason := sbx.GetState(); state != v1alpha1.SandboxStateRunning {
+		return errors.NewError(errors.ErrorConflict, fmt.Sprintf("sandbox %s is not running (state %s, reason %s)", sandboxID, state, reason))
+	}
+
+	now := time.Now()
+	heartbeat := v1alpha1.SandboxHeartbeat{LastHeartbeatTime: metav1.NewTime(now)}
+	if lastActivity != nil {
+		activity := *lastActivity
+		if activity.After(now) {
+			activity = now // do not trust the clock of the sandbox
+		}
+		heartbeat.LastActivityTime = ptr.To(metav1.NewTime(activity))
+	}
+	if persisted := sbx.GetHeartbeat(); persisted != nil {
+		if now.Sub(persisted.LastHeartbeatTime.Time) < m.heartbeatPersistInterval {
+			log.V(consts.DebugLogLevel).Info("heartbeat damped", "lastHeartbeatTime", persisted.LastHeartbeatTime)
+			return nil
+		}
+		if persisted.LastActivityTime != nil &&
+			(heartbeat.LastActivityTime == nil || persisted.LastActivityTime.After(heartbeat.LastActivityTime.Time)) {
+			heartbeat.LastActivityTime = persisted.LastActivityTime
+		}
+	}
+	if err = sbx.SaveHeartbeat(ctx, heartbeat); err != nil {
+		log.Error(err, "failed to save heartbeat")
+		return errors.NewError(errors.ErrorInternal, fmt.Sprintf("failed to save heartbeat: %v", err))
+	}
+	return nil
+}
+
+// QuarantineSandbox freezes and isolates a suspicious sandbox for security review. It is triggered by administrators
+// or by anomaly detectors, so the sandbox is looked up regardless of its owner.
+func (m *SandboxManager) QuarantineSandbox(ctx context.Context, sandboxID string, opts infra.QuarantineOptions) error {
+	log := klog.FromContext(ctx).WithValues("sandboxID", sandboxID)
+	sbx, err := m.infra.GetClaimedSandbox(ctx, sandboxID)
+	if err != nil {
+		log.Error(err, "failed to get sandbox from cache")
+		return errors.NewError(errors.ErrorNotFound, fmt.Sprintf("sandbox %s not found", sandboxID))
+	}
+	if state, reason := sbx.GetState(); state == v1alpha1.SandboxStateDead {
+		return errors.NewError(errors.ErrorConflict, fmt.Sprintf("sandbox %s is dead (reason %s)", sandboxID, reason))
+	}
+	if err = sbx.Quarantine(ctx, opts); err != nil {
+		log.Error(err, "failed to quarantine sandbox")
+		return errors.NewError(errors.ErrorInternal, fmt.Sprintf("failed to quarantine sandbox: %v", err))
+	}
+	return nil
+}
+
+// DebugSandbox injects an ephemeral debug container into the pod of a running sandbox without interrupting it, and
+// returns the name of the container and when it exits by itself.
+func (m *SandboxManager) DebugSandbox(ctx context.Context, sandboxID string, opts infra.DebugOptions) (string, time.Time, error) {
+	log := klog.FromContext(ctx).WithValues("sandboxID", sandboxID)
+	if opts.Image == "" {
+		opts.Image = consts.DefaultDebugImage
+	}
+	if opts.TTL == 0 {
+		opts.TTL = consts.DefaultDebugTTL
+	}
+	if opts.TTL < time.Second || opts.TTL > consts.MaxDebugTTL {
+		return "", time.Time{}, errors.NewError(errors.ErrorBadRequest, fmt.Sprintf("ttl must be between 1s and %s", consts.MaxDebugTTL))
+	}
+	sbx, err := m.infra.GetClaimedSandbox(ctx, sandboxID)
+	if err != nil {
+		log.Error(err, "failed to get sandbox from cache")
+		return "", time.Time{}, errors.NewError(errors.ErrorNotFound, fmt.Sprintf("sandbox %s not found", sandboxID))
+	}
+	if state, reason := sbx.GetState(); state != v1alpha1.SandboxStateRunning {
+		return "", time.Time{}, errors.NewError(errors.ErrorConflict, fmt.Sprintf("sandbox %s is not running (state %s, reason %s)", sandboxID, state, reason))
+	}
+	start := time.Now()
+	name, err := sbx.Debug(ctx, opts)
+	if err != nil {
+		log.Error(err, "failed to debug sandbox")
+		return "", time.Time{}, errors.NewError(errors.ErrorInternal, fmt.Sprintf("failed to debug sandbox: %v", err))
+	}
+	return name, start.Add(opts.TTL), nil
+}
+
 func (m *SandboxManager) GetOwnerOfSandbox(sandboxID string) (string, bool) {
 	route, ok := m.proxy.LoadRoute(sandboxID)
 	return route.Owner, ok
@@ -189,6 +320,9 @@ func (m *SandboxManager) ResumeSandbox(ctx context.Context, sbx infra.Sandbox) e
 // DeleteSandbox deletes a sandbox and syncs route with peers
 func (m *SandboxManager) DeleteSandbox(ctx context.Context, sbx infra.Sandbox) error {
 	log := klog.FromContext(ctx).WithValues("sandbox", klog.KObj(sbx))
+	if sbx.GetQuarantine() != nil {
+		return errors.NewError(errors.ErrorNotAllowed, fmt.Sprintf("sandbox %s is quarantined", sbx.GetSandboxID()))
+	}
 	route := sbx.GetRoute()
 	route.State = v1alpha1.SandboxStateDead
 
@@ -1481,3 +1481,131 @@ func TestSandboxManager_DeleteCheckpoint(t *testing.T) {
 		})
 	}
 }
+
+func TestSandboxManager_RecordHeartbeat(t *testing.T) {
+	utils.InitLogOutput()
+	manager := setupTestManager(t, config.SandboxManagerOptions{HeartbeatPersistInterval: time.Hour})
+	client := manager.client.SandboxClient
+
+	newSandbox := func(name, token string, paused bool) *agentsv1alpha1.Sandbox {
+		sbx := &agentsv1alpha1.Sandbox{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+				Labels: map[string]string{
+					agentsv1alpha1.LabelSandboxIsClaimed: "true",
+				},
+				Annotations: map[string]string{
+					agentsv1alpha1.AnnotationOwner: testUser,
+				},
+			},
+			Status: agentsv1alpha1.SandboxStatus{
+				Phase: agentsv1alpha1.SandboxRunning,
+				Conditions: []metav1.Condition{
+					{
+						Type:   string(agentsv1alpha1.SandboxConditionReady),
+						Status: metav1.ConditionTrue,
+					},
+				},
+				PodInfo: agentsv1alpha1.PodInfo{
+					PodIP: "1.2.3.4",
+				},
+			},
+		}
+		if token != "" {
+			sbx.Annotations[agentsv1alpha1.AnnotationRuntimeAccessToken] = token
+		}
+		if paused {
+			sbx.Spec.Paused = true
+			sbx.Status.Phase = agentsv1alpha1.SandboxPaused
+			sbx.Status.Conditions = []metav1.Condition{
+				{
+					Type:   string(agentsv1alpha1.SandboxConditionPaused),
+					Status: metav1.ConditionTrue,
+				},
+			}
+		}
+		return sbx
+	}
+	for _, sbx := range []*agentsv1alpha1.Sandbox{
+		newSandbox("running", "token", false),
+		newSandbox("no-token", "", false),
+		newSandbox("paused", "token", true),
+	} {
+		CreateSandboxWithStatus(t, client, sbx)
+	}
+	require.Eventually(t, func() bool {
+		_, err := manager.infra.GetClaimedSandbox(t.Context(), "default--paused")
+		return err == nil
+	}, time.Second, 10*time.Millisecond)
+
+	errorTests := []struct {
+		name              string
+		sandboxID         string
+		token             string
+		expectedErrorCode errors.ErrorCode
+	}{
+		{
+			name:              "not found",
+			sandboxID:         "default--not-found",
+			token:             "token",
+			expectedErrorCode: errors.ErrorNotFound,
+		},
+		{
+			name:              "invalid token",
+			sandboxID:         "default--running",
+			token:             "invalid",
+			expectedErrorCode: errors.ErrorNotAllowed,
+		},
+		{
+			name:              "sandbox without token",
+			sandboxID:         "default--no-token",
+			expectedErrorCode: errors.ErrorNotAllowed,
+		},
+		{
+			name:              "paused",
+			sandboxID:         "default--paused",
+			token:             "token",
+			expectedErrorCode: errors.ErrorConflict,
+		},
+	}
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(t.Context(), 100*time.Millisecond)
+			defer cancel()
+			err := manager.RecordHeartbeat(ctx, tt.sandboxID, tt.token, nil)
+			require.Error(t, err)
+			assert.Equal(t, tt.expectedErrorCode, errors.GetErrCode(err))
+		})
+	}
+
+	getHeartbeat := func() *agentsv1alpha1.SandboxHeartbeat {
+		sbx, err := client.ApiV1alpha1().Sandboxes("default").Get(t.Context(), "running", metav1.GetOptions{})
+		require.NoError(t, err)
+		return sbx.Status.Heartbeat
+	}
+
+	// the activity reported in the future is clamped
+	start := time.Now()
+	require.NoError(t, manager.RecordHeartbeat(t.Context(), "default--running", "token", ptr.To(start.Add(time.Hour))))
+	first := getHeartbeat()
+	require.NotNil(t, first)
+	require.NotNil(t, first.LastActivityTime)
+	assert.WithinDuration(t, start, first.LastHeartbeatTime.Time, 5*time.Second)
+	assert.False(t, first.LastActivityTime.After(first.LastHeartbeatTime.Time))
+	require.Eventually(t, func() bool {
+		sbx, err := manager.infra.GetClaimedSandbox(t.Context(), "default--running")
+		return err == nil && sbx.GetHeartbeat() != nil
+	}, time.Second, 10*time.Millisecond)
+
+	// heartbeats within the persist interval are damped
+	require.NoError(t, manager.RecordHeartbeat(t.Context(), "default--running", "token", nil))
+	assert.Equal(t, first, getHeartbeat())
+
+	// the persisted activity is kept if the reported one is older
+	manager.heartbeatPersistInterval = 0
+	require.NoError(t, manager.RecordHeartbeat(t.Context(), "default--running", "token", ptr.To(start.Add(-time.Hour))))
+	second := getHeartbeat()
+	assert.Equal(t, first.LastActivityTime, second.LastActivityTime)
+	assert.False(t, second.LastHeartbeatTime.Before(&first.LastHeartbeatTime))
+}
@@ -0,0 +1,185 @@
+package sandbox_manager
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"golang.org/x/time/rate"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/klog/v2"
+
+	"github.com/openkruise/agents/api/v1alpha1"
+	"github.com/openkruise/agents/pkg/sandbox-manager/errors"
+	"github.com/openkruise/agents/pkg/utils/sandboxutils"
+)
+
+// BulkResource is the kind of objects released by a bulk release
+type BulkResource string
+
+const (
+	// BulkResourceSandboxes releases the claimed sandboxes matching the selector
+	BulkResourceSandboxes BulkResource = "sandboxes"
+	// BulkResourceSandboxClaims deletes the SandboxClaims matching the selector
+	BulkResourceSandboxClaims BulkResource = "sandboxclaims"
+)
+
+const (
+	// DefaultBulkReleaseLimit is the default number of objects listed in one page of a bulk release
+	DefaultBulkReleaseLimit = 100
+	// MaxBulkReleaseLimit is the max number of objects listed in one page of a bulk release
+	MaxBulkReleaseLimit = 500
+	// DefaultBulkReleaseQPS is the default rate of deletions of a bulk release
+	DefaultBulkReleaseQPS = 10
+	// MaxBulkReleaseQPS is the max rate of deletions of a bulk release
+	MaxBulkReleaseQPS = 100
+)
+
+// BulkReleaseOptions selects one page of objects to release
+type BulkReleaseOptions struct {
+	Resource BulkResource
+	// Namespace of the objects, empty for all namespaces
+	Namespace string
+	// Selector is the label selector of the objects, it is required so that nothing is released by accident
+	Selector string
+	// Limit and Continue paginate the objects on the API server
+	Limit    int64
+	Continue string
+	// DryRun only counts the matched objects
+	DryRun bool
+	// QPS limits the rate of deletions
+	QPS float64
+}
+
+// BulkReleaseFailure is an object failed to be released
+type BulkReleaseFailure struct {
+	Name    string
+	Message string
+}
+
+// BulkReleaseResult is the result of releasing one page of objects
+type BulkReleaseResult struct {
+	// Matched is the number of objects matched in the page
+	Matched int
+	// Released are the names of the objects released, i.e. sandbox IDs or namespace/name of SandboxClaims
+	Released []string
+	Failed   []BulkReleaseFailure
+	// Continue is the token of the next page, empty if it is the last page
+	Continue string
+	// RemainingItemCount is the estimated number of objects after the page, if known
+	RemainingItemCount *int64
+}
+
+// BulkRelease releases one page of sandboxes, or deletes one page of SandboxClaims, matching a label selector. Callers
+// release all matched objects by calling it again with the returned continue token until it is empty. Objects failed
+// to be released are reported in the result instead of failing the whole page.
+func (m *SandboxManager) BulkRelease(ctx context.Context, opts BulkReleaseOptions) (*BulkReleaseResult, error) {
+	selector, err := bulkReleaseSelector(opts)
+	if err != nil {
+		return nil, errors.NewError(errors.ErrorBadRequest, err.Error())
+	}
+	if opts.Limit == 0 {
+		opts.Limit = DefaultBulkReleaseLimit
+	}
+	if opts.Limit < 0 || opts.Limit > MaxBulkReleaseLimit {
+		return nil, errors.NewError(errors.ErrorBadRequest, fmt.Sprintf("limit must be between 1 and %d", MaxBulkReleaseLimit))
+	}
+	if opts.QPS == 0 {
+		opts.QPS = DefaultBulkReleaseQPS
+	}
+	if opts.QPS < 0 || opts.QPS > MaxBulkReleaseQPS {
+		return nil, errors.NewError(errors.ErrorBadRequest, fmt.Sprintf("qps must be between 0 and %d", MaxBulkReleaseQPS))
+	}
+	log := klog.FromContext(ctx).WithValues("resource", opts.Resource, "namespace", opts.Namespace, "selector", selector.String())
+	listOpts := metav1.ListOptions{LabelSelector: selector.String(), Limit: opts.Limit, Continue: opts.Continue}
+
+	var names []string
+	var listMeta metav1.ListMeta
+	switch opts.Resource {
+	case BulkResourceSandboxes:
+		list, err := m.client.SandboxClient.ApiV1alpha1().Sandboxes(opts.Namespace).List(ctx, listOpts)
+		if err != nil {
+			log.Error(err, "failed to list sandboxes")
+			return nil, errors.NewError(errors.ErrorInternal, fmt.Sprintf("failed to list sandboxes: %v", err))
+		}
+		for i := range list.Items {
+			names = append(names, sandboxutils.GetSandboxID(&list.Items[i]))
+		}
+		listMeta = list.ListMeta
+	case BulkResourceSandboxClaims:
+		list, err := m.client.SandboxClient.ApiV1alpha1().SandboxClaims(opts.Namespace).List(ctx, listOpts)
+		if err != nil {
+			log.Error(err, "failed to list sandboxclaims")
+			return nil, errors.NewError(errors.ErrorInternal, fmt.Sprintf("failed to list sandboxclaims: %v", err))
+		}
+		for i := range list.Items {
+			names = append(names, list.Items[i].Namespace+"/"+list.Items[i].Name)
+		}
+		listMeta = list.ListMeta
+	}
+
+	result := &BulkReleaseResult{
+		Matched:            len(names),
+		Continue:           listMeta.Continue,
+		RemainingItemCount: listMeta.RemainingItemCount,
+	}
+	if opts.DryRun {
+		log.Info("bulk release dry run", "matched", result.Matched)
+		return result, nil
+	}
+	limiter := rate.NewLimiter(rate.Limit(opts.QPS), 1)
+	for i, name := range names {
+		if err = limiter.Wait(ctx); err != nil {
+			// the context is done, report the rest as failed
+			for _, rest := range names[i:] {
+				result.Failed = append(result.Failed, BulkReleaseFailure{Name: rest, Message: err.Error()})
+			}
+			break
+		}
+		if err = m.releaseOne(ctx, opts.Resource, name); err != nil {
+			log.Error(err, "failed to release", "name", name)
+			result.Failed = append(result.Failed, BulkReleaseFailure{Name: name, Message: err.Error()})
+			continue
+		}
+		result.Released = append(result.Released, name)
+	}
+	log.Info("bulk release done", "matched", result.Matched, "released", len(result.Released), "failed", len(result.Failed))
+	return result, nil
+}
+
+// bulkReleaseSelector parses the selector of the options. Only claimed sandboxes are selected, so that the
+// sandboxes in pools are never released.
+func bulkReleaseSelector(opts BulkReleaseOptions) (labels.Selector, error) {
+	if opts.Resource != BulkResourceSandboxes && opts.Resource != BulkResourceSandboxClaims {
+		return nil, fmt.Errorf("resource must be %s or %s", BulkResourceSandboxes, BulkResourceSandboxClaims)
+	}
+	if opts.Selector == "" {
+		return nil, fmt.Errorf("selector is required")
+	}
+	selector, err := labels.Parse(opts.Selector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid selector: %v", err)
+	}
+	if opts.Resource == BulkResourceSandboxes {
+		claimed, err := labels.NewRequirement(v1alpha1.LabelSandboxIsClaimed, selection.Equals, []string{v1alpha1.True})
+		if err != nil {
+			return nil, err
+		}
+		selector = selector.Add(*claimed)
+	}
+	return selector, nil
+}
+
+func (m *SandboxManager) releaseOne(ctx context.Context, resource BulkResource, name string) error {
+	if resource == BulkResourceSandboxClaims {
+		namespace, claimName, _ := strings.Cut(name, "/")
+		return m.client.SandboxClient.ApiV1alpha1().SandboxClaims(namespace).Delete(ctx, claimName, metav1.DeleteOptions{})
+	}
+	sbx, err := m.infra.GetClaimedSandbox(ctx, name)
+	if err != nil {
+		return fmt.Errorf("sandbox not found in cache: %w", err)
+	}
+	return m.DeleteSandbox(ctx, sbx)
+}
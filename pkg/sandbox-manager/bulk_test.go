@@ -0,0 +1,144 @@
+package sandbox_manager
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+	"github.com/openkruise/agents/pkg/sandbox-manager/errors"
+	utils "github.com/openkruise/agents/pkg/utils/sandbox-manager"
+)
+
+func TestSandboxManager_BulkRelease(t *testing.T) {
+	utils.InitLogOutput()
+	manager := setupTestManager(t)
+	client := manager.client.SandboxClient
+
+	newSandbox := func(name, team string, claimed bool) *agentsv1alpha1.Sandbox {
+		sbx := &agentsv1alpha1.Sandbox{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Namespace:   "default",
+				Labels:      map[string]string{"team": team},
+				Annotations: map[string]string{agentsv1alpha1.AnnotationOwner: testUser},
+			},
+			Status: agentsv1alpha1.SandboxStatus{
+				Phase: agentsv1alpha1.SandboxRunning,
+				Conditions: []metav1.Condition{
+					{
+						Type:   string(agentsv1alpha1.SandboxConditionReady),
+						Status: metav1.ConditionTrue,
+					},
+				},
+				PodInfo: agentsv1alpha1.PodInfo{PodIP: "1.2.3.4"},
+			},
+		}
+		if claimed {
+			sbx.Labels[agentsv1alpha1.LabelSandboxIsClaimed] = agentsv1alpha1.True
+		}
+		return sbx
+	}
+	CreateSandboxWithStatus(t, client, newSandbox("a-1", "a", true))
+	CreateSandboxWithStatus(t, client, newSandbox("a-2", "a", true))
+	CreateSandboxWithStatus(t, client, newSandbox("a-pool", "a", false))
+	CreateSandboxWithStatus(t, client, newSandbox("b-1", "b", true))
+	for _, name := range []string{"claim-a", "claim-b"} {
+		_, err := client.ApiV1alpha1().SandboxClaims("default").Create(t.Context(), &agentsv1alpha1.SandboxClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: map[string]string{"team": name[len(name)-1:]}},
+		}, metav1.CreateOptions{})
+		require.NoError(t, err)
+	}
+	require.Eventually(t, func() bool {
+		_, err1 := manager.infra.GetClaimedSandbox(t.Context(), "default--a-1")
+		_, err2 := manager.infra.GetClaimedSandbox(t.Context(), "default--a-2")
+		return err1 == nil && err2 == nil
+	}, time.Second, 10*time.Millisecond)
+
+	tests := []struct {
+		name              string
+		opts              BulkReleaseOptions
+		expectedErrorCode errors.ErrorCode
+		expectMatched     int
+		expectReleased    []string
+		expectDeleted     []string
+		expectKept        []string
+	}{
+		{
+			name:              "selector required",
+			opts:              BulkReleaseOptions{Resource: BulkResourceSandboxes},
+			expectedErrorCode: errors.ErrorBadRequest,
+		},
+		{
+			name:              "invalid selector",
+			opts:              BulkReleaseOptions{Resource: BulkResourceSandboxes, Selector: "team in (a"},
+			expectedErrorCode: errors.ErrorBadRequest,
+		},
+		{
+			name:              "invalid resource",
+			opts:              BulkReleaseOptions{Resource: "pods", Selector: "team=a"},
+			expectedErrorCode: errors.ErrorBadRequest,
+		},
+		{
+			name:              "invalid limit",
+			opts:              BulkReleaseOptions{Resource: BulkResourceSandboxes, Selector: "team=a", Limit: MaxBulkReleaseLimit + 1},
+			expectedErrorCode: errors.ErrorBadRequest,
+		},
+		{
+			name:              "invalid qps",
+			opts:              BulkReleaseOptions{Resource: BulkResourceSandboxes, Selector: "team=a", QPS: -1},
+			expectedErrorCode: errors.ErrorBadRequest,
+		},
+		{
+			name:          "dry run",
+			opts:          BulkReleaseOptions{Resource: BulkResourceSandboxes, Namespace: "default", Selector: "team=a", DryRun: true},
+			expectMatched: 2,
+			expectKept:    []string{"a-1", "a-2", "a-pool", "b-1"},
+		},
+		{
+			name:           "release sandboxes",
+			opts:           BulkReleaseOptions{Resource: BulkResourceSandboxes, Namespace: "default", Selector: "team=a", QPS: MaxBulkReleaseQPS},
+			expectMatched:  2,
+			expectReleased: []string{"default--a-1", "default--a-2"},
+			expectDeleted:  []string{"a-1", "a-2"},
+			expectKept:     []string{"a-pool", "b-1"},
+		},
+		{
+			name:           "delete sandboxclaims",
+			opts:           BulkReleaseOptions{Resource: BulkResourceSandboxClaims, Selector: "team=b"},
+			expectMatched:  1,
+			expectReleased: []string{"default/claim-b"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := manager.BulkRelease(t.Context(), tt.opts)
+			if tt.expectedErrorCode != "" {
+				require.Error(t, err)
+				assert.Equal(t, tt.expectedErrorCode, errors.GetErrCode(err))
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectMatched, result.Matched)
+			assert.ElementsMatch(t, tt.expectReleased, result.Released)
+			assert.Empty(t, result.Failed)
+			for _, name := range tt.expectDeleted {
+				_, err = client.ApiV1alpha1().Sandboxes("default").Get(t.Context(), name, metav1.GetOptions{})
+				assert.True(t, apierrors.IsNotFound(err), name)
+			}
+			for _, name := range tt.expectKept {
+				_, err = client.ApiV1alpha1().Sandboxes("default").Get(t.Context(), name, metav1.GetOptions{})
+				assert.NoError(t, err, name)
+			}
+		})
+	}
+
+	claims, err := client.ApiV1alpha1().SandboxClaims("default").List(t.Context(), metav1.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, claims.Items, 1)
+	assert.Equal(t, "claim-a", claims.Items[0].Name)
+}
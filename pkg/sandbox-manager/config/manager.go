@@ -1,6 +1,8 @@
 package config
 
 import (
+	"time"
+
 	"github.com/openkruise/agents/pkg/sandbox-manager/consts"
 	"github.com/openkruise/agents/pkg/utils"
 )
@@ -18,6 +20,8 @@ type SandboxManagerOptions struct {
 	MaxCreateQPS          int
 	ExtProcMaxConcurrency uint32
 	MemberlistBindPort    int
+	// HeartbeatPersistInterval is the min interval of persisting heartbeats of a sandbox into its status
+	HeartbeatPersistInterval time.Duration
 }
 
 func InitOptions(opts SandboxManagerOptions) SandboxManagerOptions {
@@ -36,5 +40,8 @@ func InitOptions(opts SandboxManagerOptions) SandboxManagerOptions {
 	if opts.MemberlistBindPort <= 0 {
 		opts.MemberlistBindPort = DefaultMemberlistBindPort
 	}
+	if opts.HeartbeatPersistInterval <= 0 {
+		opts.HeartbeatPersistInterval = consts.DefaultHeartbeatPersistInterval
+	}
 	return opts
 }
@@ -10,6 +10,13 @@ const (
 	DefaultWaitCheckpointTimeout  = 60 * time.Second
 	DefaultClaimWorkers           = 500
 	DefaultCreateQPS              = 49
+	// DefaultHeartbeatPersistInterval is the min interval of persisting heartbeats of a sandbox into its status
+	DefaultHeartbeatPersistInterval = 30 * time.Second
+	// DefaultDebugImage is the image of debug containers if not specified
+	DefaultDebugImage = "busybox:1.36"
+	// DefaultDebugTTL and MaxDebugTTL are the default and max time debug containers run before they exit
+	DefaultDebugTTL = 15 * time.Minute
+	MaxDebugTTL     = 4 * time.Hour
 )
 
 const (
@@ -3,7 +3,10 @@ package sandbox_manager
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"os"
+	"sync/atomic"
+	"time"
 
 	"github.com/google/uuid"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -27,6 +30,16 @@ type SandboxManager struct {
 
 	infra infra.Infrastructure
 	proxy *proxy.Server
+
+	heartbeatPersistInterval time.Duration
+	securityAlertOptions     SecurityAlertOptions
+	shutdownOptions          ShutdownOptions
+	introspectionOptions     IntrospectionOptions
+	introspectionServer      *http.Server
+	claimLatencies           *claimLatencies
+
+	// draining is set once the sandbox manager starts shutting down
+	draining atomic.Bool
 }
 
 // NewSandboxManager creates a new SandboxManager instance.
@@ -49,6 +62,12 @@ func NewSandboxManager(client *clients.ClientSet, adapter proxy.RequestAdapter,
 		peersManager:       peersManager,
 		proxy:              proxy.NewServer(adapter, peersManager, opts),
 		memberlistBindPort: opts.MemberlistBindPort,
+
+		heartbeatPersistInterval: opts.HeartbeatPersistInterval,
+		securityAlertOptions:     DefaultSecurityAlertOptions,
+		shutdownOptions:          DefaultShutdownOptions,
+		introspectionOptions:     DefaultIntrospectionOptions,
+		claimLatencies:           newClaimLatencies(),
 	}
 	var err error
 	m.infra, err = sandboxcr.NewInfra(client, m.proxy, opts)
@@ -65,6 +84,7 @@ func (m *SandboxManager) Run(ctx context.Context, sysNs, peerSelector string) er
 			klog.Error(err, "proxy stopped")
 		}
 	}()
+	m.runIntrospection()
 
 	// Get pod IP for memberlist binding
 	podIP := os.Getenv("POD_IP")
@@ -108,17 +128,6 @@ func (m *SandboxManager) Run(ctx context.Context, sysNs, peerSelector string) er
 	return nil
 }
 
-func (m *SandboxManager) Stop(ctx context.Context) {
-	log := klog.FromContext(ctx)
-	m.proxy.Stop(ctx)
-	m.infra.Stop(ctx)
-	if m.peersManager != nil {
-		if err := m.peersManager.Stop(); err != nil {
-			log.Error(err, "failed to stop peers manager")
-		}
-	}
-}
-
 func (m *SandboxManager) GetInfra() infra.Infrastructure {
 	return m.infra
 }
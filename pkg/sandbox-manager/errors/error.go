@@ -14,6 +14,8 @@ const (
 	ErrorConflict   = ErrorCode("Conflict")
 	ErrorUnknown    = ErrorCode("Unknown")
 	ErrorBadRequest = ErrorCode("BadRequest")
+	// ErrorUnavailable means the sandbox manager is shutting down, the request can be retried on another instance
+	ErrorUnavailable = ErrorCode("Unavailable")
 )
 
 type Error struct {
@@ -0,0 +1,244 @@
+// Package infratest provides the conformance suite every implementation of infra.Infrastructure must pass, so that
+// the servers behave the same whichever provider serves the sandboxes.
+package infratest
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+	"github.com/openkruise/agents/pkg/sandbox-manager/config"
+	"github.com/openkruise/agents/pkg/sandbox-manager/infra"
+	testutils "github.com/openkruise/agents/test/utils"
+)
+
+const (
+	// Template is the pool the sandboxes of the suite are added to
+	Template = "conformance-template"
+	// User is the owner of the sandboxes claimed by the suite
+	User = "conformance-user"
+
+	claimTimeout = 500 * time.Millisecond
+	waitTimeout  = 5 * time.Second
+	waitInterval = 10 * time.Millisecond
+)
+
+// Runtime is the agent-runtime serving a sandbox
+type Runtime struct {
+	URL         string
+	AccessToken string
+}
+
+// Environment is a fresh environment of an Infrastructure implementation for a single conformance case.
+type Environment interface {
+	// Infra returns the running Infrastructure under test. Its sandboxes must progress as with the real backend,
+	// e.g. a sandbox being paused becomes paused, so providers backed by fakes have to simulate the controllers.
+	Infra() infra.Infrastructure
+	// AddAvailableSandbox adds a ready and unclaimed sandbox served by the runtime to the pool of the template, and
+	// returns once the sandbox can be claimed.
+	AddAvailableSandbox(t *testing.T, template string, runtime Runtime)
+}
+
+// Provider sets up an Environment, which is torn down by the provider with t.Cleanup.
+type Provider func(t *testing.T) Environment
+
+// RunConformance runs the conformance suite against the provider.
+func RunConformance(t *testing.T, provider Provider) {
+	cases := []struct {
+		name string
+		run  func(t *testing.T, env Environment, runtime Runtime)
+	}{
+		{name: "ClaimSandbox", run: testClaimSandbox},
+		{name: "ClaimSandboxIsExclusive", run: testClaimSandboxIsExclusive},
+		{name: "ClaimSandboxWithoutStock", run: testClaimSandboxWithoutStock},
+		{name: "ClaimSandboxInitRuntime", run: testClaimSandboxInitRuntime},
+		{name: "FailedClaimIsCleanedUp", run: testFailedClaimIsCleanedUp},
+		{name: "SaveTimeout", run: testSaveTimeout},
+		{name: "PauseAndResume", run: testPauseAndResume},
+		{name: "Kill", run: testKill},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := testutils.NewTestRuntimeServer(testutils.TestRuntimeServerOptions{
+				RunCommandResult:      testutils.RunCommandResult{PID: 1, Exited: true},
+				RunCommandImmediately: true,
+			})
+			t.Cleanup(server.Close)
+			tc.run(t, provider(t), Runtime{URL: server.URL, AccessToken: testutils.AccessToken})
+		})
+	}
+}
+
+func claimOptions() infra.ClaimSandboxOptions {
+	return infra.ClaimSandboxOptions{User: User, Template: Template, ClaimTimeout: claimTimeout}
+}
+
+// claim claims a sandbox and returns it once it can be found by its ID, as the servers get the claimed sandboxes.
+func claim(t *testing.T, env Environment, opts infra.ClaimSandboxOptions) infra.Sandbox {
+	sbx, _, err := env.Infra().ClaimSandbox(t.Context(), opts)
+	require.NoError(t, err)
+	require.NotNil(t, sbx)
+	var claimed infra.Sandbox
+	require.Eventually(t, func() bool {
+		claimed, err = env.Infra().GetClaimedSandbox(t.Context(), sbx.GetSandboxID())
+		return err == nil && claimed.GetAnnotations()[agentsv1alpha1.AnnotationOwner] == opts.User
+	}, waitTimeout, waitInterval, "claimed sandbox should be found by its ID")
+	return claimed
+}
+
+func selectedIDs(t *testing.T, env Environment) []string {
+	sandboxes, err := env.Infra().SelectSandboxes(User)
+	require.NoError(t, err)
+	ids := make([]string, 0, len(sandboxes))
+	for _, sbx := range sandboxes {
+		ids = append(ids, sbx.GetSandboxID())
+	}
+	return ids
+}
+
+// testClaimSandbox checks a claimed sandbox is running, owned by the user and can be found by its ID.
+func testClaimSandbox(t *testing.T, env Environment, runtime Runtime) {
+	env.AddAvailableSandbox(t, Template, runtime)
+	sbx := claim(t, env, claimOptions())
+
+	state, reason := sbx.GetState()
+	assert.Equal(t, agentsv1alpha1.SandboxStateRunning, state, reason)
+	assert.Equal(t, Template, sbx.GetTemplate())
+	assert.Equal(t, User, sbx.GetAnnotations()[agentsv1alpha1.AnnotationOwner])
+	_, err := sbx.GetClaimTime()
+	assert.NoError(t, err, "claim time should be recorded")
+
+	require.Eventually(t, func() bool {
+		return assert.ObjectsAreEqual([]string{sbx.GetSandboxID()}, selectedIDs(t, env))
+	}, waitTimeout, waitInterval, "claimed sandbox should be selected for the user")
+}
+
+// testClaimSandboxIsExclusive checks concurrent claims never get the same sandbox.
+func testClaimSandboxIsExclusive(t *testing.T, env Environment, runtime Runtime) {
+	const available = 3
+	for range available {
+		env.AddAvailableSandbox(t, Template, runtime)
+	}
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		claimed = map[string]int{}
+		errs    []error
+	)
+	for range available {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			opts := claimOptions()
+			opts.ClaimTimeout = waitTimeout
+			sbx, _, err := env.Infra().ClaimSandbox(t.Context(), opts)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs = append(errs, err)
+				return
+			}
+			claimed[sbx.GetSandboxID()]++
+		}()
+	}
+	wg.Wait()
+	require.Empty(t, errs)
+	assert.Len(t, claimed, available, "each claim should get a different sandbox")
+	for id, count := range claimed {
+		assert.Equal(t, 1, count, "sandbox %s is claimed more than once", id)
+	}
+}
+
+// testClaimSandboxWithoutStock checks claims fail when no sandbox is available, instead of taking claimed ones.
+func testClaimSandboxWithoutStock(t *testing.T, env Environment, runtime Runtime) {
+	env.AddAvailableSandbox(t, Template, runtime)
+	first := claim(t, env, claimOptions())
+
+	_, _, err := env.Infra().ClaimSandbox(t.Context(), claimOptions())
+	assert.Error(t, err, "the claimed sandbox should not be claimed again")
+	opts := claimOptions()
+	opts.Template = "not-exist"
+	_, _, err = env.Infra().ClaimSandbox(t.Context(), opts)
+	assert.Error(t, err, "claiming from an unknown pool should fail")
+
+	got, err := env.Infra().GetClaimedSandbox(t.Context(), first.GetSandboxID())
+	require.NoError(t, err)
+	assert.Equal(t, User, got.GetAnnotations()[agentsv1alpha1.AnnotationOwner])
+}
+
+// testClaimSandboxInitRuntime checks the runtime of the sandbox is initialized while claiming.
+func testClaimSandboxInitRuntime(t *testing.T, env Environment, runtime Runtime) {
+	env.AddAvailableSandbox(t, Template, runtime)
+	opts := claimOptions()
+	opts.InitRuntime = &config.InitRuntimeOptions{AccessToken: runtime.AccessToken}
+	sbx := claim(t, env, opts)
+
+	assert.Equal(t, runtime.URL, sbx.GetRuntimeURL())
+	assert.Equal(t, runtime.AccessToken, sbx.GetAccessToken())
+}
+
+// testFailedClaimIsCleanedUp checks a sandbox failing to be claimed is not left to the user.
+func testFailedClaimIsCleanedUp(t *testing.T, env Environment, _ Runtime) {
+	server := testutils.NewTestRuntimeServer(testutils.TestRuntimeServerOptions{InitErrCode: 500})
+	t.Cleanup(server.Close)
+	env.AddAvailableSandbox(t, Template, Runtime{URL: server.URL, AccessToken: testutils.AccessToken})
+	opts := claimOptions()
+	opts.InitRuntime = &config.InitRuntimeOptions{AccessToken: testutils.AccessToken}
+
+	_, _, err := env.Infra().ClaimSandbox(t.Context(), opts)
+	require.Error(t, err)
+	require.Eventually(t, func() bool {
+		return len(selectedIDs(t, env)) == 0
+	}, waitTimeout, waitInterval, "the failed sandbox should not be left to the user")
+}
+
+// testSaveTimeout checks the timeout saved for a sandbox is persisted.
+func testSaveTimeout(t *testing.T, env Environment, runtime Runtime) {
+	env.AddAvailableSandbox(t, Template, runtime)
+	sbx := claim(t, env, claimOptions())
+	timeout := infra.TimeoutOptions{
+		ShutdownTime: time.Now().Add(time.Hour).Truncate(time.Second),
+		PauseTime:    time.Now().Add(30 * time.Minute).Truncate(time.Second),
+	}
+	require.NoError(t, sbx.SaveTimeout(t.Context(), timeout))
+
+	require.Eventually(t, func() bool {
+		got, err := env.Infra().GetClaimedSandbox(t.Context(), sbx.GetSandboxID())
+		if err != nil {
+			return false
+		}
+		saved := got.GetTimeout()
+		return saved.ShutdownTime.Equal(timeout.ShutdownTime) && saved.PauseTime.Equal(timeout.PauseTime)
+	}, waitTimeout, waitInterval, "the timeout should be persisted")
+}
+
+// testPauseAndResume checks a claimed sandbox goes through running, paused and running again.
+func testPauseAndResume(t *testing.T, env Environment, runtime Runtime) {
+	env.AddAvailableSandbox(t, Template, runtime)
+	sbx := claim(t, env, claimOptions())
+
+	require.NoError(t, sbx.Pause(t.Context(), infra.PauseOptions{}))
+	state, reason := sbx.GetState()
+	assert.Equal(t, agentsv1alpha1.SandboxStatePaused, state, reason)
+	assert.Error(t, sbx.Pause(t.Context(), infra.PauseOptions{}), "a paused sandbox should not be paused again")
+
+	require.NoError(t, sbx.Resume(t.Context()))
+	state, reason = sbx.GetState()
+	assert.Equal(t, agentsv1alpha1.SandboxStateRunning, state, reason)
+	assert.Error(t, sbx.Resume(t.Context()), "a running sandbox should not be resumed")
+}
+
+// testKill checks a killed sandbox is gone for the user.
+func testKill(t *testing.T, env Environment, runtime Runtime) {
+	env.AddAvailableSandbox(t, Template, runtime)
+	sbx := claim(t, env, claimOptions())
+	require.NoError(t, sbx.Kill(t.Context()))
+
+	require.Eventually(t, func() bool {
+		return len(selectedIDs(t, env)) == 0
+	}, waitTimeout, waitInterval, "the killed sandbox should be gone for the user")
+}
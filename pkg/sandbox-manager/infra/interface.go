@@ -29,6 +29,23 @@ type PauseOptions struct {
 	Timeout *TimeoutOptions
 }
 
+// QuarantineOptions describes why a Sandbox is quarantined, see agentsv1alpha1.SandboxQuarantine
+type QuarantineOptions struct {
+	Reason string
+	Source string
+}
+
+// DebugOptions describes the ephemeral debug container injected into the pod of a Sandbox
+type DebugOptions struct {
+	// Image of the debug container
+	Image string
+	// TargetContainer is the container whose process namespace is shared with the debug container, the first
+	// container of the pod if empty
+	TargetContainer string
+	// TTL is how long the debug container runs before it exits by itself
+	TTL time.Duration
+}
+
 type Infrastructure interface {
 	Run(ctx context.Context) error // Starts the infrastructure
 	Stop(ctx context.Context)      // Stops the infrastructure
@@ -36,12 +53,18 @@ type Infrastructure interface {
 	HasCheckpoint(name string) bool
 	GetCache() CacheProvider // Get the CacheProvider for the infra
 	LoadDebugInfo() map[string]any
+	Introspect() Introspection                                                // Dump the claiming state for debugging
 	SelectSandboxes(user string) ([]Sandbox, error)                           // Select Sandboxes based on the options provided
 	GetClaimedSandbox(ctx context.Context, sandboxID string) (Sandbox, error) // Get a Sandbox interface by its ID
 	SelectSucceededCheckpoints(user string) ([]CheckpointInfo, error)
 	ClaimSandbox(ctx context.Context, opts ClaimSandboxOptions) (Sandbox, ClaimMetrics, error)
 	CloneSandbox(ctx context.Context, opts CloneSandboxOptions) (Sandbox, CloneMetrics, error)
 	DeleteCheckpoint(ctx context.Context, user string, checkpointID string) error
+	// WaitForClaim waits until the SandboxClaim is completed or timeout, and returns the latest SandboxClaim
+	// together with the sandboxes bound to it once it is completed
+	WaitForClaim(ctx context.Context, namespace, name string, timeout time.Duration) (*agentsv1alpha1.SandboxClaim, []Sandbox, error)
+	// DecideClaimApproval approves or rejects a SandboxClaim requiring approval
+	DecideClaimApproval(ctx context.Context, namespace, name, decision string) (*agentsv1alpha1.SandboxClaim, error)
 }
 
 type Sandbox interface {
@@ -61,6 +84,13 @@ type Sandbox interface {
 	SaveTimeout(ctx context.Context, opts TimeoutOptions) error
 	GetTimeout() TimeoutOptions
 	GetClaimTime() (time.Time, error)
+	GetHeartbeat() *agentsv1alpha1.SandboxHeartbeat // Get the heartbeat persisted in status, nil if never reported
+	SaveHeartbeat(ctx context.Context, heartbeat agentsv1alpha1.SandboxHeartbeat) error
+	GetSecurityAlert() *agentsv1alpha1.SandboxSecurityAlert                                             // Get the summary of runtime security alerts, nil if never alerted
+	RecordSecurityAlert(ctx context.Context, alert agentsv1alpha1.SandboxSecurityAlert) error           // Record the latest alert and count it
+	GetQuarantine() *agentsv1alpha1.SandboxQuarantine                                                   // Get the quarantine of the Sandbox, nil if not quarantined
+	Quarantine(ctx context.Context, opts QuarantineOptions) error                                       // Freeze and isolate a suspicious Sandbox
+	Debug(ctx context.Context, opts DebugOptions) (string, error)                                       // Inject an ephemeral debug container and return its name
 	Kill(ctx context.Context) error                                                                     // Delete the Sandbox resource
 	InplaceRefresh(ctx context.Context, deepcopy bool) error                                            // Update the Sandbox resource object to the latest
 	Request(ctx context.Context, method, path string, port int, body io.Reader) (*http.Response, error) // Make a request to the Sandbox
@@ -75,6 +105,8 @@ type CacheProvider interface {
 	GetSecret(namespace, name string) (*corev1.Secret, error)
 	GetConfigmap(namespace, name string) (*corev1.ConfigMap, error)
 	GetClaimedSandbox(sandboxID string) (*agentsv1alpha1.Sandbox, error)
+	GetSandbox(namespace, name string) (*agentsv1alpha1.Sandbox, error) // Get a Sandbox by its namespace and name
+	ResolveSandboxID(sandboxID string) (*agentsv1alpha1.Sandbox, error) // Resolve a Sandbox ID back to the Sandbox
 	ListSandboxWithUser(user string) ([]*agentsv1alpha1.Sandbox, error)
 	ListSandboxesInPool(pool string) ([]*agentsv1alpha1.Sandbox, error)
 	GetCheckpoint(checkpointID string) (*agentsv1alpha1.Checkpoint, error)
@@ -9,6 +9,7 @@ import (
 
 	"golang.org/x/sync/singleflight"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	k8sinformers "k8s.io/client-go/informers"
 	"k8s.io/client-go/tools/cache"
@@ -21,7 +22,9 @@ import (
 	"github.com/openkruise/agents/pkg/sandbox-manager/config"
 	"github.com/openkruise/agents/pkg/sandbox-manager/consts"
 	"github.com/openkruise/agents/pkg/utils"
+	"github.com/openkruise/agents/pkg/utils/cachetransform"
 	managerutils "github.com/openkruise/agents/pkg/utils/sandbox-manager"
+	"github.com/openkruise/agents/pkg/utils/sandboxid"
 	"github.com/openkruise/agents/pkg/utils/sandboxutils"
 )
 
@@ -37,17 +40,22 @@ type Cache struct {
 	sandboxSetInformer             cache.SharedIndexInformer
 	checkpointInformer             cache.SharedIndexInformer
 	sandboxTemplateInformer        cache.SharedIndexInformer
+	sandboxClaimInformer           cache.SharedIndexInformer
 	persistentVolumeInformer       cache.SharedIndexInformer
 	secretInformer                 cache.SharedIndexInformer
 	configmapInformer              cache.SharedIndexInformer
 	stopCh                         chan struct{}
 	waitHooks                      *sync.Map // Key: client.ObjectKey; Value: *waitEntry
 	listSandboxesGroup             singleflight.Group
+	snapshotOptions                SnapshotOptions
+	snapshotFingerprint            string
 }
 
 func NewCache(client *clients.ClientSet, opts config.SandboxManagerOptions) (*Cache, error) {
+	// Trim objects before cached to reduce memory
+	transform := cachetransform.DefaultOptions.TransformFunc()
 	// Create informer factory for custom Sandbox resources
-	informerOptions := []informers.SharedInformerOption{}
+	informerOptions := []informers.SharedInformerOption{informers.WithTransform(transform)}
 	if opts.SandboxNamespace != "" {
 		informerOptions = append(informerOptions, informers.WithNamespace(opts.SandboxNamespace))
 	}
@@ -57,17 +65,30 @@ func NewCache(client *clients.ClientSet, opts config.SandboxManagerOptions) (*Ca
 		}))
 	}
 	informerFactory := informers.NewSharedInformerFactoryWithOptions(client.SandboxClient, time.Minute*10, informerOptions...)
+	// Warm start the sandbox informer from the snapshot of the last run if possible
+	snapshotOptions, fingerprint := DefaultSnapshotOptions, snapshotFingerprint(opts)
+	if snapshotOptions.Path != "" {
+		snapshot, err := loadSnapshot(snapshotOptions.Path, fingerprint, snapshotOptions.MaxAge, time.Now())
+		if err != nil {
+			klog.InfoS("Sandbox cache will be built from a full list", "snapshot", snapshotOptions.Path, "reason", err)
+		} else {
+			klog.InfoS("Sandbox cache will warm start from snapshot", "snapshot", snapshotOptions.Path,
+				"sandboxes", len(snapshot.Items), "resourceVersion", snapshot.ResourceVersion)
+			informerFactory.InformerFor(&agentsv1alpha1.Sandbox{}, newWarmStartSandboxInformer(opts, snapshot))
+		}
+	}
 	sandboxInformer := informerFactory.Api().V1alpha1().Sandboxes().Informer()
 	sandboxSetInformer := informerFactory.Api().V1alpha1().SandboxSets().Informer()
 	checkpointInformer := informerFactory.Api().V1alpha1().Checkpoints().Informer()
 	sandboxTemplateInformer := informerFactory.Api().V1alpha1().SandboxTemplates().Informer()
+	sandboxClaimInformer := informerFactory.Api().V1alpha1().SandboxClaims().Informer()
 
 	// Create informer factory for native Kubernetes resources (PersistentVolume)
-	k8sInformerFactory := k8sinformers.NewSharedInformerFactory(client.K8sClient, time.Minute*10)
+	k8sInformerFactory := k8sinformers.NewSharedInformerFactoryWithOptions(client.K8sClient, time.Minute*10, k8sinformers.WithTransform(transform))
 	persistentVolumeInformer := k8sInformerFactory.Core().V1().PersistentVolumes().Informer()
 
 	// Create informer factory with specified namespace for native Kubernetes resources (Secret)
-	k8sInformerFactoryWithSystemNs := k8sinformers.NewSharedInformerFactoryWithOptions(client.K8sClient, time.Minute*10, k8sinformers.WithNamespace(opts.SystemNamespace))
+	k8sInformerFactoryWithSystemNs := k8sinformers.NewSharedInformerFactoryWithOptions(client.K8sClient, time.Minute*10, k8sinformers.WithNamespace(opts.SystemNamespace), k8sinformers.WithTransform(transform))
 	// to generate informers only for the specified namespace to avoid potential security privilege escalation risks.
 	secretInformer := k8sInformerFactoryWithSystemNs.Core().V1().Secrets().Informer()
 	configmapInformer := k8sInformerFactoryWithSystemNs.Core().V1().ConfigMaps().Informer()
@@ -92,9 +113,12 @@ func NewCache(client *clients.ClientSet, opts config.SandboxManagerOptions) (*Ca
 		sandboxSetInformer:             sandboxSetInformer,
 		checkpointInformer:             checkpointInformer,
 		sandboxTemplateInformer:        sandboxTemplateInformer,
+		sandboxClaimInformer:           sandboxClaimInformer,
 		persistentVolumeInformer:       persistentVolumeInformer,
 		stopCh:                         make(chan struct{}),
 		waitHooks:                      &sync.Map{},
+		snapshotOptions:                snapshotOptions,
+		snapshotFingerprint:            fingerprint,
 	}
 	return c, nil
 }
@@ -125,6 +149,10 @@ func (c *Cache) Run(ctx context.Context) error {
 		log.Error(err, "failed to create checkpoint waiter handler")
 		return err
 	}
+	if err := addWaiterHandler[*agentsv1alpha1.SandboxClaim](c, c.sandboxClaimInformer); err != nil {
+		log.Error(err, "failed to create sandboxclaim waiter handler")
+		return err
+	}
 	c.informerFactory.Start(c.stopCh)
 	c.k8sInformerFactory.Start(c.stopCh)
 	c.k8sInformerFactoryWithSystemNs.Start(c.stopCh)
@@ -135,6 +163,7 @@ func (c *Cache) Run(ctx context.Context) error {
 		c.sandboxInformer.HasSynced,
 		c.sandboxSetInformer.HasSynced,
 		c.sandboxTemplateInformer.HasSynced,
+		c.sandboxClaimInformer.HasSynced,
 		c.persistentVolumeInformer.HasSynced,
 		c.secretInformer.HasSynced,
 		c.configmapInformer.HasSynced,
@@ -142,15 +171,23 @@ func (c *Cache) Run(ctx context.Context) error {
 		return fmt.Errorf("timed out waiting for caches to sync")
 	}
 	log.Info("Cache informer synced")
+	c.runSnapshots(ctx)
 	return nil
 }
 
 func (c *Cache) Stop(ctx context.Context) {
 	log := klog.FromContext(ctx)
+	c.snapshot(ctx)
 	close(c.stopCh)
 	log.Info("Cache informer stopped")
 }
 
+// SandboxInformer returns the informer of sandboxes. Its handlers observe the events after the cache does, so that
+// the sandboxes listed from the cache by the handled events are up to date.
+func (c *Cache) SandboxInformer() cache.SharedIndexInformer {
+	return c.sandboxInformer
+}
+
 func (c *Cache) AddSandboxEventHandler(handler cache.ResourceEventHandlerFuncs) {
 	_, err := c.sandboxInformer.AddEventHandler(handler)
 	if err != nil {
@@ -186,6 +223,43 @@ func (c *Cache) GetClaimedSandbox(sandboxID string) (*agentsv1alpha1.Sandbox, er
 	return list[0], nil
 }
 
+// ResolveSandboxID resolves the ID of a sandbox back to the sandbox. The IDs of claimed sandboxes are resolved with
+// their index whatever the sandbox ID scheme is, and the ones of the schemes encoding the namespace and name with them.
+func (c *Cache) ResolveSandboxID(sandboxID string) (*agentsv1alpha1.Sandbox, error) {
+	sbx, err := c.GetClaimedSandbox(sandboxID)
+	if err == nil {
+		return sbx, nil
+	}
+	key, ok := sandboxid.Parse(sandboxID)
+	if !ok {
+		return nil, err
+	}
+	sbx, err = c.GetSandbox(key.Namespace, key.Name)
+	if err != nil {
+		return nil, err
+	}
+	if sandboxutils.GetSandboxID(sbx) != sandboxID {
+		return nil, fmt.Errorf("sandbox %s not found in cache", sandboxID)
+	}
+	return sbx, nil
+}
+
+// GetSandbox gets a sandbox by its namespace and name, e.g. to get its ID.
+func (c *Cache) GetSandbox(namespace, name string) (*agentsv1alpha1.Sandbox, error) {
+	key := fmt.Sprintf("%s/%s", namespace, name)
+	obj, exists, err := c.sandboxInformer.GetStore().GetByKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sandbox %s/%s from cache: %w", namespace, name, err)
+	}
+	if !exists {
+		return nil, fmt.Errorf("sandbox %s/%s not found in cache", namespace, name)
+	}
+	if sbx, ok := obj.(*agentsv1alpha1.Sandbox); ok {
+		return sbx, nil
+	}
+	return nil, fmt.Errorf("object with key %s is not a Sandbox", key)
+}
+
 // GetSandboxSet gets a SandboxSet with given name randomly
 func (c *Cache) GetSandboxSet(name string) (*agentsv1alpha1.SandboxSet, error) {
 	list, err := managerutils.SelectObjectWithIndex[*agentsv1alpha1.SandboxSet](c.sandboxSetInformer, IndexTemplateID, name)
@@ -198,6 +272,32 @@ func (c *Cache) GetSandboxSet(name string) (*agentsv1alpha1.SandboxSet, error) {
 	return list[0], nil
 }
 
+// ListSandboxSetsByName lists the SandboxSets of the name in all namespaces, which serve the same template
+func (c *Cache) ListSandboxSetsByName(name string) ([]*agentsv1alpha1.SandboxSet, error) {
+	list, err := managerutils.SelectObjectWithIndex[*agentsv1alpha1.SandboxSet](c.sandboxSetInformer, IndexTemplateID, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sandboxsets %s from cache: %w", name, err)
+	}
+	return list, nil
+}
+
+// GetSandboxSetByKey gets the SandboxSet of the namespace from the informer store without deep copying it, so the
+// returned SandboxSet must not be modified. A NotFound error is returned if it is not cached.
+func (c *Cache) GetSandboxSetByKey(namespace, name string) (*agentsv1alpha1.SandboxSet, error) {
+	key := fmt.Sprintf("%s/%s", namespace, name)
+	obj, exists, err := c.sandboxSetInformer.GetStore().GetByKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sandboxset %s from cache: %w", key, err)
+	}
+	if !exists {
+		return nil, apierrors.NewNotFound(agentsv1alpha1.Resource("sandboxsets"), name)
+	}
+	if sbs, ok := obj.(*agentsv1alpha1.SandboxSet); ok {
+		return sbs, nil
+	}
+	return nil, fmt.Errorf("object with key %s is not a SandboxSet", key)
+}
+
 // ListSandboxSets lists all SandboxSets in the given namespace from cache
 func (c *Cache) ListSandboxSets(namespace string) ([]*agentsv1alpha1.SandboxSet, error) {
 	// Get all SandboxSets from informer store
@@ -240,6 +340,7 @@ const (
 	WaitActionPause      WaitAction = "Pause"
 	WaitActionWaitReady  WaitAction = "WaitReady"
 	WaitActionCheckpoint WaitAction = "Checkpoint"
+	WaitActionClaim      WaitAction = "Claim"
 )
 
 type waitEntry[T client.Object] struct {
@@ -250,6 +351,10 @@ type waitEntry[T client.Object] struct {
 	closeOnce sync.Once
 }
 
+func (e *waitEntry[T]) waitAction() WaitAction {
+	return e.action
+}
+
 func addWaiterHandler[T client.Object](c *Cache, informer cache.SharedIndexInformer) error {
 	_, err := informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
 		UpdateFunc: func(oldObj, newObj interface{}) {
@@ -304,6 +409,20 @@ func (c *Cache) WaitForCheckpointSatisfied(ctx context.Context, checkpoint *agen
 	return c.refreshCheckpoint(checkpoint)
 }
 
+func (c *Cache) refreshSandboxClaim(claim *agentsv1alpha1.SandboxClaim) (*agentsv1alpha1.SandboxClaim, error) {
+	return c.GetSandboxClaim(claim.Namespace, claim.Name)
+}
+
+// WaitForSandboxClaimSatisfied waits for the SandboxClaim to be satisfied, and returns the latest SandboxClaim in cache
+// even if it is not satisfied before timeout.
+func (c *Cache) WaitForSandboxClaimSatisfied(ctx context.Context, claim *agentsv1alpha1.SandboxClaim, action WaitAction,
+	satisfiedFunc checkFunc[*agentsv1alpha1.SandboxClaim], timeout time.Duration) (*agentsv1alpha1.SandboxClaim, error) {
+	if err := waitForObjectSatisfied[*agentsv1alpha1.SandboxClaim](ctx, c, claim, action, c.refreshSandboxClaim, satisfiedFunc, timeout); err != nil {
+		klog.FromContext(ctx).V(consts.DebugLogLevel).Info("sandboxclaim is not satisfied", "claim", klog.KObj(claim), "reason", err)
+	}
+	return c.refreshSandboxClaim(claim)
+}
+
 func waitForObjectSatisfied[T client.Object](ctx context.Context, c *Cache, obj T, action WaitAction,
 	update updateFunc[T], satisfiedFunc checkFunc[T], timeout time.Duration) error {
 	key := client.ObjectKeyFromObject(obj)
@@ -475,6 +594,21 @@ func (c *Cache) GetSandboxTemplate(namespace, name string) (*agentsv1alpha1.Sand
 	return nil, fmt.Errorf("object with key %s is not a SandboxTemplate", key)
 }
 
+func (c *Cache) GetSandboxClaim(namespace, name string) (*agentsv1alpha1.SandboxClaim, error) {
+	key := fmt.Sprintf("%s/%s", namespace, name)
+	obj, exists, err := c.sandboxClaimInformer.GetStore().GetByKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sandboxclaim %s/%s from cache: %w", namespace, name, err)
+	}
+	if !exists {
+		return nil, fmt.Errorf("sandboxclaim %s/%s not found in cache", namespace, name)
+	}
+	if claim, ok := obj.(*agentsv1alpha1.SandboxClaim); ok {
+		return claim, nil
+	}
+	return nil, fmt.Errorf("object with key %s is not a SandboxClaim", key)
+}
+
 func (c *Cache) ListCheckpointsWithUser(user string) ([]*agentsv1alpha1.Checkpoint, error) {
 	return managerutils.SelectObjectWithIndex[*agentsv1alpha1.Checkpoint](c.checkpointInformer, IndexUser, user)
 }
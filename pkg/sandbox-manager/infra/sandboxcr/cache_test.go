@@ -21,6 +21,7 @@ import (
 	constantUtils "github.com/openkruise/agents/pkg/utils"
 	sandboxManagerUtils "github.com/openkruise/agents/pkg/utils/sandbox-manager"
 	utils "github.com/openkruise/agents/pkg/utils/sandbox-manager"
+	"github.com/openkruise/agents/pkg/utils/sandboxid"
 	"github.com/openkruise/agents/pkg/utils/sandboxutils"
 )
 
@@ -836,3 +837,45 @@ func TestCache_ListSandboxSets(t *testing.T) {
 		})
 	}
 }
+
+func TestCache_ResolveSandboxID(t *testing.T) {
+	t.Cleanup(func() { require.NoError(t, sandboxid.Options{}.Apply()) })
+	require.NoError(t, sandboxid.Options{Scheme: sandboxid.SchemeULID, Prefix: "sbx-"}.Apply())
+	cache, client, err := NewTestCache(t)
+	require.NoError(t, err)
+
+	claimed := &agentsv1alpha1.Sandbox{ObjectMeta: metav1.ObjectMeta{
+		Name:      "claimed",
+		Namespace: "default",
+		Labels:    map[string]string{agentsv1alpha1.LabelSandboxIsClaimed: agentsv1alpha1.True},
+	}}
+	sandboxid.Assign(claimed)
+	unclaimed := &agentsv1alpha1.Sandbox{ObjectMeta: metav1.ObjectMeta{Name: "unclaimed", Namespace: "default"}}
+	CreateSandboxWithStatus(t, client.SandboxClient, claimed)
+	CreateSandboxWithStatus(t, client.SandboxClient, unclaimed)
+	require.Eventually(t, func() bool {
+		_, err := cache.GetSandbox("default", "unclaimed")
+		return err == nil
+	}, time.Second, 10*time.Millisecond)
+
+	// claimed sandboxes are resolved by their index whatever the scheme is
+	id := sandboxutils.GetSandboxID(claimed)
+	assert.NotEqual(t, "default--claimed", id)
+	got, err := cache.ResolveSandboxID(id)
+	require.NoError(t, err)
+	assert.Equal(t, "claimed", got.Name)
+	got, err = cache.GetSandbox("default", "claimed")
+	require.NoError(t, err)
+	assert.Equal(t, id, sandboxutils.GetSandboxID(got))
+	_, err = cache.ResolveSandboxID("default--claimed")
+	assert.Error(t, err, "IDs of other schemes are not resolved")
+
+	// unclaimed sandboxes are resolved by the IDs encoding their namespace and name
+	got, err = cache.ResolveSandboxID("default--unclaimed")
+	require.NoError(t, err)
+	assert.Equal(t, "unclaimed", got.Name)
+	_, err = cache.ResolveSandboxID("default--missing")
+	assert.Error(t, err)
+	_, err = cache.GetSandbox("default", "missing")
+	assert.Error(t, err)
+}
@@ -7,8 +7,10 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"math/rand/v2"
 	"net/http"
+	"slices"
 	"sync"
 	"time"
 
@@ -16,6 +18,7 @@ import (
 	"golang.org/x/time/rate"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/util/retry"
 	"k8s.io/klog/v2"
@@ -33,6 +36,7 @@ import (
 	"github.com/openkruise/agents/pkg/utils/expectations"
 	utils "github.com/openkruise/agents/pkg/utils/sandbox-manager"
 	"github.com/openkruise/agents/pkg/utils/sandbox-manager/proxyutils"
+	"github.com/openkruise/agents/pkg/utils/sandboxid"
 	stateutils "github.com/openkruise/agents/pkg/utils/sandboxutils"
 )
 
@@ -111,6 +115,7 @@ func TryClaimSandbox(ctx context.Context, opts infra.ClaimSandboxOptions, pickCa
 	var sbx *Sandbox
 	var lockType infra.LockType
 	pickStart := time.Now()
+	setClaimStage(ctx, infra.ClaimStagePicking)
 	sbx, lockType, err = pickAnAvailableSandbox(ctx, opts, pickCache, cache, client, createLimiter)
 	if err != nil {
 		log.Error(err, "failed to select available sandbox")
@@ -133,6 +138,10 @@ func TryClaimSandbox(ctx context.Context, opts infra.ClaimSandboxOptions, pickCa
 		return
 	}
 
+	updateClaimProgress(ctx, func(progress *infra.ClaimProgress) {
+		progress.Stage = infra.ClaimStageLocking
+		progress.Sandbox = getPickKey(sbx.Sandbox)
+	})
 	err = performLockSandbox(ctx, sbx, lockType, opts, client, cache)
 	if err != nil {
 		// TODO: these lines cannot be covered by tests currently, which will be fixed when the cache is converted to controller-runtime
@@ -142,7 +151,7 @@ func TryClaimSandbox(ctx context.Context, opts infra.ClaimSandboxOptions, pickCa
 				UID:             sbx.GetUID(),
 				ResourceVersion: expectations.GetNewerResourceVersion(sbx),
 			})
-			err = retriableError{Message: fmt.Sprintf("failed to lock sandbox: %s", err)}
+			err = retriableError{Message: fmt.Sprintf("failed to lock sandbox: %s", err), Conflict: true}
 		}
 		return
 	}
@@ -158,6 +167,7 @@ func TryClaimSandbox(ctx context.Context, opts infra.ClaimSandboxOptions, pickCa
 	// Step 3: Built-in post processes. The locked sandbox must be always returned to be cleared properly.
 	if lockType == infra.LockTypeCreate || lockType == infra.LockTypeSpeculate || opts.InplaceUpdate != nil {
 		log.Info("should wait for sandbox ready", "inplaceUpdate", opts.InplaceUpdate != nil)
+		setClaimStage(ctx, infra.ClaimStageWaitingReady)
 		metrics.WaitReady, err = waitForSandboxReady(ctx, sbx, opts, cache)
 		metrics.Total += metrics.WaitReady
 		if err != nil {
@@ -170,6 +180,7 @@ func TryClaimSandbox(ctx context.Context, opts infra.ClaimSandboxOptions, pickCa
 
 	if opts.InitRuntime != nil {
 		log.Info("starting to init runtime", "opts", opts.InitRuntime)
+		setClaimStage(ctx, infra.ClaimStageInitRuntime)
 		metrics.InitRuntime, err = initRuntime(ctx, sbx, *opts.InitRuntime)
 		if err != nil {
 			log.Error(err, "failed to init runtime")
@@ -182,6 +193,7 @@ func TryClaimSandbox(ctx context.Context, opts infra.ClaimSandboxOptions, pickCa
 
 	if opts.CSIMount != nil {
 		log.Info("starting to perform csi mount")
+		setClaimStage(ctx, infra.ClaimStageCSIMount)
 		metrics.CSIMount, err = processCSIMounts(ctx, sbx, *opts.CSIMount)
 		if err != nil {
 			log.Error(err, "failed to perform csi mount")
@@ -266,12 +278,17 @@ func pickAnAvailableSandbox(ctx context.Context, opts infra.ClaimSandboxOptions,
 		return nil, "", NoAvailableError(template, "no stock")
 	}
 
-	// Select available candidates and speculated creating sandboxes
+	// Select available candidates and speculated creating sandboxes. With preferred regions, all candidates are
+	// collected to be picked region by region, at most cnt ones of each region.
+	collectLimit := cnt
+	if opts.Regions != nil && len(opts.Regions.Preferred) > 0 {
+		collectLimit = len(objects)
+	}
 	availableCandidates := make([]*v1alpha1.Sandbox, 0, cnt)
 	speculatingCandidates := make([]*v1alpha1.Sandbox, 0, cnt)
 	for _, obj := range objects {
-		if len(availableCandidates) >= cnt {
-			if opts.SpeculateCreatingDuration == 0 || len(speculatingCandidates) >= cnt {
+		if len(availableCandidates) >= collectLimit {
+			if opts.SpeculateCreatingDuration == 0 || len(speculatingCandidates) >= collectLimit {
 				break
 			}
 		}
@@ -279,6 +296,9 @@ func pickAnAvailableSandbox(ctx context.Context, opts infra.ClaimSandboxOptions,
 			log.Info("skip out-dated sandbox cache", "sandbox", klog.KObj(obj))
 			continue
 		}
+		if !matchesClaimOptions(obj, opts) {
+			continue
+		}
 		if checkErr := preCheckCandidate(obj); checkErr != nil {
 			log.Error(checkErr, "skip invalid sandbox", "sandbox", klog.KObj(obj), "resourceVersion", obj.GetResourceVersion())
 			continue
@@ -286,7 +306,7 @@ func pickAnAvailableSandbox(ctx context.Context, opts infra.ClaimSandboxOptions,
 		state, _ := stateutils.GetSandboxState(obj)
 		switch state {
 		case v1alpha1.SandboxStateAvailable:
-			if len(availableCandidates) >= cnt {
+			if len(availableCandidates) >= collectLimit {
 				continue
 			}
 			if obj.Status.PodInfo.PodIP == "" {
@@ -295,7 +315,7 @@ func pickAnAvailableSandbox(ctx context.Context, opts infra.ClaimSandboxOptions,
 			}
 			availableCandidates = append(availableCandidates, obj)
 		case v1alpha1.SandboxStateCreating:
-			if opts.SpeculateCreatingDuration == 0 || len(speculatingCandidates) >= cnt {
+			if opts.SpeculateCreatingDuration == 0 || len(speculatingCandidates) >= collectLimit {
 				continue
 			}
 			creationDuration := time.Since(obj.CreationTimestamp.Time)
@@ -308,7 +328,7 @@ func pickAnAvailableSandbox(ctx context.Context, opts infra.ClaimSandboxOptions,
 
 	// Step 1: select from available candidate
 	log.Info("picking from available candidates")
-	sbx, pickErr := pickFromCandidates(ctx, availableCandidates, pickCache)
+	sbx, pickErr := pickFromCandidatesByRegion(ctx, availableCandidates, opts.Regions, cnt, pickCache)
 	if pickErr == nil {
 		return AsSandbox(sbx, cache, client), infra.LockTypeUpdate, nil
 	}
@@ -317,7 +337,7 @@ func pickAnAvailableSandbox(ctx context.Context, opts infra.ClaimSandboxOptions,
 	// Step 2: select from speculated candidates
 	if opts.SpeculateCreatingDuration > 0 {
 		log.Info("picking from speculated candidates")
-		sbx, pickErr = pickFromCandidates(ctx, speculatingCandidates, pickCache)
+		sbx, pickErr = pickFromCandidatesByRegion(ctx, speculatingCandidates, opts.Regions, cnt, pickCache)
 		if pickErr == nil {
 			log.Info("will speculate creating sandbox", "sandbox", klog.KObj(sbx))
 			return AsSandbox(sbx, cache, client), infra.LockTypeSpeculate, nil
@@ -332,6 +352,61 @@ func pickAnAvailableSandbox(ctx context.Context, opts infra.ClaimSandboxOptions,
 	return nil, "", NoAvailableError(template, pickErr.Error())
 }
 
+// matchesClaimOptions tells if the sandbox of the pool may be picked with the revision, regions and selector of the
+// options, regardless of its state
+func matchesClaimOptions(obj *v1alpha1.Sandbox, opts infra.ClaimSandboxOptions) bool {
+	if opts.Revision != "" && obj.Labels[v1alpha1.LabelTemplateHash] != opts.Revision {
+		return false
+	}
+	if _, allowed := infra.RegionRank(opts.Regions, obj.Labels[v1alpha1.LabelSandboxRegion]); !allowed {
+		return false
+	}
+	return opts.Selector == nil || opts.Selector.Matches(labels.Set(obj.Labels))
+}
+
+// CountAvailableSandboxes counts the available sandboxes of the pool of the options that TryClaimSandbox may pick
+// right now, without locking any of them. The sandboxes created on no stock or speculated are not counted.
+func CountAvailableSandboxes(cache *Cache, opts infra.ClaimSandboxOptions) (int32, error) {
+	objects, err := cache.ListSandboxesInPool(opts.Template)
+	if err != nil {
+		return 0, err
+	}
+	var count int32
+	for _, obj := range objects {
+		if !utils.ResourceVersionExpectationSatisfied(obj) || !matchesClaimOptions(obj, opts) || preCheckCandidate(obj) != nil {
+			continue
+		}
+		if state, _ := stateutils.GetSandboxState(obj); state == v1alpha1.SandboxStateAvailable && obj.Status.PodInfo.PodIP != "" {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// pickFromCandidatesByRegion picks from the candidates of the most preferred region first, trying at most cnt
+// candidates of each region rank. All candidates must be allowed by the policy.
+func pickFromCandidatesByRegion(ctx context.Context, candidates []*v1alpha1.Sandbox, policy *v1alpha1.RegionPolicy, cnt int,
+	pickCache *sync.Map) (*v1alpha1.Sandbox, error) {
+	if policy == nil || len(policy.Preferred) == 0 {
+		return pickFromCandidates(ctx, candidates, pickCache)
+	}
+	byRank := make(map[int][]*v1alpha1.Sandbox)
+	for _, obj := range candidates {
+		rank, _ := infra.RegionRank(policy, obj.Labels[v1alpha1.LabelSandboxRegion])
+		if len(byRank[rank]) < cnt {
+			byRank[rank] = append(byRank[rank], obj)
+		}
+	}
+	pickErr := errors.New("no candidate")
+	for _, rank := range slices.Sorted(maps.Keys(byRank)) {
+		var sbx *v1alpha1.Sandbox
+		if sbx, pickErr = pickFromCandidates(ctx, byRank[rank], pickCache); pickErr == nil {
+			return sbx, nil
+		}
+	}
+	return nil, pickErr
+}
+
 func pickFromCandidates(ctx context.Context, candidates []*v1alpha1.Sandbox, pickCache *sync.Map) (*v1alpha1.Sandbox, error) {
 	log := klog.FromContext(ctx).V(consts.DebugLogLevel)
 	// Step 1: select from candidate
@@ -381,11 +456,24 @@ func newSandboxFromSandboxSet(opts infra.ClaimSandboxOptions, cache *Cache, clie
 			return nil, "", NoAvailableError(opts.Template, "sandbox creation is not allowed by rate limiter")
 		}
 	}
-	sbs, err := cache.GetSandboxSet(opts.Template)
+	sbs, err := selectSandboxSet(cache, opts)
 	if err != nil {
 		return nil, "", NoAvailableError(opts.Template, "cannot create new sandbox: "+err.Error())
 	}
+	if sbs.Spec.Paused {
+		return nil, "", NoAvailableError(opts.Template, "cannot create new sandbox: the pool is paused")
+	}
+	if opts.Revision != "" && opts.Revision != sbs.Status.UpdateRevision {
+		return nil, "", NoAvailableError(opts.Template, fmt.Sprintf("cannot create sandbox of revision %s, the pool is at revision %s",
+			opts.Revision, sbs.Status.UpdateRevision))
+	}
 	sbx := sandboxset.NewSandboxFromSandboxSet(sbs)
+	if sbs.Status.UpdateRevision != "" {
+		sbx.Labels[v1alpha1.LabelTemplateHash] = sbs.Status.UpdateRevision
+	}
+	if opts.Selector != nil && !opts.Selector.Matches(labels.Set(sbx.Labels)) {
+		return nil, "", NoAvailableError(opts.Template, "cannot create new sandbox: the sandboxes of the pool do not match the selector")
+	}
 	// sandbox manager creates high-priority sandbox
 	sbx.Annotations[v1alpha1.SandboxAnnotationPriority] = "100"
 	for _, anno := range FilteredAnnotationsOnCreation {
@@ -394,6 +482,30 @@ func newSandboxFromSandboxSet(opts infra.ClaimSandboxOptions, cache *Cache, clie
 	return AsSandbox(sbx, cache, client), infra.LockTypeCreate, nil
 }
 
+// selectSandboxSet selects the SandboxSet of the template to create the sandbox from, of the most preferred region
+// allowed by the region policy of the options.
+func selectSandboxSet(cache *Cache, opts infra.ClaimSandboxOptions) (*v1alpha1.SandboxSet, error) {
+	if opts.Regions == nil {
+		return cache.GetSandboxSet(opts.Template)
+	}
+	sandboxSets, err := cache.ListSandboxSetsByName(opts.Template)
+	if err != nil {
+		return nil, err
+	}
+	var selected *v1alpha1.SandboxSet
+	selectedRank := 0
+	for _, sbs := range sandboxSets {
+		rank, allowed := infra.RegionRank(opts.Regions, sbs.Labels[v1alpha1.LabelSandboxRegion])
+		if allowed && (selected == nil || rank < selectedRank) {
+			selected, selectedRank = sbs, rank
+		}
+	}
+	if selected == nil {
+		return nil, fmt.Errorf("no sandboxset %s in the allowed regions", opts.Template)
+	}
+	return selected, nil
+}
+
 func preCheckCandidate(sbx *v1alpha1.Sandbox) error {
 	lock := sbx.Annotations[v1alpha1.AnnotationLock]
 	if lock != "" {
@@ -402,6 +514,9 @@ func preCheckCandidate(sbx *v1alpha1.Sandbox) error {
 	if sbx.CreationTimestamp.IsZero() {
 		return errors.New("creation timestamp is zero")
 	}
+	if sbx.Spec.Quarantine != nil {
+		return errors.New("sandbox is quarantined")
+	}
 	return nil
 }
 
@@ -452,6 +567,8 @@ func modifyPickedSandbox(sbx *Sandbox, lockType infra.LockType, opts infra.Claim
 	}
 
 	sbx.SetAnnotations(annotations)
+	// IDs not derived from the sandbox are assigned along with claiming it, so that it is indexed by the ID once claimed
+	sandboxid.Assign(sbx.Sandbox)
 	return nil
 }
 
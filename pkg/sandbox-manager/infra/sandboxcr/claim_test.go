@@ -15,6 +15,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/rand"
 
@@ -816,6 +817,440 @@ func TestNewSandboxFromTemplate_RateLimitExceeded(t *testing.T) {
 	assert.Contains(t, err.Error(), template, "error should contain template name")
 }
 
+func TestPickAnAvailableSandbox_Revision(t *testing.T) {
+	utils.InitLogOutput()
+	template := "test-template"
+	tests := []struct {
+		name            string
+		revision        string
+		createOnNoStock bool
+		paused          bool
+		expectSandbox   string
+		expectError     string
+	}{
+		{
+			name:          "pinned to an old revision",
+			revision:      "rev1",
+			expectSandbox: "sbx-rev1",
+		},
+		{
+			name:          "pinned to the current revision",
+			revision:      "rev2",
+			expectSandbox: "sbx-rev2",
+		},
+		{
+			name:        "no sandbox of the revision",
+			revision:    "rev3",
+			expectError: "no candidate",
+		},
+		{
+			name:            "create sandbox of the current revision",
+			revision:        "rev2",
+			createOnNoStock: true,
+		},
+		{
+			name:            "cannot create sandbox of an old revision",
+			revision:        "rev3",
+			createOnNoStock: true,
+			expectError:     "cannot create sandbox of revision rev3, the pool is at revision rev2",
+		},
+		{
+			name:            "cannot create sandbox in a paused pool",
+			revision:        "rev2",
+			createOnNoStock: true,
+			paused:          true,
+			expectError:     "the pool is paused",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testInfra, client := NewTestInfra(t)
+			defer testInfra.Stop(t.Context())
+			sbs := &v1alpha1.SandboxSet{
+				ObjectMeta: metav1.ObjectMeta{Name: template, Namespace: "default"},
+				Spec: v1alpha1.SandboxSetSpec{
+					EmbeddedSandboxTemplate: v1alpha1.EmbeddedSandboxTemplate{
+						Template: &corev1.PodTemplateSpec{},
+					},
+					Paused: tt.paused,
+				},
+				Status: v1alpha1.SandboxSetStatus{UpdateRevision: "rev2"},
+			}
+			_, err := client.ApiV1alpha1().SandboxSets("default").Create(t.Context(), sbs, metav1.CreateOptions{})
+			require.NoError(t, err)
+			for _, revision := range []string{"rev1", "rev2"} {
+				CreateSandboxWithStatus(t, client.SandboxClient, &v1alpha1.Sandbox{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "sbx-" + revision,
+						Namespace: "default",
+						Labels: map[string]string{
+							v1alpha1.LabelSandboxTemplate: template,
+							v1alpha1.LabelTemplateHash:    revision,
+						},
+						Annotations:       map[string]string{},
+						OwnerReferences:   GetSbsOwnerReference(),
+						CreationTimestamp: metav1.Now(),
+					},
+					Status: v1alpha1.SandboxStatus{
+						Phase: v1alpha1.SandboxRunning,
+						Conditions: []metav1.Condition{
+							{Type: string(v1alpha1.SandboxConditionReady), Status: metav1.ConditionTrue},
+						},
+						PodInfo: v1alpha1.PodInfo{PodIP: "1.2.3.4"},
+					},
+				})
+			}
+			require.Eventually(t, func() bool {
+				objects, err := testInfra.Cache.ListSandboxesInPool(template)
+				_, sbsErr := testInfra.Cache.GetSandboxSet(template)
+				return err == nil && len(objects) == 2 && sbsErr == nil
+			}, time.Second, 10*time.Millisecond)
+			if tt.createOnNoStock {
+				// only the sandbox of the old revision is in stock
+				require.NoError(t, client.ApiV1alpha1().Sandboxes("default").Delete(t.Context(), "sbx-rev2", metav1.DeleteOptions{}))
+				require.Eventually(t, func() bool {
+					objects, err := testInfra.Cache.ListSandboxesInPool(template)
+					return err == nil && len(objects) == 1
+				}, time.Second, 10*time.Millisecond)
+			}
+
+			opts, err := ValidateAndInitClaimOptions(infra.ClaimSandboxOptions{
+				User:            "test-user",
+				Template:        template,
+				Revision:        tt.revision,
+				CreateOnNoStock: tt.createOnNoStock,
+			})
+			require.NoError(t, err)
+			sbx, lockType, err := pickAnAvailableSandbox(t.Context(), opts, &testInfra.pickCache, testInfra.Cache, client, nil)
+			if tt.expectError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.revision, sbx.Labels[v1alpha1.LabelTemplateHash])
+			if tt.createOnNoStock {
+				assert.Equal(t, infra.LockTypeCreate, lockType)
+			} else {
+				assert.Equal(t, tt.expectSandbox, sbx.Name)
+			}
+		})
+	}
+}
+
+func TestPickAnAvailableSandbox_Regions(t *testing.T) {
+	utils.InitLogOutput()
+	template := "test-template"
+	tests := []struct {
+		name            string
+		stock           map[string]string // sandbox name -> region
+		regions         *v1alpha1.RegionPolicy
+		createOnNoStock bool
+		expectSandbox   string
+		expectCreateIn  string
+		expectError     string
+	}{
+		{
+			name:          "preferred region",
+			stock:         map[string]string{"sbx-us": "us-east", "sbx-eu": "eu-west"},
+			regions:       &v1alpha1.RegionPolicy{Preferred: []string{"eu-west", "us-east"}},
+			expectSandbox: "sbx-eu",
+		},
+		{
+			name:          "next preferred region when the first is exhausted",
+			stock:         map[string]string{"sbx-us": "us-east", "sbx-none": ""},
+			regions:       &v1alpha1.RegionPolicy{Preferred: []string{"eu-west", "us-east"}},
+			expectSandbox: "sbx-us",
+		},
+		{
+			name:  "forbidden region is never claimed even if preferred",
+			stock: map[string]string{"sbx-us": "us-east", "sbx-eu": "eu-west"},
+			regions: &v1alpha1.RegionPolicy{
+				Preferred: []string{"us-east"},
+				Forbidden: []string{"us-east"},
+			},
+			expectSandbox: "sbx-eu",
+		},
+		{
+			name:          "fallback to other regions",
+			stock:         map[string]string{"sbx-us": "us-east"},
+			regions:       &v1alpha1.RegionPolicy{Preferred: []string{"eu-west"}, Fallback: v1alpha1.RegionFallbackAny},
+			expectSandbox: "sbx-us",
+		},
+		{
+			name:        "no fallback",
+			stock:       map[string]string{"sbx-us": "us-east"},
+			regions:     &v1alpha1.RegionPolicy{Preferred: []string{"eu-west"}, Fallback: v1alpha1.RegionFallbackNone},
+			expectError: "no candidate",
+		},
+		{
+			name:            "create in the preferred region",
+			stock:           map[string]string{"sbx-us": "us-east"},
+			regions:         &v1alpha1.RegionPolicy{Preferred: []string{"eu-west"}, Fallback: v1alpha1.RegionFallbackNone},
+			createOnNoStock: true,
+			expectCreateIn:  "eu-west",
+		},
+		{
+			name:            "cannot create without a sandboxset in the allowed regions",
+			regions:         &v1alpha1.RegionPolicy{Preferred: []string{"ap-south"}, Fallback: v1alpha1.RegionFallbackNone},
+			createOnNoStock: true,
+			expectError:     "no sandboxset test-template in the allowed regions",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testInfra, client := NewTestInfra(t)
+			defer testInfra.Stop(t.Context())
+			// the pool of the template is served by a SandboxSet in the namespace of each region
+			for _, region := range []string{"us-east", "eu-west"} {
+				sbs := &v1alpha1.SandboxSet{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      template,
+						Namespace: region,
+						Labels:    map[string]string{v1alpha1.LabelSandboxRegion: region},
+					},
+					Spec: v1alpha1.SandboxSetSpec{
+						EmbeddedSandboxTemplate: v1alpha1.EmbeddedSandboxTemplate{
+							Template: &corev1.PodTemplateSpec{},
+						},
+					},
+				}
+				_, err := client.ApiV1alpha1().SandboxSets(region).Create(t.Context(), sbs, metav1.CreateOptions{})
+				require.NoError(t, err)
+			}
+			for name, region := range tt.stock {
+				labels := map[string]string{v1alpha1.LabelSandboxTemplate: template}
+				if region != "" {
+					labels[v1alpha1.LabelSandboxRegion] = region
+				}
+				CreateSandboxWithStatus(t, client.SandboxClient, &v1alpha1.Sandbox{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:              name,
+						Namespace:         "default",
+						Labels:            labels,
+						Annotations:       map[string]string{},
+						OwnerReferences:   GetSbsOwnerReference(),
+						CreationTimestamp: metav1.Now(),
+					},
+					Status: v1alpha1.SandboxStatus{
+						Phase: v1alpha1.SandboxRunning,
+						Conditions: []metav1.Condition{
+							{Type: string(v1alpha1.SandboxConditionReady), Status: metav1.ConditionTrue},
+						},
+						PodInfo: v1alpha1.PodInfo{PodIP: "1.2.3.4"},
+					},
+				})
+			}
+			require.Eventually(t, func() bool {
+				objects, err := testInfra.Cache.ListSandboxesInPool(template)
+				sandboxSets, sbsErr := testInfra.Cache.ListSandboxSetsByName(template)
+				return err == nil && len(objects) == len(tt.stock) && sbsErr == nil && len(sandboxSets) == 2
+			}, time.Second, 10*time.Millisecond)
+
+			opts, err := ValidateAndInitClaimOptions(infra.ClaimSandboxOptions{
+				User:            "test-user",
+				Template:        template,
+				Regions:         tt.regions,
+				CreateOnNoStock: tt.createOnNoStock,
+			})
+			require.NoError(t, err)
+			sbx, lockType, err := pickAnAvailableSandbox(t.Context(), opts, &testInfra.pickCache, testInfra.Cache, client, nil)
+			if tt.expectError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectError)
+				return
+			}
+			require.NoError(t, err)
+			if tt.expectCreateIn != "" {
+				assert.Equal(t, infra.LockTypeCreate, lockType)
+				assert.Equal(t, tt.expectCreateIn, sbx.Namespace)
+				assert.Equal(t, tt.expectCreateIn, sbx.Labels[v1alpha1.LabelSandboxRegion])
+			} else {
+				assert.Equal(t, tt.expectSandbox, sbx.Name)
+			}
+		})
+	}
+}
+
+func TestPickAnAvailableSandbox_Selector(t *testing.T) {
+	utils.InitLogOutput()
+	template := "test-template"
+	tests := []struct {
+		name            string
+		selector        labels.Selector
+		createOnNoStock bool
+		expectSandboxes []string
+		expectError     string
+	}{
+		{
+			name:            "no selector",
+			expectSandboxes: []string{"sbx-gpu", "sbx-cpu"},
+		},
+		{
+			name:            "select by label",
+			selector:        labels.SelectorFromSet(labels.Set{"gpu": "true"}),
+			expectSandboxes: []string{"sbx-gpu"},
+		},
+		{
+			name:            "select by labels",
+			selector:        labels.SelectorFromSet(labels.Set{"zone": "us-east-1b"}),
+			expectSandboxes: []string{"sbx-cpu"},
+		},
+		{
+			name:        "no sandbox matches",
+			selector:    labels.SelectorFromSet(labels.Set{"gpu": "true", "zone": "us-east-1b"}),
+			expectError: "no candidate",
+		},
+		{
+			name:            "create sandbox matching the selector",
+			selector:        labels.SelectorFromSet(labels.Set{"zone": "us-east-1a"}),
+			createOnNoStock: true,
+		},
+		{
+			name:            "cannot create sandbox not matching the selector",
+			selector:        labels.SelectorFromSet(labels.Set{"gpu": "false"}),
+			createOnNoStock: true,
+			expectError:     "do not match the selector",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testInfra, client := NewTestInfra(t)
+			defer testInfra.Stop(t.Context())
+			sbs := &v1alpha1.SandboxSet{
+				ObjectMeta: metav1.ObjectMeta{Name: template, Namespace: "default"},
+				Spec: v1alpha1.SandboxSetSpec{
+					EmbeddedSandboxTemplate: v1alpha1.EmbeddedSandboxTemplate{
+						Template: &corev1.PodTemplateSpec{
+							ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"zone": "us-east-1a"}},
+						},
+					},
+				},
+			}
+			_, err := client.ApiV1alpha1().SandboxSets("default").Create(t.Context(), sbs, metav1.CreateOptions{})
+			require.NoError(t, err)
+			if !tt.createOnNoStock {
+				for name, extra := range map[string]map[string]string{
+					"sbx-gpu": {"gpu": "true", "zone": "us-east-1a"},
+					"sbx-cpu": {"zone": "us-east-1b"},
+				} {
+					sbxLabels := map[string]string{v1alpha1.LabelSandboxTemplate: template}
+					for k, v := range extra {
+						sbxLabels[k] = v
+					}
+					CreateSandboxWithStatus(t, client.SandboxClient, &v1alpha1.Sandbox{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:              name,
+							Namespace:         "default",
+							Labels:            sbxLabels,
+							Annotations:       map[string]string{},
+							OwnerReferences:   GetSbsOwnerReference(),
+							CreationTimestamp: metav1.Now(),
+						},
+						Status: v1alpha1.SandboxStatus{
+							Phase: v1alpha1.SandboxRunning,
+							Conditions: []metav1.Condition{
+								{Type: string(v1alpha1.SandboxConditionReady), Status: metav1.ConditionTrue},
+							},
+							PodInfo: v1alpha1.PodInfo{PodIP: "1.2.3.4"},
+						},
+					})
+				}
+			}
+			require.Eventually(t, func() bool {
+				objects, err := testInfra.Cache.ListSandboxesInPool(template)
+				_, sbsErr := testInfra.Cache.GetSandboxSet(template)
+				return err == nil && (tt.createOnNoStock || len(objects) == 2) && sbsErr == nil
+			}, time.Second, 10*time.Millisecond)
+
+			opts, err := ValidateAndInitClaimOptions(infra.ClaimSandboxOptions{
+				User:            "test-user",
+				Template:        template,
+				Selector:        tt.selector,
+				CreateOnNoStock: tt.createOnNoStock,
+			})
+			require.NoError(t, err)
+			sbx, lockType, err := pickAnAvailableSandbox(t.Context(), opts, &testInfra.pickCache, testInfra.Cache, client, nil)
+			if tt.expectError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectError)
+				return
+			}
+			require.NoError(t, err)
+			if tt.createOnNoStock {
+				assert.Equal(t, infra.LockTypeCreate, lockType)
+				assert.Equal(t, "us-east-1a", sbx.Labels["zone"])
+			} else {
+				assert.Contains(t, tt.expectSandboxes, sbx.Name)
+			}
+		})
+	}
+}
+
+func TestCountAvailableSandboxes(t *testing.T) {
+	utils.InitLogOutput()
+	template := "test-template"
+	testInfra, client := NewTestInfra(t)
+	defer testInfra.Stop(t.Context())
+	for name, tweak := range map[string]func(sbx *v1alpha1.Sandbox){
+		"sbx-gpu":    func(sbx *v1alpha1.Sandbox) { sbx.Labels["gpu"] = "true" },
+		"sbx-cpu":    func(sbx *v1alpha1.Sandbox) {},
+		"sbx-locked": func(sbx *v1alpha1.Sandbox) { sbx.Annotations[v1alpha1.AnnotationLock] = "other" },
+		"sbx-no-ip":  func(sbx *v1alpha1.Sandbox) { sbx.Status.PodInfo.PodIP = "" },
+		"sbx-creating": func(sbx *v1alpha1.Sandbox) {
+			sbx.Status.Phase = v1alpha1.SandboxPending
+			sbx.Status.Conditions = nil
+		},
+	} {
+		sbx := &v1alpha1.Sandbox{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         "default",
+				Labels:            map[string]string{v1alpha1.LabelSandboxTemplate: template},
+				Annotations:       map[string]string{},
+				OwnerReferences:   GetSbsOwnerReference(),
+				CreationTimestamp: metav1.Now(),
+			},
+			Status: v1alpha1.SandboxStatus{
+				Phase: v1alpha1.SandboxRunning,
+				Conditions: []metav1.Condition{
+					{Type: string(v1alpha1.SandboxConditionReady), Status: metav1.ConditionTrue},
+				},
+				PodInfo: v1alpha1.PodInfo{PodIP: "1.2.3.4"},
+			},
+		}
+		tweak(sbx)
+		CreateSandboxWithStatus(t, client.SandboxClient, sbx)
+	}
+	require.Eventually(t, func() bool {
+		objects, err := testInfra.Cache.ListSandboxesInPool(template)
+		return err == nil && len(objects) == 5
+	}, time.Second, 10*time.Millisecond)
+
+	tests := []struct {
+		name     string
+		selector labels.Selector
+		expect   int32
+	}{
+		{
+			name:   "all available",
+			expect: 2,
+		},
+		{
+			name:     "available matching the selector",
+			selector: labels.SelectorFromSet(labels.Set{"gpu": "true"}),
+			expect:   1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			count, err := CountAvailableSandboxes(testInfra.Cache, infra.ClaimSandboxOptions{Template: template, Selector: tt.selector})
+			require.NoError(t, err)
+			assert.Equal(t, tt.expect, count)
+		})
+	}
+}
+
 func TestModifyPickedSandbox_CSIMount(t *testing.T) {
 	tests := []struct {
 		name             string
@@ -1029,3 +1464,12 @@ func TestModifyPickedSandbox_InitRuntime(t *testing.T) {
 		})
 	}
 }
+
+func TestIsLockConflict(t *testing.T) {
+	conflict := retriableError{Message: "failed to lock sandbox: conflict", Conflict: true}
+	assert.True(t, IsLockConflict(conflict))
+	assert.True(t, IsLockConflict(fmt.Errorf("wrapped: %w", conflict)))
+	assert.False(t, IsLockConflict(NoAvailableError("tpl", "no stock")))
+	assert.False(t, IsLockConflict(errors.New("failed")))
+	assert.False(t, IsLockConflict(nil))
+}
@@ -0,0 +1,144 @@
+package sandboxcr
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/openkruise/agents/api/v1alpha1"
+	sandboxfake "github.com/openkruise/agents/client/clientset/versioned/fake"
+	"github.com/openkruise/agents/pkg/sandbox-manager/clients"
+	"github.com/openkruise/agents/pkg/sandbox-manager/infra"
+	"github.com/openkruise/agents/pkg/sandbox-manager/infra/infratest"
+	utils "github.com/openkruise/agents/pkg/utils/sandbox-manager"
+)
+
+type conformanceEnvironment struct {
+	infra  *Infra
+	client *clients.ClientSet
+	count  int
+}
+
+func (e *conformanceEnvironment) Infra() infra.Infrastructure {
+	return e.infra
+}
+
+func (e *conformanceEnvironment) AddAvailableSandbox(t *testing.T, template string, runtime infratest.Runtime) {
+	e.count++
+	sbx := &v1alpha1.Sandbox{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("conformance-%d", e.count),
+			Namespace: "default",
+			Labels: map[string]string{
+				v1alpha1.LabelSandboxTemplate:  template,
+				v1alpha1.LabelSandboxIsClaimed: "false",
+			},
+			Annotations: map[string]string{
+				v1alpha1.AnnotationRuntimeURL:         runtime.URL,
+				v1alpha1.AnnotationRuntimeAccessToken: runtime.AccessToken,
+			},
+			CreationTimestamp: metav1.Now(),
+			OwnerReferences:   GetSbsOwnerReference(),
+		},
+		Spec: v1alpha1.SandboxSpec{
+			EmbeddedSandboxTemplate: v1alpha1.EmbeddedSandboxTemplate{
+				Template: &corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "main", Image: "main"}}},
+				},
+			},
+		},
+		Status: v1alpha1.SandboxStatus{
+			Phase: v1alpha1.SandboxRunning,
+			Conditions: []metav1.Condition{{
+				Type:   string(v1alpha1.SandboxConditionReady),
+				Status: metav1.ConditionTrue,
+			}},
+			PodInfo: v1alpha1.PodInfo{PodIP: "1.2.3.4"},
+		},
+	}
+	CreateSandboxWithStatus(t, e.client.SandboxClient, sbx)
+	require.Eventually(t, func() bool {
+		_, ok, err := e.infra.Cache.sandboxInformer.GetStore().GetByKey(fmt.Sprintf("%s/%s", sbx.Namespace, sbx.Name))
+		return err == nil && ok
+	}, time.Second, 5*time.Millisecond)
+}
+
+// simulateSandboxController pauses and resumes the sandboxes as the sandbox controller does.
+func simulateSandboxController(ctx context.Context, client *clients.ClientSet) {
+	wait.Until(func() {
+		sandboxes, err := client.ApiV1alpha1().Sandboxes("default").List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return
+		}
+		for i := range sandboxes.Items {
+			sbx := &sandboxes.Items[i]
+			switch {
+			case sbx.Spec.Paused && sbx.Status.Phase == v1alpha1.SandboxRunning:
+				sbx.Status.Phase = v1alpha1.SandboxPaused
+				SetSandboxCondition(sbx, string(v1alpha1.SandboxConditionReady), metav1.ConditionFalse, "Paused", "")
+				SetSandboxCondition(sbx, string(v1alpha1.SandboxConditionPaused), metav1.ConditionTrue, "Paused", "")
+			case !sbx.Spec.Paused && sbx.Status.Phase == v1alpha1.SandboxPaused:
+				sbx.Status.Phase = v1alpha1.SandboxRunning
+				SetSandboxCondition(sbx, string(v1alpha1.SandboxConditionReady), metav1.ConditionTrue, "Resumed", "")
+				SetSandboxCondition(sbx, string(v1alpha1.SandboxConditionPaused), metav1.ConditionFalse, "Resumed", "")
+			default:
+				continue
+			}
+			_, _ = client.ApiV1alpha1().Sandboxes(sbx.Namespace).UpdateStatus(ctx, sbx, metav1.UpdateOptions{})
+		}
+	}, 10*time.Millisecond, ctx.Done())
+}
+
+// enforceOptimisticLock rejects the updates of stale sandboxes with conflicts like the API server, which the fake
+// client does not. Like the API server, the spec is kept on status updates and the status on spec updates.
+func enforceOptimisticLock(t *testing.T, client *clients.ClientSet) {
+	fakeClient, ok := client.SandboxClient.(*sandboxfake.Clientset)
+	require.True(t, ok, "SandboxClient must be *sandboxfake.Clientset")
+	fakeClient.PrependReactor("update", "sandboxes", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		update, ok := action.(k8stesting.UpdateAction)
+		if !ok {
+			return false, nil, nil
+		}
+		sbx := update.GetObject().(*v1alpha1.Sandbox).DeepCopy()
+		gvr := v1alpha1.SchemeGroupVersion.WithResource("sandboxes")
+		obj, err := fakeClient.Tracker().Get(gvr, sbx.Namespace, sbx.Name)
+		if err != nil {
+			return true, nil, err
+		}
+		current := obj.(*v1alpha1.Sandbox)
+		if sbx.ResourceVersion != "" && sbx.ResourceVersion != current.ResourceVersion {
+			return true, nil, apierrors.NewConflict(gvr.GroupResource(), sbx.Name, fmt.Errorf("the object has been modified"))
+		}
+		if update.GetSubresource() == "status" {
+			sbx.Spec = current.Spec
+		} else {
+			sbx.Status = current.Status
+		}
+		version, _ := strconv.Atoi(current.ResourceVersion)
+		sbx.ResourceVersion = strconv.Itoa(version + 1)
+		return true, sbx, fakeClient.Tracker().Update(gvr, sbx, sbx.Namespace)
+	})
+}
+
+//goland:noinspection GoDeprecation
+func TestInfra_Conformance(t *testing.T) {
+	utils.InitLogOutput()
+	infratest.RunConformance(t, func(t *testing.T) infratest.Environment {
+		testInfra, client := NewTestInfra(t)
+		enforceOptimisticLock(t, client)
+		ctx, cancel := context.WithCancel(context.Background())
+		t.Cleanup(cancel)
+		go simulateSandboxController(ctx, client)
+		return &conformanceEnvironment{infra: testInfra, client: client}
+	})
+}
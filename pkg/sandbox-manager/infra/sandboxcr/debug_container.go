@@ -0,0 +1,80 @@
+/*
+Copyright 2025 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sandboxcr
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilrand "k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/klog/v2"
+
+	"github.com/openkruise/agents/pkg/sandbox-manager/infra"
+)
+
+// DebugContainerPrefix is the name prefix of the ephemeral debug containers injected into sandboxes
+const DebugContainerPrefix = "debugger-"
+
+// Debug injects an ephemeral debug container into the pod of the sandbox, like `kubectl debug --target`, without
+// restarting the pod. The container sleeps for the TTL so that engineers can exec into it, and exits by itself
+// afterward. Ephemeral containers cannot be removed from a pod, so the exited container stays in the pod spec.
+func (s *Sandbox) Debug(ctx context.Context, opts infra.DebugOptions) (string, error) {
+	log := klog.FromContext(ctx).WithValues("sandbox", klog.KObj(s.Sandbox))
+	pods := s.Client.K8sClient.CoreV1().Pods(s.GetNamespace())
+	pod, err := pods.Get(ctx, s.GetName(), metav1.GetOptions{})
+	if err != nil {
+		return "", fmt.Errorf("failed to get pod of sandbox: %w", err)
+	}
+	target := opts.TargetContainer
+	if target == "" {
+		if len(pod.Spec.Containers) == 0 {
+			return "", fmt.Errorf("pod has no containers")
+		}
+		target = pod.Spec.Containers[0].Name
+	} else if !hasContainer(pod, target) {
+		return "", fmt.Errorf("container %s not found in pod", target)
+	}
+
+	name := DebugContainerPrefix + utilrand.String(5)
+	pod.Spec.EphemeralContainers = append(pod.Spec.EphemeralContainers, corev1.EphemeralContainer{
+		EphemeralContainerCommon: corev1.EphemeralContainerCommon{
+			Name:                     name,
+			Image:                    opts.Image,
+			Command:                  []string{"sleep", strconv.Itoa(int(opts.TTL.Seconds()))},
+			ImagePullPolicy:          corev1.PullIfNotPresent,
+			TerminationMessagePolicy: corev1.TerminationMessageReadFile,
+		},
+		TargetContainerName: target,
+	})
+	if _, err = pods.UpdateEphemeralContainers(ctx, pod.Name, pod, metav1.UpdateOptions{}); err != nil {
+		return "", fmt.Errorf("failed to inject debug container: %w", err)
+	}
+	log.Info("debug container injected", "container", name, "image", opts.Image, "target", target, "ttl", opts.TTL)
+	return name, nil
+}
+
+func hasContainer(pod *corev1.Pod, name string) bool {
+	for i := range pod.Spec.Containers {
+		if pod.Spec.Containers[i].Name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,85 @@
+package sandboxcr
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/openkruise/agents/api/v1alpha1"
+	"github.com/openkruise/agents/pkg/sandbox-manager/clients"
+	"github.com/openkruise/agents/pkg/sandbox-manager/infra"
+)
+
+func TestSandbox_Debug(t *testing.T) {
+	tests := []struct {
+		name         string
+		withPod      bool
+		target       string
+		expectTarget string
+		expectError  string
+	}{
+		{
+			name:         "default target",
+			withPod:      true,
+			expectTarget: "main",
+		},
+		{
+			name:         "specified target",
+			withPod:      true,
+			target:       "sidecar",
+			expectTarget: "sidecar",
+		},
+		{
+			name:        "target not found",
+			withPod:     true,
+			target:      "not-found",
+			expectError: "container not-found not found",
+		},
+		{
+			name:        "pod not found",
+			expectError: "failed to get pod of sandbox",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := clients.NewFakeClientSet(t)
+			sandbox := &v1alpha1.Sandbox{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-sandbox", Namespace: "default"},
+			}
+			if tt.withPod {
+				pod := &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{Name: "test-sandbox", Namespace: "default"},
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{{Name: "main"}, {Name: "sidecar"}},
+					},
+				}
+				_, err := client.K8sClient.CoreV1().Pods("default").Create(context.Background(), pod, metav1.CreateOptions{})
+				require.NoError(t, err)
+			}
+			s := AsSandbox(sandbox, nil, client)
+
+			name, err := s.Debug(context.Background(), infra.DebugOptions{Image: "busybox", TargetContainer: tt.target, TTL: time.Minute})
+			if tt.expectError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectError)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, strings.HasPrefix(name, DebugContainerPrefix))
+			pod, err := client.K8sClient.CoreV1().Pods("default").Get(context.Background(), "test-sandbox", metav1.GetOptions{})
+			require.NoError(t, err)
+			require.Len(t, pod.Spec.EphemeralContainers, 1)
+			container := pod.Spec.EphemeralContainers[0]
+			assert.Equal(t, name, container.Name)
+			assert.Equal(t, "busybox", container.Image)
+			assert.Equal(t, tt.expectTarget, container.TargetContainerName)
+			assert.Equal(t, []string{"sleep", "60"}, container.Command)
+		})
+	}
+}
@@ -7,6 +7,9 @@ import (
 
 type retriableError struct {
 	Message string
+	// Conflict tells that the picked sandbox was changed concurrently, e.g. claimed by another request, so that
+	// picking another one right away is likely to succeed
+	Conflict bool
 }
 
 func (e retriableError) Error() string {
@@ -24,3 +27,9 @@ func (e retriableError) Is(target error) bool {
 func NoAvailableError(template, reason string) error {
 	return retriableError{Message: fmt.Sprintf("no available sandboxes for template %s (%s)", template, reason)}
 }
+
+// IsLockConflict checks if claiming a sandbox failed as the picked sandbox was changed concurrently
+func IsLockConflict(err error) bool {
+	as := retriableError{}
+	return errors.As(err, &as) && as.Conflict
+}
@@ -9,6 +9,7 @@ import (
 
 	"golang.org/x/time/rate"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/wait"
 	k8scache "k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/util/retry"
@@ -49,6 +50,7 @@ type Infra struct {
 	pickCache        sync.Map
 	claimLockChannel chan struct{}
 	createLimiter    *rate.Limiter
+	ledger           claimLedger
 
 	// Currently, templates stores the mapping of sandboxset name -> number of namespaces. For example,
 	// if a sandboxset with the same name is created in two different namespaces, the corresponding value would be 2.
@@ -112,6 +114,8 @@ func (i *Infra) ClaimSandbox(ctx context.Context, opts infra.ClaimSandboxOptions
 
 	claimCtx, cancel := context.WithTimeout(ctx, opts.ClaimTimeout)
 	defer cancel()
+	claimCtx, untrack := i.ledger.track(claimCtx, opts)
+	defer untrack()
 
 	// Start claiming sandbox
 	log.V(consts.DebugLogLevel).Info("claim sandbox options", "options", opts)
@@ -127,6 +131,11 @@ func (i *Infra) ClaimSandbox(ctx context.Context, opts infra.ClaimSandboxOptions
 	}, func() error {
 		metrics.Retries++
 		log.Info("try to claim sandbox", "retries", metrics.Retries)
+		updateClaimProgress(claimCtx, func(progress *infra.ClaimProgress) {
+			progress.Stage = infra.ClaimStageWaitingWorker
+			progress.Retries = metrics.Retries
+			progress.Sandbox = ""
+		})
 		claimed, tryMetrics, claimErr := TryClaimSandbox(claimCtx, opts, &i.pickCache, i.Cache, i.Client, i.claimLockChannel, i.createLimiter)
 		metrics.Total += tryMetrics.Total
 		metrics.Wait += tryMetrics.Wait
@@ -142,6 +151,10 @@ func (i *Infra) ClaimSandbox(ctx context.Context, opts infra.ClaimSandboxOptions
 			claimedSandbox = claimed
 		} else {
 			metrics.RetryCost += tryMetrics.Total
+			updateClaimProgress(claimCtx, func(progress *infra.ClaimProgress) {
+				progress.Stage = infra.ClaimStageBackoff
+				progress.LastError = claimErr.Error()
+			})
 		}
 		return claimErr
 	})
@@ -282,6 +295,60 @@ func (i *Infra) GetClaimedSandbox(ctx context.Context, sandboxID string) (infra.
 	return AsSandbox(sandbox, i.Cache, i.Client), nil
 }
 
+func (i *Infra) WaitForClaim(ctx context.Context, namespace, name string, timeout time.Duration) (*v1alpha1.SandboxClaim, []infra.Sandbox, error) {
+	claim, err := i.Cache.GetSandboxClaim(namespace, name)
+	if err != nil {
+		return nil, nil, err
+	}
+	claim, err = i.Cache.WaitForSandboxClaimSatisfied(ctx, claim, WaitActionClaim, func(claim *v1alpha1.SandboxClaim) (bool, error) {
+		return claim.Status.Phase == v1alpha1.SandboxClaimPhaseCompleted || claim.Status.Phase == v1alpha1.SandboxClaimPhaseFailed, nil
+	}, timeout)
+	if err != nil {
+		return nil, nil, err
+	}
+	if claim.Status.Phase != v1alpha1.SandboxClaimPhaseCompleted {
+		return claim, nil, nil
+	}
+	objects, err := i.Cache.ListSandboxWithUser(string(claim.UID))
+	if err != nil {
+		return nil, nil, err
+	}
+	sandboxes := make([]infra.Sandbox, 0, len(objects))
+	for _, obj := range objects {
+		if obj.DeletionTimestamp != nil {
+			continue
+		}
+		sandboxes = append(sandboxes, AsSandbox(obj, i.Cache, i.Client))
+	}
+	return claim, sandboxes, nil
+}
+
+func (i *Infra) DecideClaimApproval(ctx context.Context, namespace, name, decision string) (*v1alpha1.SandboxClaim, error) {
+	log := klog.FromContext(ctx).WithValues("claim", klog.KRef(namespace, name))
+	claim, err := i.Cache.GetSandboxClaim(namespace, name)
+	if err != nil {
+		return nil, managererrors.NewError(managererrors.ErrorNotFound, err.Error())
+	}
+	if !claim.Spec.RequiresApproval {
+		return nil, managererrors.NewError(managererrors.ErrorBadRequest, fmt.Sprintf("sandboxclaim %s/%s does not require approval", namespace, name))
+	}
+	if existing, ok := claim.Annotations[v1alpha1.AnnotationClaimApproval]; ok {
+		if existing == decision {
+			return claim, nil
+		}
+		return nil, managererrors.NewError(managererrors.ErrorConflict, fmt.Sprintf("sandboxclaim %s/%s is already %s", namespace, name, existing))
+	}
+	// the approval webhook checks the service account of the sandbox-manager is allowed to approve the claim
+	patch := fmt.Sprintf(`{"metadata":{"annotations":{%q:%q}}}`, v1alpha1.AnnotationClaimApproval, decision)
+	updated, err := i.Client.ApiV1alpha1().SandboxClaims(namespace).Patch(ctx, name, types.MergePatchType, []byte(patch), metav1.PatchOptions{})
+	if err != nil {
+		log.Error(err, "failed to patch sandboxclaim approval", "decision", decision)
+		return nil, managererrors.NewError(managererrors.ErrorInternal, err.Error())
+	}
+	log.Info("sandboxclaim approval decided", "decision", decision)
+	return updated, nil
+}
+
 func (i *Infra) onSandboxAdd(obj any) {
 	sbx, ok := obj.(*v1alpha1.Sandbox)
 	if !ok {
@@ -0,0 +1,106 @@
+package sandboxcr
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/openkruise/agents/pkg/sandbox-manager/infra"
+)
+
+// claimLedger records the progress of in-flight claims, so that a claim stuck at some stage can be found by
+// introspection.
+type claimLedger struct {
+	claims sync.Map // Key: claim ID; Value: *claimRecord
+}
+
+type claimRecord struct {
+	mu       sync.Mutex
+	progress infra.ClaimProgress
+}
+
+type claimRecordKey struct{}
+
+// track adds a claim to the ledger and returns the context carrying its record, the claim is removed by the returned
+// function once finished.
+func (l *claimLedger) track(ctx context.Context, opts infra.ClaimSandboxOptions) (context.Context, func()) {
+	now := time.Now()
+	record := &claimRecord{progress: infra.ClaimProgress{
+		ID:        uuid.NewString()[:8],
+		User:      opts.User,
+		Template:  opts.Template,
+		Stage:     infra.ClaimStageWaitingWorker,
+		StartTime: now,
+		StageTime: now,
+	}}
+	l.claims.Store(record.progress.ID, record)
+	return context.WithValue(ctx, claimRecordKey{}, record), func() {
+		l.claims.Delete(record.progress.ID)
+	}
+}
+
+// list returns the progress of the in-flight claims, ordered by start time.
+func (l *claimLedger) list() []infra.ClaimProgress {
+	claims := make([]infra.ClaimProgress, 0)
+	l.claims.Range(func(_, value any) bool {
+		record := value.(*claimRecord)
+		record.mu.Lock()
+		claims = append(claims, record.progress)
+		record.mu.Unlock()
+		return true
+	})
+	sort.Slice(claims, func(i, j int) bool {
+		return claims[i].StartTime.Before(claims[j].StartTime)
+	})
+	return claims
+}
+
+// updateClaimProgress updates the record of the claim in ctx, if tracked.
+func updateClaimProgress(ctx context.Context, update func(progress *infra.ClaimProgress)) {
+	record, ok := ctx.Value(claimRecordKey{}).(*claimRecord)
+	if !ok {
+		return
+	}
+	record.mu.Lock()
+	defer record.mu.Unlock()
+	stage := record.progress.Stage
+	update(&record.progress)
+	if record.progress.Stage != stage {
+		record.progress.StageTime = time.Now()
+	}
+}
+
+// setClaimStage moves the claim in ctx to the stage.
+func setClaimStage(ctx context.Context, stage infra.ClaimStage) {
+	updateClaimProgress(ctx, func(progress *infra.ClaimProgress) {
+		progress.Stage = stage
+	})
+}
+
+func (i *Infra) Introspect() infra.Introspection {
+	introspection := infra.Introspection{
+		BusyClaimWorkers: len(i.claimLockChannel),
+		MaxClaimWorkers:  cap(i.claimLockChannel),
+		Claims:           i.ledger.list(),
+		PickLocks:        make([]string, 0),
+		WaitHooks:        make(map[string]string),
+	}
+	i.pickCache.Range(func(key, _ any) bool {
+		introspection.PickLocks = append(introspection.PickLocks, key.(string))
+		return true
+	})
+	sort.Strings(introspection.PickLocks)
+	i.Cache.waitHooks.Range(func(key, value any) bool {
+		action := ""
+		if entry, ok := value.(interface{ waitAction() WaitAction }); ok {
+			action = string(entry.waitAction())
+		}
+		introspection.WaitHooks[fmt.Sprint(key)] = action
+		return true
+	})
+	return introspection
+}
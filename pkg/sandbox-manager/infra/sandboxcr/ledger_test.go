@@ -0,0 +1,72 @@
+package sandboxcr
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openkruise/agents/pkg/sandbox-manager/config"
+	"github.com/openkruise/agents/pkg/sandbox-manager/infra"
+)
+
+func TestClaimLedger(t *testing.T) {
+	ledger := &claimLedger{}
+	// stages of untracked claims are ignored
+	setClaimStage(context.Background(), infra.ClaimStagePicking)
+
+	ctx1, untrack1 := ledger.track(context.Background(), infra.ClaimSandboxOptions{User: "user1", Template: "python"})
+	time.Sleep(time.Millisecond)
+	ctx2, untrack2 := ledger.track(context.Background(), infra.ClaimSandboxOptions{User: "user2", Template: "node"})
+	claims := ledger.list()
+	require.Len(t, claims, 2)
+	assert.Equal(t, "user1", claims[0].User)
+	assert.Equal(t, infra.ClaimStageWaitingWorker, claims[0].Stage)
+	assert.Equal(t, "node", claims[1].Template)
+
+	stageTime := claims[0].StageTime
+	time.Sleep(time.Millisecond)
+	updateClaimProgress(ctx1, func(progress *infra.ClaimProgress) {
+		progress.Stage = infra.ClaimStageLocking
+		progress.Sandbox = "default/sbx-1"
+	})
+	setClaimStage(ctx2, infra.ClaimStageWaitingWorker) // the stage time is kept for the same stage
+	claims = ledger.list()
+	assert.Equal(t, infra.ClaimStageLocking, claims[0].Stage)
+	assert.Equal(t, "default/sbx-1", claims[0].Sandbox)
+	assert.True(t, claims[0].StageTime.After(stageTime))
+	assert.Equal(t, claims[1].StartTime, claims[1].StageTime)
+
+	untrack1()
+	claims = ledger.list()
+	require.Len(t, claims, 1)
+	assert.Equal(t, "user2", claims[0].User)
+	untrack2()
+	assert.Empty(t, ledger.list())
+}
+
+func TestInfra_Introspect(t *testing.T) {
+	infraInstance, _ := NewTestInfra(t, config.SandboxManagerOptions{MaxClaimWorkers: 4})
+	defer infraInstance.Stop(context.Background())
+
+	introspection := infraInstance.Introspect()
+	assert.Equal(t, 0, introspection.BusyClaimWorkers)
+	assert.Equal(t, 4, introspection.MaxClaimWorkers)
+	assert.Empty(t, introspection.Claims)
+	assert.Empty(t, introspection.PickLocks)
+
+	infraInstance.claimLockChannel <- struct{}{}
+	infraInstance.pickCache.Store("default/sbx-2", struct{}{})
+	infraInstance.pickCache.Store("default/sbx-1", struct{}{})
+	ctx, untrack := infraInstance.ledger.track(context.Background(), infra.ClaimSandboxOptions{User: "user", Template: "python"})
+	defer untrack()
+	setClaimStage(ctx, infra.ClaimStagePicking)
+
+	introspection = infraInstance.Introspect()
+	assert.Equal(t, 1, introspection.BusyClaimWorkers)
+	assert.Equal(t, []string{"default/sbx-1", "default/sbx-2"}, introspection.PickLocks)
+	require.Len(t, introspection.Claims, 1)
+	assert.Equal(t, infra.ClaimStagePicking, introspection.Claims[0].Stage)
+}
@@ -214,6 +214,10 @@ func (s *Sandbox) Pause(ctx context.Context, opts infra.PauseOptions) error {
 	if s.Status.Phase != agentsv1alpha1.SandboxRunning {
 		return fmt.Errorf("sandbox is not in running phase")
 	}
+	if s.Spec.Quarantine != nil {
+		// pausing deletes the pod, which is kept for the review
+		return fmt.Errorf("sandbox is quarantined")
+	}
 	state, reason := s.GetState()
 	if state != agentsv1alpha1.SandboxStateRunning {
 		err := fmt.Errorf("pausing is only available for running state, current state: %s", state)
@@ -344,6 +348,71 @@ func (s *Sandbox) GetClaimTime() (time.Time, error) {
 	return time.Parse(time.RFC3339, claimTimestamp)
 }
 
+func (s *Sandbox) GetHeartbeat() *agentsv1alpha1.SandboxHeartbeat {
+	return s.Status.Heartbeat
+}
+
+func (s *Sandbox) SaveHeartbeat(ctx context.Context, heartbeat agentsv1alpha1.SandboxHeartbeat) error {
+	return s.retryUpdate(ctx, s.Client.ApiV1alpha1().Sandboxes(s.GetNamespace()).UpdateStatus, func(sbx *agentsv1alpha1.Sandbox) {
+		sbx.Status.Heartbeat = &heartbeat
+	})
+}
+
+func (s *Sandbox) GetSecurityAlert() *agentsv1alpha1.SandboxSecurityAlert {
+	return s.Status.SecurityAlert
+}
+
+// RecordSecurityAlert replaces the details of the latest security alert and counts it. The Count of alert is ignored.
+func (s *Sandbox) RecordSecurityAlert(ctx context.Context, alert agentsv1alpha1.SandboxSecurityAlert) error {
+	return s.retryUpdate(ctx, s.Client.ApiV1alpha1().Sandboxes(s.GetNamespace()).UpdateStatus, func(sbx *agentsv1alpha1.Sandbox) {
+		alert.Count = 1
+		if sbx.Status.SecurityAlert != nil {
+			alert.Count = sbx.Status.SecurityAlert.Count + 1
+		}
+		sbx.Status.SecurityAlert = &alert
+	})
+}
+
+func (s *Sandbox) GetQuarantine() *agentsv1alpha1.SandboxQuarantine {
+	return s.Spec.Quarantine
+}
+
+// FreezeCommand stops all processes in the sandbox except the runtime serving it, so that a quarantined sandbox keeps
+// its memory for the review but does nothing.
+var FreezeCommand = []string{"/bin/sh", "-c",
+	`for p in /proc/[0-9]*; do pid=${p#/proc/}; [ "$pid" = "$$" ] || [ "$pid" = "$PPID" ] || kill -STOP "$pid" 2>/dev/null; done; true`}
+
+// Quarantine freezes the processes of a running sandbox and marks it quarantined, so that the controller isolates it
+// from the network and keeps it for security review. The processes are frozen before the network is cut, since the
+// runtime is unreachable afterward. Failing to freeze does not stop the quarantine.
+func (s *Sandbox) Quarantine(ctx context.Context, opts infra.QuarantineOptions) error {
+	log := klog.FromContext(ctx).WithValues("sandbox", klog.KObj(s.Sandbox))
+	if s.Spec.Quarantine != nil {
+		log.Info("sandbox is already quarantined")
+		return nil
+	}
+	if state, _ := s.GetState(); state == agentsv1alpha1.SandboxStateRunning {
+		result, err := s.runCommandWithRuntime(ctx, &process.ProcessConfig{Cmd: FreezeCommand[0], Args: FreezeCommand[1:]}, 5*time.Second)
+		if err == nil && result.ExitCode != 0 {
+			err = fmt.Errorf("command failed: [%d] %s", result.ExitCode, result.Stderr)
+		}
+		if err != nil {
+			log.Error(err, "failed to freeze sandbox processes, quarantine it anyway")
+		} else {
+			log.Info("sandbox processes frozen")
+		}
+	}
+	err := s.retryUpdate(ctx, s.Client.ApiV1alpha1().Sandboxes(s.GetNamespace()).Update, func(sbx *agentsv1alpha1.Sandbox) {
+		sbx.Spec.Quarantine = &agentsv1alpha1.SandboxQuarantine{Reason: opts.Reason, Source: opts.Source}
+	})
+	if err != nil {
+		log.Error(err, "failed to update sandbox spec.quarantine")
+		return err
+	}
+	log.Info("sandbox quarantined", "reason", opts.Reason, "source", opts.Source)
+	return nil
+}
+
 var MountCommand = "/mnt/envd/sandbox-runtime-storage"
 
 // CSIMount creates a dynamic mount point in Sandbox with `sandbox-storage` cli
@@ -0,0 +1,245 @@
+package sandboxcr
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/spf13/pflag"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+	"github.com/openkruise/agents/client/clientset/versioned"
+	"github.com/openkruise/agents/pkg/sandbox-manager/config"
+	"github.com/openkruise/agents/pkg/sandbox-manager/consts"
+)
+
+// snapshotFormatVersion is bumped whenever the layout of snapshot files changes incompatibly.
+const snapshotFormatVersion = 1
+
+// SnapshotOptions configures the snapshot of the sandbox cache, which lets a restarted sandbox-manager warm start
+// instead of listing all sandboxes from the API server.
+type SnapshotOptions struct {
+	// Path of the snapshot file. Snapshots and warm start are disabled if empty.
+	Path string
+	// Interval of periodic snapshots. A snapshot is also taken when the cache stops.
+	Interval time.Duration
+	// MaxAge of a snapshot to warm start from. Older snapshots are discarded.
+	MaxAge time.Duration
+}
+
+// DefaultSnapshotOptions is set by the command line flags and used by the caches of the process.
+var DefaultSnapshotOptions = SnapshotOptions{
+	Interval: 5 * time.Minute,
+	MaxAge:   30 * time.Minute,
+}
+
+// AddFlags registers the flags of the options.
+func (o *SnapshotOptions) AddFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&o.Path, "cache-snapshot-path", o.Path,
+		"Path of the sandbox cache snapshot used to warm start after restart, e.g. on an emptyDir volume. Empty disables it.")
+	fs.DurationVar(&o.Interval, "cache-snapshot-interval", o.Interval, "Interval of periodic sandbox cache snapshots.")
+	fs.DurationVar(&o.MaxAge, "cache-snapshot-max-age", o.MaxAge, "Max age of a sandbox cache snapshot to warm start from.")
+}
+
+// cacheSnapshot is the file layout of a snapshot.
+type cacheSnapshot struct {
+	Version int `json:"version"`
+	// Fingerprint identifies the filters of the cache, a snapshot of a cache with other filters is discarded.
+	Fingerprint string    `json:"fingerprint"`
+	CreatedAt   time.Time `json:"createdAt"`
+	// ResourceVersion is the last resource version synced by the informer before the sandboxes are read,
+	// so that watching from it replays everything that happened after the snapshot.
+	ResourceVersion string `json:"resourceVersion"`
+	// Checksum is the sha256 of Sandboxes, to detect truncated or corrupted files.
+	Checksum  string          `json:"checksum"`
+	Sandboxes json.RawMessage `json:"sandboxes"`
+}
+
+func snapshotFingerprint(opts config.SandboxManagerOptions) string {
+	return fmt.Sprintf("namespace=%s;selector=%s", opts.SandboxNamespace, opts.SandboxLabelSelector)
+}
+
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// loadSnapshot reads the snapshot at path and verifies it can be warm started from.
+func loadSnapshot(path, fingerprint string, maxAge time.Duration, now time.Time) (*agentsv1alpha1.SandboxList, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	snapshot := &cacheSnapshot{}
+	if err = json.Unmarshal(data, snapshot); err != nil {
+		return nil, fmt.Errorf("failed to parse snapshot: %w", err)
+	}
+	if snapshot.Version != snapshotFormatVersion {
+		return nil, fmt.Errorf("unsupported snapshot version %d", snapshot.Version)
+	}
+	if snapshot.Fingerprint != fingerprint {
+		return nil, fmt.Errorf("snapshot fingerprint %q does not match %q", snapshot.Fingerprint, fingerprint)
+	}
+	if age := now.Sub(snapshot.CreatedAt); age > maxAge {
+		return nil, fmt.Errorf("snapshot is too old: %s", age.Round(time.Second))
+	}
+	if snapshot.ResourceVersion == "" {
+		return nil, fmt.Errorf("snapshot has no resource version")
+	}
+	if checksum(snapshot.Sandboxes) != snapshot.Checksum {
+		return nil, fmt.Errorf("snapshot checksum mismatch")
+	}
+	list := &agentsv1alpha1.SandboxList{}
+	if err = json.Unmarshal(snapshot.Sandboxes, &list.Items); err != nil {
+		return nil, fmt.Errorf("failed to parse sandboxes of snapshot: %w", err)
+	}
+	list.ResourceVersion = snapshot.ResourceVersion
+	return list, nil
+}
+
+// saveSnapshot writes the sandboxes of the informer to path atomically.
+func saveSnapshot(informer cache.SharedIndexInformer, path, fingerprint string, now time.Time) (int, error) {
+	// read the resource version before the sandboxes, so that the sandboxes are at least as new as it
+	resourceVersion := informer.LastSyncResourceVersion()
+	if resourceVersion == "" {
+		return 0, fmt.Errorf("sandbox informer is not synced")
+	}
+	objs := informer.GetStore().List()
+	sandboxes := make([]*agentsv1alpha1.Sandbox, 0, len(objs))
+	for _, obj := range objs {
+		if sbx, ok := obj.(*agentsv1alpha1.Sandbox); ok {
+			sandboxes = append(sandboxes, sbx)
+		}
+	}
+	items, err := json.Marshal(sandboxes)
+	if err != nil {
+		return 0, err
+	}
+	data, err := json.Marshal(&cacheSnapshot{
+		Version:         snapshotFormatVersion,
+		Fingerprint:     fingerprint,
+		CreatedAt:       now,
+		ResourceVersion: resourceVersion,
+		Checksum:        checksum(items),
+		Sandboxes:       items,
+	})
+	if err != nil {
+		return 0, err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		_ = os.Remove(tmp.Name())
+	}()
+	if _, err = tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return 0, err
+	}
+	if err = tmp.Close(); err != nil {
+		return 0, err
+	}
+	return len(sandboxes), os.Rename(tmp.Name(), path)
+}
+
+// warmStartListWatch serves the snapshot as the first list of the informer. The informer then watches from the
+// resource version of the snapshot, so only the changes after the snapshot are received from the API server.
+// If the resource version is already compacted, the watch fails with "too old resource version" and the
+// informer falls back to a full relist, which goes to the API server.
+type warmStartListWatch struct {
+	*cache.ListWatch
+	mu       sync.Mutex
+	snapshot *agentsv1alpha1.SandboxList
+}
+
+func (lw *warmStartListWatch) takeSnapshot() *agentsv1alpha1.SandboxList {
+	lw.mu.Lock()
+	defer lw.mu.Unlock()
+	snapshot := lw.snapshot
+	lw.snapshot = nil
+	return snapshot
+}
+
+func (lw *warmStartListWatch) List(options metav1.ListOptions) (runtime.Object, error) {
+	if snapshot := lw.takeSnapshot(); snapshot != nil {
+		return snapshot, nil
+	}
+	return lw.ListWatch.List(options)
+}
+
+func (lw *warmStartListWatch) ListWithContext(ctx context.Context, options metav1.ListOptions) (runtime.Object, error) {
+	if snapshot := lw.takeSnapshot(); snapshot != nil {
+		return snapshot, nil
+	}
+	return lw.ListWatch.ListWithContext(ctx, options)
+}
+
+// IsWatchListSemanticsUnSupported disables the streaming list, which would bypass the snapshot.
+func (lw *warmStartListWatch) IsWatchListSemanticsUnSupported() bool {
+	return true
+}
+
+// newWarmStartSandboxInformer returns a function creating the sandbox informer of the factory, which warm starts
+// from the snapshot. It is equivalent to the generated informer otherwise.
+func newWarmStartSandboxInformer(opts config.SandboxManagerOptions, snapshot *agentsv1alpha1.SandboxList) func(versioned.Interface, time.Duration) cache.SharedIndexInformer {
+	tweak := func(options *metav1.ListOptions) {
+		if opts.SandboxLabelSelector != "" {
+			options.LabelSelector = opts.SandboxLabelSelector
+		}
+	}
+	return func(client versioned.Interface, resyncPeriod time.Duration) cache.SharedIndexInformer {
+		lw := &warmStartListWatch{
+			ListWatch: &cache.ListWatch{
+				ListWithContextFunc: func(ctx context.Context, options metav1.ListOptions) (runtime.Object, error) {
+					tweak(&options)
+					return client.ApiV1alpha1().Sandboxes(opts.SandboxNamespace).List(ctx, options)
+				},
+				WatchFuncWithContext: func(ctx context.Context, options metav1.ListOptions) (watch.Interface, error) {
+					tweak(&options)
+					return client.ApiV1alpha1().Sandboxes(opts.SandboxNamespace).Watch(ctx, options)
+				},
+			},
+			snapshot: snapshot,
+		}
+		return cache.NewSharedIndexInformer(lw, &agentsv1alpha1.Sandbox{}, resyncPeriod,
+			cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	}
+}
+
+// runSnapshots takes snapshots of the sandbox cache periodically until the cache stops.
+func (c *Cache) runSnapshots(ctx context.Context) {
+	if c.snapshotOptions.Path == "" || c.snapshotOptions.Interval <= 0 {
+		return
+	}
+	go wait.Until(func() {
+		c.snapshot(ctx)
+	}, c.snapshotOptions.Interval, c.stopCh)
+}
+
+func (c *Cache) snapshot(ctx context.Context) {
+	if c.snapshotOptions.Path == "" {
+		return
+	}
+	log := klog.FromContext(ctx)
+	start := time.Now()
+	count, err := saveSnapshot(c.sandboxInformer, c.snapshotOptions.Path, c.snapshotFingerprint, start)
+	if err != nil {
+		log.Error(err, "failed to take sandbox cache snapshot", "path", c.snapshotOptions.Path)
+		return
+	}
+	log.V(consts.DebugLogLevel).Info("sandbox cache snapshot taken", "path", c.snapshotOptions.Path,
+		"sandboxes", count, "cost", time.Since(start))
+}
@@ -0,0 +1,161 @@
+package sandboxcr
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+	"github.com/openkruise/agents/pkg/sandbox-manager/config"
+	"github.com/openkruise/agents/pkg/utils"
+)
+
+func newSnapshotSandbox(name string) *agentsv1alpha1.Sandbox {
+	return &agentsv1alpha1.Sandbox{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            name,
+			Namespace:       "default",
+			ResourceVersion: "10",
+		},
+		Status: agentsv1alpha1.SandboxStatus{Phase: agentsv1alpha1.SandboxRunning},
+	}
+}
+
+// writeTestSnapshot takes a snapshot of an informer listing the sandboxes at resource version 10 once.
+func writeTestSnapshot(t *testing.T, path, fingerprint string, now time.Time, sandboxes ...*agentsv1alpha1.Sandbox) {
+	t.Helper()
+	list := &agentsv1alpha1.SandboxList{ListMeta: metav1.ListMeta{ResourceVersion: "10"}}
+	for _, sbx := range sandboxes {
+		list.Items = append(list.Items, *sbx)
+	}
+	informer := cache.NewSharedIndexInformer(&warmStartListWatch{
+		ListWatch: &cache.ListWatch{
+			WatchFuncWithContext: func(context.Context, metav1.ListOptions) (watch.Interface, error) {
+				return watch.NewFake(), nil
+			},
+		},
+		snapshot: list,
+	}, &agentsv1alpha1.Sandbox{}, 0, cache.Indexers{})
+	go informer.Run(t.Context().Done())
+	require.True(t, cache.WaitForCacheSync(t.Context().Done(), informer.HasSynced))
+
+	count, err := saveSnapshot(informer, path, fingerprint, now)
+	require.NoError(t, err)
+	assert.Equal(t, len(sandboxes), count)
+}
+
+func TestLoadSnapshot(t *testing.T) {
+	now := time.Now()
+	fingerprint := snapshotFingerprint(config.SandboxManagerOptions{SandboxNamespace: "default"})
+	tests := []struct {
+		name        string
+		prepare     func(t *testing.T, path string)
+		expectError string
+	}{
+		{
+			name: "valid",
+			prepare: func(t *testing.T, path string) {
+				writeTestSnapshot(t, path, fingerprint, now.Add(-time.Minute), newSnapshotSandbox("a"), newSnapshotSandbox("b"))
+			},
+		},
+		{
+			name:        "not found",
+			prepare:     func(t *testing.T, path string) {},
+			expectError: "no such file or directory",
+		},
+		{
+			name: "other filters",
+			prepare: func(t *testing.T, path string) {
+				writeTestSnapshot(t, path, snapshotFingerprint(config.SandboxManagerOptions{}), now, newSnapshotSandbox("a"))
+			},
+			expectError: "does not match",
+		},
+		{
+			name: "too old",
+			prepare: func(t *testing.T, path string) {
+				writeTestSnapshot(t, path, fingerprint, now.Add(-time.Hour), newSnapshotSandbox("a"))
+			},
+			expectError: "snapshot is too old: 1h0m0s",
+		},
+		{
+			name: "unsupported version",
+			prepare: func(t *testing.T, path string) {
+				writeTestSnapshot(t, path, fingerprint, now, newSnapshotSandbox("a"))
+				modifySnapshot(t, path, func(s *cacheSnapshot) { s.Version = snapshotFormatVersion + 1 })
+			},
+			expectError: "unsupported snapshot version 2",
+		},
+		{
+			name: "corrupted",
+			prepare: func(t *testing.T, path string) {
+				writeTestSnapshot(t, path, fingerprint, now, newSnapshotSandbox("a"))
+				modifySnapshot(t, path, func(s *cacheSnapshot) { s.Sandboxes = json.RawMessage(`[]`) })
+			},
+			expectError: "snapshot checksum mismatch",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "snapshot.json")
+			tt.prepare(t, path)
+			list, err := loadSnapshot(path, fingerprint, 30*time.Minute, now)
+			if tt.expectError != "" {
+				assert.ErrorContains(t, err, tt.expectError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "10", list.ResourceVersion)
+			require.Len(t, list.Items, 2)
+			assert.ElementsMatch(t, []string{"a", "b"}, []string{list.Items[0].Name, list.Items[1].Name})
+		})
+	}
+}
+
+func modifySnapshot(t *testing.T, path string, modify func(s *cacheSnapshot)) {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	snapshot := &cacheSnapshot{}
+	require.NoError(t, json.Unmarshal(data, snapshot))
+	modify(snapshot)
+	data, err = json.Marshal(snapshot)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+}
+
+func TestCache_WarmStart(t *testing.T) {
+	opts := config.SandboxManagerOptions{SystemNamespace: utils.DefaultSandboxDeployNamespace}
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+	writeTestSnapshot(t, path, snapshotFingerprint(opts), time.Now(), newSnapshotSandbox("from-snapshot"))
+
+	origin := DefaultSnapshotOptions
+	DefaultSnapshotOptions.Path = path
+	defer func() {
+		DefaultSnapshotOptions = origin
+	}()
+	c, client, err := NewTestCacheWithOptions(t, opts)
+	require.NoError(t, err)
+	defer c.Stop(t.Context())
+
+	// the sandbox is only in the snapshot, the fake API server does not have it
+	_, exists, err := c.sandboxInformer.GetStore().GetByKey("default/from-snapshot")
+	require.NoError(t, err)
+	assert.True(t, exists)
+	assert.Equal(t, "10", c.sandboxInformer.LastSyncResourceVersion())
+
+	// changes after the snapshot are received by watching
+	CreateSandboxWithStatus(t, client.SandboxClient, newSnapshotSandbox("from-watch"))
+	assert.Eventually(t, func() bool {
+		_, exists, _ := c.sandboxInformer.GetStore().GetByKey("default/from-watch")
+		return exists
+	}, time.Second, 10*time.Millisecond)
+}
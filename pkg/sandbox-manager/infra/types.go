@@ -2,10 +2,12 @@ package infra
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 
 	"golang.org/x/time/rate"
+	"k8s.io/apimachinery/pkg/labels"
 
 	"github.com/openkruise/agents/api/v1alpha1"
 	"github.com/openkruise/agents/pkg/sandbox-manager/config"
@@ -16,6 +18,12 @@ type ClaimSandboxOptions struct {
 	User string `json:"user"`
 	// Template specifies the pool to claim sandbox from, Required
 	Template string `json:"template"`
+	// Revision restricts the candidates to the sandboxes of a SandboxSet revision, empty means any revision
+	Revision string `json:"revision"`
+	// Regions selects the sandboxes by the regions of their pools, nil means any region
+	Regions *v1alpha1.RegionPolicy `json:"regions"`
+	// Selector restricts the candidates to the sandboxes with matching labels, nil means any sandbox
+	Selector labels.Selector `json:"-"`
 	// CandidateCounts is the maximum number of available sandboxes to select from the cache
 	CandidateCounts int `json:"candidateCounts"`
 	// Lock string used in optimistic lock
@@ -45,6 +53,24 @@ type ClaimSandboxOptions struct {
 	SpeculateCreatingDuration time.Duration `json:"speculateCreatingDuration"`
 }
 
+// RegionRank ranks the region by the policy, lower ranks are claimed first. False is returned if the region must not
+// be claimed from. Regions not preferred share the rank after the preferred ones, which is only allowed by fallback.
+func RegionRank(policy *v1alpha1.RegionPolicy, region string) (int, bool) {
+	if policy == nil {
+		return 0, true
+	}
+	if slices.Contains(policy.Forbidden, region) {
+		return 0, false
+	}
+	if len(policy.Preferred) == 0 {
+		return 0, true
+	}
+	if rank := slices.Index(policy.Preferred, region); rank >= 0 {
+		return rank, true
+	}
+	return len(policy.Preferred), policy.Fallback != v1alpha1.RegionFallbackNone
+}
+
 type CloneSandboxOptions struct {
 	User               string                  `json:"user"`
 	CheckPointID       string                  `json:"checkPointID"`
@@ -111,3 +137,46 @@ func (m CloneMetrics) String() string {
 	return fmt.Sprintf("CloneMetrics{Wait: %v, GetTemplate: %v, CreateSandbox: %v, WaitReady: %v, InitRuntime: %v, CSIMount: %v, Total: %v}",
 		m.Wait, m.GetTemplate, m.CreateSandbox, m.WaitReady, m.InitRuntime, m.CSIMount, m.Total)
 }
+
+// ClaimStage is the step an in-flight claim is at
+type ClaimStage string
+
+const (
+	ClaimStageWaitingWorker = ClaimStage("WaitingWorker")
+	ClaimStagePicking       = ClaimStage("Picking")
+	ClaimStageLocking       = ClaimStage("Locking")
+	ClaimStageWaitingReady  = ClaimStage("WaitingReady")
+	ClaimStageInitRuntime   = ClaimStage("InitRuntime")
+	ClaimStageCSIMount      = ClaimStage("CSIMount")
+	// ClaimStageBackoff is between a failed try and the next retry
+	ClaimStageBackoff = ClaimStage("Backoff")
+)
+
+// ClaimProgress is the state of an in-flight claim
+type ClaimProgress struct {
+	ID       string     `json:"id"`
+	User     string     `json:"user"`
+	Template string     `json:"template"`
+	Stage    ClaimStage `json:"stage"`
+	Retries  int        `json:"retries"`
+	// Sandbox is the sandbox locked by the current try, if any
+	Sandbox   string    `json:"sandbox,omitempty"`
+	LastError string    `json:"lastError,omitempty"`
+	StartTime time.Time `json:"startTime"`
+	// StageTime is when the claim entered the current stage, a claim stuck at a stage has an old StageTime
+	StageTime time.Time `json:"stageTime"`
+}
+
+// Introspection is a point-in-time dump of the claiming state of the infra, to diagnose claims stop progressing
+// without errors.
+type Introspection struct {
+	// BusyClaimWorkers of MaxClaimWorkers are held by claims picking and locking sandboxes
+	BusyClaimWorkers int `json:"busyClaimWorkers"`
+	MaxClaimWorkers  int `json:"maxClaimWorkers"`
+	// Claims are the in-flight claims, ordered by start time
+	Claims []ClaimProgress `json:"claims"`
+	// PickLocks are the sandboxes picked by in-flight claims and not yet locked in the API server
+	PickLocks []string `json:"pickLocks"`
+	// WaitHooks are the objects waited for to be satisfied, with the actions waiting for them
+	WaitHooks map[string]string `json:"waitHooks"`
+}
@@ -5,6 +5,8 @@ import (
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/openkruise/agents/api/v1alpha1"
 )
 
 // TestClaimMetrics_String tests the String() method of ClaimMetrics
@@ -295,3 +297,58 @@ func TestClaimMetrics_String_LongError(t *testing.T) {
 		t.Errorf("ClaimMetrics.String() should contain the error content")
 	}
 }
+
+func TestRegionRank(t *testing.T) {
+	tests := []struct {
+		name          string
+		policy        *v1alpha1.RegionPolicy
+		region        string
+		expectRank    int
+		expectAllowed bool
+	}{
+		{
+			name:          "no policy",
+			region:        "us-east",
+			expectAllowed: true,
+		},
+		{
+			name:          "no preferred regions",
+			policy:        &v1alpha1.RegionPolicy{Forbidden: []string{"eu-west"}},
+			region:        "us-east",
+			expectAllowed: true,
+		},
+		{
+			name:   "forbidden",
+			policy: &v1alpha1.RegionPolicy{Preferred: []string{"us-east"}, Forbidden: []string{"us-east"}},
+			region: "us-east",
+		},
+		{
+			name:          "second preferred",
+			policy:        &v1alpha1.RegionPolicy{Preferred: []string{"eu-west", "us-east"}},
+			region:        "us-east",
+			expectRank:    1,
+			expectAllowed: true,
+		},
+		{
+			name:          "not preferred with fallback",
+			policy:        &v1alpha1.RegionPolicy{Preferred: []string{"eu-west", "us-east"}, Fallback: v1alpha1.RegionFallbackAny},
+			region:        "",
+			expectRank:    2,
+			expectAllowed: true,
+		},
+		{
+			name:       "not preferred without fallback",
+			policy:     &v1alpha1.RegionPolicy{Preferred: []string{"eu-west"}, Fallback: v1alpha1.RegionFallbackNone},
+			region:     "us-east",
+			expectRank: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rank, allowed := RegionRank(tt.policy, tt.region)
+			if rank != tt.expectRank || allowed != tt.expectAllowed {
+				t.Errorf("RegionRank() = (%d, %v), want (%d, %v)", rank, allowed, tt.expectRank, tt.expectAllowed)
+			}
+		})
+	}
+}
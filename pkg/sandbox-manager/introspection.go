@@ -0,0 +1,97 @@
+package sandbox_manager
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"runtime/pprof"
+	"time"
+
+	"github.com/spf13/pflag"
+	"k8s.io/klog/v2"
+
+	"github.com/openkruise/agents/pkg/sandbox-manager/infra"
+)
+
+// IntrospectionOptions configures the local endpoint dumping the claiming state of the sandbox manager, to diagnose
+// claims stop progressing without errors.
+type IntrospectionOptions struct {
+	// Addr of the endpoint, which should be a local address like 127.0.0.1:6061. Empty disables it.
+	Addr string
+}
+
+// DefaultIntrospectionOptions is set by the command line flags and used by the sandbox managers of the process.
+var DefaultIntrospectionOptions = IntrospectionOptions{}
+
+// AddFlags registers the flags of the options.
+func (o *IntrospectionOptions) AddFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&o.Addr, "introspection-addr", o.Addr,
+		"Local address to serve the dump of in-flight claims, claim workers, locks and goroutines on, e.g. 127.0.0.1:6061. Empty disables it.")
+}
+
+// Introspection is a point-in-time dump of the sandbox manager
+type Introspection struct {
+	Time            time.Time `json:"time"`
+	Draining        bool      `json:"draining"`
+	InflightStreams int64     `json:"inflightStreams"`
+	infra.Introspection
+}
+
+func (m *SandboxManager) Introspect() Introspection {
+	return Introspection{
+		Time:            time.Now(),
+		Draining:        m.Draining(),
+		InflightStreams: m.proxy.InflightStreams(),
+		Introspection:   m.infra.Introspect(),
+	}
+}
+
+// introspectionHandler serves:
+//   - /debug/claims: the Introspection in JSON
+//   - /debug/goroutines: the stacks of all goroutines, to find where stuck claims are blocked
+func (m *SandboxManager) introspectionHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /debug/claims", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(m.Introspect()); err != nil {
+			klog.ErrorS(err, "Failed to write introspection")
+		}
+	})
+	mux.HandleFunc("GET /debug/goroutines", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		if err := pprof.Lookup("goroutine").WriteTo(w, 2); err != nil {
+			klog.ErrorS(err, "Failed to write goroutines")
+		}
+	})
+	return mux
+}
+
+// runIntrospection starts the introspection endpoint if enabled.
+func (m *SandboxManager) runIntrospection() {
+	if m.introspectionOptions.Addr == "" {
+		return
+	}
+	m.introspectionServer = &http.Server{
+		Addr:              m.introspectionOptions.Addr,
+		Handler:           m.introspectionHandler(),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	go func() {
+		klog.InfoS("Starting introspection server", "address", m.introspectionOptions.Addr)
+		if err := m.introspectionServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			klog.ErrorS(err, "Introspection server stopped")
+		}
+	}()
+}
+
+func (m *SandboxManager) stopIntrospection(ctx context.Context) {
+	if m.introspectionServer == nil {
+		return
+	}
+	if err := m.introspectionServer.Shutdown(ctx); err != nil {
+		klog.FromContext(ctx).Error(err, "failed to stop introspection server")
+	}
+}
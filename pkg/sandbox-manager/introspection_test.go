@@ -0,0 +1,57 @@
+package sandbox_manager
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openkruise/agents/pkg/sandbox-manager/config"
+	utils "github.com/openkruise/agents/pkg/utils/sandbox-manager"
+)
+
+func TestSandboxManager_IntrospectionHandler(t *testing.T) {
+	utils.InitLogOutput()
+	manager := setupTestManager(t, config.SandboxManagerOptions{MaxClaimWorkers: 3})
+	handler := manager.introspectionHandler()
+
+	tests := []struct {
+		name        string
+		path        string
+		contentType string
+		check       func(t *testing.T, body []byte)
+	}{
+		{
+			name:        "claims",
+			path:        "/debug/claims",
+			contentType: "application/json",
+			check: func(t *testing.T, body []byte) {
+				got := Introspection{}
+				require.NoError(t, json.Unmarshal(body, &got))
+				assert.Equal(t, 3, got.MaxClaimWorkers)
+				assert.False(t, got.Draining)
+				assert.NotZero(t, got.Time)
+			},
+		},
+		{
+			name:        "goroutines",
+			path:        "/debug/goroutines",
+			contentType: "text/plain; charset=utf-8",
+			check: func(t *testing.T, body []byte) {
+				assert.Contains(t, string(body), "TestSandboxManager_IntrospectionHandler")
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			require.Equal(t, http.StatusOK, recorder.Code)
+			assert.Equal(t, tt.contentType, recorder.Header().Get("Content-Type"))
+			tt.check(t, recorder.Body.Bytes())
+		})
+	}
+}
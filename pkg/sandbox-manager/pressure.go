@@ -0,0 +1,153 @@
+package sandbox_manager
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+	"github.com/openkruise/agents/pkg/sandbox-manager/errors"
+	"github.com/openkruise/agents/pkg/utils/sandboxstate"
+)
+
+// claimLatencyWindowSize is the number of recent claim latencies kept per template
+const claimLatencyWindowSize = 100
+
+// claimLatencies keeps the recent latencies of the sandboxes claimed by the sandbox manager, per template.
+type claimLatencies struct {
+	sync.Mutex
+	// templates is a sliding window of latencies per template, oldest first
+	templates map[string][]time.Duration
+}
+
+func newClaimLatencies() *claimLatencies {
+	return &claimLatencies{templates: make(map[string][]time.Duration)}
+}
+
+func (l *claimLatencies) observe(template string, latency time.Duration) {
+	l.Lock()
+	defer l.Unlock()
+	window := append(l.templates[template], latency)
+	if len(window) > claimLatencyWindowSize {
+		window = window[len(window)-claimLatencyWindowSize:]
+	}
+	l.templates[template] = window
+}
+
+// percentiles returns the nearest-rank p50 and p99 of the recent claim latencies of the template, false if none is
+// observed.
+func (l *claimLatencies) percentiles(template string) (p50, p99 time.Duration, ok bool) {
+	l.Lock()
+	sorted := slices.Clone(l.templates[template])
+	l.Unlock()
+	if len(sorted) == 0 {
+		return 0, 0, false
+	}
+	slices.Sort(sorted)
+	rank := func(p int) time.Duration {
+		return sorted[max((len(sorted)*p+99)/100, 1)-1]
+	}
+	return rank(50), rank(99), true
+}
+
+// PoolPressure is a point-in-time view of how hard the pool of a template is claimed, so that clients can back off or
+// claim from another region before submitting claims destined to time out.
+type PoolPressure struct {
+	Template string
+	// Available and Creating are the unclaimed sandboxes of the pool, Claimed is reported by its SandboxSets
+	Available int
+	Creating  int
+	Claimed   int32
+	// InflightClaims are the claims of the template being served by this instance, of which QueuedClaims have not
+	// locked a sandbox yet and compete for the available ones
+	InflightClaims int
+	QueuedClaims   int
+	// ClaimLatencyP50 and ClaimLatencyP99 are of the recent claims of the template served by this instance, zero if
+	// none is observed
+	ClaimLatencyP50 time.Duration
+	ClaimLatencyP99 time.Duration
+	// Saturated is true if the queued claims already take all available sandboxes, so that a new claim has to wait
+	// for sandboxes being created or fail
+	Saturated bool
+	// Regions break down the sandboxes of the pool by the regions of their SandboxSets, ordered by region. Empty if
+	// no SandboxSet of the template has a region.
+	Regions []RegionPressure
+}
+
+// RegionPressure is the share of a region in the PoolPressure
+type RegionPressure struct {
+	Region    string
+	Available int
+	Creating  int
+	Claimed   int32
+}
+
+// GetPoolPressure returns the pressure of the pool of the template.
+func (m *SandboxManager) GetPoolPressure(template string) (PoolPressure, error) {
+	if !m.infra.HasTemplate(template) {
+		return PoolPressure{}, errors.NewError(errors.ErrorNotFound, fmt.Sprintf("template %s not found", template))
+	}
+	cache := m.infra.GetCache()
+	sandboxes, err := cache.ListSandboxesInPool(template)
+	if err != nil {
+		return PoolPressure{}, errors.NewError(errors.ErrorInternal, fmt.Sprintf("failed to list sandboxes in pool: %v", err))
+	}
+	pressure := PoolPressure{Template: template}
+	regions := make(map[string]*RegionPressure)
+	regionOf := func(region string) *RegionPressure {
+		if region == "" {
+			return nil
+		}
+		if regions[region] == nil {
+			regions[region] = &RegionPressure{Region: region}
+		}
+		return regions[region]
+	}
+	now := time.Now()
+	for _, sbx := range sandboxes {
+		region := regionOf(sbx.Labels[agentsv1alpha1.LabelSandboxRegion])
+		switch state, _ := sandboxstate.Evaluate(sbx, now, sandboxstate.Policy{}); state {
+		case agentsv1alpha1.SandboxStateAvailable:
+			pressure.Available++
+			if region != nil {
+				region.Available++
+			}
+		case agentsv1alpha1.SandboxStateCreating:
+			pressure.Creating++
+			if region != nil {
+				region.Creating++
+			}
+		}
+	}
+	// SandboxSets of the same name in different namespaces serve the template together
+	sandboxSets, err := cache.ListSandboxSets("")
+	if err != nil {
+		return PoolPressure{}, errors.NewError(errors.ErrorInternal, fmt.Sprintf("failed to list sandboxsets: %v", err))
+	}
+	for _, sbs := range sandboxSets {
+		if sbs.Name != template {
+			continue
+		}
+		pressure.Claimed += sbs.Status.ClaimedReplicas
+		if region := regionOf(sbs.Labels[agentsv1alpha1.LabelSandboxRegion]); region != nil {
+			region.Claimed += sbs.Status.ClaimedReplicas
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(regions)) {
+		pressure.Regions = append(pressure.Regions, *regions[name])
+	}
+	for _, claim := range m.infra.Introspect().Claims {
+		if claim.Template != template {
+			continue
+		}
+		pressure.InflightClaims++
+		if claim.Sandbox == "" {
+			pressure.QueuedClaims++
+		}
+	}
+	pressure.ClaimLatencyP50, pressure.ClaimLatencyP99, _ = m.claimLatencies.percentiles(template)
+	pressure.Saturated = pressure.QueuedClaims >= pressure.Available
+	return pressure, nil
+}
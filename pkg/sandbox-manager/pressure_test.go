@@ -0,0 +1,157 @@
+package sandbox_manager
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+	"github.com/openkruise/agents/pkg/sandbox-manager/errors"
+	utils "github.com/openkruise/agents/pkg/utils/sandbox-manager"
+)
+
+func TestClaimLatencies(t *testing.T) {
+	tests := []struct {
+		name      string
+		latencies []time.Duration
+		expectOK  bool
+		expectP50 time.Duration
+		expectP99 time.Duration
+	}{
+		{
+			name: "none observed",
+		},
+		{
+			name:      "single latency",
+			latencies: []time.Duration{time.Second},
+			expectOK:  true,
+			expectP50: time.Second,
+			expectP99: time.Second,
+		},
+		{
+			name: "nearest rank",
+			latencies: func() []time.Duration {
+				var latencies []time.Duration
+				for i := 100; i >= 1; i-- {
+					latencies = append(latencies, time.Duration(i)*time.Millisecond)
+				}
+				return latencies
+			}(),
+			expectOK:  true,
+			expectP50: 50 * time.Millisecond,
+			expectP99: 99 * time.Millisecond,
+		},
+		{
+			name: "only recent latencies are kept",
+			latencies: func() []time.Duration {
+				latencies := []time.Duration{time.Hour}
+				for range claimLatencyWindowSize {
+					latencies = append(latencies, time.Second)
+				}
+				return latencies
+			}(),
+			expectOK:  true,
+			expectP50: time.Second,
+			expectP99: time.Second,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newClaimLatencies()
+			for _, latency := range tt.latencies {
+				l.observe("tpl", latency)
+			}
+			l.observe("other", time.Minute)
+			p50, p99, ok := l.percentiles("tpl")
+			assert.Equal(t, tt.expectOK, ok)
+			assert.Equal(t, tt.expectP50, p50)
+			assert.Equal(t, tt.expectP99, p99)
+		})
+	}
+}
+
+func TestSandboxManager_GetPoolPressure(t *testing.T) {
+	utils.InitLogOutput()
+	manager := setupTestManager(t)
+	client := manager.client.SandboxClient
+	template := "pressure"
+
+	_, err := manager.GetPoolPressure(template)
+	require.Error(t, err)
+	assert.Equal(t, errors.ErrorNotFound, errors.GetErrCode(err))
+
+	sbs := &agentsv1alpha1.SandboxSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      template,
+			Namespace: "default",
+			Labels:    map[string]string{agentsv1alpha1.LabelSandboxRegion: "us-east"},
+		},
+		Status: agentsv1alpha1.SandboxSetStatus{ClaimedReplicas: 3},
+	}
+	_, err = client.ApiV1alpha1().SandboxSets(sbs.Namespace).Create(t.Context(), sbs, metav1.CreateOptions{})
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		return manager.infra.HasTemplate(template)
+	}, time.Second, 5*time.Millisecond)
+
+	pressure, err := manager.GetPoolPressure(template)
+	require.NoError(t, err)
+	assert.True(t, pressure.Saturated, "an empty pool is saturated")
+
+	// the second sandbox is created before the pool is labeled with the region
+	for i, ready := range []bool{true, true, false} {
+		readyStatus := metav1.ConditionFalse
+		if ready {
+			readyStatus = metav1.ConditionTrue
+		}
+		labels := map[string]string{agentsv1alpha1.LabelSandboxTemplate: template}
+		if i != 1 {
+			labels[agentsv1alpha1.LabelSandboxRegion] = "us-east"
+		}
+		CreateSandboxWithStatus(t, client, &agentsv1alpha1.Sandbox{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              fmt.Sprintf("%s-%d", template, i),
+				Namespace:         "default",
+				Labels:            labels,
+				CreationTimestamp: metav1.Now(),
+				OwnerReferences:   GetSbsOwnerReference(),
+			},
+			Spec: agentsv1alpha1.SandboxSpec{
+				EmbeddedSandboxTemplate: agentsv1alpha1.EmbeddedSandboxTemplate{
+					Template: &corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "main", Image: "main"}}},
+					},
+				},
+			},
+			Status: agentsv1alpha1.SandboxStatus{
+				Phase: agentsv1alpha1.SandboxRunning,
+				Conditions: []metav1.Condition{{
+					Type:   string(agentsv1alpha1.SandboxConditionReady),
+					Status: readyStatus,
+				}},
+			},
+		})
+	}
+	manager.claimLatencies.observe(template, 200*time.Millisecond)
+
+	require.Eventually(t, func() bool {
+		pressure, err = manager.GetPoolPressure(template)
+		return err == nil && pressure.Available+pressure.Creating == 3
+	}, time.Second, 5*time.Millisecond)
+	assert.Equal(t, PoolPressure{
+		Template:        template,
+		Available:       2,
+		Creating:        1,
+		Claimed:         3,
+		ClaimLatencyP50: 200 * time.Millisecond,
+		ClaimLatencyP99: 200 * time.Millisecond,
+		Regions: []RegionPressure{
+			{Region: "us-east", Available: 1, Creating: 1, Claimed: 3},
+		},
+	}, pressure)
+}
@@ -0,0 +1,171 @@
+package sandbox_manager
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/pflag"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/openkruise/agents/api/v1alpha1"
+	"github.com/openkruise/agents/pkg/sandbox-manager/errors"
+	"github.com/openkruise/agents/pkg/sandbox-manager/infra"
+	"github.com/openkruise/agents/pkg/utils/sandboxutils"
+)
+
+// maxSecurityAlertSummaryLength is the max length of the alert output kept in the sandbox status
+const maxSecurityAlertSummaryLength = 1024
+
+// securityAlertSeverities are the severities of alerts from the lowest to the highest, following the priorities of
+// Falco and syslog.
+var securityAlertSeverities = []string{"Debug", "Informational", "Notice", "Warning", "Error", "Critical", "Alert", "Emergency"}
+
+// ParseSecurityAlertSeverity returns the canonical name and the rank of a severity, case-insensitively. Info is
+// accepted for Informational.
+func ParseSecurityAlertSeverity(severity string) (string, int, error) {
+	if strings.EqualFold(severity, "Info") {
+		severity = "Informational"
+	}
+	for rank, name := range securityAlertSeverities {
+		if strings.EqualFold(severity, name) {
+			return name, rank, nil
+		}
+	}
+	return "", 0, fmt.Errorf("invalid severity %q, must be one of %s", severity, strings.Join(securityAlertSeverities, ", "))
+}
+
+// SecurityAlertOptions configures the actions taken on the alerts of runtime security agents.
+type SecurityAlertOptions struct {
+	// PauseSeverity is the min severity of alerts to pause the sandbox. Empty disables pausing.
+	PauseSeverity string
+	// QuarantineSeverity is the min severity of alerts to quarantine the sandbox. Empty disables quarantining.
+	QuarantineSeverity string
+}
+
+// DefaultSecurityAlertOptions is set by the command line flags and used by the sandbox managers of the process.
+var DefaultSecurityAlertOptions = SecurityAlertOptions{}
+
+// AddFlags registers the flags of the options.
+func (o *SecurityAlertOptions) AddFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&o.PauseSeverity, "security-alert-pause-severity", o.PauseSeverity,
+		"Min severity of runtime security alerts to pause the sandbox, e.g. Error. Empty disables it.")
+	fs.StringVar(&o.QuarantineSeverity, "security-alert-quarantine-severity", o.QuarantineSeverity,
+		"Min severity of runtime security alerts to quarantine the sandbox, e.g. Critical. Empty disables it.")
+}
+
+// Validate checks the severities of the options.
+func (o *SecurityAlertOptions) Validate() error {
+	for flagName, severity := range map[string]string{
+		"--security-alert-pause-severity":      o.PauseSeverity,
+		"--security-alert-quarantine-severity": o.QuarantineSeverity,
+	} {
+		if severity == "" {
+			continue
+		}
+		if _, _, err := ParseSecurityAlertSeverity(severity); err != nil {
+			return fmt.Errorf("%s: %w", flagName, err)
+		}
+	}
+	return nil
+}
+
+// action returns the action configured for an alert of the severity rank
+func (o *SecurityAlertOptions) action(rank int) v1alpha1.SandboxSecurityAlertAction {
+	reaches := func(threshold string) bool {
+		if threshold == "" {
+			return false
+		}
+		_, thresholdRank, err := ParseSecurityAlertSeverity(threshold)
+		return err == nil && rank >= thresholdRank
+	}
+	if reaches(o.QuarantineSeverity) {
+		return v1alpha1.SandboxSecurityAlertActionQuarantine
+	}
+	if reaches(o.PauseSeverity) {
+		return v1alpha1.SandboxSecurityAlertActionPause
+	}
+	return v1alpha1.SandboxSecurityAlertActionNone
+}
+
+// SecurityAlert is an alert raised by a runtime security agent, like Falco or Tetragon, on a pod.
+type SecurityAlert struct {
+	Source    string
+	Namespace string
+	Pod       string
+	Rule      string
+	Severity  string
+	Output    string
+	Time      time.Time
+}
+
+// HandleSecurityAlert maps an alert on a pod to the sandbox of the pod, pauses or quarantines the sandbox according
+// to the severity of the alert, and records the alert summary in the sandbox status. It returns the action taken.
+func (m *SandboxManager) HandleSecurityAlert(ctx context.Context, alert SecurityAlert) (v1alpha1.SandboxSecurityAlertAction, error) {
+	none := v1alpha1.SandboxSecurityAlertActionNone
+	severity, rank, err := ParseSecurityAlertSeverity(alert.Severity)
+	if err != nil {
+		return none, errors.NewError(errors.ErrorBadRequest, err.Error())
+	}
+	// the pod of a sandbox is named after the sandbox, whose ID is not derived from its name with all the ID schemes
+	sandboxID := sandboxutils.GetSandboxID(&v1alpha1.Sandbox{ObjectMeta: metav1.ObjectMeta{Namespace: alert.Namespace, Name: alert.Pod}})
+	if obj, err := m.infra.GetCache().GetSandbox(alert.Namespace, alert.Pod); err == nil {
+		sandboxID = sandboxutils.GetSandboxID(obj)
+	}
+	log := klog.FromContext(ctx).WithValues("sandboxID", sandboxID, "source", alert.Source, "rule", alert.Rule, "severity", severity)
+	sbx, err := m.infra.GetClaimedSandbox(ctx, sandboxID)
+	if err != nil {
+		log.Error(err, "failed to get sandbox from cache")
+		return none, errors.NewError(errors.ErrorNotFound, fmt.Sprintf("sandbox %s not found", sandboxID))
+	}
+
+	action := m.securityAlertOptions.action(rank)
+	var actionErr error
+	switch action {
+	case v1alpha1.SandboxSecurityAlertActionQuarantine:
+		actionErr = sbx.Quarantine(ctx, infra.QuarantineOptions{
+			Reason: fmt.Sprintf("%s alert of %s rule %q", severity, alert.Source, alert.Rule),
+			Source: v1alpha1.SandboxQuarantineSourceRuntimeSecurity,
+		})
+	case v1alpha1.SandboxSecurityAlertActionPause:
+		if state, _ := sbx.GetState(); state != v1alpha1.SandboxStateRunning || sbx.GetQuarantine() != nil {
+			// nothing to pause
+			action = none
+			break
+		}
+		actionErr = m.PauseSandbox(ctx, sbx, infra.PauseOptions{})
+	}
+	if actionErr != nil {
+		log.Error(actionErr, "failed to take action on security alert", "action", action)
+		action = none
+	} else if action != none {
+		log.Info("action taken on security alert", "action", action)
+	}
+
+	summary := alert.Output
+	if len(summary) > maxSecurityAlertSummaryLength {
+		summary = summary[:maxSecurityAlertSummaryLength]
+	}
+	alertTime := alert.Time
+	if alertTime.IsZero() || alertTime.After(time.Now()) {
+		alertTime = time.Now()
+	}
+	record := v1alpha1.SandboxSecurityAlert{
+		Source:        alert.Source,
+		Rule:          alert.Rule,
+		Severity:      severity,
+		Summary:       summary,
+		Action:        action,
+		LastAlertTime: metav1.NewTime(alertTime),
+	}
+	if err = sbx.RecordSecurityAlert(ctx, record); err != nil {
+		log.Error(err, "failed to save security alert")
+		return action, errors.NewError(errors.ErrorInternal, fmt.Sprintf("failed to save security alert: %v", err))
+	}
+	if actionErr != nil {
+		return action, errors.NewError(errors.ErrorInternal, fmt.Sprintf("failed to take action on security alert: %v", actionErr))
+	}
+	return action, nil
+}
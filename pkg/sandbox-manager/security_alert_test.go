@@ -0,0 +1,205 @@
+package sandbox_manager
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8stesting "k8s.io/client-go/testing"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+	sandboxfake "github.com/openkruise/agents/client/clientset/versioned/fake"
+	"github.com/openkruise/agents/pkg/sandbox-manager/errors"
+	utils "github.com/openkruise/agents/pkg/utils/sandbox-manager"
+)
+
+func TestSecurityAlertOptions_Action(t *testing.T) {
+	opts := SecurityAlertOptions{PauseSeverity: "error", QuarantineSeverity: "Critical"}
+	require.NoError(t, opts.Validate())
+	tests := []struct {
+		severity string
+		expected agentsv1alpha1.SandboxSecurityAlertAction
+	}{
+		{severity: "info", expected: agentsv1alpha1.SandboxSecurityAlertActionNone},
+		{severity: "Warning", expected: agentsv1alpha1.SandboxSecurityAlertActionNone},
+		{severity: "ERROR", expected: agentsv1alpha1.SandboxSecurityAlertActionPause},
+		{severity: "Critical", expected: agentsv1alpha1.SandboxSecurityAlertActionQuarantine},
+		{severity: "Emergency", expected: agentsv1alpha1.SandboxSecurityAlertActionQuarantine},
+	}
+	for _, tt := range tests {
+		t.Run(tt.severity, func(t *testing.T) {
+			_, rank, err := ParseSecurityAlertSeverity(tt.severity)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, opts.action(rank))
+		})
+	}
+
+	assert.Equal(t, agentsv1alpha1.SandboxSecurityAlertActionNone, (&SecurityAlertOptions{}).action(7))
+	invalid := SecurityAlertOptions{QuarantineSeverity: "Fatal"}
+	assert.ErrorContains(t, invalid.Validate(), "--security-alert-quarantine-severity")
+}
+
+func TestSandboxManager_HandleSecurityAlert(t *testing.T) {
+	utils.InitLogOutput()
+	manager := setupTestManager(t)
+	manager.securityAlertOptions = SecurityAlertOptions{PauseSeverity: "Error", QuarantineSeverity: "Critical"}
+	client := manager.client.SandboxClient
+	fakeClient, ok := client.(*sandboxfake.Clientset)
+	require.True(t, ok, "SandboxClient must be *sandboxfake.Clientset")
+	// The action and the record of an alert are consecutive updates of the spec and the status. Like the API server,
+	// keep the spec on status updates and the status on spec updates, so that the stale cache of the sandbox does not
+	// revert the previous update.
+	fakeClient.PrependReactor("update", "sandboxes", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		update, ok := action.(k8stesting.UpdateAction)
+		if !ok {
+			return false, nil, nil
+		}
+		sbx := update.GetObject().(*agentsv1alpha1.Sandbox).DeepCopy()
+		gvr := agentsv1alpha1.SchemeGroupVersion.WithResource("sandboxes")
+		obj, err := fakeClient.Tracker().Get(gvr, sbx.Namespace, sbx.Name)
+		if err != nil {
+			return true, nil, err
+		}
+		current := obj.(*agentsv1alpha1.Sandbox)
+		if update.GetSubresource() == "status" {
+			sbx.Spec = current.Spec
+		} else {
+			sbx.Status = current.Status
+		}
+		return true, sbx, fakeClient.Tracker().Update(gvr, sbx, sbx.Namespace)
+	})
+
+	newSandbox := func(name string) *agentsv1alpha1.Sandbox {
+		return &agentsv1alpha1.Sandbox{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+				Labels: map[string]string{
+					agentsv1alpha1.LabelSandboxIsClaimed: "true",
+				},
+				Annotations: map[string]string{
+					agentsv1alpha1.AnnotationOwner: testUser,
+				},
+			},
+			Status: agentsv1alpha1.SandboxStatus{
+				Phase: agentsv1alpha1.SandboxRunning,
+				Conditions: []metav1.Condition{
+					{
+						Type:   string(agentsv1alpha1.SandboxConditionReady),
+						Status: metav1.ConditionTrue,
+					},
+					{
+						// Hack: make the sync pause success
+						Type:   string(agentsv1alpha1.SandboxConditionPaused),
+						Status: metav1.ConditionTrue,
+					},
+				},
+				PodInfo: agentsv1alpha1.PodInfo{
+					PodIP: "1.2.3.4",
+				},
+			},
+		}
+	}
+	for _, name := range []string{"warned", "paused", "quarantined"} {
+		CreateSandboxWithStatus(t, client, newSandbox(name))
+	}
+	require.Eventually(t, func() bool {
+		_, err := manager.infra.GetClaimedSandbox(t.Context(), "default--quarantined")
+		return err == nil
+	}, time.Second, 10*time.Millisecond)
+
+	tests := []struct {
+		name              string
+		alert             SecurityAlert
+		expectedErrorCode errors.ErrorCode
+		expectedAction    agentsv1alpha1.SandboxSecurityAlertAction
+		check             func(t *testing.T, sbx *agentsv1alpha1.Sandbox)
+	}{
+		{
+			name:              "invalid severity",
+			alert:             SecurityAlert{Source: "Falco", Namespace: "default", Pod: "warned", Severity: "Fatal"},
+			expectedErrorCode: errors.ErrorBadRequest,
+		},
+		{
+			name:              "not a sandbox",
+			alert:             SecurityAlert{Source: "Falco", Namespace: "default", Pod: "not-found", Severity: "Warning"},
+			expectedErrorCode: errors.ErrorNotFound,
+		},
+		{
+			name: "recorded only",
+			alert: SecurityAlert{Source: "Falco", Namespace: "default", Pod: "warned", Rule: "Read sensitive file",
+				Severity: "warning", Output: strings.Repeat("x", 2000)},
+			expectedAction: agentsv1alpha1.SandboxSecurityAlertActionNone,
+			check: func(t *testing.T, sbx *agentsv1alpha1.Sandbox) {
+				assert.False(t, sbx.Spec.Paused)
+				assert.Nil(t, sbx.Spec.Quarantine)
+				assert.Equal(t, "Warning", sbx.Status.SecurityAlert.Severity)
+				assert.Equal(t, "Read sensitive file", sbx.Status.SecurityAlert.Rule)
+				assert.Len(t, sbx.Status.SecurityAlert.Summary, maxSecurityAlertSummaryLength)
+			},
+		},
+		{
+			name:           "paused",
+			alert:          SecurityAlert{Source: "Tetragon", Namespace: "default", Pod: "paused", Severity: "Error"},
+			expectedAction: agentsv1alpha1.SandboxSecurityAlertActionPause,
+			check: func(t *testing.T, sbx *agentsv1alpha1.Sandbox) {
+				assert.True(t, sbx.Spec.Paused)
+				assert.Nil(t, sbx.Spec.Quarantine)
+			},
+		},
+		{
+			name:           "quarantined",
+			alert:          SecurityAlert{Source: "Falco", Namespace: "default", Pod: "quarantined", Rule: "Reverse shell", Severity: "Critical"},
+			expectedAction: agentsv1alpha1.SandboxSecurityAlertActionQuarantine,
+			check: func(t *testing.T, sbx *agentsv1alpha1.Sandbox) {
+				assert.False(t, sbx.Spec.Paused)
+				require.NotNil(t, sbx.Spec.Quarantine)
+				assert.Equal(t, agentsv1alpha1.SandboxQuarantineSourceRuntimeSecurity, sbx.Spec.Quarantine.Source)
+				assert.Equal(t, `Critical alert of Falco rule "Reverse shell"`, sbx.Spec.Quarantine.Reason)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			timeout := 5 * time.Second
+			if tt.expectedErrorCode != "" {
+				timeout = 100 * time.Millisecond
+			}
+			ctx, cancel := context.WithTimeout(t.Context(), timeout)
+			defer cancel()
+			action, err := manager.HandleSecurityAlert(ctx, tt.alert)
+			if tt.expectedErrorCode != "" {
+				require.Error(t, err)
+				assert.Equal(t, tt.expectedErrorCode, errors.GetErrCode(err))
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedAction, action)
+			sbx, err := client.ApiV1alpha1().Sandboxes("default").Get(t.Context(), tt.alert.Pod, metav1.GetOptions{})
+			require.NoError(t, err)
+			require.NotNil(t, sbx.Status.SecurityAlert)
+			assert.Equal(t, int32(1), sbx.Status.SecurityAlert.Count)
+			assert.Equal(t, tt.alert.Source, sbx.Status.SecurityAlert.Source)
+			assert.Equal(t, tt.expectedAction, sbx.Status.SecurityAlert.Action)
+			assert.WithinDuration(t, time.Now(), sbx.Status.SecurityAlert.LastAlertTime.Time, 5*time.Second)
+			tt.check(t, sbx)
+		})
+	}
+
+	// alerts are counted
+	require.Eventually(t, func() bool {
+		sbx, err := manager.infra.GetClaimedSandbox(t.Context(), "default--warned")
+		return err == nil && sbx.GetSecurityAlert() != nil
+	}, time.Second, 10*time.Millisecond)
+	_, err := manager.HandleSecurityAlert(t.Context(), SecurityAlert{Source: "Falco", Namespace: "default", Pod: "warned", Severity: "Notice"})
+	require.NoError(t, err)
+	sbx, err := client.ApiV1alpha1().Sandboxes("default").Get(t.Context(), "warned", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), sbx.Status.SecurityAlert.Count)
+	assert.Equal(t, "Notice", sbx.Status.SecurityAlert.Severity)
+}
@@ -0,0 +1,74 @@
+package sandbox_manager
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/spf13/pflag"
+	"k8s.io/klog/v2"
+
+	"github.com/openkruise/agents/pkg/sandbox-manager/consts"
+	"github.com/openkruise/agents/pkg/sandbox-manager/errors"
+)
+
+// errShuttingDown is returned for new claims and clones once the sandbox manager is draining
+var errShuttingDown = errors.NewError(errors.ErrorUnavailable, "sandbox manager is shutting down")
+
+// ShutdownOptions configures the shutdown sequence of the sandbox manager.
+type ShutdownOptions struct {
+	// GracePeriod is the max time to wait for in-flight proxied streams, e.g. exec and attach sessions of agents, to
+	// finish before they are closed.
+	GracePeriod time.Duration
+}
+
+// DefaultShutdownOptions is set by the command line flags and used by the sandbox managers of the process.
+var DefaultShutdownOptions = ShutdownOptions{
+	GracePeriod: 60 * time.Second,
+}
+
+// AddFlags registers the flags of the options.
+func (o *ShutdownOptions) AddFlags(fs *pflag.FlagSet) {
+	fs.DurationVar(&o.GracePeriod, "shutdown-grace-period", o.GracePeriod,
+		"Max time to wait for in-flight proxied streams to finish on shutdown before closing them.")
+}
+
+// Validate checks the grace period leaves time for the rest of the shutdown.
+func (o *ShutdownOptions) Validate() error {
+	if o.GracePeriod < 0 || o.GracePeriod >= consts.ShutdownTimeout {
+		return fmt.Errorf("--shutdown-grace-period must be non-negative and less than %s", consts.ShutdownTimeout)
+	}
+	return nil
+}
+
+// Draining returns whether the sandbox manager is shutting down and rejects new claims.
+func (m *SandboxManager) Draining() bool {
+	return m.draining.Load()
+}
+
+// Stop shuts the sandbox manager down without severing the live sessions of agents:
+//  1. new claims and clones are rejected, and the health checks report not serving, so that traffic moves to the
+//     other instances
+//  2. in-flight proxied streams are waited for up to the grace period, after which they are closed
+//  3. the infra is stopped, which flushes the snapshot of the sandbox cache
+//  4. the instance leaves the memberlist, so that peers stop syncing routes to it
+func (m *SandboxManager) Stop(ctx context.Context) {
+	log := klog.FromContext(ctx)
+	m.draining.Store(true)
+	log.Info("sandbox manager is draining", "inflightStreams", m.proxy.InflightStreams(),
+		"gracePeriod", m.shutdownOptions.GracePeriod)
+
+	drainCtx, cancel := context.WithTimeout(ctx, m.shutdownOptions.GracePeriod)
+	m.proxy.Stop(drainCtx)
+	cancel()
+	log.Info("proxy drained")
+
+	m.infra.Stop(ctx)
+	if m.peersManager != nil {
+		if err := m.peersManager.Stop(); err != nil {
+			log.Error(err, "failed to stop peers manager")
+		}
+	}
+	m.stopIntrospection(ctx)
+	log.Info("sandbox manager stopped")
+}
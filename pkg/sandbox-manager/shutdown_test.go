@@ -0,0 +1,56 @@
+package sandbox_manager
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openkruise/agents/pkg/sandbox-manager/errors"
+	"github.com/openkruise/agents/pkg/sandbox-manager/infra"
+	utils "github.com/openkruise/agents/pkg/utils/sandbox-manager"
+)
+
+func TestShutdownOptions_Validate(t *testing.T) {
+	tests := []struct {
+		name        string
+		gracePeriod time.Duration
+		expectError bool
+	}{
+		{name: "default", gracePeriod: DefaultShutdownOptions.GracePeriod},
+		{name: "no grace period", gracePeriod: 0},
+		{name: "negative", gracePeriod: -time.Second, expectError: true},
+		{name: "longer than shutdown timeout", gracePeriod: 2 * time.Minute, expectError: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := ShutdownOptions{GracePeriod: tt.gracePeriod}
+			err := opts.Validate()
+			if tt.expectError {
+				assert.ErrorContains(t, err, "--shutdown-grace-period")
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestSandboxManager_Stop(t *testing.T) {
+	utils.InitLogOutput()
+	manager := setupTestManager(t)
+	manager.shutdownOptions.GracePeriod = 100 * time.Millisecond
+	require.False(t, manager.Draining())
+
+	start := time.Now()
+	manager.Stop(context.Background())
+	assert.Less(t, time.Since(start), 5*time.Second)
+	assert.True(t, manager.Draining())
+
+	// new claims and clones are rejected once draining
+	_, err := manager.ClaimSandbox(context.Background(), infra.ClaimSandboxOptions{User: testUser, Template: "test-template"})
+	assert.Equal(t, errors.ErrorUnavailable, errors.GetErrCode(err))
+	_, err = manager.CloneSandbox(context.Background(), infra.CloneSandboxOptions{User: testUser, CheckPointID: "checkpoint"})
+	assert.Equal(t, errors.ErrorUnavailable, errors.GetErrCode(err))
+}
@@ -0,0 +1,65 @@
+package e2b
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"k8s.io/klog/v2"
+
+	sandbox_manager "github.com/openkruise/agents/pkg/sandbox-manager"
+	"github.com/openkruise/agents/pkg/sandbox-manager/errors"
+	"github.com/openkruise/agents/pkg/servers/e2b/models"
+	"github.com/openkruise/agents/pkg/servers/web"
+)
+
+// BulkRelease releases one page of sandboxes, or deletes one page of SandboxClaims, matching a label selector. Clients
+// release all of them by calling again with the returned continue token. It is only allowed to the admin.
+func (sc *Controller) BulkRelease(r *http.Request) (web.ApiResponse[*models.BulkReleaseResponse], *web.ApiError) {
+	ctx := r.Context()
+	request := models.BulkReleaseRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		return web.ApiResponse[*models.BulkReleaseResponse]{}, &web.ApiError{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("Failed to parse request body: %v", err),
+		}
+	}
+	result, err := sc.manager.BulkRelease(ctx, sandbox_manager.BulkReleaseOptions{
+		Resource:  sandbox_manager.BulkResource(request.Resource),
+		Namespace: request.Namespace,
+		Selector:  request.Selector,
+		Limit:     request.Limit,
+		Continue:  request.Continue,
+		DryRun:    request.DryRun,
+		QPS:       request.QPS,
+	})
+	if err != nil {
+		code := http.StatusInternalServerError
+		if errors.GetErrCode(err) == errors.ErrorBadRequest {
+			code = http.StatusBadRequest
+		}
+		return web.ApiResponse[*models.BulkReleaseResponse]{}, &web.ApiError{
+			Code:    code,
+			Message: err.Error(),
+		}
+	}
+	resp := &models.BulkReleaseResponse{
+		Matched:            result.Matched,
+		Released:           result.Released,
+		Failed:             make([]models.BulkReleaseFailure, 0, len(result.Failed)),
+		Continue:           result.Continue,
+		RemainingItemCount: result.RemainingItemCount,
+	}
+	if resp.Released == nil {
+		resp.Released = []string{}
+	}
+	for _, failure := range result.Failed {
+		resp.Failed = append(resp.Failed, models.BulkReleaseFailure{Name: failure.Name, Message: failure.Message})
+	}
+	klog.FromContext(ctx).Info("bulk release handled", "resource", request.Resource, "selector", request.Selector,
+		"dryRun", request.DryRun, "matched", resp.Matched, "released", len(resp.Released), "failed", len(resp.Failed))
+	return web.ApiResponse[*models.BulkReleaseResponse]{
+		Code: http.StatusOK,
+		Body: resp,
+	}, nil
+}
@@ -0,0 +1,93 @@
+package e2b
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/openkruise/agents/api/v1alpha1"
+	"github.com/openkruise/agents/pkg/servers/e2b/keys"
+	"github.com/openkruise/agents/pkg/servers/e2b/models"
+)
+
+func TestBulkRelease(t *testing.T) {
+	controller, client, teardown := Setup(t)
+	defer teardown()
+	user := &models.CreatedTeamAPIKey{
+		ID:   keys.AdminKeyID,
+		Key:  InitKey,
+		Name: "admin",
+	}
+	templateName := "test-bulk-release"
+	// the claimed sandbox is deleted by the test, so the pool is not cleaned up
+	_ = CreateSandboxPool(t, controller, templateName, 2)
+
+	createResp, apiErr := controller.CreateSandbox(NewRequest(t, nil, models.NewSandboxRequest{
+		TemplateID: templateName,
+		Timeout:    300,
+		Metadata: map[string]string{
+			models.ExtensionKeySkipInitRuntime: v1alpha1.True,
+		},
+	}, nil, user))
+	require.Nil(t, apiErr)
+	sbx := GetSandbox(t, createResp.Body.SandboxID, client.SandboxClient)
+	selector := v1alpha1.LabelSandboxTemplate + "=" + templateName
+
+	tests := []struct {
+		name           string
+		body           any
+		expectCode     int
+		expectMatched  int
+		expectReleased []string
+	}{
+		{
+			name:       "invalid body",
+			body:       "not-an-object",
+			expectCode: http.StatusBadRequest,
+		},
+		{
+			name:       "missing selector",
+			body:       models.BulkReleaseRequest{Resource: "sandboxes"},
+			expectCode: http.StatusBadRequest,
+		},
+		{
+			name:          "dry run",
+			body:          models.BulkReleaseRequest{Resource: "sandboxes", Namespace: sbx.Namespace, Selector: selector, DryRun: true},
+			expectCode:    http.StatusOK,
+			expectMatched: 1,
+		},
+		{
+			name:           "released",
+			body:           models.BulkReleaseRequest{Resource: "sandboxes", Namespace: sbx.Namespace, Selector: selector, QPS: 100},
+			expectCode:     http.StatusOK,
+			expectMatched:  1,
+			expectReleased: []string{createResp.Body.SandboxID},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, apiErr := controller.BulkRelease(NewRequest(t, nil, tt.body, nil, user))
+			if tt.expectCode != http.StatusOK {
+				require.NotNil(t, apiErr)
+				assert.Equal(t, tt.expectCode, apiErr.Code)
+				return
+			}
+			require.Nil(t, apiErr)
+			assert.Equal(t, tt.expectMatched, resp.Body.Matched)
+			assert.ElementsMatch(t, tt.expectReleased, resp.Body.Released)
+			assert.Empty(t, resp.Body.Failed)
+			assert.Empty(t, resp.Body.Continue)
+		})
+	}
+
+	// only the claimed sandbox is released, the one in the pool is kept
+	_, err := client.SandboxClient.ApiV1alpha1().Sandboxes(sbx.Namespace).Get(t.Context(), sbx.Name, metav1.GetOptions{})
+	assert.True(t, apierrors.IsNotFound(err))
+	pool, err := client.SandboxClient.ApiV1alpha1().Sandboxes(sbx.Namespace).List(t.Context(), metav1.ListOptions{LabelSelector: selector})
+	require.NoError(t, err)
+	assert.Len(t, pool.Items, 1)
+}
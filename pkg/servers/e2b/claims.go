@@ -0,0 +1,119 @@
+package e2b
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/klog/v2"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+	"github.com/openkruise/agents/pkg/sandbox-manager/errors"
+	"github.com/openkruise/agents/pkg/sandbox-manager/infra"
+	"github.com/openkruise/agents/pkg/servers/e2b/models"
+	"github.com/openkruise/agents/pkg/servers/web"
+)
+
+// WaitForClaim long-polls a SandboxClaim until it is completed or the timeout (in seconds) is reached, and returns the
+// sandboxes bound to it once completed. A claim that is still claiming is returned with completed=false, so that clients
+// can simply call again.
+func (sc *Controller) WaitForClaim(r *http.Request) (web.ApiResponse[*models.SandboxClaim], *web.ApiError) {
+	namespace, name := r.PathValue("namespace"), r.PathValue("claimName")
+	log := klog.FromContext(r.Context()).WithValues("claim", klog.KRef(namespace, name))
+
+	timeoutSeconds := models.DefaultWaitForClaimTimeoutSeconds
+	if value := r.URL.Query().Get("timeout"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 0 || parsed > models.MaxWaitForClaimTimeoutSeconds {
+			return web.ApiResponse[*models.SandboxClaim]{}, &web.ApiError{
+				Code:    http.StatusBadRequest,
+				Message: fmt.Sprintf("Invalid timeout: %v, must be between 0 and %d", value, models.MaxWaitForClaimTimeoutSeconds),
+			}
+		}
+		timeoutSeconds = parsed
+	}
+
+	claim, sandboxes, err := sc.manager.WaitForClaim(r.Context(), namespace, name, time.Duration(timeoutSeconds)*time.Second)
+	if err != nil {
+		code := http.StatusInternalServerError
+		if errors.GetErrCode(err) == errors.ErrorNotFound {
+			code = http.StatusNotFound
+		}
+		return web.ApiResponse[*models.SandboxClaim]{}, &web.ApiError{
+			Code:    code,
+			Message: err.Error(),
+		}
+	}
+
+	resp := sc.convertToSandboxClaim(claim, sandboxes)
+	log.Info("sandboxclaim waited", "completed", resp.Completed, "sandboxes", len(resp.Sandboxes))
+	return web.ApiResponse[*models.SandboxClaim]{
+		Body: resp,
+	}, nil
+}
+
+// DecideClaimApproval approves or rejects a SandboxClaim requiring approval. The decision is final.
+func (sc *Controller) DecideClaimApproval(r *http.Request) (web.ApiResponse[*models.SandboxClaim], *web.ApiError) {
+	namespace, name := r.PathValue("namespace"), r.PathValue("claimName")
+	log := klog.FromContext(r.Context()).WithValues("claim", klog.KRef(namespace, name))
+
+	request := models.SandboxClaimApprovalRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		return web.ApiResponse[*models.SandboxClaim]{}, &web.ApiError{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("Failed to parse request body: %v", err),
+		}
+	}
+	claim, err := sc.manager.DecideClaimApproval(r.Context(), namespace, name, request.Approved)
+	if err != nil {
+		code := http.StatusInternalServerError
+		switch errors.GetErrCode(err) {
+		case errors.ErrorNotFound:
+			code = http.StatusNotFound
+		case errors.ErrorBadRequest:
+			code = http.StatusBadRequest
+		case errors.ErrorConflict:
+			code = http.StatusConflict
+		}
+		return web.ApiResponse[*models.SandboxClaim]{}, &web.ApiError{
+			Code:    code,
+			Message: err.Error(),
+		}
+	}
+	log.Info("sandboxclaim approval decided", "approved", request.Approved)
+	return web.ApiResponse[*models.SandboxClaim]{
+		Body: sc.convertToSandboxClaim(claim, nil),
+	}, nil
+}
+
+func (sc *Controller) convertToSandboxClaim(claim *agentsv1alpha1.SandboxClaim, sandboxes []infra.Sandbox) *models.SandboxClaim {
+	resp := &models.SandboxClaim{
+		Namespace:       claim.Namespace,
+		Name:            claim.Name,
+		Phase:           string(claim.Status.Phase),
+		Completed:       claim.Status.Phase == agentsv1alpha1.SandboxClaimPhaseCompleted || claim.Status.Phase == agentsv1alpha1.SandboxClaimPhaseFailed,
+		TimedOut:        meta.IsStatusConditionTrue(claim.Status.Conditions, string(agentsv1alpha1.SandboxClaimConditionTimedOut)),
+		Message:         claim.Status.Message,
+		DesiredReplicas: 1, // default replicas of SandboxClaim
+		ClaimedReplicas: claim.Status.ClaimedReplicas,
+		Sandboxes:       make([]*models.Sandbox, 0, len(sandboxes)),
+	}
+	if claim.Spec.Replicas != nil {
+		resp.DesiredReplicas = *claim.Spec.Replicas
+	}
+	if eta := claim.Status.ETA; eta != nil {
+		resp.ETA = &models.SandboxClaimETA{
+			EstimatedTime:           eta.EstimatedTime.Time,
+			CreatingReplicas:        eta.CreatingReplicas,
+			WarmupLatencyP50Seconds: int(eta.WarmupLatencyP50.Seconds()),
+			WarmupLatencyP90Seconds: int(eta.WarmupLatencyP90.Seconds()),
+		}
+	}
+	for _, sbx := range sandboxes {
+		resp.Sandboxes = append(resp.Sandboxes, sc.convertToE2BSandbox(sbx, sbx.GetAccessToken()))
+	}
+	return resp
+}
@@ -0,0 +1,268 @@
+package e2b
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+	"github.com/openkruise/agents/pkg/servers/e2b/models"
+)
+
+func TestWaitForClaim(t *testing.T) {
+	controller, client, teardown := Setup(t)
+	defer teardown()
+
+	newClaim := func(name string, phase agentsv1alpha1.SandboxClaimPhase) *agentsv1alpha1.SandboxClaim {
+		return &agentsv1alpha1.SandboxClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: Namespace,
+				UID:       types.UID("uid-" + name),
+			},
+			Spec: agentsv1alpha1.SandboxClaimSpec{
+				TemplateName: "test-template",
+				Replicas:     ptr.To[int32](2),
+			},
+			Status: agentsv1alpha1.SandboxClaimStatus{
+				Phase:           phase,
+				ClaimedReplicas: 1,
+			},
+		}
+	}
+	eta := &agentsv1alpha1.SandboxClaimETA{
+		EstimatedTime:    metav1.NewTime(time.Now().Add(time.Minute).Truncate(time.Second)),
+		CreatingReplicas: 1,
+		WarmupLatencyP50: metav1.Duration{Duration: 20 * time.Second},
+		WarmupLatencyP90: metav1.Duration{Duration: time.Minute},
+	}
+	claimingWithETA := newClaim("claiming", agentsv1alpha1.SandboxClaimPhaseClaiming)
+	claimingWithETA.Status.ETA = eta
+	// completeLater completes the claim while it is waited
+	completeLater := func(t *testing.T, claim *agentsv1alpha1.SandboxClaim) {
+		go func() {
+			time.Sleep(100 * time.Millisecond)
+			claim = claim.DeepCopy()
+			claim.Status.Phase = agentsv1alpha1.SandboxClaimPhaseCompleted
+			claim.Status.Conditions = []metav1.Condition{{
+				Type:               string(agentsv1alpha1.SandboxClaimConditionTimedOut),
+				Status:             metav1.ConditionTrue,
+				Reason:             "ClaimTimeoutReached",
+				LastTransitionTime: metav1.Now(),
+			}}
+			_, err := client.SandboxClient.ApiV1alpha1().SandboxClaims(Namespace).UpdateStatus(t.Context(), claim, metav1.UpdateOptions{})
+			assert.NoError(t, err)
+		}()
+	}
+
+	tests := []struct {
+		name            string
+		claim           *agentsv1alpha1.SandboxClaim
+		claimName       string
+		timeout         string
+		setup           func(t *testing.T, claim *agentsv1alpha1.SandboxClaim)
+		expectStatus    int
+		expectCompleted bool
+		expectTimedOut  bool
+		expectSandboxes int
+	}{
+		{
+			name:            "completed claim returns bound sandboxes",
+			claim:           newClaim("completed", agentsv1alpha1.SandboxClaimPhaseCompleted),
+			expectStatus:    http.StatusOK,
+			expectCompleted: true,
+			expectSandboxes: 1,
+		},
+		{
+			name:         "claiming claim returns after timeout with ETA",
+			claim:        claimingWithETA,
+			timeout:      "0",
+			expectStatus: http.StatusOK,
+		},
+		{
+			name:            "claim completed while waiting",
+			claim:           newClaim("waiting", agentsv1alpha1.SandboxClaimPhaseClaiming),
+			timeout:         "10",
+			setup:           completeLater,
+			expectStatus:    http.StatusOK,
+			expectCompleted: true,
+			expectTimedOut:  true,
+			expectSandboxes: 1,
+		},
+		{
+			name:         "claim not found",
+			claimName:    "not-exist",
+			expectStatus: http.StatusNotFound,
+		},
+		{
+			name:         "invalid timeout",
+			claimName:    "not-exist",
+			timeout:      "3600",
+			expectStatus: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claimName := tt.claimName
+			if tt.claim != nil {
+				claimName = tt.claim.Name
+				_, err := client.SandboxClient.ApiV1alpha1().SandboxClaims(Namespace).Create(t.Context(), tt.claim, metav1.CreateOptions{})
+				require.NoError(t, err)
+				CreateSandboxWithStatus(t, client.SandboxClient, &agentsv1alpha1.Sandbox{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:        "sbx-" + claimName,
+						Namespace:   Namespace,
+						Annotations: map[string]string{agentsv1alpha1.AnnotationOwner: string(tt.claim.UID)},
+						Labels: map[string]string{
+							agentsv1alpha1.LabelSandboxTemplate:  "test-template",
+							agentsv1alpha1.LabelSandboxIsClaimed: "true",
+						},
+					},
+					Status: agentsv1alpha1.SandboxStatus{Phase: agentsv1alpha1.SandboxRunning},
+				})
+				time.Sleep(50 * time.Millisecond) // wait for cache sync
+			}
+			if tt.setup != nil {
+				tt.setup(t, tt.claim)
+			}
+			var query map[string]string
+			if tt.timeout != "" {
+				query = map[string]string{"timeout": tt.timeout}
+			}
+			req := NewRequest(t, query, nil, map[string]string{
+				"namespace": Namespace,
+				"claimName": claimName,
+			}, AnonymousUser)
+
+			resp, apiErr := controller.WaitForClaim(req)
+			if tt.expectStatus != http.StatusOK {
+				require.NotNil(t, apiErr)
+				assert.Equal(t, tt.expectStatus, apiErr.Code)
+				return
+			}
+			require.Nil(t, apiErr)
+			assert.Equal(t, claimName, resp.Body.Name)
+			assert.Equal(t, tt.expectCompleted, resp.Body.Completed)
+			assert.Equal(t, tt.expectTimedOut, resp.Body.TimedOut)
+			assert.Equal(t, int32(2), resp.Body.DesiredReplicas)
+			assert.Len(t, resp.Body.Sandboxes, tt.expectSandboxes)
+			if tt.claim.Status.ETA == nil {
+				assert.Nil(t, resp.Body.ETA)
+			} else {
+				require.NotNil(t, resp.Body.ETA)
+				assert.True(t, eta.EstimatedTime.Time.Equal(resp.Body.ETA.EstimatedTime))
+				assert.Equal(t, int32(1), resp.Body.ETA.CreatingReplicas)
+				assert.Equal(t, 20, resp.Body.ETA.WarmupLatencyP50Seconds)
+				assert.Equal(t, 60, resp.Body.ETA.WarmupLatencyP90Seconds)
+			}
+		})
+	}
+}
+
+func TestDecideClaimApproval(t *testing.T) {
+	controller, client, teardown := Setup(t)
+	defer teardown()
+
+	newClaim := func(name string, requiresApproval bool, decision string) *agentsv1alpha1.SandboxClaim {
+		claim := &agentsv1alpha1.SandboxClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: Namespace,
+			},
+			Spec: agentsv1alpha1.SandboxClaimSpec{
+				TemplateName:     "test-template",
+				RequiresApproval: requiresApproval,
+			},
+			Status: agentsv1alpha1.SandboxClaimStatus{
+				Phase: agentsv1alpha1.SandboxClaimPhasePendingApproval,
+			},
+		}
+		if decision != "" {
+			claim.Annotations = map[string]string{agentsv1alpha1.AnnotationClaimApproval: decision}
+		}
+		return claim
+	}
+
+	tests := []struct {
+		name           string
+		claim          *agentsv1alpha1.SandboxClaim
+		claimName      string
+		approved       bool
+		expectStatus   int
+		expectDecision string
+	}{
+		{
+			name:           "approve",
+			claim:          newClaim("approve", true, ""),
+			approved:       true,
+			expectStatus:   http.StatusOK,
+			expectDecision: agentsv1alpha1.SandboxClaimApprovalApproved,
+		},
+		{
+			name:           "reject",
+			claim:          newClaim("reject", true, ""),
+			expectStatus:   http.StatusOK,
+			expectDecision: agentsv1alpha1.SandboxClaimApprovalRejected,
+		},
+		{
+			name:           "same decision again",
+			claim:          newClaim("again", true, agentsv1alpha1.SandboxClaimApprovalApproved),
+			approved:       true,
+			expectStatus:   http.StatusOK,
+			expectDecision: agentsv1alpha1.SandboxClaimApprovalApproved,
+		},
+		{
+			name:           "other decision",
+			claim:          newClaim("other", true, agentsv1alpha1.SandboxClaimApprovalApproved),
+			expectStatus:   http.StatusConflict,
+			expectDecision: agentsv1alpha1.SandboxClaimApprovalApproved,
+		},
+		{
+			name:         "approval not required",
+			claim:        newClaim("not-required", false, ""),
+			approved:     true,
+			expectStatus: http.StatusBadRequest,
+		},
+		{
+			name:         "claim not found",
+			claimName:    "not-exist",
+			approved:     true,
+			expectStatus: http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claimName := tt.claimName
+			if tt.claim != nil {
+				claimName = tt.claim.Name
+				_, err := client.SandboxClient.ApiV1alpha1().SandboxClaims(Namespace).Create(t.Context(), tt.claim, metav1.CreateOptions{})
+				require.NoError(t, err)
+				time.Sleep(50 * time.Millisecond) // wait for cache sync
+			}
+			req := NewRequest(t, nil, models.SandboxClaimApprovalRequest{Approved: tt.approved}, map[string]string{
+				"namespace": Namespace,
+				"claimName": claimName,
+			}, AnonymousUser)
+
+			resp, apiErr := controller.DecideClaimApproval(req)
+			if tt.expectStatus != http.StatusOK {
+				require.NotNil(t, apiErr)
+				assert.Equal(t, tt.expectStatus, apiErr.Code)
+			} else {
+				require.Nil(t, apiErr)
+				assert.Equal(t, claimName, resp.Body.Name)
+			}
+			if tt.claim != nil {
+				claim, err := client.SandboxClient.ApiV1alpha1().SandboxClaims(Namespace).Get(t.Context(), claimName, metav1.GetOptions{})
+				require.NoError(t, err)
+				assert.Equal(t, tt.expectDecision, claim.Annotations[agentsv1alpha1.AnnotationClaimApproval])
+			}
+		})
+	}
+}
@@ -37,6 +37,7 @@ type Controller struct {
 	sandboxLabelSelector  string
 	sandboxNamespace      string
 	memberlistBindPort    int
+	heartbeatInterval     time.Duration
 
 	// fields
 	mux             *http.ServeMux
@@ -53,7 +54,7 @@ type Controller struct {
 
 // NewController creates a new E2B Controller
 func NewController(domain, adminKey string, sysNs, sandboxNamespace, sandboxLabelSelector string, maxTimeout, maxClaimWorkers, maxCreateQPS int, extProcMaxConcurrency uint32,
-	port int, enableAuth bool, memberlistBindPort int, clientSet *clients.ClientSet) *Controller {
+	port int, enableAuth bool, memberlistBindPort int, heartbeatInterval time.Duration, clientSet *clients.ClientSet) *Controller {
 	sc := &Controller{
 		mux:                   http.NewServeMux(),
 		client:                clientSet,
@@ -68,6 +69,7 @@ func NewController(domain, adminKey string, sysNs, sandboxNamespace, sandboxLabe
 		maxCreateQPS:          maxCreateQPS,
 		extProcMaxConcurrency: extProcMaxConcurrency,
 		memberlistBindPort:    memberlistBindPort,
+		heartbeatInterval:     heartbeatInterval,
 	}
 
 	sc.server = &http.Server{
@@ -93,13 +95,14 @@ func (sc *Controller) Init() error {
 	log.Info("init controller")
 	adapter := adapters.DefaultAdapterFactory(sc.port)
 	sandboxManager, err := sandbox_manager.NewSandboxManager(sc.client, adapter, config.SandboxManagerOptions{
-		SystemNamespace:       sc.systemNamespace,
-		SandboxNamespace:      sc.sandboxNamespace,
-		SandboxLabelSelector:  sc.sandboxLabelSelector,
-		MaxClaimWorkers:       sc.maxClaimWorkers,
-		ExtProcMaxConcurrency: sc.extProcMaxConcurrency,
-		MaxCreateQPS:          sc.maxCreateQPS,
-		MemberlistBindPort:    sc.memberlistBindPort,
+		SystemNamespace:          sc.systemNamespace,
+		SandboxNamespace:         sc.sandboxNamespace,
+		SandboxLabelSelector:     sc.sandboxLabelSelector,
+		MaxClaimWorkers:          sc.maxClaimWorkers,
+		ExtProcMaxConcurrency:    sc.extProcMaxConcurrency,
+		MaxCreateQPS:             sc.maxCreateQPS,
+		MemberlistBindPort:       sc.memberlistBindPort,
+		HeartbeatPersistInterval: sc.heartbeatInterval,
 	})
 	if err != nil {
 		return err
@@ -89,7 +89,7 @@ func Setup(t *testing.T) (*Controller, *clients.ClientSet, func()) {
 	assert.NoError(t, err)
 
 	controller := NewController("example.com", InitKey, namespace, "", "", models.DefaultMaxTimeout, 10,
-		0, 0, TestServerPort, true, config.DefaultMemberlistBindPort, clientSet)
+		0, 0, TestServerPort, true, config.DefaultMemberlistBindPort, 0, clientSet)
 	assert.NoError(t, controller.Init())
 	_, err = controller.Run(namespace, "component=sandbox-manager")
 	assert.NoError(t, err)
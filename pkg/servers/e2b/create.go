@@ -31,6 +31,12 @@ func (sc *Controller) CreateSandbox(r *http.Request) (web.ApiResponse[*models.Sa
 			Message: "User is empty",
 		}
 	}
+	if sc.manager.Draining() {
+		return web.ApiResponse[*models.Sandbox]{}, &web.ApiError{
+			Code:    http.StatusServiceUnavailable,
+			Message: "Sandbox manager is shutting down, please retry",
+		}
+	}
 	request, parseErr := sc.parseCreateSandboxRequest(r)
 	if parseErr != nil {
 		return web.ApiResponse[*models.Sandbox]{}, parseErr
@@ -67,6 +73,7 @@ func (sc *Controller) createSandboxWithClaim(ctx context.Context, request models
 		},
 		ReserveFailedSandbox: request.Extensions.ReserveFailedSandbox,
 		CreateOnNoStock:      request.Extensions.CreateOnNoStock,
+		Regions:              request.Extensions.Regions,
 	}
 
 	if !request.Extensions.SkipInitRuntime {
@@ -0,0 +1,68 @@
+package e2b
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"github.com/openkruise/agents/pkg/sandbox-manager/errors"
+	"github.com/openkruise/agents/pkg/sandbox-manager/infra"
+	"github.com/openkruise/agents/pkg/servers/e2b/models"
+	"github.com/openkruise/agents/pkg/servers/web"
+)
+
+// DebugSandbox injects an ephemeral debug container into a sandbox of any user for troubleshooting, without
+// interrupting the session in the sandbox. It is only allowed to the admin.
+func (sc *Controller) DebugSandbox(r *http.Request) (web.ApiResponse[*models.DebugSandboxResponse], *web.ApiError) {
+	id := r.PathValue("sandboxID")
+	ctx := r.Context()
+	request := models.DebugSandboxRequest{}
+	// the body is optional
+	if r.Body != nil {
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil && err != io.EOF {
+			return web.ApiResponse[*models.DebugSandboxResponse]{}, &web.ApiError{
+				Code:    http.StatusBadRequest,
+				Message: fmt.Sprintf("Failed to parse request body: %v", err),
+			}
+		}
+	}
+	if request.TTL < 0 {
+		return web.ApiResponse[*models.DebugSandboxResponse]{}, &web.ApiError{
+			Code:    http.StatusBadRequest,
+			Message: "ttl must be positive",
+		}
+	}
+	opts := infra.DebugOptions{
+		Image:           request.Image,
+		TargetContainer: request.Target,
+		TTL:             time.Duration(request.TTL) * time.Second,
+	}
+	container, expiresAt, err := sc.manager.DebugSandbox(ctx, id, opts)
+	if err != nil {
+		code := http.StatusInternalServerError
+		switch errors.GetErrCode(err) {
+		case errors.ErrorBadRequest:
+			code = http.StatusBadRequest
+		case errors.ErrorNotFound:
+			code = http.StatusNotFound
+		case errors.ErrorConflict:
+			code = http.StatusConflict
+		}
+		return web.ApiResponse[*models.DebugSandboxResponse]{}, &web.ApiError{
+			Code:    code,
+			Message: err.Error(),
+		}
+	}
+	klog.FromContext(ctx).Info("debug container injected", "sandboxID", id, "container", container)
+	return web.ApiResponse[*models.DebugSandboxResponse]{
+		Code: http.StatusCreated,
+		Body: &models.DebugSandboxResponse{
+			Container: container,
+			ExpiresAt: expiresAt,
+		},
+	}, nil
+}
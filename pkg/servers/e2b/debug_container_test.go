@@ -0,0 +1,98 @@
+package e2b
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/openkruise/agents/api/v1alpha1"
+	"github.com/openkruise/agents/pkg/servers/e2b/keys"
+	"github.com/openkruise/agents/pkg/servers/e2b/models"
+)
+
+func TestDebugSandbox(t *testing.T) {
+	controller, client, teardown := Setup(t)
+	defer teardown()
+	user := &models.CreatedTeamAPIKey{
+		ID:   keys.AdminKeyID,
+		Key:  InitKey,
+		Name: "admin",
+	}
+	templateName := "test-debug"
+	cleanup := CreateSandboxPool(t, controller, templateName, 1)
+	defer cleanup()
+
+	createResp, apiErr := controller.CreateSandbox(NewRequest(t, nil, models.NewSandboxRequest{
+		TemplateID: templateName,
+		Timeout:    300,
+		Metadata: map[string]string{
+			models.ExtensionKeySkipInitRuntime: v1alpha1.True,
+		},
+	}, nil, user))
+	require.Nil(t, apiErr)
+	sandboxID := createResp.Body.SandboxID
+	sbx := GetSandbox(t, sandboxID, client.SandboxClient)
+	_, err := client.K8sClient.CoreV1().Pods(sbx.Namespace).Create(t.Context(), &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: sbx.Name, Namespace: sbx.Namespace},
+		Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "main"}}},
+	}, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		sandboxID  string
+		body       any
+		expectCode int
+	}{
+		{
+			name:       "sandbox not found",
+			sandboxID:  "default--not-found",
+			expectCode: http.StatusNotFound,
+		},
+		{
+			name:       "ttl too long",
+			sandboxID:  sandboxID,
+			body:       models.DebugSandboxRequest{TTL: 24 * 3600},
+			expectCode: http.StatusBadRequest,
+		},
+		{
+			name:       "negative ttl",
+			sandboxID:  sandboxID,
+			body:       models.DebugSandboxRequest{TTL: -1},
+			expectCode: http.StatusBadRequest,
+		},
+		{
+			name:       "debug with defaults",
+			sandboxID:  sandboxID,
+			expectCode: http.StatusCreated,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(t.Context(), 100*time.Millisecond)
+			defer cancel()
+			start := time.Now()
+			req := NewRequest(t, nil, tt.body, map[string]string{"sandboxID": tt.sandboxID}, user).WithContext(ctx)
+			resp, apiErr := controller.DebugSandbox(req)
+			if tt.expectCode != http.StatusCreated {
+				require.NotNil(t, apiErr)
+				assert.Equal(t, tt.expectCode, apiErr.Code)
+				return
+			}
+			require.Nil(t, apiErr)
+			assert.Equal(t, tt.expectCode, resp.Code)
+			assert.WithinDuration(t, start.Add(15*time.Minute), resp.Body.ExpiresAt, 5*time.Second)
+			pod, err := client.K8sClient.CoreV1().Pods(sbx.Namespace).Get(t.Context(), sbx.Name, metav1.GetOptions{})
+			require.NoError(t, err)
+			require.Len(t, pod.Spec.EphemeralContainers, 1)
+			assert.Equal(t, resp.Body.Container, pod.Spec.EphemeralContainers[0].Name)
+			assert.Equal(t, "main", pod.Spec.EphemeralContainers[0].TargetContainerName)
+		})
+	}
+}
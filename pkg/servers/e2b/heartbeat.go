@@ -0,0 +1,46 @@
+package e2b
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/openkruise/agents/pkg/sandbox-manager/errors"
+	"github.com/openkruise/agents/pkg/servers/e2b/models"
+	"github.com/openkruise/agents/pkg/servers/web"
+)
+
+// SandboxHeartbeat receives the heartbeat of the agent in the sandbox. It is authenticated by the access token of the
+// sandbox instead of API keys, since the agent has no API key of the owner.
+func (sc *Controller) SandboxHeartbeat(r *http.Request) (web.ApiResponse[struct{}], *web.ApiError) {
+	request := models.SandboxHeartbeatRequest{}
+	// the body is optional
+	if r.Body != nil {
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil && err != io.EOF {
+			return web.ApiResponse[struct{}]{}, &web.ApiError{
+				Code:    http.StatusBadRequest,
+				Message: fmt.Sprintf("Failed to parse request body: %v", err),
+			}
+		}
+	}
+	err := sc.manager.RecordHeartbeat(r.Context(), r.PathValue("sandboxID"), r.Header.Get("X-Access-Token"), request.LastActivity)
+	if err != nil {
+		code := http.StatusInternalServerError
+		switch errors.GetErrCode(err) {
+		case errors.ErrorNotFound:
+			code = http.StatusNotFound
+		case errors.ErrorNotAllowed:
+			code = http.StatusUnauthorized
+		case errors.ErrorConflict:
+			code = http.StatusConflict
+		}
+		return web.ApiResponse[struct{}]{}, &web.ApiError{
+			Code:    code,
+			Message: err.Error(),
+		}
+	}
+	return web.ApiResponse[struct{}]{
+		Code: http.StatusNoContent,
+	}, nil
+}
@@ -0,0 +1,108 @@
+package e2b
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openkruise/agents/api/v1alpha1"
+	"github.com/openkruise/agents/pkg/servers/e2b/keys"
+	"github.com/openkruise/agents/pkg/servers/e2b/models"
+)
+
+func TestSandboxHeartbeat(t *testing.T) {
+	controller, client, teardown := Setup(t)
+	defer teardown()
+	user := &models.CreatedTeamAPIKey{
+		ID:   keys.AdminKeyID,
+		Key:  InitKey,
+		Name: "admin",
+	}
+	templateName := "test-heartbeat"
+	cleanup := CreateSandboxPool(t, controller, templateName, 1, CreateSandboxPoolOptions{
+		AccessToken: "token",
+	})
+	defer cleanup()
+
+	createResp, apiErr := controller.CreateSandbox(NewRequest(t, nil, models.NewSandboxRequest{
+		TemplateID: templateName,
+		Timeout:    300,
+		Metadata: map[string]string{
+			models.ExtensionKeySkipInitRuntime: v1alpha1.True,
+		},
+	}, nil, user))
+	require.Nil(t, apiErr)
+	sandboxID := createResp.Body.SandboxID
+
+	lastActivity := time.Now().Add(-time.Minute).Truncate(time.Second)
+	tests := []struct {
+		name         string
+		sandboxID    string
+		token        string
+		body         any
+		expectCode   int
+		expectStatus bool
+	}{
+		{
+			name:       "sandbox not found",
+			sandboxID:  "default--not-found",
+			token:      "token",
+			expectCode: http.StatusNotFound,
+		},
+		{
+			name:       "invalid token",
+			sandboxID:  sandboxID,
+			token:      "invalid",
+			expectCode: http.StatusUnauthorized,
+		},
+		{
+			name:       "invalid body",
+			sandboxID:  sandboxID,
+			token:      "token",
+			body:       "not-an-object",
+			expectCode: http.StatusBadRequest,
+		},
+		{
+			name:         "heartbeat without body",
+			sandboxID:    sandboxID,
+			token:        "token",
+			expectCode:   http.StatusNoContent,
+			expectStatus: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(t.Context(), 100*time.Millisecond)
+			defer cancel()
+			req := NewRequest(t, nil, tt.body, map[string]string{"sandboxID": tt.sandboxID}, nil).WithContext(ctx)
+			req.Header.Set("X-Access-Token", tt.token)
+			resp, apiErr := controller.SandboxHeartbeat(req)
+			if tt.expectCode != http.StatusNoContent {
+				require.NotNil(t, apiErr)
+				assert.Equal(t, tt.expectCode, apiErr.Code)
+				return
+			}
+			require.Nil(t, apiErr)
+			assert.Equal(t, tt.expectCode, resp.Code)
+			sbx := GetSandbox(t, tt.sandboxID, client.SandboxClient)
+			require.NotNil(t, sbx.Status.Heartbeat)
+			assert.WithinDuration(t, time.Now(), sbx.Status.Heartbeat.LastHeartbeatTime.Time, 5*time.Second)
+		})
+	}
+
+	// the persisted heartbeat is not updated within the persist interval
+	require.Eventually(t, func() bool {
+		sbx, err := controller.cache.GetClaimedSandbox(sandboxID)
+		return err == nil && sbx.Status.Heartbeat != nil
+	}, time.Second, 10*time.Millisecond)
+	req := NewRequest(t, nil, models.SandboxHeartbeatRequest{LastActivity: &lastActivity},
+		map[string]string{"sandboxID": sandboxID}, nil)
+	req.Header.Set("X-Access-Token", "token")
+	_, apiErr = controller.SandboxHeartbeat(req)
+	require.Nil(t, apiErr)
+	assert.Nil(t, GetSandbox(t, sandboxID, client.SandboxClient).Status.Heartbeat.LastActivityTime)
+}
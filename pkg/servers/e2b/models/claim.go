@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+const (
+	DefaultWaitForClaimTimeoutSeconds = 30
+	MaxWaitForClaimTimeoutSeconds     = 300
+)
+
+// SandboxClaim is the result of waiting for a SandboxClaim
+type SandboxClaim struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Phase     string `json:"phase"`
+	// Completed is true if the claim reached the Completed or Failed phase, either all replicas are claimed or timed out
+	Completed       bool       `json:"completed"`
+	TimedOut        bool       `json:"timedOut"`
+	Message         string     `json:"message"`
+	DesiredReplicas int32      `json:"desiredReplicas"`
+	ClaimedReplicas int32      `json:"claimedReplicas"`
+	Sandboxes       []*Sandbox `json:"sandboxes"`
+	// ETA is set while the pool is exhausted but sandboxes are being created in it, so that callers can decide to
+	// keep waiting or fall back
+	ETA *SandboxClaimETA `json:"eta,omitempty"`
+}
+
+// SandboxClaimETA estimates when the remaining replicas of a claim become claimable
+type SandboxClaimETA struct {
+	EstimatedTime           time.Time `json:"estimatedTime"`
+	CreatingReplicas        int32     `json:"creatingReplicas"`
+	WarmupLatencyP50Seconds int       `json:"warmupLatencyP50Seconds"`
+	WarmupLatencyP90Seconds int       `json:"warmupLatencyP90Seconds"`
+}
+
+// SandboxClaimApprovalRequest approves or rejects a SandboxClaim requiring approval
+type SandboxClaimApprovalRequest struct {
+	Approved bool `json:"approved"`
+}
@@ -29,6 +29,10 @@ const (
 	ExtensionKeyReserveFailedSandbox          = v1alpha1.E2BPrefix + "reserve-failed-sandbox"
 	ExtensionKeyCreateOnNoStock               = v1alpha1.E2BPrefix + "create-on-no-stock"
 	ExtensionKeyNeverTimeout                  = v1alpha1.E2BPrefix + "never-timeout"
+	// ExtensionKeyPreferredRegions and ExtensionKeyForbiddenRegions are comma-separated regions, see v1alpha1.RegionPolicy
+	ExtensionKeyPreferredRegions = v1alpha1.E2BPrefix + "preferred-regions"
+	ExtensionKeyForbiddenRegions = v1alpha1.E2BPrefix + "forbidden-regions"
+	ExtensionKeyRegionFallback   = v1alpha1.E2BPrefix + "region-fallback"
 )
 
 const (
@@ -80,9 +84,57 @@ func (r *NewSandboxRequest) parseCommonExtensions() error {
 	if err = r.parseExtensionLabels(); err != nil {
 		return err
 	}
+	if err = r.parseExtensionRegions(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *NewSandboxRequest) parseExtensionRegions() error {
+	preferred, hasPreferred := r.Metadata[ExtensionKeyPreferredRegions]
+	forbidden, hasForbidden := r.Metadata[ExtensionKeyForbiddenRegions]
+	fallback, hasFallback := r.Metadata[ExtensionKeyRegionFallback]
+	if !hasPreferred && !hasForbidden && !hasFallback {
+		return nil
+	}
+	delete(r.Metadata, ExtensionKeyPreferredRegions)
+	delete(r.Metadata, ExtensionKeyForbiddenRegions)
+	delete(r.Metadata, ExtensionKeyRegionFallback)
+	policy := &v1alpha1.RegionPolicy{Fallback: v1alpha1.RegionFallbackAny}
+	var err error
+	if policy.Preferred, err = parseRegions(preferred); err != nil {
+		return err
+	}
+	if policy.Forbidden, err = parseRegions(forbidden); err != nil {
+		return err
+	}
+	switch v1alpha1.RegionFallback(fallback) {
+	case "", v1alpha1.RegionFallbackAny:
+	case v1alpha1.RegionFallbackNone:
+		policy.Fallback = v1alpha1.RegionFallbackNone
+	default:
+		return fmt.Errorf("invalid region fallback [%s], must be %s or %s", fallback, v1alpha1.RegionFallbackAny, v1alpha1.RegionFallbackNone)
+	}
+	r.Extensions.Regions = policy
 	return nil
 }
 
+// parseRegions parses comma-separated regions, which must be valid label values
+func parseRegions(value string) ([]string, error) {
+	if value == "" {
+		return nil, nil
+	}
+	var regions []string
+	for _, region := range strings.Split(value, ",") {
+		region = strings.TrimSpace(region)
+		if region == "" || len(validation.IsValidLabelValue(region)) != 0 {
+			return nil, fmt.Errorf("invalid region [%s]", region)
+		}
+		regions = append(regions, region)
+	}
+	return regions, nil
+}
+
 func (r *NewSandboxRequest) parseExtensionLabels() error {
 	for k, v := range r.Metadata {
 		key := strings.TrimPrefix(k, v1alpha1.E2BLabelPrefix)
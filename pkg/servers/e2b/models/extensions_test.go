@@ -578,6 +578,69 @@ func TestParseExtensionLabels(t *testing.T) {
 	}
 }
 
+func TestParseExtensionRegions(t *testing.T) {
+	tests := []struct {
+		name          string
+		metadata      map[string]string
+		expectError   string
+		expectRegions *v1alpha1.RegionPolicy
+	}{
+		{
+			name:     "no regions",
+			metadata: map[string]string{"app": "myapp"},
+		},
+		{
+			name: "preferred and forbidden regions",
+			metadata: map[string]string{
+				ExtensionKeyPreferredRegions: "us-east, eu-west",
+				ExtensionKeyForbiddenRegions: "cn-north",
+			},
+			expectRegions: &v1alpha1.RegionPolicy{
+				Preferred: []string{"us-east", "eu-west"},
+				Forbidden: []string{"cn-north"},
+				Fallback:  v1alpha1.RegionFallbackAny,
+			},
+		},
+		{
+			name: "no fallback",
+			metadata: map[string]string{
+				ExtensionKeyPreferredRegions: "us-east",
+				ExtensionKeyRegionFallback:   "None",
+			},
+			expectRegions: &v1alpha1.RegionPolicy{
+				Preferred: []string{"us-east"},
+				Fallback:  v1alpha1.RegionFallbackNone,
+			},
+		},
+		{
+			name:        "invalid fallback",
+			metadata:    map[string]string{ExtensionKeyRegionFallback: "Nearest"},
+			expectError: "invalid region fallback [Nearest]",
+		},
+		{
+			name:        "invalid region",
+			metadata:    map[string]string{ExtensionKeyPreferredRegions: "us-east,,eu-west"},
+			expectError: "invalid region []",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := &NewSandboxRequest{Metadata: tt.metadata}
+			err := req.parseExtensionRegions()
+			if tt.expectError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectRegions, req.Extensions.Regions)
+			assert.NotContains(t, req.Metadata, ExtensionKeyPreferredRegions)
+			assert.NotContains(t, req.Metadata, ExtensionKeyForbiddenRegions)
+			assert.NotContains(t, req.Metadata, ExtensionKeyRegionFallback)
+		})
+	}
+}
+
 func TestParseExtensionForMultiCSIMount(t *testing.T) {
 	tests := []struct {
 		name               string
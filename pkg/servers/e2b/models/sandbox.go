@@ -2,6 +2,8 @@
 package models
 
 import (
+	"time"
+
 	"github.com/openkruise/agents/api/v1alpha1"
 )
 
@@ -50,6 +52,8 @@ type NewSandboxRequestExtension struct {
 	TimeoutSeconds       int
 	NeverTimeout         bool
 	Labels               map[string]string
+	// Regions selects the sandbox to claim by the region of its pool, nil means any region
+	Regions *v1alpha1.RegionPolicy
 }
 
 type InplaceUpdateExtension struct {
@@ -70,6 +74,84 @@ type SetTimeoutRequest struct {
 	TimeoutSeconds int `json:"timeout"`
 }
 
+// SandboxHeartbeatRequest is reported by the agent in the sandbox to show it is alive
+type SandboxHeartbeatRequest struct {
+	// LastActivity is the last time the sandbox was used, e.g. the last command or request it served
+	LastActivity *time.Time `json:"lastActivity,omitempty"`
+}
+
+// QuarantineSandboxRequest quarantines a suspicious sandbox for security review
+type QuarantineSandboxRequest struct {
+	Reason string `json:"reason"`
+	// Source is who quarantines the sandbox, Manual by default
+	Source string `json:"source,omitempty"`
+}
+
+// DebugSandboxRequest injects an ephemeral debug container into a sandbox
+type DebugSandboxRequest struct {
+	// Image of the debug container, busybox by default
+	Image string `json:"image,omitempty"`
+	// Target is the container to share the process namespace with, the first container by default
+	Target string `json:"target,omitempty"`
+	// TTL is how long the debug container runs in seconds, 15 minutes by default
+	TTL int `json:"ttl,omitempty"`
+}
+
+type DebugSandboxResponse struct {
+	// Container is the name of the debug container to exec into
+	Container string `json:"container"`
+	// ExpiresAt is when the debug container exits
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// SecurityAlertRequest is an alert on a pod raised by a runtime security agent like Falco or Tetragon
+type SecurityAlertRequest struct {
+	// Source is the agent raising the alert, e.g. Falco
+	Source    string `json:"source"`
+	Namespace string `json:"namespace"`
+	Pod       string `json:"pod"`
+	Rule      string `json:"rule,omitempty"`
+	// Severity is one of Emergency, Alert, Critical, Error, Warning, Notice, Informational and Debug
+	Severity string `json:"severity"`
+	// Output is the description of the alert, truncated when recorded in the sandbox status
+	Output string     `json:"output,omitempty"`
+	Time   *time.Time `json:"time,omitempty"`
+}
+
+type SecurityAlertResponse struct {
+	// Action is taken on the sandbox, one of None, Pause and Quarantine
+	Action string `json:"action"`
+}
+
+// BulkReleaseRequest releases one page of sandboxes, or deletes one page of SandboxClaims, matching a label selector
+type BulkReleaseRequest struct {
+	// Resource is sandboxes or sandboxclaims
+	Resource string `json:"resource"`
+	// Namespace of the objects, empty for all namespaces
+	Namespace string `json:"namespace,omitempty"`
+	Selector  string `json:"selector"`
+	Limit     int64  `json:"limit,omitempty"`
+	Continue  string `json:"continue,omitempty"`
+	// DryRun only counts the matched objects
+	DryRun bool `json:"dryRun,omitempty"`
+	// QPS limits the rate of deletions
+	QPS float64 `json:"qps,omitempty"`
+}
+
+type BulkReleaseFailure struct {
+	Name    string `json:"name"`
+	Message string `json:"message"`
+}
+
+type BulkReleaseResponse struct {
+	Matched  int                  `json:"matched"`
+	Released []string             `json:"released"`
+	Failed   []BulkReleaseFailure `json:"failed"`
+	// Continue is the token of the next page, empty if it is the last page
+	Continue           string `json:"continue,omitempty"`
+	RemainingItemCount *int64 `json:"remainingItemCount,omitempty"`
+}
+
 type NewSnapshotRequest struct {
 	Name       string                      `json:"name"` // name is not used by the E2B SDK yet, just reserved for future use
 	Extensions NewSnapshotRequestExtension `json:"-"`
@@ -33,6 +33,8 @@ type TemplateInfo struct {
 	BuildCount    int        `json:"buildCount"`
 	EnvdVersion   string     `json:"envdVersion"`
 	BuildStatus   string     `json:"buildStatus"`
+	// Region of the SandboxSet serving the template, empty if not labeled
+	Region string `json:"region,omitempty"`
 }
 
 // Build represents a build of a template
@@ -47,3 +49,31 @@ type Build struct {
 	DiskSizeMB  int       `json:"diskSizeMB"`
 	EnvdVersion string    `json:"envdVersion"`
 }
+
+// TemplatePressure is the pressure of the pool of a template, for clients to back off or claim from another region
+// before submitting claims destined to time out
+type TemplatePressure struct {
+	TemplateID string `json:"templateID"`
+	// Available and Creating are the unclaimed sandboxes of the pool
+	Available int   `json:"available"`
+	Creating  int   `json:"creating"`
+	Claimed   int32 `json:"claimed"`
+	// InflightClaims and QueuedClaims are only of the sandbox-manager instance serving the request
+	InflightClaims int `json:"inflightClaims"`
+	QueuedClaims   int `json:"queuedClaims"`
+	// ClaimLatencyP50Ms and ClaimLatencyP99Ms are of the recent claims served by the instance, 0 if none
+	ClaimLatencyP50Ms int64 `json:"claimLatencyP50Ms"`
+	ClaimLatencyP99Ms int64 `json:"claimLatencyP99Ms"`
+	// Saturated is true if new claims have to wait for sandboxes being created or fail
+	Saturated bool `json:"saturated"`
+	// Regions break down the pool by the regions of its SandboxSets, so that claims can prefer regions with capacity
+	Regions []RegionPressure `json:"regions,omitempty"`
+}
+
+// RegionPressure is the share of a region in the TemplatePressure
+type RegionPressure struct {
+	Region    string `json:"region"`
+	Available int    `json:"available"`
+	Creating  int    `json:"creating"`
+	Claimed   int32  `json:"claimed"`
+}
@@ -0,0 +1,66 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2b
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/yaml"
+
+	"github.com/openkruise/agents/pkg/servers/e2b/adapters"
+)
+
+const openAPISpecPath = "../../../api/openapi/sandbox-manager.yaml"
+
+type openAPISpec struct {
+	Paths map[string]map[string]any `json:"paths"`
+}
+
+// TestOpenAPISpecMatchesRoutes makes sure the generated SDK clients only call routes that sandbox-manager serves.
+func TestOpenAPISpecMatchesRoutes(t *testing.T) {
+	data, err := os.ReadFile(openAPISpecPath)
+	require.NoError(t, err)
+	spec := openAPISpec{}
+	require.NoError(t, yaml.Unmarshal(data, &spec))
+	require.NotEmpty(t, spec.Paths)
+
+	controller := &Controller{mux: http.NewServeMux()}
+	controller.registerRoutes()
+
+	pathParam := regexp.MustCompile(`\{[^}]+}`)
+	for path, item := range spec.Paths {
+		for method := range item {
+			if method == "parameters" {
+				continue
+			}
+			method = strings.ToUpper(method)
+			t.Run(method+" "+path, func(t *testing.T) {
+				fullPath := adapters.CustomPrefix + "/api" + path
+				req := httptest.NewRequest(method, pathParam.ReplaceAllString(fullPath, "test"), nil)
+				_, pattern := controller.mux.Handler(req)
+				assert.Equal(t, method+" "+fullPath, pattern)
+			})
+		}
+	}
+}
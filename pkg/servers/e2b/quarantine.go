@@ -0,0 +1,57 @@
+package e2b
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"k8s.io/klog/v2"
+
+	"github.com/openkruise/agents/api/v1alpha1"
+	"github.com/openkruise/agents/pkg/sandbox-manager/errors"
+	"github.com/openkruise/agents/pkg/sandbox-manager/infra"
+	"github.com/openkruise/agents/pkg/servers/e2b/models"
+	"github.com/openkruise/agents/pkg/servers/web"
+)
+
+// QuarantineSandbox freezes and isolates a suspicious sandbox for security review. It is only allowed to the admin,
+// e.g. an operator or the anomaly detector of an egress proxy, and applies to sandboxes of any user.
+func (sc *Controller) QuarantineSandbox(r *http.Request) (web.ApiResponse[struct{}], *web.ApiError) {
+	id := r.PathValue("sandboxID")
+	ctx := r.Context()
+	log := klog.FromContext(ctx).WithValues("sandboxID", id)
+	request := models.QuarantineSandboxRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		return web.ApiResponse[struct{}]{}, &web.ApiError{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("Failed to parse request body: %v", err),
+		}
+	}
+	if request.Reason == "" {
+		return web.ApiResponse[struct{}]{}, &web.ApiError{
+			Code:    http.StatusBadRequest,
+			Message: "reason is required",
+		}
+	}
+	if request.Source == "" {
+		request.Source = v1alpha1.SandboxQuarantineSourceManual
+	}
+	err := sc.manager.QuarantineSandbox(ctx, id, infra.QuarantineOptions{Reason: request.Reason, Source: request.Source})
+	if err != nil {
+		code := http.StatusInternalServerError
+		switch errors.GetErrCode(err) {
+		case errors.ErrorNotFound:
+			code = http.StatusNotFound
+		case errors.ErrorConflict:
+			code = http.StatusConflict
+		}
+		return web.ApiResponse[struct{}]{}, &web.ApiError{
+			Code:    code,
+			Message: err.Error(),
+		}
+	}
+	log.Info("sandbox quarantined", "reason", request.Reason, "source", request.Source)
+	return web.ApiResponse[struct{}]{
+		Code: http.StatusNoContent,
+	}, nil
+}
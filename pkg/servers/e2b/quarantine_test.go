@@ -0,0 +1,115 @@
+package e2b
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openkruise/agents/api/v1alpha1"
+	"github.com/openkruise/agents/pkg/servers/e2b/keys"
+	"github.com/openkruise/agents/pkg/servers/e2b/models"
+)
+
+func TestQuarantineSandbox(t *testing.T) {
+	controller, client, teardown := Setup(t)
+	defer teardown()
+	user := &models.CreatedTeamAPIKey{
+		ID:   keys.AdminKeyID,
+		Key:  InitKey,
+		Name: "admin",
+	}
+	templateName := "test-quarantine"
+	cleanup := CreateSandboxPool(t, controller, templateName, 1)
+	defer cleanup()
+
+	createResp, apiErr := controller.CreateSandbox(NewRequest(t, nil, models.NewSandboxRequest{
+		TemplateID: templateName,
+		Timeout:    300,
+		Metadata: map[string]string{
+			models.ExtensionKeySkipInitRuntime: v1alpha1.True,
+		},
+	}, nil, user))
+	require.Nil(t, apiErr)
+	sandboxID := createResp.Body.SandboxID
+
+	tests := []struct {
+		name         string
+		sandboxID    string
+		body         any
+		expectCode   int
+		expectSource string
+	}{
+		{
+			name:       "sandbox not found",
+			sandboxID:  "default--not-found",
+			body:       models.QuarantineSandboxRequest{Reason: "suspicious"},
+			expectCode: http.StatusNotFound,
+		},
+		{
+			name:       "invalid body",
+			sandboxID:  sandboxID,
+			body:       "not-an-object",
+			expectCode: http.StatusBadRequest,
+		},
+		{
+			name:       "missing reason",
+			sandboxID:  sandboxID,
+			body:       models.QuarantineSandboxRequest{Source: v1alpha1.SandboxQuarantineSourceAnomalyDetector},
+			expectCode: http.StatusBadRequest,
+		},
+		{
+			name:         "quarantined",
+			sandboxID:    sandboxID,
+			body:         models.QuarantineSandboxRequest{Reason: "suspicious"},
+			expectCode:   http.StatusNoContent,
+			expectSource: v1alpha1.SandboxQuarantineSourceManual,
+		},
+		{
+			name:      "quarantined again",
+			sandboxID: sandboxID,
+			body: models.QuarantineSandboxRequest{
+				Reason: "another reason",
+				Source: v1alpha1.SandboxQuarantineSourceAnomalyDetector,
+			},
+			expectCode:   http.StatusNoContent,
+			expectSource: v1alpha1.SandboxQuarantineSourceManual,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(t.Context(), 100*time.Millisecond)
+			defer cancel()
+			if tt.expectCode == http.StatusNoContent {
+				// quarantining tries to freeze the sandbox and updates it with retries
+				ctx = t.Context()
+			}
+			req := NewRequest(t, nil, tt.body, map[string]string{"sandboxID": tt.sandboxID}, nil).WithContext(ctx)
+			resp, apiErr := controller.QuarantineSandbox(req)
+			if tt.expectCode != http.StatusNoContent {
+				require.NotNil(t, apiErr)
+				assert.Equal(t, tt.expectCode, apiErr.Code)
+				return
+			}
+			require.Nil(t, apiErr)
+			assert.Equal(t, tt.expectCode, resp.Code)
+			sbx := GetSandbox(t, tt.sandboxID, client.SandboxClient)
+			require.NotNil(t, sbx.Spec.Quarantine)
+			assert.Equal(t, "suspicious", sbx.Spec.Quarantine.Reason)
+			assert.Equal(t, tt.expectSource, sbx.Spec.Quarantine.Source)
+			require.Eventually(t, func() bool {
+				cached, err := controller.cache.GetClaimedSandbox(sandboxID)
+				return err == nil && cached.Spec.Quarantine != nil
+			}, time.Second, 10*time.Millisecond)
+		})
+	}
+
+	// a quarantined sandbox is kept for review
+	_, apiErr = controller.DeleteSandbox(NewRequest(t, nil, nil, map[string]string{"sandboxID": sandboxID}, user))
+	require.NotNil(t, apiErr)
+	assert.Equal(t, http.StatusForbidden, apiErr.Code)
+	assert.NotNil(t, GetSandbox(t, sandboxID, client.SandboxClient).Spec.Quarantine)
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 
+	"github.com/go-logr/logr"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/controller-runtime/pkg/metrics"
@@ -18,6 +19,14 @@ import (
 
 func (sc *Controller) registerRoutes() {
 	sc.mux.HandleFunc("GET /health", func(w http.ResponseWriter, _ *http.Request) {
+		// fail the readiness once draining, so that the instance is removed from the endpoints of the service
+		if sc.manager != nil && sc.manager.Draining() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			if _, err := fmt.Fprintf(w, "Draining"); err != nil {
+				klog.ErrorS(err, "Failed to write health check response")
+			}
+			return
+		}
 		w.WriteHeader(http.StatusOK)
 		_, err := fmt.Fprintf(w, "OK")
 		if err != nil {
@@ -25,8 +34,8 @@ func (sc *Controller) registerRoutes() {
 		}
 	})
 
-	// Prometheus metrics endpoint for exporting metrics
-	sc.mux.Handle("GET /metrics", promhttp.HandlerFor(metrics.Registry, promhttp.HandlerOpts{}))
+	// Prometheus metrics endpoint for exporting metrics, in the OpenMetrics format if accepted by the scraper
+	sc.mux.Handle("GET /metrics", promhttp.HandlerFor(metrics.Registry, promhttp.HandlerOpts{EnableOpenMetrics: true}))
 
 	// Sandbox management endpoints
 	RegisterE2BRoute(sc.mux, http.MethodPost, "/sandboxes", sc.CreateSandbox, sc.CheckApiKey)
@@ -38,12 +47,24 @@ func (sc *Controller) registerRoutes() {
 	RegisterE2BRoute(sc.mux, http.MethodPost, "/sandboxes/{sandboxID}/connect", sc.ConnectSandbox, sc.CheckApiKey)
 	RegisterE2BRoute(sc.mux, http.MethodPost, "/sandboxes/{sandboxID}/timeout", sc.SetSandboxTimeout, sc.CheckApiKey)
 	RegisterE2BRoute(sc.mux, http.MethodPost, "/sandboxes/{sandboxID}/snapshots", sc.CreateSnapshot, sc.CheckApiKey)
+	// Heartbeats are reported by the agent in the sandbox, which is authenticated by the access token of the sandbox
+	RegisterE2BRoute(sc.mux, http.MethodPost, "/sandboxes/{sandboxID}/heartbeat", sc.SandboxHeartbeat)
+	// Sandboxes of any user can be quarantined or debugged by the admin
+	RegisterE2BRoute(sc.mux, http.MethodPost, "/sandboxes/{sandboxID}/quarantine", sc.QuarantineSandbox, sc.CheckAdminApiKey)
+	RegisterE2BRoute(sc.mux, http.MethodPost, "/sandboxes/{sandboxID}/debug", sc.DebugSandbox, sc.CheckAdminApiKey)
 	RegisterE2BRoute(sc.mux, http.MethodGet, "/snapshots", sc.ListSnapshots, sc.CheckApiKey)
 	RegisterE2BRoute(sc.mux, http.MethodGet, "/templates", sc.ListTemplates, sc.CheckApiKey)
 	RegisterE2BRoute(sc.mux, http.MethodGet, "/templates/{templateID}", sc.GetTemplate, sc.CheckApiKey)
 	RegisterE2BRoute(sc.mux, http.MethodDelete, "/templates/{templateID}", sc.DeleteTemplate, sc.CheckApiKey)
+	RegisterE2BRoute(sc.mux, http.MethodGet, "/templates/{templateID}/pressure", sc.GetTemplatePressure, sc.CheckApiKey)
 	RegisterE2BRoute(sc.mux, http.MethodGet, "/browser/{sandboxID}/json/version", sc.BrowserUse, sc.CheckApiKey)
 	RegisterE2BRoute(sc.mux, http.MethodGet, "/debug", sc.Debug, sc.CheckApiKey)
+	// SandboxClaims are not owned by API keys, so only admin can wait for them
+	RegisterE2BRoute(sc.mux, http.MethodGet, "/sandboxclaims/{namespace}/{claimName}/wait", sc.WaitForClaim, sc.CheckApiKey, sc.CheckAdminKey)
+	RegisterE2BRoute(sc.mux, http.MethodPost, "/sandboxclaims/{namespace}/{claimName}/approval", sc.DecideClaimApproval, sc.CheckApiKey, sc.CheckAdminKey)
+	// Alerts of runtime security agents are forwarded by their webhooks configured with the admin key
+	RegisterE2BRoute(sc.mux, http.MethodPost, "/security-alerts", sc.ReceiveSecurityAlert, sc.CheckApiKey, sc.CheckAdminKey)
+	RegisterE2BRoute(sc.mux, http.MethodPost, "/bulk-release", sc.BulkRelease, sc.CheckApiKey, sc.CheckAdminKey)
 
 	// API Keys management endpoints
 	if sc.keys != nil {
@@ -67,24 +88,30 @@ var AnonymousUser = &models.CreatedTeamAPIKey{
 	Name: "auth-disabled",
 }
 
+// loadUser loads the user of the API key in the request
+func (sc *Controller) loadUser(r *http.Request, log logr.Logger) (*models.CreatedTeamAPIKey, *web.ApiError) {
+	if sc.keys == nil {
+		return AnonymousUser, nil
+	}
+	apiKey := r.Header.Get("X-API-KEY")
+	user, ok := sc.keys.LoadByKey(apiKey)
+	if !ok {
+		log.Info("failed to load key by API-KEY")
+		return nil, &web.ApiError{
+			Code:    http.StatusUnauthorized,
+			Message: fmt.Sprintf("Invalid API Key: %s", apiKey),
+		}
+	}
+	return user, nil
+}
+
 // CheckApiKey implements common ApiKey validation
 func (sc *Controller) CheckApiKey(ctx context.Context, r *http.Request) (context.Context, *web.ApiError) {
 	logger := klog.FromContext(ctx)
 	middleWareLog := logger.WithValues("middleware", "CheckApiKey").V(consts.DebugLogLevel)
-	apiKey := r.Header.Get("X-API-KEY")
-	var user *models.CreatedTeamAPIKey
-	var ok bool
-	if sc.keys == nil {
-		user = AnonymousUser
-	} else {
-		user, ok = sc.keys.LoadByKey(apiKey)
-		if !ok {
-			middleWareLog.Info("failed to load key by API-KEY")
-			return ctx, &web.ApiError{
-				Code:    http.StatusUnauthorized,
-				Message: fmt.Sprintf("Invalid API Key: %s", apiKey),
-			}
-		}
+	user, apiErr := sc.loadUser(r, middleWareLog)
+	if apiErr != nil {
+		return ctx, apiErr
 	}
 	if sandboxID := r.PathValue("sandboxID"); sandboxID != "" {
 		owner, ok := sc.manager.GetOwnerOfSandbox(sandboxID)
@@ -105,6 +132,19 @@ func (sc *Controller) CheckApiKey(ctx context.Context, r *http.Request) (context
 	return context.WithValue(klog.NewContext(ctx, logger.WithValues("user", user.Name)), "user", user), nil
 }
 
+// CheckAdminApiKey checks the API key is of the admin. Unlike CheckApiKey, it does not check the owner of the sandbox
+// in the path, so that the admin can operate the sandboxes of any user.
+func (sc *Controller) CheckAdminApiKey(ctx context.Context, r *http.Request) (context.Context, *web.ApiError) {
+	logger := klog.FromContext(ctx)
+	middleWareLog := logger.WithValues("middleware", "CheckAdminApiKey").V(consts.DebugLogLevel)
+	user, apiErr := sc.loadUser(r, middleWareLog)
+	if apiErr != nil {
+		return ctx, apiErr
+	}
+	ctx = context.WithValue(klog.NewContext(ctx, logger.WithValues("user", user.Name)), "user", user)
+	return sc.CheckAdminKey(ctx, r)
+}
+
 // CheckAdminKey must be called after CheckApiKey. It checks if the user is an admin.
 func (sc *Controller) CheckAdminKey(ctx context.Context, _ *http.Request) (context.Context, *web.ApiError) {
 	logger := klog.FromContext(ctx)
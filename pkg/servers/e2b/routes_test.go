@@ -472,3 +472,69 @@ func TestGetUserFromContext(t *testing.T) {
 		})
 	}
 }
+
+// TestCheckAdminApiKey tests CheckAdminApiKey allows the admin to access sandboxes of any user
+func TestCheckAdminApiKey(t *testing.T) {
+	controller, _, teardown := Setup(t)
+	defer teardown()
+
+	templateName := "test-template-admin-auth"
+	cleanup := CreateSandboxPool(t, controller, templateName, 1)
+	defer cleanup()
+
+	adminUser := &models.CreatedTeamAPIKey{
+		ID:   keys.AdminKeyID,
+		Key:  InitKey,
+		Name: "admin",
+	}
+	ctx := logs.NewContext()
+	regularUser, err := controller.keys.CreateKey(ctx, adminUser, "regular-user")
+	require.NoError(t, err)
+
+	createResp, apiErr := controller.CreateSandbox(NewRequest(t, nil, models.NewSandboxRequest{
+		TemplateID: templateName,
+		Metadata: map[string]string{
+			models.ExtensionKeySkipInitRuntime: "true",
+		},
+	}, nil, regularUser))
+	require.Nil(t, apiErr)
+	sandboxID := createResp.Body.SandboxID
+
+	tests := []struct {
+		name         string
+		apiKeyHeader string
+		expectedCode int
+	}{
+		{
+			name:         "admin can access other user's sandbox",
+			apiKeyHeader: InitKey,
+		},
+		{
+			name:         "owner is not admin",
+			apiKeyHeader: regularUser.Key,
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:         "invalid api key",
+			apiKeyHeader: "invalid",
+			expectedCode: http.StatusUnauthorized,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodPost, "http://localhost/test", nil)
+			require.NoError(t, err)
+			req.Header.Set("X-API-KEY", tt.apiKeyHeader)
+			req.SetPathValue("sandboxID", sandboxID)
+
+			newCtx, apiErr := controller.CheckAdminApiKey(logs.NewContext(), req)
+			if tt.expectedCode != 0 {
+				require.NotNil(t, apiErr)
+				assert.Equal(t, tt.expectedCode, apiErr.Code)
+				return
+			}
+			require.Nil(t, apiErr)
+			assert.Equal(t, keys.AdminKeyID, GetUserFromContext(newCtx).ID)
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package e2b
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	sandbox_manager "github.com/openkruise/agents/pkg/sandbox-manager"
+	"github.com/openkruise/agents/pkg/sandbox-manager/errors"
+	"github.com/openkruise/agents/pkg/servers/e2b/models"
+	"github.com/openkruise/agents/pkg/servers/web"
+)
+
+// ReceiveSecurityAlert ingests an alert of a runtime security agent on the pod of a sandbox. The sandbox is paused or
+// quarantined if the severity of the alert reaches the configured ones. It is only allowed to the admin.
+func (sc *Controller) ReceiveSecurityAlert(r *http.Request) (web.ApiResponse[*models.SecurityAlertResponse], *web.ApiError) {
+	ctx := r.Context()
+	request := models.SecurityAlertRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		return web.ApiResponse[*models.SecurityAlertResponse]{}, &web.ApiError{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("Failed to parse request body: %v", err),
+		}
+	}
+	if request.Source == "" || request.Namespace == "" || request.Pod == "" {
+		return web.ApiResponse[*models.SecurityAlertResponse]{}, &web.ApiError{
+			Code:    http.StatusBadRequest,
+			Message: "source, namespace and pod are required",
+		}
+	}
+	alert := sandbox_manager.SecurityAlert{
+		Source:    request.Source,
+		Namespace: request.Namespace,
+		Pod:       request.Pod,
+		Rule:      request.Rule,
+		Severity:  request.Severity,
+		Output:    request.Output,
+	}
+	if request.Time != nil {
+		alert.Time = *request.Time
+	} else {
+		alert.Time = time.Now()
+	}
+	action, err := sc.manager.HandleSecurityAlert(ctx, alert)
+	if err != nil {
+		code := http.StatusInternalServerError
+		switch errors.GetErrCode(err) {
+		case errors.ErrorBadRequest:
+			code = http.StatusBadRequest
+		case errors.ErrorNotFound:
+			code = http.StatusNotFound
+		}
+		return web.ApiResponse[*models.SecurityAlertResponse]{}, &web.ApiError{
+			Code:    code,
+			Message: err.Error(),
+		}
+	}
+	klog.FromContext(ctx).Info("security alert received", "namespace", request.Namespace, "pod", request.Pod,
+		"severity", request.Severity, "action", action)
+	return web.ApiResponse[*models.SecurityAlertResponse]{
+		Code: http.StatusOK,
+		Body: &models.SecurityAlertResponse{Action: string(action)},
+	}, nil
+}
@@ -0,0 +1,89 @@
+package e2b
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openkruise/agents/api/v1alpha1"
+	"github.com/openkruise/agents/pkg/servers/e2b/keys"
+	"github.com/openkruise/agents/pkg/servers/e2b/models"
+)
+
+func TestReceiveSecurityAlert(t *testing.T) {
+	controller, client, teardown := Setup(t)
+	defer teardown()
+	user := &models.CreatedTeamAPIKey{
+		ID:   keys.AdminKeyID,
+		Key:  InitKey,
+		Name: "admin",
+	}
+	templateName := "test-security-alert"
+	cleanup := CreateSandboxPool(t, controller, templateName, 1)
+	defer cleanup()
+
+	createResp, apiErr := controller.CreateSandbox(NewRequest(t, nil, models.NewSandboxRequest{
+		TemplateID: templateName,
+		Timeout:    300,
+		Metadata: map[string]string{
+			models.ExtensionKeySkipInitRuntime: v1alpha1.True,
+		},
+	}, nil, user))
+	require.Nil(t, apiErr)
+	sbx := GetSandbox(t, createResp.Body.SandboxID, client.SandboxClient)
+
+	tests := []struct {
+		name         string
+		body         any
+		expectCode   int
+		expectAction string
+	}{
+		{
+			name:       "invalid body",
+			body:       "not-an-object",
+			expectCode: http.StatusBadRequest,
+		},
+		{
+			name:       "missing pod",
+			body:       models.SecurityAlertRequest{Source: "Falco", Namespace: sbx.Namespace, Severity: "Warning"},
+			expectCode: http.StatusBadRequest,
+		},
+		{
+			name:       "invalid severity",
+			body:       models.SecurityAlertRequest{Source: "Falco", Namespace: sbx.Namespace, Pod: sbx.Name, Severity: "Fatal"},
+			expectCode: http.StatusBadRequest,
+		},
+		{
+			name:       "pod of no sandbox",
+			body:       models.SecurityAlertRequest{Source: "Falco", Namespace: sbx.Namespace, Pod: "not-found", Severity: "Warning"},
+			expectCode: http.StatusNotFound,
+		},
+		{
+			name:         "recorded",
+			body:         models.SecurityAlertRequest{Source: "Falco", Namespace: sbx.Namespace, Pod: sbx.Name, Rule: "Terminal shell in container", Severity: "Notice"},
+			expectCode:   http.StatusOK,
+			expectAction: string(v1alpha1.SandboxSecurityAlertActionNone),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(t.Context(), 100*time.Millisecond)
+			defer cancel()
+			resp, apiErr := controller.ReceiveSecurityAlert(NewRequest(t, nil, tt.body, nil, user).WithContext(ctx))
+			if tt.expectCode != http.StatusOK {
+				require.NotNil(t, apiErr)
+				assert.Equal(t, tt.expectCode, apiErr.Code)
+				return
+			}
+			require.Nil(t, apiErr)
+			assert.Equal(t, tt.expectAction, resp.Body.Action)
+			got := GetSandbox(t, createResp.Body.SandboxID, client.SandboxClient)
+			require.NotNil(t, got.Status.SecurityAlert)
+			assert.Equal(t, "Terminal shell in container", got.Status.SecurityAlert.Rule)
+		})
+	}
+}
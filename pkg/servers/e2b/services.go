@@ -10,6 +10,7 @@ import (
 	"k8s.io/klog/v2"
 
 	sandboxmanager "github.com/openkruise/agents/pkg/sandbox-manager"
+	"github.com/openkruise/agents/pkg/sandbox-manager/errors"
 	"github.com/openkruise/agents/pkg/sandbox-manager/infra"
 	"github.com/openkruise/agents/pkg/servers/e2b/models"
 	"github.com/openkruise/agents/pkg/servers/web"
@@ -47,7 +48,12 @@ func (sc *Controller) DeleteSandbox(r *http.Request) (web.ApiResponse[struct{}],
 
 	if err := sc.manager.DeleteSandbox(r.Context(), sbx); err != nil {
 		log.Error(err, "failed to delete sandbox", "id", id)
+		code := http.StatusInternalServerError
+		if errors.GetErrCode(err) == errors.ErrorNotAllowed {
+			code = http.StatusForbidden
+		}
 		return web.ApiResponse[struct{}]{}, &web.ApiError{
+			Code:    code,
 			Message: fmt.Sprintf("Failed to delete sandbox: %v", err),
 		}
 	}
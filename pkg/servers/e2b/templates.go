@@ -100,6 +100,46 @@ func (sc *Controller) GetTemplate(r *http.Request) (web.ApiResponse[*models.Temp
 	}, nil
 }
 
+// GetTemplatePressure returns the pressure of the pool of a template, so that SDKs can delay claims or route them to
+// another region when the pool is saturated.
+func (sc *Controller) GetTemplatePressure(r *http.Request) (web.ApiResponse[*models.TemplatePressure], *web.ApiError) {
+	templateID := r.PathValue("templateID")
+	pressure, err := sc.manager.GetPoolPressure(templateID)
+	if err != nil {
+		code := http.StatusInternalServerError
+		if errors.GetErrCode(err) == errors.ErrorNotFound {
+			code = http.StatusNotFound
+		}
+		return web.ApiResponse[*models.TemplatePressure]{}, &web.ApiError{
+			Code:    code,
+			Message: err.Error(),
+		}
+	}
+	resp := &models.TemplatePressure{
+		TemplateID:        pressure.Template,
+		Available:         pressure.Available,
+		Creating:          pressure.Creating,
+		Claimed:           pressure.Claimed,
+		InflightClaims:    pressure.InflightClaims,
+		QueuedClaims:      pressure.QueuedClaims,
+		ClaimLatencyP50Ms: pressure.ClaimLatencyP50.Milliseconds(),
+		ClaimLatencyP99Ms: pressure.ClaimLatencyP99.Milliseconds(),
+		Saturated:         pressure.Saturated,
+	}
+	for _, region := range pressure.Regions {
+		resp.Regions = append(resp.Regions, models.RegionPressure{
+			Region:    region.Region,
+			Available: region.Available,
+			Creating:  region.Creating,
+			Claimed:   region.Claimed,
+		})
+	}
+	return web.ApiResponse[*models.TemplatePressure]{
+		Code: http.StatusOK,
+		Body: resp,
+	}, nil
+}
+
 // DeleteTemplate deletes a template (checkpoint and its associated sandbox template)
 func (sc *Controller) DeleteTemplate(r *http.Request) (web.ApiResponse[struct{}], *web.ApiError) {
 	templateID := r.PathValue("templateID")
@@ -176,6 +216,7 @@ func (sc *Controller) convertToTemplateInfo(tmpl *agentsv1alpha1.SandboxSet) *mo
 		BuildCount:  1,
 		EnvdVersion: "0.1.1",
 		BuildStatus: buildStatus(tmpl),
+		Region:      tmpl.Labels[agentsv1alpha1.LabelSandboxRegion],
 	}
 }
 
@@ -653,3 +653,47 @@ func TestBuildResource(t *testing.T) {
 		})
 	}
 }
+
+func TestGetTemplatePressure(t *testing.T) {
+	controller, _, teardown := Setup(t)
+	defer teardown()
+	templateName := "test-template-pressure"
+	cleanup := CreateSandboxPool(t, controller, templateName, 2)
+	defer cleanup()
+
+	tests := []struct {
+		name         string
+		templateID   string
+		expectStatus int
+		expect       *models.TemplatePressure
+	}{
+		{
+			name:         "pool with available sandboxes",
+			templateID:   templateName,
+			expectStatus: http.StatusOK,
+			expect: &models.TemplatePressure{
+				TemplateID: templateName,
+				Available:  2,
+			},
+		},
+		{
+			name:         "template not found",
+			templateID:   "not-exist",
+			expectStatus: http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := NewRequest(t, nil, nil, map[string]string{"templateID": tt.templateID}, nil)
+			resp, apiErr := controller.GetTemplatePressure(req)
+			if tt.expect == nil {
+				require.NotNil(t, apiErr)
+				assert.Equal(t, tt.expectStatus, apiErr.Code)
+				return
+			}
+			require.Nil(t, apiErr)
+			assert.Equal(t, tt.expectStatus, resp.Code)
+			assert.Equal(t, tt.expect, resp.Body)
+		})
+	}
+}
@@ -0,0 +1,44 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// gen writes the tool spec used by the adapters in other languages.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/openkruise/agents/pkg/servers/tools"
+)
+
+func main() {
+	output := flag.String("o", "", "Path of the generated tool spec")
+	flag.Parse()
+	if *output == "" {
+		_, _ = fmt.Fprintln(os.Stderr, "-o is required")
+		os.Exit(1)
+	}
+	data, err := tools.MarshalSpec()
+	if err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if err = os.WriteFile(*output, data, 0644); err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package langchain is the reference implementation of the LangChain tool adapters. Tool implements the
+// tools.Tool interface of github.com/tmc/langchaingo (Name, Description and Call), and Session wraps the
+// claim, exec, file and release APIs of a sandbox. The Python shim in sdk/langchain is generated from the
+// same tool definitions, see pkg/servers/tools/gen.
+package langchain
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/openkruise/agents/pkg/agentsctl"
+	"github.com/openkruise/agents/pkg/servers/e2b/models"
+	"github.com/openkruise/agents/pkg/servers/tools"
+)
+
+// Tool is a sandbox tool in the LangChain format, the input of Call is the JSON encoded arguments.
+type Tool struct {
+	definition tools.FunctionDefinition
+	runtime    tools.SandboxRuntime
+}
+
+func (t *Tool) Name() string {
+	return t.definition.Name
+}
+
+// Description includes the JSON schema of the arguments, since LangChain agents only see the description.
+func (t *Tool) Description() string {
+	return fmt.Sprintf("%s The input is a JSON object with the schema: %s", t.definition.Description, t.definition.Parameters)
+}
+
+// Call runs the tool in the sandbox. Failures of the tool are returned as the output, so that the agent can react.
+func (t *Tool) Call(ctx context.Context, input string) (string, error) {
+	message := tools.Call(ctx, t.runtime, tools.ToolCall{
+		Type:     tools.ToolTypeFunction,
+		Function: tools.FunctionCall{Name: t.definition.Name, Arguments: input},
+	})
+	return message.Content, nil
+}
+
+// NewTools returns all sandbox tools running in the runtime.
+func NewTools(runtime tools.SandboxRuntime) []*Tool {
+	definitions := tools.Definitions()
+	result := make([]*Tool, 0, len(definitions))
+	for _, definition := range definitions {
+		result = append(result, &Tool{definition: definition.Function, runtime: runtime})
+	}
+	return result
+}
+
+// Session is a sandbox claimed for an agent, its tools run in the sandbox until it is closed.
+type Session struct {
+	SandboxID string
+	Tools     []*Tool
+
+	client *agentsctl.Client
+}
+
+// NewSession claims a sandbox with the request and connects its tools.
+func NewSession(ctx context.Context, client *agentsctl.Client, request *models.NewSandboxRequest) (*Session, error) {
+	sbx, err := client.Claim(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	runtime, err := client.Runtime(ctx, sbx.SandboxID)
+	if err != nil {
+		_ = client.Release(ctx, sbx.SandboxID)
+		return nil, err
+	}
+	return &Session{
+		SandboxID: sbx.SandboxID,
+		Tools:     NewTools(runtime),
+		client:    client,
+	}, nil
+}
+
+// Close releases the sandbox of the session.
+func (s *Session) Close(ctx context.Context) error {
+	return s.client.Release(ctx, s.SandboxID)
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package langchain
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openkruise/agents/pkg/agentsctl"
+	"github.com/openkruise/agents/pkg/servers/e2b/models"
+	"github.com/openkruise/agents/pkg/servers/tools"
+	testutils "github.com/openkruise/agents/test/utils"
+)
+
+const testSandboxID = "sbx-1"
+
+// newTestServer fakes a sandbox-manager serving one sandbox, whose runtime is a test runtime server.
+func newTestServer(t *testing.T) (*httptest.Server, *int) {
+	released := 0
+	runtime := testutils.NewTestRuntimeServer(testutils.TestRuntimeServerOptions{
+		RunCommandResult:      testutils.RunCommandResult{Stdout: []string{"hello"}, Exited: true},
+		RunCommandImmediately: true,
+	})
+	t.Cleanup(runtime.Close)
+	runtimeURL, err := url.Parse(runtime.URL)
+	require.NoError(t, err)
+	runtimePrefix := "/kruise/" + testSandboxID + "/49983"
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /kruise/api/sandboxes", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(models.Sandbox{SandboxID: testSandboxID})
+	})
+	mux.HandleFunc("GET /kruise/api/sandboxes/"+testSandboxID, func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(models.Sandbox{SandboxID: testSandboxID, EnvdAccessToken: testutils.AccessToken})
+	})
+	mux.HandleFunc("DELETE /kruise/api/sandboxes/"+testSandboxID, func(w http.ResponseWriter, _ *http.Request) {
+		released++
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.Handle(runtimePrefix+"/", http.StripPrefix(runtimePrefix, httputil.NewSingleHostReverseProxy(runtimeURL)))
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server, &released
+}
+
+func TestSession(t *testing.T) {
+	server, released := newTestServer(t)
+	ctx := context.Background()
+
+	session, err := NewSession(ctx, agentsctl.NewClient(server.URL, "key"), &models.NewSandboxRequest{TemplateID: "python"})
+	require.NoError(t, err)
+	assert.Equal(t, testSandboxID, session.SandboxID)
+
+	var runCommand *Tool
+	for _, tool := range session.Tools {
+		if tool.Name() == tools.ToolRunCommand {
+			runCommand = tool
+		}
+	}
+	require.NotNil(t, runCommand)
+	assert.Contains(t, runCommand.Description(), `"required":["command"]`)
+
+	output, err := runCommand.Call(ctx, `{"command":"echo hello"}`)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"exit_code":0,"stdout":"hello","stderr":""}`, output)
+
+	output, err = runCommand.Call(ctx, `echo hello`)
+	require.NoError(t, err)
+	assert.Contains(t, output, "error: invalid arguments of run_command")
+
+	require.NoError(t, session.Close(ctx))
+	assert.Equal(t, 1, *released)
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package tools implements the tool server, which exposes the exec and file APIs of a claimed sandbox as tools
+// in the OpenAI function calling format. An LLM orchestrator passes the tools of a session to the model and
+// posts the tool calls of the model back, without any custom glue code.
+//
+// A session is a claimed sandbox, and is addressed by its sandbox ID. Requests are authenticated by the
+// sandbox-manager with the X-API-KEY header, which is forwarded as-is.
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"k8s.io/klog/v2"
+
+	"github.com/openkruise/agents/pkg/agentsctl"
+	"github.com/openkruise/agents/pkg/servers/web"
+)
+
+// Connector connects to the agent runtime of a claimed sandbox on behalf of the owner of apiKey.
+type Connector func(ctx context.Context, apiKey, sandboxID string) (SandboxRuntime, error)
+
+// NewSandboxManagerConnector returns a Connector resolving sandboxes through the sandbox-manager at server.
+func NewSandboxManagerConnector(server string) Connector {
+	return func(ctx context.Context, apiKey, sandboxID string) (SandboxRuntime, error) {
+		return agentsctl.NewClient(server, apiKey).Runtime(ctx, sandboxID)
+	}
+}
+
+type Server struct {
+	mux     *http.ServeMux
+	connect Connector
+}
+
+func NewServer(connect Connector) *Server {
+	s := &Server{
+		mux:     http.NewServeMux(),
+		connect: connect,
+	}
+	s.registerRoutes()
+	return s
+}
+
+func (s *Server) Handler() http.Handler {
+	return s.mux
+}
+
+func (s *Server) registerRoutes() {
+	s.mux.HandleFunc("GET /health", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		if _, err := fmt.Fprintf(w, "OK"); err != nil {
+			klog.ErrorS(err, "Failed to write health check response")
+		}
+	})
+	web.RegisterRoute(s.mux, http.MethodGet, "/sessions/{sandboxID}/tools", s.ListTools)
+	web.RegisterRoute(s.mux, http.MethodPost, "/sessions/{sandboxID}/tool_calls", s.CallTools)
+}
+
+// ListTools returns the tools of a session, to be passed as the `tools` of a chat completions request.
+func (s *Server) ListTools(r *http.Request) (web.ApiResponse[[]Tool], *web.ApiError) {
+	if _, apiErr := s.runtime(r); apiErr != nil {
+		return web.ApiResponse[[]Tool]{}, apiErr
+	}
+	return web.ApiResponse[[]Tool]{Body: Definitions()}, nil
+}
+
+// CallTools runs the tool calls of an assistant message in order and returns the tool messages to reply with.
+func (s *Server) CallTools(r *http.Request) (web.ApiResponse[ToolCallsResponse], *web.ApiError) {
+	log := klog.FromContext(r.Context())
+	request := ToolCallsRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		return web.ApiResponse[ToolCallsResponse]{}, &web.ApiError{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("Failed to parse request body: %v", err),
+		}
+	}
+	runtime, apiErr := s.runtime(r)
+	if apiErr != nil {
+		return web.ApiResponse[ToolCallsResponse]{}, apiErr
+	}
+	response := ToolCallsResponse{Messages: make([]ToolMessage, 0, len(request.ToolCalls))}
+	for _, call := range request.ToolCalls {
+		message := Call(r.Context(), runtime, call)
+		log.Info("tool called", "tool", call.Function.Name, "toolCallID", call.ID)
+		response.Messages = append(response.Messages, message)
+	}
+	return web.ApiResponse[ToolCallsResponse]{Body: response}, nil
+}
+
+func (s *Server) runtime(r *http.Request) (SandboxRuntime, *web.ApiError) {
+	sandboxID := r.PathValue("sandboxID")
+	runtime, err := s.connect(r.Context(), r.Header.Get(agentsctl.HeaderAPIKey), sandboxID)
+	if err == nil {
+		return runtime, nil
+	}
+	apiErr := &web.ApiError{
+		Code:    http.StatusBadGateway,
+		Message: fmt.Sprintf("Failed to connect to sandbox %s: %v", sandboxID, err),
+	}
+	var respErr *agentsctl.ResponseError
+	if errors.As(err, &respErr) && respErr.StatusCode < http.StatusInternalServerError {
+		// pass through client errors of the sandbox-manager, e.g. invalid API key and sandbox not found
+		apiErr.Code = respErr.StatusCode
+		apiErr.Message = respErr.Message
+	}
+	return nil, apiErr
+}
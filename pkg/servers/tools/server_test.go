@@ -0,0 +1,204 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openkruise/agents/pkg/agentsctl"
+	"github.com/openkruise/agents/pkg/servers/web"
+)
+
+const (
+	testAPIKey    = "test-api-key"
+	testSandboxID = "sbx-1"
+)
+
+type fakeRuntime struct {
+	files    map[string]string
+	commands [][]string
+	stdout   string
+	stderr   string
+	exitCode int32
+}
+
+func (f *fakeRuntime) Exec(_ context.Context, cmd []string, _ map[string]string, _ string, stdout, stderr io.Writer) (int32, error) {
+	f.commands = append(f.commands, cmd)
+	_, _ = io.WriteString(stdout, f.stdout)
+	_, _ = io.WriteString(stderr, f.stderr)
+	return f.exitCode, nil
+}
+
+func (f *fakeRuntime) Download(_ context.Context, path string, w io.Writer) error {
+	content, ok := f.files[path]
+	if !ok {
+		return &agentsctl.ResponseError{StatusCode: http.StatusNotFound, Message: "file not found"}
+	}
+	_, err := io.WriteString(w, content)
+	return err
+}
+
+func (f *fakeRuntime) Upload(_ context.Context, path string, reader io.Reader) error {
+	content, err := io.ReadAll(reader)
+	f.files[path] = string(content)
+	return err
+}
+
+func newTestServer(runtime *fakeRuntime) *httptest.Server {
+	return httptest.NewServer(NewServer(func(_ context.Context, apiKey, sandboxID string) (SandboxRuntime, error) {
+		if apiKey != testAPIKey {
+			return nil, &agentsctl.ResponseError{StatusCode: http.StatusUnauthorized, Message: "Invalid API Key"}
+		}
+		if sandboxID != testSandboxID {
+			return nil, &agentsctl.ResponseError{StatusCode: http.StatusNotFound, Message: "sandbox not found"}
+		}
+		return runtime, nil
+	}).Handler())
+}
+
+func doRequest(t *testing.T, method, url, apiKey string, body any) *http.Response {
+	var reader io.Reader
+	if body != nil {
+		by, err := json.Marshal(body)
+		require.NoError(t, err)
+		reader = bytes.NewReader(by)
+	}
+	req, err := http.NewRequest(method, url, reader)
+	require.NoError(t, err)
+	req.Header.Set(agentsctl.HeaderAPIKey, apiKey)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = resp.Body.Close()
+	})
+	return resp
+}
+
+func TestServer_ListTools(t *testing.T) {
+	server := newTestServer(&fakeRuntime{})
+	defer server.Close()
+
+	tests := []struct {
+		name       string
+		sandboxID  string
+		apiKey     string
+		expectCode int
+	}{
+		{name: "ok", sandboxID: testSandboxID, apiKey: testAPIKey, expectCode: http.StatusOK},
+		{name: "invalid api key", sandboxID: testSandboxID, apiKey: "wrong", expectCode: http.StatusUnauthorized},
+		{name: "sandbox not found", sandboxID: "not-exist", apiKey: testAPIKey, expectCode: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := doRequest(t, http.MethodGet, fmt.Sprintf("%s/sessions/%s/tools", server.URL, tt.sandboxID), tt.apiKey, nil)
+			assert.Equal(t, tt.expectCode, resp.StatusCode)
+			if tt.expectCode != http.StatusOK {
+				return
+			}
+			var tools []Tool
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&tools))
+			var names []string
+			for _, tool := range tools {
+				assert.Equal(t, ToolTypeFunction, tool.Type)
+				assert.True(t, json.Valid(tool.Function.Parameters), tool.Function.Name)
+				names = append(names, tool.Function.Name)
+			}
+			assert.Equal(t, []string{ToolRunCommand, ToolReadFile, ToolWriteFile}, names)
+		})
+	}
+}
+
+func TestServer_CallTools(t *testing.T) {
+	runtime := &fakeRuntime{
+		files:    map[string]string{"/workspace/a.txt": "hello"},
+		stdout:   "out",
+		stderr:   "err",
+		exitCode: 1,
+	}
+	server := newTestServer(runtime)
+	defer server.Close()
+
+	request := ToolCallsRequest{ToolCalls: []ToolCall{
+		{ID: "call-1", Type: ToolTypeFunction, Function: FunctionCall{Name: ToolRunCommand, Arguments: `{"command":"ls /"}`}},
+		{ID: "call-2", Type: ToolTypeFunction, Function: FunctionCall{Name: ToolReadFile, Arguments: `{"path":"/workspace/a.txt"}`}},
+		{ID: "call-3", Type: ToolTypeFunction, Function: FunctionCall{Name: ToolWriteFile, Arguments: `{"path":"/workspace/b.txt","content":"world"}`}},
+		{ID: "call-4", Type: ToolTypeFunction, Function: FunctionCall{Name: ToolReadFile, Arguments: `{"path":"/not-exist"}`}},
+		{ID: "call-5", Type: ToolTypeFunction, Function: FunctionCall{Name: "unknown", Arguments: `{}`}},
+		{ID: "call-6", Type: ToolTypeFunction, Function: FunctionCall{Name: ToolRunCommand, Arguments: `not json`}},
+	}}
+	resp := doRequest(t, http.MethodPost, fmt.Sprintf("%s/sessions/%s/tool_calls", server.URL, testSandboxID), testAPIKey, request)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	response := ToolCallsResponse{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+
+	expectContents := []string{
+		`{"exit_code":1,"stdout":"out","stderr":"err"}`,
+		"hello",
+		"wrote 5 bytes to /workspace/b.txt",
+		"error: status code 404: file not found",
+		`error: unknown tool "unknown"`,
+		"error: invalid arguments of run_command",
+	}
+	require.Len(t, response.Messages, len(expectContents))
+	for i, message := range response.Messages {
+		assert.Equal(t, RoleTool, message.Role)
+		assert.Equal(t, request.ToolCalls[i].ID, message.ToolCallID)
+		assert.True(t, strings.HasPrefix(message.Content, expectContents[i]), message.Content)
+	}
+	assert.Equal(t, [][]string{{"/bin/bash", "-l", "-c", "ls /"}}, runtime.commands)
+	assert.Equal(t, "world", runtime.files["/workspace/b.txt"])
+
+	resp = doRequest(t, http.MethodPost, fmt.Sprintf("%s/sessions/%s/tool_calls", server.URL, testSandboxID), "wrong", request)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	apiErr := web.ApiError{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&apiErr))
+	assert.Equal(t, "Invalid API Key", apiErr.Message)
+}
+
+func TestLimitedBuffer(t *testing.T) {
+	tests := []struct {
+		name   string
+		writes []string
+		expect string
+	}{
+		{name: "within limit", writes: []string{"ab", "cd"}, expect: "abcd"},
+		{name: "exceeds limit", writes: []string{"abc", "def"}, expect: "abcde\n...(truncated)"},
+		{name: "write after full", writes: []string{"abcde", "f", "g"}, expect: "abcde\n...(truncated)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &limitedBuffer{limit: 5}
+			for _, w := range tt.writes {
+				n, err := buf.Write([]byte(w))
+				require.NoError(t, err)
+				assert.Equal(t, len(w), n)
+			}
+			assert.Equal(t, tt.expect, buf.String())
+		})
+	}
+}
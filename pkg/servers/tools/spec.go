@@ -0,0 +1,40 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tools
+
+import "encoding/json"
+
+//go:generate go run ./gen -o ../../../sdk/langchain/kruise_agents_langchain/tools.json
+
+// SpecVersion is bumped on breaking changes of the tools, e.g. renamed tools or arguments.
+const SpecVersion = "v1"
+
+// Spec is the tool definitions shipped with the adapters in other languages, which must be regenerated
+// with `go generate ./pkg/servers/tools/` whenever the definitions change.
+type Spec struct {
+	Version string `json:"version"`
+	Tools   []Tool `json:"tools"`
+}
+
+// MarshalSpec returns the content of the generated tool spec file.
+func MarshalSpec() ([]byte, error) {
+	data, err := json.MarshalIndent(Spec{Version: SpecVersion, Tools: Definitions()}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
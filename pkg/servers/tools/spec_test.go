@@ -0,0 +1,34 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tools
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSpecUpToDate keeps the Python adapters in lockstep with the tool definitions.
+func TestSpecUpToDate(t *testing.T) {
+	expect, err := MarshalSpec()
+	require.NoError(t, err)
+	actual, err := os.ReadFile("../../../sdk/langchain/kruise_agents_langchain/tools.json")
+	require.NoError(t, err)
+	assert.Equal(t, string(expect), string(actual), "tool spec is out of date, run `go generate ./pkg/servers/tools/`")
+}
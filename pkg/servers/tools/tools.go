@@ -0,0 +1,203 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	ToolRunCommand = "run_command"
+	ToolReadFile   = "read_file"
+	ToolWriteFile  = "write_file"
+
+	// MaxOutputBytes limits each output returned to the model, so that a noisy command does not use up its context.
+	MaxOutputBytes = 32 * 1024
+	// MaxCommandTimeout limits the timeout of run_command.
+	MaxCommandTimeout = 10 * time.Minute
+	// DefaultCommandTimeout is used when run_command has no timeout.
+	DefaultCommandTimeout = time.Minute
+)
+
+// SandboxRuntime is the part of the agent runtime of a claimed sandbox used by the tools.
+type SandboxRuntime interface {
+	Exec(ctx context.Context, cmd []string, envs map[string]string, cwd string, stdout, stderr io.Writer) (int32, error)
+	Download(ctx context.Context, path string, w io.Writer) error
+	Upload(ctx context.Context, path string, reader io.Reader) error
+}
+
+// Definitions returns the tools exposed for every session.
+func Definitions() []Tool {
+	return []Tool{
+		{
+			Type: ToolTypeFunction,
+			Function: FunctionDefinition{
+				Name:        ToolRunCommand,
+				Description: "Run a shell command in the sandbox and return its exit code, stdout and stderr.",
+				Parameters: json.RawMessage(`{"type":"object","properties":{` +
+					`"command":{"type":"string","description":"The command to run with bash -c"},` +
+					`"cwd":{"type":"string","description":"Working directory of the command"},` +
+					`"timeout":{"type":"integer","description":"Timeout of the command in seconds"}},` +
+					`"required":["command"]}`),
+			},
+		},
+		{
+			Type: ToolTypeFunction,
+			Function: FunctionDefinition{
+				Name:        ToolReadFile,
+				Description: "Read a text file in the sandbox.",
+				Parameters: json.RawMessage(`{"type":"object","properties":{` +
+					`"path":{"type":"string","description":"Absolute path of the file"}},` +
+					`"required":["path"]}`),
+			},
+		},
+		{
+			Type: ToolTypeFunction,
+			Function: FunctionDefinition{
+				Name:        ToolWriteFile,
+				Description: "Create or overwrite a text file in the sandbox.",
+				Parameters: json.RawMessage(`{"type":"object","properties":{` +
+					`"path":{"type":"string","description":"Absolute path of the file"},` +
+					`"content":{"type":"string","description":"Content of the file"}},` +
+					`"required":["path","content"]}`),
+			},
+		},
+	}
+}
+
+type runCommandArgs struct {
+	Command string `json:"command"`
+	Cwd     string `json:"cwd,omitempty"`
+	Timeout int    `json:"timeout,omitempty"`
+}
+
+type runCommandResult struct {
+	ExitCode int32  `json:"exit_code"`
+	Stdout   string `json:"stdout"`
+	Stderr   string `json:"stderr"`
+}
+
+type fileArgs struct {
+	Path    string `json:"path"`
+	Content string `json:"content,omitempty"`
+}
+
+// Call runs a tool call in the sandbox. Failures of the tool itself, e.g. a missing file, are reported to the
+// model in the content of the message instead of failing the request, so that the model can react to them.
+func Call(ctx context.Context, runtime SandboxRuntime, call ToolCall) ToolMessage {
+	content, err := callFunction(ctx, runtime, call.Function)
+	if err != nil {
+		content = fmt.Sprintf("error: %s", err.Error())
+	}
+	return ToolMessage{
+		Role:       RoleTool,
+		ToolCallID: call.ID,
+		Content:    content,
+	}
+}
+
+func callFunction(ctx context.Context, runtime SandboxRuntime, function FunctionCall) (string, error) {
+	switch function.Name {
+	case ToolRunCommand:
+		args := runCommandArgs{}
+		if err := unmarshalArgs(function, &args); err != nil {
+			return "", err
+		}
+		return runCommand(ctx, runtime, args)
+	case ToolReadFile:
+		args := fileArgs{}
+		if err := unmarshalArgs(function, &args); err != nil {
+			return "", err
+		}
+		out := &limitedBuffer{limit: MaxOutputBytes}
+		if err := runtime.Download(ctx, args.Path, out); err != nil {
+			return "", err
+		}
+		return out.String(), nil
+	case ToolWriteFile:
+		args := fileArgs{}
+		if err := unmarshalArgs(function, &args); err != nil {
+			return "", err
+		}
+		if err := runtime.Upload(ctx, args.Path, strings.NewReader(args.Content)); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("wrote %d bytes to %s", len(args.Content), args.Path), nil
+	default:
+		return "", fmt.Errorf("unknown tool %q", function.Name)
+	}
+}
+
+func unmarshalArgs(function FunctionCall, args any) error {
+	if err := json.Unmarshal([]byte(function.Arguments), args); err != nil {
+		return fmt.Errorf("invalid arguments of %s: %w", function.Name, err)
+	}
+	return nil
+}
+
+func runCommand(ctx context.Context, runtime SandboxRuntime, args runCommandArgs) (string, error) {
+	if args.Command == "" {
+		return "", fmt.Errorf("command is required")
+	}
+	timeout := DefaultCommandTimeout
+	if args.Timeout > 0 {
+		timeout = min(time.Duration(args.Timeout)*time.Second, MaxCommandTimeout)
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	stdout, stderr := &limitedBuffer{limit: MaxOutputBytes}, &limitedBuffer{limit: MaxOutputBytes}
+	exitCode, err := runtime.Exec(ctx, []string{"/bin/bash", "-l", "-c", args.Command}, nil, args.Cwd, stdout, stderr)
+	if err != nil {
+		return "", err
+	}
+	result, err := json.Marshal(runCommandResult{
+		ExitCode: exitCode,
+		Stdout:   stdout.String(),
+		Stderr:   stderr.String(),
+	})
+	return string(result), err
+}
+
+// limitedBuffer keeps the first limit bytes written to it and drops the rest.
+type limitedBuffer struct {
+	buf       bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if remain := b.limit - b.buf.Len(); remain < len(p) {
+		b.truncated = true
+		b.buf.Write(p[:max(remain, 0)])
+	} else {
+		b.buf.Write(p)
+	}
+	return len(p), nil
+}
+
+func (b *limitedBuffer) String() string {
+	if b.truncated {
+		return b.buf.String() + "\n...(truncated)"
+	}
+	return b.buf.String()
+}
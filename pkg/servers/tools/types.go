@@ -0,0 +1,71 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tools
+
+import "encoding/json"
+
+// The types below follow the tools / function calling format of the OpenAI chat completions API, so that the
+// tool definitions can be passed as the `tools` of a request, and the `tool_calls` of a response can be posted
+// back as-is.
+
+const (
+	ToolTypeFunction = "function"
+	RoleTool         = "tool"
+)
+
+// Tool is an entry of the `tools` of a chat completions request.
+type Tool struct {
+	Type     string             `json:"type"`
+	Function FunctionDefinition `json:"function"`
+}
+
+// FunctionDefinition describes a function the model may call, Parameters is a JSON schema object.
+type FunctionDefinition struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	Parameters  json.RawMessage `json:"parameters"`
+}
+
+// ToolCall is an entry of the `tool_calls` of an assistant message.
+type ToolCall struct {
+	ID       string       `json:"id"`
+	Type     string       `json:"type"`
+	Function FunctionCall `json:"function"`
+}
+
+// FunctionCall is the function and its JSON encoded arguments generated by the model.
+type FunctionCall struct {
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"`
+}
+
+// ToolMessage is the message carrying the result of a tool call back to the model.
+type ToolMessage struct {
+	Role       string `json:"role"`
+	ToolCallID string `json:"tool_call_id"`
+	Content    string `json:"content"`
+}
+
+// ToolCallsRequest is the body of POST /sessions/{sandboxID}/tool_calls.
+type ToolCallsRequest struct {
+	ToolCalls []ToolCall `json:"tool_calls"`
+}
+
+// ToolCallsResponse contains one ToolMessage for each ToolCall, in the same order.
+type ToolCallsResponse struct {
+	Messages []ToolMessage `json:"messages"`
+}
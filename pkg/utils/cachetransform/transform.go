@@ -0,0 +1,81 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package cachetransform trims objects before they are stored in informer caches, which dominate the resident
+// memory of the controller-manager and the sandbox-manager at large Sandbox scale.
+package cachetransform
+
+import (
+	"strings"
+
+	"github.com/spf13/pflag"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	toolscache "k8s.io/client-go/tools/cache"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+)
+
+// Options controls how objects are trimmed before cached.
+type Options struct {
+	// StripManagedFields drops metadata.managedFields of all cached objects. Nothing reads them from the cache.
+	StripManagedFields bool
+	// MaxSandboxAnnotationBytes truncates the annotations of cached Sandboxes longer than it, except the ones
+	// with the agents.kruise.io/ prefix. Zero disables the truncation.
+	MaxSandboxAnnotationBytes int
+}
+
+// DefaultOptions is set by the command line flags and used by all informer caches of the process.
+var DefaultOptions = Options{
+	StripManagedFields: true,
+}
+
+// AddFlags registers the flags of the options.
+func (o *Options) AddFlags(fs *pflag.FlagSet) {
+	fs.BoolVar(&o.StripManagedFields, "cache-strip-managed-fields", o.StripManagedFields,
+		"Drop metadata.managedFields of objects in informer caches to reduce memory.")
+	fs.IntVar(&o.MaxSandboxAnnotationBytes, "cache-max-sandbox-annotation-bytes", o.MaxSandboxAnnotationBytes,
+		"Truncate annotations of Sandboxes in informer caches longer than this, except agents.kruise.io/ ones. "+
+			"Sandboxes updated from the cache lose the truncated part, so only enable it if such annotations "+
+			"are not needed after creation, e.g. kubectl.kubernetes.io/last-applied-configuration. 0 disables it.")
+}
+
+// TransformFunc returns the transform function of informers trimming objects by the options.
+func (o Options) TransformFunc() toolscache.TransformFunc {
+	return func(obj interface{}) (interface{}, error) {
+		accessor, ok := obj.(metav1.Object)
+		if !ok {
+			// e.g. DeletedFinalStateUnknown
+			return obj, nil
+		}
+		if o.StripManagedFields {
+			accessor.SetManagedFields(nil)
+		}
+		if _, isSandbox := obj.(*agentsv1alpha1.Sandbox); isSandbox && o.MaxSandboxAnnotationBytes > 0 {
+			truncateAnnotations(accessor, o.MaxSandboxAnnotationBytes)
+		}
+		return obj, nil
+	}
+}
+
+func truncateAnnotations(obj metav1.Object, maxBytes int) {
+	annotations := obj.GetAnnotations()
+	for key, value := range annotations {
+		if len(value) <= maxBytes || strings.HasPrefix(key, agentsv1alpha1.InternalPrefix) {
+			continue
+		}
+		annotations[key] = value[:maxBytes]
+	}
+}
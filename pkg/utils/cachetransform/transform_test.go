@@ -0,0 +1,102 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cachetransform
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	toolscache "k8s.io/client-go/tools/cache"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+)
+
+func TestOptions_TransformFunc(t *testing.T) {
+	large := strings.Repeat("x", 100)
+	newMeta := func() metav1.ObjectMeta {
+		return metav1.ObjectMeta{
+			Name:          "test",
+			ManagedFields: []metav1.ManagedFieldsEntry{{Manager: "kubectl"}},
+			Annotations: map[string]string{
+				"kubectl.kubernetes.io/last-applied-configuration": large,
+				agentsv1alpha1.AnnotationInitRuntimeRequest:        large,
+				"small": "value",
+			},
+		}
+	}
+
+	tests := []struct {
+		name                string
+		opts                Options
+		obj                 metav1.Object
+		expectManagedFields bool
+		expectTruncated     bool
+	}{
+		{
+			name:                "disabled",
+			opts:                Options{},
+			obj:                 &agentsv1alpha1.Sandbox{ObjectMeta: newMeta()},
+			expectManagedFields: true,
+		},
+		{
+			name:            "strip managed fields and truncate annotations of sandbox",
+			opts:            Options{StripManagedFields: true, MaxSandboxAnnotationBytes: 10},
+			obj:             &agentsv1alpha1.Sandbox{ObjectMeta: newMeta()},
+			expectTruncated: true,
+		},
+		{
+			name: "annotations of other objects are not truncated",
+			opts: Options{StripManagedFields: true, MaxSandboxAnnotationBytes: 10},
+			obj:  &corev1.Pod{ObjectMeta: newMeta()},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.opts.TransformFunc()(tt.obj)
+			require.NoError(t, err)
+			obj := got.(metav1.Object)
+			assert.Equal(t, tt.expectManagedFields, obj.GetManagedFields() != nil)
+			expectLastApplied := large
+			if tt.expectTruncated {
+				expectLastApplied = large[:10]
+			}
+			assert.Equal(t, expectLastApplied, obj.GetAnnotations()["kubectl.kubernetes.io/last-applied-configuration"])
+			// internal annotations are never truncated
+			assert.Equal(t, large, obj.GetAnnotations()[agentsv1alpha1.AnnotationInitRuntimeRequest])
+			assert.Equal(t, "value", obj.GetAnnotations()["small"])
+		})
+	}
+
+	tombstone := toolscache.DeletedFinalStateUnknown{Key: "default/test"}
+	got, err := DefaultOptions.TransformFunc()(tombstone)
+	require.NoError(t, err)
+	assert.Equal(t, tombstone, got)
+}
+
+func TestOptions_AddFlags(t *testing.T) {
+	opts := DefaultOptions
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	opts.AddFlags(fs)
+	require.NoError(t, fs.Parse([]string{"--cache-strip-managed-fields=false", "--cache-max-sandbox-annotation-bytes=1024"}))
+	assert.Equal(t, Options{StripManagedFields: false, MaxSandboxAnnotationBytes: 1024}, opts)
+	assert.True(t, DefaultOptions.StripManagedFields)
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package claimlatency tracks the latency of SandboxClaims, from the start of claiming to all replicas being claimed,
+// per template. The latencies are exported as the sandboxclaim_claim_latency_seconds histogram, while the recent ones
+// are kept in memory so that the webhooks in the same process can read their percentiles.
+package claimlatency
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	// windowSize is the number of recent claim latencies kept per template
+	windowSize = 200
+	// minSamples is the number of claim latencies of a template required before its percentiles are reported
+	minSamples = 20
+)
+
+var claimLatencySeconds = prometheus.NewHistogramVec(
+	prometheus.HistogramOpts{
+		Name:    "sandboxclaim_claim_latency_seconds",
+		Help:    "Latency of SandboxClaims from the start of claiming to all replicas being claimed, by template",
+		Buckets: prometheus.ExponentialBuckets(0.1, 2, 14),
+	},
+	[]string{"namespace", "template"},
+)
+
+func init() {
+	metrics.Registry.MustRegister(claimLatencySeconds)
+}
+
+// Default is the Tracker observed by the SandboxClaim controller
+var Default = NewTracker()
+
+// Tracker keeps the recent claim latencies of each template.
+type Tracker struct {
+	mu sync.Mutex
+	// templates is a sliding window of latencies per namespace/template, oldest first
+	templates map[string][]time.Duration
+}
+
+func NewTracker() *Tracker {
+	return &Tracker{templates: make(map[string][]time.Duration)}
+}
+
+// Observe records the latency of a claim of the template.
+func (t *Tracker) Observe(namespace, template string, latency time.Duration) {
+	claimLatencySeconds.WithLabelValues(namespace, template).Observe(latency.Seconds())
+	key := namespace + "/" + template
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	window := append(t.templates[key], latency)
+	if len(window) > windowSize {
+		window = window[len(window)-windowSize:]
+	}
+	t.templates[key] = window
+}
+
+// Percentile returns the nearest-rank p-th percentile of the recent claim latencies of the template, false if too
+// few claims are observed for it to be meaningful.
+func (t *Tracker) Percentile(namespace, template string, p int) (time.Duration, bool) {
+	t.mu.Lock()
+	sorted := slices.Clone(t.templates[namespace+"/"+template])
+	t.mu.Unlock()
+	if len(sorted) < minSamples {
+		return 0, false
+	}
+	slices.Sort(sorted)
+	rank := (len(sorted)*p + 99) / 100
+	return sorted[max(rank, 1)-1], true
+}
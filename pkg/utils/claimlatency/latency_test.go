@@ -0,0 +1,83 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package claimlatency
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTracker_Percentile(t *testing.T) {
+	tests := []struct {
+		name      string
+		latencies []time.Duration
+		p         int
+		expect    time.Duration
+		expectOK  bool
+	}{
+		{
+			name:      "too few samples",
+			latencies: []time.Duration{time.Second, 2 * time.Second},
+			p:         99,
+		},
+		{
+			name:      "p99 of samples",
+			latencies: seconds(1, 100),
+			p:         99,
+			expect:    99 * time.Second,
+			expectOK:  true,
+		},
+		{
+			name:      "p50 of samples",
+			latencies: seconds(1, 100),
+			p:         50,
+			expect:    50 * time.Second,
+			expectOK:  true,
+		},
+		{
+			name:      "only the recent samples are kept",
+			latencies: append(seconds(1000, 1000+windowSize), seconds(1, windowSize)...),
+			p:         100,
+			expect:    windowSize * time.Second,
+			expectOK:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker := NewTracker()
+			for _, latency := range tt.latencies {
+				tracker.Observe("default", "pool", latency)
+			}
+			got, ok := tracker.Percentile("default", "pool", tt.p)
+			assert.Equal(t, tt.expectOK, ok)
+			assert.Equal(t, tt.expect, got)
+			_, ok = tracker.Percentile("default", "other", tt.p)
+			assert.False(t, ok, "latencies of other templates should not be mixed")
+		})
+	}
+}
+
+// seconds returns the latencies from..to seconds in descending order
+func seconds(from, to int) []time.Duration {
+	latencies := make([]time.Duration, 0, to-from+1)
+	for i := to; i >= from; i-- {
+		latencies = append(latencies, time.Duration(i)*time.Second)
+	}
+	return latencies
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package conditions provides typed helpers for manipulating metav1.Condition slices
+// shared by all controllers. It follows k8s.io/apimachinery meta.SetStatusCondition semantics:
+// LastTransitionTime is only bumped when the condition status changes, and ObservedGeneration
+// is recorded on every condition.
+package conditions
+
+import (
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Set sets the corresponding condition in conditions to newCondition and returns true
+// if the conditions are changed by this call.
+//  1. if the condition of the specified type already exists, all fields of the existing condition are updated to
+//     newCondition, LastTransitionTime is set to now if the new status differs from the old status
+//  2. if a condition of the specified type does not exist, LastTransitionTime is set to now() if unset,
+//     and newCondition is appended
+func Set(conditions *[]metav1.Condition, newCondition metav1.Condition) bool {
+	return apimeta.SetStatusCondition(conditions, newCondition)
+}
+
+// Get returns the condition with the provided type, or nil if it does not exist.
+func Get(conditions []metav1.Condition, condType string) *metav1.Condition {
+	return apimeta.FindStatusCondition(conditions, condType)
+}
+
+// Remove removes the condition with the provided type and returns true if it existed.
+func Remove(conditions *[]metav1.Condition, condType string) bool {
+	return apimeta.RemoveStatusCondition(conditions, condType)
+}
+
+// IsTrue returns true if the condition with the provided type is present and set to True.
+func IsTrue(conditions []metav1.Condition, condType string) bool {
+	return apimeta.IsStatusConditionTrue(conditions, condType)
+}
+
+// IsFalse returns true if the condition with the provided type is present and set to False.
+func IsFalse(conditions []metav1.Condition, condType string) bool {
+	return apimeta.IsStatusConditionFalse(conditions, condType)
+}
+
+// Builder accumulates condition changes for a status object. All conditions written by the
+// same Builder share the ObservedGeneration and the LastTransitionTime used for new transitions,
+// so a single reconcile produces consistent timestamps.
+type Builder struct {
+	conditions *[]metav1.Condition
+	generation int64
+	now        metav1.Time
+	changed    bool
+}
+
+// NewBuilder creates a Builder writing into conditions with the given observed generation.
+func NewBuilder(conditions *[]metav1.Condition, generation int64) *Builder {
+	return &Builder{
+		conditions: conditions,
+		generation: generation,
+		now:        metav1.Now(),
+	}
+}
+
+// WithTime overrides the timestamp used for LastTransitionTime, mainly for tests.
+func (b *Builder) WithTime(now metav1.Time) *Builder {
+	b.now = now
+	return b
+}
+
+// Now returns the timestamp used by this Builder, so callers can stamp related status fields consistently.
+func (b *Builder) Now() metav1.Time {
+	return b.now
+}
+
+// Set records a condition with the given type, status, reason and message.
+func (b *Builder) Set(condType string, status metav1.ConditionStatus, reason, message string) *Builder {
+	if Set(b.conditions, metav1.Condition{
+		Type:               condType,
+		Status:             status,
+		ObservedGeneration: b.generation,
+		LastTransitionTime: b.now,
+		Reason:             reason,
+		Message:            message,
+	}) {
+		b.changed = true
+	}
+	return b
+}
+
+// True records a condition with status True.
+func (b *Builder) True(condType, reason, message string) *Builder {
+	return b.Set(condType, metav1.ConditionTrue, reason, message)
+}
+
+// False records a condition with status False.
+func (b *Builder) False(condType, reason, message string) *Builder {
+	return b.Set(condType, metav1.ConditionFalse, reason, message)
+}
+
+// Unknown records a condition with status Unknown.
+func (b *Builder) Unknown(condType, reason, message string) *Builder {
+	return b.Set(condType, metav1.ConditionUnknown, reason, message)
+}
+
+// Remove deletes the condition with the given type.
+func (b *Builder) Remove(condType string) *Builder {
+	if Remove(b.conditions, condType) {
+		b.changed = true
+	}
+	return b
+}
+
+// Changed reports whether any call on this Builder modified the conditions.
+func (b *Builder) Changed() bool {
+	return b.changed
+}
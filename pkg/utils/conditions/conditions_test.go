@@ -0,0 +1,184 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conditions
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSet(t *testing.T) {
+	now := metav1.Now()
+	originalTime := metav1.NewTime(now.Add(-10 * time.Minute))
+
+	tests := []struct {
+		name              string
+		existing          []metav1.Condition
+		condition         metav1.Condition
+		expectChanged     bool
+		expectLength      int
+		expectTransition  metav1.Time
+		expectReason      string
+		expectGeneration  int64
+		expectFinalStatus metav1.ConditionStatus
+	}{
+		{
+			name: "add first condition",
+			condition: metav1.Condition{
+				Type: "Ready", Status: metav1.ConditionTrue, Reason: "AllReady", LastTransitionTime: now, ObservedGeneration: 1,
+			},
+			expectChanged:     true,
+			expectLength:      1,
+			expectTransition:  now,
+			expectReason:      "AllReady",
+			expectGeneration:  1,
+			expectFinalStatus: metav1.ConditionTrue,
+		},
+		{
+			name: "no change when nothing changes",
+			existing: []metav1.Condition{
+				{Type: "Ready", Status: metav1.ConditionTrue, Reason: "AllReady", Message: "ok", LastTransitionTime: originalTime, ObservedGeneration: 1},
+			},
+			condition: metav1.Condition{
+				Type: "Ready", Status: metav1.ConditionTrue, Reason: "AllReady", Message: "ok", LastTransitionTime: now, ObservedGeneration: 1,
+			},
+			expectChanged:     false,
+			expectLength:      1,
+			expectTransition:  originalTime,
+			expectReason:      "AllReady",
+			expectGeneration:  1,
+			expectFinalStatus: metav1.ConditionTrue,
+		},
+		{
+			name: "preserve LastTransitionTime when only reason and generation change",
+			existing: []metav1.Condition{
+				{Type: "Ready", Status: metav1.ConditionTrue, Reason: "OldReason", LastTransitionTime: originalTime, ObservedGeneration: 1},
+			},
+			condition: metav1.Condition{
+				Type: "Ready", Status: metav1.ConditionTrue, Reason: "NewReason", LastTransitionTime: now, ObservedGeneration: 2,
+			},
+			expectChanged:     true,
+			expectLength:      1,
+			expectTransition:  originalTime,
+			expectReason:      "NewReason",
+			expectGeneration:  2,
+			expectFinalStatus: metav1.ConditionTrue,
+		},
+		{
+			name: "update LastTransitionTime when status changes",
+			existing: []metav1.Condition{
+				{Type: "Ready", Status: metav1.ConditionTrue, Reason: "AllReady", LastTransitionTime: originalTime},
+			},
+			condition: metav1.Condition{
+				Type: "Ready", Status: metav1.ConditionFalse, Reason: "NotReady", LastTransitionTime: now,
+			},
+			expectChanged:     true,
+			expectLength:      1,
+			expectTransition:  now,
+			expectReason:      "NotReady",
+			expectFinalStatus: metav1.ConditionFalse,
+		},
+		{
+			name: "add second condition",
+			existing: []metav1.Condition{
+				{Type: "Completed", Status: metav1.ConditionTrue, Reason: "Done", LastTransitionTime: originalTime},
+			},
+			condition: metav1.Condition{
+				Type: "Ready", Status: metav1.ConditionFalse, Reason: "NotReady", LastTransitionTime: now,
+			},
+			expectChanged:     true,
+			expectLength:      2,
+			expectTransition:  now,
+			expectReason:      "NotReady",
+			expectFinalStatus: metav1.ConditionFalse,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conds := append([]metav1.Condition(nil), tt.existing...)
+			changed := Set(&conds, tt.condition)
+			assert.Equal(t, tt.expectChanged, changed)
+			assert.Len(t, conds, tt.expectLength)
+			got := Get(conds, tt.condition.Type)
+			if assert.NotNil(t, got) {
+				assert.True(t, tt.expectTransition.Equal(&got.LastTransitionTime))
+				assert.Equal(t, tt.expectReason, got.Reason)
+				assert.Equal(t, tt.expectGeneration, got.ObservedGeneration)
+				assert.Equal(t, tt.expectFinalStatus, got.Status)
+			}
+		})
+	}
+}
+
+func TestGetAndRemove(t *testing.T) {
+	conds := []metav1.Condition{
+		{Type: "Ready", Status: metav1.ConditionTrue},
+		{Type: "Completed", Status: metav1.ConditionFalse},
+	}
+	assert.NotNil(t, Get(conds, "Ready"))
+	assert.Nil(t, Get(conds, "NotExist"))
+	assert.Nil(t, Get(nil, "Ready"))
+	assert.True(t, IsTrue(conds, "Ready"))
+	assert.True(t, IsFalse(conds, "Completed"))
+	assert.False(t, IsTrue(conds, "NotExist"))
+
+	assert.True(t, Remove(&conds, "Ready"))
+	assert.False(t, Remove(&conds, "Ready"))
+	assert.Len(t, conds, 1)
+}
+
+func TestBuilder(t *testing.T) {
+	now := metav1.Now()
+	past := metav1.NewTime(now.Add(-time.Hour))
+	conds := []metav1.Condition{
+		{Type: "Ready", Status: metav1.ConditionTrue, Reason: "AllReady", LastTransitionTime: past, ObservedGeneration: 1},
+		{Type: "Stale", Status: metav1.ConditionTrue, Reason: "Old", LastTransitionTime: past},
+	}
+
+	builder := NewBuilder(&conds, 3).WithTime(now)
+	got := builder.Now()
+	assert.True(t, got.Equal(&now))
+	assert.False(t, builder.Changed())
+
+	builder.True("Ready", "AllReady", "").
+		False("Completed", "InProgress", "claiming").
+		Unknown("Probe", "Pending", "").
+		Remove("Stale")
+	assert.True(t, builder.Changed())
+	assert.Len(t, conds, 3)
+
+	ready := Get(conds, "Ready")
+	assert.True(t, ready.LastTransitionTime.Equal(&past), "status unchanged keeps transition time")
+	assert.Equal(t, int64(3), ready.ObservedGeneration)
+
+	completed := Get(conds, "Completed")
+	assert.True(t, completed.LastTransitionTime.Equal(&now))
+	assert.Equal(t, metav1.ConditionFalse, completed.Status)
+	assert.Equal(t, metav1.ConditionUnknown, Get(conds, "Probe").Status)
+	assert.Nil(t, Get(conds, "Stale"))
+
+	// A second identical pass must not report any change.
+	again := NewBuilder(&conds, 3).
+		True("Ready", "AllReady", "").
+		False("Completed", "InProgress", "claiming").
+		Unknown("Probe", "Pending", "")
+	assert.False(t, again.Changed())
+}
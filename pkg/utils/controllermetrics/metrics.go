@@ -0,0 +1,225 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package controllermetrics provides the standard metrics of every controller, all registered under the
+// agents_controller prefix and labeled by controller name, so that operators can compare the controllers
+// and see which one is falling behind:
+//
+//   - agents_controller_workqueue_depth: current depth of the workqueue.
+//   - agents_controller_workqueue_adds_total: items added to the workqueue, including delayed and rate limited adds.
+//   - agents_controller_reconcile_total: reconciles by result (success, error, requeue, requeue_after).
+//   - agents_controller_reconcile_errors_total: reconciles returning an error.
+//   - agents_controller_reconcile_duration_seconds: reconcile duration, with the trace ID of the reconcile context
+//     attached as an exemplar when the context carries a sampled span.
+//
+// Exemplars are only exposed in the OpenMetrics format, which is served at OpenMetricsPath.
+package controllermetrics
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/otel/trace"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	namespace = "agents"
+	subsystem = "controller"
+
+	// OpenMetricsPath is the path of the metrics server serving metrics in the OpenMetrics format with exemplars.
+	OpenMetricsPath = "/metrics/openmetrics"
+
+	ResultSuccess      = "success"
+	ResultError        = "error"
+	ResultRequeue      = "requeue"
+	ResultRequeueAfter = "requeue_after"
+)
+
+var (
+	workqueueAdds = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "workqueue_adds_total",
+			Help:      "Total number of items added to the workqueue of the controller",
+		},
+		[]string{"controller"},
+	)
+
+	reconcileTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "reconcile_total",
+			Help:      "Total number of reconciles of the controller, by result",
+		},
+		[]string{"controller", "result"},
+	)
+
+	reconcileErrors = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "reconcile_errors_total",
+			Help:      "Total number of reconciles of the controller returning an error",
+		},
+		[]string{"controller"},
+	)
+
+	reconcileDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "reconcile_duration_seconds",
+			Help:      "Duration of reconciles of the controller in seconds",
+			Buckets:   []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60},
+		},
+		[]string{"controller"},
+	)
+
+	depths = &depthCollector{
+		desc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "workqueue_depth"),
+			"Current depth of the workqueue of the controller",
+			[]string{"controller"}, nil,
+		),
+		queues: make(map[string]func() int),
+	}
+)
+
+func init() {
+	metrics.Registry.MustRegister(workqueueAdds, reconcileTotal, reconcileErrors, reconcileDuration, depths)
+}
+
+// OpenMetricsHandler serves the metrics of the controller-runtime registry in the OpenMetrics format,
+// which is the only format exposing exemplars.
+func OpenMetricsHandler() http.Handler {
+	return promhttp.HandlerFor(metrics.Registry, promhttp.HandlerOpts{
+		ErrorHandling:     promhttp.HTTPErrorOnError,
+		EnableOpenMetrics: true,
+	})
+}
+
+// depthCollector reads the depth of the workqueues on scraping.
+type depthCollector struct {
+	desc   *prometheus.Desc
+	mu     sync.Mutex
+	queues map[string]func() int
+}
+
+func (c *depthCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+func (c *depthCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for controller, depth := range c.queues {
+		ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, float64(depth()), controller)
+	}
+}
+
+func (c *depthCollector) set(controller string, depth func() int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.queues[controller] = depth
+}
+
+// NewQueue is the NewQueue option of controllers, creating the default rate limiting queue of controller-runtime
+// with the depth and adds of the queue recorded.
+func NewQueue(controllerName string, rateLimiter workqueue.TypedRateLimiter[reconcile.Request]) workqueue.TypedRateLimitingInterface[reconcile.Request] {
+	queue := workqueue.NewTypedRateLimitingQueueWithConfig(rateLimiter, workqueue.TypedRateLimitingQueueConfig[reconcile.Request]{
+		Name: controllerName,
+	})
+	return InstrumentQueue(controllerName, queue)
+}
+
+// InstrumentQueue records the depth and adds of the queue of the controller.
+func InstrumentQueue[T comparable](controllerName string, queue workqueue.TypedRateLimitingInterface[T]) workqueue.TypedRateLimitingInterface[T] {
+	depths.set(controllerName, queue.Len)
+	return &instrumentedQueue[T]{
+		TypedRateLimitingInterface: queue,
+		adds:                       workqueueAdds.WithLabelValues(controllerName),
+	}
+}
+
+type instrumentedQueue[T comparable] struct {
+	workqueue.TypedRateLimitingInterface[T]
+	adds prometheus.Counter
+}
+
+func (q *instrumentedQueue[T]) Add(item T) {
+	q.adds.Inc()
+	q.TypedRateLimitingInterface.Add(item)
+}
+
+func (q *instrumentedQueue[T]) AddAfter(item T, duration time.Duration) {
+	q.adds.Inc()
+	q.TypedRateLimitingInterface.AddAfter(item, duration)
+}
+
+func (q *instrumentedQueue[T]) AddRateLimited(item T) {
+	q.adds.Inc()
+	q.TypedRateLimitingInterface.AddRateLimited(item)
+}
+
+// Reconciler records the reconcile metrics of the wrapped reconciler.
+type Reconciler struct {
+	reconcile.Reconciler
+	controller string
+}
+
+// Wrap returns a reconciler recording the reconcile metrics of the controller.
+func Wrap(controllerName string, r reconcile.Reconciler) *Reconciler {
+	return &Reconciler{Reconciler: r, controller: controllerName}
+}
+
+func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	start := time.Now()
+	result, err := r.Reconciler.Reconcile(ctx, req)
+	observe(ctx, r.controller, time.Since(start), result, err)
+	return result, err
+}
+
+func observe(ctx context.Context, controller string, duration time.Duration, result reconcile.Result, err error) {
+	histogram := reconcileDuration.WithLabelValues(controller)
+	if sc := trace.SpanContextFromContext(ctx); sc.IsSampled() {
+		histogram.(prometheus.ExemplarObserver).ObserveWithExemplar(duration.Seconds(), prometheus.Labels{
+			"trace_id": sc.TraceID().String(),
+		})
+	} else {
+		histogram.Observe(duration.Seconds())
+	}
+
+	switch {
+	case err != nil:
+		reconcileErrors.WithLabelValues(controller).Inc()
+		reconcileTotal.WithLabelValues(controller, ResultError).Inc()
+	case result.RequeueAfter > 0:
+		reconcileTotal.WithLabelValues(controller, ResultRequeueAfter).Inc()
+	case result.Requeue:
+		reconcileTotal.WithLabelValues(controller, ResultRequeue).Inc()
+	default:
+		reconcileTotal.WithLabelValues(controller, ResultSuccess).Inc()
+	}
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllermetrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestReconciler_Reconcile(t *testing.T) {
+	tests := []struct {
+		name   string
+		result reconcile.Result
+		err    error
+		expect string
+	}{
+		{name: "success", expect: ResultSuccess},
+		{name: "error", result: reconcile.Result{RequeueAfter: time.Second}, err: errors.New("failed"), expect: ResultError},
+		{name: "requeue after", result: reconcile.Result{RequeueAfter: time.Second}, expect: ResultRequeueAfter},
+		{name: "requeue", result: reconcile.Result{Requeue: true}, expect: ResultRequeue},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			controller := "test-" + strings.ReplaceAll(tt.name, " ", "-")
+			r := Wrap(controller, reconcile.Func(func(context.Context, reconcile.Request) (reconcile.Result, error) {
+				return tt.result, tt.err
+			}))
+			result, err := r.Reconcile(context.Background(), reconcile.Request{})
+			assert.Equal(t, tt.result, result)
+			assert.Equal(t, tt.err, err)
+
+			assert.Equal(t, float64(1), testutil.ToFloat64(reconcileTotal.WithLabelValues(controller, tt.expect)))
+			expectErrors := float64(0)
+			if tt.err != nil {
+				expectErrors = 1
+			}
+			assert.Equal(t, expectErrors, testutil.ToFloat64(reconcileErrors.WithLabelValues(controller)))
+			m := &dto.Metric{}
+			require.NoError(t, reconcileDuration.WithLabelValues(controller).(prometheus.Metric).Write(m))
+			assert.Equal(t, uint64(1), m.GetHistogram().GetSampleCount())
+		})
+	}
+}
+
+func TestReconciler_Exemplar(t *testing.T) {
+	traceID := trace.TraceID{0x01, 0x02}
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     trace.SpanID{0x01},
+		TraceFlags: trace.FlagsSampled,
+	}))
+	r := Wrap("test-exemplar", reconcile.Func(func(context.Context, reconcile.Request) (reconcile.Result, error) {
+		return reconcile.Result{}, nil
+	}))
+	_, err := r.Reconcile(ctx, reconcile.Request{})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, OpenMetricsPath, nil)
+	req.Header.Set("Accept", "application/openmetrics-text; version=1.0.0")
+	rec := httptest.NewRecorder()
+	OpenMetricsHandler().ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `# {trace_id="`+traceID.String()+`"}`)
+}
+
+func TestInstrumentQueue(t *testing.T) {
+	controller := "test-queue"
+	queue := NewQueue(controller, workqueue.DefaultTypedControllerRateLimiter[reconcile.Request]())
+	defer queue.ShutDown()
+
+	first := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "a"}}
+	second := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "b"}}
+	queue.Add(first)
+	queue.Add(first)
+	queue.AddRateLimited(second)
+	queue.AddAfter(second, time.Hour)
+
+	assert.Equal(t, float64(4), testutil.ToFloat64(workqueueAdds.WithLabelValues(controller)))
+	assert.Eventually(t, func() bool {
+		return queue.Len() == 2
+	}, time.Second, 10*time.Millisecond)
+	expected := `
+# HELP agents_controller_workqueue_depth Current depth of the workqueue of the controller
+# TYPE agents_controller_workqueue_depth gauge
+agents_controller_workqueue_depth{controller="test-queue"} 2
+`
+	assert.NoError(t, testutil.CollectAndCompare(depths, strings.NewReader(expected)))
+}
@@ -1009,6 +1009,14 @@ func (m *mockCacheProvider) GetClaimedSandbox(string) (*agentsv1alpha1.Sandbox,
 	return nil, fmt.Errorf("not implemented for PV cache mock")
 }
 
+func (m *mockCacheProvider) GetSandbox(string, string) (*agentsv1alpha1.Sandbox, error) {
+	return nil, fmt.Errorf("not implemented for PV cache mock")
+}
+
+func (m *mockCacheProvider) ResolveSandboxID(string) (*agentsv1alpha1.Sandbox, error) {
+	return nil, fmt.Errorf("not implemented for PV cache mock")
+}
+
 func (m *mockCacheProvider) ListSandboxWithUser(string) ([]*agentsv1alpha1.Sandbox, error) {
 	return nil, fmt.Errorf("not implemented for PV cache mock")
 }
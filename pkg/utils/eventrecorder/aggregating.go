@@ -0,0 +1,119 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package eventrecorder provides event recorders shared by the controllers.
+package eventrecorder
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+)
+
+// DefaultAggregationWindow is the window in which repeated events of an object are dropped
+const DefaultAggregationWindow = 5 * time.Minute
+
+type eventKey struct {
+	uid       types.UID
+	eventType string
+	reason    string
+	message   string
+}
+
+// Aggregating is an EventRecorder dropping the events repeating an event of the same object recorded within the
+// window, so that controllers may report their progress on every reconcile, e.g. while retrying on a shortage,
+// without flooding the events of the object. Events of objects without UID are always recorded.
+type Aggregating struct {
+	record.EventRecorder
+
+	window time.Duration
+	// now is replaced by tests
+	now func() time.Time
+
+	mu        sync.Mutex
+	recorded  map[eventKey]time.Time
+	lastPrune time.Time
+}
+
+var _ record.EventRecorder = &Aggregating{}
+
+// NewAggregating wraps the recorder to drop the events repeated within the window.
+func NewAggregating(recorder record.EventRecorder, window time.Duration) *Aggregating {
+	return &Aggregating{
+		EventRecorder: recorder,
+		window:        window,
+		now:           time.Now,
+		recorded:      map[eventKey]time.Time{},
+	}
+}
+
+// Event records the event unless it was recorded for the object within the window.
+func (a *Aggregating) Event(object runtime.Object, eventType, reason, message string) {
+	if !a.shouldRecord(object, eventType, reason, message) {
+		return
+	}
+	a.EventRecorder.Event(object, eventType, reason, message)
+}
+
+// Eventf is like Event, but formats the message.
+func (a *Aggregating) Eventf(object runtime.Object, eventType, reason, messageFmt string, args ...interface{}) {
+	a.Event(object, eventType, reason, fmt.Sprintf(messageFmt, args...))
+}
+
+// AnnotatedEventf is like Eventf, but also adds the annotations to the event.
+func (a *Aggregating) AnnotatedEventf(object runtime.Object, annotations map[string]string, eventType, reason, messageFmt string, args ...interface{}) {
+	message := fmt.Sprintf(messageFmt, args...)
+	if !a.shouldRecord(object, eventType, reason, message) {
+		return
+	}
+	a.EventRecorder.AnnotatedEventf(object, annotations, eventType, reason, "%s", message)
+}
+
+func (a *Aggregating) shouldRecord(object runtime.Object, eventType, reason, message string) bool {
+	accessor, err := meta.Accessor(object)
+	if err != nil || accessor.GetUID() == "" {
+		return true
+	}
+	key := eventKey{uid: accessor.GetUID(), eventType: eventType, reason: reason, message: message}
+	now := a.now()
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.pruneLocked(now)
+	if last, ok := a.recorded[key]; ok && now.Sub(last) < a.window {
+		return false
+	}
+	a.recorded[key] = now
+	return true
+}
+
+// pruneLocked forgets the events out of the window, at most once per window.
+func (a *Aggregating) pruneLocked(now time.Time) {
+	if now.Sub(a.lastPrune) < a.window {
+		return
+	}
+	for key, last := range a.recorded {
+		if now.Sub(last) >= a.window {
+			delete(a.recorded, key)
+		}
+	}
+	a.lastPrune = now
+}
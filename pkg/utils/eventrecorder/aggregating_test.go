@@ -0,0 +1,112 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package eventrecorder
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestAggregating(t *testing.T) {
+	objA := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "a", UID: types.UID("a")}}
+	objB := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "b", UID: types.UID("b")}}
+	noUID := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "no-uid"}}
+
+	type event struct {
+		object  *corev1.ConfigMap
+		reason  string
+		message string
+		after   time.Duration
+	}
+	tests := []struct {
+		name     string
+		events   []event
+		expected []string
+	}{
+		{
+			name: "repeated event is dropped within the window",
+			events: []event{
+				{object: objA, reason: "Exhausted", message: "7 more needed"},
+				{object: objA, reason: "Exhausted", message: "7 more needed", after: time.Minute},
+			},
+			expected: []string{"Normal Exhausted 7 more needed"},
+		},
+		{
+			name: "repeated event is recorded again after the window",
+			events: []event{
+				{object: objA, reason: "Exhausted", message: "7 more needed"},
+				{object: objA, reason: "Exhausted", message: "7 more needed", after: 2 * time.Minute},
+			},
+			expected: []string{"Normal Exhausted 7 more needed", "Normal Exhausted 7 more needed"},
+		},
+		{
+			name: "events with other messages or objects are recorded",
+			events: []event{
+				{object: objA, reason: "Exhausted", message: "7 more needed"},
+				{object: objA, reason: "Exhausted", message: "6 more needed"},
+				{object: objB, reason: "Exhausted", message: "7 more needed"},
+			},
+			expected: []string{"Normal Exhausted 7 more needed", "Normal Exhausted 6 more needed", "Normal Exhausted 7 more needed"},
+		},
+		{
+			name: "events of objects without uid are always recorded",
+			events: []event{
+				{object: noUID, reason: "Exhausted", message: "7 more needed"},
+				{object: noUID, reason: "Exhausted", message: "7 more needed"},
+			},
+			expected: []string{"Normal Exhausted 7 more needed", "Normal Exhausted 7 more needed"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeRecorder := record.NewFakeRecorder(10)
+			recorder := NewAggregating(fakeRecorder, 2*time.Minute)
+			now := time.Now()
+			recorder.now = func() time.Time { return now }
+			for _, e := range tt.events {
+				now = now.Add(e.after)
+				recorder.Eventf(e.object, corev1.EventTypeNormal, e.reason, "%s", e.message)
+			}
+			close(fakeRecorder.Events)
+			var recorded []string
+			for e := range fakeRecorder.Events {
+				recorded = append(recorded, e)
+			}
+			assert.Equal(t, tt.expected, recorded)
+		})
+	}
+}
+
+func TestAggregatingPrune(t *testing.T) {
+	recorder := NewAggregating(record.NewFakeRecorder(10), time.Minute)
+	now := time.Now()
+	recorder.now = func() time.Time { return now }
+	for _, uid := range []types.UID{"a", "b"} {
+		recorder.Event(&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{UID: uid}}, corev1.EventTypeNormal, "Bound", "bound")
+	}
+	assert.Len(t, recorder.recorded, 2)
+
+	now = now.Add(time.Minute)
+	recorder.Event(&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{UID: "c"}}, corev1.EventTypeNormal, "Bound", "bound")
+	assert.Len(t, recorder.recorded, 1, "events out of the window should be forgotten")
+}
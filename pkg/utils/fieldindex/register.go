@@ -29,6 +29,9 @@ import (
 
 const (
 	IndexNameForOwnerRefUID = "ownerRefUID"
+	IndexNameForUID         = "uid"
+	// IndexNameForSandboxSetName indexes SandboxClaims by the SandboxSet they claim from
+	IndexNameForSandboxSetName = "sandboxSetName"
 )
 
 var (
@@ -43,6 +46,11 @@ var OwnerIndexFunc = func(obj client.Object) []string {
 	return owners
 }
 
+// UIDIndexFunc indexes objects by their own UID, e.g. SandboxClaims by the claim UID labels of their sandboxes
+var UIDIndexFunc = func(obj client.Object) []string {
+	return []string{string(obj.GetUID())}
+}
+
 func RegisterFieldIndexes(c cache.Cache) error {
 	var err error
 	registerOnce.Do(func() {
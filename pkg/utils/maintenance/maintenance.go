@@ -0,0 +1,202 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package maintenance restricts disruptive operations on sandboxes, like scaling down pools, to maintenance windows.
+//
+// The windows are configured in the ConfigMap ConfigMapName in the system namespace. The key ClusterKey holds the
+// windows of all namespaces, and a key named after a namespace overrides them for that namespace. Each value is a
+// YAML list of windows, for example:
+//
+//	_cluster: |
+//	  - days: [Sat, Sun]
+//	    start: "00:00"
+//	    end: "24:00"
+//	  - start: "02:00"
+//	    end: "05:00"
+//	    timeZone: Asia/Shanghai
+//	team-a: "[]"
+//
+// A namespace without any windows, like team-a above or all namespaces if the ConfigMap does not exist, is not
+// restricted.
+package maintenance
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// ConfigMapName is the name of the ConfigMap of maintenance windows in the system namespace
+	ConfigMapName = "sandbox-maintenance-windows"
+	// ClusterKey is the key of the windows of namespaces without their own windows. It is not a valid namespace name.
+	ClusterKey = "_cluster"
+)
+
+// Window is a daily time range in which disruptive operations are allowed.
+type Window struct {
+	// Days are the weekdays the window starts on, like Mon or Sunday. Empty means every day.
+	Days []string `json:"days,omitempty"`
+	// Start is the start of the window in the format 15:04.
+	Start string `json:"start"`
+	// End is the end of the window in the format 15:04, up to 24:00. An end not after the start means the window
+	// ends on the next day.
+	End string `json:"end"`
+	// TimeZone is the IANA time zone of the window, UTC by default.
+	TimeZone string `json:"timeZone,omitempty"`
+}
+
+// Windows are the maintenance windows of a namespace. No windows means disruptive operations are always allowed.
+type Windows []Window
+
+// Allowed returns whether disruptive operations are allowed at now, and if not, how long to wait for the next window.
+func (ws Windows) Allowed(now time.Time) (bool, time.Duration, error) {
+	if len(ws) == 0 {
+		return true, 0, nil
+	}
+	var wait time.Duration
+	for i := range ws {
+		open, next, err := ws[i].allowed(now)
+		if err != nil {
+			return false, 0, err
+		}
+		if open {
+			return true, 0, nil
+		}
+		if wait == 0 || next < wait {
+			wait = next
+		}
+	}
+	return false, wait, nil
+}
+
+// allowed returns whether now is in the window, or how long to wait for the window to open
+func (w *Window) allowed(now time.Time) (bool, time.Duration, error) {
+	location := time.UTC
+	if w.TimeZone != "" {
+		var err error
+		if location, err = time.LoadLocation(w.TimeZone); err != nil {
+			return false, 0, fmt.Errorf("invalid time zone %q: %w", w.TimeZone, err)
+		}
+	}
+	start, err := parseClock(w.Start)
+	if err != nil {
+		return false, 0, fmt.Errorf("invalid start: %w", err)
+	}
+	end, err := parseClock(w.End)
+	if err != nil {
+		return false, 0, fmt.Errorf("invalid end: %w", err)
+	}
+	if end <= start {
+		end += 24 * time.Hour
+	}
+	days := map[time.Weekday]bool{}
+	for _, day := range w.Days {
+		weekday, err := parseWeekday(day)
+		if err != nil {
+			return false, 0, err
+		}
+		days[weekday] = true
+	}
+
+	local := now.In(location)
+	today := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, location)
+	// the window started yesterday may be still open, and the next window opens within a week
+	for offset := -1; offset <= 7; offset++ {
+		day := today.AddDate(0, 0, offset)
+		if len(days) > 0 && !days[day.Weekday()] {
+			continue
+		}
+		opensAt := day.Add(start)
+		if now.Before(opensAt) {
+			return false, opensAt.Sub(now), nil
+		}
+		if now.Before(day.Add(end)) {
+			return true, 0, nil
+		}
+	}
+	return false, 0, fmt.Errorf("window never opens")
+}
+
+func parseClock(value string) (time.Duration, error) {
+	if value == "24:00" {
+		return 24 * time.Hour, nil
+	}
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+func parseWeekday(value string) (time.Weekday, error) {
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		name := day.String()
+		if strings.EqualFold(value, name) || strings.EqualFold(value, name[:3]) {
+			return day, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid day %q", value)
+}
+
+// ParseWindows parses the windows of a namespace from the ConfigMap
+func ParseWindows(cm *corev1.ConfigMap, namespace string) (Windows, error) {
+	value, ok := cm.Data[namespace]
+	key := namespace
+	if !ok {
+		value, key = cm.Data[ClusterKey], ClusterKey
+	}
+	var windows Windows
+	if err := yaml.UnmarshalStrict([]byte(value), &windows); err != nil {
+		return nil, fmt.Errorf("failed to parse maintenance windows %s: %w", key, err)
+	}
+	return windows, nil
+}
+
+// Checker checks the maintenance windows of namespaces.
+type Checker struct {
+	// Reader reads the ConfigMap from the API server, so that ConfigMaps are not cached cluster-wide.
+	Reader client.Reader
+	// Namespace is the system namespace of the ConfigMap
+	Namespace string
+}
+
+// Allowed returns whether disruptive operations in the namespace are allowed at now, and if not, how long to wait for
+// the next window. A nil Checker allows everything.
+func (c *Checker) Allowed(ctx context.Context, namespace string, now time.Time) (bool, time.Duration, error) {
+	if c == nil {
+		return true, 0, nil
+	}
+	cm := &corev1.ConfigMap{}
+	if err := c.Reader.Get(ctx, types.NamespacedName{Namespace: c.Namespace, Name: ConfigMapName}, cm); err != nil {
+		if errors.IsNotFound(err) {
+			return true, 0, nil
+		}
+		return false, 0, fmt.Errorf("failed to get maintenance windows: %w", err)
+	}
+	windows, err := ParseWindows(cm, namespace)
+	if err != nil {
+		return false, 0, err
+	}
+	return windows.Allowed(now)
+}
@@ -0,0 +1,213 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package maintenance
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestWindows_Allowed(t *testing.T) {
+	// 2025-01-01 is a Wednesday
+	wednesday := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name        string
+		windows     Windows
+		now         time.Time
+		expectAllow bool
+		expectWait  time.Duration
+		expectError string
+	}{
+		{
+			name:        "no windows",
+			now:         wednesday,
+			expectAllow: true,
+		},
+		{
+			name:        "in daily window",
+			windows:     Windows{{Start: "09:00", End: "11:00"}},
+			now:         wednesday,
+			expectAllow: true,
+		},
+		{
+			name:       "before daily window",
+			windows:    Windows{{Start: "12:00", End: "13:00"}},
+			now:        wednesday,
+			expectWait: 2 * time.Hour,
+		},
+		{
+			name:       "after daily window",
+			windows:    Windows{{Start: "08:00", End: "09:00"}},
+			now:        wednesday,
+			expectWait: 22 * time.Hour,
+		},
+		{
+			name:       "end is exclusive",
+			windows:    Windows{{Start: "08:00", End: "10:00"}},
+			now:        wednesday,
+			expectWait: 22 * time.Hour,
+		},
+		{
+			name:        "whole day",
+			windows:     Windows{{Days: []string{"wed"}, Start: "00:00", End: "24:00"}},
+			now:         wednesday,
+			expectAllow: true,
+		},
+		{
+			name:       "weekend",
+			windows:    Windows{{Days: []string{"Sat", "Sunday"}, Start: "00:00", End: "24:00"}},
+			now:        wednesday,
+			expectWait: 62 * time.Hour,
+		},
+		{
+			name:        "cross midnight window started yesterday",
+			windows:     Windows{{Days: []string{"Tue"}, Start: "22:00", End: "02:00"}},
+			now:         time.Date(2025, 1, 1, 1, 0, 0, 0, time.UTC),
+			expectAllow: true,
+		},
+		{
+			name:       "cross midnight window of next week",
+			windows:    Windows{{Days: []string{"Tue"}, Start: "22:00", End: "02:00"}},
+			now:        time.Date(2025, 1, 1, 3, 0, 0, 0, time.UTC),
+			expectWait: 6*24*time.Hour + 19*time.Hour,
+		},
+		{
+			name:        "time zone",
+			windows:     Windows{{Start: "17:00", End: "19:00", TimeZone: "Asia/Shanghai"}},
+			now:         wednesday,
+			expectAllow: true,
+		},
+		{
+			name:       "nearest window",
+			windows:    Windows{{Start: "20:00", End: "21:00"}, {Start: "11:30", End: "12:00"}},
+			now:        wednesday,
+			expectWait: 90 * time.Minute,
+		},
+		{
+			name:        "invalid start",
+			windows:     Windows{{Start: "25:00", End: "26:00"}},
+			now:         wednesday,
+			expectError: "invalid start",
+		},
+		{
+			name:        "invalid day",
+			windows:     Windows{{Days: []string{"Someday"}, Start: "00:00", End: "01:00"}},
+			now:         wednesday,
+			expectError: "invalid day",
+		},
+		{
+			name:        "invalid time zone",
+			windows:     Windows{{Start: "00:00", End: "01:00", TimeZone: "Mars/Olympus"}},
+			now:         wednesday,
+			expectError: "invalid time zone",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			allowed, wait, err := tt.windows.Allowed(tt.now)
+			if tt.expectError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectAllow, allowed)
+			assert.Equal(t, tt.expectWait, wait)
+		})
+	}
+}
+
+func TestChecker_Allowed(t *testing.T) {
+	now := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	newConfigMap := func(data map[string]string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: ConfigMapName, Namespace: "sandbox-system"},
+			Data:       data,
+		}
+	}
+	tests := []struct {
+		name        string
+		configMap   *corev1.ConfigMap
+		namespace   string
+		expectAllow bool
+		expectError bool
+	}{
+		{
+			name:        "no config map",
+			namespace:   "default",
+			expectAllow: true,
+		},
+		{
+			name:      "cluster windows",
+			configMap: newConfigMap(map[string]string{ClusterKey: `[{"start": "20:00", "end": "22:00"}]`}),
+			namespace: "default",
+		},
+		{
+			name: "namespace windows override cluster windows",
+			configMap: newConfigMap(map[string]string{
+				ClusterKey: `[{"start": "20:00", "end": "22:00"}]`,
+				"team-a":   "[]",
+			}),
+			namespace:   "team-a",
+			expectAllow: true,
+		},
+		{
+			name: "yaml windows",
+			configMap: newConfigMap(map[string]string{ClusterKey: `
+- days: [Wed]
+  start: "09:00"
+  end: "11:00"`}),
+			namespace:   "default",
+			expectAllow: true,
+		},
+		{
+			name:        "unknown field",
+			configMap:   newConfigMap(map[string]string{ClusterKey: `[{"begin": "20:00", "end": "22:00"}]`}),
+			namespace:   "default",
+			expectError: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			builder := fake.NewClientBuilder().WithScheme(scheme.Scheme)
+			if tt.configMap != nil {
+				builder = builder.WithObjects(tt.configMap)
+			}
+			checker := &Checker{Reader: builder.Build(), Namespace: "sandbox-system"}
+			allowed, _, err := checker.Allowed(context.Background(), tt.namespace, now)
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectAllow, allowed)
+		})
+	}
+
+	var checker *Checker
+	allowed, _, err := checker.Allowed(context.Background(), "default", now)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+}
@@ -0,0 +1,161 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package objectsize caps the unbounded fields of SandboxClaims and Sandboxes, so that a pathological object cannot
+// grow past the request size limit of the apiserver and balloon etcd.
+package objectsize
+
+import (
+	"fmt"
+	"slices"
+	"unicode/utf8"
+
+	"github.com/spf13/pflag"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+)
+
+// truncatedSuffix marks a truncated message
+const truncatedSuffix = "...(truncated)"
+
+// Options are the caps of the unbounded fields. Zero disables a cap.
+type Options struct {
+	// MaxMessageBytes truncates the messages of the status, its conditions and the history of claims.
+	MaxMessageBytes int
+	// MaxConditions drops the conditions of the status with the oldest transitions beyond it.
+	MaxConditions int
+	// MaxClaimHistory drops the oldest entries of the history of claims beyond it.
+	MaxClaimHistory int
+	// MaxClaimObjectBytes rejects SandboxClaims whose serialized object is larger than it.
+	MaxClaimObjectBytes int
+	// MaxClaimMetadataBytes rejects SandboxClaims whose labels, annotations and envVars to set on the claimed
+	// sandboxes are larger than it in total.
+	MaxClaimMetadataBytes int
+}
+
+// DefaultOptions is set by the command line flags and used by the controllers and webhooks of the process.
+var DefaultOptions = Options{
+	MaxMessageBytes:       1024,
+	MaxConditions:         32,
+	MaxClaimHistory:       agentsv1alpha1.SandboxClaimHistoryLimit,
+	MaxClaimObjectBytes:   512 * 1024,
+	MaxClaimMetadataBytes: 128 * 1024,
+}
+
+// AddFlags registers the flags of the options.
+func (o *Options) AddFlags(fs *pflag.FlagSet) {
+	fs.IntVar(&o.MaxMessageBytes, "max-status-message-bytes", o.MaxMessageBytes,
+		"Truncate the status messages of SandboxClaims and Sandboxes, including their conditions, longer than this. 0 disables it.")
+	fs.IntVar(&o.MaxConditions, "max-status-conditions", o.MaxConditions,
+		"Drop the conditions of SandboxClaims and Sandboxes with the oldest transitions beyond this. 0 disables it.")
+	fs.IntVar(&o.MaxClaimHistory, "max-sandboxclaim-history", o.MaxClaimHistory,
+		"Drop the oldest entries of the history of SandboxClaims beyond this. 0 disables it.")
+	fs.IntVar(&o.MaxClaimObjectBytes, "max-sandboxclaim-object-bytes", o.MaxClaimObjectBytes,
+		"Reject SandboxClaims larger than this when serialized. 0 disables it.")
+	fs.IntVar(&o.MaxClaimMetadataBytes, "max-sandboxclaim-metadata-bytes", o.MaxClaimMetadataBytes,
+		"Reject SandboxClaims whose spec.labels, spec.annotations and spec.envVars are larger than this in total. 0 disables it.")
+}
+
+// TruncateMessage truncates the message to at most maxBytes bytes without splitting a character, marking it as
+// truncated. A non-positive maxBytes keeps the message.
+func TruncateMessage(message string, maxBytes int) string {
+	if maxBytes <= 0 || len(message) <= maxBytes {
+		return message
+	}
+	suffix := truncatedSuffix
+	if maxBytes <= len(suffix) {
+		suffix = ""
+	}
+	keep := maxBytes - len(suffix)
+	for keep > 0 && !utf8.RuneStart(message[keep]) {
+		keep--
+	}
+	return message[:keep] + suffix
+}
+
+// TruncateClaimStatus caps the messages, conditions and history of the status of a claim.
+func (o Options) TruncateClaimStatus(status *agentsv1alpha1.SandboxClaimStatus) {
+	status.Message = TruncateMessage(status.Message, o.MaxMessageBytes)
+	status.Conditions = o.truncateConditions(status.Conditions)
+	if o.MaxClaimHistory > 0 && len(status.History) > o.MaxClaimHistory {
+		status.History = slices.Clone(status.History[len(status.History)-o.MaxClaimHistory:])
+	}
+	for i := range status.History {
+		status.History[i].Message = TruncateMessage(status.History[i].Message, o.MaxMessageBytes)
+	}
+}
+
+// TruncateSandboxStatus caps the messages and conditions of the status of a sandbox.
+func (o Options) TruncateSandboxStatus(status *agentsv1alpha1.SandboxStatus) {
+	status.Message = TruncateMessage(status.Message, o.MaxMessageBytes)
+	status.Conditions = o.truncateConditions(status.Conditions)
+}
+
+// truncateConditions truncates the messages of the conditions, and drops the ones with the oldest transitions beyond
+// the cap. The conditions are copied when changed, as they may be shared with the informer cache.
+func (o Options) truncateConditions(conditions []metav1.Condition) []metav1.Condition {
+	changed := slices.ContainsFunc(conditions, func(cond metav1.Condition) bool {
+		return o.MaxMessageBytes > 0 && len(cond.Message) > o.MaxMessageBytes
+	})
+	overflow := 0
+	if o.MaxConditions > 0 {
+		overflow = len(conditions) - o.MaxConditions
+	}
+	if !changed && overflow <= 0 {
+		return conditions
+	}
+	conditions = slices.Clone(conditions)
+	for i := range conditions {
+		conditions[i].Message = TruncateMessage(conditions[i].Message, o.MaxMessageBytes)
+	}
+	if overflow > 0 {
+		oldest := slices.Clone(conditions)
+		slices.SortStableFunc(oldest, func(a, b metav1.Condition) int {
+			return a.LastTransitionTime.Compare(b.LastTransitionTime.Time)
+		})
+		dropped := map[string]bool{}
+		for _, cond := range oldest[:overflow] {
+			dropped[cond.Type] = true
+		}
+		conditions = slices.DeleteFunc(conditions, func(cond metav1.Condition) bool {
+			return dropped[cond.Type]
+		})
+	}
+	return conditions
+}
+
+// ValidateClaim checks the size of the claim, and of the metadata it sets on its sandboxes. The serialized size of
+// the object is given by the caller, e.g. the raw object of an admission request.
+func (o Options) ValidateClaim(claim *agentsv1alpha1.SandboxClaim, objectBytes int) error {
+	if o.MaxClaimObjectBytes > 0 && objectBytes > o.MaxClaimObjectBytes {
+		return fmt.Errorf("SandboxClaim is %d bytes, exceeding the limit of %d bytes", objectBytes, o.MaxClaimObjectBytes)
+	}
+	if o.MaxClaimMetadataBytes <= 0 {
+		return nil
+	}
+	size := 0
+	for _, m := range []map[string]string{claim.Spec.Labels, claim.Spec.Annotations, claim.Spec.EnvVars} {
+		for key, value := range m {
+			size += len(key) + len(value)
+		}
+	}
+	if size > o.MaxClaimMetadataBytes {
+		return fmt.Errorf("spec.labels, spec.annotations and spec.envVars are %d bytes in total, exceeding the limit of %d bytes",
+			size, o.MaxClaimMetadataBytes)
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package objectsize
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+)
+
+func TestTruncateMessage(t *testing.T) {
+	tests := []struct {
+		name     string
+		message  string
+		maxBytes int
+		expect   string
+	}{
+		{name: "disabled", message: strings.Repeat("x", 100), maxBytes: 0, expect: strings.Repeat("x", 100)},
+		{name: "within limit", message: "short", maxBytes: 5, expect: "short"},
+		{name: "truncated", message: strings.Repeat("x", 100), maxBytes: 20, expect: "xxxxxx" + truncatedSuffix},
+		{name: "limit below suffix", message: strings.Repeat("x", 100), maxBytes: 4, expect: "xxxx"},
+		// "沙" is 3 bytes, the 2nd one would be split at 7 bytes
+		{name: "multi-byte characters kept whole", message: strings.Repeat("沙", 10), maxBytes: 7 + len(truncatedSuffix), expect: "沙沙" + truncatedSuffix},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := TruncateMessage(tt.message, tt.maxBytes)
+			assert.Equal(t, tt.expect, got)
+			if tt.maxBytes > 0 {
+				assert.LessOrEqual(t, len(got), tt.maxBytes)
+			}
+		})
+	}
+}
+
+func TestOptions_TruncateClaimStatus(t *testing.T) {
+	opts := Options{MaxMessageBytes: 32, MaxConditions: 2, MaxClaimHistory: 3}
+	long := strings.Repeat("m", 100)
+	now := time.Now()
+	conditions := []metav1.Condition{
+		{Type: "Newest", LastTransitionTime: metav1.NewTime(now), Message: long},
+		{Type: "Oldest", LastTransitionTime: metav1.NewTime(now.Add(-2 * time.Minute))},
+		{Type: "Middle", LastTransitionTime: metav1.NewTime(now.Add(-time.Minute))},
+	}
+	status := &agentsv1alpha1.SandboxClaimStatus{Message: long, Conditions: conditions}
+	for i := range 5 {
+		status.History = append(status.History, agentsv1alpha1.SandboxClaimHistoryEntry{Reason: fmt.Sprintf("R%d", i), Message: long})
+	}
+
+	opts.TruncateClaimStatus(status)
+	assert.Len(t, status.Message, 32)
+	require.Len(t, status.Conditions, 2)
+	assert.Equal(t, "Newest", status.Conditions[0].Type)
+	assert.Equal(t, "Middle", status.Conditions[1].Type)
+	assert.Len(t, status.Conditions[0].Message, 32)
+	// the conditions passed in, e.g. of the informer cache, are kept
+	assert.Equal(t, long, conditions[0].Message)
+	require.Len(t, status.History, 3)
+	assert.Equal(t, "R2", status.History[0].Reason)
+	for _, entry := range status.History {
+		assert.Len(t, entry.Message, 32)
+	}
+
+	// truncating again changes nothing, so that the status is not patched on every reconcile
+	truncated := status.DeepCopy()
+	opts.TruncateClaimStatus(status)
+	assert.Equal(t, truncated, status)
+
+	// disabled
+	status = &agentsv1alpha1.SandboxClaimStatus{Message: long, Conditions: conditions}
+	Options{}.TruncateClaimStatus(status)
+	assert.Equal(t, long, status.Message)
+	assert.Len(t, status.Conditions, 3)
+}
+
+func TestOptions_TruncateSandboxStatus(t *testing.T) {
+	status := &agentsv1alpha1.SandboxStatus{
+		Message:    strings.Repeat("m", 100),
+		Conditions: []metav1.Condition{{Type: string(agentsv1alpha1.SandboxConditionReady), Message: strings.Repeat("m", 100)}},
+	}
+	Options{MaxMessageBytes: 50}.TruncateSandboxStatus(status)
+	assert.Len(t, status.Message, 50)
+	assert.Len(t, status.Conditions[0].Message, 50)
+}
+
+func TestOptions_ValidateClaim(t *testing.T) {
+	opts := Options{MaxClaimObjectBytes: 1000, MaxClaimMetadataBytes: 100}
+	tests := []struct {
+		name        string
+		spec        agentsv1alpha1.SandboxClaimSpec
+		objectBytes int
+		expectErr   string
+	}{
+		{
+			name:        "within limits",
+			spec:        agentsv1alpha1.SandboxClaimSpec{Labels: map[string]string{"k": "v"}},
+			objectBytes: 1000,
+		},
+		{
+			name:        "object too large",
+			objectBytes: 1001,
+			expectErr:   "SandboxClaim is 1001 bytes, exceeding the limit of 1000 bytes",
+		},
+		{
+			name: "metadata too large in total",
+			spec: agentsv1alpha1.SandboxClaimSpec{
+				Labels:      map[string]string{"label": strings.Repeat("v", 30)},
+				Annotations: map[string]string{"annotation": strings.Repeat("v", 30)},
+				EnvVars:     map[string]string{"ENV": strings.Repeat("v", 30)},
+			},
+			expectErr: "spec.labels, spec.annotations and spec.envVars are 108 bytes in total, exceeding the limit of 100 bytes",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := opts.ValidateClaim(&agentsv1alpha1.SandboxClaim{Spec: tt.spec}, tt.objectBytes)
+			if tt.expectErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.expectErr)
+			}
+		})
+	}
+	assert.NoError(t, Options{}.ValidateClaim(&agentsv1alpha1.SandboxClaim{Spec: tests[2].spec}, 1<<30))
+}
+
+func TestOptions_AddFlags(t *testing.T) {
+	opts := DefaultOptions
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	opts.AddFlags(fs)
+	require.NoError(t, fs.Parse([]string{"--max-status-message-bytes=10", "--max-sandboxclaim-history=0"}))
+	assert.Equal(t, 10, opts.MaxMessageBytes)
+	assert.Equal(t, 0, opts.MaxClaimHistory)
+	assert.Equal(t, DefaultOptions.MaxConditions, opts.MaxConditions)
+}
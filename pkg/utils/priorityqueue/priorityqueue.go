@@ -0,0 +1,160 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package priorityqueue provides a workqueue for reconcilers which hands out the ready items of higher priorities
+// first. Unlike the priority queue of controller-runtime, whose priorities are set by the event handlers, the
+// priority of an item is decided by the queue itself whenever the item is added, so that any source of events,
+// including requeues of the reconciler, gets the right priority.
+package priorityqueue
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/client-go/util/workqueue"
+	crpriorityqueue "sigs.k8s.io/controller-runtime/pkg/controller/priorityqueue"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// Priorities of the bands, items of the same priority are handed out in the order they became ready.
+const (
+	PriorityLow    = -100
+	PriorityNormal = 0
+	PriorityHigh   = 100
+	PriorityUrgent = 200
+)
+
+var waitDuration = prometheus.NewHistogramVec(
+	prometheus.HistogramOpts{
+		Namespace: "agents",
+		Subsystem: "controller",
+		Name:      "workqueue_wait_seconds",
+		Help:      "Duration items of the priority workqueue of the controller wait between being ready and handed out, by priority band",
+		Buckets:   []float64{0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 2.5, 5, 10, 30, 60},
+	},
+	[]string{"controller", "priority"},
+)
+
+func init() {
+	metrics.Registry.MustRegister(waitDuration)
+}
+
+// Band returns the name of the band of a priority, which is the label of the wait metrics.
+func Band(priority int) string {
+	switch {
+	case priority >= PriorityUrgent:
+		return "urgent"
+	case priority >= PriorityHigh:
+		return "high"
+	case priority > PriorityLow:
+		return "normal"
+	default:
+		return "low"
+	}
+}
+
+// PriorityFunc returns the priority of an item when it is added to the queue.
+type PriorityFunc[T comparable] func(item T) int
+
+// Queue is a rate limiting workqueue prioritizing items with a PriorityFunc.
+type Queue[T comparable] struct {
+	crpriorityqueue.PriorityQueue[T]
+	controller   string
+	rateLimiter  workqueue.TypedRateLimiter[T]
+	priorityFunc PriorityFunc[T]
+	now          func() time.Time
+
+	mu sync.Mutex
+	// readyAt is the earliest time each queued item became or becomes ready
+	readyAt map[T]time.Time
+}
+
+// New creates a priority queue of the controller. It matches the NewQueue option of controllers when the
+// PriorityFunc is bound.
+func New[T comparable](controllerName string, rateLimiter workqueue.TypedRateLimiter[T], priorityFunc PriorityFunc[T]) *Queue[T] {
+	return &Queue[T]{
+		PriorityQueue: crpriorityqueue.New(controllerName, func(o *crpriorityqueue.Opts[T]) {
+			o.RateLimiter = rateLimiter
+		}),
+		controller:   controllerName,
+		rateLimiter:  rateLimiter,
+		priorityFunc: priorityFunc,
+		now:          time.Now,
+		readyAt:      map[T]time.Time{},
+	}
+}
+
+// AddWithOpts adds the items with the priority of the PriorityFunc, unless a higher priority is set in the options.
+func (q *Queue[T]) AddWithOpts(o crpriorityqueue.AddOpts, items ...T) {
+	for _, item := range items {
+		after := o.After
+		if o.RateLimited {
+			// the delay is decided here instead of by the underlying queue, so that the ready time is known
+			if limited := q.rateLimiter.When(item); after == 0 || limited < after {
+				after = limited
+			}
+		}
+		priority := q.priorityFunc(item)
+		if o.Priority != 0 && o.Priority > priority {
+			priority = o.Priority
+		}
+		readyAt := q.now().Add(after)
+		q.mu.Lock()
+		if current, ok := q.readyAt[item]; !ok || readyAt.Before(current) {
+			q.readyAt[item] = readyAt
+		}
+		q.mu.Unlock()
+		q.PriorityQueue.AddWithOpts(crpriorityqueue.AddOpts{After: after, Priority: priority}, item)
+	}
+}
+
+func (q *Queue[T]) Add(item T) {
+	q.AddWithOpts(crpriorityqueue.AddOpts{}, item)
+}
+
+func (q *Queue[T]) AddAfter(item T, duration time.Duration) {
+	q.AddWithOpts(crpriorityqueue.AddOpts{After: duration}, item)
+}
+
+func (q *Queue[T]) AddRateLimited(item T) {
+	q.AddWithOpts(crpriorityqueue.AddOpts{RateLimited: true}, item)
+}
+
+func (q *Queue[T]) Get() (T, bool) {
+	item, _, shutdown := q.GetWithPriority()
+	return item, shutdown
+}
+
+// GetWithPriority hands out the ready item of the highest priority and observes how long it waited.
+func (q *Queue[T]) GetWithPriority() (T, int, bool) {
+	item, priority, shutdown := q.PriorityQueue.GetWithPriority()
+	if shutdown {
+		return item, priority, shutdown
+	}
+	q.mu.Lock()
+	readyAt, ok := q.readyAt[item]
+	delete(q.readyAt, item)
+	q.mu.Unlock()
+	if ok {
+		wait := q.now().Sub(readyAt)
+		if wait < 0 {
+			wait = 0
+		}
+		waitDuration.WithLabelValues(q.controller, Band(priority)).Observe(wait.Seconds())
+	}
+	return item, priority, shutdown
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package priorityqueue
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/util/workqueue"
+	crpriorityqueue "sigs.k8s.io/controller-runtime/pkg/controller/priorityqueue"
+)
+
+func TestBand(t *testing.T) {
+	tests := []struct {
+		priority int
+		expected string
+	}{
+		{priority: PriorityUrgent, expected: "urgent"},
+		{priority: PriorityHigh, expected: "high"},
+		{priority: PriorityNormal, expected: "normal"},
+		{priority: PriorityLow, expected: "low"},
+		{priority: PriorityLow - 1, expected: "low"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Band(tt.priority))
+		})
+	}
+}
+
+func TestQueue(t *testing.T) {
+	priorities := map[string]int{
+		"batch":       PriorityLow,
+		"normal":      PriorityNormal,
+		"interactive": PriorityHigh,
+		"urgent":      PriorityUrgent,
+	}
+	controller := "test-priority-queue"
+	rateLimiter := workqueue.NewTypedItemExponentialFailureRateLimiter[string](time.Millisecond, time.Second)
+	q := New(controller, rateLimiter, func(item string) int {
+		return priorities[item]
+	})
+	defer q.ShutDown()
+
+	// items of the same readiness are handed out by priority instead of the order they are added
+	for _, item := range []string{"batch", "normal", "urgent", "interactive"} {
+		q.Add(item)
+	}
+	// an explicit priority higher than the one of the PriorityFunc wins
+	q.AddWithOpts(crpriorityqueue.AddOpts{Priority: PriorityUrgent + 1}, "escalated")
+	require.Eventually(t, func() bool { return q.Len() == 5 }, time.Second, time.Millisecond)
+
+	var got []string
+	for range 5 {
+		item, priority, shutdown := q.GetWithPriority()
+		require.False(t, shutdown)
+		if item != "escalated" {
+			assert.Equal(t, priorities[item], priority)
+		}
+		got = append(got, item)
+		q.Done(item)
+	}
+	assert.Equal(t, []string{"escalated", "urgent", "interactive", "normal", "batch"}, got)
+	assert.Equal(t, uint64(1), waitCount(t, controller, "low"))
+	assert.Equal(t, uint64(2), waitCount(t, controller, "urgent"))
+
+	// rate limited items are delayed by the shared rate limiter
+	q.AddRateLimited("normal")
+	assert.Equal(t, 1, q.NumRequeues("normal"))
+	item, shutdown := q.Get()
+	require.False(t, shutdown)
+	assert.Equal(t, "normal", item)
+	q.Forget(item)
+	q.Done(item)
+	assert.Equal(t, 0, q.NumRequeues("normal"))
+	assert.Equal(t, uint64(2), waitCount(t, controller, "normal"))
+	assert.Empty(t, q.readyAt)
+}
+
+func waitCount(t *testing.T, controller, band string) uint64 {
+	m := &dto.Metric{}
+	require.NoError(t, waitDuration.WithLabelValues(controller, band).(prometheus.Metric).Write(m))
+	return m.GetHistogram().GetSampleCount()
+}
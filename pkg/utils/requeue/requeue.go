@@ -0,0 +1,128 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package requeue describes how a reconciler wants to be requeued after a successful pass.
+//
+// Business logic (the Ensure* methods of the controllers) returns a Strategy rather than a
+// ctrl.Result, so that the Reconciler can convert it in a single place and log / record
+// the chosen strategy together with a short machine-readable reason.
+package requeue
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// Kind classifies a Strategy for logging and metrics.
+type Kind string
+
+const (
+	// KindNone means no requeue; the reconciler waits for Watch events.
+	KindNone Kind = "None"
+	// KindImmediate means requeue immediately (ctrl.Result{Requeue: true}).
+	KindImmediate Kind = "Immediate"
+	// KindAfter means requeue after a delay (ctrl.Result{RequeueAfter: d}).
+	KindAfter Kind = "After"
+)
+
+// Strategy defines the requeue behavior for controller reconciliation.
+type Strategy struct {
+	// Immediate indicates whether to requeue immediately (ctrl.Result{Requeue: true})
+	// If false, uses After duration
+	Immediate bool
+
+	// After specifies the duration to wait before requeue
+	// Only used when Immediate is false
+	After time.Duration
+
+	// Reason is a short CamelCase explanation of why this strategy was chosen, e.g. "NoAvailableSandboxes".
+	// It is only used for observability and never changes the requeue behavior.
+	Reason string
+}
+
+// NoRequeue returns a strategy that waits for Watch events.
+func NoRequeue() Strategy {
+	return Strategy{}
+}
+
+// Immediately returns a strategy for immediate requeue.
+func Immediately() Strategy {
+	return Strategy{Immediate: true}
+}
+
+// After returns a strategy for delayed requeue. A non-positive duration means no requeue.
+func After(d time.Duration) Strategy {
+	return Strategy{After: d}
+}
+
+// WithReason returns a copy of the strategy with the given reason attached.
+func (s Strategy) WithReason(reason string) Strategy {
+	s.Reason = reason
+	return s
+}
+
+// Sooner returns the strategy requeuing sooner, an immediate requeue is the soonest and no requeue the latest. The
+// reason of the chosen strategy is kept, and s wins a tie unless it has no reason.
+func (s Strategy) Sooner(other Strategy) Strategy {
+	sKind, otherKind := s.Kind(), other.Kind()
+	switch {
+	case sKind == otherKind && (sKind != KindAfter || s.After == other.After):
+		if s.Reason == "" {
+			return other
+		}
+		return s
+	case sKind == KindImmediate, otherKind == KindNone:
+		return s
+	case otherKind == KindImmediate, sKind == KindNone:
+		return other
+	case other.After < s.After:
+		return other
+	default:
+		return s
+	}
+}
+
+// Kind returns the classification of the strategy.
+func (s Strategy) Kind() Kind {
+	switch {
+	case s.Immediate:
+		return KindImmediate
+	case s.After > 0:
+		return KindAfter
+	default:
+		return KindNone
+	}
+}
+
+// Result converts the strategy into a ctrl.Result.
+func (s Strategy) Result() ctrl.Result {
+	switch s.Kind() {
+	case KindImmediate:
+		return ctrl.Result{Requeue: true}
+	case KindAfter:
+		return ctrl.Result{RequeueAfter: s.After}
+	default:
+		return ctrl.Result{}
+	}
+}
+
+// Record increments the counter of the reconciler by the kind and reason of the strategy. The counter must be
+// labeled by "strategy" and "reason" in that order.
+func (s Strategy) Record(counter *prometheus.CounterVec) {
+	counter.WithLabelValues(string(s.Kind()), s.Reason).Inc()
+}
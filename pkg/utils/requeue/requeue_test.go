@@ -0,0 +1,128 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package requeue
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+func TestStrategy(t *testing.T) {
+	tests := []struct {
+		name         string
+		strategy     Strategy
+		expectKind   Kind
+		expectResult ctrl.Result
+		expectReason string
+	}{
+		{
+			name:         "no requeue",
+			strategy:     NoRequeue(),
+			expectKind:   KindNone,
+			expectResult: ctrl.Result{},
+		},
+		{
+			name:         "immediately with reason",
+			strategy:     Immediately().WithReason("Progress"),
+			expectKind:   KindImmediate,
+			expectResult: ctrl.Result{Requeue: true},
+			expectReason: "Progress",
+		},
+		{
+			name:         "after duration",
+			strategy:     After(2 * time.Second).WithReason("Retry"),
+			expectKind:   KindAfter,
+			expectResult: ctrl.Result{RequeueAfter: 2 * time.Second},
+			expectReason: "Retry",
+		},
+		{
+			name:         "non-positive after means no requeue",
+			strategy:     After(-time.Second),
+			expectKind:   KindNone,
+			expectResult: ctrl.Result{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expectKind, tt.strategy.Kind())
+			assert.Equal(t, tt.expectResult, tt.strategy.Result())
+			assert.Equal(t, tt.expectReason, tt.strategy.Reason)
+		})
+	}
+}
+
+func TestStrategy_Sooner(t *testing.T) {
+	tests := []struct {
+		name   string
+		s      Strategy
+		other  Strategy
+		expect Strategy
+	}{
+		{
+			name:   "immediate is the soonest",
+			s:      After(time.Second).WithReason("Retry"),
+			other:  Immediately().WithReason("Progress"),
+			expect: Immediately().WithReason("Progress"),
+		},
+		{
+			name:   "shorter delay wins",
+			s:      After(2 * time.Second).WithReason("ShutdownTime"),
+			other:  After(time.Second).WithReason("ReadinessProbe"),
+			expect: After(time.Second).WithReason("ReadinessProbe"),
+		},
+		{
+			name:   "any delay is sooner than no requeue",
+			s:      NoRequeue().WithReason("PodCreated"),
+			other:  After(time.Minute).WithReason("PauseTime"),
+			expect: After(time.Minute).WithReason("PauseTime"),
+		},
+		{
+			name:   "tie keeps the first reason",
+			s:      NoRequeue().WithReason("PodCreated"),
+			other:  NoRequeue().WithReason("Other"),
+			expect: NoRequeue().WithReason("PodCreated"),
+		},
+		{
+			name:   "tie without reason takes the other one",
+			s:      After(time.Second),
+			other:  After(time.Second).WithReason("Retry"),
+			expect: After(time.Second).WithReason("Retry"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expect, tt.s.Sooner(tt.other))
+		})
+	}
+}
+
+func TestStrategyRecord(t *testing.T) {
+	counter := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "test_requeue_total"}, []string{"strategy", "reason"})
+	NoRequeue().WithReason("PodCreated").Record(counter)
+	After(time.Minute).WithReason("ShutdownTime").Record(counter)
+	After(time.Second).WithReason("ShutdownTime").Record(counter)
+
+	assert.Equal(t, float64(1), testutil.ToFloat64(counter.WithLabelValues(string(KindNone), "PodCreated")))
+	assert.Equal(t, float64(2), testutil.ToFloat64(counter.WithLabelValues(string(KindAfter), "ShutdownTime")))
+	assert.Equal(t, float64(0), testutil.ToFloat64(counter.WithLabelValues(string(KindImmediate), "")))
+}
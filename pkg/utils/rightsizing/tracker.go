@@ -0,0 +1,228 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package rightsizing keeps the recent claims and occupancy of SandboxSets observed by the SandboxSet controller, and
+// recommends the sizes of the pools from them, so that teams can tell how much of their warm pools is never claimed.
+package rightsizing
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+const (
+	// Retention is the longest window the observations are kept for
+	Retention = 24 * time.Hour
+	// maxEvents is the number of observations kept per pool and kind, so that busy pools do not take unbounded memory
+	maxEvents = 10000
+	// minClaims is the number of claims of a pool required before its sizes are recommended
+	minClaims = 20
+	// minReleases is the number of released sandboxes of a pool required before their TTL is recommended
+	minReleases = 20
+)
+
+// Default is the Tracker observed by the SandboxSet controller
+var Default = NewTracker()
+
+type releaseEvent struct {
+	time time.Time
+	held time.Duration
+}
+
+type occupancySample struct {
+	time      time.Time
+	available int32
+}
+
+type poolEvents struct {
+	// claims, releases and samples are ordered by time, oldest first
+	claims   []time.Time
+	releases []releaseEvent
+	samples  []occupancySample
+}
+
+// Tracker keeps the claims, releases and occupancy of each pool within the Retention.
+type Tracker struct {
+	mu    sync.Mutex
+	pools map[string]*poolEvents
+}
+
+func NewTracker() *Tracker {
+	return &Tracker{pools: make(map[string]*poolEvents)}
+}
+
+// ObserveClaim records a sandbox claimed from the pool at now.
+func (t *Tracker) ObserveClaim(namespace, pool string, now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	events := t.pool(namespace, pool)
+	events.claims = trim(append(events.claims, now), func(e time.Time) time.Time { return e }, now)
+}
+
+// ObserveRelease records a sandbox claimed from the pool deleted at now, after it was held for the duration.
+func (t *Tracker) ObserveRelease(namespace, pool string, held time.Duration, now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	events := t.pool(namespace, pool)
+	events.releases = trim(append(events.releases, releaseEvent{time: now, held: held}),
+		func(e releaseEvent) time.Time { return e.time }, now)
+}
+
+// ObserveOccupancy records the available sandboxes of the pool at now, if changed since the last observation.
+func (t *Tracker) ObserveOccupancy(namespace, pool string, available int32, now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	events := t.pool(namespace, pool)
+	if n := len(events.samples); n > 0 && events.samples[n-1].available == available {
+		return
+	}
+	events.samples = trim(append(events.samples, occupancySample{time: now, available: available}),
+		func(e occupancySample) time.Time { return e.time }, now)
+}
+
+// Forget drops the observations of a deleted pool
+func (t *Tracker) Forget(namespace, pool string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.pools, key(namespace, pool))
+}
+
+// Recommendation is the recommended sizes of a pool and the observations they are made from.
+type Recommendation struct {
+	// Window is how long the pool is observed for
+	Window time.Duration
+	// Claims is the number of sandboxes claimed in the window
+	Claims int
+	// PeakClaims and P95Claims are the maximum and the p95 of the sandboxes claimed per refill window
+	PeakClaims int32
+	P95Claims  int32
+	// MinAvailable is the fewest available sandboxes observed, i.e. the part of the pool never claimed
+	MinAvailable int32
+	// Replicas covers the claims of 95% of the refill windows
+	Replicas int32
+	// Standby absorbs the claims of the peak refill window beyond the replicas
+	Standby int32
+	// SandboxTTL is the p95 of how long the claimed sandboxes are held, zero if too few are released
+	SandboxTTL time.Duration
+}
+
+// Report summarizes the recommendation in a sentence.
+func (r *Recommendation) Report(refill time.Duration) string {
+	report := fmt.Sprintf("%d sandboxes claimed in the last %v, at most %d and p95 %d per %v, at least %d available at all times",
+		r.Claims, r.Window.Round(time.Minute), r.PeakClaims, r.P95Claims, refill, r.MinAvailable)
+	if r.SandboxTTL > 0 {
+		report += fmt.Sprintf(", held for %v at p95", r.SandboxTTL)
+	}
+	return report
+}
+
+// Recommend recommends the sizes of the pool from its observations up to now, given how long the pool takes to refill
+// a claimed sandbox. The replicas cover the sandboxes claimed in 95% of the refill windows, and the standby the rest of
+// the peak window. False if too few claims are observed for the recommendation to be meaningful.
+func (t *Tracker) Recommend(namespace, pool string, refill time.Duration, now time.Time) (Recommendation, bool) {
+	t.mu.Lock()
+	events, ok := t.pools[key(namespace, pool)]
+	if !ok {
+		t.mu.Unlock()
+		return Recommendation{}, false
+	}
+	claims := slices.Clone(events.claims)
+	releases := slices.Clone(events.releases)
+	samples := slices.Clone(events.samples)
+	t.mu.Unlock()
+
+	since := now.Add(-Retention)
+	claims = claims[countBefore(claims, func(e time.Time) time.Time { return e }, since):]
+	if len(claims) < minClaims || refill <= 0 {
+		return Recommendation{}, false
+	}
+	start := claims[0]
+	if len(samples) > 0 && samples[0].time.Before(start) {
+		start = samples[0].time
+	}
+	if start.Before(since) {
+		start = since
+	}
+	rec := Recommendation{Window: now.Sub(start), Claims: len(claims)}
+
+	// claims per refill window, the windows without claims included
+	buckets := make([]int32, int(rec.Window/refill)+1)
+	for _, claimed := range claims {
+		buckets[int(claimed.Sub(start)/refill)]++
+	}
+	slices.Sort(buckets)
+	rec.PeakClaims = buckets[len(buckets)-1]
+	rec.P95Claims = percentile(buckets, 95)
+	rec.Replicas = max(rec.P95Claims, 1)
+	rec.Standby = rec.PeakClaims - min(rec.PeakClaims, rec.Replicas)
+
+	// the samples are only recorded on changes, so an older one may still be in effect in the window
+	for i, sample := range samples {
+		if i == 0 || sample.available < rec.MinAvailable {
+			rec.MinAvailable = sample.available
+		}
+	}
+
+	releases = releases[countBefore(releases, func(e releaseEvent) time.Time { return e.time }, since):]
+	if len(releases) >= minReleases {
+		held := make([]time.Duration, len(releases))
+		for i := range releases {
+			held[i] = releases[i].held
+		}
+		slices.Sort(held)
+		rec.SandboxTTL = percentile(held, 95).Round(time.Minute)
+	}
+	return rec, true
+}
+
+func (t *Tracker) pool(namespace, pool string) *poolEvents {
+	events, ok := t.pools[key(namespace, pool)]
+	if !ok {
+		events = &poolEvents{}
+		t.pools[key(namespace, pool)] = events
+	}
+	return events
+}
+
+func key(namespace, pool string) string {
+	return namespace + "/" + pool
+}
+
+// percentile returns the nearest-rank p-th percentile of the sorted values
+func percentile[T any](sorted []T, p int) T {
+	rank := (len(sorted)*p + 99) / 100
+	return sorted[max(rank, 1)-1]
+}
+
+// countBefore counts the events before the time
+func countBefore[T any](events []T, timeOf func(T) time.Time, since time.Time) int {
+	n := 0
+	for n < len(events) && timeOf(events[n]).Before(since) {
+		n++
+	}
+	return n
+}
+
+// trim drops the events beyond the Retention or maxEvents
+func trim[T any](events []T, timeOf func(T) time.Time, now time.Time) []T {
+	start := max(len(events)-maxEvents, 0)
+	for start < len(events) && now.Sub(timeOf(events[start])) > Retention {
+		start++
+	}
+	return events[start:]
+}
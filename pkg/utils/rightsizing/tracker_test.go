@@ -0,0 +1,134 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rightsizing
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTracker_Recommend(t *testing.T) {
+	now := time.Now()
+	start := now.Add(-time.Hour)
+	tracker := NewTracker()
+	// a claim every minute, with a burst of 9 more in the 30th minute
+	for i := range 60 {
+		tracker.ObserveClaim("default", "pool", start.Add(time.Duration(i)*time.Minute))
+		if i == 30 {
+			for range 9 {
+				tracker.ObserveClaim("default", "pool", start.Add(30*time.Minute))
+			}
+		}
+	}
+	tracker.ObserveOccupancy("default", "pool", 5, start)
+	tracker.ObserveOccupancy("default", "pool", 5, start.Add(time.Minute))
+	tracker.ObserveOccupancy("default", "pool", 2, start.Add(30*time.Minute))
+	tracker.ObserveOccupancy("default", "pool", 4, now.Add(-10*time.Minute))
+	for i := 1; i <= minReleases; i++ {
+		tracker.ObserveRelease("default", "pool", time.Duration(i)*time.Minute, now)
+	}
+	tracker.ObserveClaim("default", "other", now)
+
+	tests := []struct {
+		name     string
+		pool     string
+		refill   time.Duration
+		expected Recommendation
+		expectOK bool
+	}{
+		{
+			name:   "replicas cover the p95 and standby the peak",
+			pool:   "pool",
+			refill: time.Minute,
+			expected: Recommendation{
+				Window:       time.Hour,
+				Claims:       69,
+				PeakClaims:   10,
+				P95Claims:    1,
+				MinAvailable: 2,
+				Replicas:     1,
+				Standby:      9,
+				SandboxTTL:   19 * time.Minute,
+			},
+			expectOK: true,
+		},
+		{
+			name:   "longer refill windows",
+			pool:   "pool",
+			refill: 30 * time.Minute,
+			expected: Recommendation{
+				Window:       time.Hour,
+				Claims:       69,
+				PeakClaims:   39,
+				P95Claims:    39,
+				MinAvailable: 2,
+				Replicas:     39,
+				SandboxTTL:   19 * time.Minute,
+			},
+			expectOK: true,
+		},
+		{
+			name:   "too few claims",
+			pool:   "other",
+			refill: time.Minute,
+		},
+		{
+			name:   "unknown pool",
+			pool:   "unknown",
+			refill: time.Minute,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec, ok := tracker.Recommend("default", tt.pool, tt.refill, now)
+			assert.Equal(t, tt.expectOK, ok)
+			assert.Equal(t, tt.expected, rec)
+		})
+	}
+}
+
+func TestTracker_Retention(t *testing.T) {
+	now := time.Now()
+	tracker := NewTracker()
+	for i := range minClaims {
+		tracker.ObserveClaim("default", "pool", now.Add(-Retention-time.Duration(minClaims-i)*time.Minute))
+	}
+	tracker.ObserveClaim("default", "pool", now)
+	_, ok := tracker.Recommend("default", "pool", time.Minute, now)
+	assert.False(t, ok, "claims beyond the retention are dropped")
+
+	for range minClaims {
+		tracker.ObserveClaim("default", "pool", now)
+	}
+	_, ok = tracker.Recommend("default", "pool", time.Minute, now)
+	require.True(t, ok)
+	tracker.Forget("default", "pool")
+	_, ok = tracker.Recommend("default", "pool", time.Minute, now)
+	assert.False(t, ok)
+}
+
+func TestRecommendation_Report(t *testing.T) {
+	rec := Recommendation{Window: time.Hour, Claims: 69, PeakClaims: 10, P95Claims: 1, MinAvailable: 2}
+	assert.Equal(t, "69 sandboxes claimed in the last 1h0m0s, at most 10 and p95 1 per 1m0s, at least 2 available at all times",
+		rec.Report(time.Minute))
+	rec.SandboxTTL = 19 * time.Minute
+	assert.Equal(t, "69 sandboxes claimed in the last 1h0m0s, at most 10 and p95 1 per 1m0s, at least 2 available at all times, "+
+		"held for 19m0s at p95", rec.Report(time.Minute))
+}
@@ -6,11 +6,13 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"time"
 
 	"k8s.io/klog/v2"
 
 	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
 	"github.com/openkruise/agents/pkg/proxy"
+	"github.com/openkruise/agents/pkg/utils/sandboxstate"
 	stateutils "github.com/openkruise/agents/pkg/utils/sandboxutils"
 )
 
@@ -20,15 +22,13 @@ var (
 )
 
 func getRouteFromSandbox(s *agentsv1alpha1.Sandbox) proxy.Route {
-	state, _ := stateutils.GetSandboxState(s)
-	if s.Status.PodInfo.PodIP == "" {
-		state = agentsv1alpha1.SandboxStateCreating
-	}
+	state, _ := sandboxstate.Evaluate(s, time.Now(), sandboxstate.Policy{RequirePodIP: true})
 	return proxy.Route{
 		IP:              s.Status.PodInfo.PodIP,
 		ID:              stateutils.GetSandboxID(s),
 		UID:             s.GetUID(),
 		Owner:           s.GetAnnotations()[agentsv1alpha1.AnnotationOwner],
+		Template:        s.GetLabels()[agentsv1alpha1.LabelSandboxTemplate],
 		State:           state,
 		ResourceVersion: s.GetResourceVersion(),
 	}
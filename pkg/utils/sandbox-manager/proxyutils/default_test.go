@@ -26,6 +26,7 @@ func TestGetRouteFromSandbox(t *testing.T) {
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      "running-sandbox",
 					Namespace: "default",
+					Labels:    map[string]string{v1alpha1.LabelSandboxTemplate: "pool"},
 				},
 				Status: v1alpha1.SandboxStatus{
 					Phase: v1alpha1.SandboxRunning,
@@ -41,10 +42,11 @@ func TestGetRouteFromSandbox(t *testing.T) {
 				},
 			},
 			expectedRoute: proxy.Route{
-				IP:    "10.0.0.2",
-				ID:    "default--running-sandbox",
-				Owner: "",
-				State: v1alpha1.SandboxStateRunning,
+				IP:       "10.0.0.2",
+				ID:       "default--running-sandbox",
+				Owner:    "",
+				Template: "pool",
+				State:    v1alpha1.SandboxStateRunning,
 			},
 		},
 		{
@@ -72,6 +74,24 @@ func TestGetRouteFromSandbox(t *testing.T) {
 				State: v1alpha1.SandboxStateCreating,
 			},
 		},
+		{
+			name: "failed sandbox without ip",
+			sandbox: &v1alpha1.Sandbox{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "failed-sandbox",
+					Namespace: "default",
+				},
+				Status: v1alpha1.SandboxStatus{
+					Phase: v1alpha1.SandboxFailed,
+				},
+			},
+			expectedRoute: proxy.Route{
+				IP:    "",
+				ID:    "default--failed-sandbox",
+				Owner: "",
+				State: v1alpha1.SandboxStateDead,
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,310 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package sandboxid generates the IDs sandboxes are addressed by, e.g. in the E2B API and the routes of the proxy and
+// gateway. The scheme is configured per process, and all the processes routing to the same sandboxes must agree on it.
+package sandboxid
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"text/template"
+	"time"
+
+	"github.com/spf13/pflag"
+	"k8s.io/apimachinery/pkg/types"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+)
+
+const (
+	// SchemeNamespaced is the `<namespace>--<name>` ID of the sandbox, which can be parsed back.
+	SchemeNamespaced = "namespaced"
+	// SchemeHash is a keyed hash of the namespace and name of the sandbox, short, DNS-safe and not enumerable
+	// without the key.
+	SchemeHash = "hash"
+	// SchemeULID is a random, time-ordered ULID assigned when the sandbox is claimed and kept in its annotation.
+	SchemeULID = "ulid"
+	// SchemeFormat renders the ID with a text/template of the sandbox, for the IDs of an external system.
+	SchemeFormat = "format"
+)
+
+// Environment variables of the options, for the processes configured without flags, e.g. the gateway.
+const (
+	EnvScheme = "SANDBOX_ID_SCHEME"
+	EnvKey    = "SANDBOX_ID_KEY"
+	EnvLength = "SANDBOX_ID_LENGTH"
+	EnvPrefix = "SANDBOX_ID_PREFIX"
+	EnvFormat = "SANDBOX_ID_FORMAT"
+)
+
+// Scheme generates the IDs of sandboxes.
+type Scheme interface {
+	// ID returns the ID of the sandbox.
+	ID(sbx *agentsv1alpha1.Sandbox) string
+	// Assign records the ID on a sandbox being claimed, for the schemes not deriving it from the sandbox.
+	Assign(sbx *agentsv1alpha1.Sandbox)
+	// Parse resolves the namespace and name of a sandbox from its ID, if the ID encodes them. As IDs of different
+	// sandboxes may parse the same, the caller checks the ID of the resolved sandbox. The other IDs are resolved with
+	// the index of the claimed sandboxes by ID.
+	Parse(id string) (types.NamespacedName, bool)
+}
+
+// Options configure the scheme of the process.
+type Options struct {
+	// Scheme is one of SchemeNamespaced, SchemeHash, SchemeULID and SchemeFormat.
+	Scheme string
+	// Key is the secret key of SchemeHash.
+	Key string
+	// Length is the number of characters of the hash of SchemeHash.
+	Length int
+	// Prefix is prepended to the IDs of SchemeHash and SchemeULID, so that they start with a letter as DNS labels do.
+	Prefix string
+	// Format is the text/template of SchemeFormat, executed on the sandbox, e.g. `{{.Labels.tenant}}-{{.Name}}`.
+	Format string
+}
+
+// DefaultOptions is set by the command line flags, and applied with Apply after they are parsed.
+var DefaultOptions = Options{
+	Scheme: SchemeNamespaced,
+	Length: 20,
+	Prefix: "sbx-",
+}
+
+// AddFlags registers the flags of the options. The key defaults to the environment variable, to keep it out of the
+// command line.
+func (o *Options) AddFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&o.Scheme, "sandbox-id-scheme", o.Scheme,
+		"The scheme of sandbox IDs: namespaced (<namespace>--<name>), hash (keyed hash of the namespace and name), "+
+			"ulid (random, assigned on claim) or format (rendered with --sandbox-id-format).")
+	fs.StringVar(&o.Key, "sandbox-id-key", os.Getenv(EnvKey),
+		"The secret key of the hash sandbox ID scheme. Defaults to $"+EnvKey+".")
+	fs.IntVar(&o.Length, "sandbox-id-length", o.Length, "The number of characters of the hash of the hash sandbox ID scheme.")
+	fs.StringVar(&o.Prefix, "sandbox-id-prefix", o.Prefix, "The prefix of the sandbox IDs of the hash and ulid schemes.")
+	fs.StringVar(&o.Format, "sandbox-id-format", o.Format,
+		"The text/template of the format sandbox ID scheme, executed on the Sandbox, e.g. '{{.Labels.tenant}}-{{.Name}}'.")
+}
+
+// LoadEnv overrides the options with the environment variables set.
+func (o *Options) LoadEnv() error {
+	if v, ok := os.LookupEnv(EnvScheme); ok {
+		o.Scheme = v
+	}
+	if v, ok := os.LookupEnv(EnvKey); ok {
+		o.Key = v
+	}
+	if v, ok := os.LookupEnv(EnvLength); ok {
+		length, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("invalid %s %q: %w", EnvLength, v, err)
+		}
+		o.Length = length
+	}
+	if v, ok := os.LookupEnv(EnvPrefix); ok {
+		o.Prefix = v
+	}
+	if v, ok := os.LookupEnv(EnvFormat); ok {
+		o.Format = v
+	}
+	return nil
+}
+
+// New builds the scheme of the options.
+func (o Options) New() (Scheme, error) {
+	switch o.Scheme {
+	case "", SchemeNamespaced:
+		return namespacedScheme{}, nil
+	case SchemeHash:
+		if o.Key == "" {
+			return nil, fmt.Errorf("the %s sandbox ID scheme requires a key", SchemeHash)
+		}
+		// 52 characters encode the whole sha256
+		if o.Length < 8 || o.Length > 52 {
+			return nil, fmt.Errorf("the length of the %s sandbox ID scheme must be within [8, 52], got %d", SchemeHash, o.Length)
+		}
+		return hashScheme{key: []byte(o.Key), length: o.Length, prefix: o.Prefix}, nil
+	case SchemeULID:
+		return ulidScheme{prefix: o.Prefix}, nil
+	case SchemeFormat:
+		if o.Format == "" {
+			return nil, fmt.Errorf("the %s sandbox ID scheme requires a format", SchemeFormat)
+		}
+		tmpl, err := template.New("sandbox-id").Option("missingkey=zero").Parse(o.Format)
+		if err != nil {
+			return nil, fmt.Errorf("invalid sandbox ID format: %w", err)
+		}
+		return formatScheme{tmpl: tmpl}, nil
+	default:
+		return nil, fmt.Errorf("unknown sandbox ID scheme %q", o.Scheme)
+	}
+}
+
+// Apply builds the scheme of the options and makes it the one of the process.
+func (o Options) Apply() error {
+	scheme, err := o.New()
+	if err != nil {
+		return err
+	}
+	SetDefault(scheme)
+	return nil
+}
+
+var defaultScheme atomic.Pointer[Scheme]
+
+func init() {
+	SetDefault(namespacedScheme{})
+}
+
+// SetDefault sets the scheme of the process.
+func SetDefault(scheme Scheme) {
+	defaultScheme.Store(&scheme)
+}
+
+// Default returns the scheme of the process.
+func Default() Scheme {
+	return *defaultScheme.Load()
+}
+
+// ID returns the ID of the sandbox with the scheme of the process.
+func ID(sbx *agentsv1alpha1.Sandbox) string {
+	return Default().ID(sbx)
+}
+
+// Assign records the ID on a sandbox being claimed with the scheme of the process.
+func Assign(sbx *agentsv1alpha1.Sandbox) {
+	Default().Assign(sbx)
+}
+
+// Parse resolves the namespace and name of a sandbox from its ID with the scheme of the process.
+func Parse(id string) (types.NamespacedName, bool) {
+	return Default().Parse(id)
+}
+
+func namespacedID(sbx *agentsv1alpha1.Sandbox) string {
+	return fmt.Sprintf("%s--%s", sbx.Namespace, sbx.Name)
+}
+
+type namespacedScheme struct{}
+
+func (namespacedScheme) ID(sbx *agentsv1alpha1.Sandbox) string {
+	return namespacedID(sbx)
+}
+
+func (namespacedScheme) Assign(*agentsv1alpha1.Sandbox) {}
+
+func (namespacedScheme) Parse(id string) (types.NamespacedName, bool) {
+	// namespaces never contain "--" in practice, while names may
+	namespace, name, ok := strings.Cut(id, "--")
+	if !ok || namespace == "" || name == "" {
+		return types.NamespacedName{}, false
+	}
+	return types.NamespacedName{Namespace: namespace, Name: name}, true
+}
+
+var hashEncoding = base32.NewEncoding("abcdefghijklmnopqrstuvwxyz234567").WithPadding(base32.NoPadding)
+
+type hashScheme struct {
+	key    []byte
+	length int
+	prefix string
+}
+
+func (s hashScheme) ID(sbx *agentsv1alpha1.Sandbox) string {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(sbx.Namespace + "/" + sbx.Name))
+	return s.prefix + hashEncoding.EncodeToString(mac.Sum(nil))[:s.length]
+}
+
+func (hashScheme) Assign(*agentsv1alpha1.Sandbox) {}
+
+func (hashScheme) Parse(string) (types.NamespacedName, bool) {
+	return types.NamespacedName{}, false
+}
+
+// crockford is the lowercase base32 alphabet of ULIDs
+const crockford = "0123456789abcdefghjkmnpqrstvwxyz"
+
+type ulidScheme struct {
+	prefix string
+}
+
+// ID returns the ULID assigned to the sandbox. Sandboxes claimed before the scheme was configured keep their
+// namespaced IDs.
+func (ulidScheme) ID(sbx *agentsv1alpha1.Sandbox) string {
+	if id := sbx.Annotations[agentsv1alpha1.AnnotationSandboxID]; id != "" {
+		return id
+	}
+	return namespacedID(sbx)
+}
+
+func (s ulidScheme) Assign(sbx *agentsv1alpha1.Sandbox) {
+	if sbx.Annotations[agentsv1alpha1.AnnotationSandboxID] != "" {
+		return
+	}
+	if sbx.Annotations == nil {
+		sbx.Annotations = make(map[string]string, 1)
+	}
+	sbx.Annotations[agentsv1alpha1.AnnotationSandboxID] = s.prefix + newULID(time.Now())
+}
+
+// Parse resolves the namespaced IDs of the sandboxes without assigned IDs.
+func (ulidScheme) Parse(id string) (types.NamespacedName, bool) {
+	return namespacedScheme{}.Parse(id)
+}
+
+// newULID returns a ULID of the time: 48 bits of milliseconds followed by 80 random bits, in 26 characters.
+func newULID(now time.Time) string {
+	var b [16]byte
+	binary.BigEndian.PutUint64(b[:8], uint64(now.UnixMilli())<<16)
+	_, _ = rand.Read(b[6:])
+	n := new(big.Int).SetBytes(b[:])
+	out := make([]byte, 26)
+	mask := big.NewInt(31)
+	for i := len(out) - 1; i >= 0; i-- {
+		out[i] = crockford[new(big.Int).And(n, mask).Int64()]
+		n.Rsh(n, 5)
+	}
+	return string(out)
+}
+
+type formatScheme struct {
+	tmpl *template.Template
+}
+
+// ID renders the format on the sandbox, falling back to the namespaced ID if it fails or renders nothing.
+func (s formatScheme) ID(sbx *agentsv1alpha1.Sandbox) string {
+	var buf strings.Builder
+	if err := s.tmpl.Execute(&buf, sbx); err != nil || buf.Len() == 0 {
+		return namespacedID(sbx)
+	}
+	return buf.String()
+}
+
+func (formatScheme) Assign(*agentsv1alpha1.Sandbox) {}
+
+// Parse resolves the namespaced IDs the format falls back to.
+func (formatScheme) Parse(id string) (types.NamespacedName, bool) {
+	return namespacedScheme{}.Parse(id)
+}
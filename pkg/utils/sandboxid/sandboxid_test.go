@@ -0,0 +1,185 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sandboxid
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+)
+
+func newSandbox(namespace, name string) *agentsv1alpha1.Sandbox {
+	return &agentsv1alpha1.Sandbox{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name}}
+}
+
+func TestOptions_New(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      Options
+		expectErr string
+	}{
+		{name: "default", opts: Options{}},
+		{name: "namespaced", opts: Options{Scheme: SchemeNamespaced}},
+		{name: "hash", opts: Options{Scheme: SchemeHash, Key: "secret", Length: 20}},
+		{name: "hash without key", opts: Options{Scheme: SchemeHash, Length: 20}, expectErr: "the hash sandbox ID scheme requires a key"},
+		{name: "hash too short", opts: Options{Scheme: SchemeHash, Key: "secret", Length: 4}, expectErr: "the length of the hash sandbox ID scheme must be within [8, 52], got 4"},
+		{name: "ulid", opts: Options{Scheme: SchemeULID}},
+		{name: "format", opts: Options{Scheme: SchemeFormat, Format: "{{.Name}}"}},
+		{name: "format without template", opts: Options{Scheme: SchemeFormat}, expectErr: "the format sandbox ID scheme requires a format"},
+		{name: "invalid format", opts: Options{Scheme: SchemeFormat, Format: "{{.Name"}, expectErr: "invalid sandbox ID format"},
+		{name: "unknown", opts: Options{Scheme: "uuid"}, expectErr: `unknown sandbox ID scheme "uuid"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme, err := tt.opts.New()
+			if tt.expectErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.NotNil(t, scheme)
+		})
+	}
+}
+
+func TestNamespacedScheme(t *testing.T) {
+	scheme := namespacedScheme{}
+	sbx := newSandbox("default", "sbx--1")
+	id := scheme.ID(sbx)
+	assert.Equal(t, "default--sbx--1", id)
+
+	key, ok := scheme.Parse(id)
+	assert.True(t, ok)
+	assert.Equal(t, types.NamespacedName{Namespace: "default", Name: "sbx--1"}, key)
+	for _, invalid := range []string{"default", "--sbx", "default--"} {
+		_, ok = scheme.Parse(invalid)
+		assert.False(t, ok, invalid)
+	}
+}
+
+func TestHashScheme(t *testing.T) {
+	scheme, err := Options{Scheme: SchemeHash, Key: "secret", Length: 20, Prefix: "sbx-"}.New()
+	require.NoError(t, err)
+	id := scheme.ID(newSandbox("default", "sbx-1"))
+	assert.Len(t, id, len("sbx-")+20)
+	assert.Empty(t, validation.IsDNS1123Label(id))
+	assert.Equal(t, id, scheme.ID(newSandbox("default", "sbx-1")), "IDs are stable")
+	assert.NotEqual(t, id, scheme.ID(newSandbox("default", "sbx-2")))
+	assert.NotEqual(t, id, scheme.ID(newSandbox("other", "sbx-1")))
+
+	other, err := Options{Scheme: SchemeHash, Key: "another", Length: 20, Prefix: "sbx-"}.New()
+	require.NoError(t, err)
+	assert.NotEqual(t, id, other.ID(newSandbox("default", "sbx-1")), "IDs depend on the key")
+
+	_, ok := scheme.Parse(id)
+	assert.False(t, ok)
+}
+
+func TestULIDScheme(t *testing.T) {
+	scheme := ulidScheme{prefix: "sbx-"}
+	sbx := newSandbox("default", "sbx-1")
+	assert.Equal(t, "default--sbx-1", scheme.ID(sbx), "sandboxes without assigned IDs keep namespaced IDs")
+
+	scheme.Assign(sbx)
+	id := scheme.ID(sbx)
+	assert.Regexp(t, regexp.MustCompile(`^sbx-[0-9a-hjkmnp-tv-z]{26}$`), id)
+	assert.Empty(t, validation.IsDNS1123Label(id))
+	assert.Equal(t, id, sbx.Annotations[agentsv1alpha1.AnnotationSandboxID])
+	_, ok := scheme.Parse(id)
+	assert.False(t, ok)
+	_, ok = scheme.Parse("default--sbx-1")
+	assert.True(t, ok, "namespaced IDs of sandboxes without assigned IDs are parsed")
+
+	// assigned IDs are kept
+	scheme.Assign(sbx)
+	assert.Equal(t, id, scheme.ID(sbx))
+	assert.NotEqual(t, id, func() string {
+		another := newSandbox("default", "sbx-1")
+		scheme.Assign(another)
+		return scheme.ID(another)
+	}(), "IDs are random")
+}
+
+func TestNewULID(t *testing.T) {
+	now := time.UnixMilli(1700000000000)
+	earlier, later := newULID(now), newULID(now.Add(time.Millisecond))
+	assert.Len(t, earlier, 26)
+	assert.Less(t, earlier, later, "ULIDs sort by time")
+	// the leading 10 characters encode the time
+	assert.Equal(t, earlier[:10], newULID(now)[:10])
+	assert.Equal(t, "01hf", earlier[:4])
+}
+
+func TestFormatScheme(t *testing.T) {
+	scheme, err := Options{Scheme: SchemeFormat, Format: "{{.Labels.tenant}}-{{.Name}}"}.New()
+	require.NoError(t, err)
+	sbx := newSandbox("default", "sbx-1")
+	sbx.Labels = map[string]string{"tenant": "acme"}
+	assert.Equal(t, "acme-sbx-1", scheme.ID(sbx))
+
+	scheme, err = Options{Scheme: SchemeFormat, Format: "{{.Annotations.external}}"}.New()
+	require.NoError(t, err)
+	assert.Equal(t, "default--sbx-1", scheme.ID(sbx), "empty IDs fall back to namespaced ones")
+}
+
+func TestApply(t *testing.T) {
+	t.Cleanup(func() { SetDefault(namespacedScheme{}) })
+	sbx := newSandbox("default", "sbx-1")
+	assert.Equal(t, "default--sbx-1", ID(sbx))
+
+	require.NoError(t, Options{Scheme: SchemeHash, Key: "secret", Length: 8, Prefix: "h"}.Apply())
+	assert.True(t, strings.HasPrefix(ID(sbx), "h"))
+	assert.Len(t, ID(sbx), 9)
+	_, ok := Parse(ID(sbx))
+	assert.False(t, ok)
+
+	assert.Error(t, Options{Scheme: "uuid"}.Apply())
+	assert.Len(t, ID(sbx), 9, "invalid options keep the scheme")
+}
+
+func TestOptions_FlagsAndEnv(t *testing.T) {
+	opts := DefaultOptions
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	opts.AddFlags(fs)
+	require.NoError(t, fs.Parse([]string{"--sandbox-id-scheme=hash", "--sandbox-id-key=secret"}))
+	assert.Equal(t, SchemeHash, opts.Scheme)
+	assert.Equal(t, "secret", opts.Key)
+	assert.Equal(t, DefaultOptions.Length, opts.Length)
+
+	t.Setenv(EnvScheme, SchemeFormat)
+	t.Setenv(EnvFormat, "{{.Name}}")
+	t.Setenv(EnvLength, "12")
+	require.NoError(t, opts.LoadEnv())
+	assert.Equal(t, SchemeFormat, opts.Scheme)
+	assert.Equal(t, "{{.Name}}", opts.Format)
+	assert.Equal(t, 12, opts.Length)
+	assert.Equal(t, "secret", opts.Key, "unset variables keep the options")
+
+	t.Setenv(EnvLength, "short")
+	assert.Error(t, opts.LoadEnv())
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package sandboxstate derives the state of sandboxes, i.e. creating, available, running, paused or dead. It is the
+// single place the state is derived, so that the pool controller, the claim allocator, the router and the metrics
+// always agree on it. The evaluation only depends on its explicit inputs, without any global state.
+package sandboxstate
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+	"github.com/openkruise/agents/pkg/utils"
+)
+
+// Policy tunes the evaluation for the needs of a component. The zero value is the default policy, which is used by
+// the controllers and the claim allocator.
+type Policy struct {
+	// RequirePodIP evaluates alive sandboxes without a pod IP as creating, since requests cannot be routed to them
+	// yet. Set by the routes to sandboxes.
+	RequirePodIP bool
+}
+
+// Evaluate returns the state of the sandbox at the time with the policy, and the reason of the state.
+// NOTE: the reason is unique and hard-coded, so we can easily search the conditions of some reason when debugging.
+func Evaluate(sbx *agentsv1alpha1.Sandbox, now time.Time, policy Policy) (state string, reason string) {
+	state, reason = evaluate(sbx, now)
+	if policy.RequirePodIP && state != agentsv1alpha1.SandboxStateDead && sbx.Status.PodInfo.PodIP == "" {
+		return agentsv1alpha1.SandboxStateCreating, "ResourceWithoutPodIP"
+	}
+	return state, reason
+}
+
+func evaluate(sbx *agentsv1alpha1.Sandbox, now time.Time) (string, string) {
+	if sbx.DeletionTimestamp != nil {
+		return agentsv1alpha1.SandboxStateDead, "ResourceDeleted"
+	}
+	if sbx.Spec.ShutdownTime != nil && now.After(sbx.Spec.ShutdownTime.Time) {
+		return agentsv1alpha1.SandboxStateDead, "ShutdownTimeReached"
+	}
+	switch sbx.Status.Phase {
+	case agentsv1alpha1.SandboxPending:
+		return agentsv1alpha1.SandboxStateCreating, "ResourcePending"
+	case agentsv1alpha1.SandboxSucceeded:
+		return agentsv1alpha1.SandboxStateDead, "ResourceSucceeded"
+	case agentsv1alpha1.SandboxFailed:
+		return agentsv1alpha1.SandboxStateDead, "ResourceFailed"
+	case agentsv1alpha1.SandboxTerminating:
+		return agentsv1alpha1.SandboxStateDead, "ResourceTerminating"
+	}
+
+	ready := IsReady(sbx)
+	if IsControlledBySandboxSet(sbx) {
+		if ready {
+			return agentsv1alpha1.SandboxStateAvailable, "ResourceControlledBySbsAndReady"
+		}
+		return agentsv1alpha1.SandboxStateCreating, "ResourceControlledBySbsButNotReady"
+	}
+	if sbx.Status.Phase != agentsv1alpha1.SandboxRunning {
+		// Paused and Resuming phases are both treated as paused state
+		return agentsv1alpha1.SandboxStatePaused, "NotRunningResourceClaimed"
+	}
+	if sbx.Spec.Paused {
+		return agentsv1alpha1.SandboxStatePaused, "RunningResourceClaimedAndPaused"
+	}
+	if ready {
+		return agentsv1alpha1.SandboxStateRunning, "RunningResourceClaimedAndReady"
+	}
+	return agentsv1alpha1.SandboxStateDead, "RunningResourceClaimedButNotReady"
+}
+
+// IsControlledBySandboxSet returns whether the sandbox is still in the pool of a SandboxSet, i.e. not claimed yet.
+func IsControlledBySandboxSet(sbx *agentsv1alpha1.Sandbox) bool {
+	controller := metav1.GetControllerOfNoCopy(sbx)
+	if controller == nil {
+		return false
+	}
+	return controller.Kind == agentsv1alpha1.SandboxSetControllerKind.Kind &&
+		// ** REMEMBER TO MODIFY THIS WHEN A NEW API VERSION(LIKE v1beta1) IS ADDED **
+		controller.APIVersion == agentsv1alpha1.SandboxSetControllerKind.GroupVersion().String()
+}
+
+// IsReady returns whether the Ready condition of the sandbox is true.
+func IsReady(sbx *agentsv1alpha1.Sandbox) bool {
+	readyCond := utils.GetSandboxCondition(&sbx.Status, string(agentsv1alpha1.SandboxConditionReady))
+	return readyCond != nil && readyCond.Status == metav1.ConditionTrue
+}
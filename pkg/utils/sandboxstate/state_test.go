@@ -0,0 +1,118 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sandboxstate
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+)
+
+func TestEvaluate(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	newSandbox := func(pooled bool, phase agentsv1alpha1.SandboxPhase, ready bool, podIP string) *agentsv1alpha1.Sandbox {
+		sbx := &agentsv1alpha1.Sandbox{
+			Status: agentsv1alpha1.SandboxStatus{
+				Phase:   phase,
+				PodInfo: agentsv1alpha1.PodInfo{PodIP: podIP},
+			},
+		}
+		if pooled {
+			sbx.OwnerReferences = []metav1.OwnerReference{{
+				APIVersion: agentsv1alpha1.SandboxSetControllerKind.GroupVersion().String(),
+				Kind:       agentsv1alpha1.SandboxSetControllerKind.Kind,
+				Controller: ptr.To(true),
+			}}
+		}
+		if ready {
+			sbx.Status.Conditions = []metav1.Condition{{
+				Type:   string(agentsv1alpha1.SandboxConditionReady),
+				Status: metav1.ConditionTrue,
+			}}
+		}
+		return sbx
+	}
+	withShutdownTime := func(sbx *agentsv1alpha1.Sandbox, shutdownTime time.Time) *agentsv1alpha1.Sandbox {
+		sbx.Spec.ShutdownTime = ptr.To(metav1.NewTime(shutdownTime))
+		return sbx
+	}
+
+	tests := []struct {
+		name           string
+		sandbox        *agentsv1alpha1.Sandbox
+		policy         Policy
+		expectedState  string
+		expectedReason string
+	}{
+		{
+			name:           "available in pool",
+			sandbox:        newSandbox(true, agentsv1alpha1.SandboxRunning, true, "1.2.3.4"),
+			expectedState:  agentsv1alpha1.SandboxStateAvailable,
+			expectedReason: "ResourceControlledBySbsAndReady",
+		},
+		{
+			name:           "claimed and running",
+			sandbox:        newSandbox(false, agentsv1alpha1.SandboxRunning, true, "1.2.3.4"),
+			expectedState:  agentsv1alpha1.SandboxStateRunning,
+			expectedReason: "RunningResourceClaimedAndReady",
+		},
+		{
+			name:           "shutdown time is evaluated at the given time",
+			sandbox:        withShutdownTime(newSandbox(false, agentsv1alpha1.SandboxRunning, true, "1.2.3.4"), now.Add(-time.Second)),
+			expectedState:  agentsv1alpha1.SandboxStateDead,
+			expectedReason: "ShutdownTimeReached",
+		},
+		{
+			name:           "shutdown time not reached at the given time",
+			sandbox:        withShutdownTime(newSandbox(false, agentsv1alpha1.SandboxRunning, true, "1.2.3.4"), now),
+			expectedState:  agentsv1alpha1.SandboxStateRunning,
+			expectedReason: "RunningResourceClaimedAndReady",
+		},
+		{
+			name:           "no pod ip with the default policy",
+			sandbox:        newSandbox(false, agentsv1alpha1.SandboxRunning, true, ""),
+			expectedState:  agentsv1alpha1.SandboxStateRunning,
+			expectedReason: "RunningResourceClaimedAndReady",
+		},
+		{
+			name:           "no pod ip when pod ip is required",
+			sandbox:        newSandbox(false, agentsv1alpha1.SandboxRunning, true, ""),
+			policy:         Policy{RequirePodIP: true},
+			expectedState:  agentsv1alpha1.SandboxStateCreating,
+			expectedReason: "ResourceWithoutPodIP",
+		},
+		{
+			name:           "dead without pod ip when pod ip is required",
+			sandbox:        newSandbox(false, agentsv1alpha1.SandboxFailed, false, ""),
+			policy:         Policy{RequirePodIP: true},
+			expectedState:  agentsv1alpha1.SandboxStateDead,
+			expectedReason: "ResourceFailed",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state, reason := Evaluate(tt.sandbox, now, tt.policy)
+			assert.Equal(t, tt.expectedState, state)
+			assert.Equal(t, tt.expectedReason, reason)
+		})
+	}
+}
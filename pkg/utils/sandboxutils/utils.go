@@ -1,77 +1,54 @@
 package sandboxutils
 
 import (
-	"fmt"
 	"time"
 
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
-	"github.com/openkruise/agents/pkg/utils"
+	"github.com/openkruise/agents/pkg/utils/sandboxid"
+	"github.com/openkruise/agents/pkg/utils/sandboxstate"
 )
 
-// GetSandboxState the state of agentsv1alpha1 Sandbox.
-// NOTE: the reason is unique and hard-coded, so we can easily search the conditions of some reason when debugging.
+// GetSandboxState the state of agentsv1alpha1 Sandbox, evaluated now with the default policy.
+// Components evaluating many sandboxes at once should use sandboxstate.Evaluate with a single time instead.
 func GetSandboxState(sbx *agentsv1alpha1.Sandbox) (state string, reason string) {
-	if sbx.DeletionTimestamp != nil {
-		return agentsv1alpha1.SandboxStateDead, "ResourceDeleted"
-	}
-	if sbx.Spec.ShutdownTime != nil && time.Since(sbx.Spec.ShutdownTime.Time) > 0 {
-		return agentsv1alpha1.SandboxStateDead, "ShutdownTimeReached"
-	}
-	if sbx.Status.Phase == agentsv1alpha1.SandboxPending {
-		return agentsv1alpha1.SandboxStateCreating, "ResourcePending"
-	}
-	if sbx.Status.Phase == agentsv1alpha1.SandboxSucceeded {
-		return agentsv1alpha1.SandboxStateDead, "ResourceSucceeded"
-	}
-	if sbx.Status.Phase == agentsv1alpha1.SandboxFailed {
-		return agentsv1alpha1.SandboxStateDead, "ResourceFailed"
-	}
-	if sbx.Status.Phase == agentsv1alpha1.SandboxTerminating {
-		return agentsv1alpha1.SandboxStateDead, "ResourceTerminating"
-	}
-
-	sandboxReady := IsSandboxReady(sbx)
-	if IsControlledBySandboxSet(sbx) {
-		if sandboxReady {
-			return agentsv1alpha1.SandboxStateAvailable, "ResourceControlledBySbsAndReady"
-		} else {
-			return agentsv1alpha1.SandboxStateCreating, "ResourceControlledBySbsButNotReady"
-		}
-	} else {
-		if sbx.Status.Phase == agentsv1alpha1.SandboxRunning {
-			if sbx.Spec.Paused {
-				return agentsv1alpha1.SandboxStatePaused, "RunningResourceClaimedAndPaused"
-			} else {
-				if sandboxReady {
-					return agentsv1alpha1.SandboxStateRunning, "RunningResourceClaimedAndReady"
-				} else {
-					return agentsv1alpha1.SandboxStateDead, "RunningResourceClaimedButNotReady"
-				}
-			}
-		} else {
-			// Paused and Resuming phases are both treated as paused state
-			return agentsv1alpha1.SandboxStatePaused, "NotRunningResourceClaimed"
-		}
-	}
+	return sandboxstate.Evaluate(sbx, time.Now(), sandboxstate.Policy{})
 }
 
 func IsControlledBySandboxSet(sbx *agentsv1alpha1.Sandbox) bool {
-	controller := metav1.GetControllerOfNoCopy(sbx)
-	if controller == nil {
-		return false
-	}
-	return controller.Kind == agentsv1alpha1.SandboxSetControllerKind.Kind &&
-		// ** REMEMBER TO MODIFY THIS WHEN A NEW API VERSION(LIKE v1beta1) IS ADDED **
-		controller.APIVersion == agentsv1alpha1.SandboxSetControllerKind.GroupVersion().String()
+	return sandboxstate.IsControlledBySandboxSet(sbx)
 }
 
+// GetSandboxID returns the ID of the sandbox with the sandbox ID scheme of the process, see sandboxid.
 func GetSandboxID(sbx *agentsv1alpha1.Sandbox) string {
-	return fmt.Sprintf("%s--%s", sbx.Namespace, sbx.Name)
+	return sandboxid.ID(sbx)
 }
 
 func IsSandboxReady(sbx *agentsv1alpha1.Sandbox) bool {
-	readyCond := utils.GetSandboxCondition(&sbx.Status, string(agentsv1alpha1.SandboxConditionReady))
-	return readyCond != nil && readyCond.Status == metav1.ConditionTrue
+	return sandboxstate.IsReady(sbx)
+}
+
+// ResumeLatency returns how long the latest resume of the sandbox took, from spec.paused being unset to the sandbox
+// being ready again. It is unknown until the sandbox is ready after a resume.
+func ResumeLatency(sbx *agentsv1alpha1.Sandbox) (time.Duration, bool) {
+	start := sbx.Status.ResumeStartTime
+	if start == nil {
+		return 0, false
+	}
+	cond := meta.FindStatusCondition(sbx.Status.Conditions, string(agentsv1alpha1.SandboxConditionReady))
+	if cond == nil || cond.Status != metav1.ConditionTrue {
+		return 0, false
+	}
+	return max(cond.LastTransitionTime.Sub(start.Time), 0), true
+}
+
+// ClaimSandboxSetName returns the SandboxSet a claim claims from: its templateName, or the SandboxSet picked by its
+// templateSelector. Empty if the claim of a templateSelector has not picked one yet.
+func ClaimSandboxSetName(claim *agentsv1alpha1.SandboxClaim) string {
+	if claim.Spec.TemplateName != "" {
+		return claim.Spec.TemplateName
+	}
+	return claim.Status.SandboxSetName
 }
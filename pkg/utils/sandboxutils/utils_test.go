@@ -368,3 +368,54 @@ func TestGetSandboxID(t *testing.T) {
 		})
 	}
 }
+
+func TestResumeLatency(t *testing.T) {
+	start := metav1.NewTime(time.Now().Add(-time.Minute))
+	readyAt := func(status metav1.ConditionStatus, at time.Time) []metav1.Condition {
+		return []metav1.Condition{{
+			Type:               string(agentsv1alpha1.SandboxConditionReady),
+			Status:             status,
+			LastTransitionTime: metav1.NewTime(at),
+		}}
+	}
+	tests := []struct {
+		name          string
+		status        agentsv1alpha1.SandboxStatus
+		expectLatency time.Duration
+		expectOk      bool
+	}{
+		{
+			name:   "never resumed",
+			status: agentsv1alpha1.SandboxStatus{Conditions: readyAt(metav1.ConditionTrue, start.Time)},
+		},
+		{
+			name:   "resuming",
+			status: agentsv1alpha1.SandboxStatus{ResumeStartTime: &start, Conditions: readyAt(metav1.ConditionFalse, start.Time)},
+		},
+		{
+			name:          "ready again",
+			status:        agentsv1alpha1.SandboxStatus{ResumeStartTime: &start, Conditions: readyAt(metav1.ConditionTrue, start.Add(3*time.Second))},
+			expectLatency: 3 * time.Second,
+			expectOk:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			latency, ok := ResumeLatency(&agentsv1alpha1.Sandbox{Status: tt.status})
+			assert.Equal(t, tt.expectOk, ok)
+			assert.Equal(t, tt.expectLatency, latency)
+		})
+	}
+}
+
+func TestClaimSandboxSetName(t *testing.T) {
+	templated := &agentsv1alpha1.SandboxClaim{
+		Spec:   agentsv1alpha1.SandboxClaimSpec{TemplateName: "pool"},
+		Status: agentsv1alpha1.SandboxClaimStatus{SandboxSetName: "stale"},
+	}
+	selected := &agentsv1alpha1.SandboxClaim{Status: agentsv1alpha1.SandboxClaimStatus{SandboxSetName: "picked"}}
+
+	assert.Equal(t, "pool", ClaimSandboxSetName(templated))
+	assert.Equal(t, "picked", ClaimSandboxSetName(selected))
+	assert.Empty(t, ClaimSandboxSetName(&agentsv1alpha1.SandboxClaim{}))
+}
@@ -0,0 +1,205 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package slo keeps the recent events the service level objectives of SandboxSets are evaluated on, i.e. the results
+// of their claims, warmups and resumes, which are observed by the controllers in the same process.
+package slo
+
+import (
+	"math"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// Retention is the longest window the events are kept for
+	Retention = 24 * time.Hour
+	// maxEvents is the number of events kept per pool and kind, so that busy pools do not take unbounded memory
+	maxEvents = 10000
+)
+
+// Default is the Tracker observed by the controllers
+var Default = NewTracker()
+
+type claimEvent struct {
+	time      time.Time
+	latency   time.Duration
+	completed bool
+}
+
+type warmupEvent struct {
+	time      time.Time
+	succeeded bool
+}
+
+type resumeEvent struct {
+	time    time.Time
+	latency time.Duration
+}
+
+type poolEvents struct {
+	// claims, warmups and resumes are ordered by time, oldest first
+	claims  []claimEvent
+	warmups []warmupEvent
+	resumes []resumeEvent
+}
+
+// Tracker keeps the claim and warmup events of each pool within the Retention.
+type Tracker struct {
+	mu    sync.Mutex
+	pools map[string]*poolEvents
+}
+
+func NewTracker() *Tracker {
+	return &Tracker{pools: make(map[string]*poolEvents)}
+}
+
+// ObserveClaim records a claim of the pool finished at now, either completed with all replicas after the latency or
+// timed out.
+func (t *Tracker) ObserveClaim(namespace, pool string, latency time.Duration, completed bool, now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	events := t.pool(namespace, pool)
+	events.claims = trim(append(events.claims, claimEvent{time: now, latency: latency, completed: completed}),
+		func(e claimEvent) time.Time { return e.time }, now)
+}
+
+// ObserveWarmup records a sandbox of the pool becoming available, or dying while being created, at now.
+func (t *Tracker) ObserveWarmup(namespace, pool string, succeeded bool, now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	events := t.pool(namespace, pool)
+	events.warmups = trim(append(events.warmups, warmupEvent{time: now, succeeded: succeeded}),
+		func(e warmupEvent) time.Time { return e.time }, now)
+}
+
+// ObserveResume records a paused sandbox of the pool ready again at now, resumed after the latency.
+func (t *Tracker) ObserveResume(namespace, pool string, latency time.Duration, now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	events := t.pool(namespace, pool)
+	events.resumes = trim(append(events.resumes, resumeEvent{time: now, latency: latency}),
+		func(e resumeEvent) time.Time { return e.time }, now)
+}
+
+// ClaimLatency counts the claims of the pool since the time, and those timed out or slower than the target.
+func (t *Tracker) ClaimLatency(namespace, pool string, target time.Duration, since time.Time) (total, bad int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	events, ok := t.pools[key(namespace, pool)]
+	if !ok {
+		return 0, 0
+	}
+	for _, e := range events.claims {
+		if e.time.Before(since) {
+			continue
+		}
+		total++
+		if !e.completed || e.latency > target {
+			bad++
+		}
+	}
+	return total, bad
+}
+
+// WarmupSuccess counts the sandboxes of the pool warmed up since the time, and those dying while being created.
+func (t *Tracker) WarmupSuccess(namespace, pool string, since time.Time) (total, bad int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	events, ok := t.pools[key(namespace, pool)]
+	if !ok {
+		return 0, 0
+	}
+	for _, e := range events.warmups {
+		if e.time.Before(since) {
+			continue
+		}
+		total++
+		if !e.succeeded {
+			bad++
+		}
+	}
+	return total, bad
+}
+
+// ResumeLatency counts the resumes of the sandboxes of the pool since the time, and those slower than the target.
+func (t *Tracker) ResumeLatency(namespace, pool string, target time.Duration, since time.Time) (total, bad int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	events, ok := t.pools[key(namespace, pool)]
+	if !ok {
+		return 0, 0
+	}
+	for _, e := range events.resumes {
+		if e.time.Before(since) {
+			continue
+		}
+		total++
+		if e.latency > target {
+			bad++
+		}
+	}
+	return total, bad
+}
+
+// Forget drops the events of a deleted pool
+func (t *Tracker) Forget(namespace, pool string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.pools, key(namespace, pool))
+}
+
+func (t *Tracker) pool(namespace, pool string) *poolEvents {
+	events, ok := t.pools[key(namespace, pool)]
+	if !ok {
+		events = &poolEvents{}
+		t.pools[key(namespace, pool)] = events
+	}
+	return events
+}
+
+func key(namespace, pool string) string {
+	return namespace + "/" + pool
+}
+
+// trim drops the events beyond the Retention or maxEvents
+func trim[T any](events []T, timeOf func(T) time.Time, now time.Time) []T {
+	start := max(len(events)-maxEvents, 0)
+	for start < len(events) && now.Sub(timeOf(events[start])) > Retention {
+		start++
+	}
+	return events[start:]
+}
+
+// BurnRate returns the rate at which the error budget of the objective, a percentage like "99.5", is consumed by the
+// bad events out of the total, i.e. the ratio of bad events to the ratio allowed by the objective. Any bad event
+// burns the empty budget of a 100% objective at an infinite rate.
+func BurnRate(objective string, total, bad int) (float64, error) {
+	percent, err := strconv.ParseFloat(objective, 64)
+	if err != nil {
+		return 0, err
+	}
+	if total == 0 || bad == 0 {
+		return 0, nil
+	}
+	budget := 1 - percent/100
+	badRatio := float64(bad) / float64(total)
+	if budget <= 0 {
+		return math.Inf(1), nil
+	}
+	return badRatio / budget, nil
+}
@@ -0,0 +1,142 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package slo
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTracker(t *testing.T) {
+	now := time.Now()
+	tracker := NewTracker()
+	tracker.ObserveClaim("default", "pool", time.Second, true, now.Add(-2*time.Hour))
+	tracker.ObserveClaim("default", "pool", time.Second, true, now.Add(-time.Minute))
+	tracker.ObserveClaim("default", "pool", 10*time.Second, true, now.Add(-time.Minute))
+	tracker.ObserveClaim("default", "pool", time.Second, false, now)
+	tracker.ObserveClaim("default", "other", time.Minute, true, now)
+	tracker.ObserveWarmup("default", "pool", true, now.Add(-time.Minute))
+	tracker.ObserveWarmup("default", "pool", false, now)
+	tracker.ObserveResume("default", "pool", 2*time.Second, now.Add(-2*time.Hour))
+	tracker.ObserveResume("default", "pool", 2*time.Second, now.Add(-time.Minute))
+	tracker.ObserveResume("default", "pool", 8*time.Second, now)
+
+	tests := []struct {
+		name        string
+		count       func() (int, int)
+		expectTotal int
+		expectBad   int
+	}{
+		{
+			name: "claims in window",
+			count: func() (int, int) {
+				return tracker.ClaimLatency("default", "pool", 5*time.Second, now.Add(-time.Hour))
+			},
+			expectTotal: 3,
+			expectBad:   2,
+		},
+		{
+			name: "claims in longer window",
+			count: func() (int, int) {
+				return tracker.ClaimLatency("default", "pool", 5*time.Second, now.Add(-3*time.Hour))
+			},
+			expectTotal: 4,
+			expectBad:   2,
+		},
+		{
+			name: "warmups in window",
+			count: func() (int, int) {
+				return tracker.WarmupSuccess("default", "pool", now.Add(-time.Hour))
+			},
+			expectTotal: 2,
+			expectBad:   1,
+		},
+		{
+			name: "resumes in window",
+			count: func() (int, int) {
+				return tracker.ResumeLatency("default", "pool", 5*time.Second, now.Add(-time.Hour))
+			},
+			expectTotal: 2,
+			expectBad:   1,
+		},
+		{
+			name: "unknown pool",
+			count: func() (int, int) {
+				return tracker.WarmupSuccess("default", "unknown", now.Add(-time.Hour))
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			total, bad := tt.count()
+			assert.Equal(t, tt.expectTotal, total)
+			assert.Equal(t, tt.expectBad, bad)
+		})
+	}
+
+	tracker.Forget("default", "pool")
+	total, _ := tracker.ClaimLatency("default", "pool", time.Second, now.Add(-time.Hour))
+	assert.Zero(t, total)
+	total, _ = tracker.ClaimLatency("default", "other", time.Second, now.Add(-time.Hour))
+	assert.Equal(t, 1, total)
+}
+
+func TestTracker_Retention(t *testing.T) {
+	now := time.Now()
+	tracker := NewTracker()
+	tracker.ObserveWarmup("default", "pool", false, now.Add(-Retention-time.Minute))
+	for range maxEvents {
+		tracker.ObserveWarmup("default", "pool", true, now)
+	}
+	tracker.ObserveWarmup("default", "pool", false, now)
+	total, bad := tracker.WarmupSuccess("default", "pool", time.Time{})
+	assert.Equal(t, maxEvents, total)
+	assert.Equal(t, 1, bad)
+}
+
+func TestBurnRate(t *testing.T) {
+	tests := []struct {
+		name      string
+		objective string
+		total     int
+		bad       int
+		expect    float64
+		expectErr bool
+	}{
+		{name: "no events", objective: "99", expect: 0},
+		{name: "no bad events", objective: "99", total: 100, expect: 0},
+		{name: "within budget", objective: "99", total: 200, bad: 1, expect: 0.5},
+		{name: "budget exhausted", objective: "99.5", total: 100, bad: 2, expect: 4},
+		{name: "empty budget", objective: "100", total: 100, bad: 1, expect: math.Inf(1)},
+		{name: "invalid objective", objective: "high", total: 100, bad: 1, expectErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := BurnRate(tt.objective, tt.total, tt.bad)
+			if tt.expectErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.InDelta(t, tt.expect, got, 1e-9)
+		})
+	}
+}
@@ -0,0 +1,132 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package statusupdater writes the status of objects only when it semantically changes, so that controllers
+// recomputing the status on every reconcile do not churn etcd.
+package statusupdater
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Updater patches the status subresource of objects with the JSON merge patch from their last applied status to the
+// desired one. Ignored fields, e.g. heartbeats written by other components, neither trigger a patch nor are part of
+// one, so that they are never overwritten with the stale values the controller has seen.
+type Updater struct {
+	// IgnoredFields are the dotted json paths in the status of the ignored fields, e.g. "heartbeat" or
+	// "usage.lastSampleTime".
+	IgnoredFields []string
+}
+
+// New returns an Updater ignoring the fields.
+func New(ignoredFields ...string) *Updater {
+	return &Updater{IgnoredFields: ignoredFields}
+}
+
+// Diff returns the JSON merge patch of the status from last to desired, or nil if they are semantically equal, e.g.
+// nil and empty slices of fields omitted when empty are equal.
+func (u *Updater) Diff(last, desired any) ([]byte, error) {
+	lastFields, err := u.normalize(last)
+	if err != nil {
+		return nil, err
+	}
+	desiredFields, err := u.normalize(desired)
+	if err != nil {
+		return nil, err
+	}
+	patch := mergePatch(lastFields, desiredFields)
+	if len(patch) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(patch)
+}
+
+// Patch patches the status of the object to desired if it differs from last, the status currently applied to the
+// object. Only the namespace and name of the object are used, and the object is updated with the patched one.
+// Returns whether the status is patched.
+func (u *Updater) Patch(ctx context.Context, c client.Client, obj client.Object, last, desired any) (bool, error) {
+	diff, err := u.Diff(last, desired)
+	if err != nil || diff == nil {
+		return false, err
+	}
+	patch := append(append([]byte(`{"status":`), diff...), '}')
+	if err = c.Status().Patch(ctx, obj, client.RawPatch(types.MergePatchType, patch)); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// normalize converts the status to its json fields without the ignored ones.
+func (u *Updater) normalize(status any) (map[string]any, error) {
+	by, err := json.Marshal(status)
+	if err != nil {
+		return nil, err
+	}
+	fields := map[string]any{}
+	if err = json.Unmarshal(by, &fields); err != nil {
+		return nil, err
+	}
+	for _, path := range u.IgnoredFields {
+		removeField(fields, strings.Split(path, "."))
+	}
+	return fields, nil
+}
+
+func removeField(fields map[string]any, path []string) {
+	if len(path) == 1 {
+		delete(fields, path[0])
+		return
+	}
+	if nested, ok := fields[path[0]].(map[string]any); ok {
+		removeField(nested, path[1:])
+	}
+}
+
+// mergePatch returns the RFC 7386 merge patch from last to desired, in which objects are merged recursively while
+// lists and other values are replaced as a whole.
+func mergePatch(last, desired map[string]any) map[string]any {
+	patch := map[string]any{}
+	for key, value := range desired {
+		lastValue, ok := last[key]
+		if !ok {
+			patch[key] = value
+			continue
+		}
+		desiredObject, desiredIsObject := value.(map[string]any)
+		lastObject, lastIsObject := lastValue.(map[string]any)
+		if desiredIsObject && lastIsObject {
+			if nested := mergePatch(lastObject, desiredObject); len(nested) > 0 {
+				patch[key] = nested
+			}
+			continue
+		}
+		if !reflect.DeepEqual(lastValue, value) {
+			patch[key] = value
+		}
+	}
+	for key := range last {
+		if _, ok := desired[key]; !ok {
+			patch[key] = nil
+		}
+	}
+	return patch
+}
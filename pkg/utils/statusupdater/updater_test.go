@@ -0,0 +1,144 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package statusupdater
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+)
+
+func TestUpdater_Diff(t *testing.T) {
+	now := metav1.NewTime(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	later := metav1.NewTime(now.Add(time.Minute))
+	base := func() agentsv1alpha1.SandboxStatus {
+		return agentsv1alpha1.SandboxStatus{
+			Phase:      agentsv1alpha1.SandboxRunning,
+			Conditions: []metav1.Condition{{Type: "Ready", Status: metav1.ConditionTrue, LastTransitionTime: now}},
+			PodInfo:    agentsv1alpha1.PodInfo{PodIP: "1.2.3.4"},
+			Heartbeat:  &agentsv1alpha1.SandboxHeartbeat{LastHeartbeatTime: now},
+			Usage:      &agentsv1alpha1.SandboxUsage{CPU: resource.MustParse("100m"), LastSampleTime: now},
+		}
+	}
+	tests := []struct {
+		name        string
+		ignored     []string
+		modify      func(status *agentsv1alpha1.SandboxStatus)
+		expectPatch string
+	}{
+		{
+			name:   "unchanged",
+			modify: func(status *agentsv1alpha1.SandboxStatus) {},
+		},
+		{
+			name: "removed field is nulled",
+			modify: func(status *agentsv1alpha1.SandboxStatus) {
+				status.Conditions = nil
+			},
+			expectPatch: `{"conditions":null}`,
+		},
+		{
+			name: "changed nested field",
+			modify: func(status *agentsv1alpha1.SandboxStatus) {
+				status.PodInfo.PodIP = "5.6.7.8"
+			},
+			expectPatch: `{"podInfo":{"podIP":"5.6.7.8"}}`,
+		},
+		{
+			name: "changed condition replaces the list",
+			modify: func(status *agentsv1alpha1.SandboxStatus) {
+				status.Conditions[0].Status = metav1.ConditionFalse
+			},
+			expectPatch: `{"conditions":[{"type":"Ready","status":"False","lastTransitionTime":"2025-01-01T00:00:00Z","reason":"","message":""}]}`,
+		},
+		{
+			name:    "ignored fields do not trigger a patch",
+			ignored: []string{"heartbeat", "usage.lastSampleTime"},
+			modify: func(status *agentsv1alpha1.SandboxStatus) {
+				status.Heartbeat = &agentsv1alpha1.SandboxHeartbeat{LastHeartbeatTime: later}
+				status.Usage.LastSampleTime = later
+			},
+		},
+		{
+			name:    "ignored fields are not patched",
+			ignored: []string{"heartbeat", "usage.lastSampleTime"},
+			modify: func(status *agentsv1alpha1.SandboxStatus) {
+				status.Phase = agentsv1alpha1.SandboxPaused
+				status.Heartbeat = nil
+				status.Usage.CPU = resource.MustParse("0.2")
+				status.Usage.LastSampleTime = later
+			},
+			expectPatch: `{"phase":"Paused","usage":{"cpu":"200m"}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			last, desired := base(), base()
+			tt.modify(&desired)
+			patch, err := New(tt.ignored...).Diff(last, desired)
+			require.NoError(t, err)
+			if tt.expectPatch == "" {
+				assert.Nil(t, patch)
+				return
+			}
+			assert.JSONEq(t, tt.expectPatch, string(patch))
+		})
+	}
+}
+
+func TestUpdater_Patch(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, agentsv1alpha1.AddToScheme(scheme))
+	heartbeat := &agentsv1alpha1.SandboxHeartbeat{LastHeartbeatTime: metav1.NewTime(time.Now().Truncate(time.Second))}
+	box := &agentsv1alpha1.Sandbox{
+		ObjectMeta: metav1.ObjectMeta{Name: "sbx", Namespace: "default"},
+		Status: agentsv1alpha1.SandboxStatus{
+			Phase:     agentsv1alpha1.SandboxRunning,
+			Heartbeat: heartbeat,
+		},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(box).WithStatusSubresource(box).Build()
+	updater := New("heartbeat")
+
+	// the controller has seen no heartbeat yet
+	seen := box.Status.DeepCopy()
+	seen.Heartbeat = nil
+	desired := seen.DeepCopy()
+	patched, err := updater.Patch(context.TODO(), c, box.DeepCopy(), *seen, *desired)
+	require.NoError(t, err)
+	assert.False(t, patched)
+
+	desired.Phase = agentsv1alpha1.SandboxPaused
+	patched, err = updater.Patch(context.TODO(), c, box.DeepCopy(), *seen, *desired)
+	require.NoError(t, err)
+	assert.True(t, patched)
+	got := &agentsv1alpha1.Sandbox{}
+	require.NoError(t, c.Get(context.TODO(), client.ObjectKeyFromObject(box), got))
+	assert.Equal(t, agentsv1alpha1.SandboxPaused, got.Status.Phase)
+	assert.Equal(t, heartbeat.LastHeartbeatTime.Unix(), got.Status.Heartbeat.LastHeartbeatTime.Unix(),
+		"the heartbeat written by others should be kept")
+}
@@ -0,0 +1,175 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package templateutils resolves SandboxSets inheriting a base SandboxTemplate with a template overlay.
+package templateutils
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+)
+
+// ApplyOverlay strategic merges the overlay onto the base pod template, e.g. containers and env are merged by name.
+// Fields not set in the overlay are kept as the base.
+func ApplyOverlay(base, overlay *corev1.PodTemplateSpec) (*corev1.PodTemplateSpec, error) {
+	if base == nil {
+		base = &corev1.PodTemplateSpec{}
+	}
+	if overlay == nil {
+		return base.DeepCopy(), nil
+	}
+	baseJSON, err := json.Marshal(base)
+	if err != nil {
+		return nil, err
+	}
+	patch, err := overlayPatch(overlay)
+	if err != nil {
+		return nil, err
+	}
+	merged, err := strategicpatch.StrategicMergePatch(baseJSON, patch, corev1.PodTemplateSpec{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to apply template overlay: %w", err)
+	}
+	resolved := &corev1.PodTemplateSpec{}
+	if err = json.Unmarshal(merged, resolved); err != nil {
+		return nil, err
+	}
+	keepBaseOrder(resolved.Spec.Containers, base.Spec.Containers, containerName)
+	keepBaseOrder(resolved.Spec.InitContainers, base.Spec.InitContainers, containerName)
+	keepBaseOrder(resolved.Spec.Volumes, base.Spec.Volumes, func(v corev1.Volume) string { return v.Name })
+	for _, containers := range [][]corev1.Container{resolved.Spec.Containers, resolved.Spec.InitContainers} {
+		for i := range containers {
+			if baseContainer := findContainer(base, containers[i].Name); baseContainer != nil {
+				keepBaseOrder(containers[i].Env, baseContainer.Env, func(e corev1.EnvVar) string { return e.Name })
+			}
+		}
+	}
+	return resolved, nil
+}
+
+// keepBaseOrder moves the merged items back to their order in the base, followed by the items added by the overlay.
+// The strategic merge puts the items of the patch first, while the first container is the main one and env vars may
+// refer to the former ones.
+func keepBaseOrder[T any](merged, base []T, key func(T) string) {
+	rank := make(map[string]int, len(base))
+	for i, item := range base {
+		rank[key(item)] = i
+	}
+	sort.SliceStable(merged, func(i, j int) bool {
+		ri, iInBase := rank[key(merged[i])]
+		rj, jInBase := rank[key(merged[j])]
+		if iInBase && jInBase {
+			return ri < rj
+		}
+		return iInBase && !jInBase
+	})
+}
+
+func containerName(c corev1.Container) string {
+	return c.Name
+}
+
+func findContainer(template *corev1.PodTemplateSpec, name string) *corev1.Container {
+	for _, containers := range [][]corev1.Container{template.Spec.Containers, template.Spec.InitContainers} {
+		for i := range containers {
+			if containers[i].Name == name {
+				return &containers[i]
+			}
+		}
+	}
+	return nil
+}
+
+// overlayPatch encodes the overlay as a strategic merge patch. The nulls of fields not set in the typed overlay are
+// dropped, otherwise they would delete the fields of the base.
+func overlayPatch(overlay *corev1.PodTemplateSpec) ([]byte, error) {
+	by, err := json.Marshal(overlay)
+	if err != nil {
+		return nil, err
+	}
+	var patch map[string]any
+	if err = json.Unmarshal(by, &patch); err != nil {
+		return nil, err
+	}
+	return json.Marshal(dropNulls(patch))
+}
+
+func dropNulls(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, item := range v {
+			if item == nil {
+				delete(v, key)
+				continue
+			}
+			v[key] = dropNulls(item)
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = dropNulls(item)
+		}
+	}
+	return value
+}
+
+// ResolveSandboxSet returns a copy of the SandboxSet whose template is flattened from the SandboxTemplate referenced by
+// spec.templateRef and spec.templateOverlay, so that its sandboxes are created, validated and hashed with the resolved
+// template. PersistentContents, runtimes and volumeClaimTemplates of the SandboxTemplate are inherited unless set by
+// the SandboxSet. SandboxSets without spec.templateOverlay are returned as is.
+func ResolveSandboxSet(ctx context.Context, reader client.Reader, sbs *agentsv1alpha1.SandboxSet) (*agentsv1alpha1.SandboxSet, error) {
+	if sbs.Spec.TemplateOverlay == nil {
+		return sbs, nil
+	}
+	if sbs.Spec.TemplateRef == nil {
+		return nil, fmt.Errorf("templateOverlay requires templateRef")
+	}
+	base := &agentsv1alpha1.SandboxTemplate{}
+	if err := reader.Get(ctx, client.ObjectKey{Namespace: sbs.Namespace, Name: sbs.Spec.TemplateRef.Name}, base); err != nil {
+		return nil, fmt.Errorf("failed to get sandbox template %s: %w", sbs.Spec.TemplateRef.Name, err)
+	}
+	return FlattenSandboxSet(sbs, base)
+}
+
+// FlattenSandboxSet resolves the template of the SandboxSet with the base SandboxTemplate, see ResolveSandboxSet.
+func FlattenSandboxSet(sbs *agentsv1alpha1.SandboxSet, base *agentsv1alpha1.SandboxTemplate) (*agentsv1alpha1.SandboxSet, error) {
+	base = base.DeepCopy()
+	template, err := ApplyOverlay(base.Spec.Template, sbs.Spec.TemplateOverlay)
+	if err != nil {
+		return nil, err
+	}
+	resolved := sbs.DeepCopy()
+	resolved.Spec.TemplateRef = nil
+	resolved.Spec.TemplateOverlay = nil
+	resolved.Spec.Template = template
+	if len(resolved.Spec.PersistentContents) == 0 {
+		resolved.Spec.PersistentContents = base.Spec.PersistentContents
+	}
+	if len(resolved.Spec.Runtimes) == 0 {
+		resolved.Spec.Runtimes = base.Spec.Runtimes
+	}
+	if len(resolved.Spec.VolumeClaimTemplates) == 0 {
+		resolved.Spec.VolumeClaimTemplates = base.Spec.VolumeClaimTemplates
+	}
+	return resolved, nil
+}
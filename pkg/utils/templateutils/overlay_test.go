@@ -0,0 +1,174 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package templateutils
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+)
+
+func newBaseTemplate() *corev1.PodTemplateSpec {
+	return &corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "base"}},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{
+				Name:  "main",
+				Image: "main:v1",
+				Env: []corev1.EnvVar{
+					{Name: "A", Value: "a"},
+					{Name: "B", Value: "$(A)-b"},
+				},
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")},
+				},
+			}},
+		},
+	}
+}
+
+func TestApplyOverlay(t *testing.T) {
+	tests := []struct {
+		name     string
+		overlay  *corev1.PodTemplateSpec
+		expected func() *corev1.PodTemplateSpec
+	}{
+		{
+			name:     "no overlay",
+			expected: newBaseTemplate,
+		},
+		{
+			name: "extra env, resources and labels",
+			overlay: &corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"tier": "gpu"}},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{
+						Name: "main",
+						Env: []corev1.EnvVar{
+							{Name: "C", Value: "c"},
+							{Name: "B", Value: "overridden"},
+						},
+						Resources: corev1.ResourceRequirements{
+							Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("1Gi")},
+						},
+					}},
+				},
+			},
+			expected: func() *corev1.PodTemplateSpec {
+				template := newBaseTemplate()
+				template.Labels["tier"] = "gpu"
+				main := &template.Spec.Containers[0]
+				main.Env = []corev1.EnvVar{
+					{Name: "A", Value: "a"},
+					{Name: "B", Value: "overridden"},
+					{Name: "C", Value: "c"},
+				}
+				main.Resources.Limits = corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("1Gi")}
+				return template
+			},
+		},
+		{
+			name: "sidecar is added after the main container",
+			overlay: &corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: "sidecar", Image: "sidecar:v1"}},
+				},
+			},
+			expected: func() *corev1.PodTemplateSpec {
+				template := newBaseTemplate()
+				template.Spec.Containers = append(template.Spec.Containers, corev1.Container{Name: "sidecar", Image: "sidecar:v1"})
+				return template
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolved, err := ApplyOverlay(newBaseTemplate(), tt.overlay)
+			require.NoError(t, err)
+			expected := tt.expected()
+			assert.Equal(t, expected.Labels, resolved.Labels)
+			require.Len(t, resolved.Spec.Containers, len(expected.Spec.Containers))
+			for i := range expected.Spec.Containers {
+				assert.Equal(t, expected.Spec.Containers[i].Name, resolved.Spec.Containers[i].Name)
+				assert.Equal(t, expected.Spec.Containers[i].Image, resolved.Spec.Containers[i].Image)
+				assert.Equal(t, expected.Spec.Containers[i].Env, resolved.Spec.Containers[i].Env)
+				assert.True(t, expected.Spec.Containers[i].Resources.Requests.Cpu().Equal(*resolved.Spec.Containers[i].Resources.Requests.Cpu()))
+				assert.True(t, expected.Spec.Containers[i].Resources.Limits.Memory().Equal(*resolved.Spec.Containers[i].Resources.Limits.Memory()))
+			}
+		})
+	}
+}
+
+func TestResolveSandboxSet(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, agentsv1alpha1.AddToScheme(scheme))
+	base := &agentsv1alpha1.SandboxTemplate{
+		ObjectMeta: metav1.ObjectMeta{Name: "base", Namespace: "default"},
+		Spec: agentsv1alpha1.SandboxTemplateSpec{
+			Template:           newBaseTemplate(),
+			PersistentContents: []string{agentsv1alpha1.PersistentContentMemory},
+		},
+	}
+	reader := fake.NewClientBuilder().WithScheme(scheme).WithObjects(base).Build()
+	newSandboxSet := func(templateRef string, overlay *corev1.PodTemplateSpec) *agentsv1alpha1.SandboxSet {
+		sbs := &agentsv1alpha1.SandboxSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "pool", Namespace: "default"},
+			Spec:       agentsv1alpha1.SandboxSetSpec{TemplateOverlay: overlay},
+		}
+		if templateRef != "" {
+			sbs.Spec.TemplateRef = &agentsv1alpha1.SandboxTemplateRef{Name: templateRef}
+		}
+		return sbs
+	}
+	overlay := &corev1.PodTemplateSpec{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"pool": "overlay"}}}
+
+	t.Run("without overlay", func(t *testing.T) {
+		sbs := newSandboxSet("base", nil)
+		resolved, err := ResolveSandboxSet(context.Background(), reader, sbs)
+		require.NoError(t, err)
+		assert.Same(t, sbs, resolved)
+	})
+	t.Run("with overlay", func(t *testing.T) {
+		sbs := newSandboxSet("base", overlay)
+		resolved, err := ResolveSandboxSet(context.Background(), reader, sbs)
+		require.NoError(t, err)
+		assert.Nil(t, resolved.Spec.TemplateRef)
+		assert.Nil(t, resolved.Spec.TemplateOverlay)
+		require.NotNil(t, resolved.Spec.Template)
+		assert.Equal(t, map[string]string{"app": "base", "pool": "overlay"}, resolved.Spec.Template.Labels)
+		assert.Equal(t, "main", resolved.Spec.Template.Spec.Containers[0].Name)
+		assert.Equal(t, []string{agentsv1alpha1.PersistentContentMemory}, resolved.Spec.PersistentContents)
+		assert.NotNil(t, sbs.Spec.TemplateOverlay, "the SandboxSet should not be modified")
+	})
+	t.Run("base template not found", func(t *testing.T) {
+		_, err := ResolveSandboxSet(context.Background(), reader, newSandboxSet("not-exist", overlay))
+		assert.ErrorContains(t, err, "failed to get sandbox template not-exist")
+	})
+	t.Run("without templateRef", func(t *testing.T) {
+		_, err := ResolveSandboxSet(context.Background(), reader, newSandboxSet("", overlay))
+		assert.ErrorContains(t, err, "templateOverlay requires templateRef")
+	})
+}
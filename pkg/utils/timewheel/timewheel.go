@@ -0,0 +1,157 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package timewheel schedules the timers of objects, like the shutdown time of sandboxes, on a hashed timing wheel.
+//
+// Unlike requeueing objects after their timers with the delaying workqueue, a timer can be moved or canceled when the
+// object changes, so that removed or postponed timers cause no stale reconciles, and far timers of many objects stay
+// out of the workqueue.
+package timewheel
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Wheel fires the deadline of each key within a tick after it is due. Each key has at most one deadline, scheduling
+// a key again replaces it. Scheduling and canceling are O(1), and a tick only visits the keys of a slot.
+type Wheel[K comparable] struct {
+	tick time.Duration
+	fire func(K)
+
+	mu    sync.Mutex
+	slots []map[K]time.Time
+	// slotOf is the slot of each scheduled key
+	slotOf map[K]int
+	// last is when the wheel was advanced last, deadlines before it are fired by the next advance
+	last time.Time
+}
+
+// New returns a wheel of size slots ticking every tick, firing due keys with fire. A round of the wheel spans
+// size*tick, deadlines beyond it stay in their slots for more rounds. fire is called without holding the wheel, so
+// it may schedule keys again.
+func New[K comparable](tick time.Duration, size int, fire func(K)) *Wheel[K] {
+	slots := make([]map[K]time.Time, size)
+	for i := range slots {
+		slots[i] = make(map[K]time.Time)
+	}
+	return &Wheel[K]{
+		tick:   tick,
+		fire:   fire,
+		slots:  slots,
+		slotOf: make(map[K]int),
+		last:   time.Now(),
+	}
+}
+
+// Schedule sets the deadline of the key, replacing its previous one. Deadlines already due are fired on the next tick.
+func (w *Wheel[K]) Schedule(key K, deadline time.Time) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.cancel(key)
+	slotTime := deadline
+	if slotTime.Before(w.last) {
+		slotTime = w.last
+	}
+	slot := w.slotIndex(slotTime)
+	w.slots[slot][key] = deadline
+	w.slotOf[key] = slot
+}
+
+// Cancel removes the deadline of the key, if any.
+func (w *Wheel[K]) Cancel(key K) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.cancel(key)
+}
+
+func (w *Wheel[K]) cancel(key K) {
+	if slot, ok := w.slotOf[key]; ok {
+		delete(w.slots[slot], key)
+		delete(w.slotOf, key)
+	}
+}
+
+// Deadline returns the deadline of the key, false if none is scheduled.
+func (w *Wheel[K]) Deadline(key K) (time.Time, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	slot, ok := w.slotOf[key]
+	if !ok {
+		return time.Time{}, false
+	}
+	return w.slots[slot][key], true
+}
+
+// Len returns the number of scheduled keys.
+func (w *Wheel[K]) Len() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return len(w.slotOf)
+}
+
+// Start advances the wheel every tick until the context is done, as a Runnable of the manager. It needs the leader
+// election like the controllers it requeues objects for.
+func (w *Wheel[K]) Start(ctx context.Context) error {
+	ticker := time.NewTicker(w.tick)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case now := <-ticker.C:
+			w.advance(now)
+		}
+	}
+}
+
+// advance fires the keys due at now, visiting the slots of the ticks since the last advance, or the whole wheel if
+// it has been stalled for more than a round.
+func (w *Wheel[K]) advance(now time.Time) {
+	w.mu.Lock()
+	from, to := w.tickOf(w.last), w.tickOf(now)
+	if size := int64(len(w.slots)); to-from >= size {
+		from = to - size + 1
+	}
+	var due []K
+	for t := from; t <= to; t++ {
+		slot := int(t % int64(len(w.slots)))
+		for key, deadline := range w.slots[slot] {
+			if !deadline.After(now) {
+				due = append(due, key)
+				delete(w.slots[slot], key)
+				delete(w.slotOf, key)
+			}
+		}
+	}
+	if now.After(w.last) {
+		w.last = now
+	}
+	w.mu.Unlock()
+
+	for _, key := range due {
+		w.fire(key)
+	}
+}
+
+func (w *Wheel[K]) tickOf(t time.Time) int64 {
+	return t.UnixNano() / int64(w.tick)
+}
+
+func (w *Wheel[K]) slotIndex(t time.Time) int {
+	return int(w.tickOf(t) % int64(len(w.slots)))
+}
@@ -0,0 +1,150 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package timewheel
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWheel(t *testing.T) {
+	base := time.Unix(1700000000, 0)
+	type step struct {
+		schedule map[string]time.Duration
+		cancel   []string
+		advance  time.Duration
+		fired    []string
+	}
+
+	tests := []struct {
+		name    string
+		steps   []step
+		pending int
+	}{
+		{
+			name: "fire due keys only",
+			steps: []step{
+				{schedule: map[string]time.Duration{"a": time.Second, "b": 3 * time.Second}},
+				{advance: 500 * time.Millisecond},
+				{advance: time.Second, fired: []string{"a"}},
+				{advance: 3 * time.Second, fired: []string{"b"}},
+			},
+		},
+		{
+			name: "rescheduling replaces the deadline",
+			steps: []step{
+				{schedule: map[string]time.Duration{"a": time.Second}},
+				{schedule: map[string]time.Duration{"a": 5 * time.Second}},
+				{advance: 2 * time.Second},
+				{advance: 5 * time.Second, fired: []string{"a"}},
+			},
+		},
+		{
+			name: "canceled keys are not fired",
+			steps: []step{
+				{schedule: map[string]time.Duration{"a": time.Second, "b": time.Second}},
+				{cancel: []string{"a"}},
+				{advance: 2 * time.Second, fired: []string{"b"}},
+			},
+		},
+		{
+			name: "deadlines beyond a round wait for more rounds",
+			steps: []step{
+				{schedule: map[string]time.Duration{"a": 25 * time.Second}},
+				{advance: 10 * time.Second},
+				{advance: 20 * time.Second},
+				{advance: 25 * time.Second, fired: []string{"a"}},
+			},
+		},
+		{
+			name: "stalled wheel fires all due keys",
+			steps: []step{
+				{schedule: map[string]time.Duration{"a": time.Second, "b": 7 * time.Second, "c": time.Minute}},
+				{advance: 30 * time.Second, fired: []string{"a", "b"}},
+			},
+			pending: 1,
+		},
+		{
+			name: "overdue deadlines are fired on the next tick",
+			steps: []step{
+				{advance: 5 * time.Second},
+				{schedule: map[string]time.Duration{"a": time.Second}},
+				{advance: 5 * time.Second, fired: []string{"a"}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var fired []string
+			// a round of 10s
+			w := New(time.Second, 10, func(key string) { fired = append(fired, key) })
+			w.last = base
+			for i, s := range tt.steps {
+				fired = nil
+				for key, after := range s.schedule {
+					w.Schedule(key, base.Add(after))
+				}
+				for _, key := range s.cancel {
+					w.Cancel(key)
+				}
+				if s.advance > 0 {
+					w.advance(base.Add(s.advance))
+				}
+				assert.ElementsMatch(t, s.fired, fired, "step %d", i)
+			}
+			assert.Equal(t, tt.pending, w.Len())
+		})
+	}
+}
+
+func TestWheel_Deadline(t *testing.T) {
+	w := New(time.Second, 10, func(string) {})
+	_, ok := w.Deadline("a")
+	assert.False(t, ok)
+	deadline := time.Now().Add(time.Hour)
+	w.Schedule("a", deadline)
+	got, ok := w.Deadline("a")
+	assert.True(t, ok)
+	assert.Equal(t, deadline, got)
+}
+
+func TestWheel_Start(t *testing.T) {
+	var mu sync.Mutex
+	var fired []string
+	w := New(10*time.Millisecond, 16, func(key string) {
+		mu.Lock()
+		defer mu.Unlock()
+		fired = append(fired, key)
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- w.Start(ctx) }()
+	w.Schedule("a", time.Now().Add(30*time.Millisecond))
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(fired) == 1
+	}, time.Second, 5*time.Millisecond)
+	cancel()
+	assert.NoError(t, <-done)
+	assert.Equal(t, 0, w.Len())
+}
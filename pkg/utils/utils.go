@@ -21,34 +21,18 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
 	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+	"github.com/openkruise/agents/pkg/utils/conditions"
 )
 
+// SetSandboxCondition sets or updates a condition in the Sandbox status.
 func SetSandboxCondition(status *agentsv1alpha1.SandboxStatus, condition metav1.Condition) {
-	currentCond := GetSandboxCondition(status, condition.Type)
-	if currentCond != nil && currentCond.Status == condition.Status && currentCond.Reason == condition.Reason &&
-		currentCond.Message == condition.Message {
-		return
-	} else if currentCond == nil {
-		status.Conditions = append(status.Conditions, condition)
-		return
-	}
-	if currentCond.Status != condition.Status {
-		currentCond.LastTransitionTime = condition.LastTransitionTime
-	}
-	currentCond.Status = condition.Status
-	currentCond.Reason = condition.Reason
-	currentCond.Message = condition.Message
+	conditions.Set(&status.Conditions, condition)
 }
 
 func GetSandboxCondition(status *agentsv1alpha1.SandboxStatus, condType string) *metav1.Condition {
-	for i := range status.Conditions {
-		c := &status.Conditions[i]
-		if c.Type == condType {
-			return c
-		}
-	}
-	return nil
+	return conditions.Get(status.Conditions, condType)
 }
+
 func GetPodCondition(status *corev1.PodStatus, condType corev1.PodConditionType) *corev1.PodCondition {
 	for i := range status.Conditions {
 		c := &status.Conditions[i]
@@ -0,0 +1,141 @@
+// Package faultinjection degrades admission webhooks on purpose, for testing only. Once enabled by the command line
+// flag, the webhooks inject latency and errors into a fraction of their requests as configured by the ConfigMap named
+// ConfigMapName in the namespace of the webhook, so that e2e tests can verify controllers and clients retry or fall
+// back correctly while admission is degraded. Without the ConfigMap, requests are served as usual.
+package faultinjection
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"time"
+
+	"github.com/spf13/pflag"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+	"sigs.k8s.io/yaml"
+
+	"github.com/openkruise/agents/pkg/utils/webhookutils"
+)
+
+// ConfigMapName is the name of the ConfigMap holding the faults. Every key of the ConfigMap is the path of a webhook,
+// e.g. /validate-sandboxclaim-limits, or AllWebhooks, whose value is a YAML or JSON encoded Fault.
+const ConfigMapName = "webhook-fault-injection"
+
+// AllWebhooks is the key of the fault of the webhooks without a fault of their own.
+const AllWebhooks = "*"
+
+// Options configures the fault injection.
+type Options struct {
+	// Enabled wraps the webhooks to inject the faults of the ConfigMap. Never enable it in production.
+	Enabled bool
+}
+
+// DefaultOptions is set by the command line flags and used by the webhook server.
+var DefaultOptions = Options{}
+
+// AddFlags registers the flags of the options.
+func (o *Options) AddFlags(fs *pflag.FlagSet) {
+	fs.BoolVar(&o.Enabled, "webhook-fault-injection", o.Enabled,
+		"For testing only: inject latency and errors into admission requests as configured by the ConfigMap "+ConfigMapName+".")
+}
+
+// Fault is injected into a fraction of the requests of a webhook.
+type Fault struct {
+	// Rate is the fraction of requests to inject the fault into, from 0 to 1.
+	Rate float64 `json:"rate"`
+	// Latency delays the selected requests before they are served or failed.
+	Latency metav1.Duration `json:"latency,omitempty"`
+	// Code fails the selected requests with the HTTP status code, e.g. 500. Zero serves them after the latency.
+	Code int32 `json:"code,omitempty"`
+	// Message is the error message of the failed requests.
+	Message string `json:"message,omitempty"`
+}
+
+// Handler injects the faults of the ConfigMap into the requests of the webhook at Path.
+type Handler struct {
+	admission.Handler
+	Path string
+	// Reader reads the ConfigMap from the API server, so that faults take effect at once.
+	Reader client.Reader
+	// Rand returns a number in [0, 1) to select the requests, rand.Float64 if nil.
+	Rand func() float64
+}
+
+// Wrap returns the handler injecting the faults of the webhook at path into the requests of handler.
+func Wrap(path string, handler admission.Handler, reader client.Reader) *Handler {
+	return &Handler{Handler: handler, Path: path, Reader: reader}
+}
+
+func (h *Handler) Handle(ctx context.Context, req admission.Request) admission.Response {
+	fault, err := h.fault(ctx)
+	if err != nil {
+		// faults are best effort, a broken configuration must not fail the webhook by itself
+		klog.ErrorS(err, "failed to get the webhook fault", "path", h.Path)
+		return h.Handler.Handle(ctx, req)
+	}
+	if fault == nil || !h.selected(fault.Rate) {
+		return h.Handler.Handle(ctx, req)
+	}
+	klog.InfoS("Injecting webhook fault", "path", h.Path, "latency", fault.Latency.Duration, "code", fault.Code,
+		"object", klog.KRef(req.Namespace, req.Name))
+	if fault.Latency.Duration > 0 {
+		timer := time.NewTimer(fault.Latency.Duration)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return admission.Errored(http.StatusInternalServerError, ctx.Err())
+		}
+	}
+	if fault.Code != 0 {
+		message := fault.Message
+		if message == "" {
+			message = "injected webhook fault"
+		}
+		return admission.Errored(fault.Code, errors.New(message))
+	}
+	return h.Handler.Handle(ctx, req)
+}
+
+// fault returns the fault of the webhook, nil if none is configured.
+func (h *Handler) fault(ctx context.Context) (*Fault, error) {
+	cm := &corev1.ConfigMap{}
+	err := h.Reader.Get(ctx, types.NamespacedName{Namespace: webhookutils.GetNamespace(), Name: ConfigMapName}, cm)
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fault injection configmap: %w", err)
+	}
+	value, ok := cm.Data[h.Path]
+	if !ok {
+		value, ok = cm.Data[AllWebhooks]
+	}
+	if !ok {
+		return nil, nil
+	}
+	fault := &Fault{}
+	if err = yaml.Unmarshal([]byte(value), fault); err != nil {
+		return nil, fmt.Errorf("failed to parse the fault of %s: %w", h.Path, err)
+	}
+	return fault, nil
+}
+
+func (h *Handler) selected(rate float64) bool {
+	if rate <= 0 {
+		return false
+	}
+	random := h.Rand
+	if random == nil {
+		random = rand.Float64
+	}
+	return random() < rate
+}
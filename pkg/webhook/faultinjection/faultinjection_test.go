@@ -0,0 +1,130 @@
+package faultinjection
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/openkruise/agents/pkg/utils/webhookutils"
+)
+
+type countingHandler struct {
+	calls int
+}
+
+func (h *countingHandler) Handle(context.Context, admission.Request) admission.Response {
+	h.calls++
+	return admission.Allowed("")
+}
+
+func TestHandler_Handle(t *testing.T) {
+	const path = "/validate-sandboxclaim-limits"
+	newConfigMap := func(data map[string]string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: ConfigMapName, Namespace: webhookutils.GetNamespace()},
+			Data:       data,
+		}
+	}
+
+	tests := []struct {
+		name          string
+		configMap     *corev1.ConfigMap
+		random        float64
+		expectAllowed bool
+		expectCode    int32
+		expectMessage string
+		expectCalls   int
+		expectLatency time.Duration
+	}{
+		{
+			name:          "no configmap",
+			expectAllowed: true,
+			expectCalls:   1,
+		},
+		{
+			name:          "no fault of the webhook",
+			configMap:     newConfigMap(map[string]string{"/validate-sandboxset": "rate: 1\ncode: 500"}),
+			expectAllowed: true,
+			expectCalls:   1,
+		},
+		{
+			name:          "error injected",
+			configMap:     newConfigMap(map[string]string{path: "rate: 1\ncode: 503\nmessage: degraded"}),
+			expectCode:    http.StatusServiceUnavailable,
+			expectMessage: "degraded",
+		},
+		{
+			name:          "error of all webhooks injected",
+			configMap:     newConfigMap(map[string]string{AllWebhooks: "rate: 0.5\ncode: 500"}),
+			random:        0.2,
+			expectCode:    http.StatusInternalServerError,
+			expectMessage: "injected webhook fault",
+		},
+		{
+			name:          "request not selected",
+			configMap:     newConfigMap(map[string]string{AllWebhooks: "rate: 0.5\ncode: 500"}),
+			random:        0.7,
+			expectAllowed: true,
+			expectCalls:   1,
+		},
+		{
+			name:          "latency injected",
+			configMap:     newConfigMap(map[string]string{path: `{"rate": 1, "latency": "50ms"}`}),
+			expectAllowed: true,
+			expectCalls:   1,
+			expectLatency: 50 * time.Millisecond,
+		},
+		{
+			name:          "invalid fault ignored",
+			configMap:     newConfigMap(map[string]string{path: "rate: [1]"}),
+			expectAllowed: true,
+			expectCalls:   1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var objects []client.Object
+			if tt.configMap != nil {
+				objects = append(objects, tt.configMap)
+			}
+			inner := &countingHandler{}
+			handler := Wrap(path, inner, fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(objects...).Build())
+			handler.Rand = func() float64 { return tt.random }
+
+			start := time.Now()
+			response := handler.Handle(context.TODO(), admission.Request{})
+			assert.GreaterOrEqual(t, time.Since(start), tt.expectLatency)
+			assert.Equal(t, tt.expectAllowed, response.Allowed)
+			assert.Equal(t, tt.expectCalls, inner.calls)
+			if tt.expectCode != 0 {
+				assert.Equal(t, tt.expectCode, response.Result.Code)
+				assert.Equal(t, tt.expectMessage, response.Result.Message)
+			}
+		})
+	}
+}
+
+func TestHandler_Handle_ContextCanceled(t *testing.T) {
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: ConfigMapName, Namespace: webhookutils.GetNamespace()},
+		Data:       map[string]string{AllWebhooks: "rate: 1\nlatency: 1h"},
+	}
+	inner := &countingHandler{}
+	handler := Wrap("/validate-sandboxset", inner, fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(configMap).Build())
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	response := handler.Handle(ctx, admission.Request{})
+	assert.False(t, response.Allowed)
+	assert.Equal(t, int32(http.StatusInternalServerError), response.Result.Code)
+	assert.Equal(t, 0, inner.calls)
+}
@@ -0,0 +1,93 @@
+package mutating
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+	"github.com/openkruise/agents/pkg/features"
+	utilfeature "github.com/openkruise/agents/pkg/utils/feature"
+)
+
+const (
+	// DefaultClaimTimeout is the claimTimeout of claims omitting it, unless adapted to the observed claim latencies
+	DefaultClaimTimeout = time.Minute
+
+	// adaptiveClaimTimeoutFactor is the headroom of the adaptive claimTimeout over the p99 claim latency
+	adaptiveClaimTimeoutFactor = 2
+	minAdaptiveClaimTimeout    = 10 * time.Second
+	maxAdaptiveClaimTimeout    = 10 * time.Minute
+)
+
+// LatencyReader reads the percentiles of the claim latencies observed for the templates
+type LatencyReader interface {
+	Percentile(namespace, template string, p int) (time.Duration, bool)
+}
+
+// SandboxClaimDefaulter defaults the claimTimeout of new SandboxClaims. With the SandboxClaimAdaptiveTimeout feature
+// gate, it is adapted to the p99 latency of the recent claims of the template, which are only observed by the leader,
+// so that the other replicas fall back to DefaultClaimTimeout.
+type SandboxClaimDefaulter struct {
+	Decoder   admission.Decoder
+	Latencies LatencyReader
+}
+
+// +kubebuilder:webhook:path=/default-sandboxclaim,mutating=true,failurePolicy=fail,sideEffects=None,admissionReviewVersions=v1;v1beta1,groups=agents.kruise.io,resources=sandboxclaims,verbs=create,versions=v1alpha1,name=md-sbc.kb.io
+
+func (h *SandboxClaimDefaulter) Path() string {
+	return "/default-sandboxclaim"
+}
+
+func (h *SandboxClaimDefaulter) Enabled() bool {
+	return true
+}
+
+func (h *SandboxClaimDefaulter) Handle(_ context.Context, req admission.Request) admission.Response {
+	claim := &agentsv1alpha1.SandboxClaim{}
+	if err := h.Decoder.Decode(req, claim); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	if !h.SetDefaults(claim) {
+		return admission.Allowed("")
+	}
+	marshal, err := json.Marshal(claim)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	return admission.PatchResponseFromRaw(req.Object.Raw, marshal)
+}
+
+// SetDefaults defaults the claimTimeout of the new SandboxClaim and returns whether it is changed. It is shared with
+// the controller defaulting SandboxClaims in the standalone mode.
+func (h *SandboxClaimDefaulter) SetDefaults(claim *agentsv1alpha1.SandboxClaim) bool {
+	if claim.Spec.ClaimTimeout != nil {
+		return false
+	}
+	timeout := h.defaultClaimTimeout(claim.Namespace, claim.Spec.TemplateName)
+	klog.V(4).InfoS("Defaulting claimTimeout of SandboxClaim", "claim", klog.KObj(claim), "timeout", timeout)
+	claim.Spec.ClaimTimeout = &metav1.Duration{Duration: timeout}
+	return true
+}
+
+// defaultClaimTimeout returns twice the p99 latency of the recent claims of the template, rounded up to seconds and
+// bounded, if the adaptive claimTimeout is enabled and enough claims are observed, otherwise DefaultClaimTimeout.
+func (h *SandboxClaimDefaulter) defaultClaimTimeout(namespace, template string) time.Duration {
+	if !utilfeature.DefaultFeatureGate.Enabled(features.SandboxClaimAdaptiveTimeoutGate) || h.Latencies == nil {
+		return DefaultClaimTimeout
+	}
+	p99, ok := h.Latencies.Percentile(namespace, template, 99)
+	if !ok {
+		return DefaultClaimTimeout
+	}
+	timeout := (p99 * adaptiveClaimTimeoutFactor).Truncate(time.Second)
+	if timeout < p99*adaptiveClaimTimeoutFactor {
+		timeout += time.Second
+	}
+	return min(max(timeout, minAdaptiveClaimTimeout), maxAdaptiveClaimTimeout)
+}
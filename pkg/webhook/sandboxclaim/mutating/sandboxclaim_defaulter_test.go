@@ -0,0 +1,119 @@
+package mutating
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+	"github.com/openkruise/agents/pkg/features"
+	utilfeature "github.com/openkruise/agents/pkg/utils/feature"
+)
+
+type fakeLatencies map[string]time.Duration
+
+func (f fakeLatencies) Percentile(namespace, template string, _ int) (time.Duration, bool) {
+	latency, ok := f[namespace+"/"+template]
+	return latency, ok
+}
+
+func TestSandboxClaimDefaulter_Handle(t *testing.T) {
+	require.NoError(t, agentsv1alpha1.AddToScheme(scheme.Scheme))
+	latencies := fakeLatencies{
+		"default/fast":   2 * time.Second,
+		"default/normal": 20*time.Second + 300*time.Millisecond,
+		"default/slow":   time.Hour,
+	}
+
+	tests := []struct {
+		name          string
+		adaptive      bool
+		template      string
+		claimTimeout  *metav1.Duration
+		expectTimeout *time.Duration
+	}{
+		{
+			name:         "claimTimeout set",
+			adaptive:     true,
+			template:     "normal",
+			claimTimeout: &metav1.Duration{Duration: 5 * time.Minute},
+		},
+		{
+			name:          "static default",
+			template:      "normal",
+			expectTimeout: ptrTo(DefaultClaimTimeout),
+		},
+		{
+			name:          "adaptive without observed latencies",
+			adaptive:      true,
+			template:      "unknown",
+			expectTimeout: ptrTo(DefaultClaimTimeout),
+		},
+		{
+			name:          "adaptive from p99 rounded up",
+			adaptive:      true,
+			template:      "normal",
+			expectTimeout: ptrTo(41 * time.Second),
+		},
+		{
+			name:          "adaptive bounded below",
+			adaptive:      true,
+			template:      "fast",
+			expectTimeout: ptrTo(minAdaptiveClaimTimeout),
+		},
+		{
+			name:          "adaptive bounded above",
+			adaptive:      true,
+			template:      "slow",
+			expectTimeout: ptrTo(maxAdaptiveClaimTimeout),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, utilfeature.DefaultMutableFeatureGate.Set(
+				fmt.Sprintf("%s=%t", features.SandboxClaimAdaptiveTimeoutGate, tt.adaptive)))
+			defer func() {
+				_ = utilfeature.DefaultMutableFeatureGate.Set(fmt.Sprintf("%s=false", features.SandboxClaimAdaptiveTimeoutGate))
+			}()
+			handler := &SandboxClaimDefaulter{
+				Decoder:   admission.NewDecoder(scheme.Scheme),
+				Latencies: latencies,
+			}
+			raw, err := json.Marshal(&agentsv1alpha1.SandboxClaim{
+				ObjectMeta: metav1.ObjectMeta{Name: "claim", Namespace: "default"},
+				Spec:       agentsv1alpha1.SandboxClaimSpec{TemplateName: tt.template, ClaimTimeout: tt.claimTimeout},
+			})
+			require.NoError(t, err)
+			response := handler.Handle(context.TODO(), admission.Request{
+				AdmissionRequest: admissionv1.AdmissionRequest{
+					Operation: admissionv1.Create,
+					Namespace: "default",
+					Name:      "claim",
+					Object:    runtime.RawExtension{Raw: raw},
+				},
+			})
+			require.True(t, response.Allowed, response.Result)
+			if tt.expectTimeout == nil {
+				assert.Empty(t, response.Patches)
+				return
+			}
+			require.Len(t, response.Patches, 1)
+			assert.Equal(t, "/spec/claimTimeout", response.Patches[0].Path)
+			assert.Equal(t, tt.expectTimeout.String(), response.Patches[0].Value)
+		})
+	}
+}
+
+func ptrTo(d time.Duration) *time.Duration {
+	return &d
+}
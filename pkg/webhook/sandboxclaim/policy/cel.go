@@ -0,0 +1,131 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/ext"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// celCostLimit bounds the cost of evaluating a single policy, so that a bad expression cannot stall admission.
+const celCostLimit = 1000000
+
+// Policy is a CEL policy, the claim is denied if Expression evaluates to false.
+// Expressions can refer to the variables:
+//
+//	object:  the SandboxClaim
+//	request: the admission request, see NewInput
+//	now:     the current time as a timestamp, e.g. now.getHours("Asia/Shanghai") < 18
+type Policy struct {
+	Expression string `json:"expression"`
+	// Message is returned to the user if the claim is denied by the policy.
+	Message string `json:"message,omitempty"`
+}
+
+type compiledPolicy struct {
+	name    string
+	message string
+	program cel.Program
+}
+
+// CELEvaluator evaluates the CEL policies of the policy ConfigMap. Compiled policies are reused until the
+// ConfigMap changes.
+type CELEvaluator struct {
+	env *cel.Env
+
+	mu       sync.Mutex
+	key      string
+	policies []compiledPolicy
+	err      error
+}
+
+func NewCELEvaluator() (*CELEvaluator, error) {
+	env, err := cel.NewEnv(
+		cel.Variable("object", cel.DynType),
+		cel.Variable("request", cel.DynType),
+		cel.Variable("now", cel.TimestampType),
+		ext.Strings(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &CELEvaluator{env: env}, nil
+}
+
+// Evaluate evaluates the policies of the ConfigMap in the order of their names, and denies the claim on the first
+// violated policy. An invalid policy fails the evaluation, so that a broken ConfigMap never lets claims through.
+func (e *CELEvaluator) Evaluate(ctx context.Context, cm *corev1.ConfigMap, input map[string]any, now time.Time) (Decision, error) {
+	policies, err := e.compile(cm)
+	if err != nil {
+		return Decision{}, err
+	}
+	activation := map[string]any{
+		"object":  input["object"],
+		"request": input["request"],
+		"now":     now,
+	}
+	for _, policy := range policies {
+		out, _, err := policy.program.ContextEval(ctx, activation)
+		if err != nil {
+			return Decision{}, fmt.Errorf("failed to evaluate policy %s: %w", policy.name, err)
+		}
+		allowed, ok := out.Value().(bool)
+		if !ok {
+			return Decision{}, fmt.Errorf("policy %s evaluated to %v instead of bool", policy.name, out.Value())
+		}
+		if !allowed {
+			message := policy.message
+			if message == "" {
+				message = fmt.Sprintf("denied by policy %s", policy.name)
+			}
+			return Decision{Message: message}, nil
+		}
+	}
+	return Allow, nil
+}
+
+func (e *CELEvaluator) compile(cm *corev1.ConfigMap) ([]compiledPolicy, error) {
+	key := string(cm.UID) + "/" + cm.ResourceVersion
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if key == e.key {
+		return e.policies, e.err
+	}
+	e.key = key
+	e.policies, e.err = e.compilePolicies(cm.Data)
+	return e.policies, e.err
+}
+
+func (e *CELEvaluator) compilePolicies(data map[string]string) ([]compiledPolicy, error) {
+	names := make([]string, 0, len(data))
+	for name := range data {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	policies := make([]compiledPolicy, 0, len(names))
+	for _, name := range names {
+		policy := Policy{}
+		if err := yaml.Unmarshal([]byte(data[name]), &policy); err != nil {
+			return nil, fmt.Errorf("failed to parse policy %s: %w", name, err)
+		}
+		ast, issues := e.env.Compile(policy.Expression)
+		if issues.Err() != nil {
+			return nil, fmt.Errorf("failed to compile policy %s: %w", name, issues.Err())
+		}
+		if ast.OutputType() != cel.BoolType && ast.OutputType() != cel.DynType {
+			return nil, fmt.Errorf("policy %s must evaluate to bool, got %s", name, ast.OutputType())
+		}
+		program, err := e.env.Program(ast, cel.CostLimit(celCostLimit), cel.InterruptCheckFrequency(100))
+		if err != nil {
+			return nil, fmt.Errorf("failed to build policy %s: %w", name, err)
+		}
+		policies = append(policies, compiledPolicy{name: name, message: policy.Message, program: program})
+	}
+	return policies, nil
+}
@@ -0,0 +1,80 @@
+package policy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// OPAClient queries a decision of OPA with the Data API, see https://www.openpolicyagent.org/docs/rest-api.
+// The policy input is posted as the input document, and the decision must be either a bool or an object like
+// {"allowed": false, "message": "..."}.
+type OPAClient struct {
+	URL    string
+	Client *http.Client
+}
+
+func NewOPAClient(opts Options) *OPAClient {
+	return &OPAClient{
+		URL:    opts.OPAURL,
+		Client: &http.Client{Timeout: opts.OPATimeout},
+	}
+}
+
+type opaResponse struct {
+	Result json.RawMessage `json:"result"`
+}
+
+type opaDecision struct {
+	Allowed bool   `json:"allowed"`
+	Message string `json:"message"`
+}
+
+func (c *OPAClient) Evaluate(ctx context.Context, input map[string]any) (Decision, error) {
+	body, err := json.Marshal(map[string]any{"input": input})
+	if err != nil {
+		return Decision{}, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.URL, bytes.NewReader(body))
+	if err != nil {
+		return Decision{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.Client.Do(req)
+	if err != nil {
+		return Decision{}, fmt.Errorf("failed to query opa: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return Decision{}, fmt.Errorf("opa responded %d: %s", resp.StatusCode, msg)
+	}
+	response := opaResponse{}
+	if err = json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return Decision{}, fmt.Errorf("failed to parse opa response: %w", err)
+	}
+	if len(response.Result) == 0 {
+		// OPA omits the result if the decision is undefined, e.g. the policy is not loaded
+		return Decision{}, fmt.Errorf("opa decision is undefined")
+	}
+	var allowed bool
+	if err = json.Unmarshal(response.Result, &allowed); err == nil {
+		if !allowed {
+			return Decision{Message: "denied by opa"}, nil
+		}
+		return Allow, nil
+	}
+	decision := opaDecision{}
+	if err = json.Unmarshal(response.Result, &decision); err != nil {
+		return Decision{}, fmt.Errorf("unsupported opa decision %s", response.Result)
+	}
+	if !decision.Allowed && decision.Message == "" {
+		decision.Message = "denied by opa"
+	}
+	return Decision{Allowed: decision.Allowed, Message: decision.Message}, nil
+}
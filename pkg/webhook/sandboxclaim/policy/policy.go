@@ -0,0 +1,79 @@
+// Package policy evaluates organization-specific admission policies of SandboxClaims, so that rules like image
+// allowlists or working-hour restrictions can be changed without rebuilding the webhook. Policies are either CEL
+// expressions stored in the ConfigMap named ConfigMapName in the namespace of the webhook, or decided by an
+// external OPA endpoint, or both.
+package policy
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/pflag"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+)
+
+// ConfigMapName is the name of the ConfigMap holding the CEL policies. Every key of the ConfigMap is a policy,
+// whose value is a YAML or JSON encoded Policy.
+const ConfigMapName = "sandbox-claim-policy"
+
+// Options configures the external policy engine.
+type Options struct {
+	// OPAURL is the URL of the OPA decision to query, e.g. http://opa:8181/v1/data/agents/claim/allow.
+	// The OPA endpoint is not queried if empty.
+	OPAURL string
+	// OPATimeout is the timeout of a query to OPA.
+	OPATimeout time.Duration
+}
+
+// DefaultOptions is set by the command line flags and used by the policy webhook.
+var DefaultOptions = Options{
+	OPATimeout: 3 * time.Second,
+}
+
+// AddFlags registers the flags of the options.
+func (o *Options) AddFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&o.OPAURL, "claim-policy-opa-url", o.OPAURL,
+		"URL of the OPA decision queried on SandboxClaim admission, e.g. http://opa:8181/v1/data/agents/claim/allow. Empty disables it.")
+	fs.DurationVar(&o.OPATimeout, "claim-policy-opa-timeout", o.OPATimeout, "Timeout of a query to OPA.")
+}
+
+// Decision is the result of evaluating the policies.
+type Decision struct {
+	Allowed bool
+	// Message explains why the claim is denied.
+	Message string
+}
+
+// Allow is the decision of claims not violating any policy.
+var Allow = Decision{Allowed: true}
+
+// NewInput builds the document the policies are evaluated against:
+//
+//	object:  the SandboxClaim
+//	request: operation, namespace, name and userInfo (username, uid, groups) of the admission request
+func NewInput(req admission.Request, claim *agentsv1alpha1.SandboxClaim) (map[string]any, error) {
+	object, err := runtime.DefaultUnstructuredConverter.ToUnstructured(claim)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert sandboxclaim: %w", err)
+	}
+	groups := make([]any, 0, len(req.UserInfo.Groups))
+	for _, group := range req.UserInfo.Groups {
+		groups = append(groups, group)
+	}
+	return map[string]any{
+		"object": object,
+		"request": map[string]any{
+			"operation": string(req.Operation),
+			"namespace": req.Namespace,
+			"name":      req.Name,
+			"userInfo": map[string]any{
+				"username": req.UserInfo.Username,
+				"uid":      req.UserInfo.UID,
+				"groups":   groups,
+			},
+		},
+	}, nil
+}
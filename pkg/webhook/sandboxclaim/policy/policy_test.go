@@ -0,0 +1,234 @@
+package policy
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+)
+
+func newTestInput(t *testing.T) map[string]any {
+	t.Helper()
+	input, err := NewInput(admission.Request{
+		AdmissionRequest: admissionv1.AdmissionRequest{
+			Operation: admissionv1.Create,
+			Namespace: "default",
+			Name:      "claim",
+			UserInfo: authenticationv1.UserInfo{
+				Username: "alice",
+				Groups:   []string{"dev"},
+			},
+		},
+	}, &agentsv1alpha1.SandboxClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "claim", Namespace: "default"},
+		Spec: agentsv1alpha1.SandboxClaimSpec{
+			TemplateName: "python",
+			InplaceUpdate: &agentsv1alpha1.SandboxClaimInplaceUpdateOptions{
+				Image: "registry.example.com/python:3.12",
+			},
+		},
+	})
+	require.NoError(t, err)
+	return input
+}
+
+func TestCELEvaluator_Evaluate(t *testing.T) {
+	// 10:00 UTC on a Wednesday
+	now := time.Date(2026, 1, 7, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name          string
+		data          map[string]string
+		expectAllowed bool
+		expectMessage string
+		expectError   string
+	}{
+		{
+			name:          "no policies",
+			expectAllowed: true,
+		},
+		{
+			name: "image allowlist allowed",
+			data: map[string]string{
+				"image-allowlist": `expression: '!has(object.spec.inplaceUpdate) || object.spec.inplaceUpdate.image.startsWith("registry.example.com/")'`,
+			},
+			expectAllowed: true,
+		},
+		{
+			name: "working hours denied",
+			data: map[string]string{
+				"image-allowlist": `{"expression": "object.spec.templateName == 'python'"}`,
+				"working-hours": `
+expression: now.getHours() >= 12 || "oncall" in request.userInfo.groups
+message: sandboxes can only be claimed in the afternoon`,
+			},
+			expectMessage: "sandboxes can only be claimed in the afternoon",
+		},
+		{
+			name: "default message",
+			data: map[string]string{
+				"users": `expression: request.userInfo.username != "alice"`,
+			},
+			expectMessage: "denied by policy users",
+		},
+		{
+			name: "invalid expression",
+			data: map[string]string{
+				"broken": `expression: object.spec.(`,
+			},
+			expectError: "failed to compile policy broken",
+		},
+		{
+			name: "not bool",
+			data: map[string]string{
+				"string": `expression: '"python"'`,
+			},
+			expectError: "policy string must evaluate to bool",
+		},
+		{
+			name: "evaluated to not bool",
+			data: map[string]string{
+				"template": `expression: object.spec.templateName`,
+			},
+			expectError: "policy template evaluated to python instead of bool",
+		},
+		{
+			name: "evaluation error",
+			data: map[string]string{
+				"missing": `expression: object.spec.notExist == "x"`,
+			},
+			expectError: "failed to evaluate policy missing",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			evaluator, err := NewCELEvaluator()
+			require.NoError(t, err)
+			cm := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: ConfigMapName, UID: "uid", ResourceVersion: "1"},
+				Data:       tt.data,
+			}
+			decision, err := evaluator.Evaluate(context.Background(), cm, newTestInput(t), now)
+			if tt.expectError != "" {
+				assert.ErrorContains(t, err, tt.expectError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectAllowed, decision.Allowed)
+			assert.Equal(t, tt.expectMessage, decision.Message)
+		})
+	}
+}
+
+func TestCELEvaluator_Recompile(t *testing.T) {
+	evaluator, err := NewCELEvaluator()
+	require.NoError(t, err)
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: ConfigMapName, UID: "uid", ResourceVersion: "1"},
+		Data:       map[string]string{"deny": `expression: "false"`},
+	}
+	decision, err := evaluator.Evaluate(context.Background(), cm, newTestInput(t), time.Now())
+	require.NoError(t, err)
+	assert.False(t, decision.Allowed)
+
+	// the compiled policies are reused until the resource version changes
+	cm.Data = map[string]string{"allow": `expression: "true"`}
+	decision, err = evaluator.Evaluate(context.Background(), cm, newTestInput(t), time.Now())
+	require.NoError(t, err)
+	assert.False(t, decision.Allowed)
+
+	cm.ResourceVersion = "2"
+	decision, err = evaluator.Evaluate(context.Background(), cm, newTestInput(t), time.Now())
+	require.NoError(t, err)
+	assert.True(t, decision.Allowed)
+}
+
+func TestOPAClient_Evaluate(t *testing.T) {
+	tests := []struct {
+		name          string
+		status        int
+		response      string
+		expectAllowed bool
+		expectMessage string
+		expectError   string
+	}{
+		{
+			name:          "bool allowed",
+			status:        http.StatusOK,
+			response:      `{"result": true}`,
+			expectAllowed: true,
+		},
+		{
+			name:          "bool denied",
+			status:        http.StatusOK,
+			response:      `{"result": false}`,
+			expectMessage: "denied by opa",
+		},
+		{
+			name:          "object denied",
+			status:        http.StatusOK,
+			response:      `{"result": {"allowed": false, "message": "image is not allowed"}}`,
+			expectMessage: "image is not allowed",
+		},
+		{
+			name:          "object allowed",
+			status:        http.StatusOK,
+			response:      `{"result": {"allowed": true}}`,
+			expectAllowed: true,
+		},
+		{
+			name:        "undefined",
+			status:      http.StatusOK,
+			response:    `{}`,
+			expectError: "opa decision is undefined",
+		},
+		{
+			name:        "unsupported",
+			status:      http.StatusOK,
+			response:    `{"result": "yes"}`,
+			expectError: "unsupported opa decision",
+		},
+		{
+			name:        "server error",
+			status:      http.StatusInternalServerError,
+			response:    `{"code": "internal_error"}`,
+			expectError: "opa responded 500",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodPost, r.Method)
+				body := map[string]any{}
+				assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+				input := body["input"].(map[string]any)
+				assert.Equal(t, "alice", input["request"].(map[string]any)["userInfo"].(map[string]any)["username"])
+				assert.Equal(t, "python", input["object"].(map[string]any)["spec"].(map[string]any)["templateName"])
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.response))
+			}))
+			defer server.Close()
+
+			client := NewOPAClient(Options{OPAURL: server.URL, OPATimeout: time.Second})
+			decision, err := client.Evaluate(context.Background(), newTestInput(t))
+			if tt.expectError != "" {
+				assert.ErrorContains(t, err, tt.expectError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectAllowed, decision.Allowed)
+			assert.Equal(t, tt.expectMessage, decision.Message)
+		})
+	}
+}
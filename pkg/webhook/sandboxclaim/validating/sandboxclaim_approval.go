@@ -0,0 +1,102 @@
+package validating
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+)
+
+// VerbApprove is the verb on sandboxclaims required to approve or reject a claim
+const VerbApprove = "approve"
+
+// SandboxClaimApprovalHandler makes sure that only approvers decide the approval of SandboxClaims,
+// and that the decision is final.
+type SandboxClaimApprovalHandler struct {
+	Client  client.Client
+	Decoder admission.Decoder
+}
+
+// +kubebuilder:webhook:path=/validate-sandboxclaim-approval,mutating=false,failurePolicy=fail,sideEffects=None,admissionReviewVersions=v1;v1beta1,groups=agents.kruise.io,resources=sandboxclaims,verbs=create;update,versions=v1alpha1,name=v-sbc-approval.kb.io
+// +kubebuilder:rbac:groups=authorization.k8s.io,resources=subjectaccessreviews,verbs=create
+
+func (h *SandboxClaimApprovalHandler) Path() string {
+	return "/validate-sandboxclaim-approval"
+}
+
+func (h *SandboxClaimApprovalHandler) Enabled() bool {
+	return true
+}
+
+func (h *SandboxClaimApprovalHandler) Handle(ctx context.Context, req admission.Request) admission.Response {
+	claim := &agentsv1alpha1.SandboxClaim{}
+	if err := h.Decoder.Decode(req, claim); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	decision, decided := claim.Annotations[agentsv1alpha1.AnnotationClaimApproval]
+	var oldDecision string
+	var oldDecided bool
+	if req.Operation == admissionv1.Update {
+		oldClaim := &agentsv1alpha1.SandboxClaim{}
+		if err := h.Decoder.DecodeRaw(req.OldObject, oldClaim); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		oldDecision, oldDecided = oldClaim.Annotations[agentsv1alpha1.AnnotationClaimApproval]
+	}
+	if decision == oldDecision && decided == oldDecided {
+		return admission.Allowed("")
+	}
+	if oldDecided {
+		return admission.Denied(fmt.Sprintf("annotation %s is immutable once set", agentsv1alpha1.AnnotationClaimApproval))
+	}
+	if decision != agentsv1alpha1.SandboxClaimApprovalApproved && decision != agentsv1alpha1.SandboxClaimApprovalRejected {
+		return admission.Denied(fmt.Sprintf("annotation %s must be %s or %s", agentsv1alpha1.AnnotationClaimApproval,
+			agentsv1alpha1.SandboxClaimApprovalApproved, agentsv1alpha1.SandboxClaimApprovalRejected))
+	}
+
+	allowed, reason, err := h.canApprove(ctx, req)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	if !allowed {
+		return admission.Denied(fmt.Sprintf("user %s is not allowed to %s sandboxclaim %s/%s: %s",
+			req.UserInfo.Username, VerbApprove, req.Namespace, req.Name, reason))
+	}
+	klog.InfoS("SandboxClaim approval decided", "claim", klog.KRef(req.Namespace, req.Name),
+		"decision", decision, "user", req.UserInfo.Username)
+	return admission.Allowed("")
+}
+
+// canApprove checks whether the requesting user is allowed to approve the claim with a SubjectAccessReview.
+func (h *SandboxClaimApprovalHandler) canApprove(ctx context.Context, req admission.Request) (bool, string, error) {
+	extra := make(map[string]authorizationv1.ExtraValue, len(req.UserInfo.Extra))
+	for k, v := range req.UserInfo.Extra {
+		extra[k] = authorizationv1.ExtraValue(v)
+	}
+	review := &authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			User:   req.UserInfo.Username,
+			UID:    req.UserInfo.UID,
+			Groups: req.UserInfo.Groups,
+			Extra:  extra,
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Namespace: req.Namespace,
+				Verb:      VerbApprove,
+				Group:     agentsv1alpha1.GroupVersion.Group,
+				Resource:  "sandboxclaims",
+				Name:      req.Name,
+			},
+		},
+	}
+	if err := h.Client.Create(ctx, review); err != nil {
+		return false, "", err
+	}
+	return review.Status.Allowed, review.Status.Reason, nil
+}
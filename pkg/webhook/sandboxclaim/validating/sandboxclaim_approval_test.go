@@ -0,0 +1,188 @@
+package validating
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+)
+
+func TestSandboxClaimApprovalHandler_Path(t *testing.T) {
+	handler := &SandboxClaimApprovalHandler{}
+	require.Equal(t, "/validate-sandboxclaim-approval", handler.Path())
+	require.True(t, handler.Enabled())
+}
+
+func TestSandboxClaimApprovalHandler_Handle(t *testing.T) {
+	require.NoError(t, agentsv1alpha1.AddToScheme(scheme.Scheme))
+
+	newClaim := func(decision string) *agentsv1alpha1.SandboxClaim {
+		claim := &agentsv1alpha1.SandboxClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: "claim", Namespace: "default"},
+			Spec: agentsv1alpha1.SandboxClaimSpec{
+				TemplateName:     "test",
+				RequiresApproval: true,
+			},
+		}
+		if decision != "" {
+			claim.Annotations = map[string]string{agentsv1alpha1.AnnotationClaimApproval: decision}
+		}
+		return claim
+	}
+
+	tests := []struct {
+		name          string
+		operation     admissionv1.Operation
+		oldClaim      *agentsv1alpha1.SandboxClaim
+		claim         *agentsv1alpha1.SandboxClaim
+		approver      bool
+		reviewErr     error
+		expectAllow   bool
+		expectReviews int
+	}{
+		{
+			name:        "create without decision",
+			operation:   admissionv1.Create,
+			claim:       newClaim(""),
+			expectAllow: true,
+		},
+		{
+			name:          "create approved by non approver",
+			operation:     admissionv1.Create,
+			claim:         newClaim(agentsv1alpha1.SandboxClaimApprovalApproved),
+			expectReviews: 1,
+		},
+		{
+			name:          "approved by approver",
+			operation:     admissionv1.Update,
+			oldClaim:      newClaim(""),
+			claim:         newClaim(agentsv1alpha1.SandboxClaimApprovalApproved),
+			approver:      true,
+			expectAllow:   true,
+			expectReviews: 1,
+		},
+		{
+			name:          "rejected by approver",
+			operation:     admissionv1.Update,
+			oldClaim:      newClaim(""),
+			claim:         newClaim(agentsv1alpha1.SandboxClaimApprovalRejected),
+			approver:      true,
+			expectAllow:   true,
+			expectReviews: 1,
+		},
+		{
+			name:          "approved by non approver",
+			operation:     admissionv1.Update,
+			oldClaim:      newClaim(""),
+			claim:         newClaim(agentsv1alpha1.SandboxClaimApprovalApproved),
+			expectReviews: 1,
+		},
+		{
+			name:      "invalid decision",
+			operation: admissionv1.Update,
+			oldClaim:  newClaim(""),
+			claim:     newClaim("Maybe"),
+			approver:  true,
+		},
+		{
+			name:      "decision changed",
+			operation: admissionv1.Update,
+			oldClaim:  newClaim(agentsv1alpha1.SandboxClaimApprovalApproved),
+			claim:     newClaim(agentsv1alpha1.SandboxClaimApprovalRejected),
+			approver:  true,
+		},
+		{
+			name:      "decision removed",
+			operation: admissionv1.Update,
+			oldClaim:  newClaim(agentsv1alpha1.SandboxClaimApprovalApproved),
+			claim:     newClaim(""),
+			approver:  true,
+		},
+		{
+			name:        "decision unchanged by non approver",
+			operation:   admissionv1.Update,
+			oldClaim:    newClaim(agentsv1alpha1.SandboxClaimApprovalApproved),
+			claim:       newClaim(agentsv1alpha1.SandboxClaimApprovalApproved),
+			expectAllow: true,
+		},
+		{
+			name:          "review failed",
+			operation:     admissionv1.Update,
+			oldClaim:      newClaim(""),
+			claim:         newClaim(agentsv1alpha1.SandboxClaimApprovalApproved),
+			reviewErr:     errors.New("review failed"),
+			expectReviews: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var reviews []*authorizationv1.SubjectAccessReview
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithInterceptorFuncs(interceptor.Funcs{
+				Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+					review, ok := obj.(*authorizationv1.SubjectAccessReview)
+					if !ok {
+						return c.Create(ctx, obj, opts...)
+					}
+					reviews = append(reviews, review.DeepCopy())
+					review.Status.Allowed = tt.approver
+					return tt.reviewErr
+				},
+			}).Build()
+			handler := &SandboxClaimApprovalHandler{
+				Client:  fakeClient,
+				Decoder: admission.NewDecoder(scheme.Scheme),
+			}
+
+			raw, err := json.Marshal(tt.claim)
+			require.NoError(t, err)
+			req := admission.Request{
+				AdmissionRequest: admissionv1.AdmissionRequest{
+					Operation: tt.operation,
+					Namespace: tt.claim.Namespace,
+					Name:      tt.claim.Name,
+					UserInfo: authenticationv1.UserInfo{
+						Username: "alice",
+						Groups:   []string{"approvers"},
+					},
+					Object: runtime.RawExtension{Raw: raw},
+				},
+			}
+			if tt.oldClaim != nil {
+				oldRaw, err := json.Marshal(tt.oldClaim)
+				require.NoError(t, err)
+				req.OldObject = runtime.RawExtension{Raw: oldRaw}
+			}
+
+			response := handler.Handle(context.TODO(), req)
+			assert.Equal(t, tt.expectAllow, response.Allowed, response.Result)
+			require.Len(t, reviews, tt.expectReviews)
+			if tt.expectReviews > 0 {
+				spec := reviews[0].Spec
+				assert.Equal(t, "alice", spec.User)
+				assert.Equal(t, []string{"approvers"}, spec.Groups)
+				assert.Equal(t, &authorizationv1.ResourceAttributes{
+					Namespace: "default",
+					Verb:      VerbApprove,
+					Group:     agentsv1alpha1.GroupVersion.Group,
+					Resource:  "sandboxclaims",
+					Name:      "claim",
+				}, spec.ResourceAttributes)
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package validating
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+)
+
+// SandboxClaimLimitsHandler rejects new SandboxClaims beyond the maxReplicasPerClaim and maxOutstandingClaims of
+// their SandboxSets, and increases of replicas beyond maxReplicasPerClaim. The limits are checked against the cache,
+// so that concurrent claims may slightly exceed them.
+type SandboxClaimLimitsHandler struct {
+	Client  client.Client
+	Decoder admission.Decoder
+}
+
+// +kubebuilder:webhook:path=/validate-sandboxclaim-limits,mutating=false,failurePolicy=fail,sideEffects=None,admissionReviewVersions=v1;v1beta1,groups=agents.kruise.io,resources=sandboxclaims,verbs=create;update,versions=v1alpha1,name=v-sbc-limits.kb.io
+
+func (h *SandboxClaimLimitsHandler) Path() string {
+	return "/validate-sandboxclaim-limits"
+}
+
+func (h *SandboxClaimLimitsHandler) Enabled() bool {
+	return true
+}
+
+func (h *SandboxClaimLimitsHandler) Handle(ctx context.Context, req admission.Request) admission.Response {
+	claim := &agentsv1alpha1.SandboxClaim{}
+	if err := h.Decoder.Decode(req, claim); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	if req.Operation == admissionv1.Update {
+		oldClaim := &agentsv1alpha1.SandboxClaim{}
+		if err := h.Decoder.DecodeRaw(req.OldObject, oldClaim); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		// only increased replicas are checked, so that claims created before their limits can still scale down
+		if claimReplicas(claim) <= claimReplicas(oldClaim) {
+			return admission.Allowed("")
+		}
+	}
+	if claim.Spec.TemplateName == "" {
+		// the SandboxSet of a templateSelector is only picked by the SandboxClaim controller
+		return admission.Allowed("")
+	}
+	sandboxSet := &agentsv1alpha1.SandboxSet{}
+	err := h.Client.Get(ctx, client.ObjectKey{Namespace: req.Namespace, Name: claim.Spec.TemplateName}, sandboxSet)
+	if errors.IsNotFound(err) {
+		// reported by the SandboxClaim controller
+		return admission.Allowed("")
+	} else if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+
+	message, err := CheckLimits(ctx, h.Client, claim, sandboxSet, req.Operation == admissionv1.Create)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	if message != "" {
+		return admission.Denied(message)
+	}
+	return admission.Allowed("")
+}
+
+// CheckLimits returns why the claim exceeds the limits of its SandboxSet, or an empty string if it does not. The
+// outstanding claims are only limited for new claims. It is shared with the controller validating SandboxClaims in the
+// standalone mode, for which the claim being checked is already created.
+func CheckLimits(ctx context.Context, reader client.Reader, claim *agentsv1alpha1.SandboxClaim,
+	sandboxSet *agentsv1alpha1.SandboxSet, create bool) (string, error) {
+	if limit := sandboxSet.Spec.MaxReplicasPerClaim; limit != nil {
+		if replicas := claimReplicas(claim); replicas > *limit {
+			return fmt.Sprintf("replicas %d exceeds maxReplicasPerClaim %d of SandboxSet %s",
+				replicas, *limit, sandboxSet.Name), nil
+		}
+	}
+	if limit := sandboxSet.Spec.MaxOutstandingClaims; limit != nil && create {
+		outstanding, err := countOutstandingClaims(ctx, reader, claim, sandboxSet.Name)
+		if err != nil {
+			return "", err
+		}
+		if outstanding >= *limit {
+			klog.InfoS("SandboxClaim denied by maxOutstandingClaims", "claim", klog.KObj(claim),
+				"sandboxSet", sandboxSet.Name, "outstanding", outstanding)
+			return fmt.Sprintf("SandboxSet %s already has %d outstanding claims, reaching its maxOutstandingClaims %d",
+				sandboxSet.Name, outstanding, *limit), nil
+		}
+	}
+	return "", nil
+}
+
+func claimReplicas(claim *agentsv1alpha1.SandboxClaim) int32 {
+	if claim.Spec.Replicas != nil {
+		return *claim.Spec.Replicas
+	}
+	return 1
+}
+
+// countOutstandingClaims counts the other claims of the SandboxSet in the namespace of the claim which are neither
+// completed nor deleted. Claims created after an existing claim are not counted for it, as they were not outstanding
+// when it was created.
+func countOutstandingClaims(ctx context.Context, reader client.Reader, claim *agentsv1alpha1.SandboxClaim, template string) (int32, error) {
+	claims := &agentsv1alpha1.SandboxClaimList{}
+	if err := reader.List(ctx, claims, client.InNamespace(claim.Namespace)); err != nil {
+		return 0, fmt.Errorf("failed to list sandboxclaims: %w", err)
+	}
+	var outstanding int32
+	for i := range claims.Items {
+		other := &claims.Items[i]
+		if other.Spec.TemplateName != template || other.DeletionTimestamp != nil ||
+			other.Status.Phase == agentsv1alpha1.SandboxClaimPhaseCompleted || other.Status.Phase == agentsv1alpha1.SandboxClaimPhaseFailed {
+			continue
+		}
+		if claim.UID != "" && (other.UID == claim.UID || claim.CreationTimestamp.Before(&other.CreationTimestamp)) {
+			continue
+		}
+		outstanding++
+	}
+	return outstanding, nil
+}
@@ -0,0 +1,212 @@
+package validating
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+)
+
+func TestSandboxClaimLimitsHandler_Path(t *testing.T) {
+	handler := &SandboxClaimLimitsHandler{}
+	require.Equal(t, "/validate-sandboxclaim-limits", handler.Path())
+	require.True(t, handler.Enabled())
+}
+
+func TestSandboxClaimLimitsHandler_Handle(t *testing.T) {
+	require.NoError(t, agentsv1alpha1.AddToScheme(scheme.Scheme))
+
+	newSandboxSet := func(maxReplicas, maxOutstanding *int32) *agentsv1alpha1.SandboxSet {
+		return &agentsv1alpha1.SandboxSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "pool", Namespace: "default"},
+			Spec: agentsv1alpha1.SandboxSetSpec{
+				MaxReplicasPerClaim:  maxReplicas,
+				MaxOutstandingClaims: maxOutstanding,
+			},
+		}
+	}
+	existingClaim := func(name, template string, phase agentsv1alpha1.SandboxClaimPhase) *agentsv1alpha1.SandboxClaim {
+		return &agentsv1alpha1.SandboxClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec:       agentsv1alpha1.SandboxClaimSpec{TemplateName: template},
+			Status:     agentsv1alpha1.SandboxClaimStatus{Phase: phase},
+		}
+	}
+
+	tests := []struct {
+		name          string
+		sandboxSet    *agentsv1alpha1.SandboxSet
+		existing      []client.Object
+		operation     admissionv1.Operation
+		replicas      *int32
+		oldReplicas   *int32
+		expectAllow   bool
+		expectMessage string
+	}{
+		{
+			name:        "sandboxset not found",
+			replicas:    ptr.To[int32](100),
+			expectAllow: true,
+		},
+		{
+			name:        "no limits",
+			sandboxSet:  newSandboxSet(nil, nil),
+			replicas:    ptr.To[int32](100),
+			expectAllow: true,
+		},
+		{
+			name:        "replicas within maxReplicasPerClaim",
+			sandboxSet:  newSandboxSet(ptr.To[int32](10), nil),
+			replicas:    ptr.To[int32](10),
+			expectAllow: true,
+		},
+		{
+			name:        "default replicas within maxReplicasPerClaim",
+			sandboxSet:  newSandboxSet(ptr.To[int32](1), nil),
+			expectAllow: true,
+		},
+		{
+			name:          "replicas exceed maxReplicasPerClaim",
+			sandboxSet:    newSandboxSet(ptr.To[int32](10), nil),
+			replicas:      ptr.To[int32](11),
+			expectMessage: "replicas 11 exceeds maxReplicasPerClaim 10 of SandboxSet pool",
+		},
+		{
+			name:       "outstanding claims below maxOutstandingClaims",
+			sandboxSet: newSandboxSet(nil, ptr.To[int32](2)),
+			existing: []client.Object{
+				existingClaim("claiming", "pool", agentsv1alpha1.SandboxClaimPhaseClaiming),
+				existingClaim("completed", "pool", agentsv1alpha1.SandboxClaimPhaseCompleted),
+				existingClaim("other-pool", "other", agentsv1alpha1.SandboxClaimPhaseClaiming),
+			},
+			expectAllow: true,
+		},
+		{
+			name:       "outstanding claims reach maxOutstandingClaims",
+			sandboxSet: newSandboxSet(nil, ptr.To[int32](2)),
+			existing: []client.Object{
+				existingClaim("claiming", "pool", agentsv1alpha1.SandboxClaimPhaseClaiming),
+				existingClaim("pending", "pool", ""),
+			},
+			expectMessage: "SandboxSet pool already has 2 outstanding claims, reaching its maxOutstandingClaims 2",
+		},
+		{
+			name:          "replicas increased beyond maxReplicasPerClaim",
+			sandboxSet:    newSandboxSet(ptr.To[int32](10), nil),
+			operation:     admissionv1.Update,
+			replicas:      ptr.To[int32](11),
+			oldReplicas:   ptr.To[int32](5),
+			expectMessage: "replicas 11 exceeds maxReplicasPerClaim 10 of SandboxSet pool",
+		},
+		{
+			name:        "replicas decreased",
+			sandboxSet:  newSandboxSet(ptr.To[int32](10), nil),
+			operation:   admissionv1.Update,
+			replicas:    ptr.To[int32](11),
+			oldReplicas: ptr.To[int32](20),
+			expectAllow: true,
+		},
+		{
+			name:       "updates are not counted against maxOutstandingClaims",
+			sandboxSet: newSandboxSet(nil, ptr.To[int32](1)),
+			existing: []client.Object{
+				existingClaim("claiming", "pool", agentsv1alpha1.SandboxClaimPhaseClaiming),
+			},
+			operation:   admissionv1.Update,
+			replicas:    ptr.To[int32](3),
+			expectAllow: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			builder := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(tt.existing...)
+			if tt.sandboxSet != nil {
+				builder = builder.WithObjects(tt.sandboxSet)
+			}
+			handler := &SandboxClaimLimitsHandler{
+				Client:  builder.Build(),
+				Decoder: admission.NewDecoder(scheme.Scheme),
+			}
+			raw, err := json.Marshal(&agentsv1alpha1.SandboxClaim{
+				ObjectMeta: metav1.ObjectMeta{Name: "claim", Namespace: "default"},
+				Spec:       agentsv1alpha1.SandboxClaimSpec{TemplateName: "pool", Replicas: tt.replicas},
+			})
+			require.NoError(t, err)
+			oldRaw, err := json.Marshal(&agentsv1alpha1.SandboxClaim{
+				ObjectMeta: metav1.ObjectMeta{Name: "claim", Namespace: "default"},
+				Spec:       agentsv1alpha1.SandboxClaimSpec{TemplateName: "pool", Replicas: tt.oldReplicas},
+			})
+			require.NoError(t, err)
+			operation := tt.operation
+			if operation == "" {
+				operation = admissionv1.Create
+			}
+			req := admission.Request{
+				AdmissionRequest: admissionv1.AdmissionRequest{
+					Operation: operation,
+					Namespace: "default",
+					Name:      "claim",
+					Object:    runtime.RawExtension{Raw: raw},
+				},
+			}
+			if operation == admissionv1.Update {
+				req.OldObject = runtime.RawExtension{Raw: oldRaw}
+			}
+			response := handler.Handle(context.TODO(), req)
+			assert.Equal(t, tt.expectAllow, response.Allowed, response.Result)
+			if tt.expectMessage != "" {
+				assert.Equal(t, tt.expectMessage, response.Result.Message)
+			}
+		})
+	}
+}
+
+func TestCheckLimits_CreatedClaim(t *testing.T) {
+	require.NoError(t, agentsv1alpha1.AddToScheme(scheme.Scheme))
+	now := time.Now()
+	newClaim := func(name string, createdAgo time.Duration) *agentsv1alpha1.SandboxClaim {
+		return &agentsv1alpha1.SandboxClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         "default",
+				UID:               types.UID(name + "-uid"),
+				CreationTimestamp: metav1.NewTime(now.Add(-createdAgo)),
+			},
+			Spec: agentsv1alpha1.SandboxClaimSpec{TemplateName: "pool"},
+		}
+	}
+	earlier, claim, later := newClaim("earlier", 2*time.Minute), newClaim("claim", time.Minute), newClaim("later", 0)
+	sandboxSet := &agentsv1alpha1.SandboxSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "pool", Namespace: "default"},
+		Spec:       agentsv1alpha1.SandboxSetSpec{MaxOutstandingClaims: ptr.To[int32](2)},
+	}
+	reader := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(earlier, claim, later).Build()
+
+	// the claim itself and the ones created after it are not outstanding for it
+	message, err := CheckLimits(context.TODO(), reader, claim, sandboxSet, true)
+	require.NoError(t, err)
+	assert.Empty(t, message)
+
+	message, err = CheckLimits(context.TODO(), reader, later, sandboxSet, true)
+	require.NoError(t, err)
+	assert.Equal(t, "SandboxSet pool already has 2 outstanding claims, reaching its maxOutstandingClaims 2", message)
+
+	// the outstanding claims are only limited for new claims
+	message, err = CheckLimits(context.TODO(), reader, later, sandboxSet, false)
+	require.NoError(t, err)
+	assert.Empty(t, message)
+}
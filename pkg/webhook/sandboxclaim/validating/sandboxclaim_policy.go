@@ -0,0 +1,83 @@
+package validating
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+	"github.com/openkruise/agents/pkg/features"
+	utilfeature "github.com/openkruise/agents/pkg/utils/feature"
+	"github.com/openkruise/agents/pkg/utils/webhookutils"
+	"github.com/openkruise/agents/pkg/webhook/sandboxclaim/policy"
+)
+
+// SandboxClaimPolicyHandler admits new SandboxClaims by the CEL policies of the policy ConfigMap and the
+// external OPA endpoint, if configured.
+type SandboxClaimPolicyHandler struct {
+	// Reader reads the policy ConfigMap from the API server, so that ConfigMaps are not cached cluster-wide.
+	Reader  client.Reader
+	Decoder admission.Decoder
+	CEL     *policy.CELEvaluator
+	// OPA is nil if no OPA endpoint is configured.
+	OPA *policy.OPAClient
+}
+
+// +kubebuilder:webhook:path=/validate-sandboxclaim-policy,mutating=false,failurePolicy=fail,sideEffects=None,admissionReviewVersions=v1;v1beta1,groups=agents.kruise.io,resources=sandboxclaims,verbs=create,versions=v1alpha1,name=v-sbc-policy.kb.io
+// +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get,namespace=sandbox-system
+
+func (h *SandboxClaimPolicyHandler) Path() string {
+	return "/validate-sandboxclaim-policy"
+}
+
+func (h *SandboxClaimPolicyHandler) Enabled() bool {
+	return utilfeature.DefaultFeatureGate.Enabled(features.SandboxClaimPolicyGate)
+}
+
+func (h *SandboxClaimPolicyHandler) Handle(ctx context.Context, req admission.Request) admission.Response {
+	claim := &agentsv1alpha1.SandboxClaim{}
+	if err := h.Decoder.Decode(req, claim); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	input, err := policy.NewInput(req, claim)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	decision, err := h.evaluate(ctx, input)
+	if err != nil {
+		klog.ErrorS(err, "failed to evaluate sandboxclaim policies", "claim", klog.KRef(req.Namespace, req.Name))
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	if !decision.Allowed {
+		klog.InfoS("SandboxClaim denied by policy", "claim", klog.KRef(req.Namespace, req.Name),
+			"user", req.UserInfo.Username, "message", decision.Message)
+		return admission.Denied(decision.Message)
+	}
+	return admission.Allowed("")
+}
+
+func (h *SandboxClaimPolicyHandler) evaluate(ctx context.Context, input map[string]any) (policy.Decision, error) {
+	cm := &corev1.ConfigMap{}
+	err := h.Reader.Get(ctx, types.NamespacedName{Namespace: webhookutils.GetNamespace(), Name: policy.ConfigMapName}, cm)
+	if err != nil && !errors.IsNotFound(err) {
+		return policy.Decision{}, fmt.Errorf("failed to get policy configmap: %w", err)
+	}
+	if err == nil {
+		decision, err := h.CEL.Evaluate(ctx, cm, input, time.Now())
+		if err != nil || !decision.Allowed {
+			return decision, err
+		}
+	}
+	if h.OPA != nil {
+		return h.OPA.Evaluate(ctx, input)
+	}
+	return policy.Allow, nil
+}
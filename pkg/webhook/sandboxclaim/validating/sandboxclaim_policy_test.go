@@ -0,0 +1,139 @@
+package validating
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+	"github.com/openkruise/agents/pkg/utils/webhookutils"
+	"github.com/openkruise/agents/pkg/webhook/sandboxclaim/policy"
+)
+
+func TestSandboxClaimPolicyHandler_Path(t *testing.T) {
+	handler := &SandboxClaimPolicyHandler{}
+	require.Equal(t, "/validate-sandboxclaim-policy", handler.Path())
+	// disabled by default
+	require.False(t, handler.Enabled())
+}
+
+func TestSandboxClaimPolicyHandler_Handle(t *testing.T) {
+	require.NoError(t, agentsv1alpha1.AddToScheme(scheme.Scheme))
+
+	templateAllowlist := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: policy.ConfigMapName, Namespace: webhookutils.GetNamespace()},
+		Data: map[string]string{
+			"templates": `
+expression: object.spec.templateName in ["python", "node"]
+message: template is not allowed`,
+		},
+	}
+	tests := []struct {
+		name          string
+		configMap     *corev1.ConfigMap
+		template      string
+		opaResult     string
+		expectAllow   bool
+		expectMessage string
+		expectOPA     bool
+	}{
+		{
+			name:        "no policies",
+			template:    "java",
+			expectAllow: true,
+		},
+		{
+			name:        "allowed by cel",
+			configMap:   templateAllowlist,
+			template:    "python",
+			expectAllow: true,
+		},
+		{
+			name:          "denied by cel",
+			configMap:     templateAllowlist,
+			template:      "java",
+			expectMessage: "template is not allowed",
+		},
+		{
+			name:        "allowed by cel and opa",
+			configMap:   templateAllowlist,
+			template:    "python",
+			opaResult:   `{"result": true}`,
+			expectAllow: true,
+			expectOPA:   true,
+		},
+		{
+			name:          "allowed by cel and denied by opa",
+			configMap:     templateAllowlist,
+			template:      "python",
+			opaResult:     `{"result": {"allowed": false, "message": "outside working hours"}}`,
+			expectMessage: "outside working hours",
+			expectOPA:     true,
+		},
+		{
+			name:          "denied by cel before opa",
+			configMap:     templateAllowlist,
+			template:      "java",
+			opaResult:     `{"result": true}`,
+			expectMessage: "template is not allowed",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			builder := fake.NewClientBuilder().WithScheme(scheme.Scheme)
+			if tt.configMap != nil {
+				builder = builder.WithObjects(tt.configMap)
+			}
+			evaluator, err := policy.NewCELEvaluator()
+			require.NoError(t, err)
+			handler := &SandboxClaimPolicyHandler{
+				Reader:  builder.Build(),
+				Decoder: admission.NewDecoder(scheme.Scheme),
+				CEL:     evaluator,
+			}
+			opaQueried := false
+			if tt.opaResult != "" {
+				server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					opaQueried = true
+					_, _ = w.Write([]byte(tt.opaResult))
+				}))
+				defer server.Close()
+				handler.OPA = policy.NewOPAClient(policy.Options{OPAURL: server.URL, OPATimeout: time.Second})
+			}
+
+			raw, err := json.Marshal(&agentsv1alpha1.SandboxClaim{
+				ObjectMeta: metav1.ObjectMeta{Name: "claim", Namespace: "default"},
+				Spec:       agentsv1alpha1.SandboxClaimSpec{TemplateName: tt.template},
+			})
+			require.NoError(t, err)
+			response := handler.Handle(context.TODO(), admission.Request{
+				AdmissionRequest: admissionv1.AdmissionRequest{
+					Operation: admissionv1.Create,
+					Namespace: "default",
+					Name:      "claim",
+					UserInfo:  authenticationv1.UserInfo{Username: "alice"},
+					Object:    runtime.RawExtension{Raw: raw},
+				},
+			})
+			assert.Equal(t, tt.expectAllow, response.Allowed, response.Result)
+			if tt.expectMessage != "" {
+				assert.Equal(t, tt.expectMessage, response.Result.Message)
+			}
+			assert.Equal(t, tt.expectOPA, opaQueried)
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package validating
+
+import (
+	"context"
+	"net/http"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+	"github.com/openkruise/agents/pkg/utils/objectsize"
+)
+
+// SandboxClaimSizeHandler rejects SandboxClaims beyond the size limits of --max-sandboxclaim-object-bytes and
+// --max-sandboxclaim-metadata-bytes. Updates are only rejected if they grow the claim, so that claims created before
+// the limits can still be shrunk, finalized and deleted.
+type SandboxClaimSizeHandler struct {
+	Decoder admission.Decoder
+	Options objectsize.Options
+}
+
+// +kubebuilder:webhook:path=/validate-sandboxclaim-size,mutating=false,failurePolicy=fail,sideEffects=None,admissionReviewVersions=v1;v1beta1,groups=agents.kruise.io,resources=sandboxclaims,verbs=create;update,versions=v1alpha1,name=v-sbc-size.kb.io
+
+func (h *SandboxClaimSizeHandler) Path() string {
+	return "/validate-sandboxclaim-size"
+}
+
+func (h *SandboxClaimSizeHandler) Enabled() bool {
+	return true
+}
+
+func (h *SandboxClaimSizeHandler) Handle(_ context.Context, req admission.Request) admission.Response {
+	claim := &agentsv1alpha1.SandboxClaim{}
+	if err := h.Decoder.Decode(req, claim); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	err := h.Options.ValidateClaim(claim, len(req.Object.Raw))
+	if err == nil {
+		return admission.Allowed("")
+	}
+	if req.Operation == admissionv1.Update && len(req.Object.Raw) <= len(req.OldObject.Raw) {
+		return admission.Allowed("")
+	}
+	return admission.Denied(err.Error())
+}
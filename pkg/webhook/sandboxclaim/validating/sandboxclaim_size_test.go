@@ -0,0 +1,108 @@
+package validating
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+	"github.com/openkruise/agents/pkg/utils/objectsize"
+)
+
+func TestSandboxClaimSizeHandler_Path(t *testing.T) {
+	handler := &SandboxClaimSizeHandler{}
+	require.Equal(t, "/validate-sandboxclaim-size", handler.Path())
+	require.True(t, handler.Enabled())
+}
+
+func TestSandboxClaimSizeHandler_Handle(t *testing.T) {
+	require.NoError(t, agentsv1alpha1.AddToScheme(scheme.Scheme))
+
+	newClaimRaw := func(envBytes int) []byte {
+		raw, err := json.Marshal(&agentsv1alpha1.SandboxClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: "claim", Namespace: "default"},
+			Spec: agentsv1alpha1.SandboxClaimSpec{
+				TemplateName: "pool",
+				EnvVars:      map[string]string{"ENV": strings.Repeat("v", envBytes)},
+			},
+		})
+		require.NoError(t, err)
+		return raw
+	}
+
+	tests := []struct {
+		name          string
+		operation     admissionv1.Operation
+		envBytes      int
+		oldEnvBytes   int
+		expectAllow   bool
+		expectMessage string
+	}{
+		{
+			name:        "within limits",
+			operation:   admissionv1.Create,
+			envBytes:    10,
+			expectAllow: true,
+		},
+		{
+			name:          "metadata too large",
+			operation:     admissionv1.Create,
+			envBytes:      200,
+			expectMessage: "spec.labels, spec.annotations and spec.envVars are 203 bytes in total, exceeding the limit of 100 bytes",
+		},
+		{
+			name:          "grown beyond the limits",
+			operation:     admissionv1.Update,
+			envBytes:      200,
+			oldEnvBytes:   10,
+			expectMessage: "spec.labels, spec.annotations and spec.envVars are 203 bytes in total, exceeding the limit of 100 bytes",
+		},
+		{
+			name:        "claim created before the limits shrinks",
+			operation:   admissionv1.Update,
+			envBytes:    150,
+			oldEnvBytes: 200,
+			expectAllow: true,
+		},
+		{
+			name:        "claim created before the limits is finalized",
+			operation:   admissionv1.Update,
+			envBytes:    200,
+			oldEnvBytes: 200,
+			expectAllow: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := &SandboxClaimSizeHandler{
+				Decoder: admission.NewDecoder(scheme.Scheme),
+				Options: objectsize.Options{MaxClaimObjectBytes: 4096, MaxClaimMetadataBytes: 100},
+			}
+			req := admission.Request{
+				AdmissionRequest: admissionv1.AdmissionRequest{
+					Operation: tt.operation,
+					Namespace: "default",
+					Name:      "claim",
+					Object:    runtime.RawExtension{Raw: newClaimRaw(tt.envBytes)},
+				},
+			}
+			if tt.operation == admissionv1.Update {
+				req.OldObject = runtime.RawExtension{Raw: newClaimRaw(tt.oldEnvBytes)}
+			}
+			response := handler.Handle(context.TODO(), req)
+			assert.Equal(t, tt.expectAllow, response.Allowed, response.Result)
+			if tt.expectMessage != "" {
+				assert.Equal(t, tt.expectMessage, response.Result.Message)
+			}
+		})
+	}
+}
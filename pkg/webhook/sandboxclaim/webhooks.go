@@ -0,0 +1,56 @@
+package sandboxclaim
+
+import (
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/openkruise/agents/pkg/utils/claimlatency"
+	"github.com/openkruise/agents/pkg/utils/objectsize"
+	"github.com/openkruise/agents/pkg/webhook/sandboxclaim/mutating"
+	"github.com/openkruise/agents/pkg/webhook/sandboxclaim/policy"
+	"github.com/openkruise/agents/pkg/webhook/sandboxclaim/validating"
+	"github.com/openkruise/agents/pkg/webhook/types"
+)
+
+func GetHandlerGetters() []types.HandlerGetter {
+	return []types.HandlerGetter{
+		func(mgr manager.Manager) types.Handler {
+			return &mutating.SandboxClaimDefaulter{
+				Decoder:   admission.NewDecoder(mgr.GetScheme()),
+				Latencies: claimlatency.Default,
+			}
+		},
+		func(mgr manager.Manager) types.Handler {
+			return &validating.SandboxClaimApprovalHandler{
+				Client:  mgr.GetClient(),
+				Decoder: admission.NewDecoder(mgr.GetScheme()),
+			}
+		},
+		func(mgr manager.Manager) types.Handler {
+			return &validating.SandboxClaimLimitsHandler{
+				Client:  mgr.GetClient(),
+				Decoder: admission.NewDecoder(mgr.GetScheme()),
+			}
+		},
+		func(mgr manager.Manager) types.Handler {
+			return &validating.SandboxClaimSizeHandler{
+				Decoder: admission.NewDecoder(mgr.GetScheme()),
+				Options: objectsize.DefaultOptions,
+			}
+		},
+		func(mgr manager.Manager) types.Handler {
+			evaluator, err := policy.NewCELEvaluator()
+			utilruntime.Must(err)
+			handler := &validating.SandboxClaimPolicyHandler{
+				Reader:  mgr.GetAPIReader(),
+				Decoder: admission.NewDecoder(mgr.GetScheme()),
+				CEL:     evaluator,
+			}
+			if policy.DefaultOptions.OPAURL != "" {
+				handler.OPA = policy.NewOPAClient(policy.DefaultOptions)
+			}
+			return handler
+		},
+	}
+}
@@ -68,24 +68,29 @@ func (h *SandboxSetDefaulter) Handle(_ context.Context, req admission.Request) a
 		return admission.Errored(http.StatusBadRequest, err)
 	}
 
+	if SetDefaults(obj, req.Operation == admissionv1.Create) {
+		marshal, err := json.Marshal(obj)
+		if err != nil {
+			return admission.Errored(http.StatusInternalServerError, err)
+		}
+		return admission.PatchResponseFromRaw(req.Object.Raw, marshal)
+	}
+	return admission.Allowed("")
+}
+
+// SetDefaults defaults the SandboxSet, with the default persistent contents for new ones, and returns whether it is
+// changed. It is shared with the controller defaulting SandboxSets in the standalone mode.
+func SetDefaults(obj *agentsv1alpha1.SandboxSet, create bool) bool {
 	clone := obj.DeepCopy()
 	setDefaultPodTemplate(obj.Spec.Template)
 
-	if req.Operation == admissionv1.Create && len(obj.Spec.PersistentContents) == 0 && len(defaultPersistentContents) > 0 {
+	if create && len(obj.Spec.PersistentContents) == 0 && len(defaultPersistentContents) > 0 {
 		obj.Spec.PersistentContents = defaultPersistentContents
 	}
 
 	// Apply defaulting logic to volume claim templates
 	setDefaultVolumeClaimTemplates(obj.Spec.VolumeClaimTemplates)
-
-	if !reflect.DeepEqual(obj, clone) {
-		marshal, err := json.Marshal(obj)
-		if err != nil {
-			return admission.Errored(http.StatusInternalServerError, err)
-		}
-		return admission.PatchResponseFromRaw(req.Object.Raw, marshal)
-	}
-	return admission.Allowed("")
+	return !reflect.DeepEqual(obj, clone)
 }
 
 func setDefaultPodTemplate(template *v1.PodTemplateSpec) {
@@ -7,9 +7,12 @@ import (
 	"net/http"
 	"strings"
 
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/validation"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
 	intstrutil "k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/kubernetes/pkg/apis/core"
 	corev1 "k8s.io/kubernetes/pkg/apis/core/v1"
@@ -18,6 +21,9 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 
 	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+	"github.com/openkruise/agents/pkg/features"
+	utilfeature "github.com/openkruise/agents/pkg/utils/feature"
+	"github.com/openkruise/agents/pkg/utils/templateutils"
 	webhookutils "github.com/openkruise/agents/pkg/webhook/utils"
 )
 
@@ -36,21 +42,62 @@ func (h *SandboxSetValidatingHandler) Enabled() bool {
 	return true
 }
 
-func (h *SandboxSetValidatingHandler) Handle(_ context.Context, req admission.Request) admission.Response {
+func (h *SandboxSetValidatingHandler) Handle(ctx context.Context, req admission.Request) admission.Response {
 	obj := &agentsv1alpha1.SandboxSet{}
 	err := h.Decoder.Decode(req, obj)
 	if err != nil {
 		return admission.Errored(http.StatusBadRequest, err)
 	}
-	var errList field.ErrorList
-	errList = append(errList, validateSandboxSetMetadata(obj.ObjectMeta, field.NewPath("metadata"))...)
-	errList = append(errList, validateSandboxSetSpec(obj.Spec, field.NewPath("spec"))...)
+	errList := ValidateSandboxSet(obj)
 	if len(errList) > 0 {
 		return admission.Errored(http.StatusUnprocessableEntity, errList.ToAggregate())
 	}
+	resolvedErrList, err := h.validateResolvedTemplate(ctx, obj)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	if len(resolvedErrList) > 0 {
+		return admission.Errored(http.StatusUnprocessableEntity, resolvedErrList.ToAggregate())
+	}
 	return admission.Allowed("")
 }
 
+// validateResolvedTemplate validates the template resolved from the base SandboxTemplate and the overlay. A base
+// template not created yet is left to the controller, which reports it until created.
+func (h *SandboxSetValidatingHandler) validateResolvedTemplate(ctx context.Context, obj *agentsv1alpha1.SandboxSet) (field.ErrorList, error) {
+	if obj.Spec.TemplateOverlay == nil {
+		return nil, nil
+	}
+	fldPath := field.NewPath("spec", "templateOverlay")
+	base := &agentsv1alpha1.SandboxTemplate{}
+	if err := h.Client.Get(ctx, client.ObjectKey{Namespace: obj.Namespace, Name: obj.Spec.TemplateRef.Name}, base); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	resolved, err := templateutils.FlattenSandboxSet(obj, base)
+	if err != nil {
+		return field.ErrorList{field.Invalid(fldPath, obj.Spec.TemplateOverlay, err.Error())}, nil
+	}
+	return ValidateResolvedTemplate(resolved), nil
+}
+
+// ValidateSandboxSet validates the metadata and spec of the SandboxSet. It is shared with the controller validating
+// SandboxSets in the standalone mode.
+func ValidateSandboxSet(obj *agentsv1alpha1.SandboxSet) field.ErrorList {
+	var errList field.ErrorList
+	errList = append(errList, validateSandboxSetMetadata(obj.ObjectMeta, field.NewPath("metadata"))...)
+	errList = append(errList, validateSandboxSetSpec(obj.Spec, field.NewPath("spec"))...)
+	return errList
+}
+
+// ValidateResolvedTemplate validates the template of a SandboxSet resolved from the base SandboxTemplate and the
+// overlay.
+func ValidateResolvedTemplate(resolved *agentsv1alpha1.SandboxSet) field.ErrorList {
+	return validateSandboxSetPodTemplateSpec(resolved.Spec, field.NewPath("spec", "templateOverlay", "resolved"))
+}
+
 func validateSandboxSetMetadata(metadata metav1.ObjectMeta, fldPath *field.Path) field.ErrorList {
 	var errList field.ErrorList
 	errList = append(errList, validation.ValidateObjectMeta(&metadata, true, validation.NameIsDNSSubdomain, fldPath)...)
@@ -84,6 +131,13 @@ func validateSandboxSetSpec(spec agentsv1alpha1.SandboxSetSpec, fldPath *field.P
 		errList = append(errList, field.Invalid(fldPath.Child("templateRef"), spec.TemplateRef, "templateRef and podtemplate is mutual exclusive"))
 	}
 
+	if spec.TemplateOverlay != nil {
+		if spec.TemplateRef == nil {
+			errList = append(errList, field.Required(fldPath.Child("templateRef"), "templateOverlay requires templateRef"))
+		}
+		errList = append(errList, validateLabelsAndAnnotations(spec.TemplateOverlay.ObjectMeta, fldPath.Child("templateOverlay"))...)
+	}
+
 	if spec.EmbeddedSandboxTemplate.Template != nil {
 		errList = append(errList, validateLabelsAndAnnotations(spec.Template.ObjectMeta, fldPath.Child("template"))...)
 		errList = append(errList, validateSandboxSetPodTemplateSpec(spec, fldPath)...)
@@ -94,6 +148,46 @@ func validateSandboxSetSpec(spec agentsv1alpha1.SandboxSetSpec, fldPath *field.P
 		errList = append(errList, field.Invalid(fldPath.Child("scaleStrategy.maxUnavailable"), spec.ScaleStrategy.MaxUnavailable, "maxUnavailable is invalid"))
 	}
 
+	if reserve := spec.InteractiveReserve; reserve != nil {
+		// a percentage of 100 available sandboxes is the percentage itself
+		scaled, err := intstrutil.GetScaledValueFromIntOrPercent(reserve, 100, true)
+		if err != nil || scaled < 0 || (reserve.Type == intstrutil.String && scaled > 100) {
+			errList = append(errList, field.Invalid(fldPath.Child("interactiveReserve"), reserve,
+				"interactiveReserve must be a non-negative number or a percentage up to 100%"))
+		}
+	}
+
+	if len(spec.NodePools) > 0 {
+		errList = append(errList, validateNodePools(spec, fldPath)...)
+	}
+
+	return errList
+}
+
+// validateNodePools validates the node pools, which place sandboxes by patching their pod template
+func validateNodePools(spec agentsv1alpha1.SandboxSetSpec, specPath *field.Path) field.ErrorList {
+	var errList field.ErrorList
+	fldPath := specPath.Child("nodePools")
+	if !utilfeature.DefaultFeatureGate.Enabled(features.SandboxSetNodePoolsGate) {
+		return append(errList, field.Forbidden(fldPath, "nodePools requires the SandboxSetNodePools feature gate"))
+	}
+	if spec.Template == nil && spec.TemplateOverlay == nil {
+		errList = append(errList, field.Required(specPath.Child("templateOverlay"), "nodePools require template or templateOverlay"))
+	}
+	names := sets.New[string]()
+	var weights int32
+	for i, pool := range spec.NodePools {
+		poolPath := fldPath.Index(i)
+		if names.Has(pool.Name) {
+			errList = append(errList, field.Duplicate(poolPath.Child("name"), pool.Name))
+		}
+		names.Insert(pool.Name)
+		errList = append(errList, metav1validation.ValidateLabels(pool.NodeSelector, poolPath.Child("nodeSelector"))...)
+		weights += pool.Weight
+	}
+	if weights <= 0 {
+		errList = append(errList, field.Invalid(fldPath, spec.NodePools, "the weight of at least one node pool must be positive"))
+	}
 	return errList
 }
 
@@ -3,6 +3,7 @@ package validating
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"testing"
 
 	"github.com/onsi/gomega"
@@ -12,11 +13,14 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/client-go/kubernetes/scheme"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 
 	"github.com/openkruise/agents/api/v1alpha1"
+	"github.com/openkruise/agents/pkg/features"
+	utilfeature "github.com/openkruise/agents/pkg/utils/feature"
 )
 
 func TestSandboxSetValidatingHandler_Handle(t *testing.T) {
@@ -24,6 +28,50 @@ func TestSandboxSetValidatingHandler_Handle(t *testing.T) {
 	err := v1alpha1.AddToScheme(scheme.Scheme)
 	require.NoError(t, err)
 
+	newContainer := func(name string) corev1.Container {
+		return corev1.Container{
+			Name:                     name,
+			Image:                    "nginx:latest",
+			ImagePullPolicy:          corev1.PullAlways,
+			TerminationMessagePolicy: corev1.TerminationMessageReadFile,
+		}
+	}
+	baseTemplate := &v1alpha1.SandboxTemplate{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "base-template",
+			Namespace: "default",
+		},
+		Spec: v1alpha1.SandboxTemplateSpec{
+			Template: &corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "test"}},
+				Spec: corev1.PodSpec{
+					RestartPolicy:                 corev1.RestartPolicyAlways,
+					DNSPolicy:                     corev1.DNSClusterFirst,
+					TerminationGracePeriodSeconds: new(int64),
+					Containers:                    []corev1.Container{newContainer("main")},
+				},
+			},
+		},
+	}
+	newOverlaySandboxSet := func(templateRef string, containers ...corev1.Container) *v1alpha1.SandboxSet {
+		sbs := &v1alpha1.SandboxSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-sbs",
+				Namespace: "default",
+			},
+			Spec: v1alpha1.SandboxSetSpec{
+				Replicas: 3,
+				TemplateOverlay: &corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{Containers: containers},
+				},
+			},
+		}
+		if templateRef != "" {
+			sbs.Spec.TemplateRef = &v1alpha1.SandboxTemplateRef{Name: templateRef}
+		}
+		return sbs
+	}
+
 	tests := []struct {
 		name         string
 		sandboxSet   *v1alpha1.SandboxSet
@@ -468,6 +516,64 @@ func TestSandboxSetValidatingHandler_Handle(t *testing.T) {
 			expectError:  true,
 			errorMessage: "maxUnavailable is invalid",
 		},
+		{
+			name: "Valid InteractiveReserve",
+			sandboxSet: func() *v1alpha1.SandboxSet {
+				sbs := newOverlaySandboxSet("base-template")
+				sbs.Spec.InteractiveReserve = &intstr.IntOrString{Type: intstr.String, StrVal: "20%"}
+				return sbs
+			}(),
+			expectAllow: true,
+			expectError: false,
+		},
+		{
+			name: "Invalid InteractiveReserve - percentage over 100%",
+			sandboxSet: func() *v1alpha1.SandboxSet {
+				sbs := newOverlaySandboxSet("base-template")
+				sbs.Spec.InteractiveReserve = &intstr.IntOrString{Type: intstr.String, StrVal: "120%"}
+				return sbs
+			}(),
+			expectAllow:  false,
+			expectError:  true,
+			errorMessage: "interactiveReserve must be a non-negative number or a percentage up to 100%",
+		},
+		{
+			name: "Invalid InteractiveReserve - negative",
+			sandboxSet: func() *v1alpha1.SandboxSet {
+				sbs := newOverlaySandboxSet("base-template")
+				sbs.Spec.InteractiveReserve = &intstr.IntOrString{Type: intstr.Int, IntVal: -1}
+				return sbs
+			}(),
+			expectAllow:  false,
+			expectError:  true,
+			errorMessage: "interactiveReserve must be a non-negative number or a percentage up to 100%",
+		},
+		{
+			name:        "Valid template overlay adding a sidecar",
+			sandboxSet:  newOverlaySandboxSet("base-template", newContainer("sidecar")),
+			expectAllow: true,
+			expectError: false,
+		},
+		{
+			name:        "Template overlay with base template not created yet",
+			sandboxSet:  newOverlaySandboxSet("not-exist", newContainer("sidecar")),
+			expectAllow: true,
+			expectError: false,
+		},
+		{
+			name:         "Invalid template overlay - without templateRef",
+			sandboxSet:   newOverlaySandboxSet("", newContainer("sidecar")),
+			expectAllow:  false,
+			expectError:  true,
+			errorMessage: "templateOverlay requires templateRef",
+		},
+		{
+			name:         "Invalid template overlay - resolved template is invalid",
+			sandboxSet:   newOverlaySandboxSet("base-template", newContainer("Invalid_Sidecar")),
+			expectAllow:  false,
+			expectError:  true,
+			errorMessage: "spec.templateOverlay.resolved.template.spec.containers[1].name",
+		},
 	}
 
 	for _, tt := range tests {
@@ -475,7 +581,7 @@ func TestSandboxSetValidatingHandler_Handle(t *testing.T) {
 			g := gomega.NewGomegaWithT(t)
 
 			// Create fake client
-			objs := []runtime.Object{}
+			objs := []runtime.Object{baseTemplate}
 			fakeClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithRuntimeObjects(objs...).Build()
 
 			// Create decoder
@@ -517,3 +623,89 @@ func TestSandboxSetValidatingHandler_Handle(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateNodePools(t *testing.T) {
+	newSpec := func(pools ...v1alpha1.SandboxSetNodePool) v1alpha1.SandboxSetSpec {
+		return v1alpha1.SandboxSetSpec{
+			Replicas: 10,
+			EmbeddedSandboxTemplate: v1alpha1.EmbeddedSandboxTemplate{
+				Template: &corev1.PodTemplateSpec{},
+			},
+			NodePools: pools,
+		}
+	}
+	spot := v1alpha1.SandboxSetNodePool{Name: "spot", NodeSelector: map[string]string{"capacity-type": "spot"}, Weight: 7}
+	onDemand := v1alpha1.SandboxSetNodePool{Name: "on-demand", NodeSelector: map[string]string{"capacity-type": "on-demand"}, Weight: 3}
+
+	tests := []struct {
+		name         string
+		spec         v1alpha1.SandboxSetSpec
+		disabled     bool
+		errorMessage string
+	}{
+		{
+			name: "valid node pools",
+			spec: newSpec(spot, onDemand),
+		},
+		{
+			name: "valid node pools with template overlay",
+			spec: func() v1alpha1.SandboxSetSpec {
+				spec := newSpec(spot, onDemand)
+				spec.Template = nil
+				spec.TemplateRef = &v1alpha1.SandboxTemplateRef{Name: "base-template"}
+				spec.TemplateOverlay = &corev1.PodTemplateSpec{}
+				return spec
+			}(),
+		},
+		{
+			name:         "feature gate disabled",
+			spec:         newSpec(spot, onDemand),
+			disabled:     true,
+			errorMessage: "nodePools requires the SandboxSetNodePools feature gate",
+		},
+		{
+			name: "templateRef without overlay",
+			spec: func() v1alpha1.SandboxSetSpec {
+				spec := newSpec(spot, onDemand)
+				spec.Template = nil
+				spec.TemplateRef = &v1alpha1.SandboxTemplateRef{Name: "base-template"}
+				return spec
+			}(),
+			errorMessage: "nodePools require template or templateOverlay",
+		},
+		{
+			name:         "duplicated names",
+			spec:         newSpec(spot, spot),
+			errorMessage: "spec.nodePools[1].name: Duplicate value",
+		},
+		{
+			name: "invalid node selector",
+			spec: newSpec(v1alpha1.SandboxSetNodePool{
+				Name: "spot", NodeSelector: map[string]string{"capacity-type": "not valid"}, Weight: 1,
+			}),
+			errorMessage: "spec.nodePools[0].nodeSelector",
+		},
+		{
+			name: "no positive weight",
+			spec: newSpec(v1alpha1.SandboxSetNodePool{
+				Name: "spot", NodeSelector: map[string]string{"capacity-type": "spot"}, MinReplicas: 2,
+			}),
+			errorMessage: "the weight of at least one node pool must be positive",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, utilfeature.DefaultMutableFeatureGate.Set(
+				fmt.Sprintf("%s=%t", features.SandboxSetNodePoolsGate, !tt.disabled)))
+			defer func() {
+				_ = utilfeature.DefaultMutableFeatureGate.Set(fmt.Sprintf("%s=false", features.SandboxSetNodePoolsGate))
+			}()
+			errList := validateNodePools(tt.spec, field.NewPath("spec"))
+			if tt.errorMessage == "" {
+				require.Empty(t, errList)
+				return
+			}
+			require.ErrorContains(t, errList.ToAggregate(), tt.errorMessage)
+		})
+	}
+}
@@ -13,7 +13,9 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 
+	"github.com/openkruise/agents/pkg/webhook/faultinjection"
 	"github.com/openkruise/agents/pkg/webhook/pod"
+	"github.com/openkruise/agents/pkg/webhook/sandboxclaim"
 	"github.com/openkruise/agents/pkg/webhook/sandboxset"
 	"github.com/openkruise/agents/pkg/webhook/types"
 )
@@ -29,6 +31,7 @@ var (
 func init() {
 	HandlerGetters = append(HandlerGetters, sandboxset.GetHandlerGetters()...)
 	HandlerGetters = append(HandlerGetters, pod.GetHandlerGetters()...)
+	HandlerGetters = append(HandlerGetters, sandboxclaim.GetHandlerGetters()...)
 }
 
 // +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;update;patch;delete,namespace=sandbox-system
@@ -47,6 +50,10 @@ func SetupWithManager(logger logr.Logger, mgr manager.Manager) error {
 	}
 	// register admission handlers
 	for path, handler := range HandlerMap {
+		if faultinjection.DefaultOptions.Enabled {
+			logger.Info("Injecting faults into webhook handler, for testing only", "path", path)
+			handler = faultinjection.Wrap(path, handler, mgr.GetAPIReader())
+		}
 		server.Register(path, &webhook.Admission{Handler: handler})
 		logger.Info("Registered webhook handler", "path", path)
 	}
@@ -0,0 +1,86 @@
+// Package standalone runs the defaulting and validation of the webhooks in the controllers, for the installations
+// without the webhooks, e.g. minimal edge installs. The checks depending on the requesting user, i.e. the approval of
+// SandboxClaims and the SandboxClaim policies, are left to the webhooks.
+package standalone
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+
+	"github.com/spf13/pflag"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+	"github.com/openkruise/agents/pkg/utils/claimlatency"
+	"github.com/openkruise/agents/pkg/utils/objectsize"
+	claimmutating "github.com/openkruise/agents/pkg/webhook/sandboxclaim/mutating"
+	claimvalidating "github.com/openkruise/agents/pkg/webhook/sandboxclaim/validating"
+	setmutating "github.com/openkruise/agents/pkg/webhook/sandboxset/mutating"
+	setvalidating "github.com/openkruise/agents/pkg/webhook/sandboxset/validating"
+)
+
+// EventValidationFailed is the reason of the warning events of objects a webhook would have rejected
+const EventValidationFailed = "ValidationFailed"
+
+// Options configure the standalone validation mode.
+type Options struct {
+	// Enabled makes the controllers default the objects and skip the ones a webhook would have rejected.
+	Enabled bool
+}
+
+// DefaultOptions is set by the command line flags. The mode is enabled by default if the webhooks are disabled.
+var DefaultOptions = Options{
+	Enabled: os.Getenv("ENABLE_WEBHOOKS") == "false",
+}
+
+// AddFlags registers the flags of the options.
+func (o *Options) AddFlags(fs *pflag.FlagSet) {
+	fs.BoolVar(&o.Enabled, "standalone-validation", o.Enabled,
+		"If true, the controllers default and validate SandboxSets and SandboxClaims themselves, for installations "+
+			"without the webhooks. Defaults to true if ENABLE_WEBHOOKS=false.")
+}
+
+// DefaultSandboxSet defaults the SandboxSet as the webhook does and returns whether it is changed. SandboxSets never
+// reconciled are defaulted as new ones.
+func DefaultSandboxSet(sbs *agentsv1alpha1.SandboxSet) bool {
+	return setmutating.SetDefaults(sbs, sbs.Status.ObservedGeneration == 0)
+}
+
+// ValidateSandboxSet validates the SandboxSet as the webhook does, except for its resolved template.
+func ValidateSandboxSet(sbs *agentsv1alpha1.SandboxSet) field.ErrorList {
+	return setvalidating.ValidateSandboxSet(sbs)
+}
+
+// ValidateResolvedSandboxSet validates the template of the SandboxSet resolved from the base SandboxTemplate and the
+// overlay, if any.
+func ValidateResolvedSandboxSet(sbs, resolved *agentsv1alpha1.SandboxSet) field.ErrorList {
+	if sbs.Spec.TemplateOverlay == nil {
+		return nil
+	}
+	return setvalidating.ValidateResolvedTemplate(resolved)
+}
+
+// DefaultSandboxClaim defaults the new SandboxClaim as the webhook does and returns whether it is changed.
+func DefaultSandboxClaim(claim *agentsv1alpha1.SandboxClaim) bool {
+	defaulter := &claimmutating.SandboxClaimDefaulter{Latencies: claimlatency.Default}
+	return defaulter.SetDefaults(claim)
+}
+
+// ValidateSandboxClaim returns why the webhooks would have rejected the SandboxClaim, or an empty string if they would
+// not. The size of new claims is checked as they are marshalled, and only the replicas of the other ones are checked
+// against the limits of their SandboxSet, as the webhooks only reject their updates growing them.
+func ValidateSandboxClaim(ctx context.Context, reader client.Reader, claim *agentsv1alpha1.SandboxClaim,
+	sandboxSet *agentsv1alpha1.SandboxSet, create bool) (string, error) {
+	if create {
+		raw, err := json.Marshal(claim)
+		if err != nil {
+			return "", err
+		}
+		if err = objectsize.DefaultOptions.ValidateClaim(claim, len(raw)); err != nil {
+			return err.Error(), nil
+		}
+	}
+	return claimvalidating.CheckLimits(ctx, reader, claim, sandboxSet, create)
+}
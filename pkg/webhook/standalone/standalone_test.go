@@ -0,0 +1,121 @@
+package standalone
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+	"github.com/openkruise/agents/pkg/utils/objectsize"
+	claimmutating "github.com/openkruise/agents/pkg/webhook/sandboxclaim/mutating"
+)
+
+func TestSandboxSet(t *testing.T) {
+	sbs := &agentsv1alpha1.SandboxSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "pool", Namespace: "default"},
+		Spec: agentsv1alpha1.SandboxSetSpec{
+			Replicas: 1,
+			EmbeddedSandboxTemplate: agentsv1alpha1.EmbeddedSandboxTemplate{
+				Template: &corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "main", Image: "main"}}},
+				},
+			},
+		},
+	}
+	assert.True(t, DefaultSandboxSet(sbs))
+	assert.Equal(t, ptr.To(false), sbs.Spec.Template.Spec.AutomountServiceAccountToken)
+	assert.False(t, DefaultSandboxSet(sbs), "defaulted SandboxSets are kept")
+	assert.Empty(t, ValidateSandboxSet(sbs))
+	assert.Empty(t, ValidateResolvedSandboxSet(sbs, nil), "SandboxSets without overlays have no resolved templates to validate")
+
+	sbs.Spec.Replicas = -1
+	errList := ValidateSandboxSet(sbs)
+	require.Len(t, errList, 1)
+	assert.Equal(t, "spec.replicas", errList[0].Field)
+}
+
+func TestDefaultSandboxClaim(t *testing.T) {
+	claim := &agentsv1alpha1.SandboxClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "claim", Namespace: "default"},
+		Spec:       agentsv1alpha1.SandboxClaimSpec{TemplateName: "pool"},
+	}
+	assert.True(t, DefaultSandboxClaim(claim))
+	require.NotNil(t, claim.Spec.ClaimTimeout)
+	assert.Equal(t, claimmutating.DefaultClaimTimeout, claim.Spec.ClaimTimeout.Duration)
+	assert.False(t, DefaultSandboxClaim(claim))
+}
+
+func TestValidateSandboxClaim(t *testing.T) {
+	require.NoError(t, agentsv1alpha1.AddToScheme(scheme.Scheme))
+	opts := objectsize.DefaultOptions
+	t.Cleanup(func() { objectsize.DefaultOptions = opts })
+	objectsize.DefaultOptions.MaxClaimMetadataBytes = 100
+
+	sandboxSet := &agentsv1alpha1.SandboxSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "pool", Namespace: "default"},
+		Spec:       agentsv1alpha1.SandboxSetSpec{MaxReplicasPerClaim: ptr.To[int32](2)},
+	}
+	tests := []struct {
+		name          string
+		replicas      int32
+		envBytes      int
+		create        bool
+		expectMessage string
+	}{
+		{
+			name:     "valid",
+			replicas: 2,
+			create:   true,
+		},
+		{
+			name:          "new claim too large",
+			replicas:      1,
+			envBytes:      200,
+			create:        true,
+			expectMessage: "spec.labels, spec.annotations and spec.envVars are 203 bytes in total, exceeding the limit of 100 bytes",
+		},
+		{
+			name:     "size of claim in progress not checked",
+			replicas: 1,
+			envBytes: 200,
+		},
+		{
+			name:          "claim in progress beyond maxReplicasPerClaim",
+			replicas:      3,
+			expectMessage: "replicas 3 exceeds maxReplicasPerClaim 2 of SandboxSet pool",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claim := &agentsv1alpha1.SandboxClaim{
+				ObjectMeta: metav1.ObjectMeta{Name: "claim", Namespace: "default"},
+				Spec: agentsv1alpha1.SandboxClaimSpec{
+					TemplateName: "pool",
+					Replicas:     ptr.To(tt.replicas),
+					EnvVars:      map[string]string{"ENV": strings.Repeat("v", tt.envBytes)},
+				},
+			}
+			reader := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(claim).Build()
+			message, err := ValidateSandboxClaim(context.TODO(), reader, claim, sandboxSet, tt.create)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectMessage, message)
+		})
+	}
+}
+
+func TestOptions_AddFlags(t *testing.T) {
+	opts := Options{}
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	opts.AddFlags(fs)
+	require.NoError(t, fs.Parse([]string{"--standalone-validation"}))
+	assert.True(t, opts.Enabled)
+}
@@ -0,0 +1,84 @@
+import json
+import urllib.request
+from typing import Dict, Iterable
+
+FALLBACK_ANY = "Any"
+FALLBACK_NONE = "None"
+
+# Metadata keys of sandbox creation selecting the region of the pool to claim from, in the same sandbox-manager
+METADATA_PREFERRED_REGIONS = "e2b.agents.kruise.io/preferred-regions"
+METADATA_FORBIDDEN_REGIONS = "e2b.agents.kruise.io/forbidden-regions"
+METADATA_REGION_FALLBACK = "e2b.agents.kruise.io/region-fallback"
+
+
+class NoRegionAvailable(Exception):
+    pass
+
+
+def region_metadata(preferred: Iterable[str] = (), forbidden: Iterable[str] = (),
+                    fallback: str = FALLBACK_ANY) -> Dict[str, str]:
+    """Returns the metadata to create a sandbox from the pools of the regions labeled on their SandboxSets."""
+    return {
+        METADATA_PREFERRED_REGIONS: ",".join(preferred),
+        METADATA_FORBIDDEN_REGIONS: ",".join(forbidden),
+        METADATA_REGION_FALLBACK: fallback,
+    }
+
+
+def fetch_pressure(api_url: str, template_id: str, api_key: str, timeout: float = 2.0) -> dict:
+    """Fetches the claim pressure of the pool of the template from the sandbox-manager serving api_url."""
+    request = urllib.request.Request(f"{api_url}/templates/{template_id}/pressure", headers={"X-API-KEY": api_key})
+    with urllib.request.urlopen(request, timeout=timeout) as response:
+        return json.loads(response.read())
+
+
+def _capacity(pressure: dict) -> int:
+    return pressure.get("available", 0) - pressure.get("queuedClaims", 0)
+
+
+def select_region(pressures: Dict[str, dict], preferred: Iterable[str] = (), forbidden: Iterable[str] = (),
+                  fallback: str = FALLBACK_ANY) -> str:
+    """
+    Selects the region to claim from by the pool pressures of the regions, like the sandbox-manager does for the
+    regions of its pools: the first preferred region with free sandboxes, then with fallback Any the other region with
+    the most free sandboxes and the lowest claim latency. If every allowed region is exhausted, the claim is sent to
+    the first preferred region, or the allowed one with the lowest claim latency, to wait for sandboxes being created.
+    Regions missing in pressures, e.g. unreachable, are never selected.
+    """
+    preferred, forbidden = list(preferred), set(forbidden)
+    allowed = {region: p for region, p in pressures.items() if region not in forbidden}
+    if preferred and fallback == FALLBACK_NONE:
+        allowed = {region: p for region, p in allowed.items() if region in preferred}
+    if not allowed:
+        raise NoRegionAvailable("no allowed region is reachable")
+
+    for region in preferred:
+        if region in allowed and _capacity(allowed[region]) > 0:
+            return region
+    others = [region for region in allowed if region not in preferred and _capacity(allowed[region]) > 0]
+    if others:
+        return min(others, key=lambda r: (-_capacity(allowed[r]), allowed[r].get("claimLatencyP99Ms", 0), r))
+
+    for region in preferred:
+        if region in allowed:
+            return region
+    return min(allowed, key=lambda r: (allowed[r].get("claimLatencyP99Ms", 0), r))
+
+
+def pick_region(endpoints: Dict[str, str], template_id: str, api_key: str, preferred: Iterable[str] = (),
+                forbidden: Iterable[str] = (), fallback: str = FALLBACK_ANY,
+                timeout: float = 2.0) -> str:
+    """
+    Picks the region to claim a sandbox of the template from, given the API URL of the sandbox-manager of each
+    region, see select_region. Regions failing to report their pressure are skipped.
+    """
+    forbidden = set(forbidden)
+    pressures = {}
+    for region, api_url in endpoints.items():
+        if region in forbidden:
+            continue
+        try:
+            pressures[region] = fetch_pressure(api_url, template_id, api_key, timeout)
+        except Exception:
+            continue
+    return select_region(pressures, preferred, forbidden, fallback)
@@ -0,0 +1,3 @@
+from .tools import ToolServerSession, langchain_tools, llama_index_tools, load_spec
+
+__all__ = ["ToolServerSession", "langchain_tools", "llama_index_tools", "load_spec"]
@@ -0,0 +1,92 @@
+"""Python shim of the sandbox tools, the Go reference implementation is pkg/servers/tools/langchain.
+
+The tool definitions are loaded from tools.json, which is generated from pkg/servers/tools with
+`go generate ./pkg/servers/tools/` and checked by TestSpecUpToDate, so the shim does not hard-code any tool.
+Tool calls are sent to the tool server (cmd/tool-server) of a claimed sandbox.
+"""
+import json
+import os
+from typing import Any, Dict, List, Optional, Type
+
+import requests
+from pydantic import BaseModel, Field, create_model
+
+SPEC_VERSION = "v1"
+
+_JSON_TYPES = {"string": str, "integer": int, "number": float, "boolean": bool}
+
+
+def load_spec() -> Dict[str, Any]:
+    with open(os.path.join(os.path.dirname(__file__), "tools.json"), "r", encoding="utf-8") as f:
+        spec = json.load(f)
+    if spec["version"] != SPEC_VERSION:
+        raise ValueError(f"unsupported tool spec version {spec['version']}, expected {SPEC_VERSION}")
+    return spec
+
+
+class ToolServerSession:
+    """A claimed sandbox, whose tools are called through the tool server."""
+
+    def __init__(self, tool_server: str, sandbox_id: str, api_key: str, timeout: Optional[float] = None):
+        self.url = f"{tool_server.rstrip('/')}/sessions/{sandbox_id}/tool_calls"
+        self.sandbox_id = sandbox_id
+        self.api_key = api_key
+        self.timeout = timeout
+
+    def call(self, name: str, arguments: Dict[str, Any]) -> str:
+        resp = requests.post(
+            self.url,
+            headers={"X-API-KEY": self.api_key},
+            json={"tool_calls": [{
+                "id": name,
+                "type": "function",
+                "function": {"name": name, "arguments": json.dumps(arguments)},
+            }]},
+            timeout=self.timeout,
+        )
+        resp.raise_for_status()
+        return resp.json()["messages"][0]["content"]
+
+
+def _args_model(name: str, parameters: Dict[str, Any]) -> Type[BaseModel]:
+    required = set(parameters.get("required", []))
+    fields = {}
+    for field, schema in parameters.get("properties", {}).items():
+        field_type = _JSON_TYPES[schema["type"]]
+        description = schema.get("description", "")
+        if field in required:
+            fields[field] = (field_type, Field(..., description=description))
+        else:
+            fields[field] = (Optional[field_type], Field(None, description=description))
+    return create_model(f"{name}_args", **fields)
+
+
+def _functions(session: ToolServerSession):
+    for tool in load_spec()["tools"]:
+        function = tool["function"]
+        name = function["name"]
+
+        def call(_name=name, **kwargs) -> str:
+            return session.call(_name, {k: v for k, v in kwargs.items() if v is not None})
+
+        yield name, function["description"], _args_model(name, function["parameters"]), call
+
+
+def langchain_tools(session: ToolServerSession) -> List[Any]:
+    """Returns the sandbox tools as LangChain StructuredTools."""
+    from langchain_core.tools import StructuredTool
+
+    return [
+        StructuredTool.from_function(func=call, name=name, description=description, args_schema=args_model)
+        for name, description, args_model, call in _functions(session)
+    ]
+
+
+def llama_index_tools(session: ToolServerSession) -> List[Any]:
+    """Returns the sandbox tools as LlamaIndex FunctionTools."""
+    from llama_index.core.tools import FunctionTool, ToolMetadata
+
+    return [
+        FunctionTool(fn=call, metadata=ToolMetadata(name=name, description=description, fn_schema=args_model))
+        for name, description, args_model, call in _functions(session)
+    ]
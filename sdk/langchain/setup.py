@@ -0,0 +1,36 @@
+from setuptools import setup, find_packages
+
+with open("README.md", "r", encoding="utf-8") as fh:
+    long_description = fh.read()
+
+setup(
+    name="kruise-agents-langchain",
+    version="0.1.0",
+    author="OpenKruise",
+    author_email="",
+    description="LangChain and LlamaIndex tools running in OpenKruise Agents sandboxes",
+    long_description=long_description,
+    long_description_content_type="text/markdown",
+    url="https://github.com/openkruise/agents",
+    packages=find_packages(),
+    package_data={"kruise_agents_langchain": ["tools.json"]},
+    classifiers=[
+        "Development Status :: 3 - Alpha",
+        "Intended Audience :: Developers",
+        "License :: OSI Approved :: Apache Software License",
+        "Operating System :: OS Independent",
+        "Programming Language :: Python :: 3",
+    ],
+    python_requires=">=3.9,<4.0",
+    install_requires=[
+        "pydantic>=2.0",
+        "requests>=2.25",
+    ],
+    extras_require={
+        "langchain": ["langchain-core>=0.3"],
+        "llama-index": ["llama-index-core>=0.11"],
+        "dev": [
+            "pytest>=6.2.5",
+        ],
+    },
+)
@@ -309,14 +309,14 @@ var _ = Describe("SandboxClaim", func() {
 			}
 		})
 
-		It("should reject updates to replicas field", func() {
+		It("should allow updates to replicas field", func() {
 			By("Creating a SandboxClaim")
 			Expect(k8sClient.Create(ctx, sandboxClaim)).To(Succeed())
 
 			By("Waiting for controller to reconcile")
 			time.Sleep(time.Second)
 
-			By("Attempting to update replicas field")
+			By("Updating replicas field")
 			// Get the latest version to avoid ResourceVersion conflict
 			claim := &agentsv1alpha1.SandboxClaim{}
 			Expect(k8sClient.Get(ctx, types.NamespacedName{
@@ -325,12 +325,10 @@ var _ = Describe("SandboxClaim", func() {
 			}, claim)).To(Succeed())
 
 			claim.Spec.Replicas = ptr.To(int32(5))
+			claim.Spec.ReleasePolicy = agentsv1alpha1.SandboxClaimReleaseNewest
 
-			err := k8sClient.Update(ctx, claim)
-
-			By("Verifying the update is rejected by webhook")
-			Expect(err).To(HaveOccurred())
-			Expect(err.Error()).To(ContainSubstring("replicas is immutable"))
+			By("Verifying the update is accepted")
+			Expect(k8sClient.Update(ctx, claim)).To(Succeed())
 		})
 
 	})
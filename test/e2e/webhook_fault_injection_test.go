@@ -0,0 +1,165 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	agentsv1alpha1 "github.com/openkruise/agents/api/v1alpha1"
+	"github.com/openkruise/agents/pkg/webhook/faultinjection"
+)
+
+// The scenario only runs against a controller started with --webhook-fault-injection, and is skipped otherwise. It
+// degrades all webhooks and must not run in parallel with other tests.
+var _ = Describe("Webhook fault injection", Serial, func() {
+	var (
+		ctx       = context.Background()
+		namespace string
+	)
+
+	setFault := func(fault string) {
+		cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: faultinjection.ConfigMapName, Namespace: "sandbox-system"}}
+		_, err := controllerutil.CreateOrUpdate(ctx, k8sClient, cm, func() error {
+			cm.Data = map[string]string{faultinjection.AllWebhooks: fault}
+			return nil
+		})
+		Expect(err).NotTo(HaveOccurred())
+	}
+
+	newSandboxSet := func(replicas int32) *agentsv1alpha1.SandboxSet {
+		return &agentsv1alpha1.SandboxSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("fault-pool-%d", time.Now().UnixNano()),
+				Namespace: namespace,
+			},
+			Spec: agentsv1alpha1.SandboxSetSpec{
+				Replicas: replicas,
+				EmbeddedSandboxTemplate: agentsv1alpha1.EmbeddedSandboxTemplate{
+					Template: &corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name:  "test-container",
+									Image: "nginx:stable-alpine3.23",
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	// createWithRetry creates the object like a client retrying the errors of a degraded webhook
+	createWithRetry := func(obj client.Object) error {
+		backoff := wait.Backoff{Steps: 20, Duration: 100 * time.Millisecond, Factor: 1.5, Cap: 2 * time.Second}
+		return retry.OnError(backoff, apierrors.IsInternalError, func() error {
+			return k8sClient.Create(ctx, obj)
+		})
+	}
+
+	BeforeEach(func() {
+		namespace = createNamespace(ctx)
+
+		By("Failing every admission request")
+		setFault("rate: 1\ncode: 500\nmessage: e2e injected fault")
+		probe := newSandboxSet(0)
+		var err error
+		Eventually(func() error {
+			err = k8sClient.Create(ctx, probe)
+			if err == nil || apierrors.IsInternalError(err) {
+				return nil
+			}
+			return err
+		}, time.Second*30, time.Second).Should(Succeed())
+		if err == nil {
+			_ = k8sClient.Delete(ctx, probe)
+			Skip("webhook fault injection is not enabled in the controller")
+		}
+		Expect(err.Error()).To(ContainSubstring("e2e injected fault"))
+	})
+
+	AfterEach(func() {
+		_ = k8sClient.Delete(ctx, &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+			Name:      faultinjection.ConfigMapName,
+			Namespace: "sandbox-system",
+		}})
+		ns := &corev1.Namespace{}
+		if err := k8sClient.Get(ctx, types.NamespacedName{Name: namespace}, ns); err == nil {
+			_ = k8sClient.Delete(ctx, ns)
+		}
+	})
+
+	It("should claim sandboxes while half of the admission requests fail", func() {
+		setFault("rate: 0.5\ncode: 500")
+
+		By("Creating a SandboxSet with retries")
+		sandboxSet := newSandboxSet(3)
+		Expect(createWithRetry(sandboxSet)).To(Succeed())
+
+		By("Verifying the controllers retry creating the sandboxes of the pool")
+		Eventually(func() int32 {
+			_ = k8sClient.Get(ctx, client.ObjectKeyFromObject(sandboxSet), sandboxSet)
+			return sandboxSet.Status.AvailableReplicas
+		}, time.Minute*3, time.Second).Should(Equal(int32(3)))
+
+		By("Creating a SandboxClaim with retries")
+		claim := &agentsv1alpha1.SandboxClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("fault-claim-%d", time.Now().UnixNano()),
+				Namespace: namespace,
+			},
+			Spec: agentsv1alpha1.SandboxClaimSpec{
+				TemplateName:    sandboxSet.Name,
+				Replicas:        ptr.To(int32(2)),
+				SkipInitRuntime: true,
+			},
+		}
+		Expect(createWithRetry(claim)).To(Succeed())
+
+		By("Verifying the claim completes")
+		Eventually(func() agentsv1alpha1.SandboxClaimPhase {
+			_ = k8sClient.Get(ctx, client.ObjectKeyFromObject(claim), claim)
+			return claim.Status.Phase
+		}, time.Minute*2, time.Second).Should(Equal(agentsv1alpha1.SandboxClaimPhaseCompleted))
+		Expect(claim.Status.ClaimedReplicas).To(Equal(int32(2)))
+	})
+
+	It("should admit requests delayed by the webhooks", func() {
+		setFault("rate: 1\nlatency: 2s")
+
+		By("Creating a SandboxSet through delayed webhooks")
+		sandboxSet := newSandboxSet(0)
+		start := time.Now()
+		Expect(k8sClient.Create(ctx, sandboxSet)).To(Succeed())
+		Expect(time.Since(start)).To(BeNumerically(">=", 2*time.Second))
+	})
+})